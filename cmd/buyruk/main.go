@@ -8,7 +8,13 @@ import (
 
 func main() {
 	rootCmd := cli.NewRootCmd()
+	args, err := cli.ExpandAliases(rootCmd, os.Args[1:])
+	if err != nil {
+		rootCmd.PrintErrln("Error:", err)
+		os.Exit(cli.ExitCode(err))
+	}
+	rootCmd.SetArgs(args)
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(cli.ExitCode(err))
 	}
 }
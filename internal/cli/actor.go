@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+// currentActor returns who is running buyruk, as "Name <email>": user.name
+// and user.email from the config, or else from git's config. Returns "" when
+// neither is set, and changes are then not attributed. The actor is resolved
+// once per command run.
+func currentActor(ctx context.Context) string {
+	state := runStateFrom(ctx)
+	if state == nil {
+		return resolveActor()
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if !state.actorResolved {
+		state.actor, state.actorResolved = resolveActor(), true
+	}
+	return state.actor
+}
+
+// resolveActor looks up who is running buyruk; see currentActor.
+func resolveActor() string {
+	var name, email string
+	if cfg, err := config.Get(); err == nil {
+		name, email = cfg.UserName, cfg.UserEmail
+	}
+	if name == "" {
+		name = gitConfigValue("user.name")
+	}
+	if email == "" {
+		email = gitConfigValue("user.email")
+	}
+	return formatActor(name, email)
+}
+
+// formatActor joins a name and an email like git does.
+func formatActor(name, email string) string {
+	switch {
+	case name != "" && email != "":
+		return name + " <" + email + ">"
+	case email != "":
+		return "<" + email + ">"
+	default:
+		return name
+	}
+}
+
+// gitConfigValue returns a value of git's config for the working directory,
+// or "" when git or the value is missing.
+func gitConfigValue(key string) string {
+	output, err := git("", "config", "--get", key)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// attributionWriteFilter stamps who made a change on every issue and epic
+// write: the creator of new issues, epics, and comments, the last person to
+// change an issue or epic, and who made each new history event. Changes
+// synced in from another copy keep the attribution they arrive with.
+func attributionWriteFilter(ctx context.Context, path string, data []byte) ([]byte, error) {
+	who := currentActor(ctx)
+	if who == "" {
+		return data, nil
+	}
+	source := historySourceFrom(ctx)
+	if _, ok := issueFileProject(ctx, path); ok {
+		return attributeIssue(ctx, path, data, who, source)
+	}
+	if _, ok := epicFileProject(ctx, path); ok {
+		return attributeEpic(ctx, path, data, who, source)
+	}
+	return data, nil
+}
+
+// attributeIssue stamps who on the parts of an issue write that are new.
+// source is the command syncing the issue in, or "" for a local change.
+func attributeIssue(ctx context.Context, path string, data []byte, who, source string) ([]byte, error) {
+	var updated, old models.Issue
+	if err := json.Unmarshal(data, &updated); err != nil {
+		return data, nil
+	}
+	exists := storage.ReadJSON(ctx, path, &old) == nil
+
+	changed := false
+	stamp := func(field *string, value string) {
+		if *field != value {
+			*field = value
+			changed = true
+		}
+	}
+	if updated.CreatedBy == "" && !exists {
+		stamp(&updated.CreatedBy, who)
+	}
+	if source == "" || updated.UpdatedBy == "" {
+		if !exists || !sameIssueContent(&old, &updated) {
+			stamp(&updated.UpdatedBy, who)
+		}
+	}
+	for i, comment := range updated.Comments {
+		isNew := !slices.ContainsFunc(old.Comments, func(c models.Comment) bool {
+			return c.Body == comment.Body && c.CreatedAt == comment.CreatedAt
+		})
+		if comment.CreatedBy == "" && isNew {
+			stamp(&updated.Comments[i].CreatedBy, who)
+		}
+	}
+	for i, event := range updated.History {
+		if event.By == "" && !slices.Contains(old.History, event) {
+			stamp(&updated.History[i].By, who)
+		}
+	}
+	if !changed {
+		return data, nil
+	}
+
+	attributed, err := json.MarshalIndent(&updated, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to marshal issue %s: %w", updated.ID, err)
+	}
+	return attributed, nil
+}
+
+// attributeEpic stamps who on a new or changed epic. source is the command
+// syncing the epic in, or "" for a local change.
+func attributeEpic(ctx context.Context, path string, data []byte, who, source string) ([]byte, error) {
+	var updated, old models.Epic
+	if err := json.Unmarshal(data, &updated); err != nil {
+		return data, nil
+	}
+	exists := storage.ReadJSON(ctx, path, &old) == nil
+	if exists && sameEpicContent(&old, &updated) {
+		return data, nil
+	}
+
+	changed := false
+	if updated.CreatedBy == "" && !exists {
+		updated.CreatedBy, changed = who, true
+	}
+	if (source == "" || updated.UpdatedBy == "") && updated.UpdatedBy != who {
+		updated.UpdatedBy, changed = who, true
+	}
+	if !changed {
+		return data, nil
+	}
+
+	attributed, err := json.MarshalIndent(&updated, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to marshal epic %s: %w", updated.ID, err)
+	}
+	return attributed, nil
+}
+
+// sameEpicContent reports whether two versions of an epic differ only in
+// when and by whom they were last updated.
+func sameEpicContent(a, b *models.Epic) bool {
+	strip := func(epic models.Epic) []byte {
+		epic.UpdatedAt, epic.UpdatedBy = "", ""
+		data, _ := json.Marshal(&epic)
+		return data
+	}
+	return bytes.Equal(strip(*a), strip(*b))
+}
+
+// epicFileProject returns the project of an epic file path, if path is one.
+func epicFileProject(ctx context.Context, path string) (string, bool) {
+	dir := filepath.Dir(path)
+	if filepath.Ext(path) != ".json" || filepath.Base(dir) != "epics" {
+		return "", false
+	}
+	projectKey := filepath.Base(filepath.Dir(dir))
+	if epicsDir, err := storage.EpicsDir(ctx, projectKey); err != nil || epicsDir != dir {
+		return "", false
+	}
+	return projectKey, true
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestAttribution(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+	originalCfg, _ := config.Get()
+	defer func() {
+		if originalCfg != nil {
+			config.Save(t.Context(), originalCfg)
+		}
+	}()
+
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := NewRootCmd()
+		cmd.SetArgs(append(args, "--project", projectKey, "--no-hooks"))
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+		return out.String()
+	}
+	as := func(name, email string) {
+		t.Helper()
+		if err := config.Set(t.Context(), "user.name", name); err != nil {
+			t.Fatal(err)
+		}
+		if err := config.Set(t.Context(), "user.email", email); err != nil {
+			t.Fatal(err)
+		}
+	}
+	alice, bob := "Alice <alice@example.com>", "Bob <bob@example.com>"
+
+	as("Alice", "alice@example.com")
+	run("project", "create", projectKey)
+	run("issue", "create", "--title", "Login page")
+	run("epic", "create", "--id", projectKey+"-E1", "--title", "Auth")
+
+	as("Bob", "bob@example.com")
+	run("issue", "update", projectKey+"-1", "--status", "DOING")
+	run("issue", "comment", projectKey+"-1", "Started")
+
+	issue, err := loadLocalIssue(t.Context(), projectKey, projectKey+"-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issue.CreatedBy != alice || issue.UpdatedBy != bob {
+		t.Errorf("CreatedBy, UpdatedBy = %q, %q; want Alice, Bob", issue.CreatedBy, issue.UpdatedBy)
+	}
+	if len(issue.History) != 1 || issue.History[0].By != bob {
+		t.Errorf("History = %+v, want the status change by Bob", issue.History)
+	}
+	if len(issue.Comments) != 1 || issue.Comments[0].CreatedBy != bob {
+		t.Errorf("Comments = %+v, want a comment by Bob", issue.Comments)
+	}
+
+	// Writes that change nothing keep the last author
+	as("Carol", "carol@example.com")
+	run("issue", "update", projectKey+"-1", "--status", "DOING")
+	if issue, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-1"); issue.UpdatedBy != bob {
+		t.Errorf("UpdatedBy = %q after a no-op update, want Bob", issue.UpdatedBy)
+	}
+
+	run("epic", "update", projectKey+"-E1", "--title", "Authentication")
+	epicPath, _ := storage.EpicPath(t.Context(), projectKey, projectKey+"-E1")
+	var epic models.Epic
+	if err := storage.ReadJSON(t.Context(), epicPath, &epic); err != nil {
+		t.Fatal(err)
+	}
+	if epic.CreatedBy != alice || epic.UpdatedBy != "Carol <carol@example.com>" {
+		t.Errorf("Epic CreatedBy, UpdatedBy = %q, %q; want Alice, Carol", epic.CreatedBy, epic.UpdatedBy)
+	}
+
+	out := run("issue", "view", projectKey+"-1")
+	if !strings.Contains(out, "by Alice") || !strings.Contains(out, "by Bob: Started") {
+		t.Errorf("Expected authors in issue view, got:\n%s", out)
+	}
+	if out := run("issue", "flow", projectKey+"-1"); !strings.Contains(out, "TODO → DOING · Bob") {
+		t.Errorf("Expected the author in the timeline, got:\n%s", out)
+	}
+}
+
+func TestFormatActor(t *testing.T) {
+	for _, tt := range []struct{ name, email, want, wantName string }{
+		{"Alice", "alice@example.com", "Alice <alice@example.com>", "Alice"},
+		{"Alice", "", "Alice", "Alice"},
+		{"", "alice@example.com", "<alice@example.com>", "alice@example.com"},
+		{"", "", "", ""},
+	} {
+		got := formatActor(tt.name, tt.email)
+		if got != tt.want {
+			t.Errorf("formatActor(%q, %q) = %q, want %q", tt.name, tt.email, got, tt.want)
+		}
+		if name := models.ActorName(got); name != tt.wantName {
+			t.Errorf("ActorName(%q) = %q, want %q", got, name, tt.wantName)
+		}
+	}
+}
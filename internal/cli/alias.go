@@ -0,0 +1,308 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// maxAliasDepth bounds how many aliases can expand into one another.
+const maxAliasDepth = 10
+
+// Commands cobra adds at execution time, which aliases cannot shadow.
+var reservedCommandNames = []string{"help", "__complete", "__completeNoDesc"}
+
+// Alias is a command alias, for config alias list.
+type Alias struct {
+	Name    string `json:"name"`
+	Command string `json:"command"`
+}
+
+// NewConfigAliasCmd creates and returns the config alias command.
+func NewConfigAliasCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "alias",
+		Short: "Manage command aliases",
+		Long: `Define shortcuts for buyruk commands, like git aliases. An alias expands to
+its command when it is the first argument, and any further arguments are
+appended:
+
+  buyruk config alias set ls "list --status TODO --sort priority:desc"
+  buyruk ls --project WEB    # buyruk list --status TODO --sort priority:desc --project WEB
+
+Aliases can't shadow built-in commands. They are stored in the config file
+as alias.<name> keys, so config export shares them with a team.`,
+	}
+
+	cmd.AddCommand(NewConfigAliasSetCmd())
+	cmd.AddCommand(NewConfigAliasUnsetCmd())
+	cmd.AddCommand(NewConfigAliasListCmd())
+
+	return cmd
+}
+
+// NewConfigAliasSetCmd creates and returns the config alias set command.
+func NewConfigAliasSetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <name> <command>",
+		Short: "Define or replace an alias",
+		Example: `  buyruk config alias set ls "list --status TODO --sort priority:desc"
+  buyruk config alias set mine "list --filter 'assignee=alice and status!=DONE'"`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setAlias(args[0], args[1], cmd)
+		},
+	}
+
+	return cmd
+}
+
+// setAlias defines an alias after checking that it doesn't shadow a command,
+// that its command can be split into arguments, and that it doesn't expand to
+// itself.
+func setAlias(name, command string, cmd *cobra.Command) error {
+	if isCommandName(cmd.Root(), name) {
+		return conflictf("cli: %q is a buyruk command and can't be an alias", name)
+	}
+	words, err := splitAliasArgs(command)
+	if err != nil {
+		return invalidf("cli: invalid alias command: %w", err)
+	}
+	if len(words) == 0 {
+		return invalidf("cli: alias command cannot be empty")
+	}
+	// Expand the alias as it would be defined, to refuse one that expands to
+	// itself, directly or through other aliases
+	lookup := func(alias string) (string, bool) {
+		if alias == name {
+			return command, true
+		}
+		return config.LookupAlias(alias)
+	}
+	if _, err := expandAliases(cmd.Root(), []string{name}, lookup); err != nil {
+		return err
+	}
+	if err := config.Set(cmd.Context(), config.AliasKeyPrefix+name, command); err != nil {
+		return invalidf("cli: failed to set alias: %w", err)
+	}
+
+	fmt.Fprintf(successOut(cmd), "Set alias %s = %s\n", name, strings.TrimSpace(command))
+	return nil
+}
+
+// NewConfigAliasUnsetCmd creates and returns the config alias unset command.
+func NewConfigAliasUnsetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "unset <name>",
+		Short:             "Remove an alias",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeAliasArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return unsetAlias(args[0], cmd)
+		},
+	}
+
+	return cmd
+}
+
+// unsetAlias removes an alias.
+func unsetAlias(name string, cmd *cobra.Command) error {
+	if _, ok := config.LookupAlias(name); !ok {
+		return notFoundf("cli: alias %q not found", name)
+	}
+	if err := config.Set(cmd.Context(), config.AliasKeyPrefix+name, ""); err != nil {
+		return fmt.Errorf("cli: failed to remove alias: %w", err)
+	}
+
+	fmt.Fprintf(successOut(cmd), "Removed alias %s\n", name)
+	return nil
+}
+
+// NewConfigAliasListCmd creates and returns the config alias list command.
+func NewConfigAliasListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List aliases",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listAliases(cmd)
+		},
+	}
+
+	return cmd
+}
+
+// listAliases renders the aliases in the resolved output format.
+func listAliases(cmd *cobra.Command) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("cli: failed to load config: %w", err)
+	}
+	aliases := []Alias{}
+	for _, name := range sortedKeys(cfg.Aliases) {
+		aliases = append(aliases, Alias{Name: name, Command: cfg.Aliases[name]})
+	}
+
+	w := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(aliases)
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(w, aliases)
+	case config.DefaultFormatLSON:
+		for _, alias := range aliases {
+			fmt.Fprintf(w, "@ALIAS: %s|%s\n", alias.Name, alias.Command)
+		}
+	default:
+		if len(aliases) == 0 {
+			fmt.Fprintf(w, "No aliases (define one with `buyruk config alias set ls \"list --status TODO\"`)\n")
+			return nil
+		}
+		styles := ui.NewStyles()
+		for _, alias := range aliases {
+			fmt.Fprintf(w, "%s  %s\n", styles.ID(alias.Name), alias.Command)
+		}
+	}
+	return nil
+}
+
+// ExpandAliases replaces an alias in the command position of args with the
+// arguments of its command. Global flags before the command are kept, and
+// built-in commands always win over aliases. An alias may expand to another
+// alias.
+func ExpandAliases(root *cobra.Command, args []string) ([]string, error) {
+	return expandAliases(root, args, config.LookupAlias)
+}
+
+// expandAliases is ExpandAliases with the command of each alias looked up
+// with lookup.
+func expandAliases(root *cobra.Command, args []string, lookup func(name string) (string, bool)) ([]string, error) {
+	pos := commandPosition(root, args)
+	if pos < 0 {
+		return args, nil
+	}
+
+	var seen []string
+	for {
+		name := args[pos]
+		if isCommandName(root, name) {
+			return args, nil
+		}
+		command, ok := lookup(name)
+		if !ok {
+			return args, nil
+		}
+		if slices.Contains(seen, name) || len(seen) >= maxAliasDepth {
+			return nil, invalidf("cli: alias %q expands to itself (%s)", name, strings.Join(append(seen, name), " -> "))
+		}
+		seen = append(seen, name)
+
+		words, err := splitAliasArgs(command)
+		if err != nil {
+			return nil, invalidf("cli: invalid alias %q: %w", name, err)
+		}
+		if len(words) == 0 {
+			return nil, invalidf("cli: alias %q has no command", name)
+		}
+		expanded := make([]string, 0, len(args)+len(words)-1)
+		expanded = append(expanded, args[:pos]...)
+		expanded = append(expanded, words...)
+		expanded = append(expanded, args[pos+1:]...)
+		args = expanded
+	}
+}
+
+// commandPosition returns the index of the first argument that isn't a
+// global flag or a flag's value, or -1 if there is none.
+func commandPosition(root *cobra.Command, args []string) int {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return -1
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			return i
+		}
+		if strings.Contains(arg, "=") {
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		flag := root.PersistentFlags().Lookup(name)
+		if flag == nil && !strings.HasPrefix(arg, "--") && len(name) == 1 {
+			flag = root.PersistentFlags().ShorthandLookup(name)
+		}
+		if flag != nil && flag.NoOptDefVal == "" {
+			i++ // skip the flag's value
+		}
+	}
+	return -1
+}
+
+// isCommandName reports whether name is a command or command alias of root.
+func isCommandName(root *cobra.Command, name string) bool {
+	if slices.Contains(reservedCommandNames, name) {
+		return true
+	}
+	for _, c := range root.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitAliasArgs splits an alias command into arguments like a shell does:
+// on whitespace, except inside single or double quotes, with backslash
+// escapes outside single quotes.
+func splitAliasArgs(s string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				args = append(args, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if inWord {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+)
+
+func TestSplitAliasArgs(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"list --status TODO", []string{"list", "--status", "TODO"}},
+		{`  list   --filter "type=bug and status!=DONE" `, []string{"list", "--filter", "type=bug and status!=DONE"}},
+		{`list --filter 'title~"login"'`, []string{"list", "--filter", `title~"login"`}},
+		{`issue create --title a\ b ""`, []string{"issue", "create", "--title", "a b", ""}},
+	}
+	for _, tt := range tests {
+		got, err := splitAliasArgs(tt.input)
+		if err != nil {
+			t.Errorf("splitAliasArgs(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("splitAliasArgs(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{`list --filter "open`, `list \`} {
+		if _, err := splitAliasArgs(input); err == nil {
+			t.Errorf("Expected splitAliasArgs(%q) to fail", input)
+		}
+	}
+}
+
+func TestAliases(t *testing.T) {
+	defer func() {
+		for _, name := range []string{"aliastest-ls", "aliastest-mine", "aliastest-loop", "aliastest-next"} {
+			config.Set(t.Context(), config.AliasKeyPrefix+name, "")
+		}
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	for _, args := range [][]string{
+		{"config", "alias", "set", "aliastest-ls", "list --status TODO --sort priority:desc"},
+		{"config", "alias", "set", "aliastest-mine", "aliastest-ls --assignee me"},
+		{"config", "alias", "set", "aliastest-next", "aliastest-loop"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	if _, err := run("config", "alias", "set", "list", "list --status TODO"); err == nil {
+		t.Error("Expected an alias shadowing a command to be refused")
+	}
+	if _, err := run("config", "alias", "set", "Bad", "list"); err == nil {
+		t.Error("Expected an invalid alias name to be refused")
+	}
+	if _, err := run("config", "alias", "set", "aliastest-bad", `list --filter "open`); err == nil {
+		t.Error("Expected an alias with an unterminated quote to be refused")
+	}
+	for _, command := range []string{"aliastest-loop --quiet", "aliastest-next"} {
+		if _, err := run("config", "alias", "set", "aliastest-loop", command); ExitCode(err) != ExitInvalid || !strings.Contains(err.Error(), "expands to itself") {
+			t.Errorf("Expected an alias expanding to itself through %q to be refused, got %v", command, err)
+		}
+	}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"aliastest-ls", "--project", "WEB"}, "list --status TODO --sort priority:desc --project WEB"},
+		{[]string{"--project", "WEB", "-q", "aliastest-mine"}, "--project WEB -q list --status TODO --sort priority:desc --assignee me"},
+		{[]string{"--format=json", "list", "aliastest-ls"}, "--format=json list aliastest-ls"},
+		{[]string{"help", "aliastest-ls"}, "help aliastest-ls"},
+		{[]string{"unknown"}, "unknown"},
+	}
+	for _, tt := range tests {
+		got, err := ExpandAliases(NewRootCmd(), tt.args)
+		if err != nil {
+			t.Errorf("ExpandAliases(%q) failed: %v", tt.args, err)
+			continue
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("ExpandAliases(%q) = %q, want %q", tt.args, strings.Join(got, " "), tt.want)
+		}
+	}
+	// A loop in a config edited by hand fails when it's used
+	if err := config.Set(t.Context(), config.AliasKeyPrefix+"aliastest-loop", "aliastest-loop --quiet"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExpandAliases(NewRootCmd(), []string{"aliastest-loop"}); err == nil {
+		t.Error("Expected an alias that expands to itself to fail")
+	}
+
+	out, err := run("config", "alias", "list", "--format", "lson")
+	if err != nil {
+		t.Fatalf("config alias list failed: %v", err)
+	}
+	if !strings.Contains(out, "@ALIAS: aliastest-ls|list --status TODO --sort priority:desc\n") {
+		t.Errorf("Expected aliastest-ls in the alias list, got %q", out)
+	}
+
+	if _, err := run("config", "alias", "unset", "aliastest-ls"); err != nil {
+		t.Fatalf("config alias unset failed: %v", err)
+	}
+	if _, err := run("config", "alias", "unset", "aliastest-ls"); err == nil {
+		t.Error("Expected unsetting a missing alias to fail")
+	}
+}
@@ -0,0 +1,847 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/timeparse"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// Apply operation names
+const (
+	ApplyOpCreate  = "create"
+	ApplyOpUpdate  = "update"
+	ApplyOpLink    = "link"
+	ApplyOpComment = "comment"
+)
+
+// Apply result statuses
+const (
+	ApplyStatusOK         = "ok"          // Applied (or valid, with --dry-run)
+	ApplyStatusError      = "error"       // This operation failed
+	ApplyStatusRolledBack = "rolled_back" // Valid, but not applied because another operation failed
+	ApplyStatusSkipped    = "skipped"     // Not attempted because an earlier operation failed
+)
+
+// ApplyOp is a single operation read by `buyruk apply`, one JSON object per line.
+// Field names follow the issue JSON format; empty fields are left unchanged.
+type ApplyOp struct {
+	Op  string `json:"op"`            // Required: create, update, link, or comment
+	ID  string `json:"id,omitempty"`  // Issue ID (optional for create); "$ref" refers to an issue created earlier in the batch
+	Ref string `json:"ref,omitempty"` // create: name for "$ref" references in later operations
+
+	Title       string   `json:"title,omitempty"`
+	Type        string   `json:"type,omitempty"`
+	Status      string   `json:"status,omitempty"`
+	Priority    string   `json:"priority,omitempty"`
+	Assignee    string   `json:"assignee,omitempty"` // "none" unassigns the issue
+	Description string   `json:"description,omitempty"`
+	EpicID      string   `json:"epic_id,omitempty"`
+	ParentID    string   `json:"parent_id,omitempty"` // "none" makes the issue top-level
+	Due         string   `json:"due,omitempty"`       // Same values as --due; "none" clears it
+	Resolution  string   `json:"resolution,omitempty"`
+	Labels      []string `json:"labels,omitempty"` // Replaces the labels; [] clears them
+	Force       bool     `json:"force,omitempty"`  // update: bypass workflow transition rules
+
+	BlockedBy string `json:"blocked_by,omitempty"` // link: dependency ID
+	Remove    bool   `json:"remove,omitempty"`     // link: remove the dependency instead of adding it
+
+	Body string `json:"body,omitempty"` // comment: comment text
+}
+
+// ApplyResult reports the outcome of one operation.
+type ApplyResult struct {
+	Line   int    `json:"line"`
+	Op     string `json:"op"`
+	ID     string `json:"id,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// NewApplyCmd creates and returns the apply command.
+func NewApplyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apply [file]",
+		Short: "Apply a batch of operations from JSONL",
+		Long: `Apply a batch of operations to a project, one JSON object per line:
+
+  {"op":"create","ref":"a","title":"Add login","labels":["auth"]}
+  {"op":"update","id":"CORE-3","status":"DOING"}
+  {"op":"link","id":"$a","blocked_by":"CORE-3"}
+  {"op":"comment","id":"CORE-3","body":"Started"}
+
+The batch is applied under one project lock and is all-or-nothing: if any
+operation fails, nothing is written. One result is reported per operation
+(--format json emits one JSON object per line). Operations may refer to an
+issue created earlier in the batch as "$ref".
+
+Reads from the given file, or from stdin with --stdin.`,
+		Args: cobra.MaximumNArgs(1),
+		// Stdout carries the results; a failed batch must not append usage text
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runApply(cmd, args)
+		},
+	}
+
+	cmd.Flags().Bool("stdin", false, "Read operations from stdin")
+	cmd.Flags().Bool("dry-run", false, "Validate the batch without writing anything")
+
+	return cmd
+}
+
+// runApply reads, applies, and reports a batch of operations.
+func runApply(cmd *cobra.Command, args []string) error {
+	useStdin, _ := cmd.Flags().GetBool("stdin")
+	var input io.Reader
+	switch {
+	case useStdin && len(args) > 0:
+		return fmt.Errorf("cli: use either a file or --stdin, not both")
+	case useStdin:
+		input = cmd.InOrStdin()
+	case len(args) > 0:
+		file, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("cli: failed to open %s: %w", args[0], err)
+		}
+		defer file.Close()
+		input = file
+	default:
+		return fmt.Errorf("cli: no input (give a file or use --stdin)")
+	}
+
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+
+	projectDir, err := storage.ProjectDir(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve project directory: %w", err)
+	}
+	if _, err := os.Stat(projectDir); os.IsNotExist(err) {
+		return notFoundf("cli: project %q does not exist", projectKey)
+	}
+
+	ops, lines, results, err := readApplyOps(input)
+	if err != nil {
+		return err
+	}
+
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	failed := failedApplyResult(results)
+
+	if failed == nil {
+		var cleanup func()
+		if !dryRun {
+			// Dependencies in other projects get reverse links, so their projects are locked too
+			cleanup, err = storage.AcquireLocks(cmd.Context(), append([]string{projectKey}, applyLinkedProjects(cmd.Context(), ops, projectKey)...)...)
+			if err != nil {
+				return fmt.Errorf("cli: failed to acquire lock: %w", err)
+			}
+			defer cleanup()
+		}
+
+		batch, err := newApplyBatch(cmd.Context(), projectKey)
+		if err != nil {
+			return err
+		}
+		results = batch.apply(cmd.Context(), ops, lines)
+		failed = failedApplyResult(results)
+
+		if failed == nil && !dryRun {
+			if err := batch.write(cmd.Context()); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := renderApplyResults(results, cmd, cmd.OutOrStdout()); err != nil {
+		return fmt.Errorf("cli: failed to render results: %w", err)
+	}
+
+	if failed != nil {
+		return fmt.Errorf("cli: apply failed at line %d: %s (no changes were written)", failed.Line, failed.Error)
+	}
+	if dryRun {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Dry run: %d operations are valid, nothing was written\n", len(results))
+		return nil
+	}
+
+	warnQuota(projectKey, cmd)
+	return nil
+}
+
+// readApplyOps decodes one operation per non-blank line.
+// Undecodable lines are reported as errors in the returned results.
+func readApplyOps(r io.Reader) ([]ApplyOp, []int, []ApplyResult, error) {
+	var ops []ApplyOp
+	var lines []int
+	var results []ApplyResult
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var op ApplyOp
+		dec := json.NewDecoder(bytes.NewReader([]byte(text)))
+		dec.DisallowUnknownFields()
+		result := ApplyResult{Line: lineNo, Status: ApplyStatusOK}
+		if err := dec.Decode(&op); err != nil {
+			result.Status = ApplyStatusError
+			result.Error = fmt.Sprintf("invalid JSON: %v", err)
+		}
+		result.Op = op.Op
+		result.ID = op.ID
+
+		ops = append(ops, op)
+		lines = append(lines, lineNo)
+		results = append(results, result)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, nil, fmt.Errorf("cli: failed to read operations: %w", err)
+	}
+	if len(ops) == 0 {
+		return nil, nil, nil, fmt.Errorf("cli: no operations to apply")
+	}
+
+	// A malformed line fails the whole batch before anything is applied
+	if failedApplyResult(results) != nil {
+		for i := range results {
+			if results[i].Status == ApplyStatusOK {
+				results[i].Status = ApplyStatusSkipped
+			}
+		}
+	}
+
+	return ops, lines, results, nil
+}
+
+// failedApplyResult returns the first failed result, or nil if all succeeded.
+func failedApplyResult(results []ApplyResult) *ApplyResult {
+	for i := range results {
+		if results[i].Status == ApplyStatusError {
+			return &results[i]
+		}
+	}
+	return nil
+}
+
+// applyBatch holds the in-memory state of a batch until it is written.
+type applyBatch struct {
+	projectKey string
+	wf         *models.Workflow
+	index      *models.ProjectIndex
+	issues     map[string]*models.Issue // Issues loaded or created by the batch
+	changed    map[string]bool          // Issues to write
+	refs       map[string]string        // "$ref" name to issue ID
+	nextSeq    int
+}
+
+// newApplyBatch loads the project state a batch applies to.
+func newApplyBatch(ctx context.Context, projectKey string) (*applyBatch, error) {
+	wf, err := loadWorkflow(ctx, projectKey)
+	if err != nil {
+		return nil, err
+	}
+
+	indexPath, err := storage.ProjectIndexPath(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	index := &models.ProjectIndex{ProjectKey: projectKey, Issues: []models.IndexEntry{}}
+	if err := storage.ReadIndex(ctx, indexPath, index); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+
+	maxSeq := maxRedirectSequence(ctx, projectKey)
+	for _, entry := range index.Issues {
+		if _, seq, err := models.ParseIssueID(entry.ID); err == nil && seq > maxSeq {
+			maxSeq = seq
+		}
+	}
+
+	return &applyBatch{
+		projectKey: projectKey,
+		wf:         wf,
+		index:      index,
+		issues:     map[string]*models.Issue{},
+		changed:    map[string]bool{},
+		refs:       map[string]string{},
+		nextSeq:    maxSeq + 1,
+	}, nil
+}
+
+// apply applies the operations in order, stopping at the first failure.
+func (b *applyBatch) apply(ctx context.Context, ops []ApplyOp, lines []int) []ApplyResult {
+	results := make([]ApplyResult, len(ops))
+	failed := false
+	for i, op := range ops {
+		result := ApplyResult{Line: lines[i], Op: op.Op, ID: op.ID, Status: ApplyStatusSkipped}
+		if !failed {
+			id, err := b.applyOp(ctx, op)
+			if id != "" {
+				result.ID = id
+			}
+			if err != nil {
+				result.Status = ApplyStatusError
+				result.Error = strings.TrimPrefix(err.Error(), "cli: ")
+				failed = true
+				for j := 0; j < i; j++ {
+					results[j].Status = ApplyStatusRolledBack
+				}
+			} else {
+				result.Status = ApplyStatusOK
+			}
+		}
+		results[i] = result
+	}
+	return results
+}
+
+// applyOp applies a single operation and returns the ID of the issue it changed.
+func (b *applyBatch) applyOp(ctx context.Context, op ApplyOp) (string, error) {
+	switch op.Op {
+	case ApplyOpCreate:
+		return b.create(ctx, op)
+	case ApplyOpUpdate:
+		return b.update(ctx, op)
+	case ApplyOpLink:
+		return b.link(ctx, op)
+	case ApplyOpComment:
+		return b.comment(ctx, op)
+	case "":
+		return "", invalidf("cli: op is required")
+	default:
+		return "", invalidf("cli: unknown op %q (allowed: %s, %s, %s, %s)", op.Op, ApplyOpCreate, ApplyOpUpdate, ApplyOpLink, ApplyOpComment)
+	}
+}
+
+// resolveID resolves "$ref" references and checks that the issue belongs to the batch project.
+func (b *applyBatch) resolveID(id string) (string, error) {
+	if id == "" {
+		return "", invalidf("cli: id is required")
+	}
+	if ref, ok := strings.CutPrefix(id, "$"); ok {
+		resolved, ok := b.refs[ref]
+		if !ok {
+			return "", invalidf("cli: unknown reference %q (refs must be created earlier in the batch)", id)
+		}
+		return resolved, nil
+	}
+	if _, _, err := models.ParseIssueID(id); err != nil {
+		return "", invalidf("cli: invalid issue ID %q: %w", id, err)
+	}
+	if !models.IsIssueIDOf(id, b.projectKey) {
+		return "", invalidf("cli: issue %q is not in project %q (a batch applies to one project)", id, b.projectKey)
+	}
+	return id, nil
+}
+
+// load returns an issue of the batch project, from the batch or from disk.
+func (b *applyBatch) load(ctx context.Context, id string) (*models.Issue, error) {
+	if issue, ok := b.issues[id]; ok {
+		return issue, nil
+	}
+	key, _, err := parseIssueID(ctx, id)
+	if err != nil {
+		return nil, invalidf("cli: invalid issue ID %q: %w", id, err)
+	}
+	issuePath, err := storage.IssuePath(ctx, key, id)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve issue path: %w", err)
+	}
+	var issue models.Issue
+	if err := storage.ReadJSON(ctx, issuePath, &issue); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, notFoundf("cli: issue %q not found", id)
+		}
+		return nil, fmt.Errorf("cli: failed to load issue %s: %w", id, err)
+	}
+	b.issues[id] = &issue
+	return &issue, nil
+}
+
+// exists reports whether an issue exists in the batch or on disk.
+func (b *applyBatch) exists(id string) bool {
+	if _, ok := b.issues[id]; ok {
+		return true
+	}
+	return b.index.FindIssue(id) != nil
+}
+
+// save records an issue change in the batch and the in-memory index.
+func (b *applyBatch) save(issue *models.Issue) error {
+	if !models.IsIssueIDOf(issue.ID, b.projectKey) {
+		// Dependencies in other projects only gain or lose reverse links
+		b.issues[issue.ID] = issue
+		b.changed[issue.ID] = true
+		return nil
+	}
+	if err := issue.ValidateWithWorkflow(b.wf); err != nil {
+		return invalidf("cli: invalid issue: %w", err)
+	}
+	b.issues[issue.ID] = issue
+	b.changed[issue.ID] = true
+	b.index.AddIssue(issue)
+	return nil
+}
+
+// create applies a create operation.
+func (b *applyBatch) create(ctx context.Context, op ApplyOp) (string, error) {
+	if op.Title == "" {
+		return "", invalidf("cli: title is required")
+	}
+	if op.Ref != "" {
+		if _, ok := b.refs[op.Ref]; ok {
+			return "", fmt.Errorf("cli: duplicate ref %q", op.Ref)
+		}
+	}
+
+	issueID := op.ID
+	if issueID == "" {
+		var err error
+		if issueID, err = b.index.NewIssueID(b.projectKey, storage.NextSequence(ctx, b.projectKey, b.nextSeq), storage.Now(ctx)); err != nil {
+			return "", err
+		}
+	} else {
+		var err error
+		if issueID, err = b.resolveID(issueID); err != nil {
+			return "", err
+		}
+	}
+	if b.exists(issueID) {
+		return issueID, conflictf("cli: issue %q already exists", issueID)
+	}
+	if _, seq, err := models.ParseIssueID(issueID); err == nil && seq >= b.nextSeq {
+		b.nextSeq = seq + 1
+	}
+
+	issueType := op.Type
+	if issueType == "" {
+		issueType = models.TypeTask
+		if !b.wf.IsValidType(issueType) {
+			issueType = b.wf.TypeList()[0]
+		}
+	}
+	status := op.Status
+	if status == "" {
+		status = b.wf.DefaultStatus()
+	}
+
+	issue := &models.Issue{
+		ID:          issueID,
+		Type:        issueType,
+		Title:       op.Title,
+		Status:      status,
+		Priority:    op.Priority,
+		Description: op.Description,
+		Resolution:  op.Resolution,
+		CreatedAt:   storage.Timestamp(ctx),
+		UpdatedAt:   storage.Timestamp(ctx),
+	}
+	if len(op.Labels) > 0 {
+		issue.Labels = op.Labels
+	}
+	if err := b.index.AssignUID(issue, storage.Now(ctx)); err != nil {
+		return issueID, err
+	}
+	if err := b.setFields(ctx, issue, op); err != nil {
+		return issueID, err
+	}
+	if err := b.save(issue); err != nil {
+		return issueID, err
+	}
+
+	if op.Ref != "" {
+		b.refs[op.Ref] = issueID
+	}
+	return issueID, nil
+}
+
+// update applies an update operation.
+func (b *applyBatch) update(ctx context.Context, op ApplyOp) (string, error) {
+	issueID, err := b.resolveID(op.ID)
+	if err != nil {
+		return "", err
+	}
+	// Operations change the loaded issue in place: a failed operation
+	// aborts the whole batch, so partial changes are never written
+	issue, err := b.load(ctx, issueID)
+	if err != nil {
+		return issueID, err
+	}
+
+	if op.Title != "" {
+		issue.Title = op.Title
+	}
+	if op.Type != "" {
+		if !b.wf.IsValidType(op.Type) {
+			return issueID, invalidf("cli: invalid type %q (allowed: %s)", op.Type, strings.Join(b.wf.TypeList(), ", "))
+		}
+		issue.Type = op.Type
+	}
+	previousStatus := issue.Status
+	if op.Status != "" {
+		if !b.wf.IsValidStatus(op.Status) {
+			return issueID, invalidf("cli: invalid status %q (allowed: %s)", op.Status, strings.Join(b.wf.StatusList(), ", "))
+		}
+		if !op.Force && !b.wf.CanTransition(issue.Status, op.Status) {
+			next, _ := b.wf.NextStatuses(issue.Status)
+			return issueID, invalidf("cli: cannot move %s from %s to %s (allowed next: %s; use \"force\": true to override)",
+				issueID, issue.Status, op.Status, formatStatusList(next))
+		}
+		issue.Status = op.Status
+	}
+	if op.Priority != "" {
+		if !b.wf.IsValidPriority(op.Priority) {
+			return issueID, invalidf("cli: invalid priority %q (allowed: %s)", op.Priority, strings.Join(b.wf.PriorityList(), ", "))
+		}
+		issue.Priority = op.Priority
+	}
+	if op.Description != "" {
+		if err := editSealed(ctx, issue, b.projectKey, func() error {
+			issue.Description = op.Description
+			return nil
+		}); err != nil {
+			return issueID, err
+		}
+	}
+	if op.Resolution != "" {
+		issue.Resolution = op.Resolution
+	}
+	if op.Labels != nil {
+		issue.Labels = nil
+		for _, label := range op.Labels {
+			issue.AddLabel(label)
+		}
+	}
+	if err := b.setFields(ctx, issue, op); err != nil {
+		return issueID, err
+	}
+
+	// Check fields required to enter the new status
+	if !op.Force && issue.Status != previousStatus {
+		if missing := b.wf.MissingFields(issue, issue.Status); len(missing) > 0 {
+			return issueID, invalidf("cli: moving %s to %s requires: %s (use \"force\": true to override)",
+				issueID, issue.Status, strings.Join(missing, ", "))
+		}
+	}
+
+	issue.UpdatedAt = storage.Timestamp(ctx)
+	return issueID, b.save(issue)
+}
+
+// setFields applies the epic, parent, and due fields shared by create and update.
+func (b *applyBatch) setFields(ctx context.Context, issue *models.Issue, op ApplyOp) error {
+	if op.EpicID != "" {
+		if err := validateProjectEpicID(b.projectKey, op.EpicID); err != nil {
+			return err
+		}
+		epicPath, err := storage.EpicPath(ctx, b.projectKey, op.EpicID)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve epic path: %w", err)
+		}
+		if _, err := os.Stat(epicPath); err != nil {
+			if os.IsNotExist(err) {
+				return notFoundf("cli: epic %q not found", op.EpicID)
+			}
+			return fmt.Errorf("cli: failed to stat epic path %q: %w", epicPath, err)
+		}
+		issue.EpicID = op.EpicID
+	}
+
+	if op.ParentID == "none" {
+		issue.ParentID = ""
+	} else if op.ParentID != "" {
+		parentID, err := b.resolveID(op.ParentID)
+		if err != nil {
+			return err
+		}
+		if err := b.validateParent(issue.ID, parentID); err != nil {
+			return err
+		}
+		issue.ParentID = parentID
+	}
+
+	if op.Assignee == "none" {
+		issue.Assignee = ""
+	} else if op.Assignee != "" {
+		issue.Assignee = op.Assignee
+	}
+
+	if op.Due == "none" {
+		issue.Due = ""
+	} else if op.Due != "" {
+		t, err := timeparse.ParseTime(op.Due, storage.Now(ctx), config.Location())
+		if err != nil {
+			return invalidf("cli: invalid due value: %w", err)
+		}
+		issue.Due = t.UTC().Format(time.RFC3339)
+	}
+
+	return nil
+}
+
+// validateParent checks that parentID exists and that adopting issueID creates no cycle,
+// taking issues created earlier in the batch into account.
+func (b *applyBatch) validateParent(issueID, parentID string) error {
+	if parentID == issueID {
+		return invalidf("cli: issue %q cannot be its own parent", issueID)
+	}
+	if !b.exists(parentID) {
+		return notFoundf("cli: parent issue %q not found", parentID)
+	}
+
+	seen := map[string]bool{}
+	for current := parentID; current != "" && !seen[current]; {
+		if current == issueID {
+			return invalidf("cli: cannot make %q a subtask of its own subtask %q", issueID, parentID)
+		}
+		seen[current] = true
+		entry := b.index.FindIssue(current)
+		if entry == nil {
+			break
+		}
+		current = entry.ParentID
+	}
+	return nil
+}
+
+// link applies a link operation.
+func (b *applyBatch) link(ctx context.Context, op ApplyOp) (string, error) {
+	issueID, err := b.resolveID(op.ID)
+	if err != nil {
+		return "", err
+	}
+	if op.BlockedBy == "" {
+		return issueID, invalidf("cli: blocked_by is required")
+	}
+
+	dependencyID := op.BlockedBy
+	if strings.HasPrefix(dependencyID, "$") {
+		if dependencyID, err = b.resolveID(dependencyID); err != nil {
+			return issueID, err
+		}
+	} else {
+		depKey, _, err := parseIssueID(ctx, dependencyID)
+		if err != nil {
+			return issueID, invalidf("cli: invalid dependency ID %q: %w", dependencyID, err)
+		}
+		found := b.exists(dependencyID)
+		if depKey != b.projectKey {
+			depPath, err := storage.IssuePath(ctx, depKey, dependencyID)
+			if err != nil {
+				return issueID, fmt.Errorf("cli: failed to resolve dependency path: %w", err)
+			}
+			_, err = os.Stat(depPath)
+			found = err == nil
+		}
+		if !found && !op.Remove {
+			return issueID, notFoundf("cli: dependency %q not found", dependencyID)
+		}
+	}
+
+	issue, err := b.load(ctx, issueID)
+	if err != nil {
+		return issueID, err
+	}
+	if dependencyID == issueID {
+		return issueID, invalidf("cli: cannot link %s to itself", issueID)
+	}
+	// The dependency gets the reverse "blocks" link; a missing one can still be removed
+	dependency, err := b.load(ctx, dependencyID)
+	if err != nil && !op.Remove {
+		return issueID, err
+	}
+	now := storage.Timestamp(ctx)
+	if op.Remove {
+		models.UnlinkIssues(issue, dependency, dependencyID, models.LinkBlockedBy)
+	} else {
+		models.LinkIssues(issue, dependency, models.LinkBlockedBy)
+		issue.MarkBlocked(now)
+	}
+	issue.UpdatedAt = now
+	if dependency != nil {
+		dependency.UpdatedAt = now
+		if err := b.save(dependency); err != nil {
+			return issueID, err
+		}
+	}
+	return issueID, b.save(issue)
+}
+
+// applyLinkedProjects returns the other projects whose issues link operations
+// make dependencies of the batch project's issues.
+func applyLinkedProjects(ctx context.Context, ops []ApplyOp, projectKey string) []string {
+	var keys []string
+	for _, op := range ops {
+		if op.Op != ApplyOpLink || op.BlockedBy == "" || strings.HasPrefix(op.BlockedBy, "$") {
+			continue
+		}
+		key, _, err := parseIssueID(ctx, op.BlockedBy)
+		if err == nil && key != projectKey && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// comment applies a comment operation.
+func (b *applyBatch) comment(ctx context.Context, op ApplyOp) (string, error) {
+	issueID, err := b.resolveID(op.ID)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(op.Body) == "" {
+		return issueID, invalidf("cli: body is required")
+	}
+
+	issue, err := b.load(ctx, issueID)
+	if err != nil {
+		return issueID, err
+	}
+	now := storage.Timestamp(ctx)
+	if err := editSealed(ctx, issue, b.projectKey, func() error {
+		issue.AddComment(op.Body, now)
+		return nil
+	}); err != nil {
+		return issueID, err
+	}
+	issue.UpdatedAt = now
+	return issueID, b.save(issue)
+}
+
+// write writes the changed issues and the index under a transaction.
+// The caller must hold the locks of the batch project and of the projects of
+// linked dependencies. If any write fails, the files already written are
+// restored so the batch leaves no partial changes.
+func (b *applyBatch) write(ctx context.Context) error {
+	ids := make([]string, 0, len(b.changed))
+	for id := range b.changed {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	indexPath, err := storage.ProjectIndexPath(ctx, b.projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+
+	var writes []fileWrite
+	projectKeys := []string{b.projectKey}
+	for _, id := range ids {
+		key, _, err := parseIssueID(ctx, id)
+		if err != nil {
+			return invalidf("cli: invalid issue ID %q: %w", id, err)
+		}
+		if !slices.Contains(projectKeys, key) {
+			projectKeys = append(projectKeys, key)
+		}
+		issuePath, err := storage.IssuePath(ctx, key, id)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+		}
+		write, err := plannedJSONWrite(issuePath, b.issues[id])
+		if err != nil {
+			return err
+		}
+		writes = append(writes, write)
+	}
+	b.index.UpdatedAt = storage.Timestamp(ctx)
+	indexWrite, err := plannedIndexWrite(indexPath, b.index)
+	if err != nil {
+		return err
+	}
+	writes = append(writes, indexWrite)
+
+	files := make([]string, 0, len(writes))
+	for _, w := range writes {
+		files = append(files, w.path)
+	}
+
+	rollback := func() {
+		for _, key := range projectKeys {
+			storage.RollbackTransaction(ctx, key)
+		}
+	}
+	for _, key := range projectKeys {
+		if err := storage.BeginTransaction(ctx, key, "apply", map[string]interface{}{
+			"files": files,
+		}); err != nil {
+			rollback()
+			return fmt.Errorf("cli: failed to begin transaction: %w", err)
+		}
+	}
+
+	// The files written so far are restored if one fails
+	if err := writeFiles(ctx, writes); err != nil {
+		rollback()
+		return err
+	}
+
+	for _, key := range projectKeys {
+		if err := storage.CommitTransaction(ctx, key); err != nil {
+			return fmt.Errorf("cli: failed to commit transaction: %w", err)
+		}
+	}
+	return nil
+}
+
+// renderApplyResults renders one result per operation in the resolved output format.
+func renderApplyResults(results []ApplyResult, cmd *cobra.Command, w io.Writer) error {
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		// JSON Lines, mirroring the input
+		encoder := json.NewEncoder(w)
+		for _, result := range results {
+			if err := encoder.Encode(result); err != nil {
+				return err
+			}
+		}
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(w, results)
+	case config.DefaultFormatLSON:
+		for _, result := range results {
+			fmt.Fprintf(w, "@RESULT: %d|%s|%s|%s|%s\n", result.Line, result.Op, result.ID, result.Status, result.Error)
+		}
+	default:
+		styles := ui.NewStyles()
+		for _, result := range results {
+			status := result.Status
+			switch status {
+			case ApplyStatusOK:
+				status = styles.Success(status)
+			case ApplyStatusError:
+				status = styles.Error(status)
+			}
+			fmt.Fprintf(w, "line %d: %s %s %s", result.Line, result.Op, styles.ID(result.ID), status)
+			if result.Error != "" {
+				fmt.Fprintf(w, ": %s", result.Error)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestNewApplyCmd(t *testing.T) {
+	cmd := NewApplyCmd()
+	if cmd == nil {
+		t.Fatal("NewApplyCmd() returned nil")
+	}
+	if !strings.HasPrefix(cmd.Use, "apply") {
+		t.Errorf("Expected Use to start with 'apply', got '%s'", cmd.Use)
+	}
+}
+
+// setupApplyProject creates a project with one existing issue (<KEY>-1).
+func setupApplyProject(t *testing.T) string {
+	t.Helper()
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	t.Cleanup(func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	})
+
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "Existing"},
+	} {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		cmd.SetOut(new(bytes.Buffer))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+	return projectKey
+}
+
+// runApplyTest runs `apply --stdin --format json` and returns the decoded results.
+func runApplyTest(t *testing.T, projectKey, input string, extraArgs ...string) ([]ApplyResult, error) {
+	t.Helper()
+	cmd := NewRootCmd()
+	cmd.SetArgs(append([]string{"apply", "--stdin", "--project", projectKey, "--format", "json"}, extraArgs...))
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetIn(strings.NewReader(input))
+	err := cmd.Execute()
+
+	var results []ApplyResult
+	dec := json.NewDecoder(out)
+	for dec.More() {
+		var result ApplyResult
+		if decErr := dec.Decode(&result); decErr != nil {
+			t.Fatalf("Failed to decode result: %v\n%s", decErr, out.String())
+		}
+		results = append(results, result)
+	}
+	return results, err
+}
+
+func applyStatuses(results []ApplyResult) []string {
+	var statuses []string
+	for _, r := range results {
+		statuses = append(statuses, r.Status)
+	}
+	return statuses
+}
+
+func TestApply_Batch(t *testing.T) {
+	projectKey := setupApplyProject(t)
+	existing := projectKey + "-1"
+
+	input := strings.Join([]string{
+		`{"op":"create","ref":"login","title":"Add login","labels":["auth"]}`,
+		``,
+		`{"op":"update","id":"` + existing + `","status":"DOING","priority":"HIGH"}`,
+		`{"op":"link","id":"$login","blocked_by":"` + existing + `"}`,
+		`{"op":"comment","id":"$login","body":"Created by a script"}`,
+	}, "\n")
+
+	results, err := runApplyTest(t, projectKey, input)
+	if err != nil {
+		t.Fatalf("apply failed: %v", err)
+	}
+	if got, want := applyStatuses(results), []string{"ok", "ok", "ok", "ok"}; !slices.Equal(got, want) {
+		t.Fatalf("statuses = %v, want %v (%+v)", got, want, results)
+	}
+	created := projectKey + "-2"
+	if results[0].ID != created || results[2].ID != created || results[2].Line != 4 {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+
+	issuePath, _ := storage.IssuePath(t.Context(), projectKey, created)
+	var issue models.Issue
+	if err := storage.ReadJSON(t.Context(), issuePath, &issue); err != nil {
+		t.Fatalf("Failed to load created issue: %v", err)
+	}
+	if !slices.Equal(issue.BlockedBy, []string{existing}) || len(issue.Comments) != 1 || !slices.Equal(issue.Labels, []string{"auth"}) {
+		t.Errorf("Unexpected created issue: %+v", issue)
+	}
+	existingPath, _ := storage.IssuePath(t.Context(), projectKey, existing)
+	var dependency models.Issue
+	if err := storage.ReadJSON(t.Context(), existingPath, &dependency); err != nil {
+		t.Fatalf("Failed to load dependency: %v", err)
+	}
+	if got := dependency.LinkedIDs(models.LinkBlocks); !slices.Equal(got, []string{created}) {
+		t.Errorf("Expected %s to block %s, got %v", existing, created, got)
+	}
+
+	indexPath, _ := storage.ProjectIndexPath(t.Context(), projectKey)
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(t.Context(), indexPath, &index); err != nil {
+		t.Fatalf("Failed to load index: %v", err)
+	}
+	if entry := index.FindIssue(existing); entry == nil || entry.Status != "DOING" {
+		t.Errorf("Index entry for %s not updated: %+v", existing, entry)
+	}
+	if index.FindIssue(created) == nil {
+		t.Errorf("Index missing created issue %s", created)
+	}
+	if pending, _, _ := storage.CheckPendingTransaction(t.Context(), projectKey); pending {
+		t.Error("Transaction should be committed")
+	}
+}
+
+func TestApply_AllOrNothing(t *testing.T) {
+	projectKey := setupApplyProject(t)
+	existing := projectKey + "-1"
+
+	indexPath, _ := storage.ProjectIndexPath(t.Context(), projectKey)
+	before, _ := os.ReadFile(indexPath)
+
+	input := strings.Join([]string{
+		`{"op":"create","title":"Never written"}`,
+		`{"op":"comment","id":"` + existing + `","body":"Never written"}`,
+		`{"op":"update","id":"` + existing + `","status":"BOGUS"}`,
+		`{"op":"create","title":"Not attempted"}`,
+	}, "\n")
+
+	results, err := runApplyTest(t, projectKey, input)
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Fatalf("Expected failure at line 3, got %v", err)
+	}
+	if got, want := applyStatuses(results), []string{"rolled_back", "rolled_back", "error", "skipped"}; !slices.Equal(got, want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+	if !strings.Contains(results[2].Error, "invalid status") {
+		t.Errorf("Expected invalid status error, got %q", results[2].Error)
+	}
+
+	after, _ := os.ReadFile(indexPath)
+	if !bytes.Equal(before, after) {
+		t.Error("Project index changed after a failed batch")
+	}
+	issuePath, _ := storage.IssuePath(t.Context(), projectKey, projectKey+"-2")
+	if _, err := os.Stat(issuePath); !os.IsNotExist(err) {
+		t.Error("Issue from a failed batch was written")
+	}
+	var issue models.Issue
+	existingPath, _ := storage.IssuePath(t.Context(), projectKey, existing)
+	if err := storage.ReadJSON(t.Context(), existingPath, &issue); err != nil || len(issue.Comments) != 0 {
+		t.Errorf("Existing issue changed after a failed batch: %+v (%v)", issue, err)
+	}
+}
+
+func TestApply_InvalidInput(t *testing.T) {
+	projectKey := setupApplyProject(t)
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"malformed JSON", `{"op":"create","title":"A"}` + "\n" + `{"op":`, "invalid JSON"},
+		{"unknown field", `{"op":"create","title":"A","colour":"red"}`, "unknown field"},
+		{"unknown op", `{"op":"delete","id":"X-1"}`, "unknown op"},
+		{"unknown ref", `{"op":"comment","id":"$missing","body":"x"}`, "unknown reference"},
+		{"other project", `{"op":"comment","id":"OTHER-1","body":"x"}`, "not in project"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := runApplyTest(t, projectKey, tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v (%+v)", tt.want, err, results)
+			}
+		})
+	}
+}
+
+func TestApply_DryRun(t *testing.T) {
+	projectKey := setupApplyProject(t)
+
+	results, err := runApplyTest(t, projectKey, `{"op":"create","title":"Dry"}`, "--dry-run")
+	if err != nil {
+		t.Fatalf("apply --dry-run failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Status != ApplyStatusOK {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+
+	issuePath, _ := storage.IssuePath(t.Context(), projectKey, projectKey+"-2")
+	if _, err := os.Stat(issuePath); !os.IsNotExist(err) {
+		t.Error("--dry-run wrote an issue")
+	}
+}
@@ -0,0 +1,376 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// NewIssueAttachCmd creates and returns the issue attach command.
+func NewIssueAttachCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "attach <id> <file>",
+		Short: "Attach a file to an issue",
+		Long: `Attach a file to an issue. The file is copied into the project's
+attachments/<id>/ directory, and its name, size, and SHA-256 checksum are
+recorded in the issue. Attaching a file with the name of an existing
+attachment replaces it.
+
+Files larger than the project's attachment limit (10 MB unless changed with
+buyruk project attachment-limit) are refused. Files cannot be attached to
+sensitive issues, whose content is encrypted at rest.`,
+		Example: `  buyruk issue attach CORE-12 screenshot.png
+  buyruk issue attach CORE-12 ./logs/server.log --name crash.log`,
+		Args: cobra.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+			if len(args) == 1 {
+				return nil, cobra.ShellCompDirectiveDefault
+			}
+			return completeIssueArgs(1)(cmd, args, toComplete)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveIssueArgs(cmd, args[:1]); err != nil {
+				return err
+			}
+			return attachFile(args[0], args[1], cmd)
+		},
+	}
+
+	cmd.Flags().String("name", "", "Name to attach the file as (default: the file's name)")
+
+	return cmd
+}
+
+// NewIssueAttachmentsCmd creates and returns the issue attachments command.
+func NewIssueAttachmentsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "attachments <id>",
+		Short:             "List the files attached to an issue",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeIssueArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveIssueArgs(cmd, args); err != nil {
+				return err
+			}
+			return listAttachments(args[0], cmd)
+		},
+	}
+
+	return cmd
+}
+
+// NewProjectAttachmentLimitCmd creates and returns the project attachment-limit command.
+func NewProjectAttachmentLimitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "attachment-limit <key> [size|default]",
+		Short: "View or change the largest file issue attach accepts",
+		Long: `View or change the largest file that can be attached to an issue of a
+project, e.g. 512KB or 25MB. default goes back to 10 MB.`,
+		Example: `  buyruk project attachment-limit CORE
+  buyruk project attachment-limit CORE 25MB`,
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: completeProjectArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				return showAttachmentLimit(args[0], cmd)
+			}
+			return setAttachmentLimit(args[0], args[1], cmd)
+		},
+	}
+
+	return cmd
+}
+
+// attachFile copies a file into the attachments of an issue and records it
+// in the issue, in one project transaction.
+func attachFile(issueID, filePath string, cmd *cobra.Command) error {
+	projectKey, _, err := parseIssueID(cmd.Context(), issueID)
+	if err != nil {
+		return invalidf("cli: invalid issue ID %q: %w", issueID, err)
+	}
+	name, _ := cmd.Flags().GetString("name")
+	if name == "" {
+		name = filepath.Base(filePath)
+	}
+	if err := models.ValidateAttachmentName(name); err != nil {
+		return invalidf("cli: %w", err)
+	}
+
+	index, err := loadQueryIndex(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return notFoundf("cli: file %q not found", filePath)
+		}
+		return fmt.Errorf("cli: failed to read %s: %w", filePath, err)
+	}
+	if info.IsDir() {
+		return invalidf("cli: %s is a directory", filePath)
+	}
+	limit := index.AttachmentSizeLimit()
+	if info.Size() > limit {
+		return invalidf("cli: %s is %s, larger than the attachment limit of project %s (%s)", filePath, formatSize(info.Size()), projectKey, formatSize(limit))
+	}
+	data, err := readFileLimit(filePath, limit)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+
+	issuePath, err := storage.IssuePath(cmd.Context(), projectKey, issueID)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+	}
+	attachmentPath, err := storage.AttachmentPath(cmd.Context(), projectKey, issueID, name)
+	if err != nil {
+		return invalidf("cli: %w", err)
+	}
+
+	tx, err := storage.BeginProjectTx(cmd.Context(), projectKey, "attach_file", map[string]interface{}{
+		"issue_id": issueID,
+		"name":     name,
+	})
+	if err != nil {
+		return fmt.Errorf("cli: failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var issue models.Issue
+	if err := storage.ReadJSON(cmd.Context(), issuePath, &issue); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return notFoundf("cli: issue %q not found", issueID)
+		}
+		return fmt.Errorf("cli: failed to load issue: %w", err)
+	}
+	if issue.Sensitive {
+		return invalidf("cli: cannot attach files to sensitive issue %s (attachments are not encrypted)", issueID)
+	}
+
+	// Write the file before the issue lists it
+	if err := tx.Write(attachmentPath, data); err != nil {
+		return fmt.Errorf("cli: failed to write attachment: %w", err)
+	}
+	now := storage.Timestamp(cmd.Context())
+	replaced := issue.SetAttachment(models.Attachment{
+		Name:    name,
+		Size:    int64(len(data)),
+		SHA256:  hex.EncodeToString(sum[:]),
+		AddedAt: now,
+		AddedBy: currentActor(cmd.Context()),
+	})
+	issue.UpdatedAt = now
+	if err := tx.WriteJSON(issuePath, &issue); err != nil {
+		return fmt.Errorf("cli: failed to update issue: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("cli: failed to commit transaction: %w", err)
+	}
+
+	out := successOut(cmd)
+	if replaced {
+		fmt.Fprintf(out, "Replaced attachment %s of %s (%s)\n", name, issueID, formatSize(int64(len(data))))
+	} else {
+		fmt.Fprintf(out, "Attached %s to %s (%s)\n", name, issueID, formatSize(int64(len(data))))
+	}
+	return nil
+}
+
+// readFileLimit reads a file, failing if it grew past limit since it was checked.
+func readFileLimit(path string, limit int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to read %s: %w", path, err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to read %s: %w", path, err)
+	}
+	if int64(len(data)) > limit {
+		return nil, invalidf("cli: %s is larger than the attachment limit (%s)", path, formatSize(limit))
+	}
+	return data, nil
+}
+
+// listAttachments renders the attachments of an issue in the resolved output format.
+func listAttachments(issueID string, cmd *cobra.Command) error {
+	projectKey, _, err := parseIssueID(cmd.Context(), issueID)
+	if err != nil {
+		return invalidf("cli: invalid issue ID %q: %w", issueID, err)
+	}
+	issuePath, err := storage.IssuePath(cmd.Context(), projectKey, issueID)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+	}
+	var issue models.Issue
+	if err := storage.ReadJSON(cmd.Context(), issuePath, &issue); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return notFoundf("cli: issue %q not found", issueID)
+		}
+		return fmt.Errorf("cli: failed to load issue: %w", err)
+	}
+	attachments := issue.Attachments
+	if attachments == nil {
+		attachments = []models.Attachment{}
+	}
+
+	w := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(attachments)
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(w, attachments)
+	case config.DefaultFormatLSON:
+		for _, a := range attachments {
+			fmt.Fprintf(w, "@ATTACHMENT: %s|%d|%s\n", a.Name, a.Size, a.SHA256)
+		}
+	default:
+		if len(attachments) == 0 {
+			fmt.Fprintf(w, "No attachments (add one with `buyruk issue attach %s FILE`)\n", issueID)
+			return nil
+		}
+		styles := ui.NewStyles()
+		for _, a := range attachments {
+			path, _ := storage.AttachmentPath(cmd.Context(), projectKey, issueID, a.Name)
+			line := fmt.Sprintf("%s  %s  sha256:%s", styles.Title(a.Name), formatSize(a.Size), a.SHA256[:min(12, len(a.SHA256))])
+			if _, err := os.Stat(path); err != nil {
+				line += "  (missing)"
+			}
+			fmt.Fprintln(w, line)
+			fmt.Fprintf(w, "  %s\n", path)
+		}
+	}
+	return nil
+}
+
+// showAttachmentLimit prints the attachment limit of a project.
+func showAttachmentLimit(projectKey string, cmd *cobra.Command) error {
+	index, err := loadQueryIndex(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), formatSize(index.AttachmentSizeLimit()))
+	return nil
+}
+
+// setAttachmentLimit changes the attachment limit of a project.
+func setAttachmentLimit(projectKey, value string, cmd *cobra.Command) error {
+	var limit int64
+	if value != "default" {
+		parsed, err := config.ParseSize(value)
+		if err != nil {
+			return invalidf("cli: %w", err)
+		}
+		limit = parsed
+	}
+	if _, err := loadQueryIndex(cmd.Context(), projectKey); err != nil {
+		return err
+	}
+	indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	var index models.ProjectIndex
+	if err := storage.UpdateIndexAtomic(cmd.Context(), indexPath, &index, func(v interface{}) error {
+		v.(*models.ProjectIndex).MaxAttachmentSize = limit
+		return nil
+	}); err != nil {
+		return fmt.Errorf("cli: failed to update project index: %w", err)
+	}
+
+	fmt.Fprintf(successOut(cmd), "Attachment limit of project %q is %s\n", projectKey, formatSize(index.AttachmentSizeLimit()))
+	return nil
+}
+
+// readProjectAttachments reads the attached files of the given issues that
+// match the checksums recorded in them, by "<issue ID>/<name>". Missing or
+// changed files are skipped with a warning.
+func readProjectAttachments(ctx context.Context, projectKey string, issues []*models.Issue, errOut io.Writer) map[string][]byte {
+	files := map[string][]byte{}
+	for _, issue := range issues {
+		for _, a := range issue.Attachments {
+			path, err := storage.AttachmentPath(ctx, projectKey, issue.ID, a.Name)
+			if err != nil {
+				continue
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				fmt.Fprintf(errOut, "Warning: failed to read attachment %s of %s: %v\n", a.Name, issue.ID, err)
+				continue
+			}
+			if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != a.SHA256 {
+				fmt.Fprintf(errOut, "Warning: attachment %s of %s does not match its checksum\n", a.Name, issue.ID)
+				continue
+			}
+			files[issue.ID+"/"+a.Name] = data
+		}
+	}
+	return files
+}
+
+// writeProjectAttachments writes attached files read from an export archive
+// (by "<issue ID>/<name>") into a project, for the issues that record them
+// with the same checksum. Returns how many files were written.
+func writeProjectAttachments(ctx context.Context, projectKey string, files map[string][]byte, errOut io.Writer) int {
+	written := 0
+	issues := map[string]*models.Issue{}
+	for key, data := range files {
+		issueID, name, ok := cutAttachmentKey(key)
+		if !ok {
+			continue
+		}
+		issue, loaded := issues[issueID]
+		if !loaded {
+			issue = &models.Issue{}
+			if path, err := storage.IssuePath(ctx, projectKey, issueID); err != nil || storage.ReadJSON(ctx, path, issue) != nil {
+				issue = nil
+			}
+			issues[issueID] = issue
+		}
+		if issue == nil {
+			continue
+		}
+		a := issue.FindAttachment(name)
+		if sum := sha256.Sum256(data); a == nil || hex.EncodeToString(sum[:]) != a.SHA256 {
+			fmt.Fprintf(errOut, "Warning: skipping attachment %s of %s, not recorded in the issue\n", name, issueID)
+			continue
+		}
+		path, err := storage.AttachmentPath(ctx, projectKey, issueID, name)
+		if err != nil {
+			continue
+		}
+		if err := storage.WriteAtomic(ctx, path, data); err != nil {
+			fmt.Fprintf(errOut, "Warning: failed to write attachment %s of %s: %v\n", name, issueID, err)
+			continue
+		}
+		written++
+	}
+	return written
+}
+
+// cutAttachmentKey splits "<issue ID>/<name>" into the issue ID and the file name.
+func cutAttachmentKey(key string) (string, string, bool) {
+	dir, name := filepath.Split(filepath.FromSlash(key))
+	issueID := filepath.Clean(dir)
+	if dir == "" || filepath.Dir(issueID) != "." || models.ValidateAttachmentName(name) != nil {
+		return "", "", false
+	}
+	return issueID, name, true
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestIssueAttach(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	renamedKey := projectKey + "X"
+	defer func() {
+		for _, key := range []string{projectKey, renamedKey} {
+			projectDir, _ := storage.ProjectDir(t.Context(), key)
+			os.RemoveAll(projectDir)
+		}
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(append(args, "--no-hooks"))
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	mustRun := func(args ...string) string {
+		t.Helper()
+		out, err := run(args...)
+		if err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+		return out
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "notes.txt")
+	os.WriteFile(file, []byte("first"), 0644)
+	issueID := projectKey + "-1"
+
+	mustRun("project", "create", projectKey)
+	mustRun("issue", "create", "--project", projectKey, "--title", "Issue")
+	if out := mustRun("issue", "attach", issueID, file); !strings.Contains(out, "Attached notes.txt to "+issueID) {
+		t.Errorf("Unexpected attach output: %s", out)
+	}
+	stored, _ := storage.AttachmentPath(t.Context(), projectKey, issueID, "notes.txt")
+	if data, err := os.ReadFile(stored); err != nil || string(data) != "first" {
+		t.Errorf("Attached copy = %q, %v", data, err)
+	}
+
+	// Attaching the same name replaces the file and its checksum
+	os.WriteFile(file, []byte("second"), 0644)
+	if out := mustRun("issue", "attach", issueID, file); !strings.Contains(out, "Replaced attachment notes.txt") {
+		t.Errorf("Unexpected attach output: %s", out)
+	}
+	var attachments []models.Attachment
+	if err := json.Unmarshal([]byte(mustRun("issue", "attachments", issueID, "--format", "json")), &attachments); err != nil {
+		t.Fatalf("Failed to parse attachments: %v", err)
+	}
+	// sha256("second")
+	if len(attachments) != 1 || attachments[0].Size != 6 || attachments[0].SHA256 != "16367aacb67a4a017c8da8ab95682ccb390863780f7114dda0a0e0c55644c7c4" {
+		t.Errorf("attachments = %+v, want one 6-byte file", attachments)
+	}
+
+	// Files over the project's limit are refused
+	mustRun("project", "attachment-limit", projectKey, "4B")
+	if _, err := run("issue", "attach", issueID, file, "--name", "big.txt"); err == nil || !strings.Contains(err.Error(), "attachment limit") {
+		t.Errorf("Expected attachment limit error, got: %v", err)
+	}
+	mustRun("project", "attachment-limit", projectKey, "default")
+	if out := mustRun("project", "attachment-limit", projectKey); strings.TrimSpace(out) != "10.0 MB" {
+		t.Errorf("attachment-limit = %q, want 10.0 MB", out)
+	}
+	if _, err := run("issue", "attach", issueID, file, "--name", "../x"); err == nil {
+		t.Error("Expected a name with directories to be refused")
+	}
+
+	// An export archive carries the attachments back into an import
+	archive := filepath.Join(dir, "export.tar.gz")
+	mustRun("export", projectKey, "--output", archive)
+	attachmentsDir, _ := storage.AttachmentsDir(t.Context(), projectKey, issueID)
+	os.RemoveAll(attachmentsDir)
+	if out := mustRun("import", archive, "--overwrite"); !strings.Contains(out, "Restored 1 attachment(s)") {
+		t.Errorf("Unexpected import output: %s", out)
+	}
+	if data, err := os.ReadFile(stored); err != nil || string(data) != "second" {
+		t.Errorf("Imported attachment = %q, %v", data, err)
+	}
+
+	// Renaming the project keeps the attachments under the new issue ID
+	mustRun("project", "rename", projectKey, renamedKey)
+	renamed, _ := storage.AttachmentPath(t.Context(), renamedKey, renamedKey+"-1", "notes.txt")
+	if data, err := os.ReadFile(renamed); err != nil || string(data) != "second" {
+		t.Errorf("Renamed attachment = %q, %v", data, err)
+	}
+}
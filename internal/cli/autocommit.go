@@ -0,0 +1,235 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// localGitignore keeps the files buyruk writes for its own bookkeeping (locks,
+// pending transactions, lock stats, sequence counters, temporary files) out of
+// the repository.
+const localGitignore = `# Written by buyruk; runtime files that should not be committed
+.buyruk.lock
+.buyruk_pending
+.buyruk_lockstats
+.buyruk_seq
+*.tmp
+`
+
+// autocommitSummaryLimit is how many changes are named in the commit subject.
+const autocommitSummaryLimit = 3
+
+// autocommit commits the changes a command made to the repo-local .buyruk
+// directory, when autocommit is enabled, and to repositories set up by sync
+// init, unless --no-autocommit is given. Failures are reported as warnings:
+// the command itself already succeeded.
+func autocommit(cmd *cobra.Command) {
+	if skip, _ := cmd.Flags().GetBool("no-autocommit"); skip {
+		return
+	}
+	for _, repo := range gitSyncRepos(cmd.Context()) {
+		if err := commitLocalChanges(repo); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: autocommit failed: %v\n", err)
+		}
+	}
+	cfg, err := config.Get()
+	if err != nil || !cfg.Autocommit {
+		return
+	}
+	localDir, ok := storage.LocalDir()
+	if !ok {
+		return
+	}
+	if err := commitLocalChanges(localDir); err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: autocommit failed: %v\n", err)
+	}
+}
+
+// commitLocalChanges stages everything under localDir and commits it with a
+// message describing the changed issues. Other staged changes are left alone.
+func commitLocalChanges(localDir string) error {
+	if _, err := git(localDir, "rev-parse", "--is-inside-work-tree"); err != nil {
+		return fmt.Errorf("%s is not in a git repository", localDir)
+	}
+
+	gitignorePath := filepath.Join(localDir, ".gitignore")
+	if _, err := os.Stat(gitignorePath); errors.Is(err, os.ErrNotExist) {
+		if err := os.WriteFile(gitignorePath, []byte(localGitignore), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", gitignorePath, err)
+		}
+	}
+
+	if _, err := git(localDir, "add", "--all", "--", "."); err != nil {
+		return err
+	}
+	output, err := git(localDir, "diff", "--cached", "--name-status", "--no-renames", "-z", "--", ".")
+	if err != nil {
+		return err
+	}
+	changes := parseNameStatus(output)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	var summaries []string
+	for _, change := range changes {
+		if summary := describeLocalChange(localDir, change); summary != "" {
+			summaries = append(summaries, summary)
+		}
+	}
+	subject, body := autocommitMessage(summaries)
+
+	args := []string{"commit", "--quiet", "-m", subject}
+	if body != "" {
+		args = append(args, "-m", body)
+	}
+	args = append(args, "--", ".")
+	if _, err := git(localDir, args...); err != nil {
+		return err
+	}
+	return nil
+}
+
+// localChange is a file changed in the git index: A(dded), M(odified), or D(eleted).
+type localChange struct {
+	status string
+	path   string // relative to the repository root
+}
+
+// parseNameStatus parses the output of git diff --name-status -z.
+func parseNameStatus(output []byte) []localChange {
+	fields := strings.Split(strings.TrimSuffix(string(output), "\x00"), "\x00")
+	var changes []localChange
+	for i := 0; i+1 < len(fields); i += 2 {
+		changes = append(changes, localChange{status: fields[i][:1], path: fields[i+1]})
+	}
+	return changes
+}
+
+// describeLocalChange summarizes a changed file: "CORE-12 status TODO→DOING",
+// "CORE-13 created", "epic CORE-E2 updated", "project CORE created". Returns ""
+// for files that are not worth naming (the index, workflow, and so on).
+func describeLocalChange(localDir string, change localChange) string {
+	parts := strings.Split(filepath.ToSlash(change.path), "/")
+	n := len(parts)
+	if n < 2 {
+		return ""
+	}
+	dir, file := parts[n-2], parts[n-1]
+
+	switch {
+	case dir == "issues" && strings.HasSuffix(file, ".json"):
+		id := strings.TrimSuffix(file, ".json")
+		switch change.status {
+		case "A":
+			return id + " created"
+		case "D":
+			return id + " deleted"
+		}
+		before, errBefore := git(localDir, "show", "HEAD:"+change.path)
+		after, errAfter := git(localDir, "show", ":"+change.path)
+		if errBefore != nil || errAfter != nil {
+			return id + " updated"
+		}
+		if fields := describeIssueChange(before, after); fields != "" {
+			return id + " " + fields
+		}
+		return id + " updated"
+	case dir == "epics" && strings.HasSuffix(file, ".json"):
+		id := "epic " + strings.TrimSuffix(file, ".json")
+		switch change.status {
+		case "A":
+			return id + " created"
+		case "D":
+			return id + " deleted"
+		}
+		return id + " updated"
+	case file == "project.json" && n >= 3 && parts[n-3] == "projects":
+		switch change.status {
+		case "A":
+			return "project " + dir + " created"
+		case "D":
+			return "project " + dir + " deleted"
+		}
+	}
+	return ""
+}
+
+// describeIssueChange lists the fields that differ between two versions of an
+// issue file. Short text values are shown as old→new ("status TODO→DOING");
+// other fields by name. The updated_at timestamp and history are not listed.
+func describeIssueChange(before, after []byte) string {
+	changes, err := jsonFieldChanges(before, after)
+	if err != nil {
+		return ""
+	}
+	var fields []string
+	for _, change := range changes {
+		from, fromOK := shortJSONString(change.From)
+		to, toOK := shortJSONString(change.To)
+		if fromOK && toOK {
+			fields = append(fields, fmt.Sprintf("%s %s→%s", change.Field, from, to))
+		} else {
+			fields = append(fields, change.Field)
+		}
+	}
+	return strings.Join(fields, ", ")
+}
+
+// shortJSONString returns a JSON string value ("none" for a missing one) if it
+// is short enough to show in a commit subject.
+func shortJSONString(raw json.RawMessage) (string, bool) {
+	if raw == nil {
+		return "none", true
+	}
+	var s string
+	if json.Unmarshal(raw, &s) != nil || len(s) > 20 || strings.ContainsAny(s, "\n\r") {
+		return "", false
+	}
+	if s == "" {
+		return "none", true
+	}
+	return s, true
+}
+
+// autocommitMessage builds the commit subject ("buyruk: CORE-12 status
+// TODO→DOING") and, when there are several changes, a body listing them all.
+func autocommitMessage(summaries []string) (string, string) {
+	if len(summaries) == 0 {
+		return "buyruk: update project data", ""
+	}
+	subject := "buyruk: " + strings.Join(summaries[:min(len(summaries), autocommitSummaryLimit)], "; ")
+	if extra := len(summaries) - autocommitSummaryLimit; extra > 0 {
+		subject += fmt.Sprintf(" (and %d more)", extra)
+	}
+	if len(summaries) == 1 {
+		return subject, ""
+	}
+	return subject, "- " + strings.Join(summaries, "\n- ")
+}
+
+// git runs a git command in dir and returns its standard output.
+func git(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return output, nil
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestAutocommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"config", "user.name", "Test"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "commit.gpgsign", "false"},
+	} {
+		if _, err := git(repo, args...); err != nil {
+			t.Fatalf("git %v failed: %v", args, err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(repo, storage.LocalDirName), 0755); err != nil {
+		t.Fatalf("Failed to create .buyruk: %v", err)
+	}
+	t.Chdir(repo)
+
+	originalCfg, _ := config.Get()
+	defer func() {
+		if originalCfg != nil {
+			config.Save(t.Context(), originalCfg)
+		}
+	}()
+	if err := config.Set(t.Context(), "autocommit", "true"); err != nil {
+		t.Fatalf("Failed to enable autocommit: %v", err)
+	}
+
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		cmd.SetOut(new(bytes.Buffer))
+		errBuf := new(bytes.Buffer)
+		cmd.SetErr(errBuf)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+		return errBuf.String()
+	}
+	lastSubject := func() string {
+		t.Helper()
+		out, err := git(repo, "log", "-1", "--format=%s")
+		if err != nil {
+			t.Fatalf("git log failed: %v", err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	commitCount := func() string {
+		out, _ := git(repo, "rev-list", "--count", "HEAD")
+		return strings.TrimSpace(string(out))
+	}
+
+	if stderr := run("project", "create", "CORE"); stderr != "" {
+		t.Fatalf("Unexpected warnings: %s", stderr)
+	}
+	if got := lastSubject(); got != "buyruk: project CORE created" {
+		t.Errorf("Subject = %q, want project created", got)
+	}
+
+	run("issue", "create", "--project", "CORE", "--title", "Login redirect")
+	if got := lastSubject(); got != "buyruk: CORE-1 created" {
+		t.Errorf("Subject = %q, want CORE-1 created", got)
+	}
+
+	run("issue", "update", "CORE-1", "--status", "DOING")
+	if got := lastSubject(); got != "buyruk: CORE-1 status TODO→DOING" {
+		t.Errorf("Subject = %q, want status change", got)
+	}
+
+	// Read-only commands and --no-autocommit don't commit
+	before := commitCount()
+	run("list", "--project", "CORE")
+	run("issue", "update", "CORE-1", "priority=HIGH", "--no-autocommit")
+	if got := commitCount(); got != before {
+		t.Errorf("Expected no new commits, went from %s to %s", before, got)
+	}
+	if status, _ := git(repo, "status", "--porcelain"); !strings.Contains(string(status), "CORE-1.json") {
+		t.Errorf("Expected the uncommitted change to remain, got status: %s", status)
+	}
+
+	// The next command commits it along with its own change
+	run("issue", "update", "CORE-1", "--title", "Fix login redirect")
+	if got := lastSubject(); got != "buyruk: CORE-1 priority none→HIGH, title Login redirect→Fix login redirect" {
+		t.Errorf("Subject = %q, want priority and title change", got)
+	}
+
+	files, err := git(repo, "ls-files")
+	if err != nil {
+		t.Fatalf("git ls-files failed: %v", err)
+	}
+	if !strings.Contains(string(files), ".buyruk/.gitignore") {
+		t.Errorf("Expected .gitignore to be committed, got: %s", files)
+	}
+	for _, name := range []string{".buyruk.lock", ".buyruk_lockstats", ".tmp"} {
+		if strings.Contains(string(files), name) {
+			t.Errorf("Expected %s not to be committed, got: %s", name, files)
+		}
+	}
+}
+
+func TestAutocommitMessage(t *testing.T) {
+	tests := []struct {
+		summaries   []string
+		wantSubject string
+		wantBody    string
+	}{
+		{nil, "buyruk: update project data", ""},
+		{[]string{"CORE-1 created"}, "buyruk: CORE-1 created", ""},
+		{
+			[]string{"CORE-1 deleted", "CORE-2 deleted", "CORE-3 deleted", "CORE-4 deleted"},
+			"buyruk: CORE-1 deleted; CORE-2 deleted; CORE-3 deleted (and 1 more)",
+			"- CORE-1 deleted\n- CORE-2 deleted\n- CORE-3 deleted\n- CORE-4 deleted",
+		},
+	}
+	for _, tt := range tests {
+		subject, body := autocommitMessage(tt.summaries)
+		if subject != tt.wantSubject || body != tt.wantBody {
+			t.Errorf("autocommitMessage(%v) = %q, %q; want %q, %q", tt.summaries, subject, body, tt.wantSubject, tt.wantBody)
+		}
+	}
+}
@@ -0,0 +1,416 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// NewAwayCmd creates and returns the away command.
+func NewAwayCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "away",
+		Short: "Mark users away and hand over their work",
+		Long: `Mark users as away (vacation, leave), optionally until a date and with a
+delegate. Assigning an issue to an away user prints a warning, and
+away reassign moves their in-progress issues to the delegate.
+
+The away list is shared by all projects.`,
+	}
+
+	cmd.AddCommand(NewAwaySetCmd())
+	cmd.AddCommand(NewAwayClearCmd())
+	cmd.AddCommand(NewAwayListCmd())
+	cmd.AddCommand(NewAwayReassignCmd())
+
+	return cmd
+}
+
+// NewAwaySetCmd creates and returns the away set command.
+func NewAwaySetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <user>",
+		Short: "Mark a user as away",
+		Long: `Mark a user as away. Without --until the user is away until cleared with
+away clear. Setting a user again replaces their entry.`,
+		Example: `  buyruk away set alice --until 2025-02-01 --delegate bob
+  buyruk away set carol --note "parental leave"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setAway(args[0], cmd)
+		},
+	}
+
+	cmd.Flags().String("until", "", "Date the user is back (e.g. 2025-02-01, \"next monday\", 2w)")
+	cmd.Flags().String("delegate", "", "User who takes over while away")
+	cmd.Flags().String("note", "", "Note shown with the away entry")
+
+	return cmd
+}
+
+// NewAwayClearCmd creates and returns the away clear command.
+func NewAwayClearCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clear <user>",
+		Short: "Mark a user as back",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return clearAway(args[0], cmd)
+		},
+	}
+
+	return cmd
+}
+
+// NewAwayListCmd creates and returns the away list command.
+func NewAwayListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List away users",
+		Long:  "List the users marked as away. Entries whose end date has passed are shown as back.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listAway(cmd)
+		},
+	}
+
+	return cmd
+}
+
+// NewAwayReassignCmd creates and returns the away reassign command.
+func NewAwayReassignCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reassign <user>",
+		Short: "Move an away user's in-progress issues to their delegate",
+		Long: `Reassign the issues of a user in a project to their delegate, or to the
+user given with --to. Only issues in the --status statuses (DOING by default)
+are moved; all are reassigned or none are.`,
+		Example: `  buyruk away reassign alice --project CORE
+  buyruk away reassign alice --to carol --status DOING,REVIEW --dry-run`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return reassignAway(args[0], cmd)
+		},
+	}
+
+	cmd.Flags().String("to", "", "User to reassign to (default: the delegate)")
+	cmd.Flags().String("status", models.StatusDOING, "Comma-separated statuses of the issues to reassign")
+	cmd.Flags().Bool("dry-run", false, "Show the issues that would be reassigned without changing them")
+
+	return cmd
+}
+
+// loadAwayRegistry loads the away registry. Returns an empty registry if none exists.
+func loadAwayRegistry(ctx context.Context) (*models.AwayRegistry, error) {
+	awayPath, err := storage.AwayPath()
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve away path: %w", err)
+	}
+	registry := &models.AwayRegistry{}
+	if err := storage.ReadJSON(ctx, awayPath, registry); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("cli: failed to load away list: %w", err)
+	}
+	return registry, nil
+}
+
+// updateAwayRegistry applies a change to the away registry and saves it with
+// an atomic write (like the config file, it is not tied to a project lock).
+func updateAwayRegistry(ctx context.Context, update func(registry *models.AwayRegistry) error) error {
+	registry, err := loadAwayRegistry(ctx)
+	if err != nil {
+		return err
+	}
+	if err := update(registry); err != nil {
+		return err
+	}
+	if registry.Users == nil {
+		registry.Users = []models.Away{}
+	}
+
+	awayPath, err := storage.AwayPath()
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve away path: %w", err)
+	}
+	if err := storage.EnsureDir(awayPath); err != nil {
+		return fmt.Errorf("cli: failed to create config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(registry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cli: failed to marshal away list: %w", err)
+	}
+	if err := storage.WriteAtomic(ctx, awayPath, data); err != nil {
+		return fmt.Errorf("cli: failed to save away list: %w", err)
+	}
+	return nil
+}
+
+// setAway marks a user as away.
+func setAway(user string, cmd *cobra.Command) error {
+	away := models.Away{User: user, CreatedAt: storage.Timestamp(cmd.Context())}
+	away.Delegate, _ = cmd.Flags().GetString("delegate")
+	away.Note, _ = cmd.Flags().GetString("note")
+	if t, ok, err := getTimeFlag(cmd, "until"); err != nil {
+		return err
+	} else if ok {
+		if !t.After(storage.Now(cmd.Context())) {
+			return invalidf("cli: --until must be in the future")
+		}
+		away.Until = t.UTC().Format(time.RFC3339)
+	}
+	if err := away.Validate(); err != nil {
+		return invalidf("cli: invalid away entry: %w", err)
+	}
+
+	if err := updateAwayRegistry(cmd.Context(), func(registry *models.AwayRegistry) error {
+		registry.Set(away)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(successOut(cmd), "Marked %s as away%s\n", user, describeAway(&away))
+	warnAway(away.Delegate, cmd)
+	return nil
+}
+
+// clearAway marks a user as back.
+func clearAway(user string, cmd *cobra.Command) error {
+	if err := updateAwayRegistry(cmd.Context(), func(registry *models.AwayRegistry) error {
+		if !registry.Remove(user) {
+			return fmt.Errorf("cli: %s is not marked as away", user)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(successOut(cmd), "Marked %s as back\n", user)
+	return nil
+}
+
+// listAway lists the away users.
+func listAway(cmd *cobra.Command) error {
+	registry, err := loadAwayRegistry(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(registry.Users)
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(out, registry.Users)
+	case config.DefaultFormatLSON:
+		for i, away := range registry.Users {
+			if i > 0 {
+				fmt.Fprintln(out)
+			}
+			fmt.Fprintf(out, "@USER: %s\n", away.User)
+			if away.Until != "" {
+				fmt.Fprintf(out, "@UNTIL: %s\n", away.Until)
+			}
+			if away.Delegate != "" {
+				fmt.Fprintf(out, "@DELEGATE: %s\n", away.Delegate)
+			}
+			if away.Note != "" {
+				fmt.Fprintf(out, "@NOTE: %s\n", away.Note)
+			}
+			fmt.Fprintf(out, "@AWAY: %t\n", away.IsAway(storage.Now(cmd.Context())))
+		}
+		return nil
+	}
+
+	if len(registry.Users) == 0 {
+		fmt.Fprintln(out, "No users are away")
+		return nil
+	}
+	styles := ui.NewStyles()
+	for _, away := range registry.Users {
+		state := ""
+		if !away.IsAway(storage.Now(cmd.Context())) {
+			state = " (back)"
+		}
+		fmt.Fprintf(out, "%s%s%s\n", styles.Label(away.User), describeAway(&away), state)
+	}
+	return nil
+}
+
+// describeAway describes the end date, delegate, and note of an away entry.
+func describeAway(away *models.Away) string {
+	var parts []string
+	if away.Until != "" {
+		parts = append(parts, "until "+ui.FormatTime(away.Until, config.Location()))
+	}
+	if away.Delegate != "" {
+		parts = append(parts, "delegate: "+away.Delegate)
+	}
+	if away.Note != "" {
+		parts = append(parts, away.Note)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// warnAway prints a warning if the user is away. Errors reading the away list
+// are ignored, so a broken list never blocks assigning issues.
+func warnAway(user string, cmd *cobra.Command) {
+	if user == "" {
+		return
+	}
+	registry, err := loadAwayRegistry(cmd.Context())
+	if err != nil {
+		return
+	}
+	if away := registry.Active(user, storage.Now(cmd.Context())); away != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s is away%s\n", user, describeAway(away))
+	}
+}
+
+// reassignAway moves the issues of a user in the given statuses to another user.
+func reassignAway(user string, cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	wf, err := loadWorkflow(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	statusValue, _ := cmd.Flags().GetString("status")
+	statuses := splitList(statusValue)
+	if len(statuses) == 0 {
+		return invalidf("cli: --status is required")
+	}
+	for _, status := range statuses {
+		if !wf.IsValidStatus(status) {
+			return invalidf("cli: invalid status %q (allowed: %s)", status, strings.Join(wf.StatusList(), ", "))
+		}
+	}
+
+	registry, err := loadAwayRegistry(cmd.Context())
+	if err != nil {
+		return err
+	}
+	to, _ := cmd.Flags().GetString("to")
+	if to == "" {
+		away := registry.Find(user)
+		if away == nil || away.Delegate == "" {
+			return fmt.Errorf("cli: %s has no delegate (use --to)", user)
+		}
+		to = away.Delegate
+	}
+	if err := models.ValidateUser(to); err != nil {
+		return invalidf("cli: invalid --to value: %w", err)
+	}
+	if to == user {
+		return invalidf("cli: cannot reassign %s's issues to themselves", user)
+	}
+	if _, err := loadQueryIndex(cmd.Context(), projectKey); err != nil {
+		return err
+	}
+
+	cleanup, err := storage.AcquireLock(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to acquire lock: %w", err)
+	}
+	defer cleanup()
+
+	indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(cmd.Context(), indexPath, &index); err != nil {
+		return fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	out := successOut(cmd)
+	if dryRun {
+		out = cmd.OutOrStdout() // A dry run reports what would change
+	}
+	now := storage.Timestamp(cmd.Context())
+	var writes []fileWrite
+	var reassigned []string
+	for i, entry := range index.Issues {
+		if entry.Assignee != user || !slices.Contains(statuses, entry.Status) {
+			continue
+		}
+		issuePath, err := storage.IssuePath(cmd.Context(), projectKey, entry.ID)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+		}
+		// Re-read under the lock so concurrent edits are kept
+		original, err := os.ReadFile(issuePath)
+		if err != nil {
+			return fmt.Errorf("cli: failed to read issue %s: %w", entry.ID, err)
+		}
+		var issue models.Issue
+		if err := json.Unmarshal(original, &issue); err != nil {
+			return fmt.Errorf("cli: failed to parse issue %s: %w", entry.ID, err)
+		}
+		issue.Assignee = to
+		issue.UpdatedAt = now
+		data, err := json.MarshalIndent(&issue, "", "  ")
+		if err != nil {
+			return fmt.Errorf("cli: failed to marshal issue %s: %w", entry.ID, err)
+		}
+		writes = append(writes, fileWrite{path: issuePath, data: data, original: original})
+		index.Issues[i].Assignee = to
+		reassigned = append(reassigned, entry.ID)
+	}
+
+	if len(reassigned) == 0 {
+		fmt.Fprintf(out, "No %s issues assigned to %s in project %q\n", strings.Join(statuses, "/"), user, projectKey)
+		return nil
+	}
+	if dryRun {
+		for _, id := range reassigned {
+			fmt.Fprintf(out, "Would reassign %s from %s to %s\n", id, user, to)
+		}
+		return nil
+	}
+
+	index.UpdatedAt = now
+	indexWrite, err := plannedIndexWrite(indexPath, &index)
+	if err != nil {
+		return err
+	}
+	writes = append(writes, indexWrite)
+
+	if err := storage.BeginTransaction(cmd.Context(), projectKey, "away_reassign", map[string]interface{}{
+		"from": user,
+		"to":   to,
+	}); err != nil {
+		return fmt.Errorf("cli: failed to begin transaction: %w", err)
+	}
+	if err := writeFiles(cmd.Context(), writes); err != nil {
+		storage.RollbackTransaction(cmd.Context(), projectKey)
+		return err
+	}
+	if err := storage.CommitTransaction(cmd.Context(), projectKey); err != nil {
+		return fmt.Errorf("cli: failed to commit transaction: %w", err)
+	}
+
+	for _, id := range reassigned {
+		fmt.Fprintf(out, "Reassigned %s from %s to %s\n", id, user, to)
+	}
+	warnAway(to, cmd)
+	return nil
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestAway(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	away, delegate := strings.ToLower(projectKey)+"-alice", strings.ToLower(projectKey)+"-bob"
+	defer func() {
+		updateAwayRegistry(t.Context(), func(registry *models.AwayRegistry) error {
+			registry.Remove(away)
+			return nil
+		})
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(errOut)
+		err := cmd.Execute()
+		return out.String(), errOut.String(), err
+	}
+
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "In progress", "--assignee", away},
+		{"issue", "create", "--project", projectKey, "--title", "Not started", "--assignee", away},
+		{"issue", "update", projectKey + "-1", "--status", "DOING"},
+	} {
+		if _, _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	if _, _, err := run("away", "reassign", away, "--project", projectKey); err == nil {
+		t.Error("Expected reassign without a delegate to fail")
+	}
+	if _, _, err := run("away", "set", away, "--until", "2w", "--delegate", delegate); err != nil {
+		t.Fatalf("away set failed: %v", err)
+	}
+	if _, _, err := run("away", "set", away, "--until", "2020-01-01"); err == nil {
+		t.Error("Expected a past --until to fail")
+	}
+
+	// Assigning to an away user warns
+	_, errOut, err := run("issue", "create", "--project", projectKey, "--title", "New work", "--assignee", away)
+	if err != nil {
+		t.Fatalf("issue create failed: %v", err)
+	}
+	if !strings.Contains(errOut, "Warning: "+away+" is away") || !strings.Contains(errOut, "delegate: "+delegate) {
+		t.Errorf("Expected away warning, got: %q", errOut)
+	}
+	if _, errOut, _ := run("issue", "update", projectKey+"-2", "--assignee", delegate); strings.Contains(errOut, "Warning") {
+		t.Errorf("Expected no warning for a present user, got: %q", errOut)
+	}
+
+	out, _, err := run("away", "list", "--format", "lson")
+	if err != nil {
+		t.Fatalf("away list failed: %v", err)
+	}
+	if !strings.Contains(out, "@USER: "+away) || !strings.Contains(out, "@DELEGATE: "+delegate) {
+		t.Errorf("Expected away user in list, got: %s", out)
+	}
+
+	out, _, err = run("away", "reassign", away, "--project", projectKey, "--dry-run")
+	if err != nil {
+		t.Fatalf("away reassign --dry-run failed: %v", err)
+	}
+	if !strings.Contains(out, "Would reassign "+projectKey+"-1") {
+		t.Errorf("Expected dry run to list the DOING issue, got: %s", out)
+	}
+
+	out, _, err = run("away", "reassign", away, "--project", projectKey)
+	if err != nil {
+		t.Fatalf("away reassign failed: %v", err)
+	}
+	if !strings.Contains(out, "Reassigned "+projectKey+"-1 from "+away+" to "+delegate) || strings.Contains(out, projectKey+"-3") {
+		t.Errorf("Expected only the DOING issue to be reassigned, got: %s", out)
+	}
+	index, err := loadQueryIndex(t.Context(), projectKey)
+	if err != nil {
+		t.Fatalf("Failed to load index: %v", err)
+	}
+	if entry := index.FindIssue(projectKey + "-1"); entry.Assignee != delegate {
+		t.Errorf("Expected index assignee %s, got %s", delegate, entry.Assignee)
+	}
+	if entry := index.FindIssue(projectKey + "-3"); entry.Assignee != away {
+		t.Errorf("Expected TODO issue to stay assigned, got %s", entry.Assignee)
+	}
+	if out, _, _ := run("query", "--project", projectKey, "--count", "assignee="+delegate); strings.TrimSpace(out) != "2" {
+		t.Errorf("Expected 2 issues assigned to the delegate, got %q", out)
+	}
+
+	if _, _, err := run("away", "clear", away); err != nil {
+		t.Fatalf("away clear failed: %v", err)
+	}
+	if _, _, err := run("away", "clear", away); err == nil {
+		t.Error("Expected clearing a present user to fail")
+	}
+}
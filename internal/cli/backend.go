@@ -0,0 +1,357 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// The storage backend (config set storage_backend URL) keeps a team's
+// projects in an S3 bucket, on a WebDAV share, or on a buyruk server, one
+// export per project under the URL. Commands work on a local copy of the
+// backend's projects: before a command runs, the projects that changed in the
+// backend are fetched, and once it succeeds, the projects it changed are
+// written back. Every write is conditional on the version (ETag) the local
+// copy was fetched at, so instead of the lock files, which only order the
+// commands of one machine, the backend refuses a write based on a version
+// another machine has replaced, and the command fails with a conflict.
+
+// backendFreeCommands are the top-level commands that don't use projects, and
+// run without reaching the storage backend.
+var backendFreeCommands = []string{"version", "config", "completion", "help", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd}
+
+// backendRun is a command run's local copy of the storage backend.
+type backendRun struct {
+	url string
+
+	mu      sync.Mutex
+	started map[string]*models.BackendCopy // Projects of the local copy when the command started, by key
+	written map[string]bool                // Keys of the projects the command wrote
+}
+
+// storageBackendOf returns the base URL of the storage backend a command
+// keeps projects in, or "" if it uses a data directory: --data-dir and
+// repo-local mode take precedence over the storage_backend config.
+func storageBackendOf(cmd *cobra.Command) string {
+	if dir, _ := cmd.Flags().GetString("data-dir"); dir != "" {
+		return ""
+	}
+	if _, local := storage.LocalDir(); local {
+		return ""
+	}
+	cfg, err := config.Get()
+	if err != nil {
+		return ""
+	}
+	return cfg.StorageBackend
+}
+
+// startBackendRun returns the backendRun of a command about to run, or nil if
+// it doesn't use a storage backend. A command run by another command shares
+// the backendRun of that command, which writes back the changes of both.
+func startBackendRun(cmd *cobra.Command, parent *runState) *backendRun {
+	if parent != nil {
+		return parent.backend
+	}
+	backendURL := storageBackendOf(cmd)
+	if backendURL == "" {
+		return nil
+	}
+	top := cmd
+	for top.HasParent() && top.Parent().HasParent() {
+		top = top.Parent()
+	}
+	if slices.Contains(backendFreeCommands, top.Name()) {
+		return nil
+	}
+	return &backendRun{url: backendURL, written: map[string]bool{}}
+}
+
+// observeWrite records the project of a file the command writes.
+func (b *backendRun) observeWrite(ctx context.Context, path string) {
+	dataDir, err := storage.DataDir(ctx)
+	if err != nil {
+		return
+	}
+	rel, err := filepath.Rel(filepath.Join(dataDir, "projects"), path)
+	if err != nil || !filepath.IsLocal(rel) {
+		return
+	}
+	projectKey, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.written[projectKey] = true
+}
+
+// refreshBackend brings the local copy of the storage backend up to date
+// before a command runs: projects that changed in the backend replace their
+// local copy, new ones are fetched, and deleted ones are removed. If the
+// backend can't be reached, the command runs on the local copy as it is.
+func refreshBackend(cmd *cobra.Command) error {
+	state := runStateFrom(cmd.Context())
+	if state == nil || state.nested || state.backend == nil {
+		return nil
+	}
+	b := state.backend
+	ctx := backendSyncContext(cmd.Context())
+	errOut := cmd.ErrOrStderr()
+
+	keys, err := listRemoteProjects(ctx, b.url)
+	if err != nil {
+		fmt.Fprintf(errOut, "Warning: failed to reach the storage backend, using the local copy of its projects: %v\n", err)
+	} else {
+		for _, projectKey := range keys {
+			if err := b.fetchProject(ctx, projectKey, errOut); err != nil {
+				fmt.Fprintf(errOut, "Warning: failed to fetch project %q from the storage backend: %v\n", projectKey, err)
+			}
+		}
+		local, err := storage.ListProjects(ctx)
+		if err != nil {
+			return fmt.Errorf("cli: failed to list projects: %w", err)
+		}
+		for _, projectKey := range local {
+			if slices.Contains(keys, projectKey) {
+				continue
+			}
+			if synced, err := loadBackendCopy(ctx, projectKey, b.url); err == nil && synced != nil {
+				if err := removeBackendProject(ctx, projectKey, errOut); err != nil {
+					fmt.Fprintf(errOut, "Warning: failed to remove project %q, which was deleted from the storage backend: %v\n", projectKey, err)
+				}
+			}
+		}
+	}
+
+	b.started = map[string]*models.BackendCopy{}
+	local, err := storage.ListProjects(ctx)
+	if err != nil {
+		return fmt.Errorf("cli: failed to list projects: %w", err)
+	}
+	for _, projectKey := range local {
+		if synced, err := loadBackendCopy(ctx, projectKey, b.url); err == nil && synced != nil {
+			b.started[projectKey] = synced
+		}
+	}
+	return nil
+}
+
+// fetchProject replaces the local copy of a project with the backend's, unless
+// the local copy is at the backend's version.
+func (b *backendRun) fetchProject(ctx context.Context, projectKey string, errOut io.Writer) error {
+	projectURL := b.url + "/" + projectKey
+	synced, err := loadBackendCopy(ctx, projectKey, b.url)
+	if err != nil {
+		return err
+	}
+	known := ""
+	if synced != nil {
+		known = synced.ETag
+	}
+	store, err := newRemoteStore(ctx, projectURL)
+	if err != nil {
+		return err
+	}
+	content, etag, err := store.Get(known)
+	if errors.Is(err, errRemoteUnchanged) || errors.Is(err, errRemoteMissing) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	data, err := parseRemoteProject(content)
+	if err != nil {
+		return err
+	}
+	if data.Project.ProjectKey != projectKey {
+		return fmt.Errorf("cli: storage backend returned project %q for %q", data.Project.ProjectKey, projectKey)
+	}
+
+	importCmd := &cobra.Command{}
+	importCmd.SetContext(ctx)
+	importCmd.SetOut(io.Discard)
+	importCmd.SetErr(errOut)
+	if err := importExportData(data, true, importCmd); err != nil {
+		return err
+	}
+	return saveBackendCopy(ctx, projectKey, projectURL, etag, errOut)
+}
+
+// storeBackend writes the projects a command changed back to the storage
+// backend once it succeeds: changed projects replace the backend's version
+// they were fetched at, new ones are created, and deleted ones removed. A
+// project that changed in the backend meanwhile fails the command with a
+// conflict; its local copy is fetched again by the next command.
+func storeBackend(cmd *cobra.Command) error {
+	state := runStateFrom(cmd.Context())
+	if state == nil || state.nested || state.backend == nil {
+		return nil
+	}
+	b := state.backend
+	ctx := backendSyncContext(cmd.Context())
+	errOut := cmd.ErrOrStderr()
+
+	local, err := storage.ListProjects(ctx)
+	if err != nil {
+		return fmt.Errorf("cli: failed to list projects: %w", err)
+	}
+	b.mu.Lock()
+	written := b.written
+	b.mu.Unlock()
+	var errs []error
+	// Projects are created before others are deleted, so a renamed
+	// project is never missing from the backend
+	for _, projectKey := range local {
+		synced, err := loadBackendCopy(ctx, projectKey, b.url)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if synced != nil && !written[projectKey] {
+			continue
+		}
+		if err := b.storeProject(ctx, projectKey, synced, errOut); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for projectKey, synced := range b.started {
+		if slices.Contains(local, projectKey) {
+			continue
+		}
+		store, err := newRemoteStore(ctx, synced.URL)
+		if err == nil {
+			err = store.Delete(synced.ETag)
+		}
+		if errors.Is(err, errRemoteChanged) {
+			err = conflictf("cli: project %q changed in the storage backend while this command ran, so it was not deleted there (run the command again)", projectKey)
+		} else if err != nil {
+			err = fmt.Errorf("cli: failed to delete project %q from the storage backend: %w", projectKey, err)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// storeProject writes a project to the storage backend if it differs from the
+// version its local copy is at (copy, nil for a project not in the backend).
+func (b *backendRun) storeProject(ctx context.Context, projectKey string, synced *models.BackendCopy, errOut io.Writer) error {
+	data, hash, err := loadServedProject(ctx, projectKey, errOut)
+	if err != nil {
+		return err
+	}
+	if synced != nil && synced.Hash == hash {
+		return nil
+	}
+	body, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("cli: failed to marshal project: %w", err)
+	}
+	projectURL := b.url + "/" + projectKey
+	store, err := newRemoteStore(ctx, projectURL)
+	if err != nil {
+		return err
+	}
+	match := ""
+	if synced != nil {
+		match = synced.ETag
+	}
+	etag, err := store.Put(body, match)
+	if err == nil {
+		return writeBackendCopy(ctx, projectKey, &models.BackendCopy{
+			URL: projectURL, ETag: etag, Hash: hash, SyncedAt: storage.Timestamp(ctx),
+		})
+	}
+
+	// The local copy no longer matches a version of the backend: forget
+	// its version, so that the next command fetches the backend's
+	if copyPath, pathErr := storage.BackendCopyPath(ctx, projectKey); pathErr == nil {
+		os.Remove(copyPath)
+	}
+	if errors.Is(err, errRemoteChanged) {
+		if synced == nil {
+			return conflictf("cli: project %q already exists in the storage backend, so this command's changes to it were not saved (run the command again)", projectKey)
+		}
+		return conflictf("cli: project %q changed in the storage backend while this command ran, so this command's changes to it were not saved (run the command again)", projectKey)
+	}
+	return fmt.Errorf("cli: failed to save project %q to the storage backend, so this command's changes to it were not saved: %w", projectKey, err)
+}
+
+// backendSyncContext returns the context the local copy of the storage
+// backend is updated in: the command's storage without its write filter, so
+// that fetched projects are taken as they are (no history, hooks, or
+// read-only checks), and its writes aren't recorded as the command's.
+func backendSyncContext(ctx context.Context) context.Context {
+	env := storage.EnvFrom(ctx)
+	env.WriteFilter = nil
+	env.AfterWrite = nil
+	return withRunState(storage.WithEnv(ctx, env), &runState{nested: true, unlockOverride: true})
+}
+
+// removeBackendProject removes the local copy of a project deleted from the
+// storage backend.
+func removeBackendProject(ctx context.Context, projectKey string, errOut io.Writer) error {
+	deleteCmd := &cobra.Command{}
+	deleteCmd.Flags().Bool("yes", true, "")
+	deleteCmd.SetContext(ctx)
+	deleteCmd.SetOut(io.Discard)
+	deleteCmd.SetErr(errOut)
+	return deleteProject(projectKey, deleteCmd)
+}
+
+// loadBackendCopy loads the version of the storage backend a project's local
+// copy is at. Returns nil for a project the backend at backendURL doesn't
+// have a version of (a new project, or one renamed from another key).
+func loadBackendCopy(ctx context.Context, projectKey, backendURL string) (*models.BackendCopy, error) {
+	copyPath, err := storage.BackendCopyPath(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve backend copy path: %w", err)
+	}
+	var synced models.BackendCopy
+	if err := storage.ReadJSON(ctx, copyPath, &synced); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cli: failed to load backend copy: %w", err)
+	}
+	if synced.URL != backendURL+"/"+projectKey {
+		return nil, nil
+	}
+	return &synced, nil
+}
+
+// saveBackendCopy records that a project's local copy is at version etag of
+// the storage backend.
+func saveBackendCopy(ctx context.Context, projectKey, projectURL, etag string, errOut io.Writer) error {
+	_, hash, err := loadServedProject(ctx, projectKey, errOut)
+	if err != nil {
+		return err
+	}
+	return writeBackendCopy(ctx, projectKey, &models.BackendCopy{
+		URL: projectURL, ETag: etag, Hash: hash, SyncedAt: storage.Timestamp(ctx),
+	})
+}
+
+// writeBackendCopy writes the version of the storage backend a project's
+// local copy is at.
+func writeBackendCopy(ctx context.Context, projectKey string, synced *models.BackendCopy) error {
+	copyPath, err := storage.BackendCopyPath(ctx, projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve backend copy path: %w", err)
+	}
+	if err := storage.WriteJSONAtomic(ctx, copyPath, synced); err != nil {
+		return fmt.Errorf("cli: failed to save backend copy: %w", err)
+	}
+	return nil
+}
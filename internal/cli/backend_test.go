@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestStorageBackend(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	originalCfg, _ := config.Get()
+	defer func() {
+		if originalCfg != nil {
+			config.Save(t.Context(), originalCfg)
+		}
+	}()
+
+	run := func(args ...string) (string, string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(errOut)
+		err := cmd.ExecuteContext(t.Context())
+		return out.String(), errOut.String(), err
+	}
+	mustRun := func(args ...string) string {
+		t.Helper()
+		out, errOut, err := run(args...)
+		if err != nil {
+			t.Fatalf("%v failed: %v\n%s", args, err, errOut)
+		}
+		return out
+	}
+
+	objects, server := newObjectServer(t)
+	baseURL := strings.Replace(server.URL, "http://", "webdav+http://", 1) + "/dav/buyruk"
+	mustRun("config", "set", "storage_backend", baseURL)
+	dir, err := storage.BackendDir(baseURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filePath := "/dav/buyruk/" + projectKey + ".json"
+	backendObject := func() *ExportData {
+		t.Helper()
+		var data ExportData
+		if err := json.Unmarshal(objects.files[filePath], &data); err != nil {
+			t.Fatalf("Failed to parse %s: %v", filePath, err)
+		}
+		return &data
+	}
+
+	// Creating the project and the issue both write it to the backend
+	mustRun("project", "create", projectKey)
+	mustRun("issue", "create", "--project", projectKey, "--title", "Login page")
+	if data := backendObject(); len(data.Issues) != 1 || data.Issues[0].Title != "Login page" {
+		t.Fatalf("Expected the issue in the backend, got %+v", data.Issues)
+	}
+	var conditional int
+	for _, req := range objects.requests {
+		if req.Method == "PUT" && req.Header.Get("If-Match") != "" {
+			conditional++
+		}
+	}
+	if conditional == 0 {
+		t.Error("Expected the issue to be written with If-Match")
+	}
+
+	// Reading it again only revalidates it
+	before := len(objects.requests)
+	mustRun("list", "--project", projectKey)
+	for _, req := range objects.requests[before:] {
+		if req.Method == "PUT" {
+			t.Errorf("Expected a read not to write the project, got %s %s", req.Method, req.URL)
+		}
+	}
+
+	// --data-dir keeps projects in a directory instead
+	if out, _, err := run("--data-dir", t.TempDir(), "list", "--project", projectKey); err == nil {
+		t.Errorf("Expected the project not to be in another data directory, got %s", out)
+	}
+
+	// A change of another machine is fetched before the command runs
+	data := backendObject()
+	data.Issues[0].Title = "Login page (v2)"
+	content, _ := json.Marshal(data)
+	objects.set(filePath, content)
+	if out := mustRun("issue", "view", projectKey+"-1"); !strings.Contains(out, "Login page (v2)") {
+		t.Errorf("Expected the change from the backend, got %s", out)
+	}
+
+	// Another machine writes the project while the command runs
+	objects.beforeWrite = func(path string) {
+		objects.set(path, objects.files[path])
+		objects.beforeWrite = nil
+	}
+	_, _, err = run("issue", "create", "--project", projectKey, "--title", "Signup page")
+	if err == nil || ExitCode(err) != ExitConflict || !strings.Contains(err.Error(), "changed in the storage backend") {
+		t.Fatalf("Expected a conflict, got %v", err)
+	}
+	if data := backendObject(); len(data.Issues) != 1 {
+		t.Errorf("Expected the backend to keep its version, got %d issues", len(data.Issues))
+	}
+
+	// The next command fetches the backend's version again
+	if out := mustRun("list", "--project", projectKey, "--format", "json"); strings.Contains(out, "Signup page") {
+		t.Errorf("Expected the unsaved issue to be dropped, got %s", out)
+	}
+	mustRun("issue", "create", "--project", projectKey, "--title", "Signup page")
+	if data := backendObject(); len(data.Issues) != 2 {
+		t.Errorf("Expected the retried issue in the backend, got %d issues", len(data.Issues))
+	}
+
+	if _, _, err := run("serve"); ExitCode(err) != ExitInvalid {
+		t.Errorf("Expected serve to refuse the storage backend, got %v", err)
+	}
+
+	// A project another machine deleted is removed from the local copy
+	delete(objects.files, filePath)
+	mustRun("list", "--all-projects")
+	if _, err := os.Stat(filepath.Join(dir, "projects", projectKey)); !os.IsNotExist(err) {
+		t.Errorf("Expected the deleted project to be removed, got %v", err)
+	}
+
+	// A project deleted here is deleted from the backend
+	mustRun("project", "create", projectKey)
+	if _, ok := objects.files[filePath]; !ok {
+		t.Fatal("Expected the project to be created again")
+	}
+	mustRun("project", "delete", projectKey, "-y")
+	if _, ok := objects.files[filePath]; ok {
+		t.Error("Expected the project to be deleted from the backend")
+	}
+
+	// Offline, reads use the local copy and changes that can't be saved
+	// fail the command
+	mustRun("project", "create", projectKey)
+	mustRun("issue", "create", "--project", projectKey, "--title", "Login page")
+	server.Close()
+	out, errOut, err := run("list", "--project", projectKey)
+	if err != nil || !strings.Contains(out, "Login page") || !strings.Contains(errOut, "failed to reach the storage backend") {
+		t.Fatalf("Expected the local copy with a warning, got %v: %s%s", err, out, errOut)
+	}
+	if _, _, err := run("issue", "create", "--project", projectKey, "--title", "Signup page"); err == nil || !strings.Contains(err.Error(), "were not saved") {
+		t.Errorf("Expected the change to fail, got %v", err)
+	}
+}
+
+func TestListRemoteProjects_S3(t *testing.T) {
+	objects, server := newObjectServer(t)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_ENDPOINT_URL", server.URL)
+	objects.set("/team-bucket/buyruk/CORE.json", []byte(`{}`))
+	objects.set("/team-bucket/buyruk/WEB.json", []byte(`{}`))
+	objects.set("/team-bucket/other/API.json", []byte(`{}`))
+
+	keys, err := listRemoteProjects(t.Context(), "s3://team-bucket/buyruk")
+	if err != nil || strings.Join(keys, ",") != "CORE,WEB" {
+		t.Fatalf("listRemoteProjects = %v, %v; want CORE and WEB", keys, err)
+	}
+	if query := objects.requests[0].URL.Query(); query.Get("prefix") != "buyruk/" || query.Get("delimiter") != "/" {
+		t.Errorf("Unexpected list query %s", objects.requests[0].URL.RawQuery)
+	}
+	if len(objects.requests) != 2 || objects.requests[1].URL.Query().Get("continuation-token") == "" {
+		t.Errorf("Expected a second page to be requested, got %d requests", len(objects.requests))
+	}
+}
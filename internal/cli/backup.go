@@ -0,0 +1,794 @@
+package cli
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// backupVersion is the version of the archive format written by backup.
+const backupVersion = 1
+
+// backupManifestName is the archive entry describing a backup.
+const backupManifestName = "manifest.json"
+
+// BackupManifest describes a backup archive. Project files are stored under
+// projects/KEY/ and config files under config/.
+type BackupManifest struct {
+	Version   int      `json:"version"`
+	CreatedAt string   `json:"created_at"`
+	Projects  []string `json:"projects"`
+	Config    []string `json:"config,omitempty"` // Config files, relative to the config directory
+}
+
+// backupConfigFiles are the files and directories of the config directory
+// that backups include. Unlocked keys (sessions), caches, the usage log, and
+// the replica ID that identifies this machine stay behind.
+var backupConfigFiles = []string{"config.json", "views.json", "away.json", "notifications", "themes"}
+
+// Conflict handling of restore for projects and config files that exist
+// locally.
+const (
+	restoreSkipExisting = "skip-existing"
+	restoreOverwrite    = "overwrite"
+	restoreMerge        = "merge"
+)
+
+// NewBackupCmd creates and returns the backup command.
+func NewBackupCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Back up all projects and the configuration to an archive",
+		Long: `Bundle all projects (issues, epics, archived issues, workflows, and
+settings) and the configuration (config, templates, global saved views, away
+list, notification state, and themes) into a compressed tar archive, for moving to another
+machine or scheduled backups. Restore it with 'buyruk restore'.
+
+Each project is read under its lock, so a backup taken while other commands
+run is consistent per project. Unlocked keys, caches, and the usage log are
+not included.`,
+		Example: `  buyruk backup
+  buyruk backup --output backup.tar.gz
+  buyruk backup --projects CORE,WEB --no-config --output - | ssh host 'cat > backup.tar.gz'`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return backupAll(cmd)
+		},
+	}
+
+	cmd.Flags().StringP("output", "o", "", "Archive to write (default buyruk-backup-DATE.tar.gz, \"-\" for stdout)")
+	cmd.Flags().String("projects", "", "Only back up these projects (comma-separated keys)")
+	cmd.Flags().Bool("no-config", false, "Leave out the configuration")
+
+	return cmd
+}
+
+// NewRestoreCmd creates and returns the restore command.
+func NewRestoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore <file>",
+		Short: "Restore projects and the configuration from a backup archive",
+		Long: `Restore the projects and configuration of an archive written by
+'buyruk backup' ("-" reads stdin).
+
+Projects and config files that do not exist locally are restored. If some
+exist, restore stops before changing anything unless told how to handle
+them:
+
+  --skip-existing  keep local projects and config files as they are
+  --overwrite      replace them with the backed-up copy
+  --merge          add the issues, epics, and files missing locally and take
+                   backed-up issues and epics that were updated more recently;
+                   config settings and global views missing locally are
+                   added
+
+Each project is restored completely or not at all.`,
+		Example: `  buyruk restore backup.tar.gz
+  buyruk restore backup.tar.gz --merge --dry-run
+  buyruk restore backup.tar.gz --projects CORE --overwrite`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return restoreAll(args[0], cmd)
+		},
+	}
+
+	cmd.Flags().Bool(restoreSkipExisting, false, "Keep projects and config files that exist locally")
+	cmd.Flags().Bool(restoreOverwrite, false, "Replace projects and config files that exist locally")
+	cmd.Flags().Bool(restoreMerge, false, "Merge into projects and config files that exist locally")
+	cmd.MarkFlagsMutuallyExclusive(restoreSkipExisting, restoreOverwrite, restoreMerge)
+	cmd.Flags().String("projects", "", "Only restore these projects (comma-separated keys)")
+	cmd.Flags().Bool("no-config", false, "Leave the configuration as it is")
+	cmd.Flags().Bool("dry-run", false, "Show what would be restored without writing")
+
+	return cmd
+}
+
+// backupAll writes the backup archive.
+func backupAll(cmd *cobra.Command) error {
+	keys, err := backupProjectKeys(cmd)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	manifest := &BackupManifest{Version: backupVersion, CreatedAt: storage.Timestamp(cmd.Context()), Projects: []string{}}
+	files := map[string][]byte{}
+
+	for _, key := range keys {
+		if err := readBackupProject(cmd.Context(), key, files); err != nil {
+			return err
+		}
+		manifest.Projects = append(manifest.Projects, key)
+	}
+	if noConfig, _ := cmd.Flags().GetBool("no-config"); !noConfig {
+		configDir, err := storage.ConfigDir()
+		if err != nil {
+			return fmt.Errorf("cli: %w", err)
+		}
+		for _, name := range backupConfigFiles {
+			read, err := readBackupTree(filepath.Join(configDir, name), "config/"+name, files)
+			if err != nil {
+				return err
+			}
+			for _, file := range read {
+				manifest.Config = append(manifest.Config, strings.TrimPrefix(file, "config/"))
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cli: failed to marshal backup manifest: %w", err)
+	}
+	if err := writeTarFile(cmd.Context(), tw, backupManifestName, data); err != nil {
+		return err
+	}
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		if err := writeTarFile(cmd.Context(), tw, name, files[name]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("cli: failed to write backup: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("cli: failed to write backup: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	outputPath, _ := cmd.Flags().GetString("output")
+	if outputPath == "-" {
+		_, err := buf.WriteTo(out)
+		return err
+	}
+	if outputPath == "" {
+		outputPath = fmt.Sprintf("buyruk-backup-%s.tar.gz", storage.Now(cmd.Context()).In(config.Location()).Format("20060102-150405"))
+	}
+	size := int64(buf.Len())
+	if err := os.WriteFile(outputPath, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("cli: failed to write backup: %w", err)
+	}
+
+	fmt.Fprintf(successOut(cmd), "Backed up %d projects and %d config files to %s (%d files, %s)\n",
+		len(manifest.Projects), len(manifest.Config), outputPath, len(files), formatSize(size))
+	return nil
+}
+
+// backupProjectKeys returns the projects to back up: those of --projects, or
+// all projects.
+func backupProjectKeys(cmd *cobra.Command) ([]string, error) {
+	keys, err := storage.ListProjects(cmd.Context())
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to list projects: %w", err)
+	}
+	value, _ := cmd.Flags().GetString("projects")
+	if value == "" {
+		slices.Sort(keys)
+		return keys, nil
+	}
+	selected := splitList(value)
+	for _, key := range selected {
+		if !slices.Contains(keys, key) {
+			return nil, notFoundf("cli: project %q does not exist", key)
+		}
+	}
+	slices.Sort(selected)
+	return slices.Compact(selected), nil
+}
+
+// readBackupProject reads the files of a project under its lock.
+func readBackupProject(ctx context.Context, projectKey string, files map[string][]byte) error {
+	hasPending, _, err := storage.CheckPendingTransaction(ctx, projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to check pending transaction: %w", err)
+	}
+	if hasPending {
+		return fmt.Errorf("cli: project %q has a pending transaction (may indicate a crash); run 'buyruk project repair %s' first", projectKey, projectKey)
+	}
+	cleanup, err := storage.AcquireLock(ctx, projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to acquire project lock for %q: %w", projectKey, err)
+	}
+	defer cleanup()
+
+	projectDir, err := storage.ProjectDir(ctx, projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve project directory: %w", err)
+	}
+	_, err = readBackupTree(projectDir, "projects/"+projectKey, files)
+	return err
+}
+
+// readBackupTree reads the file or directory at root into files, under the
+// archive name prefix. It returns the archive names read; a missing root
+// reads nothing.
+func readBackupTree(root, prefix string, files map[string][]byte) ([]string, error) {
+	var names []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == root && errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return fmt.Errorf("cli: failed to read %s: %w", p, err)
+		}
+		if d.IsDir() {
+			// The repository of a project synced with git is not project data
+			if p != root && d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// Lock, lock statistics, transaction log, and leftovers of interrupted
+		// writes stay behind
+		name := d.Name()
+		if name == ".buyruk.lock" || name == ".buyruk_lockstats" || name == ".buyruk_pending" || strings.HasSuffix(name, ".tmp") {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve %s: %w", p, err)
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("cli: failed to read %s: %w", p, err)
+		}
+		archiveName := path.Join(prefix, filepath.ToSlash(rel))
+		files[archiveName] = data
+		names = append(names, archiveName)
+		return nil
+	})
+	return names, err
+}
+
+// writeTarFile adds a file to a tar archive.
+func writeTarFile(ctx context.Context, tw *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: storage.Now(ctx),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("cli: failed to write archive: %w", err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("cli: failed to write archive: %w", err)
+	}
+	return nil
+}
+
+// readBackup reads a backup archive: its manifest, and its files by project
+// key (with names relative to the project directory) and by config file.
+func readBackup(r io.Reader) (*BackupManifest, map[string]map[string][]byte, map[string][]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, nil, invalidf("cli: invalid backup archive: %w", err)
+	}
+	defer gz.Close()
+
+	var manifest *BackupManifest
+	projects := map[string]map[string][]byte{}
+	configFiles := map[string][]byte{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, invalidf("cli: invalid backup archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, nil, nil, invalidf("cli: invalid backup archive: %w", err)
+		}
+
+		name := header.Name
+		if name != path.Clean(name) || path.IsAbs(name) || strings.HasPrefix(name, "..") {
+			return nil, nil, nil, invalidf("cli: invalid backup archive: unsafe path %q", name)
+		}
+		if name == backupManifestName {
+			manifest = &BackupManifest{}
+			if err := json.Unmarshal(data, manifest); err != nil {
+				return nil, nil, nil, invalidf("cli: invalid backup manifest: %w", err)
+			}
+			continue
+		}
+		if rest, ok := strings.CutPrefix(name, "config/"); ok {
+			if !isBackupConfigFile(rest) {
+				return nil, nil, nil, invalidf("cli: invalid backup archive: unexpected config file %q", name)
+			}
+			configFiles[rest] = data
+			continue
+		}
+		rest, ok := strings.CutPrefix(name, "projects/")
+		key, file, found := strings.Cut(rest, "/")
+		if !ok || !found || !isValidProjectKey(key) {
+			return nil, nil, nil, invalidf("cli: invalid backup archive: unexpected file %q", name)
+		}
+		if projects[key] == nil {
+			projects[key] = map[string][]byte{}
+		}
+		projects[key][file] = data
+	}
+
+	if manifest == nil {
+		return nil, nil, nil, invalidf("cli: invalid backup archive: no %s (not written by 'buyruk backup'?)", backupManifestName)
+	}
+	if manifest.Version > backupVersion {
+		return nil, nil, nil, fmt.Errorf("cli: backup version %d is newer than supported (%d); upgrade buyruk", manifest.Version, backupVersion)
+	}
+	return manifest, projects, configFiles, nil
+}
+
+// isBackupConfigFile reports whether name, a path in the config directory, is
+// one of backupConfigFiles or in one of its directories, so a restore never
+// writes the files backups leave behind.
+func isBackupConfigFile(name string) bool {
+	for _, file := range backupConfigFiles {
+		if name == file || strings.HasPrefix(name, file+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// restoreAll restores a backup archive.
+func restoreAll(archivePath string, cmd *cobra.Command) error {
+	var data []byte
+	var err error
+	if archivePath == "-" {
+		data, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		data, err = os.ReadFile(archivePath)
+	}
+	if err != nil {
+		return fmt.Errorf("cli: failed to read backup: %w", err)
+	}
+	manifest, projects, configFiles, err := readBackup(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	mode := ""
+	for _, name := range []string{restoreSkipExisting, restoreOverwrite, restoreMerge} {
+		if set, _ := cmd.Flags().GetBool(name); set {
+			mode = name
+		}
+	}
+	keys := manifest.Projects
+	if value, _ := cmd.Flags().GetString("projects"); value != "" {
+		keys = splitList(value)
+		for _, key := range keys {
+			if !slices.Contains(manifest.Projects, key) {
+				return notFoundf("cli: project %q is not in the backup", key)
+			}
+		}
+	}
+	if noConfig, _ := cmd.Flags().GetBool("no-config"); noConfig {
+		configFiles = nil
+	}
+	configDir, err := storage.ConfigDir()
+	if err != nil {
+		return fmt.Errorf("cli: %w", err)
+	}
+
+	// Find conflicts before changing anything
+	var conflicts []string
+	existing := map[string]bool{}
+	for _, key := range keys {
+		projectDir, err := storage.ProjectDir(cmd.Context(), key)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve project directory: %w", err)
+		}
+		if _, err := os.Stat(projectDir); err == nil {
+			existing[key] = true
+			conflicts = append(conflicts, "project "+key)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(configFiles)) {
+		local, err := os.ReadFile(filepath.Join(configDir, filepath.FromSlash(name)))
+		if err == nil && !bytes.Equal(local, configFiles[name]) {
+			existing["config/"+name] = true
+			conflicts = append(conflicts, name)
+		}
+	}
+	if len(conflicts) > 0 && mode == "" {
+		return conflictf("cli: already exists locally: %s (use --skip-existing, --overwrite, or --merge)", strings.Join(conflicts, ", "))
+	}
+
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	out := successOut(cmd)
+	if dryRun {
+		out = cmd.OutOrStdout() // A dry run reports what would change
+	}
+	restored := 0
+	for _, key := range keys {
+		files := projects[key]
+		switch {
+		case !existing[key]:
+			if !dryRun {
+				if err := restoreProject(cmd.Context(), key, files, false); err != nil {
+					return err
+				}
+			}
+			fmt.Fprintf(out, "%s project %s (%d files)\n", restoreVerb(dryRun, "restore"), key, len(files))
+			restored++
+		case mode == restoreSkipExisting:
+			fmt.Fprintf(out, "Skipped project %s (exists locally)\n", key)
+		case mode == restoreOverwrite:
+			if !dryRun {
+				if err := restoreProject(cmd.Context(), key, files, true); err != nil {
+					return err
+				}
+			}
+			fmt.Fprintf(out, "%s project %s (%d files)\n", restoreVerb(dryRun, "replace"), key, len(files))
+			restored++
+		case mode == restoreMerge:
+			result, err := mergeProject(cmd.Context(), key, files, dryRun)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "%s project %s: %d issues added, %d updated; %d epics added, %d updated; %d other files added\n",
+				restoreVerb(dryRun, "merge"), key, result.issuesAdded, result.issuesUpdated, result.epicsAdded, result.epicsUpdated, result.filesAdded)
+			if len(result.idConflicts) > 0 {
+				fmt.Fprintf(out, "  Kept local %s: the backup has a different issue with the same ID\n", strings.Join(result.idConflicts, ", "))
+			}
+			restored++
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(configFiles)) {
+		target := filepath.Join(configDir, filepath.FromSlash(name))
+		data := configFiles[name]
+		if !existing["config/"+name] {
+			if _, err := os.Stat(target); err == nil {
+				continue // Same as the local copy
+			}
+		} else {
+			switch mode {
+			case restoreSkipExisting:
+				fmt.Fprintf(out, "Skipped %s (exists locally)\n", name)
+				continue
+			case restoreMerge:
+				local, _ := os.ReadFile(target)
+				switch name {
+				case "config.json":
+					data, err = mergeConfigJSON(local, data)
+				case "views.json":
+					data, err = mergeViewsJSON(local, data)
+				default:
+					fmt.Fprintf(out, "Kept the local %s\n", name)
+					continue
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+		if !dryRun {
+			if err := storage.WriteAtomic(cmd.Context(), target, data); err != nil {
+				return fmt.Errorf("cli: failed to restore %s: %w", name, err)
+			}
+		}
+		fmt.Fprintf(out, "%s %s\n", restoreVerb(dryRun, "restore"), name)
+	}
+
+	fmt.Fprintf(out, "%s %d of %d projects from a backup of %s\n", restoreVerb(dryRun, "restore"), restored, len(keys), manifest.CreatedAt)
+	return nil
+}
+
+// restoreVerb returns the past tense of verb ("Restored"), or "Would restore"
+// in a dry run.
+func restoreVerb(dryRun bool, verb string) string {
+	if dryRun {
+		return "Would " + verb
+	}
+	return strings.ToUpper(verb[:1]) + verb[1:] + "d"
+}
+
+// restoreProject writes a backed-up project next to the project directory
+// and moves it into place, replacing the local project if replace is set.
+func restoreProject(ctx context.Context, projectKey string, files map[string][]byte, replace bool) error {
+	projectDir, err := storage.ProjectDir(ctx, projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve project directory: %w", err)
+	}
+	projectsDir := filepath.Dir(projectDir)
+	stageDir := filepath.Join(projectsDir, "."+projectKey+".restoring")
+	replacedDir := filepath.Join(projectsDir, "."+projectKey+".replaced")
+
+	if replace {
+		if err := checkProjectWritable(ctx, projectKey); err != nil {
+			return err
+		}
+		hasPending, _, err := storage.CheckPendingTransaction(ctx, projectKey)
+		if err != nil {
+			return fmt.Errorf("cli: failed to check pending transaction: %w", err)
+		}
+		if hasPending {
+			return fmt.Errorf("cli: project %q has a pending transaction (may indicate a crash); run 'buyruk project repair %s' first", projectKey, projectKey)
+		}
+		cleanup, err := storage.AcquireLock(ctx, projectKey)
+		if err != nil {
+			return fmt.Errorf("cli: failed to acquire project lock for %q: %w", projectKey, err)
+		}
+		// Once swapped, the lock file leaves with the old directory
+		swapped := false
+		defer func() {
+			if !swapped {
+				cleanup()
+			}
+		}()
+		if err := stageBackupProject(stageDir, files); err != nil {
+			return err
+		}
+		if err := os.Rename(projectDir, replacedDir); err != nil {
+			os.RemoveAll(stageDir)
+			return fmt.Errorf("cli: failed to move project %q aside: %w", projectKey, err)
+		}
+		if err := os.Rename(stageDir, projectDir); err != nil {
+			os.Rename(replacedDir, projectDir)
+			os.RemoveAll(stageDir)
+			return fmt.Errorf("cli: failed to restore project %q: %w", projectKey, err)
+		}
+		swapped = true
+		os.RemoveAll(replacedDir)
+		return nil
+	}
+
+	if err := stageBackupProject(stageDir, files); err != nil {
+		return err
+	}
+	if err := os.Rename(stageDir, projectDir); err != nil {
+		os.RemoveAll(stageDir)
+		return fmt.Errorf("cli: failed to restore project %q: %w", projectKey, err)
+	}
+	return nil
+}
+
+// stageBackupProject writes the files of a backed-up project to stageDir.
+func stageBackupProject(stageDir string, files map[string][]byte) error {
+	os.RemoveAll(stageDir)
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		target := filepath.Join(stageDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			os.RemoveAll(stageDir)
+			return fmt.Errorf("cli: failed to create %s: %w", filepath.Dir(target), err)
+		}
+		if err := os.WriteFile(target, files[name], 0644); err != nil {
+			os.RemoveAll(stageDir)
+			return fmt.Errorf("cli: failed to write %s: %w", target, err)
+		}
+	}
+	return nil
+}
+
+// projectMerge counts what merging a backed-up project changed.
+type projectMerge struct {
+	issuesAdded, issuesUpdated int
+	epicsAdded, epicsUpdated   int
+	filesAdded                 int
+	idConflicts                []string // Issues kept locally as the backup has a different issue with their ID
+}
+
+// mergeProject merges a backed-up project into the local one: issues and
+// epics missing locally are added, ones updated more recently in the backup
+// replace the local copy, and other files are only added. The local index is
+// updated to match, all under the project lock. Read-only projects are
+// refused, in a dry run too.
+func mergeProject(ctx context.Context, projectKey string, files map[string][]byte, dryRun bool) (*projectMerge, error) {
+	if err := checkProjectWritable(ctx, projectKey); err != nil {
+		return nil, err
+	}
+	cleanup, err := storage.AcquireLock(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to acquire project lock for %q: %w", projectKey, err)
+	}
+	defer cleanup()
+
+	projectDir, err := storage.ProjectDir(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve project directory: %w", err)
+	}
+	indexPath, err := storage.ProjectIndexPath(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(ctx, indexPath, &index); err != nil {
+		return nil, fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+
+	result := &projectMerge{}
+	var writes []fileWrite
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		if name == "project.json" {
+			continue
+		}
+		data := files[name]
+		target := filepath.Join(projectDir, filepath.FromSlash(name))
+		local, err := os.ReadFile(target)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("cli: failed to read %s: %w", target, err)
+		}
+		exists := err == nil
+
+		switch dir := path.Dir(name); {
+		case dir == "issues" && strings.HasSuffix(name, ".json"):
+			var backedUp, current models.Issue
+			if err := json.Unmarshal(data, &backedUp); err != nil {
+				return nil, invalidf("cli: invalid issue %s in backup: %w", name, err)
+			}
+			if exists {
+				if err := json.Unmarshal(local, &current); err != nil {
+					continue
+				}
+				if !sameIssue(&backedUp, &current) {
+					result.idConflicts = append(result.idConflicts, backedUp.ID)
+					continue
+				}
+				if !newerRecord(backedUp.UpdatedAt, current.UpdatedAt) {
+					continue
+				}
+				result.issuesUpdated++
+			} else {
+				result.issuesAdded++
+			}
+			index.AddIssue(&backedUp)
+		case dir == "epics" && strings.HasSuffix(name, ".json"):
+			var backedUp, current models.Epic
+			if err := json.Unmarshal(data, &backedUp); err != nil {
+				return nil, invalidf("cli: invalid epic %s in backup: %w", name, err)
+			}
+			if exists {
+				if err := json.Unmarshal(local, &current); err != nil || !newerRecord(backedUp.UpdatedAt, current.UpdatedAt) {
+					continue
+				}
+				result.epicsUpdated++
+			} else {
+				result.epicsAdded++
+			}
+			index.SetEpic(&backedUp)
+		default:
+			if exists {
+				continue
+			}
+			result.filesAdded++
+		}
+		writes = append(writes, fileWrite{path: target, data: data, original: local})
+	}
+	if len(writes) == 0 || dryRun {
+		return result, nil
+	}
+
+	index.RecountEpics()
+	index.UpdatedAt = storage.Timestamp(ctx)
+	write, err := plannedIndexWrite(indexPath, &index)
+	if err != nil {
+		return nil, err
+	}
+	writes = append(writes, write)
+
+	if err := storage.BeginTransaction(ctx, projectKey, "restore_merge", map[string]interface{}{
+		"files": len(writes),
+	}); err != nil {
+		return nil, fmt.Errorf("cli: failed to begin transaction: %w", err)
+	}
+	if err := writeFiles(ctx, writes); err != nil {
+		storage.RollbackTransaction(ctx, projectKey)
+		return nil, err
+	}
+	if err := storage.CommitTransaction(ctx, projectKey); err != nil {
+		return nil, fmt.Errorf("cli: failed to commit transaction: %w", err)
+	}
+	return result, nil
+}
+
+// sameIssue reports whether a backed-up issue and the local one with its ID are
+// the same issue: by UID when both have one, and otherwise by creation time,
+// as issues created separately in the backup and here rarely share it.
+func sameIssue(backedUp, local *models.Issue) bool {
+	if backedUp.UID != "" && local.UID != "" {
+		return backedUp.UID == local.UID
+	}
+	return backedUp.CreatedAt == "" || local.CreatedAt == "" || backedUp.CreatedAt == local.CreatedAt
+}
+
+// newerRecord reports whether the backed-up updated_at time is later than the
+// local one.
+func newerRecord(backedUp, local string) bool {
+	b, err := time.Parse(time.RFC3339, backedUp)
+	if err != nil {
+		return false
+	}
+	l, err := time.Parse(time.RFC3339, local)
+	return err != nil || b.After(l)
+}
+
+// mergeConfigJSON adds the settings of a backed-up config.json that are not
+// set in the local one.
+func mergeConfigJSON(local, backedUp []byte) ([]byte, error) {
+	var merged, incoming map[string]json.RawMessage
+	if err := json.Unmarshal(local, &merged); err != nil || merged == nil {
+		merged = map[string]json.RawMessage{}
+	}
+	if err := json.Unmarshal(backedUp, &incoming); err != nil {
+		return nil, invalidf("cli: invalid config.json in backup: %w", err)
+	}
+	for key, value := range incoming {
+		if _, ok := merged[key]; !ok {
+			merged[key] = value
+		}
+	}
+	data, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to marshal config: %w", err)
+	}
+	return data, nil
+}
+
+// mergeViewsJSON adds the saved views of a backed-up views.json that are not
+// in the local one.
+func mergeViewsJSON(local, backedUp []byte) ([]byte, error) {
+	var merged, incoming models.SavedViews
+	if err := json.Unmarshal(local, &merged); err != nil {
+		merged = models.SavedViews{}
+	}
+	if err := json.Unmarshal(backedUp, &incoming); err != nil {
+		return nil, invalidf("cli: invalid views.json in backup: %w", err)
+	}
+	for _, view := range incoming.Views {
+		if merged.Find(view.Name) == nil {
+			merged.Set(view)
+		}
+	}
+	data, err := json.MarshalIndent(&merged, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to marshal views: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,313 @@
+package cli
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestBackupRestore(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(append(args, "--no-autocommit", "--no-hooks"))
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	if _, err := run("project", "create", projectKey); err != nil {
+		t.Fatalf("project create failed: %v", err)
+	}
+	for _, title := range []string{"First", "Second"} {
+		if _, err := run("issue", "create", "--title", title, "--project", projectKey); err != nil {
+			t.Fatalf("issue create failed: %v", err)
+		}
+	}
+
+	archivePath := filepath.Join(t.TempDir(), "backup.tar.gz")
+	out, err := run("backup", "--projects", projectKey, "--no-config", "--output", archivePath)
+	if err != nil || !strings.Contains(out, "Backed up 1 projects and 0 config files to "+archivePath) {
+		t.Fatalf("backup failed: %v\n%s", err, out)
+	}
+	if _, err := run("backup", "--projects", "NOPE", "--output", archivePath+".2"); err == nil {
+		t.Error("backup of a missing project succeeded")
+	}
+
+	// Existing projects are conflicts unless a mode is given
+	if _, err := run("restore", archivePath, "--no-config"); err == nil || !strings.Contains(err.Error(), "project "+projectKey) {
+		t.Fatalf("Expected a conflict error, got %v", err)
+	}
+	out, err = run("restore", archivePath, "--no-config", "--skip-existing")
+	if err != nil || !strings.Contains(out, "Skipped project "+projectKey) {
+		t.Fatalf("restore --skip-existing failed: %v\n%s", err, out)
+	}
+
+	// Merge brings back deleted issues and keeps local additions
+	if _, err := run("issue", "delete", projectKey+"-1", "--project", projectKey, "--yes"); err != nil {
+		t.Fatalf("issue delete failed: %v", err)
+	}
+	if _, err := run("issue", "create", "--title", "Local only", "--project", projectKey); err != nil {
+		t.Fatalf("issue create failed: %v", err)
+	}
+	out, err = run("restore", archivePath, "--no-config", "--merge", "--dry-run")
+	if err != nil || !strings.Contains(out, "Would merge project "+projectKey+": 1 issues added, 0 updated") {
+		t.Fatalf("restore --merge --dry-run failed: %v\n%s", err, out)
+	}
+	if issue, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-1"); issue != nil {
+		t.Fatal("Dry run restored an issue")
+	}
+	if _, err := run("restore", archivePath, "--no-config", "--merge"); err != nil {
+		t.Fatalf("restore --merge failed: %v", err)
+	}
+	index, _ := loadQueryIndex(t.Context(), projectKey)
+	if first, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-1"); first == nil || first.Title != "First" || index.FindIssue(projectKey+"-1") == nil {
+		t.Fatalf("Expected the deleted issue back, got %+v", first)
+	}
+	if local, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-3"); local == nil {
+		t.Fatal("Merge removed a local issue")
+	}
+
+	// Overwrite replaces the project with the backed-up copy
+	out, err = run("restore", archivePath, "--no-config", "--overwrite")
+	if err != nil || !strings.Contains(out, "Replaced project "+projectKey) {
+		t.Fatalf("restore --overwrite failed: %v\n%s", err, out)
+	}
+	if local, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-3"); local != nil {
+		t.Error("Overwrite kept a local-only issue")
+	}
+	projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+	if _, err := os.Stat(filepath.Join(filepath.Dir(projectDir), "."+projectKey+".replaced")); !os.IsNotExist(err) {
+		t.Error("Expected the replaced project to be removed")
+	}
+
+	// A missing project is restored as a whole
+	os.RemoveAll(projectDir)
+	out, err = run("restore", archivePath, "--no-config")
+	if err != nil || !strings.Contains(out, "Restored project "+projectKey) {
+		t.Fatalf("restore failed: %v\n%s", err, out)
+	}
+	if index, err := loadQueryIndex(t.Context(), projectKey); err != nil || len(index.Issues) != 2 {
+		t.Fatalf("Expected 2 issues after restore, got %+v (%v)", index, err)
+	}
+}
+
+func TestBackupRestore_MergeReadOnly(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(append(args, "--no-autocommit", "--no-hooks"))
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	archivePath := filepath.Join(t.TempDir(), "backup.tar.gz")
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--title", "First", "--project", projectKey},
+		{"backup", "--projects", projectKey, "--no-config", "--output", archivePath},
+		{"issue", "delete", projectKey + "-1", "--project", projectKey, "--yes"},
+		{"project", "lock", projectKey},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	// A read-only project is refused before anything is merged, even in a
+	// dry run
+	for _, args := range [][]string{{"--merge", "--dry-run"}, {"--merge"}} {
+		if _, err := run(append([]string{"restore", archivePath, "--no-config"}, args...)...); !errors.Is(err, ErrConflict) || !strings.Contains(err.Error(), "read-only") {
+			t.Fatalf("Expected restore %v into a read-only project to conflict, got %v", args, err)
+		}
+	}
+	if issue, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-1"); issue != nil {
+		t.Error("Merge restored an issue into a read-only project")
+	}
+	if _, err := run("restore", archivePath, "--no-config", "--merge", "--unlock-override"); err != nil {
+		t.Fatalf("restore --merge --unlock-override failed: %v", err)
+	}
+	if issue, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-1"); issue == nil {
+		t.Error("Expected --unlock-override to merge the issue back")
+	}
+}
+
+func TestBackupRestore_MergeIDConflict(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(append(args, "--no-autocommit", "--no-hooks"))
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	archivePath := filepath.Join(t.TempDir(), "backup.tar.gz")
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--title", "First", "--project", projectKey},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	// Lock statistics are not project data
+	statsPath, _ := storage.LockStatsPath(t.Context(), projectKey)
+	if err := os.WriteFile(statsPath, []byte("{}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run("backup", "--projects", projectKey, "--no-config", "--output", archivePath); err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+	archive, err := os.Open(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, projects, _, err := readBackup(archive)
+	archive.Close()
+	if err != nil {
+		t.Fatalf("readBackup failed: %v", err)
+	}
+	if _, ok := projects[projectKey][".buyruk_lockstats"]; ok {
+		t.Error("Expected the lock statistics to be left out of the backup")
+	}
+
+	// A different issue, created here with the same ID and no UID, is older
+	// but kept: it isn't the backed-up issue
+	issuePath, _ := storage.IssuePath(t.Context(), projectKey, projectKey+"-1")
+	local := models.Issue{
+		ID:        projectKey + "-1",
+		Title:     "Local",
+		Status:    models.StatusTODO,
+		Type:      models.TypeTask,
+		CreatedAt: "2000-01-01T00:00:00Z",
+		UpdatedAt: "2000-01-01T00:00:00Z",
+	}
+	data, _ := json.MarshalIndent(&local, "", "  ")
+	if err := storage.WriteAtomic(t.Context(), issuePath, data); err != nil {
+		t.Fatal(err)
+	}
+	out, err := run("restore", archivePath, "--no-config", "--merge")
+	if err != nil || !strings.Contains(out, "Kept local "+projectKey+"-1") {
+		t.Fatalf("Expected an ID conflict, got %v\n%s", err, out)
+	}
+	if issue, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-1"); issue == nil || issue.Title != "Local" {
+		t.Errorf("Expected the local issue to be kept, got %+v", issue)
+	}
+}
+
+func TestReadBackupRejectsUnsafePaths(t *testing.T) {
+	var buf bytes.Buffer
+	gz := newTestBackup(t, &buf, map[string]string{
+		backupManifestName:           `{"version": 1, "projects": ["CORE"]}`,
+		"projects/CORE/../../evil":   "x",
+		"projects/CORE/project.json": "{}",
+	})
+	if _, _, _, err := readBackup(gz); err == nil || !strings.Contains(err.Error(), "unsafe path") {
+		t.Errorf("Expected an unsafe path error, got %v", err)
+	}
+
+	buf.Reset()
+	gz = newTestBackup(t, &buf, map[string]string{backupManifestName: `{"version": 99}`})
+	if _, _, _, err := readBackup(gz); err == nil || !strings.Contains(err.Error(), "newer than supported") {
+		t.Errorf("Expected a version error, got %v", err)
+	}
+}
+
+func TestReadBackupRejectsUnlistedConfigFiles(t *testing.T) {
+	var buf bytes.Buffer
+	gz := newTestBackup(t, &buf, map[string]string{
+		backupManifestName:             `{"version": 1}`,
+		"config/config.json":           "{}",
+		"config/themes/dark.json":      "{}",
+		"config/unlocked/session.json": "{}",
+	})
+	if _, _, _, err := readBackup(gz); err == nil || !strings.Contains(err.Error(), `unexpected config file "config/unlocked/session.json"`) {
+		t.Errorf("Expected a config file backups leave behind to be rejected, got %v", err)
+	}
+
+	buf.Reset()
+	gz = newTestBackup(t, &buf, map[string]string{
+		backupManifestName:        `{"version": 1}`,
+		"config/config.json":      "{}",
+		"config/views.json":       "{}",
+		"config/themes/dark.json": "{}",
+	})
+	_, _, configFiles, err := readBackup(gz)
+	if err != nil {
+		t.Fatalf("readBackup failed: %v", err)
+	}
+	if len(configFiles) != 3 || configFiles["views.json"] == nil || configFiles["themes/dark.json"] == nil {
+		t.Errorf("Expected config.json, the global views, and the theme, got %v", configFiles)
+	}
+}
+
+// newTestBackup writes a backup archive with the given files.
+func newTestBackup(t *testing.T, buf *bytes.Buffer, files map[string]string) *bytes.Buffer {
+	t.Helper()
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	for name, data := range files {
+		if err := writeTarFile(t.Context(), tw, name, []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tw.Close()
+	gz.Close()
+	return buf
+}
+
+func TestMergeConfigJSON(t *testing.T) {
+	merged, err := mergeConfigJSON([]byte(`{"default_format": "json"}`), []byte(`{"default_format": "lson", "timezone": "UTC"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]string
+	if err := json.Unmarshal(merged, &got); err != nil || got["default_format"] != "json" || got["timezone"] != "UTC" {
+		t.Errorf("Unexpected merged config: %s (%v)", merged, err)
+	}
+}
+
+func TestMergeViewsJSON(t *testing.T) {
+	merged, err := mergeViewsJSON([]byte(`{"views": [{"name": "mine", "query": "assignee=me"}]}`),
+		[]byte(`{"views": [{"name": "mine", "query": "all"}, {"name": "bugs", "query": "type=bug"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got models.SavedViews
+	if err := json.Unmarshal(merged, &got); err != nil || len(got.Views) != 2 || got.Find("mine").Query != "assignee=me" || got.Find("bugs") == nil {
+		t.Errorf("Unexpected merged views: %s (%v)", merged, err)
+	}
+}
@@ -0,0 +1,311 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// BlockersReport lists dependency problems in a project: issues that were
+// started while a blocker has not been, and issues blocked for too long.
+type BlockersReport struct {
+	Project   string         `json:"project"`
+	OlderThan string         `json:"older_than"`
+	Started   []BlockerAlert `json:"started_but_blocked"`
+	Stale     []BlockerAlert `json:"blocked_too_long"`
+}
+
+// BlockerAlert is an issue flagged by the blockers report.
+type BlockerAlert struct {
+	ID           string        `json:"id"`
+	Title        string        `json:"title"`
+	Status       string        `json:"status"`
+	Assignee     string        `json:"assignee,omitempty"`
+	BlockedSince string        `json:"blocked_since,omitempty"`
+	BlockedFor   string        `json:"blocked_for,omitempty"`
+	Blockers     []BlockerInfo `json:"blockers"`
+}
+
+// BlockerInfo is an open dependency of a flagged issue.
+type BlockerInfo struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+}
+
+// NewBlockersCmd creates and returns the blockers command.
+func NewBlockersCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "blockers",
+		Short: "Inspect issue dependencies",
+		Long:  "Commands for finding problems with issue dependencies.",
+	}
+
+	cmd.AddCommand(NewBlockersReportCmd())
+
+	return cmd
+}
+
+// NewBlockersReportCmd creates and returns the blockers report command.
+func NewBlockersReportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Report started-but-blocked and long-blocked issues",
+		Long: `Report dependency problems in a project, to catch planning mistakes early.
+
+Two kinds of issues are listed:
+  - Started but blocked: issues in progress (neither in the workflow's first
+    status nor done) with a blocker that has not been started yet, so the
+    work cannot be finished.
+  - Blocked too long: open issues with an open blocker that have been blocked
+    for longer than --older-than (7 days by default).
+
+How long an issue has been blocked is counted from when it got its first
+dependency (or from its creation, for dependencies added by older versions).`,
+		Example: `  buyruk blockers report
+  buyruk blockers report --project CORE --older-than 2w --format json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return reportBlockers(cmd)
+		},
+	}
+
+	cmd.Flags().String("older-than", "7d", "Report issues blocked for longer than this (e.g. 3d, 2w)")
+
+	return cmd
+}
+
+// reportBlockers builds and prints the blockers report of a project.
+func reportBlockers(cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	threshold, _, err := getDurationFlag(cmd, "older-than")
+	if err != nil {
+		return err
+	}
+	olderThan, _ := cmd.Flags().GetString("older-than")
+
+	index, err := loadQueryIndex(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	wf, err := loadWorkflow(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+
+	report := BlockersReport{
+		Project:   projectKey,
+		OlderThan: olderThan,
+		Started:   []BlockerAlert{},
+		Stale:     []BlockerAlert{},
+	}
+	lookup := newBlockerLookup(projectKey, wf)
+	now := storage.Now(cmd.Context())
+	errOut := cmd.ErrOrStderr()
+	for _, entry := range index.Issues {
+		if wf.IsDoneStatus(entry.Status) {
+			continue
+		}
+		issue, err := loadLocalIssue(cmd.Context(), projectKey, entry.ID)
+		if err != nil || issue == nil {
+			fmt.Fprintf(errOut, "Warning: failed to load issue %s\n", entry.ID)
+			continue
+		}
+		if len(issue.BlockedBy) == 0 {
+			continue
+		}
+
+		var open []BlockerInfo
+		notStarted := false
+		for _, id := range issue.BlockedBy {
+			blocker, ok := lookup.find(cmd.Context(), id)
+			if !ok || blocker.done {
+				continue
+			}
+			open = append(open, blocker.BlockerInfo)
+			notStarted = notStarted || blocker.notStarted
+		}
+		if len(open) == 0 {
+			continue
+		}
+
+		alert := BlockerAlert{
+			ID:           issue.ID,
+			Title:        issue.Title,
+			Status:       issue.Status,
+			Assignee:     issue.Assignee,
+			BlockedSince: issue.BlockedSince,
+			Blockers:     open,
+		}
+		if alert.BlockedSince == "" {
+			alert.BlockedSince = issue.CreatedAt
+		}
+		var blockedFor time.Duration
+		if since, err := time.Parse(time.RFC3339, alert.BlockedSince); err == nil {
+			blockedFor = now.Sub(since)
+			alert.BlockedFor = formatAge(blockedFor)
+		}
+
+		if notStarted && issue.Status != wf.DefaultStatus() {
+			report.Started = append(report.Started, alert)
+		}
+		if alert.BlockedFor != "" && blockedFor > threshold {
+			report.Stale = append(report.Stale, alert)
+		}
+	}
+
+	return writeBlockersReport(cmd, &report)
+}
+
+// blockerState is a blocker as seen from the reported project.
+type blockerState struct {
+	BlockerInfo
+	done       bool // In a done status of its workflow
+	notStarted bool // Still in the first status of its workflow
+}
+
+// blockerLookup finds blockers by ID, loading issues and workflows of other
+// projects as needed and caching what it loads.
+type blockerLookup struct {
+	workflows map[string]*models.Workflow
+	blockers  map[string]blockerState
+}
+
+func newBlockerLookup(projectKey string, wf *models.Workflow) *blockerLookup {
+	return &blockerLookup{
+		workflows: map[string]*models.Workflow{projectKey: wf},
+		blockers:  map[string]blockerState{},
+	}
+}
+
+// find returns the state of a blocker, or false if it cannot be loaded.
+func (l *blockerLookup) find(ctx context.Context, id string) (blockerState, bool) {
+	if state, ok := l.blockers[id]; ok {
+		return state, true
+	}
+	projectKey, _, err := parseIssueID(ctx, id)
+	if err != nil {
+		return blockerState{}, false
+	}
+	wf, ok := l.workflows[projectKey]
+	if !ok {
+		if wf, err = loadWorkflow(ctx, projectKey); err != nil {
+			return blockerState{}, false
+		}
+		l.workflows[projectKey] = wf
+	}
+	issue, err := loadLocalIssue(ctx, projectKey, id)
+	if err != nil || issue == nil {
+		return blockerState{}, false
+	}
+	state := blockerState{
+		BlockerInfo: BlockerInfo{ID: issue.ID, Title: issue.Title, Status: issue.Status},
+		done:        wf.IsDoneStatus(issue.Status),
+		notStarted:  issue.Status == wf.DefaultStatus(),
+	}
+	l.blockers[id] = state
+	return state, true
+}
+
+// formatAge formats a duration in days, or hours when it is less than a day.
+func formatAge(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	if d < 24*time.Hour {
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	}
+	return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+}
+
+// writeBlockersReport prints the report in the resolved output format.
+func writeBlockersReport(cmd *cobra.Command, report *BlockersReport) error {
+	out := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("cli: failed to marshal blockers report: %w", err)
+		}
+		fmt.Fprintln(out, string(data))
+		return nil
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(out, report)
+	case config.DefaultFormatLSON:
+		writeBlockerAlertsLSON(out, "STARTED_BUT_BLOCKED", report.Started)
+		writeBlockerAlertsLSON(out, "BLOCKED_TOO_LONG", report.Stale)
+		return nil
+	}
+
+	if len(report.Started) == 0 && len(report.Stale) == 0 {
+		fmt.Fprintf(out, "No blocker problems in project %q\n", report.Project)
+		return nil
+	}
+	styles := ui.NewStyles()
+	sections := []struct {
+		heading string
+		alerts  []BlockerAlert
+	}{
+		{"Started but blocked", report.Started},
+		{"Blocked longer than " + report.OlderThan, report.Stale},
+	}
+	first := true
+	for _, section := range sections {
+		if len(section.alerts) == 0 {
+			continue
+		}
+		if !first {
+			fmt.Fprintln(out)
+		}
+		first = false
+		fmt.Fprintf(out, "%s (%d)\n", styles.Title(section.heading), len(section.alerts))
+		for _, alert := range section.alerts {
+			details := []string{alert.Status}
+			if alert.Assignee != "" {
+				details = append(details, "@"+alert.Assignee)
+			}
+			if alert.BlockedFor != "" {
+				details = append(details, "blocked "+alert.BlockedFor)
+			}
+			fmt.Fprintf(out, "  %s %s [%s]\n", styles.ID(alert.ID), alert.Title, strings.Join(details, ", "))
+			for _, blocker := range alert.Blockers {
+				fmt.Fprintf(out, "    blocked by %s %s [%s]\n", styles.ID(blocker.ID), blocker.Title, blocker.Status)
+			}
+		}
+	}
+	return nil
+}
+
+// writeBlockerAlertsLSON prints alerts as LSON blocks tagged with their kind.
+func writeBlockerAlertsLSON(out io.Writer, kind string, alerts []BlockerAlert) {
+	for _, alert := range alerts {
+		fmt.Fprintf(out, "@ALERT: %s\n", kind)
+		fmt.Fprintf(out, "@ID: %s\n", alert.ID)
+		fmt.Fprintf(out, "@TITLE: %s\n", alert.Title)
+		fmt.Fprintf(out, "@STATUS: %s\n", alert.Status)
+		if alert.Assignee != "" {
+			fmt.Fprintf(out, "@ASSIGNEE: %s\n", alert.Assignee)
+		}
+		if alert.BlockedFor != "" {
+			fmt.Fprintf(out, "@BLOCKED_FOR: %s\n", alert.BlockedFor)
+		}
+		ids := make([]string, len(alert.Blockers))
+		for i, blocker := range alert.Blockers {
+			ids[i] = blocker.ID
+		}
+		fmt.Fprintf(out, "@BLOCKED_BY: %s\n", strings.Join(ids, ", "))
+		fmt.Fprintln(out)
+	}
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestBlockersReport(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	report := func(fixedTime string, args ...string) BlockersReport {
+		t.Helper()
+		args = append([]string{"blockers", "report", "--project", projectKey, "--format", "json", "--fixed-time", fixedTime}, args...)
+		out, err := run(args...)
+		if err != nil {
+			t.Fatalf("blockers report failed: %v", err)
+		}
+		var r BlockersReport
+		if err := json.Unmarshal([]byte(out), &r); err != nil {
+			t.Fatalf("Failed to parse report: %v\n%s", err, out)
+		}
+		return r
+	}
+
+	const created = "2024-06-01T09:00:00Z"
+	id := func(n string) string { return projectKey + "-" + n }
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "Checkout flow"},
+		{"issue", "create", "--project", projectKey, "--title", "Payment API"},
+		{"issue", "create", "--project", projectKey, "--title", "Release notes"},
+		{"issue", "create", "--project", projectKey, "--title", "Infra", "--status", "DONE"},
+		{"issue", "link", id("1"), id("2")},
+		{"issue", "update", id("1"), "--status", "DOING"},
+		{"issue", "link", id("3"), id("4")},
+	} {
+		if _, err := run(append(args, "--fixed-time", created)...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	issue, _ := loadLocalIssue(t.Context(), projectKey, id("1"))
+	if issue.BlockedSince != created {
+		t.Errorf("BlockedSince = %q, want %q", issue.BlockedSince, created)
+	}
+
+	// Issue 1 was started while its blocker is still TODO; issue 3's blocker is done
+	r := report("2024-06-05T09:00:00Z")
+	if len(r.Started) != 1 || r.Started[0].ID != id("1") || r.Started[0].Blockers[0].ID != id("2") {
+		t.Errorf("Expected %s started but blocked, got %+v", id("1"), r.Started)
+	}
+	if len(r.Stale) != 0 {
+		t.Errorf("Expected nothing blocked too long after 4 days, got %+v", r.Stale)
+	}
+
+	r = report("2024-06-10T09:00:00Z")
+	if len(r.Stale) != 1 || r.Stale[0].ID != id("1") || r.Stale[0].BlockedFor != "9d" {
+		t.Errorf("Expected %s blocked for 9d, got %+v", id("1"), r.Stale)
+	}
+	if r = report("2024-06-10T09:00:00Z", "--older-than", "2w"); len(r.Stale) != 0 {
+		t.Errorf("Expected nothing blocked longer than 2w, got %+v", r.Stale)
+	}
+
+	// Once the blocker is started, issue 1 is only flagged for how long it waits
+	if _, err := run("issue", "update", id("2"), "--status", "DOING"); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	r = report("2024-06-10T09:00:00Z")
+	if len(r.Started) != 0 || len(r.Stale) != 1 {
+		t.Errorf("Expected only the long-blocked alert, got %+v", r)
+	}
+
+	out, err := run("blockers", "report", "--project", projectKey, "--format", "modern", "--fixed-time", "2024-06-10T09:00:00Z")
+	if err != nil {
+		t.Fatalf("blockers report failed: %v", err)
+	}
+	if !strings.Contains(out, "Blocked longer than 7d") || !strings.Contains(out, "blocked by "+id("2")) {
+		t.Errorf("Unexpected report output: %s", out)
+	}
+
+	// Removing the last dependency clears the blocked time
+	if _, err := run("issue", "link", id("1"), id("2"), "--remove"); err != nil {
+		t.Fatalf("unlink failed: %v", err)
+	}
+	issue, _ = loadLocalIssue(t.Context(), projectKey, id("1"))
+	if issue.BlockedSince != "" {
+		t.Errorf("Expected BlockedSince to be cleared, got %q", issue.BlockedSince)
+	}
+	if _, err := run("blockers", "report", "--project", projectKey, "--older-than", "soon"); err == nil {
+		t.Error("Expected an invalid --older-than to fail")
+	}
+}
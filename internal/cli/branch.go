@@ -0,0 +1,228 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// NewBranchCmd creates and returns the branch command.
+func NewBranchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "branch <id>",
+		Short: "Create and check out a git branch for an issue",
+		Long: `Create and check out a git branch for an issue in the current repository,
+and record the branch on the issue. The branch is named from the
+branch_template config key (default "{prefix}/{id}-{slug}", e.g.
+"feat/CORE-12-login-page"; {prefix} is "fix" for bugs). An issue that already
+has a branch gets that branch checked out again.`,
+		Example: `  buyruk branch CORE-12
+  buyruk config set branch_template '{type}/{id}-{slug}'`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeIssueArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveIssueArgs(cmd, args[:1]); err != nil {
+				return err
+			}
+			return branchIssue(args[0], cmd)
+		},
+	}
+
+	cmd.Flags().Bool("no-checkout", false, "Create the branch without checking it out")
+
+	return cmd
+}
+
+// NewIssueCurrentCmd creates and returns the issue current command.
+func NewIssueCurrentCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "current",
+		Short: "View the issue of the current git branch",
+		Long: `View the issue of the current git branch. The issue is found from an issue
+ID in the branch name (as created by buyruk branch), or else from the branch
+recorded on an issue of the current project.`,
+		Example: `  buyruk issue current
+  buyruk issue update $(buyruk issue current --id) --status REVIEW`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return currentIssue(cmd)
+		},
+	}
+
+	cmd.Flags().Bool("id", false, "Print only the issue ID")
+	addTemplateFlags(cmd, "Render with a Go template")
+
+	return cmd
+}
+
+// branchIssue creates or checks out the branch of an issue and records it.
+func branchIssue(issueID string, cmd *cobra.Command) error {
+	projectKey, _, err := parseIssueID(cmd.Context(), issueID)
+	if err != nil {
+		return invalidf("cli: invalid issue ID %q: %w", issueID, err)
+	}
+	issue, err := loadLocalIssue(cmd.Context(), projectKey, issueID)
+	if err != nil {
+		return err
+	}
+	if issue == nil {
+		return notFoundf("cli: issue %q not found", issueID)
+	}
+
+	if _, err := git(".", "rev-parse", "--is-inside-work-tree"); err != nil {
+		return fmt.Errorf("cli: not in a git repository")
+	}
+
+	branch := issue.Branch
+	if branch == "" {
+		cfg, err := config.Get()
+		if err != nil {
+			return fmt.Errorf("cli: failed to load config: %w", err)
+		}
+		branch = models.BranchName(cfg.BranchNameTemplate(), projectKey, issue)
+	}
+	if _, err := git(".", "check-ref-format", "--branch", branch); err != nil {
+		return invalidf("cli: %q is not a valid branch name (check branch_template)", branch)
+	}
+
+	noCheckout, _ := cmd.Flags().GetBool("no-checkout")
+	_, err = git(".", "rev-parse", "--verify", "--quiet", "refs/heads/"+branch)
+	exists := err == nil
+	var args []string
+	switch {
+	case exists && noCheckout:
+	case exists:
+		args = []string{"checkout", "--quiet", branch}
+	case noCheckout:
+		args = []string{"branch", branch}
+	default:
+		args = []string{"checkout", "--quiet", "-b", branch}
+	}
+	if args != nil {
+		if _, err := git(".", args...); err != nil {
+			return fmt.Errorf("cli: %w", err)
+		}
+	}
+
+	if issue.Branch != branch {
+		if err := recordIssueBranch(cmd.Context(), projectKey, issueID, branch); err != nil {
+			return err
+		}
+	}
+
+	out := successOut(cmd)
+	switch {
+	case noCheckout && exists:
+		fmt.Fprintf(out, "Branch %q of %s already exists\n", branch, issueID)
+	case noCheckout:
+		fmt.Fprintf(out, "Created branch %q for %s\n", branch, issueID)
+	case exists:
+		fmt.Fprintf(out, "Switched to branch %q of %s\n", branch, issueID)
+	default:
+		fmt.Fprintf(out, "Switched to a new branch %q for %s\n", branch, issueID)
+	}
+	return nil
+}
+
+// recordIssueBranch sets the branch of an issue.
+func recordIssueBranch(ctx context.Context, projectKey, issueID, branch string) error {
+	issuePath, err := storage.IssuePath(ctx, projectKey, issueID)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+	}
+	var issue models.Issue
+	if err := storage.UpdateJSONAtomic(ctx, issuePath, &issue, func(v interface{}) error {
+		iss := v.(*models.Issue)
+		if iss.ID == "" || iss.ID != issueID {
+			return notFoundf("cli: issue %q not found", issueID)
+		}
+		iss.Branch = branch
+		iss.UpdatedAt = storage.Timestamp(ctx)
+		return nil
+	}); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return notFoundf("cli: issue %q not found", issueID)
+		}
+		return fmt.Errorf("cli: failed to update issue: %w", err)
+	}
+	return nil
+}
+
+// currentIssue views the issue of the current git branch.
+func currentIssue(cmd *cobra.Command) error {
+	output, err := git(".", "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		return fmt.Errorf("cli: not on a git branch")
+	}
+	branch := strings.TrimSpace(string(output))
+
+	issueID, err := findBranchIssue(branch, cmd)
+	if err != nil {
+		return err
+	}
+	if issueID == "" {
+		return notFoundf("cli: no issue found for branch %q", branch)
+	}
+
+	if idOnly, _ := cmd.Flags().GetBool("id"); idOnly {
+		fmt.Fprintln(cmd.OutOrStdout(), issueID)
+		return nil
+	}
+	return viewIssue(issueID, cmd)
+}
+
+// findBranchIssue returns the issue of a branch: an existing issue whose ID
+// appears in the branch name (matched case-insensitively, longest project key
+// first), or else the issue of the current project that recorded the branch.
+// Returns "" when there is none.
+func findBranchIssue(branch string, cmd *cobra.Command) (string, error) {
+	projects, err := storage.ListProjects(cmd.Context())
+	if err != nil {
+		return "", fmt.Errorf("cli: %w", err)
+	}
+	slices.SortStableFunc(projects, func(a, b string) int { return len(b) - len(a) })
+	for _, projectKey := range projects {
+		pattern := regexp.MustCompile(`(?i)(?:^|[^a-z0-9])` + regexp.QuoteMeta(projectKey) + `-(` + issueSuffixPattern + `)(?:[^0-9]|$)`)
+		for _, match := range pattern.FindAllStringSubmatch(branch, -1) {
+			issueID := projectKey + "-" + strings.ToUpper(match[1])
+			if _, sequence, err := models.ParseIssueID(issueID); err != nil {
+				continue
+			} else if sequence > 0 {
+				issueID = issueIDBySequence(cmd.Context(), projectKey, sequence)
+			}
+			issue, err := loadLocalIssue(cmd.Context(), projectKey, issueID)
+			if err != nil {
+				return "", err
+			}
+			if issue != nil {
+				return issueID, nil
+			}
+		}
+	}
+
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return "", nil
+	}
+	index, err := loadQueryIndex(cmd.Context(), projectKey)
+	if err != nil {
+		return "", nil
+	}
+	for _, entry := range index.Issues {
+		issue, err := loadLocalIssue(cmd.Context(), projectKey, entry.ID)
+		if err != nil {
+			return "", err
+		}
+		if issue != nil && issue.Branch == branch {
+			return issue.ID, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestBranch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"config", "user.name", "Test"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "commit.gpgsign", "false"},
+		{"commit", "--quiet", "--allow-empty", "-m", "Initial commit"},
+	} {
+		if _, err := git(repo, args...); err != nil {
+			t.Fatalf("git %v failed: %v", args, err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(repo, storage.LocalDirName), 0755); err != nil {
+		t.Fatalf("Failed to create .buyruk: %v", err)
+	}
+	t.Chdir(repo)
+
+	originalCfg, _ := config.Get()
+	defer func() {
+		if originalCfg != nil {
+			config.Save(t.Context(), originalCfg)
+		}
+	}()
+	if err := config.Set(t.Context(), "branch_template", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	currentBranch := func() string {
+		out, _ := git(repo, "symbolic-ref", "--short", "HEAD")
+		return strings.TrimSpace(string(out))
+	}
+
+	for _, args := range [][]string{
+		{"project", "create", "CORE"},
+		{"issue", "create", "--project", "CORE", "--title", "Fix login (SSO)", "--type", "bug"},
+		{"issue", "create", "--project", "CORE", "--title", "Audit log"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+	base := currentBranch()
+
+	out, err := run("branch", "CORE-1")
+	if err != nil {
+		t.Fatalf("branch failed: %v", err)
+	}
+	if got := currentBranch(); got != "fix/CORE-1-fix-login-sso" {
+		t.Fatalf("Current branch = %q, want fix/CORE-1-fix-login-sso (%s)", got, out)
+	}
+	if issue, _ := loadLocalIssue(t.Context(), "CORE", "CORE-1"); issue == nil || issue.Branch != "fix/CORE-1-fix-login-sso" {
+		t.Errorf("Expected the branch to be recorded on CORE-1, got %+v", issue)
+	}
+	if out, err := run("issue", "current", "--id"); err != nil || out != "CORE-1\n" {
+		t.Errorf("issue current = %q (%v), want CORE-1", out, err)
+	}
+
+	// The recorded branch is checked out again, even after a retitle
+	git(repo, "checkout", "--quiet", base)
+	if _, err := run("issue", "update", "CORE-1", "--title", "SSO login"); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := run("branch", "CORE-1"); err != nil || !strings.Contains(out, "Switched to branch") || currentBranch() != "fix/CORE-1-fix-login-sso" {
+		t.Errorf("Expected the existing branch to be checked out, got %q (%v) on %s", out, err, currentBranch())
+	}
+
+	// Custom template, without checkout
+	if err := config.Set(t.Context(), "branch_template", "{slug}"); err == nil {
+		t.Error("Expected a template without {id} to be rejected")
+	}
+	if err := config.Set(t.Context(), "branch_template", "work/{project}/{id}"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run("branch", "CORE-2", "--no-checkout"); err != nil {
+		t.Fatalf("branch --no-checkout failed: %v", err)
+	}
+	if currentBranch() != "fix/CORE-1-fix-login-sso" {
+		t.Errorf("--no-checkout switched branches to %s", currentBranch())
+	}
+	if _, err := git(repo, "rev-parse", "--verify", "--quiet", "refs/heads/work/CORE/CORE-2"); err != nil {
+		t.Error("Expected branch work/CORE/CORE-2 to exist")
+	}
+
+	// A branch without an issue ID is matched by the branch recorded on an issue
+	git(repo, "checkout", "--quiet", "-b", "hotfix")
+	if _, err := run("issue", "current", "--project", "CORE"); err == nil {
+		t.Error("Expected no issue for an unrecorded branch")
+	}
+	if err := recordIssueBranch(t.Context(), "CORE", "CORE-2", "hotfix"); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := run("issue", "current", "--project", "CORE", "--id"); err != nil || out != "CORE-2\n" {
+		t.Errorf("issue current = %q (%v), want CORE-2", out, err)
+	}
+}
@@ -0,0 +1,327 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/timeparse"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// EpicBudgetUsage is the time logged against an epic's issues, compared with
+// the epic's budget.
+type EpicBudgetUsage struct {
+	Budget        *models.Budget `json:"budget,omitempty"`
+	LoggedHours   float64        `json:"logged_hours"`
+	Cost          float64        `json:"cost,omitempty"`           // Logged hours at the budget rate
+	HoursPercent  int            `json:"hours_percent,omitempty"`  // Of budgeted hours
+	AmountPercent int            `json:"amount_percent,omitempty"` // Of budgeted amount
+	Issues        []IssueTime    `json:"issues"`                   // Issues with logged time
+
+	logged time.Duration
+}
+
+// IssueTime is the time logged against one issue.
+type IssueTime struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	LoggedHours float64 `json:"logged_hours"`
+}
+
+// NewIssueLogCmd creates and returns the issue log command.
+func NewIssueLogCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "log <id> <duration>",
+		Short: "Log working time on an issue",
+		Long: `Log working time on an issue, e.g. "1h30m" or "2d" (a day is 8 hours and a
+week 5 days). Time logged on issues of an epic with a budget counts against
+it, and crossing one of the budget's warning levels prints a warning.`,
+		Example:           `  buyruk issue log CORE-12 1h30m --user alice --note "Code review"`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeIssueArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveIssueArgs(cmd, args[:1]); err != nil {
+				return err
+			}
+			return logIssueTime(args[0], args[1], cmd)
+		},
+	}
+
+	cmd.Flags().String("user", "", "User who did the work")
+	cmd.Flags().String("note", "", "What the time was spent on")
+
+	return cmd
+}
+
+// logIssueTime adds a time entry to an issue and warns when the time pushes
+// the issue's epic past a budget warning level.
+func logIssueTime(issueID, duration string, cmd *cobra.Command) error {
+	projectKey, _, err := parseIssueID(cmd.Context(), issueID)
+	if err != nil {
+		return invalidf("cli: invalid issue ID %q: %w", issueID, err)
+	}
+
+	user, _ := cmd.Flags().GetString("user")
+	note, _ := cmd.Flags().GetString("note")
+	entry := models.TimeEntry{
+		Duration: strings.ToLower(strings.TrimSpace(duration)),
+		User:     user,
+		Note:     note,
+		LoggedAt: storage.Timestamp(cmd.Context()),
+	}
+	if err := entry.Validate(); err != nil {
+		return invalidf("cli: %w", err)
+	}
+
+	issuePath, err := storage.IssuePath(cmd.Context(), projectKey, issueID)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+	}
+
+	var issue models.Issue
+	if err := storage.UpdateJSONAtomic(cmd.Context(), issuePath, &issue, func(v interface{}) error {
+		iss := v.(*models.Issue)
+
+		// Check if issue exists (ID should match if file existed)
+		if iss.ID == "" || iss.ID != issueID {
+			return notFoundf("cli: issue %q not found", issueID)
+		}
+
+		iss.TimeEntries = append(iss.TimeEntries, entry)
+		iss.UpdatedAt = entry.LoggedAt
+
+		return nil
+	}); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return notFoundf("cli: issue %q not found", issueID)
+		}
+		return fmt.Errorf("cli: failed to update issue: %w", err)
+	}
+
+	out := successOut(cmd)
+	fmt.Fprintf(out, "Logged %s on %s (%s total)\n", entry.Duration, issueID, ui.FormatWorkDuration(issue.LoggedTime()))
+
+	if issue.EpicID == "" {
+		return nil
+	}
+	epic, err := loadEpic(cmd.Context(), projectKey, issue.EpicID)
+	if err != nil || epic.Budget == nil {
+		return nil
+	}
+	usage, err := loadEpicBudgetUsage(cmd.Context(), projectKey, epic)
+	if err != nil {
+		return nil
+	}
+	logged, _ := timeparse.ParseWorkDuration(entry.Duration)
+	if level := epic.Budget.CrossedWarning(usage.logged-logged, usage.logged); level > 0 {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: epic %s has used %d%% of its budget (warning at %d%%)\n",
+			epic.ID, int(epic.Budget.Burn(usage.logged)), level)
+	}
+	return nil
+}
+
+// loadEpic loads an epic by ID.
+func loadEpic(ctx context.Context, projectKey, epicID string) (*models.Epic, error) {
+	epicPath, err := storage.EpicPath(ctx, projectKey, epicID)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve epic path: %w", err)
+	}
+	var epic models.Epic
+	if err := storage.ReadJSON(ctx, epicPath, &epic); err != nil {
+		return nil, fmt.Errorf("cli: failed to load epic %q: %w", epicID, err)
+	}
+	return &epic, nil
+}
+
+// addBudgetFlags adds the flags that set an epic's budget.
+func addBudgetFlags(cmd *cobra.Command) {
+	cmd.Flags().Float64("budget-hours", 0, "Budgeted hours")
+	cmd.Flags().Float64("budget-amount", 0, "Budgeted amount of money (needs --rate)")
+	cmd.Flags().Float64("rate", 0, "Hourly rate billed for logged time")
+	cmd.Flags().String("currency", "", "Currency of the budget amount and rate (e.g. USD)")
+	cmd.Flags().String("budget-warnings", "", "Comma-separated percentages of the budget that warn when crossed (default 80,100)")
+}
+
+// applyBudgetFlags applies the budget flags that were set to an epic. Setting
+// every value to zero removes the budget.
+func applyBudgetFlags(cmd *cobra.Command, epic *models.Epic) error {
+	budget := models.Budget{}
+	if epic.Budget != nil {
+		budget = *epic.Budget
+	}
+	changed := false
+	for _, f := range []struct {
+		name  string
+		value *float64
+	}{
+		{"budget-hours", &budget.Hours},
+		{"budget-amount", &budget.Amount},
+		{"rate", &budget.Rate},
+	} {
+		if cmd.Flags().Changed(f.name) {
+			*f.value, _ = cmd.Flags().GetFloat64(f.name)
+			changed = true
+		}
+	}
+	if cmd.Flags().Changed("currency") {
+		currency, _ := cmd.Flags().GetString("currency")
+		budget.Currency = strings.ToUpper(strings.TrimSpace(currency))
+		changed = true
+	}
+	if cmd.Flags().Changed("budget-warnings") {
+		value, _ := cmd.Flags().GetString("budget-warnings")
+		budget.Warnings = nil
+		for _, item := range splitList(value) {
+			p, err := strconv.Atoi(strings.TrimSuffix(item, "%"))
+			if err != nil {
+				return invalidf("cli: invalid --budget-warnings value %q", item)
+			}
+			budget.Warnings = append(budget.Warnings, p)
+		}
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	if budget.IsZero() {
+		epic.Budget = nil
+		return nil
+	}
+	if err := budget.Validate(); err != nil {
+		return invalidf("cli: invalid budget: %w", err)
+	}
+	epic.Budget = &budget
+	return nil
+}
+
+// loadEpicBudgetUsage totals the time logged against the issues of an epic.
+func loadEpicBudgetUsage(ctx context.Context, projectKey string, epic *models.Epic) (*EpicBudgetUsage, error) {
+	index, err := loadQueryIndex(ctx, projectKey)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &EpicBudgetUsage{Budget: epic.Budget, Issues: []IssueTime{}}
+	var logged time.Duration
+	for _, entry := range index.Issues {
+		if entry.EpicID != epic.ID {
+			continue
+		}
+		issue, err := loadLocalIssue(ctx, projectKey, entry.ID)
+		if err != nil {
+			return nil, err
+		}
+		if issue == nil {
+			continue
+		}
+		if d := issue.LoggedTime(); d > 0 {
+			logged += d
+			usage.Issues = append(usage.Issues, IssueTime{ID: issue.ID, Title: issue.Title, LoggedHours: roundHours(d.Hours())})
+		}
+	}
+
+	usage.logged = logged
+	usage.LoggedHours = roundHours(logged.Hours())
+	if b := epic.Budget; b != nil {
+		usage.Cost = roundHours(logged.Hours() * b.Rate)
+		if b.Hours > 0 {
+			usage.HoursPercent = int(logged.Hours() * 100 / b.Hours)
+		}
+		if b.Amount > 0 {
+			usage.AmountPercent = int(logged.Hours() * b.Rate * 100 / b.Amount)
+		}
+	}
+	return usage, nil
+}
+
+// warnEpicBudget warns when an epic's logged time is at or past one of its
+// budget's warning levels.
+func warnEpicBudget(epic *models.Epic, usage *EpicBudgetUsage, w io.Writer) {
+	if epic.Budget == nil {
+		return
+	}
+	burn := epic.Budget.Burn(usage.logged)
+	reached := 0
+	for _, level := range epic.Budget.WarningLevels() {
+		if burn >= float64(level) {
+			reached = level
+		}
+	}
+	if reached > 0 {
+		fmt.Fprintf(w, "Warning: epic %s has used %d%% of its budget (warning at %d%%)\n", epic.ID, int(burn), reached)
+	}
+}
+
+// renderEpicBudget renders an epic's budget usage after the epic in modern and
+// L-SON output.
+func renderEpicBudget(usage *EpicBudgetUsage, cmd *cobra.Command, w io.Writer) {
+	if usage == nil {
+		return
+	}
+	b := usage.Budget
+	if b == nil {
+		b = &models.Budget{}
+	}
+
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatLSON:
+		fields := []string{formatHours(usage.LoggedHours)}
+		if b.Hours > 0 {
+			fields[0] += "/" + formatHours(b.Hours)
+		}
+		if b.Rate > 0 {
+			amount := formatMoney(usage.Cost, "")
+			if b.Amount > 0 {
+				amount += "/" + formatMoney(b.Amount, "")
+			}
+			fields = append(fields, strings.TrimSpace(amount+" "+b.Currency))
+		}
+		fmt.Fprintf(w, "@BUDGET: %s\n", strings.Join(fields, "|"))
+		for _, issue := range usage.Issues {
+			fmt.Fprintf(w, "@LOGGED: %s|%s\n", issue.ID, formatHours(issue.LoggedHours))
+		}
+	case config.DefaultFormatModern:
+		styles := ui.NewStyles()
+		if usage.Budget == nil {
+			fmt.Fprintf(w, "\n%s: %s logged (no budget set)\n", styles.Label("Budget"), formatHours(usage.LoggedHours))
+		} else {
+			fmt.Fprintf(w, "\n%s:\n", styles.Label("Budget"))
+			if b.Hours > 0 {
+				fmt.Fprintf(w, "  Hours   %s of %s (%d%%)\n", formatHours(usage.LoggedHours), formatHours(b.Hours), usage.HoursPercent)
+			} else {
+				fmt.Fprintf(w, "  Hours   %s logged\n", formatHours(usage.LoggedHours))
+			}
+			if b.Amount > 0 {
+				fmt.Fprintf(w, "  Amount  %s of %s (%d%%)\n", formatMoney(usage.Cost, b.Currency), formatMoney(b.Amount, b.Currency), usage.AmountPercent)
+			} else if b.Rate > 0 {
+				fmt.Fprintf(w, "  Amount  %s\n", formatMoney(usage.Cost, b.Currency))
+			}
+		}
+		for _, issue := range usage.Issues {
+			fmt.Fprintf(w, "  - %s %s: %s\n", styles.ID(issue.ID), issue.Title, formatHours(issue.LoggedHours))
+		}
+	}
+}
+
+// formatHours renders hours with at most two decimals, e.g. "1.5h".
+func formatHours(hours float64) string {
+	return strconv.FormatFloat(roundHours(hours), 'f', -1, 64) + "h"
+}
+
+// formatMoney renders an amount with two decimals and an optional currency.
+func formatMoney(amount float64, currency string) string {
+	return strings.TrimSpace(fmt.Sprintf("%.2f %s", amount, currency))
+}
+
+// roundHours rounds to two decimals.
+func roundHours(hours float64) float64 {
+	return float64(int64(hours*100+0.5)) / 100
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestEpicBudget(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(errOut)
+		err := cmd.Execute()
+		return out.String(), errOut.String(), err
+	}
+
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"epic", "create", "--project", projectKey, "--title", "Client site", "--budget-hours", "20", "--rate", "100", "--currency", "usd"},
+		{"issue", "create", "--project", projectKey, "--title", "Landing page", "--epic", projectKey + "-E1"},
+		{"issue", "create", "--project", projectKey, "--title", "Contact form", "--epic", projectKey + "-E1"},
+	} {
+		if _, _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+	if _, _, err := run("epic", "update", projectKey+"-E1", "--project", projectKey, "--budget-amount", "-5"); err == nil {
+		t.Error("Expected a negative budget to fail")
+	}
+	if _, _, err := run("issue", "log", projectKey+"-1", "soon"); err == nil {
+		t.Error("Expected an invalid duration to fail")
+	}
+
+	out, errOut, err := run("issue", "log", projectKey+"-1", "1d", "--user", "alice", "--note", "Layout")
+	if err != nil || !strings.Contains(out, "Logged 1d on "+projectKey+"-1 (1d total)") || errOut != "" {
+		t.Fatalf("log failed: %v: %s%s", err, out, errOut)
+	}
+
+	// 8h + 9h is 85% of 20h, crossing the default 80% warning
+	if _, errOut, _ = run("issue", "log", projectKey+"-2", "9h"); !strings.Contains(errOut, "epic "+projectKey+"-E1 has used 85% of its budget (warning at 80%)") {
+		t.Errorf("Expected a budget warning, got %q", errOut)
+	}
+	if _, errOut, _ = run("issue", "log", projectKey+"-2", "30m"); errOut != "" {
+		t.Errorf("Expected no warning without crossing a level, got %q", errOut)
+	}
+
+	out, errOut, err = run("epic", "view", projectKey+"-E1", "--project", projectKey, "--with-budget")
+	if err != nil {
+		t.Fatalf("epic view failed: %v", err)
+	}
+	for _, want := range []string{"Hours   17.5h of 20h (87%)", "Amount  1750.00 USD", projectKey + "-2 Contact form: 9.5h"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+	if !strings.Contains(errOut, "Warning: epic "+projectKey+"-E1 has used 87%") {
+		t.Errorf("Expected a budget warning, got %q", errOut)
+	}
+
+	out, _, err = run("epic", "view", projectKey+"-E1", "--project", projectKey, "--with-budget", "--format", "json")
+	if err != nil {
+		t.Fatalf("epic view failed: %v", err)
+	}
+	var result EpicWithProgress
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+	if result.Usage == nil || result.Usage.LoggedHours != 17.5 || result.Usage.Cost != 1750 || len(result.Usage.Issues) != 2 {
+		t.Errorf("Unexpected budget usage: %+v", result.Usage)
+	}
+	if result.Budget == nil || result.Budget.Currency != "USD" {
+		t.Errorf("Expected the epic budget in JSON, got %+v", result.Budget)
+	}
+
+	// Zeroing every value removes the budget
+	if _, _, err := run("epic", "update", projectKey+"-E1", "--project", projectKey, "--budget-hours", "0", "--rate", "0", "--currency", ""); err != nil {
+		t.Fatalf("epic update failed: %v", err)
+	}
+	if epic, _ := loadEpic(t.Context(), projectKey, projectKey+"-E1"); epic.Budget != nil {
+		t.Errorf("Expected the budget to be removed, got %+v", epic.Budget)
+	}
+}
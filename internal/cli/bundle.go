@@ -0,0 +1,624 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// configBundleVersion is the version of the bundle format written by config export.
+const configBundleVersion = 1
+
+// ConfigBundle is a portable snapshot of a user's setup: global config (including
+// named templates and aliases), custom themes, saved views, and project
+// workflows and retention policies. It never contains issue data or secrets.
+type ConfigBundle struct {
+	Version      int                           `json:"version"`
+	ExportedAt   string                        `json:"exported_at"`
+	Config       *config.Config                `json:"config"`
+	Themes       map[string]*ui.Theme          `json:"themes,omitempty"`        // Custom color themes by name
+	Views        []models.SavedView            `json:"views,omitempty"`         // Saved views of all projects
+	ProjectViews map[string][]models.SavedView `json:"project_views,omitempty"` // Saved views by project key
+	Workflows    map[string]*models.Workflow   `json:"workflows,omitempty"`     // Custom workflows by project key
+	Policies     map[string]*models.Policy     `json:"policies,omitempty"`      // Retention policies by project key
+}
+
+// NewConfigExportCmd creates and returns the config export command.
+func NewConfigExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export [file]",
+		Short: "Export configuration, templates, themes, views, and workflows to a bundle",
+		Long: `Export the global configuration, named templates, aliases, custom themes,
+saved views, and the custom workflows and retention policies of projects to a
+JSON bundle, to replicate a setup on another machine or share team
+conventions. Issue data and secrets are never included.
+
+Writes to stdout if no file (or "-") is given.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "-"
+			if len(args) > 0 {
+				path = args[0]
+			}
+			return exportConfigBundle(path, cmd)
+		},
+	}
+
+	cmd.Flags().Bool("no-workflows", false, "Exclude project workflows")
+
+	return cmd
+}
+
+// NewConfigImportCmd creates and returns the config import command.
+func NewConfigImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import configuration, templates, themes, views, and workflows from a bundle",
+		Long: `Import a bundle written by 'buyruk config export' ("-" reads stdin).
+
+Settings set in the bundle overwrite local ones, and templates, aliases,
+themes, and saved views are merged by name; use --replace to replace the local
+configuration and saved views entirely (themes are still only added or
+updated). Project views, workflows, and retention policies are only imported
+for projects that exist locally.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return importConfigBundle(args[0], cmd)
+		},
+	}
+
+	cmd.Flags().Bool("replace", false, "Replace the local configuration and saved views instead of merging")
+	cmd.Flags().Bool("dry-run", false, "Show what would change without writing")
+
+	return cmd
+}
+
+// exportConfigBundle writes the current setup as a bundle to path.
+func exportConfigBundle(path string, cmd *cobra.Command) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("cli: failed to load config: %w", err)
+	}
+
+	bundle := ConfigBundle{
+		Version:    configBundleVersion,
+		ExportedAt: storage.Timestamp(cmd.Context()),
+		Config:     cfg,
+	}
+
+	themes, err := loadCustomThemes(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if len(themes) > 0 {
+		bundle.Themes = themes
+	}
+	globalViewsPath, err := storage.GlobalViewsPath()
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve views path: %w", err)
+	}
+	var views models.SavedViews
+	if _, err := readBundleFile(cmd.Context(), globalViewsPath, &views); err != nil {
+		return fmt.Errorf("cli: failed to load views: %w", err)
+	}
+	bundle.Views = views.Views
+
+	noWorkflows, _ := cmd.Flags().GetBool("no-workflows")
+	keys, err := storage.ListProjects(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("cli: failed to list projects: %w", err)
+	}
+	for _, key := range keys {
+		viewsPath, err := storage.ViewsPath(cmd.Context(), key)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve views path: %w", err)
+		}
+		var views models.SavedViews
+		if _, err := readBundleFile(cmd.Context(), viewsPath, &views); err != nil {
+			return fmt.Errorf("cli: failed to load views of %s: %w", key, err)
+		}
+		if len(views.Views) > 0 {
+			if bundle.ProjectViews == nil {
+				bundle.ProjectViews = map[string][]models.SavedView{}
+			}
+			bundle.ProjectViews[key] = views.Views
+		}
+
+		policyPath, err := storage.PolicyPath(cmd.Context(), key)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve policy path: %w", err)
+		}
+		var policy models.Policy
+		if _, err := readBundleFile(cmd.Context(), policyPath, &policy); err != nil {
+			return fmt.Errorf("cli: failed to load policy of %s: %w", key, err)
+		}
+		if !policy.IsEmpty() {
+			if bundle.Policies == nil {
+				bundle.Policies = map[string]*models.Policy{}
+			}
+			bundle.Policies[key] = &policy
+		}
+
+		if noWorkflows {
+			continue
+		}
+		workflowPath, err := storage.WorkflowPath(cmd.Context(), key)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve workflow path: %w", err)
+		}
+		var wf models.Workflow
+		ok, err := readBundleFile(cmd.Context(), workflowPath, &wf)
+		if err != nil {
+			return fmt.Errorf("cli: failed to load workflow of %s: %w", key, err)
+		}
+		if !ok {
+			continue
+		}
+		if bundle.Workflows == nil {
+			bundle.Workflows = map[string]*models.Workflow{}
+		}
+		bundle.Workflows[key] = &wf
+	}
+
+	data, err := json.MarshalIndent(&bundle, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cli: failed to marshal bundle: %w", err)
+	}
+	data = append(data, '\n')
+
+	if path == "-" {
+		_, err := cmd.OutOrStdout().Write(data)
+		return err
+	}
+	if err := storage.WriteAtomic(cmd.Context(), path, data); err != nil {
+		return fmt.Errorf("cli: failed to write bundle: %w", err)
+	}
+	viewCount := len(bundle.Views)
+	for _, views := range bundle.ProjectViews {
+		viewCount += len(views)
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Exported config bundle to %s (%d templates, %d aliases, %d themes, %d views, %d workflows, %d policies)\n",
+		path, len(cfg.Templates), len(cfg.Aliases), len(bundle.Themes), viewCount, len(bundle.Workflows), len(bundle.Policies))
+	return nil
+}
+
+// readBundleFile reads a JSON file of a setup into v, and reports whether it
+// exists.
+func readBundleFile(ctx context.Context, path string, v interface{}) (bool, error) {
+	if err := storage.ReadJSON(ctx, path, v); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// loadCustomThemes loads the custom color themes in the themes directory by name.
+func loadCustomThemes(ctx context.Context) (map[string]*ui.Theme, error) {
+	themesDir, err := storage.ThemesDir()
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve themes directory: %w", err)
+	}
+	entries, err := os.ReadDir(themesDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("cli: failed to read themes directory: %w", err)
+	}
+	themes := map[string]*ui.Theme{}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() || !config.IsCustomThemeName(name) {
+			continue
+		}
+		var theme ui.Theme
+		if err := storage.ReadJSON(ctx, filepath.Join(themesDir, entry.Name()), &theme); err != nil {
+			return nil, fmt.Errorf("cli: failed to load theme %q: %w", name, err)
+		}
+		themes[name] = &theme
+	}
+	return themes, nil
+}
+
+// importConfigBundle applies a bundle read from path.
+func importConfigBundle(path string, cmd *cobra.Command) error {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("cli: failed to read bundle: %w", err)
+	}
+
+	var bundle ConfigBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return invalidf("cli: invalid bundle: %w", err)
+	}
+	if bundle.Version < 1 || bundle.Version > configBundleVersion {
+		return fmt.Errorf("cli: unsupported bundle version %d (this version of buyruk reads up to %d)", bundle.Version, configBundleVersion)
+	}
+	if bundle.Config == nil {
+		bundle.Config = &config.Config{}
+	}
+	if err := config.Validate(bundle.Config); err != nil {
+		return invalidf("cli: invalid config in bundle: %w", err)
+	}
+	for key, wf := range bundle.Workflows {
+		if wf == nil {
+			return invalidf("cli: invalid workflow for %s in bundle", key)
+		}
+		if err := wf.Validate(); err != nil {
+			return invalidf("cli: invalid workflow for %s in bundle: %w", key, err)
+		}
+	}
+	for name, theme := range bundle.Themes {
+		if !config.IsCustomThemeName(name) || theme == nil {
+			return invalidf("cli: invalid theme %q in bundle", name)
+		}
+		if theme.Base != "" && !slices.Contains(ui.BuiltinThemeNames(), theme.Base) {
+			return invalidf("cli: theme %q in bundle has unknown base %q", name, theme.Base)
+		}
+	}
+	if err := (&models.SavedViews{Views: bundle.Views}).Validate(); err != nil {
+		return invalidf("cli: invalid views in bundle: %w", err)
+	}
+	for key, views := range bundle.ProjectViews {
+		if err := (&models.SavedViews{Views: views}).Validate(); err != nil {
+			return invalidf("cli: invalid views for %s in bundle: %w", key, err)
+		}
+	}
+	for key, policy := range bundle.Policies {
+		if policy == nil {
+			return invalidf("cli: invalid policy for %s in bundle", key)
+		}
+		if err := policy.Validate(); err != nil {
+			return invalidf("cli: invalid policy for %s in bundle: %w", key, err)
+		}
+	}
+
+	current, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("cli: failed to load config: %w", err)
+	}
+
+	replace, _ := cmd.Flags().GetBool("replace")
+	merged, changes := mergeConfig(current, bundle.Config, replace)
+
+	currentThemes, err := loadCustomThemes(cmd.Context())
+	if err != nil {
+		return err
+	}
+	themeNames := slices.Sorted(maps.Keys(bundle.Themes))
+	mergedThemes := maps.Clone(currentThemes)
+	maps.Copy(mergedThemes, bundle.Themes)
+	changes = append(changes, namedChanges("themes/", themeValues(currentThemes), themeValues(mergedThemes))...)
+
+	globalViewsPath, err := storage.GlobalViewsPath()
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve views path: %w", err)
+	}
+	var globalViews models.SavedViews
+	if _, err := readBundleFile(cmd.Context(), globalViewsPath, &globalViews); err != nil {
+		return fmt.Errorf("cli: failed to load views: %w", err)
+	}
+	mergedViews, viewChanges := mergeViews("view.", globalViews.Views, bundle.Views, replace)
+	changes = append(changes, viewChanges...)
+
+	// Project views, workflows, and policies of projects missing on this
+	// machine are skipped
+	errOut := cmd.ErrOrStderr()
+	projectViews := map[string][]models.SavedView{}
+	viewKeys, err := bundleProjectKeys(cmd.Context(), errOut, "views", slices.Sorted(maps.Keys(bundle.ProjectViews)))
+	if err != nil {
+		return err
+	}
+	for _, key := range viewKeys {
+		viewsPath, err := storage.ViewsPath(cmd.Context(), key)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve views path: %w", err)
+		}
+		var views models.SavedViews
+		if _, err := readBundleFile(cmd.Context(), viewsPath, &views); err != nil {
+			return fmt.Errorf("cli: failed to load views of %s: %w", key, err)
+		}
+		var keyChanges []string
+		projectViews[key], keyChanges = mergeViews("view.", views.Views, bundle.ProjectViews[key], replace)
+		for _, change := range keyChanges {
+			changes = append(changes, change+" of "+key)
+		}
+	}
+	workflowKeys, err := bundleProjectKeys(cmd.Context(), errOut, "workflow", slices.Sorted(maps.Keys(bundle.Workflows)))
+	if err != nil {
+		return err
+	}
+	for _, key := range workflowKeys {
+		changes = append(changes, "workflow of "+key)
+	}
+	policyKeys, err := bundleProjectKeys(cmd.Context(), errOut, "policy", slices.Sorted(maps.Keys(bundle.Policies)))
+	if err != nil {
+		return err
+	}
+	for _, key := range policyKeys {
+		policy, err := loadPolicy(cmd.Context(), key)
+		if err != nil {
+			return err
+		}
+		if *policy != *bundle.Policies[key] {
+			changes = append(changes, "policy of "+key)
+		}
+	}
+
+	out := cmd.OutOrStdout()
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		if len(changes) == 0 {
+			fmt.Fprintln(out, "No changes")
+		}
+		for _, change := range changes {
+			fmt.Fprintf(out, "Would set %s\n", change)
+		}
+		return nil
+	}
+
+	// Themes are written first, so that a config naming one of them is valid
+	for _, name := range themeNames {
+		themePath, err := storage.ThemePath(name)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve theme path: %w", err)
+		}
+		data, err := json.MarshalIndent(bundle.Themes[name], "", "  ")
+		if err != nil {
+			return fmt.Errorf("cli: failed to marshal theme %q: %w", name, err)
+		}
+		if err := storage.WriteAtomic(cmd.Context(), themePath, data); err != nil {
+			return fmt.Errorf("cli: failed to write theme %q: %w", name, err)
+		}
+	}
+	if err := config.Save(cmd.Context(), merged); err != nil {
+		return fmt.Errorf("cli: failed to save config: %w", err)
+	}
+	if len(bundle.Views) > 0 || replace {
+		if err := updateViews(cmd.Context(), globalViewsPath, viewScopeGlobal, func(views *models.SavedViews) error {
+			views.Views = mergedViews
+			return nil
+		}); err != nil {
+			return fmt.Errorf("cli: failed to update views: %w", err)
+		}
+	}
+	for _, key := range viewKeys {
+		viewsPath, err := storage.ViewsPath(cmd.Context(), key)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve views path: %w", err)
+		}
+		if err := updateViews(cmd.Context(), viewsPath, viewScopeProject, func(views *models.SavedViews) error {
+			views.Views = projectViews[key]
+			return nil
+		}); err != nil {
+			return fmt.Errorf("cli: failed to update views of %s: %w", key, err)
+		}
+	}
+	for _, key := range policyKeys {
+		policyPath, err := storage.PolicyPath(cmd.Context(), key)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve policy path: %w", err)
+		}
+		if err := storage.WriteJSONAtomic(cmd.Context(), policyPath, bundle.Policies[key]); err != nil {
+			return fmt.Errorf("cli: failed to write policy of %s: %w", key, err)
+		}
+	}
+	for _, key := range workflowKeys {
+		workflowPath, err := storage.WorkflowPath(cmd.Context(), key)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve workflow path: %w", err)
+		}
+		if err := storage.WriteJSONAtomic(cmd.Context(), workflowPath, bundle.Workflows[key]); err != nil {
+			return fmt.Errorf("cli: failed to write workflow of %s: %w", key, err)
+		}
+	}
+
+	if merged.DefaultProject != "" {
+		if projectDir, err := storage.ProjectDir(cmd.Context(), merged.DefaultProject); err == nil {
+			if _, err := os.Stat(projectDir); os.IsNotExist(err) {
+				fmt.Fprintf(errOut, "Warning: default project %q does not exist\n", merged.DefaultProject)
+			}
+		}
+	}
+
+	out = successOut(cmd)
+	fmt.Fprintf(out, "Imported config bundle (%d changes)\n", len(changes))
+	for _, change := range changes {
+		fmt.Fprintf(out, "  %s\n", change)
+	}
+	return nil
+}
+
+// mergeConfig applies the bundle config to the current one and describes the changes.
+// Without replace, only settings present in the bundle are applied and templates
+// are merged by name.
+func mergeConfig(current, bundle *config.Config, replace bool) (*config.Config, []string) {
+	merged := *bundle
+	if !replace {
+		merged = *current
+		if bundle.DefaultProject != "" {
+			merged.DefaultProject = bundle.DefaultProject
+		}
+		if bundle.DefaultFormat != "" {
+			merged.DefaultFormat = bundle.DefaultFormat
+		}
+		if bundle.Timezone != "" {
+			merged.Timezone = bundle.Timezone
+		}
+		if bundle.MaxIssues != 0 {
+			merged.MaxIssues = bundle.MaxIssues
+		}
+		if bundle.MaxIndexSize != 0 {
+			merged.MaxIndexSize = bundle.MaxIndexSize
+		}
+		if bundle.Autocommit {
+			merged.Autocommit = true
+		}
+		if bundle.Theme != "" {
+			merged.Theme = bundle.Theme
+		}
+		merged.Templates = mergeNamed(current.Templates, bundle.Templates)
+		merged.Aliases = mergeNamed(current.Aliases, bundle.Aliases)
+		if len(bundle.ProjectDefaults) > 0 {
+			merged.ProjectDefaults = maps.Clone(current.ProjectDefaults)
+			if merged.ProjectDefaults == nil {
+				merged.ProjectDefaults = map[string]config.IssueDefaults{}
+			}
+			maps.Copy(merged.ProjectDefaults, bundle.ProjectDefaults)
+		}
+	}
+	// Where projects are kept is up to each machine
+	merged.DataDir = current.DataDir
+
+	var changes []string
+	changed := func(key, from, to string) {
+		if from == to {
+			return
+		}
+		if to == "" {
+			to = "(not set)"
+		}
+		changes = append(changes, fmt.Sprintf("%s = %s", key, to))
+	}
+	count := func(n int64) string {
+		if n == 0 {
+			return ""
+		}
+		return strconv.FormatInt(n, 10)
+	}
+	changed("default_project", current.DefaultProject, merged.DefaultProject)
+	changed("default_format", current.DefaultFormat, merged.DefaultFormat)
+	changed("timezone", current.Timezone, merged.Timezone)
+	changed("max_issues", count(int64(current.MaxIssues)), count(int64(merged.MaxIssues)))
+	changed("max_index_size", count(current.MaxIndexSize), count(merged.MaxIndexSize))
+	changed("autocommit", strconv.FormatBool(current.Autocommit), strconv.FormatBool(merged.Autocommit))
+	changed("theme", current.Theme, merged.Theme)
+
+	changes = append(changes, namedChanges(config.TemplateKeyPrefix, current.Templates, merged.Templates)...)
+	changes = append(changes, namedChanges(config.AliasKeyPrefix, current.Aliases, merged.Aliases)...)
+	changes = append(changes, namedChanges("", projectDefaultValues(current), projectDefaultValues(&merged))...)
+
+	return &merged, changes
+}
+
+// bundleProjectKeys returns, sorted, the project keys of a bundle's project
+// settings of a kind (views, workflow, policy) that can be imported: those of
+// projects that exist locally. The others are skipped with a warning.
+func bundleProjectKeys(ctx context.Context, errOut io.Writer, kind string, keys []string) ([]string, error) {
+	var existing []string
+	for _, key := range keys {
+		if !isValidProjectKey(key) {
+			return nil, invalidf("cli: invalid project key %q in bundle", key)
+		}
+		projectDir, err := storage.ProjectDir(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("cli: failed to resolve project directory: %w", err)
+		}
+		if _, err := os.Stat(projectDir); os.IsNotExist(err) {
+			fmt.Fprintf(errOut, "Warning: skipping %s for %s: project does not exist\n", kind, key)
+			continue
+		}
+		existing = append(existing, key)
+	}
+	return existing, nil
+}
+
+// mergeViews applies the bundle's saved views to the current ones and
+// describes the changes as prefixed view names. Without replace, views are
+// merged by name.
+func mergeViews(prefix string, current, bundle []models.SavedView, replace bool) ([]models.SavedView, []string) {
+	merged := models.SavedViews{Views: slices.Clone(current)}
+	if replace {
+		merged.Views = nil
+	}
+	for _, view := range bundle {
+		merged.Set(view)
+	}
+	return merged.Views, namedChanges(prefix, viewValues(current), viewValues(merged.Views))
+}
+
+// viewValues returns saved views as JSON by name, to compare them.
+func viewValues(views []models.SavedView) map[string]string {
+	values := map[string]string{}
+	for _, view := range views {
+		data, _ := json.Marshal(view)
+		values[view.Name] = string(data)
+	}
+	return values
+}
+
+// themeValues returns custom themes as JSON by name, to compare them.
+func themeValues(themes map[string]*ui.Theme) map[string]string {
+	values := map[string]string{}
+	for name, theme := range themes {
+		data, _ := json.Marshal(theme)
+		values[name] = string(data)
+	}
+	return values
+}
+
+// projectDefaultValues returns the per-project issue defaults by config key.
+func projectDefaultValues(cfg *config.Config) map[string]string {
+	values := map[string]string{}
+	for _, entry := range projectDefaultEntries(cfg) {
+		values[entry[0]] = entry[1]
+	}
+	return values
+}
+
+// mergeNamed merges named config entries such as templates, with the
+// bundle's entries replacing current ones of the same name.
+func mergeNamed(current, bundle map[string]string) map[string]string {
+	merged := map[string]string{}
+	for _, entries := range []map[string]string{current, bundle} {
+		for name, text := range entries {
+			merged[name] = text
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// namedChanges describes the named config entries that differ between
+// current and merged, as prefixed keys.
+func namedChanges(prefix string, current, merged map[string]string) []string {
+	names := map[string]string{}
+	for name := range current {
+		names[name] = ""
+	}
+	for name := range merged {
+		names[name] = ""
+	}
+	var changes []string
+	for _, name := range sortedKeys(names) {
+		from, to := current[name], merged[name]
+		switch {
+		case from == to:
+		case to == "":
+			changes = append(changes, prefix+name+" (removed)")
+		default:
+			changes = append(changes, prefix+name)
+		}
+	}
+	return changes
+}
@@ -0,0 +1,215 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestMergeConfig(t *testing.T) {
+	current := &config.Config{
+		DefaultFormat: "json",
+		Timezone:      "UTC",
+		Templates:     map[string]string{"short": "{{.ID}}", "old": "{{.Title}}"},
+	}
+	bundle := &config.Config{
+		DefaultFormat: "lson",
+		MaxIssues:     500,
+		Templates:     map[string]string{"short": "{{.ID}} {{.Status}}", "wide": "{{.ID}} {{.Title}}"},
+	}
+
+	merged, changes := mergeConfig(current, bundle, false)
+	if merged.DefaultFormat != "lson" || merged.Timezone != "UTC" || merged.MaxIssues != 500 {
+		t.Errorf("Unexpected merged config: %+v", merged)
+	}
+	if len(merged.Templates) != 3 || merged.Templates["old"] != "{{.Title}}" {
+		t.Errorf("Templates should be merged by name, got %v", merged.Templates)
+	}
+	want := []string{"default_format = lson", "max_issues = 500", "template.short", "template.wide"}
+	if !slices.Equal(changes, want) {
+		t.Errorf("changes = %v, want %v", changes, want)
+	}
+
+	replaced, changes := mergeConfig(current, bundle, true)
+	if replaced.Timezone != "" || len(replaced.Templates) != 2 {
+		t.Errorf("Replace should drop local settings, got %+v", replaced)
+	}
+	if !slices.Contains(changes, "timezone = (not set)") || !slices.Contains(changes, "template.old (removed)") {
+		t.Errorf("Replace should report removed settings, got %v", changes)
+	}
+
+	if _, changes := mergeConfig(current, &config.Config{}, false); len(changes) != 0 {
+		t.Errorf("Empty bundle should change nothing, got %v", changes)
+	}
+}
+
+func TestConfigBundle_RoundTrip(t *testing.T) {
+	originalCfg, _ := config.Get()
+	defer func() {
+		if originalCfg != nil {
+			config.Save(t.Context(), originalCfg)
+		}
+	}()
+
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		buf := new(bytes.Buffer)
+		cmd.SetOut(buf)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return buf.String(), err
+	}
+
+	steps := [][]string{
+		{"project", "create", projectKey},
+		{"project", "workflow", projectKey, "--statuses", "TODO,DOING,REVIEW,DONE"},
+		{"config", "set", "timezone", "UTC"},
+		{"config", "set", "template.bundletest", "{{.ID}}"},
+		{"view", "save", "open", "--project", projectKey, "--query", "status!=DONE"},
+		{"policy", "set", "--project", projectKey, "--archive-done-after", "60d"},
+	}
+	for _, args := range steps {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	// A custom theme and a global view to export along with it
+	themePath, _ := storage.ThemePath("bundletest")
+	storage.EnsureDir(themePath)
+	os.WriteFile(themePath, []byte(`{"base": "light", "id": "blue"}`), 0644)
+	defer os.Remove(themePath)
+	globalViewsPath, _ := storage.GlobalViewsPath()
+	if original, err := os.ReadFile(globalViewsPath); err == nil {
+		defer os.WriteFile(globalViewsPath, original, 0644)
+	} else {
+		defer os.Remove(globalViewsPath)
+	}
+	if _, err := run("view", "save", "bundletest", "--global", "--query", "type=bug"); err != nil {
+		t.Fatalf("view save --global failed: %v", err)
+	}
+
+	bundlePath := filepath.Join(t.TempDir(), "bundle.json")
+	if _, err := run("config", "export", bundlePath); err != nil {
+		t.Fatalf("config export failed: %v", err)
+	}
+
+	var bundle ConfigBundle
+	if err := storage.ReadJSON(t.Context(), bundlePath, &bundle); err != nil {
+		t.Fatalf("Failed to read bundle: %v", err)
+	}
+	if bundle.Version != configBundleVersion || bundle.Config.Templates["bundletest"] != "{{.ID}}" {
+		t.Errorf("Unexpected bundle: %+v", bundle)
+	}
+	if wf := bundle.Workflows[projectKey]; wf == nil || !slices.Contains(wf.Statuses, "REVIEW") {
+		t.Fatalf("Expected workflow of %s in bundle, got %v", projectKey, bundle.Workflows)
+	}
+	if theme := bundle.Themes["bundletest"]; theme == nil || theme.Base != "light" {
+		t.Errorf("Expected the custom theme in bundle, got %v", bundle.Themes)
+	}
+	if views := (models.SavedViews{Views: bundle.Views}); views.Find("bundletest") == nil {
+		t.Errorf("Expected the global view in bundle, got %v", bundle.Views)
+	}
+	if views := bundle.ProjectViews[projectKey]; len(views) != 1 || views[0].Name != "open" {
+		t.Errorf("Expected the project view in bundle, got %v", bundle.ProjectViews)
+	}
+	if policy := bundle.Policies[projectKey]; policy == nil || policy.ArchiveDoneAfter != "60d" {
+		t.Errorf("Expected the retention policy in bundle, got %v", bundle.Policies)
+	}
+
+	// Change local state, then restore it from the bundle
+	if err := config.Set(t.Context(), "template.bundletest", ""); err != nil {
+		t.Fatalf("Failed to unset template: %v", err)
+	}
+	os.Remove(themePath)
+	for _, args := range [][]string{
+		{"view", "delete", "bundletest", "--global"},
+		{"view", "delete", "open", "--project", projectKey},
+		{"policy", "set", "--project", projectKey, "--archive-done-after", "off"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+	workflowPath, _ := storage.WorkflowPath(t.Context(), projectKey)
+	os.Remove(workflowPath)
+
+	output, err := run("config", "import", bundlePath, "--dry-run")
+	if err != nil {
+		t.Fatalf("config import --dry-run failed: %v", err)
+	}
+	for _, want := range []string{"Would set template.bundletest", "Would set themes/bundletest", "Would set view.bundletest", "Would set view.open of " + projectKey, "Would set policy of " + projectKey} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in dry-run output, got: %s", want, output)
+		}
+	}
+	if _, err := os.Stat(workflowPath); !os.IsNotExist(err) {
+		t.Error("Dry run should not write the workflow")
+	}
+
+	if _, err := run("config", "import", bundlePath); err != nil {
+		t.Fatalf("config import failed: %v", err)
+	}
+	if text, _ := config.LookupTemplate("bundletest"); text != "{{.ID}}" {
+		t.Errorf("Template not imported, got %q", text)
+	}
+	var wf models.Workflow
+	if err := storage.ReadJSON(t.Context(), workflowPath, &wf); err != nil || !slices.Contains(wf.Statuses, "REVIEW") {
+		t.Errorf("Workflow not imported: %v %+v", err, wf)
+	}
+	if _, err := os.Stat(themePath); err != nil {
+		t.Errorf("Theme not imported: %v", err)
+	}
+	if out, err := run("view", "list", "--project", projectKey); err != nil || !strings.Contains(out, "bundletest") || !strings.Contains(out, "open") {
+		t.Errorf("Views not imported, got: %s (%v)", out, err)
+	}
+	if policy, _ := loadPolicy(t.Context(), projectKey); policy == nil || policy.ArchiveDoneAfter != "60d" {
+		t.Errorf("Policy not imported, got %+v", policy)
+	}
+}
+
+func TestConfigImport_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name   string
+		bundle interface{}
+		errMsg string
+	}{
+		{"future version", map[string]interface{}{"version": 99}, "unsupported bundle version"},
+		{"invalid format", map[string]interface{}{"version": 1, "config": map[string]string{"default_format": "xml"}}, "invalid config"},
+		{"invalid key", map[string]interface{}{"version": 1, "workflows": map[string]interface{}{"../x": map[string]interface{}{}}}, "invalid project key"},
+		{"invalid theme", map[string]interface{}{"version": 1, "themes": map[string]interface{}{"../x": map[string]interface{}{}}}, "invalid theme"},
+		{"invalid view", map[string]interface{}{"version": 1, "views": []map[string]string{{"name": "bugs"}}}, "invalid views"},
+		{"invalid policy", map[string]interface{}{"version": 1, "policies": map[string]interface{}{"CORE": map[string]string{"archive_done_after": "soon"}}}, "invalid policy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := json.Marshal(tt.bundle)
+			path := filepath.Join(dir, sanitizeTestName(tt.name)+".json")
+			os.WriteFile(path, data, 0644)
+
+			cmd := NewConfigImportCmd()
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(new(bytes.Buffer))
+			err := importConfigBundle(path, cmd)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
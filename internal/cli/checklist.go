@@ -0,0 +1,235 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// NewIssueCheckCmd creates and returns the issue check command.
+func NewIssueCheckCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Manage the checklist of an issue",
+		Long: `Keep a checklist inside an issue, for steps too small to be issues of their
+own. Items are numbered from 1 in the order they were added. The progress
+(e.g. 3/5) is shown by issue view and list, and buyruk stats counts the
+checked off items of a project.`,
+	}
+
+	cmd.AddCommand(NewIssueCheckAddCmd())
+	cmd.AddCommand(NewIssueCheckDoneCmd())
+	cmd.AddCommand(NewIssueCheckUndoCmd())
+	cmd.AddCommand(NewIssueCheckRemoveCmd())
+
+	return cmd
+}
+
+// NewIssueCheckAddCmd creates and returns the issue check add command.
+func NewIssueCheckAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "add <id> <text>",
+		Short:             "Add an item to the checklist of an issue",
+		Example:           `  buyruk issue check add CORE-12 "write tests"`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeIssueArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveIssueArgs(cmd, args[:1]); err != nil {
+				return err
+			}
+			return addChecklistItem(args[0], args[1], cmd)
+		},
+	}
+
+	return cmd
+}
+
+// NewIssueCheckDoneCmd creates and returns the issue check done command.
+func NewIssueCheckDoneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "done <id> <n>",
+		Short:             "Check off a checklist item",
+		Example:           `  buyruk issue check done CORE-12 2`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeIssueArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveIssueArgs(cmd, args[:1]); err != nil {
+				return err
+			}
+			return setChecklistItemDone(args[0], args[1], true, cmd)
+		},
+	}
+
+	return cmd
+}
+
+// NewIssueCheckUndoCmd creates and returns the issue check undo command.
+func NewIssueCheckUndoCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "undo <id> <n>",
+		Short:             "Uncheck a checklist item",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeIssueArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveIssueArgs(cmd, args[:1]); err != nil {
+				return err
+			}
+			return setChecklistItemDone(args[0], args[1], false, cmd)
+		},
+	}
+
+	return cmd
+}
+
+// NewIssueCheckRemoveCmd creates and returns the issue check remove command.
+func NewIssueCheckRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "remove <id> <n>",
+		Short:             "Remove an item from the checklist of an issue",
+		Long:              "Remove an item from the checklist of an issue. The items after it move up a number.",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeIssueArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveIssueArgs(cmd, args[:1]); err != nil {
+				return err
+			}
+			return removeChecklistItem(args[0], args[1], cmd)
+		},
+	}
+
+	return cmd
+}
+
+// addChecklistItem adds an item to the end of the checklist of an issue.
+func addChecklistItem(issueID, text string, cmd *cobra.Command) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return invalidf("cli: checklist item text cannot be empty")
+	}
+	var number int
+	issue, err := updateChecklist(cmd.Context(), issueID, func(iss *models.Issue) error {
+		iss.Checklist = append(iss.Checklist, models.ChecklistItem{Text: text})
+		number = len(iss.Checklist)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	done, total := issue.ChecklistProgress()
+	fmt.Fprintf(successOut(cmd), "Added item %d to %s (%d/%d done)\n", number, issueID, done, total)
+	return nil
+}
+
+// setChecklistItemDone checks off or unchecks a checklist item.
+func setChecklistItemDone(issueID, arg string, done bool, cmd *cobra.Command) error {
+	n, err := parseChecklistNumber(arg)
+	if err != nil {
+		return err
+	}
+	issue, err := updateChecklist(cmd.Context(), issueID, func(iss *models.Issue) error {
+		item, err := checklistItem(iss, n)
+		if err != nil {
+			return err
+		}
+		item.Done = done
+		item.DoneAt = ""
+		if done {
+			item.DoneAt = storage.Timestamp(cmd.Context())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	verb := "Checked off"
+	if !done {
+		verb = "Unchecked"
+	}
+	doneCount, total := issue.ChecklistProgress()
+	fmt.Fprintf(successOut(cmd), "%s item %d of %s: %s (%d/%d done)\n", verb, n, issueID, issue.Checklist[n-1].Text, doneCount, total)
+	return nil
+}
+
+// removeChecklistItem removes an item from the checklist of an issue.
+func removeChecklistItem(issueID, arg string, cmd *cobra.Command) error {
+	n, err := parseChecklistNumber(arg)
+	if err != nil {
+		return err
+	}
+	var removed models.ChecklistItem
+	if _, err := updateChecklist(cmd.Context(), issueID, func(iss *models.Issue) error {
+		item, err := checklistItem(iss, n)
+		if err != nil {
+			return err
+		}
+		removed = *item
+		iss.Checklist = append(iss.Checklist[:n-1], iss.Checklist[n:]...)
+		if len(iss.Checklist) == 0 {
+			iss.Checklist = nil
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(successOut(cmd), "Removed item %d from %s: %s\n", n, issueID, removed.Text)
+	return nil
+}
+
+// parseChecklistNumber parses the 1-based number of a checklist item.
+func parseChecklistNumber(arg string) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 {
+		return 0, invalidf("cli: invalid checklist item number %q (items are numbered from 1)", arg)
+	}
+	return n, nil
+}
+
+// checklistItem returns the checklist item of an issue with a 1-based number.
+func checklistItem(issue *models.Issue, n int) (*models.ChecklistItem, error) {
+	if n > len(issue.Checklist) {
+		return nil, notFoundf("cli: %s has no checklist item %d (it has %d)", issue.ID, n, len(issue.Checklist))
+	}
+	return &issue.Checklist[n-1], nil
+}
+
+// updateChecklist applies a change to the checklist of an issue and returns
+// the updated issue.
+func updateChecklist(ctx context.Context, issueID string, update func(iss *models.Issue) error) (*models.Issue, error) {
+	projectKey, _, err := parseIssueID(ctx, issueID)
+	if err != nil {
+		return nil, invalidf("cli: invalid issue ID %q: %w", issueID, err)
+	}
+	issuePath, err := storage.IssuePath(ctx, projectKey, issueID)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve issue path: %w", err)
+	}
+
+	var updateErr error
+	var issue models.Issue
+	if err := storage.UpdateJSONAtomic(ctx, issuePath, &issue, func(v interface{}) error {
+		iss := v.(*models.Issue)
+		if iss.ID == "" || iss.ID != issueID {
+			updateErr = notFoundf("cli: issue %q not found", issueID)
+			return updateErr
+		}
+		if updateErr = update(iss); updateErr != nil {
+			return updateErr
+		}
+		iss.UpdatedAt = storage.Timestamp(ctx)
+		return nil
+	}); err != nil {
+		if updateErr != nil {
+			return nil, updateErr
+		}
+		return nil, fmt.Errorf("cli: failed to update issue: %w", err)
+	}
+	return &issue, nil
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestIssueChecklist(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	id := projectKey + "-1"
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "Release"},
+		{"issue", "create", "--project", projectKey, "--title", "No checklist"},
+		{"issue", "check", "add", id, "write tests"},
+		{"issue", "check", "add", id, "update docs"},
+		{"issue", "check", "add", id, "tag release"},
+		{"issue", "check", "add", id, "announce"},
+		{"issue", "check", "done", id, "1"},
+		{"issue", "check", "done", id, "3"},
+		{"issue", "check", "undo", id, "3"},
+		{"issue", "check", "done", id, "2"},
+		{"issue", "check", "remove", id, "4"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	issue, err := loadLocalIssue(t.Context(), projectKey, id)
+	if err != nil || issue == nil {
+		t.Fatalf("Failed to load issue: %v", err)
+	}
+	if done, total := issue.ChecklistProgress(); done != 2 || total != 3 {
+		t.Errorf("Expected 2/3 items done, got %d/%d", done, total)
+	}
+	if !issue.Checklist[1].Done || issue.Checklist[1].DoneAt == "" || issue.Checklist[2].Done {
+		t.Errorf("Unexpected checklist: %+v", issue.Checklist)
+	}
+
+	out, err := run("issue", "view", id, "--format", "lson")
+	if err != nil {
+		t.Fatalf("issue view failed: %v", err)
+	}
+	for _, want := range []string{"@CHECKLIST: 2/3", "@CHECK: 1|x|write tests", "@CHECK: 3||tag release"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in issue view, got:\n%s", want, out)
+		}
+	}
+
+	out, err = run("list", "--project", projectKey, "--format", "modern")
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if !strings.Contains(out, "Release (2/3)") || strings.Contains(out, "No checklist (") {
+		t.Errorf("Expected checklist progress in the list, got:\n%s", out)
+	}
+
+	out, err = run("stats", projectKey, "--format", "json")
+	if err != nil {
+		t.Fatalf("stats failed: %v", err)
+	}
+	var stats ProjectStats
+	if err := json.Unmarshal([]byte(out), &stats); err != nil {
+		t.Fatalf("Failed to parse stats: %v\n%s", err, out)
+	}
+	if stats.Checklists != (StatsChecklist{Issues: 1, Items: 3, Done: 2}) {
+		t.Errorf("Unexpected checklist stats: %+v", stats.Checklists)
+	}
+
+	for _, args := range [][]string{
+		{"issue", "check", "done", id, "4"},
+		{"issue", "check", "done", id, "0"},
+		{"issue", "check", "add", id, "  "},
+		{"issue", "check", "add", projectKey + "-99", "nope"},
+	} {
+		if _, err := run(args...); err == nil {
+			t.Errorf("Expected %v to fail", args)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// NewCompletionCmd creates and returns the completion command.
+func NewCompletionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "completion bash|zsh|fish|powershell",
+		Short: "Generate shell completion scripts",
+		Long: `Generate a shell completion script. Issue IDs, epic IDs, and project keys
+are completed from the local data.
+
+  bash:       source <(buyruk completion bash)
+  zsh:        buyruk completion zsh > "${fpath[1]}/_buyruk"
+  fish:       buyruk completion fish > ~/.config/fish/completions/buyruk.fish
+  powershell: buyruk completion powershell | Out-String | Invoke-Expression`,
+		Args:                  cobra.ExactArgs(1),
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return generateCompletion(cmd, args[0])
+		},
+	}
+
+	return cmd
+}
+
+// generateCompletion writes the completion script for a shell.
+func generateCompletion(cmd *cobra.Command, shell string) error {
+	root := cmd.Root()
+	out := cmd.OutOrStdout()
+	switch shell {
+	case "bash":
+		return root.GenBashCompletionV2(out, true)
+	case "zsh":
+		return root.GenZshCompletion(out)
+	case "fish":
+		return root.GenFishCompletion(out, true)
+	case "powershell":
+		return root.GenPowerShellCompletionWithDesc(out)
+	default:
+		return fmt.Errorf("cli: unsupported shell %q (use bash, zsh, fish, or powershell)", shell)
+	}
+}
+
+// completeIssueArgs returns a ValidArgsFunction that completes issue IDs
+// for the first n positional arguments.
+func completeIssueArgs(n int) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) >= n {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeIssueIDs(cmd, args, toComplete)
+	}
+}
+
+// completeIssueIDs completes issue IDs with their titles.
+// A typed project prefix ("CORE-") selects the project; otherwise the --project flag
+// or default project is used, falling back to every project.
+func completeIssueIDs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	var projectKeys []string
+	if key, _, ok := strings.Cut(toComplete, "-"); ok {
+		projectKeys = []string{key}
+	} else if key, err := config.ResolveProject(cmd); err == nil {
+		projectKeys = []string{key}
+	} else if keys, err := storage.ListProjects(cmd.Context()); err == nil {
+		projectKeys = keys
+	}
+
+	var completions []cobra.Completion
+	for _, projectKey := range projectKeys {
+		indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
+		if err != nil {
+			continue
+		}
+		var index models.ProjectIndex
+		if err := storage.ReadIndex(cmd.Context(), indexPath, &index); err != nil {
+			continue
+		}
+		for _, entry := range index.Issues {
+			if strings.HasPrefix(entry.ID, toComplete) {
+				completions = append(completions, cobra.CompletionWithDesc(entry.ID, entry.Title))
+			}
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeEpicArgs completes epic IDs of the resolved project for the first positional argument.
+func completeEpicArgs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeEpicIDs(cmd, args, toComplete)
+}
+
+// completeEpicIDs completes epic IDs of the resolved project with their titles.
+func completeEpicIDs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	epicsDir, err := storage.EpicsDir(cmd.Context(), projectKey)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	files, err := os.ReadDir(epicsDir)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []cobra.Completion
+	for _, file := range files {
+		epicID, ok := strings.CutSuffix(file.Name(), ".json")
+		if !ok || !strings.HasPrefix(epicID, toComplete) {
+			continue
+		}
+		var epic models.Epic
+		if err := storage.ReadJSON(cmd.Context(), filepath.Join(epicsDir, file.Name()), &epic); err != nil {
+			continue
+		}
+		completions = append(completions, cobra.CompletionWithDesc(epicID, epic.Title))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSprintArgs completes a sprint ID for the first positional argument.
+func completeSprintArgs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeSprintIDs(cmd, args, toComplete)
+}
+
+// completeSprintIDs completes sprint IDs of the resolved project with their dates.
+func completeSprintIDs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	sprintsPath, err := storage.SprintsPath(cmd.Context(), projectKey)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var sprints models.Sprints
+	if err := storage.ReadJSON(cmd.Context(), sprintsPath, &sprints); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []cobra.Completion
+	for _, sprint := range sprints.Sprints {
+		if strings.HasPrefix(sprint.ID, toComplete) {
+			completions = append(completions, cobra.CompletionWithDesc(sprint.ID, sprint.Start+" to "+sprint.End))
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeMilestoneArgs completes a milestone ID for the first positional
+// argument.
+func completeMilestoneArgs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeMilestoneIDs(cmd, args, toComplete)
+}
+
+// completeMilestoneIDs completes milestone IDs of the resolved project with
+// their status.
+func completeMilestoneIDs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	milestonesPath, err := storage.MilestonesPath(cmd.Context(), projectKey)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var milestones models.Milestones
+	if err := storage.ReadJSON(cmd.Context(), milestonesPath, &milestones); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []cobra.Completion
+	for _, milestone := range milestones.Milestones {
+		if strings.HasPrefix(milestone.ID, toComplete) {
+			completions = append(completions, cobra.CompletionWithDesc(milestone.ID, milestone.Status))
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeComponentArgs completes a component name for the first positional
+// argument.
+func completeComponentArgs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeComponentNames(cmd, args, toComplete)
+}
+
+// completeComponentNames completes component names of the resolved project
+// with their descriptions.
+func completeComponentNames(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	componentsPath, err := storage.ComponentsPath(cmd.Context(), projectKey)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var components models.Components
+	if err := storage.ReadJSON(cmd.Context(), componentsPath, &components); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []cobra.Completion
+	for _, component := range components.Components {
+		if strings.HasPrefix(component.Name, toComplete) {
+			completions = append(completions, cobra.CompletionWithDesc(component.Name, component.Description))
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeViewArgs completes a saved view name for the first positional
+// argument, from the project and global views.
+func completeViewArgs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	views, err := loadScopedViews(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []cobra.Completion
+	for _, view := range views {
+		if strings.HasPrefix(view.Name, toComplete) {
+			completions = append(completions, cobra.CompletionWithDesc(view.Name, view.Query))
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeAliasArgs completes alias names for the first positional argument.
+func completeAliasArgs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	cfg, err := config.Get()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []cobra.Completion
+	for _, name := range sortedKeys(cfg.Aliases) {
+		if strings.HasPrefix(name, toComplete) {
+			completions = append(completions, cobra.CompletionWithDesc(name, cfg.Aliases[name]))
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProjectArgs completes project keys for the first positional argument.
+func completeProjectArgs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeProjectKeys(cmd, args, toComplete)
+}
+
+// completeProjectKeys completes existing project keys with their names.
+func completeProjectKeys(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	keys, err := storage.ListProjects(cmd.Context())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []cobra.Completion
+	for _, key := range keys {
+		if !strings.HasPrefix(key, strings.ToUpper(toComplete)) {
+			continue
+		}
+		name := ""
+		if indexPath, err := storage.ProjectIndexPath(cmd.Context(), key); err == nil {
+			var index models.ProjectIndex
+			if storage.ReadIndex(cmd.Context(), indexPath, &index) == nil {
+				name = index.ProjectName
+			}
+		}
+		completions = append(completions, cobra.CompletionWithDesc(key, name))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeFormats completes the --format flag.
+func completeFormats(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	return []cobra.Completion{
+		config.DefaultFormatModern,
+		config.DefaultFormatJSON,
+		config.DefaultFormatLSON,
+		config.DefaultFormatNDJSON,
+		config.DefaultFormatYAML,
+	}, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeLinkTypes completes the --type flag of issue link.
+func completeLinkTypes(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	return models.LinkTypes, cobra.ShellCompDirectiveNoFileComp
+}
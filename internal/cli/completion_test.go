@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+// runCompletion runs cobra's hidden __complete command and returns the completion lines.
+func runCompletion(t *testing.T, args ...string) []string {
+	t.Helper()
+	cmd := NewRootCmd()
+	cmd.SetArgs(append([]string{"__complete"}, args...))
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("__complete %v failed: %v", args, err)
+	}
+
+	// The last line is the shell directive (":4")
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	return lines[:len(lines)-1]
+}
+
+func TestCompletion_Scripts(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		cmd := NewRootCmd()
+		cmd.SetArgs([]string{"completion", shell})
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("completion %s failed: %v", shell, err)
+		}
+		if !strings.Contains(out.String(), "buyruk") {
+			t.Errorf("completion %s output does not mention buyruk", shell)
+		}
+	}
+}
+
+func TestCompletion_IssueAndProjectIDs(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	for _, args := range [][]string{
+		{"project", "create", projectKey, "--name", "Completion"},
+		{"issue", "create", "--project", projectKey, "--title", "First"},
+		{"issue", "create", "--project", projectKey, "--title", "Second"},
+		{"epic", "create", "--project", projectKey, "--id", projectKey + "-E1", "--title", "Epic one"},
+	} {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		cmd.SetOut(new(bytes.Buffer))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	got := runCompletion(t, "issue", "update", projectKey+"-")
+	want := []string{projectKey + "-1\tFirst", projectKey + "-2\tSecond"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("issue update completions = %q, want %q", got, want)
+	}
+
+	// The project flag selects the project when no prefix is typed; view
+	// also offers its saved-view subcommands
+	got = runCompletion(t, "view", "--project", projectKey, "")
+	if len(got) != 6 || got[4] != want[0] || got[5] != want[1] {
+		t.Errorf("view completions = %q, want 4 subcommands and 2 issue IDs", got)
+	}
+
+	// Only the first argument of update is an issue ID
+	if got := runCompletion(t, "issue", "update", projectKey+"-1", ""); len(got) != 0 {
+		t.Errorf("Expected no completions for a second argument, got %q", got)
+	}
+
+	got = runCompletion(t, "list", "--project", projectKey[:len(projectKey)-1])
+	found := false
+	for _, line := range got {
+		if line == projectKey+"\tCompletion" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("--project completions %q missing %s", got, projectKey)
+	}
+
+	got = runCompletion(t, "epic", "view", "--project", projectKey, projectKey+"-E")
+	if strings.Join(got, "|") != projectKey+"-E1\tEpic one" {
+		t.Errorf("epic view completions = %q", got)
+	}
+}
@@ -0,0 +1,291 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// ComponentWithIssues is a component with the number of issues in it, for
+// component list.
+type ComponentWithIssues struct {
+	models.Component
+	Issues int `json:"issues"`
+}
+
+// NewComponentCmd creates and returns the component command.
+func NewComponentCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "component",
+		Short: "Manage the components issues are filed under",
+		Long: `Register the components (areas) of a project's codebase, such as "ui" or
+"api/auth". Issues are filed under registered components only (issue create
+--components ui,api), can be listed by component (list --component ui, or
+"component=ui" in query filters), and are assigned to the component's owner
+when created without --assignee.
+
+Components are stored in projects/[KEY]/components.json.`,
+	}
+
+	cmd.AddCommand(NewComponentAddCmd())
+	cmd.AddCommand(NewComponentListCmd())
+	cmd.AddCommand(NewComponentRemoveCmd())
+
+	return cmd
+}
+
+// NewComponentAddCmd creates and returns the component add command.
+func NewComponentAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Register a component, or change its description or owner",
+		Example: `  buyruk component add ui --owner alice --description "Web frontend"
+  buyruk component add api/auth --owner none`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeComponentArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return addComponent(args[0], cmd)
+		},
+	}
+
+	cmd.Flags().String("description", "", "What the component covers")
+	cmd.Flags().String("owner", "", "User new issues of the component are assigned to (\"none\" clears it)")
+
+	return cmd
+}
+
+// addComponent registers a component or updates its description and owner.
+func addComponent(name string, cmd *cobra.Command) error {
+	if err := models.ValidateComponentName(name); err != nil {
+		return invalidf("cli: %w", err)
+	}
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	if _, err := loadComponents(cmd.Context(), projectKey); err != nil {
+		return err
+	}
+	owner, _ := cmd.Flags().GetString("owner")
+	if owner != "" && owner != "none" {
+		if err := models.ValidateUser(owner); err != nil {
+			return invalidf("cli: %w", err)
+		}
+	}
+	description, _ := cmd.Flags().GetString("description")
+
+	componentsPath, err := storage.ComponentsPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve components path: %w", err)
+	}
+
+	added := false
+	if err := storage.UpdateJSONAtomic(cmd.Context(), componentsPath, &models.Components{}, func(v interface{}) error {
+		c := v.(*models.Components)
+		component := models.Component{Name: name}
+		if existing := c.Find(name); existing != nil {
+			component = *existing
+		} else {
+			added = true
+		}
+		if cmd.Flags().Changed("description") {
+			component.Description = description
+		}
+		if owner == "none" {
+			component.Owner = ""
+		} else if owner != "" {
+			component.Owner = owner
+		}
+		c.Set(component)
+		return c.Validate()
+	}); err != nil {
+		return fmt.Errorf("cli: failed to update components: %w", err)
+	}
+
+	if added {
+		fmt.Fprintf(successOut(cmd), "Added component %s\n", name)
+	} else {
+		fmt.Fprintf(successOut(cmd), "Updated component %s\n", name)
+	}
+	return nil
+}
+
+// NewComponentListCmd creates and returns the component list command.
+func NewComponentListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the components of a project with their issue counts",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listComponents(cmd)
+		},
+	}
+
+	return cmd
+}
+
+// listComponents renders the components of a project in the resolved output
+// format.
+func listComponents(cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	components, err := loadComponents(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	index, err := loadQueryIndex(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+
+	list := make([]ComponentWithIssues, len(components.Components))
+	for i, component := range components.Components {
+		list[i].Component = component
+		for _, entry := range index.Issues {
+			if slices.Contains(entry.Components, component.Name) {
+				list[i].Issues++
+			}
+		}
+	}
+
+	w := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(list)
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(w, list)
+	case config.DefaultFormatLSON:
+		for _, c := range list {
+			fmt.Fprintf(w, "@COMPONENT: %s|%s|%d\n", c.Name, c.Owner, c.Issues)
+		}
+	default:
+		if len(list) == 0 {
+			fmt.Fprintf(w, "No components (add one with `buyruk component add ui --owner alice`)\n")
+			return nil
+		}
+		styles := ui.NewStyles()
+		for _, c := range list {
+			line := fmt.Sprintf("%s  %d issue(s)", styles.ID(c.Name), c.Issues)
+			if c.Owner != "" {
+				line += "  owner " + c.Owner
+			}
+			if c.Description != "" {
+				line += "  " + styles.Title(c.Description)
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+	return nil
+}
+
+// NewComponentRemoveCmd creates and returns the component remove command.
+func NewComponentRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Unregister a component",
+		Long: `Unregister a component. A component issues are still filed under is only
+removed with --force; the issues keep it until they are updated.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeComponentArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return removeComponent(args[0], cmd)
+		},
+	}
+
+	cmd.Flags().Bool("force", false, "Remove the component even if issues are filed under it")
+
+	return cmd
+}
+
+// removeComponent unregisters a component.
+func removeComponent(name string, cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	if force, _ := cmd.Flags().GetBool("force"); !force {
+		index, err := loadQueryIndex(cmd.Context(), projectKey)
+		if err != nil {
+			return err
+		}
+		for _, entry := range index.Issues {
+			if slices.Contains(entry.Components, name) {
+				return invalidf("cli: issues such as %s are filed under component %s (update them first, or use --force)", entry.ID, name)
+			}
+		}
+	}
+
+	componentsPath, err := storage.ComponentsPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve components path: %w", err)
+	}
+	if _, err := os.Stat(componentsPath); os.IsNotExist(err) {
+		return notFoundf("cli: component %q not found", name)
+	}
+	if err := storage.UpdateJSONAtomic(cmd.Context(), componentsPath, &models.Components{}, func(v interface{}) error {
+		c := v.(*models.Components)
+		if c.Find(name) == nil {
+			return notFoundf("cli: component %q not found", name)
+		}
+		c.Components = slices.DeleteFunc(c.Components, func(component models.Component) bool { return component.Name == name })
+		return nil
+	}); err != nil {
+		return fmt.Errorf("cli: failed to update components: %w", err)
+	}
+
+	fmt.Fprintf(successOut(cmd), "Removed component %s\n", name)
+	return nil
+}
+
+// validateComponents checks that components are registered in a project, and
+// returns the registry.
+func validateComponents(ctx context.Context, projectKey string, names []string) (*models.Components, error) {
+	components, err := loadComponents(ctx, projectKey)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if err := models.ValidateComponentName(name); err != nil {
+			return nil, invalidf("cli: %w", err)
+		}
+		if components.Find(name) == nil {
+			return nil, notFoundf("cli: component %q is not registered in %s (add it with `buyruk component add %s`)", name, projectKey, name)
+		}
+	}
+	return components, nil
+}
+
+// loadComponents loads the components of a project. Projects without
+// components have none.
+func loadComponents(ctx context.Context, projectKey string) (*models.Components, error) {
+	projectDir, err := storage.ProjectDir(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve project directory: %w", err)
+	}
+	if _, err := os.Stat(projectDir); os.IsNotExist(err) {
+		return nil, notFoundf("cli: project %q does not exist", projectKey)
+	}
+
+	componentsPath, err := storage.ComponentsPath(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve components path: %w", err)
+	}
+	components := &models.Components{Components: []models.Component{}}
+	if err := storage.ReadJSON(ctx, componentsPath, components); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("cli: failed to load components: %w", err)
+	}
+	return components, nil
+}
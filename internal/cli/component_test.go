@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestComponent(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	id := func(n string) string { return projectKey + "-" + n }
+	listed := func(args ...string) string {
+		t.Helper()
+		out, err := run(append([]string{"list", "--project", projectKey, "--sort", "id", "--template", "{{.ID}}"}, args...)...)
+		if err != nil {
+			t.Fatalf("list failed: %v", err)
+		}
+		return strings.ReplaceAll(strings.Join(strings.Fields(out), " "), projectKey+"-", "")
+	}
+
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"component", "add", "ui", "--project", projectKey, "--owner", "alice"},
+		{"component", "add", "api/auth", "--project", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "Login form", "--components", "ui,api/auth"},
+		{"issue", "create", "--project", projectKey, "--title", "Token refresh", "--components", "api/auth", "--assignee", "bob"},
+		{"issue", "create", "--project", projectKey, "--title", "Docs"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	if _, err := run("issue", "create", "--project", projectKey, "--title", "Bad", "--components", "db"); err == nil {
+		t.Error("Expected an unregistered component to be refused")
+	}
+	issue, err := loadLocalIssue(t.Context(), projectKey, id("1"))
+	if err != nil {
+		t.Fatalf("Failed to load issue: %v", err)
+	}
+	if issue.Assignee != "alice" {
+		t.Errorf("Expected the owner of ui to be assigned, got %q", issue.Assignee)
+	}
+
+	if got := listed("--component", "api/auth"); got != "1 2" {
+		t.Errorf("Expected issues 1 and 2 in api/auth, got %q", got)
+	}
+	if got := listed("--component", "none"); got != "3" {
+		t.Errorf("Expected issue 3 without components, got %q", got)
+	}
+
+	for _, args := range [][]string{
+		{"issue", "update", id("1"), "components-=api/auth"},
+		{"issue", "update", id("3"), "--add-component", "ui"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+	if got := listed("--component", "ui"); got != "1 3" {
+		t.Errorf("Expected issues 1 and 3 in ui, got %q", got)
+	}
+
+	out, err := run("component", "list", "--project", projectKey, "--format", "lson")
+	if err != nil {
+		t.Fatalf("component list failed: %v", err)
+	}
+	if want := "@COMPONENT: api/auth||1\n@COMPONENT: ui|alice|2\n"; out != want {
+		t.Errorf("Expected component list %q, got %q", want, out)
+	}
+
+	if _, err := run("component", "remove", "ui", "--project", projectKey); err == nil {
+		t.Error("Expected removing a component in use to fail")
+	}
+	if _, err := run("component", "remove", "ui", "--project", projectKey, "--force"); err != nil {
+		t.Fatalf("component remove failed: %v", err)
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/buyruk-project/buyruk-cli/internal/config"
 	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 )
@@ -23,6 +26,10 @@ func NewConfigCmd() *cobra.Command {
 	cmd.AddCommand(NewConfigGetCmd())
 	cmd.AddCommand(NewConfigSetCmd())
 	cmd.AddCommand(NewConfigListCmd())
+	cmd.AddCommand(NewConfigExportCmd())
+	cmd.AddCommand(NewConfigImportCmd())
+	cmd.AddCommand(NewConfigThemesCmd())
+	cmd.AddCommand(NewConfigAliasCmd())
 
 	return cmd
 }
@@ -74,6 +81,101 @@ func NewConfigListCmd() *cobra.Command {
 	return cmd
 }
 
+// NewConfigThemesCmd creates and returns the config themes command.
+func NewConfigThemesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "themes",
+		Short: "List color themes",
+		Long: `List the built-in color themes and the custom themes in the themes/
+directory of the config directory. Select one with config set theme <name>;
+auto (the default) picks dark or light from the COLORFGBG variable.
+
+A custom theme is a JSON file that starts from a base theme and overrides
+some of its colors:
+
+  {"base": "light", "id": "#005f87", "statuses": {"REVIEW": "magenta"}}`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listThemes(cmd)
+		},
+	}
+
+	return cmd
+}
+
+// ThemeInfo describes an available color theme.
+type ThemeInfo struct {
+	Name    string `json:"name"`
+	Builtin bool   `json:"builtin"`
+	Active  bool   `json:"active"`
+}
+
+// listThemes lists the built-in and custom color themes.
+func listThemes(cmd *cobra.Command) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("cli: failed to load config: %w", err)
+	}
+	active := cfg.Theme
+	if active == "" {
+		active = config.ThemeAuto
+	}
+
+	themes := []ThemeInfo{{Name: config.ThemeAuto, Builtin: true}}
+	for _, name := range ui.BuiltinThemeNames() {
+		themes = append(themes, ThemeInfo{Name: name, Builtin: true})
+	}
+	themesDir, err := storage.ThemesDir()
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve themes directory: %w", err)
+	}
+	entries, err := os.ReadDir(themesDir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("cli: failed to read themes directory: %w", err)
+	}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() || config.IsBuiltinTheme(name) {
+			continue
+		}
+		themes = append(themes, ThemeInfo{Name: name})
+	}
+	for i := range themes {
+		themes[i].Active = themes[i].Name == active
+	}
+
+	out := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(themes); err != nil {
+			return fmt.Errorf("cli: failed to encode JSON: %w", err)
+		}
+	case "lson":
+		for _, theme := range themes {
+			fmt.Fprintf(out, "@THEME: %s\n@BUILTIN: %t\n@ACTIVE: %t\n\n", theme.Name, theme.Builtin, theme.Active)
+		}
+	case "yaml":
+		if err := ui.EncodeYAML(out, themes); err != nil {
+			return fmt.Errorf("cli: failed to encode YAML: %w", err)
+		}
+	default: // modern
+		for _, theme := range themes {
+			marker := " "
+			if theme.Active {
+				marker = "*"
+			}
+			kind := "custom"
+			if theme.Builtin {
+				kind = "built-in"
+			}
+			fmt.Fprintf(out, "%s %-14s %s\n", marker, theme.Name, kind)
+		}
+	}
+	return nil
+}
+
 // getConfig gets a configuration value and displays it.
 func getConfig(key string, cmd *cobra.Command) error {
 	value, err := config.GetValue(key)
@@ -96,6 +198,10 @@ func getConfig(key string, cmd *cobra.Command) error {
 		}
 	case "lson":
 		fmt.Fprintf(out, "@%s: %s\n", strings.ToUpper(key), value)
+	case "yaml":
+		if err := ui.EncodeYAML(out, map[string]string{key: value}); err != nil {
+			return fmt.Errorf("cli: failed to encode YAML: %w", err)
+		}
 	default: // modern
 		if value == "" {
 			fmt.Fprintf(out, "%s: (not set)\n", key)
@@ -110,13 +216,13 @@ func getConfig(key string, cmd *cobra.Command) error {
 // setConfig sets a configuration value.
 func setConfig(key, value string, cmd *cobra.Command) error {
 	// Set config value (config.Set() handles all validation)
-	if err := config.Set(key, value); err != nil {
-		return fmt.Errorf("cli: failed to set config: %w", err)
+	if err := config.Set(cmd.Context(), key, value); err != nil {
+		return invalidf("cli: failed to set config: %w", err)
 	}
 
 	// CLI-specific: warn if setting default_project to non-existent project
 	if key == "default_project" && value != "" {
-		projectDir, err := storage.ProjectDir(value)
+		projectDir, err := storage.ProjectDir(cmd.Context(), value)
 		if err == nil {
 			if _, err := os.Stat(projectDir); os.IsNotExist(err) {
 				errOut := cmd.ErrOrStderr()
@@ -126,7 +232,7 @@ func setConfig(key, value string, cmd *cobra.Command) error {
 	}
 
 	// Success message
-	out := cmd.OutOrStdout()
+	out := successOut(cmd)
 	fmt.Fprintf(out, "Set %s = %s\n", key, value)
 
 	return nil
@@ -156,6 +262,42 @@ func listConfig(cmd *cobra.Command) error {
 		if cfg.DefaultFormat != "" {
 			fmt.Fprintf(out, "@DEFAULT_FORMAT: %s\n", cfg.DefaultFormat)
 		}
+		if cfg.Timezone != "" {
+			fmt.Fprintf(out, "@TIMEZONE: %s\n", cfg.Timezone)
+		}
+		fmt.Fprintf(out, "@MAX_ISSUES: %d\n", cfg.IssueLimit())
+		fmt.Fprintf(out, "@MAX_INDEX_SIZE: %d\n", cfg.IndexSizeLimit())
+		fmt.Fprintf(out, "@AUTOCOMMIT: %t\n", cfg.Autocommit)
+		fmt.Fprintf(out, "@USAGE_LOG: %t\n", cfg.UsageLog)
+		fmt.Fprintf(out, "@BRANCH_TEMPLATE: %s\n", cfg.BranchNameTemplate())
+		if cfg.Theme != "" {
+			fmt.Fprintf(out, "@THEME: %s\n", cfg.Theme)
+		}
+		if cfg.DataDir != "" {
+			fmt.Fprintf(out, "@DATA_DIR: %s\n", cfg.DataDir)
+		}
+		fmt.Fprintf(out, "@DURABILITY: %s\n", cfg.DurabilityLevel())
+		fmt.Fprintf(out, "@EPIC_STATUS: %s\n", cfg.EpicStatusMode())
+		fmt.Fprintf(out, "@ESCALATE_ACTION: %s\n", cfg.EscalateActionMode())
+		for _, priority := range sortedKeys(cfg.Escalate) {
+			fmt.Fprintf(out, "@ESCALATE.%s: %s\n", strings.ToUpper(priority), cfg.Escalate[priority])
+		}
+		if cfg.NotifyCommand != "" {
+			fmt.Fprintf(out, "@NOTIFY_COMMAND: %s\n", cfg.NotifyCommand)
+		}
+		for _, name := range sortedKeys(cfg.Templates) {
+			fmt.Fprintf(out, "@TEMPLATE.%s: %s\n", strings.ToUpper(name), cfg.Templates[name])
+		}
+		for _, name := range sortedKeys(cfg.Aliases) {
+			fmt.Fprintf(out, "@ALIAS.%s: %s\n", strings.ToUpper(name), cfg.Aliases[name])
+		}
+		for _, entry := range projectDefaultEntries(cfg) {
+			fmt.Fprintf(out, "@%s: %s\n", strings.ToUpper(entry[0]), entry[1])
+		}
+	case "yaml":
+		if err := ui.EncodeYAML(out, cfg); err != nil {
+			return fmt.Errorf("cli: failed to encode YAML: %w", err)
+		}
 	default: // modern
 		// Use table for modern format
 		table := tablewriter.NewWriter(out)
@@ -177,8 +319,105 @@ func listConfig(cmd *cobra.Command) error {
 			table.Append([]string{"default_format", "modern"})
 		}
 
+		if cfg.Timezone != "" {
+			table.Append([]string{"timezone", cfg.Timezone})
+		} else {
+			table.Append([]string{"timezone", "(local)"})
+		}
+
+		table.Append([]string{"max_issues", strconv.Itoa(cfg.IssueLimit())})
+		table.Append([]string{"max_index_size", formatSize(cfg.IndexSizeLimit())})
+		table.Append([]string{"autocommit", strconv.FormatBool(cfg.Autocommit)})
+		table.Append([]string{"usage_log", strconv.FormatBool(cfg.UsageLog)})
+		table.Append([]string{"branch_template", cfg.BranchNameTemplate()})
+		if cfg.UserName != "" {
+			table.Append([]string{"user.name", cfg.UserName})
+		} else {
+			table.Append([]string{"user.name", "(from git)"})
+		}
+		if cfg.UserEmail != "" {
+			table.Append([]string{"user.email", cfg.UserEmail})
+		} else {
+			table.Append([]string{"user.email", "(from git)"})
+		}
+		if cfg.DefaultRemote != "" {
+			table.Append([]string{"default_remote", cfg.DefaultRemote})
+		} else {
+			table.Append([]string{"default_remote", "(not set)"})
+		}
+		if cfg.StorageBackend != "" {
+			table.Append([]string{"storage_backend", cfg.StorageBackend})
+		} else {
+			table.Append([]string{"storage_backend", "(not set)"})
+		}
+		if cfg.DataDir != "" {
+			table.Append([]string{"data_dir", cfg.DataDir})
+		} else {
+			table.Append([]string{"data_dir", "(config directory)"})
+		}
+		table.Append([]string{"durability", cfg.DurabilityLevel()})
+		table.Append([]string{"epic_status", cfg.EpicStatusMode()})
+		table.Append([]string{"escalate_action", cfg.EscalateActionMode()})
+		for _, priority := range sortedKeys(cfg.Escalate) {
+			table.Append([]string{config.EscalateKeyPrefix + priority, cfg.Escalate[priority]})
+		}
+		if cfg.NotifyCommand != "" {
+			table.Append([]string{"notify_command", cfg.NotifyCommand})
+		} else {
+			table.Append([]string{"notify_command", "(desktop notifications)"})
+		}
+		if cfg.Theme != "" {
+			table.Append([]string{"theme", cfg.Theme})
+		} else {
+			table.Append([]string{"theme", config.ThemeAuto})
+		}
+
+		for _, name := range sortedKeys(cfg.Templates) {
+			table.Append([]string{config.TemplateKeyPrefix + name, cfg.Templates[name]})
+		}
+		for _, name := range sortedKeys(cfg.Aliases) {
+			table.Append([]string{config.AliasKeyPrefix + name, cfg.Aliases[name]})
+		}
+		for _, entry := range projectDefaultEntries(cfg) {
+			table.Append(entry[:])
+		}
+
 		table.Render()
+		if local, err := config.LoadLocal(); err == nil && local != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Note: %s overrides the default project and format here\n", local.Path)
+		}
 	}
 
 	return nil
 }
+
+// projectDefaultEntries returns the per-project issue defaults as config
+// keys and values, by project key.
+func projectDefaultEntries(cfg *config.Config) [][2]string {
+	projectKeys := make([]string, 0, len(cfg.ProjectDefaults))
+	for projectKey := range cfg.ProjectDefaults {
+		projectKeys = append(projectKeys, projectKey)
+	}
+	sort.Strings(projectKeys)
+
+	var entries [][2]string
+	for _, projectKey := range projectKeys {
+		defaults := cfg.ProjectDefaults[projectKey]
+		for _, field := range config.IssueDefaultFields {
+			if value := defaults.Value(field); value != "" {
+				entries = append(entries, [2]string{config.ProjectKeyPrefix + projectKey + "." + field, value})
+			}
+		}
+	}
+	return entries
+}
+
+// sortedKeys returns the keys of a string map in sorted order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -51,12 +51,12 @@ func TestConfigGet_ValidKey(t *testing.T) {
 	originalCfg, _ := config.Get()
 	defer func() {
 		if originalCfg != nil {
-			config.Save(originalCfg)
+			config.Save(t.Context(), originalCfg)
 		}
 	}()
 
 	// Set a test value
-	if err := config.Set("default_format", "json"); err != nil {
+	if err := config.Set(t.Context(), "default_format", "json"); err != nil {
 		t.Fatalf("Failed to set config: %v", err)
 	}
 
@@ -99,14 +99,14 @@ func TestConfigGet_UnsetValue(t *testing.T) {
 	originalCfg, _ := config.Get()
 	defer func() {
 		if originalCfg != nil {
-			config.Save(originalCfg)
+			config.Save(t.Context(), originalCfg)
 		}
 	}()
 
 	// Clear default_project
 	cfg, _ := config.Get()
 	cfg.DefaultProject = ""
-	if err := config.Save(cfg); err != nil {
+	if err := config.Save(t.Context(), cfg); err != nil {
 		t.Fatalf("Failed to clear config: %v", err)
 	}
 
@@ -132,12 +132,12 @@ func TestConfigGet_JSONFormat(t *testing.T) {
 	originalCfg, _ := config.Get()
 	defer func() {
 		if originalCfg != nil {
-			config.Save(originalCfg)
+			config.Save(t.Context(), originalCfg)
 		}
 	}()
 
 	// Set a test value
-	if err := config.Set("default_format", "json"); err != nil {
+	if err := config.Set(t.Context(), "default_format", "json"); err != nil {
 		t.Fatalf("Failed to set config: %v", err)
 	}
 
@@ -168,12 +168,12 @@ func TestConfigGet_LSONFormat(t *testing.T) {
 	originalCfg, _ := config.Get()
 	defer func() {
 		if originalCfg != nil {
-			config.Save(originalCfg)
+			config.Save(t.Context(), originalCfg)
 		}
 	}()
 
 	// Set a test value
-	if err := config.Set("default_format", "lson"); err != nil {
+	if err := config.Set(t.Context(), "default_format", "lson"); err != nil {
 		t.Fatalf("Failed to set config: %v", err)
 	}
 
@@ -197,12 +197,43 @@ func TestConfigGet_LSONFormat(t *testing.T) {
 	}
 }
 
+func TestConfigGet_YAMLFormat(t *testing.T) {
+	// Save original config
+	originalCfg, _ := config.Get()
+	defer func() {
+		if originalCfg != nil {
+			config.Save(t.Context(), originalCfg)
+		}
+	}()
+
+	// Set a test value
+	if err := config.Set(t.Context(), "default_format", "yaml"); err != nil {
+		t.Fatalf("Failed to set config: %v", err)
+	}
+
+	rootCmd := NewRootCmd()
+	rootCmd.SetArgs([]string{"config", "get", "default_format", "--format", "yaml"})
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatalf("config get command failed: %v", err)
+	}
+
+	output := buf.String()
+	if output != "default_format: yaml\n" {
+		t.Errorf("Expected output 'default_format: yaml', got: %s", output)
+	}
+}
+
 func TestConfigSet_ValidKeyValue(t *testing.T) {
 	// Save original config
 	originalCfg, _ := config.Get()
 	defer func() {
 		if originalCfg != nil {
-			config.Save(originalCfg)
+			config.Save(t.Context(), originalCfg)
 		}
 	}()
 
@@ -290,7 +321,7 @@ func TestConfigSet_NonExistentProject(t *testing.T) {
 	originalCfg, _ := config.Get()
 	defer func() {
 		if originalCfg != nil {
-			config.Save(originalCfg)
+			config.Save(t.Context(), originalCfg)
 		}
 	}()
 
@@ -328,14 +359,14 @@ func TestConfigSet_ValidProject(t *testing.T) {
 	originalCfg, _ := config.Get()
 	defer func() {
 		if originalCfg != nil {
-			config.Save(originalCfg)
+			config.Save(t.Context(), originalCfg)
 		}
 	}()
 
 	// Create a test project
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -376,12 +407,12 @@ func TestConfigList_ModernFormat(t *testing.T) {
 	originalCfg, _ := config.Get()
 	defer func() {
 		if originalCfg != nil {
-			config.Save(originalCfg)
+			config.Save(t.Context(), originalCfg)
 		}
 	}()
 
 	// Set some test values
-	if err := config.Set("default_format", "json"); err != nil {
+	if err := config.Set(t.Context(), "default_format", "json"); err != nil {
 		t.Fatalf("Failed to set config: %v", err)
 	}
 
@@ -410,12 +441,12 @@ func TestConfigList_JSONFormat(t *testing.T) {
 	originalCfg, _ := config.Get()
 	defer func() {
 		if originalCfg != nil {
-			config.Save(originalCfg)
+			config.Save(t.Context(), originalCfg)
 		}
 	}()
 
 	// Set some test values
-	if err := config.Set("default_format", "json"); err != nil {
+	if err := config.Set(t.Context(), "default_format", "json"); err != nil {
 		t.Fatalf("Failed to set config: %v", err)
 	}
 
@@ -446,15 +477,15 @@ func TestConfigList_LSONFormat(t *testing.T) {
 	originalCfg, _ := config.Get()
 	defer func() {
 		if originalCfg != nil {
-			config.Save(originalCfg)
+			config.Save(t.Context(), originalCfg)
 		}
 	}()
 
 	// Set some test values
-	if err := config.Set("default_format", "lson"); err != nil {
+	if err := config.Set(t.Context(), "default_format", "lson"); err != nil {
 		t.Fatalf("Failed to set config: %v", err)
 	}
-	if err := config.Set("default_project", "TEST"); err != nil {
+	if err := config.Set(t.Context(), "default_project", "TEST"); err != nil {
 		t.Fatalf("Failed to set config: %v", err)
 	}
 
@@ -477,3 +508,62 @@ func TestConfigList_LSONFormat(t *testing.T) {
 		t.Errorf("Expected output to contain '@DEFAULT_PROJECT:', got: %s", output)
 	}
 }
+
+func TestConfigProjectDefaults(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	originalCfg, _ := config.Get()
+	defer func() {
+		if originalCfg != nil {
+			config.Save(t.Context(), originalCfg)
+		}
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) error {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+		return cmd.Execute()
+	}
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"config", "set", "project." + projectKey + ".type", "bug"},
+		{"config", "set", "project." + projectKey + ".priority", "HIGH"},
+		{"config", "set", "project." + projectKey + ".labels", "ops,oncall"},
+		{"config", "set", "project." + projectKey + ".assignee", "alice"},
+		{"issue", "create", "--project", projectKey, "--title", "Disk full"},
+		{"issue", "create", "--project", projectKey, "--title", "Rotate keys", "--type", "task", "--priority", "LOW", "--labels", "", "--assignee", ""},
+	} {
+		if err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	issue, err := loadLocalIssue(t.Context(), projectKey, projectKey+"-1")
+	if err != nil {
+		t.Fatalf("Failed to load issue: %v", err)
+	}
+	if issue.Type != "bug" || issue.Priority != "HIGH" || strings.Join(issue.Labels, ",") != "ops,oncall" || issue.Assignee != "alice" {
+		t.Errorf("Expected the project defaults, got %+v", issue)
+	}
+
+	// Flags win over the defaults, even when empty
+	issue, err = loadLocalIssue(t.Context(), projectKey, projectKey+"-2")
+	if err != nil {
+		t.Fatalf("Failed to load issue: %v", err)
+	}
+	if issue.Type != "task" || issue.Priority != "LOW" || len(issue.Labels) != 0 || issue.Assignee != "" {
+		t.Errorf("Expected the flags to override the defaults, got %+v", issue)
+	}
+
+	// A default the workflow doesn't allow is reported
+	if err := run("config", "set", "project."+projectKey+".priority", "URGENT"); err != nil {
+		t.Fatalf("config set failed: %v", err)
+	}
+	err = run("issue", "create", "--project", projectKey, "--title", "Outage")
+	if err == nil || !strings.Contains(err.Error(), "default priority") {
+		t.Errorf("Expected an invalid default priority to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,454 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// conflictCopyPatterns match the copies file-sharing services make when a
+// file changed on two machines: Dropbox's "CORE-1 (alice's conflicted copy
+// 2024-06-01).json" and Syncthing's "CORE-1.sync-conflict-20240601-120000-ABCDEFG.json".
+var conflictCopyPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^(.+?) \([^()]*conflicted copy[^()]*\)\.json$`),
+	regexp.MustCompile(`^(.+?)\.sync-conflict-\d{8}-\d{6}(-[A-Za-z0-9]+)?\.json$`),
+}
+
+// NewProjectCRDTCmd creates and returns the project crdt command.
+func NewProjectCRDTCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "crdt <key> [on|off]",
+		Short: "View or change CRDT mode of a project (experimental)",
+		Long: `View or change CRDT mode of a project, for projects kept in a folder synced
+by a file-sharing service (Dropbox, Syncthing, ...).
+
+In CRDT mode every issue write records when each field last changed and which
+labels, PRs, blockers, links, comments, and time entries were added or
+removed. When the same issue is edited on two machines and the service leaves a conflict copy,
+buyruk sync merge merges the copies field by field: the latest change of each
+field wins, and list elements added on one side are kept unless the other
+side removed them after seeing them.
+
+This mode is experimental: the metadata makes issue files larger, and only
+issues and the project index are merged.`,
+		Example: `  buyruk project crdt CORE
+  buyruk project crdt CORE on`,
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: completeProjectArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				return showCRDTMode(args[0], cmd)
+			}
+			return setCRDTMode(args[0], args[1], cmd)
+		},
+	}
+
+	return cmd
+}
+
+// NewSyncMergeCmd creates and returns the sync merge command.
+func NewSyncMergeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "merge",
+		Short: "Merge conflict copies left by a file-sharing service",
+		Long: `Merge the conflict copies of issues and of the project index that a
+file-sharing service left in a project in CRDT mode, then delete the copies.
+See buyruk project crdt.`,
+		Example: `  buyruk sync merge --project CORE
+  buyruk sync merge --project CORE --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return mergeConflictCopies(cmd)
+		},
+	}
+
+	cmd.Flags().Bool("dry-run", false, "Show the conflict copies without merging them")
+
+	return cmd
+}
+
+// showCRDTMode prints whether a project is in CRDT mode.
+func showCRDTMode(projectKey string, cmd *cobra.Command) error {
+	index, err := loadQueryIndex(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	mode := "off"
+	if index.CRDT {
+		mode = "on"
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), mode)
+	return nil
+}
+
+// setCRDTMode turns CRDT mode of a project on or off.
+func setCRDTMode(projectKey, mode string, cmd *cobra.Command) error {
+	if mode != "on" && mode != "off" {
+		return invalidf("cli: invalid CRDT mode %q (allowed: on, off)", mode)
+	}
+	if _, err := loadQueryIndex(cmd.Context(), projectKey); err != nil {
+		return err
+	}
+	indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	var index models.ProjectIndex
+	if err := storage.UpdateIndexAtomic(cmd.Context(), indexPath, &index, func(v interface{}) error {
+		v.(*models.ProjectIndex).CRDT = mode == "on"
+		return nil
+	}); err != nil {
+		return fmt.Errorf("cli: failed to update project index: %w", err)
+	}
+
+	out := successOut(cmd)
+	fmt.Fprintf(out, "CRDT mode of project %q is %s\n", projectKey, mode)
+	if mode == "on" {
+		fmt.Fprintln(out, "Note: CRDT mode is experimental; run 'buyruk sync merge' after your sync service reports conflicts")
+	}
+	return nil
+}
+
+// crdtModeEntry caches whether a project is in CRDT mode, for one version of
+// its index file.
+type crdtModeEntry struct {
+	modTime time.Time
+	size    int64
+	enabled bool
+}
+
+// crdtEnabled reports whether a project is in CRDT mode.
+func crdtEnabled(ctx context.Context, projectKey string) bool {
+	indexPath, err := storage.ProjectIndexPath(ctx, projectKey)
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(indexPath)
+	if err != nil {
+		return false
+	}
+	// The mode is cached in the runState, so bulk writes don't parse the
+	// index once per issue
+	state := runStateFrom(ctx)
+	if state != nil {
+		state.mu.Lock()
+		entry, ok := state.crdtModes[indexPath]
+		state.mu.Unlock()
+		if ok && entry.modTime.Equal(info.ModTime()) && entry.size == info.Size() {
+			return entry.enabled
+		}
+	}
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(ctx, indexPath, &index); err != nil {
+		return false
+	}
+	if state != nil {
+		state.mu.Lock()
+		if state.crdtModes == nil {
+			state.crdtModes = map[string]crdtModeEntry{}
+		}
+		state.crdtModes[indexPath] = crdtModeEntry{modTime: info.ModTime(), size: info.Size(), enabled: index.CRDT}
+		state.mu.Unlock()
+	}
+	return index.CRDT
+}
+
+// crdtWriteFilter stamps issue files of projects in CRDT mode with the
+// changes being written, comparing them with the file on disk. It is
+// installed as the storage write filter, so every command that writes issues
+// keeps the metadata up to date.
+func crdtWriteFilter(ctx context.Context, path string, data []byte) ([]byte, error) {
+	projectKey, ok := issueFileProject(ctx, path)
+	if !ok || !crdtEnabled(ctx, projectKey) {
+		return data, nil
+	}
+
+	var updated models.Issue
+	if err := json.Unmarshal(data, &updated); err != nil {
+		return data, nil
+	}
+	var old *models.Issue
+	var existing models.Issue
+	if err := storage.ReadJSON(ctx, path, &existing); err == nil {
+		old = &existing
+	}
+	replica, err := replicaID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := models.StampIssue(old, &updated, models.NewClock(storage.Now(ctx), replica)); err != nil {
+		return nil, fmt.Errorf("cli: failed to record changes of %s: %w", updated.ID, err)
+	}
+	stamped, err := json.MarshalIndent(&updated, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to marshal issue %s: %w", updated.ID, err)
+	}
+	return stamped, nil
+}
+
+// issueFileProject returns the project of an issue file path, or false if
+// the path is not an issue file.
+func issueFileProject(ctx context.Context, path string) (string, bool) {
+	dir := filepath.Dir(path)
+	if filepath.Ext(path) != ".json" || filepath.Base(dir) != "issues" {
+		return "", false
+	}
+	projectKey := filepath.Base(filepath.Dir(dir))
+	if issuesDir, err := storage.IssuesDir(ctx, projectKey); err != nil || issuesDir != dir {
+		return "", false
+	}
+	return projectKey, true
+}
+
+// replicaID returns the ID this machine uses in CRDT clocks, creating it on
+// first use. It is kept in the config directory, which is not synced.
+func replicaID(ctx context.Context) (string, error) {
+	state := runStateFrom(ctx)
+	if state == nil {
+		return resolveReplicaID(ctx)
+	}
+	state.mu.Lock()
+	replica := state.replica
+	state.mu.Unlock()
+	if replica != "" {
+		return replica, nil
+	}
+	// Resolved without holding the lock, as saving a new ID runs the write
+	// filter, which uses the runState too
+	replica, err := resolveReplicaID(ctx)
+	if err != nil {
+		return "", err
+	}
+	state.mu.Lock()
+	state.replica = replica
+	state.mu.Unlock()
+	return replica, nil
+}
+
+// resolveReplicaID reads the replica ID, or creates it; see replicaID.
+func resolveReplicaID(ctx context.Context) (string, error) {
+	configDir, err := storage.ConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cli: failed to resolve config directory: %w", err)
+	}
+	replicaPath := filepath.Join(configDir, "replica")
+	if data, err := os.ReadFile(replicaPath); err == nil && len(strings.TrimSpace(string(data))) > 0 {
+		return strings.TrimSpace(string(data)), nil
+	}
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("cli: failed to generate replica ID: %w", err)
+	}
+	replica := hex.EncodeToString(id)
+	if err := storage.WriteAtomic(ctx, replicaPath, []byte(replica+"\n")); err != nil {
+		return "", fmt.Errorf("cli: failed to save replica ID: %w", err)
+	}
+	return replica, nil
+}
+
+// conflictCopyBase returns the file name a conflict copy was made from.
+func conflictCopyBase(name string) (string, bool) {
+	for _, pattern := range conflictCopyPatterns {
+		if m := pattern.FindStringSubmatch(name); m != nil {
+			return m[1] + ".json", true
+		}
+	}
+	return "", false
+}
+
+// mergeConflictCopies merges the conflict copies of a project's issues and
+// index. Merged issues, the index, and the deleted copies change together or
+// not at all.
+func mergeConflictCopies(cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	index, err := loadQueryIndex(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	if !index.CRDT {
+		return invalidf("cli: project %q is not in CRDT mode (enable it with 'buyruk project crdt %s on')", projectKey, projectKey)
+	}
+	issuesDir, err := storage.IssuesDir(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve issues directory: %w", err)
+	}
+	projectDir, err := storage.ProjectDir(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve project directory: %w", err)
+	}
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	out := successOut(cmd)
+	if dryRun {
+		out = cmd.OutOrStdout() // A dry run reports what would change
+	}
+
+	cleanup, err := storage.AcquireLock(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to acquire lock: %w", err)
+	}
+	defer cleanup()
+
+	issueCopies, err := findConflictCopies(issuesDir)
+	if err != nil {
+		return err
+	}
+	indexCopies, err := findConflictCopies(projectDir)
+	if err != nil {
+		return err
+	}
+	if len(indexCopies["project.json"]) == 0 && len(issueCopies) == 0 {
+		fmt.Fprintf(out, "No conflict copies in project %q\n", projectKey)
+		return nil
+	}
+	for name := range indexCopies {
+		if name != "project.json" {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: conflict copies of %s are not merged\n", name)
+		}
+	}
+
+	indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	if err := storage.ReadIndex(cmd.Context(), indexPath, index); err != nil {
+		return fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+
+	var writes []fileWrite
+	remove := func(path string) error {
+		original, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("cli: failed to read %s: %w", path, err)
+		}
+		writes = append(writes, fileWrite{path: path, original: original})
+		return nil
+	}
+
+	bases := make([]string, 0, len(issueCopies))
+	for base := range issueCopies {
+		bases = append(bases, base)
+	}
+	sort.Strings(bases)
+	for _, base := range bases {
+		issueID := strings.TrimSuffix(base, ".json")
+		merged, err := loadLocalIssue(cmd.Context(), projectKey, issueID)
+		if err != nil {
+			return err
+		}
+		for _, name := range issueCopies[base] {
+			var copied models.Issue
+			if err := storage.ReadJSON(cmd.Context(), filepath.Join(issuesDir, name), &copied); err != nil {
+				return fmt.Errorf("cli: failed to load conflict copy %s: %w", name, err)
+			}
+			if merged == nil {
+				merged = &copied
+			} else if merged, err = models.MergeIssues(merged, &copied); err != nil {
+				return fmt.Errorf("cli: failed to merge %s: %w", name, err)
+			}
+			fmt.Fprintf(out, "Merged %q into %s\n", name, issueID)
+		}
+
+		write, err := plannedJSONWrite(filepath.Join(issuesDir, base), merged)
+		if err != nil {
+			return err
+		}
+		writes = append(writes, write)
+		for _, name := range issueCopies[base] {
+			if err := remove(filepath.Join(issuesDir, name)); err != nil {
+				return err
+			}
+		}
+		index.AddIssue(merged)
+	}
+
+	// Index copies only add the issues created on the other machine; the
+	// entries of existing issues are rebuilt from the merged issue files
+	for _, name := range indexCopies["project.json"] {
+		var copied models.ProjectIndex
+		if err := storage.ReadJSON(cmd.Context(), filepath.Join(projectDir, name), &copied); err != nil {
+			return fmt.Errorf("cli: failed to load conflict copy %s: %w", name, err)
+		}
+		var added []string
+		for _, entry := range copied.Issues {
+			if index.FindIssue(entry.ID) != nil {
+				continue
+			}
+			issue, err := loadLocalIssue(cmd.Context(), projectKey, entry.ID)
+			if err != nil || issue == nil {
+				continue
+			}
+			index.AddIssue(issue)
+			added = append(added, entry.ID)
+		}
+		fmt.Fprintf(out, "Merged conflict copy of the project index")
+		if len(added) > 0 {
+			fmt.Fprintf(out, " (added %s)", strings.Join(added, ", "))
+		}
+		fmt.Fprintln(out)
+		if err := remove(filepath.Join(projectDir, name)); err != nil {
+			return err
+		}
+	}
+
+	if dryRun {
+		fmt.Fprintln(out, "Dry run: nothing was changed")
+		return nil
+	}
+
+	index.UpdatedAt = storage.Timestamp(cmd.Context())
+	write, err := plannedIndexWrite(indexPath, index)
+	if err != nil {
+		return err
+	}
+	writes = append(writes, write)
+
+	if err := storage.BeginTransaction(cmd.Context(), projectKey, "merge_conflict_copies", map[string]interface{}{
+		"files": len(writes),
+	}); err != nil {
+		return err
+	}
+	if err := writeFiles(cmd.Context(), writes); err != nil {
+		storage.RollbackTransaction(cmd.Context(), projectKey)
+		return err
+	}
+	return storage.CommitTransaction(cmd.Context(), projectKey)
+}
+
+// findConflictCopies lists the conflict copies in a directory by the name of
+// the file they were copied from, in name order.
+func findConflictCopies(dir string) (map[string][]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cli: failed to read %s: %w", dir, err)
+	}
+	copies := map[string][]string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if base, ok := conflictCopyBase(entry.Name()); ok {
+			copies[base] = append(copies[base], entry.Name())
+		}
+	}
+	return copies, nil
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestSyncMerge(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	if _, err := run("project", "create", projectKey); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if _, err := run("sync", "merge", "--project", projectKey); err == nil || !strings.Contains(err.Error(), "not in CRDT mode") {
+		t.Errorf("Expected merge outside CRDT mode to fail, got %v", err)
+	}
+	if _, err := run("project", "crdt", projectKey, "on"); err != nil {
+		t.Fatalf("crdt on failed: %v", err)
+	}
+	if out, _ := run("project", "crdt", projectKey); strings.TrimSpace(out) != "on" {
+		t.Errorf("Expected CRDT mode on, got %q", out)
+	}
+	if _, err := run("issue", "create", "--project", projectKey, "--title", "Login page", "--labels", "auth", "--fixed-time", "2024-06-01T09:00:00Z"); err != nil {
+		t.Fatalf("issue create failed: %v", err)
+	}
+	issueID := projectKey + "-1"
+	issuePath, _ := storage.IssuePath(t.Context(), projectKey, issueID)
+	original, err := os.ReadFile(issuePath)
+	if err != nil {
+		t.Fatalf("Failed to read issue: %v", err)
+	}
+
+	// Bob's machine changes the status and a label...
+	if _, err := run("issue", "update", issueID, "--project", projectKey, "--status", "DOING", "--add-label", "ui", "--fixed-time", "2024-06-01T10:00:00Z"); err != nil {
+		t.Fatalf("issue update failed: %v", err)
+	}
+	bobs, _ := os.ReadFile(issuePath)
+
+	// ...while Alice's, which has not seen that change, renames the issue
+	if err := os.WriteFile(issuePath, original, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run("issue", "update", issueID, "--project", projectKey, "--title", "Login and signup", "--fixed-time", "2024-06-01T11:00:00Z"); err != nil {
+		t.Fatalf("issue update failed: %v", err)
+	}
+	conflictPath := filepath.Join(filepath.Dir(issuePath), issueID+" (bob's conflicted copy 2024-06-01).json")
+	if err := os.WriteFile(conflictPath, bobs, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := run("sync", "merge", "--project", projectKey, "--dry-run")
+	if err != nil || !strings.Contains(out, "into "+issueID) {
+		t.Fatalf("dry run failed: %v: %s", err, out)
+	}
+	if _, err := os.Stat(conflictPath); err != nil {
+		t.Error("Expected the dry run to keep the conflict copy")
+	}
+
+	if _, err := run("sync", "merge", "--project", projectKey); err != nil {
+		t.Fatalf("merge failed: %v", err)
+	}
+	if _, err := os.Stat(conflictPath); !os.IsNotExist(err) {
+		t.Error("Expected the conflict copy to be removed")
+	}
+	var merged models.Issue
+	if err := storage.ReadJSON(t.Context(), issuePath, &merged); err != nil {
+		t.Fatalf("Failed to read merged issue: %v", err)
+	}
+	if merged.Title != "Login and signup" || merged.Status != models.StatusDOING {
+		t.Errorf("Expected both changes to survive, got title %q and status %q", merged.Title, merged.Status)
+	}
+	if strings.Join(merged.Labels, ",") != "auth,ui" {
+		t.Errorf("Expected labels auth,ui, got %v", merged.Labels)
+	}
+	index, _ := loadQueryIndex(t.Context(), projectKey)
+	if entry := index.FindIssue(issueID); entry == nil || entry.Status != models.StatusDOING {
+		t.Errorf("Expected the index to reflect the merge, got %+v", entry)
+	}
+
+	if out, _ := run("sync", "merge", "--project", projectKey); !strings.Contains(out, "No conflict copies") {
+		t.Errorf("Expected nothing left to merge, got %q", out)
+	}
+}
@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// ProjectHealth is the result of checking a single project.
+type ProjectHealth struct {
+	Project   string   `json:"project"`
+	Issues    int      `json:"issues"`
+	IndexSize int64    `json:"index_size"` // project.json (and index shard) size in bytes
+	Warnings  []string `json:"warnings,omitempty"`
+}
+
+// NewDoctorCmd creates and returns the doctor command.
+func NewDoctorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check projects for problems",
+		Long: `Check projects for problems such as interrupted writes and growth beyond
+the configured soft limits (max_issues, max_index_size).
+
+Checks the project given with --project, or every project if none is given.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDoctor(cmd)
+		},
+	}
+
+	return cmd
+}
+
+// runDoctor checks one or all projects and renders the results.
+func runDoctor(cmd *cobra.Command) error {
+	var projectKeys []string
+	if project := GetProject(cmd); project != "" {
+		projectKeys = []string{project}
+	} else {
+		keys, err := storage.ListProjects(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("cli: failed to list projects: %w", err)
+		}
+		projectKeys = keys
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("cli: failed to load config: %w", err)
+	}
+
+	results := make([]*ProjectHealth, 0, len(projectKeys))
+	for _, projectKey := range projectKeys {
+		health, err := checkProjectHealth(cmd.Context(), projectKey, cfg)
+		if err != nil {
+			return err
+		}
+		results = append(results, health)
+	}
+
+	return renderDoctor(results, cmd, cmd.OutOrStdout())
+}
+
+// checkProjectHealth checks a project for pending transactions and quota overruns.
+func checkProjectHealth(ctx context.Context, projectKey string, cfg *config.Config) (*ProjectHealth, error) {
+	projectDir, err := storage.ProjectDir(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve project directory: %w", err)
+	}
+	if _, err := os.Stat(projectDir); os.IsNotExist(err) {
+		return nil, notFoundf("cli: project %q does not exist", projectKey)
+	}
+
+	health := &ProjectHealth{Project: projectKey}
+
+	if pending, _, err := storage.CheckPendingTransaction(ctx, projectKey); err == nil && pending {
+		health.Warnings = append(health.Warnings,
+			"found a pending transaction from an interrupted write (run `buyruk project repair "+projectKey+"`)")
+	}
+
+	indexPath, err := storage.ProjectIndexPath(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+
+	info, err := os.Stat(indexPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			health.Warnings = append(health.Warnings,
+				"project index is missing (run `buyruk project repair "+projectKey+"`)")
+			return health, nil
+		}
+		return nil, fmt.Errorf("cli: failed to stat project index: %w", err)
+	}
+	health.IndexSize = info.Size()
+	if size, err := storage.IndexSize(ctx, projectKey); err == nil {
+		health.IndexSize = size // Including the shards of a sharded index
+	}
+
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(ctx, indexPath, &index); err != nil {
+		health.Warnings = append(health.Warnings, fmt.Sprintf("project index is unreadable: %v", err))
+		return health, nil
+	}
+	health.Issues = len(index.Issues)
+
+	health.Warnings = append(health.Warnings, quotaWarnings(health, cfg)...)
+	return health, nil
+}
+
+// quotaWarnings returns warnings for a project that has reached the configured soft limits.
+func quotaWarnings(health *ProjectHealth, cfg *config.Config) []string {
+	var warnings []string
+	if limit := cfg.IssueLimit(); health.Issues >= limit {
+		warnings = append(warnings, fmt.Sprintf(
+			"project %s has %d issues (soft limit %d); consider archiving done issues or splitting the project",
+			health.Project, health.Issues, limit))
+	}
+	if limit := cfg.IndexSizeLimit(); health.IndexSize >= limit {
+		warnings = append(warnings, fmt.Sprintf(
+			"project %s index is %s (soft limit %s); consider archiving done issues or splitting the project",
+			health.Project, formatSize(health.IndexSize), formatSize(limit)))
+	}
+	return warnings
+}
+
+// warnQuota prints soft limit warnings for a project to stderr.
+// Failures are ignored: quota checks must never fail the command that triggered them.
+func warnQuota(projectKey string, cmd *cobra.Command) {
+	cfg, err := config.Get()
+	if err != nil {
+		return
+	}
+	health, err := checkProjectHealth(cmd.Context(), projectKey, cfg)
+	if err != nil {
+		return
+	}
+
+	errOut := cmd.ErrOrStderr()
+	for _, warning := range quotaWarnings(health, cfg) {
+		fmt.Fprintf(errOut, "Warning: %s\n", warning)
+	}
+}
+
+// renderDoctor renders doctor results in the resolved output format.
+func renderDoctor(results []*ProjectHealth, cmd *cobra.Command, w io.Writer) error {
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(w, results)
+	case config.DefaultFormatLSON:
+		for i, health := range results {
+			if i > 0 {
+				fmt.Fprintf(w, "\n")
+			}
+			fmt.Fprintf(w, "@PROJECT: %s\n", health.Project)
+			fmt.Fprintf(w, "@ISSUES: %d\n", health.Issues)
+			fmt.Fprintf(w, "@INDEX_SIZE: %d\n", health.IndexSize)
+			for _, warning := range health.Warnings {
+				fmt.Fprintf(w, "@WARN: %s\n", warning)
+			}
+		}
+	default:
+		styles := ui.NewStyles()
+		if len(results) == 0 {
+			fmt.Fprintf(w, "No projects found\n")
+			return nil
+		}
+		for _, health := range results {
+			fmt.Fprintf(w, "%s: %d issues, index %s\n", styles.ID(health.Project), health.Issues, formatSize(health.IndexSize))
+			if len(health.Warnings) == 0 {
+				fmt.Fprintf(w, "  %s\n", styles.Success("OK"))
+			}
+			for _, warning := range health.Warnings {
+				fmt.Fprintf(w, "  %s %s\n", styles.Error("!"), warning)
+			}
+		}
+	}
+	return nil
+}
+
+// formatSize formats a size in bytes for display (e.g. "512 B", "24.0 KB", "1.0 MB").
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(size)/(1<<30))
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestNewDoctorCmd(t *testing.T) {
+	cmd := NewDoctorCmd()
+	if cmd == nil {
+		t.Fatal("NewDoctorCmd() returned nil")
+	}
+	if cmd.Use != "doctor" {
+		t.Errorf("Expected Use to be 'doctor', got '%s'", cmd.Use)
+	}
+}
+
+func TestDoctor_Quotas(t *testing.T) {
+	// Save original config
+	originalCfg, _ := config.Get()
+	defer func() {
+		if originalCfg != nil {
+			config.Save(t.Context(), originalCfg)
+		}
+	}()
+
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	if err := config.Set(t.Context(), "max_issues", "2"); err != nil {
+		t.Fatalf("Failed to set config: %v", err)
+	}
+
+	rootCmd := NewRootCmd()
+	rootCmd.SetArgs([]string{"project", "create", projectKey})
+	rootCmd.SetOut(new(bytes.Buffer))
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	// Creating issues up to the limit warns on stderr after the create
+	var errBuf *bytes.Buffer
+	for i := 0; i < 2; i++ {
+		cmd := NewRootCmd()
+		cmd.SetArgs([]string{"issue", "create", "--project", projectKey, "--title", "Issue"})
+		cmd.SetOut(new(bytes.Buffer))
+		errBuf = new(bytes.Buffer)
+		cmd.SetErr(errBuf)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Failed to create issue: %v", err)
+		}
+		if i == 0 && errBuf.Len() != 0 {
+			t.Errorf("Expected no warning below the limit, got: %s", errBuf.String())
+		}
+	}
+	if !strings.Contains(errBuf.String(), "Warning: project "+projectKey+" has 2 issues (soft limit 2)") {
+		t.Errorf("Expected quota warning, got: %s", errBuf.String())
+	}
+
+	// Doctor reports the same warning
+	rootCmd2 := NewRootCmd()
+	rootCmd2.SetArgs([]string{"doctor", "--project", projectKey, "--format", "json"})
+	buf := new(bytes.Buffer)
+	rootCmd2.SetOut(buf)
+	if err := rootCmd2.Execute(); err != nil {
+		t.Fatalf("doctor command failed: %v", err)
+	}
+
+	var results []ProjectHealth
+	if err := json.Unmarshal(buf.Bytes(), &results); err != nil {
+		t.Fatalf("Failed to parse doctor output: %v\n%s", err, buf.String())
+	}
+	if len(results) != 1 || results[0].Issues != 2 || len(results[0].Warnings) != 1 {
+		t.Errorf("Unexpected doctor results: %+v", results)
+	}
+}
+
+func TestDoctor_MissingProject(t *testing.T) {
+	rootCmd := NewRootCmd()
+	rootCmd.SetArgs([]string{"doctor", "--project", "NONEXISTENT-DOCTOR"})
+	rootCmd.SetOut(new(bytes.Buffer))
+	rootCmd.SetErr(new(bytes.Buffer))
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("Expected error for missing project")
+	}
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/timeparse"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+)
+
+// Effort sums the story points and estimates of a group of issues, and of
+// the done ones among them, for planning capacity.
+type Effort struct {
+	Points       int    `json:"points"`
+	DonePoints   int    `json:"done_points"`
+	Estimate     string `json:"estimate,omitempty"`      // Working time, e.g. "3d 4h"
+	DoneEstimate string `json:"done_estimate,omitempty"` // Working time of the done issues
+
+	estimate, doneEstimate time.Duration
+}
+
+// add counts the points and estimate of an issue. Invalid estimates are
+// left out.
+func (e *Effort) add(points int, estimate string, done bool) {
+	e.Points += points
+	if done {
+		e.DonePoints += points
+	}
+	if estimate == "" {
+		return
+	}
+	d, err := timeparse.ParseWorkDuration(estimate)
+	if err != nil {
+		return
+	}
+	e.estimate += d
+	if done {
+		e.doneEstimate += d
+	}
+	e.Estimate = ui.FormatWorkDuration(e.estimate)
+	e.DoneEstimate = ui.FormatWorkDuration(e.doneEstimate)
+}
+
+// renderEffort renders the points and estimates of an effort that has any,
+// in modern and L-SON output.
+func renderEffort(e Effort, format string, w io.Writer) {
+	switch format {
+	case config.DefaultFormatLSON:
+		if e.Points > 0 {
+			fmt.Fprintf(w, "@POINTS: %d/%d\n", e.DonePoints, e.Points)
+		}
+		if e.Estimate != "" {
+			fmt.Fprintf(w, "@ESTIMATE: %s/%s\n", e.DoneEstimate, e.Estimate)
+		}
+	case config.DefaultFormatModern:
+		styles := ui.NewStyles()
+		if e.Points > 0 {
+			fmt.Fprintf(w, "%s: %d of %d done\n", styles.Label("Points"), e.DonePoints, e.Points)
+		}
+		if e.Estimate != "" {
+			fmt.Fprintf(w, "%s: %s of %s done\n", styles.Label("Estimate"), e.DoneEstimate, e.Estimate)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestEffortRollups(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(append(args, "--project", projectKey))
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	mustRun := func(args ...string) string {
+		t.Helper()
+		out, err := run(args...)
+		if err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+		return out
+	}
+	id := func(n string) string { return projectKey + "-" + n }
+
+	mustRun("project", "create", projectKey)
+	mustRun("epic", "create", "--title", "Checkout")
+	mustRun("issue", "create", "--title", "Cart", "--epic", projectKey+"-E1", "--points", "3", "--estimate", "1d")
+	mustRun("issue", "create", "--title", "Payment", "--epic", projectKey+"-E1", "--points", "5", "--estimate", "4h")
+	mustRun("issue", "create", "--title", "Receipt", "--epic", projectKey+"-E1")
+	mustRun("issue", "update", id("3"), "--points", "2")
+	mustRun("issue", "update", id("1"), "--status", "DONE")
+	mustRun("sprint", "create", "S-1", "--start", "2024-06-03", "--end", "2024-06-14")
+	mustRun("sprint", "add", "S-1", id("1"), id("3"))
+
+	want := Effort{Points: 10, DonePoints: 3, Estimate: "1d 4h", DoneEstimate: "1d"}
+	var epic EpicWithProgress
+	if err := json.Unmarshal([]byte(mustRun("epic", "view", projectKey+"-E1", "--format", "json")), &epic); err != nil {
+		t.Fatalf("Failed to parse epic: %v", err)
+	}
+	if epic.Progress == nil || epic.Progress.Effort != want {
+		t.Errorf("Unexpected epic effort: %+v, want %+v", epic.Progress, want)
+	}
+
+	var sprint SprintWithProgress
+	if err := json.Unmarshal([]byte(mustRun("sprint", "view", "S-1", "--format", "json")), &sprint); err != nil {
+		t.Fatalf("Failed to parse sprint: %v", err)
+	}
+	if want := (Effort{Points: 5, DonePoints: 3, Estimate: "1d", DoneEstimate: "1d"}); sprint.Progress == nil || sprint.Progress.Effort != want || sprint.Progress.Total != 2 {
+		t.Errorf("Unexpected sprint progress: %+v", sprint.Progress)
+	}
+	if out := mustRun("sprint", "view", "S-1", "--format", "lson"); !strings.Contains(out, "@POINTS: 3/5") || !strings.Contains(out, "@PROGRESS: 1/2") {
+		t.Errorf("Unexpected sprint view:\n%s", out)
+	}
+
+	var stats ProjectStats
+	if err := json.Unmarshal([]byte(mustRun("stats", "--format", "json")), &stats); err != nil {
+		t.Fatalf("Failed to parse stats: %v", err)
+	}
+	if stats.Effort != want {
+		t.Errorf("Unexpected stats effort: %+v, want %+v", stats.Effort, want)
+	}
+
+	if _, err := run("issue", "update", id("2"), "--points", "-1"); err == nil {
+		t.Error("Expected negative points to be refused")
+	}
+	if _, err := run("sprint", "view", "S-9"); err == nil {
+		t.Error("Expected an unknown sprint to fail")
+	}
+}
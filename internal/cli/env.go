@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"context"
+	"net/http"
+	"os/exec"
+	"time"
+)
+
+// hostEnv is how commands reach out to the machine they run on and the hosts
+// it talks to: desktop notifications, stress worker processes, the polling of
+// --watch, PR status queries, remotes, and URL hooks. It
+// travels in the context like storage.Env, so tests replace them for their own
+// commands without changing them for commands running side by side. The zero
+// hostEnv uses the real ones.
+type hostEnv struct {
+	// notifyDesktop shows a desktop notification; nil for
+	// sendDesktopNotification.
+	notifyDesktop func(title, body string) error
+	// stressWorker returns the command running a stress worker process with
+	// the given buyruk arguments; nil for stressWorkerCommand.
+	stressWorker func(args ...string) (*exec.Cmd, error)
+	// watchInterval is how often a watched project directory is checked for
+	// changes; 0 for defaultWatchInterval.
+	watchInterval time.Duration
+	// prAPIBaseURLs overrides the API base URL of PR hosts (github.com,
+	// gitlab.com, ...); hosts it leaves out use their real API.
+	prAPIBaseURLs map[string]string
+	// prStatusClient is the HTTP client querying GitHub and GitLab; nil for
+	// one with a timeout of prStatusTimeout.
+	prStatusClient *http.Client
+	// remoteClient is the HTTP client of clone, pull, push, and the storage
+	// backend; nil for one with a timeout of remoteTimeout.
+	remoteClient *http.Client
+	// hookClient is the HTTP client of URL hooks; nil for one with the
+	// hook's timeout.
+	hookClient *http.Client
+}
+
+// hostEnvKey is the context key of the hostEnv.
+type hostEnvKey struct{}
+
+// withHostEnv returns a copy of ctx that carries env.
+func withHostEnv(ctx context.Context, env hostEnv) context.Context {
+	return context.WithValue(ctx, hostEnvKey{}, env)
+}
+
+// hostEnvFrom returns the hostEnv carried by ctx, or the zero hostEnv.
+func hostEnvFrom(ctx context.Context) hostEnv {
+	if ctx == nil {
+		return hostEnv{}
+	}
+	env, _ := ctx.Value(hostEnvKey{}).(hostEnv)
+	return env
+}
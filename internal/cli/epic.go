@@ -2,12 +2,15 @@ package cli
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
-	"time"
 
 	"github.com/buyruk-project/buyruk-cli/internal/config"
 	"github.com/buyruk-project/buyruk-cli/internal/models"
@@ -28,6 +31,7 @@ func NewEpicCmd() *cobra.Command {
 	cmd.AddCommand(NewEpicViewCmd())
 	cmd.AddCommand(NewEpicUpdateCmd())
 	cmd.AddCommand(NewEpicListCmd())
+	cmd.AddCommand(NewEpicIssuesCmd())
 	cmd.AddCommand(NewEpicDeleteCmd())
 
 	return cmd
@@ -38,16 +42,20 @@ func NewEpicCreateCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create a new epic",
-		Long:  "Create a new epic in the project. Title is required.",
+		Long: `Create a new epic in the project. Title is required.
+
+Epic IDs are scoped to the project: generated ones count up (CORE-E1, CORE-E2)
+and a custom --id must start with the project key (CORE-AUTH).`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return createEpic(cmd)
 		},
 	}
 
-	cmd.Flags().String("id", "", "Epic ID (optional, auto-generated if not provided)")
+	cmd.Flags().String("id", "", "Epic ID starting with the project key (optional, auto-generated as KEY-E<n>)")
 	cmd.Flags().String("title", "", "Epic title (required)")
 	cmd.Flags().String("status", "TODO", "Epic status (TODO, DOING, DONE, default: TODO)")
 	cmd.Flags().String("description", "", "Epic description (Markdown)")
+	addBudgetFlags(cmd)
 
 	return cmd
 }
@@ -61,14 +69,14 @@ func createEpic(cmd *cobra.Command) error {
 	}
 
 	// Verify project exists
-	projectDir, err := storage.ProjectDir(projectKey)
+	projectDir, err := storage.ProjectDir(cmd.Context(), projectKey)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve project directory: %w", err)
 	}
 
 	if _, err := os.Stat(projectDir); err != nil {
 		if os.IsNotExist(err) {
-			return fmt.Errorf("cli: project %q does not exist", projectKey)
+			return notFoundf("cli: project %q does not exist", projectKey)
 		}
 		return fmt.Errorf("cli: failed to access project directory: %w", err)
 	}
@@ -76,31 +84,40 @@ func createEpic(cmd *cobra.Command) error {
 	// Get title (required)
 	title, _ := cmd.Flags().GetString("title")
 	if title == "" {
-		return fmt.Errorf("cli: title is required")
+		return invalidf("cli: title is required")
 	}
 
 	// Get ID (optional, auto-generate if not provided)
 	epicID, _ := cmd.Flags().GetString("id")
 	if epicID == "" {
-		nextSeq, err := getNextEpicSequence(projectKey)
+		nextSeq, err := getNextEpicSequence(cmd.Context(), projectKey)
 		if err != nil {
 			return fmt.Errorf("cli: failed to get next epic sequence: %w", err)
 		}
-		epicID = fmt.Sprintf("E-%d", nextSeq)
+		epicID = models.GenerateEpicID(projectKey, nextSeq)
 	} else {
-		// Validate provided ID format
-		if err := validateEpicID(epicID); err != nil {
-			return fmt.Errorf("cli: invalid epic ID format: %w", err)
+		// Validate provided ID format; new epics are scoped to their project
+		if err := validateProjectEpicID(projectKey, epicID); err != nil {
+			return err
+		}
+		if !strings.HasPrefix(epicID, projectKey+"-") {
+			return invalidf("cli: epic ID %q must start with the project key (e.g. %s)", epicID, models.GenerateEpicID(projectKey, 1))
 		}
 	}
 
-	// Get status (default: TODO)
+	// Load project workflow (custom statuses)
+	wf, err := loadWorkflow(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+
+	// Get status (default: first workflow status, TODO for the default workflow)
 	status, _ := cmd.Flags().GetString("status")
-	if status == "" {
-		status = models.StatusTODO
+	if status == "" || !cmd.Flags().Changed("status") {
+		status = wf.DefaultStatus()
 	}
-	if !models.IsValidStatus(status) {
-		return fmt.Errorf("cli: invalid status %q", status)
+	if !wf.IsValidStatus(status) {
+		return invalidf("cli: invalid status %q", status)
 	}
 
 	// Get optional fields
@@ -112,30 +129,40 @@ func createEpic(cmd *cobra.Command) error {
 		Title:       title,
 		Status:      status,
 		Description: description,
-		CreatedAt:   time.Now().Format(time.RFC3339),
-		UpdatedAt:   time.Now().Format(time.RFC3339),
+		CreatedAt:   storage.Timestamp(cmd.Context()),
+		UpdatedAt:   storage.Timestamp(cmd.Context()),
+	}
+	if err := applyBudgetFlags(cmd, epic); err != nil {
+		return err
 	}
 
 	// Validate epic
-	if err := epic.Validate(); err != nil {
-		return fmt.Errorf("cli: invalid epic: %w", err)
+	if err := epic.ValidateWithWorkflow(wf); err != nil {
+		return invalidf("cli: invalid epic: %w", err)
 	}
 
 	// Write epic file atomically (fails if file already exists)
-	epicPath, err := storage.EpicPath(projectKey, epicID)
+	epicPath, err := storage.EpicPath(cmd.Context(), projectKey, epicID)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve epic path: %w", err)
 	}
 
-	if err := storage.WriteJSONAtomicCreate(epicPath, epic); err != nil {
+	if err := storage.WriteJSONAtomicCreate(cmd.Context(), epicPath, epic); err != nil {
 		if strings.Contains(err.Error(), "already exists") {
-			return fmt.Errorf("cli: epic %q already exists", epicID)
+			return conflictf("cli: epic %q already exists", epicID)
 		}
 		return fmt.Errorf("cli: failed to create epic file: %w", err)
 	}
 
+	// Register the epic in the project index
+	if err := updateEpicIndex(cmd.Context(), projectKey, func(idx *models.ProjectIndex) {
+		idx.SetEpic(epic)
+	}); err != nil {
+		return err
+	}
+
 	// Success message
-	out := cmd.OutOrStdout()
+	out := successOut(cmd)
 	fmt.Fprintf(out, "Created epic %q\n", epicID)
 
 	return nil
@@ -143,8 +170,8 @@ func createEpic(cmd *cobra.Command) error {
 
 // getNextEpicSequence returns the next sequence number for an epic in the project.
 // It scans the epics directory to find the highest sequence number and returns the next one.
-func getNextEpicSequence(projectKey string) (int, error) {
-	epicsDir, err := storage.EpicsDir(projectKey)
+func getNextEpicSequence(ctx context.Context, projectKey string) (int, error) {
+	epicsDir, err := storage.EpicsDir(ctx, projectKey)
 	if err != nil {
 		return 0, fmt.Errorf("cli: failed to resolve epics directory: %w", err)
 	}
@@ -169,18 +196,15 @@ func getNextEpicSequence(projectKey string) (int, error) {
 		// Extract epic ID from filename (remove .json extension)
 		epicID := strings.TrimSuffix(entry.Name(), ".json")
 
-		// Parse sequence from epic ID (format: E-1, E-2, etc.)
-		// Only consider IDs matching the standard "E-<n>" pattern for auto-increment
-		// This prevents unrelated epic IDs (e.g., "CUSTOM-99") from affecting the sequence
-		if strings.HasPrefix(epicID, "E-") {
-			// Extract the sequence number after "E-"
-			seqStr := strings.TrimPrefix(epicID, "E-")
-			var seq int
-			if _, err := fmt.Sscanf(seqStr, "%d", &seq); err == nil {
-				if seq > maxSeq {
-					maxSeq = seq
-				}
-			}
+		// Only generated IDs count: project-scoped ones (CORE-E1) and the
+		// unscoped ones of older projects (E-1), so migrated epics keep
+		// their numbers. Custom IDs (e.g., "CORE-AUTH") don't affect the sequence
+		seq, ok := models.LegacyEpicSequence(epicID)
+		if key, scoped, err := models.ParseEpicID(epicID); err == nil && key == projectKey {
+			seq, ok = scoped, true
+		}
+		if ok && seq > maxSeq {
+			maxSeq = seq
 		}
 	}
 
@@ -188,49 +212,90 @@ func getNextEpicSequence(projectKey string) (int, error) {
 	return maxSeq + 1, nil
 }
 
+// validateProjectEpicID validates an epic ID referenced in a project. An ID
+// scoped to another project (OTHER-E1) is refused; unscoped IDs of epics
+// created before IDs carried their project key (E-1) are accepted until
+// buyruk project migrate-epics rewrites them.
+func validateProjectEpicID(projectKey, epicID string) error {
+	if err := validateEpicID(epicID); err != nil {
+		return invalidf("cli: invalid epic ID format: %w", err)
+	}
+	if key, _, err := models.ParseEpicID(epicID); err == nil && key != projectKey {
+		return invalidf("cli: epic %q belongs to project %q, not %q", epicID, key, projectKey)
+	}
+	return nil
+}
+
+// resolveEpicProject validates an epic ID given on the command line and
+// returns its project: the one a scoped ID names, or else the resolved
+// project. An explicit --project that disagrees with the ID is an error.
+func resolveEpicProject(epicID string, cmd *cobra.Command) (string, error) {
+	if err := validateEpicID(epicID); err != nil {
+		return "", invalidf("cli: invalid epic ID format: %w", err)
+	}
+	key, _, err := models.ParseEpicID(epicID)
+	if err != nil {
+		return config.ResolveProject(cmd)
+	}
+	if flag, _ := cmd.Flags().GetString("project"); flag != "" && flag != key {
+		return "", invalidf("cli: epic %q belongs to project %q, not %q", epicID, key, flag)
+	}
+	return key, nil
+}
+
 // NewEpicViewCmd creates and returns the epic view command.
 func NewEpicViewCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "view <id>",
-		Short: "View epic details",
-		Long:  "View detailed information about an epic",
-		Args:  cobra.ExactArgs(1),
+		Use:               "view <id>",
+		Short:             "View epic details",
+		Long:              "View detailed information about an epic",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeEpicArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			epicID := args[0]
 			return viewEpic(epicID, cmd)
 		},
 	}
 
+	cmd.Flags().Bool("with-budget", false, "Show time logged on the epic's issues against its budget")
+
 	return cmd
 }
 
 // viewEpic views a single epic by ID.
 func viewEpic(epicID string, cmd *cobra.Command) error {
-	// Validate epic ID format
-	if err := validateEpicID(epicID); err != nil {
-		return fmt.Errorf("cli: invalid epic ID format: %w", err)
-	}
-
-	// Resolve project
-	projectKey, err := config.ResolveProject(cmd)
+	projectKey, err := resolveEpicProject(epicID, cmd)
 	if err != nil {
 		return err
 	}
 
 	// Load epic
-	epicPath, err := storage.EpicPath(projectKey, epicID)
+	epicPath, err := storage.EpicPath(cmd.Context(), projectKey, epicID)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve epic path: %w", err)
 	}
 
 	var epic models.Epic
-	if err := storage.ReadJSON(epicPath, &epic); err != nil {
-		if os.IsNotExist(err) {
-			return fmt.Errorf("cli: epic %q not found", epicID)
+	if err := storage.ReadJSON(cmd.Context(), epicPath, &epic); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return notFoundf("cli: epic %q not found", epicID)
 		}
 		return fmt.Errorf("cli: failed to load epic: %w", err)
 	}
 
+	progress, err := loadEpicProgress(cmd.Context(), projectKey, []*models.Epic{&epic})
+	if err != nil {
+		return err
+	}
+
+	var usage *EpicBudgetUsage
+	if withBudget, _ := cmd.Flags().GetBool("with-budget"); withBudget {
+		if usage, err = loadEpicBudgetUsage(cmd.Context(), projectKey, &epic); err != nil {
+			return err
+		}
+		warnEpicBudget(&epic, usage, cmd.ErrOrStderr())
+	}
+
 	// Render using UI layer
 	renderer, err := ui.GetRenderer(cmd)
 	if err != nil {
@@ -238,9 +303,19 @@ func viewEpic(epicID string, cmd *cobra.Command) error {
 	}
 
 	out := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(EpicWithProgress{&epic, progress[epic.ID], usage})
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(out, EpicWithProgress{&epic, progress[epic.ID], usage})
+	}
 	if err := renderer.RenderEpic(&epic, out); err != nil {
 		return fmt.Errorf("cli: failed to render epic: %w", err)
 	}
+	renderEpicProgress(progress[epic.ID], true, cmd, out)
+	renderEpicBudget(usage, cmd, out)
 
 	return nil
 }
@@ -250,8 +325,16 @@ func NewEpicUpdateCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "update <id>",
 		Short: "Update an epic",
-		Long:  "Update fields of an existing epic",
-		Args:  cobra.ExactArgs(1),
+		Long: `Update fields of an existing epic.
+
+With epic_status set to auto (buyruk config set epic_status auto), an epic's
+status follows its issues: the default status while none has started, the
+in-progress status (e.g. DOING) once any has, and the first done status when
+all of them are done. It is recomputed whenever an issue of the epic is
+created, deleted, moved to another epic, or changes status, so a status set
+with --status lasts until then.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeEpicArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			epicID := args[0]
 			return updateEpic(epicID, cmd)
@@ -261,36 +344,38 @@ func NewEpicUpdateCmd() *cobra.Command {
 	cmd.Flags().String("title", "", "Update title")
 	cmd.Flags().String("status", "", "Update status")
 	cmd.Flags().String("description", "", "Update description")
+	addBudgetFlags(cmd)
 
 	return cmd
 }
 
 // updateEpic updates an existing epic.
 func updateEpic(epicID string, cmd *cobra.Command) error {
-	// Validate epic ID format
-	if err := validateEpicID(epicID); err != nil {
-		return fmt.Errorf("cli: invalid epic ID format: %w", err)
+	projectKey, err := resolveEpicProject(epicID, cmd)
+	if err != nil {
+		return err
 	}
 
-	// Resolve project
-	projectKey, err := config.ResolveProject(cmd)
+	// Load project workflow (custom statuses)
+	wf, err := loadWorkflow(cmd.Context(), projectKey)
 	if err != nil {
 		return err
 	}
 
 	// Load and update epic atomically
-	epicPath, err := storage.EpicPath(projectKey, epicID)
+	epicPath, err := storage.EpicPath(cmd.Context(), projectKey, epicID)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve epic path: %w", err)
 	}
 
 	var epic models.Epic
-	if err := storage.UpdateJSONAtomic(epicPath, &epic, func(v interface{}) error {
+	statusSet := false
+	if err := storage.UpdateJSONAtomic(cmd.Context(), epicPath, &epic, func(v interface{}) error {
 		ep := v.(*models.Epic)
 
 		// Check if epic exists (ID should match if file existed)
 		if ep.ID == "" || ep.ID != epicID {
-			return fmt.Errorf("cli: epic %q not found", epicID)
+			return notFoundf("cli: epic %q not found", epicID)
 		}
 
 		// Update fields from flags
@@ -299,35 +384,50 @@ func updateEpic(epicID string, cmd *cobra.Command) error {
 		}
 
 		if status, _ := cmd.Flags().GetString("status"); status != "" {
-			if !models.IsValidStatus(status) {
-				return fmt.Errorf("cli: invalid status %q", status)
+			if !wf.IsValidStatus(status) {
+				return invalidf("cli: invalid status %q", status)
 			}
 			ep.Status = status
+			statusSet = true
 		}
 
 		if description, _ := cmd.Flags().GetString("description"); description != "" {
 			ep.Description = description
 		}
 
+		if err := applyBudgetFlags(cmd, ep); err != nil {
+			return err
+		}
+
 		// Update timestamp
-		ep.UpdatedAt = time.Now().Format(time.RFC3339)
+		ep.UpdatedAt = storage.Timestamp(cmd.Context())
 
 		// Validate
-		if err := ep.Validate(); err != nil {
-			return fmt.Errorf("cli: invalid epic after update: %w", err)
+		if err := ep.ValidateWithWorkflow(wf); err != nil {
+			return invalidf("cli: invalid epic after update: %w", err)
 		}
 
 		return nil
 	}); err != nil {
 		if strings.Contains(err.Error(), "not found") {
-			return fmt.Errorf("cli: epic %q not found", epicID)
+			return notFoundf("cli: epic %q not found", epicID)
 		}
 		return fmt.Errorf("cli: failed to update epic: %w", err)
 	}
 
+	// Cascade title and status to the project index
+	if err := updateEpicIndex(cmd.Context(), projectKey, func(idx *models.ProjectIndex) {
+		idx.SetEpic(&epic)
+	}); err != nil {
+		return err
+	}
+
 	// Success message
-	out := cmd.OutOrStdout()
+	out := successOut(cmd)
 	fmt.Fprintf(out, "Updated %s\n", epicID)
+	if cfg, err := config.Get(); statusSet && err == nil && cfg.EpicStatusMode() == config.EpicStatusAuto {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: epic_status is auto, so the status of %s follows its issues again once one of them changes\n", epicID)
+	}
 
 	return nil
 }
@@ -343,6 +443,8 @@ func NewEpicListCmd() *cobra.Command {
 		},
 	}
 
+	cmd.Flags().Bool("with-progress", false, "Show the progress of each epic, rolled up from its issues")
+
 	return cmd
 }
 
@@ -355,7 +457,7 @@ func listEpics(cmd *cobra.Command) error {
 	}
 
 	// Read all epic files from epics directory
-	epicsDir, err := storage.EpicsDir(projectKey)
+	epicsDir, err := storage.EpicsDir(cmd.Context(), projectKey)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve epics directory: %w", err)
 	}
@@ -370,7 +472,7 @@ func listEpics(cmd *cobra.Command) error {
 		}
 		out := cmd.OutOrStdout()
 		// Render empty list
-		return renderEpicList(epics, renderer, cmd, out)
+		return renderEpicList(epics, nil, renderer, cmd, out)
 	}
 
 	entries, err := os.ReadDir(epicsDir)
@@ -387,7 +489,7 @@ func listEpics(cmd *cobra.Command) error {
 
 		epicPath := filepath.Join(epicsDir, entry.Name())
 		var epic models.Epic
-		if err := storage.ReadJSON(epicPath, &epic); err != nil {
+		if err := storage.ReadJSON(cmd.Context(), epicPath, &epic); err != nil {
 			// Log warning but continue
 			errOut := cmd.ErrOrStderr()
 			fmt.Fprintf(errOut, "Warning: failed to load epic %s: %v\n", entry.Name(), err)
@@ -397,6 +499,13 @@ func listEpics(cmd *cobra.Command) error {
 		epics = append(epics, &epic)
 	}
 
+	var progress map[string]*EpicProgress
+	if withProgress, _ := cmd.Flags().GetBool("with-progress"); withProgress {
+		if progress, err = loadEpicProgress(cmd.Context(), projectKey, epics); err != nil {
+			return err
+		}
+	}
+
 	// Render using UI layer
 	renderer, err := ui.GetRenderer(cmd)
 	if err != nil {
@@ -404,19 +513,115 @@ func listEpics(cmd *cobra.Command) error {
 	}
 
 	out := cmd.OutOrStdout()
-	return renderEpicList(epics, renderer, cmd, out)
+	return renderEpicList(epics, progress, renderer, cmd, out)
+}
+
+// NewEpicIssuesCmd creates and returns the epic issues command.
+func NewEpicIssuesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "issues <id>",
+		Short: "List the issues of an epic",
+		Long: `List the issues linked to an epic, using the project index.
+
+JSON and YAML output list the index entries (ID, title, status, type, parent,
+labels), so tools don't need to read the issue files.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeEpicArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listEpicIssues(args[0], cmd)
+		},
+	}
+
+	return cmd
+}
+
+// listEpicIssues lists the issues linked to an epic from the project index.
+func listEpicIssues(epicID string, cmd *cobra.Command) error {
+	projectKey, err := resolveEpicProject(epicID, cmd)
+	if err != nil {
+		return err
+	}
+	index, err := loadQueryIndex(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+
+	// Indexes written before epics were indexed may not list the epic
+	if index.FindEpic(epicID) == nil {
+		epicPath, err := storage.EpicPath(cmd.Context(), projectKey, epicID)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve epic path: %w", err)
+		}
+		if _, err := os.Stat(epicPath); os.IsNotExist(err) {
+			return notFoundf("cli: epic %q not found", epicID)
+		}
+	}
+	entries := index.EpicIssues(epicID)
+
+	out := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(out, entries)
+	}
+
+	issues := make([]*models.Issue, 0, len(entries))
+	for _, entry := range entries {
+		issues = append(issues, &models.Issue{
+			ID:       entry.ID,
+			UID:      entry.UID,
+			Title:    entry.Title,
+			Status:   entry.Status,
+			Type:     entry.Type,
+			Priority: entry.Priority,
+			EpicID:   entry.EpicID,
+			ParentID: entry.ParentID,
+			Labels:   entry.Labels,
+			Assignee: entry.Assignee,
+		})
+	}
+	renderer, err := ui.GetRenderer(cmd)
+	if err != nil {
+		return fmt.Errorf("cli: failed to get renderer: %w", err)
+	}
+	if err := renderer.RenderIssueList(issues, out); err != nil {
+		return fmt.Errorf("cli: failed to render issue list: %w", err)
+	}
+	return nil
 }
 
 // renderEpicList renders a list of epics using the appropriate renderer.
-func renderEpicList(epics []*models.Epic, renderer ui.Renderer, cmd *cobra.Command, w interface{ Write([]byte) (int, error) }) error {
-	// For JSON format, render as an array
+// Progress is shown for the epics in the progress map, if given.
+func renderEpicList(epics []*models.Epic, progress map[string]*EpicProgress, renderer ui.Renderer, cmd *cobra.Command, w interface{ Write([]byte) (int, error) }) error {
 	format := config.ResolveFormat(cmd)
+	if progress != nil && (format == config.DefaultFormatJSON || format == config.DefaultFormatYAML) {
+		items := make([]EpicWithProgress, 0, len(epics))
+		for _, epic := range epics {
+			items = append(items, EpicWithProgress{epic, progress[epic.ID], nil})
+		}
+		if format == config.DefaultFormatYAML {
+			return ui.EncodeYAML(w, items)
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(items)
+	}
+
+	// For JSON format, render as an array
 	if format == config.DefaultFormatJSON {
 		encoder := json.NewEncoder(w)
 		encoder.SetIndent("", "  ")
 		return encoder.Encode(epics)
 	}
 
+	// For YAML format, render as a sequence
+	if format == config.DefaultFormatYAML {
+		return ui.EncodeYAML(w, epics)
+	}
+
 	// For modern/LSON, render each epic individually
 	for i, epic := range epics {
 		if i > 0 {
@@ -426,17 +631,168 @@ func renderEpicList(epics []*models.Epic, renderer ui.Renderer, cmd *cobra.Comma
 		if err := renderer.RenderEpic(epic, w); err != nil {
 			return err
 		}
+		if progress != nil {
+			renderEpicProgress(progress[epic.ID], false, cmd, w)
+		}
 	}
 	return nil
 }
 
+// EpicProgress is the progress of an epic, or of a milestone or sprint, rolled
+// up from the issues linked to it in the project index.
+type EpicProgress struct {
+	Done       int                 `json:"done"`
+	Total      int                 `json:"total"`
+	Percent    int                 `json:"percent"`
+	Statuses   []EpicStatusCount   `json:"statuses"`
+	OpenIssues []models.IndexEntry `json:"open_issues"`
+	Effort     Effort              `json:"effort"` // Story points and estimates of the issues
+}
+
+// EpicStatusCount is the number of an epic's issues in one status.
+type EpicStatusCount struct {
+	Status  string `json:"status"`
+	Count   int    `json:"count"`
+	Percent int    `json:"percent"`
+}
+
+// EpicWithProgress is an epic with its progress, for JSON and YAML output.
+type EpicWithProgress struct {
+	*models.Epic
+	Progress *EpicProgress    `json:"progress"`
+	Usage    *EpicBudgetUsage `json:"budget_usage,omitempty"` // With --with-budget only
+}
+
+// loadEpicProgress rolls up the progress of the epics from one scan of the
+// project index. Statuses follow the workflow, including those with no issues.
+func loadEpicProgress(ctx context.Context, projectKey string, epics []*models.Epic) (map[string]*EpicProgress, error) {
+	ids := make([]string, len(epics))
+	for i, epic := range epics {
+		ids[i] = epic.ID
+	}
+	return loadProgress(ctx, projectKey, ids, func(entry *models.IndexEntry) string { return entry.EpicID })
+}
+
+// loadProgress rolls up the progress of the epics or milestones with the IDs,
+// linking each issue to the one groupOf returns.
+func loadProgress(ctx context.Context, projectKey string, ids []string, groupOf func(*models.IndexEntry) string) (map[string]*EpicProgress, error) {
+	index, err := loadQueryIndex(ctx, projectKey)
+	if err != nil {
+		return nil, err
+	}
+	wf, err := loadWorkflow(ctx, projectKey)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := make(map[string]*EpicProgress, len(ids))
+	for _, id := range ids {
+		progress[id] = &EpicProgress{Statuses: []EpicStatusCount{}, OpenIssues: []models.IndexEntry{}}
+	}
+	for i := range index.Issues {
+		entry := index.Issues[i]
+		p, ok := progress[groupOf(&entry)]
+		if !ok {
+			continue
+		}
+		p.Total++
+		done := wf.IsDoneStatus(entry.Status)
+		p.Effort.add(entry.Points, entry.Estimate, done)
+		if done {
+			p.Done++
+		} else {
+			p.OpenIssues = append(p.OpenIssues, entry)
+		}
+		found := false
+		for i := range p.Statuses {
+			if p.Statuses[i].Status == entry.Status {
+				p.Statuses[i].Count++
+				found = true
+			}
+		}
+		if !found {
+			p.Statuses = append(p.Statuses, EpicStatusCount{Status: entry.Status, Count: 1})
+		}
+	}
+
+	// Order statuses by the workflow; statuses outside it keep their order after
+	statuses := wf.StatusList()
+	for _, p := range progress {
+		counts := make([]EpicStatusCount, 0, len(statuses)+len(p.Statuses))
+		for _, status := range statuses {
+			counts = append(counts, EpicStatusCount{Status: status})
+		}
+		for _, count := range p.Statuses {
+			if i := slices.Index(statuses, count.Status); i >= 0 {
+				counts[i].Count = count.Count
+			} else {
+				counts = append(counts, count)
+			}
+		}
+		for i := range counts {
+			counts[i].Percent = percentOf(counts[i].Count, p.Total)
+		}
+		p.Statuses = counts
+		p.Percent = percentOf(p.Done, p.Total)
+	}
+	return progress, nil
+}
+
+// percentOf returns n as a whole percentage of total (0 if total is 0).
+func percentOf(n, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return n * 100 / total
+}
+
+// renderEpicProgress renders an epic's (or milestone's) progress after it in
+// modern and L-SON output. Per-status counts and open issues are shown in detail only.
+func renderEpicProgress(p *EpicProgress, detail bool, cmd *cobra.Command, w io.Writer) {
+	if p == nil {
+		return
+	}
+
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatLSON:
+		fmt.Fprintf(w, "@PROGRESS: %d/%d\n", p.Done, p.Total)
+		if !detail {
+			return
+		}
+		for _, count := range p.Statuses {
+			fmt.Fprintf(w, "@COUNT: %s|%d|%d%%\n", count.Status, count.Count, count.Percent)
+		}
+		renderEffort(p.Effort, config.DefaultFormatLSON, w)
+		for _, entry := range p.OpenIssues {
+			fmt.Fprintf(w, "@OPEN: %s|%s|%s\n", entry.ID, entry.Status, entry.Title)
+		}
+	case config.DefaultFormatModern:
+		styles := ui.NewStyles()
+		fmt.Fprintf(w, "%s: %s\n", styles.Label("Progress"), formatProgress(p.Done, p.Total))
+		if !detail {
+			return
+		}
+		for _, count := range p.Statuses {
+			fmt.Fprintf(w, "  %-12s %3d (%d%%)\n", count.Status, count.Count, count.Percent)
+		}
+		renderEffort(p.Effort, config.DefaultFormatModern, w)
+		if len(p.OpenIssues) > 0 {
+			fmt.Fprintf(w, "\n%s (%d):\n", styles.Label("Open issues"), len(p.OpenIssues))
+			for _, entry := range p.OpenIssues {
+				fmt.Fprintf(w, "  - %s %s [%s]\n", styles.ID(entry.ID), entry.Title, styles.StatusColor(entry.Status)(entry.Status))
+			}
+		}
+	}
+}
+
 // NewEpicDeleteCmd creates and returns the epic delete command.
 func NewEpicDeleteCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "delete <id>",
-		Short: "Delete an epic",
-		Long:  "Delete an epic from the project",
-		Args:  cobra.ExactArgs(1),
+		Use:               "delete <id>",
+		Short:             "Delete an epic",
+		Long:              "Delete an epic from the project",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeEpicArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			epicID := args[0]
 			return deleteEpic(epicID, cmd)
@@ -450,38 +806,32 @@ func NewEpicDeleteCmd() *cobra.Command {
 
 // deleteEpic deletes an epic from the project.
 func deleteEpic(epicID string, cmd *cobra.Command) error {
-	// Validate epic ID format
-	if err := validateEpicID(epicID); err != nil {
-		return fmt.Errorf("cli: invalid epic ID format: %w", err)
-	}
-
-	// Resolve project
-	projectKey, err := config.ResolveProject(cmd)
+	projectKey, err := resolveEpicProject(epicID, cmd)
 	if err != nil {
 		return err
 	}
 
 	// Check if epic exists
-	epicPath, err := storage.EpicPath(projectKey, epicID)
+	epicPath, err := storage.EpicPath(cmd.Context(), projectKey, epicID)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve epic path: %w", err)
 	}
 
 	if _, err := os.Stat(epicPath); err != nil {
 		if os.IsNotExist(err) {
-			return fmt.Errorf("cli: epic %q not found", epicID)
+			return notFoundf("cli: epic %q not found", epicID)
 		}
 		return fmt.Errorf("cli: failed to stat epic: %w", err)
 	}
 
 	// Check for issues that reference this epic
-	indexPath, err := storage.ProjectIndexPath(projectKey)
+	indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve index path: %w", err)
 	}
 
 	var index models.ProjectIndex
-	if err := storage.ReadJSON(indexPath, &index); err == nil {
+	if err := storage.ReadIndex(cmd.Context(), indexPath, &index); err == nil {
 		// Check if any issues reference this epic
 		referencedIssues := []string{}
 		for _, entry := range index.Issues {
@@ -512,13 +862,36 @@ func deleteEpic(epicID string, cmd *cobra.Command) error {
 	}
 
 	// Delete epic file atomically (with lock and transaction)
-	if err := storage.DeleteAtomic(epicPath); err != nil {
+	if err := storage.DeleteAtomic(cmd.Context(), epicPath); err != nil {
 		return fmt.Errorf("cli: failed to delete epic: %w", err)
 	}
+	if err := updateEpicIndex(cmd.Context(), projectKey, func(idx *models.ProjectIndex) {
+		idx.RemoveEpic(epicID)
+	}); err != nil {
+		return err
+	}
 
 	// Success message
-	out := cmd.OutOrStdout()
+	out := successOut(cmd)
 	fmt.Fprintf(out, "Deleted epic %q\n", epicID)
 
 	return nil
 }
+
+// updateEpicIndex applies an epic change to the project index, so the epic
+// titles, statuses, and issue counts in the index stay in sync.
+func updateEpicIndex(ctx context.Context, projectKey string, update func(idx *models.ProjectIndex)) error {
+	indexPath, err := storage.ProjectIndexPath(ctx, projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	if err := storage.UpdateIndexAtomic(ctx, indexPath, &models.ProjectIndex{}, func(v interface{}) error {
+		idx := v.(*models.ProjectIndex)
+		update(idx)
+		idx.UpdatedAt = storage.Timestamp(ctx)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("cli: failed to update project index: %w", err)
+	}
+	return nil
+}
@@ -2,10 +2,14 @@ package cli
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"os"
 	"strings"
 	"testing"
 
+	"github.com/buyruk-project/buyruk-cli/internal/config"
 	"github.com/buyruk-project/buyruk-cli/internal/models"
 	"github.com/buyruk-project/buyruk-cli/internal/storage"
 )
@@ -32,7 +36,7 @@ func TestNewEpicCmd(t *testing.T) {
 func TestCreateEpic_Minimal(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -61,18 +65,18 @@ func TestCreateEpic_Minimal(t *testing.T) {
 	}
 
 	// Verify epic was created
-	epicPath, err := storage.EpicPath(projectKey, "E-1")
+	epicPath, err := storage.EpicPath(t.Context(), projectKey, projectKey+"-E1")
 	if err != nil {
 		t.Fatalf("Failed to resolve epic path: %v", err)
 	}
 
 	var epic models.Epic
-	if err := storage.ReadJSON(epicPath, &epic); err != nil {
+	if err := storage.ReadJSON(t.Context(), epicPath, &epic); err != nil {
 		t.Fatalf("Failed to read epic: %v", err)
 	}
 
-	if epic.ID != "E-1" {
-		t.Errorf("Epic ID = %q, want E-1", epic.ID)
+	if epic.ID != projectKey+"-E1" {
+		t.Errorf("Epic ID = %q, want %s-E1", epic.ID, projectKey)
 	}
 	if epic.Title != "Test Epic" {
 		t.Errorf("Epic Title = %q, want 'Test Epic'", epic.Title)
@@ -85,7 +89,7 @@ func TestCreateEpic_Minimal(t *testing.T) {
 func TestCreateEpic_WithCustomID(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -102,7 +106,7 @@ func TestCreateEpic_WithCustomID(t *testing.T) {
 	rootCmd2.SetArgs([]string{
 		"epic", "create",
 		"--project", projectKey,
-		"--id", "CUSTOM-1",
+		"--id", projectKey + "-CUSTOM",
 		"--title", "Custom Epic",
 		"--status", "DOING",
 	})
@@ -116,28 +120,39 @@ func TestCreateEpic_WithCustomID(t *testing.T) {
 	}
 
 	// Verify epic was created with custom ID
-	epicPath, err := storage.EpicPath(projectKey, "CUSTOM-1")
+	epicPath, err := storage.EpicPath(t.Context(), projectKey, projectKey+"-CUSTOM")
 	if err != nil {
 		t.Fatalf("Failed to resolve epic path: %v", err)
 	}
 
 	var epic models.Epic
-	if err := storage.ReadJSON(epicPath, &epic); err != nil {
+	if err := storage.ReadJSON(t.Context(), epicPath, &epic); err != nil {
 		t.Fatalf("Failed to read epic: %v", err)
 	}
 
-	if epic.ID != "CUSTOM-1" {
-		t.Errorf("Epic ID = %q, want CUSTOM-1", epic.ID)
+	if epic.ID != projectKey+"-CUSTOM" {
+		t.Errorf("Epic ID = %q, want %s-CUSTOM", epic.ID, projectKey)
 	}
 	if epic.Status != models.StatusDOING {
 		t.Errorf("Epic Status = %q, want %q", epic.Status, models.StatusDOING)
 	}
+
+	// Custom IDs are scoped to the project like generated ones
+	for _, id := range []string{"CUSTOM-1", "OTHER-E1", projectKey + "-X-E1"} {
+		cmd := NewRootCmd()
+		cmd.SetArgs([]string{"epic", "create", "--project", projectKey, "--id", id, "--title", "Unscoped"})
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+		if err := cmd.Execute(); err == nil {
+			t.Errorf("Expected epic create --id %s to fail", id)
+		}
+	}
 }
 
 func TestViewEpic(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -163,7 +178,7 @@ func TestViewEpic(t *testing.T) {
 	// View epic
 	rootCmd3 := NewRootCmd()
 	rootCmd3.SetArgs([]string{
-		"epic", "view", "E-1",
+		"epic", "view", projectKey + "-E1",
 		"--project", projectKey,
 	})
 
@@ -176,7 +191,7 @@ func TestViewEpic(t *testing.T) {
 	}
 
 	output := buf.String()
-	if !strings.Contains(output, "E-1") {
+	if !strings.Contains(output, projectKey+"-E1") {
 		t.Error("epic view output missing epic ID")
 	}
 	if !strings.Contains(output, "View Test Epic") {
@@ -187,7 +202,7 @@ func TestViewEpic(t *testing.T) {
 func TestDeleteEpic_WithYesFlag(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -213,7 +228,7 @@ func TestDeleteEpic_WithYesFlag(t *testing.T) {
 	// Delete epic with -y flag
 	rootCmd3 := NewRootCmd()
 	rootCmd3.SetArgs([]string{
-		"epic", "delete", "E-1",
+		"epic", "delete", projectKey + "-E1",
 		"--project", projectKey,
 		"-y",
 	})
@@ -227,7 +242,7 @@ func TestDeleteEpic_WithYesFlag(t *testing.T) {
 	}
 
 	// Verify epic was deleted
-	epicPath, err := storage.EpicPath(projectKey, "E-1")
+	epicPath, err := storage.EpicPath(t.Context(), projectKey, projectKey+"-E1")
 	if err != nil {
 		t.Fatalf("Failed to resolve epic path: %v", err)
 	}
@@ -240,7 +255,7 @@ func TestDeleteEpic_WithYesFlag(t *testing.T) {
 func TestDeleteEpic_NonExistent(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -255,7 +270,7 @@ func TestDeleteEpic_NonExistent(t *testing.T) {
 	// Try to delete non-existent epic
 	rootCmd2 := NewRootCmd()
 	rootCmd2.SetArgs([]string{
-		"epic", "delete", "E-999",
+		"epic", "delete", projectKey + "-E999",
 		"--project", projectKey,
 		"-y",
 	})
@@ -274,3 +289,370 @@ func TestDeleteEpic_NonExistent(t *testing.T) {
 		t.Errorf("Expected error about epic not found, got: %v", err)
 	}
 }
+
+func TestEpicProgress(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"epic", "create", "--project", projectKey, "--title", "Onboarding"},
+		{"epic", "create", "--project", projectKey, "--title", "Empty"},
+		{"issue", "create", "--project", projectKey, "--title", "Login page", "--epic", projectKey + "-E1"},
+		{"issue", "create", "--project", projectKey, "--title", "Signup page", "--epic", projectKey + "-E1"},
+		{"issue", "create", "--project", projectKey, "--title", "Welcome email", "--epic", projectKey + "-E1"},
+		{"issue", "create", "--project", projectKey, "--title", "Unrelated"},
+		{"issue", "update", projectKey + "-1", "--status", "DONE"},
+		{"issue", "update", projectKey + "-2", "--status", "DOING"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	out, err := run("epic", "view", projectKey+"-E1", "--project", projectKey, "--format", "json")
+	if err != nil {
+		t.Fatalf("epic view failed: %v", err)
+	}
+	var view EpicWithProgress
+	if err := json.Unmarshal([]byte(out), &view); err != nil {
+		t.Fatalf("Failed to parse epic view JSON: %v", err)
+	}
+	if view.Epic == nil || view.Title != "Onboarding" {
+		t.Errorf("Expected epic fields in JSON output, got: %s", out)
+	}
+	p := view.Progress
+	if p == nil || p.Done != 1 || p.Total != 3 || p.Percent != 33 {
+		t.Fatalf("Expected progress 1/3 (33%%), got %+v", p)
+	}
+	want := []EpicStatusCount{{"TODO", 1, 33}, {"DOING", 1, 33}, {"DONE", 1, 33}}
+	if len(p.Statuses) != len(want) {
+		t.Fatalf("Expected %d status counts, got %+v", len(want), p.Statuses)
+	}
+	for i, count := range want {
+		if p.Statuses[i] != count {
+			t.Errorf("Expected status count %+v, got %+v", count, p.Statuses[i])
+		}
+	}
+	if len(p.OpenIssues) != 2 {
+		t.Errorf("Expected 2 open issues, got %+v", p.OpenIssues)
+	}
+	for _, entry := range p.OpenIssues {
+		if entry.ID == projectKey+"-1" {
+			t.Error("Expected done issue not to be listed as open")
+		}
+	}
+
+	out, err = run("epic", "view", projectKey+"-E1", "--project", projectKey, "--format", "lson")
+	if err != nil {
+		t.Fatalf("epic view failed: %v", err)
+	}
+	for _, line := range []string{"@PROGRESS: 1/3", "@COUNT: DOING|1|33%", "@OPEN: " + projectKey + "-2|DOING|Signup page"} {
+		if !strings.Contains(out, line) {
+			t.Errorf("Expected L-SON output to contain %q, got: %s", line, out)
+		}
+	}
+
+	out, err = run("epic", "list", "--project", projectKey, "--with-progress", "--format", "json")
+	if err != nil {
+		t.Fatalf("epic list failed: %v", err)
+	}
+	var list []EpicWithProgress
+	if err := json.Unmarshal([]byte(out), &list); err != nil {
+		t.Fatalf("Failed to parse epic list JSON: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("Expected 2 epics, got %d", len(list))
+	}
+	for _, item := range list {
+		if item.Progress == nil {
+			t.Fatalf("Expected progress for %s", item.ID)
+		}
+		if item.ID == projectKey+"-E2" && (item.Progress.Total != 0 || item.Progress.Percent != 0) {
+			t.Errorf("Expected empty epic to have no progress, got %+v", item.Progress)
+		}
+	}
+
+	out, err = run("epic", "list", "--project", projectKey, "--format", "json")
+	if err != nil {
+		t.Fatalf("epic list failed: %v", err)
+	}
+	if strings.Contains(out, "progress") {
+		t.Error("Expected no progress without --with-progress")
+	}
+}
+
+func TestEpicIssues(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"epic", "create", "--project", projectKey, "--title", "Onboarding"},
+		{"issue", "create", "--project", projectKey, "--title", "Login page", "--epic", projectKey + "-E1"},
+		{"issue", "create", "--project", projectKey, "--title", "Signup page", "--epic", projectKey + "-E1"},
+		{"issue", "create", "--project", projectKey, "--title", "Unrelated"},
+		{"epic", "update", projectKey + "-E1", "--project", projectKey, "--title", "Onboarding flow", "--status", "DOING"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	index, err := loadQueryIndex(t.Context(), projectKey)
+	if err != nil {
+		t.Fatalf("Failed to load index: %v", err)
+	}
+	epic := index.FindEpic(projectKey + "-E1")
+	if epic == nil || epic.Title != "Onboarding flow" || epic.Status != "DOING" || epic.Issues != 2 {
+		t.Fatalf("Expected epic in index with updated title, status, and 2 issues, got %+v", epic)
+	}
+
+	out, err := run("epic", "issues", projectKey+"-E1", "--project", projectKey, "--format", "json")
+	if err != nil {
+		t.Fatalf("epic issues failed: %v", err)
+	}
+	var entries []models.IndexEntry
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		t.Fatalf("Failed to parse epic issues JSON: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Title != "Login page" || entries[1].Title != "Signup page" {
+		t.Errorf("Expected the two epic issues, got %+v", entries)
+	}
+
+	out, err = run("epic", "issues", projectKey+"-E1", "--project", projectKey, "--format", "lson")
+	if err != nil {
+		t.Fatalf("epic issues failed: %v", err)
+	}
+	if !strings.Contains(out, "@TITLE: Login page") || strings.Contains(out, "Unrelated") {
+		t.Errorf("Unexpected L-SON output: %s", out)
+	}
+
+	// Moving an issue to another epic updates both counts
+	if _, err := run("epic", "create", "--project", projectKey, "--title", "Billing"); err != nil {
+		t.Fatalf("epic create failed: %v", err)
+	}
+	if _, err := run("issue", "update", projectKey+"-2", "--epic", projectKey+"-E2"); err != nil {
+		t.Fatalf("issue update failed: %v", err)
+	}
+	index, _ = loadQueryIndex(t.Context(), projectKey)
+	if index.FindEpic(projectKey+"-E1").Issues != 1 || index.FindEpic(projectKey+"-E2").Issues != 1 {
+		t.Errorf("Expected one issue per epic, got %+v", index.Epics)
+	}
+	if _, err := run("epic", "issues", projectKey+"-E9", "--project", projectKey); err == nil {
+		t.Error("Expected missing epic to fail")
+	}
+	if _, err := run("epic", "delete", projectKey+"-E1", "--project", projectKey, "--yes"); err != nil {
+		t.Fatalf("epic delete failed: %v", err)
+	}
+	index, _ = loadQueryIndex(t.Context(), projectKey)
+	if index.FindEpic(projectKey+"-E1") != nil {
+		t.Error("Expected deleted epic to be removed from the index")
+	}
+}
+
+func TestEpicStatusRollup(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	originalCfg, _ := config.Get()
+	defer func() {
+		if originalCfg != nil {
+			config.Save(t.Context(), originalCfg)
+		}
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) {
+		t.Helper()
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+	epicStatus := func(epicID string) string {
+		t.Helper()
+		epicPath, _ := storage.EpicPath(t.Context(), projectKey, epicID)
+		var epic models.Epic
+		if err := storage.ReadJSON(t.Context(), epicPath, &epic); err != nil {
+			t.Fatalf("Failed to read epic %s: %v", epicID, err)
+		}
+		index, err := loadQueryIndex(t.Context(), projectKey)
+		if err != nil {
+			t.Fatalf("Failed to read index: %v", err)
+		}
+		if entry := index.FindEpic(epicID); entry == nil || entry.Status != epic.Status {
+			t.Errorf("Expected the index to have status %q for %s, got %+v", epic.Status, epicID, entry)
+		}
+		return epic.Status
+	}
+
+	run("config", "set", "epic_status", "manual")
+	run("project", "create", projectKey)
+	run("epic", "create", "--project", projectKey, "--title", "Onboarding")
+	run("epic", "create", "--project", projectKey, "--title", "Billing")
+	run("issue", "create", "--project", projectKey, "--title", "Login page", "--epic", projectKey+"-E1")
+	run("issue", "create", "--project", projectKey, "--title", "Signup page", "--epic", projectKey+"-E1")
+	run("issue", "update", projectKey+"-1", "--status", "DOING")
+	if got := epicStatus(projectKey + "-E1"); got != "TODO" {
+		t.Errorf("Expected manual epic status to stay TODO, got %q", got)
+	}
+
+	run("config", "set", "epic_status", "auto")
+	run("issue", "update", projectKey+"-1", "--status", "DONE")
+	if got := epicStatus(projectKey + "-E1"); got != "DOING" {
+		t.Errorf("Expected E-1 to be DOING with one of two issues done, got %q", got)
+	}
+	run("issue", "update", projectKey+"-2", "--status", "DONE")
+	if got := epicStatus(projectKey + "-E1"); got != "DONE" {
+		t.Errorf("Expected E-1 to be DONE with all issues done, got %q", got)
+	}
+
+	// Moving an issue to another epic rolls up both
+	run("issue", "create", "--project", projectKey, "--title", "Invoices", "--epic", projectKey+"-E1")
+	if got := epicStatus(projectKey + "-E1"); got != "DOING" {
+		t.Errorf("Expected E-1 to be DOING after a new open issue, got %q", got)
+	}
+	run("issue", "update", projectKey+"-3", "--epic", projectKey+"-E2")
+	if got := epicStatus(projectKey + "-E1"); got != "DONE" {
+		t.Errorf("Expected E-1 to be DONE again once the open issue left, got %q", got)
+	}
+	if got := epicStatus(projectKey + "-E2"); got != "TODO" {
+		t.Errorf("Expected E-2 to be TODO with no started issue, got %q", got)
+	}
+
+	run("issue", "update", projectKey+"-3", "--status", "DOING")
+	run("issue", "delete", projectKey+"-3", "--yes")
+	if got := epicStatus(projectKey + "-E2"); got != "DOING" {
+		t.Errorf("Expected an epic left without issues to keep its status, got %q", got)
+	}
+}
+
+func TestEpicStatusRollup_FailedIndexWrite(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	originalCfg, _ := config.Get()
+	defer func() {
+		if originalCfg != nil {
+			config.Save(t.Context(), originalCfg)
+		}
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	for _, args := range [][]string{
+		{"config", "set", "epic_status", "auto"},
+		{"project", "create", projectKey},
+		{"epic", "create", "--project", projectKey, "--title", "Onboarding"},
+		{"issue", "create", "--project", projectKey, "--title", "Login page", "--epic", projectKey + "-E1"},
+		{"issue", "create", "--project", projectKey, "--title", "Signup page", "--epic", projectKey + "-E1"},
+	} {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	// The index write fails after the rollup: the epic keeps its status
+	indexPath, _ := storage.ProjectIndexPath(t.Context(), projectKey)
+	failIndex := func(ctx context.Context, path string, data []byte) ([]byte, error) {
+		data, err := issueWriteFilter(ctx, path, data)
+		if err == nil && path == indexPath {
+			err = errors.New("disk full")
+		}
+		return data, err
+	}
+	ctx := storage.WithEnv(t.Context(), storage.Env{WriteFilter: failIndex, AfterWrite: applyEpicRollups})
+	ctx = withRunState(ctx, &runState{epicStatus: config.EpicStatusAuto})
+	if err := updateEpicIndex(ctx, projectKey, func(idx *models.ProjectIndex) {
+		idx.FindIssue(projectKey + "-1").Status = "DONE"
+		idx.FindIssue(projectKey + "-2").Status = "DONE"
+	}); err == nil {
+		t.Fatal("Expected the index update to fail")
+	}
+	epicPath, _ := storage.EpicPath(t.Context(), projectKey, projectKey+"-E1")
+	var epic models.Epic
+	if err := storage.ReadJSON(ctx, epicPath, &epic); err != nil {
+		t.Fatalf("Failed to read epic: %v", err)
+	}
+	if epic.Status == "DONE" {
+		t.Errorf("Expected the epic not to be rolled up by a failed index write, got %q", epic.Status)
+	}
+}
+
+func TestEpicStatusRollup_InIndexWrite(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	originalCfg, _ := config.Get()
+	defer func() {
+		if originalCfg != nil {
+			config.Save(t.Context(), originalCfg)
+		}
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	for _, args := range [][]string{
+		{"config", "set", "epic_status", "auto"},
+		{"project", "create", projectKey},
+		{"epic", "create", "--project", projectKey, "--title", "Onboarding"},
+		{"issue", "create", "--project", projectKey, "--title", "Login page", "--epic", projectKey + "-E1"},
+	} {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	// The epic is rolled up by the index write itself, with no command left
+	// to finish afterwards
+	ctx := storage.WithEnv(t.Context(), storage.Env{WriteFilter: issueWriteFilter, AfterWrite: applyEpicRollups})
+	ctx = withRunState(ctx, &runState{epicStatus: config.EpicStatusAuto})
+	if err := updateEpicIndex(ctx, projectKey, func(idx *models.ProjectIndex) {
+		idx.FindIssue(projectKey + "-1").Status = "DONE"
+	}); err != nil {
+		t.Fatalf("Index update failed: %v", err)
+	}
+
+	epicPath, _ := storage.EpicPath(t.Context(), projectKey, projectKey+"-E1")
+	var epic models.Epic
+	if err := storage.ReadJSON(ctx, epicPath, &epic); err != nil {
+		t.Fatalf("Failed to read epic: %v", err)
+	}
+	index, _ := loadQueryIndex(t.Context(), projectKey)
+	if epic.Status != "DONE" || index.FindEpic(epic.ID).Status != "DONE" {
+		t.Errorf("Expected the epic to be rolled up to DONE in its file and the index, got %q and %+v", epic.Status, index.FindEpic(epic.ID))
+	}
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+// Exit codes of the buyruk command. Scripts can rely on them: they don't
+// change between releases.
+const (
+	ExitOK          = 0 // Success
+	ExitFailure     = 1 // Any other error
+	ExitNotFound    = 2 // A project, issue, epic, or other item doesn't exist
+	ExitInvalid     = 3 // Invalid arguments, flags, or data
+	ExitLockTimeout = 4 // The project is locked by another process
+	ExitConflict    = 5 // The item already exists or was changed concurrently
+)
+
+// Error kinds, matched with errors.Is to pick the exit code of an error.
+var (
+	ErrNotFound = errors.New("not found")
+	ErrInvalid  = errors.New("invalid")
+	ErrConflict = errors.New("conflict")
+)
+
+// kindError tags an error with one of the error kinds without changing its
+// message.
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// notFoundf formats an error, like fmt.Errorf, of kind ErrNotFound.
+func notFoundf(format string, args ...any) error {
+	return &kindError{kind: ErrNotFound, err: fmt.Errorf(format, args...)}
+}
+
+// invalidf formats an error, like fmt.Errorf, of kind ErrInvalid.
+func invalidf(format string, args ...any) error {
+	return &kindError{kind: ErrInvalid, err: fmt.Errorf(format, args...)}
+}
+
+// conflictf formats an error, like fmt.Errorf, of kind ErrConflict.
+func conflictf(format string, args ...any) error {
+	return &kindError{kind: ErrConflict, err: fmt.Errorf(format, args...)}
+}
+
+// exitCodeOf returns the exit code for the kind of an error.
+func exitCodeOf(err error) int {
+	switch {
+	case errors.Is(err, storage.ErrLockTimeout):
+		return ExitLockTimeout
+	case errors.Is(err, ErrNotFound):
+		return ExitNotFound
+	case errors.Is(err, ErrInvalid):
+		return ExitInvalid
+	case errors.Is(err, ErrConflict):
+		return ExitConflict
+	}
+	return ExitFailure
+}
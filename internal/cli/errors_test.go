@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestExitCode_Kinds(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"not found", notFoundf("cli: issue %q not found", "CORE-1"), ExitNotFound},
+		{"invalid", invalidf("cli: invalid status %q", "NOPE"), ExitInvalid},
+		{"conflict", conflictf("cli: project %q already exists", "CORE"), ExitConflict},
+		{"wrapped", fmt.Errorf("cli: failed to update issue: %w", notFoundf("cli: epic %q not found", "E-1")), ExitNotFound},
+		{"lock timeout", fmt.Errorf("cli: failed to acquire lock: %w", fmt.Errorf("%w after 5s", storage.ErrLockTimeout)), ExitLockTimeout},
+		{"exit error wins", &ExitError{Code: QueryExitFalse, Err: invalidf("cli: invalid")}, QueryExitFalse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	// Kinds don't change the message
+	err := notFoundf("cli: issue %q not found", "CORE-1")
+	if err.Error() != `cli: issue "CORE-1" not found` {
+		t.Errorf("Expected the message to be kept, got %q", err.Error())
+	}
+	if !errors.Is(err, ErrNotFound) || errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected the error to be of kind ErrNotFound only")
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, int) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(append(args, "--project", projectKey, "--no-hooks"))
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		code := ExitCode(cmd.Execute())
+		return out.String(), code
+	}
+
+	if _, code := run("project", "create", projectKey); code != ExitOK {
+		t.Fatalf("project create exited with %d", code)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"missing issue", []string{"view", projectKey + "-9"}, ExitNotFound},
+		{"missing epic", []string{"epic", "view", "E-9"}, ExitNotFound},
+		{"invalid status", []string{"issue", "create", "--title", "Login", "--status", "NOPE"}, ExitInvalid},
+		{"unknown flag", []string{"list", "--nope"}, ExitInvalid},
+		{"missing argument", []string{"view"}, ExitInvalid},
+		{"existing project", []string{"project", "create", projectKey}, ExitConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, code := run(tt.args...); code != tt.want {
+				t.Errorf("%v exited with %d, want %d", tt.args, code, tt.want)
+			}
+		})
+	}
+
+	out, code := run("--quiet", "issue", "create", "--title", "Login")
+	if code != ExitOK || out != "" {
+		t.Errorf("Expected a quiet create to succeed silently, got %d and %q", code, out)
+	}
+	if out, _ := run("--quiet", "view", projectKey+"-1", "--format", "json"); out == "" {
+		t.Error("Expected --quiet to keep the requested output")
+	}
+}
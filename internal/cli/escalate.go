@@ -0,0 +1,303 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/timeparse"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// escalatedLabel flags issues that waited too long in the first status.
+	escalatedLabel = "escalated"
+
+	// escalateUnprioritized is the escalate.<priority> key of issues
+	// without a priority.
+	escalateUnprioritized = "none"
+)
+
+// Escalation is an issue that waited too long in the first status, and what
+// buyruk escalate did about it.
+type Escalation struct {
+	ID            string `json:"id"`
+	Title         string `json:"title"`
+	From          string `json:"from,omitempty"`    // Priority before
+	To            string `json:"to,omitempty"`      // Priority after, empty when flagged
+	Flagged       bool   `json:"flagged,omitempty"` // Labeled escalated instead
+	WaitedSeconds int64  `json:"waited_seconds"`    // Time in the first status
+	Threshold     string `json:"threshold"`         // The escalate.<priority> setting that was exceeded
+}
+
+// NewEscalateCmd creates and returns the escalate command.
+func NewEscalateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "escalate",
+		Short: "Bump the priority of issues left too long in the first status",
+		Long: `Find the issues of a project that have waited in the first workflow status
+(TODO by default) longer than the threshold of their priority, and escalate
+them: bump raises the priority one level, flag adds the "escalated" label.
+Issues at the highest priority are flagged.
+
+Thresholds are set per priority, with "none" for issues without one:
+
+  buyruk config set escalate.LOW 30d
+  buyruk config set escalate.MEDIUM 14d
+  buyruk config set escalate_action flag
+
+The wait restarts when an issue is escalated, so running escalate from a
+daily job raises a forgotten issue one level per threshold.`,
+		Example: `  buyruk escalate --project CORE --dry-run
+  buyruk escalate --project CORE --action flag`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return escalateIssues(cmd)
+		},
+	}
+
+	cmd.Flags().String("action", "", "bump or flag (default: the escalate_action setting)")
+	cmd.Flags().Bool("dry-run", false, "Show the issues that would be escalated without changing them")
+
+	return cmd
+}
+
+// escalateIssues escalates the issues of a project that waited longer than
+// their threshold in the first status.
+func escalateIssues(cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("cli: failed to load config: %w", err)
+	}
+	action, _ := cmd.Flags().GetString("action")
+	if action == "" {
+		action = cfg.EscalateActionMode()
+	}
+	if action != config.EscalateBump && action != config.EscalateFlag {
+		return invalidf("cli: invalid --action %q (must be %s or %s)", action, config.EscalateBump, config.EscalateFlag)
+	}
+	thresholds := map[string]time.Duration{}
+	for priority, after := range cfg.Escalate {
+		d, err := timeparse.ParseDuration(after)
+		if err != nil {
+			return fmt.Errorf("cli: invalid %s%s %q: %w", config.EscalateKeyPrefix, priority, after, err)
+		}
+		thresholds[strings.ToUpper(priority)] = d
+	}
+	if len(thresholds) == 0 {
+		return invalidf("cli: no escalation thresholds are set (e.g. buyruk config set %sLOW 30d)", config.EscalateKeyPrefix)
+	}
+
+	wf, err := loadWorkflow(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	if _, err := loadQueryIndex(cmd.Context(), projectKey); err != nil {
+		return err
+	}
+
+	cleanup, err := storage.AcquireLock(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to acquire lock: %w", err)
+	}
+	defer cleanup()
+
+	indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(cmd.Context(), indexPath, &index); err != nil {
+		return fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	priorities := wf.PriorityList()
+	waiting := wf.DefaultStatus()
+	now := storage.Now(cmd.Context())
+	stamp := storage.Timestamp(cmd.Context())
+	var writes []fileWrite
+	escalations := []Escalation{}
+	for _, entry := range index.Issues {
+		if entry.Status != waiting {
+			continue
+		}
+		key := strings.ToUpper(entry.Priority)
+		if key == "" {
+			key = strings.ToUpper(escalateUnprioritized)
+		}
+		threshold, ok := thresholds[key]
+		if !ok {
+			continue
+		}
+
+		issuePath, err := storage.IssuePath(cmd.Context(), projectKey, entry.ID)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+		}
+		// Re-read under the lock so concurrent edits are kept
+		original, err := os.ReadFile(issuePath)
+		if err != nil {
+			return fmt.Errorf("cli: failed to read issue %s: %w", entry.ID, err)
+		}
+		var issue models.Issue
+		if err := json.Unmarshal(original, &issue); err != nil {
+			return fmt.Errorf("cli: failed to parse issue %s: %w", entry.ID, err)
+		}
+		since, ok := waitingSince(&issue, waiting)
+		if !ok || now.Sub(since) <= threshold {
+			continue
+		}
+
+		escalation := Escalation{
+			ID:            issue.ID,
+			Title:         issue.Title,
+			From:          issue.Priority,
+			WaitedSeconds: int64(now.Sub(since).Seconds()),
+			Threshold:     lookupEscalateThreshold(cfg.Escalate, key),
+		}
+		next := nextPriority(priorities, issue.Priority)
+		if action == config.EscalateBump && next != "" {
+			escalation.To = next
+			issue.Priority = next
+		} else {
+			if slices.Contains(issue.Labels, escalatedLabel) {
+				continue
+			}
+			escalation.Flagged = true
+			issue.AddLabel(escalatedLabel)
+		}
+		escalations = append(escalations, escalation)
+
+		issue.History = append(issue.History, models.HistoryEvent{At: stamp, Type: models.HistoryEscalated, Detail: escalation.To})
+		issue.UpdatedAt = stamp
+		data, err := json.MarshalIndent(&issue, "", "  ")
+		if err != nil {
+			return fmt.Errorf("cli: failed to marshal issue %s: %w", issue.ID, err)
+		}
+		writes = append(writes, fileWrite{path: issuePath, data: data, original: original})
+		index.AddIssue(&issue)
+	}
+
+	if !dryRun && len(escalations) > 0 {
+		index.UpdatedAt = stamp
+		indexWrite, err := plannedIndexWrite(indexPath, &index)
+		if err != nil {
+			return err
+		}
+		writes = append(writes, indexWrite)
+
+		if err := storage.BeginTransaction(cmd.Context(), projectKey, "escalate", map[string]interface{}{
+			"action": action,
+			"issues": len(escalations),
+		}); err != nil {
+			return fmt.Errorf("cli: failed to begin transaction: %w", err)
+		}
+		if err := writeFiles(cmd.Context(), writes); err != nil {
+			storage.RollbackTransaction(cmd.Context(), projectKey)
+			return err
+		}
+		if err := storage.CommitTransaction(cmd.Context(), projectKey); err != nil {
+			return fmt.Errorf("cli: failed to commit transaction: %w", err)
+		}
+	}
+
+	return renderEscalations(escalations, projectKey, dryRun, cmd)
+}
+
+// waitingSince returns when an issue last entered the waiting status or was
+// last escalated, whichever is later. Issues created before history was
+// recorded count from their creation.
+func waitingSince(issue *models.Issue, waiting string) (time.Time, bool) {
+	at := issue.CreatedAt
+	for _, event := range issue.History {
+		if (event.To == waiting && event.From != "") || event.Type == models.HistoryEscalated {
+			at = event.At
+		}
+	}
+	t, err := time.Parse(time.RFC3339, at)
+	return t, err == nil
+}
+
+// nextPriority returns the priority one level above current, the lowest
+// priority for an issue without one, or "" at the highest priority.
+func nextPriority(priorities []string, current string) string {
+	if current == "" {
+		return priorities[0]
+	}
+	i := slices.Index(priorities, current)
+	if i < 0 || i == len(priorities)-1 {
+		return ""
+	}
+	return priorities[i+1]
+}
+
+// lookupEscalateThreshold finds a threshold setting whatever the case of its
+// priority.
+func lookupEscalateThreshold(settings map[string]string, priority string) string {
+	for key, after := range settings {
+		if strings.EqualFold(key, priority) {
+			return after
+		}
+	}
+	return ""
+}
+
+// renderEscalations reports the escalated issues.
+func renderEscalations(escalations []Escalation, projectKey string, dryRun bool, cmd *cobra.Command) error {
+	out := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(escalations)
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(out, escalations)
+	case config.DefaultFormatLSON:
+		for _, e := range escalations {
+			change := e.From + ">" + e.To
+			if e.Flagged {
+				change = escalatedLabel
+			}
+			fmt.Fprintf(out, "@ESCALATED: %s|%s|%s|%s|%s\n", e.ID, e.Title, change, ui.FormatElapsed(time.Duration(e.WaitedSeconds)*time.Second), e.Threshold)
+		}
+		return nil
+	}
+
+	if !dryRun {
+		out = successOut(cmd)
+	}
+	if len(escalations) == 0 {
+		fmt.Fprintf(out, "No issues in project %q waited past their threshold\n", projectKey)
+		return nil
+	}
+	verb, flagVerb := "Bumped", "Flagged"
+	if dryRun {
+		verb, flagVerb = "Would bump", "Would flag"
+	}
+	styles := ui.NewStyles()
+	for _, e := range escalations {
+		waited := ui.FormatElapsed(time.Duration(e.WaitedSeconds) * time.Second)
+		from := e.From
+		if from == "" {
+			from = "no priority"
+		}
+		if e.Flagged {
+			fmt.Fprintf(out, "%s %s %s (waited %s, over %s at %s)\n", flagVerb, styles.ID(e.ID), e.Title, waited, e.Threshold, from)
+			continue
+		}
+		fmt.Fprintf(out, "%s %s %s from %s to %s (waited %s, over %s)\n", verb, styles.ID(e.ID), e.Title, from, e.To, waited, e.Threshold)
+	}
+	return nil
+}
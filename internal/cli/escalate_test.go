@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestEscalate(t *testing.T) {
+	originalCfg, _ := config.Get()
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		if originalCfg != nil {
+			config.Save(t.Context(), originalCfg)
+		}
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	id := func(n string) string { return projectKey + "-" + n }
+	created := "2024-06-01T09:00:00Z"
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "Old low", "--priority", "LOW", "--fixed-time", created},
+		{"issue", "create", "--project", projectKey, "--title", "Old critical", "--priority", "CRITICAL", "--fixed-time", created},
+		{"issue", "create", "--project", projectKey, "--title", "Started", "--priority", "LOW", "--fixed-time", created},
+		{"issue", "update", id("3"), "--status", "DOING", "--fixed-time", created},
+		{"issue", "create", "--project", projectKey, "--title", "Recent", "--priority", "LOW", "--fixed-time", "2024-06-20T09:00:00Z"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	if _, err := run("escalate", "--project", projectKey); err == nil {
+		t.Error("Expected escalate without thresholds to fail")
+	}
+	for _, args := range [][]string{
+		{"config", "set", "escalate.LOW", "14d"},
+		{"config", "set", "escalate.CRITICAL", "7d"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+	if _, err := run("config", "set", "escalate.LOW", "soon"); err == nil {
+		t.Error("Expected an invalid threshold to fail")
+	}
+
+	escalate := func(args ...string) []Escalation {
+		t.Helper()
+		out, err := run(append([]string{"escalate", "--project", projectKey, "--format", "json", "--fixed-time", "2024-06-25T09:00:00Z"}, args...)...)
+		if err != nil {
+			t.Fatalf("escalate %v failed: %v", args, err)
+		}
+		var escalations []Escalation
+		if err := json.Unmarshal([]byte(out), &escalations); err != nil {
+			t.Fatalf("Failed to parse escalations: %v\n%s", err, out)
+		}
+		return escalations
+	}
+
+	dry := escalate("--dry-run")
+	if len(dry) != 2 || dry[0].ID != id("1") || dry[0].To != models.PriorityMEDIUM || dry[1].ID != id("2") || !dry[1].Flagged {
+		t.Fatalf("Unexpected dry run: %+v", dry)
+	}
+	if issue, _ := loadLocalIssue(t.Context(), projectKey, id("1")); issue.Priority != models.PriorityLOW {
+		t.Errorf("Dry run changed the priority to %s", issue.Priority)
+	}
+
+	escalate()
+	low, _ := loadLocalIssue(t.Context(), projectKey, id("1"))
+	if low.Priority != models.PriorityMEDIUM || low.History[len(low.History)-1].Type != models.HistoryEscalated {
+		t.Errorf("Expected %s bumped to MEDIUM with history, got %s %+v", low.ID, low.Priority, low.History)
+	}
+	critical, _ := loadLocalIssue(t.Context(), projectKey, id("2"))
+	if critical.Priority != models.PriorityCRITICAL || !slices.Contains(critical.Labels, escalatedLabel) {
+		t.Errorf("Expected %s flagged, got %s %v", critical.ID, critical.Priority, critical.Labels)
+	}
+
+	// The wait restarts once escalated, and flagged issues stay flagged once
+	if again := escalate(); len(again) != 0 {
+		t.Errorf("Expected nothing to escalate twice, got %+v", again)
+	}
+
+	out, err := run("issue", "flow", id("1"), "--format", "modern")
+	if err != nil {
+		t.Fatalf("issue flow failed: %v", err)
+	}
+	if !strings.Contains(out, "Escalated to MEDIUM") {
+		t.Errorf("Expected escalation in flow, got: %s", out)
+	}
+}
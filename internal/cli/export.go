@@ -1,25 +1,56 @@
 package cli
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
-	"time"
 
+	"github.com/buyruk-project/buyruk-cli/internal/config"
 	"github.com/buyruk-project/buyruk-cli/internal/models"
 	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
 	"github.com/spf13/cobra"
 )
 
-// ExportData represents the structure of an exported project
+// ExportData represents the structure of an exported project. Changes to it
+// need a new version in exportVersions with converters to and from the last one.
 type ExportData struct {
-	Version    string               `json:"version"`     // Export format version
-	ExportedAt string               `json:"exported_at"` // ISO 8601 timestamp
-	Project    *models.ProjectIndex `json:"project"`     // Project index
-	Issues     []*models.Issue      `json:"issues"`      // All issues
-	Epics      []*models.Epic       `json:"epics"`       // All epics (if any)
+	Version    string               `json:"version"`            // Export format version
+	ExportedAt string               `json:"exported_at"`        // ISO 8601 timestamp
+	Project    *models.ProjectIndex `json:"project"`            // Project index
+	Issues     []*models.Issue      `json:"issues"`             // All issues
+	Epics      []*models.Epic       `json:"epics"`              // All epics (if any)
+	Workflow   *models.Workflow     `json:"workflow,omitempty"` // Custom project workflow (if any, since 1.1)
+	Views      []models.SavedView   `json:"views,omitempty"`    // Saved views of the project (if any, since 1.1)
+	Partial    bool                 `json:"partial,omitempty"`  // Only a subset of the issues, for import --merge (since 1.3)
+}
+
+// exportRecord is one line of an NDJSON export. Exactly one field is set:
+// the export header first, then the project, workflow, saved views, epics,
+// and issues.
+type exportRecord struct {
+	Export   *exportHeader        `json:"export,omitempty"`
+	Project  *models.ProjectIndex `json:"project,omitempty"`
+	Workflow *models.Workflow     `json:"workflow,omitempty"`
+	View     *models.SavedView    `json:"view,omitempty"`
+	Epic     *models.Epic         `json:"epic,omitempty"`
+	Issue    *models.Issue        `json:"issue,omitempty"`
+}
+
+// exportHeader is the version and time of an NDJSON export.
+type exportHeader struct {
+	Version    string `json:"version"`
+	ExportedAt string `json:"exported_at"`
+	Partial    bool   `json:"partial,omitempty"`
 }
 
 // NewExportCmd creates and returns the export command.
@@ -27,65 +58,90 @@ func NewExportCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "export <project>",
 		Short: "Export a project",
-		Long:  "Export a project to a portable JSON file",
-		Args:  cobra.ExactArgs(1),
+		Long: `Export a project to a portable JSON file (or YAML with --format yaml or a
+.yaml/.yml output path).
+
+With a .tar.gz/.tgz output path the export is a gzipped tarball holding the
+JSON export as export.json and the files attached to issues under
+attachments/<id>/, which buyruk import restores.
+
+With --format ndjson (or a .ndjson/.jsonl output path) the export is written as
+newline-delimited JSON, one record per line: {"export": ...} first, then
+{"project": ...}, {"workflow": ...}, one {"view": ...} per saved view, one
+{"epic": ...} per epic, and one {"issue": ...} per issue. Use --output - to stream it to stdout, e.g.
+
+  buyruk export CORE --format ndjson --output - | jq -c 'select(.issue) | .issue'
+
+--status, --epic, --since, and --ids export a subset of the issues (all given
+filters must match), with the epics they belong to and the project workflow.
+The partial export is marked as such: import --merge applies it to an existing
+project, and import creates a new project from it, but it never replaces a
+project with --overwrite.
+
+--schema-version writes an older version of the export format for older
+buyruk releases; data the older version cannot hold is left out with a
+warning. buyruk import reads every version.`,
+		Example: `  buyruk export CORE --output core.tar.gz
+  buyruk export CORE --status DOING --output - | ssh laptop buyruk import - --merge`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeProjectArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			projectKey := args[0]
 			return exportProject(projectKey, cmd)
 		},
 	}
 
-	cmd.Flags().String("output", "", "Output file path (default: <project>.json)")
+	cmd.Flags().String("output", "", "Output file path, or - for stdout (default: <project>.json)")
+	cmd.Flags().String("status", "", "Only export issues with these statuses (comma-separated)")
+	cmd.Flags().String("epic", "", "Only export issues of these epics (comma-separated, \"none\" for no epic)")
+	cmd.Flags().String("since", "", "Only export issues updated since then (e.g. 2024-06-01, monday, \"2w ago\")")
+	cmd.Flags().String("ids", "", "Only export these issues (comma-separated IDs)")
+	cmd.Flags().String("schema-version", CurrentExportVersion, "Export format version to write ("+exportVersionList()+")")
 
 	return cmd
 }
 
-// exportProject exports a project to a JSON file.
-func exportProject(projectKey string, cmd *cobra.Command) error {
+// loadExportData loads a project with its issues, epics, and custom workflow.
+// Issues and epics that cannot be loaded are skipped with a warning.
+func loadExportData(ctx context.Context, projectKey string, errOut io.Writer) (*ExportData, error) {
 	// Validate project exists
-	projectDir, err := storage.ProjectDir(projectKey)
+	projectDir, err := storage.ProjectDir(ctx, projectKey)
 	if err != nil {
-		return fmt.Errorf("cli: failed to resolve project directory: %w", err)
+		return nil, fmt.Errorf("cli: failed to resolve project directory: %w", err)
 	}
 
 	if _, err := os.Stat(projectDir); os.IsNotExist(err) {
-		return fmt.Errorf("cli: project %q does not exist", projectKey)
+		return nil, notFoundf("cli: project %q does not exist", projectKey)
 	}
 
 	// Load project index
-	indexPath, err := storage.ProjectIndexPath(projectKey)
+	indexPath, err := storage.ProjectIndexPath(ctx, projectKey)
 	if err != nil {
-		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+		return nil, fmt.Errorf("cli: failed to resolve index path: %w", err)
 	}
 
 	var index models.ProjectIndex
-	if err := storage.ReadJSON(indexPath, &index); err != nil {
-		return fmt.Errorf("cli: failed to load project index: %w", err)
+	if err := storage.ReadIndex(ctx, indexPath, &index); err != nil {
+		return nil, fmt.Errorf("cli: failed to load project index: %w", err)
 	}
 
-	// Load all issues
+	// Load all issues, several files at a time
+	ids := make([]string, len(index.Issues))
+	for i, entry := range index.Issues {
+		ids[i] = entry.ID
+	}
 	issues := []*models.Issue{}
-	for _, entry := range index.Issues {
-		issuePath, err := storage.IssuePath(projectKey, entry.ID)
-		if err != nil {
-			errOut := cmd.ErrOrStderr()
-			fmt.Fprintf(errOut, "Warning: failed to resolve path for issue %s: %v\n", entry.ID, err)
+	for _, file := range storage.ReadIssues(ctx, projectKey, ids) {
+		if file.Err != nil {
+			fmt.Fprintf(errOut, "Warning: failed to load issue %s: %v\n", file.ID, file.Err)
 			continue
 		}
-
-		var issue models.Issue
-		if err := storage.ReadJSON(issuePath, &issue); err != nil {
-			errOut := cmd.ErrOrStderr()
-			fmt.Fprintf(errOut, "Warning: failed to load issue %s: %v\n", entry.ID, err)
-			continue
-		}
-
-		issues = append(issues, &issue)
+		issues = append(issues, file.Issue)
 	}
 
 	// Load all epics (if epic directory exists and has files)
 	epics := []*models.Epic{}
-	epicsDir, err := storage.EpicsDir(projectKey)
+	epicsDir, err := storage.EpicsDir(ctx, projectKey)
 	if err == nil {
 		if entries, err := os.ReadDir(epicsDir); err == nil {
 			for _, entry := range entries {
@@ -95,8 +151,7 @@ func exportProject(projectKey string, cmd *cobra.Command) error {
 
 				epicPath := filepath.Join(epicsDir, entry.Name())
 				var epic models.Epic
-				if err := storage.ReadJSON(epicPath, &epic); err != nil {
-					errOut := cmd.ErrOrStderr()
+				if err := storage.ReadJSON(ctx, epicPath, &epic); err != nil {
 					fmt.Fprintf(errOut, "Warning: failed to load epic %s: %v\n", entry.Name(), err)
 					continue
 				}
@@ -106,51 +161,291 @@ func exportProject(projectKey string, cmd *cobra.Command) error {
 		}
 	}
 
-	// Create export data
-	exportData := ExportData{
-		Version:    "1.0",
-		ExportedAt: time.Now().Format(time.RFC3339),
+	// Load custom workflow (only exported when the project defines one)
+	var workflow *models.Workflow
+	if workflowPath, err := storage.WorkflowPath(ctx, projectKey); err == nil {
+		var wf models.Workflow
+		if err := storage.ReadJSON(ctx, workflowPath, &wf); err == nil {
+			workflow = &wf
+		}
+	}
+
+	// Load saved views (shared with whoever imports the export)
+	var views []models.SavedView
+	if viewsPath, err := storage.ViewsPath(ctx, projectKey); err == nil {
+		var saved models.SavedViews
+		if err := storage.ReadJSON(ctx, viewsPath, &saved); err == nil {
+			views = saved.Views
+		}
+	}
+
+	return &ExportData{
+		Version:    CurrentExportVersion,
+		ExportedAt: storage.Timestamp(ctx),
 		Project:    &index,
 		Issues:     issues,
 		Epics:      epics,
+		Workflow:   workflow,
+		Views:      views,
+	}, nil
+}
+
+// exportProject exports a project to a JSON file.
+func exportProject(projectKey string, cmd *cobra.Command) error {
+	schemaVersion, _ := cmd.Flags().GetString("schema-version")
+	if _, err := exportVersionIndex(schemaVersion); err != nil {
+		return invalidf("cli: invalid --schema-version: %w", err)
+	}
+	exportData, err := loadExportData(cmd.Context(), projectKey, cmd.ErrOrStderr())
+	if err != nil {
+		return err
+	}
+	filter, err := parseExportFilter(projectKey, cmd)
+	if err != nil {
+		return err
+	}
+	if filter != nil {
+		if err := filter.apply(exportData); err != nil {
+			return err
+		}
+	}
+	if err := downgradeExportData(exportData, schemaVersion, cmd.ErrOrStderr()); err != nil {
+		return fmt.Errorf("cli: %w", err)
 	}
 
-	// Determine output path
+	// Determine output path and encoding (YAML or NDJSON when requested by format or file extension)
 	outputPath, _ := cmd.Flags().GetString("output")
+	format := config.ResolveFormat(cmd)
+	useArchive := isArchivePath(outputPath)
+	useYAML := !useArchive && (format == config.DefaultFormatYAML || isYAMLPath(outputPath))
+	useNDJSON := !useArchive && !useYAML && (format == config.DefaultFormatNDJSON || isNDJSONPath(outputPath))
 	if outputPath == "" {
-		outputPath = fmt.Sprintf("%s.json", projectKey)
+		switch {
+		case useYAML:
+			outputPath = fmt.Sprintf("%s.yaml", projectKey)
+		case useNDJSON:
+			outputPath = fmt.Sprintf("%s.ndjson", projectKey)
+		default:
+			outputPath = fmt.Sprintf("%s.json", projectKey)
+		}
 	}
 
-	// Write export file
-	data, err := json.MarshalIndent(exportData, "", "  ")
-	if err != nil {
-		return fmt.Errorf("cli: failed to marshal export data: %w", err)
+	// Encode export data
+	var buf bytes.Buffer
+	switch {
+	case useYAML:
+		data, err := ui.MarshalYAML(exportData)
+		if err != nil {
+			return fmt.Errorf("cli: failed to marshal export data: %w", err)
+		}
+		buf.Write(data)
+	case useNDJSON:
+		if err := writeExportNDJSON(&buf, exportData); err != nil {
+			return fmt.Errorf("cli: failed to marshal export data: %w", err)
+		}
+	case useArchive:
+		attachments := readProjectAttachments(cmd.Context(), projectKey, exportData.Issues, cmd.ErrOrStderr())
+		if err := writeExportArchive(cmd.Context(), &buf, exportData, attachments); err != nil {
+			return err
+		}
+	default:
+		data, err := json.MarshalIndent(exportData, "", "  ")
+		if err != nil {
+			return fmt.Errorf("cli: failed to marshal export data: %w", err)
+		}
+		buf.Write(data)
+	}
+
+	out := cmd.OutOrStdout()
+	if outputPath == "-" {
+		_, err := buf.WriteTo(out)
+		return err
 	}
 
-	if err := os.WriteFile(outputPath, data, 0644); err != nil {
+	if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
 		return fmt.Errorf("cli: failed to write export file: %w", err)
 	}
 
 	// Success message
-	out := cmd.OutOrStdout()
-	fmt.Fprintf(out, "Exported project %q to %s (%d issues, %d epics)\n",
-		projectKey, outputPath, len(issues), len(epics))
+	partial := ""
+	if exportData.Partial {
+		partial = "; partial, apply with import --merge"
+	}
+	fmt.Fprintf(successOut(cmd), "Exported project %q to %s (%d issues, %d epics%s)\n",
+		projectKey, outputPath, len(exportData.Issues), len(exportData.Epics), partial)
+
+	return nil
+}
 
+// writeExportNDJSON writes export data as NDJSON records, one per line.
+func writeExportNDJSON(w io.Writer, data *ExportData) error {
+	encoder := json.NewEncoder(w)
+	records := []exportRecord{
+		{Export: &exportHeader{Version: data.Version, ExportedAt: data.ExportedAt, Partial: data.Partial}},
+		{Project: data.Project},
+	}
+	if data.Workflow != nil {
+		records = append(records, exportRecord{Workflow: data.Workflow})
+	}
+	for i := range data.Views {
+		records = append(records, exportRecord{View: &data.Views[i]})
+	}
+	for _, epic := range data.Epics {
+		records = append(records, exportRecord{Epic: epic})
+	}
+	for _, issue := range data.Issues {
+		records = append(records, exportRecord{Issue: issue})
+	}
+	for i := range records {
+		if err := encoder.Encode(&records[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readExportNDJSON reads export data written by writeExportNDJSON.
+func readExportNDJSON(r io.Reader) (*ExportData, error) {
+	data := &ExportData{Issues: []*models.Issue{}, Epics: []*models.Epic{}}
+	decoder := json.NewDecoder(r)
+	for line := 1; ; line++ {
+		var record exportRecord
+		if err := decoder.Decode(&record); err == io.EOF {
+			return data, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("record %d: %w", line, err)
+		}
+		switch {
+		case record.Export != nil:
+			data.Version = record.Export.Version
+			data.ExportedAt = record.Export.ExportedAt
+			data.Partial = record.Export.Partial
+		case record.Project != nil:
+			data.Project = record.Project
+		case record.Workflow != nil:
+			data.Workflow = record.Workflow
+		case record.View != nil:
+			data.Views = append(data.Views, *record.View)
+		case record.Epic != nil:
+			data.Epics = append(data.Epics, record.Epic)
+		case record.Issue != nil:
+			data.Issues = append(data.Issues, record.Issue)
+		default:
+			return nil, fmt.Errorf("record %d: unknown record type", line)
+		}
+	}
+}
+
+// exportArchiveData is the name of the JSON export in an export archive.
+const exportArchiveData = "export.json"
+
+// writeExportArchive writes export data and attached files (by "<issue
+// ID>/<name>") as a gzipped tarball.
+func writeExportArchive(ctx context.Context, w io.Writer, data *ExportData, attachments map[string][]byte) error {
+	encoded, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cli: failed to marshal export data: %w", err)
+	}
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	if err := writeTarFile(ctx, tw, exportArchiveData, encoded); err != nil {
+		return err
+	}
+	for _, key := range slices.Sorted(maps.Keys(attachments)) {
+		if err := writeTarFile(ctx, tw, "attachments/"+key, attachments[key]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("cli: failed to write archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("cli: failed to write archive: %w", err)
+	}
 	return nil
 }
 
+// readExportArchive reads an archive written by writeExportArchive: the JSON
+// export, and the attached files by "<issue ID>/<name>".
+func readExportArchive(r io.Reader) ([]byte, map[string][]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer gz.Close()
+
+	var data []byte
+	attachments := map[string][]byte{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, nil, err
+		}
+		if header.Name == exportArchiveData {
+			data = content
+		} else if key, ok := strings.CutPrefix(header.Name, "attachments/"); ok {
+			if _, _, ok := cutAttachmentKey(key); ok {
+				attachments[key] = content
+			}
+		}
+	}
+	if data == nil {
+		return nil, nil, fmt.Errorf("archive has no %s", exportArchiveData)
+	}
+	return data, attachments, nil
+}
+
+// isArchivePath reports whether the file path has a gzipped tarball extension (.tar.gz or .tgz).
+func isArchivePath(path string) bool {
+	lower := strings.ToLower(path)
+	return strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz")
+}
+
+// isYAMLPath reports whether the file path has a YAML extension (.yaml or .yml).
+func isYAMLPath(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// isNDJSONPath reports whether the file path has an NDJSON extension (.ndjson or .jsonl).
+func isNDJSONPath(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".ndjson" || ext == ".jsonl"
+}
+
 // validateExportData validates the export data structure.
 // Individual issues and epics are validated during import, not here.
 func validateExportData(data *ExportData) error {
-	if data.Version == "" {
-		return fmt.Errorf("export: missing version")
+	if _, err := exportVersionIndex(data.Version); err != nil {
+		return err
 	}
 
 	if data.Project == nil {
 		return fmt.Errorf("export: missing project data")
 	}
 
-	if err := data.Project.Validate(); err != nil {
+	if data.Workflow != nil {
+		if err := data.Workflow.Validate(); err != nil {
+			return fmt.Errorf("export: invalid workflow: %w", err)
+		}
+	}
+
+	if err := (&models.SavedViews{Views: data.Views}).Validate(); err != nil {
+		return fmt.Errorf("export: invalid views: %w", err)
+	}
+
+	if err := data.Project.ValidateWithWorkflow(data.Workflow); err != nil {
 		return fmt.Errorf("export: invalid project data: %w", err)
 	}
 
@@ -28,7 +28,7 @@ func TestExportProject_ValidProject(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 		os.Remove(projectKey + ".json")
 	}()
@@ -85,8 +85,8 @@ func TestExportProject_ValidProject(t *testing.T) {
 		t.Fatalf("Failed to parse export file: %v", err)
 	}
 
-	if exportData.Version != "1.0" {
-		t.Errorf("Export Version = %q, want '1.0'", exportData.Version)
+	if exportData.Version != CurrentExportVersion {
+		t.Errorf("Export Version = %q, want %q", exportData.Version, CurrentExportVersion)
 	}
 
 	if exportData.Project == nil {
@@ -111,7 +111,7 @@ func TestExportProject_WithEpics(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 		os.Remove(projectKey + ".json")
 	}()
@@ -132,12 +132,12 @@ func TestExportProject_WithEpics(t *testing.T) {
 		CreatedAt: "2024-01-01T00:00:00Z",
 	}
 
-	epicPath, err := storage.EpicPath(projectKey, epic.ID)
+	epicPath, err := storage.EpicPath(t.Context(), projectKey, epic.ID)
 	if err != nil {
 		t.Fatalf("Failed to resolve epic path: %v", err)
 	}
 
-	if err := storage.WriteJSONAtomic(epicPath, epic); err != nil {
+	if err := storage.WriteJSONAtomic(t.Context(), epicPath, epic); err != nil {
 		t.Fatalf("Failed to write epic: %v", err)
 	}
 
@@ -180,7 +180,7 @@ func TestExportProject_CustomOutputPath(t *testing.T) {
 	customPath := filepath.Join(t.TempDir(), "custom-export.json")
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -232,7 +232,7 @@ func TestExportProject_MultipleIssues(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 		os.Remove(projectKey + ".json")
 	}()
@@ -381,3 +381,251 @@ func TestValidateExportData(t *testing.T) {
 		})
 	}
 }
+
+func TestExportProject_NDJSON(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"epic", "create", "--project", projectKey, "--title", "Payments"},
+		{"issue", "create", "--project", projectKey, "--title", "First"},
+		{"issue", "create", "--project", projectKey, "--title", "Second"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	// Streamed to stdout, one record per line
+	out, err := run("export", projectKey, "--format", "ndjson", "--output", "-")
+	if err != nil {
+		t.Fatalf("export --format ndjson failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	var kinds []string
+	for i, line := range lines {
+		var record map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(line), &record); err != nil || len(record) != 1 {
+			t.Fatalf("Line %d is not a single-key record: %s", i+1, line)
+		}
+		for kind := range record {
+			kinds = append(kinds, kind)
+		}
+	}
+	if want := "export project epic issue issue"; strings.Join(kinds, " ") != want {
+		t.Errorf("Record kinds = %v, want %s", kinds, want)
+	}
+
+	// An .ndjson output path selects NDJSON, and import reads it back
+	exportPath := filepath.Join(t.TempDir(), "export.ndjson")
+	if _, err := run("export", projectKey, "--output", exportPath); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if _, err := run("import", exportPath, "--overwrite"); err != nil {
+		t.Fatalf("import of NDJSON export failed: %v", err)
+	}
+	index, err := loadQueryIndex(t.Context(), projectKey)
+	if err != nil {
+		t.Fatalf("Failed to load index: %v", err)
+	}
+	if len(index.Issues) != 2 || len(index.Epics) != 1 {
+		t.Errorf("Expected 2 issues and 1 epic after import, got %d and %d", len(index.Issues), len(index.Epics))
+	}
+
+	badPath := filepath.Join(t.TempDir(), "bad.jsonl")
+	os.WriteFile(badPath, []byte(`{"comment":"x"}`+"\n"), 0644)
+	if _, err := run("import", badPath); err == nil {
+		t.Error("Expected an unknown NDJSON record to fail")
+	}
+}
+
+func TestExportSchemaVersion(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(errOut)
+		err := cmd.Execute()
+		return out.String(), errOut.String(), err
+	}
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"epic", "create", "--project", projectKey, "--title", "Payments"},
+		{"issue", "create", "--project", projectKey, "--title", "Checkout", "--epic", projectKey + "-E1"},
+		{"view", "save", "open", "--project", projectKey, "--query", "status!=DONE"},
+	} {
+		if _, _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	if _, _, err := run("export", projectKey, "--schema-version", "0.9", "--output", "-"); err == nil {
+		t.Error("Expected an unknown --schema-version to fail")
+	}
+
+	// 1.0 has no saved views and unscoped epic IDs
+	out, errOut, err := run("export", projectKey, "--schema-version", "1.0", "--output", "-")
+	if err != nil {
+		t.Fatalf("export --schema-version 1.0 failed: %v", err)
+	}
+	var old ExportData
+	if err := json.Unmarshal([]byte(out), &old); err != nil {
+		t.Fatalf("Failed to parse export: %v\n%s", err, out)
+	}
+	if old.Version != "1.0" || len(old.Views) != 0 || old.Epics[0].ID != "E-1" || old.Issues[0].EpicID != "E-1" || old.Project.Epics[0].ID != "E-1" {
+		t.Errorf("Unexpected 1.0 export: version %s, %d views, epic %s, link %s", old.Version, len(old.Views), old.Epics[0].ID, old.Issues[0].EpicID)
+	}
+	if !strings.Contains(errOut, "no saved views") {
+		t.Errorf("Expected a warning about the dropped views, got %q", errOut)
+	}
+
+	// Importing the old export upgrades it to the current version
+	exportFile := filepath.Join(t.TempDir(), "old.json")
+	if err := os.WriteFile(exportFile, []byte(out), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := run("import", exportFile, "--overwrite"); err != nil {
+		t.Fatalf("import of a 1.0 export failed: %v", err)
+	}
+	if issue, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-1"); issue == nil || issue.EpicID != projectKey+"-E1" {
+		t.Errorf("Expected the imported epic link to be scoped, got %+v", issue)
+	}
+
+	// Exports from a newer buyruk are refused
+	old.Version = "9.0"
+	data, _ := json.Marshal(&old)
+	if err := os.WriteFile(exportFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := run("import", exportFile, "--overwrite"); err == nil || !strings.Contains(err.Error(), "unsupported version") {
+		t.Errorf("Expected an unsupported version error, got %v", err)
+	}
+}
+
+func TestExportPartial(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	id := func(n string) string { return projectKey + "-" + n }
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"epic", "create", "--project", projectKey, "--title", "Payments"},
+		{"epic", "create", "--project", projectKey, "--title", "Search"},
+		{"issue", "create", "--project", projectKey, "--title", "Checkout", "--epic", id("E1"), "--fixed-time", "2024-06-01T09:00:00Z"},
+		{"issue", "create", "--project", projectKey, "--title", "Refunds", "--epic", id("E1"), "--fixed-time", "2024-06-01T09:00:00Z"},
+		{"issue", "create", "--project", projectKey, "--title", "Cleanup", "--fixed-time", "2024-06-01T09:00:00Z"},
+		{"issue", "update", id("1"), "--status", "DOING", "--fixed-time", "2024-06-10T09:00:00Z"},
+		{"view", "save", "open", "--project", projectKey, "--query", "status!=DONE"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	export := func(args ...string) *ExportData {
+		t.Helper()
+		out, err := run(append([]string{"export", projectKey, "--output", "-"}, args...)...)
+		if err != nil {
+			t.Fatalf("export %v failed: %v", args, err)
+		}
+		var data ExportData
+		if err := json.Unmarshal([]byte(out), &data); err != nil {
+			t.Fatalf("Failed to parse export: %v\n%s", err, out)
+		}
+		return &data
+	}
+	issueIDs := func(data *ExportData) string {
+		ids := []string{}
+		for _, issue := range data.Issues {
+			ids = append(ids, issue.ID)
+		}
+		return strings.Join(ids, ",")
+	}
+
+	if data := export(); data.Partial || len(data.Issues) != 3 || len(data.Views) != 1 {
+		t.Errorf("Expected a whole export without filters, got partial=%v with %d issues", data.Partial, len(data.Issues))
+	}
+	data := export("--epic", id("E1"), "--status", "doing")
+	if !data.Partial || issueIDs(data) != id("1") || len(data.Epics) != 1 || data.Epics[0].ID != id("E1") || len(data.Views) != 0 {
+		t.Errorf("Unexpected epic and status slice: %s, %d epics, %d views", issueIDs(data), len(data.Epics), len(data.Views))
+	}
+	if len(data.Project.Issues) != 1 || len(data.Project.Epics) != 1 {
+		t.Errorf("Expected the index to list only the slice, got %d issues and %d epics", len(data.Project.Issues), len(data.Project.Epics))
+	}
+	if data := export("--epic", "none"); issueIDs(data) != id("3") || len(data.Epics) != 0 {
+		t.Errorf("Expected only the issue without an epic, got %s", issueIDs(data))
+	}
+	if data := export("--since", "2024-06-05"); issueIDs(data) != id("1") {
+		t.Errorf("Expected only the recently updated issue, got %s", issueIDs(data))
+	}
+	if data := export("--ids", id("2")+","+id("3")); issueIDs(data) != id("2")+","+id("3") {
+		t.Errorf("Expected the listed issues, got %s", issueIDs(data))
+	}
+	for _, args := range [][]string{
+		{"--ids", id("404")},
+		{"--status", "SHIPPED"},
+		{"--epic", "OTHER-E1"},
+	} {
+		if _, err := run(append([]string{"export", projectKey, "--output", "-"}, args...)...); err == nil {
+			t.Errorf("Expected export %v to fail", args)
+		}
+	}
+
+	// A slice merges into the project but never replaces it
+	slicePath := filepath.Join(t.TempDir(), "slice.json")
+	if _, err := run("export", projectKey, "--ids", id("1"), "--output", slicePath); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if _, err := run("import", slicePath, "--overwrite"); err == nil {
+		t.Error("Expected import --overwrite of a partial export to fail")
+	}
+	if _, err := run("import", slicePath, "--merge"); err != nil {
+		t.Errorf("import --merge of a partial export failed: %v", err)
+	}
+
+	// Or becomes a project of its own, e.g. for a contractor
+	projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+	os.RemoveAll(projectDir)
+	if _, err := run("import", slicePath); err != nil {
+		t.Fatalf("import of a partial export failed: %v", err)
+	}
+	index, err := loadQueryIndex(t.Context(), projectKey)
+	if err != nil {
+		t.Fatalf("Failed to load index: %v", err)
+	}
+	if len(index.Issues) != 1 || index.FindEpic(id("E1")) == nil {
+		t.Errorf("Expected the imported slice with its epic, got %d issues and epics %+v", len(index.Issues), index.Epics)
+	}
+}
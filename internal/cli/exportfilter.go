@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// exportFilter selects the issues of a partial export. Empty fields match
+// every issue.
+type exportFilter struct {
+	statuses []string
+	epics    []string // "" for issues without an epic
+	since    time.Time
+	ids      []string
+}
+
+// parseExportFilter reads the subset flags of export. It returns nil when no
+// subset was asked for.
+func parseExportFilter(projectKey string, cmd *cobra.Command) (*exportFilter, error) {
+	wf, err := loadWorkflow(cmd.Context(), projectKey)
+	if err != nil {
+		return nil, err
+	}
+	filter := &exportFilter{}
+
+	status, _ := cmd.Flags().GetString("status")
+	for _, s := range splitList(status) {
+		i := slices.IndexFunc(wf.StatusList(), func(a string) bool { return strings.EqualFold(a, s) })
+		if i < 0 {
+			return nil, invalidf("cli: invalid status %q (allowed: %s)", s, strings.Join(wf.StatusList(), ", "))
+		}
+		filter.statuses = append(filter.statuses, wf.StatusList()[i])
+	}
+
+	epic, _ := cmd.Flags().GetString("epic")
+	for _, e := range splitList(epic) {
+		if strings.EqualFold(e, "none") {
+			filter.epics = append(filter.epics, "")
+			continue
+		}
+		if err := validateProjectEpicID(projectKey, e); err != nil {
+			return nil, err
+		}
+		filter.epics = append(filter.epics, e)
+	}
+
+	since, hasSince, err := getTimeFlag(cmd, "since")
+	if err != nil {
+		return nil, err
+	}
+	filter.since = since
+
+	ids, _ := cmd.Flags().GetString("ids")
+	for _, id := range splitList(ids) {
+		filter.ids = append(filter.ids, strings.ToUpper(id))
+	}
+
+	if len(filter.statuses) == 0 && len(filter.epics) == 0 && !hasSince && len(filter.ids) == 0 {
+		return nil, nil
+	}
+	return filter, nil
+}
+
+// matches reports whether an issue belongs to the partial export.
+func (f *exportFilter) matches(issue *models.Issue) bool {
+	if len(f.statuses) > 0 && !slices.Contains(f.statuses, issue.Status) {
+		return false
+	}
+	if len(f.epics) > 0 && !slices.Contains(f.epics, issue.EpicID) {
+		return false
+	}
+	if len(f.ids) > 0 && !slices.Contains(f.ids, issue.ID) {
+		return false
+	}
+	if !f.since.IsZero() {
+		updated := issue.UpdatedAt
+		if updated == "" {
+			updated = issue.CreatedAt
+		}
+		t, err := time.Parse(time.RFC3339, updated)
+		if err != nil || t.Before(f.since) {
+			return false
+		}
+	}
+	return true
+}
+
+// apply narrows export data to the matching issues, the epics they (or
+// --epic) name, and their index entries. Saved views are left out, as they
+// query the whole project.
+func (f *exportFilter) apply(data *ExportData) error {
+	for _, id := range f.ids {
+		if !slices.ContainsFunc(data.Issues, func(issue *models.Issue) bool { return issue.ID == id }) {
+			return notFoundf("cli: issue %q not found in project %q", id, data.Project.ProjectKey)
+		}
+	}
+
+	issues := []*models.Issue{}
+	kept := map[string]bool{}
+	epicIDs := map[string]bool{}
+	for _, id := range f.epics {
+		epicIDs[id] = true
+	}
+	for _, issue := range data.Issues {
+		if f.matches(issue) {
+			issues = append(issues, issue)
+			kept[issue.ID] = true
+			epicIDs[issue.EpicID] = true
+		}
+	}
+	epics := []*models.Epic{}
+	for _, epic := range data.Epics {
+		if epicIDs[epic.ID] {
+			epics = append(epics, epic)
+		}
+	}
+
+	index := *data.Project
+	index.Issues = slices.DeleteFunc(slices.Clone(index.Issues), func(entry models.IndexEntry) bool { return !kept[entry.ID] })
+	index.Epics = slices.DeleteFunc(slices.Clone(index.Epics), func(entry models.EpicIndexEntry) bool { return !epicIDs[entry.ID] })
+	if len(index.Epics) == 0 {
+		index.Epics = nil
+	}
+
+	data.Project = &index
+	data.Issues = issues
+	data.Epics = epics
+	data.Views = nil
+	data.Partial = true
+	return nil
+}
@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+)
+
+// CurrentExportVersion is the version of the export format written by export.
+const CurrentExportVersion = "1.3"
+
+// exportVersion is one version of the export format and the converters
+// between it and the version before it. Every change to ExportData or to the
+// meaning of its fields gets a new version here, so import reads every older
+// export and export --schema-version can write every older version.
+type exportVersion struct {
+	Version     string
+	Description string
+
+	// upgrade converts export data of the previous version to this one.
+	upgrade func(data *ExportData) error
+
+	// downgrade converts export data of this version to the previous one,
+	// reporting what the previous version cannot hold on warn.
+	downgrade func(data *ExportData, warn io.Writer) error
+}
+
+// exportVersions lists the export format versions, oldest first; the last is
+// CurrentExportVersion.
+var exportVersions = []exportVersion{
+	{Version: "1.0", Description: "project, issues, and epics"},
+	{Version: "1.1", Description: "adds the custom workflow and saved views", upgrade: upgradeExport11, downgrade: downgradeExport11},
+	{Version: "1.2", Description: "scopes epic IDs to the project (CORE-E1)", upgrade: upgradeExport12, downgrade: downgradeExport12},
+	{Version: "1.3", Description: "marks partial exports", upgrade: upgradeExport13, downgrade: downgradeExport13},
+}
+
+// exportVersionList lists the supported export versions for help and error messages.
+func exportVersionList() string {
+	versions := make([]string, len(exportVersions))
+	for i, v := range exportVersions {
+		versions[i] = v.Version
+	}
+	return strings.Join(versions, ", ")
+}
+
+// exportVersionIndex returns the position of a version in exportVersions.
+func exportVersionIndex(version string) (int, error) {
+	if version == "" {
+		return 0, fmt.Errorf("export: missing version")
+	}
+	i := slices.IndexFunc(exportVersions, func(v exportVersion) bool { return v.Version == version })
+	if i < 0 {
+		return 0, fmt.Errorf("export: unsupported version %q (this version of buyruk reads %s)", version, exportVersionList())
+	}
+	return i, nil
+}
+
+// upgradeExportData converts export data of any supported version to
+// CurrentExportVersion, one version at a time.
+func upgradeExportData(data *ExportData) error {
+	from, err := exportVersionIndex(data.Version)
+	if err != nil {
+		return err
+	}
+	for _, v := range exportVersions[from+1:] {
+		if err := v.upgrade(data); err != nil {
+			return fmt.Errorf("export: failed to convert to version %s: %w", v.Version, err)
+		}
+		data.Version = v.Version
+	}
+	return nil
+}
+
+// downgradeExportData converts current export data to an older version, one
+// version at a time. Data the older version cannot hold is dropped with a
+// warning.
+func downgradeExportData(data *ExportData, version string, warn io.Writer) error {
+	to, err := exportVersionIndex(version)
+	if err != nil {
+		return err
+	}
+	from, err := exportVersionIndex(data.Version)
+	if err != nil {
+		return err
+	}
+	for i := from; i > to; i-- {
+		if err := exportVersions[i].downgrade(data, warn); err != nil {
+			return fmt.Errorf("export: failed to convert to version %s: %w", exportVersions[i-1].Version, err)
+		}
+		data.Version = exportVersions[i-1].Version
+	}
+	return nil
+}
+
+// upgradeExport11 has nothing to convert: 1.0 exports have no workflow or views.
+func upgradeExport11(data *ExportData) error {
+	return nil
+}
+
+func downgradeExport11(data *ExportData, warn io.Writer) error {
+	if data.Workflow != nil {
+		fmt.Fprintf(warn, "Warning: export version 1.0 has no custom workflow; the project workflow was left out\n")
+		data.Workflow = nil
+	}
+	if len(data.Views) > 0 {
+		fmt.Fprintf(warn, "Warning: export version 1.0 has no saved views; %d view(s) were left out\n", len(data.Views))
+		data.Views = nil
+	}
+	return nil
+}
+
+// upgradeExport12 scopes unscoped epic IDs (E-1, AUTH) to the project, as
+// project migrate-epics does.
+func upgradeExport12(data *ExportData) error {
+	if data.Project == nil {
+		return nil
+	}
+	projectKey := data.Project.ProjectKey
+	return renameExportEpics(data, func(epicID string) string {
+		if strings.HasPrefix(epicID, projectKey+"-") {
+			return epicID
+		}
+		return scopedEpicID(projectKey, epicID)
+	})
+}
+
+// downgradeExport12 turns generated epic IDs back into the unscoped form of
+// 1.1 (CORE-E1 becomes E-1). Custom IDs already fit 1.1 and are kept.
+func downgradeExport12(data *ExportData, warn io.Writer) error {
+	if data.Project == nil {
+		return nil
+	}
+	projectKey := data.Project.ProjectKey
+	return renameExportEpics(data, func(epicID string) string {
+		if key, seq, err := models.ParseEpicID(epicID); err == nil && key == projectKey {
+			return fmt.Sprintf("E-%d", seq)
+		}
+		return epicID
+	})
+}
+
+// upgradeExport13 has nothing to convert: exports before 1.3 are whole projects.
+func upgradeExport13(data *ExportData) error {
+	return nil
+}
+
+func downgradeExport13(data *ExportData, warn io.Writer) error {
+	if data.Partial {
+		fmt.Fprintf(warn, "Warning: export version 1.2 cannot mark a partial export; import it with --merge only\n")
+		data.Partial = false
+	}
+	return nil
+}
+
+// renameExportEpics rewrites the epic IDs of export data wherever they
+// appear: the epics, the epic links of issues, and the project index.
+func renameExportEpics(data *ExportData, rename func(epicID string) string) error {
+	renamed := map[string]string{}
+	for _, epic := range data.Epics {
+		newID := rename(epic.ID)
+		if other, ok := renamed[newID]; ok {
+			return fmt.Errorf("epics %q and %q would both become %q", other, epic.ID, newID)
+		}
+		renamed[newID] = epic.ID
+		epic.ID = newID
+	}
+	for _, issue := range data.Issues {
+		if issue.EpicID != "" {
+			issue.EpicID = rename(issue.EpicID)
+		}
+	}
+	if data.Project != nil {
+		for i := range data.Project.Epics {
+			data.Project.Epics[i].ID = rename(data.Project.Epics[i].ID)
+		}
+		for i := range data.Project.Issues {
+			if data.Project.Issues[i].EpicID != "" {
+				data.Project.Issues[i].EpicID = rename(data.Project.Issues[i].EpicID)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/timeparse"
+	"github.com/spf13/cobra"
+)
+
+// getTimeFlag parses a human-friendly date flag (e.g. "2024-06-01", "next friday", "3d", "eod")
+// in the configured timezone. Returns false if the flag is empty.
+func getTimeFlag(cmd *cobra.Command, name string) (time.Time, bool, error) {
+	value, _ := cmd.Flags().GetString(name)
+	if value == "" {
+		return time.Time{}, false, nil
+	}
+	t, err := timeparse.ParseTime(value, storage.Now(cmd.Context()), config.Location())
+	if err != nil {
+		return time.Time{}, false, invalidf("cli: invalid --%s value: %w", name, err)
+	}
+	return t, true, nil
+}
+
+// getDurationFlag parses a duration flag that accepts days and weeks (e.g. "3d", "2w", "12h").
+// Returns false if the flag is empty.
+func getDurationFlag(cmd *cobra.Command, name string) (time.Duration, bool, error) {
+	value, _ := cmd.Flags().GetString(name)
+	if value == "" {
+		return 0, false, nil
+	}
+	d, err := timeparse.ParseDuration(value)
+	if err != nil {
+		return 0, false, invalidf("cli: invalid --%s value: %w", name, err)
+	}
+	return d, true, nil
+}
+
+// getEstimateFlag parses an effort estimate flag in working time (e.g. "4h", "2d", "1w").
+// Returns the normalized estimate, or false if the flag is empty.
+func getEstimateFlag(cmd *cobra.Command, name string) (string, bool, error) {
+	value, _ := cmd.Flags().GetString(name)
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return "", false, nil
+	}
+	if _, err := timeparse.ParseWorkDuration(value); err != nil {
+		return "", false, invalidf("cli: invalid --%s value: %w", name, err)
+	}
+	return value, true, nil
+}
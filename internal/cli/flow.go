@@ -0,0 +1,325 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/secret"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// syncCommands write issues that arrive from another copy of a project.
+// Their writes are recorded as sync events instead of local changes.
+var syncCommands = []string{"sync", "pull", "clone", "import"}
+
+// Flow event types that are not history event types.
+const (
+	flowCreated = "created"
+	flowComment = "comment"
+	flowLogged  = "logged"
+)
+
+// IssueFlow is the timeline of an issue.
+type IssueFlow struct {
+	ID     string      `json:"id"`
+	Title  string      `json:"title"`
+	Status string      `json:"status"`
+	Events []FlowEvent `json:"events"`
+}
+
+// FlowEvent is one entry of an issue timeline.
+type FlowEvent struct {
+	At           string `json:"at"`
+	Type         string `json:"type"` // created, status, comment, pr_added, pr_removed, sync, logged
+	Text         string `json:"text"`
+	From         string `json:"from,omitempty"`
+	To           string `json:"to,omitempty"`
+	Detail       string `json:"detail,omitempty"`
+	By           string `json:"by,omitempty"`            // Who made the change
+	AfterSeconds int64  `json:"after_seconds,omitempty"` // Time spent in the previous status (status changes)
+}
+
+// NewIssueFlowCmd creates and returns the issue flow command.
+func NewIssueFlowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "flow <id>",
+		Short: "Show the timeline of an issue",
+		Long: `Show the timeline of an issue: when it was created, each status change with
+the time spent in the previous status, comments, logged time, PRs linked and
+unlinked, and changes that arrived through sync. Status, PR, and sync changes
+are recorded from the moment this version of buyruk makes them.`,
+		Example:           "  buyruk issue flow CORE-12",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeIssueArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveIssueArgs(cmd, args[:1]); err != nil {
+				return err
+			}
+			return showIssueFlow(args[0], cmd)
+		},
+	}
+
+	return cmd
+}
+
+// historySourceOf returns the name of cmd when it syncs issues in from
+// elsewhere, or "" when its changes are local.
+func historySourceOf(cmd *cobra.Command) string {
+	name := strings.TrimSpace(strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()))
+	if fields := strings.Fields(name); len(fields) > 0 && slices.Contains(syncCommands, fields[0]) {
+		return name
+	}
+	return ""
+}
+
+// historySourceFrom returns the command syncing issues in of the running
+// command, or "" for local changes.
+func historySourceFrom(ctx context.Context) string {
+	if state := runStateFrom(ctx); state != nil {
+		return state.historySource
+	}
+	return ""
+}
+
+// historyWriteFilter records the status and PR changes of an issue write in
+// the issue's history, or a sync event when the running command syncs issues
+// in from another copy.
+func historyWriteFilter(ctx context.Context, path string, data []byte) ([]byte, error) {
+	if _, ok := issueFileProject(ctx, path); !ok {
+		return data, nil
+	}
+	var updated, old models.Issue
+	if err := json.Unmarshal(data, &updated); err != nil {
+		return data, nil
+	}
+	if err := storage.ReadJSON(ctx, path, &old); err != nil {
+		return data, nil
+	}
+
+	recorded := len(updated.History)
+	if historySource := historySourceFrom(ctx); historySource != "" {
+		if !sameIssueContent(&old, &updated) {
+			models.RecordSyncHistory(&old, &updated, storage.Timestamp(ctx), historySource)
+		}
+	} else {
+		models.RecordHistory(&old, &updated, storage.Timestamp(ctx))
+	}
+	if len(updated.History) == recorded {
+		return data, nil
+	}
+
+	recordedData, err := json.MarshalIndent(&updated, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to marshal issue %s: %w", updated.ID, err)
+	}
+	return recordedData, nil
+}
+
+// sameIssueContent reports whether two versions of an issue differ only in
+// bookkeeping: history, replication metadata, and when and by whom they were
+// last updated.
+func sameIssueContent(a, b *models.Issue) bool {
+	strip := func(issue models.Issue) []byte {
+		issue.History, issue.CRDT, issue.UpdatedAt, issue.UpdatedBy = nil, nil, "", ""
+		data, _ := json.Marshal(&issue)
+		return data
+	}
+	return bytes.Equal(strip(*a), strip(*b))
+}
+
+// showIssueFlow renders the timeline of an issue.
+func showIssueFlow(issueID string, cmd *cobra.Command) error {
+	projectKey, _, err := parseIssueID(cmd.Context(), issueID)
+	if err != nil {
+		return invalidf("cli: invalid issue ID %q: %w", issueID, err)
+	}
+	issue, err := loadLocalIssue(cmd.Context(), projectKey, issueID)
+	if err != nil {
+		return err
+	}
+	if issue == nil {
+		archived, ok := loadArchivedIssue(cmd.Context(), projectKey, issueID)
+		if !ok {
+			return notFoundf("cli: issue %q not found", issueID)
+		}
+		issue = archived
+	}
+	if issue.Sealed != nil {
+		if key, ok := secret.SessionKey(cmd.Context(), projectKey, issue.Sealed.Key); ok {
+			if err := secret.OpenIssue(issue, key); err != nil {
+				return fmt.Errorf("cli: failed to decrypt %s: %w", issueID, err)
+			}
+		} else {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Note: %s is sensitive; its comments are encrypted (run `buyruk unlock` to include them)\n", issueID)
+		}
+	}
+
+	flow := buildIssueFlow(issue)
+	out := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(flow)
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(out, flow)
+	case config.DefaultFormatLSON:
+		renderIssueFlowLSON(flow, out)
+	default:
+		renderIssueFlow(flow, out)
+	}
+	return nil
+}
+
+// buildIssueFlow collects the timeline of an issue, oldest first.
+func buildIssueFlow(issue *models.Issue) *IssueFlow {
+	flow := &IssueFlow{ID: issue.ID, Title: issue.Title, Status: issue.Status, Events: []FlowEvent{}}
+
+	for _, event := range issue.History {
+		entry := FlowEvent{At: event.At, Type: event.Type, From: event.From, To: event.To, Detail: event.Detail, By: event.By}
+		switch event.Type {
+		case models.HistoryStatus:
+			entry.Text = fmt.Sprintf("%s → %s", event.From, event.To)
+		case models.HistoryPRAdded:
+			entry.Text = "PR linked: " + event.Detail
+		case models.HistoryPRRemoved:
+			entry.Text = "PR unlinked: " + event.Detail
+		case models.HistorySync:
+			entry.Text = "Synced in via " + event.Detail
+			if event.From != "" {
+				entry.Text += fmt.Sprintf(" (%s → %s)", event.From, event.To)
+			}
+		case models.HistoryMergedInto:
+			entry.Text = "Merged into " + event.Detail + " as a duplicate"
+		case models.HistoryMergedFrom:
+			entry.Text = "Merged duplicate " + event.Detail
+		case models.HistoryEscalated:
+			entry.Text = "Escalated to " + event.Detail
+			if event.Detail == "" {
+				entry.Text = "Flagged as escalated"
+			}
+		default:
+			entry.Text = event.Type
+		}
+		flow.Events = append(flow.Events, entry)
+	}
+	for _, comment := range issue.Comments {
+		body, _ := json.Marshal(comment.Body)
+		flow.Events = append(flow.Events, FlowEvent{At: comment.CreatedAt, Type: flowComment, Text: "Comment: " + formatFieldValue(body), By: comment.CreatedBy})
+	}
+	for _, entry := range issue.TimeEntries {
+		text := "Logged " + entry.Duration
+		if entry.User != "" {
+			text += " by " + entry.User
+		}
+		if entry.Note != "" {
+			text += ": " + entry.Note
+		}
+		flow.Events = append(flow.Events, FlowEvent{At: entry.LoggedAt, Type: flowLogged, Text: text})
+	}
+	slices.SortStableFunc(flow.Events, func(a, b FlowEvent) int { return compareTimestamps(a.At, b.At) })
+
+	if issue.CreatedAt != "" {
+		initial := issue.Status
+		for _, event := range flow.Events {
+			if event.From != "" {
+				initial = event.From
+				break
+			}
+		}
+		created := FlowEvent{At: issue.CreatedAt, Type: flowCreated, Text: "Created as " + initial, To: initial, By: issue.CreatedBy}
+		flow.Events = append([]FlowEvent{created}, flow.Events...)
+	}
+
+	// Annotate status changes with the time spent in the previous status
+	var since time.Time
+	for i := range flow.Events {
+		event := &flow.Events[i]
+		at, err := time.Parse(time.RFC3339, event.At)
+		if err != nil || event.To == "" {
+			continue
+		}
+		if !since.IsZero() && event.From != "" {
+			event.AfterSeconds = int64(at.Sub(since).Seconds())
+		}
+		since = at
+	}
+	return flow
+}
+
+// compareTimestamps orders RFC3339 timestamps, placing unparsable ones last.
+func compareTimestamps(a, b string) int {
+	ta, errA := time.Parse(time.RFC3339, a)
+	tb, errB := time.Parse(time.RFC3339, b)
+	switch {
+	case errA != nil && errB != nil:
+		return 0
+	case errA != nil:
+		return 1
+	case errB != nil:
+		return -1
+	}
+	return ta.Compare(tb)
+}
+
+// flowMarkers are the timeline markers of each event type.
+var flowMarkers = map[string]string{
+	flowCreated:              "●",
+	models.HistoryStatus:     "●",
+	flowComment:              "○",
+	flowLogged:               "◷",
+	models.HistoryPRAdded:    "◆",
+	models.HistoryPRRemoved:  "◇",
+	models.HistorySync:       "⇄",
+	models.HistoryMergedInto: "⇢",
+	models.HistoryMergedFrom: "⇠",
+	models.HistoryEscalated:  "▲",
+}
+
+// renderIssueFlow renders an issue timeline as a vertical annotated line.
+func renderIssueFlow(flow *IssueFlow, w io.Writer) {
+	styles := ui.NewStyles()
+	loc := config.Location()
+	fmt.Fprintf(w, "%s %s [%s]\n\n", styles.ID(flow.ID), styles.Title(flow.Title), styles.StatusColor(flow.Status)(flow.Status))
+	if len(flow.Events) == 0 {
+		fmt.Fprintln(w, "No recorded events")
+		return
+	}
+
+	for i, event := range flow.Events {
+		marker := flowMarkers[event.Type]
+		if marker == "" {
+			marker = "•"
+		}
+		text := event.Text
+		if event.AfterSeconds > 0 {
+			text += fmt.Sprintf(" (after %s in %s)", ui.FormatElapsed(time.Duration(event.AfterSeconds)*time.Second), event.From)
+		}
+		if event.By != "" {
+			text += " · " + models.ActorName(event.By)
+		}
+		fmt.Fprintf(w, "  %-16s  %s  %s\n", ui.FormatTime(event.At, loc), marker, text)
+		if i < len(flow.Events)-1 {
+			fmt.Fprintf(w, "  %-16s  │\n", "")
+		}
+	}
+}
+
+// renderIssueFlowLSON renders an issue timeline in L-SON format.
+func renderIssueFlowLSON(flow *IssueFlow, w io.Writer) {
+	fmt.Fprintf(w, "@ID: %s\n", flow.ID)
+	fmt.Fprintf(w, "@STATUS: %s\n", flow.Status)
+	for _, event := range flow.Events {
+		fmt.Fprintf(w, "@EVENT: %s|%s|%s\n", event.At, event.Type, event.Text)
+	}
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestIssueFlow(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	issueID := projectKey + "-1"
+	exportFile := filepath.Join(t.TempDir(), "export.json")
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "Login page", "--fixed-time", "2024-06-03T09:00:00Z"},
+		{"issue", "update", issueID, "--status", "DOING", "--fixed-time", "2024-06-03T11:30:00Z"},
+		{"issue", "comment", issueID, "Waiting for API keys\nfrom the vendor", "--fixed-time", "2024-06-03T12:00:00Z"},
+		{"issue", "pr", issueID, "https://example.com/pr/7", "--fixed-time", "2024-06-04T09:00:00Z"},
+		{"issue", "log", issueID, "3h", "--user", "alice", "--fixed-time", "2024-06-04T10:00:00Z"},
+		{"issue", "update", issueID, "--title", "Login", "--fixed-time", "2024-06-04T10:30:00Z"},
+		{"export", projectKey, "--output", exportFile, "--fixed-time", "2024-06-05T08:00:00Z"},
+		{"issue", "update", issueID, "--status", "DONE", "--fixed-time", "2024-06-05T09:00:00Z"},
+		// Bring the exported copy back: the status change arrives through sync
+		{"import", exportFile, "--review", "--fixed-time", "2024-06-06T08:00:00Z"},
+		{"sync", "review", "--project", projectKey, "--accept", "all", "--fixed-time", "2024-06-06T09:00:00Z"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	out, err := run("issue", "flow", issueID, "--format", "json")
+	if err != nil {
+		t.Fatalf("issue flow failed: %v", err)
+	}
+	var flow IssueFlow
+	if err := json.Unmarshal([]byte(out), &flow); err != nil {
+		t.Fatalf("Failed to parse flow: %v\n%s", err, out)
+	}
+	var types []string
+	for _, event := range flow.Events {
+		types = append(types, event.Type)
+	}
+	want := []string{"created", models.HistoryStatus, "comment", models.HistoryPRAdded, "logged", models.HistoryStatus, models.HistorySync}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Fatalf("Event types = %v, want %v", types, want)
+	}
+	if e := flow.Events[1]; e.From != "TODO" || e.To != "DOING" || e.AfterSeconds != 9000 {
+		t.Errorf("Unexpected status event: %+v", e)
+	}
+	if e := flow.Events[6]; e.From != "DONE" || e.To != "DOING" || e.Detail != "sync review" || e.AfterSeconds != 86400 {
+		t.Errorf("Unexpected sync event: %+v", e)
+	}
+
+	out, err = run("issue", "flow", issueID, "--format", "modern")
+	if err != nil {
+		t.Fatalf("issue flow failed: %v", err)
+	}
+	for _, want := range []string{
+		"Created as TODO",
+		"TODO → DOING (after 2h 30m in TODO)",
+		"Comment: Waiting for API keys …",
+		"PR linked: https://example.com/pr/7",
+		"Logged 3h by alice",
+		"Synced in via sync review (DONE → DOING) (after 1d in DONE)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in flow, got:\n%s", want, out)
+		}
+	}
+}
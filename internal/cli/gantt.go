@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+// ganttSection is a group of gantt tasks: the issues of an epic.
+type ganttSection struct {
+	Name  string
+	Tasks []ganttTask
+}
+
+// ganttTask is an issue drawn from its creation to its due or close date.
+type ganttTask struct {
+	Name  string
+	Tags  []string // Mermaid task tags: done, active, crit
+	Start string   // YYYY-MM-DD
+	End   string   // YYYY-MM-DD, after Start
+}
+
+// loadGantt groups the issues of a project by epic, in index order, with
+// issues without an epic last. Each issue runs from its creation date to its
+// due date, the date it was closed, or today.
+func loadGantt(ctx context.Context, projectKey string, now time.Time) ([]ganttSection, error) {
+	index, err := loadQueryIndex(ctx, projectKey)
+	if err != nil {
+		return nil, err
+	}
+	wf, err := loadWorkflow(ctx, projectKey)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := now.Location()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	day := func(t time.Time) time.Time {
+		t = t.In(loc)
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	}
+
+	var sections []ganttSection
+	sectionOf := map[string]int{}
+	for _, epic := range index.Epics {
+		sectionOf[epic.ID] = len(sections)
+		sections = append(sections, ganttSection{Name: ganttText(epic.ID + " " + epic.Title)})
+	}
+	var unplanned []ganttTask
+	for _, entry := range index.Issues {
+		issue, err := loadLocalIssue(ctx, projectKey, entry.ID)
+		if err != nil || issue == nil {
+			continue
+		}
+		created, err := time.Parse(time.RFC3339, issue.CreatedAt)
+		if err != nil {
+			continue
+		}
+		start := day(created)
+
+		task := ganttTask{Name: ganttText(issue.ID + " " + issue.Title), Start: start.Format(time.DateOnly)}
+		done := wf.IsDoneStatus(issue.Status)
+		end := today
+		due, dueErr := time.Parse(time.RFC3339, issue.Due)
+		switch {
+		case done:
+			task.Tags = append(task.Tags, "done")
+			if _, closed := issueStartAndClose(issue, wf); !closed.IsZero() {
+				end = day(closed)
+			}
+		case dueErr == nil:
+			end = day(due)
+			if end.Before(today) {
+				task.Tags = append(task.Tags, "crit")
+			}
+		}
+		if !done && issue.Status != wf.DefaultStatus() {
+			task.Tags = append(task.Tags, "active")
+		}
+		// Tasks end at the start of their end date, so they cover it
+		end = end.AddDate(0, 0, 1)
+		if !end.After(start) {
+			end = start.AddDate(0, 0, 1)
+		}
+		task.End = end.Format(time.DateOnly)
+
+		if i, ok := sectionOf[issue.EpicID]; ok {
+			sections[i].Tasks = append(sections[i].Tasks, task)
+		} else {
+			unplanned = append(unplanned, task)
+		}
+	}
+
+	sections = slices.DeleteFunc(sections, func(s ganttSection) bool { return len(s.Tasks) == 0 })
+	if len(unplanned) > 0 {
+		sections = append(sections, ganttSection{Name: "No epic", Tasks: unplanned})
+	}
+	return sections, nil
+}
+
+// writeGantt writes a Mermaid gantt chart of the sections.
+func writeGantt(w io.Writer, projectKey string, sections []ganttSection) {
+	fmt.Fprintln(w, "gantt")
+	fmt.Fprintf(w, "    title Roadmap: %s\n", projectKey)
+	fmt.Fprintln(w, "    dateFormat YYYY-MM-DD")
+	for _, section := range sections {
+		fmt.Fprintf(w, "    section %s\n", section.Name)
+		for _, task := range section.Tasks {
+			fields := append(slices.Clone(task.Tags), task.Start, task.End)
+			fmt.Fprintf(w, "    %s :%s\n", task.Name, strings.Join(fields, ", "))
+		}
+	}
+}
+
+// ganttText makes text safe for a Mermaid gantt line: colons separate task
+// names from their data and '#' and ';' start comments and statements.
+func ganttText(text string) string {
+	text = strings.NewReplacer(":", " -", "#", "", ";", ",", "\n", " ", "\r", "").Replace(text)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// showGantt prints the Mermaid gantt chart of a project.
+func showGantt(ctx context.Context, w io.Writer, projectKey string) error {
+	sections, err := loadGantt(ctx, projectKey, storage.Now(ctx).In(config.Location()))
+	if err != nil {
+		return err
+	}
+	writeGantt(w, projectKey, sections)
+	return nil
+}
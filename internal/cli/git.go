@@ -0,0 +1,379 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// gitHookMarker identifies hook scripts written by buyruk, which may be
+// replaced or removed without --force.
+const gitHookMarker = "# Installed by buyruk git install-hooks"
+
+// gitHooks are the scripts installed by buyruk git install-hooks. A missing
+// buyruk binary makes them do nothing rather than block commits.
+var gitHooks = map[string]string{
+	"commit-msg": `#!/bin/sh
+` + gitHookMarker + `: checks the issues a commit message closes.
+command -v buyruk >/dev/null 2>&1 || exit 0
+exec buyruk git commit-msg "$1"
+`,
+	"post-commit": `#!/bin/sh
+` + gitHookMarker + `: closes the issues a commit fixes.
+command -v buyruk >/dev/null 2>&1 || exit 0
+buyruk git post-commit || true
+`,
+}
+
+// commitKeywordRegex matches closing and referencing keywords followed by
+// issue IDs, e.g. "Fixes CORE-12", "Closes: CORE-1, CORE-2 and CORE-3", or
+// "Refs CORE-4 CORE-5".
+var commitKeywordRegex = regexp.MustCompile(`(?i)\b(close[sd]?|fix(?:e[sd])?|resolve[sd]?|refs?|references)\b:?[ \t]+(` + commitIssueID + `(?:(?:[ \t]*,[ \t]*|[ \t]+and[ \t]+|[ \t]+)` + commitIssueID + `)*)`)
+
+// commitIssueIDRegex matches one issue ID in a keyword match.
+var commitIssueIDRegex = regexp.MustCompile(`(?i)` + commitIssueID)
+
+// commitIssueID matches an issue ID of any ID scheme.
+const commitIssueID = `[A-Z][A-Z0-9-]*-(?:` + issueSuffixPattern + `)`
+
+// issueSuffixPattern matches the part of an issue ID after the project key in
+// any ID scheme: a ULID, or a sequence number, optionally padded or after a
+// year.
+const issueSuffixPattern = `[0-7][0-9A-HJKMNP-TV-Z]{25}|[0-9]{4}-[0-9]{3,}|[0-9]+`
+
+// NewGitCmd creates and returns the git command.
+func NewGitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "git",
+		Short: "Drive issues from git commit messages",
+		Long: `Drive issues from git commit messages, like closing keywords on GitHub.
+
+With the hooks installed, a commit whose message says "Fixes CORE-12" (also
+close, closes, closed, fix, fixed, resolve, resolves, resolved) moves CORE-12
+to the first done status of its workflow and records the commit on it.
+"Refs CORE-12" (also ref, references) only records the commit. Issues are
+only moved along allowed workflow transitions, and a message closing an
+issue that does not exist is rejected.`,
+	}
+
+	cmd.AddCommand(NewGitInstallHooksCmd())
+	cmd.AddCommand(NewGitCommitMsgCmd())
+	cmd.AddCommand(NewGitPostCommitCmd())
+
+	return cmd
+}
+
+// NewGitInstallHooksCmd creates and returns the git install-hooks command.
+func NewGitInstallHooksCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "install-hooks",
+		Short: "Install the commit-msg and post-commit hooks in the current repository",
+		Example: `  buyruk git install-hooks
+  buyruk git install-hooks --remove`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return installGitHooks(cmd)
+		},
+	}
+
+	cmd.Flags().Bool("force", false, "Replace existing hooks not installed by buyruk")
+	cmd.Flags().Bool("remove", false, "Remove the hooks installed by buyruk")
+
+	return cmd
+}
+
+// NewGitCommitMsgCmd creates and returns the git commit-msg command.
+func NewGitCommitMsgCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "commit-msg <message-file>",
+		Short: "Check the issues a commit message closes (run by the commit-msg hook)",
+		Args:  cobra.ExactArgs(1),
+		// The hook shows the error to someone committing, not using buyruk
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return checkCommitMessage(cmd.Context(), args[0])
+		},
+	}
+
+	return cmd
+}
+
+// NewGitPostCommitCmd creates and returns the git post-commit command.
+func NewGitPostCommitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "post-commit [commit]",
+		Short: "Apply the issue keywords of a commit (run by the post-commit hook)",
+		Long:  "Apply the issue keywords of a commit (HEAD by default). A commit already recorded on an issue is skipped, so running it twice is safe.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rev := "HEAD"
+			if len(args) == 1 {
+				rev = args[0]
+			}
+			return applyCommit(rev, cmd)
+		},
+	}
+
+	return cmd
+}
+
+// installGitHooks installs or removes the buyruk hooks.
+func installGitHooks(cmd *cobra.Command) error {
+	output, err := git(".", "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return fmt.Errorf("cli: not in a git repository")
+	}
+	hooksDir := strings.TrimSpace(string(output))
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return fmt.Errorf("cli: failed to create %s: %w", hooksDir, err)
+	}
+
+	force, _ := cmd.Flags().GetBool("force")
+	remove, _ := cmd.Flags().GetBool("remove")
+	out := successOut(cmd)
+	names := []string{"commit-msg", "post-commit"}
+
+	// Check every hook first so nothing is half installed
+	for _, name := range names {
+		path := filepath.Join(hooksDir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return fmt.Errorf("cli: failed to read %s: %w", path, err)
+		}
+		if !strings.Contains(string(data), gitHookMarker) && !force {
+			return conflictf("cli: %s already exists and was not installed by buyruk (use --force to replace it)", path)
+		}
+	}
+
+	for _, name := range names {
+		path := filepath.Join(hooksDir, name)
+		if remove {
+			data, err := os.ReadFile(path)
+			if err != nil || !strings.Contains(string(data), gitHookMarker) {
+				continue
+			}
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("cli: failed to remove %s: %w", path, err)
+			}
+			fmt.Fprintf(out, "Removed %s\n", path)
+			continue
+		}
+		if err := os.WriteFile(path, []byte(gitHooks[name]), 0755); err != nil {
+			return fmt.Errorf("cli: failed to write %s: %w", path, err)
+		}
+		// WriteFile keeps the mode of an existing file
+		if err := os.Chmod(path, 0755); err != nil {
+			return fmt.Errorf("cli: failed to make %s executable: %w", path, err)
+		}
+		fmt.Fprintf(out, "Installed %s\n", path)
+	}
+	return nil
+}
+
+// parseCommitReferences returns the issue IDs a commit message closes and the
+// ones it only references. IDs are upper-cased and listed once, closing taking
+// precedence.
+func parseCommitReferences(message string) (closes, refs []string) {
+	for _, match := range commitKeywordRegex.FindAllStringSubmatch(message, -1) {
+		keyword := strings.ToLower(match[1])
+		closing := !strings.HasPrefix(keyword, "ref")
+		for _, id := range commitIssueIDRegex.FindAllString(match[2], -1) {
+			id = strings.ToUpper(id)
+			switch {
+			case closing && !slices.Contains(closes, id):
+				closes = append(closes, id)
+				refs = slices.DeleteFunc(refs, func(r string) bool { return r == id })
+			case !closing && !slices.Contains(closes, id) && !slices.Contains(refs, id):
+				refs = append(refs, id)
+			}
+		}
+	}
+	return closes, refs
+}
+
+// localIssueProject returns the project of an issue ID when that project
+// exists locally. Words that only look like issue IDs ("UTF-8") have none.
+func localIssueProject(ctx context.Context, issueID string) (string, bool) {
+	projectKey, _, err := parseIssueID(ctx, issueID)
+	if err != nil {
+		return "", false
+	}
+	indexPath, err := storage.ProjectIndexPath(ctx, projectKey)
+	if err != nil {
+		return "", false
+	}
+	if _, err := os.Stat(indexPath); err != nil {
+		return "", false
+	}
+	return projectKey, true
+}
+
+// checkCommitMessage rejects a commit message that closes an issue missing
+// from a local project.
+func checkCommitMessage(ctx context.Context, messageFile string) error {
+	data, err := os.ReadFile(messageFile)
+	if err != nil {
+		return fmt.Errorf("cli: failed to read commit message: %w", err)
+	}
+	closes, _ := parseCommitReferences(stripCommitComments(string(data)))
+	for _, issueID := range closes {
+		projectKey, ok := localIssueProject(ctx, issueID)
+		if !ok {
+			continue
+		}
+		issue, err := loadLocalIssue(ctx, projectKey, issueID)
+		if err != nil {
+			return err
+		}
+		if issue == nil {
+			return invalidf("cli: commit message closes %s, which does not exist", issueID)
+		}
+	}
+	return nil
+}
+
+// stripCommitComments removes the comment lines git adds to the message file.
+func stripCommitComments(message string) string {
+	var lines []string
+	for line := range strings.SplitSeq(message, "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// applyCommit closes and records the issues referenced by a commit message.
+func applyCommit(rev string, cmd *cobra.Command) error {
+	output, err := git(".", "log", "-1", "--format=%H%n%B", rev)
+	if err != nil {
+		return fmt.Errorf("cli: %w", err)
+	}
+	sha, message, _ := strings.Cut(string(output), "\n")
+	closes, refs := parseCommitReferences(message)
+
+	out := successOut(cmd)
+	errOut := cmd.ErrOrStderr()
+	for _, issueID := range append(closes, refs...) {
+		projectKey, ok := localIssueProject(cmd.Context(), issueID)
+		if !ok {
+			continue
+		}
+		result, err := recordCommit(cmd.Context(), projectKey, issueID, sha, slices.Contains(closes, issueID))
+		if err != nil {
+			fmt.Fprintf(errOut, "Warning: %v\n", err)
+			continue
+		}
+		switch {
+		case result.duplicate:
+		case result.blocked != "":
+			fmt.Fprintf(out, "Recorded commit %s on %s\n", shortSHA(sha), issueID)
+			fmt.Fprintf(errOut, "Warning: did not close %s: %s\n", issueID, result.blocked)
+		case result.closed != "":
+			fmt.Fprintf(out, "Closed %s (%s) in commit %s\n", issueID, result.closed, shortSHA(sha))
+		default:
+			fmt.Fprintf(out, "Recorded commit %s on %s\n", shortSHA(sha), issueID)
+		}
+	}
+	return nil
+}
+
+// commitResult is what recording a commit on an issue did.
+type commitResult struct {
+	duplicate bool   // The commit was already recorded
+	closed    string // Done status the issue moved to
+	blocked   string // Why a closing commit did not move the issue
+}
+
+// recordCommit records a commit on an issue and, for a closing commit, moves
+// the issue to the first done status if the workflow allows it.
+func recordCommit(ctx context.Context, projectKey, issueID, sha string, closing bool) (*commitResult, error) {
+	wf, err := loadWorkflow(ctx, projectKey)
+	if err != nil {
+		return nil, err
+	}
+	issuePath, err := storage.IssuePath(ctx, projectKey, issueID)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve issue path: %w", err)
+	}
+
+	result := &commitResult{}
+	var issue models.Issue
+	if err := storage.UpdateJSONAtomic(ctx, issuePath, &issue, func(v interface{}) error {
+		iss := v.(*models.Issue)
+		if iss.ID == "" || iss.ID != issueID {
+			return notFoundf("cli: issue %q not found", issueID)
+		}
+		if slices.Contains(iss.Commits, sha) {
+			result.duplicate = true
+			return nil
+		}
+		iss.Commits = append(iss.Commits, sha)
+
+		if closing {
+			result.closed, result.blocked = closeIssue(iss, wf, "Fixed in commit "+shortSHA(sha))
+		}
+		iss.UpdatedAt = storage.Timestamp(ctx)
+		return nil
+	}); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, notFoundf("cli: issue %q not found", issueID)
+		}
+		return nil, fmt.Errorf("cli: failed to update issue: %w", err)
+	}
+	if result.closed == "" {
+		return result, nil
+	}
+
+	indexPath, err := storage.ProjectIndexPath(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	if err := storage.UpdateIndexAtomic(ctx, indexPath, &models.ProjectIndex{}, func(v interface{}) error {
+		idx := v.(*models.ProjectIndex)
+		idx.AddIssue(&issue)
+		idx.UpdatedAt = storage.Timestamp(ctx)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("cli: failed to update project index: %w", err)
+	}
+	return result, nil
+}
+
+// closeIssue moves an open issue to the first done status of its workflow,
+// filling in the resolution if the workflow requires one. It returns the
+// status the issue moved to, or why it could not move.
+func closeIssue(issue *models.Issue, wf *models.Workflow, resolution string) (closed, blocked string) {
+	if wf.IsDoneStatus(issue.Status) {
+		return "", ""
+	}
+	done := wf.DoneStatusList()[0]
+	if !wf.CanTransition(issue.Status, done) {
+		return "", fmt.Sprintf("the workflow does not allow %s → %s", issue.Status, done)
+	}
+	if issue.Resolution == "" && slices.Contains(wf.MissingFields(issue, done), "resolution") {
+		issue.Resolution = resolution
+	}
+	if missing := wf.MissingFields(issue, done); len(missing) > 0 {
+		return "", fmt.Sprintf("moving to %s requires: %s", done, strings.Join(missing, ", "))
+	}
+	issue.Status = done
+	return done, ""
+}
+
+// shortSHA abbreviates a commit SHA for display.
+func shortSHA(sha string) string {
+	return sha[:min(len(sha), 12)]
+}
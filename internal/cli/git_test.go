@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestParseCommitReferences(t *testing.T) {
+	closes, refs := parseCommitReferences("Fix login\n\nFixes CORE-12, core-13 and CORE-14\nRefs CORE-12 CORE-20\nCloses: WEB-1\nHandle UTF-8 input")
+	if !slices.Equal(closes, []string{"CORE-12", "CORE-13", "CORE-14", "WEB-1"}) {
+		t.Errorf("closes = %v", closes)
+	}
+	if !slices.Equal(refs, []string{"CORE-20"}) {
+		t.Errorf("refs = %v", refs)
+	}
+}
+
+func TestGitHooks(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"config", "user.name", "Test"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "commit.gpgsign", "false"},
+		{"config", "core.hooksPath", ".git/hooks"},
+	} {
+		if _, err := git(repo, args...); err != nil {
+			t.Fatalf("git %v failed: %v", args, err)
+		}
+	}
+	t.Chdir(repo)
+
+	run := func(args ...string) (string, string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(errOut)
+		err := cmd.Execute()
+		return out.String(), errOut.String(), err
+	}
+
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "Login page"},
+		{"issue", "create", "--project", projectKey, "--title", "Audit log"},
+		{"project", "workflow", projectKey, "--transition", "TODO:DOING"},
+	} {
+		if _, _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	// Install, refusing to replace a foreign hook
+	hooksDir := filepath.Join(repo, ".git", "hooks")
+	os.MkdirAll(hooksDir, 0755)
+	os.WriteFile(filepath.Join(hooksDir, "post-commit"), []byte("#!/bin/sh\necho mine\n"), 0755)
+	if _, _, err := run("git", "install-hooks"); err == nil {
+		t.Fatal("Expected install-hooks to refuse replacing an existing hook")
+	}
+	if _, _, err := run("git", "install-hooks", "--force"); err != nil {
+		t.Fatalf("install-hooks --force failed: %v", err)
+	}
+	for _, name := range []string{"commit-msg", "post-commit"} {
+		info, err := os.Stat(filepath.Join(hooksDir, name))
+		if err != nil || info.Mode()&0111 == 0 {
+			t.Errorf("Expected an executable %s hook (%v)", name, err)
+		}
+	}
+	if _, _, err := run("git", "install-hooks"); err != nil {
+		t.Errorf("Reinstalling hooks failed: %v", err)
+	}
+
+	// The commit-msg check rejects closing unknown issues
+	messageFile := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+	os.WriteFile(messageFile, []byte("Fix it\n\nFixes "+projectKey+"-9\n# Fixes "+projectKey+"-10\n"), 0644)
+	if _, _, err := run("git", "commit-msg", messageFile); err == nil || !strings.Contains(err.Error(), projectKey+"-9") {
+		t.Errorf("Expected the message to be rejected, got %v", err)
+	}
+	os.WriteFile(messageFile, []byte("Fix it\n\nFixes "+projectKey+"-1, UTF-8\n"), 0644)
+	if _, _, err := run("git", "commit-msg", messageFile); err != nil {
+		t.Errorf("Expected the message to pass, got %v", err)
+	}
+
+	// Closing keywords close allowed issues; blocked ones only record the commit
+	if _, _, err := run("issue", "update", projectKey+"-1", "--status", "DOING"); err != nil {
+		t.Fatal(err)
+	}
+	message := "Add login\n\nFixes " + projectKey + "-1 and " + projectKey + "-2"
+	if _, err := git(repo, "-c", "core.hooksPath=/dev/null", "commit", "--quiet", "--allow-empty", "-m", message); err != nil {
+		t.Fatalf("git commit failed: %v", err)
+	}
+	sha, _ := git(repo, "rev-parse", "HEAD")
+	head := strings.TrimSpace(string(sha))
+
+	out, errOut, err := run("git", "post-commit")
+	if err != nil {
+		t.Fatalf("git post-commit failed: %v", err)
+	}
+	if !strings.Contains(out, "Closed "+projectKey+"-1 (DONE)") || !strings.Contains(errOut, "did not close "+projectKey+"-2") {
+		t.Errorf("Unexpected post-commit output:\n%s\n%s", out, errOut)
+	}
+	first, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-1")
+	second, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-2")
+	if first.Status != models.StatusDONE || !slices.Equal(first.Commits, []string{head}) {
+		t.Errorf("Expected %s-1 closed with the commit, got %s %v", projectKey, first.Status, first.Commits)
+	}
+	if second.Status != models.StatusTODO || !slices.Equal(second.Commits, []string{head}) {
+		t.Errorf("Expected %s-2 open with the commit, got %s %v", projectKey, second.Status, second.Commits)
+	}
+	if index, _ := loadQueryIndex(t.Context(), projectKey); index.FindIssue(projectKey+"-1").Status != models.StatusDONE {
+		t.Error("Expected the index to show the closed status")
+	}
+
+	// Running again changes nothing
+	if out, _, _ := run("git", "post-commit"); out != "" {
+		t.Errorf("Expected no changes on a second run, got %q", out)
+	}
+
+	if _, _, err := run("git", "install-hooks", "--remove"); err != nil {
+		t.Fatalf("install-hooks --remove failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(hooksDir, "post-commit")); !os.IsNotExist(err) {
+		t.Error("Expected the post-commit hook to be removed")
+	}
+}
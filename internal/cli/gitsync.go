@@ -0,0 +1,602 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// syncGitignore keeps everything but project data out of a synced data
+// directory: the config, unlocked keys, the replica ID, and caches stay on
+// this machine.
+const syncGitignore = `# Written by buyruk sync init; only project data is synced
+/*
+!/.gitignore
+!/.gitattributes
+!/projects/
+.buyruk.lock
+.buyruk_pending
+.buyruk_lockstats
+.buyruk_seq
+*.tmp
+`
+
+// syncGitattributes makes git leave JSON files changed on both sides to sync
+// pull, which merges them field by field instead of line by line.
+const syncGitattributes = `# Written by buyruk sync init; buyruk sync pull merges JSON files
+*.json -merge
+`
+
+// syncRemote is the name of the git remote sync pushes to and pulls from.
+const syncRemote = "origin"
+
+// NewSyncInitCmd creates and returns the sync init command.
+func NewSyncInitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Version the data directory (or a project) with git",
+		Long: `Turn the data directory into a git repository, so every change is committed
+and can be synced between machines with sync push and sync pull. With
+--project, only that project's directory becomes a repository.
+
+Only project data is committed: the configuration, unlocked keys, and caches
+stay on this machine. After sync init, every command that changes data
+commits it with a message describing the change (skip with --no-autocommit).
+
+In repo-local mode the data is already versioned with the code; turn on
+autocommit (buyruk config set autocommit true) instead.`,
+		Example: `  buyruk sync init --remote git@github.com:alice/buyruk-data.git
+  buyruk sync init --project CORE --remote https://git.example.com/core-issues.git`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return initGitSync(cmd)
+		},
+	}
+
+	cmd.Flags().String("remote", "", "URL of the git repository to sync with")
+
+	return cmd
+}
+
+// NewSyncPushCmd creates and returns the sync push command.
+func NewSyncPushCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "push",
+		Short: "Send committed changes to the sync remote",
+		Long: `Commit any pending changes and push them to the remote set with sync init.
+If the remote has changes from another machine, run sync pull first.`,
+		Example: `  buyruk sync push
+  buyruk sync push --project CORE`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return pushGitSync(cmd)
+		},
+	}
+	return cmd
+}
+
+// NewSyncPullCmd creates and returns the sync pull command.
+func NewSyncPullCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pull",
+		Short: "Fetch and merge changes from the sync remote",
+		Long: `Commit any pending changes, then fetch and merge the remote's changes.
+
+Files changed on both machines are merged field by field with the last
+common version as the base: a field changed on one side takes that change,
+lists such as labels and comments keep the additions of both sides, and
+issues in the index are merged by ID. A field changed differently on both
+sides takes the value of the copy updated last; these are reported as
+conflicts.`,
+		Example: `  buyruk sync pull
+  buyruk sync pull --project CORE`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return pullGitSync(cmd)
+		},
+	}
+	return cmd
+}
+
+// gitSyncTarget returns the directory sync init versions: the project
+// directory with --project, otherwise the data directory.
+func gitSyncTarget(cmd *cobra.Command) (string, error) {
+	if localDir, ok := storage.LocalDir(); ok {
+		return "", fmt.Errorf("cli: data in %s is versioned with its repository; use buyruk config set autocommit true instead", localDir)
+	}
+	if cmd.Flags().Changed("project") {
+		projectKey, _ := cmd.Flags().GetString("project")
+		projectDir, err := storage.ProjectDir(cmd.Context(), projectKey)
+		if err != nil {
+			return "", fmt.Errorf("cli: failed to resolve project directory: %w", err)
+		}
+		if _, err := os.Stat(projectDir); err != nil {
+			return "", notFoundf("cli: project %q does not exist", projectKey)
+		}
+		return projectDir, nil
+	}
+	dataDir, err := storage.DataDir(cmd.Context())
+	if err != nil {
+		return "", fmt.Errorf("cli: %w", err)
+	}
+	return dataDir, nil
+}
+
+// gitSyncRepo returns the repository sync push and pull work on, which must
+// have been set up with sync init.
+func gitSyncRepo(cmd *cobra.Command) (string, error) {
+	dir, err := gitSyncTarget(cmd)
+	if err != nil {
+		return "", err
+	}
+	if !isGitSyncRepo(dir) {
+		if cmd.Flags().Changed("project") {
+			projectKey, _ := cmd.Flags().GetString("project")
+			return "", fmt.Errorf("cli: project %q is not synced (run 'buyruk sync init --project %s')", projectKey, projectKey)
+		}
+		return "", fmt.Errorf("cli: the data directory is not synced (run 'buyruk sync init')")
+	}
+	return dir, nil
+}
+
+// isGitSyncRepo reports whether dir is the root of a repository set up by
+// sync init.
+func isGitSyncRepo(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// gitSyncRepos returns the repositories set up by sync init: the data
+// directory's and those of single projects.
+func gitSyncRepos(ctx context.Context) []string {
+	if _, ok := storage.LocalDir(); ok {
+		return nil
+	}
+	var repos []string
+	if dataDir, err := storage.DataDir(ctx); err == nil && isGitSyncRepo(dataDir) {
+		repos = append(repos, dataDir)
+	}
+	keys, _ := storage.ListProjects(ctx)
+	for _, key := range keys {
+		if projectDir, err := storage.ProjectDir(ctx, key); err == nil && isGitSyncRepo(projectDir) {
+			repos = append(repos, projectDir)
+		}
+	}
+	return repos
+}
+
+// initGitSync sets up a repository for syncing and commits the current data.
+func initGitSync(cmd *cobra.Command) error {
+	dir, err := gitSyncTarget(cmd)
+	if err != nil {
+		return err
+	}
+	out := successOut(cmd)
+	created := !isGitSyncRepo(dir)
+	if created {
+		if _, err := git(dir, "init", "--quiet"); err != nil {
+			return fmt.Errorf("cli: %w", err)
+		}
+	}
+
+	gitignore := localGitignore
+	if dataDir, err := storage.DataDir(cmd.Context()); err == nil && dir == dataDir {
+		gitignore = syncGitignore
+	}
+	for name, content := range map[string]string{".gitignore": gitignore, ".gitattributes": syncGitattributes} {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				return fmt.Errorf("cli: failed to write %s: %w", path, err)
+			}
+		}
+	}
+
+	if remote, _ := cmd.Flags().GetString("remote"); remote != "" {
+		if _, err := git(dir, "remote", "get-url", syncRemote); err == nil {
+			_, err = git(dir, "remote", "set-url", syncRemote, remote)
+			if err != nil {
+				return fmt.Errorf("cli: %w", err)
+			}
+		} else if _, err := git(dir, "remote", "add", syncRemote, remote); err != nil {
+			return fmt.Errorf("cli: %w", err)
+		}
+	}
+
+	if err := commitLocalChanges(dir); err != nil {
+		return fmt.Errorf("cli: %w", err)
+	}
+	if created {
+		fmt.Fprintf(out, "Syncing %s with git\n", dir)
+	} else {
+		fmt.Fprintf(out, "%s is already synced with git\n", dir)
+	}
+	if remote, err := git(dir, "remote", "get-url", syncRemote); err == nil {
+		fmt.Fprintf(out, "Remote: %s (run 'buyruk sync pull' to bring in its changes, 'buyruk sync push' to send yours)\n", strings.TrimSpace(string(remote)))
+	} else {
+		fmt.Fprintln(out, "No remote yet (add one with 'buyruk sync init --remote URL')")
+	}
+	return nil
+}
+
+// pushGitSync commits pending changes and pushes them to the sync remote.
+func pushGitSync(cmd *cobra.Command) error {
+	dir, err := gitSyncRepo(cmd)
+	if err != nil {
+		return err
+	}
+	if err := requireSyncRemote(dir); err != nil {
+		return err
+	}
+	if err := commitLocalChanges(dir); err != nil {
+		return fmt.Errorf("cli: %w", err)
+	}
+
+	ahead := -1
+	if count, err := git(dir, "rev-list", "--count", "@{upstream}..HEAD"); err == nil {
+		ahead, _ = strconv.Atoi(strings.TrimSpace(string(count)))
+	}
+	out := successOut(cmd)
+	if ahead == 0 {
+		fmt.Fprintln(out, "Nothing to push")
+		return nil
+	}
+	if _, err := git(dir, "push", "--quiet", "--set-upstream", syncRemote, "HEAD"); err != nil {
+		if strings.Contains(err.Error(), "rejected") || strings.Contains(err.Error(), "fetch first") {
+			return fmt.Errorf("cli: the remote has changes from elsewhere; run 'buyruk sync pull' first")
+		}
+		return fmt.Errorf("cli: %w", err)
+	}
+	if ahead > 0 {
+		fmt.Fprintf(out, "Pushed %d commits to %s\n", ahead, syncRemote)
+	} else {
+		fmt.Fprintf(out, "Pushed to %s\n", syncRemote)
+	}
+	return nil
+}
+
+// requireSyncRemote returns an error if the repository has no sync remote.
+func requireSyncRemote(dir string) error {
+	if _, err := git(dir, "remote", "get-url", syncRemote); err != nil {
+		return fmt.Errorf("cli: no remote to sync with (add one with 'buyruk sync init --remote URL')")
+	}
+	return nil
+}
+
+// pullGitSync commits pending changes, then fetches and merges the remote's.
+func pullGitSync(cmd *cobra.Command) error {
+	dir, err := gitSyncRepo(cmd)
+	if err != nil {
+		return err
+	}
+	if err := requireSyncRemote(dir); err != nil {
+		return err
+	}
+	if err := commitLocalChanges(dir); err != nil {
+		return fmt.Errorf("cli: %w", err)
+	}
+	if _, err := git(dir, "fetch", "--quiet", syncRemote); err != nil {
+		return fmt.Errorf("cli: %w", err)
+	}
+
+	out := successOut(cmd)
+	branch, err := git(dir, "symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return fmt.Errorf("cli: %w", err)
+	}
+	remoteRef := syncRemote + "/" + strings.TrimSpace(string(branch))
+	if _, err := git(dir, "rev-parse", "--verify", "--quiet", "refs/remotes/"+remoteRef); err != nil {
+		fmt.Fprintln(out, "Nothing to pull: the remote has no data yet")
+		return nil
+	}
+	count, err := git(dir, "rev-list", "--count", "HEAD.."+remoteRef)
+	if err != nil {
+		return fmt.Errorf("cli: %w", err)
+	}
+	incoming, _ := strconv.Atoi(strings.TrimSpace(string(count)))
+	if incoming == 0 {
+		fmt.Fprintln(out, "Already up to date")
+		return nil
+	}
+
+	merged, conflicts := 0, 0
+	if _, err := git(dir, "merge", "--quiet", "--no-edit", "--allow-unrelated-histories", remoteRef); err != nil {
+		merged, conflicts, err = resolveSyncMerge(dir)
+		if err != nil {
+			git(dir, "merge", "--abort")
+			return err
+		}
+		if _, err := git(dir, "commit", "--quiet", "--no-edit"); err != nil {
+			git(dir, "merge", "--abort")
+			return fmt.Errorf("cli: %w", err)
+		}
+	}
+
+	fmt.Fprintf(out, "Pulled %d commits from %s", incoming, syncRemote)
+	if merged > 0 {
+		fmt.Fprintf(out, "; merged %d files changed on both sides", merged)
+	}
+	fmt.Fprintln(out)
+	if conflicts > 0 {
+		fmt.Fprintf(out, "%d fields were changed differently on both sides and kept from the copy updated last\n", conflicts)
+	}
+	return nil
+}
+
+// resolveSyncMerge merges the files a git merge left conflicted: JSON files
+// field by field, and files deleted on one side and changed on the other by
+// keeping the changed copy. It returns the number of files merged and of
+// fields changed differently on both sides.
+func resolveSyncMerge(dir string) (merged, conflicts int, err error) {
+	output, err := git(dir, "diff", "--name-only", "--diff-filter=U", "-z")
+	if err != nil {
+		return 0, 0, fmt.Errorf("cli: %w", err)
+	}
+	paths := strings.Split(strings.TrimSuffix(string(output), "\x00"), "\x00")
+	if len(paths) == 1 && paths[0] == "" {
+		return 0, 0, fmt.Errorf("cli: git merge failed without conflicts; see git status in %s", dir)
+	}
+
+	for _, path := range paths {
+		stage := func(n int) []byte {
+			data, err := git(dir, "show", fmt.Sprintf(":%d:%s", n, path))
+			if err != nil {
+				return nil
+			}
+			return data
+		}
+		base, ours, theirs := stage(1), stage(2), stage(3)
+
+		var result []byte
+		switch {
+		case ours == nil:
+			result = theirs
+		case theirs == nil:
+			result = ours
+		case strings.HasSuffix(path, ".json"):
+			data, n, err := mergeJSON3(base, ours, theirs)
+			if err != nil {
+				return 0, 0, fmt.Errorf("cli: failed to merge %s: %w", path, err)
+			}
+			result, conflicts = canonicalSyncJSON(path, data), conflicts+n
+		default:
+			return 0, 0, fmt.Errorf("cli: %s changed on both sides; merge it with git in %s", path, dir)
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(path)), result, 0644); err != nil {
+			return 0, 0, fmt.Errorf("cli: failed to write %s: %w", path, err)
+		}
+		if _, err := git(dir, "add", "--", path); err != nil {
+			return 0, 0, fmt.Errorf("cli: %w", err)
+		}
+		merged++
+	}
+	return merged, conflicts, nil
+}
+
+// canonicalSyncJSON re-encodes a merged issue, epic, or index with the field
+// order buyruk writes, so merges don't reorder files. Other files are kept
+// as merged.
+func canonicalSyncJSON(path string, data []byte) []byte {
+	var v interface{}
+	switch name := filepath.Base(path); {
+	case name == "project.json":
+		v = &models.ProjectIndex{}
+	case filepath.Base(filepath.Dir(path)) == "issues":
+		v = &models.Issue{}
+	case filepath.Base(filepath.Dir(path)) == "epics":
+		v = &models.Epic{}
+	default:
+		return data
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return data
+	}
+	encoded, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return data
+	}
+	return encoded
+}
+
+// jsonAbsent marks a field missing from one version in a three-way merge.
+var jsonAbsent = &struct{}{}
+
+// jsonMerge is a three-way merge of JSON values.
+type jsonMerge struct {
+	preferTheirs bool // Take their value when both sides changed a field
+	conflicts    int  // Fields changed differently on both sides
+}
+
+// mergeJSON3 merges two versions of a JSON document changed from a common
+// base (nil when there is none). It returns the merged document and the
+// number of fields changed differently on both sides, which take the value
+// of the version whose top-level updated_at is later (ours on a tie).
+func mergeJSON3(base, ours, theirs []byte) ([]byte, int, error) {
+	decode := func(data []byte) (interface{}, error) {
+		if data == nil {
+			return jsonAbsent, nil
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		var v interface{}
+		if err := decoder.Decode(&v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+	b, err := decode(base)
+	if err != nil {
+		return nil, 0, err
+	}
+	o, err := decode(ours)
+	if err != nil {
+		return nil, 0, err
+	}
+	t, err := decode(theirs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	updatedAt := func(v interface{}) string {
+		if object, ok := v.(map[string]interface{}); ok {
+			s, _ := object["updated_at"].(string)
+			return s
+		}
+		return ""
+	}
+	m := &jsonMerge{preferTheirs: newerRecord(updatedAt(t), updatedAt(o))}
+	merged, err := json.MarshalIndent(m.merge("", b, o, t), "", "  ")
+	if err != nil {
+		return nil, 0, err
+	}
+	return merged, m.conflicts, nil
+}
+
+// merge merges one value. key is the name of the field holding it.
+func (m *jsonMerge) merge(key string, base, ours, theirs interface{}) interface{} {
+	switch {
+	case reflect.DeepEqual(ours, theirs), reflect.DeepEqual(base, theirs):
+		return ours
+	case reflect.DeepEqual(base, ours):
+		return theirs
+	// A record deleted on one side and changed on the other is kept
+	case ours == jsonAbsent:
+		return theirs
+	case theirs == jsonAbsent:
+		return ours
+	}
+
+	if key == "updated_at" {
+		if s, ok := theirs.(string); ok && newerRecord(s, fmt.Sprint(ours)) {
+			return theirs
+		}
+		return ours
+	}
+	if o, ok := ours.(map[string]interface{}); ok {
+		if t, ok := theirs.(map[string]interface{}); ok {
+			b, _ := base.(map[string]interface{})
+			return m.mergeObjects(b, o, t)
+		}
+	}
+	if o, ok := ours.([]interface{}); ok {
+		if t, ok := theirs.([]interface{}); ok {
+			b, _ := base.([]interface{})
+			return m.mergeLists(b, o, t)
+		}
+	}
+
+	m.conflicts++
+	if m.preferTheirs {
+		return theirs
+	}
+	return ours
+}
+
+// mergeObjects merges the fields of two objects.
+func (m *jsonMerge) mergeObjects(base, ours, theirs map[string]interface{}) map[string]interface{} {
+	field := func(object map[string]interface{}, key string) interface{} {
+		if v, ok := object[key]; ok {
+			return v
+		}
+		return jsonAbsent
+	}
+	merged := map[string]interface{}{}
+	for _, object := range []map[string]interface{}{ours, theirs} {
+		for key := range object {
+			if _, done := merged[key]; done {
+				continue
+			}
+			if v := m.merge(key, field(base, key), field(ours, key), field(theirs, key)); v != jsonAbsent {
+				merged[key] = v
+			}
+		}
+	}
+	return merged
+}
+
+// mergeLists merges two lists. Lists of objects with an "id" (such as the
+// issues of an index) are merged entry by entry; other lists keep the
+// entries of ours that they did not remove, followed by the entries they
+// added.
+func (m *jsonMerge) mergeLists(base, ours, theirs []interface{}) []interface{} {
+	if ids, ok := listIDs(base, ours, theirs); ok {
+		byID := func(list []interface{}) map[string]interface{} {
+			entries := map[string]interface{}{}
+			for _, entry := range list {
+				entries[entry.(map[string]interface{})["id"].(string)] = entry
+			}
+			return entries
+		}
+		b, o, t := byID(base), byID(ours), byID(theirs)
+		merged := []interface{}{}
+		for _, id := range ids {
+			field := func(entries map[string]interface{}) interface{} {
+				if v, ok := entries[id]; ok {
+					return v
+				}
+				return jsonAbsent
+			}
+			if v := m.merge("", field(b), field(o), field(t)); v != jsonAbsent {
+				merged = append(merged, v)
+			}
+		}
+		return merged
+	}
+
+	contains := func(list []interface{}, v interface{}) bool {
+		for _, entry := range list {
+			if reflect.DeepEqual(entry, v) {
+				return true
+			}
+		}
+		return false
+	}
+	merged := []interface{}{}
+	for _, entry := range ours {
+		if !contains(base, entry) || contains(theirs, entry) {
+			merged = append(merged, entry)
+		}
+	}
+	for _, entry := range theirs {
+		if !contains(base, entry) && !contains(ours, entry) {
+			merged = append(merged, entry)
+		}
+	}
+	return merged
+}
+
+// listIDs returns the IDs of lists whose entries are all objects with a
+// string "id", in the order of ours followed by the IDs only theirs have.
+func listIDs(base, ours, theirs []interface{}) ([]string, bool) {
+	var ids []string
+	seen := map[string]bool{}
+	for i, list := range [][]interface{}{ours, theirs, base} {
+		for _, entry := range list {
+			object, ok := entry.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			id, ok := object["id"].(string)
+			if !ok {
+				return nil, false
+			}
+			if !seen[id] && i < 2 {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids, len(ours)+len(theirs) > 0
+}
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestGitSync(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	for _, name := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(name, "Test")
+	}
+	for _, name := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(name, "test@example.com")
+	}
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "commit.gpgsign")
+	t.Setenv("GIT_CONFIG_VALUE_0", "false")
+
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+	defer os.RemoveAll(projectDir)
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(append(args, "--project", projectKey, "--no-hooks"))
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	if _, err := run("project", "create", projectKey); err != nil {
+		t.Fatalf("project create failed: %v", err)
+	}
+	if _, err := run("issue", "create", "--title", "Login redirect"); err != nil {
+		t.Fatalf("issue create failed: %v", err)
+	}
+	if _, err := run("sync", "push"); err == nil || !strings.Contains(err.Error(), "not synced") {
+		t.Fatalf("Expected a not synced error, got %v", err)
+	}
+
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	if _, err := git(filepath.Dir(remote), "init", "--quiet", "--bare", remote); err != nil {
+		t.Fatalf("git init --bare failed: %v", err)
+	}
+	if out, err := run("sync", "init", "--remote", remote); err != nil || !strings.Contains(out, "Syncing "+projectDir) {
+		t.Fatalf("sync init failed: %v\n%s", err, out)
+	}
+	if out, err := run("sync", "push"); err != nil || !strings.Contains(out, "Pushed to origin") {
+		t.Fatalf("sync push failed: %v\n%s", err, out)
+	}
+
+	// Another machine retitles the issue...
+	branch, _ := git(projectDir, "symbolic-ref", "--short", "HEAD")
+	clone := filepath.Join(t.TempDir(), "clone")
+	if _, err := git(filepath.Dir(clone), "clone", "--quiet", "--branch", strings.TrimSpace(string(branch)), remote, clone); err != nil {
+		t.Fatalf("git clone failed: %v", err)
+	}
+	issueFile := filepath.Join("issues", projectKey+"-1.json")
+	var issue map[string]interface{}
+	data, _ := os.ReadFile(filepath.Join(clone, issueFile))
+	if err := json.Unmarshal(data, &issue); err != nil {
+		t.Fatalf("Failed to parse cloned issue: %v", err)
+	}
+	issue["title"] = "Fix login redirect"
+	data, _ = json.MarshalIndent(issue, "", "  ")
+	os.WriteFile(filepath.Join(clone, issueFile), data, 0644)
+	for _, args := range [][]string{{"commit", "--quiet", "-am", "Retitle"}, {"push", "--quiet"}} {
+		if _, err := git(clone, args...); err != nil {
+			t.Fatalf("git %v failed: %v", args, err)
+		}
+	}
+
+	// ...while this one moves it along, which is committed right away
+	if _, err := run("issue", "update", projectKey+"-1", "--status", "DOING"); err != nil {
+		t.Fatalf("issue update failed: %v", err)
+	}
+	subject, _ := git(projectDir, "log", "-1", "--format=%s")
+	if got := strings.TrimSpace(string(subject)); got != "buyruk: "+projectKey+"-1 status TODO→DOING" {
+		t.Errorf("Subject = %q, want the status change", got)
+	}
+
+	if _, err := run("sync", "push"); err == nil || !strings.Contains(err.Error(), "sync pull") {
+		t.Fatalf("Expected push to ask for a pull, got %v", err)
+	}
+	out, err := run("sync", "pull")
+	if err != nil || !strings.Contains(out, "Pulled 1 commits from origin; merged 1 files") {
+		t.Fatalf("sync pull failed: %v\n%s", err, out)
+	}
+	merged, err := loadLocalIssue(t.Context(), projectKey, projectKey+"-1")
+	if err != nil || merged.Title != "Fix login redirect" || merged.Status != "DOING" {
+		t.Fatalf("Expected both changes, got %+v (%v)", merged, err)
+	}
+	if out, err := run("sync", "push"); err != nil || !strings.Contains(out, "Pushed 2 commits") {
+		t.Fatalf("sync push after pull failed: %v\n%s", err, out)
+	}
+	if out, err := run("sync", "pull"); err != nil || !strings.Contains(out, "Already up to date") {
+		t.Fatalf("second sync pull failed: %v\n%s", err, out)
+	}
+}
+
+func TestMergeJSON3(t *testing.T) {
+	base := `{"id": "CORE-1", "title": "Login", "status": "TODO", "labels": ["auth"], "updated_at": "2026-01-01T00:00:00Z",
+		"comments": [{"body": "first"}], "issues": [{"id": "CORE-1", "title": "Login"}, {"id": "CORE-2", "title": "Gone"}]}`
+	ours := `{"id": "CORE-1", "title": "Login", "status": "DOING", "labels": ["auth", "web"], "updated_at": "2026-01-02T00:00:00Z",
+		"comments": [{"body": "first"}, {"body": "ours"}], "issues": [{"id": "CORE-1", "title": "Login"}]}`
+	theirs := `{"id": "CORE-1", "title": "Fix login", "status": "DONE", "labels": ["web", "ui"], "updated_at": "2026-01-03T00:00:00Z",
+		"comments": [{"body": "first"}, {"body": "theirs"}], "issues": [{"id": "CORE-1", "title": "Fix login"}, {"id": "CORE-2", "title": "Gone"}, {"id": "CORE-3", "title": "New"}]}`
+
+	data, conflicts, err := mergeJSON3([]byte(base), []byte(ours), []byte(theirs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conflicts != 1 {
+		t.Errorf("conflicts = %d, want 1 (status)", conflicts)
+	}
+	var got struct {
+		Title     string              `json:"title"`
+		Status    string              `json:"status"`
+		Labels    []string            `json:"labels"`
+		UpdatedAt string              `json:"updated_at"`
+		Comments  []map[string]string `json:"comments"`
+		Issues    []map[string]string `json:"issues"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	// The status changed on both sides; theirs was updated last
+	if got.Title != "Fix login" || got.Status != "DONE" || got.UpdatedAt != "2026-01-03T00:00:00Z" {
+		t.Errorf("Unexpected fields: %+v", got)
+	}
+	if strings.Join(got.Labels, ",") != "web,ui" {
+		t.Errorf("labels = %v, want web,ui", got.Labels)
+	}
+	if len(got.Comments) != 3 || got.Comments[1]["body"] != "ours" || got.Comments[2]["body"] != "theirs" {
+		t.Errorf("comments = %v, want both additions", got.Comments)
+	}
+	if len(got.Issues) != 2 || got.Issues[0]["title"] != "Fix login" || got.Issues[1]["id"] != "CORE-3" {
+		t.Errorf("issues = %v, want CORE-1 retitled, CORE-2 removed, CORE-3 added", got.Issues)
+	}
+}
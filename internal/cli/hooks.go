@@ -0,0 +1,383 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// HookEvent is the payload passed to hooks.
+type HookEvent struct {
+	Event      string        `json:"event"`                 // e.g. "issue.status_changed"
+	Project    string        `json:"project"`               // Project key
+	IssueID    string        `json:"issue_id"`              // Issue the event is about
+	At         string        `json:"at"`                    // ISO 8601 timestamp of the event
+	FromStatus string        `json:"from_status,omitempty"` // Previous status (status_changed)
+	ToStatus   string        `json:"to_status,omitempty"`   // New status (status_changed)
+	Issue      *models.Issue `json:"issue"`                 // The issue after the event (before it, for deletes)
+}
+
+// NewHooksCmd creates and returns the hooks command.
+func NewHooksCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hooks",
+		Short: "Inspect and test project hooks",
+		Long: `Inspect and test the hooks of a project.
+
+Hooks run commands, so they are configured on this machine only, in
+hooks/KEY.json of the buyruk config directory, never with the project data
+that sync, backups, and imports bring in from elsewhere:
+
+  {
+    "hooks": [
+      {"events": ["issue.created", "issue.status_changed"], "command": "./notify.sh"},
+      {"events": ["issue.*"], "url": "https://example.com/buyruk", "headers": {"Authorization": "Bearer ..."}}
+    ]
+  }
+
+Events are issue.created, issue.updated, issue.status_changed, and
+issue.deleted ("issue.*" or "*" for all). After a command succeeds, each
+matching hook gets the event as JSON: commands on stdin (with BUYRUK_EVENT,
+BUYRUK_PROJECT, and BUYRUK_ISSUE_ID set) and URLs as a POST body. A failing
+hook prints a warning but does not fail the command. --no-hooks skips hooks
+for one command.`,
+	}
+
+	cmd.AddCommand(NewHooksListCmd())
+	cmd.AddCommand(NewHooksTestCmd())
+
+	return cmd
+}
+
+// NewHooksListCmd creates and returns the hooks list command.
+func NewHooksListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the hooks of a project",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listHooks(cmd)
+		},
+	}
+
+	return cmd
+}
+
+// NewHooksTestCmd creates and returns the hooks test command.
+func NewHooksTestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "test <issue-id>",
+		Short:             "Fire the hooks of an event for an issue",
+		Long:              "Fire the hooks matching an event with a payload built from an existing issue, and report each result.",
+		Example:           "  buyruk hooks test CORE-12 --event issue.status_changed",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeIssueArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveIssueArgs(cmd, args[:1]); err != nil {
+				return err
+			}
+			return testHooks(args[0], cmd)
+		},
+	}
+
+	cmd.Flags().String("event", models.EventIssueUpdated, "Event to fire")
+
+	return cmd
+}
+
+// loadHooks loads a project's hook configuration. A project without a hook
+// file has no hooks.
+func loadHooks(ctx context.Context, projectKey string) (*models.HookConfig, error) {
+	hooksPath, err := storage.HooksPath(projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve hooks path: %w", err)
+	}
+	var hooks models.HookConfig
+	if err := storage.ReadJSON(ctx, hooksPath, &hooks); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &hooks, nil
+		}
+		return nil, fmt.Errorf("cli: failed to load hooks of %s: %w", projectKey, err)
+	}
+	if err := hooks.Validate(); err != nil {
+		return nil, invalidf("cli: invalid hooks of %s: %w", projectKey, err)
+	}
+	return &hooks, nil
+}
+
+// observeHookEvents queues the events of an issue write: issue.created for a
+// new issue file, and issue.updated (plus issue.status_changed when the status
+// changed) for an existing one.
+func observeHookEvents(ctx context.Context, path string, data []byte) {
+	projectKey, ok := issueFileProject(ctx, path)
+	if !ok {
+		return
+	}
+	var updated models.Issue
+	if err := json.Unmarshal(data, &updated); err != nil {
+		return
+	}
+	event := HookEvent{Project: projectKey, IssueID: updated.ID, At: storage.Timestamp(ctx), Issue: &updated}
+
+	var old models.Issue
+	if err := storage.ReadJSON(ctx, path, &old); err != nil {
+		event.Event = models.EventIssueCreated
+		queueHookEvents(ctx, event)
+		return
+	}
+	event.Event = models.EventIssueUpdated
+	queueHookEvents(ctx, event)
+	if old.Status != updated.Status {
+		event.Event = models.EventIssueStatusChanged
+		event.FromStatus, event.ToStatus = old.Status, updated.Status
+		queueHookEvents(ctx, event)
+	}
+}
+
+// queueDeleteHookEvent queues the issue.deleted event of a deleted issue.
+func queueDeleteHookEvent(ctx context.Context, projectKey string, issue *models.Issue) {
+	queueHookEvents(ctx, HookEvent{
+		Event:   models.EventIssueDeleted,
+		Project: projectKey,
+		IssueID: issue.ID,
+		At:      storage.Timestamp(ctx),
+		Issue:   issue,
+	})
+}
+
+// queueHookEvents queues events in the run of the command that caused them.
+// They are fired after the command succeeds, so a failed or rolled-back
+// command fires none, and its events end with its run.
+func queueHookEvents(ctx context.Context, events ...HookEvent) {
+	state := runStateFrom(ctx)
+	if state == nil {
+		return
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.hookEvents = append(state.hookEvents, events...)
+}
+
+// fireHooks fires the hooks of the events the command queued, unless
+// --no-hooks is given.
+func fireHooks(cmd *cobra.Command) {
+	state := runStateFrom(cmd.Context())
+	if state == nil {
+		return
+	}
+	state.mu.Lock()
+	events := state.hookEvents
+	state.hookEvents = nil
+	state.mu.Unlock()
+	if skip, _ := cmd.Flags().GetBool("no-hooks"); skip || len(events) == 0 {
+		return
+	}
+
+	errOut := cmd.ErrOrStderr()
+	configs := map[string]*models.HookConfig{}
+	for _, event := range events {
+		hooks, ok := configs[event.Project]
+		if !ok {
+			var err error
+			if hooks, err = loadHooks(cmd.Context(), event.Project); err != nil {
+				fmt.Fprintf(errOut, "Warning: %v\n", err)
+			}
+			configs[event.Project] = hooks
+			warnLegacyHooks(cmd.Context(), event.Project, errOut)
+		}
+		if hooks == nil {
+			continue
+		}
+		for i := range hooks.Hooks {
+			hook := &hooks.Hooks[i]
+			if !hook.Matches(event.Event) {
+				continue
+			}
+			if err := runHook(cmd.Context(), hook, &event); err != nil {
+				fmt.Fprintf(errOut, "Warning: hook %q failed on %s of %s: %v\n", hook.Label(), event.Event, event.IssueID, err)
+			}
+		}
+	}
+}
+
+// warnLegacyHooks warns that a project's hooks.json, where hooks were
+// configured before, is ignored.
+func warnLegacyHooks(ctx context.Context, projectKey string, errOut io.Writer) {
+	legacyPath, err := storage.LegacyHooksPath(ctx, projectKey)
+	if err != nil {
+		return
+	}
+	if _, err := os.Stat(legacyPath); err != nil {
+		return
+	}
+	hooksPath, _ := storage.HooksPath(projectKey)
+	fmt.Fprintf(errOut, "Warning: ignoring %s: hooks are configured in %s (move it there if you trust its commands)\n", legacyPath, hooksPath)
+}
+
+// runHook runs one hook with an event payload.
+func runHook(ctx context.Context, hook *models.Hook, event *HookEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, hook.TimeoutDuration())
+	defer cancel()
+
+	if hook.Command != "" {
+		var c *exec.Cmd
+		if runtime.GOOS == "windows" {
+			c = exec.CommandContext(ctx, "cmd", "/C", hook.Command)
+		} else {
+			c = exec.CommandContext(ctx, "sh", "-c", hook.Command)
+		}
+		c.Stdin = bytes.NewReader(payload)
+		c.Env = append(os.Environ(),
+			"BUYRUK_EVENT="+event.Event,
+			"BUYRUK_PROJECT="+event.Project,
+			"BUYRUK_ISSUE_ID="+event.IssueID,
+		)
+		if output, err := c.CombinedOutput(); err != nil {
+			if msg := bytes.TrimSpace(output); len(msg) > 0 {
+				return fmt.Errorf("%w: %s", err, msg)
+			}
+			return err
+		}
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "buyruk")
+	req.Header.Set("X-Buyruk-Event", event.Event)
+	for name, value := range hook.Headers {
+		req.Header.Set(name, value)
+	}
+	client := hostEnvFrom(ctx).hookClient
+	if client == nil {
+		client = &http.Client{Timeout: hook.TimeoutDuration()}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("server returned %s", resp.Status)
+	}
+	return nil
+}
+
+// listHooks prints the hooks of a project.
+func listHooks(cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	if _, err := loadQueryIndex(cmd.Context(), projectKey); err != nil {
+		return err
+	}
+	hooks, err := loadHooks(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	warnLegacyHooks(cmd.Context(), projectKey, cmd.ErrOrStderr())
+
+	out := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(hooks)
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(out, hooks)
+	case config.DefaultFormatLSON:
+		for _, hook := range hooks.Hooks {
+			target := hook.Command
+			if target == "" {
+				target = hook.URL
+			}
+			fmt.Fprintf(out, "@HOOK: %s|%s\n", strings.Join(hook.Events, ","), target)
+		}
+	default:
+		if len(hooks.Hooks) == 0 {
+			hooksPath, _ := storage.HooksPath(projectKey)
+			fmt.Fprintf(out, "No hooks in project %q (configure them in %s)\n", projectKey, hooksPath)
+			return nil
+		}
+		styles := ui.NewStyles()
+		for _, hook := range hooks.Hooks {
+			kind, target := "command", hook.Command
+			if target == "" {
+				kind, target = "POST", hook.URL
+			}
+			fmt.Fprintf(out, "%s %s: %s\n", styles.Label(strings.Join(hook.Events, ",")), kind, target)
+		}
+	}
+	return nil
+}
+
+// testHooks fires the hooks of an event for an existing issue.
+func testHooks(issueID string, cmd *cobra.Command) error {
+	projectKey, _, err := parseIssueID(cmd.Context(), issueID)
+	if err != nil {
+		return invalidf("cli: invalid issue ID %q: %w", issueID, err)
+	}
+	event, _ := cmd.Flags().GetString("event")
+	if !slices.Contains(models.HookEvents, event) {
+		return invalidf("cli: unknown event %q (allowed: %s)", event, strings.Join(models.HookEvents, ", "))
+	}
+	issue, err := loadLocalIssue(cmd.Context(), projectKey, issueID)
+	if err != nil {
+		return err
+	}
+	if issue == nil {
+		return notFoundf("cli: issue %q not found", issueID)
+	}
+	hooks, err := loadHooks(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	warnLegacyHooks(cmd.Context(), projectKey, cmd.ErrOrStderr())
+
+	payload := HookEvent{Event: event, Project: projectKey, IssueID: issueID, At: storage.Timestamp(cmd.Context()), Issue: issue}
+	if event == models.EventIssueStatusChanged {
+		payload.FromStatus, payload.ToStatus = issue.Status, issue.Status
+	}
+	out := cmd.OutOrStdout()
+	fired := 0
+	for i := range hooks.Hooks {
+		hook := &hooks.Hooks[i]
+		if !hook.Matches(event) {
+			continue
+		}
+		fired++
+		if err := runHook(cmd.Context(), hook, &payload); err != nil {
+			fmt.Fprintf(out, "FAIL %s: %v\n", hook.Label(), err)
+		} else {
+			fmt.Fprintf(out, "OK   %s\n", hook.Label())
+		}
+	}
+	if fired == 0 {
+		fmt.Fprintf(out, "No hooks of project %q fire on %s\n", projectKey, event)
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestHooks(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(errOut)
+		err := cmd.Execute()
+		return out.String(), errOut.String(), err
+	}
+
+	if _, _, err := run("project", "create", projectKey); err != nil {
+		t.Fatalf("project create failed: %v", err)
+	}
+
+	var mu sync.Mutex
+	var posted []HookEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var event HookEvent
+		if err := json.Unmarshal(body, &event); err != nil || r.Header.Get("X-Buyruk-Event") != event.Event || r.Header.Get("X-Token") != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		posted = append(posted, event)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	logPath := filepath.Join(t.TempDir(), "events.log")
+	hooksPath, _ := storage.HooksPath(projectKey)
+	defer os.Remove(hooksPath)
+	writeHooks := func(hooks models.HookConfig) {
+		data, _ := json.Marshal(hooks)
+		if err := storage.EnsureDir(hooksPath); err != nil {
+			t.Fatal(err)
+		}
+		if err := storage.WriteAtomic(t.Context(), hooksPath, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeHooks(models.HookConfig{Hooks: []models.Hook{
+		{Events: []string{"issue.*"}, Command: `echo "$BUYRUK_EVENT $BUYRUK_ISSUE_ID" >> '` + logPath + `'`},
+		{Events: []string{models.EventIssueStatusChanged}, URL: server.URL, Headers: map[string]string{"X-Token": "secret"}},
+	}})
+	issueID := projectKey + "-1"
+
+	for _, args := range [][]string{
+		{"issue", "create", "--project", projectKey, "--title", "Login page"},
+		{"issue", "update", issueID, "--status", "DOING"},
+		{"issue", "update", issueID, "--title", "Login", "--no-hooks"},
+		{"issue", "delete", issueID, "--yes"},
+	} {
+		if _, errOut, err := run(args...); err != nil || errOut != "" {
+			t.Fatalf("%v failed: %v %s", args, err, errOut)
+		}
+	}
+
+	logged, _ := os.ReadFile(logPath)
+	want := strings.Join([]string{
+		models.EventIssueCreated + " " + issueID,
+		models.EventIssueUpdated + " " + issueID,
+		models.EventIssueStatusChanged + " " + issueID,
+		models.EventIssueDeleted + " " + issueID,
+	}, "\n") + "\n"
+	if string(logged) != want {
+		t.Errorf("Expected command hook log:\n%s\ngot:\n%s", want, logged)
+	}
+	if len(posted) != 1 || posted[0].FromStatus != "TODO" || posted[0].ToStatus != "DOING" || posted[0].Issue == nil {
+		t.Errorf("Expected one status_changed POST from TODO to DOING, got %+v", posted)
+	}
+
+	// A failing hook warns without failing the command
+	writeHooks(models.HookConfig{Hooks: []models.Hook{{Name: "broken", Events: []string{"*"}, Command: "exit 3"}}})
+	out, errOut, err := run("issue", "create", "--project", projectKey, "--title", "Audit log")
+	if err != nil {
+		t.Fatalf("issue create failed: %v", err)
+	}
+	if !strings.Contains(errOut, `hook "broken" failed on issue.created`) {
+		t.Errorf("Expected a hook failure warning, got %q", errOut)
+	}
+
+	createdID := strings.Trim(strings.TrimPrefix(strings.TrimSpace(out), "Created issue "), `"`)
+	out, _, err = run("hooks", "test", createdID, "--event", models.EventIssueCreated)
+	if err != nil || !strings.Contains(out, "FAIL broken") {
+		t.Errorf("Expected hooks test to report the failure, got %q (%v)", out, err)
+	}
+	if _, _, err := run("hooks", "test", createdID, "--event", "issue.closed"); err == nil {
+		t.Error("Expected an unknown event to be rejected")
+	}
+
+	// An invalid hook file warns
+	writeHooks(models.HookConfig{Hooks: []models.Hook{{Events: []string{"issue.created"}}}})
+	_, errOut, err = run("issue", "create", "--project", projectKey, "--title", "Exports")
+	if err != nil || !strings.Contains(errOut, "invalid hooks of "+projectKey) {
+		t.Errorf("Expected an invalid hooks warning, got %q (%v)", errOut, err)
+	}
+	if _, _, err := run("hooks", "list", "--project", projectKey); err == nil {
+		t.Error("Expected hooks list to fail on an invalid hook file")
+	}
+}
+
+func TestHooks_AbandonedRunFiresNothing(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) {
+		t.Helper()
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+	run("project", "create", projectKey)
+	run("issue", "create", "--project", projectKey, "--title", "Login page")
+	issueID := projectKey + "-1"
+
+	logPath := filepath.Join(t.TempDir(), "events.log")
+	hooksPath, _ := storage.HooksPath(projectKey)
+	defer os.Remove(hooksPath)
+	hooks, _ := json.Marshal(models.HookConfig{Hooks: []models.Hook{
+		{Events: []string{"issue.*"}, Command: `echo "$BUYRUK_EVENT $BUYRUK_ISSUE_ID" >> '` + logPath + `'`},
+	}})
+	if err := storage.EnsureDir(hooksPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.WriteAtomic(t.Context(), hooksPath, hooks); err != nil {
+		t.Fatal(err)
+	}
+
+	// A run that writes an issue but never finishes, like a command failing
+	// after the write, keeps its events to itself
+	ctx := withRunState(storage.WithEnv(t.Context(), storage.Env{WriteFilter: issueWriteFilter}), &runState{})
+	issuePath, _ := storage.IssuePath(t.Context(), projectKey, issueID)
+	var issue models.Issue
+	if err := storage.ReadJSON(ctx, issuePath, &issue); err != nil {
+		t.Fatal(err)
+	}
+	issue.Status = "DOING"
+	data, _ := json.MarshalIndent(&issue, "", "  ")
+	if err := storage.WriteAtomic(ctx, issuePath, data); err != nil {
+		t.Fatal(err)
+	}
+
+	run("issue", "update", issueID, "--title", "Login")
+	logged, _ := os.ReadFile(logPath)
+	if want := models.EventIssueUpdated + " " + issueID + "\n"; string(logged) != want {
+		t.Errorf("Expected only the events of the finished command:\n%s\ngot:\n%s", want, logged)
+	}
+}
+
+func TestHooks_ProjectDataIgnored(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		errOut := new(bytes.Buffer)
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(errOut)
+		err := cmd.Execute()
+		return errOut.String(), err
+	}
+	if _, err := run("project", "create", projectKey); err != nil {
+		t.Fatalf("project create failed: %v", err)
+	}
+
+	// A hooks.json that came with the project data, e.g. through sync, never runs
+	marker := filepath.Join(t.TempDir(), "ran")
+	legacyPath, _ := storage.LegacyHooksPath(t.Context(), projectKey)
+	data, _ := json.Marshal(models.HookConfig{Hooks: []models.Hook{{Events: []string{"*"}, Command: "touch '" + marker + "'"}}})
+	if err := os.WriteFile(legacyPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	errOut, err := run("issue", "create", "--project", projectKey, "--title", "Exports")
+	if err != nil || !strings.Contains(errOut, "Warning: ignoring "+legacyPath) {
+		t.Errorf("Expected a warning that hooks.json is ignored, got %q (%v)", errOut, err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("Expected the hook from the project data not to run")
+	}
+}
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// NewProjectIDModeCmd creates and returns the project id-mode command.
+func NewProjectIDModeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "id-mode <key> [sequence|ulid]",
+		Short: "View or change how a project identifies issues",
+		Long: `View or change the ID mode of a project.
+
+In the default sequence mode issues are identified by their project-prefixed
+sequence (CORE-12) only. In ulid mode each issue also gets a ULID, stored as
+"uid", that stays the same across devices, moves, and renames. Tools that sync
+or merge projects use it to tell issues apart; CORE-12 is still shown everywhere.
+
+Switching to ulid mode gives existing issues a uid. Switching back keeps the
+uids already assigned but stops assigning new ones.`,
+		Example: `  buyruk project id-mode CORE
+  buyruk project id-mode CORE ulid`,
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: completeProjectArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				return showIDMode(args[0], cmd)
+			}
+			return setIDMode(args[0], args[1], cmd)
+		},
+	}
+
+	return cmd
+}
+
+// showIDMode prints the ID mode of a project.
+func showIDMode(projectKey string, cmd *cobra.Command) error {
+	index, err := loadQueryIndex(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	mode := index.IDMode
+	if mode == "" {
+		mode = models.IDModeSequence
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), mode)
+	return nil
+}
+
+// setIDMode changes the ID mode of a project, assigning a uid to every issue
+// that has none when switching to ulid mode. All files change or none do.
+func setIDMode(projectKey, mode string, cmd *cobra.Command) error {
+	if !models.IsValidIDMode(mode) {
+		return invalidf("cli: invalid ID mode %q (allowed: %s)", mode, strings.Join(models.ValidIDModes, ", "))
+	}
+	if _, err := loadQueryIndex(cmd.Context(), projectKey); err != nil {
+		return err
+	}
+
+	cleanup, err := storage.AcquireLock(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to acquire lock: %w", err)
+	}
+	defer cleanup()
+
+	indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(cmd.Context(), indexPath, &index); err != nil {
+		return fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+	index.IDMode = mode
+	if mode == models.IDModeSequence {
+		index.IDMode = ""
+	}
+
+	var writes []fileWrite
+	for i, entry := range index.Issues {
+		if !index.UsesULID() || entry.UID != "" {
+			continue
+		}
+		issuePath, err := storage.IssuePath(cmd.Context(), projectKey, entry.ID)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+		}
+		original, err := os.ReadFile(issuePath)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return fmt.Errorf("cli: failed to read issue %s: %w", entry.ID, err)
+		}
+		var issue models.Issue
+		if err := json.Unmarshal(original, &issue); err != nil {
+			return fmt.Errorf("cli: failed to parse issue %s: %w", entry.ID, err)
+		}
+		if issue.UID == "" {
+			if err := index.AssignUID(&issue, storage.Now(cmd.Context())); err != nil {
+				return err
+			}
+			data, err := json.MarshalIndent(&issue, "", "  ")
+			if err != nil {
+				return fmt.Errorf("cli: failed to marshal issue %s: %w", entry.ID, err)
+			}
+			writes = append(writes, fileWrite{path: issuePath, data: data, original: original})
+		}
+		index.Issues[i].UID = issue.UID
+	}
+	index.UpdatedAt = storage.Timestamp(cmd.Context())
+	indexWrite, err := plannedIndexWrite(indexPath, &index)
+	if err != nil {
+		return err
+	}
+	writes = append(writes, indexWrite)
+
+	if err := storage.BeginTransaction(cmd.Context(), projectKey, "id_mode", map[string]interface{}{
+		"mode": mode,
+	}); err != nil {
+		return fmt.Errorf("cli: failed to begin transaction: %w", err)
+	}
+	if err := writeFiles(cmd.Context(), writes); err != nil {
+		storage.RollbackTransaction(cmd.Context(), projectKey)
+		return err
+	}
+	if err := storage.CommitTransaction(cmd.Context(), projectKey); err != nil {
+		return fmt.Errorf("cli: failed to commit transaction: %w", err)
+	}
+
+	out := successOut(cmd)
+	fmt.Fprintf(out, "Set ID mode of project %q to %s\n", projectKey, mode)
+	if assigned := len(writes) - 1; assigned > 0 {
+		fmt.Fprintf(out, "Assigned uids to %d issues\n", assigned)
+	}
+	return nil
+}
+
+// assignIssueUID gives a new issue a uid if its project is in ulid mode.
+func assignIssueUID(ctx context.Context, projectKey string, issue *models.Issue) error {
+	indexPath, err := storage.ProjectIndexPath(ctx, projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(ctx, indexPath, &index); err != nil {
+		return fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+	return index.AssignUID(issue, storage.Now(ctx))
+}
+
+// projectIDScheme returns the ID scheme of a project, empty for sequence IDs.
+func projectIDScheme(ctx context.Context, projectKey string) (string, error) {
+	indexPath, err := storage.ProjectIndexPath(ctx, projectKey)
+	if err != nil {
+		return "", fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(ctx, indexPath, &index); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+	return index.IDScheme, nil
+}
@@ -0,0 +1,272 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestProjectIDMode(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	otherKey := projectKey + "-SEQ"
+	defer func() {
+		for _, key := range []string{projectKey, otherKey} {
+			projectDir, _ := storage.ProjectDir(t.Context(), key)
+			os.RemoveAll(projectDir)
+		}
+	}()
+
+	run := func(input string, args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		cmd.SetIn(strings.NewReader(input))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	loadIssue := func(id string) models.Issue {
+		t.Helper()
+		key, _, _ := models.ParseIssueID(id)
+		issuePath, _ := storage.IssuePath(t.Context(), key, id)
+		var issue models.Issue
+		if err := storage.ReadJSON(t.Context(), issuePath, &issue); err != nil {
+			t.Fatalf("Failed to load %s: %v", id, err)
+		}
+		return issue
+	}
+
+	// --id-mode of project create is deprecated, but still checked
+	if out, err := run("", "project", "create", projectKey, "--id-mode", "uuid"); err == nil || !strings.Contains(out, "--id-mode has been deprecated") {
+		t.Fatalf("Expected invalid ID mode to fail with a deprecation notice, got %v: %s", err, out)
+	}
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"project", "id-mode", projectKey, "ulid"},
+		{"project", "create", otherKey},
+		{"issue", "create", "--project", projectKey, "--title", "Synced"},
+		{"issue", "create", "--project", otherKey, "--title", "Plain"},
+	} {
+		if _, err := run("", args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+	if _, err := run(`{"op":"create","title":"Batched"}`, "apply", "--stdin", "--project", projectKey); err != nil {
+		t.Fatalf("apply failed: %v", err)
+	}
+
+	synced := loadIssue(projectKey + "-1")
+	if !models.IsValidULID(synced.UID) {
+		t.Fatalf("Expected a uid in ulid mode, got %q", synced.UID)
+	}
+	if batched := loadIssue(projectKey + "-2"); !models.IsValidULID(batched.UID) || batched.UID == synced.UID {
+		t.Errorf("Expected a distinct uid for applied issues, got %q", batched.UID)
+	}
+	if plain := loadIssue(otherKey + "-1"); plain.UID != "" {
+		t.Errorf("Expected no uid in sequence mode, got %q", plain.UID)
+	}
+
+	// Display IDs stay sequential, and the index carries the uid
+	index, err := loadQueryIndex(t.Context(), projectKey)
+	if err != nil {
+		t.Fatalf("Failed to load index: %v", err)
+	}
+	if entry := index.FindIssue(projectKey + "-1"); entry == nil || entry.UID != synced.UID {
+		t.Errorf("Expected index entry with uid %q, got %+v", synced.UID, entry)
+	}
+	out, err := run("", "issue", "view", projectKey+"-1", "--format", "lson")
+	if err != nil {
+		t.Fatalf("view failed: %v", err)
+	}
+	if !strings.Contains(out, "@ID: "+projectKey+"-1") || !strings.Contains(out, "@UID: "+synced.UID) {
+		t.Errorf("Expected ID and uid in view, got: %s", out)
+	}
+
+	// A moved issue keeps its identity under its new ID
+	if _, err := run("", "issue", "move", projectKey+"-1", otherKey); err != nil {
+		t.Fatalf("move failed: %v", err)
+	}
+	if moved := loadIssue(otherKey + "-2"); moved.UID != synced.UID {
+		t.Errorf("Expected moved issue to keep uid %q, got %q", synced.UID, moved.UID)
+	}
+
+	// Switching a project to ulid mode backfills uids
+	out, err = run("", "project", "id-mode", otherKey, "ulid")
+	if err != nil {
+		t.Fatalf("id-mode failed: %v", err)
+	}
+	if !strings.Contains(out, "Assigned uids to 1 issues") {
+		t.Errorf("Expected one backfilled issue, got: %s", out)
+	}
+	if plain := loadIssue(otherKey + "-1"); !models.IsValidULID(plain.UID) {
+		t.Errorf("Expected backfilled uid, got %q", plain.UID)
+	}
+	if out, _ := run("", "project", "id-mode", otherKey); strings.TrimSpace(out) != "ulid" {
+		t.Errorf("Expected id-mode to show ulid, got %q", out)
+	}
+	if _, err := run("", "project", "id-mode", otherKey, "uuid"); err == nil {
+		t.Error("Expected invalid ID mode to fail")
+	}
+}
+
+func TestProjectIDScheme(t *testing.T) {
+	paddedKey := sanitizeTestName("TEST" + t.Name())
+	dateKey, ulidKey := paddedKey+"-D", paddedKey+"-U"
+	defer func() {
+		for _, key := range []string{paddedKey, dateKey, ulidKey} {
+			projectDir, _ := storage.ProjectDir(t.Context(), key)
+			os.RemoveAll(projectDir)
+		}
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	if _, err := run("project", "create", paddedKey, "--id-scheme", "roman"); err == nil {
+		t.Fatal("Expected invalid ID scheme to fail")
+	}
+	for _, args := range [][]string{
+		{"project", "create", paddedKey, "--id-scheme", "padded"},
+		{"project", "create", dateKey, "--id-scheme", "date"},
+		{"project", "create", ulidKey, "--id-scheme", "ulid"},
+		{"issue", "create", "--project", paddedKey, "--title", "Padded"},
+		{"issue", "create", "--project", paddedKey, "--title", "Padded again"},
+		{"issue", "create", "--project", dateKey, "--title", "Dated", "--fixed-time", "2024-06-01T09:00:00Z"},
+		{"issue", "create", "--project", ulidKey, "--title", "Unique"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	list := func(projectKey string) []string {
+		t.Helper()
+		index, err := loadQueryIndex(t.Context(), projectKey)
+		if err != nil {
+			t.Fatalf("Failed to load index of %s: %v", projectKey, err)
+		}
+		var ids []string
+		for _, entry := range index.Issues {
+			ids = append(ids, entry.ID)
+		}
+		return ids
+	}
+	if ids := list(paddedKey); len(ids) != 2 || !slices.Contains(ids, paddedKey+"-0001") || !slices.Contains(ids, paddedKey+"-0002") {
+		t.Errorf("Expected padded IDs, got %v", ids)
+	}
+	if ids := list(dateKey); len(ids) != 1 || ids[0] != dateKey+"-2024-001" {
+		t.Errorf("Expected a date-based ID, got %v", ids)
+	}
+	ids := list(ulidKey)
+	if len(ids) != 1 || !models.IsValidULID(strings.TrimPrefix(ids[0], ulidKey+"-")) {
+		t.Fatalf("Expected a ULID suffix, got %v", ids)
+	}
+
+	// Every scheme's IDs work with issue commands, and bare numbers resolve
+	for _, id := range []string{paddedKey + "-0002", dateKey + "-2024-001", ids[0]} {
+		if out, err := run("issue", "view", id, "--format", "lson"); err != nil || !strings.Contains(out, id) {
+			t.Errorf("issue view %s failed: %v\n%s", id, err, out)
+		}
+	}
+	if out, err := run("issue", "view", "2", "--project", paddedKey, "--format", "lson"); err != nil || !strings.Contains(out, paddedKey+"-0002") {
+		t.Errorf("Expected 2 to resolve to %s-0002, got %v\n%s", paddedKey, err, out)
+	}
+}
+
+func TestProjectIDScheme_KeyEndingInYear(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	yearKey := projectKey + "-2024"
+	defer func() {
+		for _, key := range []string{projectKey, yearKey} {
+			projectDir, _ := storage.ProjectDir(t.Context(), key)
+			os.RemoveAll(projectDir)
+		}
+	}()
+
+	ctx := storage.WithEnv(t.Context(), storage.Env{Sequence: storage.CounterSequence(100)})
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.ExecuteContext(ctx)
+		return out.String(), err
+	}
+
+	if _, err := run("project", "create", yearKey); err != nil {
+		t.Fatalf("project create failed: %v", err)
+	}
+	if _, err := run("issue", "create", "--project", yearKey, "--title", "Release notes"); err != nil {
+		t.Fatalf("issue create failed: %v", err)
+	}
+	issueID := yearKey + "-100"
+
+	// The year is part of the key while there is no date-based project to
+	// claim the ID, and also when the project without the year isn't one
+	if out, err := run("issue", "view", issueID, "--format", "lson"); err != nil || !strings.Contains(out, issueID) {
+		t.Errorf("issue view %s failed: %v\n%s", issueID, err, out)
+	}
+	if _, err := run("project", "create", projectKey); err != nil {
+		t.Fatalf("project create failed: %v", err)
+	}
+	if out, err := run("issue", "update", issueID, "--status", "DOING", "--format", "json"); err != nil || !strings.Contains(out, "DOING") {
+		t.Errorf("issue update %s failed: %v\n%s", issueID, err, out)
+	}
+}
+
+func TestProjectIDScheme_KeyEndingInYearNextToDateProject(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	yearKey, otherYearKey := projectKey+"-2024", projectKey+"-2025"
+	defer func() {
+		for _, key := range []string{projectKey, yearKey, otherYearKey} {
+			projectDir, _ := storage.ProjectDir(t.Context(), key)
+			os.RemoveAll(projectDir)
+		}
+	}()
+
+	run := func(args ...string) error {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+		return cmd.Execute()
+	}
+	mustRun := func(args ...string) {
+		t.Helper()
+		if err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	// A key ending in a year is fine until a project without the year uses
+	// date-based IDs
+	mustRun("project", "create", yearKey)
+	if err := run("project", "create", projectKey, "--id-scheme", "date"); ExitCode(err) != ExitInvalid || !strings.Contains(err.Error(), "would read as its own") {
+		t.Fatalf("Expected a date-based project next to %s to fail, got %v", yearKey, err)
+	}
+	mustRun("project", "create", projectKey)
+	if err := run("project", "rename", yearKey, otherYearKey); err != nil {
+		t.Fatalf("Expected renaming %s next to a sequence project to work, got %v", yearKey, err)
+	}
+	mustRun("project", "delete", otherYearKey, "-y")
+	mustRun("project", "delete", projectKey, "-y")
+
+	mustRun("project", "create", projectKey, "--id-scheme", "date")
+	if err := run("project", "create", yearKey); ExitCode(err) != ExitInvalid || !strings.Contains(err.Error(), "date-based IDs of project "+projectKey) {
+		t.Errorf("Expected %s next to a date-based project to fail, got %v", yearKey, err)
+	}
+}
@@ -1,22 +1,82 @@
 package cli
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/buyruk-project/buyruk-cli/internal/models"
 	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 // NewImportCmd creates and returns the import command.
 func NewImportCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "import <file>",
+		Use:   "import <file|->",
 		Short: "Import a project",
-		Long:  "Import a project from an export file",
-		Args:  cobra.ExactArgs(1),
+		Long: `Import a project from an export file (JSON, YAML for .yaml/.yml files,
+NDJSON for .ndjson/.jsonl files, or an archive with attachments for
+.tar.gz/.tgz files). With - as the file, the export is read from standard
+input and its encoding is recognized from the content, so a project can be
+piped from another machine:
+
+  ssh build-host buyruk export CORE --output - | buyruk import - --merge
+
+With --merge, the export is merged into an existing project instead: issues
+and epics are added or updated by ID, local issues missing from the export
+are kept, and the workflow and project settings stay as they are. When an
+issue differs, the copy updated last wins; --prefer local or --prefer file
+always keeps one side. Issues whose dropped copy is not older than the kept
+one are reported as conflicts. A project that does not exist yet is imported as usual.
+
+With --review the project must already exist: instead of replacing it, the
+issues and epics that differ from the local copy are staged for sync review,
+where each change can be accepted or rejected.
+
+With --from markdown, the checklist and bullet items of a Markdown file (for
+example meeting notes) become new issues in an existing project, after a
+preview and confirmation:
+
+  - [ ] Draft the RFC !! @alice #docs    TODO, HIGH, assigned to alice, labeled docs
+  - [x] Book the room                     DONE
+  - [~] Migrate the database              in progress (the first non-default, non-done status)
+  - 🚧 Update the runbook                  status from emoji (✅ done, 🚧 🔄 ⏳ in progress)
+
+"!" marks MEDIUM, "!!" HIGH, and "!!!" CRITICAL (the top three workflow
+priorities), indented text becomes the description, and nested items become
+subtasks.
+
+With --from jira, the issues of a Jira export (CSV, the XML export, or JSON
+from the REST API) are imported into an existing project. Columns are mapped
+to fields by name and Jira statuses, types, and priorities to the closest
+workflow values; --field-map and --status-map change the mapping. Epics
+become buyruk epics, issues keep their Epic Link, sub-tasks their parent,
+and each issue gets a comment naming its Jira key. The mapping and the
+records that cannot be imported are shown before confirmation.
+
+--from trello and --from linear work the same way. A Trello board export
+(JSON) gives an issue per card, with the card's list as its status, its
+labels (or label colors), due date, first member, and description; checklists
+are appended to the description as task lists, and archived cards and lists
+are skipped. A Linear export (CSV, or JSON from the GraphQL API) keeps each
+issue's status, priority, labels, assignee, and parent, and each Linear
+project becomes an epic; archived issues are skipped.`,
+		Example: `  buyruk import export.json
+  buyruk export CORE --output - | ssh laptop buyruk import - --overwrite
+  buyruk import export.json --merge --dry-run
+  buyruk import notes.md --from markdown --project CORE
+  buyruk import notes.md --from markdown --project CORE --status-map "👀=REVIEW"
+  buyruk import jira.csv --from jira --project CORE --status-map "In QA=REVIEW" --field-map "assignee=Reporter"
+  buyruk import board.json --from trello --project CORE --status-map "Ideas=TODO" --dry-run
+  buyruk import linear.csv --from linear --project CORE --yes`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			filePath := args[0]
 			return importProject(filePath, cmd)
@@ -24,45 +84,210 @@ func NewImportCmd() *cobra.Command {
 	}
 
 	cmd.Flags().Bool("overwrite", false, "Overwrite existing project if it exists")
+	cmd.Flags().Bool("review", false, "Stage changes to an existing project for sync review instead of importing")
+	cmd.Flags().Bool("merge", false, "Merge into an existing project: add and update issues and epics by ID, never delete")
+	cmd.Flags().String("prefer", mergePreferNewer, "Copy --merge keeps when an issue differs: newer (by updated_at), local, or file")
+	cmd.MarkFlagsMutuallyExclusive("overwrite", "review", "merge")
+	cmd.Flags().String("from", importFromBuyruk, "Source format: "+importSourceList())
+	cmd.Flags().String("status-map", "", "Extra emoji=STATUS pairs for --from markdown, e.g. \"👀=REVIEW\", or status=STATUS pairs (tracker status or Trello list) for other sources")
+	cmd.Flags().String("field-map", "", "field=Column pairs overriding the guessed column of a field for tracker sources, e.g. \"epic=Custom field (Epic)\"")
+	cmd.Flags().Bool("dry-run", false, "Show what --merge or --from a source other than buyruk would change without changing it")
+	cmd.Flags().BoolP("yes", "y", false, "Create the issues --from a source other than buyruk without asking for confirmation")
 
 	return cmd
 }
 
+// importSource is a format import --from reads.
+type importSource struct {
+	Name        string                                          // --from value
+	Description string                                          // Shown in the --from help
+	Import      func(filePath string, cmd *cobra.Command) error // Nil for buyruk exports, which importProject reads
+}
+
+// importSources are the formats import --from reads, in the order they are
+// listed. Adding a source here is all it takes to support it.
+var importSources = []importSource{
+	{Name: importFromBuyruk, Description: "an export file"},
+	{Name: importFromMarkdown, Description: "checklist notes", Import: importMarkdown},
+	{Name: jiraSource.From, Description: "a Jira export", Import: jiraSource.importFile},
+	{Name: trelloSource.From, Description: "a Trello board export", Import: trelloSource.importFile},
+	{Name: linearSource.From, Description: "a Linear export", Import: linearSource.importFile},
+}
+
+// importSourceList describes the import sources for help and error messages.
+func importSourceList() string {
+	items := make([]string, len(importSources))
+	for i, source := range importSources {
+		items[i] = fmt.Sprintf("%s (%s)", source.Name, source.Description)
+	}
+	return strings.Join(items, ", ")
+}
+
 // importProject imports a project from an export file.
 func importProject(filePath string, cmd *cobra.Command) error {
-	// Read export file
-	data, err := os.ReadFile(filePath)
-	if err != nil {
+	from, _ := cmd.Flags().GetString("from")
+	i := slices.IndexFunc(importSources, func(source importSource) bool { return source.Name == from })
+	if i < 0 {
+		names := make([]string, len(importSources))
+		for i, source := range importSources {
+			names[i] = source.Name
+		}
+		return invalidf("cli: invalid --from value %q (allowed: %s)", from, strings.Join(names, ", "))
+	}
+	if importSources[i].Import != nil {
+		if filePath == stdinPath {
+			return invalidf("cli: only buyruk exports can be read from standard input (save the %s export to a file)", from)
+		}
+		if merge, _ := cmd.Flags().GetBool("merge"); merge {
+			return fmt.Errorf("cli: --merge is not supported with --from %s", from)
+		}
+		return importSources[i].Import(filePath, cmd)
+	}
+
+	// Read export file, or standard input for "-"
+	var data []byte
+	var err error
+	if filePath == stdinPath {
+		if data, err = io.ReadAll(cmd.InOrStdin()); err != nil {
+			return fmt.Errorf("cli: failed to read export from standard input: %w", err)
+		}
+		filePath = stdinExportName(data)
+	} else if data, err = os.ReadFile(filePath); err != nil {
 		return fmt.Errorf("cli: failed to read export file: %w", err)
 	}
 
+	var attachments map[string][]byte
+	if isArchivePath(filePath) {
+		if data, attachments, err = readExportArchive(bytes.NewReader(data)); err != nil {
+			return invalidf("cli: invalid export archive: %w", err)
+		}
+	}
+
 	var exportData ExportData
-	if err := json.Unmarshal(data, &exportData); err != nil {
+	if isYAMLPath(filePath) {
+		if err := ui.UnmarshalYAML(data, &exportData); err != nil {
+			return fmt.Errorf("cli: failed to parse export file: %w", err)
+		}
+	} else if isNDJSONPath(filePath) {
+		records, err := readExportNDJSON(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("cli: failed to parse export file: %w", err)
+		}
+		exportData = *records
+	} else if err := json.Unmarshal(data, &exportData); err != nil {
 		return fmt.Errorf("cli: failed to parse export file: %w", err)
 	}
 
-	// Validate export data
+	// Convert exports of older versions, then validate
+	if err := upgradeExportData(&exportData); err != nil {
+		return invalidf("cli: invalid export file: %w", err)
+	}
 	if err := validateExportData(&exportData); err != nil {
-		return fmt.Errorf("cli: invalid export file: %w", err)
+		return invalidf("cli: invalid export file: %w", err)
 	}
 
 	projectKey := exportData.Project.ProjectKey
 
 	// Check if project already exists
-	projectDir, err := storage.ProjectDir(projectKey)
+	projectDir, err := storage.ProjectDir(cmd.Context(), projectKey)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve project directory: %w", err)
 	}
 
+	if review, _ := cmd.Flags().GetBool("review"); review {
+		if _, err := os.Stat(projectDir); err != nil {
+			return notFoundf("cli: project %q does not exist (import without --review to create it)", projectKey)
+		}
+		staged, err := stageIncoming(projectKey, &exportData, nil, cmd)
+		if err != nil {
+			return err
+		}
+		out := successOut(cmd)
+		if staged == 0 {
+			fmt.Fprintf(out, "No changes to review: project %q matches the export\n", projectKey)
+			return nil
+		}
+		fmt.Fprintf(out, "Staged %d changes for review (run 'buyruk sync review --project %s')\n", staged, projectKey)
+		return nil
+	}
+
+	if merge, _ := cmd.Flags().GetBool("merge"); merge {
+		if _, err := os.Stat(projectDir); err == nil {
+			if err := mergeExportData(&exportData, cmd); err != nil {
+				return err
+			}
+			return importAttachments(projectKey, attachments, cmd)
+		}
+	}
+
 	overwrite, _ := cmd.Flags().GetBool("overwrite")
+	if err := importExportData(&exportData, overwrite, cmd); err != nil {
+		return err
+	}
+	return importAttachments(projectKey, attachments, cmd)
+}
+
+// stdinPath is the file argument of import that reads standard input.
+const stdinPath = "-"
+
+// stdinExportName names an export read from standard input after the
+// encoding of its content, as there is no file extension to go by.
+func stdinExportName(data []byte) string {
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		return "stdin.tar.gz"
+	}
+	trimmed := bytes.TrimSpace(data)
+	if !bytes.HasPrefix(trimmed, []byte("{")) {
+		return "stdin.yaml"
+	}
+	// NDJSON starts with a one-line export header record
+	firstLine, _, _ := bytes.Cut(trimmed, []byte("\n"))
+	var record exportRecord
+	if json.Unmarshal(firstLine, &record) == nil && record.Export != nil {
+		return "stdin.ndjson"
+	}
+	return "stdin.json"
+}
+
+// importAttachments writes the attached files of an export archive into the
+// imported project.
+func importAttachments(projectKey string, attachments map[string][]byte, cmd *cobra.Command) error {
+	if len(attachments) == 0 {
+		return nil
+	}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		return nil
+	}
+	written := writeProjectAttachments(cmd.Context(), projectKey, attachments, cmd.ErrOrStderr())
+	fmt.Fprintf(successOut(cmd), "Restored %d attachment(s)\n", written)
+	return nil
+}
+
+// importExportData writes validated export data as a local project. With
+// overwrite, the project data of an existing project (index, issues, epics,
+// and workflow) is replaced; local-only state such as its retention policy,
+// archive, and staged changes is kept.
+func importExportData(exportData *ExportData, overwrite bool, cmd *cobra.Command) error {
+	projectKey := exportData.Project.ProjectKey
+	projectDir, err := storage.ProjectDir(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve project directory: %w", err)
+	}
+
 	if _, err := os.Stat(projectDir); err == nil {
 		if !overwrite {
-			return fmt.Errorf("cli: project %q already exists (use --overwrite to replace)", projectKey)
+			return conflictf("cli: project %q already exists (use --overwrite to replace or --merge to merge)", projectKey)
+		}
+		if exportData.Partial {
+			return conflictf("cli: the export holds part of project %q and would replace all of it (use --merge)", projectKey)
 		}
 
-		// Remove existing project
-		if err := os.RemoveAll(projectDir); err != nil {
-			return fmt.Errorf("cli: failed to remove existing project: %w", err)
+		// Remove the existing project data
+		if err := checkProjectWritable(cmd.Context(), projectKey); err != nil {
+			return err
+		}
+		if err := removeProjectData(cmd.Context(), projectKey); err != nil {
+			return err
 		}
 	}
 
@@ -71,7 +296,7 @@ func importProject(filePath string, cmd *cobra.Command) error {
 		return fmt.Errorf("cli: failed to create project directory: %w", err)
 	}
 
-	issuesDir, err := storage.IssuesDir(projectKey)
+	issuesDir, err := storage.IssuesDir(cmd.Context(), projectKey)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve issues directory: %w", err)
 	}
@@ -80,7 +305,7 @@ func importProject(filePath string, cmd *cobra.Command) error {
 		return fmt.Errorf("cli: failed to create issues directory: %w", err)
 	}
 
-	epicsDir, err := storage.EpicsDir(projectKey)
+	epicsDir, err := storage.EpicsDir(cmd.Context(), projectKey)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve epics directory: %w", err)
 	}
@@ -89,27 +314,48 @@ func importProject(filePath string, cmd *cobra.Command) error {
 		return fmt.Errorf("cli: failed to create epics directory: %w", err)
 	}
 
+	// Write custom workflow before validating issues against it
+	if exportData.Workflow != nil {
+		workflowPath, err := storage.WorkflowPath(cmd.Context(), projectKey)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve workflow path: %w", err)
+		}
+		if err := storage.WriteJSONAtomic(cmd.Context(), workflowPath, exportData.Workflow); err != nil {
+			return fmt.Errorf("cli: failed to write workflow: %w", err)
+		}
+	}
+
+	if len(exportData.Views) > 0 {
+		viewsPath, err := storage.ViewsPath(cmd.Context(), projectKey)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve views path: %w", err)
+		}
+		if err := storage.WriteJSONAtomic(cmd.Context(), viewsPath, &models.SavedViews{Views: exportData.Views}); err != nil {
+			return fmt.Errorf("cli: failed to write views: %w", err)
+		}
+	}
+
 	// Track successfully imported items to build index
 	var importedIssues []models.IndexEntry
-	var importedEpicsCount int
+	var importedEpics []*models.Epic
 
 	// Write all issues
 	for _, issue := range exportData.Issues {
 		// Validate issue
-		if err := issue.Validate(); err != nil {
+		if err := issue.ValidateWithWorkflow(exportData.Workflow); err != nil {
 			errOut := cmd.ErrOrStderr()
 			fmt.Fprintf(errOut, "Warning: skipping invalid issue %s: %v\n", issue.ID, err)
 			continue
 		}
 
-		issuePath, err := storage.IssuePath(projectKey, issue.ID)
+		issuePath, err := storage.IssuePath(cmd.Context(), projectKey, issue.ID)
 		if err != nil {
 			errOut := cmd.ErrOrStderr()
 			fmt.Fprintf(errOut, "Warning: failed to resolve path for issue %s: %v\n", issue.ID, err)
 			continue
 		}
 
-		if err := storage.WriteJSONAtomic(issuePath, issue); err != nil {
+		if err := storage.WriteJSONAtomic(cmd.Context(), issuePath, issue); err != nil {
 			errOut := cmd.ErrOrStderr()
 			fmt.Fprintf(errOut, "Warning: failed to write issue %s: %v\n", issue.ID, err)
 			continue
@@ -117,62 +363,103 @@ func importProject(filePath string, cmd *cobra.Command) error {
 
 		// Track successfully imported issue
 		importedIssues = append(importedIssues, models.IndexEntry{
-			ID:     issue.ID,
-			Title:  issue.Title,
-			Status: issue.Status,
-			Type:   issue.Type,
-			EpicID: issue.EpicID,
+			ID:           issue.ID,
+			UID:          issue.UID,
+			Title:        issue.Title,
+			Status:       issue.Status,
+			Type:         issue.Type,
+			Priority:     issue.Priority,
+			EpicID:       issue.EpicID,
+			FixVersion:   issue.FixVersion,
+			ParentID:     issue.ParentID,
+			Labels:       issue.Labels,
+			Components:   issue.Components,
+			Assignee:     issue.Assignee,
+			Rank:         issue.Rank,
+			Points:       issue.Points,
+			Estimate:     issue.Estimate,
+			SnoozedUntil: issue.SnoozedUntil,
 		})
 	}
 
 	// Write all epics
 	for _, epic := range exportData.Epics {
 		// Validate epic
-		if err := epic.Validate(); err != nil {
+		if err := epic.ValidateWithWorkflow(exportData.Workflow); err != nil {
 			errOut := cmd.ErrOrStderr()
 			fmt.Fprintf(errOut, "Warning: skipping invalid epic %s: %v\n", epic.ID, err)
 			continue
 		}
 
-		epicPath, err := storage.EpicPath(projectKey, epic.ID)
+		epicPath, err := storage.EpicPath(cmd.Context(), projectKey, epic.ID)
 		if err != nil {
 			errOut := cmd.ErrOrStderr()
 			fmt.Fprintf(errOut, "Warning: failed to resolve path for epic %s: %v\n", epic.ID, err)
 			continue
 		}
 
-		if err := storage.WriteJSONAtomic(epicPath, epic); err != nil {
+		if err := storage.WriteJSONAtomic(cmd.Context(), epicPath, epic); err != nil {
 			errOut := cmd.ErrOrStderr()
 			fmt.Fprintf(errOut, "Warning: failed to write epic %s: %v\n", epic.ID, err)
 			continue
 		}
 
 		// Track successfully imported epic
-		importedEpicsCount++
+		importedEpics = append(importedEpics, epic)
 	}
 
 	// Build and write project index from successfully imported items
-	indexPath, err := storage.ProjectIndexPath(projectKey)
+	indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve index path: %w", err)
 	}
 
 	index := &models.ProjectIndex{
-		ProjectKey:  exportData.Project.ProjectKey,
-		ProjectName: exportData.Project.ProjectName,
-		Issues:      importedIssues,
-		CreatedAt:   exportData.Project.CreatedAt,
-		UpdatedAt:   exportData.Project.UpdatedAt,
+		ProjectKey:        exportData.Project.ProjectKey,
+		ProjectName:       exportData.Project.ProjectName,
+		IDMode:            exportData.Project.IDMode,
+		IDScheme:          exportData.Project.IDScheme,
+		MaxAttachmentSize: exportData.Project.MaxAttachmentSize,
+		Issues:            importedIssues,
+		CreatedAt:         exportData.Project.CreatedAt,
+		UpdatedAt:         exportData.Project.UpdatedAt,
+	}
+	for _, epic := range importedEpics {
+		index.SetEpic(epic)
 	}
 
-	if err := storage.WriteJSONAtomic(indexPath, index); err != nil {
+	if err := storage.WriteJSONAtomic(cmd.Context(), indexPath, index); err != nil {
 		return fmt.Errorf("cli: failed to write project index: %w", err)
 	}
 
 	// Success message with counts of successfully imported items
-	out := cmd.OutOrStdout()
+	out := successOut(cmd)
 	fmt.Fprintf(out, "Imported project %q (%d issues, %d epics)\n",
-		projectKey, len(importedIssues), importedEpicsCount)
+		projectKey, len(importedIssues), len(importedEpics))
 
+	// Warn when the imported project is past the configured soft limits
+	warnQuota(projectKey, cmd)
+
+	return nil
+}
+
+// removeProjectData removes the files of a project that an import replaces.
+func removeProjectData(ctx context.Context, projectKey string) error {
+	var paths []string
+	for _, resolve := range []func(context.Context, string) (string, error){
+		storage.ProjectIndexPath, storage.IndexShardsDir, storage.IssuesDir, storage.EpicsDir,
+		storage.WorkflowPath, storage.ViewsPath,
+	} {
+		path, err := resolve(ctx, projectKey)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve project path: %w", err)
+		}
+		paths = append(paths, path)
+	}
+	for _, path := range paths {
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("cli: failed to remove existing project: %w", err)
+		}
+	}
 	return nil
 }
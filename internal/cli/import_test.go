@@ -29,7 +29,7 @@ func TestImportProject_ValidExport(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -78,13 +78,13 @@ func TestImportProject_ValidExport(t *testing.T) {
 	}
 
 	// Verify project was created
-	indexPath, err := storage.ProjectIndexPath(projectKey)
+	indexPath, err := storage.ProjectIndexPath(t.Context(), projectKey)
 	if err != nil {
 		t.Fatalf("Failed to resolve index path: %v", err)
 	}
 
 	var index models.ProjectIndex
-	if err := storage.ReadJSON(indexPath, &index); err != nil {
+	if err := storage.ReadIndex(t.Context(), indexPath, &index); err != nil {
 		t.Fatalf("Failed to read project index: %v", err)
 	}
 
@@ -98,7 +98,7 @@ func TestImportProject_WithIssues(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -153,13 +153,13 @@ func TestImportProject_WithIssues(t *testing.T) {
 	}
 
 	// Verify issues were imported
-	issue1Path, err := storage.IssuePath(projectKey, projectKey+"-1")
+	issue1Path, err := storage.IssuePath(t.Context(), projectKey, projectKey+"-1")
 	if err != nil {
 		t.Fatalf("Failed to resolve issue path: %v", err)
 	}
 
 	var issue1 models.Issue
-	if err := storage.ReadJSON(issue1Path, &issue1); err != nil {
+	if err := storage.ReadJSON(t.Context(), issue1Path, &issue1); err != nil {
 		t.Fatalf("Failed to read issue 1: %v", err)
 	}
 
@@ -167,13 +167,13 @@ func TestImportProject_WithIssues(t *testing.T) {
 		t.Errorf("Issue 1 Title = %q, want 'Issue 1'", issue1.Title)
 	}
 
-	issue2Path, err := storage.IssuePath(projectKey, projectKey+"-2")
+	issue2Path, err := storage.IssuePath(t.Context(), projectKey, projectKey+"-2")
 	if err != nil {
 		t.Fatalf("Failed to resolve issue path: %v", err)
 	}
 
 	var issue2 models.Issue
-	if err := storage.ReadJSON(issue2Path, &issue2); err != nil {
+	if err := storage.ReadJSON(t.Context(), issue2Path, &issue2); err != nil {
 		t.Fatalf("Failed to read issue 2: %v", err)
 	}
 
@@ -187,7 +187,7 @@ func TestImportProject_WithEpics(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -232,14 +232,14 @@ func TestImportProject_WithEpics(t *testing.T) {
 		t.Fatalf("import command failed: %v", err)
 	}
 
-	// Verify epic was imported
-	epicPath, err := storage.EpicPath(projectKey, "E-1")
+	// Verify epic was imported, its 1.0 ID scoped to the project
+	epicPath, err := storage.EpicPath(t.Context(), projectKey, projectKey+"-E1")
 	if err != nil {
 		t.Fatalf("Failed to resolve epic path: %v", err)
 	}
 
 	var epic models.Epic
-	if err := storage.ReadJSON(epicPath, &epic); err != nil {
+	if err := storage.ReadJSON(t.Context(), epicPath, &epic); err != nil {
 		t.Fatalf("Failed to read epic: %v", err)
 	}
 
@@ -253,7 +253,7 @@ func TestImportProject_OverwriteExisting(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -300,7 +300,7 @@ func TestImportProject_OverwriteExisting(t *testing.T) {
 	}
 
 	// Verify project still exists
-	indexPath, err := storage.ProjectIndexPath(projectKey)
+	indexPath, err := storage.ProjectIndexPath(t.Context(), projectKey)
 	if err != nil {
 		t.Fatalf("Failed to resolve index path: %v", err)
 	}
@@ -315,7 +315,7 @@ func TestImportProject_ProjectExistsWithoutOverwrite(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -435,7 +435,7 @@ func TestImportProject_RoundTrip(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 		os.Remove(projectKey + ".json")
 	}()
@@ -469,7 +469,7 @@ func TestImportProject_RoundTrip(t *testing.T) {
 	}
 
 	// Remove original project
-	projectDir, err := storage.ProjectDir(projectKey)
+	projectDir, err := storage.ProjectDir(t.Context(), projectKey)
 	if err != nil {
 		t.Fatalf("Failed to resolve project directory: %v", err)
 	}
@@ -490,13 +490,13 @@ func TestImportProject_RoundTrip(t *testing.T) {
 	}
 
 	// Verify issues were restored
-	issue1Path, err := storage.IssuePath(projectKey, projectKey+"-1")
+	issue1Path, err := storage.IssuePath(t.Context(), projectKey, projectKey+"-1")
 	if err != nil {
 		t.Fatalf("Failed to resolve issue path: %v", err)
 	}
 
 	var issue1 models.Issue
-	if err := storage.ReadJSON(issue1Path, &issue1); err != nil {
+	if err := storage.ReadJSON(t.Context(), issue1Path, &issue1); err != nil {
 		t.Fatalf("Failed to read issue 1: %v", err)
 	}
 
@@ -504,13 +504,13 @@ func TestImportProject_RoundTrip(t *testing.T) {
 		t.Errorf("Issue 1 Title = %q, want 'Issue 1'", issue1.Title)
 	}
 
-	issue2Path, err := storage.IssuePath(projectKey, projectKey+"-2")
+	issue2Path, err := storage.IssuePath(t.Context(), projectKey, projectKey+"-2")
 	if err != nil {
 		t.Fatalf("Failed to resolve issue path: %v", err)
 	}
 
 	var issue2 models.Issue
-	if err := storage.ReadJSON(issue2Path, &issue2); err != nil {
+	if err := storage.ReadJSON(t.Context(), issue2Path, &issue2); err != nil {
 		t.Fatalf("Failed to read issue 2: %v", err)
 	}
 
@@ -519,12 +519,87 @@ func TestImportProject_RoundTrip(t *testing.T) {
 	}
 }
 
+func TestImportProject_YAMLRoundTrip(t *testing.T) {
+	// Use unique project key to avoid conflicts
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	exportFile := filepath.Join(t.TempDir(), projectKey+".yaml")
+	// Clean up after test
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	// Create project and issue
+	rootCmd := NewRootCmd()
+	rootCmd.SetArgs([]string{"project", "create", projectKey})
+	rootCmd.SetOut(new(bytes.Buffer))
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	rootCmd2 := NewRootCmd()
+	rootCmd2.SetArgs([]string{"issue", "create", "--project", projectKey, "--title", "YAML: issue", "--priority", "HIGH"})
+	rootCmd2.SetOut(new(bytes.Buffer))
+	if err := rootCmd2.Execute(); err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+
+	// Export project as YAML (selected by file extension)
+	rootCmd3 := NewRootCmd()
+	rootCmd3.SetArgs([]string{"export", projectKey, "--output", exportFile})
+	rootCmd3.SetOut(new(bytes.Buffer))
+	if err := rootCmd3.Execute(); err != nil {
+		t.Fatalf("Failed to export project: %v", err)
+	}
+
+	data, err := os.ReadFile(exportFile)
+	if err != nil {
+		t.Fatalf("Failed to read export file: %v", err)
+	}
+	if !strings.Contains(string(data), "project_key: "+projectKey) {
+		t.Errorf("Export file should be YAML, got: %s", string(data))
+	}
+
+	// Remove original project and import from YAML
+	projectDir, err := storage.ProjectDir(t.Context(), projectKey)
+	if err != nil {
+		t.Fatalf("Failed to resolve project directory: %v", err)
+	}
+	if err := os.RemoveAll(projectDir); err != nil {
+		t.Fatalf("Failed to remove project: %v", err)
+	}
+
+	rootCmd4 := NewRootCmd()
+	rootCmd4.SetArgs([]string{"import", exportFile})
+	rootCmd4.SetOut(new(bytes.Buffer))
+	if err := rootCmd4.Execute(); err != nil {
+		t.Fatalf("import command failed: %v", err)
+	}
+
+	issuePath, err := storage.IssuePath(t.Context(), projectKey, projectKey+"-1")
+	if err != nil {
+		t.Fatalf("Failed to resolve issue path: %v", err)
+	}
+
+	var issue models.Issue
+	if err := storage.ReadJSON(t.Context(), issuePath, &issue); err != nil {
+		t.Fatalf("Failed to read issue: %v", err)
+	}
+
+	if issue.Title != "YAML: issue" {
+		t.Errorf("Issue Title = %q, want 'YAML: issue'", issue.Title)
+	}
+	if issue.Priority != models.PriorityHIGH {
+		t.Errorf("Issue Priority = %q, want HIGH", issue.Priority)
+	}
+}
+
 func TestImportProject_InvalidIssueSkipped(t *testing.T) {
 	// Use unique project key to avoid conflicts
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -585,7 +660,7 @@ func TestImportProject_InvalidIssueSkipped(t *testing.T) {
 	}
 
 	// Verify only valid issue was imported
-	issue1Path, err := storage.IssuePath(projectKey, projectKey+"-1")
+	issue1Path, err := storage.IssuePath(t.Context(), projectKey, projectKey+"-1")
 	if err != nil {
 		t.Fatalf("Failed to resolve issue path: %v", err)
 	}
@@ -594,7 +669,7 @@ func TestImportProject_InvalidIssueSkipped(t *testing.T) {
 		t.Fatal("Valid issue was not imported")
 	}
 
-	issue2Path, err := storage.IssuePath(projectKey, projectKey+"-2")
+	issue2Path, err := storage.IssuePath(t.Context(), projectKey, projectKey+"-2")
 	if err != nil {
 		t.Fatalf("Failed to resolve issue path: %v", err)
 	}
@@ -603,3 +678,153 @@ func TestImportProject_InvalidIssueSkipped(t *testing.T) {
 		t.Error("Invalid issue should not have been imported")
 	}
 }
+
+func TestImportProject_Merge(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	id := func(n int) string { return fmt.Sprintf("%s-%d", projectKey, n) }
+
+	if _, err := run("project", "create", projectKey); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	for _, title := range []string{"Local A", "Local B"} {
+		if _, err := run("issue", "create", "--title", title, "--project", projectKey); err != nil {
+			t.Fatalf("Failed to create issue: %v", err)
+		}
+	}
+	exportFile := filepath.Join(t.TempDir(), "export.json")
+	if _, err := run("export", projectKey, "--output", exportFile); err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+
+	// The file changes issue 1 at the same time as the local copy, issue 2
+	// later, and adds issue 10; issue 3 only exists locally
+	var exportData ExportData
+	data, _ := os.ReadFile(exportFile)
+	if err := json.Unmarshal(data, &exportData); err != nil {
+		t.Fatal(err)
+	}
+	for _, issue := range exportData.Issues {
+		switch issue.ID {
+		case id(1):
+			issue.Title = "File A"
+		case id(2):
+			issue.Title = "File B"
+			issue.UpdatedAt = "2099-01-01T00:00:00Z"
+		}
+	}
+	added := *exportData.Issues[0]
+	added.ID, added.UID, added.Title = id(10), "", "File only"
+	exportData.Issues = append(exportData.Issues, &added)
+	data, _ = json.MarshalIndent(exportData, "", "  ")
+	if err := os.WriteFile(exportFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run("issue", "create", "--title", "Local only", "--project", projectKey); err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+
+	if _, err := run("import", exportFile, "--merge", "--prefer", "theirs"); err == nil {
+		t.Error("import --merge with an invalid --prefer succeeded")
+	}
+	if _, err := run("import", exportFile, "--merge", "--overwrite"); err == nil {
+		t.Error("import with --merge and --overwrite succeeded")
+	}
+
+	out, err := run("import", exportFile, "--merge", "--dry-run")
+	if err != nil {
+		t.Fatalf("import --merge --dry-run failed: %v", err)
+	}
+	for _, want := range []string{
+		"Would merge into \"" + projectKey + "\": 1 issues added, 1 updated, 0 unchanged, 1 kept local",
+		id(1) + ": kept the local copy, both copies have the same update time (differs in title)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+	if issue, _ := loadLocalIssue(t.Context(), projectKey, id(10)); issue != nil {
+		t.Fatal("Dry run added an issue")
+	}
+
+	if _, err := run("import", exportFile, "--merge"); err != nil {
+		t.Fatalf("import --merge failed: %v", err)
+	}
+	index, _ := loadQueryIndex(t.Context(), projectKey)
+	for n, title := range map[int]string{1: "Local A", 2: "File B", 3: "Local only", 10: "File only"} {
+		issue, _ := loadLocalIssue(t.Context(), projectKey, id(n))
+		if issue == nil || issue.Title != title || index.FindIssue(id(n)) == nil {
+			t.Errorf("Expected %s to be %q and indexed, got %+v", id(n), title, issue)
+		}
+	}
+
+	out, err = run("import", exportFile, "--merge", "--prefer", "file")
+	if err != nil || !strings.Contains(out, "0 issues added, 1 updated, 2 unchanged, 0 kept local") {
+		t.Fatalf("import --merge --prefer file failed: %v\n%s", err, out)
+	}
+	if issue, _ := loadLocalIssue(t.Context(), projectKey, id(1)); issue == nil || issue.Title != "File A" {
+		t.Errorf("Expected the file's copy with --prefer file, got %+v", issue)
+	}
+}
+
+func TestImportProject_Stdin(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(stdin string, args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		cmd.SetIn(strings.NewReader(stdin))
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"epic", "create", "--project", projectKey, "--title", "Payments"},
+		{"issue", "create", "--project", projectKey, "--title", "Checkout", "--epic", projectKey + "-E1"},
+	} {
+		if _, err := run("", args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	// Each encoding is piped from export to import without a file
+	for _, format := range []string{"json", "yaml", "ndjson"} {
+		exported, err := run("", "export", projectKey, "--format", format, "--output", "-")
+		if err != nil {
+			t.Fatalf("export --format %s failed: %v", format, err)
+		}
+		if _, err := run(exported, "import", "-", "--overwrite"); err != nil {
+			t.Fatalf("import - of %s failed: %v", format, err)
+		}
+		if issue, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-1"); issue == nil || issue.EpicID != projectKey+"-E1" {
+			t.Errorf("Expected the issue back from %s on stdin, got %+v", format, issue)
+		}
+	}
+
+	if _, err := run("not an export", "import", "-", "--overwrite"); err == nil {
+		t.Error("Expected garbage on stdin to fail")
+	}
+	if _, err := run("- [ ] Task", "import", "-", "--from", "markdown", "--project", projectKey); err == nil {
+		t.Error("Expected --from markdown on stdin to fail")
+	}
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"encoding/xml"
+	"html"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// jiraSource reads Jira's CSV export, its XML export, and JSON from its REST
+// API search.
+var jiraSource = &trackerSource{
+	From: "jira",
+	Name: "Jira",
+	Load: loadJiraRecords,
+	Columns: map[string][]string{
+		"key":        {"issuekey", "key"},
+		"id":         {"issueid", "id"},
+		"assignee":   {"assignee", "assigneedisplayname", "assigneename"},
+		"resolution": {"resolution", "resolutionname"},
+		"created":    {"created"},
+		"epic":       {"customfieldepiclink", "epiclink"},
+		"parent":     {"parentid", "parent", "parentkey"},
+	},
+}
+
+// htmlTag matches an HTML tag, to turn the HTML of Jira XML exports into text.
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// blankLines matches runs of blank lines.
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+// loadJiraRecords reads a Jira export by its file extension.
+func loadJiraRecords(filePath string, data []byte) (*migrateRecords, error) {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".xml":
+		return parseJiraXMLRecords(data)
+	case ".json":
+		return parseJiraRecords(data)
+	default:
+		return parseCSVRecords(data)
+	}
+}
+
+// jiraXML is the part of a Jira XML export (an RSS feed of issues) that is
+// imported.
+type jiraXML struct {
+	Items []struct {
+		Key struct {
+			ID    string `xml:"id,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"key"`
+		Summary     string   `xml:"summary"`
+		Type        string   `xml:"type"`
+		Status      string   `xml:"status"`
+		Priority    string   `xml:"priority"`
+		Resolution  string   `xml:"resolution"`
+		Assignee    string   `xml:"assignee"`
+		Description string   `xml:"description"`
+		Created     string   `xml:"created"`
+		Due         string   `xml:"due"`
+		Labels      []string `xml:"labels>label"`
+		Parent      string   `xml:"parent"`
+		Fields      []struct {
+			Name   string   `xml:"customfieldname"`
+			Values []string `xml:"customfieldvalues>customfieldvalue"`
+		} `xml:"customfields>customfield"`
+	} `xml:"channel>item"`
+}
+
+// parseJiraXMLRecords reads a Jira XML export, with the column names of the
+// CSV export. Custom fields become "Custom field (NAME)" columns.
+func parseJiraXMLRecords(data []byte) (*migrateRecords, error) {
+	var export jiraXML
+	if err := xml.Unmarshal(data, &export); err != nil {
+		return nil, invalidf("cli: invalid Jira XML export: %w", err)
+	}
+
+	rows := make([]map[string]string, 0, len(export.Items))
+	for _, item := range export.Items {
+		record := map[string]string{}
+		set := func(column, value string) {
+			if value = strings.TrimSpace(value); value != "" && value != "Unassigned" && value != "Unresolved" {
+				record[column] = value
+			}
+		}
+		set("Issue key", item.Key.Value)
+		set("Issue id", item.Key.ID)
+		set("Summary", item.Summary)
+		set("Issue Type", item.Type)
+		set("Status", item.Status)
+		set("Priority", item.Priority)
+		set("Resolution", item.Resolution)
+		set("Assignee", item.Assignee)
+		set("Description", htmlToText(item.Description))
+		set("Created", item.Created)
+		set("Due Date", item.Due)
+		set("Labels", strings.Join(item.Labels, ","))
+		set("Parent", item.Parent)
+		for _, field := range item.Fields {
+			set("Custom field ("+field.Name+")", strings.Join(field.Values, ","))
+		}
+		rows = append(rows, record)
+	}
+	return newMigrateRecords(rows, "Issue key", "Summary"), nil
+}
+
+// htmlToText turns the HTML of a Jira XML export into plain text.
+func htmlToText(s string) string {
+	s = strings.NewReplacer("<br/>", "\n", "<br>", "\n", "</p>", "\n\n", "<li>", "- ").Replace(s)
+	s = html.UnescapeString(htmlTag.ReplaceAllString(s, ""))
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
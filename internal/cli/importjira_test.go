@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+const jiraCSV = "\xef\xbb\xbfSummary,Issue key,Issue id,Issue Type,Status,Priority,Assignee,Created,Due Date,Labels,Labels,Parent id,Custom field (Epic Link),Description\n" +
+	"Checkout redesign,WEB-1,10001,Epic,In Progress,Medium,,01/Mar/24 9:00 AM,,,,,,The new checkout\n" +
+	"Pay with card,WEB-2,10002,Story,In QA,High,Alice Smith,02/Mar/24 10:30 AM,15/Apr/24,payments,ui,,WEB-1,\"Card form,\nwith validation\"\n" +
+	"Card icons,WEB-3,10003,Sub-task,Done,Low,,03/Mar/24 11:00 AM,,,,10002,,\n" +
+	"Crash on submit,WEB-4,10004,Bug,To Do,Highest,bob,,,,,,WEB-99,\n" +
+	",WEB-5,10005,Task,To Do,,,,,,,,,\n" +
+	"Bad due date,WEB-6,10006,Task,To Do,,,,someday,,,,,\n"
+
+const jiraXMLExport = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="0.92"><channel><title>Jira</title>
+<item>
+  <title>[OPS-7] Rotate keys</title>
+  <key id="20007">OPS-7</key>
+  <summary>Rotate keys</summary>
+  <type id="3">Task</type>
+  <status id="3">In Progress</status>
+  <priority id="2">High</priority>
+  <assignee username="carol">Carol Jones</assignee>
+  <description>&lt;p&gt;Rotate &lt;b&gt;all&lt;/b&gt; keys&lt;/p&gt;</description>
+  <created>Mon, 4 Mar 2024 10:00:00 +0000</created>
+  <labels><label>security</label></labels>
+  <customfields>
+    <customfield id="customfield_10014"><customfieldname>Epic Link</customfieldname>
+      <customfieldvalues><customfieldvalue>OPS-1</customfieldvalue></customfieldvalues></customfield>
+  </customfields>
+</item>
+<item>
+  <key id="20001">OPS-1</key>
+  <summary>Hardening</summary>
+  <type id="10000">Epic</type>
+  <status id="1">Open</status>
+</item>
+</channel></rss>`
+
+func TestImportJira(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "jira.csv")
+	xmlPath := filepath.Join(dir, "jira.xml")
+	if err := os.WriteFile(csvPath, []byte(jiraCSV), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(xmlPath, []byte(jiraXMLExport), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(stdin string, args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		cmd.SetIn(strings.NewReader(stdin))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	if _, err := run("", "project", "create", projectKey); err != nil {
+		t.Fatalf("project create failed: %v", err)
+	}
+	for _, args := range [][]string{
+		{"--field-map", "owner=Assignee"},
+		{"--field-map", "epic=Missing column"},
+		{"--status-map", "In QA=LATER"},
+		{"--review"},
+	} {
+		if _, err := run("", append([]string{"import", csvPath, "--from", "jira", "--project", projectKey}, args...)...); err == nil {
+			t.Errorf("import with %v succeeded", args)
+		}
+	}
+
+	// The dry run shows the mapping and the skipped records
+	out, err := run("", "import", csvPath, "--from", "jira", "--project", projectKey, "--status-map", "In QA=DOING", "--dry-run")
+	if err != nil {
+		t.Fatalf("import --dry-run failed: %v", err)
+	}
+	for _, want := range []string{
+		`status "In QA" -> DOING`,
+		`status "To Do" -> TODO`,
+		"epic <- Custom field (Epic Link)",
+		"parent <- Parent id",
+		"To import: 3 issues (2 linked to an epic or parent) and 1 epics",
+		"record 5 (WEB-5): empty title",
+		`record 6 (WEB-6): invalid due date "someday"`,
+		"WEB-4: linked issue WEB-99 is not in the export",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+	if index, _ := loadQueryIndex(t.Context(), projectKey); len(index.Issues) != 0 {
+		t.Fatalf("Expected no issues after a dry run, got %d", len(index.Issues))
+	}
+
+	out, err = run("y\n", "import", csvPath, "--from", "jira", "--project", projectKey, "--status-map", "In QA=DOING")
+	if err != nil || !strings.Contains(out, "Imported 3 issues and 1 epics into "+projectKey+" (2 records skipped)") {
+		t.Fatalf("import failed: %v\n%s", err, out)
+	}
+	card, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-1")
+	if card == nil || card.Title != "Pay with card" || card.Status != models.StatusDOING || card.Priority != models.PriorityHIGH ||
+		card.Assignee != "alice.smith" || card.EpicID != projectKey+"-E1" || card.Due != "2024-04-15T00:00:00Z" ||
+		strings.Join(card.Labels, ",") != "payments,ui" || card.Description != "Card form,\nwith validation" ||
+		card.CreatedAt != "2024-03-02T10:30:00Z" || len(card.Comments) != 1 || card.Comments[0].Body != "Imported from Jira WEB-2" {
+		t.Errorf("Unexpected issue: %+v", card)
+	}
+	if icons, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-2"); icons == nil || icons.ParentID != projectKey+"-1" || icons.Status != models.StatusDONE {
+		t.Errorf("Expected the sub-task under its parent, got %+v", icons)
+	}
+	if crash, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-3"); crash == nil || crash.Type != models.TypeBug || crash.Priority != models.PriorityCRITICAL || crash.EpicID != "" {
+		t.Errorf("Unexpected bug: %+v", crash)
+	}
+	epic, err := loadEpic(t.Context(), projectKey, projectKey+"-E1")
+	if err != nil || epic.Title != "Checkout redesign" || epic.Status != models.StatusDOING {
+		t.Errorf("Unexpected epic: %+v (%v)", epic, err)
+	}
+	index, _ := loadQueryIndex(t.Context(), projectKey)
+	if entry := index.FindEpic(projectKey + "-E1"); entry == nil || entry.Issues != 1 {
+		t.Errorf("Unexpected epic index entry: %+v", entry)
+	}
+
+	// XML exports map the same way
+	out, err = run("", "import", xmlPath, "--from", "jira", "--project", projectKey, "--yes")
+	if err != nil || !strings.Contains(out, "Imported 1 issues and 1 epics") {
+		t.Fatalf("XML import failed: %v\n%s", err, out)
+	}
+	keys, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-4")
+	if keys == nil || keys.Title != "Rotate keys" || keys.EpicID != projectKey+"-E2" || keys.Description != "Rotate all keys" ||
+		keys.Assignee != "carol.jones" || keys.CreatedAt != "2024-03-04T10:00:00Z" || strings.Join(keys.Labels, ",") != "security" {
+		t.Errorf("Unexpected issue from XML: %+v", keys)
+	}
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+)
+
+// linearSource reads Linear's CSV export and JSON from its GraphQL API.
+var linearSource = &trackerSource{
+	From: "linear",
+	Name: "Linear",
+	Load: loadLinearRecords,
+	Columns: map[string][]string{
+		"key":      {"id", "identifier"},
+		"assignee": {"assignee", "assigneename"},
+		"created":  {"created", "createdat"},
+		"archived": {"archived", "archivedat"},
+		"parent":   {"parentissue", "parent"},
+		"group":    {"project", "projectname"},
+	},
+}
+
+// linearIssues is the result of a Linear GraphQL issues query, such as
+//
+//	{ issues { nodes { identifier title description priorityLabel dueDate
+//	  createdAt archivedAt state { name } assignee { displayName }
+//	  labels { nodes { name } } parent { identifier } project { name } } } }
+type linearIssues struct {
+	Data struct {
+		Issues struct {
+			Nodes []struct {
+				Identifier    string `json:"identifier"`
+				Title         string `json:"title"`
+				Description   string `json:"description"`
+				PriorityLabel string `json:"priorityLabel"`
+				DueDate       string `json:"dueDate"`
+				CreatedAt     string `json:"createdAt"`
+				ArchivedAt    string `json:"archivedAt"`
+				State         struct {
+					Name string `json:"name"`
+				} `json:"state"`
+				Assignee *struct {
+					Name        string `json:"name"`
+					DisplayName string `json:"displayName"`
+				} `json:"assignee"`
+				Labels struct {
+					Nodes []struct {
+						Name string `json:"name"`
+					} `json:"nodes"`
+				} `json:"labels"`
+				Parent *struct {
+					Identifier string `json:"identifier"`
+				} `json:"parent"`
+				Project *struct {
+					Name string `json:"name"`
+				} `json:"project"`
+			} `json:"nodes"`
+		} `json:"issues"`
+	} `json:"data"`
+}
+
+// loadLinearRecords reads a Linear export: CSV, or JSON from the GraphQL API
+// with the column names of the CSV export.
+func loadLinearRecords(filePath string, data []byte) (*migrateRecords, error) {
+	if !strings.EqualFold(filepath.Ext(filePath), ".json") {
+		return parseCSVRecords(data)
+	}
+
+	var result linearIssues
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, invalidf("cli: invalid Linear export: %w", err)
+	}
+	rows := make([]map[string]string, 0, len(result.Data.Issues.Nodes))
+	for _, issue := range result.Data.Issues.Nodes {
+		record := map[string]string{}
+		set := func(column, value string) {
+			if value = strings.TrimSpace(value); value != "" {
+				record[column] = value
+			}
+		}
+		set("ID", issue.Identifier)
+		set("Title", issue.Title)
+		set("Description", issue.Description)
+		set("Status", issue.State.Name)
+		set("Priority", issue.PriorityLabel)
+		set("Due Date", issue.DueDate)
+		set("Created", issue.CreatedAt)
+		set("Archived", issue.ArchivedAt)
+		if issue.Assignee != nil {
+			set("Assignee", issue.Assignee.DisplayName)
+		}
+		var labels []string
+		for _, label := range issue.Labels.Nodes {
+			labels = append(labels, label.Name)
+		}
+		set("Labels", strings.Join(labels, ","))
+		if issue.Parent != nil {
+			set("Parent issue", issue.Parent.Identifier)
+		}
+		if issue.Project != nil {
+			set("Project", issue.Project.Name)
+		}
+		rows = append(rows, record)
+	}
+	return newMigrateRecords(rows, "ID", "Title"), nil
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+const linearCSV = "ID,Team,Title,Description,Status,Priority,Project,Assignee,Labels,Created,Due Date,Parent issue,Archived\n" +
+	"ENG-1,Engineering,Search API,Query endpoint,In Progress,High,Search,dana,\"backend, api\",2024-03-01T09:00:00.000Z,2024-04-01,,\n" +
+	"ENG-2,Engineering,Index documents,,Todo,Urgent,Search,,backend,2024-03-02T09:00:00.000Z,,ENG-1,\n" +
+	"ENG-3,Engineering,Old spike,,Canceled,No priority,,,,2024-01-02T09:00:00.000Z,,,\n" +
+	"ENG-4,Engineering,Stale task,,Backlog,Low,,,,2024-01-03T09:00:00.000Z,,,2024-02-01T00:00:00.000Z\n"
+
+const linearJSON = `{"data": {"issues": {"nodes": [
+  {"identifier": "ENG-9", "title": "Ranking", "description": "Boost recent docs", "priorityLabel": "Medium",
+   "createdAt": "2024-03-05T09:00:00.000Z", "state": {"name": "Done", "type": "completed"},
+   "assignee": {"name": "Erin Park", "displayName": "erin"}, "labels": {"nodes": [{"name": "backend"}]},
+   "parent": null, "project": {"name": "Search"}}
+]}}}`
+
+func TestImportLinear(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "linear.csv")
+	jsonPath := filepath.Join(dir, "linear.json")
+	if err := os.WriteFile(csvPath, []byte(linearCSV), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(jsonPath, []byte(linearJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		cmd.SetIn(strings.NewReader(""))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	if _, err := run("project", "create", projectKey); err != nil {
+		t.Fatalf("project create failed: %v", err)
+	}
+
+	out, err := run("import", csvPath, "--from", "linear", "--project", projectKey, "--yes")
+	if err != nil || !strings.Contains(out, "Imported 3 issues and 1 epics into "+projectKey+" (1 records skipped)") {
+		t.Fatalf("import failed: %v\n%s", err, out)
+	}
+	for _, want := range []string{"group <- Project", "record 4 (ENG-4): archived"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+	search, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-1")
+	if search == nil || search.Title != "Search API" || search.Status != models.StatusDOING || search.Priority != models.PriorityHIGH ||
+		search.Assignee != "dana" || search.EpicID != projectKey+"-E1" || search.Due != "2024-04-01T00:00:00Z" ||
+		strings.Join(search.Labels, ",") != "backend,api" || search.CreatedAt != "2024-03-01T09:00:00Z" ||
+		len(search.Comments) != 1 || search.Comments[0].Body != "Imported from Linear ENG-1" {
+		t.Errorf("Unexpected issue: %+v", search)
+	}
+	if index, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-2"); index == nil || index.ParentID != projectKey+"-1" ||
+		index.EpicID != projectKey+"-E1" || index.Priority != models.PriorityCRITICAL {
+		t.Errorf("Unexpected sub-issue: %+v", index)
+	}
+	if spike, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-3"); spike == nil || spike.Status != models.StatusDONE || spike.EpicID != "" {
+		t.Errorf("Expected the canceled issue done, got %+v", spike)
+	}
+	if epic, err := loadEpic(t.Context(), projectKey, projectKey+"-E1"); err != nil || epic.Title != "Search" {
+		t.Errorf("Unexpected epic: %+v (%v)", epic, err)
+	}
+
+	// API exports map the same way
+	out, err = run("import", jsonPath, "--from", "linear", "--project", projectKey, "--yes")
+	if err != nil || !strings.Contains(out, "Imported 1 issues and 1 epics") {
+		t.Fatalf("JSON import failed: %v\n%s", err, out)
+	}
+	ranking, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-4")
+	if ranking == nil || ranking.Title != "Ranking" || ranking.Status != models.StatusDONE || ranking.Assignee != "erin" || ranking.EpicID != projectKey+"-E2" {
+		t.Errorf("Unexpected issue from JSON: %+v", ranking)
+	}
+}
@@ -0,0 +1,367 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// Import sources accepted by import --from.
+const (
+	importFromBuyruk   = "buyruk"
+	importFromMarkdown = "markdown"
+)
+
+// markdownListItem matches a bullet or numbered list item with an optional
+// checkbox: indent, checkbox state, and text.
+var markdownListItem = regexp.MustCompile(`^(\s*)(?:[-*+]|\d+[.)])\s+(?:\[([ xX~/-])\]\s+)?(.*)$`)
+
+// markdownHeading matches a Markdown heading.
+var markdownHeading = regexp.MustCompile(`^\s{0,3}#{1,6}(\s|$)`)
+
+// defaultStatusEmoji maps status emoji at the start of an item to a status
+// role: "done" or "doing" (the first status that is neither default nor done).
+var defaultStatusEmoji = map[string]string{
+	"✅":  "done",
+	"✔️": "done",
+	"✔":  "done",
+	"☑️": "done",
+	"🚧":  "doing",
+	"🔄":  "doing",
+	"⏳":  "doing",
+	"🏗️": "doing",
+}
+
+// markdownItem is an issue parsed from a Markdown list item.
+type markdownItem struct {
+	issue  *models.Issue
+	parent int // Index of the enclosing item, or -1
+	depth  int
+}
+
+// importMarkdown creates issues from the checklist and bullet items of a
+// Markdown file, after showing a preview and asking for confirmation.
+func importMarkdown(filePath string, cmd *cobra.Command) error {
+	if overwrite, _ := cmd.Flags().GetBool("overwrite"); overwrite {
+		return fmt.Errorf("cli: --overwrite is not supported with --from %s", importFromMarkdown)
+	}
+	if review, _ := cmd.Flags().GetBool("review"); review {
+		return fmt.Errorf("cli: --review is not supported with --from %s", importFromMarkdown)
+	}
+
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	if _, err := loadQueryIndex(cmd.Context(), projectKey); err != nil {
+		return err
+	}
+	wf, err := loadWorkflow(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("cli: failed to read %s: %w", filePath, err)
+	}
+	statusMap, _ := cmd.Flags().GetString("status-map")
+	emoji, err := parseStatusEmojiMap(statusMap, wf)
+	if err != nil {
+		return err
+	}
+	items := parseMarkdownItems(data, emoji, wf)
+
+	out := cmd.OutOrStdout()
+	if len(items) == 0 {
+		fmt.Fprintf(out, "No list items found in %s\n", filePath)
+		return nil
+	}
+	fmt.Fprintf(out, "Preview (%d issues from %s):\n", len(items), filePath)
+	printMarkdownPreview(out, items)
+
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		return nil
+	}
+	assumeYes, _ := cmd.Flags().GetBool("yes")
+	p := &migratePrompter{scanner: bufio.NewScanner(cmd.InOrStdin()), out: cmd.ErrOrStderr(), assumeYes: assumeYes}
+	if !assumeYes && !p.confirm(fmt.Sprintf("Create %d issues in %s?", len(items), projectKey), false) {
+		fmt.Fprintln(out, "Import cancelled")
+		return nil
+	}
+
+	ids, err := createMarkdownIssues(cmd.Context(), projectKey, items)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(successOut(cmd), "Created %d issues in %s (%s to %s)\n", len(ids), projectKey, ids[0], ids[len(ids)-1])
+	warnQuota(projectKey, cmd)
+	return nil
+}
+
+// parseStatusEmojiMap returns the emoji to status map: the defaults, resolved
+// against the workflow, with "emoji=STATUS" pairs from --status-map on top.
+func parseStatusEmojiMap(value string, wf *models.Workflow) (map[string]string, error) {
+	roles := map[string]string{"done": wf.DoneStatusList()[0]}
+	if doing := doingStatus(wf); doing != "" {
+		roles["doing"] = doing
+	}
+
+	emoji := map[string]string{}
+	for e, role := range defaultStatusEmoji {
+		if status, ok := roles[role]; ok {
+			emoji[e] = status
+		}
+	}
+	for _, pair := range splitList(value) {
+		e, status, ok := strings.Cut(pair, "=")
+		e, status = strings.TrimSpace(e), strings.ToUpper(strings.TrimSpace(status))
+		if !ok || e == "" {
+			return nil, invalidf("cli: invalid --status-map entry %q (use emoji=STATUS)", pair)
+		}
+		if !wf.IsValidStatus(status) {
+			return nil, invalidf("cli: invalid status %q in --status-map", status)
+		}
+		emoji[e] = status
+	}
+	return emoji, nil
+}
+
+// doingStatus returns the first workflow status that is neither the default
+// nor done, or "" if there is none.
+func doingStatus(wf *models.Workflow) string {
+	for _, status := range wf.StatusList() {
+		if status != wf.DefaultStatus() && !wf.IsDoneStatus(status) {
+			return status
+		}
+	}
+	return ""
+}
+
+// parseMarkdownItems parses the list items of a Markdown document into
+// issues. Checked boxes mark issues done, "[~]" or "[/]" in progress, and a
+// leading status emoji sets the status. Runs of "!" set the priority ("!!!"
+// is the highest workflow priority, "!!" the next), "@user" the assignee,
+// and "#label" a label. Indented lines below an item become its description,
+// and nested items become subtasks of the item they are nested in.
+func parseMarkdownItems(data []byte, emoji map[string]string, wf *models.Workflow) []*markdownItem {
+	var items []*markdownItem
+	var stack []int // Open items, outermost first
+	var indents []int
+	inFence := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || line == "" {
+			continue
+		}
+		if markdownHeading.MatchString(line) {
+			stack, indents = nil, nil
+			continue
+		}
+
+		m := markdownListItem.FindStringSubmatch(line)
+		indent := len(strings.ReplaceAll(leadingSpace(line), "\t", "    "))
+		if m == nil {
+			// Indented text continues the description of the open item
+			if len(stack) > 0 && indent > indents[len(indents)-1] {
+				issue := items[stack[len(stack)-1]].issue
+				issue.Description = strings.TrimSpace(issue.Description + "\n" + strings.TrimSpace(line))
+			} else {
+				stack, indents = nil, nil
+			}
+			continue
+		}
+
+		for len(stack) > 0 && indents[len(indents)-1] >= indent {
+			stack, indents = stack[:len(stack)-1], indents[:len(indents)-1]
+		}
+		issue := parseMarkdownItem(m[2], m[3], emoji, wf)
+		if issue == nil {
+			continue
+		}
+		item := &markdownItem{issue: issue, parent: -1, depth: len(stack)}
+		if len(stack) > 0 {
+			item.parent = stack[len(stack)-1]
+		}
+		items = append(items, item)
+		stack, indents = append(stack, len(items)-1), append(indents, indent)
+	}
+	return items
+}
+
+// parseMarkdownItem builds an issue from a list item's checkbox and text.
+// Returns nil for items without a title.
+func parseMarkdownItem(checkbox, text string, emoji map[string]string, wf *models.Workflow) *models.Issue {
+	issue := &models.Issue{Type: defaultMigrateType(wf), Status: wf.DefaultStatus()}
+	switch checkbox {
+	case "x", "X":
+		issue.Status = wf.DoneStatusList()[0]
+	case "~", "/", "-":
+		if doing := doingStatus(wf); doing != "" {
+			issue.Status = doing
+		}
+	}
+
+	// Longer emoji first, so "✔️" is not taken for "✔" and a variation selector
+	text = strings.TrimSpace(text)
+	keys := slices.Collect(maps.Keys(emoji))
+	slices.SortFunc(keys, func(a, b string) int { return len(b) - len(a) })
+	for _, e := range keys {
+		if rest, ok := strings.CutPrefix(text, e); ok {
+			issue.Status = emoji[e]
+			text = strings.TrimSpace(rest)
+			break
+		}
+	}
+
+	var words []string
+	for _, word := range strings.Fields(text) {
+		switch {
+		case strings.Trim(word, "!") == "":
+			priorities := wf.PriorityList()
+			if rank := len(priorities) - 4 + min(len(word), 3); rank >= 0 {
+				issue.Priority = priorities[rank]
+			}
+		case len(word) > 1 && word[0] == '@' && models.ValidateUser(word[1:]) == nil:
+			issue.Assignee = word[1:]
+		case len(word) > 1 && word[0] == '#' && models.ValidateLabel(word[1:]) == nil && !isDigits(word[1:]):
+			if !slices.Contains(issue.Labels, word[1:]) {
+				issue.Labels = append(issue.Labels, word[1:])
+			}
+		default:
+			words = append(words, word)
+		}
+	}
+	issue.Title = strings.Join(words, " ")
+	if issue.Title == "" {
+		return nil
+	}
+	return issue
+}
+
+// printMarkdownPreview lists the parsed issues, nested as in the document.
+func printMarkdownPreview(w io.Writer, items []*markdownItem) {
+	for i, item := range items {
+		issue := item.issue
+		fmt.Fprintf(w, "  %s%d. [%s] %s", strings.Repeat("  ", item.depth), i+1, issue.Status, issue.Title)
+		if issue.Priority != "" {
+			fmt.Fprintf(w, " (%s)", issue.Priority)
+		}
+		if issue.Assignee != "" {
+			fmt.Fprintf(w, " @%s", issue.Assignee)
+		}
+		for _, label := range issue.Labels {
+			fmt.Fprintf(w, " #%s", label)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// createMarkdownIssues creates the parsed issues, with nested items as
+// subtasks, writing the issues and the index together under the project lock.
+func createMarkdownIssues(ctx context.Context, projectKey string, items []*markdownItem) ([]string, error) {
+	cleanup, err := storage.AcquireLock(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to acquire lock: %w", err)
+	}
+	defer cleanup()
+
+	indexPath, err := storage.ProjectIndexPath(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(ctx, indexPath, &index); err != nil {
+		return nil, fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+
+	next := maxRedirectSequence(ctx, projectKey)
+	for _, entry := range index.Issues {
+		if _, seq, err := models.ParseIssueID(entry.ID); err == nil && seq > next {
+			next = seq
+		}
+	}
+	next++
+
+	now := storage.Timestamp(ctx)
+	ids := make([]string, 0, len(items))
+	var writes []fileWrite
+	for _, item := range items {
+		issue := item.issue
+		seq := storage.NextSequence(ctx, projectKey, next)
+		next = seq + 1
+		if issue.ID, err = index.NewIssueID(projectKey, seq, storage.Now(ctx)); err != nil {
+			return nil, err
+		}
+		if item.parent >= 0 {
+			issue.ParentID = items[item.parent].issue.ID
+		}
+		issue.CreatedAt = now
+		issue.UpdatedAt = now
+		if err := index.AssignUID(issue, storage.Now(ctx)); err != nil {
+			return nil, err
+		}
+
+		issuePath, err := storage.IssuePath(ctx, projectKey, issue.ID)
+		if err != nil {
+			return nil, fmt.Errorf("cli: failed to resolve issue path: %w", err)
+		}
+		data, err := json.MarshalIndent(issue, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("cli: failed to marshal issue %s: %w", issue.ID, err)
+		}
+		writes = append(writes, fileWrite{path: issuePath, data: data})
+		index.AddIssue(issue)
+		ids = append(ids, issue.ID)
+	}
+	index.UpdatedAt = now
+
+	write, err := plannedIndexWrite(indexPath, &index)
+	if err != nil {
+		return nil, err
+	}
+	writes = append(writes, write)
+
+	if err := storage.BeginTransaction(ctx, projectKey, "import_markdown", map[string]interface{}{
+		"issues": len(ids),
+	}); err != nil {
+		return nil, fmt.Errorf("cli: failed to begin transaction: %w", err)
+	}
+	if err := writeFiles(ctx, writes); err != nil {
+		storage.RollbackTransaction(ctx, projectKey)
+		return nil, err
+	}
+	if err := storage.CommitTransaction(ctx, projectKey); err != nil {
+		return nil, fmt.Errorf("cli: failed to commit transaction: %w", err)
+	}
+	return ids, nil
+}
+
+// leadingSpace returns the leading spaces and tabs of a line.
+func leadingSpace(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// isDigits reports whether s is all ASCII digits, like issue numbers ("#12").
+func isDigits(s string) bool {
+	return strings.Trim(s, "0123456789") == ""
+}
@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+const meetingNotes = "# Weekly sync\n\n" +
+	"Attendees: alice, bob\n\n" +
+	"## Action items\n\n" +
+	"- [ ] Draft the RFC !! @alice #docs\n" +
+	"  Cover the rollout plan and the rollback path.\n" +
+	"  - [x] Collect requirements\n" +
+	"  - [~] Review the API !!!\n" +
+	"- [x] Book the room\n" +
+	"* 🚧 Update the runbook #ops\n" +
+	"1. 👀 Check the dashboards\n" +
+	"```\n- [ ] not an item\n```\n"
+
+func TestImportMarkdown(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	notesPath := filepath.Join(t.TempDir(), "notes.md")
+	if err := os.WriteFile(notesPath, []byte(meetingNotes), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(stdin string, args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		cmd.SetIn(strings.NewReader(stdin))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	if _, err := run("", "project", "create", projectKey); err != nil {
+		t.Fatalf("project create failed: %v", err)
+	}
+	if _, err := run("", "import", notesPath, "--from", "asana", "--project", projectKey); err == nil {
+		t.Error("Expected an unknown source to fail")
+	}
+	if _, err := run("", "import", notesPath, "--from", "markdown", "--project", projectKey, "--status-map", "👀=LATER"); err == nil {
+		t.Error("Expected an unknown status in --status-map to fail")
+	}
+
+	// The preview lists the items; declining creates nothing
+	out, err := run("n\n", "import", notesPath, "--from", "markdown", "--project", projectKey, "--status-map", "👀=DONE")
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	for _, want := range []string{
+		"Preview (6 issues from " + notesPath + "):",
+		"1. [TODO] Draft the RFC (HIGH) @alice #docs",
+		"    2. [DONE] Collect requirements",
+		"    3. [DOING] Review the API (CRITICAL)",
+		"5. [DOING] Update the runbook #ops",
+		"6. [DONE] Check the dashboards",
+		"Import cancelled",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+	if index, _ := loadQueryIndex(t.Context(), projectKey); len(index.Issues) != 0 {
+		t.Fatalf("Expected no issues after declining, got %d", len(index.Issues))
+	}
+
+	out, err = run("y\n", "import", notesPath, "--from", "markdown", "--project", projectKey)
+	if err != nil || !strings.Contains(out, "Created 6 issues in "+projectKey) {
+		t.Fatalf("import failed: %v: %s", err, out)
+	}
+	rfc, err := loadLocalIssue(t.Context(), projectKey, projectKey+"-1")
+	if err != nil || rfc == nil {
+		t.Fatalf("Failed to load issue: %v", err)
+	}
+	if rfc.Priority != models.PriorityHIGH || rfc.Assignee != "alice" || rfc.Description != "Cover the rollout plan and the rollback path." {
+		t.Errorf("Unexpected issue: %+v", rfc)
+	}
+	if review, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-3"); review == nil || review.ParentID != projectKey+"-1" {
+		t.Errorf("Expected nested items to become subtasks, got %+v", review)
+	}
+	if dashboards, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-6"); dashboards == nil || dashboards.Status != models.StatusTODO || dashboards.Title != "👀 Check the dashboards" {
+		t.Errorf("Expected an unmapped emoji to stay in the title, got %+v", dashboards)
+	}
+}
+
+func TestImportMarkdown_IDScheme(t *testing.T) {
+	paddedKey := sanitizeTestName("TEST" + t.Name())
+	dateKey := paddedKey + "-D"
+	defer func() {
+		for _, key := range []string{paddedKey, dateKey} {
+			projectDir, _ := storage.ProjectDir(t.Context(), key)
+			os.RemoveAll(projectDir)
+		}
+	}()
+
+	notesPath := filepath.Join(t.TempDir(), "notes.md")
+	if err := os.WriteFile(notesPath, []byte(meetingNotes), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"project", "create", paddedKey, "--id-scheme", "padded"},
+		{"project", "create", dateKey, "--id-scheme", "date"},
+		{"import", notesPath, "--from", "markdown", "--project", paddedKey},
+		{"import", notesPath, "--from", "markdown", "--project", dateKey, "--fixed-time", "2024-06-01T09:00:00Z"},
+	} {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+		cmd.SetIn(strings.NewReader("y\n"))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	// Imported issues get IDs of the project's scheme, subtasks included
+	for projectKey, ids := range map[string][2]string{
+		paddedKey: {paddedKey + "-0001", paddedKey + "-0003"},
+		dateKey:   {dateKey + "-2024-001", dateKey + "-2024-003"},
+	} {
+		review, err := loadLocalIssue(t.Context(), projectKey, ids[1])
+		if err != nil || review == nil || review.ParentID != ids[0] {
+			t.Errorf("Expected %s to be a subtask of %s, got %+v (%v)", ids[1], ids[0], review, err)
+		}
+		if index, _ := loadQueryIndex(t.Context(), projectKey); index == nil || index.FindIssue(ids[0]) == nil {
+			t.Errorf("Expected %s in the index of %s", ids[0], projectKey)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// Which copy import --merge keeps when an issue or epic differs locally and
+// in the export.
+const (
+	mergePreferNewer = "newer" // The copy updated last; the local one on a tie
+	mergePreferLocal = "local"
+	mergePreferFile  = "file"
+)
+
+// mergePreferences are the values of import --prefer.
+var mergePreferences = []string{mergePreferNewer, mergePreferLocal, mergePreferFile}
+
+// importMerge counts what merging an export into a project changes.
+type importMerge struct {
+	added, updated, unchanged, keptLocal int
+	epicsAdded, epicsUpdated             int
+	conflicts                            []importConflict
+}
+
+// importConflict is an issue or epic changed both locally and in the export,
+// or whose ID is used by different issues.
+type importConflict struct {
+	ID     string
+	Kept   string   // "local" or "file"
+	Reason string   // Why the conflict was resolved as it was
+	Fields []string // Fields that differ
+}
+
+// mergeExportData upserts the issues and epics of an export into an existing
+// project by ID. Issues that differ are resolved by --prefer and reported as
+// conflicts when the copy dropped is not older than the one kept; local
+// issues missing from the export are never deleted. The workflow and project
+// settings stay as they are.
+func mergeExportData(exportData *ExportData, cmd *cobra.Command) error {
+	projectKey := exportData.Project.ProjectKey
+	prefer, _ := cmd.Flags().GetString("prefer")
+	if !slices.Contains(mergePreferences, prefer) {
+		return invalidf("cli: invalid --prefer value %q (allowed: %s)", prefer, strings.Join(mergePreferences, ", "))
+	}
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	wf, err := loadWorkflow(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+
+	cleanup, err := storage.AcquireLock(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to acquire lock: %w", err)
+	}
+	defer cleanup()
+
+	indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(cmd.Context(), indexPath, &index); err != nil {
+		return fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+
+	errOut := cmd.ErrOrStderr()
+	result := &importMerge{}
+	var writes []fileWrite
+	for _, issue := range exportData.Issues {
+		if err := issue.ValidateWithWorkflow(wf); err != nil {
+			fmt.Fprintf(errOut, "Warning: skipping invalid issue %s: %v\n", issue.ID, err)
+			continue
+		}
+		issuePath, err := storage.IssuePath(cmd.Context(), projectKey, issue.ID)
+		if err != nil {
+			fmt.Fprintf(errOut, "Warning: failed to resolve path for issue %s: %v\n", issue.ID, err)
+			continue
+		}
+		data, err := json.MarshalIndent(issue, "", "  ")
+		if err != nil {
+			return fmt.Errorf("cli: failed to marshal issue %s: %w", issue.ID, err)
+		}
+		localData, err := os.ReadFile(issuePath)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("cli: failed to read issue %s: %w", issue.ID, err)
+		}
+
+		if localData == nil {
+			result.added++
+		} else {
+			var local models.Issue
+			if err := json.Unmarshal(localData, &local); err != nil {
+				return fmt.Errorf("cli: failed to parse issue %s: %w", issue.ID, err)
+			}
+			if issue.UID != "" && local.UID != "" && issue.UID != local.UID {
+				result.keptLocal++
+				result.conflicts = append(result.conflicts, importConflict{ID: issue.ID, Kept: mergePreferLocal, Reason: "a different issue has this ID locally"})
+				continue
+			}
+			changes, err := jsonFieldChanges(localData, data)
+			if err != nil {
+				return fmt.Errorf("cli: failed to compare issue %s: %w", issue.ID, err)
+			}
+			if len(changes) == 0 {
+				result.unchanged++
+				continue
+			}
+			useFile, conflict := resolveMergeConflict(prefer, issue.ID, issue.UpdatedAt, local.UpdatedAt, changes)
+			if conflict != nil {
+				result.conflicts = append(result.conflicts, *conflict)
+			}
+			if !useFile {
+				result.keptLocal++
+				continue
+			}
+			result.updated++
+		}
+		if err := index.AssignUID(issue, storage.Now(cmd.Context())); err != nil {
+			return err
+		}
+		writes = append(writes, fileWrite{path: issuePath, data: data, original: localData})
+		index.AddIssue(issue)
+	}
+
+	for _, epic := range exportData.Epics {
+		if err := epic.ValidateWithWorkflow(wf); err != nil {
+			fmt.Fprintf(errOut, "Warning: skipping invalid epic %s: %v\n", epic.ID, err)
+			continue
+		}
+		epicPath, err := storage.EpicPath(cmd.Context(), projectKey, epic.ID)
+		if err != nil {
+			fmt.Fprintf(errOut, "Warning: failed to resolve path for epic %s: %v\n", epic.ID, err)
+			continue
+		}
+		data, err := json.MarshalIndent(epic, "", "  ")
+		if err != nil {
+			return fmt.Errorf("cli: failed to marshal epic %s: %w", epic.ID, err)
+		}
+		localData, err := os.ReadFile(epicPath)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("cli: failed to read epic %s: %w", epic.ID, err)
+		}
+		if localData == nil {
+			result.epicsAdded++
+		} else {
+			var local models.Epic
+			if err := json.Unmarshal(localData, &local); err != nil {
+				return fmt.Errorf("cli: failed to parse epic %s: %w", epic.ID, err)
+			}
+			changes, err := jsonFieldChanges(localData, data)
+			if err != nil {
+				return fmt.Errorf("cli: failed to compare epic %s: %w", epic.ID, err)
+			}
+			if len(changes) == 0 {
+				continue
+			}
+			useFile, conflict := resolveMergeConflict(prefer, epic.ID, epic.UpdatedAt, local.UpdatedAt, changes)
+			if conflict != nil {
+				result.conflicts = append(result.conflicts, *conflict)
+			}
+			if !useFile {
+				continue
+			}
+			result.epicsUpdated++
+		}
+		writes = append(writes, fileWrite{path: epicPath, data: data, original: localData})
+		index.SetEpic(epic)
+	}
+
+	if len(writes) > 0 && !dryRun {
+		index.RecountEpics()
+		index.UpdatedAt = storage.Timestamp(cmd.Context())
+		write, err := plannedIndexWrite(indexPath, &index)
+		if err != nil {
+			return err
+		}
+		writes = append(writes, write)
+
+		if err := storage.BeginTransaction(cmd.Context(), projectKey, "import_merge", map[string]interface{}{
+			"added":   result.added,
+			"updated": result.updated,
+		}); err != nil {
+			return fmt.Errorf("cli: failed to begin transaction: %w", err)
+		}
+		if err := writeFiles(cmd.Context(), writes); err != nil {
+			storage.RollbackTransaction(cmd.Context(), projectKey)
+			return err
+		}
+		if err := storage.CommitTransaction(cmd.Context(), projectKey); err != nil {
+			return fmt.Errorf("cli: failed to commit transaction: %w", err)
+		}
+	}
+
+	renderImportMerge(cmd.OutOrStdout(), projectKey, result, dryRun)
+	if !dryRun {
+		warnQuota(projectKey, cmd)
+	}
+	return nil
+}
+
+// resolveMergeConflict decides whether the export's copy of a record that
+// differs locally replaces the local one. It returns a conflict when the copy
+// that loses was updated at the same time or later than the one kept, as its
+// changes are then likely not in the kept copy.
+func resolveMergeConflict(prefer, id, fileUpdated, localUpdated string, changes []fieldChange) (useFile bool, conflict *importConflict) {
+	fileNewer := newerRecord(fileUpdated, localUpdated)
+	localNewer := newerRecord(localUpdated, fileUpdated)
+	fields := make([]string, len(changes))
+	for i, change := range changes {
+		fields[i] = change.Field
+	}
+
+	switch prefer {
+	case mergePreferFile:
+		if localNewer {
+			return true, &importConflict{ID: id, Kept: mergePreferFile, Reason: "overwrote a newer local copy (--prefer file)", Fields: fields}
+		}
+		return true, nil
+	case mergePreferLocal:
+		if fileNewer {
+			return false, &importConflict{ID: id, Kept: mergePreferLocal, Reason: "ignored a newer copy in the file (--prefer local)", Fields: fields}
+		}
+		return false, nil
+	default:
+		if fileNewer {
+			return true, nil
+		}
+		if localNewer {
+			return false, nil
+		}
+		return false, &importConflict{ID: id, Kept: mergePreferLocal, Reason: "both copies have the same update time", Fields: fields}
+	}
+}
+
+// renderImportMerge prints what merging an export changed.
+func renderImportMerge(w io.Writer, projectKey string, result *importMerge, dryRun bool) {
+	verb := "Merged"
+	if dryRun {
+		verb = "Would merge"
+	}
+	fmt.Fprintf(w, "%s into %q: %d issues added, %d updated, %d unchanged, %d kept local; %d epics added, %d updated\n",
+		verb, projectKey, result.added, result.updated, result.unchanged, result.keptLocal, result.epicsAdded, result.epicsUpdated)
+	if len(result.conflicts) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%d conflicts:\n", len(result.conflicts))
+	for _, conflict := range result.conflicts {
+		line := fmt.Sprintf("  %s: kept the %s copy, %s", conflict.ID, conflict.Kept, conflict.Reason)
+		if len(conflict.Fields) > 0 {
+			line += " (differs in " + strings.Join(conflict.Fields, ", ") + ")"
+		}
+		fmt.Fprintln(w, line)
+	}
+}
@@ -0,0 +1,519 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// trackerSource is another issue tracker whose exports import --from reads.
+// Exports are loaded as records with named columns, mapped to issue fields
+// and workflow values like in migrate-wizard.
+type trackerSource struct {
+	From    string                                                      // --from value, e.g. "jira"
+	Name    string                                                      // Display name, e.g. "Jira"
+	Load    func(filePath string, data []byte) (*migrateRecords, error) // Reads an export
+	Columns map[string][]string                                         // Normalized column names guessed for each tracker field
+}
+
+// trackerFields are the columns read besides the migrate fields, in the order
+// they are shown:
+//
+//	key       the tracker's ID of the record, e.g. "WEB-12"
+//	id        another ID other records may refer to it by
+//	assignee  user name; "Alice Smith" becomes alice.smith
+//	resolution, created
+//	archived  records with a value other than "false" are skipped
+//	epic      key or id of an epic record (a record of type Epic)
+//	parent    key or id of the parent record (an epic or a parent issue)
+//	group     name of a group, such as a Linear project; each becomes an epic
+var trackerFields = []string{"key", "id", "assignee", "resolution", "created", "archived", "epic", "parent", "group"}
+
+// trackerTimeLayouts are the date formats of tracker exports, such as Jira's
+// CSV export (by default like 21/Mar/24 3:04 PM) and XML export.
+var trackerTimeLayouts = []string{
+	"2/Jan/06 3:04 PM",
+	"2/Jan/2006 3:04 PM",
+	"2/Jan/06",
+	"2/Jan/2006",
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05.000-0700",
+	time.RFC3339,
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// trackerItem is an issue or epic planned from a tracker record.
+type trackerItem struct {
+	key    string        // Tracker ID, e.g. "WEB-12"
+	issue  *models.Issue // Set for issues
+	epic   *models.Epic  // Set for epics
+	parent int           // Index of the parent issue, or -1
+	epicOf int           // Index of the epic, or -1
+}
+
+// trackerSkip is a tracker record that is not imported.
+type trackerSkip struct {
+	Row    int    `json:"row"` // Record number, from 1
+	Key    string `json:"key,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// importFile creates the issues and epics of a tracker export in an existing
+// project, after showing the mapping and the skipped records and asking for
+// confirmation.
+func (source *trackerSource) importFile(filePath string, cmd *cobra.Command) error {
+	if overwrite, _ := cmd.Flags().GetBool("overwrite"); overwrite {
+		return fmt.Errorf("cli: --overwrite is not supported with --from %s", source.From)
+	}
+	if review, _ := cmd.Flags().GetBool("review"); review {
+		return fmt.Errorf("cli: --review is not supported with --from %s", source.From)
+	}
+
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	if _, err := loadQueryIndex(cmd.Context(), projectKey); err != nil {
+		return err
+	}
+	wf, err := loadWorkflow(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("cli: failed to read %s: %w", filePath, err)
+	}
+	records, err := source.Load(filePath, data)
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	if len(records.Rows) == 0 {
+		fmt.Fprintf(out, "No issues found in %s\n", filePath)
+		return nil
+	}
+
+	mapping := guessMigrateMapping(records, wf)
+	columns := source.guessColumns(records)
+	fieldMap, _ := cmd.Flags().GetString("field-map")
+	if err := applyTrackerFieldMap(fieldMap, records, &mapping, columns); err != nil {
+		return err
+	}
+	for _, row := range records.Rows {
+		for _, column := range []string{mapping.Fields["due"], columns["created"]} {
+			if value := row[column]; column != "" && value != "" {
+				row[column] = normalizeTrackerTime(value)
+			}
+		}
+	}
+	mapping.guessValues(records, wf)
+	statusMap, _ := cmd.Flags().GetString("status-map")
+	if err := applyTrackerStatusMap(statusMap, source.Name, &mapping, wf); err != nil {
+		return err
+	}
+
+	items, skipped, notes := planTrackerImport(cmd.Context(), records, &mapping, columns, wf)
+	printMigrateMapping(out, &mapping)
+	for _, field := range trackerFields {
+		if column := columns[field]; column != "" {
+			fmt.Fprintf(out, "  %s <- %s\n", field, column)
+		}
+	}
+	printTrackerPlan(out, items, skipped, notes)
+
+	if len(items) == 0 {
+		return nil
+	}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		return nil
+	}
+	assumeYes, _ := cmd.Flags().GetBool("yes")
+	p := &migratePrompter{scanner: bufio.NewScanner(cmd.InOrStdin()), out: cmd.ErrOrStderr(), assumeYes: assumeYes}
+	if !assumeYes && !p.confirm(fmt.Sprintf("Import %d records into %s?", len(items), projectKey), false) {
+		fmt.Fprintln(out, "Import cancelled")
+		return nil
+	}
+
+	issues, epics, err := createTrackerItems(cmd.Context(), projectKey, source.Name, items, wf)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(successOut(cmd), "Imported %d issues and %d epics into %s (%d records skipped)\n", issues, epics, projectKey, len(skipped))
+	warnQuota(projectKey, cmd)
+	return nil
+}
+
+// normalizeTrackerTime converts a tracker date to RFC 3339 in the configured
+// timezone, or returns it unchanged if it is not in a known format.
+func normalizeTrackerTime(value string) string {
+	for _, layout := range trackerTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, config.Location()); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
+	}
+	return value
+}
+
+// guessColumns maps the tracker fields to columns by name.
+func (source *trackerSource) guessColumns(records *migrateRecords) map[string]string {
+	columns := map[string]string{}
+	for _, field := range trackerFields {
+		for _, hint := range source.Columns[field] {
+			for _, column := range records.Columns {
+				if normalizeMigrateName(column) == hint {
+					columns[field] = column
+					break
+				}
+			}
+			if columns[field] != "" {
+				break
+			}
+		}
+	}
+	return columns
+}
+
+// applyTrackerFieldMap applies "field=Column" pairs from --field-map to the
+// guessed mapping. An empty column unmaps the field.
+func applyTrackerFieldMap(value string, records *migrateRecords, mapping *migrateMapping, columns map[string]string) error {
+	for _, pair := range splitList(value) {
+		field, name, ok := strings.Cut(pair, "=")
+		field, name = strings.ToLower(strings.TrimSpace(field)), strings.TrimSpace(name)
+		if !ok || (!slices.Contains(migrateFields, field) && !slices.Contains(trackerFields, field)) {
+			return invalidf("cli: invalid --field-map entry %q (use field=Column; fields: %s, %s)", pair, strings.Join(migrateFields, ", "), strings.Join(trackerFields, ", "))
+		}
+		column := ""
+		if name != "" {
+			if column = findMigrateColumn(records.Columns, name); column == "" {
+				return fmt.Errorf("cli: --field-map: no column %q in the export", name)
+			}
+		}
+		if slices.Contains(migrateFields, field) {
+			mapping.Fields[field] = column
+		} else {
+			columns[field] = column
+		}
+	}
+	return nil
+}
+
+// applyTrackerStatusMap applies "status=STATUS" pairs from --status-map on
+// top of the guessed status mapping.
+func applyTrackerStatusMap(value, tracker string, mapping *migrateMapping, wf *models.Workflow) error {
+	for _, pair := range splitList(value) {
+		from, status, ok := strings.Cut(pair, "=")
+		from, status = strings.TrimSpace(from), strings.ToUpper(strings.TrimSpace(status))
+		if !ok || from == "" {
+			return invalidf("cli: invalid --status-map entry %q (use %s status=STATUS)", pair, tracker)
+		}
+		if !wf.IsValidStatus(status) {
+			return invalidf("cli: invalid status %q in --status-map", status)
+		}
+		if mapping.Values == nil {
+			mapping.Values = map[string]map[string]string{}
+		}
+		if mapping.Values["status"] == nil {
+			mapping.Values["status"] = map[string]string{}
+		}
+		mapping.Values["status"][from] = status
+	}
+	return nil
+}
+
+// planTrackerImport converts tracker records to issues and epics, and
+// reconnects them: records of type Epic and groups become epics, issues get
+// the epic they link to (directly, through a parent that is an epic, or
+// through their group), and sub-tasks their parent. It returns the records
+// that cannot be imported, and notes on links that point outside the export.
+func planTrackerImport(ctx context.Context, records *migrateRecords, mapping *migrateMapping, columns map[string]string, wf *models.Workflow) ([]*trackerItem, []trackerSkip, []string) {
+	var items []*trackerItem
+	var skipped []trackerSkip
+	var notes []string
+	byRef := map[string]int{}  // Key or id -> item
+	groups := map[string]int{} // Group name -> epic item
+	links := map[int][2]string{}
+
+	for i, row := range records.Rows {
+		get := func(field string) string {
+			if column := columns[field]; column != "" {
+				return strings.TrimSpace(row[column])
+			}
+			return ""
+		}
+		key := get("key")
+		if archived := get("archived"); archived != "" && !strings.EqualFold(archived, "false") {
+			skipped = append(skipped, trackerSkip{Row: i + 1, Key: key, Reason: "archived"})
+			continue
+		}
+		issue, err := mapping.issue(ctx, row, wf)
+		if err != nil {
+			skipped = append(skipped, trackerSkip{Row: i + 1, Key: key, Reason: err.Error()})
+			continue
+		}
+
+		item := &trackerItem{key: key, parent: -1, epicOf: -1}
+		if normalizeMigrateName(row[mapping.Fields["type"]]) == "epic" {
+			item.epic = &models.Epic{Title: issue.Title, Description: issue.Description, Status: issue.Status}
+		} else {
+			if issue.Type == models.TypeEpic {
+				issue.Type = defaultMigrateType(wf)
+			}
+			if assignee := trackerUserName(get("assignee")); assignee != "" {
+				issue.Assignee = assignee
+			}
+			issue.Resolution = get("resolution")
+			if created := get("created"); created != "" {
+				if _, err := time.Parse(time.RFC3339, created); err == nil {
+					issue.CreatedAt = created
+				}
+			}
+			item.issue = issue
+			if group := get("group"); group != "" {
+				epic, ok := groups[group]
+				if !ok {
+					epic = len(items)
+					groups[group] = epic
+					items = append(items, &trackerItem{epic: &models.Epic{Title: group, Status: wf.DefaultStatus()}, parent: -1, epicOf: -1})
+				}
+				item.epicOf = epic
+			}
+		}
+
+		for _, ref := range []string{key, get("id")} {
+			if ref != "" {
+				byRef[ref] = len(items)
+			}
+		}
+		links[len(items)] = [2]string{get("epic"), get("parent")}
+		items = append(items, item)
+	}
+
+	for i, item := range items {
+		if item.issue == nil {
+			continue
+		}
+		epicRef, parentRef := links[i][0], links[i][1]
+		for _, ref := range []string{epicRef, parentRef} {
+			if ref == "" {
+				continue
+			}
+			target, ok := byRef[ref]
+			switch {
+			case !ok:
+				notes = append(notes, fmt.Sprintf("%s: linked issue %s is not in the export", item.label(), ref))
+			case items[target].epic != nil:
+				item.epicOf = target
+			case ref == parentRef && target != i:
+				item.parent = target
+			}
+		}
+	}
+	// Drop parents that would form a cycle
+	for i, item := range items {
+		seen := map[int]bool{}
+		for p := item.parent; p >= 0 && !seen[p]; p = items[p].parent {
+			if p == i {
+				notes = append(notes, fmt.Sprintf("%s: parent link dropped (cycle)", item.label()))
+				item.parent = -1
+				break
+			}
+			seen[p] = true
+		}
+	}
+	return items, skipped, notes
+}
+
+// label names an item in notes.
+func (item *trackerItem) label() string {
+	switch {
+	case item.key != "":
+		return item.key
+	case item.issue != nil:
+		return fmt.Sprintf("%q", item.issue.Title)
+	}
+	return fmt.Sprintf("%q", item.epic.Title)
+}
+
+// trackerUserName turns a user name such as "Alice Smith" into a buyruk user
+// name ("alice.smith").
+func trackerUserName(name string) string {
+	return strings.ToLower(strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == ','
+	}), "."))
+}
+
+// printTrackerPlan shows what the import will create and what it skips.
+func printTrackerPlan(w io.Writer, items []*trackerItem, skipped []trackerSkip, notes []string) {
+	styles := ui.NewStyles()
+	issues, epics, linked := 0, 0, 0
+	for _, item := range items {
+		switch {
+		case item.epic != nil:
+			epics++
+		case item.epicOf >= 0 || item.parent >= 0:
+			issues++
+			linked++
+		default:
+			issues++
+		}
+	}
+	fmt.Fprintf(w, "\nTo import: %d issues (%d linked to an epic or parent) and %d epics\n", issues, linked, epics)
+	if len(skipped) > 0 {
+		fmt.Fprintf(w, "%s (%d):\n", styles.Error("Skipped records"), len(skipped))
+		for _, skip := range skipped {
+			if skip.Key != "" {
+				fmt.Fprintf(w, "  record %d (%s): %s\n", skip.Row, skip.Key, skip.Reason)
+			} else {
+				fmt.Fprintf(w, "  record %d: %s\n", skip.Row, skip.Reason)
+			}
+		}
+	}
+	if len(notes) > 0 {
+		fmt.Fprintf(w, "%s:\n", styles.Label("Notes"))
+		for _, note := range notes {
+			fmt.Fprintf(w, "  %s\n", note)
+		}
+	}
+}
+
+// createTrackerItems creates the planned epics and issues, writing them and
+// the index together under the project lock. Each issue gets a comment
+// naming the record it was imported from.
+func createTrackerItems(ctx context.Context, projectKey, tracker string, items []*trackerItem, wf *models.Workflow) (issues, epics int, err error) {
+	cleanup, err := storage.AcquireLock(ctx, projectKey)
+	if err != nil {
+		return 0, 0, fmt.Errorf("cli: failed to acquire lock: %w", err)
+	}
+	defer cleanup()
+
+	indexPath, err := storage.ProjectIndexPath(ctx, projectKey)
+	if err != nil {
+		return 0, 0, fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(ctx, indexPath, &index); err != nil {
+		return 0, 0, fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+
+	next := maxRedirectSequence(ctx, projectKey)
+	for _, entry := range index.Issues {
+		if _, seq, err := models.ParseIssueID(entry.ID); err == nil && seq > next {
+			next = seq
+		}
+	}
+	next++
+	nextEpic, err := getNextEpicSequence(ctx, projectKey)
+	if err != nil {
+		return 0, 0, fmt.Errorf("cli: failed to get next epic sequence: %w", err)
+	}
+
+	// Assign all IDs first, as issues can come before their epic or parent
+	for _, item := range items {
+		if item.epic != nil {
+			item.epic.ID = models.GenerateEpicID(projectKey, nextEpic)
+			nextEpic++
+			continue
+		}
+		seq := storage.NextSequence(ctx, projectKey, next)
+		next = seq + 1
+		if item.issue.ID, err = index.NewIssueID(projectKey, seq, storage.Now(ctx)); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	now := storage.Timestamp(ctx)
+	var writes []fileWrite
+	for _, item := range items {
+		if item.epic == nil {
+			continue
+		}
+		epic := item.epic
+		epic.CreatedAt, epic.UpdatedAt = now, now
+		if err := epic.ValidateWithWorkflow(wf); err != nil {
+			return 0, 0, invalidf("cli: invalid epic from %s: %w", item.label(), err)
+		}
+		epicPath, err := storage.EpicPath(ctx, projectKey, epic.ID)
+		if err != nil {
+			return 0, 0, fmt.Errorf("cli: failed to resolve epic path: %w", err)
+		}
+		write, err := plannedJSONWrite(epicPath, epic)
+		if err != nil {
+			return 0, 0, err
+		}
+		writes = append(writes, write)
+		index.SetEpic(epic)
+		epics++
+	}
+	for _, item := range items {
+		if item.issue == nil {
+			continue
+		}
+		issue := item.issue
+		if item.epicOf >= 0 {
+			issue.EpicID = items[item.epicOf].epic.ID
+		}
+		if item.parent >= 0 && items[item.parent].issue != nil {
+			issue.ParentID = items[item.parent].issue.ID
+		}
+		if issue.CreatedAt == "" {
+			issue.CreatedAt = now
+		}
+		issue.UpdatedAt = now
+		if item.key != "" {
+			issue.AddComment(fmt.Sprintf("Imported from %s %s", tracker, item.key), now)
+		}
+		if err := index.AssignUID(issue, storage.Now(ctx)); err != nil {
+			return 0, 0, err
+		}
+
+		issuePath, err := storage.IssuePath(ctx, projectKey, issue.ID)
+		if err != nil {
+			return 0, 0, fmt.Errorf("cli: failed to resolve issue path: %w", err)
+		}
+		data, err := json.MarshalIndent(issue, "", "  ")
+		if err != nil {
+			return 0, 0, fmt.Errorf("cli: failed to marshal issue %s: %w", issue.ID, err)
+		}
+		writes = append(writes, fileWrite{path: issuePath, data: data})
+		index.AddIssue(issue)
+		issues++
+	}
+	index.UpdatedAt = now
+
+	write, err := plannedIndexWrite(indexPath, &index)
+	if err != nil {
+		return 0, 0, err
+	}
+	writes = append(writes, write)
+
+	if err := storage.BeginTransaction(ctx, projectKey, "import_tracker", map[string]interface{}{
+		"tracker": tracker,
+		"issues":  issues,
+		"epics":   epics,
+	}); err != nil {
+		return 0, 0, fmt.Errorf("cli: failed to begin transaction: %w", err)
+	}
+	if err := writeFiles(ctx, writes); err != nil {
+		storage.RollbackTransaction(ctx, projectKey)
+		return 0, 0, err
+	}
+	if err := storage.CommitTransaction(ctx, projectKey); err != nil {
+		return 0, 0, fmt.Errorf("cli: failed to commit transaction: %w", err)
+	}
+	return issues, epics, nil
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// trelloSource reads the JSON export of a Trello board.
+var trelloSource = &trackerSource{
+	From: "trello",
+	Name: "Trello",
+	Load: loadTrelloRecords,
+	Columns: map[string][]string{
+		"key":      {"cardid", "shortlink"},
+		"id":       {"id"},
+		"assignee": {"member", "members"},
+		"archived": {"archived", "closed"},
+	},
+}
+
+// trelloBoard is the part of a Trello board export that is imported.
+type trelloBoard struct {
+	Lists []struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		Closed bool   `json:"closed"`
+	} `json:"lists"`
+	Cards []struct {
+		ID        string   `json:"id"`
+		ShortLink string   `json:"shortLink"`
+		Name      string   `json:"name"`
+		Desc      string   `json:"desc"`
+		IDList    string   `json:"idList"`
+		Due       string   `json:"due"`
+		Closed    bool     `json:"closed"`
+		IDMembers []string `json:"idMembers"`
+		Labels    []struct {
+			Name  string `json:"name"`
+			Color string `json:"color"`
+		} `json:"labels"`
+	} `json:"cards"`
+	Members []struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"members"`
+	Checklists []trelloChecklist `json:"checklists"`
+}
+
+// trelloChecklist is a checklist of a Trello card.
+type trelloChecklist struct {
+	IDCard     string            `json:"idCard"`
+	Name       string            `json:"name"`
+	Pos        float64           `json:"pos"` // Position on the card
+	CheckItems []trelloCheckItem `json:"checkItems"`
+}
+
+// trelloCheckItem is an item of a Trello checklist.
+type trelloCheckItem struct {
+	Name  string  `json:"name"`
+	State string  `json:"state"` // "complete" or "incomplete"
+	Pos   float64 `json:"pos"`
+}
+
+// loadTrelloRecords reads a Trello board export as a record per card: the
+// card's list becomes its status, and its checklists are appended to the
+// description as task lists. Cards that are archived, or in an archived
+// list, are marked archived.
+func loadTrelloRecords(filePath string, data []byte) (*migrateRecords, error) {
+	var board trelloBoard
+	if err := json.Unmarshal(data, &board); err != nil {
+		return nil, invalidf("cli: invalid Trello export: %w", err)
+	}
+
+	lists := map[string]string{}
+	closedLists := map[string]bool{}
+	for _, list := range board.Lists {
+		lists[list.ID] = list.Name
+		closedLists[list.ID] = list.Closed
+	}
+	members := map[string]string{}
+	for _, member := range board.Members {
+		members[member.ID] = member.Username
+	}
+	slices.SortStableFunc(board.Checklists, func(a, b trelloChecklist) int { return cmp.Compare(a.Pos, b.Pos) })
+	checklists := map[string][]string{}
+	for _, checklist := range board.Checklists {
+		items := checklist.CheckItems
+		slices.SortStableFunc(items, func(a, b trelloCheckItem) int { return cmp.Compare(a.Pos, b.Pos) })
+		text := "### " + checklist.Name
+		for _, item := range items {
+			mark := " "
+			if item.State == "complete" {
+				mark = "x"
+			}
+			text += fmt.Sprintf("\n- [%s] %s", mark, item.Name)
+		}
+		checklists[checklist.IDCard] = append(checklists[checklist.IDCard], text)
+	}
+
+	rows := make([]map[string]string, 0, len(board.Cards))
+	for _, card := range board.Cards {
+		record := map[string]string{}
+		set := func(column, value string) {
+			if value = strings.TrimSpace(value); value != "" {
+				record[column] = value
+			}
+		}
+		set("Card ID", card.ShortLink)
+		set("ID", card.ID)
+		set("Name", card.Name)
+		set("Status", lists[card.IDList])
+		set("Description", strings.Join(append([]string{strings.TrimSpace(card.Desc)}, checklists[card.ID]...), "\n\n"))
+		set("Due", card.Due)
+		var labels []string
+		for _, label := range card.Labels {
+			if label.Name != "" {
+				labels = append(labels, label.Name)
+			} else if label.Color != "" {
+				labels = append(labels, label.Color)
+			}
+		}
+		set("Labels", strings.Join(labels, ","))
+		if len(card.IDMembers) > 0 {
+			set("Member", members[card.IDMembers[0]])
+		}
+		if card.Closed || closedLists[card.IDList] {
+			record["Archived"] = "true"
+		}
+		rows = append(rows, record)
+	}
+	return newMigrateRecords(rows, "Card ID", "Name"), nil
+}
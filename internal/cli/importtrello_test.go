@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+const trelloBoardExport = `{
+  "name": "Website",
+  "lists": [
+    {"id": "l1", "name": "Backlog", "closed": false},
+    {"id": "l2", "name": "Doing", "closed": false},
+    {"id": "l3", "name": "Shipped", "closed": false},
+    {"id": "l4", "name": "Old ideas", "closed": true}
+  ],
+  "members": [{"id": "m1", "username": "alice", "fullName": "Alice Smith"}],
+  "cards": [
+    {"id": "c1", "shortLink": "aB3x", "name": "New landing page", "desc": "Hero and pricing", "idList": "l2",
+     "due": "2024-04-15T12:00:00.000Z", "closed": false, "idMembers": ["m1"],
+     "labels": [{"name": "design", "color": "green"}, {"name": "", "color": "red"}]},
+    {"id": "c2", "shortLink": "cD4y", "name": "Fix footer links", "desc": "", "idList": "l3", "closed": false, "idMembers": [], "labels": []},
+    {"id": "c3", "shortLink": "eF5z", "name": "Dark mode", "desc": "", "idList": "l4", "closed": false, "idMembers": [], "labels": []},
+    {"id": "c4", "shortLink": "gH6w", "name": "Old banner", "desc": "", "idList": "l1", "closed": true, "idMembers": [], "labels": []}
+  ],
+  "checklists": [
+    {"id": "k2", "idCard": "c1", "name": "Launch", "pos": 2, "checkItems": [{"name": "Announce", "state": "incomplete", "pos": 1}]},
+    {"id": "k1", "idCard": "c1", "name": "Content", "pos": 1, "checkItems": [
+      {"name": "Pricing table", "state": "incomplete", "pos": 2},
+      {"name": "Hero copy", "state": "complete", "pos": 1}
+    ]}
+  ]
+}`
+
+func TestImportTrello(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	boardPath := filepath.Join(t.TempDir(), "board.json")
+	if err := os.WriteFile(boardPath, []byte(trelloBoardExport), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		cmd.SetIn(strings.NewReader(""))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	if _, err := run("project", "create", projectKey); err != nil {
+		t.Fatalf("project create failed: %v", err)
+	}
+
+	out, err := run("import", boardPath, "--from", "trello", "--project", projectKey, "--dry-run")
+	if err != nil {
+		t.Fatalf("import --dry-run failed: %v", err)
+	}
+	for _, want := range []string{
+		`status "Doing" -> DOING`,
+		`status "Shipped" -> TODO`,
+		"To import: 2 issues",
+		"record 3 (eF5z): archived",
+		"record 4 (gH6w): archived",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+
+	out, err = run("import", boardPath, "--from", "trello", "--project", projectKey, "--status-map", "Shipped=DONE", "--yes")
+	if err != nil || !strings.Contains(out, "Imported 2 issues and 0 epics into "+projectKey+" (2 records skipped)") {
+		t.Fatalf("import failed: %v\n%s", err, out)
+	}
+	landing, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-1")
+	wantDescription := "Hero and pricing\n\n### Content\n- [x] Hero copy\n- [ ] Pricing table\n\n### Launch\n- [ ] Announce"
+	if landing == nil || landing.Title != "New landing page" || landing.Status != models.StatusDOING || landing.Assignee != "alice" ||
+		landing.Due != "2024-04-15T12:00:00Z" || strings.Join(landing.Labels, ",") != "design,red" || landing.Description != wantDescription ||
+		len(landing.Comments) != 1 || landing.Comments[0].Body != "Imported from Trello aB3x" {
+		t.Errorf("Unexpected issue: %+v", landing)
+	}
+	if footer, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-2"); footer == nil || footer.Status != models.StatusDONE {
+		t.Errorf("Expected the shipped card done, got %+v", footer)
+	}
+}
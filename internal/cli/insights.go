@@ -0,0 +1,350 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// usageRecord is a usage log entry in progress.
+type usageRecord struct {
+	entry storage.UsageEntry
+	start time.Time
+}
+
+// unrecordedCommands are not recorded in the usage log: shell completion
+// runs on every Tab press and would drown out real use.
+var unrecordedCommands = []string{"__complete", "__completeNoDesc", "completion", "help"}
+
+// InsightsReport summarizes the usage log.
+type InsightsReport struct {
+	Enabled       bool           `json:"enabled"`            // Whether new commands are recorded
+	Since         string         `json:"since,omitempty"`    // Time of the first entry considered
+	Commands      int            `json:"commands"`           // Commands recorded
+	TopCommands   []CommandUsage `json:"top_commands"`       // Most used commands, most used first
+	BusiestHours  []HourUsage    `json:"busiest_hours"`      // Hours of the day with the most commands
+	Closed        int            `json:"closed"`             // Issues moved to a done status
+	ClosedPerWeek float64        `json:"closed_per_week"`    // Average over the weeks covered
+	Weeks         float64        `json:"weeks,omitempty"`    // Weeks covered, at least 1
+	Timezone      string         `json:"timezone,omitempty"` // Timezone of BusiestHours
+}
+
+// CommandUsage is how often a command ran and how long it took on average.
+type CommandUsage struct {
+	Command string `json:"command"`
+	Count   int    `json:"count"`
+	AvgMs   int64  `json:"avg_ms"`
+}
+
+// HourUsage is the number of commands run in one hour of the day.
+type HourUsage struct {
+	Hour  int `json:"hour"` // 0-23
+	Count int `json:"count"`
+}
+
+// NewInsightsCmd creates and returns the insights command.
+func NewInsightsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "insights",
+		Short: "Show your usage patterns from the local usage log",
+		Long: `Show your usage patterns: most used commands, busiest hours, and the
+average number of issues closed per week.
+
+The usage log is off by default. When turned on with
+buyruk config set usage_log true, every command appends its name, start time,
+duration, and the number of issues it closed to usage.jsonl in the config
+directory. No arguments, flags, or issue content are recorded, and nothing
+leaves the machine. Turn it off again with buyruk config set usage_log false,
+and delete what was recorded with --clear.`,
+		Example: `  buyruk insights
+  buyruk insights --since 30d --top 10
+  buyruk insights --clear`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return showInsights(cmd)
+		},
+	}
+
+	cmd.Flags().String("since", "", "Only consider commands run within this duration (e.g. 30d, 12w)")
+	cmd.Flags().Int("top", 5, "Number of commands and hours to show")
+	cmd.Flags().Bool("clear", false, "Delete the usage log")
+
+	return cmd
+}
+
+// startUsage starts recording a command when the usage log is on. It
+// returns nil when the command is not recorded.
+func startUsage(cmd *cobra.Command) *usageRecord {
+	cfg, err := config.Get()
+	if err != nil || !cfg.UsageLog {
+		return nil
+	}
+	name := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name())
+	name = strings.TrimSpace(name)
+	if name == "" || slices.Contains(unrecordedCommands, strings.Fields(name)[0]) {
+		return nil
+	}
+	return &usageRecord{
+		entry: storage.UsageEntry{At: storage.Timestamp(cmd.Context()), Command: name},
+		start: time.Now(),
+	}
+}
+
+// stopUsage stops recording the command in the usage log and returns its
+// entry in progress, or nil when it was not recorded.
+func (s *runState) stopUsage() *usageRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	usage := s.usage
+	s.usage = nil
+	return usage
+}
+
+// recordUsage appends the command being recorded to the usage log.
+func recordUsage(ctx context.Context) {
+	state := runStateFrom(ctx)
+	if state == nil {
+		return
+	}
+	usage := state.stopUsage()
+	if usage == nil {
+		return
+	}
+	usage.entry.DurationMs = time.Since(usage.start).Milliseconds()
+	storage.RecordUsage(ctx, usage.entry)
+}
+
+// issueWriteFilter is the storage write filter of every command: it refuses
+// writes to read-only projects, records issue history, stamps issues of projects in CRDT mode, rolls up epic
+// statuses, queues hook events, counts the issues a recorded command closes,
+// and notes the projects to write back to the storage backend.
+func issueWriteFilter(ctx context.Context, path string, data []byte) ([]byte, error) {
+	data, err := readOnlyWriteFilter(ctx, path, data)
+	if err == nil {
+		data, err = historyWriteFilter(ctx, path, data)
+	}
+	if err == nil {
+		data, err = attributionWriteFilter(ctx, path, data)
+	}
+	if err == nil {
+		data, err = crdtWriteFilter(ctx, path, data)
+	}
+	if err == nil {
+		data, err = epicRollupWriteFilter(ctx, path, data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	observeHookEvents(ctx, path, data)
+	if state := runStateFrom(ctx); state != nil {
+		state.observeIssueWrite(ctx, path, data)
+		if state.backend != nil {
+			state.backend.observeWrite(ctx, path)
+		}
+	}
+	return data, nil
+}
+
+// observeIssueWrite counts an issue write that moves the issue to a done
+// status in the command's usage log entry.
+func (s *runState) observeIssueWrite(ctx context.Context, path string, data []byte) {
+	s.mu.Lock()
+	recording := s.usage != nil
+	s.mu.Unlock()
+	if !recording || !closesIssue(ctx, path, data) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.usage != nil {
+		s.usage.entry.Closed++
+	}
+}
+
+// closesIssue reports whether an issue write moves the issue to a done status.
+func closesIssue(ctx context.Context, path string, data []byte) bool {
+	projectKey, ok := issueFileProject(ctx, path)
+	if !ok {
+		return false
+	}
+	var updated, old models.Issue
+	if err := json.Unmarshal(data, &updated); err != nil {
+		return false
+	}
+	wf, err := loadWorkflow(ctx, projectKey)
+	if err != nil || !wf.IsDoneStatus(updated.Status) {
+		return false
+	}
+	return storage.ReadJSON(ctx, path, &old) != nil || !wf.IsDoneStatus(old.Status)
+}
+
+// showInsights prints the usage report, or clears the usage log.
+func showInsights(cmd *cobra.Command) error {
+	out := cmd.OutOrStdout()
+	if clearLog, _ := cmd.Flags().GetBool("clear"); clearLog {
+		if err := storage.ResetUsage(); err != nil {
+			return fmt.Errorf("cli: %w", err)
+		}
+		if state := runStateFrom(cmd.Context()); state != nil {
+			state.stopUsage() // Don't start the new log with the clear itself
+		}
+		fmt.Fprintln(successOut(cmd), "Cleared the usage log")
+		return nil
+	}
+
+	since, hasSince, err := getDurationFlag(cmd, "since")
+	if err != nil {
+		return err
+	}
+	top, _ := cmd.Flags().GetInt("top")
+	if top < 1 {
+		return invalidf("cli: --top must be at least 1")
+	}
+	entries, err := storage.ReadUsage()
+	if err != nil {
+		return fmt.Errorf("cli: %w", err)
+	}
+	if hasSince {
+		cutoff := storage.Now(cmd.Context()).Add(-since)
+		entries = slices.DeleteFunc(entries, func(e storage.UsageEntry) bool {
+			t, err := time.Parse(time.RFC3339, e.At)
+			return err != nil || t.Before(cutoff)
+		})
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("cli: failed to load config: %w", err)
+	}
+	report := buildInsights(entries, top, config.Location(), storage.Now(cmd.Context()))
+	report.Enabled = cfg.UsageLog
+	if !report.Enabled {
+		fmt.Fprintln(cmd.ErrOrStderr(), "Note: the usage log is off (turn it on with 'buyruk config set usage_log true')")
+	}
+
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(out, report)
+	case config.DefaultFormatLSON:
+		renderInsightsLSON(report, out)
+	default:
+		renderInsights(report, out)
+	}
+	return nil
+}
+
+// buildInsights summarizes usage entries, oldest first.
+func buildInsights(entries []storage.UsageEntry, top int, loc *time.Location, now time.Time) *InsightsReport {
+	report := &InsightsReport{TopCommands: []CommandUsage{}, BusiestHours: []HourUsage{}, Timezone: loc.String()}
+	if len(entries) == 0 {
+		return report
+	}
+
+	commands := map[string]*CommandUsage{}
+	totalMs := map[string]int64{}
+	var hours [24]int
+	first := now
+	for _, entry := range entries {
+		t, err := time.Parse(time.RFC3339, entry.At)
+		if err != nil {
+			continue
+		}
+		if t.Before(first) {
+			first = t
+		}
+		report.Commands++
+		report.Closed += entry.Closed
+		hours[t.In(loc).Hour()]++
+		usage, ok := commands[entry.Command]
+		if !ok {
+			usage = &CommandUsage{Command: entry.Command}
+			commands[entry.Command] = usage
+		}
+		usage.Count++
+		totalMs[entry.Command] += entry.DurationMs
+	}
+	if report.Commands == 0 {
+		return report
+	}
+	report.Since = first.UTC().Format(time.RFC3339)
+
+	for name, usage := range commands {
+		usage.AvgMs = totalMs[name] / int64(usage.Count)
+		report.TopCommands = append(report.TopCommands, *usage)
+	}
+	slices.SortFunc(report.TopCommands, func(a, b CommandUsage) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Command, b.Command)
+	})
+	report.TopCommands = report.TopCommands[:min(top, len(report.TopCommands))]
+
+	for hour, count := range hours {
+		if count > 0 {
+			report.BusiestHours = append(report.BusiestHours, HourUsage{Hour: hour, Count: count})
+		}
+	}
+	slices.SortStableFunc(report.BusiestHours, func(a, b HourUsage) int { return b.Count - a.Count })
+	report.BusiestHours = report.BusiestHours[:min(top, len(report.BusiestHours))]
+
+	report.Weeks = math.Max(1, math.Round(now.Sub(first).Hours()/(24*7)*10)/10)
+	report.ClosedPerWeek = math.Round(float64(report.Closed)/report.Weeks*10) / 10
+	return report
+}
+
+// renderInsights renders the usage report in modern format.
+func renderInsights(report *InsightsReport, w io.Writer) {
+	styles := ui.NewStyles()
+	if report.Commands == 0 {
+		fmt.Fprintln(w, "No usage recorded yet")
+		return
+	}
+
+	fmt.Fprintf(w, "%s\n\n", styles.Title(fmt.Sprintf("Usage since %s (%d commands)", ui.FormatTime(report.Since, config.Location()), report.Commands)))
+	fmt.Fprintf(w, "%s:\n", styles.Label("Most used commands"))
+	width := 0
+	for _, usage := range report.TopCommands {
+		width = max(width, len(usage.Command))
+	}
+	for _, usage := range report.TopCommands {
+		fmt.Fprintf(w, "  %-*s %5d  avg %dms\n", width, usage.Command, usage.Count, usage.AvgMs)
+	}
+	fmt.Fprintf(w, "\n%s:\n", styles.Label("Busiest hours"))
+	for _, hour := range report.BusiestHours {
+		fmt.Fprintf(w, "  %02d:00-%02d:00 %5d\n", hour.Hour, (hour.Hour+1)%24, hour.Count)
+	}
+	fmt.Fprintf(w, "\n%s: %d (%.1f per week)\n", styles.Label("Issues closed"), report.Closed, report.ClosedPerWeek)
+}
+
+// renderInsightsLSON renders the usage report in L-SON format.
+func renderInsightsLSON(report *InsightsReport, w io.Writer) {
+	fmt.Fprintf(w, "@ENABLED: %t\n", report.Enabled)
+	fmt.Fprintf(w, "@COMMANDS: %d\n", report.Commands)
+	if report.Since != "" {
+		fmt.Fprintf(w, "@SINCE: %s\n", report.Since)
+	}
+	for _, usage := range report.TopCommands {
+		fmt.Fprintf(w, "@TOP: %s|%d|%dms\n", usage.Command, usage.Count, usage.AvgMs)
+	}
+	for _, hour := range report.BusiestHours {
+		fmt.Fprintf(w, "@HOUR: %02d|%d\n", hour.Hour, hour.Count)
+	}
+	fmt.Fprintf(w, "@CLOSED: %d\n", report.Closed)
+	fmt.Fprintf(w, "@CLOSED_PER_WEEK: %.1f\n", report.ClosedPerWeek)
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestInsights(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	// Keep the real config and usage log
+	originalCfg, _ := config.Get()
+	defer func() {
+		if originalCfg != nil {
+			config.Save(t.Context(), originalCfg)
+		}
+	}()
+	usagePath, _ := storage.UsagePath()
+	if original, err := os.ReadFile(usagePath); err == nil {
+		defer os.WriteFile(usagePath, original, 0644)
+	} else {
+		defer os.Remove(usagePath)
+	}
+	storage.ResetUsage()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	// Nothing is recorded while the usage log is off
+	if err := config.Set(t.Context(), "usage_log", "false"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run("project", "create", projectKey); err != nil {
+		t.Fatalf("project create failed: %v", err)
+	}
+	if entries, _ := storage.ReadUsage(); len(entries) != 0 {
+		t.Fatalf("Expected no usage entries, got %d", len(entries))
+	}
+
+	if err := config.Set(t.Context(), "usage_log", "true"); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"issue", "create", "--project", projectKey, "--title", "Login page", "--fixed-time", "2024-06-03T09:15:00Z"},
+		{"issue", "create", "--project", projectKey, "--title", "Audit log", "--fixed-time", "2024-06-03T09:45:00Z"},
+		{"issue", "update", projectKey + "-1", "--status", "DONE", "--fixed-time", "2024-06-05T14:00:00Z"},
+		{"issue", "update", projectKey + "-1", "--title", "Login", "--fixed-time", "2024-06-05T14:10:00Z"},
+		{"__complete", "issue", "view", ""},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	entries, _ := storage.ReadUsage()
+	if len(entries) != 4 {
+		t.Fatalf("Expected 4 usage entries, got %+v", entries)
+	}
+	if entries[0].Command != "issue create" || entries[2].Closed != 1 || entries[3].Closed != 0 {
+		t.Errorf("Unexpected usage entries: %+v", entries)
+	}
+
+	report := buildInsights(entries, 5, time.UTC, time.Date(2024, 6, 17, 9, 15, 0, 0, time.UTC))
+	if report.Commands != 4 || report.Weeks != 2 || report.ClosedPerWeek != 0.5 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	if len(report.TopCommands) != 2 || report.TopCommands[0].Command != "issue create" || report.TopCommands[0].Count != 2 {
+		t.Errorf("Unexpected top commands: %+v", report.TopCommands)
+	}
+	if len(report.BusiestHours) != 2 || report.BusiestHours[0].Hour != 9 || report.BusiestHours[0].Count != 2 {
+		t.Errorf("Unexpected busiest hours: %+v", report.BusiestHours)
+	}
+
+	out, err := run("insights", "--format", "json")
+	if err != nil {
+		t.Fatalf("insights failed: %v", err)
+	}
+	var parsed InsightsReport
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil || !parsed.Enabled || parsed.Closed != 1 {
+		t.Errorf("Unexpected insights output %q: %v", out, err)
+	}
+
+	if out, err := run("insights", "--clear"); err != nil || !strings.Contains(out, "Cleared") {
+		t.Fatalf("insights --clear failed: %v: %s", err, out)
+	}
+	if entries, _ := storage.ReadUsage(); len(entries) != 0 {
+		t.Errorf("Expected the usage log to be cleared, got %d entries", len(entries))
+	}
+}
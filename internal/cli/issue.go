@@ -2,15 +2,20 @@ package cli
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/buyruk-project/buyruk-cli/internal/config"
 	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/secret"
 	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -23,10 +28,25 @@ func NewIssueCmd() *cobra.Command {
 	}
 
 	cmd.AddCommand(NewIssueCreateCmd())
+	cmd.AddCommand(NewIssueViewCmd())
+	cmd.AddCommand(NewIssueCurrentCmd())
+	cmd.AddCommand(NewIssueFlowCmd())
 	cmd.AddCommand(NewIssueUpdateCmd())
 	cmd.AddCommand(NewIssueLinkCmd())
+	cmd.AddCommand(NewIssueSuggestLinksCmd())
 	cmd.AddCommand(NewIssuePRCmd())
+	cmd.AddCommand(NewIssueCommentCmd())
+	cmd.AddCommand(NewIssueAttachCmd())
+	cmd.AddCommand(NewIssueAttachmentsCmd())
+	cmd.AddCommand(NewIssueRemindCmd())
+	cmd.AddCommand(NewIssueSnoozeCmd())
+	cmd.AddCommand(NewIssueCheckCmd())
+	cmd.AddCommand(NewIssueLogCmd())
+	cmd.AddCommand(NewIssueRankCmd())
+	cmd.AddCommand(NewIssueMoveCmd())
+	cmd.AddCommand(NewIssueMergeCmd())
 	cmd.AddCommand(NewIssueDeleteCmd())
+	cmd.AddCommand(NewIssueRestoreCmd())
 
 	return cmd
 }
@@ -36,7 +56,13 @@ func NewIssueCreateCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create a new issue",
-		Long:  "Create a new issue in the project. Only title is required.",
+		Long: `Create a new issue in the project. Only title is required.
+
+The type, priority, labels, and assignee of an issue created without those
+flags default to the project's defaults in the config, if any:
+
+  buyruk config set project.OPS.priority HIGH
+  buyruk config set project.OPS.labels ops,oncall`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return createIssue(cmd)
 		},
@@ -45,10 +71,25 @@ func NewIssueCreateCmd() *cobra.Command {
 	cmd.Flags().String("id", "", "Issue ID (optional, auto-generated if not provided)")
 	cmd.Flags().String("type", "task", "Issue type (task or bug, default: task)")
 	cmd.Flags().String("title", "", "Issue title (required)")
-	cmd.Flags().String("status", "TODO", "Issue status (TODO, DOING, DONE, default: TODO)")
-	cmd.Flags().String("priority", "", "Issue priority (LOW, MEDIUM, HIGH, CRITICAL)")
+	cmd.Flags().String("status", "TODO", "Issue status (TODO, DOING, DONE, or a project workflow status; default: first workflow status)")
+	cmd.Flags().String("priority", "", "Issue priority (LOW, MEDIUM, HIGH, CRITICAL, or a project workflow priority)")
 	cmd.Flags().String("description", "", "Issue description (Markdown)")
 	cmd.Flags().String("epic", "", "Link to epic ID")
+	cmd.Flags().String("fix-version", "", "Milestone the issue ships in (e.g. v1.2)")
+	cmd.Flags().String("parent", "", "Parent issue ID (creates a subtask)")
+	cmd.Flags().String("due", "", "Due date (e.g. 2024-06-01, tomorrow, next friday, 3d, eod)")
+	cmd.Flags().String("estimate", "", "Effort estimate in working time (e.g. 4h, 2d, 1w; a day is 8 hours)")
+	cmd.Flags().Int("points", 0, "Story points")
+	cmd.Flags().String("labels", "", "Comma-separated list of labels")
+	cmd.Flags().String("components", "", "Comma-separated list of registered components (see buyruk component list)")
+	cmd.Flags().String("assignee", "", "User to assign the issue to (default: the owner of its first component)")
+	cmd.Flags().Bool("sensitive", false, "Encrypt the description and comments at rest (requires buyruk unlock)")
+	cmd.Flags().String("key", secret.DefaultKey, "Project key to encrypt a sensitive issue with")
+	cmd.Flags().Bool("skip-rules", false, "Create the issue even if it breaks the project's validation rules")
+	cmd.RegisterFlagCompletionFunc("epic", completeEpicIDs)
+	cmd.RegisterFlagCompletionFunc("fix-version", completeMilestoneIDs)
+	cmd.RegisterFlagCompletionFunc("components", completeComponentNames)
+	cmd.RegisterFlagCompletionFunc("parent", completeIssueIDs)
 
 	return cmd
 }
@@ -62,75 +103,163 @@ func createIssue(cmd *cobra.Command) error {
 	}
 
 	// Verify project exists
-	projectDir, err := storage.ProjectDir(projectKey)
+	projectDir, err := storage.ProjectDir(cmd.Context(), projectKey)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve project directory: %w", err)
 	}
 
 	if _, err := os.Stat(projectDir); os.IsNotExist(err) {
-		return fmt.Errorf("cli: project %q does not exist", projectKey)
+		return notFoundf("cli: project %q does not exist", projectKey)
 	}
 
 	// Get title (required)
 	title, _ := cmd.Flags().GetString("title")
 	if title == "" {
-		return fmt.Errorf("cli: title is required")
+		return invalidf("cli: title is required")
 	}
 
 	// Get ID (optional, auto-generate if not provided)
 	issueID, _ := cmd.Flags().GetString("id")
+	idScheme, err := projectIDScheme(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	nextSeq := 0
 	if issueID == "" {
-		nextSeq, err := getNextIssueSequence(projectKey)
+		nextSeq, err = getNextIssueSequence(cmd.Context(), projectKey)
 		if err != nil {
 			return fmt.Errorf("cli: failed to get next issue sequence: %w", err)
 		}
-		issueID = models.GenerateIssueID(projectKey, nextSeq)
+		if issueID, err = models.FormatIssueID(idScheme, projectKey, nextSeq, storage.Now(cmd.Context())); err != nil {
+			return err
+		}
 	} else {
 		// Validate provided ID matches project key
-		parsedKey, _, err := models.ParseIssueID(issueID)
+		parsedKey, _, err := parseIssueID(cmd.Context(), issueID)
 		if err != nil {
-			return fmt.Errorf("cli: invalid issue ID format: %w", err)
+			return invalidf("cli: invalid issue ID format: %w", err)
 		}
 		if parsedKey != projectKey {
 			return fmt.Errorf("cli: issue ID %q does not match project key %q", issueID, projectKey)
 		}
 	}
 
-	// Get type (default: task)
+	// Load project workflow (custom statuses, types, priorities)
+	wf, err := loadWorkflow(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+
+	// Omitted flags take the project's defaults from the config
+	defaults := config.LookupProjectDefaults(projectKey)
+	if err := checkProjectDefaults(cmd, projectKey, defaults, wf); err != nil {
+		return err
+	}
+
+	// Get type (default: the project default, task, or the first workflow
+	// type if task is not allowed)
 	issueType, _ := cmd.Flags().GetString("type")
+	if !cmd.Flags().Changed("type") && defaults.Type != "" {
+		issueType = defaults.Type
+	}
 	if issueType == "" {
 		issueType = models.TypeTask
 	}
+	if !cmd.Flags().Changed("type") && !wf.IsValidType(issueType) {
+		issueType = wf.TypeList()[0]
+	}
 
-	// Get status (default: TODO)
+	// Get status (default: first workflow status, TODO for the default workflow)
 	status, _ := cmd.Flags().GetString("status")
-	if status == "" {
-		status = models.StatusTODO
+	if status == "" || !cmd.Flags().Changed("status") {
+		status = wf.DefaultStatus()
 	}
 
 	// Get optional fields
 	priority, _ := cmd.Flags().GetString("priority")
+	if !cmd.Flags().Changed("priority") {
+		priority = defaults.Priority
+	}
 	description, _ := cmd.Flags().GetString("description")
 	epicID, _ := cmd.Flags().GetString("epic")
+	assignee, _ := cmd.Flags().GetString("assignee")
 
 	// Validate epic ID format if provided
 	if epicID != "" {
-		if err := validateEpicID(epicID); err != nil {
-			return fmt.Errorf("cli: invalid epic ID format: %w", err)
+		if err := validateProjectEpicID(projectKey, epicID); err != nil {
+			return err
 		}
 		// Validate epic exists
-		epicPath, err := storage.EpicPath(projectKey, epicID)
+		epicPath, err := storage.EpicPath(cmd.Context(), projectKey, epicID)
 		if err != nil {
 			return fmt.Errorf("cli: failed to resolve epic path: %w", err)
 		}
 		if _, err := os.Stat(epicPath); err != nil {
 			if os.IsNotExist(err) {
-				return fmt.Errorf("cli: epic %q not found", epicID)
+				return notFoundf("cli: epic %q not found", epicID)
 			}
 			return fmt.Errorf("cli: failed to stat epic path %q: %w", epicPath, err)
 		}
 	}
 
+	// Validate fix version if provided
+	fixVersion, _ := cmd.Flags().GetString("fix-version")
+	if fixVersion != "" {
+		if err := validateFixVersion(cmd.Context(), projectKey, fixVersion); err != nil {
+			return err
+		}
+	}
+
+	// Validate parent if provided
+	parentID, _ := cmd.Flags().GetString("parent")
+	if parentID != "" {
+		if err := validateParent(cmd.Context(), projectKey, issueID, parentID); err != nil {
+			return err
+		}
+	}
+
+	// Parse due date if provided
+	due := ""
+	if t, ok, err := getTimeFlag(cmd, "due"); err != nil {
+		return err
+	} else if ok {
+		due = t.UTC().Format(time.RFC3339)
+	}
+
+	estimate, _, err := getEstimateFlag(cmd, "estimate")
+	if err != nil {
+		return err
+	}
+	points, _ := cmd.Flags().GetInt("points")
+
+	labelsValue, _ := cmd.Flags().GetString("labels")
+	labels := splitList(labelsValue)
+	if !cmd.Flags().Changed("labels") {
+		labels = slices.Clone(defaults.Labels)
+	}
+	if len(labels) == 0 {
+		labels = nil
+	}
+
+	// Validate components against the registry; the first one's owner is the
+	// default assignee
+	componentsValue, _ := cmd.Flags().GetString("components")
+	components := splitList(componentsValue)
+	if len(components) == 0 {
+		components = nil
+	} else {
+		registry, err := validateComponents(cmd.Context(), projectKey, components)
+		if err != nil {
+			return err
+		}
+		if assignee == "" {
+			assignee = registry.Find(components[0]).Owner
+		}
+	}
+	if assignee == "" && !cmd.Flags().Changed("assignee") {
+		assignee = defaults.Assignee
+	}
+
 	// Create issue
 	issue := &models.Issue{
 		ID:          issueID,
@@ -138,73 +267,162 @@ func createIssue(cmd *cobra.Command) error {
 		Title:       title,
 		Status:      status,
 		Priority:    priority,
+		Assignee:    assignee,
 		Description: description,
 		EpicID:      epicID,
-		CreatedAt:   time.Now().Format(time.RFC3339),
-		UpdatedAt:   time.Now().Format(time.RFC3339),
+		FixVersion:  fixVersion,
+		ParentID:    parentID,
+		Due:         due,
+		Estimate:    estimate,
+		Points:      points,
+		Labels:      labels,
+		Components:  components,
+		CreatedAt:   storage.Timestamp(cmd.Context()),
+		UpdatedAt:   storage.Timestamp(cmd.Context()),
 	}
 
-	// Validate issue
-	if err := issue.Validate(); err != nil {
-		return fmt.Errorf("cli: invalid issue: %w", err)
+	// Validate issue against the project workflow and its rules
+	if err := issue.ValidateWithWorkflow(wf); err != nil {
+		return invalidf("cli: invalid issue: %w", err)
+	}
+	if err := checkRules(cmd, "the new issue", wf.RuleViolations(issue)); err != nil {
+		return err
 	}
 
-	// Write issue file atomically (fails if file already exists)
-	issuePath, err := storage.IssuePath(projectKey, issueID)
-	if err != nil {
-		return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+	if sensitive, _ := cmd.Flags().GetBool("sensitive"); sensitive {
+		keyName, _ := cmd.Flags().GetString("key")
+		if err := setSensitive(cmd.Context(), issue, projectKey, keyName, true); err != nil {
+			return err
+		}
+	}
+
+	// Give the issue a uid if the project uses ULIDs for sync identity
+	if err := assignIssueUID(cmd.Context(), projectKey, issue); err != nil {
+		return err
+	}
+
+	// Refuse read-only projects before reserving a sequence number, which
+	// writes the project's sequence counter
+	if err := checkProjectWritable(cmd.Context(), projectKey); err != nil {
+		return err
 	}
 
-	if err := storage.WriteJSONAtomicCreate(issuePath, issue); err != nil {
-		if strings.Contains(err.Error(), "already exists") {
-			return fmt.Errorf("cli: issue %q already exists", issueID)
+	// Reserve the generated ID now that the issue is valid, so concurrent
+	// creates get different sequence numbers
+	if nextSeq != 0 {
+		nextSeq, err = storage.AllocateSequence(cmd.Context(), projectKey, nextSeq)
+		if err != nil {
+			return fmt.Errorf("cli: failed to allocate issue sequence: %w", err)
 		}
-		return fmt.Errorf("cli: failed to create issue file: %w", err)
+		if issueID, err = models.FormatIssueID(idScheme, projectKey, nextSeq, storage.Now(cmd.Context())); err != nil {
+			return err
+		}
+		issue.ID = issueID
 	}
 
-	// Update project index atomically (read-modify-write with locking)
-	indexPath, err := storage.ProjectIndexPath(projectKey)
+	indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve index path: %w", err)
 	}
 
+	// Write the issue file and update the index under one lock, so other
+	// commands never see the issue without its index entry
+	tx, err := storage.BeginProjectTx(cmd.Context(), projectKey, "create_issue", nil)
+	if err != nil {
+		return fmt.Errorf("cli: failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Create the issue file (fails if it already exists). Issues written
+	// without the sequence counter (imports, explicit --id) may already use
+	// the reserved ID, so generated IDs move on to the next free sequence number.
+	for attempt := 0; ; attempt++ {
+		issuePath, err := storage.IssuePath(cmd.Context(), projectKey, issueID)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+		}
+		err = tx.CreateJSON(issuePath, issue)
+		if err == nil {
+			break
+		}
+		if !strings.Contains(err.Error(), "already exists") {
+			return fmt.Errorf("cli: failed to create issue file: %w", err)
+		}
+		if nextSeq == 0 || attempt >= maxCreateAttempts {
+			return conflictf("cli: issue %q already exists", issueID)
+		}
+		nextSeq++
+		if issueID, err = models.FormatIssueID(idScheme, projectKey, nextSeq, storage.Now(cmd.Context())); err != nil {
+			return err
+		}
+		issue.ID = issueID
+	}
+
 	var index models.ProjectIndex
-	if err := storage.UpdateJSONAtomic(indexPath, &index, func(v interface{}) error {
+	if err := tx.UpdateIndex(indexPath, &index, func(v interface{}) error {
 		idx := v.(*models.ProjectIndex)
 		idx.AddIssue(issue)
-		idx.UpdatedAt = time.Now().Format(time.RFC3339)
+		idx.UpdatedAt = storage.Timestamp(cmd.Context())
 		return nil
 	}); err != nil {
 		return fmt.Errorf("cli: failed to update project index: %w", err)
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("cli: failed to commit transaction: %w", err)
+	}
+
 	// Success message
-	out := cmd.OutOrStdout()
+	out := successOut(cmd)
 	fmt.Fprintf(out, "Created issue %q\n", issueID)
+	warnAway(assignee, cmd)
+
+	// Warn when the project grows past the configured soft limits
+	warnQuota(projectKey, cmd)
 
 	return nil
 }
 
+// checkProjectDefaults checks that the type and priority defaults a create
+// uses are allowed by the project's workflow, which may have changed since
+// they were set.
+func checkProjectDefaults(cmd *cobra.Command, projectKey string, defaults config.IssueDefaults, wf *models.Workflow) error {
+	if defaults.Type != "" && !cmd.Flags().Changed("type") && !wf.IsValidType(defaults.Type) {
+		return invalidf("cli: default type %q of %s is not in its workflow (allowed: %s; change it with `buyruk config set %s%s.type <type>`)",
+			defaults.Type, projectKey, strings.Join(wf.TypeList(), ", "), config.ProjectKeyPrefix, projectKey)
+	}
+	if defaults.Priority != "" && !cmd.Flags().Changed("priority") && !wf.IsValidPriority(defaults.Priority) {
+		return invalidf("cli: default priority %q of %s is not in its workflow (allowed: %s; change it with `buyruk config set %s%s.priority <priority>`)",
+			defaults.Priority, projectKey, strings.Join(wf.PriorityList(), ", "), config.ProjectKeyPrefix, projectKey)
+	}
+	return nil
+}
+
+// maxCreateAttempts is how many following IDs issue create tries when
+// concurrent creates take the generated one.
+const maxCreateAttempts = 100
+
 // getNextIssueSequence returns the next sequence number for an issue in the project.
 // It parses all existing issue IDs to find the highest sequence number and returns the next one.
-func getNextIssueSequence(projectKey string) (int, error) {
+// The number is only reserved, with storage.AllocateSequence, when the issue is written.
+func getNextIssueSequence(ctx context.Context, projectKey string) (int, error) {
 	// Load project index
-	indexPath, err := storage.ProjectIndexPath(projectKey)
+	indexPath, err := storage.ProjectIndexPath(ctx, projectKey)
 	if err != nil {
 		return 0, fmt.Errorf("cli: failed to resolve index path: %w", err)
 	}
 
 	var index models.ProjectIndex
-	if err := storage.ReadJSON(indexPath, &index); err != nil {
+	if err := storage.ReadIndex(ctx, indexPath, &index); err != nil {
 		// If index doesn't exist, start from 1
-		if os.IsNotExist(err) {
+		if errors.Is(err, os.ErrNotExist) {
 			return 1, nil
 		}
 		return 0, fmt.Errorf("cli: failed to load project index: %w", err)
 	}
 
-	// Find the highest sequence number
-	maxSeq := 0
+	// Find the highest sequence number, including IDs of issues moved elsewhere
+	maxSeq := maxRedirectSequence(ctx, projectKey)
 	for _, entry := range index.Issues {
 		_, seq, err := models.ParseIssueID(entry.ID)
 		if err != nil {
@@ -216,19 +434,46 @@ func getNextIssueSequence(projectKey string) (int, error) {
 		}
 	}
 
-	// Return next sequence number
 	return maxSeq + 1, nil
 }
 
+// NewIssueViewCmd creates and returns the issue view command.
+// It is equivalent to the top-level view command.
+func NewIssueViewCmd() *cobra.Command {
+	cmd := NewViewCmd()
+	cmd.Short = "View issue details"
+	return cmd
+}
+
 // NewIssueUpdateCmd creates and returns the issue update command.
 func NewIssueUpdateCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "update <id>",
+		Use:   "update <id> [field=value...]",
 		Short: "Update an issue",
-		Long:  "Update fields of an existing issue",
-		Args:  cobra.ExactArgs(1),
+		Long: `Update fields of an existing issue, with flags or field=value pairs.
+
+Pairs set the field of the flag with the same name (title, type, status,
+priority, description, epic, fix-version, parent, due, estimate, resolution,
+labels, components, assignee). For labels and components, += adds and -=
+removes comma-separated values.
+A field cannot be given both as a flag and as a pair.
+
+--show-diff prints the old and new value of each field the update changed.
+With --format json or yaml, the update prints the changed fields instead of
+a message.`,
+		Example: `  buyruk issue update CORE-3 --status DOING --priority HIGH --show-diff
+  buyruk issue update CORE-3 status=DOING priority=HIGH labels+=infra labels-=ui
+  buyruk issue update CORE-3 "title=Fix login redirect" due=none`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeIssueArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveIssueArgs(cmd, args[:1]); err != nil {
+				return err
+			}
 			issueID := args[0]
+			if err := setUpdatePairs(cmd, args[1:]); err != nil {
+				return err
+			}
 			return updateIssue(issueID, cmd)
 		},
 	}
@@ -239,203 +484,651 @@ func NewIssueUpdateCmd() *cobra.Command {
 	cmd.Flags().String("priority", "", "Update priority")
 	cmd.Flags().String("description", "", "Update description")
 	cmd.Flags().String("epic", "", "Update epic link")
+	cmd.Flags().String("fix-version", "", "Update the milestone the issue ships in (\"none\" clears it)")
+	cmd.Flags().String("parent", "", "Update parent issue (\"none\" makes it a top-level issue)")
+	cmd.Flags().String("due", "", "Update due date (e.g. 2024-06-01, next friday, 3d; \"none\" clears it)")
+	cmd.Flags().String("estimate", "", "Update effort estimate (e.g. 4h, 2d; \"none\" clears it)")
+	cmd.Flags().Int("points", 0, "Update story points (0 clears them)")
+	cmd.Flags().String("resolution", "", "Set resolution (e.g. when closing an issue)")
+	cmd.Flags().String("labels", "", "Replace labels with a comma-separated list (\"none\" clears them)")
+	cmd.Flags().String("assignee", "", "Assign the issue to a user (\"none\" unassigns it)")
+	cmd.Flags().StringArray("add-label", nil, "Add a label (repeatable)")
+	cmd.Flags().StringArray("remove-label", nil, "Remove a label (repeatable)")
+	cmd.Flags().String("components", "", "Replace components with a comma-separated list (\"none\" clears them)")
+	cmd.Flags().StringArray("add-component", nil, "Add a registered component (repeatable)")
+	cmd.Flags().StringArray("remove-component", nil, "Remove a component (repeatable)")
+	cmd.Flags().Bool("sensitive", false, "Encrypt the description and comments at rest (--sensitive=false decrypts them)")
+	cmd.Flags().String("key", secret.DefaultKey, "Project key to encrypt a sensitive issue with")
+	cmd.Flags().Bool("force", false, "Bypass workflow transition rules")
+	cmd.Flags().Bool("skip-rules", false, "Update the issue even if it breaks the project's validation rules")
+	cmd.Flags().Bool("show-diff", false, "Show the old and new value of each changed field")
+	cmd.RegisterFlagCompletionFunc("epic", completeEpicIDs)
+	cmd.RegisterFlagCompletionFunc("fix-version", completeMilestoneIDs)
+	cmd.RegisterFlagCompletionFunc("components", completeComponentNames)
+	cmd.RegisterFlagCompletionFunc("add-component", completeComponentNames)
+	cmd.RegisterFlagCompletionFunc("parent", completeIssueIDs)
 
 	return cmd
 }
 
+// updateFields lists the fields issue update accepts as field=value pairs.
+// Each is set through the flag with the same name.
+var updateFields = []string{
+	"title", "type", "status", "priority", "description", "epic", "fix-version",
+	"parent", "due", "estimate", "points", "resolution", "labels", "components",
+	"assignee",
+}
+
+// updateListFlags are the flags that add to and remove from the list fields
+// of issue update, for field+= and field-= pairs.
+var updateListFlags = map[string][2]string{
+	"labels":     {"add-label", "remove-label"},
+	"components": {"add-component", "remove-component"},
+}
+
+// setUpdatePairs sets the update flags from field=value pairs. labels+=a,b and
+// labels-=a,b add and remove labels through --add-label and --remove-label.
+func setUpdatePairs(cmd *cobra.Command, pairs []string) error {
+	flags := cmd.Flags()
+	seen := make(map[string]bool)
+	for _, pair := range pairs {
+		field, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return invalidf("cli: invalid update %q (expected field=value, field+=value, or field-=value)", pair)
+		}
+		field = strings.ToLower(strings.TrimSpace(field))
+
+		op := ""
+		if f, ok := strings.CutSuffix(field, "+"); ok {
+			field, op = strings.TrimSpace(f), "+"
+		} else if f, ok := strings.CutSuffix(field, "-"); ok {
+			field, op = strings.TrimSpace(f), "-"
+		}
+		if !slices.Contains(updateFields, field) {
+			return invalidf("cli: unknown update field %q (supported: %s)", field, strings.Join(updateFields, ", "))
+		}
+
+		if op != "" {
+			listFlags, ok := updateListFlags[field]
+			if !ok {
+				return fmt.Errorf("cli: %s%s= is not supported (only labels and components can be added to or removed from)", field, op)
+			}
+			flagName := listFlags[0]
+			if op == "-" {
+				flagName = listFlags[1]
+			}
+			for _, item := range splitList(value) {
+				if err := flags.Set(flagName, item); err != nil {
+					return invalidf("cli: invalid update %q: %w", pair, err)
+				}
+			}
+			continue
+		}
+
+		if seen[field] {
+			return fmt.Errorf("cli: %s is given more than once", field)
+		}
+		if flags.Changed(field) {
+			return fmt.Errorf("cli: %s is given both as --%s and as %s=", field, field, field)
+		}
+		if value == "" {
+			return fmt.Errorf("cli: %s= needs a value", field)
+		}
+		seen[field] = true
+		if err := flags.Set(field, value); err != nil {
+			return invalidf("cli: invalid update %q: %w", pair, err)
+		}
+	}
+	return nil
+}
+
 // updateIssue updates an existing issue.
 func updateIssue(issueID string, cmd *cobra.Command) error {
 	// Parse issue ID
-	projectKey, _, err := models.ParseIssueID(issueID)
+	projectKey, _, err := parseIssueID(cmd.Context(), issueID)
 	if err != nil {
-		return fmt.Errorf("cli: invalid issue ID %q: %w", issueID, err)
+		return invalidf("cli: invalid issue ID %q: %w", issueID, err)
+	}
+
+	// Load project workflow (custom statuses, types, priorities)
+	wf, err := loadWorkflow(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+
+	force, _ := cmd.Flags().GetBool("force")
+
+	// Validate new parent before taking the lock ("none" clears it)
+	parentID, _ := cmd.Flags().GetString("parent")
+	if parentID != "" && parentID != "none" {
+		if err := validateParent(cmd.Context(), projectKey, issueID, parentID); err != nil {
+			return err
+		}
+	}
+
+	// Validate new fix version before taking the lock ("none" clears it)
+	fixVersion, _ := cmd.Flags().GetString("fix-version")
+	if fixVersion != "" && fixVersion != "none" {
+		if err := validateFixVersion(cmd.Context(), projectKey, fixVersion); err != nil {
+			return err
+		}
+	}
+
+	// Validate new components before taking the lock ("none" clears them)
+	componentsValue, _ := cmd.Flags().GetString("components")
+	addComponents, _ := cmd.Flags().GetStringArray("add-component")
+	removeComponents, _ := cmd.Flags().GetStringArray("remove-component")
+	newComponents := addComponents
+	if componentsValue != "none" {
+		newComponents = append(splitList(componentsValue), addComponents...)
+	}
+	if len(newComponents) > 0 {
+		if _, err := validateComponents(cmd.Context(), projectKey, newComponents); err != nil {
+			return err
+		}
+	}
+
+	// Parse due date before taking the lock ("none" clears it)
+	dueValue, _ := cmd.Flags().GetString("due")
+	due := ""
+	if dueValue != "" && dueValue != "none" {
+		t, _, err := getTimeFlag(cmd, "due")
+		if err != nil {
+			return err
+		}
+		due = t.UTC().Format(time.RFC3339)
+	}
+
+	// Parse estimate before taking the lock ("none" clears it)
+	estimateValue, _ := cmd.Flags().GetString("estimate")
+	estimate := ""
+	if estimateValue != "" && estimateValue != "none" {
+		if estimate, _, err = getEstimateFlag(cmd, "estimate"); err != nil {
+			return err
+		}
 	}
 
 	// Load issue atomically (read-modify-write)
-	issuePath, err := storage.IssuePath(projectKey, issueID)
+	issuePath, err := storage.IssuePath(cmd.Context(), projectKey, issueID)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve issue path: %w", err)
 	}
 
 	var issue models.Issue
-	if err := storage.UpdateJSONAtomic(issuePath, &issue, func(v interface{}) error {
+	var before []byte                      // The issue as stored, for the diff
+	var brokenRules []models.RuleViolation // Rules the issue broke before the update and still breaks
+	var wipWarning string                  // The new status is over its WIP limit
+	if err := storage.UpdateJSONAtomic(cmd.Context(), issuePath, &issue, func(v interface{}) error {
 		iss := v.(*models.Issue)
 
 		// Check if issue exists (ID should match if file existed)
 		if iss.ID == "" || iss.ID != issueID {
-			return fmt.Errorf("cli: issue %q not found", issueID)
+			return notFoundf("cli: issue %q not found", issueID)
 		}
+		before, _ = json.Marshal(iss)
+		previousViolations := wf.RuleViolations(iss)
 
 		// Update fields from flags
+		if dueValue != "" {
+			iss.Due = due
+		}
+
+		if estimateValue != "" {
+			iss.Estimate = estimate
+		}
+
+		if cmd.Flags().Changed("points") {
+			iss.Points, _ = cmd.Flags().GetInt("points")
+		}
+
+		if fixVersion == "none" {
+			iss.FixVersion = ""
+		} else if fixVersion != "" {
+			iss.FixVersion = fixVersion
+		}
+
+		if parentID == "none" {
+			iss.ParentID = ""
+		} else if parentID != "" {
+			iss.ParentID = parentID
+		}
+
 		if title, _ := cmd.Flags().GetString("title"); title != "" {
 			iss.Title = title
 		}
 
 		if issueType, _ := cmd.Flags().GetString("type"); issueType != "" {
-			if !models.IsValidType(issueType) {
-				return fmt.Errorf("cli: invalid type %q", issueType)
+			if !wf.IsValidType(issueType) {
+				return invalidf("cli: invalid type %q (allowed: %s)", issueType, strings.Join(wf.TypeList(), ", "))
 			}
 			iss.Type = issueType
 		}
 
+		previousStatus := iss.Status
 		if status, _ := cmd.Flags().GetString("status"); status != "" {
-			if !models.IsValidStatus(status) {
-				return fmt.Errorf("cli: invalid status %q", status)
+			if !wf.IsValidStatus(status) {
+				return invalidf("cli: invalid status %q (allowed: %s)", status, strings.Join(wf.StatusList(), ", "))
+			}
+			if !force && !wf.CanTransition(iss.Status, status) {
+				next, _ := wf.NextStatuses(iss.Status)
+				return invalidf("cli: cannot move %s from %s to %s (allowed next: %s; use --force to override)",
+					issueID, iss.Status, status, formatStatusList(next))
 			}
 			iss.Status = status
 		}
 
+		if resolution, _ := cmd.Flags().GetString("resolution"); resolution != "" {
+			iss.Resolution = resolution
+		}
+
+		if labels, _ := cmd.Flags().GetString("labels"); labels == "none" {
+			iss.Labels = nil
+		} else if labels != "" {
+			iss.Labels = splitList(labels)
+		}
+		addLabels, _ := cmd.Flags().GetStringArray("add-label")
+		for _, label := range addLabels {
+			iss.AddLabel(label)
+		}
+		removeLabels, _ := cmd.Flags().GetStringArray("remove-label")
+		for _, label := range removeLabels {
+			iss.RemoveLabel(label)
+		}
+		if len(iss.Labels) == 0 {
+			iss.Labels = nil
+		}
+
+		if componentsValue == "none" {
+			iss.Components = nil
+		} else if componentsValue != "" {
+			iss.Components = splitList(componentsValue)
+		}
+		for _, component := range addComponents {
+			if !slices.Contains(iss.Components, component) {
+				iss.Components = append(iss.Components, component)
+			}
+		}
+		iss.Components = slices.DeleteFunc(iss.Components, func(c string) bool { return slices.Contains(removeComponents, c) })
+		if len(iss.Components) == 0 {
+			iss.Components = nil
+		}
+
 		if priority, _ := cmd.Flags().GetString("priority"); priority != "" {
-			if !models.IsValidPriority(priority) {
-				return fmt.Errorf("cli: invalid priority %q", priority)
+			if !wf.IsValidPriority(priority) {
+				return invalidf("cli: invalid priority %q (allowed: %s)", priority, strings.Join(wf.PriorityList(), ", "))
 			}
 			iss.Priority = priority
 		}
 
+		if assignee, _ := cmd.Flags().GetString("assignee"); assignee == "none" {
+			iss.Assignee = ""
+		} else if assignee != "" {
+			iss.Assignee = assignee
+		}
+
 		if description, _ := cmd.Flags().GetString("description"); description != "" {
-			iss.Description = description
+			if err := editSealed(cmd.Context(), iss, projectKey, func() error {
+				iss.Description = description
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+
+		if cmd.Flags().Changed("sensitive") || cmd.Flags().Changed("key") {
+			sensitive, _ := cmd.Flags().GetBool("sensitive")
+			if !cmd.Flags().Changed("sensitive") {
+				sensitive = true
+			}
+			keyName, _ := cmd.Flags().GetString("key")
+			if err := setSensitive(cmd.Context(), iss, projectKey, keyName, sensitive); err != nil {
+				return err
+			}
 		}
 
 		if epicID, _ := cmd.Flags().GetString("epic"); epicID != "" {
-			// Validate epic ID format
-			if err := validateEpicID(epicID); err != nil {
-				return fmt.Errorf("cli: invalid epic ID format: %w", err)
+			if err := validateProjectEpicID(projectKey, epicID); err != nil {
+				return err
 			}
 			// Validate epic exists before setting
-			epicPath, err := storage.EpicPath(projectKey, epicID)
+			epicPath, err := storage.EpicPath(cmd.Context(), projectKey, epicID)
 			if err != nil {
 				return fmt.Errorf("cli: failed to resolve epic path: %w", err)
 			}
 			if _, err := os.Stat(epicPath); err != nil {
 				if os.IsNotExist(err) {
-					return fmt.Errorf("cli: epic %q not found", epicID)
+					return notFoundf("cli: epic %q not found", epicID)
 				}
 				return fmt.Errorf("cli: failed to stat epic path %q: %w", epicPath, err)
 			}
 			iss.EpicID = epicID
 		}
 
+		// Check fields required to enter the new status
+		if !force && iss.Status != previousStatus {
+			if missing := wf.MissingFields(iss, iss.Status); len(missing) > 0 {
+				return invalidf("cli: moving %s to %s requires: %s (use --force to override)",
+					issueID, iss.Status, strings.Join(missing, ", "))
+			}
+		}
+
+		// Check the WIP limit of the new status
+		if iss.Status != previousStatus {
+			warning, err := checkWIPLimit(cmd.Context(), wf, projectKey, issueID, iss.Status, force)
+			if err != nil {
+				return err
+			}
+			wipWarning = warning
+		}
+
 		// Update timestamp
-		iss.UpdatedAt = time.Now().Format(time.RFC3339)
+		iss.UpdatedAt = storage.Timestamp(cmd.Context())
 
 		// Validate
-		if err := iss.Validate(); err != nil {
-			return fmt.Errorf("cli: invalid issue after update: %w", err)
+		if err := iss.ValidateWithWorkflow(wf); err != nil {
+			return invalidf("cli: invalid issue after update: %w", err)
 		}
 
+		// Updates may not break more rules; rules added after the issue was
+		// written are only reported
+		introduced, kept := introducedViolations(previousViolations, wf.RuleViolations(iss))
+		if err := checkRules(cmd, issueID, introduced); err != nil {
+			return err
+		}
+		brokenRules = kept
+
 		return nil
 	}); err != nil {
 		if strings.Contains(err.Error(), "not found") {
-			return fmt.Errorf("cli: issue %q not found", issueID)
+			return notFoundf("cli: issue %q not found", issueID)
 		}
 		return fmt.Errorf("cli: failed to update issue: %w", err)
 	}
 
 	// Update project index atomically
-	indexPath, err := storage.ProjectIndexPath(projectKey)
+	indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve index path: %w", err)
 	}
 
-	if err := storage.UpdateJSONAtomic(indexPath, &models.ProjectIndex{}, func(v interface{}) error {
+	if err := storage.UpdateIndexAtomic(cmd.Context(), indexPath, &models.ProjectIndex{}, func(v interface{}) error {
 		idx := v.(*models.ProjectIndex)
 		idx.AddIssue(&issue)
-		idx.UpdatedAt = time.Now().Format(time.RFC3339)
+		idx.UpdatedAt = storage.Timestamp(cmd.Context())
 		return nil
 	}); err != nil {
 		return fmt.Errorf("cli: failed to update project index: %w", err)
 	}
 
-	// Success message
-	out := cmd.OutOrStdout()
-	fmt.Fprintf(out, "Updated %s\n", issueID)
+	after, err := json.Marshal(&issue)
+	if err != nil {
+		return fmt.Errorf("cli: failed to marshal issue: %w", err)
+	}
+	changes, err := jsonFieldChanges(before, after)
+	if err != nil {
+		return fmt.Errorf("cli: failed to compare issue versions: %w", err)
+	}
+	if err := renderIssueUpdate(cmd, &IssueUpdate{ID: issueID, Changes: changes}); err != nil {
+		return err
+	}
+	if assignee, _ := cmd.Flags().GetString("assignee"); assignee != "none" {
+		warnAway(assignee, cmd)
+	}
+	if len(brokenRules) > 0 {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s still breaks project rules: %s\n", issueID, formatRuleViolations(brokenRules))
+	}
+	if wipWarning != "" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s\n", wipWarning)
+	}
+
+	return nil
+}
 
+// IssueUpdate is the result of an issue update: the fields it changed, with
+// the same comparison as sync review.
+type IssueUpdate struct {
+	ID      string        `json:"id"`
+	Changes []fieldChange `json:"changes"`
+}
+
+// renderIssueUpdate prints the result of an issue update: the changed fields
+// with --format json or yaml, otherwise a success message, followed by a
+// diff with --show-diff.
+func renderIssueUpdate(cmd *cobra.Command, update *IssueUpdate) error {
+	if update.Changes == nil {
+		update.Changes = []fieldChange{}
+	}
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(update)
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(cmd.OutOrStdout(), update)
+	}
+
+	out := successOut(cmd)
+	fmt.Fprintf(out, "Updated %s\n", update.ID)
+	if showDiff, _ := cmd.Flags().GetBool("show-diff"); showDiff {
+		if len(update.Changes) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "  No fields changed")
+		}
+		for _, change := range update.Changes {
+			fmt.Fprintf(cmd.OutOrStdout(), "  %s: %s → %s\n", change.Field, formatFieldValue(change.From), formatFieldValue(change.To))
+		}
+	}
 	return nil
 }
 
 // NewIssueLinkCmd creates and returns the issue link command.
 func NewIssueLinkCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "link <id> <dependency-id>",
-		Short: "Link issues with dependencies",
-		Long:  "Add a dependency relationship (issue is blocked by dependency)",
-		Args:  cobra.ExactArgs(2),
+		Use:   "link <id> <other-id>",
+		Short: "Link issues with dependencies or typed links",
+		Long: `Link two issues. By default the issue is blocked by the other issue.
+
+Link types (--type):
+  blocked_by     the issue is blocked by the other issue (default)
+  blocks         the issue blocks the other issue
+  relates_to     the issues are related
+  duplicates     the issue duplicates the other issue
+  duplicated_by  the issue is duplicated by the other issue
+  parent_of      the other issue becomes a subtask of the issue
+
+The reverse link is kept on the other issue (A blocked_by B shows as B blocks A),
+and --remove removes both sides.`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeIssueArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveIssueArgs(cmd, args[:2]); err != nil {
+				return err
+			}
 			issueID := args[0]
-			dependencyID := args[1]
-			return linkIssue(issueID, dependencyID, cmd)
+			otherID := args[1]
+			return linkIssue(issueID, otherID, cmd)
 		},
 	}
 
-	cmd.Flags().Bool("remove", false, "Remove dependency instead of adding")
+	cmd.Flags().String("type", models.LinkBlockedBy, "Link type ("+strings.Join(models.LinkTypes, ", ")+")")
+	cmd.Flags().Bool("remove", false, "Remove the link instead of adding it")
+	cmd.RegisterFlagCompletionFunc("type", completeLinkTypes)
 
 	return cmd
 }
 
-// linkIssue links an issue with a dependency.
-func linkIssue(issueID, dependencyID string, cmd *cobra.Command) error {
-	// Parse issue IDs
-	projectKey, _, err := models.ParseIssueID(issueID)
-	if err != nil {
-		return fmt.Errorf("cli: invalid issue ID %q: %w", issueID, err)
+// linkIssue links an issue with another issue.
+func linkIssue(issueID, otherID string, cmd *cobra.Command) error {
+	linkType, _ := cmd.Flags().GetString("type")
+	if err := models.ValidateLinkType(linkType); err != nil {
+		return invalidf("cli: %w", err)
 	}
+	remove, _ := cmd.Flags().GetBool("remove")
 
-	depProjectKey, _, err := models.ParseIssueID(dependencyID)
+	var err error
+	if linkType == models.LinkParentOf {
+		err = setParentLink(cmd.Context(), issueID, otherID, remove)
+	} else {
+		err = setLink(cmd.Context(), issueID, otherID, linkType, remove)
+	}
 	if err != nil {
-		return fmt.Errorf("cli: invalid dependency ID %q: %w", dependencyID, err)
+		return err
+	}
+
+	// Success message
+	out := successOut(cmd)
+	switch {
+	case remove && linkType == models.LinkBlockedBy:
+		fmt.Fprintf(out, "Removed dependency %s from %s\n", otherID, issueID)
+	case remove:
+		fmt.Fprintf(out, "Removed link %s -> %s (%s)\n", issueID, otherID, linkTypeText(linkType))
+	default:
+		fmt.Fprintf(out, "Linked %s -> %s (%s)\n", issueID, otherID, linkTypeText(linkType))
 	}
 
-	// Validate dependency exists
-	depPath, err := storage.IssuePath(depProjectKey, dependencyID)
+	return nil
+}
+
+// linkTypeText returns a link type for display ("blocked by" for blocked_by).
+func linkTypeText(linkType string) string {
+	return strings.ReplaceAll(linkType, "_", " ")
+}
+
+// setDependency adds (or removes) dependencyID to the blocked-by list of issueID.
+func setDependency(ctx context.Context, issueID, dependencyID string, remove bool) error {
+	return setLink(ctx, issueID, dependencyID, models.LinkBlockedBy, remove)
+}
+
+// setLink adds (or removes) a typed link from issueID to otherID, and the
+// reverse link on otherID. Both files are updated together under the project
+// lock (the locks of both projects for cross-project links). Removing a link to
+// an issue that no longer exists only updates issueID.
+func setLink(ctx context.Context, issueID, otherID, linkType string, remove bool) error {
+	// Parse issue IDs
+	projectKey, _, err := parseIssueID(ctx, issueID)
 	if err != nil {
-		return fmt.Errorf("cli: failed to resolve dependency path: %w", err)
+		return invalidf("cli: invalid issue ID %q: %w", issueID, err)
 	}
 
-	if _, err := os.Stat(depPath); os.IsNotExist(err) {
-		return fmt.Errorf("cli: dependency %q not found", dependencyID)
+	otherProjectKey, _, err := parseIssueID(ctx, otherID)
+	if err != nil {
+		if linkType == models.LinkBlockedBy {
+			return invalidf("cli: invalid dependency ID %q: %w", otherID, err)
+		}
+		return invalidf("cli: invalid issue ID %q: %w", otherID, err)
+	}
+	if otherID == issueID {
+		return invalidf("cli: cannot link %s to itself", issueID)
 	}
 
-	// Load and update issue atomically
-	issuePath, err := storage.IssuePath(projectKey, issueID)
+	issuePath, err := storage.IssuePath(ctx, projectKey, issueID)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+	}
+	otherPath, err := storage.IssuePath(ctx, otherProjectKey, otherID)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve issue path: %w", err)
 	}
 
-	var issue models.Issue
-	remove, _ := cmd.Flags().GetBool("remove")
-
-	if err := storage.UpdateJSONAtomic(issuePath, &issue, func(v interface{}) error {
-		iss := v.(*models.Issue)
+	// Update both issues together so the link and its reverse stay consistent
+	var issue, other models.Issue
+	var notFound error
+	err = storage.UpdateJSONFilesAtomic(ctx, []string{issuePath, otherPath}, []interface{}{&issue, &other}, func(exists []bool) error {
+		if !exists[0] || issue.ID != issueID {
+			notFound = notFoundf("cli: issue %q not found", issueID)
+			return notFound
+		}
 
-		// Check if issue exists (ID should match if file existed)
-		if iss.ID == "" || iss.ID != issueID {
-			return fmt.Errorf("cli: issue %q not found", issueID)
+		// A missing other issue can still be unlinked from this side
+		otherIssue := &other
+		if !exists[1] || other.ID != otherID {
+			if !remove {
+				if linkType == models.LinkBlockedBy {
+					notFound = notFoundf("cli: dependency %q not found", otherID)
+				} else {
+					notFound = notFoundf("cli: issue %q not found", otherID)
+				}
+				return notFound
+			}
+			otherIssue = nil
 		}
 
-		// Add or remove dependency
+		now := storage.Timestamp(ctx)
 		if remove {
-			iss.RemoveDependency(dependencyID)
+			models.UnlinkIssues(&issue, otherIssue, otherID, linkType)
 		} else {
-			iss.AddDependency(dependencyID)
+			models.LinkIssues(&issue, otherIssue, linkType)
+			issue.MarkBlocked(now)
+			otherIssue.MarkBlocked(now)
 		}
 
-		// Update timestamp
-		iss.UpdatedAt = time.Now().Format(time.RFC3339)
+		issue.UpdatedAt = now
+		if otherIssue != nil {
+			otherIssue.UpdatedAt = now
+		}
+		return nil
+	})
+	if notFound != nil {
+		return notFound
+	}
+	if err != nil {
+		return fmt.Errorf("cli: failed to update issue: %w", err)
+	}
+
+	return nil
+}
+
+// setParentLink makes childID a subtask of parentID (or, with remove, a top-level issue again).
+func setParentLink(ctx context.Context, parentID, childID string, remove bool) error {
+	projectKey, _, err := parseIssueID(ctx, childID)
+	if err != nil {
+		return invalidf("cli: invalid issue ID %q: %w", childID, err)
+	}
+	if !remove {
+		if err := validateParent(ctx, projectKey, childID, parentID); err != nil {
+			return err
+		}
+	}
+
+	issuePath, err := storage.IssuePath(ctx, projectKey, childID)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+	}
 
+	var issue models.Issue
+	if err := storage.UpdateJSONAtomic(ctx, issuePath, &issue, func(v interface{}) error {
+		iss := v.(*models.Issue)
+		if iss.ID == "" || iss.ID != childID {
+			return notFoundf("cli: issue %q not found", childID)
+		}
+		if remove {
+			if iss.ParentID != parentID {
+				return notFoundf("cli: %s is not a subtask of %s", childID, parentID)
+			}
+			iss.ParentID = ""
+		} else {
+			iss.ParentID = parentID
+		}
+		iss.UpdatedAt = storage.Timestamp(ctx)
 		return nil
 	}); err != nil {
 		if strings.Contains(err.Error(), "not found") {
-			return fmt.Errorf("cli: issue %q not found", issueID)
+			return notFoundf("cli: issue %q not found", childID)
 		}
 		return fmt.Errorf("cli: failed to update issue: %w", err)
 	}
 
-	// Success message
-	out := cmd.OutOrStdout()
-	if remove {
-		fmt.Fprintf(out, "Removed dependency %s from %s\n", dependencyID, issueID)
-	} else {
-		fmt.Fprintf(out, "Linked %s -> %s (blocked by)\n", issueID, dependencyID)
+	indexPath, err := storage.ProjectIndexPath(ctx, projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	var index models.ProjectIndex
+	if err := storage.UpdateIndexAtomic(ctx, indexPath, &index, func(v interface{}) error {
+		idx := v.(*models.ProjectIndex)
+		idx.AddIssue(&issue)
+		idx.UpdatedAt = storage.Timestamp(ctx)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("cli: failed to update project index: %w", err)
 	}
 
 	return nil
@@ -444,11 +1137,15 @@ func linkIssue(issueID, dependencyID string, cmd *cobra.Command) error {
 // NewIssuePRCmd creates and returns the issue PR command.
 func NewIssuePRCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "pr <id> <pr-url>",
-		Short: "Add or remove PR links",
-		Long:  "Add or remove pull request URLs from an issue",
-		Args:  cobra.ExactArgs(2),
+		Use:               "pr <id> <pr-url>",
+		Short:             "Add or remove PR links",
+		Long:              "Add or remove pull request URLs from an issue. Use 'issue pr status' to see their state.",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeIssueArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveIssueArgs(cmd, args[:1]); err != nil {
+				return err
+			}
 			issueID := args[0]
 			prURL := args[1]
 			return manageIssuePR(issueID, prURL, cmd)
@@ -457,19 +1154,21 @@ func NewIssuePRCmd() *cobra.Command {
 
 	cmd.Flags().Bool("remove", false, "Remove PR instead of adding")
 
+	cmd.AddCommand(NewIssuePRStatusCmd())
+
 	return cmd
 }
 
 // manageIssuePR adds or removes a PR URL from an issue.
 func manageIssuePR(issueID, prURL string, cmd *cobra.Command) error {
 	// Parse issue ID
-	projectKey, _, err := models.ParseIssueID(issueID)
+	projectKey, _, err := parseIssueID(cmd.Context(), issueID)
 	if err != nil {
-		return fmt.Errorf("cli: invalid issue ID %q: %w", issueID, err)
+		return invalidf("cli: invalid issue ID %q: %w", issueID, err)
 	}
 
 	// Load and update issue atomically
-	issuePath, err := storage.IssuePath(projectKey, issueID)
+	issuePath, err := storage.IssuePath(cmd.Context(), projectKey, issueID)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve issue path: %w", err)
 	}
@@ -477,12 +1176,12 @@ func manageIssuePR(issueID, prURL string, cmd *cobra.Command) error {
 	var issue models.Issue
 	remove, _ := cmd.Flags().GetBool("remove")
 
-	if err := storage.UpdateJSONAtomic(issuePath, &issue, func(v interface{}) error {
+	if err := storage.UpdateJSONAtomic(cmd.Context(), issuePath, &issue, func(v interface{}) error {
 		iss := v.(*models.Issue)
 
 		// Check if issue exists (ID should match if file existed)
 		if iss.ID == "" || iss.ID != issueID {
-			return fmt.Errorf("cli: issue %q not found", issueID)
+			return notFoundf("cli: issue %q not found", issueID)
 		}
 
 		// Add or remove PR
@@ -493,18 +1192,18 @@ func manageIssuePR(issueID, prURL string, cmd *cobra.Command) error {
 		}
 
 		// Update timestamp
-		iss.UpdatedAt = time.Now().Format(time.RFC3339)
+		iss.UpdatedAt = storage.Timestamp(cmd.Context())
 
 		return nil
 	}); err != nil {
 		if strings.Contains(err.Error(), "not found") {
-			return fmt.Errorf("cli: issue %q not found", issueID)
+			return notFoundf("cli: issue %q not found", issueID)
 		}
 		return fmt.Errorf("cli: failed to update issue: %w", err)
 	}
 
 	// Success message
-	out := cmd.OutOrStdout()
+	out := successOut(cmd)
 	if remove {
 		fmt.Fprintf(out, "Removed PR %s from %s\n", prURL, issueID)
 	} else {
@@ -514,20 +1213,107 @@ func manageIssuePR(issueID, prURL string, cmd *cobra.Command) error {
 	return nil
 }
 
+// NewIssueCommentCmd creates and returns the issue comment command.
+func NewIssueCommentCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "comment <id> <text>",
+		Short:             "Add a comment to an issue",
+		Long:              "Add a comment (Markdown) to an issue",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeIssueArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveIssueArgs(cmd, args[:1]); err != nil {
+				return err
+			}
+			issueID := args[0]
+			body := args[1]
+			return commentIssue(issueID, body, cmd)
+		},
+	}
+
+	return cmd
+}
+
+// commentIssue adds a comment to an issue.
+func commentIssue(issueID, body string, cmd *cobra.Command) error {
+	// Parse issue ID
+	projectKey, _, err := parseIssueID(cmd.Context(), issueID)
+	if err != nil {
+		return invalidf("cli: invalid issue ID %q: %w", issueID, err)
+	}
+
+	if strings.TrimSpace(body) == "" {
+		return invalidf("cli: comment text is required")
+	}
+
+	// Load and update issue atomically
+	issuePath, err := storage.IssuePath(cmd.Context(), projectKey, issueID)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+	}
+
+	var issue models.Issue
+	if err := storage.UpdateJSONAtomic(cmd.Context(), issuePath, &issue, func(v interface{}) error {
+		iss := v.(*models.Issue)
+
+		// Check if issue exists (ID should match if file existed)
+		if iss.ID == "" || iss.ID != issueID {
+			return notFoundf("cli: issue %q not found", issueID)
+		}
+
+		now := storage.Timestamp(cmd.Context())
+		if err := editSealed(cmd.Context(), iss, projectKey, func() error {
+			iss.AddComment(body, now)
+			return nil
+		}); err != nil {
+			return err
+		}
+		iss.UpdatedAt = now
+
+		return nil
+	}); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return notFoundf("cli: issue %q not found", issueID)
+		}
+		return fmt.Errorf("cli: failed to update issue: %w", err)
+	}
+
+	// Success message
+	out := successOut(cmd)
+	fmt.Fprintf(out, "Added comment to %s\n", issueID)
+
+	return nil
+}
+
 // NewIssueDeleteCmd creates and returns the issue delete command.
 func NewIssueDeleteCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "delete <id>",
 		Short: "Delete an issue",
-		Long:  "Delete an issue from the project",
-		Args:  cobra.ExactArgs(1),
+		Long: `Delete an issue from the project.
+
+The issue is moved to the trash of its project, from which it can be brought
+back with buyruk issue restore until the trash is emptied (buyruk trash empty).
+Dependencies on and links to the issue are removed from the issues on the
+other side. With --cascade-links, subtasks of the issue are also detached from
+it and it is removed from its epic and from sprint plans, in the same
+transaction, so that restoring it doesn't add it back to the epic; without it,
+an issue with subtasks is only deleted with --yes. Issues that depend on or
+link to the issue are listed as a warning first, unless --force is given.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeIssueArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveIssueArgs(cmd, args[:1]); err != nil {
+				return err
+			}
 			issueID := args[0]
 			return deleteIssue(issueID, cmd)
 		},
 	}
 
 	cmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt and override safety checks (force delete)")
+	cmd.Flags().Bool("cascade-links", false, "Also detach subtasks and remove the issue from its epic and sprint plans")
+	cmd.Flags().Bool("force", false, "Don't warn about issues that depend on or link to this issue")
 
 	return cmd
 }
@@ -535,59 +1321,88 @@ func NewIssueDeleteCmd() *cobra.Command {
 // deleteIssue deletes an issue from the project.
 func deleteIssue(issueID string, cmd *cobra.Command) error {
 	// Parse issue ID
-	projectKey, _, err := models.ParseIssueID(issueID)
+	projectKey, _, err := parseIssueID(cmd.Context(), issueID)
 	if err != nil {
-		return fmt.Errorf("cli: invalid issue ID %q: %w", issueID, err)
+		return invalidf("cli: invalid issue ID %q: %w", issueID, err)
 	}
 
 	// Check if issue exists
-	issuePath, err := storage.IssuePath(projectKey, issueID)
+	issuePath, err := storage.IssuePath(cmd.Context(), projectKey, issueID)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve issue path: %w", err)
 	}
 
 	if _, err := os.Stat(issuePath); err != nil {
 		if os.IsNotExist(err) {
-			return fmt.Errorf("cli: issue %q not found", issueID)
+			return notFoundf("cli: issue %q not found", issueID)
 		}
 		return fmt.Errorf("cli: failed to stat issue path %q: %w", issuePath, err)
 	}
 
 	// Check for issues that depend on this issue (pre-lock read for warnings only)
-	indexPath, err := storage.ProjectIndexPath(projectKey)
+	indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve index path: %w", err)
 	}
 
+	cascade, _ := cmd.Flags().GetBool("cascade-links")
+	force, _ := cmd.Flags().GetBool("force")
 	var preLockIndex models.ProjectIndex
-	if err := storage.ReadJSON(indexPath, &preLockIndex); err == nil {
-		// Check if any issues depend on this issue
-		dependentIssues := []string{}
+	if err := storage.ReadIndex(cmd.Context(), indexPath, &preLockIndex); err == nil && !force {
+		// Check if any issues depend on or link to this issue. Links across
+		// projects are found through the reverse links of the issue itself
+		var deleted models.Issue
+		storage.ReadJSON(cmd.Context(), issuePath, &deleted)
+		candidates := make([]string, 0, len(preLockIndex.Issues))
 		for _, entry := range preLockIndex.Issues {
-			// Load issue to check dependencies
-			depIssuePath, err := storage.IssuePath(projectKey, entry.ID)
+			candidates = append(candidates, entry.ID)
+		}
+		for _, id := range deleted.LinkedIssueIDs() {
+			if !slices.Contains(candidates, id) {
+				candidates = append(candidates, id)
+			}
+		}
+		dependentIssues := []string{}
+		for _, id := range candidates {
+			// Load issue to check dependencies and links
+			key, _, err := parseIssueID(cmd.Context(), id)
+			if err != nil || id == issueID {
+				continue
+			}
+			depIssuePath, err := storage.IssuePath(cmd.Context(), key, id)
 			if err != nil {
 				continue
 			}
 			var depIssue models.Issue
-			if err := storage.ReadJSON(depIssuePath, &depIssue); err != nil {
+			if err := storage.ReadJSON(cmd.Context(), depIssuePath, &depIssue); err != nil {
 				continue
 			}
-			for _, blockedBy := range depIssue.BlockedBy {
-				if blockedBy == issueID {
-					dependentIssues = append(dependentIssues, entry.ID)
-					break
-				}
+			if slices.Contains(depIssue.LinkedIssueIDs(), issueID) {
+				dependentIssues = append(dependentIssues, id)
 			}
 		}
 		if len(dependentIssues) > 0 {
 			errOut := cmd.ErrOrStderr()
-			fmt.Fprintf(errOut, "Warning: %d issue(s) depend on this issue: %s\n", len(dependentIssues), strings.Join(dependentIssues, ", "))
+			fmt.Fprintf(errOut, "Warning: %d issue(s) depend on or link to this issue: %s\n", len(dependentIssues), strings.Join(dependentIssues, ", "))
 		}
 	}
 
-	// Confirmation prompt (unless -y flag is set)
+	// Refuse to delete issues with subtasks unless forced or detaching them
 	yes, _ := cmd.Flags().GetBool("yes")
+	children := preLockIndex.Children(issueID)
+	if len(children) > 0 && !cascade {
+		childIDs := make([]string, 0, len(children))
+		for _, child := range children {
+			childIDs = append(childIDs, child.ID)
+		}
+		if !yes {
+			return fmt.Errorf("cli: issue %q has %d subtask(s): %s (delete or re-parent them first, or use --yes)", issueID, len(children), strings.Join(childIDs, ", "))
+		}
+		errOut := cmd.ErrOrStderr()
+		fmt.Fprintf(errOut, "Warning: subtasks %s will be left without a parent\n", strings.Join(childIDs, ", "))
+	}
+
+	// Confirmation prompt (unless -y flag is set)
 	if !yes {
 		errOut := cmd.ErrOrStderr()
 		fmt.Fprintf(errOut, "Are you sure you want to delete issue %q? (yes/no): ", issueID)
@@ -602,42 +1417,74 @@ func deleteIssue(issueID string, cmd *cobra.Command) error {
 		}
 	}
 
-	// Delete issue file and update index atomically under one lock/transaction
-	// This prevents race conditions where the file is deleted but index update fails
-	cleanup, err := storage.AcquireLock(projectKey)
-	if err != nil {
-		return fmt.Errorf("cli: failed to acquire lock: %w", err)
+	// Links to this issue are removed from the issues on the other side, so the
+	// projects of linked issues are locked along with this one. An unreadable
+	// issue is still deleted; only same-project references are then cleaned up
+	var deleted models.Issue
+	storage.ReadJSON(cmd.Context(), issuePath, &deleted)
+	projectKeys := []string{projectKey}
+	for _, id := range deleted.LinkedIssueIDs() {
+		if key, _, err := parseIssueID(cmd.Context(), id); err == nil && !slices.Contains(projectKeys, key) {
+			projectKeys = append(projectKeys, key)
+		}
 	}
-	defer cleanup()
 
-	// Begin transaction
-	if err := storage.BeginTransaction(projectKey, "delete_issue", map[string]interface{}{
+	// Delete issue file, unlink other issues, and update index atomically in
+	// one transaction per project. This prevents race conditions where the
+	// file is deleted but the index or a reverse link still references it
+	txs, rollback, err := beginProjectTxs(cmd.Context(), "delete_issue", map[string]interface{}{
 		"issue_id": issueID,
 		"file":     issuePath,
-	}); err != nil {
-		return fmt.Errorf("cli: failed to begin transaction: %w", err)
+	}, projectKeys...)
+	if err != nil {
+		return err
 	}
+	defer rollback()
 
-	success := false
-	defer func() {
-		if !success {
-			storage.RollbackTransaction(projectKey)
+	// Re-read under the lock, the links may have changed since
+	original, err := os.ReadFile(issuePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return notFoundf("cli: issue %q not found", issueID)
 		}
-	}()
+		return fmt.Errorf("cli: failed to read issue: %w", err)
+	}
+	deleted = models.Issue{}
+	json.Unmarshal(original, &deleted)
 
-	// Delete issue file
-	if err := os.Remove(issuePath); err != nil {
-		if os.IsNotExist(err) {
-			return fmt.Errorf("cli: issue %q not found", issueID)
+	writes, unlinked, detached, err := unlinkDeletedIssue(cmd.Context(), projectKey, issueID, deleted.LinkedIssueIDs(), projectKeys, cascade)
+	if err != nil {
+		return err
+	}
+	var sprintIDs []string
+	var epicID string
+	trashedContent := original
+	if cascade {
+		sprintWrites, ids, err := unplanDeletedIssue(cmd.Context(), projectKey, issueID)
+		if err != nil {
+			return err
+		}
+		writes = append(writes, sprintWrites...)
+		sprintIDs = ids
+
+		// Keep the issue in the trash without its epic, so restoring it
+		// doesn't add it back
+		if deleted.EpicID != "" {
+			epicID = deleted.EpicID
+			trashedIssue := deleted
+			trashedIssue.EpicID = ""
+			trashedIssue.UpdatedAt = storage.Timestamp(cmd.Context())
+			if trashedContent, err = json.MarshalIndent(&trashedIssue, "", "  "); err != nil {
+				return fmt.Errorf("cli: failed to marshal issue: %w", err)
+			}
 		}
-		return fmt.Errorf("cli: failed to delete issue file: %w", err)
 	}
 
 	// Update project index (remove issue from index)
 	// Use a fresh index variable to avoid stale data from pre-lock read
 	var index models.ProjectIndex
-	if err := storage.ReadJSON(indexPath, &index); err != nil {
-		if !os.IsNotExist(err) {
+	if err := storage.ReadIndex(cmd.Context(), indexPath, &index); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
 			return fmt.Errorf("cli: failed to read project index: %w", err)
 		}
 		// Index doesn't exist, initialize empty index
@@ -647,31 +1494,159 @@ func deleteIssue(issueID string, cmd *cobra.Command) error {
 		}
 	}
 	index.RemoveIssue(issueID)
-	index.UpdatedAt = time.Now().Format(time.RFC3339)
-
-	// Write updated index
-	data, err := json.MarshalIndent(&index, "", "  ")
+	for _, id := range detached {
+		if entry := index.FindIssue(id); entry != nil {
+			entry.ParentID = ""
+		}
+	}
+	index.UpdatedAt = storage.Timestamp(cmd.Context())
+	indexWrite, err := plannedIndexWrite(indexPath, &index)
 	if err != nil {
-		return fmt.Errorf("cli: failed to marshal index: %w", err)
+		return err
+	}
+	// Keep the issue in the trash, unless its file can't be parsed to restore
+	trashWrite, trashed, err := trashIssueWrite(cmd.Context(), projectKey, issueID, trashedContent)
+	if err != nil {
+		return err
 	}
-	if err := storage.WriteAtomic(indexPath, data); err != nil {
-		return fmt.Errorf("cli: failed to write index: %w", err)
+	if trashed {
+		writes = append(writes, trashWrite)
+	} else {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s is not valid JSON and is deleted without keeping it in the trash\n", issueID)
 	}
+	writes = append(writes, indexWrite, fileWrite{path: issuePath, original: original})
 
-	// Commit transaction
-	if err := storage.CommitTransaction(projectKey); err != nil {
-		return fmt.Errorf("cli: failed to commit transaction: %w", err)
+	if err := writeFilesTx(cmd.Context(), txs, writes); err != nil {
+		return err
+	}
+	for _, key := range projectKeys {
+		if err := txs[key].Commit(); err != nil {
+			return fmt.Errorf("cli: failed to commit transaction: %w", err)
+		}
 	}
 
-	success = true
+	queueDeleteHookEvent(cmd.Context(), projectKey, &deleted)
 
 	// Success message
-	out := cmd.OutOrStdout()
+	out := successOut(cmd)
 	fmt.Fprintf(out, "Deleted issue %q\n", issueID)
+	if trashed {
+		fmt.Fprintf(out, "Moved %s to the trash (`buyruk issue restore %s` to bring it back)\n", issueID, issueID)
+	}
+	if len(unlinked) > 0 {
+		fmt.Fprintf(out, "Removed links to %s from %s\n", issueID, strings.Join(unlinked, ", "))
+	}
+	if len(detached) > 0 {
+		fmt.Fprintf(out, "Detached subtasks %s\n", strings.Join(detached, ", "))
+	}
+	if epicID != "" {
+		fmt.Fprintf(out, "Removed %s from epic %s\n", issueID, epicID)
+	}
+	if len(sprintIDs) > 0 {
+		fmt.Fprintf(out, "Removed %s from sprints %s\n", issueID, strings.Join(sprintIDs, ", "))
+	}
 
 	return nil
 }
 
+// unlinkDeletedIssue plans the removal of dependencies and links to a deleted
+// issue from the other issues of its project and from the linked issues of the
+// given (locked) projects, and with detach, the parent link of its subtasks.
+// Returns the writes and the IDs of the unlinked and of the detached issues.
+func unlinkDeletedIssue(ctx context.Context, projectKey, issueID string, linkedIDs, lockedKeys []string, detach bool) ([]fileWrite, []string, []string, error) {
+	issuesDir, err := storage.IssuesDir(ctx, projectKey)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cli: failed to resolve issues directory: %w", err)
+	}
+	candidates := jsonFileIDs(issuesDir)
+	for _, id := range linkedIDs {
+		key, _, err := parseIssueID(ctx, id)
+		if err == nil && key != projectKey && slices.Contains(lockedKeys, key) {
+			candidates = append(candidates, id)
+		}
+	}
+
+	now := storage.Timestamp(ctx)
+	var writes []fileWrite
+	var unlinked, detached []string
+	for _, id := range candidates {
+		if id == issueID {
+			continue
+		}
+		key, _, err := parseIssueID(ctx, id)
+		if err != nil {
+			continue
+		}
+		path, err := storage.IssuePath(ctx, key, id)
+		if err != nil {
+			continue
+		}
+		original, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, nil, nil, fmt.Errorf("cli: failed to read %s: %w", path, err)
+		}
+		var issue models.Issue
+		if err := json.Unmarshal(original, &issue); err != nil {
+			continue
+		}
+		linked := issue.RemoveLinksTo(issueID)
+		child := detach && issue.ParentID == issueID
+		if !linked && !child {
+			continue
+		}
+		if child {
+			issue.ParentID = ""
+			detached = append(detached, id)
+		}
+		if linked {
+			unlinked = append(unlinked, id)
+		}
+		issue.UpdatedAt = now
+		data, err := json.MarshalIndent(&issue, "", "  ")
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("cli: failed to marshal issue %s: %w", id, err)
+		}
+		writes = append(writes, fileWrite{path: path, data: data, original: original})
+	}
+	return writes, unlinked, detached, nil
+}
+
+// unplanDeletedIssue plans the removal of a deleted issue from the sprints of
+// its project. Returns the writes and the IDs of the updated sprints.
+func unplanDeletedIssue(ctx context.Context, projectKey, issueID string) ([]fileWrite, []string, error) {
+	sprintsPath, err := storage.SprintsPath(ctx, projectKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cli: failed to resolve sprints path: %w", err)
+	}
+	var sprints models.Sprints
+	if err := storage.ReadJSON(ctx, sprintsPath, &sprints); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("cli: failed to load sprints: %w", err)
+	}
+
+	var sprintIDs []string
+	for i := range sprints.Sprints {
+		sprint := &sprints.Sprints[i]
+		if slices.Contains(sprint.Issues, issueID) {
+			sprint.Issues = slices.DeleteFunc(sprint.Issues, func(id string) bool { return id == issueID })
+			sprintIDs = append(sprintIDs, sprint.ID)
+		}
+	}
+	if len(sprintIDs) == 0 {
+		return nil, nil, nil
+	}
+	write, err := plannedJSONWrite(sprintsPath, &sprints)
+	if err != nil {
+		return nil, nil, err
+	}
+	return []fileWrite{write}, sprintIDs, nil
+}
+
 // validateEpicID validates the format of an epic ID.
 // Epic IDs should be non-empty and contain only uppercase alphanumeric characters and hyphens.
 // Enforcing uppercase prevents collisions on case-insensitive filesystems (Windows/macOS).
@@ -2,11 +2,16 @@ package cli
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"slices"
 	"strings"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/buyruk-project/buyruk-cli/internal/config"
 	"github.com/buyruk-project/buyruk-cli/internal/models"
@@ -47,7 +52,7 @@ func TestCreateIssue_Minimal(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -80,13 +85,13 @@ func TestCreateIssue_Minimal(t *testing.T) {
 	}
 
 	// Verify issue was created
-	issuePath, err := storage.IssuePath(projectKey, expectedID)
+	issuePath, err := storage.IssuePath(t.Context(), projectKey, expectedID)
 	if err != nil {
 		t.Fatalf("Failed to resolve issue path: %v", err)
 	}
 
 	var issue models.Issue
-	if err := storage.ReadJSON(issuePath, &issue); err != nil {
+	if err := storage.ReadJSON(t.Context(), issuePath, &issue); err != nil {
 		t.Fatalf("Failed to read issue: %v", err)
 	}
 
@@ -109,7 +114,7 @@ func TestCreateIssue_WithAllFields(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -126,7 +131,7 @@ func TestCreateIssue_WithAllFields(t *testing.T) {
 	rootCmdEpic.SetArgs([]string{
 		"epic", "create",
 		"--project", projectKey,
-		"--id", "E-1",
+		"--id", projectKey + "-E1",
 		"--title", "Test Epic",
 	})
 	rootCmdEpic.SetOut(new(bytes.Buffer))
@@ -145,7 +150,7 @@ func TestCreateIssue_WithAllFields(t *testing.T) {
 		"--status", "DOING",
 		"--priority", "HIGH",
 		"--description", "This is a bug",
-		"--epic", "E-1",
+		"--epic", projectKey + "-E1",
 	})
 
 	buf := new(bytes.Buffer)
@@ -157,13 +162,13 @@ func TestCreateIssue_WithAllFields(t *testing.T) {
 	}
 
 	// Verify issue was created with correct values
-	issuePath, err := storage.IssuePath(projectKey, projectKey+"-10")
+	issuePath, err := storage.IssuePath(t.Context(), projectKey, projectKey+"-10")
 	if err != nil {
 		t.Fatalf("Failed to resolve issue path: %v", err)
 	}
 
 	var issue models.Issue
-	if err := storage.ReadJSON(issuePath, &issue); err != nil {
+	if err := storage.ReadJSON(t.Context(), issuePath, &issue); err != nil {
 		t.Fatalf("Failed to read issue: %v", err)
 	}
 
@@ -185,7 +190,7 @@ func TestCreateIssue_WithAllFields(t *testing.T) {
 	if issue.Description != "This is a bug" {
 		t.Errorf("Issue Description = %q, want 'This is a bug'", issue.Description)
 	}
-	if issue.EpicID != "E-1" {
+	if issue.EpicID != projectKey+"-E1" {
 		t.Errorf("Issue EpicID = %q, want E-1", issue.EpicID)
 	}
 }
@@ -195,7 +200,7 @@ func TestCreateIssue_AutoIncrement(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -224,14 +229,14 @@ func TestCreateIssue_AutoIncrement(t *testing.T) {
 	}
 
 	// Verify both issues exist with correct IDs
-	issue1Path, _ := storage.IssuePath(projectKey, projectKey+"-1")
-	issue2Path, _ := storage.IssuePath(projectKey, projectKey+"-2")
+	issue1Path, _ := storage.IssuePath(t.Context(), projectKey, projectKey+"-1")
+	issue2Path, _ := storage.IssuePath(t.Context(), projectKey, projectKey+"-2")
 
 	var issue1, issue2 models.Issue
-	if err := storage.ReadJSON(issue1Path, &issue1); err != nil {
+	if err := storage.ReadJSON(t.Context(), issue1Path, &issue1); err != nil {
 		t.Fatalf("Failed to read first issue: %v", err)
 	}
-	if err := storage.ReadJSON(issue2Path, &issue2); err != nil {
+	if err := storage.ReadJSON(t.Context(), issue2Path, &issue2); err != nil {
 		t.Fatalf("Failed to read second issue: %v", err)
 	}
 
@@ -248,7 +253,7 @@ func TestCreateIssue_MissingTitle(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -282,12 +287,12 @@ func TestCreateIssue_NoProject(t *testing.T) {
 	originalCfg, _ := config.Get()
 	defer func() {
 		if originalCfg != nil {
-			config.Save(originalCfg)
+			config.Save(t.Context(), originalCfg)
 		}
 	}()
 
 	// Clear default_project
-	if err := config.Set("default_project", ""); err != nil {
+	if err := config.Set(t.Context(), "default_project", ""); err != nil {
 		t.Fatalf("Failed to clear config: %v", err)
 	}
 
@@ -329,7 +334,7 @@ func TestCreateIssue_InvalidID(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -368,7 +373,7 @@ func TestCreateIssue_DuplicateID(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -420,7 +425,7 @@ func TestGetNextIssueSequence(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -433,7 +438,7 @@ func TestGetNextIssueSequence(t *testing.T) {
 	}
 
 	// First sequence should be 1
-	seq, err := getNextIssueSequence(projectKey)
+	seq, err := getNextIssueSequence(t.Context(), projectKey)
 	if err != nil {
 		t.Fatalf("getNextIssueSequence() failed: %v", err)
 	}
@@ -455,7 +460,7 @@ func TestGetNextIssueSequence(t *testing.T) {
 	}
 
 	// Next sequence should be 6
-	seq, err = getNextIssueSequence(projectKey)
+	seq, err = getNextIssueSequence(t.Context(), projectKey)
 	if err != nil {
 		t.Fatalf("getNextIssueSequence() failed: %v", err)
 	}
@@ -469,7 +474,7 @@ func TestCreateIssue_ConcurrentSameID(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -526,14 +531,14 @@ func TestCreateIssue_ConcurrentSameID(t *testing.T) {
 	}
 
 	// Verify only one issue file exists
-	issuePath, _ := storage.IssuePath(projectKey, issueID)
+	issuePath, _ := storage.IssuePath(t.Context(), projectKey, issueID)
 	if _, err := os.Stat(issuePath); os.IsNotExist(err) {
 		t.Fatal("Issue file was not created")
 	}
 
 	// Verify issue content is valid
 	var issue models.Issue
-	if err := storage.ReadJSON(issuePath, &issue); err != nil {
+	if err := storage.ReadJSON(t.Context(), issuePath, &issue); err != nil {
 		t.Fatalf("Failed to read issue: %v", err)
 	}
 
@@ -547,7 +552,7 @@ func TestCreateIssue_InvalidType(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -586,7 +591,7 @@ func TestCreateIssue_InvalidStatus(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -625,7 +630,7 @@ func TestCreateIssue_InvalidPriority(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -664,7 +669,7 @@ func TestCreateIssue_InvalidIDFormat(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -713,7 +718,7 @@ func TestUpdateIssue_Success(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -759,13 +764,13 @@ func TestUpdateIssue_Success(t *testing.T) {
 	}
 
 	// Verify issue was updated
-	issuePath, err := storage.IssuePath(projectKey, issueID)
+	issuePath, err := storage.IssuePath(t.Context(), projectKey, issueID)
 	if err != nil {
 		t.Fatalf("Failed to resolve issue path: %v", err)
 	}
 
 	var issue models.Issue
-	if err := storage.ReadJSON(issuePath, &issue); err != nil {
+	if err := storage.ReadJSON(t.Context(), issuePath, &issue); err != nil {
 		t.Fatalf("Failed to read issue: %v", err)
 	}
 
@@ -785,7 +790,7 @@ func TestUpdateIssue_NotFound(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -820,7 +825,7 @@ func TestUpdateIssue_InvalidStatus(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -863,7 +868,7 @@ func TestUpdateIssue_InvalidPriority(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -901,6 +906,227 @@ func TestUpdateIssue_InvalidPriority(t *testing.T) {
 	}
 }
 
+func TestUpdateIssue_Pairs(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) error {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+		return cmd.Execute()
+	}
+
+	issueID := projectKey + "-1"
+	if err := run("project", "create", projectKey); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if err := run("issue", "create", "--project", projectKey, "--title", "Original", "--labels", "ui,backend"); err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+
+	if err := run("issue", "update", issueID, "status=DOING", "priority=HIGH",
+		"title=Renamed issue", "labels+=infra,ops", "labels-=ui"); err != nil {
+		t.Fatalf("issue update with pairs failed: %v", err)
+	}
+
+	issuePath, _ := storage.IssuePath(t.Context(), projectKey, issueID)
+	var issue models.Issue
+	if err := storage.ReadJSON(t.Context(), issuePath, &issue); err != nil {
+		t.Fatalf("Failed to read issue: %v", err)
+	}
+	if issue.Status != models.StatusDOING || issue.Priority != models.PriorityHIGH || issue.Title != "Renamed issue" {
+		t.Errorf("Expected DOING/HIGH/Renamed issue, got %s/%s/%s", issue.Status, issue.Priority, issue.Title)
+	}
+	if got := strings.Join(issue.Labels, ","); got != "backend,infra,ops" {
+		t.Errorf("Labels = %q, want backend,infra,ops", got)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"missing operator", []string{"DOING"}, "expected field=value"},
+		{"unknown field", []string{"owner=bob"}, "unknown update field"},
+		{"list operator on scalar", []string{"status+=DONE"}, "not supported"},
+		{"flag and pair", []string{"--status", "DONE", "status=DONE"}, "both as --status"},
+		{"repeated field", []string{"priority=LOW", "priority=HIGH"}, "more than once"},
+		{"empty value", []string{"title="}, "needs a value"},
+		{"invalid value", []string{"priority=URGENT"}, "invalid priority"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := run(append([]string{"issue", "update", issueID}, tt.args...)...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got: %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestUpdateIssue_ShowDiff(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	issueID := projectKey + "-1"
+	if _, err := run("project", "create", projectKey); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := run("issue", "create", "--project", projectKey, "--title", "Original"); err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+
+	out, err := run("issue", "update", issueID, "status=DOING", "title=Renamed", "--show-diff")
+	if err != nil {
+		t.Fatalf("issue update failed: %v", err)
+	}
+	want := "Updated " + issueID + "\n  status: TODO → DOING\n  title: Original → Renamed\n"
+	if out != want {
+		t.Errorf("Expected the diff\n%q, got\n%q", want, out)
+	}
+
+	out, err = run("issue", "update", issueID, "priority=HIGH", "labels+=infra", "--format", "json")
+	if err != nil {
+		t.Fatalf("issue update failed: %v", err)
+	}
+	var update struct {
+		ID      string `json:"id"`
+		Changes []struct {
+			Field string `json:"field"`
+			From  any    `json:"from"`
+			To    any    `json:"to"`
+		} `json:"changes"`
+	}
+	if err := json.Unmarshal([]byte(out), &update); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", out, err)
+	}
+	if update.ID != issueID || len(update.Changes) != 2 {
+		t.Fatalf("Expected two changes of %s, got %+v", issueID, update)
+	}
+	if c := update.Changes[0]; c.Field != "labels" || c.From != nil || fmt.Sprint(c.To) != "[infra]" {
+		t.Errorf("Expected labels null → [infra], got %s %v → %v", c.Field, c.From, c.To)
+	}
+	if c := update.Changes[1]; c.Field != "priority" || c.From != nil || c.To != "HIGH" {
+		t.Errorf("Expected priority null → HIGH, got %s %v → %v", c.Field, c.From, c.To)
+	}
+
+	out, err = run("issue", "update", issueID, "priority=HIGH", "--show-diff")
+	if err != nil || !strings.Contains(out, "No fields changed") {
+		t.Errorf("Expected an unchanged update to say so, got %q (%v)", out, err)
+	}
+}
+
+func TestIssue_DueDate(t *testing.T) {
+	// Use unique project key to avoid conflicts
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	// Clean up after test
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	// Create project first
+	rootCmd := NewRootCmd()
+	rootCmd.SetArgs([]string{"project", "create", projectKey})
+	rootCmd.SetOut(new(bytes.Buffer))
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	// Create an issue with an absolute due date
+	issueID := projectKey + "-1"
+	rootCmd2 := NewRootCmd()
+	rootCmd2.SetArgs([]string{"issue", "create", "--project", projectKey, "--title", "Due Issue", "--due", "2024-06-01"})
+	rootCmd2.SetOut(new(bytes.Buffer))
+	if err := rootCmd2.Execute(); err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+
+	issuePath, err := storage.IssuePath(t.Context(), projectKey, issueID)
+	if err != nil {
+		t.Fatalf("Failed to resolve issue path: %v", err)
+	}
+
+	var issue models.Issue
+	if err := storage.ReadJSON(t.Context(), issuePath, &issue); err != nil {
+		t.Fatalf("Failed to read issue: %v", err)
+	}
+
+	due, err := time.Parse(time.RFC3339, issue.Due)
+	if err != nil {
+		t.Fatalf("Issue Due = %q, want RFC3339 timestamp: %v", issue.Due, err)
+	}
+	if got := due.In(config.Location()).Format("2006-01-02"); got != "2024-06-01" {
+		t.Errorf("Issue Due date = %q, want 2024-06-01", got)
+	}
+
+	// Update with a relative due date
+	rootCmd3 := NewRootCmd()
+	rootCmd3.SetArgs([]string{"issue", "update", issueID, "--due", "3d"})
+	rootCmd3.SetOut(new(bytes.Buffer))
+	if err := rootCmd3.Execute(); err != nil {
+		t.Fatalf("issue update command failed: %v", err)
+	}
+
+	if err := storage.ReadJSON(t.Context(), issuePath, &issue); err != nil {
+		t.Fatalf("Failed to read issue: %v", err)
+	}
+	due, err = time.Parse(time.RFC3339, issue.Due)
+	if err != nil {
+		t.Fatalf("Issue Due = %q, want RFC3339 timestamp: %v", issue.Due, err)
+	}
+	if diff := time.Until(due) - 72*time.Hour; diff > time.Minute || diff < -time.Minute {
+		t.Errorf("Issue Due = %v, want about 3 days from now", due)
+	}
+
+	// Invalid values are rejected with the flag name in the error
+	rootCmd4 := NewRootCmd()
+	rootCmd4.SetArgs([]string{"issue", "update", issueID, "--due", "someday"})
+	rootCmd4.SetOut(new(bytes.Buffer))
+	rootCmd4.SetErr(new(bytes.Buffer))
+	err = rootCmd4.Execute()
+	if err == nil {
+		t.Fatal("issue update should fail for invalid due date")
+	}
+	if !strings.Contains(err.Error(), "--due") {
+		t.Errorf("Error = %q, want it to mention --due", err)
+	}
+
+	// "none" clears the due date
+	rootCmd5 := NewRootCmd()
+	rootCmd5.SetArgs([]string{"issue", "update", issueID, "--due", "none"})
+	rootCmd5.SetOut(new(bytes.Buffer))
+	if err := rootCmd5.Execute(); err != nil {
+		t.Fatalf("issue update command failed: %v", err)
+	}
+
+	issue = models.Issue{}
+	if err := storage.ReadJSON(t.Context(), issuePath, &issue); err != nil {
+		t.Fatalf("Failed to read issue: %v", err)
+	}
+	if issue.Due != "" {
+		t.Errorf("Issue Due = %q, want empty", issue.Due)
+	}
+}
+
 func TestNewIssueLinkCmd(t *testing.T) {
 	cmd := NewIssueLinkCmd()
 	if cmd == nil {
@@ -916,7 +1142,7 @@ func TestLinkIssue_AddDependency(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -966,13 +1192,13 @@ func TestLinkIssue_AddDependency(t *testing.T) {
 	}
 
 	// Verify dependency was added
-	issuePath, err := storage.IssuePath(projectKey, issueID1)
+	issuePath, err := storage.IssuePath(t.Context(), projectKey, issueID1)
 	if err != nil {
 		t.Fatalf("Failed to resolve issue path: %v", err)
 	}
 
 	var issue models.Issue
-	if err := storage.ReadJSON(issuePath, &issue); err != nil {
+	if err := storage.ReadJSON(t.Context(), issuePath, &issue); err != nil {
 		t.Fatalf("Failed to read issue: %v", err)
 	}
 
@@ -986,7 +1212,7 @@ func TestLinkIssue_RemoveDependency(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -1044,13 +1270,13 @@ func TestLinkIssue_RemoveDependency(t *testing.T) {
 	}
 
 	// Verify dependency was removed
-	issuePath, err := storage.IssuePath(projectKey, issueID1)
+	issuePath, err := storage.IssuePath(t.Context(), projectKey, issueID1)
 	if err != nil {
 		t.Fatalf("Failed to resolve issue path: %v", err)
 	}
 
 	var issue models.Issue
-	if err := storage.ReadJSON(issuePath, &issue); err != nil {
+	if err := storage.ReadJSON(t.Context(), issuePath, &issue); err != nil {
 		t.Fatalf("Failed to read issue: %v", err)
 	}
 
@@ -1059,12 +1285,420 @@ func TestLinkIssue_RemoveDependency(t *testing.T) {
 	}
 }
 
+func TestLinkIssue_Types(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		buf := new(bytes.Buffer)
+		cmd.SetOut(buf)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return buf.String(), err
+	}
+	readIssue := func(id string) *models.Issue {
+		t.Helper()
+		path, _ := storage.IssuePath(t.Context(), projectKey, id)
+		var issue models.Issue
+		if err := storage.ReadJSON(t.Context(), path, &issue); err != nil {
+			t.Fatalf("Failed to read issue %s: %v", id, err)
+		}
+		return &issue
+	}
+
+	if _, err := run("project", "create", projectKey); err != nil {
+		t.Fatalf("project create failed: %v", err)
+	}
+	for i := 1; i <= 4; i++ {
+		if _, err := run("issue", "create", "--project", projectKey, "--title", fmt.Sprintf("Issue %d", i)); err != nil {
+			t.Fatalf("issue create failed: %v", err)
+		}
+	}
+	id := func(n int) string { return fmt.Sprintf("%s-%d", projectKey, n) }
+
+	steps := [][]string{
+		{"issue", "link", id(1), id(2)},
+		{"issue", "link", id(1), id(3), "--type", "relates_to"},
+		{"issue", "link", id(4), id(1), "--type", "duplicates"},
+		{"issue", "link", id(2), id(3), "--type", "blocks"},
+		{"issue", "link", id(1), id(4), "--type", "parent_of"},
+	}
+	for _, args := range steps {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	issue1 := readIssue(id(1))
+	if !slices.Equal(issue1.BlockedBy, []string{id(2)}) {
+		t.Errorf("Expected %s blocked by %s, got %v", id(1), id(2), issue1.BlockedBy)
+	}
+	if got := issue1.LinkedIDs(models.LinkRelatesTo); !slices.Equal(got, []string{id(3)}) {
+		t.Errorf("Expected %s relates to %s, got %v", id(1), id(3), got)
+	}
+	if got := issue1.LinkedIDs(models.LinkDuplicatedBy); !slices.Equal(got, []string{id(4)}) {
+		t.Errorf("Expected reverse duplicated_by link on %s, got %v", id(1), got)
+	}
+
+	issue2 := readIssue(id(2))
+	if got := issue2.LinkedIDs(models.LinkBlocks); !slices.Equal(got, []string{id(1), id(3)}) {
+		t.Errorf("Expected %s to block %s and %s, got %v", id(2), id(1), id(3), got)
+	}
+	if got := readIssue(id(3)); !slices.Equal(got.BlockedBy, []string{id(2)}) || !slices.Equal(got.LinkedIDs(models.LinkRelatesTo), []string{id(1)}) {
+		t.Errorf("Unexpected links on %s: %v %v", id(3), got.BlockedBy, got.Links)
+	}
+	if got := readIssue(id(4)); got.ParentID != id(1) {
+		t.Errorf("Expected parent_of to make %s a subtask of %s, got parent %q", id(4), id(1), got.ParentID)
+	}
+
+	output, err := run("view", id(1))
+	if err != nil {
+		t.Fatalf("view failed: %v", err)
+	}
+	for _, want := range []string{"Blocked By", "Relates To", "Duplicated By", "Subtasks"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q group in view, got: %s", want, output)
+		}
+	}
+
+	// Removing a link removes both sides
+	if _, err := run("issue", "link", id(3), id(1), "--type", "relates_to", "--remove"); err != nil {
+		t.Fatalf("link --remove failed: %v", err)
+	}
+	if len(readIssue(id(1)).LinkedIDs(models.LinkRelatesTo)) != 0 || len(readIssue(id(3)).LinkedIDs(models.LinkRelatesTo)) != 0 {
+		t.Error("Expected relates_to link removed from both issues")
+	}
+	if _, err := run("issue", "link", id(1), id(4), "--type", "parent_of", "--remove"); err != nil {
+		t.Fatalf("parent_of --remove failed: %v", err)
+	}
+	if readIssue(id(4)).ParentID != "" {
+		t.Error("Expected parent_of --remove to clear the parent")
+	}
+
+	if _, err := run("issue", "link", id(1), id(2), "--type", "clones"); err == nil || !strings.Contains(err.Error(), "invalid link type") {
+		t.Errorf("Expected invalid link type error, got: %v", err)
+	}
+	if _, err := run("issue", "link", id(1), id(1), "--type", "relates_to"); err == nil {
+		t.Error("Expected error when linking an issue to itself")
+	}
+}
+
+func TestLinkIssue_ReverseLinks(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	otherKey := projectKey + "X"
+	defer func() {
+		for _, key := range []string{projectKey, otherKey} {
+			projectDir, _ := storage.ProjectDir(t.Context(), key)
+			os.RemoveAll(projectDir)
+		}
+	}()
+
+	run := func(input string, args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		buf := new(bytes.Buffer)
+		cmd.SetOut(buf)
+		cmd.SetErr(new(bytes.Buffer))
+		cmd.SetIn(strings.NewReader(input))
+		err := cmd.Execute()
+		return buf.String(), err
+	}
+	readIssue := func(id string) *models.Issue {
+		t.Helper()
+		key, _, _ := models.ParseIssueID(id)
+		path, _ := storage.IssuePath(t.Context(), key, id)
+		var issue models.Issue
+		if err := storage.ReadJSON(t.Context(), path, &issue); err != nil {
+			t.Fatalf("Failed to read issue %s: %v", id, err)
+		}
+		return &issue
+	}
+
+	for _, key := range []string{projectKey, otherKey} {
+		if _, err := run("", "project", "create", key); err != nil {
+			t.Fatalf("project create failed: %v", err)
+		}
+	}
+	for i := 1; i <= 3; i++ {
+		if _, err := run("", "issue", "create", "--project", projectKey, "--title", fmt.Sprintf("Issue %d", i)); err != nil {
+			t.Fatalf("issue create failed: %v", err)
+		}
+	}
+	if _, err := run("", "issue", "create", "--project", otherKey, "--title", "Other"); err != nil {
+		t.Fatalf("issue create failed: %v", err)
+	}
+	a, b, c, other := projectKey+"-1", projectKey+"-2", projectKey+"-3", otherKey+"-1"
+
+	// blocked_by gains a blocks link on the other side, in any project
+	for _, args := range [][]string{
+		{"issue", "link", a, b},
+		{"issue", "link", c, b},
+		{"issue", "link", a, other},
+	} {
+		if _, err := run("", args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+	if got := readIssue(b).LinkedIDs(models.LinkBlocks); !slices.Equal(got, []string{a, c}) {
+		t.Errorf("Expected %s to block %s and %s, got %v", b, a, c, got)
+	}
+	if got := readIssue(other).LinkedIDs(models.LinkBlocks); !slices.Equal(got, []string{a}) {
+		t.Errorf("Expected %s to block %s, got %v", other, a, got)
+	}
+
+	// Unlinking cleans both sides
+	if _, err := run("", "issue", "link", c, b, "--remove"); err != nil {
+		t.Fatalf("unlink failed: %v", err)
+	}
+	if len(readIssue(c).BlockedBy) != 0 || !slices.Equal(readIssue(b).LinkedIDs(models.LinkBlocks), []string{a}) {
+		t.Errorf("Unlink left %v / %v", readIssue(c).BlockedBy, readIssue(b).Links)
+	}
+
+	// Deleting an issue removes the links to it from the issues it was linked with
+	out, err := run("", "issue", "delete", a, "--yes")
+	if err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if !strings.Contains(out, "Removed links to "+a) {
+		t.Errorf("Expected removed links message, got: %s", out)
+	}
+	if len(readIssue(b).Links) != 0 || len(readIssue(other).Links) != 0 {
+		t.Errorf("Delete left links to %s: %v / %v", a, readIssue(b).Links, readIssue(other).Links)
+	}
+	out, err = run("", "issue", "delete", b, "--yes")
+	if err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if strings.Contains(out, "Removed links") {
+		t.Errorf("Expected no links to remove, got: %s", out)
+	}
+	for _, key := range []string{projectKey, otherKey} {
+		if pending, _, _ := storage.CheckPendingTransaction(t.Context(), key); pending {
+			t.Errorf("Transaction of %s should be committed", key)
+		}
+		if locked, _ := storage.CheckLock(t.Context(), key); locked {
+			t.Errorf("Lock of %s should be released", key)
+		}
+	}
+
+	// apply maintains reverse links to dependencies in other projects
+	if _, err := run(`{"op":"link","id":"`+c+`","blocked_by":"`+other+`"}`, "apply", "--stdin", "--project", projectKey); err != nil {
+		t.Fatalf("apply failed: %v", err)
+	}
+	if got := readIssue(other).LinkedIDs(models.LinkBlocks); !slices.Equal(got, []string{c}) {
+		t.Errorf("Expected %s to block %s after apply, got %v", other, c, got)
+	}
+}
+
+func TestDeleteIssue_CascadeLinks(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(append(args, "--project", projectKey, "--no-hooks"))
+		out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(errOut)
+		err := cmd.Execute()
+		return out.String(), errOut.String(), err
+	}
+	id := func(n int) string { return fmt.Sprintf("%s-%d", projectKey, n) }
+
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"epic", "create", "--id", projectKey + "-E1", "--title", "Auth"},
+		{"issue", "create", "--title", "Parent", "--epic", projectKey + "-E1"},
+		{"issue", "create", "--title", "Subtask", "--parent", id(1)},
+		{"issue", "create", "--title", "Dependent"},
+		{"issue", "create", "--title", "Related"},
+		{"issue", "link", id(3), id(1)},
+		{"issue", "link", id(4), id(1), "--type", "relates_to"},
+		{"sprint", "create", "S-1", "--start", "2024-06-03", "--end", "2024-06-14"},
+		{"sprint", "add", "S-1", id(1), id(3)},
+	} {
+		if _, _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	// Subtasks still need --yes without --cascade-links
+	if _, _, err := run("issue", "delete", id(1), "--force"); err == nil || !strings.Contains(err.Error(), "subtask") {
+		t.Errorf("Expected subtask error, got: %v", err)
+	}
+
+	out, errOut, err := run("issue", "delete", id(1), "--cascade-links", "--force", "--yes")
+	if err != nil {
+		t.Fatalf("delete --cascade-links failed: %v", err)
+	}
+	if strings.Contains(errOut, "depend on this issue") {
+		t.Errorf("Expected --force to skip the dependency warning, got: %s", errOut)
+	}
+	for _, want := range []string{"Removed links to " + id(1) + " from " + id(3) + ", " + id(4), "Detached subtasks " + id(2), "from epic " + projectKey + "-E1", "from sprints S-1"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in output, got: %s", want, out)
+		}
+	}
+
+	subtaskPath, _ := storage.IssuePath(t.Context(), projectKey, id(2))
+	var subtask models.Issue
+	storage.ReadJSON(t.Context(), subtaskPath, &subtask)
+	if subtask.ParentID != "" {
+		t.Errorf("Expected subtask to be detached, got parent %q", subtask.ParentID)
+	}
+	dependentPath, _ := storage.IssuePath(t.Context(), projectKey, id(3))
+	var dependent models.Issue
+	storage.ReadJSON(t.Context(), dependentPath, &dependent)
+	if len(dependent.BlockedBy) != 0 {
+		t.Errorf("Expected dependency to be removed, got %v", dependent.BlockedBy)
+	}
+	indexPath, _ := storage.ProjectIndexPath(t.Context(), projectKey)
+	var index models.ProjectIndex
+	storage.ReadIndex(t.Context(), indexPath, &index)
+	if entry := index.FindIssue(id(2)); entry == nil || entry.ParentID != "" {
+		t.Errorf("Expected index entry of subtask without parent, got %+v", entry)
+	}
+	if epic := index.FindEpic(projectKey + "-E1"); epic == nil || epic.Issues != 0 {
+		t.Errorf("Expected the epic without issues, got %+v", epic)
+	}
+	sprints, err := loadSprints(t.Context(), projectKey)
+	if err != nil {
+		t.Fatalf("loadSprints() failed: %v", err)
+	}
+	if got := sprints.Sprints[0].Issues; !slices.Equal(got, []string{id(3)}) {
+		t.Errorf("Sprint issues = %v, want [%s]", got, id(3))
+	}
+	if pending, _, _ := storage.CheckPendingTransaction(t.Context(), projectKey); pending {
+		t.Error("Transaction should be committed")
+	}
+
+	// Restoring the issue doesn't add it back to the epic
+	if _, _, err := run("issue", "restore", id(1)); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	parentPath, _ := storage.IssuePath(t.Context(), projectKey, id(1))
+	var restored models.Issue
+	storage.ReadJSON(t.Context(), parentPath, &restored)
+	if restored.EpicID != "" {
+		t.Errorf("Expected the restored issue without an epic, got %q", restored.EpicID)
+	}
+}
+
+func TestDeleteIssue_DependencyWarning(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(append(args, "--project", projectKey, "--no-hooks"))
+		errOut := new(bytes.Buffer)
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(errOut)
+		err := cmd.Execute()
+		return errOut.String(), err
+	}
+	id := func(n int) string { return fmt.Sprintf("%s-%d", projectKey, n) }
+
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--title", "Parent"},
+		{"issue", "create", "--title", "Dependent"},
+		{"issue", "create", "--title", "Related"},
+		{"issue", "link", id(2), id(1)},
+		{"issue", "link", id(3), id(1), "--type", "relates_to"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	// Typed links are listed along with dependencies
+	errOut, err := run("issue", "delete", id(1), "--yes")
+	if err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if !strings.Contains(errOut, "2 issue(s) depend on or link to this issue: "+id(2)+", "+id(3)) {
+		t.Errorf("Expected a warning naming %s and %s, got: %s", id(2), id(3), errOut)
+	}
+}
+
+func TestDeleteIssue_FailedWriteRollsBack(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(ctx context.Context, args ...string) error {
+		cmd := NewRootCmd()
+		cmd.SetArgs(append(args, "--project", projectKey, "--no-hooks"))
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+		return cmd.ExecuteContext(ctx)
+	}
+	id := func(n int) string { return fmt.Sprintf("%s-%d", projectKey, n) }
+
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--title", "Parent"},
+		{"issue", "create", "--title", "Dependent"},
+		{"issue", "link", id(2), id(1)},
+	} {
+		if err := run(t.Context(), args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	// The index is written after the dependent issue and the trash: both are
+	// restored when it fails
+	indexPath, _ := storage.ProjectIndexPath(t.Context(), projectKey)
+	failed := false
+	ctx := storage.WithEnv(t.Context(), storage.Env{WriteFile: func(f *os.File, data []byte) (int, error) {
+		if f.Name() == indexPath+".tmp" && !failed {
+			failed = true
+			return 0, errors.New("disk full")
+		}
+		return f.Write(data)
+	}})
+	if err := run(ctx, "issue", "delete", id(1), "--cascade-links", "--force", "--yes"); err == nil {
+		t.Fatal("Expected the delete to fail")
+	}
+
+	issuePath, _ := storage.IssuePath(t.Context(), projectKey, id(1))
+	if _, err := os.Stat(issuePath); err != nil {
+		t.Errorf("Expected the issue to be kept: %v", err)
+	}
+	dependentPath, _ := storage.IssuePath(t.Context(), projectKey, id(2))
+	var dependent models.Issue
+	storage.ReadJSON(ctx, dependentPath, &dependent)
+	if !slices.Equal(dependent.BlockedBy, []string{id(1)}) {
+		t.Errorf("Expected the dependency to be restored, got %v", dependent.BlockedBy)
+	}
+	if err := run(t.Context(), "issue", "restore", id(1)); err == nil {
+		t.Error("Expected the issue not to be in the trash")
+	}
+	if pending, _, _ := storage.CheckPendingTransaction(t.Context(), projectKey); pending {
+		t.Error("Transaction should be rolled back")
+	}
+}
+
 func TestLinkIssue_NotFound(t *testing.T) {
 	// Use unique project key to avoid conflicts
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -1135,7 +1769,7 @@ func TestManageIssuePR_AddPR(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -1177,13 +1811,13 @@ func TestManageIssuePR_AddPR(t *testing.T) {
 	}
 
 	// Verify PR was added
-	issuePath, err := storage.IssuePath(projectKey, issueID)
+	issuePath, err := storage.IssuePath(t.Context(), projectKey, issueID)
 	if err != nil {
 		t.Fatalf("Failed to resolve issue path: %v", err)
 	}
 
 	var issue models.Issue
-	if err := storage.ReadJSON(issuePath, &issue); err != nil {
+	if err := storage.ReadJSON(t.Context(), issuePath, &issue); err != nil {
 		t.Fatalf("Failed to read issue: %v", err)
 	}
 
@@ -1197,7 +1831,7 @@ func TestManageIssuePR_RemovePR(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -1247,13 +1881,13 @@ func TestManageIssuePR_RemovePR(t *testing.T) {
 	}
 
 	// Verify PR was removed
-	issuePath, err := storage.IssuePath(projectKey, issueID)
+	issuePath, err := storage.IssuePath(t.Context(), projectKey, issueID)
 	if err != nil {
 		t.Fatalf("Failed to resolve issue path: %v", err)
 	}
 
 	var issue models.Issue
-	if err := storage.ReadJSON(issuePath, &issue); err != nil {
+	if err := storage.ReadJSON(t.Context(), issuePath, &issue); err != nil {
 		t.Fatalf("Failed to read issue: %v", err)
 	}
 
@@ -1267,7 +1901,7 @@ func TestManageIssuePR_NotFound(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -1317,7 +1951,7 @@ func TestManageIssuePR_InvalidID(t *testing.T) {
 func TestDeleteIssue_WithYesFlag(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -1359,7 +1993,7 @@ func TestDeleteIssue_WithYesFlag(t *testing.T) {
 	}
 
 	// Verify issue was deleted
-	issuePath, err := storage.IssuePath(projectKey, issueID)
+	issuePath, err := storage.IssuePath(t.Context(), projectKey, issueID)
 	if err != nil {
 		t.Fatalf("Failed to resolve issue path: %v", err)
 	}
@@ -1369,13 +2003,13 @@ func TestDeleteIssue_WithYesFlag(t *testing.T) {
 	}
 
 	// Verify issue was removed from index
-	indexPath, err := storage.ProjectIndexPath(projectKey)
+	indexPath, err := storage.ProjectIndexPath(t.Context(), projectKey)
 	if err != nil {
 		t.Fatalf("Failed to resolve index path: %v", err)
 	}
 
 	var index models.ProjectIndex
-	if err := storage.ReadJSON(indexPath, &index); err != nil {
+	if err := storage.ReadIndex(t.Context(), indexPath, &index); err != nil {
 		t.Fatalf("Failed to read index: %v", err)
 	}
 
@@ -1387,7 +2021,7 @@ func TestDeleteIssue_WithYesFlag(t *testing.T) {
 func TestDeleteIssue_NonExistent(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -1422,3 +2056,53 @@ func TestDeleteIssue_NonExistent(t *testing.T) {
 		t.Errorf("Expected error about issue not found, got: %v", err)
 	}
 }
+
+func TestCommentIssue(t *testing.T) {
+	// Use unique project key to avoid conflicts
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	// Clean up after test
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "Commented"},
+	} {
+		rootCmd := NewRootCmd()
+		rootCmd.SetArgs(args)
+		rootCmd.SetOut(new(bytes.Buffer))
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	issueID := projectKey + "-1"
+	ctx := storage.WithEnv(t.Context(), storage.Env{Clock: storage.FixedClock(time.Date(2024, 6, 5, 12, 0, 0, 0, time.UTC))})
+
+	rootCmd := NewRootCmd()
+	rootCmd.SetArgs([]string{"issue", "comment", issueID, "Looks good"})
+	rootCmd.SetOut(new(bytes.Buffer))
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("issue comment failed: %v", err)
+	}
+
+	issuePath, _ := storage.IssuePath(t.Context(), projectKey, issueID)
+	var issue models.Issue
+	if err := storage.ReadJSON(ctx, issuePath, &issue); err != nil {
+		t.Fatalf("Failed to load issue: %v", err)
+	}
+	want := []models.Comment{{Body: "Looks good", CreatedAt: "2024-06-05T12:00:00Z", CreatedBy: currentActor(t.Context())}}
+	if !slices.Equal(issue.Comments, want) {
+		t.Errorf("Comments = %+v, want %+v", issue.Comments, want)
+	}
+
+	rootCmd2 := NewRootCmd()
+	rootCmd2.SetArgs([]string{"issue", "comment", issueID, "  "})
+	rootCmd2.SetOut(new(bytes.Buffer))
+	rootCmd2.SetErr(new(bytes.Buffer))
+	if err := rootCmd2.Execute(); err == nil || !strings.Contains(err.Error(), "required") {
+		t.Errorf("Expected error for empty comment, got: %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// maxIssueCandidates is how many matching issues an ambiguous ID lists.
+const maxIssueCandidates = 10
+
+// parseIssueID parses an issue ID into project key and sequence number like
+// models.ParseIssueID, telling date-based IDs from IDs of projects whose key
+// ends in a year: the year of CORE-2024-012 is part of the ID when the project
+// CORE uses the date scheme or there is no project CORE-2024, and part of the
+// key otherwise.
+func parseIssueID(ctx context.Context, id string) (string, int, error) {
+	projectKey, seq, err := models.ParseIssueID(id)
+	if err != nil {
+		return "", 0, err
+	}
+	dateKey, ok := models.DateIssueIDKey(id)
+	if !ok {
+		return projectKey, seq, nil
+	}
+	if projectDir, err := storage.ProjectDir(ctx, projectKey); err == nil {
+		if _, err := os.Stat(projectDir); err == nil {
+			if index, err := loadQueryIndex(ctx, dateKey); err != nil || index.IDScheme != models.IDSchemeDate {
+				return projectKey, seq, nil
+			}
+		}
+	}
+	return dateKey, seq, nil
+}
+
+// checkProjectKeyScheme refuses a project key and ID scheme that would make
+// issue IDs ambiguous: a key ending in a year (CORE-2024) next to a project
+// with date-based IDs without the year (CORE), or a project with date-based
+// IDs next to one whose key is its own with a year. Keys ending in a year are
+// fine otherwise, as parseIssueID then reads the year as part of the key.
+func checkProjectKeyScheme(ctx context.Context, projectKey, idScheme string) error {
+	if base, ok := models.YearKeyBase(projectKey); ok {
+		if index, err := loadQueryIndex(ctx, base); err == nil && index.IDScheme == models.IDSchemeDate {
+			return invalidf("cli: invalid project key %q (its issue IDs would read as date-based IDs of project %s)", projectKey, base)
+		}
+	}
+	if idScheme != models.IDSchemeDate {
+		return nil
+	}
+	keys, err := storage.ListProjects(ctx)
+	if err != nil {
+		return fmt.Errorf("cli: failed to list projects: %w", err)
+	}
+	for _, key := range keys {
+		if base, ok := models.YearKeyBase(key); ok && base == projectKey {
+			return invalidf("cli: project %s can't use date-based IDs (the issue IDs of project %s would read as its own)", projectKey, key)
+		}
+	}
+	return nil
+}
+
+// resolveIssueArgs replaces the issue ID arguments in ids with the full IDs
+// they stand for (see resolveIssueID).
+func resolveIssueArgs(cmd *cobra.Command, ids []string) error {
+	for i, id := range ids {
+		resolved, err := resolveIssueID(cmd, id)
+		if err != nil {
+			return err
+		}
+		ids[i] = resolved
+	}
+	return nil
+}
+
+// issueIDBySequence returns the ID of the issue of a project with a sequence
+// number, whatever the project's ID scheme (12 is CORE-0012 or CORE-2024-012).
+func issueIDBySequence(ctx context.Context, projectKey string, seq int) string {
+	if indexPath, err := storage.ProjectIndexPath(ctx, projectKey); err == nil {
+		var index models.ProjectIndex
+		if err := storage.ReadIndex(ctx, indexPath, &index); err == nil {
+			for _, entry := range index.Issues {
+				if _, n, err := models.ParseIssueID(entry.ID); err == nil && n == seq {
+					return entry.ID
+				}
+			}
+		}
+	}
+	return models.GenerateIssueID(projectKey, seq)
+}
+
+// prefixProjectKeys returns the keys of the projects an issue ID prefix with a
+// project key can be in: every project whose key and a hyphen start it, so
+// MY-APP-1 is looked up in MY-APP (and in MY, whose IDs could start with APP),
+// or, if there is none, the key before the last hyphen. It returns false for
+// a prefix without a hyphen.
+func prefixProjectKeys(ctx context.Context, id string) ([]string, bool) {
+	i := strings.LastIndex(id, "-")
+	if i < 0 {
+		return nil, false
+	}
+	var keys []string
+	if projects, err := storage.ListProjects(ctx); err == nil {
+		for _, key := range projects {
+			if strings.HasPrefix(id, key+"-") {
+				keys = append(keys, key)
+			}
+		}
+	}
+	if len(keys) == 0 {
+		keys = []string{id[:i]}
+	}
+	return keys, true
+}
+
+// resolveIssueID resolves an issue ID as typed on the command line: a full ID
+// in any case (core-12), a bare sequence number (12) of the --project or
+// default project, or a unique prefix of an issue ID or uid. Like completion,
+// a prefix with a project key ("CORE-1") is looked up in that project, and
+// one without in the resolved project, or in every project if none is set.
+// Full IDs, and IDs that match no issue, are returned for the command to look
+// up and report as usual.
+func resolveIssueID(cmd *cobra.Command, arg string) (string, error) {
+	id := strings.ToUpper(strings.TrimSpace(arg))
+	if seq, err := strconv.Atoi(id); err == nil && seq > 0 && strings.Trim(id, "0123456789") == "" {
+		projectKey, err := config.ResolveProject(cmd)
+		if err != nil {
+			return "", invalidf("cli: issue number %s needs a project (use --project or set default_project): %w", arg, err)
+		}
+		return issueIDBySequence(cmd.Context(), projectKey, seq), nil
+	}
+	if _, _, err := models.ParseIssueID(id); err == nil || id == "" {
+		return id, nil
+	}
+
+	var projectKeys []string
+	if keys, ok := prefixProjectKeys(cmd.Context(), id); ok {
+		projectKeys = keys
+	} else if key, err := config.ResolveProject(cmd); err == nil {
+		projectKeys = []string{key}
+	} else if keys, err := storage.ListProjects(cmd.Context()); err == nil {
+		projectKeys = keys
+	}
+
+	var matches []string
+	for _, projectKey := range projectKeys {
+		indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
+		if err != nil {
+			continue
+		}
+		var index models.ProjectIndex
+		if err := storage.ReadIndex(cmd.Context(), indexPath, &index); err != nil {
+			continue
+		}
+		for _, entry := range index.Issues {
+			if strings.HasPrefix(entry.ID, id) || (entry.UID != "" && strings.HasPrefix(entry.UID, id)) {
+				matches = append(matches, entry.ID)
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return arg, nil // Let the command report the ID as invalid or missing
+	case 1:
+		return matches[0], nil
+	}
+	shown := matches
+	if len(shown) > maxIssueCandidates {
+		shown = shown[:maxIssueCandidates]
+	}
+	candidates := strings.Join(shown, ", ")
+	if more := len(matches) - len(shown); more > 0 {
+		candidates += fmt.Sprintf(", and %d more", more)
+	}
+	return "", invalidf("cli: %q matches %d issues: %s (type more of the ID)", arg, len(matches), candidates)
+}
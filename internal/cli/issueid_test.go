@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestResolveIssueID(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(append(args, "--project", projectKey, "--no-hooks"))
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	mustRun := func(args ...string) string {
+		t.Helper()
+		out, err := run(args...)
+		if err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+		return out
+	}
+
+	mustRun("project", "create", projectKey)
+	for i := 0; i < 12; i++ {
+		mustRun("issue", "create", "--title", "Issue")
+	}
+	mustRun("project", "id-mode", projectKey, "ulid")
+	index, err := loadQueryIndex(t.Context(), projectKey)
+	if err != nil {
+		t.Fatalf("Failed to load index: %v", err)
+	}
+	uid := index.Issues[2].UID
+
+	tests := []struct {
+		name string
+		arg  string
+		want string
+	}{
+		{"full ID", projectKey + "-1", projectKey + "-1"},
+		{"bare number", "12", projectKey + "-12"},
+		{"leading zeros", "007", projectKey + "-7"},
+		{"lowercase", strings.ToLower(projectKey) + "-4", projectKey + "-4"},
+		{"uid prefix", strings.ToLower(uid[:12]), index.Issues[2].ID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := run("view", tt.arg, "--format", "json")
+			if err != nil {
+				t.Fatalf("view %s failed: %v", tt.arg, err)
+			}
+			if !strings.Contains(out, `"id": "`+tt.want+`"`) {
+				t.Errorf("view %s showed %q, want %s", tt.arg, out, tt.want)
+			}
+		})
+	}
+
+	if _, err := run("view", "nope"); err == nil || !strings.Contains(err.Error(), `invalid issue ID "nope"`) {
+		t.Errorf("Expected an ID matching no issue to be reported as usual, got %v", err)
+	}
+	_, err = run("view", projectKey+"-")
+	if err == nil || !strings.Contains(err.Error(), "matches 12 issues") || !strings.Contains(err.Error(), "and 2 more") {
+		t.Errorf("Expected an ambiguous prefix to list candidates, got %v", err)
+	}
+	if ExitCode(err) != ExitInvalid {
+		t.Errorf("Expected an ambiguous prefix to exit with %d, got %d", ExitInvalid, ExitCode(err))
+	}
+
+	mustRun("issue", "update", "3", "--status", "DOING")
+	if out := mustRun("view", projectKey+"-3", "--format", "json"); !strings.Contains(out, `"status": "DOING"`) {
+		t.Errorf("Expected update 3 to update %s-3, got %q", projectKey, out)
+	}
+}
+
+func TestResolveIssueID_HyphenatedKey(t *testing.T) {
+	projectKey := sanitizeTestName("TEST"+t.Name()) + "-APP"
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(append(args, "--no-hooks"))
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	if _, err := run("project", "create", projectKey); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	for i := 0; i < 12; i++ {
+		if _, err := run("issue", "create", "--project", projectKey, "--title", "Issue"); err != nil {
+			t.Fatalf("Failed to create issue: %v", err)
+		}
+	}
+
+	out, err := run("view", strings.ToLower(projectKey)+"-12", "--format", "json")
+	if err != nil || !strings.Contains(out, `"id": "`+projectKey+`-12"`) {
+		t.Errorf("Expected the full ID to resolve to %s-12, got %q, %v", projectKey, out, err)
+	}
+	_, err = run("view", projectKey+"-")
+	if err == nil || !strings.Contains(err.Error(), "matches 12 issues") {
+		t.Errorf("Expected a prefix of a hyphenated key to be looked up in its project, got %v", err)
+	}
+}
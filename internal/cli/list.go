@@ -1,7 +1,13 @@
 package cli
 
 import (
+	"cmp"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"slices"
+	"strings"
 
 	"github.com/buyruk-project/buyruk-cli/internal/config"
 	"github.com/buyruk-project/buyruk-cli/internal/models"
@@ -15,64 +21,459 @@ func NewListCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List project issues",
-		Long:  "List all issues in a project using the project index",
+		Long: `List the issues of a project using the project index.
+
+Filters, sorting, and pagination are applied to the index before any issue
+file is loaded. Filter flags take comma-separated alternatives ("none" matches
+an unset field), and all given filters must match. --filter takes a query
+filter like query --count and share create (e.g. "type=bug and status!=DONE");
+save one with buyruk view save to rerun it by name.
+
+Issues snoozed with buyruk issue snooze are left out until their snooze ends;
+--include-snoozed lists them too.
+
+--all-projects lists the issues of every project in one list, with the same
+filters, sort, and page; issue IDs carry their project key.`,
+		Example: `  buyruk list --status TODO,DOING --assignee alice
+  buyruk list --label infra --sort priority:desc --limit 10
+  buyruk list --search login --epic E-2
+  buyruk list --sort id:desc --limit 20 --offset 20
+  buyruk list --columns id,title,assignee,due --max-width 100
+  buyruk list --all-projects --assignee alice --status TODO,DOING --sort priority:desc`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return listIssues(cmd)
 		},
 	}
 
+	addListFlags(cmd)
+
 	return cmd
 }
 
-// listIssues lists all issues in the current project.
+// addListFlags adds the filter, sort, page, and output flags of list to a
+// command that lists issues with listIssues.
+func addListFlags(cmd *cobra.Command) {
+	addTemplateFlags(cmd, "Render each issue with a Go template")
+	cmd.Flags().String("parent", "", "Only list subtasks of this issue")
+	cmd.Flags().String("status", "", "Only list issues with these statuses (comma-separated)")
+	cmd.Flags().String("type", "", "Only list issues of these types (comma-separated)")
+	cmd.Flags().String("priority", "", "Only list issues with these priorities (comma-separated)")
+	cmd.Flags().String("epic", "", "Only list issues of these epics (comma-separated, \"none\" for no epic)")
+	cmd.Flags().String("milestone", "", "Only list issues with these fix versions (comma-separated, \"none\" for no fix version)")
+	cmd.Flags().String("assignee", "", "Only list issues assigned to these users (comma-separated, \"none\" for unassigned)")
+	cmd.Flags().String("label", "", "Only list issues with one of these labels (comma-separated)")
+	cmd.Flags().String("component", "", "Only list issues in one of these components (comma-separated, \"none\" for no component)")
+	cmd.Flags().String("filter", "", "Only list issues matching a query filter (e.g. \"type=bug and status!=DONE\")")
+	cmd.Flags().String("search", "", "Only list issues whose ID or title contains this text (case-insensitive)")
+	cmd.Flags().Bool("include-snoozed", false, "Also list issues snoozed with issue snooze")
+	cmd.Flags().String("sort", "", "Sort by field[:asc|desc] ("+strings.Join(listSortFields, ", ")+")")
+	cmd.Flags().Int("limit", 0, "List at most this many issues (0 for all)")
+	cmd.Flags().Int("offset", 0, "Skip this many issues before listing")
+	cmd.Flags().String("columns", "", "Table columns to show, in order ("+strings.Join(ui.IssueColumns, ", ")+")")
+	cmd.Flags().Bool("no-truncate", false, "Show long values in full instead of wrapping them")
+	cmd.Flags().Int("max-width", 0, "Shorten titles so table rows fit in this many characters")
+	cmd.MarkFlagsMutuallyExclusive("no-truncate", "max-width")
+	cmd.Flags().Bool("watch", false, "Keep running and re-render the list when the project changes")
+	cmd.Flags().Bool("all-projects", false, "List the issues of every project instead of one")
+	cmd.MarkFlagsMutuallyExclusive("all-projects", "watch")
+	cmd.RegisterFlagCompletionFunc("parent", completeIssueIDs)
+	cmd.RegisterFlagCompletionFunc("epic", completeEpicIDs)
+	cmd.RegisterFlagCompletionFunc("milestone", completeMilestoneIDs)
+	cmd.RegisterFlagCompletionFunc("component", completeComponentNames)
+}
+
+// listIssues lists all issues in the current project, or in every project
+// with --all-projects.
 func listIssues(cmd *cobra.Command) error {
+	if all, _ := cmd.Flags().GetBool("all-projects"); all {
+		return listAllProjects(cmd)
+	}
+
 	// Resolve project
 	projectKey, err := config.ResolveProject(cmd)
 	if err != nil {
 		return err
 	}
 
-	// Load project index
-	indexPath, err := storage.ProjectIndexPath(projectKey)
+	// Parse filters once, so a watched list doesn't repeat flag errors
+	wf, err := loadWorkflow(cmd.Context(), projectKey)
 	if err != nil {
-		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+		return err
+	}
+	opts, err := parseListOptions(cmd, wf)
+	if err != nil {
+		return err
 	}
 
-	var index models.ProjectIndex
-	if err := storage.ReadJSON(indexPath, &index); err != nil {
-		return fmt.Errorf("cli: failed to load project index: %w", err)
+	if watch, _ := cmd.Flags().GetBool("watch"); watch {
+		return watchProject(cmd, projectKey, func(w io.Writer) error {
+			return renderProjectIssues(cmd, projectKey, opts, w)
+		})
+	}
+	return renderProjectIssues(cmd, projectKey, opts, cmd.OutOrStdout())
+}
+
+// renderProjectIssues renders the issues of a project to w.
+func renderProjectIssues(cmd *cobra.Command, projectKey string, opts *listOptions, w io.Writer) error {
+	entries, err := loadListEntries(cmd, projectKey)
+	if err != nil {
+		return err
+	}
+	return renderListEntries(cmd, opts.apply(cmd.Context(), entries), opts, w)
+}
+
+// listAllProjects lists the issues of every project as one list: filters,
+// sort, and page apply to the index entries of all projects together.
+func listAllProjects(cmd *cobra.Command) error {
+	if cmd.Flags().Changed("project") {
+		return invalidf("cli: --all-projects can't be combined with --project")
+	}
+	keys, err := storage.ListProjects(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("cli: failed to list projects: %w", err)
+	}
+
+	workflows := make([]*models.Workflow, 0, len(keys))
+	for _, key := range keys {
+		wf, err := loadWorkflow(cmd.Context(), key)
+		if err != nil {
+			return err
+		}
+		workflows = append(workflows, wf)
+	}
+	opts, err := parseListOptions(cmd, mergedWorkflow(workflows))
+	if err != nil {
+		return err
+	}
+
+	var entries []models.IndexEntry
+	for _, key := range keys {
+		projectEntries, err := loadListEntries(cmd, key)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: skipping project %s: %v\n", key, err)
+			continue
+		}
+		entries = append(entries, projectEntries...)
+	}
+	return renderListEntries(cmd, opts.apply(cmd.Context(), entries), opts, cmd.OutOrStdout())
+}
+
+// mergedWorkflow returns a workflow with the statuses, types, and priorities
+// of all the workflows, in the order they first appear, so a list across
+// projects accepts and orders the values of any of them.
+func mergedWorkflow(workflows []*models.Workflow) *models.Workflow {
+	merged := &models.Workflow{}
+	for _, wf := range workflows {
+		for _, status := range wf.StatusList() {
+			if !slices.Contains(merged.Statuses, status) {
+				merged.Statuses = append(merged.Statuses, status)
+			}
+		}
+		for _, t := range wf.TypeList() {
+			if !slices.Contains(merged.Types, t) {
+				merged.Types = append(merged.Types, t)
+			}
+		}
+		for _, priority := range wf.PriorityList() {
+			if !slices.Contains(merged.Priorities, priority) {
+				merged.Priorities = append(merged.Priorities, priority)
+			}
+		}
+	}
+	return merged
+}
+
+// loadListEntries loads the index entries of a project that list starts
+// from: all of them, or the subtasks of --parent.
+func loadListEntries(cmd *cobra.Command, projectKey string) ([]models.IndexEntry, error) {
+	index, err := loadQueryIndex(cmd.Context(), projectKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Filter to subtasks of a parent issue if requested
+	if parentID, _ := cmd.Flags().GetString("parent"); parentID != "" {
+		return index.Children(parentID), nil
+	}
+	return index.Issues, nil
+}
+
+// renderListEntries loads the issues of index entries and renders them to w.
+func renderListEntries(cmd *cobra.Command, entries []models.IndexEntry, opts *listOptions, w io.Writer) error {
+	templateText, useTemplate, err := resolveTemplate(cmd)
+	if err != nil {
+		return err
+	}
+
+	// NDJSON is written as each issue is loaded, so large projects stream
+	// through jq without waiting for the whole list
+	var stream *json.Encoder
+	if !useTemplate && config.ResolveFormat(cmd) == config.DefaultFormatNDJSON {
+		stream = json.NewEncoder(w)
 	}
 
 	// Convert index entries to issues (load full issue data)
 	issues := []*models.Issue{}
 
-	for _, entry := range index.Issues {
-		issuePath, err := storage.IssuePath(projectKey, entry.ID)
+	for _, entry := range entries {
+		projectKey, _, err := parseIssueID(cmd.Context(), entry.ID)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: skipping issue with invalid ID %q: %v\n", entry.ID, err)
+			continue
+		}
+		issuePath, err := storage.IssuePath(cmd.Context(), projectKey, entry.ID)
 		if err != nil {
 			return fmt.Errorf("cli: failed to resolve issue path: %w", err)
 		}
 
 		var issue models.Issue
-		if err := storage.ReadJSON(issuePath, &issue); err != nil {
+		if err := storage.ReadJSON(cmd.Context(), issuePath, &issue); err != nil {
 			// Log warning but continue
 			errOut := cmd.ErrOrStderr()
 			fmt.Fprintf(errOut, "Warning: failed to load issue %s: %v\n", entry.ID, err)
 			continue
 		}
 
+		if stream != nil {
+			if err := stream.Encode(&issue); err != nil {
+				return fmt.Errorf("cli: failed to render issue list: %w", err)
+			}
+			continue
+		}
 		issues = append(issues, &issue)
 	}
+	if stream != nil {
+		return nil
+	}
+
+	// Render through a custom template if requested
+	if useTemplate {
+		if err := renderIssuesWithTemplate(cmd.Context(), templateText, issues, w); err != nil {
+			return fmt.Errorf("cli: failed to render issue list: %w", err)
+		}
+		return nil
+	}
 
 	// Render using UI layer
 	renderer, err := ui.GetRenderer(cmd)
 	if err != nil {
 		return fmt.Errorf("cli: failed to get renderer: %w", err)
 	}
+	if modern, ok := renderer.(*ui.ModernRenderer); ok {
+		modern.SetTableOptions(opts.table)
+	}
 
-	out := cmd.OutOrStdout()
-	if err := renderer.RenderIssueList(issues, out); err != nil {
+	if err := renderer.RenderIssueList(issues, w); err != nil {
 		return fmt.Errorf("cli: failed to render issue list: %w", err)
 	}
 
 	return nil
 }
+
+// listSortFields lists the index fields list --sort accepts.
+var listSortFields = []string{"id", "title", "status", "type", "priority", "epic", "assignee", "rank"}
+
+// listOptions holds the filters, sort order, and page of a list command.
+type listOptions struct {
+	conditions     []queryCondition
+	search         string
+	includeSnoozed bool // List snoozed issues too
+	sortField      string
+	descending     bool
+	limit          int
+	offset         int
+	table          ui.TableOptions // Columns and width of the modern table
+
+	// Workflow orders, so statuses and priorities sort by meaning rather than name
+	statusOrder   []string
+	priorityOrder []string
+}
+
+// parseListOptions reads the filter, sort, and pagination flags of list.
+func parseListOptions(cmd *cobra.Command, wf *models.Workflow) (*listOptions, error) {
+	opts := &listOptions{statusOrder: wf.StatusList(), priorityOrder: wf.PriorityList()}
+
+	filters := []struct {
+		flag, field string
+		allowed     []string
+	}{
+		{"status", "status", wf.StatusList()},
+		{"type", "type", wf.TypeList()},
+		{"priority", "priority", wf.PriorityList()},
+		{"epic", "epic", nil},
+		{"milestone", "milestone", nil},
+		{"assignee", "assignee", nil},
+		{"label", "label", nil},
+		{"component", "component", nil},
+	}
+	for _, filter := range filters {
+		value, _ := cmd.Flags().GetString(filter.flag)
+		values := splitList(value)
+		if len(values) == 0 {
+			continue
+		}
+		for i, v := range values {
+			if strings.EqualFold(v, "none") {
+				values[i] = ""
+				continue
+			}
+			if filter.allowed != nil && !slices.ContainsFunc(filter.allowed, func(a string) bool { return strings.EqualFold(a, v) }) {
+				return nil, invalidf("cli: invalid %s %q (allowed: %s)", filter.flag, v, strings.Join(filter.allowed, ", "))
+			}
+		}
+		opts.conditions = append(opts.conditions, queryCondition{field: filter.field, values: values})
+	}
+
+	filter, _ := cmd.Flags().GetString("filter")
+	conditions, err := parseQueryFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	opts.conditions = append(opts.conditions, conditions...)
+
+	opts.search, _ = cmd.Flags().GetString("search")
+	opts.search = strings.ToLower(strings.TrimSpace(opts.search))
+	opts.includeSnoozed, _ = cmd.Flags().GetBool("include-snoozed")
+
+	if sortValue, _ := cmd.Flags().GetString("sort"); sortValue != "" {
+		field, direction, _ := strings.Cut(sortValue, ":")
+		opts.sortField = strings.ToLower(strings.TrimSpace(field))
+		if !slices.Contains(listSortFields, opts.sortField) {
+			return nil, invalidf("cli: invalid sort field %q (allowed: %s)", field, strings.Join(listSortFields, ", "))
+		}
+		switch strings.ToLower(strings.TrimSpace(direction)) {
+		case "", "asc":
+		case "desc":
+			opts.descending = true
+		default:
+			return nil, invalidf("cli: invalid sort direction %q (must be asc or desc)", direction)
+		}
+	}
+
+	opts.limit, _ = cmd.Flags().GetInt("limit")
+	opts.offset, _ = cmd.Flags().GetInt("offset")
+	if opts.limit < 0 || opts.offset < 0 {
+		return nil, invalidf("cli: --limit and --offset must not be negative")
+	}
+
+	if value, _ := cmd.Flags().GetString("columns"); value != "" {
+		for _, column := range splitList(strings.ToLower(value)) {
+			if !slices.Contains(ui.IssueColumns, column) {
+				return nil, invalidf("cli: unknown column %q (use %s)", column, strings.Join(ui.IssueColumns, ", "))
+			}
+			if slices.Contains(opts.table.Columns, column) {
+				return nil, fmt.Errorf("cli: column %q is listed twice", column)
+			}
+			opts.table.Columns = append(opts.table.Columns, column)
+		}
+	}
+	opts.table.NoTruncate, _ = cmd.Flags().GetBool("no-truncate")
+	opts.table.MaxWidth, _ = cmd.Flags().GetInt("max-width")
+	if opts.table.MaxWidth < 0 {
+		return nil, invalidf("cli: --max-width must not be negative")
+	}
+	return opts, nil
+}
+
+// apply filters, sorts, and pages index entries, leaving out snoozed issues
+// unless includeSnoozed is set. The index is not modified.
+func (opts *listOptions) apply(ctx context.Context, entries []models.IndexEntry) []models.IndexEntry {
+	result := []models.IndexEntry{}
+	now := storage.Now(ctx)
+	for i := range entries {
+		entry := &entries[i]
+		if !matchesQuery(entry, opts.conditions) {
+			continue
+		}
+		if !opts.includeSnoozed && entry.IsSnoozed(now) {
+			continue
+		}
+		if opts.search != "" &&
+			!strings.Contains(strings.ToLower(entry.Title), opts.search) &&
+			!strings.Contains(strings.ToLower(entry.ID), opts.search) {
+			continue
+		}
+		result = append(result, *entry)
+	}
+
+	if opts.sortField != "" {
+		slices.SortStableFunc(result, func(a, b models.IndexEntry) int {
+			// Unset values sort last in both directions
+			unsetA, unsetB := opts.sortValue(&a) == "", opts.sortValue(&b) == ""
+			if unsetA != unsetB {
+				if unsetA {
+					return 1
+				}
+				return -1
+			}
+			c := opts.compare(&a, &b)
+			if opts.descending {
+				return -c
+			}
+			return c
+		})
+	}
+
+	if opts.offset >= len(result) {
+		return []models.IndexEntry{}
+	}
+	result = result[opts.offset:]
+	if opts.limit > 0 && opts.limit < len(result) {
+		result = result[:opts.limit]
+	}
+	return result
+}
+
+// sortValue returns the value of the sort field of an entry.
+func (opts *listOptions) sortValue(entry *models.IndexEntry) string {
+	switch opts.sortField {
+	case "id":
+		return entry.ID
+	case "title":
+		return entry.Title
+	case "status":
+		return entry.Status
+	case "type":
+		return entry.Type
+	case "priority":
+		return entry.Priority
+	case "epic":
+		return entry.EpicID
+	case "assignee":
+		return entry.Assignee
+	case "rank":
+		return entry.Rank
+	}
+	return ""
+}
+
+// compare orders two index entries by the sort field. IDs compare by project
+// key and sequence number (ULID suffixes by creation time), statuses and priorities by their workflow order,
+// ranks as they are, and other fields case-insensitively.
+func (opts *listOptions) compare(a, b *models.IndexEntry) int {
+	switch opts.sortField {
+	case "id":
+		keyA, seqA, _ := models.ParseIssueID(a.ID)
+		keyB, seqB, _ := models.ParseIssueID(b.ID)
+		return cmp.Or(strings.Compare(keyA, keyB), cmp.Compare(seqA, seqB), strings.Compare(a.ID, b.ID))
+	case "status":
+		return compareOrdered(opts.statusOrder, a.Status, b.Status)
+	case "priority":
+		return compareOrdered(opts.priorityOrder, a.Priority, b.Priority)
+	case "rank":
+		return models.CompareRanks(a.Rank, b.Rank)
+	}
+	return strings.Compare(strings.ToLower(opts.sortValue(a)), strings.ToLower(opts.sortValue(b)))
+}
+
+// compareOrdered compares values by their position in order; values not in
+// order sort after those that are.
+func compareOrdered(order []string, a, b string) int {
+	rank := func(v string) int {
+		if i := slices.Index(order, v); i >= 0 {
+			return i
+		}
+		return len(order)
+	}
+	return cmp.Compare(rank(a), rank(b))
+}
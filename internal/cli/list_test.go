@@ -2,7 +2,9 @@ package cli
 
 import (
 	"bytes"
+	"encoding/json"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -26,7 +28,7 @@ func TestListIssues_WithProjectFlag(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -46,28 +48,28 @@ func TestListIssues_WithProjectFlag(t *testing.T) {
 		Status: models.StatusTODO,
 	}
 
-	issuePath, err := storage.IssuePath(projectKey, projectKey+"-1")
+	issuePath, err := storage.IssuePath(t.Context(), projectKey, projectKey+"-1")
 	if err != nil {
 		t.Fatalf("Failed to resolve issue path: %v", err)
 	}
 
-	if err := storage.WriteJSONAtomic(issuePath, issue); err != nil {
+	if err := storage.WriteJSONAtomic(t.Context(), issuePath, issue); err != nil {
 		t.Fatalf("Failed to write issue: %v", err)
 	}
 
 	// Update index manually
-	indexPath, err := storage.ProjectIndexPath(projectKey)
+	indexPath, err := storage.ProjectIndexPath(t.Context(), projectKey)
 	if err != nil {
 		t.Fatalf("Failed to resolve index path: %v", err)
 	}
 
 	var index models.ProjectIndex
-	if err := storage.ReadJSON(indexPath, &index); err != nil {
+	if err := storage.ReadIndex(t.Context(), indexPath, &index); err != nil {
 		t.Fatalf("Failed to read index: %v", err)
 	}
 
 	index.AddIssue(issue)
-	if err := storage.WriteJSONAtomic(indexPath, &index); err != nil {
+	if err := storage.WriteJSONAtomic(t.Context(), indexPath, &index); err != nil {
 		t.Fatalf("Failed to update index: %v", err)
 	}
 
@@ -96,14 +98,14 @@ func TestListIssues_WithConfigProject(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 		// Clear config
-		config.Set("default_project", "")
+		config.Set(t.Context(), "default_project", "")
 	}()
 
 	// Set default project in config
-	if err := config.Set("default_project", projectKey); err != nil {
+	if err := config.Set(t.Context(), "default_project", projectKey); err != nil {
 		t.Fatalf("Failed to set default project: %v", err)
 	}
 
@@ -123,28 +125,28 @@ func TestListIssues_WithConfigProject(t *testing.T) {
 		Status: models.StatusTODO,
 	}
 
-	issuePath, err := storage.IssuePath(projectKey, projectKey+"-1")
+	issuePath, err := storage.IssuePath(t.Context(), projectKey, projectKey+"-1")
 	if err != nil {
 		t.Fatalf("Failed to resolve issue path: %v", err)
 	}
 
-	if err := storage.WriteJSONAtomic(issuePath, issue); err != nil {
+	if err := storage.WriteJSONAtomic(t.Context(), issuePath, issue); err != nil {
 		t.Fatalf("Failed to write issue: %v", err)
 	}
 
 	// Update index manually
-	indexPath, err := storage.ProjectIndexPath(projectKey)
+	indexPath, err := storage.ProjectIndexPath(t.Context(), projectKey)
 	if err != nil {
 		t.Fatalf("Failed to resolve index path: %v", err)
 	}
 
 	var index models.ProjectIndex
-	if err := storage.ReadJSON(indexPath, &index); err != nil {
+	if err := storage.ReadIndex(t.Context(), indexPath, &index); err != nil {
 		t.Fatalf("Failed to read index: %v", err)
 	}
 
 	index.AddIssue(issue)
-	if err := storage.WriteJSONAtomic(indexPath, &index); err != nil {
+	if err := storage.WriteJSONAtomic(t.Context(), indexPath, &index); err != nil {
 		t.Fatalf("Failed to update index: %v", err)
 	}
 
@@ -173,12 +175,12 @@ func TestListIssues_NoProject(t *testing.T) {
 	originalCfg, _ := config.Get()
 	defer func() {
 		if originalCfg != nil {
-			config.Save(originalCfg)
+			config.Save(t.Context(), originalCfg)
 		}
 	}()
 
 	// Clear default_project
-	if err := config.Set("default_project", ""); err != nil {
+	if err := config.Set(t.Context(), "default_project", ""); err != nil {
 		t.Fatalf("Failed to clear config: %v", err)
 	}
 
@@ -213,8 +215,11 @@ func TestListIssues_MissingProject(t *testing.T) {
 		t.Fatal("list command should fail when project does not exist")
 	}
 
-	if !strings.Contains(err.Error(), "failed to load project index") {
-		t.Errorf("Expected error about failed to load project index, got: %v", err)
+	if !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("Expected error about the missing project, got: %v", err)
+	}
+	if code := ExitCode(err); code != ExitNotFound {
+		t.Errorf("Expected exit code %d, got %d", ExitNotFound, code)
 	}
 }
 
@@ -223,7 +228,7 @@ func TestListIssues_EmptyProject(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -258,7 +263,7 @@ func TestListIssues_WithFormatFlags(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -278,28 +283,28 @@ func TestListIssues_WithFormatFlags(t *testing.T) {
 		Status: models.StatusTODO,
 	}
 
-	issuePath, err := storage.IssuePath(projectKey, projectKey+"-1")
+	issuePath, err := storage.IssuePath(t.Context(), projectKey, projectKey+"-1")
 	if err != nil {
 		t.Fatalf("Failed to resolve issue path: %v", err)
 	}
 
-	if err := storage.WriteJSONAtomic(issuePath, issue); err != nil {
+	if err := storage.WriteJSONAtomic(t.Context(), issuePath, issue); err != nil {
 		t.Fatalf("Failed to write issue: %v", err)
 	}
 
 	// Update index manually
-	indexPath, err := storage.ProjectIndexPath(projectKey)
+	indexPath, err := storage.ProjectIndexPath(t.Context(), projectKey)
 	if err != nil {
 		t.Fatalf("Failed to resolve index path: %v", err)
 	}
 
 	var index models.ProjectIndex
-	if err := storage.ReadJSON(indexPath, &index); err != nil {
+	if err := storage.ReadIndex(t.Context(), indexPath, &index); err != nil {
 		t.Fatalf("Failed to read index: %v", err)
 	}
 
 	index.AddIssue(issue)
-	if err := storage.WriteJSONAtomic(indexPath, &index); err != nil {
+	if err := storage.WriteJSONAtomic(t.Context(), indexPath, &index); err != nil {
 		t.Fatalf("Failed to update index: %v", err)
 	}
 
@@ -336,6 +341,25 @@ func TestListIssues_WithFormatFlags(t *testing.T) {
 	if !strings.Contains(output2, "@") {
 		t.Errorf("Expected LSON output with @ prefix, got: %s", output2)
 	}
+
+	// Test NDJSON format: one compact object per issue, no array
+	rootCmd4 := NewRootCmd()
+	rootCmd4.SetArgs([]string{"list", "--project", projectKey, "--format", "ndjson"})
+
+	buf3 := new(bytes.Buffer)
+	rootCmd4.SetOut(buf3)
+
+	if err := rootCmd4.Execute(); err != nil {
+		t.Fatalf("list command with ndjson format failed: %v", err)
+	}
+
+	var listed models.Issue
+	if err := json.Unmarshal(buf3.Bytes(), &listed); err != nil || listed.ID != issue.ID {
+		t.Errorf("Expected one NDJSON issue line, got: %s", buf3.String())
+	}
+	if strings.Count(buf3.String(), "\n") != 1 {
+		t.Errorf("Expected a single line, got: %s", buf3.String())
+	}
 }
 
 func TestListIssues_MissingIssueFile(t *testing.T) {
@@ -343,7 +367,7 @@ func TestListIssues_MissingIssueFile(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -356,13 +380,13 @@ func TestListIssues_MissingIssueFile(t *testing.T) {
 	}
 
 	// Manually add an index entry for a non-existent issue file
-	indexPath, err := storage.ProjectIndexPath(projectKey)
+	indexPath, err := storage.ProjectIndexPath(t.Context(), projectKey)
 	if err != nil {
 		t.Fatalf("Failed to resolve index path: %v", err)
 	}
 
 	var index models.ProjectIndex
-	if err := storage.ReadJSON(indexPath, &index); err != nil {
+	if err := storage.ReadIndex(t.Context(), indexPath, &index); err != nil {
 		t.Fatalf("Failed to read index: %v", err)
 	}
 
@@ -374,7 +398,7 @@ func TestListIssues_MissingIssueFile(t *testing.T) {
 		Type:   models.TypeTask,
 	})
 
-	if err := storage.WriteJSONAtomic(indexPath, &index); err != nil {
+	if err := storage.WriteJSONAtomic(t.Context(), indexPath, &index); err != nil {
 		t.Fatalf("Failed to update index: %v", err)
 	}
 
@@ -398,3 +422,315 @@ func TestListIssues_MissingIssueFile(t *testing.T) {
 		t.Logf("Note: No warning about missing issue file (this is acceptable)")
 	}
 }
+
+func TestListIssues_NamedTemplate(t *testing.T) {
+	// Use unique project key to avoid conflicts (sanitize test name)
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	originalCfg, _ := config.Get()
+	// Clean up after test
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+		if originalCfg != nil {
+			config.Save(t.Context(), originalCfg)
+		}
+	}()
+
+	if err := config.Set(t.Context(), "template.compact", "{{.ID}}|{{lower .Status}}"); err != nil {
+		t.Fatalf("Failed to set template: %v", err)
+	}
+
+	// Create project and issues
+	rootCmd := NewRootCmd()
+	rootCmd.SetArgs([]string{"project", "create", projectKey})
+	rootCmd.SetOut(new(bytes.Buffer))
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	for _, title := range []string{"First", "Second"} {
+		createCmd := NewRootCmd()
+		createCmd.SetArgs([]string{"issue", "create", "--project", projectKey, "--title", title})
+		createCmd.SetOut(new(bytes.Buffer))
+		if err := createCmd.Execute(); err != nil {
+			t.Fatalf("Failed to create issue: %v", err)
+		}
+	}
+
+	// List issues with the named template
+	rootCmd2 := NewRootCmd()
+	rootCmd2.SetArgs([]string{"list", "--project", projectKey, "--template", "compact"})
+
+	buf := new(bytes.Buffer)
+	rootCmd2.SetOut(buf)
+
+	if err := rootCmd2.Execute(); err != nil {
+		t.Fatalf("list command failed: %v", err)
+	}
+
+	want := projectKey + "-1|todo\n" + projectKey + "-2|todo\n"
+	if buf.String() != want {
+		t.Errorf("Expected output %q, got %q", want, buf.String())
+	}
+}
+
+func TestListIssues_FilterSortPage(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"epic", "create", "--project", projectKey, "--title", "Auth"},
+		{"issue", "create", "--project", projectKey, "--title", "Login page", "--priority", "LOW", "--labels", "ui", "--assignee", "alice"},
+		{"issue", "create", "--project", projectKey, "--title", "Token refresh", "--priority", "HIGH", "--labels", "infra", "--epic", projectKey + "-E1", "--status", "DOING"},
+		{"issue", "create", "--project", projectKey, "--title", "Login audit log", "--type", "bug", "--labels", "infra,ui", "--assignee", "alice"},
+		{"issue", "create", "--project", projectKey, "--title", "Docs", "--priority", "MEDIUM"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	// list returns the IDs listed, in order, without the project prefix
+	list := func(args ...string) string {
+		t.Helper()
+		out, err := run(append([]string{"list", "--project", projectKey, "--format", "json"}, args...)...)
+		if err != nil {
+			t.Fatalf("list %v failed: %v", args, err)
+		}
+		var issues []models.Issue
+		if err := json.Unmarshal([]byte(out), &issues); err != nil {
+			t.Fatalf("Failed to parse list output: %v\n%s", err, out)
+		}
+		var ids []string
+		for _, issue := range issues {
+			ids = append(ids, strings.TrimPrefix(issue.ID, projectKey+"-"))
+		}
+		return strings.Join(ids, ",")
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"all", nil, "1,2,3,4"},
+		{"status", []string{"--status", "DOING"}, "2"},
+		{"status alternatives", []string{"--status", "todo,doing"}, "1,2,3,4"},
+		{"type", []string{"--type", "BUG"}, "3"},
+		{"priority", []string{"--priority", "LOW,HIGH"}, "1,2"},
+		{"epic", []string{"--epic", projectKey + "-E1"}, "2"},
+		{"no epic", []string{"--epic", "none"}, "1,3,4"},
+		{"assignee", []string{"--assignee", "alice"}, "1,3"},
+		{"unassigned", []string{"--assignee", "none"}, "2,4"},
+		{"label", []string{"--label", "infra"}, "2,3"},
+		{"search title", []string{"--search", "LOGIN"}, "1,3"},
+		{"combined", []string{"--label", "ui", "--search", "audit"}, "3"},
+		{"sort priority desc", []string{"--sort", "priority:desc"}, "2,4,1,3"},
+		{"sort priority asc", []string{"--sort", "priority"}, "1,4,2,3"},
+		{"sort title", []string{"--sort", "title:asc"}, "4,3,1,2"},
+		{"sort id desc", []string{"--sort", "id:desc"}, "4,3,2,1"},
+		{"sort status", []string{"--sort", "status"}, "1,3,4,2"},
+		{"limit", []string{"--limit", "2"}, "1,2"},
+		{"offset", []string{"--offset", "1", "--limit", "2"}, "2,3"},
+		{"offset past end", []string{"--offset", "10"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := list(tt.args...); got != tt.want {
+				t.Errorf("list %v = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+
+	for _, args := range [][]string{
+		{"--status", "WAITING"},
+		{"--sort", "created"},
+		{"--sort", "priority:up"},
+		{"--limit", "-1"},
+	} {
+		if _, err := run(append([]string{"list", "--project", projectKey}, args...)...); err == nil {
+			t.Errorf("Expected list %v to fail", args)
+		}
+	}
+}
+
+func TestListIssues_TemplateFormat(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "First"},
+		{"issue", "create", "--project", projectKey, "--title", "Second", "--status", "DOING"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	out, err := run("list", "--project", projectKey, "--format", "template", "--template", "{{.ID}} {{.Status}} {{.Title}}")
+	if err != nil {
+		t.Fatalf("list --format template failed: %v", err)
+	}
+	if !strings.Contains(out, projectKey+"-1 TODO First\n") || !strings.Contains(out, projectKey+"-2 DOING Second\n") {
+		t.Errorf("Unexpected template output: %s", out)
+	}
+
+	templateFile := filepath.Join(t.TempDir(), "issue.tmpl")
+	os.WriteFile(templateFile, []byte("{{.ID}}={{lower .Status}}\n"), 0644)
+	out, err = run("view", projectKey+"-2", "--template-file", templateFile)
+	if err != nil {
+		t.Fatalf("view --template-file failed: %v", err)
+	}
+	if out != projectKey+"-2=doing\n" {
+		t.Errorf("view --template-file output = %q", out)
+	}
+
+	if _, err := run("list", "--project", projectKey, "--format", "template"); err == nil || !strings.Contains(err.Error(), "requires --template") {
+		t.Errorf("Expected --format template without a template to fail, got %v", err)
+	}
+	if _, err := run("list", "--project", projectKey, "--template", "x", "--template-file", templateFile); err == nil {
+		t.Error("Expected --template and --template-file together to fail")
+	}
+	if _, err := run("list", "--project", projectKey, "--template-file", filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected a missing template file to fail")
+	}
+}
+
+func TestListIssues_Columns(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "Rotate keys", "--assignee", "alice", "--due", "2030-05-01"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	out, err := run("list", "--project", projectKey, "--format", "modern", "--columns", "id,title,assignee,due")
+	if err != nil {
+		t.Fatalf("list --columns failed: %v", err)
+	}
+	for _, want := range []string{"ASSIGNEE", "DUE", "alice", "2030-05-01"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in table, got: %s", want, out)
+		}
+	}
+	if strings.Contains(out, "PRIORITY") {
+		t.Errorf("Expected only the selected columns, got: %s", out)
+	}
+
+	for _, args := range [][]string{
+		{"--columns", "id,owner"},
+		{"--columns", "id,title,id"},
+		{"--max-width", "-1"},
+		{"--no-truncate", "--max-width", "80"},
+	} {
+		if _, err := run(append([]string{"list", "--project", projectKey}, args...)...); err == nil {
+			t.Errorf("Expected list %v to fail", args)
+		}
+	}
+}
+
+func TestListIssues_AllProjects(t *testing.T) {
+	keyA := sanitizeTestName("TEST" + t.Name() + "A")
+	keyB := sanitizeTestName("TEST" + t.Name() + "B")
+	defer func() {
+		for _, key := range []string{keyA, keyB} {
+			projectDir, _ := storage.ProjectDir(t.Context(), key)
+			os.RemoveAll(projectDir)
+		}
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return strings.Join(strings.Fields(out.String()), " "), err
+	}
+	for _, args := range [][]string{
+		{"project", "create", keyA},
+		{"project", "create", keyB},
+		{"project", "workflow", keyB, "--statuses", "OPEN,REVIEW,CLOSED", "--done-statuses", "CLOSED"},
+		{"issue", "create", "--project", keyA, "--title", "Quokka login", "--priority", "LOW"},
+		{"issue", "create", "--project", keyA, "--title", "Quokka signup", "--priority", "HIGH"},
+		{"issue", "create", "--project", keyB, "--title", "Quokka review", "--priority", "CRITICAL", "--status", "REVIEW"},
+		{"issue", "create", "--project", keyB, "--title", "Other work"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"list", "--all-projects", "--search", "quokka", "--sort", "id"}, keyA + "-1 " + keyA + "-2 " + keyB + "-1"},
+		{[]string{"list", "--all-projects", "--search", "quokka", "--sort", "priority:desc", "--limit", "2"}, keyB + "-1 " + keyA + "-2"},
+		{[]string{"list", "--all-projects", "--search", "quokka", "--status", "REVIEW"}, keyB + "-1"},
+		{[]string{"search", "Quokka", "--sort", "id:desc"}, keyB + "-1 " + keyA + "-2 " + keyA + "-1"},
+		{[]string{"search", "quokka", "--project", keyA}, keyA + "-1 " + keyA + "-2"},
+	}
+	for _, tt := range tests {
+		got, err := run(append(tt.args, "--template", "{{.ID}}")...)
+		if err != nil {
+			t.Errorf("%v failed: %v", tt.args, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%v = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+
+	if _, err := run("list", "--all-projects", "--project", keyA); err == nil {
+		t.Error("Expected --all-projects with --project to fail")
+	}
+	if _, err := run("list", "--all-projects", "--status", "NOPE"); err == nil {
+		t.Error("Expected a status of no project's workflow to be refused")
+	}
+}
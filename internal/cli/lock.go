@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// LockStats summarizes the lock wait times recorded for a project.
+type LockStats struct {
+	Project   string               `json:"project"`
+	Samples   int                  `json:"samples"`
+	Contended int                  `json:"contended"`
+	Timeouts  int                  `json:"timeouts"`
+	P50Ms     int64                `json:"p50_ms"`
+	P95Ms     int64                `json:"p95_ms"`
+	MaxMs     int64                `json:"max_ms"`
+	Recent    []storage.LockSample `json:"recent"`
+}
+
+// NewLockCmd creates and returns the lock command.
+func NewLockCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lock",
+		Short: "Inspect project lock contention",
+		Long: `Inspect how long commands wait for the project lock.
+
+Every lock acquisition that has to wait records its wait time, and every lock
+timeout, in projects/[KEY]/.buyruk_lockstats. Use this to diagnose slow scripted workloads
+that run many commands against one project in parallel.`,
+	}
+
+	cmd.AddCommand(NewLockStatsCmd())
+
+	return cmd
+}
+
+// NewLockStatsCmd creates and returns the lock stats command.
+func NewLockStatsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show lock wait percentiles and recent contention",
+		Long: `Show the p50/p95/max lock wait time of a project and its most recent
+contention incidents (acquisitions that had to wait, and timeouts).`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return showLockStats(cmd)
+		},
+	}
+
+	cmd.Flags().Int("recent", 10, "Number of recent contention incidents to show")
+	cmd.Flags().Bool("reset", false, "Clear the recorded stats")
+
+	return cmd
+}
+
+// showLockStats prints the lock stats of the resolved project.
+func showLockStats(cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+
+	w := cmd.OutOrStdout()
+	if reset, _ := cmd.Flags().GetBool("reset"); reset {
+		if err := storage.ResetLockStats(cmd.Context(), projectKey); err != nil {
+			return fmt.Errorf("cli: failed to reset lock stats: %w", err)
+		}
+		fmt.Fprintf(successOut(cmd), "Cleared lock stats for %s\n", projectKey)
+		return nil
+	}
+
+	samples, err := storage.ReadLockStats(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to load lock stats: %w", err)
+	}
+	recent, _ := cmd.Flags().GetInt("recent")
+	stats := summarizeLockStats(projectKey, samples, recent)
+
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(w, stats)
+	case config.DefaultFormatLSON:
+		fmt.Fprintf(w, "@LOCKSTATS: %s|samples=%d|contended=%d|timeouts=%d|p50=%dms|p95=%dms|max=%dms\n",
+			stats.Project, stats.Samples, stats.Contended, stats.Timeouts, stats.P50Ms, stats.P95Ms, stats.MaxMs)
+		for _, s := range stats.Recent {
+			fmt.Fprintf(w, "@INCIDENT: %s|%dms|timed_out=%t|pid=%d\n", s.At, s.WaitMs, s.TimedOut, s.PID)
+		}
+		return nil
+	}
+
+	styles := ui.NewStyles()
+	if stats.Samples == 0 {
+		fmt.Fprintf(w, "No lock stats recorded for %s\n", projectKey)
+		return nil
+	}
+
+	fmt.Fprintf(w, "%s %s\n", styles.Label("Lock stats for"), styles.ID(projectKey))
+	fmt.Fprintf(w, "%s: %d (%d contended, %d timeouts)\n", styles.Label("Acquisitions"), stats.Samples, stats.Contended, stats.Timeouts)
+	fmt.Fprintf(w, "%s: p50 %s, p95 %s, max %s\n", styles.Label("Wait"),
+		formatWaitMs(stats.P50Ms), formatWaitMs(stats.P95Ms), formatWaitMs(stats.MaxMs))
+
+	if len(stats.Recent) == 0 {
+		return nil
+	}
+	loc := config.Location()
+	now := storage.Now(cmd.Context())
+	fmt.Fprintf(w, "%s:\n", styles.Label("Recent contention"))
+	for _, s := range stats.Recent {
+		what := "waited " + formatWaitMs(s.WaitMs)
+		if s.TimedOut {
+			what = styles.Error("timed out after " + formatWaitMs(s.WaitMs))
+		}
+		fmt.Fprintf(w, "  - %s: %s (pid %d)\n", ui.FormatTimeRelative(s.At, loc, now), what, s.PID)
+	}
+	return nil
+}
+
+// summarizeLockStats computes wait percentiles over all samples and collects
+// the most recent contention incidents, newest first.
+func summarizeLockStats(projectKey string, samples []storage.LockSample, recent int) LockStats {
+	stats := LockStats{Project: projectKey, Samples: len(samples), Recent: []storage.LockSample{}}
+
+	waits := make([]int64, 0, len(samples))
+	for _, s := range samples {
+		waits = append(waits, s.WaitMs)
+		if s.Contended {
+			stats.Contended++
+		}
+		if s.TimedOut {
+			stats.Timeouts++
+		}
+	}
+	sort.Slice(waits, func(i, j int) bool { return waits[i] < waits[j] })
+	stats.P50Ms = percentile(waits, 50)
+	stats.P95Ms = percentile(waits, 95)
+	if len(waits) > 0 {
+		stats.MaxMs = waits[len(waits)-1]
+	}
+
+	for i := len(samples) - 1; i >= 0 && len(stats.Recent) < recent; i-- {
+		if samples[i].Contended || samples[i].TimedOut {
+			stats.Recent = append(stats.Recent, samples[i])
+		}
+	}
+	return stats
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted values.
+func percentile(sorted []int64, p int) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// formatWaitMs formats a wait time in milliseconds, e.g. "0s", "350ms", "1.2s".
+func formatWaitMs(ms int64) string {
+	d := time.Duration(ms) * time.Millisecond
+	if d >= time.Second {
+		d = d.Round(100 * time.Millisecond)
+	}
+	return d.String()
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestNewLockCmd(t *testing.T) {
+	cmd := NewLockCmd()
+	if cmd == nil {
+		t.Fatal("NewLockCmd() returned nil")
+	}
+	if cmd.Use != "lock" {
+		t.Errorf("Expected Use to be 'lock', got '%s'", cmd.Use)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	values := []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	if got := percentile(values, 50); got != 5 {
+		t.Errorf("percentile(50) = %d, want 5", got)
+	}
+	if got := percentile(values, 95); got != 10 {
+		t.Errorf("percentile(95) = %d, want 10", got)
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("percentile(nil) = %d, want 0", got)
+	}
+}
+
+func TestSummarizeLockStats(t *testing.T) {
+	samples := []storage.LockSample{
+		{At: "2024-01-01T00:00:00Z", WaitMs: 0},
+		{At: "2024-01-01T00:01:00Z", WaitMs: 300, Contended: true},
+		{At: "2024-01-01T00:02:00Z", WaitMs: 1},
+		{At: "2024-01-01T00:03:00Z", WaitMs: 5000, Contended: true, TimedOut: true},
+	}
+
+	stats := summarizeLockStats("CORE", samples, 1)
+	if stats.Samples != 4 || stats.Contended != 2 || stats.Timeouts != 1 {
+		t.Errorf("Unexpected counts: %+v", stats)
+	}
+	if stats.P50Ms != 1 || stats.P95Ms != 5000 || stats.MaxMs != 5000 {
+		t.Errorf("Unexpected percentiles: p50=%d p95=%d max=%d", stats.P50Ms, stats.P95Ms, stats.MaxMs)
+	}
+	if len(stats.Recent) != 1 || !stats.Recent[0].TimedOut {
+		t.Errorf("Expected the most recent incident to be the timeout, got %+v", stats.Recent)
+	}
+}
+
+func TestLockStatsCmd(t *testing.T) {
+	projectKey := setupApplyProject(t)
+
+	// Setting up the project took the lock right away, which isn't recorded;
+	// an issue created while the lock is held waits for it
+	cleanup, err := storage.AcquireLock(t.Context(), projectKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cleanup()
+	}()
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"issue", "create", "--project", projectKey, "--title", "Waits"})
+	cmd.SetOut(new(bytes.Buffer))
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("issue create failed: %v", err)
+	}
+
+	cmd = NewRootCmd()
+	cmd.SetArgs([]string{"lock", "stats", "--project", projectKey, "--format", "json"})
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("lock stats failed: %v", err)
+	}
+
+	var stats LockStats
+	if err := json.Unmarshal(out.Bytes(), &stats); err != nil {
+		t.Fatalf("Failed to decode stats: %v\n%s", err, out.String())
+	}
+	if stats.Project != projectKey || stats.Samples != 1 || stats.Contended != 1 {
+		t.Errorf("Expected the one wait of %s, got %+v", projectKey, stats)
+	}
+
+	cmd = NewRootCmd()
+	cmd.SetArgs([]string{"lock", "stats", "--project", projectKey, "--reset"})
+	out = new(bytes.Buffer)
+	cmd.SetOut(out)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("lock stats --reset failed: %v", err)
+	}
+
+	cmd = NewRootCmd()
+	cmd.SetArgs([]string{"lock", "stats", "--project", projectKey})
+	out = new(bytes.Buffer)
+	cmd.SetOut(out)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("lock stats failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "No lock stats recorded") {
+		t.Errorf("Expected no stats after reset, got %q", out.String())
+	}
+}
@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// dueSoonWindow is how close a due date is highlighted as due soon.
+const dueSoonWindow = 72 * time.Hour
+
+// How close an issue is to its due date, for MyWorkItem.DueState.
+const (
+	dueStateOverdue = "overdue"
+	dueStateSoon    = "soon"
+)
+
+// MyWork is the open issues assigned to a user across projects, grouped by
+// status.
+type MyWork struct {
+	User   string        `json:"user"`
+	Groups []MyWorkGroup `json:"groups"`
+}
+
+// MyWorkGroup is the issues of a personal dashboard in one status.
+type MyWorkGroup struct {
+	Status string       `json:"status"`
+	Issues []MyWorkItem `json:"issues"`
+}
+
+// MyWorkItem is an issue on a personal dashboard.
+type MyWorkItem struct {
+	ID       string        `json:"id"`
+	Title    string        `json:"title"`
+	Priority string        `json:"priority,omitempty"`
+	Due      string        `json:"due,omitempty"`
+	DueState string        `json:"due_state,omitempty"` // "overdue" or "soon" (within 3 days)
+	Blockers []BlockerInfo `json:"blockers,omitempty"`  // Open blockers only
+}
+
+// NewMeCmd creates and returns the me command.
+func NewMeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "me",
+		Short: "Show the open issues assigned to you across projects",
+		Long: `Show a personal dashboard: the open issues assigned to you in every project
+(or only --project), grouped by status. Within a status, issues due soonest
+come first; overdue issues, issues due within 3 days, and issues waiting on an
+open blocker are highlighted.
+
+You are the name and email changes are attributed to (user.name and
+user.email, or git's), as with buyruk standup; --user shows someone else's.`,
+		Example: `  buyruk me
+  buyruk me --user alice --format json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return showMyWork(cmd)
+		},
+	}
+
+	cmd.Flags().String("user", "", "User to show the issues of (default: you)")
+
+	return cmd
+}
+
+// showMyWork builds and prints the personal dashboard of a user.
+func showMyWork(cmd *cobra.Command) error {
+	names, user, err := standupUser(cmd)
+	if err != nil {
+		return err
+	}
+
+	var projectKeys []string
+	if projectKey, _ := cmd.Flags().GetString("project"); projectKey != "" {
+		projectKeys = []string{projectKey}
+	} else {
+		keys, err := storage.ListProjects(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("cli: failed to list projects: %w", err)
+		}
+		projectKeys = keys
+	}
+
+	work := &MyWork{User: user, Groups: []MyWorkGroup{}}
+	var workflows []*models.Workflow
+	byStatus := map[string][]MyWorkItem{}
+	now := storage.Now(cmd.Context())
+	for _, projectKey := range projectKeys {
+		wf, err := loadWorkflow(cmd.Context(), projectKey)
+		if err != nil {
+			return err
+		}
+		workflows = append(workflows, wf)
+		index, err := loadQueryIndex(cmd.Context(), projectKey)
+		if err != nil {
+			return err
+		}
+		lookup := newBlockerLookup(projectKey, wf)
+		for _, entry := range index.Issues {
+			if wf.IsDoneStatus(entry.Status) || !matchesUser(names, entry.Assignee) {
+				continue
+			}
+			issue, err := loadLocalIssue(cmd.Context(), projectKey, entry.ID)
+			if err != nil || issue == nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: failed to load issue %s\n", entry.ID)
+				continue
+			}
+			item := MyWorkItem{ID: issue.ID, Title: issue.Title, Priority: issue.Priority, Due: issue.Due}
+			if due, err := time.Parse(time.RFC3339, issue.Due); err == nil {
+				switch {
+				case due.Before(now):
+					item.DueState = dueStateOverdue
+				case due.Sub(now) <= dueSoonWindow:
+					item.DueState = dueStateSoon
+				}
+			}
+			for _, id := range issue.BlockedBy {
+				if blocker, ok := lookup.find(cmd.Context(), id); ok && !blocker.done {
+					item.Blockers = append(item.Blockers, blocker.BlockerInfo)
+				}
+			}
+			byStatus[issue.Status] = append(byStatus[issue.Status], item)
+		}
+	}
+
+	// Statuses in workflow order; statuses of no workflow come last
+	statuses := mergedWorkflow(workflows).Statuses
+	for _, status := range slices.Sorted(maps.Keys(byStatus)) {
+		if !slices.Contains(statuses, status) {
+			statuses = append(statuses, status)
+		}
+	}
+	for _, status := range statuses {
+		items := byStatus[status]
+		if len(items) == 0 {
+			continue
+		}
+		// Soonest due first; issues without a due date keep their order after
+		slices.SortStableFunc(items, func(a, b MyWorkItem) int {
+			if (a.Due == "") != (b.Due == "") {
+				if a.Due == "" {
+					return 1
+				}
+				return -1
+			}
+			return cmp.Compare(a.Due, b.Due)
+		})
+		work.Groups = append(work.Groups, MyWorkGroup{Status: status, Issues: items})
+	}
+	return writeMyWork(cmd, work, now)
+}
+
+// writeMyWork prints a personal dashboard in the resolved output format.
+func writeMyWork(cmd *cobra.Command, work *MyWork, now time.Time) error {
+	out := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		data, err := json.MarshalIndent(work, "", "  ")
+		if err != nil {
+			return fmt.Errorf("cli: failed to marshal dashboard: %w", err)
+		}
+		fmt.Fprintln(out, string(data))
+		return nil
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(out, work)
+	case config.DefaultFormatLSON:
+		fmt.Fprintf(out, "@USER: %s\n", work.User)
+		for _, group := range work.Groups {
+			fmt.Fprintf(out, "@STATUS: %s\n", group.Status)
+			for _, item := range group.Issues {
+				fmt.Fprintf(out, "@ISSUE: %s|%s|%s|%s|%s|%s\n", item.ID, item.Title, item.Priority, item.Due, item.DueState, strings.Join(blockerIDs(item.Blockers), ","))
+			}
+		}
+		return nil
+	}
+	renderMyWork(work, now, out)
+	return nil
+}
+
+// renderMyWork renders a personal dashboard in the modern format.
+func renderMyWork(work *MyWork, now time.Time, out io.Writer) {
+	styles := ui.NewStyles()
+	count := 0
+	for _, group := range work.Groups {
+		count += len(group.Issues)
+	}
+	fmt.Fprintf(out, "%s %s (%d open)\n", styles.Label("My work:"), work.User, count)
+	if count == 0 {
+		fmt.Fprintf(out, "\nNothing open is assigned to %s.\n", work.User)
+		return
+	}
+
+	for _, group := range work.Groups {
+		fmt.Fprintf(out, "\n%s (%d)\n", styles.StatusColor(group.Status)(group.Status), len(group.Issues))
+		for _, item := range group.Issues {
+			line := fmt.Sprintf("  %s  %s", styles.ID(item.ID), styles.Title(item.Title))
+			if item.Priority != "" {
+				line += "  " + styles.PriorityColor(item.Priority)(item.Priority)
+			}
+			if due, err := time.Parse(time.RFC3339, item.Due); err == nil {
+				text := fmt.Sprintf("due %s (%s)", ui.FormatTime(item.Due, config.Location()), ui.RelativeTime(due, now))
+				switch item.DueState {
+				case dueStateOverdue:
+					text = styles.Error("overdue: " + text)
+				case dueStateSoon:
+					text = styles.Label(text)
+				}
+				line += "  " + text
+			}
+			if len(item.Blockers) > 0 {
+				line += "  " + styles.Error("blocked by "+strings.Join(blockerIDs(item.Blockers), ", "))
+			}
+			fmt.Fprintln(out, line)
+		}
+	}
+}
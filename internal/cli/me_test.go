@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestMe(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	otherKey := projectKey + "X"
+	defer func() {
+		for _, key := range []string{projectKey, otherKey} {
+			projectDir, _ := storage.ProjectDir(t.Context(), key)
+			os.RemoveAll(projectDir)
+		}
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	user := strings.ToLower(projectKey)
+	id := func(n string) string { return projectKey + "-" + n }
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"project", "create", otherKey},
+		{"issue", "create", "--project", projectKey, "--title", "Late", "--assignee", user, "--due", "2024-06-10T00:00:00Z"},
+		{"issue", "create", "--project", projectKey, "--title", "Soon", "--assignee", user, "--due", "2024-06-16T00:00:00Z"},
+		{"issue", "create", "--project", projectKey, "--title", "Finished", "--assignee", user},
+		{"issue", "create", "--project", projectKey, "--title", "Someone else's", "--assignee", "nobody"},
+		{"issue", "create", "--project", otherKey, "--title", "Elsewhere", "--assignee", user},
+		{"issue", "update", id("3"), "--status", "DONE"},
+		{"issue", "update", id("2"), "--status", "DOING"},
+		{"issue", "link", id("1"), id("4")},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	out, err := run("me", "--user", user, "--format", "lson", "--fixed-time", "2024-06-15T09:00:00Z")
+	if err != nil {
+		t.Fatalf("me failed: %v", err)
+	}
+	want := []string{
+		"@USER: " + user,
+		"@STATUS: TODO",
+		"@ISSUE: " + id("1") + "|Late||2024-06-10T00:00:00Z|overdue|" + id("4"),
+		"@ISSUE: " + otherKey + "-1|Elsewhere||||",
+		"@STATUS: DOING",
+		"@ISSUE: " + id("2") + "|Soon||2024-06-16T00:00:00Z|soon|",
+	}
+	got := strings.Split(strings.TrimSpace(out), "\n")
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected dashboard:\ngot:\n%s\nwant:\n%s", out, strings.Join(want, "\n"))
+	}
+
+	out, err = run("me", "--user", user, "--project", otherKey, "--format", "lson")
+	if err != nil {
+		t.Fatalf("me --project failed: %v", err)
+	}
+	if strings.Contains(out, id("1")) || !strings.Contains(out, otherKey+"-1") {
+		t.Errorf("Expected only %s issues, got:\n%s", otherKey, out)
+	}
+}
@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// resolutionDuplicate is the resolution of an issue merged into another.
+const resolutionDuplicate = "duplicate"
+
+// NewIssueMergeCmd creates and returns the issue merge command.
+func NewIssueMergeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "merge <src> <dst>",
+		Short: "Merge a duplicate issue into another issue",
+		Long: `Merge SRC into DST as a duplicate. The PRs, dependencies and links, comments,
+and labels of SRC move to DST (issues linked to SRC are linked to DST
+instead), SRC is linked as a duplicate of DST and closed with resolution
+"duplicate", and both issues record the merge in their history (see
+buyruk issue flow).
+
+Both issues must be in the same project, and sensitive issues can't be merged.`,
+		Example:           `  buyruk issue merge CORE-14 CORE-9`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeIssueArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveIssueArgs(cmd, args[:2]); err != nil {
+				return err
+			}
+			return mergeIssue(args[0], args[1], cmd)
+		},
+	}
+
+	return cmd
+}
+
+// mergeIssue merges a duplicate issue into another issue.
+func mergeIssue(srcID, dstID string, cmd *cobra.Command) error {
+	projectKey, _, err := parseIssueID(cmd.Context(), srcID)
+	if err != nil {
+		return invalidf("cli: invalid issue ID %q: %w", srcID, err)
+	}
+	dstProjectKey, _, err := parseIssueID(cmd.Context(), dstID)
+	if err != nil {
+		return invalidf("cli: invalid issue ID %q: %w", dstID, err)
+	}
+	if srcID == dstID {
+		return invalidf("cli: cannot merge %s into itself", srcID)
+	}
+	if dstProjectKey != projectKey {
+		return invalidf("cli: %s and %s are in different projects (move one with `buyruk issue move` first)", srcID, dstID)
+	}
+
+	wf, err := loadWorkflow(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+
+	// The issues linked to SRC are updated too, to link to DST instead
+	src, err := loadLocalIssue(cmd.Context(), projectKey, srcID)
+	if err != nil {
+		return err
+	}
+	if src == nil {
+		return notFoundf("cli: issue %q not found", srcID)
+	}
+	linkedIDs := slices.DeleteFunc(src.LinkedIssueIDs(), func(id string) bool { return id == dstID })
+
+	paths := make([]string, 0, len(linkedIDs)+3)
+	for _, id := range append([]string{srcID, dstID}, linkedIDs...) {
+		key, _, err := parseIssueID(cmd.Context(), id)
+		if err != nil {
+			return invalidf("cli: invalid linked issue ID %q: %w", id, err)
+		}
+		path, err := storage.IssuePath(cmd.Context(), key, id)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+		}
+		paths = append(paths, path)
+	}
+	indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	paths = append(paths, indexPath)
+
+	issues := make([]models.Issue, len(linkedIDs)+2)
+	var index models.ProjectIndex
+	values := make([]interface{}, 0, len(paths))
+	for i := range issues {
+		values = append(values, &issues[i])
+	}
+	values = append(values, &index)
+
+	var mergeErr error
+	err = storage.UpdateJSONFilesAtomic(cmd.Context(), paths, values, func(exists []bool) error {
+		src, dst := &issues[0], &issues[1]
+		for i, id := range []string{srcID, dstID} {
+			if !exists[i] || issues[i].ID != id {
+				mergeErr = notFoundf("cli: issue %q not found", id)
+				return mergeErr
+			}
+		}
+		if src.Sealed != nil || dst.Sealed != nil {
+			mergeErr = invalidf("cli: sensitive issues can't be merged (make them non-sensitive first with `issue update --sensitive=false`)")
+			return mergeErr
+		}
+		if target := src.LinkedIDs(models.LinkDuplicates); src.Resolution == resolutionDuplicate && len(target) > 0 {
+			mergeErr = conflictf("cli: %s was already merged into %s", srcID, target[0])
+			return mergeErr
+		}
+		if target := dst.LinkedIDs(models.LinkDuplicates); dst.Resolution == resolutionDuplicate && len(target) > 0 {
+			mergeErr = invalidf("cli: %s was merged into %s (merge into %s instead)", dstID, target[0], target[0])
+			return mergeErr
+		}
+
+		// Issues linked since SRC was read would keep links to it
+		linked := map[string]*models.Issue{}
+		for i, id := range linkedIDs {
+			if exists[i+2] && issues[i+2].ID == id {
+				linked[id] = &issues[i+2]
+			}
+		}
+		for _, id := range src.LinkedIssueIDs() {
+			if id != dstID && !slices.Contains(linkedIDs, id) {
+				mergeErr = conflictf("cli: %s changed while merging, try again", srcID)
+				return mergeErr
+			}
+		}
+
+		now := storage.Timestamp(cmd.Context())
+		for _, pr := range src.PRs {
+			dst.AddPR(pr)
+		}
+		src.PRs = nil
+		for _, label := range src.Labels {
+			dst.AddLabel(label)
+		}
+		src.Labels = nil
+		dst.Comments = append(dst.Comments, src.Comments...)
+		slices.SortStableFunc(dst.Comments, func(a, b models.Comment) int { return cmp.Compare(a.CreatedAt, b.CreatedAt) })
+		src.Comments = nil
+
+		models.MoveLinks(src, dst, linked)
+		models.LinkIssues(src, dst, models.LinkDuplicates)
+		dst.MarkBlocked(now)
+		for _, other := range linked {
+			other.MarkBlocked(now)
+			other.UpdatedAt = now
+		}
+
+		src.Status = wf.DoneStatusList()[0]
+		src.Resolution = resolutionDuplicate
+		src.History = append(src.History, models.HistoryEvent{At: now, Type: models.HistoryMergedInto, Detail: dstID})
+		dst.History = append(dst.History, models.HistoryEvent{At: now, Type: models.HistoryMergedFrom, Detail: srcID})
+		src.UpdatedAt = now
+		dst.UpdatedAt = now
+
+		for _, issue := range []*models.Issue{src, dst} {
+			if err := issue.ValidateWithWorkflow(wf); err != nil {
+				mergeErr = invalidf("cli: invalid issue %s after merge: %w", issue.ID, err)
+				return mergeErr
+			}
+			index.AddIssue(issue)
+		}
+		index.UpdatedAt = now
+		return nil
+	})
+	if mergeErr != nil {
+		return mergeErr
+	}
+	if err != nil {
+		return fmt.Errorf("cli: failed to merge issues: %w", err)
+	}
+
+	fmt.Fprintf(successOut(cmd), "Merged %s into %s (%s is closed as a duplicate)\n", srcID, dstID, srcID)
+	return nil
+}
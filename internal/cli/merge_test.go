@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestIssueMerge(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) error {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+		return cmd.Execute()
+	}
+	id := func(n string) string { return projectKey + "-" + n }
+	load := func(n string) *models.Issue {
+		t.Helper()
+		issue, err := loadLocalIssue(t.Context(), projectKey, id(n))
+		if err != nil || issue == nil {
+			t.Fatalf("Failed to load %s: %v", id(n), err)
+		}
+		return issue
+	}
+
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "Login fails", "--labels", "auth"},
+		{"issue", "create", "--project", projectKey, "--title", "Can't log in", "--labels", "ui,auth"},
+		{"issue", "create", "--project", projectKey, "--title", "Session store"},
+		{"issue", "create", "--project", projectKey, "--title", "Login docs"},
+		{"issue", "pr", id("2"), "https://example.com/pr/7"},
+		{"issue", "comment", id("2"), "Same on mobile"},
+		{"issue", "link", id("2"), id("3")},
+		{"issue", "link", id("4"), id("2"), "--type", "relates_to"},
+		{"issue", "link", id("2"), id("1"), "--type", "relates_to"},
+	} {
+		if err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	if err := run("issue", "merge", id("2"), id("1")); err != nil {
+		t.Fatalf("issue merge failed: %v", err)
+	}
+
+	src, dst := load("2"), load("1")
+	if src.Status != models.StatusDONE || src.Resolution != "duplicate" {
+		t.Errorf("Expected %s to be closed as a duplicate, got %s/%s", id("2"), src.Status, src.Resolution)
+	}
+	if len(src.PRs) != 0 || len(src.Comments) != 0 || len(src.Labels) != 0 || len(src.BlockedBy) != 0 {
+		t.Errorf("Expected the PRs, comments, labels, and dependencies to leave %s, got %+v", id("2"), src)
+	}
+	if want := []models.Link{{Type: models.LinkDuplicates, ID: id("1")}}; !slices.Equal(src.Links, want) {
+		t.Errorf("Expected %s to only link to %s as a duplicate, got %v", id("2"), id("1"), src.Links)
+	}
+
+	if !slices.Equal(dst.PRs, []string{"https://example.com/pr/7"}) || !slices.Equal(dst.Labels, []string{"auth", "ui"}) {
+		t.Errorf("Expected the PR and labels on %s, got %v %v", id("1"), dst.PRs, dst.Labels)
+	}
+	if len(dst.Comments) != 1 || dst.Comments[0].Body != "Same on mobile" {
+		t.Errorf("Expected the comment on %s, got %v", id("1"), dst.Comments)
+	}
+	if !slices.Equal(dst.BlockedBy, []string{id("3")}) {
+		t.Errorf("Expected %s to be blocked by %s, got %v", id("1"), id("3"), dst.BlockedBy)
+	}
+	if !slices.Contains(dst.Links, models.Link{Type: models.LinkRelatesTo, ID: id("4")}) ||
+		!slices.Contains(dst.Links, models.Link{Type: models.LinkDuplicatedBy, ID: id("2")}) ||
+		slices.Contains(dst.Links, models.Link{Type: models.LinkRelatesTo, ID: id("2")}) {
+		t.Errorf("Expected %s to relate to %s and be duplicated by %s, got %v", id("1"), id("4"), id("2"), dst.Links)
+	}
+
+	// The other sides of the links point at DST
+	if blocker := load("3"); !slices.Contains(blocker.LinkedIDs(models.LinkBlocks), id("1")) || slices.Contains(blocker.LinkedIDs(models.LinkBlocks), id("2")) {
+		t.Errorf("Expected %s to block %s instead of %s, got %v", id("3"), id("1"), id("2"), blocker.Links)
+	}
+	if related := load("4"); !slices.Equal(related.LinkedIDs(models.LinkRelatesTo), []string{id("1")}) {
+		t.Errorf("Expected %s to relate to %s, got %v", id("4"), id("1"), related.Links)
+	}
+
+	hasEvent := func(issue *models.Issue, eventType, detail string) bool {
+		return slices.ContainsFunc(issue.History, func(e models.HistoryEvent) bool { return e.Type == eventType && e.Detail == detail })
+	}
+	if !hasEvent(src, models.HistoryMergedInto, id("1")) || !hasEvent(dst, models.HistoryMergedFrom, id("2")) {
+		t.Errorf("Expected the merge in both histories, got %v and %v", src.History, dst.History)
+	}
+
+	index, err := loadQueryIndex(t.Context(), projectKey)
+	if err != nil {
+		t.Fatalf("Failed to load index: %v", err)
+	}
+	if entry := index.FindIssue(id("2")); entry == nil || entry.Status != models.StatusDONE {
+		t.Errorf("Expected the index to show %s as done, got %+v", id("2"), entry)
+	}
+
+	if err := run("issue", "merge", id("2"), id("1")); err == nil {
+		t.Error("Expected merging an issue twice to fail")
+	}
+	if err := run("issue", "merge", id("4"), id("2")); err == nil {
+		t.Error("Expected merging into a merged duplicate to fail")
+	}
+}
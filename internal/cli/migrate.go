@@ -0,0 +1,960 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/timeparse"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// Migration source kinds.
+const (
+	migrateSourceCSV    = "csv"
+	migrateSourceJira   = "jira"
+	migrateSourceGitHub = "github"
+)
+
+// migrateFields are the issue fields a source column can be mapped to, in prompt order.
+var migrateFields = []string{"title", "description", "type", "status", "priority", "labels", "due"}
+
+// migrateValueFields are the mapped fields whose values must fit the project workflow.
+var migrateValueFields = []string{"type", "status", "priority"}
+
+// migrateColumnHints are the normalized column names guessed for each field.
+// Jira and GitHub column names are included so their exports map without edits.
+var migrateColumnHints = map[string][]string{
+	"title":       {"title", "summary", "name", "subject"},
+	"description": {"description", "body", "details"},
+	"type":        {"type", "issuetype", "issuetypename", "kind"},
+	"status":      {"status", "state", "statusname"},
+	"priority":    {"priority", "priorityname"},
+	"labels":      {"labels", "label", "tags"},
+	"due":         {"due", "duedate"},
+}
+
+// githubRemotePattern extracts OWNER/REPO from a GitHub remote URL.
+var githubRemotePattern = regexp.MustCompile(`github\.com[:/]([^/]+/[^/]+?)(?:\.git)?/?$`)
+
+// migrateSource is a place records can be migrated from.
+type migrateSource struct {
+	Kind     string // csv, jira, or github
+	Location string // File path, or OWNER/REPO for a GitHub repository
+}
+
+// String identifies the source in prompts and checkpoints.
+func (s migrateSource) String() string {
+	return s.Kind + ":" + s.Location
+}
+
+// migrateRecords are the records of a source, as flat column/value maps.
+type migrateRecords struct {
+	Columns []string
+	Rows    []map[string]string
+}
+
+// migrateMapping describes how source records become issues.
+type migrateMapping struct {
+	Fields map[string]string            `json:"fields"`           // Issue field -> source column
+	Values map[string]map[string]string `json:"values,omitempty"` // Issue field -> source value -> workflow value
+}
+
+// migrateCheckpoint records the progress of a migration so it can be resumed.
+type migrateCheckpoint struct {
+	Source    string         `json:"source"`
+	Mapping   migrateMapping `json:"mapping"`
+	Done      int            `json:"done"`    // Source records processed, in source order
+	Created   int            `json:"created"` // Issues created
+	Skipped   int            `json:"skipped"` // Records that could not be converted
+	UpdatedAt string         `json:"updated_at"`
+}
+
+// NewMigrateWizardCmd creates and returns the migrate-wizard command.
+func NewMigrateWizardCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate-wizard [source]",
+		Short: "Interactively migrate issues from another tracker",
+		Long: `Guide a migration from another tracker into a project.
+
+Sources are detected in the current directory: CSV files, Jira exports (CSV,
+or JSON from the Jira REST API), JSON written by 'gh issue list --json', and
+the GitHub repository of the "origin" remote, which is read with the gh CLI.
+A source can also be given explicitly, as a file or as github:OWNER/REPO.
+
+The wizard then walks through the mapping of source columns to issue fields
+and of source statuses, types, and priorities to the project workflow, shows
+a preview of the first records, and imports them in batches. Progress is
+checkpointed after every batch: running the wizard again for the same source
+and project resumes an interrupted migration.
+
+With --yes, the detected source and guessed mappings are used without prompting.
+
+Example:
+  buyruk migrate-wizard jira-export.csv --project CORE`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMigrateWizard(cmd, args)
+		},
+	}
+
+	cmd.Flags().Int("preview", 5, "Number of records to preview before importing")
+	cmd.Flags().Int("batch", 100, "Number of records imported per checkpoint")
+	cmd.Flags().BoolP("yes", "y", false, "Accept the detected source and guessed mappings without prompting")
+	cmd.Flags().Bool("restart", false, "Discard the checkpoint of an interrupted migration and start over")
+
+	return cmd
+}
+
+// migratePrompter asks questions on stderr and reads answers from stdin.
+// With assumeYes, or once input is exhausted, every question takes its default.
+type migratePrompter struct {
+	scanner   *bufio.Scanner
+	out       io.Writer
+	assumeYes bool
+}
+
+// prompt asks a question and returns the trimmed answer ("" for the default).
+func (p *migratePrompter) prompt(question, hint string) string {
+	if p.assumeYes {
+		return ""
+	}
+	fmt.Fprintf(p.out, "%s [%s]: ", question, hint)
+	if !p.scanner.Scan() {
+		fmt.Fprintln(p.out)
+		p.assumeYes = true
+		return ""
+	}
+	return strings.TrimSpace(p.scanner.Text())
+}
+
+// ask asks a question with a default answer.
+func (p *migratePrompter) ask(question, def string) string {
+	if answer := p.prompt(question, def); answer != "" {
+		return answer
+	}
+	return def
+}
+
+// confirm asks a yes/no question.
+func (p *migratePrompter) confirm(question string, def bool) bool {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	switch strings.ToLower(p.prompt(question, hint)) {
+	case "":
+		return def
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// runMigrateWizard runs the migration wizard.
+func runMigrateWizard(cmd *cobra.Command, args []string) error {
+	assumeYes, _ := cmd.Flags().GetBool("yes")
+	errOut := cmd.ErrOrStderr()
+	p := &migratePrompter{scanner: bufio.NewScanner(cmd.InOrStdin()), out: errOut, assumeYes: assumeYes}
+
+	// Source
+	var sources []migrateSource
+	if len(args) > 0 {
+		source, err := parseMigrateSource(args[0])
+		if err != nil {
+			return err
+		}
+		sources = []migrateSource{source}
+	} else {
+		sources = detectMigrateSources(".")
+	}
+	if len(sources) == 0 {
+		return fmt.Errorf("cli: no migration sources found (give a CSV or Jira export file, or github:OWNER/REPO)")
+	}
+	source := sources[0]
+	if len(sources) > 1 && !assumeYes {
+		fmt.Fprintln(errOut, "Detected sources:")
+		for i, s := range sources {
+			fmt.Fprintf(errOut, "  %d. %s\n", i+1, s)
+		}
+		for {
+			n, err := strconv.Atoi(p.ask("Source", "1"))
+			if err == nil && n >= 1 && n <= len(sources) {
+				source = sources[n-1]
+				break
+			}
+			fmt.Fprintf(errOut, "Enter a number from 1 to %d\n", len(sources))
+		}
+	}
+
+	records, err := loadMigrateRecords(source)
+	if err != nil {
+		return err
+	}
+	if len(records.Rows) == 0 {
+		return fmt.Errorf("cli: no records found in %s", source)
+	}
+	fmt.Fprintf(errOut, "Found %d records in %s\n", len(records.Rows), source)
+
+	// Target project
+	projectKey, _ := config.ResolveProject(cmd)
+	if !cmd.Flags().Changed("project") {
+		projectKey = p.ask("Target project", projectKey)
+	}
+	if projectKey == "" {
+		return fmt.Errorf("cli: no target project (use --project)")
+	}
+	if !isValidProjectKey(projectKey) {
+		return invalidf("cli: invalid project key %q (must contain only uppercase letters, numbers, and hyphens)", projectKey)
+	}
+	indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	if _, err := os.Stat(indexPath); os.IsNotExist(err) {
+		if !p.confirm(fmt.Sprintf("Project %s does not exist. Create it?", projectKey), true) {
+			return notFoundf("cli: project %q does not exist", projectKey)
+		}
+		if err := createProject(projectKey, cmd); err != nil {
+			return err
+		}
+	}
+	wf, err := loadWorkflow(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+
+	// Checkpoint of an interrupted run
+	checkpointPath, err := storage.MigrateCheckpointPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve checkpoint path: %w", err)
+	}
+	cp := &migrateCheckpoint{Source: source.String()}
+	resumed := false
+	var previous migrateCheckpoint
+	if err := storage.ReadJSON(cmd.Context(), checkpointPath, &previous); err == nil {
+		restart, _ := cmd.Flags().GetBool("restart")
+		switch {
+		case restart:
+			fmt.Fprintf(errOut, "Discarding the checkpoint of the interrupted migration from %s\n", previous.Source)
+		case previous.Source != cp.Source:
+			return fmt.Errorf("cli: an interrupted migration from %s into %q exists (run the wizard for that source to resume it, or use --restart to discard it)", previous.Source, projectKey)
+		case p.confirm(fmt.Sprintf("Resume the interrupted migration at record %d of %d?", previous.Done+1, len(records.Rows)), true):
+			cp = &previous
+			resumed = true
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("cli: failed to load migration checkpoint: %w", err)
+	}
+
+	if !resumed {
+		cp.Mapping = guessMigrateMapping(records, wf)
+		if !assumeYes {
+			p.editMapping(&cp.Mapping, records, wf)
+		}
+	}
+	printMigrateMapping(errOut, &cp.Mapping)
+
+	// Preview
+	out := cmd.OutOrStdout()
+	remaining := records.Rows[min(cp.Done, len(records.Rows)):]
+	previewCount, _ := cmd.Flags().GetInt("preview")
+	if previewCount > 0 {
+		previewCount = min(previewCount, len(remaining))
+		fmt.Fprintf(out, "Preview (first %d of %d records):\n", previewCount, len(remaining))
+		printMigratePreview(cmd.Context(), out, remaining[:previewCount], cp.Done, &cp.Mapping, wf)
+	}
+
+	if !p.confirm(fmt.Sprintf("Import %d records into %s?", len(remaining), projectKey), true) {
+		fmt.Fprintln(out, "Migration cancelled")
+		return nil
+	}
+
+	batchSize, _ := cmd.Flags().GetInt("batch")
+	if batchSize < 1 {
+		return invalidf("cli: --batch must be at least 1")
+	}
+	for cp.Done < len(records.Rows) {
+		end := min(cp.Done+batchSize, len(records.Rows))
+		if err := migrateBatch(cmd.Context(), projectKey, records.Rows[cp.Done:end], cp, checkpointPath, wf, errOut); err != nil {
+			return err
+		}
+		fmt.Fprintf(errOut, "Imported %d/%d records\n", cp.Done, len(records.Rows))
+	}
+
+	if err := storage.DeleteAtomic(cmd.Context(), checkpointPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintf(errOut, "Warning: failed to remove migration checkpoint: %v\n", err)
+	}
+
+	fmt.Fprintf(successOut(cmd), "Migrated %s into %s: %d issues created, %d records skipped\n", source, projectKey, cp.Created, cp.Skipped)
+	warnQuota(projectKey, cmd)
+	return nil
+}
+
+// parseMigrateSource resolves an explicit source argument.
+func parseMigrateSource(arg string) (migrateSource, error) {
+	if repo, ok := strings.CutPrefix(arg, "github:"); ok {
+		if strings.Count(repo, "/") != 1 || strings.HasPrefix(repo, "/") || strings.HasSuffix(repo, "/") {
+			return migrateSource{}, invalidf("cli: invalid GitHub repository %q (expected OWNER/REPO)", repo)
+		}
+		return migrateSource{Kind: migrateSourceGitHub, Location: repo}, nil
+	}
+	if _, err := os.Stat(arg); err != nil {
+		return migrateSource{}, fmt.Errorf("cli: failed to open %s: %w", arg, err)
+	}
+	kind := sniffMigrateFile(arg)
+	if kind == "" {
+		return migrateSource{}, invalidf("cli: unrecognized source %s (expected a CSV file, a Jira export, or gh issue list JSON)", arg)
+	}
+	return migrateSource{Kind: kind, Location: arg}, nil
+}
+
+// detectMigrateSources finds migration sources in dir: export files, and the
+// GitHub repository of the origin remote if the gh CLI is installed.
+func detectMigrateSources(dir string) []migrateSource {
+	var sources []migrateSource
+
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if kind := sniffMigrateFile(path); kind != "" {
+			sources = append(sources, migrateSource{Kind: kind, Location: path})
+		}
+	}
+
+	if _, err := exec.LookPath("gh"); err == nil {
+		remote := exec.Command("git", "remote", "get-url", "origin")
+		remote.Dir = dir
+		if output, err := remote.Output(); err == nil {
+			if m := githubRemotePattern.FindStringSubmatch(strings.TrimSpace(string(output))); m != nil {
+				sources = append(sources, migrateSource{Kind: migrateSourceGitHub, Location: m[1]})
+			}
+		}
+	}
+
+	return sources
+}
+
+// sniffMigrateFile returns the source kind of a file from its extension and
+// first bytes, or "" if it is not a supported export.
+func sniffMigrateFile(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	head := make([]byte, 4096)
+	n, _ := io.ReadFull(file, head)
+	head = bytes.TrimSpace(head[:n])
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		header, err := csv.NewReader(bytes.NewReader(head)).Read()
+		if err != nil {
+			return ""
+		}
+		columns := map[string]bool{}
+		for _, column := range header {
+			columns[normalizeMigrateName(column)] = true
+		}
+		if columns["summary"] && (columns["issuekey"] || columns["issueid"]) {
+			return migrateSourceJira
+		}
+		return migrateSourceCSV
+	case ".json":
+		switch {
+		case bytes.HasPrefix(head, []byte("{")) && bytes.Contains(head, []byte(`"issues"`)):
+			return migrateSourceJira
+		case bytes.HasPrefix(head, []byte("[")) && bytes.Contains(head, []byte(`"number"`)):
+			return migrateSourceGitHub
+		}
+	}
+	return ""
+}
+
+// loadMigrateRecords reads all records of a source.
+func loadMigrateRecords(source migrateSource) (*migrateRecords, error) {
+	if source.Kind == migrateSourceGitHub && !strings.HasSuffix(strings.ToLower(source.Location), ".json") {
+		if _, err := exec.LookPath("gh"); err != nil {
+			return nil, fmt.Errorf("cli: reading GitHub issues requires the gh CLI (https://cli.github.com)")
+		}
+		output, err := exec.Command("gh", "issue", "list", "--repo", source.Location, "--state", "all",
+			"--limit", "100000", "--json", "number,title,body,state,labels,createdAt").Output()
+		if err != nil {
+			return nil, fmt.Errorf("cli: failed to list issues of %s with gh: %w", source.Location, err)
+		}
+		return parseGitHubRecords(output)
+	}
+
+	data, err := os.ReadFile(source.Location)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to read %s: %w", source.Location, err)
+	}
+	switch {
+	case source.Kind == migrateSourceGitHub:
+		return parseGitHubRecords(data)
+	case source.Kind == migrateSourceJira && strings.HasSuffix(strings.ToLower(source.Location), ".json"):
+		return parseJiraRecords(data)
+	default:
+		return parseCSVRecords(data)
+	}
+}
+
+// parseCSVRecords reads CSV with a header row. Repeated columns, which Jira uses
+// for multi-value fields such as labels, are joined with commas.
+func parseCSVRecords(data []byte) (*migrateRecords, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, invalidf("cli: invalid CSV: %w", err)
+	}
+	if len(rows) == 0 {
+		return &migrateRecords{}, nil
+	}
+
+	records := &migrateRecords{}
+	seen := map[string]bool{}
+	for _, column := range rows[0] {
+		column = strings.TrimSpace(column)
+		if !seen[column] {
+			seen[column] = true
+			records.Columns = append(records.Columns, column)
+		}
+	}
+	for _, row := range rows[1:] {
+		record := map[string]string{}
+		empty := true
+		for i, value := range row {
+			if i >= len(rows[0]) {
+				break
+			}
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+			empty = false
+			column := strings.TrimSpace(rows[0][i])
+			if record[column] != "" {
+				value = record[column] + "," + value
+			}
+			record[column] = value
+		}
+		if !empty {
+			records.Rows = append(records.Rows, record)
+		}
+	}
+	return records, nil
+}
+
+// parseJiraRecords reads a Jira REST API search result ({"issues": [...]}).
+// Issue fields are flattened, so the status name becomes "status.name".
+func parseJiraRecords(data []byte) (*migrateRecords, error) {
+	var result struct {
+		Issues []struct {
+			Key    string                 `json:"key"`
+			Fields map[string]interface{} `json:"fields"`
+		} `json:"issues"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, invalidf("cli: invalid Jira export: %w", err)
+	}
+
+	rows := make([]map[string]string, 0, len(result.Issues))
+	for _, issue := range result.Issues {
+		record := map[string]string{}
+		flattenMigrateValue("", issue.Fields, record)
+		record["key"] = issue.Key
+		rows = append(rows, record)
+	}
+	return newMigrateRecords(rows, "key"), nil
+}
+
+// parseGitHubRecords reads the output of gh issue list --json, oldest issue first
+// so that issues opened during an interrupted migration are appended at the end.
+func parseGitHubRecords(data []byte) (*migrateRecords, error) {
+	var issues []map[string]interface{}
+	if err := json.Unmarshal(data, &issues); err != nil {
+		return nil, invalidf("cli: invalid GitHub issue list: %w", err)
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, _ := issues[i]["number"].(float64)
+		b, _ := issues[j]["number"].(float64)
+		return a < b
+	})
+
+	rows := make([]map[string]string, 0, len(issues))
+	for _, issue := range issues {
+		record := map[string]string{}
+		flattenMigrateValue("", issue, record)
+		rows = append(rows, record)
+	}
+	return newMigrateRecords(rows, "number", "title"), nil
+}
+
+// newMigrateRecords collects the columns of rows, with first columns leading.
+func newMigrateRecords(rows []map[string]string, first ...string) *migrateRecords {
+	columns := map[string]string{}
+	for _, row := range rows {
+		for column := range row {
+			columns[column] = ""
+		}
+	}
+	records := &migrateRecords{Rows: rows}
+	for _, column := range first {
+		if _, ok := columns[column]; ok {
+			records.Columns = append(records.Columns, column)
+			delete(columns, column)
+		}
+	}
+	records.Columns = append(records.Columns, sortedKeys(columns)...)
+	return records
+}
+
+// flattenMigrateValue stores a decoded JSON value as flat columns. Nested objects
+// become dotted columns; lists of strings or named objects are joined with commas.
+func flattenMigrateValue(column string, v interface{}, record map[string]string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if column != "" {
+				key = column + "." + key
+			}
+			flattenMigrateValue(key, value, record)
+		}
+	case []interface{}:
+		var items []string
+		for _, item := range v {
+			switch item := item.(type) {
+			case string:
+				items = append(items, item)
+			case map[string]interface{}:
+				if name, ok := item["name"].(string); ok {
+					items = append(items, name)
+				}
+			}
+		}
+		if len(items) > 0 {
+			record[column] = strings.Join(items, ",")
+		}
+	case string:
+		if v = strings.TrimSpace(v); v != "" {
+			record[column] = v
+		}
+	case float64:
+		record[column] = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		record[column] = strconv.FormatBool(v)
+	}
+}
+
+// normalizeMigrateName lowercases a name and drops everything but letters and
+// digits, so "Issue Type", "issue_type", and "issuetype" compare equal.
+func normalizeMigrateName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// guessMigrateMapping maps columns to fields by name, and source values of
+// mapped fields to the closest workflow values.
+func guessMigrateMapping(records *migrateRecords, wf *models.Workflow) migrateMapping {
+	m := migrateMapping{Fields: map[string]string{}}
+	for _, field := range migrateFields {
+		for _, hint := range migrateColumnHints[field] {
+			for _, column := range records.Columns {
+				if normalizeMigrateName(column) == hint {
+					m.Fields[field] = column
+					break
+				}
+			}
+			if m.Fields[field] != "" {
+				break
+			}
+		}
+	}
+	m.guessValues(records, wf)
+	return m
+}
+
+// guessValues (re)builds the value mappings of the mapped workflow fields.
+func (m *migrateMapping) guessValues(records *migrateRecords, wf *models.Workflow) {
+	m.Values = nil
+	for _, field := range migrateValueFields {
+		for _, value := range m.distinctValues(field, records) {
+			if guess := guessMigrateValue(field, value, wf); guess != value {
+				if m.Values == nil {
+					m.Values = map[string]map[string]string{}
+				}
+				if m.Values[field] == nil {
+					m.Values[field] = map[string]string{}
+				}
+				m.Values[field][value] = guess
+			}
+		}
+	}
+}
+
+// distinctValues returns the sorted non-empty values of a mapped field.
+func (m *migrateMapping) distinctValues(field string, records *migrateRecords) []string {
+	column := m.Fields[field]
+	if column == "" {
+		return nil
+	}
+	values := map[string]string{}
+	for _, row := range records.Rows {
+		if value := row[column]; value != "" {
+			values[value] = ""
+		}
+	}
+	return sortedKeys(values)
+}
+
+// guessMigrateValue returns the workflow value closest to a source value of a
+// type, status, or priority field. Unmatched priorities map to "" (no priority).
+func guessMigrateValue(field, value string, wf *models.Workflow) string {
+	var candidates []string
+	switch field {
+	case "type":
+		candidates = wf.TypeList()
+	case "status":
+		candidates = wf.StatusList()
+	case "priority":
+		candidates = wf.PriorityList()
+	}
+	normalized := normalizeMigrateName(value)
+	for _, candidate := range candidates {
+		if normalizeMigrateName(candidate) == normalized {
+			return candidate
+		}
+	}
+
+	has := func(words ...string) bool {
+		for _, word := range words {
+			if strings.Contains(normalized, word) {
+				return true
+			}
+		}
+		return false
+	}
+	switch field {
+	case "type":
+		if has("bug", "defect") && wf.IsValidType(models.TypeBug) {
+			return models.TypeBug
+		}
+		return defaultMigrateType(wf)
+	case "status":
+		if has("done", "closed", "resolved", "complete", "fixed", "cancel") {
+			return wf.DoneStatusList()[0]
+		}
+		if has("progress", "doing", "review", "started", "active") {
+			for _, status := range candidates[1:] {
+				if !wf.IsDoneStatus(status) {
+					return status
+				}
+			}
+		}
+		return wf.DefaultStatus()
+	default:
+		switch {
+		case has("highest", "blocker", "urgent", "critical"):
+			return candidates[len(candidates)-1]
+		case has("lowest", "trivial", "minor", "low"):
+			return candidates[0]
+		case has("high", "major") && len(candidates) > 1:
+			return candidates[len(candidates)-2]
+		case has("medium", "normal"):
+			return candidates[(len(candidates)-1)/2]
+		}
+		return ""
+	}
+}
+
+// defaultMigrateType returns the type of records without a mapped type.
+func defaultMigrateType(wf *models.Workflow) string {
+	if wf.IsValidType(models.TypeTask) {
+		return models.TypeTask
+	}
+	return wf.TypeList()[0]
+}
+
+// editMapping walks through the guessed mapping, letting the user change it.
+func (p *migratePrompter) editMapping(m *migrateMapping, records *migrateRecords, wf *models.Workflow) {
+	fmt.Fprintln(p.out, "Columns:")
+	for i, column := range records.Columns {
+		fmt.Fprintf(p.out, "  %d. %s\n", i+1, column)
+	}
+	fmt.Fprintln(p.out, `Map each issue field to a column (name or number, "-" for none):`)
+
+	for _, field := range migrateFields {
+		def := m.Fields[field]
+		if def == "" {
+			def = "-"
+		}
+		for {
+			answer := p.ask(field, def)
+			if answer == "-" {
+				if field == "title" {
+					fmt.Fprintln(p.out, "A title column is required")
+					continue
+				}
+				delete(m.Fields, field)
+				break
+			}
+			if column := findMigrateColumn(records.Columns, answer); column != "" {
+				m.Fields[field] = column
+				break
+			}
+			fmt.Fprintf(p.out, "Unknown column %q\n", answer)
+		}
+	}
+
+	m.guessValues(records, wf)
+	for _, field := range migrateValueFields {
+		for _, value := range m.distinctValues(field, records) {
+			def, ok := m.Values[field][value]
+			if !ok {
+				continue
+			}
+			if def == "" {
+				def = "-"
+			}
+			for {
+				answer := p.ask(fmt.Sprintf("%s %q maps to", field, value), def)
+				if answer == "-" && field == "priority" {
+					answer = ""
+				}
+				if (field == "type" && wf.IsValidType(answer)) ||
+					(field == "status" && wf.IsValidStatus(answer)) ||
+					(field == "priority" && (answer == "" || wf.IsValidPriority(answer))) {
+					m.Values[field][value] = answer
+					break
+				}
+				fmt.Fprintf(p.out, "%q is not a %s of the project workflow\n", answer, field)
+			}
+		}
+	}
+}
+
+// findMigrateColumn resolves a column given by name (case-insensitively) or number.
+func findMigrateColumn(columns []string, answer string) string {
+	if n, err := strconv.Atoi(answer); err == nil {
+		if n >= 1 && n <= len(columns) {
+			return columns[n-1]
+		}
+		return ""
+	}
+	for _, column := range columns {
+		if strings.EqualFold(column, answer) {
+			return column
+		}
+	}
+	return ""
+}
+
+// printMigrateMapping shows the mapping that will be used.
+func printMigrateMapping(w io.Writer, m *migrateMapping) {
+	fmt.Fprintln(w, "Mapping:")
+	for _, field := range migrateFields {
+		if column := m.Fields[field]; column != "" {
+			fmt.Fprintf(w, "  %s <- %s\n", field, column)
+		}
+	}
+	for _, field := range migrateValueFields {
+		for _, value := range sortedKeys(m.Values[field]) {
+			target := m.Values[field][value]
+			if target == "" {
+				target = "(none)"
+			}
+			fmt.Fprintf(w, "  %s %q -> %s\n", field, value, target)
+		}
+	}
+}
+
+// printMigratePreview shows how records will be imported, numbered from offset+1.
+func printMigratePreview(ctx context.Context, w io.Writer, rows []map[string]string, offset int, m *migrateMapping, wf *models.Workflow) {
+	styles := ui.NewStyles()
+	for i, row := range rows {
+		issue, err := m.issue(ctx, row, wf)
+		if err != nil {
+			fmt.Fprintf(w, "  %d. %s\n", offset+i+1, styles.Error("skipped: "+err.Error()))
+			continue
+		}
+		details := []string{issue.Type, styles.StatusColor(issue.Status)(issue.Status)}
+		if issue.Priority != "" {
+			details = append(details, styles.PriorityColor(issue.Priority)(issue.Priority))
+		}
+		if len(issue.Labels) > 0 {
+			details = append(details, styles.Label(strings.Join(issue.Labels, ", ")))
+		}
+		if issue.Due != "" {
+			details = append(details, "due "+issue.Due)
+		}
+		fmt.Fprintf(w, "  %d. %s [%s]\n", offset+i+1, styles.Title(issue.Title), strings.Join(details, " | "))
+	}
+}
+
+// issue converts a source record to an issue without ID and timestamps.
+func (m *migrateMapping) issue(ctx context.Context, row map[string]string, wf *models.Workflow) (*models.Issue, error) {
+	get := func(field string) string {
+		return strings.TrimSpace(row[m.Fields[field]])
+	}
+	value := func(field string) string {
+		v := get(field)
+		if mapped, ok := m.Values[field][v]; ok {
+			return mapped
+		}
+		return v
+	}
+
+	issue := &models.Issue{
+		Title:       get("title"),
+		Description: get("description"),
+		Type:        value("type"),
+		Status:      value("status"),
+		Priority:    value("priority"),
+	}
+	if issue.Title == "" {
+		return nil, fmt.Errorf("empty title")
+	}
+	if issue.Type == "" {
+		issue.Type = defaultMigrateType(wf)
+	}
+	if issue.Status == "" {
+		issue.Status = wf.DefaultStatus()
+	}
+	if labels := splitList(get("labels")); len(labels) > 0 {
+		issue.Labels = labels
+	}
+	if due := get("due"); due != "" {
+		t, err := timeparse.ParseTime(due, storage.Now(ctx), config.Location())
+		if err != nil {
+			return nil, fmt.Errorf("invalid due date %q", due)
+		}
+		issue.Due = t.UTC().Format(time.RFC3339)
+	}
+	if err := issue.ValidateWithWorkflow(wf); err != nil {
+		return nil, err
+	}
+	return issue, nil
+}
+
+// migrateBatch imports rows as new issues and advances the checkpoint, writing
+// the issues, the index, and the checkpoint together under the project lock.
+func migrateBatch(ctx context.Context, projectKey string, rows []map[string]string, cp *migrateCheckpoint, checkpointPath string, wf *models.Workflow, errOut io.Writer) error {
+	cleanup, err := storage.AcquireLock(ctx, projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to acquire lock: %w", err)
+	}
+	defer cleanup()
+
+	if pending, _, err := storage.CheckPendingTransaction(ctx, projectKey); err != nil {
+		return fmt.Errorf("cli: failed to check pending transaction: %w", err)
+	} else if pending {
+		return fmt.Errorf("cli: project %q has an incomplete operation (run 'buyruk project repair %s', then run the wizard again to resume)", projectKey, projectKey)
+	}
+
+	indexPath, err := storage.ProjectIndexPath(ctx, projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(ctx, indexPath, &index); err != nil {
+		return fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+
+	next := maxRedirectSequence(ctx, projectKey)
+	for _, entry := range index.Issues {
+		if _, seq, err := models.ParseIssueID(entry.ID); err == nil && seq > next {
+			next = seq
+		}
+	}
+	next++
+
+	now := storage.Timestamp(ctx)
+	var writes []fileWrite
+	for i, row := range rows {
+		issue, err := cp.Mapping.issue(ctx, row, wf)
+		if err != nil {
+			fmt.Fprintf(errOut, "Warning: skipping record %d: %v\n", cp.Done+i+1, err)
+			cp.Skipped++
+			continue
+		}
+		seq := storage.NextSequence(ctx, projectKey, next)
+		next = seq + 1
+		if issue.ID, err = index.NewIssueID(projectKey, seq, storage.Now(ctx)); err != nil {
+			return err
+		}
+		issue.CreatedAt = now
+		issue.UpdatedAt = now
+		if err := index.AssignUID(issue, storage.Now(ctx)); err != nil {
+			return err
+		}
+
+		issuePath, err := storage.IssuePath(ctx, projectKey, issue.ID)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+		}
+		data, err := json.MarshalIndent(issue, "", "  ")
+		if err != nil {
+			return fmt.Errorf("cli: failed to marshal issue %s: %w", issue.ID, err)
+		}
+		writes = append(writes, fileWrite{path: issuePath, data: data})
+		index.AddIssue(issue)
+		cp.Created++
+	}
+	index.UpdatedAt = now
+	cp.Done += len(rows)
+	cp.UpdatedAt = now
+
+	indexWrite, err := plannedIndexWrite(indexPath, &index)
+	if err != nil {
+		return err
+	}
+	checkpointWrite, err := plannedJSONWrite(checkpointPath, cp)
+	if err != nil {
+		return err
+	}
+	writes = append(writes, indexWrite, checkpointWrite)
+
+	if err := storage.BeginTransaction(ctx, projectKey, "migrate", map[string]interface{}{
+		"source": cp.Source,
+		"done":   cp.Done,
+	}); err != nil {
+		return fmt.Errorf("cli: failed to begin transaction: %w", err)
+	}
+	if err := writeFiles(ctx, writes); err != nil {
+		storage.RollbackTransaction(ctx, projectKey)
+		return err
+	}
+	if err := storage.CommitTransaction(ctx, projectKey); err != nil {
+		return fmt.Errorf("cli: failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,318 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+const migrateTestJiraCSV = "\xef\xbb\xbfSummary,Issue key,Issue Type,Status,Priority,Labels,Labels,Description\n" +
+	"Login fails,OLD-1,Bug,In Progress,Highest,auth,web,Cannot log in\n" +
+	",OLD-2,Task,To Do,Medium,,,No title\n" +
+	"Write docs,OLD-3,Story,Closed,Low,docs,,\n" +
+	",,,,,,,\n" +
+	"Add dark mode,OLD-4,Task,To Do,,ui,,\n"
+
+func TestParseCSVRecords(t *testing.T) {
+	records, err := parseCSVRecords([]byte(migrateTestJiraCSV))
+	if err != nil {
+		t.Fatalf("parseCSVRecords() failed: %v", err)
+	}
+
+	wantColumns := []string{"Summary", "Issue key", "Issue Type", "Status", "Priority", "Labels", "Description"}
+	if !slices.Equal(records.Columns, wantColumns) {
+		t.Errorf("Columns = %v, want %v", records.Columns, wantColumns)
+	}
+	if len(records.Rows) != 4 {
+		t.Fatalf("Expected 4 records (blank row skipped), got %d", len(records.Rows))
+	}
+	if got := records.Rows[0]["Labels"]; got != "auth,web" {
+		t.Errorf("Expected repeated Labels columns to be joined, got %q", got)
+	}
+}
+
+func TestSniffMigrateFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"jira.csv":    migrateTestJiraCSV,
+		"plain.csv":   "title,state\nA,open\n",
+		"jira.json":   `{"issues": [{"key": "OLD-1", "fields": {"summary": "A"}}]}`,
+		"github.json": `[{"number": 1, "title": "A"}]`,
+		"other.json":  `{"name": "package"}`,
+		"notes.txt":   "title,state\n",
+	}
+	want := map[string]string{
+		"jira.csv":    migrateSourceJira,
+		"plain.csv":   migrateSourceCSV,
+		"jira.json":   migrateSourceJira,
+		"github.json": migrateSourceGitHub,
+		"other.json":  "",
+		"notes.txt":   "",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		if got := sniffMigrateFile(path); got != want[name] {
+			t.Errorf("sniffMigrateFile(%s) = %q, want %q", name, got, want[name])
+		}
+	}
+
+	sources := detectMigrateSources(dir)
+	found := 0
+	for _, s := range sources {
+		if s.Kind != migrateSourceGitHub || strings.HasSuffix(s.Location, ".json") {
+			found++
+		}
+	}
+	if found != 4 {
+		t.Errorf("Expected 4 detected file sources, got %v", sources)
+	}
+}
+
+func TestParseJiraAndGitHubRecords(t *testing.T) {
+	jira, err := parseJiraRecords([]byte(`{"issues": [{"key": "OLD-7", "fields": {
+		"summary": "Crash on start",
+		"status": {"name": "Done", "statusCategory": {"key": "done"}},
+		"issuetype": {"name": "Bug"},
+		"labels": ["crash", "mobile"]}}]}`))
+	if err != nil {
+		t.Fatalf("parseJiraRecords() failed: %v", err)
+	}
+	if jira.Columns[0] != "key" {
+		t.Errorf("Expected key as first column, got %v", jira.Columns)
+	}
+	row := jira.Rows[0]
+	if row["status.name"] != "Done" || row["issuetype.name"] != "Bug" || row["labels"] != "crash,mobile" {
+		t.Errorf("Unexpected flattened Jira record: %v", row)
+	}
+
+	github, err := parseGitHubRecords([]byte(`[
+		{"number": 12, "title": "Newer", "state": "OPEN", "labels": []},
+		{"number": 3, "title": "Older", "state": "CLOSED", "labels": [{"name": "bug"}, {"name": "good first issue"}]}]`))
+	if err != nil {
+		t.Fatalf("parseGitHubRecords() failed: %v", err)
+	}
+	if github.Rows[0]["number"] != "3" || github.Rows[0]["labels"] != "bug,good first issue" {
+		t.Errorf("Expected oldest issue first with joined labels, got %v", github.Rows[0])
+	}
+
+	m := guessMigrateMapping(github, nil)
+	if m.Fields["title"] != "title" || m.Fields["status"] != "state" || m.Fields["labels"] != "labels" {
+		t.Errorf("Unexpected GitHub mapping: %v", m.Fields)
+	}
+	if m.Values["status"]["CLOSED"] != models.StatusDONE || m.Values["status"]["OPEN"] != models.StatusTODO {
+		t.Errorf("Unexpected GitHub status mapping: %v", m.Values["status"])
+	}
+}
+
+func TestGuessMigrateMapping(t *testing.T) {
+	records, err := parseCSVRecords([]byte(migrateTestJiraCSV))
+	if err != nil {
+		t.Fatalf("parseCSVRecords() failed: %v", err)
+	}
+	m := guessMigrateMapping(records, nil)
+
+	wantFields := map[string]string{
+		"title":       "Summary",
+		"description": "Description",
+		"type":        "Issue Type",
+		"status":      "Status",
+		"priority":    "Priority",
+		"labels":      "Labels",
+	}
+	for field, column := range wantFields {
+		if m.Fields[field] != column {
+			t.Errorf("Fields[%s] = %q, want %q", field, m.Fields[field], column)
+		}
+	}
+
+	tests := []struct {
+		field, value, want string
+	}{
+		{"status", "In Progress", models.StatusDOING},
+		{"status", "Closed", models.StatusDONE},
+		{"status", "To Do", models.StatusTODO},
+		{"type", "Bug", models.TypeBug},
+		{"type", "Story", models.TypeTask},
+		{"priority", "Highest", models.PriorityCRITICAL},
+		{"priority", "Medium", models.PriorityMEDIUM},
+		{"priority", "Low", models.PriorityLOW},
+	}
+	for _, tt := range tests {
+		if got := m.Values[tt.field][tt.value]; got != tt.want {
+			t.Errorf("Values[%s][%q] = %q, want %q", tt.field, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestMigrateWizard(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	source := filepath.Join(t.TempDir(), "export.csv")
+	if err := os.WriteFile(source, []byte(migrateTestJiraCSV), 0644); err != nil {
+		t.Fatalf("Failed to write source: %v", err)
+	}
+
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"migrate-wizard", source, "--project", projectKey, "--yes", "--batch", "2"})
+	buf := new(bytes.Buffer)
+	errBuf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(errBuf)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("migrate-wizard failed: %v\n%s", err, errBuf.String())
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "Preview (first 4 of 4 records)") || !strings.Contains(output, "skipped: empty title") {
+		t.Errorf("Expected preview with skipped record, got: %s", output)
+	}
+	if !strings.Contains(output, "3 issues created, 1 records skipped") {
+		t.Errorf("Expected summary, got: %s", output)
+	}
+	if !strings.Contains(errBuf.String(), "Warning: skipping record 2: empty title") {
+		t.Errorf("Expected warning for record 2, got: %s", errBuf.String())
+	}
+
+	indexPath, _ := storage.ProjectIndexPath(t.Context(), projectKey)
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(t.Context(), indexPath, &index); err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	if len(index.Issues) != 3 {
+		t.Fatalf("Expected 3 issues, got %d", len(index.Issues))
+	}
+
+	issuePath, _ := storage.IssuePath(t.Context(), projectKey, projectKey+"-1")
+	var issue models.Issue
+	if err := storage.ReadJSON(t.Context(), issuePath, &issue); err != nil {
+		t.Fatalf("Failed to read issue: %v", err)
+	}
+	if issue.Title != "Login fails" || issue.Type != models.TypeBug || issue.Status != models.StatusDOING ||
+		issue.Priority != models.PriorityCRITICAL || !slices.Equal(issue.Labels, []string{"auth", "web"}) ||
+		issue.Description != "Cannot log in" {
+		t.Errorf("Unexpected imported issue: %+v", issue)
+	}
+
+	checkpointPath, _ := storage.MigrateCheckpointPath(t.Context(), projectKey)
+	if _, err := os.Stat(checkpointPath); !os.IsNotExist(err) {
+		t.Errorf("Expected checkpoint to be removed after a complete migration")
+	}
+}
+
+func TestMigrateWizardResume(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	source := filepath.Join(t.TempDir(), "export.csv")
+	if err := os.WriteFile(source, []byte(migrateTestJiraCSV), 0644); err != nil {
+		t.Fatalf("Failed to write source: %v", err)
+	}
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		buf := new(bytes.Buffer)
+		cmd.SetOut(buf)
+		cmd.SetErr(new(bytes.Buffer))
+		cmd.SetIn(strings.NewReader(""))
+		err := cmd.Execute()
+		return buf.String(), err
+	}
+
+	if _, err := run("project", "create", projectKey); err != nil {
+		t.Fatalf("project create failed: %v", err)
+	}
+
+	// An earlier run stopped after the first two records
+	checkpointPath, _ := storage.MigrateCheckpointPath(t.Context(), projectKey)
+	checkpoint := migrateCheckpoint{
+		Source:  migrateSource{Kind: migrateSourceJira, Location: source}.String(),
+		Mapping: migrateMapping{Fields: map[string]string{"title": "Summary"}},
+		Done:    2,
+		Created: 1,
+		Skipped: 1,
+	}
+	if err := storage.WriteJSONAtomic(t.Context(), checkpointPath, &checkpoint); err != nil {
+		t.Fatalf("Failed to write checkpoint: %v", err)
+	}
+
+	// A different source cannot be started while the migration is interrupted
+	other := filepath.Join(t.TempDir(), "other.csv")
+	os.WriteFile(other, []byte("title\nA\n"), 0644)
+	if _, err := run("migrate-wizard", other, "--project", projectKey); err == nil || !strings.Contains(err.Error(), "--restart") {
+		t.Errorf("Expected interrupted migration error, got: %v", err)
+	}
+
+	output, err := run("migrate-wizard", source, "--project", projectKey)
+	if err != nil {
+		t.Fatalf("migrate-wizard failed: %v", err)
+	}
+	if !strings.Contains(output, "3 issues created, 1 records skipped") {
+		t.Errorf("Expected totals to include the earlier run, got: %s", output)
+	}
+
+	indexPath, _ := storage.ProjectIndexPath(t.Context(), projectKey)
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(t.Context(), indexPath, &index); err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	var titles []string
+	for _, entry := range index.Issues {
+		titles = append(titles, entry.Title)
+	}
+	if !slices.Equal(titles, []string{"Write docs", "Add dark mode"}) {
+		t.Errorf("Expected only the remaining records to be imported, got %v", titles)
+	}
+}
+
+func TestMigrateWizardEditMapping(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	source := filepath.Join(t.TempDir(), "tasks.csv")
+	if err := os.WriteFile(source, []byte("Name,Summary,Notes\nTask A,Ignored,Details\n"), 0644); err != nil {
+		t.Fatalf("Failed to write source: %v", err)
+	}
+
+	// Map the title to column 1, keep the other defaults, and accept at EOF
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"migrate-wizard", source, "--project", projectKey})
+	cmd.SetIn(strings.NewReader("y\n1\n"))
+	cmd.SetOut(new(bytes.Buffer))
+	errBuf := new(bytes.Buffer)
+	cmd.SetErr(errBuf)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("migrate-wizard failed: %v", err)
+	}
+	if !strings.Contains(errBuf.String(), "title <- Name") {
+		t.Errorf("Expected edited mapping to be shown, got: %s", errBuf.String())
+	}
+
+	issuePath, _ := storage.IssuePath(t.Context(), projectKey, projectKey+"-1")
+	var issue models.Issue
+	if err := storage.ReadJSON(t.Context(), issuePath, &issue); err != nil {
+		t.Fatalf("Failed to read issue: %v", err)
+	}
+	if issue.Title != "Task A" {
+		t.Errorf("Expected title from the Name column, got %q", issue.Title)
+	}
+}
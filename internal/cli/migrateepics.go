@@ -0,0 +1,272 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// EpicMigration is an epic whose ID project migrate-epics scoped to its project.
+type EpicMigration struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Issues int    `json:"issues"` // Issues whose epic link was rewritten
+}
+
+// NewProjectMigrateEpicsCmd creates and returns the project migrate-epics command.
+func NewProjectMigrateEpicsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate-epics <key>",
+		Short: "Scope the epic IDs of a project to its key",
+		Long: `Rewrite the epic IDs of a project created before epic IDs carried the
+project key, so they can't be mistaken for the epics of another project.
+
+Generated IDs keep their number (E-3 becomes CORE-E3) and custom IDs get the
+key as a prefix (AUTH becomes CORE-AUTH). The epic files, the epic links of
+issues, the project index, and the roadmap are rewritten together. Epics that
+already start with the project key are left alone, so running it twice is safe.`,
+		Example:           `  buyruk project migrate-epics CORE --dry-run`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeProjectArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return migrateEpicIDs(args[0], cmd)
+		},
+	}
+
+	cmd.Flags().Bool("dry-run", false, "Show the epic IDs that would change without changing them")
+
+	return cmd
+}
+
+// scopedEpicID returns the project-scoped ID of an unscoped epic ID.
+func scopedEpicID(projectKey, epicID string) string {
+	if seq, ok := models.LegacyEpicSequence(epicID); ok {
+		return models.GenerateEpicID(projectKey, seq)
+	}
+	return projectKey + "-" + epicID
+}
+
+// migrateEpicIDs scopes the unscoped epic IDs of a project to its key.
+func migrateEpicIDs(projectKey string, cmd *cobra.Command) error {
+	if !isValidProjectKey(projectKey) {
+		return invalidf("cli: invalid project key %q (must contain only uppercase letters, numbers, and hyphens)", projectKey)
+	}
+
+	cleanup, err := storage.AcquireLock(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to acquire lock: %w", err)
+	}
+	defer cleanup()
+
+	indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(cmd.Context(), indexPath, &index); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return notFoundf("cli: project %q does not exist", projectKey)
+		}
+		return fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+
+	epicsDir, err := storage.EpicsDir(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve epics directory: %w", err)
+	}
+	entries, err := os.ReadDir(epicsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("cli: failed to read epics directory: %w", err)
+	}
+	existing := map[string]bool{}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			existing[strings.TrimSuffix(entry.Name(), ".json")] = true
+		}
+	}
+
+	// Map every unscoped epic to its scoped ID
+	mapping := map[string]string{}
+	var fromIDs []string
+	for epicID := range existing {
+		if strings.HasPrefix(epicID, projectKey+"-") {
+			continue
+		}
+		newID := scopedEpicID(projectKey, epicID)
+		if existing[newID] {
+			return conflictf("cli: cannot migrate epic %q: epic %q already exists", epicID, newID)
+		}
+		mapping[epicID] = newID
+		fromIDs = append(fromIDs, epicID)
+	}
+	sort.Strings(fromIDs)
+
+	stamp := storage.Timestamp(cmd.Context())
+	var writes []fileWrite
+	migrations := make([]EpicMigration, 0, len(fromIDs))
+	counts := map[string]int{}
+
+	// Rewrite the epic links of issues, re-read under the lock
+	for _, entry := range index.Issues {
+		newEpicID, ok := mapping[entry.EpicID]
+		if !ok {
+			continue
+		}
+		issuePath, err := storage.IssuePath(cmd.Context(), projectKey, entry.ID)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+		}
+		original, err := os.ReadFile(issuePath)
+		if err != nil {
+			return fmt.Errorf("cli: failed to read issue %s: %w", entry.ID, err)
+		}
+		var issue models.Issue
+		if err := json.Unmarshal(original, &issue); err != nil {
+			return fmt.Errorf("cli: failed to parse issue %s: %w", entry.ID, err)
+		}
+		counts[entry.EpicID]++
+		issue.EpicID = newEpicID
+		issue.UpdatedAt = stamp
+		data, err := json.MarshalIndent(&issue, "", "  ")
+		if err != nil {
+			return fmt.Errorf("cli: failed to marshal issue %s: %w", issue.ID, err)
+		}
+		writes = append(writes, fileWrite{path: issuePath, data: data, original: original})
+		index.AddIssue(&issue)
+	}
+
+	// Write each epic under its new ID and remove the old file
+	for _, epicID := range fromIDs {
+		oldPath := filepath.Join(epicsDir, epicID+".json")
+		original, err := os.ReadFile(oldPath)
+		if err != nil {
+			return fmt.Errorf("cli: failed to read epic %s: %w", epicID, err)
+		}
+		var epic models.Epic
+		if err := json.Unmarshal(original, &epic); err != nil {
+			return fmt.Errorf("cli: failed to parse epic %s: %w", epicID, err)
+		}
+		epic.ID = mapping[epicID]
+		epic.UpdatedAt = stamp
+		newPath, err := storage.EpicPath(cmd.Context(), projectKey, epic.ID)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve epic path: %w", err)
+		}
+		data, err := json.MarshalIndent(&epic, "", "  ")
+		if err != nil {
+			return fmt.Errorf("cli: failed to marshal epic %s: %w", epic.ID, err)
+		}
+		writes = append(writes,
+			fileWrite{path: newPath, data: data},
+			fileWrite{path: oldPath, original: original},
+		)
+		index.RemoveEpic(epicID)
+		index.SetEpic(&epic)
+		migrations = append(migrations, EpicMigration{From: epicID, To: epic.ID, Issues: counts[epicID]})
+	}
+
+	// Planned epics on the roadmap may not have a file yet
+	roadmapPath, err := storage.RoadmapPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve roadmap path: %w", err)
+	}
+	if original, err := os.ReadFile(roadmapPath); err == nil {
+		var roadmap models.Roadmap
+		if err := json.Unmarshal(original, &roadmap); err != nil {
+			return fmt.Errorf("cli: failed to parse roadmap: %w", err)
+		}
+		changed := false
+		for i := range roadmap.Items {
+			item := &roadmap.Items[i]
+			if item.EpicID == "" || strings.HasPrefix(item.EpicID, projectKey+"-") {
+				continue
+			}
+			newID, ok := mapping[item.EpicID]
+			if !ok {
+				newID = scopedEpicID(projectKey, item.EpicID)
+				migrations = append(migrations, EpicMigration{From: item.EpicID, To: newID})
+			}
+			item.EpicID = newID
+			changed = true
+		}
+		if changed {
+			data, err := json.MarshalIndent(&roadmap, "", "  ")
+			if err != nil {
+				return fmt.Errorf("cli: failed to marshal roadmap: %w", err)
+			}
+			writes = append(writes, fileWrite{path: roadmapPath, data: data, original: original})
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("cli: failed to read roadmap: %w", err)
+	}
+
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	if !dryRun && len(writes) > 0 {
+		index.UpdatedAt = stamp
+		indexWrite, err := plannedIndexWrite(indexPath, &index)
+		if err != nil {
+			return err
+		}
+		writes = append(writes, indexWrite)
+
+		if err := storage.BeginTransaction(cmd.Context(), projectKey, "migrate-epics", map[string]interface{}{
+			"epics": mapping,
+		}); err != nil {
+			return fmt.Errorf("cli: failed to begin transaction: %w", err)
+		}
+		if err := writeFiles(cmd.Context(), writes); err != nil {
+			storage.RollbackTransaction(cmd.Context(), projectKey)
+			return err
+		}
+		if err := storage.CommitTransaction(cmd.Context(), projectKey); err != nil {
+			return fmt.Errorf("cli: failed to commit transaction: %w", err)
+		}
+	}
+
+	return renderEpicMigrations(migrations, projectKey, dryRun, cmd)
+}
+
+// renderEpicMigrations reports the rewritten epic IDs.
+func renderEpicMigrations(migrations []EpicMigration, projectKey string, dryRun bool, cmd *cobra.Command) error {
+	out := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(migrations)
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(out, migrations)
+	case config.DefaultFormatLSON:
+		for _, m := range migrations {
+			fmt.Fprintf(out, "@MIGRATED: %s|%s|%d\n", m.From, m.To, m.Issues)
+		}
+		return nil
+	}
+
+	if !dryRun {
+		out = successOut(cmd)
+	}
+	if len(migrations) == 0 {
+		fmt.Fprintf(out, "All epic IDs of project %q are already scoped to it\n", projectKey)
+		return nil
+	}
+	verb := "Migrated"
+	if dryRun {
+		verb = "Would migrate"
+	}
+	styles := ui.NewStyles()
+	for _, m := range migrations {
+		fmt.Fprintf(out, "%s %s -> %s (%d issues)\n", verb, m.From, styles.ID(m.To), m.Issues)
+	}
+	return nil
+}
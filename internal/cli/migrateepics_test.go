@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+// writeLegacyEpic writes an epic with an ID from before epic IDs were scoped
+// to their project, as epic create no longer makes them.
+func writeLegacyEpic(t *testing.T, projectKey, epicID, title string) {
+	t.Helper()
+	epic := &models.Epic{ID: epicID, Title: title, Status: models.StatusTODO}
+	epicPath, _ := storage.EpicPath(t.Context(), projectKey, epicID)
+	if err := storage.WriteJSONAtomic(t.Context(), epicPath, epic); err != nil {
+		t.Fatalf("Failed to write epic %s: %v", epicID, err)
+	}
+	if err := updateEpicIndex(t.Context(), projectKey, func(idx *models.ProjectIndex) {
+		idx.SetEpic(epic)
+	}); err != nil {
+		t.Fatalf("Failed to index epic %s: %v", epicID, err)
+	}
+}
+
+func TestMigrateEpics(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	if _, err := run("project", "create", projectKey); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	writeLegacyEpic(t, projectKey, "E-1", "Login")
+	writeLegacyEpic(t, projectKey, "AUTH", "Auth")
+	for _, args := range [][]string{
+		{"issue", "create", "--project", projectKey, "--title", "Form", "--epic", "E-1"},
+		{"issue", "create", "--project", projectKey, "--title", "Tokens", "--epic", "AUTH"},
+		{"roadmap", "add", "E-1", "--project", projectKey, "--quarter", "2024-Q3"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	// New epics continue the sequence of the legacy ones
+	if _, err := run("epic", "create", "--project", projectKey, "--title", "Billing"); err != nil {
+		t.Fatalf("epic create failed: %v", err)
+	}
+	if _, err := run("epic", "view", projectKey+"-E2", "--project", projectKey); err != nil {
+		t.Errorf("Expected the new epic to be %s-E2: %v", projectKey, err)
+	}
+
+	migrate := func(args ...string) []EpicMigration {
+		t.Helper()
+		out, err := run(append([]string{"project", "migrate-epics", projectKey, "--format", "json"}, args...)...)
+		if err != nil {
+			t.Fatalf("migrate-epics %v failed: %v", args, err)
+		}
+		var migrations []EpicMigration
+		if err := json.Unmarshal([]byte(out), &migrations); err != nil {
+			t.Fatalf("Failed to parse migrations: %v\n%s", err, out)
+		}
+		return migrations
+	}
+
+	dry := migrate("--dry-run")
+	if len(dry) != 2 || dry[0] != (EpicMigration{From: "AUTH", To: projectKey + "-AUTH", Issues: 1}) || dry[1] != (EpicMigration{From: "E-1", To: projectKey + "-E1", Issues: 1}) {
+		t.Fatalf("Unexpected dry run: %+v", dry)
+	}
+	if issue, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-1"); issue.EpicID != "E-1" {
+		t.Errorf("Dry run changed the epic link to %q", issue.EpicID)
+	}
+
+	migrate()
+	if issue, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-1"); issue.EpicID != projectKey+"-E1" {
+		t.Errorf("EpicID = %q, want %s-E1", issue.EpicID, projectKey)
+	}
+	if issue, _ := loadLocalIssue(t.Context(), projectKey, projectKey+"-2"); issue.EpicID != projectKey+"-AUTH" {
+		t.Errorf("EpicID = %q, want %s-AUTH", issue.EpicID, projectKey)
+	}
+	oldPath, _ := storage.EpicPath(t.Context(), projectKey, "E-1")
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Error("Expected the legacy epic file to be removed")
+	}
+	index, err := loadQueryIndex(t.Context(), projectKey)
+	if err != nil {
+		t.Fatalf("Failed to load index: %v", err)
+	}
+	if entry := index.FindEpic(projectKey + "-E1"); entry == nil || index.FindEpic("E-1") != nil {
+		t.Errorf("Expected the index to list %s-E1 instead of E-1, got %+v", projectKey, index.Epics)
+	}
+	roadmapPath, _ := storage.RoadmapPath(t.Context(), projectKey)
+	var roadmap models.Roadmap
+	if err := storage.ReadJSON(t.Context(), roadmapPath, &roadmap); err != nil || len(roadmap.Items) != 1 || roadmap.Items[0].EpicID != projectKey+"-E1" {
+		t.Errorf("Expected the roadmap to plan %s-E1, got %+v (%v)", projectKey, roadmap.Items, err)
+	}
+
+	if again := migrate(); len(again) != 0 {
+		t.Errorf("Expected nothing to migrate twice, got %+v", again)
+	}
+
+	// Epics of another project are refused once IDs are scoped
+	if _, err := run("issue", "update", projectKey+"-1", "--epic", "OTHER-E1"); err == nil {
+		t.Error("Expected linking another project's epic to fail")
+	}
+	if _, err := run("epic", "view", projectKey+"-E1", "--project", "OTHER"); err == nil {
+		t.Error("Expected a --project that disagrees with the epic ID to fail")
+	}
+	if out, err := run("epic", "view", projectKey+"-E1", "--format", "lson"); err != nil || !strings.Contains(out, "Login") {
+		t.Errorf("Expected the epic's project to follow from its ID, got %q (%v)", out, err)
+	}
+}
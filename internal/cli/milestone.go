@@ -0,0 +1,434 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// MilestoneWithProgress is a milestone with its progress and the epics its
+// issues belong to, for JSON and YAML output.
+type MilestoneWithProgress struct {
+	*models.Milestone
+	Progress *EpicProgress `json:"progress"`
+	Epics    []string      `json:"epics"` // Epics of the milestone's issues; "" for issues without one
+}
+
+// NewMilestoneCmd creates and returns the milestone command.
+func NewMilestoneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "milestone",
+		Short: "Plan issues into releases",
+		Long: `Plan issues into milestones, such as versions, through their fix version
+(issue create/update --fix-version), and follow their progress.
+
+Unlike an epic, which groups the issues of one feature, a milestone is what
+ships together, so it can take issues of any epic. Milestones are stored in
+projects/[KEY]/milestones.json.`,
+	}
+
+	cmd.AddCommand(NewMilestoneCreateCmd())
+	cmd.AddCommand(NewMilestoneListCmd())
+	cmd.AddCommand(NewMilestoneViewCmd())
+	cmd.AddCommand(NewMilestoneCloseCmd())
+
+	return cmd
+}
+
+// NewMilestoneCreateCmd creates and returns the milestone create command.
+func NewMilestoneCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <milestone-id>",
+		Short: "Create a milestone, or change its due date or description",
+		Example: `  buyruk milestone create v1.2 --due 2024-07-01 --description "Login and payments"
+  buyruk milestone create v1.2 --due none`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeMilestoneArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return createMilestone(args[0], cmd)
+		},
+	}
+
+	cmd.Flags().String("due", "", "Planned release day (e.g. 2024-07-01, 3w; \"none\" clears it)")
+	cmd.Flags().String("description", "", "What the release is about")
+
+	return cmd
+}
+
+// createMilestone creates a milestone or updates its due date and description.
+func createMilestone(milestoneID string, cmd *cobra.Command) error {
+	if err := models.ValidateMilestoneID(milestoneID); err != nil {
+		return invalidf("cli: %w", err)
+	}
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	if _, err := loadMilestones(cmd.Context(), projectKey); err != nil {
+		return err
+	}
+
+	due, _ := cmd.Flags().GetString("due")
+	if due != "" && due != "none" {
+		t, _, err := getTimeFlag(cmd, "due")
+		if err != nil {
+			return err
+		}
+		due = t.In(config.Location()).Format(time.DateOnly)
+	}
+	description, _ := cmd.Flags().GetString("description")
+
+	milestonesPath, err := storage.MilestonesPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve milestones path: %w", err)
+	}
+
+	created := false
+	var milestones models.Milestones
+	if err := storage.UpdateJSONAtomic(cmd.Context(), milestonesPath, &milestones, func(v interface{}) error {
+		m := v.(*models.Milestones)
+		milestone := models.Milestone{ID: milestoneID, Status: models.MilestoneOpen, CreatedAt: storage.Timestamp(cmd.Context())}
+		if existing := m.Find(milestoneID); existing != nil {
+			milestone = *existing
+		} else {
+			created = true
+		}
+		if due == "none" {
+			milestone.Due = ""
+		} else if due != "" {
+			milestone.Due = due
+		}
+		if cmd.Flags().Changed("description") {
+			milestone.Description = description
+		}
+		m.Set(milestone)
+		return m.Validate()
+	}); err != nil {
+		return fmt.Errorf("cli: failed to update milestones: %w", err)
+	}
+
+	out := successOut(cmd)
+	if created {
+		fmt.Fprintf(out, "Created milestone %s\n", milestoneID)
+	} else {
+		fmt.Fprintf(out, "Updated milestone %s\n", milestoneID)
+	}
+	return nil
+}
+
+// NewMilestoneListCmd creates and returns the milestone list command.
+func NewMilestoneListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the milestones of a project with their progress",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listMilestones(cmd)
+		},
+	}
+
+	cmd.Flags().Bool("open", false, "Only list open milestones")
+
+	return cmd
+}
+
+// listMilestones renders the milestones of a project in the resolved output
+// format.
+func listMilestones(cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	milestones, err := loadMilestones(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	if open, _ := cmd.Flags().GetBool("open"); open {
+		milestones.Milestones = slices.DeleteFunc(milestones.Milestones, func(m models.Milestone) bool { return m.IsClosed() })
+	}
+	list, err := loadMilestoneProgress(cmd.Context(), projectKey, milestones.Milestones)
+	if err != nil {
+		return err
+	}
+
+	w := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(list)
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(w, list)
+	case config.DefaultFormatLSON:
+		for _, m := range list {
+			fmt.Fprintf(w, "@MILESTONE: %s|%s|%s|%d/%d\n", m.ID, m.Status, m.Due, m.Progress.Done, m.Progress.Total)
+		}
+	default:
+		if len(list) == 0 {
+			fmt.Fprintf(w, "No milestones (create one with `buyruk milestone create v1.0 --due 4w`)\n")
+			return nil
+		}
+		styles := ui.NewStyles()
+		for _, m := range list {
+			line := fmt.Sprintf("%s  %-6s  %s", styles.ID(m.ID), m.Status, formatProgress(m.Progress.Done, m.Progress.Total))
+			if m.Due != "" {
+				line += "  due " + m.Due
+			}
+			if m.Description != "" {
+				line += "  " + styles.Title(m.Description)
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+	return nil
+}
+
+// NewMilestoneViewCmd creates and returns the milestone view command.
+func NewMilestoneViewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "view <milestone-id>",
+		Short: "Show the progress of a milestone",
+		Long: `Show a milestone with its issues by status, how many are done, the issues
+still open, and the epics its issues come from.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeMilestoneArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return viewMilestone(args[0], cmd)
+		},
+	}
+
+	return cmd
+}
+
+// viewMilestone renders a milestone and its progress.
+func viewMilestone(milestoneID string, cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	milestones, err := loadMilestones(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	milestone := milestones.Find(milestoneID)
+	if milestone == nil {
+		return notFoundf("cli: milestone %q not found", milestoneID)
+	}
+	list, err := loadMilestoneProgress(cmd.Context(), projectKey, []models.Milestone{*milestone})
+	if err != nil {
+		return err
+	}
+	m := list[0]
+
+	out := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(m)
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(out, m)
+	case config.DefaultFormatLSON:
+		fmt.Fprintf(out, "@MILESTONE: %s\n@STATUS: %s\n", m.ID, m.Status)
+		if m.Due != "" {
+			fmt.Fprintf(out, "@DUE: %s\n", m.Due)
+		}
+		if m.Description != "" {
+			fmt.Fprintf(out, "@DESCRIPTION: %s\n", m.Description)
+		}
+		fmt.Fprintf(out, "@EPICS: %s\n", strings.Join(m.Epics, ","))
+	default:
+		renderMilestone(m, out)
+	}
+	renderEpicProgress(m.Progress, true, cmd, out)
+	return nil
+}
+
+// renderMilestone renders the details of a milestone in the modern format.
+func renderMilestone(m MilestoneWithProgress, w io.Writer) {
+	styles := ui.NewStyles()
+	fmt.Fprintf(w, "%s  %s\n", styles.ID(m.ID), m.Status)
+	if m.Description != "" {
+		fmt.Fprintf(w, "%s\n", styles.Title(m.Description))
+	}
+	if m.Due != "" {
+		fmt.Fprintf(w, "%s: %s\n", styles.Label("Due"), m.Due)
+	}
+	if m.ClosedAt != "" {
+		fmt.Fprintf(w, "%s: %s\n", styles.Label("Closed"), ui.FormatTime(m.ClosedAt, config.Location()))
+	}
+	if len(m.Epics) > 0 {
+		epics := make([]string, len(m.Epics))
+		for i, epic := range m.Epics {
+			epics[i] = epic
+			if epic == "" {
+				epics[i] = "(no epic)"
+			}
+		}
+		fmt.Fprintf(w, "%s: %s\n", styles.Label("Epics"), strings.Join(epics, ", "))
+	}
+}
+
+// NewMilestoneCloseCmd creates and returns the milestone close command.
+func NewMilestoneCloseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "close <milestone-id>",
+		Short: "Close a released milestone",
+		Long: `Close a milestone once it has shipped. Closed milestones can't be given as
+a fix version, and a milestone with open issues is only closed with --force
+(move the issues to the next milestone first with issue update --fix-version).`,
+		Example: `  buyruk milestone close v1.2
+  buyruk milestone close v1.2 --reopen`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeMilestoneArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return closeMilestone(args[0], cmd)
+		},
+	}
+
+	cmd.Flags().Bool("force", false, "Close the milestone even if it has open issues")
+	cmd.Flags().Bool("reopen", false, "Reopen a closed milestone")
+	cmd.MarkFlagsMutuallyExclusive("force", "reopen")
+
+	return cmd
+}
+
+// closeMilestone closes or reopens a milestone.
+func closeMilestone(milestoneID string, cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	milestones, err := loadMilestones(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	if milestones.Find(milestoneID) == nil {
+		return notFoundf("cli: milestone %q not found", milestoneID)
+	}
+	reopen, _ := cmd.Flags().GetBool("reopen")
+	if force, _ := cmd.Flags().GetBool("force"); !reopen && !force {
+		progress, err := loadProgress(cmd.Context(), projectKey, []string{milestoneID}, func(entry *models.IndexEntry) string { return entry.FixVersion })
+		if err != nil {
+			return err
+		}
+		if open := progress[milestoneID].OpenIssues; len(open) > 0 {
+			return invalidf("cli: milestone %s has %d open issue(s), e.g. %s (move them with issue update --fix-version, or use --force)",
+				milestoneID, len(open), open[0].ID)
+		}
+	}
+
+	milestonesPath, err := storage.MilestonesPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve milestones path: %w", err)
+	}
+	if err := storage.UpdateJSONAtomic(cmd.Context(), milestonesPath, &models.Milestones{}, func(v interface{}) error {
+		milestone := v.(*models.Milestones).Find(milestoneID)
+		if milestone == nil {
+			return notFoundf("cli: milestone %q not found", milestoneID)
+		}
+		if reopen {
+			milestone.Status = models.MilestoneOpen
+			milestone.ClosedAt = ""
+		} else if !milestone.IsClosed() {
+			milestone.Status = models.MilestoneClosed
+			milestone.ClosedAt = storage.Timestamp(cmd.Context())
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("cli: failed to update milestones: %w", err)
+	}
+
+	if reopen {
+		fmt.Fprintf(successOut(cmd), "Reopened milestone %s\n", milestoneID)
+	} else {
+		fmt.Fprintf(successOut(cmd), "Closed milestone %s\n", milestoneID)
+	}
+	return nil
+}
+
+// loadMilestoneProgress rolls up the progress of milestones from their
+// issues' fix versions, with the epics the issues belong to.
+func loadMilestoneProgress(ctx context.Context, projectKey string, milestones []models.Milestone) ([]MilestoneWithProgress, error) {
+	ids := make([]string, len(milestones))
+	for i, milestone := range milestones {
+		ids[i] = milestone.ID
+	}
+	fixVersion := func(entry *models.IndexEntry) string { return entry.FixVersion }
+	progress, err := loadProgress(ctx, projectKey, ids, fixVersion)
+	if err != nil {
+		return nil, err
+	}
+	index, err := loadQueryIndex(ctx, projectKey)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]MilestoneWithProgress, len(milestones))
+	for i := range milestones {
+		epics := []string{}
+		for j := range index.Issues {
+			entry := &index.Issues[j]
+			if entry.FixVersion == milestones[i].ID && !slices.Contains(epics, entry.EpicID) {
+				epics = append(epics, entry.EpicID)
+			}
+		}
+		slices.Sort(epics)
+		list[i] = MilestoneWithProgress{Milestone: &milestones[i], Progress: progress[milestones[i].ID], Epics: epics}
+	}
+	return list, nil
+}
+
+// validateFixVersion checks that a fix version is an open milestone of a
+// project.
+func validateFixVersion(ctx context.Context, projectKey, milestoneID string) error {
+	if err := models.ValidateMilestoneID(milestoneID); err != nil {
+		return invalidf("cli: %w", err)
+	}
+	milestones, err := loadMilestones(ctx, projectKey)
+	if err != nil {
+		return err
+	}
+	milestone := milestones.Find(milestoneID)
+	if milestone == nil {
+		return notFoundf("cli: milestone %q not found (create it with `buyruk milestone create %s`)", milestoneID, milestoneID)
+	}
+	if milestone.IsClosed() {
+		return invalidf("cli: milestone %s is closed (reopen it with `buyruk milestone close %s --reopen`)", milestoneID, milestoneID)
+	}
+	return nil
+}
+
+// loadMilestones loads the milestones of a project. Projects without
+// milestones have none.
+func loadMilestones(ctx context.Context, projectKey string) (*models.Milestones, error) {
+	projectDir, err := storage.ProjectDir(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve project directory: %w", err)
+	}
+	if _, err := os.Stat(projectDir); os.IsNotExist(err) {
+		return nil, notFoundf("cli: project %q does not exist", projectKey)
+	}
+
+	milestonesPath, err := storage.MilestonesPath(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve milestones path: %w", err)
+	}
+	milestones := &models.Milestones{Milestones: []models.Milestone{}}
+	if err := storage.ReadJSON(ctx, milestonesPath, milestones); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("cli: failed to load milestones: %w", err)
+	}
+	return milestones, nil
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestMilestone(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	id := func(n string) string { return projectKey + "-" + n }
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"epic", "create", "--project", projectKey, "--id", projectKey + "-E1", "--title", "Login"},
+		{"epic", "create", "--project", projectKey, "--id", projectKey + "-E2", "--title", "Payments"},
+		{"milestone", "create", "v1.2", "--project", projectKey, "--due", "2024-07-01", "--description", "First release"},
+		{"issue", "create", "--project", projectKey, "--title", "Login page", "--epic", projectKey + "-E1", "--fix-version", "v1.2"},
+		{"issue", "create", "--project", projectKey, "--title", "Card payments", "--epic", projectKey + "-E2", "--fix-version", "v1.2"},
+		{"issue", "create", "--project", projectKey, "--title", "Later"},
+		{"issue", "update", id("1"), "--status", "DONE"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	if _, err := run("issue", "update", id("3"), "fix-version=v9"); err == nil {
+		t.Error("Expected a fix version that is not a milestone to fail")
+	}
+
+	out, err := run("list", "--project", projectKey, "--milestone", "v1.2", "--sort", "id", "--template", "{{.ID}}")
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if got := strings.Join(strings.Fields(out), " "); got != id("1")+" "+id("2") {
+		t.Errorf("Expected the issues of v1.2, got %q", got)
+	}
+
+	out, err = run("milestone", "view", "v1.2", "--project", projectKey, "--format", "json")
+	if err != nil {
+		t.Fatalf("milestone view failed: %v", err)
+	}
+	var milestone MilestoneWithProgress
+	if err := json.Unmarshal([]byte(out), &milestone); err != nil {
+		t.Fatalf("Failed to parse milestone: %v\n%s", err, out)
+	}
+	if milestone.Due != "2024-07-01" || milestone.Progress.Done != 1 || milestone.Progress.Total != 2 || milestone.Progress.Percent != 50 {
+		t.Errorf("Expected v1.2 due 2024-07-01 and 1/2 done, got %+v", milestone)
+	}
+	if strings.Join(milestone.Epics, ",") != projectKey+"-E1,"+projectKey+"-E2" {
+		t.Errorf("Expected v1.2 to span %s-E1 and %s-E2, got %v", projectKey, projectKey, milestone.Epics)
+	}
+
+	// Open issues keep a milestone open unless forced
+	if _, err := run("milestone", "close", "v1.2", "--project", projectKey); err == nil {
+		t.Error("Expected closing a milestone with open issues to fail")
+	}
+	for _, args := range [][]string{
+		{"milestone", "create", "v1.3", "--project", projectKey},
+		{"issue", "update", id("2"), "--fix-version", "v1.3"},
+		{"milestone", "close", "v1.2", "--project", projectKey},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+	if _, err := run("issue", "update", id("3"), "--fix-version", "v1.2"); err == nil {
+		t.Error("Expected a closed milestone to be refused as a fix version")
+	}
+
+	out, err = run("milestone", "list", "--project", projectKey, "--open", "--format", "lson")
+	if err != nil {
+		t.Fatalf("milestone list failed: %v", err)
+	}
+	if want := "@MILESTONE: v1.3|open||0/1\n"; out != want {
+		t.Errorf("Expected only v1.3 open, got %q", out)
+	}
+
+	if _, err := run("issue", "update", id("2"), "--fix-version", "none"); err != nil {
+		t.Fatalf("issue update failed: %v", err)
+	}
+	issue, err := loadLocalIssue(t.Context(), projectKey, id("2"))
+	if err != nil {
+		t.Fatalf("Failed to load issue: %v", err)
+	}
+	if issue.FixVersion != "" {
+		t.Errorf("Expected the fix version to be cleared, got %q", issue.FixVersion)
+	}
+}
@@ -0,0 +1,321 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// NewIssueMoveCmd creates and returns the issue move command.
+func NewIssueMoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "move <id> <project>",
+		Short: "Move an issue to another project",
+		Long: `Move an issue (and its subtasks) to another existing project.
+
+The issue gets the next sequence number of the target project. Dependencies on
+it are rewritten in every project, both indexes are updated together, and the
+source project keeps a redirect so the old ID still resolves with 'view' and
+is never reused.
+
+The epic link is kept only if the target project has an epic with the same ID,
+and the issue must fit the target project's workflow.
+
+Example:
+  buyruk issue move CORE-12 OTHER`,
+		Args: cobra.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+			if len(args) == 1 {
+				return completeProjectKeys(cmd, args, toComplete)
+			}
+			return completeIssueArgs(1)(cmd, args, toComplete)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveIssueArgs(cmd, args[:1]); err != nil {
+				return err
+			}
+			issueID := args[0]
+			targetKey := args[1]
+			return moveIssue(issueID, targetKey, cmd)
+		},
+	}
+
+	return cmd
+}
+
+// moveIssue moves an issue and its subtasks to another project.
+func moveIssue(issueID, targetKey string, cmd *cobra.Command) error {
+	projectKey, _, err := parseIssueID(cmd.Context(), issueID)
+	if err != nil {
+		return invalidf("cli: invalid issue ID %q: %w", issueID, err)
+	}
+	if !isValidProjectKey(targetKey) {
+		return invalidf("cli: invalid project key %q (must contain only uppercase letters, numbers, and hyphens)", targetKey)
+	}
+	if targetKey == projectKey {
+		return invalidf("cli: %s is already in project %q", issueID, targetKey)
+	}
+
+	targetIndexPath, err := storage.ProjectIndexPath(cmd.Context(), targetKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	if _, err := os.Stat(targetIndexPath); os.IsNotExist(err) {
+		return notFoundf("cli: project %q does not exist", targetKey)
+	}
+
+	// Lock both projects in key order so concurrent moves cannot deadlock
+	first, second := projectKey, targetKey
+	if second < first {
+		first, second = second, first
+	}
+	for _, key := range []string{first, second} {
+		cleanup, err := storage.AcquireLock(cmd.Context(), key)
+		if err != nil {
+			return fmt.Errorf("cli: failed to acquire project lock for %q: %w", key, err)
+		}
+		defer cleanup()
+	}
+
+	indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(cmd.Context(), indexPath, &index); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return notFoundf("cli: project %q does not exist", projectKey)
+		}
+		return fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+	if index.FindIssue(issueID) == nil {
+		return notFoundf("cli: issue %q not found", issueID)
+	}
+
+	var targetIndex models.ProjectIndex
+	if err := storage.ReadIndex(cmd.Context(), targetIndexPath, &targetIndex); err != nil {
+		return fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+	targetWorkflow, err := loadWorkflow(cmd.Context(), targetKey)
+	if err != nil {
+		return err
+	}
+
+	// The issue moves with all its subtasks
+	selected := map[string]bool{issueID: true}
+	for changed := true; changed; {
+		changed = false
+		for _, entry := range index.Issues {
+			if entry.ParentID != "" && selected[entry.ParentID] && !selected[entry.ID] {
+				selected[entry.ID] = true
+				changed = true
+			}
+		}
+	}
+	moved, err := loadIssuesByID(cmd.Context(), projectKey, selected)
+	if err != nil {
+		return err
+	}
+
+	// Assign the next sequence numbers of the target project
+	next := maxRedirectSequence(cmd.Context(), targetKey)
+	for _, entry := range targetIndex.Issues {
+		if _, seq, err := models.ParseIssueID(entry.ID); err == nil && seq > next {
+			next = seq
+		}
+	}
+	next++
+	oldIDs := make([]string, len(moved))
+	mapping := make(map[string]string, len(moved))
+	for i, issue := range moved {
+		oldIDs[i] = issue.ID
+		seq := storage.NextSequence(cmd.Context(), targetKey, next)
+		if mapping[issue.ID], err = targetIndex.NewIssueID(targetKey, seq, storage.Now(cmd.Context())); err != nil {
+			return err
+		}
+		next = seq + 1
+	}
+
+	errOut := cmd.ErrOrStderr()
+	now := storage.Timestamp(cmd.Context())
+	var writes []fileWrite
+
+	for _, issue := range moved {
+		oldID := issue.ID
+		issue.ID = mapping[oldID]
+		issue.RenameLinks(renameFromMapping(mapping))
+		if issue.ParentID != "" {
+			if newParent, ok := mapping[issue.ParentID]; ok {
+				issue.ParentID = newParent
+			} else {
+				fmt.Fprintf(errOut, "Warning: %s was a subtask of %s, which stays in %s; the parent link was removed\n", oldID, issue.ParentID, projectKey)
+				issue.ParentID = ""
+			}
+		}
+		if issue.EpicID != "" {
+			epicPath, err := storage.EpicPath(cmd.Context(), targetKey, issue.EpicID)
+			if err != nil {
+				return fmt.Errorf("cli: failed to resolve epic path: %w", err)
+			}
+			if _, err := os.Stat(epicPath); os.IsNotExist(err) {
+				fmt.Fprintf(errOut, "Warning: epic %s does not exist in %s; the epic link of %s was removed\n", issue.EpicID, targetKey, oldID)
+				issue.EpicID = ""
+			}
+		}
+		if len(issue.Components) > 0 {
+			registry, err := loadComponents(cmd.Context(), targetKey)
+			if err != nil {
+				return err
+			}
+			issue.Components = slices.DeleteFunc(issue.Components, func(component string) bool {
+				if registry.Find(component) != nil {
+					return false
+				}
+				fmt.Fprintf(errOut, "Warning: component %s is not registered in %s; it was removed from %s\n", component, targetKey, oldID)
+				return true
+			})
+			if len(issue.Components) == 0 {
+				issue.Components = nil
+			}
+		}
+		if issue.FixVersion != "" {
+			if err := validateFixVersion(cmd.Context(), targetKey, issue.FixVersion); err != nil {
+				fmt.Fprintf(errOut, "Warning: milestone %s is not open in %s; the fix version of %s was removed\n", issue.FixVersion, targetKey, oldID)
+				issue.FixVersion = ""
+			}
+		}
+		if err := issue.ValidateWithWorkflow(targetWorkflow); err != nil {
+			return fmt.Errorf("cli: %s does not fit the workflow of %q: %w", oldID, targetKey, err)
+		}
+		issue.UpdatedAt = now
+		if err := targetIndex.AssignUID(issue, storage.Now(cmd.Context())); err != nil {
+			return err
+		}
+
+		targetPath, err := storage.IssuePath(cmd.Context(), targetKey, issue.ID)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+		}
+		data, err := json.MarshalIndent(issue, "", "  ")
+		if err != nil {
+			return fmt.Errorf("cli: failed to marshal issue %s: %w", issue.ID, err)
+		}
+		writes = append(writes, fileWrite{path: targetPath, data: data})
+
+		sourcePath, err := storage.IssuePath(cmd.Context(), projectKey, oldID)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+		}
+		original, err := os.ReadFile(sourcePath)
+		if err != nil {
+			return fmt.Errorf("cli: failed to read issue %s: %w", oldID, err)
+		}
+		writes = append(writes, fileWrite{path: sourcePath, original: original})
+
+		index.RemoveIssue(oldID)
+		targetIndex.AddIssue(issue)
+	}
+
+	// Both indexes, and the redirects that keep old IDs resolving
+	index.UpdatedAt = now
+	targetIndex.UpdatedAt = now
+	redirectsPath, err := storage.RedirectsPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve redirects path: %w", err)
+	}
+	redirects := map[string]string{}
+	if err := storage.ReadJSON(cmd.Context(), redirectsPath, &redirects); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("cli: failed to load redirects: %w", err)
+	}
+	for oldID, newID := range mapping {
+		redirects[oldID] = newID
+	}
+	for _, file := range []struct {
+		path  string
+		index *models.ProjectIndex
+	}{{indexPath, &index}, {targetIndexPath, &targetIndex}} {
+		write, err := plannedIndexWrite(file.path, file.index)
+		if err != nil {
+			return err
+		}
+		writes = append(writes, write)
+	}
+	redirectsWrite, err := plannedJSONWrite(redirectsPath, redirects)
+	if err != nil {
+		return err
+	}
+	writes = append(writes, redirectsWrite)
+
+	// Dependencies on the moved issues, in any project
+	refs := &referenceRewrite{
+		rename: renameFromMapping(mapping),
+		locked: map[string]bool{projectKey: true, targetKey: true},
+		skip:   selected,
+		now:    now,
+	}
+	external, unlock, err := refs.plan(cmd.Context())
+	defer unlock()
+	if err != nil {
+		return err
+	}
+	writes = append(writes, external...)
+
+	// Apply everything under one transaction per project
+	for _, key := range []string{projectKey, targetKey} {
+		if err := storage.BeginTransaction(cmd.Context(), key, "move_issue", map[string]interface{}{
+			"issue": issueID,
+			"to":    mapping[issueID],
+		}); err != nil {
+			storage.RollbackTransaction(cmd.Context(), projectKey)
+			return fmt.Errorf("cli: failed to begin transaction: %w", err)
+		}
+	}
+	if err := writeFiles(cmd.Context(), writes); err != nil {
+		storage.RollbackTransaction(cmd.Context(), projectKey)
+		storage.RollbackTransaction(cmd.Context(), targetKey)
+		return err
+	}
+	for _, key := range []string{projectKey, targetKey} {
+		if err := storage.CommitTransaction(cmd.Context(), key); err != nil {
+			return fmt.Errorf("cli: failed to commit transaction: %w", err)
+		}
+	}
+
+	out := successOut(cmd)
+	for _, oldID := range oldIDs {
+		fmt.Fprintf(out, "Moved %s -> %s\n", oldID, mapping[oldID])
+	}
+	if len(external) > 0 {
+		fmt.Fprintf(out, "Updated %d dependent issues\n", len(external))
+	}
+	return nil
+}
+
+// plannedJSONWrite marshals v as the new content of path, keeping the original content.
+func plannedJSONWrite(path string, v interface{}) (fileWrite, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fileWrite{}, fmt.Errorf("cli: failed to marshal %s: %w", path, err)
+	}
+	original, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fileWrite{}, fmt.Errorf("cli: failed to read %s: %w", path, err)
+	}
+	return fileWrite{path: path, data: data, original: original}, nil
+}
+
+// plannedIndexWrite plans to write index as the new project index at path,
+// keeping the original content of project.json.
+func plannedIndexWrite(path string, index *models.ProjectIndex) (fileWrite, error) {
+	original, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fileWrite{}, fmt.Errorf("cli: failed to read %s: %w", path, err)
+	}
+	return fileWrite{path: path, index: index, original: original}, nil
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestMoveIssue(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	targetKey := projectKey + "-OTHER"
+	defer func() {
+		for _, key := range []string{projectKey, targetKey} {
+			projectDir, _ := storage.ProjectDir(t.Context(), key)
+			os.RemoveAll(projectDir)
+		}
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		buf := new(bytes.Buffer)
+		cmd.SetOut(buf)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return buf.String(), err
+	}
+
+	// KEY-2 moves with its subtask KEY-3; KEY-1 and OTHER-1 depend on KEY-2
+	steps := [][]string{
+		{"project", "create", projectKey},
+		{"project", "create", targetKey},
+		{"issue", "create", "--project", projectKey, "--title", "Stays"},
+		{"issue", "create", "--project", projectKey, "--title", "Moves"},
+		{"issue", "create", "--project", projectKey, "--title", "Subtask", "--parent", projectKey + "-2"},
+		{"issue", "create", "--project", targetKey, "--title", "Existing"},
+		{"issue", "link", projectKey + "-1", projectKey + "-2"},
+		{"issue", "link", targetKey + "-1", projectKey + "-2"},
+		{"issue", "link", projectKey + "-2", projectKey + "-1"},
+	}
+	for _, args := range steps {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	output, err := run("issue", "move", projectKey+"-2", targetKey)
+	if err != nil {
+		t.Fatalf("issue move failed: %v", err)
+	}
+	if !strings.Contains(output, projectKey+"-2 -> "+targetKey+"-2") || !strings.Contains(output, projectKey+"-3 -> "+targetKey+"-3") {
+		t.Errorf("Expected ID mapping in output, got: %s", output)
+	}
+
+	readIssue := func(key, id string) *models.Issue {
+		t.Helper()
+		path, _ := storage.IssuePath(t.Context(), key, id)
+		var issue models.Issue
+		if err := storage.ReadJSON(t.Context(), path, &issue); err != nil {
+			t.Fatalf("Failed to read issue %s: %v", id, err)
+		}
+		return &issue
+	}
+
+	moved := readIssue(targetKey, targetKey+"-2")
+	if moved.Title != "Moves" || !slices.Equal(moved.BlockedBy, []string{projectKey + "-1"}) {
+		t.Errorf("Unexpected moved issue: %+v", moved)
+	}
+	if sub := readIssue(targetKey, targetKey+"-3"); sub.ParentID != targetKey+"-2" {
+		t.Errorf("Subtask ParentID = %q, want %q", sub.ParentID, targetKey+"-2")
+	}
+	if stays := readIssue(projectKey, projectKey+"-1"); !slices.Equal(stays.BlockedBy, []string{targetKey + "-2"}) {
+		t.Errorf("Source dependency not rewritten: %v", stays.BlockedBy)
+	}
+	if existing := readIssue(targetKey, targetKey+"-1"); !slices.Equal(existing.BlockedBy, []string{targetKey + "-2"}) {
+		t.Errorf("Target dependency not rewritten: %v", existing.BlockedBy)
+	}
+
+	// Indexes
+	var index, targetIndex models.ProjectIndex
+	indexPath, _ := storage.ProjectIndexPath(t.Context(), projectKey)
+	targetIndexPath, _ := storage.ProjectIndexPath(t.Context(), targetKey)
+	storage.ReadIndex(t.Context(), indexPath, &index)
+	storage.ReadIndex(t.Context(), targetIndexPath, &targetIndex)
+	if len(index.Issues) != 1 || len(targetIndex.Issues) != 3 {
+		t.Errorf("Expected 1 and 3 index entries, got %d and %d", len(index.Issues), len(targetIndex.Issues))
+	}
+
+	// Old ID resolves, and is not reused
+	if newID, ok := resolveRedirect(t.Context(), projectKey+"-2"); !ok || newID != targetKey+"-2" {
+		t.Errorf("resolveRedirect() = %q, %v", newID, ok)
+	}
+	if _, err := run("issue", "create", "--project", projectKey, "--title", "Next"); err != nil {
+		t.Fatalf("issue create failed: %v", err)
+	}
+	storage.ReadJSON(t.Context(), indexPath, &index)
+	if index.FindIssue(projectKey+"-4") == nil {
+		t.Errorf("Expected new issue %s-4 after moved IDs, got %+v", projectKey, index.Issues)
+	}
+
+	for _, key := range []string{projectKey, targetKey} {
+		if hasPending, _, _ := storage.CheckPendingTransaction(t.Context(), key); hasPending {
+			t.Errorf("Project %s should not have a pending transaction", key)
+		}
+	}
+}
+
+func TestMoveIssue_Errors(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	targetKey := projectKey + "-OTHER"
+	defer func() {
+		for _, key := range []string{projectKey, targetKey} {
+			projectDir, _ := storage.ProjectDir(t.Context(), key)
+			os.RemoveAll(projectDir)
+		}
+	}()
+
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"project", "create", targetKey},
+		{"project", "workflow", targetKey, "--statuses", "OPEN,CLOSED"},
+		{"issue", "create", "--project", projectKey, "--title", "Task"},
+	} {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		cmd.SetOut(new(bytes.Buffer))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		issueID string
+		target  string
+		errMsg  string
+	}{
+		{"same project", projectKey + "-1", projectKey, "already in project"},
+		{"missing target", projectKey + "-1", projectKey + "-MISSING", "does not exist"},
+		{"missing issue", projectKey + "-9", targetKey, "not found"},
+		{"workflow mismatch", projectKey + "-1", targetKey, "does not fit the workflow"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewIssueMoveCmd()
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(new(bytes.Buffer))
+			err := moveIssue(tt.issueID, tt.target, cmd)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+
+	// Nothing moved after the failures
+	path, _ := storage.IssuePath(t.Context(), projectKey, projectKey+"-1")
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Issue should still exist in the source project: %v", err)
+	}
+}
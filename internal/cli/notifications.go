@@ -0,0 +1,372 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// Notification kinds.
+const (
+	notifyAssigned  = "assigned"
+	notifyMention   = "mention"
+	notifyUnblocked = "unblocked"
+)
+
+// NotificationList is the notifications of a user in a project.
+type NotificationList struct {
+	User          string         `json:"user"`
+	Project       string         `json:"project"`
+	Unread        int            `json:"unread"`
+	Notifications []Notification `json:"notifications"`
+}
+
+// Notification is something in a project a user should look at. It is derived
+// from the issues each time, so its ID stays the same between runs.
+type Notification struct {
+	ID      string `json:"id"`
+	Kind    string `json:"kind"`
+	IssueID string `json:"issue_id"`
+	Title   string `json:"title"`
+	Status  string `json:"status"`
+	Detail  string `json:"detail"`
+	Read    bool   `json:"read"`
+}
+
+// NewNotificationsCmd creates and returns the notifications command.
+func NewNotificationsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "notifications",
+		Short: "Show what needs a user's attention",
+		Long: `Show a user's notifications in a project: open issues assigned to them,
+@mentions in issue descriptions and comments, and issues of theirs whose
+blockers are all done.
+
+Which notifications were read is kept per user and shared by all projects,
+so unread ones are still there in the next session.`,
+	}
+
+	cmd.AddCommand(NewNotificationsListCmd())
+	cmd.AddCommand(NewNotificationsReadCmd())
+
+	return cmd
+}
+
+// NewNotificationsListCmd creates and returns the notifications list command.
+func NewNotificationsListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List a user's unread notifications",
+		Long: `List a user's unread notifications in a project. With --watch the list is
+kept on screen and refreshed whenever the project changes.`,
+		Example: `  buyruk notifications list --user alice
+  buyruk notifications list --user alice --project CORE --all
+  buyruk notifications list --user alice --watch`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listNotifications(cmd)
+		},
+	}
+
+	cmd.Flags().String("user", "", "User to show notifications for (required)")
+	cmd.Flags().Bool("all", false, "Include notifications that were already read")
+	cmd.Flags().BoolP("watch", "w", false, "Keep the list on screen and refresh it when the project changes")
+	cmd.MarkFlagRequired("user")
+
+	return cmd
+}
+
+// NewNotificationsReadCmd creates and returns the notifications read command.
+func NewNotificationsReadCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "read [notification-id...]",
+		Short: "Mark notifications as read",
+		Long: `Mark notifications as read, by the IDs shown by notifications list, or all
+current notifications of the project with --all.`,
+		Example: `  buyruk notifications read assigned:CORE-12 --user alice
+  buyruk notifications read --all --user alice --project CORE`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return readNotifications(cmd, args)
+		},
+	}
+
+	cmd.Flags().String("user", "", "User whose notifications to mark (required)")
+	cmd.Flags().Bool("all", false, "Mark all current notifications of the project as read")
+	cmd.MarkFlagRequired("user")
+
+	return cmd
+}
+
+// listNotifications prints the notifications of a user, once or on every change.
+func listNotifications(cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	user, _ := cmd.Flags().GetString("user")
+	if err := models.ValidateUser(user); err != nil {
+		return invalidf("cli: %w", err)
+	}
+	all, _ := cmd.Flags().GetBool("all")
+
+	render := func(w io.Writer) error {
+		list, err := buildNotifications(cmd, projectKey, user)
+		if err != nil {
+			return err
+		}
+		if !all {
+			unread := []Notification{}
+			for _, n := range list.Notifications {
+				if !n.Read {
+					unread = append(unread, n)
+				}
+			}
+			list.Notifications = unread
+		}
+		return writeNotifications(cmd, w, list)
+	}
+
+	if watch, _ := cmd.Flags().GetBool("watch"); watch {
+		return watchProject(cmd, projectKey, render)
+	}
+	return render(cmd.OutOrStdout())
+}
+
+// readNotifications marks notifications of a user as read.
+func readNotifications(cmd *cobra.Command, ids []string) error {
+	user, _ := cmd.Flags().GetString("user")
+	if err := models.ValidateUser(user); err != nil {
+		return invalidf("cli: %w", err)
+	}
+	all, _ := cmd.Flags().GetBool("all")
+	if all == (len(ids) > 0) {
+		return fmt.Errorf("cli: give notification IDs or --all")
+	}
+
+	if all {
+		projectKey, err := config.ResolveProject(cmd)
+		if err != nil {
+			return err
+		}
+		list, err := buildNotifications(cmd, projectKey, user)
+		if err != nil {
+			return err
+		}
+		for _, n := range list.Notifications {
+			ids = append(ids, n.ID)
+		}
+	} else {
+		for _, id := range ids {
+			if err := validateNotificationID(id); err != nil {
+				return err
+			}
+		}
+	}
+
+	marked := 0
+	readAt := storage.Timestamp(cmd.Context())
+	if err := updateNotificationState(cmd.Context(), user, func(state *models.NotificationState) error {
+		for _, id := range ids {
+			if state.MarkRead(id, readAt) {
+				marked++
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(successOut(cmd), "Marked %d notifications as read for %s\n", marked, user)
+	return nil
+}
+
+// buildNotifications derives the notifications of a user from the open issues
+// of a project and marks the ones the user has read.
+func buildNotifications(cmd *cobra.Command, projectKey, user string) (*NotificationList, error) {
+	index, err := loadQueryIndex(cmd.Context(), projectKey)
+	if err != nil {
+		return nil, err
+	}
+	wf, err := loadWorkflow(cmd.Context(), projectKey)
+	if err != nil {
+		return nil, err
+	}
+	state, err := loadNotificationState(cmd.Context(), user)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &NotificationList{User: user, Project: projectKey, Notifications: []Notification{}}
+	mention := mentionPattern(user)
+	lookup := newBlockerLookup(projectKey, wf)
+	errOut := cmd.ErrOrStderr()
+	for _, entry := range index.Issues {
+		if wf.IsDoneStatus(entry.Status) {
+			continue
+		}
+		issue, err := loadLocalIssue(cmd.Context(), projectKey, entry.ID)
+		if err != nil || issue == nil {
+			fmt.Fprintf(errOut, "Warning: failed to load issue %s\n", entry.ID)
+			continue
+		}
+
+		add := func(kind, suffix, detail string) {
+			n := Notification{
+				ID:      kind + ":" + issue.ID + suffix,
+				Kind:    kind,
+				IssueID: issue.ID,
+				Title:   issue.Title,
+				Status:  issue.Status,
+				Detail:  detail,
+			}
+			n.Read = state.IsRead(n.ID)
+			if !n.Read {
+				list.Unread++
+			}
+			list.Notifications = append(list.Notifications, n)
+		}
+
+		if issue.Assignee == user {
+			add(notifyAssigned, "", "Assigned to you")
+		}
+		if mention.MatchString(issue.Description) {
+			add(notifyMention, "", "Mentioned in the description")
+		}
+		for i, comment := range issue.Comments {
+			if mention.MatchString(comment.Body) {
+				add(notifyMention, "#"+strconv.Itoa(i+1), fmt.Sprintf("Mentioned in comment %d", i+1))
+			}
+		}
+		if issue.Assignee == user && len(issue.BlockedBy) > 0 {
+			unblocked := true
+			for _, id := range issue.BlockedBy {
+				if blocker, ok := lookup.find(cmd.Context(), id); ok && !blocker.done {
+					unblocked = false
+					break
+				}
+			}
+			if unblocked {
+				add(notifyUnblocked, "", "All blockers are done")
+			}
+		}
+	}
+	return list, nil
+}
+
+// mentionPattern matches @user as a whole word.
+func mentionPattern(user string) *regexp.Regexp {
+	return regexp.MustCompile(`(^|[^\w@])@` + regexp.QuoteMeta(user) + `($|[^\w-])`)
+}
+
+// validateNotificationID checks that an ID has the form kind:ISSUE-ID, with
+// an optional #comment suffix.
+func validateNotificationID(id string) error {
+	kind, rest, ok := strings.Cut(id, ":")
+	issueID, _, _ := strings.Cut(rest, "#")
+	if ok {
+		switch kind {
+		case notifyAssigned, notifyMention, notifyUnblocked:
+			if _, _, err := models.ParseIssueID(issueID); err == nil {
+				return nil
+			}
+		}
+	}
+	return invalidf("cli: invalid notification ID %q (expected e.g. assigned:CORE-12)", id)
+}
+
+// loadNotificationState loads the read state of a user. Returns an empty
+// state if none exists.
+func loadNotificationState(ctx context.Context, user string) (*models.NotificationState, error) {
+	statePath, err := storage.NotificationsPath(user)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve notifications path: %w", err)
+	}
+	state := &models.NotificationState{User: user}
+	if err := storage.ReadJSON(ctx, statePath, state); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("cli: failed to load notifications of %s: %w", user, err)
+	}
+	return state, nil
+}
+
+// updateNotificationState applies a change to the read state of a user and
+// saves it with an atomic write (like the away list, it is not tied to a
+// project lock).
+func updateNotificationState(ctx context.Context, user string, update func(state *models.NotificationState) error) error {
+	state, err := loadNotificationState(ctx, user)
+	if err != nil {
+		return err
+	}
+	if err := update(state); err != nil {
+		return err
+	}
+
+	statePath, err := storage.NotificationsPath(user)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve notifications path: %w", err)
+	}
+	if err := storage.EnsureDir(statePath); err != nil {
+		return fmt.Errorf("cli: failed to create notifications directory: %w", err)
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cli: failed to marshal notifications: %w", err)
+	}
+	if err := storage.WriteAtomic(ctx, statePath, data); err != nil {
+		return fmt.Errorf("cli: failed to save notifications: %w", err)
+	}
+	return nil
+}
+
+// writeNotifications prints notifications in the resolved output format.
+func writeNotifications(cmd *cobra.Command, out io.Writer, list *NotificationList) error {
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		data, err := json.MarshalIndent(list, "", "  ")
+		if err != nil {
+			return fmt.Errorf("cli: failed to marshal notifications: %w", err)
+		}
+		fmt.Fprintln(out, string(data))
+		return nil
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(out, list)
+	case config.DefaultFormatLSON:
+		for _, n := range list.Notifications {
+			fmt.Fprintf(out, "@NOTIFICATION: %s\n", n.ID)
+			fmt.Fprintf(out, "@KIND: %s\n", n.Kind)
+			fmt.Fprintf(out, "@ISSUE: %s\n", n.IssueID)
+			fmt.Fprintf(out, "@TITLE: %s\n", n.Title)
+			fmt.Fprintf(out, "@STATUS: %s\n", n.Status)
+			fmt.Fprintf(out, "@READ: %t\n", n.Read)
+			fmt.Fprintln(out)
+		}
+		return nil
+	}
+
+	if len(list.Notifications) == 0 {
+		fmt.Fprintf(out, "No unread notifications for %s in project %q\n", list.User, list.Project)
+		return nil
+	}
+	styles := ui.NewStyles()
+	fmt.Fprintf(out, "%s (%d unread)\n", styles.Title("Notifications for "+list.User), list.Unread)
+	for _, n := range list.Notifications {
+		marker := "*"
+		if n.Read {
+			marker = " "
+		}
+		fmt.Fprintf(out, "%s %s %s [%s]\n", marker, styles.ID(n.IssueID), n.Title, n.Status)
+		fmt.Fprintf(out, "    %s (%s)\n", n.Detail, n.ID)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestNotifications(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	user := strings.ToLower(projectKey) + "-alice"
+	defer func() {
+		statePath, _ := storage.NotificationsPath(user)
+		os.Remove(statePath)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	list := func(args ...string) NotificationList {
+		t.Helper()
+		args = append([]string{"notifications", "list", "--user", user, "--project", projectKey, "--format", "json"}, args...)
+		out, err := run(args...)
+		if err != nil {
+			t.Fatalf("notifications list failed: %v", err)
+		}
+		var l NotificationList
+		if err := json.Unmarshal([]byte(out), &l); err != nil {
+			t.Fatalf("Failed to parse notifications: %v\n%s", err, out)
+		}
+		return l
+	}
+	ids := func(l NotificationList) string {
+		var ids []string
+		for _, n := range l.Notifications {
+			ids = append(ids, n.ID)
+		}
+		return strings.Join(ids, ",")
+	}
+	id := func(n string) string { return projectKey + "-" + n }
+
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "Checkout", "--assignee", user},
+		{"issue", "create", "--project", projectKey, "--title", "Payment API"},
+		{"issue", "create", "--project", projectKey, "--title", "Docs", "--description", "Ask @" + user + "-bob, not @" + user + "x"},
+		{"issue", "link", id("1"), id("2")},
+		{"issue", "comment", id("2"), "@" + user + ", can you review?"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	l := list()
+	if want := "assigned:" + id("1") + ",mention:" + id("2") + "#1"; ids(l) != want || l.Unread != 2 {
+		t.Errorf("Notifications = %s (%d unread), want %s", ids(l), l.Unread, want)
+	}
+
+	if _, err := run("notifications", "read", "--user", user, "bogus"); err == nil {
+		t.Error("Expected an invalid notification ID to fail")
+	}
+	if _, err := run("notifications", "read", "--user", user, "assigned:"+id("1")); err != nil {
+		t.Fatalf("notifications read failed: %v", err)
+	}
+	if l = list(); ids(l) != "mention:"+id("2")+"#1" {
+		t.Errorf("Expected only the mention to stay unread, got %s", ids(l))
+	}
+	if l = list("--all"); len(l.Notifications) != 2 || !l.Notifications[0].Read || l.Unread != 1 {
+		t.Errorf("Expected --all to include the read notification, got %+v", l)
+	}
+
+	// Finishing the blocker unblocks issue 1
+	if _, err := run("issue", "update", id("2"), "--status", "DONE"); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if l = list(); ids(l) != "unblocked:"+id("1") {
+		t.Errorf("Expected an unblocked notification, got %s", ids(l))
+	}
+
+	out, err := run("notifications", "read", "--all", "--user", user, "--project", projectKey)
+	if err != nil || !strings.Contains(out, "Marked 1 notifications") {
+		t.Errorf("Expected one notification to be marked, got %v: %s", err, out)
+	}
+	out, _ = run("notifications", "list", "--user", user, "--project", projectKey)
+	if !strings.Contains(out, "No unread notifications") {
+		t.Errorf("Expected no unread notifications, got: %s", out)
+	}
+}
@@ -0,0 +1,576 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// Kinds of notices sent by buyruk notify.
+const (
+	noticeReminder = "reminder"
+	noticeOverdue  = "overdue"
+)
+
+// notifyCommandTimeout is how long a notify_command may run per notice.
+const notifyCommandTimeout = 30 * time.Second
+
+// DueNotice is a reminder that is due or an issue that is overdue, as sent by
+// buyruk notify.
+type DueNotice struct {
+	Kind    string `json:"kind"`
+	Project string `json:"project"`
+	IssueID string `json:"issue_id"`
+	Title   string `json:"title"`
+	At      string `json:"at"`             // When the reminder was due, or the issue's due date
+	Note    string `json:"note,omitempty"` // Note of a reminder
+	Sent    bool   `json:"sent"`
+}
+
+// key identifies a notice in the sent record, so each reminder and each due
+// date is sent once.
+func (n *DueNotice) key() string {
+	return n.Kind + ":" + n.IssueID + "@" + n.At
+}
+
+// heading is the notification title of a notice.
+func (n *DueNotice) heading() string {
+	if n.Kind == noticeOverdue {
+		return n.IssueID + " is overdue"
+	}
+	return "Reminder: " + n.IssueID
+}
+
+// message is the notification body of a notice.
+func (n *DueNotice) message() string {
+	if n.Note != "" {
+		return n.Title + "\n" + n.Note
+	}
+	if n.Kind == noticeOverdue {
+		return n.Title + "\nDue " + ui.FormatTime(n.At, config.Location())
+	}
+	return n.Title
+}
+
+// notifySent records the notices buyruk notify has sent, by key, with the
+// time each was sent.
+type notifySent struct {
+	Sent map[string]string `json:"sent"`
+}
+
+// sendDesktopNotification shows a desktop notification.
+func sendDesktopNotification(title, body string) error {
+	var c *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(body), appleScriptString(title))
+		c = exec.Command("osascript", "-e", script)
+	case "windows":
+		return errors.New("desktop notifications are not supported on Windows (set notify_command)")
+	default:
+		c = exec.Command("notify-send", "--app-name=buyruk", title, body)
+	}
+	if output, err := c.CombinedOutput(); err != nil {
+		if msg := bytes.TrimSpace(output); len(msg) > 0 {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// appleScriptString quotes s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// NewIssueRemindCmd creates and returns the issue remind command.
+func NewIssueRemindCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remind <id>",
+		Short: "Set or list reminders of an issue",
+		Long: `Set a reminder of an issue at a time, which buyruk notify sends once it is
+due. Without --at, the issue's reminders are listed. --clear removes the
+reminder at --at, or all reminders of the issue.`,
+		Example: `  buyruk issue remind CORE-12 --at 2024-06-01T09:00
+  buyruk issue remind CORE-12 --at "next friday" --note "Check the rollout"
+  buyruk issue remind CORE-12 --clear`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeIssueArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveIssueArgs(cmd, args[:1]); err != nil {
+				return err
+			}
+			at, _ := cmd.Flags().GetString("at")
+			clearReminders, _ := cmd.Flags().GetBool("clear")
+			if at == "" && !clearReminders {
+				return listReminders(args[0], cmd)
+			}
+			return remindIssue(args[0], cmd)
+		},
+	}
+
+	cmd.Flags().String("at", "", "When to be reminded (e.g. 2024-06-01T09:00, tomorrow, next friday, 3d)")
+	cmd.Flags().String("note", "", "What to be reminded of")
+	cmd.Flags().Bool("clear", false, "Remove the reminder at --at, or all reminders")
+
+	return cmd
+}
+
+// remindIssue adds a reminder to an issue, or removes reminders with --clear.
+func remindIssue(issueID string, cmd *cobra.Command) error {
+	projectKey, _, err := parseIssueID(cmd.Context(), issueID)
+	if err != nil {
+		return invalidf("cli: invalid issue ID %q: %w", issueID, err)
+	}
+
+	at := ""
+	if t, ok, err := getTimeFlag(cmd, "at"); err != nil {
+		return err
+	} else if ok {
+		at = t.UTC().Format(time.RFC3339)
+	}
+	clearReminders, _ := cmd.Flags().GetBool("clear")
+	note, _ := cmd.Flags().GetString("note")
+	if clearReminders && note != "" {
+		return invalidf("cli: --note can't be used with --clear")
+	}
+
+	issuePath, err := storage.IssuePath(cmd.Context(), projectKey, issueID)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+	}
+
+	removed := 0
+	var issue models.Issue
+	if err := storage.UpdateJSONAtomic(cmd.Context(), issuePath, &issue, func(v interface{}) error {
+		iss := v.(*models.Issue)
+
+		// Check if issue exists (ID should match if file existed)
+		if iss.ID == "" || iss.ID != issueID {
+			return notFoundf("cli: issue %q not found", issueID)
+		}
+
+		if clearReminders {
+			kept := iss.Reminders[:0]
+			for _, reminder := range iss.Reminders {
+				if at != "" && reminder.At != at {
+					kept = append(kept, reminder)
+				}
+			}
+			removed = len(iss.Reminders) - len(kept)
+			if removed == 0 {
+				return nil
+			}
+			iss.Reminders = kept
+			if len(kept) == 0 {
+				iss.Reminders = nil
+			}
+		} else {
+			iss.AddReminder(models.Reminder{At: at, Note: note, CreatedBy: currentActor(cmd.Context())})
+		}
+		iss.UpdatedAt = storage.Timestamp(cmd.Context())
+
+		return nil
+	}); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return notFoundf("cli: issue %q not found", issueID)
+		}
+		return fmt.Errorf("cli: failed to update issue: %w", err)
+	}
+
+	out := successOut(cmd)
+	if !clearReminders {
+		fmt.Fprintf(out, "Will remind of %s at %s\n", issueID, ui.FormatTime(at, config.Location()))
+		return nil
+	}
+	if removed == 0 {
+		if at != "" {
+			return notFoundf("cli: %s has no reminder at %s", issueID, ui.FormatTime(at, config.Location()))
+		}
+		fmt.Fprintf(out, "%s has no reminders\n", issueID)
+		return nil
+	}
+	fmt.Fprintf(out, "Removed %d reminder(s) from %s\n", removed, issueID)
+	return nil
+}
+
+// listReminders prints the reminders of an issue.
+func listReminders(issueID string, cmd *cobra.Command) error {
+	projectKey, _, err := parseIssueID(cmd.Context(), issueID)
+	if err != nil {
+		return invalidf("cli: invalid issue ID %q: %w", issueID, err)
+	}
+	issue, err := loadLocalIssue(cmd.Context(), projectKey, issueID)
+	if err != nil {
+		return err
+	}
+	if issue == nil {
+		return notFoundf("cli: issue %q not found", issueID)
+	}
+	reminders := issue.Reminders
+	if reminders == nil {
+		reminders = []models.Reminder{}
+	}
+
+	out := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		data, err := json.MarshalIndent(reminders, "", "  ")
+		if err != nil {
+			return fmt.Errorf("cli: failed to marshal reminders: %w", err)
+		}
+		fmt.Fprintln(out, string(data))
+		return nil
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(out, reminders)
+	case config.DefaultFormatLSON:
+		for _, reminder := range reminders {
+			fmt.Fprintf(out, "@REMIND: %s|%s\n", reminder.At, reminder.Note)
+		}
+		return nil
+	}
+
+	if len(reminders) == 0 {
+		fmt.Fprintf(out, "%s has no reminders\n", issueID)
+		return nil
+	}
+	now := storage.Now(cmd.Context())
+	for _, reminder := range reminders {
+		fmt.Fprintf(out, "%s", ui.FormatTimeRelative(reminder.At, config.Location(), now))
+		if reminder.Note != "" {
+			fmt.Fprintf(out, "  %s", reminder.Note)
+		}
+		fmt.Fprintln(out)
+	}
+	return nil
+}
+
+// NewNotifyCmd creates and returns the notify command.
+func NewNotifyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "notify",
+		Short: "Send notifications for due reminders and overdue issues",
+		Long: `Send a notification for each reminder (set with buyruk issue remind) that is
+due and each open issue past its due date, in all projects (or only
+--project). Each reminder and each due date is notified once; what was sent
+is recorded in notify_sent.json in the config directory.
+
+Notifications are shown on the desktop (notify-send on Linux, osascript on
+macOS), or passed to a shell command set with notify_command or --command.
+The command gets the notice as JSON on stdin and BUYRUK_NOTIFY_KIND
+(reminder or overdue), BUYRUK_PROJECT, BUYRUK_ISSUE_ID, BUYRUK_NOTIFY_TITLE,
+and BUYRUK_NOTIFY_BODY in the environment. A notice that fails to send is
+tried again on the next run.
+
+Meant to run periodically, e.g. from cron:
+  */10 * * * * buyruk notify --quiet`,
+		Example: `  buyruk notify
+  buyruk notify --dry-run
+  buyruk notify --command 'mail -s "$BUYRUK_NOTIFY_TITLE" me@example.com'`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return notify(cmd)
+		},
+	}
+
+	cmd.Flags().String("command", "", "Shell command to run for each notice instead of the notify_command setting")
+	cmd.Flags().Bool("dry-run", false, "List what would be sent without sending or recording it")
+
+	return cmd
+}
+
+// notify sends the notices of the selected projects that weren't sent yet. A
+// failing project or notice doesn't stop the others; the command fails after
+// all have run.
+func notify(cmd *cobra.Command) error {
+	var projectKeys []string
+	if projectKey, _ := cmd.Flags().GetString("project"); projectKey != "" {
+		projectKeys = []string{projectKey}
+	} else {
+		keys, err := storage.ListProjects(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("cli: failed to list projects: %w", err)
+		}
+		projectKeys = keys
+	}
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	command, _ := cmd.Flags().GetString("command")
+	if command == "" {
+		if cfg, err := config.Get(); err == nil {
+			command = cfg.NotifyCommand
+		}
+	}
+
+	sent, err := loadNotifySent(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	errOut := cmd.ErrOrStderr()
+	now := storage.Now(cmd.Context())
+	var notices []DueNotice
+	var failed []string
+	for _, projectKey := range projectKeys {
+		found, err := dueNotices(cmd.Context(), projectKey, now, errOut)
+		if err != nil {
+			fmt.Fprintf(errOut, "Warning: %v\n", err)
+			failed = append(failed, projectKey)
+			continue
+		}
+		notices = append(notices, found...)
+	}
+
+	// Keep only the record of notices that are still due, so a reminder set
+	// again at the same time or a due date moved back is notified again
+	checked := map[string]bool{}
+	for _, projectKey := range projectKeys {
+		checked[projectKey] = true
+	}
+	current := map[string]bool{}
+	for _, n := range notices {
+		current[n.key()] = true
+	}
+	for key := range sent.Sent {
+		if project := noticeKeyProject(cmd.Context(), key); checked[project] && !current[key] {
+			delete(sent.Sent, key)
+		}
+	}
+
+	pending := []DueNotice{}
+	sendFailures := 0
+	for _, n := range notices {
+		if _, ok := sent.Sent[n.key()]; ok {
+			continue
+		}
+		if !dryRun {
+			if err := sendNotice(cmd.Context(), &n, command); err != nil {
+				fmt.Fprintf(errOut, "Warning: failed to send notification for %s: %v\n", n.IssueID, err)
+				sendFailures++
+				continue
+			}
+			n.Sent = true
+			sent.Sent[n.key()] = storage.Timestamp(cmd.Context())
+		}
+		pending = append(pending, n)
+	}
+
+	if !dryRun {
+		if err := saveNotifySent(cmd.Context(), sent); err != nil {
+			return err
+		}
+	}
+	if err := writeNotices(cmd, pending, dryRun); err != nil {
+		return err
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("cli: failed to check %d project(s): %s", len(failed), strings.Join(failed, ", "))
+	}
+	if sendFailures > 0 {
+		return fmt.Errorf("cli: failed to send %d notification(s)", sendFailures)
+	}
+	return nil
+}
+
+// dueNotices returns the reminders of a project due by now and its open issues
+// past their due date, ordered by time.
+func dueNotices(ctx context.Context, projectKey string, now time.Time, errOut io.Writer) ([]DueNotice, error) {
+	wf, err := loadWorkflow(ctx, projectKey)
+	if err != nil {
+		return nil, err
+	}
+	files, err := storage.ReadAllIssues(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to read issues of %s: %w", projectKey, err)
+	}
+
+	notices := []DueNotice{}
+	for _, file := range files {
+		if file.Err != nil {
+			fmt.Fprintf(errOut, "Warning: failed to load issue %s\n", file.ID)
+			continue
+		}
+		issue := file.Issue
+		for _, reminder := range issue.Reminders {
+			if t, err := time.Parse(time.RFC3339, reminder.At); err == nil && !t.After(now) {
+				notices = append(notices, DueNotice{
+					Kind:    noticeReminder,
+					Project: projectKey,
+					IssueID: issue.ID,
+					Title:   issue.Title,
+					At:      reminder.At,
+					Note:    reminder.Note,
+				})
+			}
+		}
+		if issue.Due == "" || wf.IsDoneStatus(issue.Status) {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339, issue.Due); err == nil && t.Before(now) {
+			notices = append(notices, DueNotice{
+				Kind:    noticeOverdue,
+				Project: projectKey,
+				IssueID: issue.ID,
+				Title:   issue.Title,
+				At:      issue.Due,
+			})
+		}
+	}
+	sort.SliceStable(notices, func(i, j int) bool {
+		return notices[i].At < notices[j].At
+	})
+	return notices, nil
+}
+
+// noticeKeyProject returns the project key of the issue a sent record key is
+// about.
+func noticeKeyProject(ctx context.Context, key string) string {
+	_, rest, _ := strings.Cut(key, ":")
+	issueID, _, _ := strings.Cut(rest, "@")
+	projectKey, _, _ := parseIssueID(ctx, issueID)
+	return projectKey
+}
+
+// sendNotice delivers a notice with the command, or as a desktop notification
+// (of the context's hostEnv) if command is empty.
+func sendNotice(ctx context.Context, n *DueNotice, command string) error {
+	if command == "" {
+		notify := hostEnvFrom(ctx).notifyDesktop
+		if notify == nil {
+			notify = sendDesktopNotification
+		}
+		return notify(n.heading(), n.message())
+	}
+
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notice: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, notifyCommandTimeout)
+	defer cancel()
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		c = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		c = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	c.Stdin = bytes.NewReader(payload)
+	c.Env = append(os.Environ(),
+		"BUYRUK_NOTIFY_KIND="+n.Kind,
+		"BUYRUK_PROJECT="+n.Project,
+		"BUYRUK_ISSUE_ID="+n.IssueID,
+		"BUYRUK_NOTIFY_TITLE="+n.heading(),
+		"BUYRUK_NOTIFY_BODY="+n.message(),
+	)
+	if output, err := c.CombinedOutput(); err != nil {
+		if msg := bytes.TrimSpace(output); len(msg) > 0 {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// loadNotifySent loads the record of sent notices. Returns an empty record if
+// none exists.
+func loadNotifySent(ctx context.Context) (*notifySent, error) {
+	sentPath, err := storage.NotifySentPath()
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve notify record path: %w", err)
+	}
+	sent := &notifySent{}
+	if err := storage.ReadJSON(ctx, sentPath, sent); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("cli: failed to load notify record: %w", err)
+	}
+	if sent.Sent == nil {
+		sent.Sent = map[string]string{}
+	}
+	return sent, nil
+}
+
+// saveNotifySent saves the record of sent notices with an atomic write (like
+// notification read state, it is not tied to a project lock).
+func saveNotifySent(ctx context.Context, sent *notifySent) error {
+	sentPath, err := storage.NotifySentPath()
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve notify record path: %w", err)
+	}
+	if err := storage.EnsureDir(sentPath); err != nil {
+		return fmt.Errorf("cli: failed to create config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(sent, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cli: failed to marshal notify record: %w", err)
+	}
+	if err := storage.WriteAtomic(ctx, sentPath, data); err != nil {
+		return fmt.Errorf("cli: failed to save notify record: %w", err)
+	}
+	return nil
+}
+
+// writeNotices prints the notices sent (or, with dryRun, that would be sent)
+// in the resolved output format.
+func writeNotices(cmd *cobra.Command, notices []DueNotice, dryRun bool) error {
+	out := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		data, err := json.MarshalIndent(notices, "", "  ")
+		if err != nil {
+			return fmt.Errorf("cli: failed to marshal notifications: %w", err)
+		}
+		fmt.Fprintln(out, string(data))
+		return nil
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(out, notices)
+	case config.DefaultFormatLSON:
+		for _, n := range notices {
+			fmt.Fprintf(out, "@NOTICE: %s\n", n.Kind)
+			fmt.Fprintf(out, "@ISSUE: %s\n", n.IssueID)
+			fmt.Fprintf(out, "@TITLE: %s\n", n.Title)
+			fmt.Fprintf(out, "@AT: %s\n", n.At)
+			if n.Note != "" {
+				fmt.Fprintf(out, "@NOTE: %s\n", n.Note)
+			}
+			fmt.Fprintf(out, "@SENT: %t\n", n.Sent)
+			fmt.Fprintln(out)
+		}
+		return nil
+	}
+
+	out = successOut(cmd)
+	if len(notices) == 0 {
+		fmt.Fprintln(out, "Nothing to notify")
+		return nil
+	}
+	verb := "Sent"
+	if dryRun {
+		verb = "Would send"
+	}
+	styles := ui.NewStyles()
+	for _, n := range notices {
+		detail := "reminder"
+		if n.Kind == noticeOverdue {
+			detail = "overdue since " + ui.FormatTime(n.At, config.Location())
+		} else if n.Note != "" {
+			detail = "reminder: " + n.Note
+		}
+		fmt.Fprintf(out, "%s %s %s (%s)\n", verb, styles.ID(n.IssueID), n.Title, detail)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestIssueRemind(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	issueID := projectKey + "-1"
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "Rollout"},
+		{"issue", "remind", issueID, "--at", "2024-06-03T09:00", "--note", "Check the rollout"},
+		{"issue", "remind", issueID, "--at", "2024-06-01T09:00"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	out, err := run("issue", "remind", issueID, "--format", "json")
+	if err != nil {
+		t.Fatalf("Listing reminders failed: %v", err)
+	}
+	var reminders []models.Reminder
+	if err := json.Unmarshal([]byte(out), &reminders); err != nil {
+		t.Fatalf("Failed to parse reminders: %v\n%s", err, out)
+	}
+	if len(reminders) != 2 || !strings.HasPrefix(reminders[0].At, "2024-06-01") || reminders[1].Note != "Check the rollout" {
+		t.Fatalf("Expected two reminders ordered by time, got %+v", reminders)
+	}
+
+	if _, err := run("issue", "remind", issueID, "--clear", "--at", "2024-06-05T09:00"); err == nil {
+		t.Error("Expected clearing a reminder that doesn't exist to fail")
+	}
+	if _, err := run("issue", "remind", issueID, "--clear", "--at", "2024-06-01T09:00"); err != nil {
+		t.Fatalf("Clearing a reminder failed: %v", err)
+	}
+	issue, err := loadLocalIssue(t.Context(), projectKey, issueID)
+	if err != nil {
+		t.Fatalf("Failed to load issue: %v", err)
+	}
+	if len(issue.Reminders) != 1 || issue.Reminders[0].Note != "Check the rollout" {
+		t.Errorf("Expected only the reminder at the other time to be kept, got %+v", issue.Reminders)
+	}
+
+	if _, err := run("issue", "remind", issueID, "--clear"); err != nil {
+		t.Fatalf("Clearing all reminders failed: %v", err)
+	}
+	if issue, _ = loadLocalIssue(t.Context(), projectKey, issueID); len(issue.Reminders) != 0 {
+		t.Errorf("Expected no reminders, got %+v", issue.Reminders)
+	}
+}
+
+func TestNotify(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		sentPath, _ := storage.NotifySentPath()
+		os.Remove(sentPath)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	// Commands run with this context record their desktop notifications
+	var shown []string
+	ctx := withHostEnv(t.Context(), hostEnv{notifyDesktop: func(title, body string) error {
+		shown = append(shown, title)
+		return nil
+	}})
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.ExecuteContext(ctx)
+		return out.String(), err
+	}
+	id := func(n string) string { return projectKey + "-" + n }
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "Late", "--due", "2024-06-01"},
+		{"issue", "create", "--project", projectKey, "--title", "Shipped", "--due", "2024-06-01", "--status", "DONE"},
+		{"issue", "create", "--project", projectKey, "--title", "Later", "--due", "2024-07-01"},
+		{"issue", "remind", id("3"), "--at", "2024-06-10T09:00", "--note", "Start on it"},
+		{"issue", "remind", id("3"), "--at", "2024-06-20T09:00"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	notify := func(args ...string) []DueNotice {
+		t.Helper()
+		args = append([]string{"notify", "--project", projectKey, "--format", "json", "--fixed-time", "2024-06-15T12:00:00Z"}, args...)
+		out, err := run(args...)
+		if err != nil {
+			t.Fatalf("notify failed: %v", err)
+		}
+		var notices []DueNotice
+		if err := json.Unmarshal([]byte(out), &notices); err != nil {
+			t.Fatalf("Failed to parse notices: %v\n%s", err, out)
+		}
+		return notices
+	}
+
+	if notices := notify("--dry-run"); len(notices) != 2 || notices[0].Sent || len(shown) != 0 {
+		t.Fatalf("Expected a dry run to list two notices without sending them, got %+v (shown %v)", notices, shown)
+	}
+
+	notices := notify()
+	if len(notices) != 2 {
+		t.Fatalf("Expected the overdue issue and the due reminder, got %+v", notices)
+	}
+	if notices[0].Kind != noticeOverdue || notices[0].IssueID != id("1") {
+		t.Errorf("Expected %s to be overdue first, got %+v", id("1"), notices[0])
+	}
+	if notices[1].Kind != noticeReminder || notices[1].IssueID != id("3") || notices[1].Note != "Start on it" || !notices[1].Sent {
+		t.Errorf("Expected the due reminder of %s, got %+v", id("3"), notices[1])
+	}
+	if len(shown) != 2 {
+		t.Errorf("Expected two desktop notifications, got %v", shown)
+	}
+
+	if notices := notify(); len(notices) != 0 {
+		t.Errorf("Expected sent notices not to be sent again, got %+v", notices)
+	}
+
+	// A command replaces desktop notifications
+	logPath := filepath.Join(t.TempDir(), "notify.log")
+	if _, err := run("issue", "update", id("1"), "--due", "2024-06-14"); err != nil {
+		t.Fatalf("Updating due date failed: %v", err)
+	}
+	notices = notify("--command", `echo "$BUYRUK_NOTIFY_KIND $BUYRUK_ISSUE_ID" >> `+logPath)
+	if len(notices) != 1 || notices[0].IssueID != id("1") {
+		t.Fatalf("Expected a new due date to be notified again, got %+v", notices)
+	}
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Expected the command to run: %v", err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "overdue "+id("1") {
+		t.Errorf("Expected the command to get the notice, got %q", got)
+	}
+	if len(shown) != 2 {
+		t.Errorf("Expected no desktop notification with a command, got %v", shown)
+	}
+
+	// A failing command leaves the notice to be sent next time
+	if _, err := run("issue", "update", id("1"), "--due", "2024-06-13"); err != nil {
+		t.Fatalf("Updating due date failed: %v", err)
+	}
+	if _, err := run("notify", "--project", projectKey, "--fixed-time", "2024-06-15T12:00:00Z", "--command", "exit 1"); err == nil {
+		t.Error("Expected notify to fail when the command fails")
+	}
+	if notices := notify(); len(notices) != 1 || notices[0].IssueID != id("1") {
+		t.Errorf("Expected the failed notice to be sent again, got %+v", notices)
+	}
+}
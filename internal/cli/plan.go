@@ -0,0 +1,511 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/timeparse"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// WeeklyPlan is the result of a planning session.
+type WeeklyPlan struct {
+	Project  string      `json:"project"`
+	Label    string      `json:"label"`
+	Week     string      `json:"week"` // Monday of the planned week (YYYY-MM-DD)
+	Capacity string      `json:"capacity"`
+	Planned  string      `json:"planned"` // Total estimate of the planned issues
+	Issues   []PlanIssue `json:"issues"`
+}
+
+// PlanIssue is an issue considered in a planning session.
+type PlanIssue struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Status   string `json:"status"`
+	Priority string `json:"priority,omitempty"`
+	Estimate string `json:"estimate,omitempty"`
+	Due      string `json:"due,omitempty"`
+
+	effort   time.Duration // Parsed estimate (0 if unestimated)
+	selected bool
+}
+
+// NewPlanCmd creates and returns the plan command.
+func NewPlanCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Plan a week of work",
+		Long: `Run a planning session for the coming week.
+
+Candidates are the open issues whose dependencies are all done, ordered by
+priority, then due date. A week's worth of estimated issues (--capacity, in
+working time with 8-hour days) is preselected, along with issues that already
+carry the plan label. Toggle issues by number, then press Enter to accept.
+
+The accepted plan is applied as a label (--label, "this-week" by default; use a
+name like "sprint-12" to plan a sprint): planned issues get the label and open
+issues that were dropped from the plan lose it. The plan is then printed as
+Markdown, or as JSON or YAML with --format json|yaml.
+
+With --yes, the preselected plan is accepted without prompting.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return planWeek(cmd)
+		},
+	}
+
+	cmd.Flags().String("capacity", "1w", "Working time available for the week (e.g. 1w, 3d, 30h)")
+	cmd.Flags().String("label", "this-week", "Label applied to planned issues")
+	cmd.Flags().Int("limit", 30, "Maximum number of candidates shown (0 for all)")
+	cmd.Flags().BoolP("yes", "y", false, "Accept the preselected plan without prompting")
+	cmd.Flags().Bool("dry-run", false, "Print the plan without applying the label")
+	cmd.Flags().String("output", "", "Output file path (default: stdout)")
+
+	return cmd
+}
+
+// planWeek runs a planning session and applies and prints the resulting plan.
+func planWeek(cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+
+	capacityValue, _ := cmd.Flags().GetString("capacity")
+	capacity, err := timeparse.ParseWorkDuration(capacityValue)
+	if err != nil {
+		return invalidf("cli: invalid --capacity value: %w", err)
+	}
+	label, _ := cmd.Flags().GetString("label")
+	if err := models.ValidateLabel(label); err != nil {
+		return invalidf("cli: invalid --label value: %w", err)
+	}
+
+	// Markdown is the natural output format, so the configured default format
+	// only applies when --format is given explicitly
+	format := "markdown"
+	if cmd.Flags().Changed("format") {
+		format = strings.ToLower(GetFormat(cmd))
+	}
+	switch format {
+	case "markdown", "md", config.DefaultFormatJSON, config.DefaultFormatYAML:
+	default:
+		return fmt.Errorf("cli: unsupported plan format %q (use markdown, json, or yaml)", format)
+	}
+
+	indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(cmd.Context(), indexPath, &index); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return notFoundf("cli: project %q does not exist", projectKey)
+		}
+		return fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+	wf, err := loadWorkflow(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+
+	// Open issues, and those of them that can be worked on now
+	errOut := cmd.ErrOrStderr()
+	var open []*models.Issue
+	for _, entry := range index.Issues {
+		if wf.IsDoneStatus(entry.Status) {
+			continue
+		}
+		issuePath, err := storage.IssuePath(cmd.Context(), projectKey, entry.ID)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+		}
+		var issue models.Issue
+		if err := storage.ReadJSON(cmd.Context(), issuePath, &issue); err != nil {
+			fmt.Fprintf(errOut, "Warning: failed to load issue %s: %v\n", entry.ID, err)
+			continue
+		}
+		open = append(open, &issue)
+	}
+
+	candidates := planCandidates(cmd.Context(), open, &index, wf, errOut)
+	if limit, _ := cmd.Flags().GetInt("limit"); limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	preselectPlan(candidates, open, label, capacity)
+
+	if assumeYes, _ := cmd.Flags().GetBool("yes"); !assumeYes {
+		if !runPlanSession(candidates, capacity, capacityValue, cmd.InOrStdin(), errOut) {
+			fmt.Fprintln(errOut, "Planning cancelled")
+			return nil
+		}
+	}
+
+	var planned time.Duration
+	plan := WeeklyPlan{
+		Project:  projectKey,
+		Label:    label,
+		Week:     startOfWeek(storage.Now(cmd.Context()).In(config.Location())).Format("2006-01-02"),
+		Capacity: capacityValue,
+		Issues:   []PlanIssue{},
+	}
+	selected := map[string]bool{}
+	for _, c := range candidates {
+		if c.selected {
+			plan.Issues = append(plan.Issues, *c)
+			planned += c.effort
+			selected[c.ID] = true
+		}
+	}
+	plan.Planned = ui.FormatWorkDuration(planned)
+	if planned > capacity {
+		fmt.Fprintf(errOut, "Warning: the plan exceeds the capacity of %s by %s\n", capacityValue, ui.FormatWorkDuration(planned-capacity))
+	}
+
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); !dryRun {
+		added, removed, err := applyPlanLabel(cmd.Context(), projectKey, label, selected, open)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(errOut, "Labeled %d issues %q (%d added, %d removed)\n", len(plan.Issues), label, added, removed)
+	}
+
+	var sb strings.Builder
+	switch format {
+	case config.DefaultFormatJSON:
+		data, err := json.MarshalIndent(&plan, "", "  ")
+		if err != nil {
+			return fmt.Errorf("cli: failed to marshal plan: %w", err)
+		}
+		sb.Write(data)
+		sb.WriteString("\n")
+	case config.DefaultFormatYAML:
+		if err := ui.EncodeYAML(&sb, &plan); err != nil {
+			return fmt.Errorf("cli: failed to encode plan: %w", err)
+		}
+	default:
+		writePlanMarkdown(&sb, &plan)
+	}
+
+	outputPath, _ := cmd.Flags().GetString("output")
+	if outputPath == "" {
+		_, err := io.WriteString(cmd.OutOrStdout(), sb.String())
+		return err
+	}
+	if err := storage.WriteAtomic(cmd.Context(), outputPath, []byte(sb.String())); err != nil {
+		return fmt.Errorf("cli: failed to write plan: %w", err)
+	}
+	fmt.Fprintf(successOut(cmd), "Wrote plan for project %q to %s\n", projectKey, outputPath)
+	return nil
+}
+
+// planCandidates returns the open issues whose dependencies are all done,
+// highest priority first, then earliest due date, then oldest.
+func planCandidates(ctx context.Context, open []*models.Issue, index *models.ProjectIndex, wf *models.Workflow, errOut io.Writer) []*PlanIssue {
+	var candidates []*PlanIssue
+	for _, issue := range open {
+		if hasOpenDependency(ctx, issue, index, wf) {
+			continue
+		}
+		c := &PlanIssue{
+			ID:       issue.ID,
+			Title:    issue.Title,
+			Status:   issue.Status,
+			Priority: issue.Priority,
+			Estimate: issue.Estimate,
+			Due:      issue.Due,
+		}
+		if c.Estimate != "" {
+			effort, err := timeparse.ParseWorkDuration(c.Estimate)
+			if err != nil {
+				fmt.Fprintf(errOut, "Warning: ignoring invalid estimate %q of %s\n", c.Estimate, c.ID)
+				c.Estimate = ""
+			}
+			c.effort = effort
+		}
+		candidates = append(candidates, c)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if ra, rb := wf.PriorityRank(a.Priority), wf.PriorityRank(b.Priority); ra != rb {
+			return ra > rb
+		}
+		if a.Due != b.Due {
+			return b.Due == "" || (a.Due != "" && a.Due < b.Due)
+		}
+		_, seqA, _ := models.ParseIssueID(a.ID)
+		_, seqB, _ := models.ParseIssueID(b.ID)
+		return seqA < seqB
+	})
+	return candidates
+}
+
+// hasOpenDependency reports whether an issue is blocked by an issue that is not done.
+// Dependencies in other projects are checked against their own workflow; missing
+// dependencies do not block.
+func hasOpenDependency(ctx context.Context, issue *models.Issue, index *models.ProjectIndex, wf *models.Workflow) bool {
+	for _, dep := range issue.BlockedBy {
+		if entry := index.FindIssue(dep); entry != nil {
+			if !wf.IsDoneStatus(entry.Status) {
+				return true
+			}
+			continue
+		}
+		depKey, _, err := parseIssueID(ctx, dep)
+		if err != nil || depKey == index.ProjectKey {
+			continue
+		}
+		depPath, err := storage.IssuePath(ctx, depKey, dep)
+		if err != nil {
+			continue
+		}
+		var depIssue models.Issue
+		if err := storage.ReadJSON(ctx, depPath, &depIssue); err != nil {
+			continue
+		}
+		depWorkflow, err := loadWorkflow(ctx, depKey)
+		if err != nil || !depWorkflow.IsDoneStatus(depIssue.Status) {
+			return true
+		}
+	}
+	return false
+}
+
+// preselectPlan selects candidates that already carry the label, then fills the
+// remaining capacity with estimated candidates in order.
+func preselectPlan(candidates []*PlanIssue, open []*models.Issue, label string, capacity time.Duration) {
+	labeled := map[string]bool{}
+	for _, issue := range open {
+		if slices.Contains(issue.Labels, label) {
+			labeled[issue.ID] = true
+		}
+	}
+
+	var planned time.Duration
+	for _, c := range candidates {
+		if labeled[c.ID] {
+			c.selected = true
+			planned += c.effort
+		}
+	}
+	for _, c := range candidates {
+		if !c.selected && c.effort > 0 && planned+c.effort <= capacity {
+			c.selected = true
+			planned += c.effort
+		}
+	}
+}
+
+// runPlanSession shows the candidates and lets the user toggle them until the
+// plan is accepted. Returns false if the session was cancelled.
+func runPlanSession(candidates []*PlanIssue, capacity time.Duration, capacityValue string, in io.Reader, w io.Writer) bool {
+	if len(candidates) == 0 {
+		fmt.Fprintln(w, "No unblocked open issues to plan")
+		return true
+	}
+
+	styles := ui.NewStyles()
+	scanner := bufio.NewScanner(in)
+	for {
+		var planned time.Duration
+		for i, c := range candidates {
+			mark := "[ ]"
+			if c.selected {
+				mark = "[x]"
+				planned += c.effort
+			}
+			estimate := c.Estimate
+			if estimate == "" {
+				estimate = "?"
+			}
+			line := fmt.Sprintf("%s %2d. %s %s (%s", mark, i+1, styles.ID(c.ID), c.Title, estimate)
+			if c.Priority != "" {
+				line += ", " + styles.PriorityColor(c.Priority)(c.Priority)
+			}
+			if c.Due != "" {
+				line += ", due " + ui.FormatTime(c.Due, config.Location())
+			}
+			fmt.Fprintln(w, line+")")
+		}
+		summary := fmt.Sprintf("Planned %s of %s", ui.FormatWorkDuration(planned), capacityValue)
+		if planned > capacity {
+			summary = styles.Error(summary + " (over capacity)")
+		}
+		fmt.Fprintln(w, summary)
+		fmt.Fprint(w, "Toggle issues by number (e.g. \"2 4-6\"), Enter to accept, q to quit: ")
+
+		if !scanner.Scan() {
+			fmt.Fprintln(w)
+			return true
+		}
+		answer := strings.TrimSpace(scanner.Text())
+		switch strings.ToLower(answer) {
+		case "":
+			return true
+		case "q":
+			return false
+		}
+		numbers, err := parsePlanSelection(answer, len(candidates))
+		if err != nil {
+			fmt.Fprintf(w, "Error: %v\n", err)
+			continue
+		}
+		for _, n := range numbers {
+			candidates[n-1].selected = !candidates[n-1].selected
+		}
+	}
+}
+
+// parsePlanSelection parses numbers and ranges such as "2 4-6" or "1,3".
+func parsePlanSelection(answer string, max int) ([]int, error) {
+	var numbers []int
+	for _, field := range strings.FieldsFunc(answer, func(r rune) bool { return r == ' ' || r == ',' }) {
+		from, to, isRange := strings.Cut(field, "-")
+		first, err := strconv.Atoi(from)
+		if err != nil {
+			return nil, invalidf("cli: invalid selection %q", field)
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(to); err != nil || last < first {
+				return nil, invalidf("cli: invalid selection %q", field)
+			}
+		}
+		if first < 1 || last > max {
+			return nil, fmt.Errorf("cli: selection %q is out of range (1-%d)", field, max)
+		}
+		for n := first; n <= last; n++ {
+			numbers = append(numbers, n)
+		}
+	}
+	return numbers, nil
+}
+
+// applyPlanLabel adds the label to the selected issues and removes it from the
+// other open issues, updating issue files and the index together under the lock.
+func applyPlanLabel(ctx context.Context, projectKey, label string, selected map[string]bool, open []*models.Issue) (added, removed int, err error) {
+	var changed []string
+	for _, issue := range open {
+		if selected[issue.ID] != slices.Contains(issue.Labels, label) {
+			changed = append(changed, issue.ID)
+		}
+	}
+	if len(changed) == 0 {
+		return 0, 0, nil
+	}
+
+	cleanup, err := storage.AcquireLock(ctx, projectKey)
+	if err != nil {
+		return 0, 0, fmt.Errorf("cli: failed to acquire lock: %w", err)
+	}
+	defer cleanup()
+
+	indexPath, err := storage.ProjectIndexPath(ctx, projectKey)
+	if err != nil {
+		return 0, 0, fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(ctx, indexPath, &index); err != nil {
+		return 0, 0, fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+
+	now := storage.Timestamp(ctx)
+	var writes []fileWrite
+	for _, id := range changed {
+		issuePath, err := storage.IssuePath(ctx, projectKey, id)
+		if err != nil {
+			return 0, 0, fmt.Errorf("cli: failed to resolve issue path: %w", err)
+		}
+		// Re-read under the lock so concurrent edits are kept
+		original, err := os.ReadFile(issuePath)
+		if err != nil {
+			return 0, 0, fmt.Errorf("cli: failed to read issue %s: %w", id, err)
+		}
+		var issue models.Issue
+		if err := json.Unmarshal(original, &issue); err != nil {
+			return 0, 0, fmt.Errorf("cli: failed to parse issue %s: %w", id, err)
+		}
+		if selected[id] {
+			issue.AddLabel(label)
+			added++
+		} else {
+			issue.RemoveLabel(label)
+			if len(issue.Labels) == 0 {
+				issue.Labels = nil
+			}
+			removed++
+		}
+		issue.UpdatedAt = now
+		data, err := json.MarshalIndent(&issue, "", "  ")
+		if err != nil {
+			return 0, 0, fmt.Errorf("cli: failed to marshal issue %s: %w", id, err)
+		}
+		writes = append(writes, fileWrite{path: issuePath, data: data, original: original})
+		index.AddIssue(&issue)
+	}
+	index.UpdatedAt = now
+	indexWrite, err := plannedIndexWrite(indexPath, &index)
+	if err != nil {
+		return 0, 0, err
+	}
+	writes = append(writes, indexWrite)
+
+	if err := storage.BeginTransaction(ctx, projectKey, "plan", map[string]interface{}{
+		"label": label,
+	}); err != nil {
+		return 0, 0, fmt.Errorf("cli: failed to begin transaction: %w", err)
+	}
+	if err := writeFiles(ctx, writes); err != nil {
+		storage.RollbackTransaction(ctx, projectKey)
+		return 0, 0, err
+	}
+	if err := storage.CommitTransaction(ctx, projectKey); err != nil {
+		return 0, 0, fmt.Errorf("cli: failed to commit transaction: %w", err)
+	}
+	return added, removed, nil
+}
+
+// startOfWeek returns the Monday of the week of t, at midnight.
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	y, m, d := t.AddDate(0, 0, -offset).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// writePlanMarkdown writes the plan as a Markdown document.
+func writePlanMarkdown(w io.Writer, plan *WeeklyPlan) {
+	fmt.Fprintf(w, "# Plan: %s (%s)\n\n", plan.Label, plan.Project)
+	fmt.Fprintf(w, "Week of %s: %d issues, %s planned of %s capacity.\n\n", plan.Week, len(plan.Issues), plan.Planned, plan.Capacity)
+	fmt.Fprintf(w, "| ID | Title | Priority | Estimate | Due |\n")
+	fmt.Fprintf(w, "| :--- | :--- | :--- | :--- | :--- |\n")
+	for _, issue := range plan.Issues {
+		title := strings.ReplaceAll(issue.Title, "|", "\\|")
+		priority, estimate, due := issue.Priority, issue.Estimate, "-"
+		if priority == "" {
+			priority = "-"
+		}
+		if estimate == "" {
+			estimate = "-"
+		}
+		if t, err := time.Parse(time.RFC3339, issue.Due); err == nil {
+			due = t.In(config.Location()).Format("2006-01-02")
+		}
+		fmt.Fprintf(w, "| %s | %s | %s | %s | %s |\n", issue.ID, title, priority, estimate, due)
+	}
+}
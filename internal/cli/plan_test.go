@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestParsePlanSelection(t *testing.T) {
+	got, err := parsePlanSelection("2 4-6,1", 6)
+	if err != nil {
+		t.Fatalf("parsePlanSelection() failed: %v", err)
+	}
+	if want := []int{2, 4, 5, 6, 1}; !slices.Equal(got, want) {
+		t.Errorf("parsePlanSelection() = %v, want %v", got, want)
+	}
+
+	for _, answer := range []string{"0", "7", "x", "3-2", "1-9"} {
+		if _, err := parsePlanSelection(answer, 6); err == nil {
+			t.Errorf("parsePlanSelection(%q) should fail", answer)
+		}
+	}
+}
+
+func TestStartOfWeek(t *testing.T) {
+	monday := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	for _, day := range []time.Time{monday, monday.Add(50 * time.Hour), monday.AddDate(0, 0, 6).Add(23 * time.Hour)} {
+		if got := startOfWeek(day); !got.Equal(monday) {
+			t.Errorf("startOfWeek(%v) = %v, want %v", day, got, monday)
+		}
+	}
+}
+
+func TestPlan(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(input string, args ...string) (string, string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(errOut)
+		cmd.SetIn(strings.NewReader(input))
+		err := cmd.Execute()
+		return out.String(), errOut.String(), err
+	}
+	labels := func(id string) []string {
+		t.Helper()
+		path, _ := storage.IssuePath(t.Context(), projectKey, id)
+		var issue models.Issue
+		if err := storage.ReadJSON(t.Context(), path, &issue); err != nil {
+			t.Fatalf("Failed to read issue %s: %v", id, err)
+		}
+		return issue.Labels
+	}
+	id := func(n int) string { return fmt.Sprintf("%s-%d", projectKey, n) }
+
+	if _, _, err := run("", "project", "create", projectKey); err != nil {
+		t.Fatalf("project create failed: %v", err)
+	}
+	setup := [][]string{
+		{"issue", "create", "--project", projectKey, "--title", "Fix login", "--priority", "HIGH", "--estimate", "3d"},
+		{"issue", "create", "--project", projectKey, "--title", "Write docs", "--priority", "CRITICAL", "--estimate", "2d"},
+		{"issue", "create", "--project", projectKey, "--title", "Refactor", "--priority", "LOW", "--estimate", "3d"},
+		{"issue", "create", "--project", projectKey, "--title", "Spike", "--priority", "CRITICAL"},
+		{"issue", "link", id(2), id(3)},
+	}
+	for _, args := range setup {
+		if _, _, err := run("", args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	// Candidates are Spike (unestimated), Fix login, and Refactor; Write docs is blocked.
+	// Only Fix login fits the preselection.
+	out, _, err := run("", "plan", "--project", projectKey, "--yes")
+	if err != nil {
+		t.Fatalf("plan failed: %v", err)
+	}
+	if !strings.Contains(out, "| "+id(1)+" | Fix login | HIGH | 3d |") {
+		t.Errorf("Expected Fix login in the plan, got:\n%s", out)
+	}
+	for _, n := range []int{2, 3, 4} {
+		if strings.Contains(out, "| "+id(n)+" |") {
+			t.Errorf("Expected %s not to be planned, got:\n%s", id(n), out)
+		}
+	}
+	if !strings.Contains(out, "1 issues, 3d planned of 1w capacity") {
+		t.Errorf("Expected plan summary, got:\n%s", out)
+	}
+	if !slices.Contains(labels(id(1)), "this-week") {
+		t.Errorf("Expected %s to be labeled this-week, got %v", id(1), labels(id(1)))
+	}
+
+	// Toggle Fix login (2) off and Refactor (3) and Spike (1) on
+	out, errOut, err := run("2 3\n1\n\n", "plan", "--project", projectKey, "--format", "json")
+	if err != nil {
+		t.Fatalf("interactive plan failed: %v", err)
+	}
+	if !strings.Contains(errOut, "[x]  3. ") || !strings.Contains(out, `"planned": "3d"`) {
+		t.Errorf("Expected Refactor to be planned, got:\n%s\n%s", errOut, out)
+	}
+	if !strings.Contains(out, `"id": "`+id(4)+`"`) || strings.Contains(out, `"id": "`+id(1)+`"`) {
+		t.Errorf("Expected Spike but not Fix login in the plan, got:\n%s", out)
+	}
+	if slices.Contains(labels(id(1)), "this-week") {
+		t.Errorf("Expected label to be removed from %s, got %v", id(1), labels(id(1)))
+	}
+	for _, n := range []int{3, 4} {
+		if !slices.Contains(labels(id(n)), "this-week") {
+			t.Errorf("Expected %s to be labeled this-week, got %v", id(n), labels(id(n)))
+		}
+	}
+
+	// Quitting leaves labels alone, and --dry-run does not apply them
+	if _, errOut, err := run("q\n", "plan", "--project", projectKey); err != nil || !strings.Contains(errOut, "Planning cancelled") {
+		t.Errorf("Expected cancelled plan, got err=%v:\n%s", err, errOut)
+	}
+	if _, _, err := run("", "plan", "--project", projectKey, "--label", "sprint-1", "--yes", "--dry-run"); err != nil {
+		t.Fatalf("dry-run plan failed: %v", err)
+	}
+	if slices.Contains(labels(id(1)), "sprint-1") {
+		t.Errorf("Expected --dry-run not to apply labels, got %v", labels(id(1)))
+	}
+
+	// Estimates can be changed and cleared
+	if _, _, err := run("", "issue", "update", id(3), "--estimate", "none"); err != nil {
+		t.Fatalf("issue update failed: %v", err)
+	}
+	out, _, err = run("", "plan", "--project", projectKey, "--yes", "--dry-run")
+	if err != nil {
+		t.Fatalf("plan failed: %v", err)
+	}
+	if !strings.Contains(out, "| "+id(3)+" | Refactor | LOW | - |") {
+		t.Errorf("Expected Refactor to be unestimated, got:\n%s", out)
+	}
+	if _, _, err := run("", "issue", "update", id(3), "--estimate", "3x"); err == nil {
+		t.Error("Expected invalid estimate to fail")
+	}
+}
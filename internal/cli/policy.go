@@ -0,0 +1,454 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// RetentionAction is a change a retention policy makes to a project.
+type RetentionAction struct {
+	Project string `json:"project"`
+	Action  string `json:"action"` // "archive"
+	IssueID string `json:"issue_id"`
+	Title   string `json:"title"`
+	Reason  string `json:"reason"`
+}
+
+// NewPolicyCmd creates and returns the policy command.
+func NewPolicyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Manage project retention policies",
+		Long: `Manage per-project retention policies, applied by buyruk tick.
+
+A policy archives done issues that have not been updated for a while: they are
+moved out of the index into the project's archive/ directory, so lists and
+reports stay small. Archived issues can still be viewed by ID.
+
+  buyruk policy set --project CORE --archive-done-after 60d
+  buyruk policy preview --project CORE   # what the next tick would do
+  buyruk tick                            # apply the policies of all projects`,
+	}
+
+	cmd.AddCommand(NewPolicySetCmd())
+	cmd.AddCommand(NewPolicyShowCmd())
+	cmd.AddCommand(NewPolicyPreviewCmd())
+
+	return cmd
+}
+
+// NewPolicySetCmd creates and returns the policy set command.
+func NewPolicySetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set",
+		Short: "Set a project's retention policy",
+		Long: `Set the retention rules of a project. Use "off" to remove a rule.
+
+  --archive-done-after 60d   archive done issues not updated for 60 days`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setPolicy(cmd)
+		},
+	}
+
+	cmd.Flags().String("archive-done-after", "", "Archive done issues not updated for this long (e.g. 60d, 8w), or off")
+
+	return cmd
+}
+
+// NewPolicyShowCmd creates and returns the policy show command.
+func NewPolicyShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show a project's retention policy",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return showPolicy(cmd)
+		},
+	}
+	return cmd
+}
+
+// NewPolicyPreviewCmd creates and returns the policy preview command.
+func NewPolicyPreviewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "preview",
+		Short: "Show what the next tick would do",
+		Long:  "List the changes the next buyruk tick would make to a project, without making them.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectKey, err := config.ResolveProject(cmd)
+			if err != nil {
+				return err
+			}
+			policy, err := loadPolicy(cmd.Context(), projectKey)
+			if err != nil {
+				return err
+			}
+			actions, err := runRetention(cmd.Context(), projectKey, policy, storage.Now(cmd.Context()), false)
+			if err != nil {
+				return err
+			}
+			return writeRetentionActions(cmd, actions, true)
+		},
+	}
+	return cmd
+}
+
+// NewTickCmd creates and returns the tick command.
+func NewTickCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tick",
+		Short: "Apply retention policies",
+		Long: `Apply the retention policies of all projects (or only --project).
+
+Meant to run periodically, e.g. from cron:
+  0 3 * * * buyruk tick
+
+Use buyruk policy preview to see what a tick would do first.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return tick(cmd)
+		},
+	}
+	return cmd
+}
+
+// tick applies the retention policies of the selected projects. A failing
+// project doesn't stop the others; the command fails after all have run.
+func tick(cmd *cobra.Command) error {
+	var projectKeys []string
+	if projectKey, _ := cmd.Flags().GetString("project"); projectKey != "" {
+		projectKeys = []string{projectKey}
+	} else {
+		keys, err := storage.ListProjects(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("cli: failed to list projects: %w", err)
+		}
+		projectKeys = keys
+	}
+
+	errOut := cmd.ErrOrStderr()
+	var actions []RetentionAction
+	var failed []string
+	for _, projectKey := range projectKeys {
+		policy, err := loadPolicy(cmd.Context(), projectKey)
+		if err == nil && policy.IsEmpty() {
+			continue
+		}
+		var applied []RetentionAction
+		if err == nil {
+			applied, err = runRetention(cmd.Context(), projectKey, policy, storage.Now(cmd.Context()), true)
+		}
+		if err != nil {
+			fmt.Fprintf(errOut, "Warning: %s: %v\n", projectKey, err)
+			failed = append(failed, projectKey)
+			continue
+		}
+		actions = append(actions, applied...)
+	}
+
+	if err := writeRetentionActions(cmd, actions, false); err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("cli: retention policy failed for %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// setPolicy updates the retention policy of a project.
+func setPolicy(cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	if !cmd.Flags().Changed("archive-done-after") {
+		return invalidf("cli: nothing to set (use --archive-done-after)")
+	}
+	if _, err := loadPolicy(cmd.Context(), projectKey); err != nil {
+		return err
+	}
+	value, _ := cmd.Flags().GetString("archive-done-after")
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "off" || value == "none" {
+		value = ""
+	}
+
+	policyPath, err := storage.PolicyPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve policy path: %w", err)
+	}
+	var policy models.Policy
+	if err := storage.UpdateJSONAtomic(cmd.Context(), policyPath, &policy, func(v interface{}) error {
+		p := v.(*models.Policy)
+		p.ArchiveDoneAfter = value
+		return p.Validate()
+	}); err != nil {
+		return fmt.Errorf("cli: failed to update policy: %w", err)
+	}
+
+	fmt.Fprintf(successOut(cmd), "Updated retention policy of project %q\n", projectKey)
+	return nil
+}
+
+// showPolicy prints the retention policy of a project.
+func showPolicy(cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	policy, err := loadPolicy(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(policy)
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(out, policy)
+	case config.DefaultFormatLSON:
+		if policy.ArchiveDoneAfter != "" {
+			fmt.Fprintf(out, "@ARCHIVE_DONE_AFTER: %s\n", policy.ArchiveDoneAfter)
+		}
+		return nil
+	}
+
+	if policy.IsEmpty() {
+		fmt.Fprintf(out, "Project %q has no retention policy\n", projectKey)
+		return nil
+	}
+	fmt.Fprintf(out, "Archive done issues after: %s\n", policy.ArchiveDoneAfter)
+	return nil
+}
+
+// loadPolicy loads the retention policy of a project; a project without one
+// gets an empty policy.
+func loadPolicy(ctx context.Context, projectKey string) (*models.Policy, error) {
+	projectDir, err := storage.ProjectDir(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve project directory: %w", err)
+	}
+	if _, err := os.Stat(projectDir); os.IsNotExist(err) {
+		return nil, notFoundf("cli: project %q does not exist", projectKey)
+	}
+
+	policyPath, err := storage.PolicyPath(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve policy path: %w", err)
+	}
+	var policy models.Policy
+	if err := storage.ReadJSON(ctx, policyPath, &policy); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &policy, nil
+		}
+		return nil, fmt.Errorf("cli: failed to load policy: %w", err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, invalidf("cli: invalid policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// runRetention works out the actions of a project's retention policy as of
+// now and, if apply is set, carries them out. Applying plans under the
+// project lock, so the actions match the data they are applied to.
+func runRetention(ctx context.Context, projectKey string, policy *models.Policy, now time.Time, apply bool) ([]RetentionAction, error) {
+	age, ok := policy.ArchiveAge()
+	if !ok {
+		return nil, nil
+	}
+	wf, err := loadWorkflow(ctx, projectKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if apply {
+		cleanup, err := storage.AcquireLock(ctx, projectKey)
+		if err != nil {
+			return nil, fmt.Errorf("cli: failed to acquire lock: %w", err)
+		}
+		defer cleanup()
+	}
+
+	indexPath, err := storage.ProjectIndexPath(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(ctx, indexPath, &index); err != nil {
+		return nil, fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+
+	// Done issues unchanged for the policy's age; parents of open subtasks stay
+	var actions []RetentionAction
+	var writes []fileWrite
+	for _, entry := range slices.Clone(index.Issues) {
+		if !wf.IsDoneStatus(entry.Status) || hasOpenChild(&index, entry.ID, wf) {
+			continue
+		}
+		issuePath, err := storage.IssuePath(ctx, projectKey, entry.ID)
+		if err != nil {
+			return nil, fmt.Errorf("cli: failed to resolve issue path: %w", err)
+		}
+		data, err := os.ReadFile(issuePath)
+		if err != nil {
+			continue
+		}
+		var issue models.Issue
+		if err := json.Unmarshal(data, &issue); err != nil {
+			continue
+		}
+		changed := issue.UpdatedAt
+		if changed == "" {
+			changed = issue.CreatedAt
+		}
+		changedAt, err := time.Parse(time.RFC3339, changed)
+		if err != nil || now.Sub(changedAt) < age {
+			continue
+		}
+
+		actions = append(actions, RetentionAction{
+			Project: projectKey,
+			Action:  "archive",
+			IssueID: issue.ID,
+			Title:   issue.Title,
+			Reason:  fmt.Sprintf("%s, unchanged for %s", issue.Status, formatAge(now.Sub(changedAt))),
+		})
+		if !apply {
+			continue
+		}
+		archivePath, err := storage.ArchivePath(ctx, projectKey, issue.ID)
+		if err != nil {
+			return nil, fmt.Errorf("cli: failed to resolve archive path: %w", err)
+		}
+		writes = append(writes,
+			fileWrite{path: archivePath, data: data},
+			fileWrite{path: issuePath, original: data},
+		)
+		index.RemoveIssue(issue.ID)
+	}
+	if !apply || len(actions) == 0 {
+		return actions, nil
+	}
+
+	index.UpdatedAt = storage.Timestamp(ctx)
+	indexWrite, err := plannedIndexWrite(indexPath, &index)
+	if err != nil {
+		return nil, err
+	}
+	writes = append(writes, indexWrite)
+
+	ids := make([]string, len(actions))
+	for i, action := range actions {
+		ids[i] = action.IssueID
+	}
+	if err := storage.BeginTransaction(ctx, projectKey, "archive_issues", map[string]interface{}{
+		"issues": ids,
+	}); err != nil {
+		return nil, fmt.Errorf("cli: failed to begin transaction: %w", err)
+	}
+	if err := writeFiles(ctx, writes); err != nil {
+		storage.RollbackTransaction(ctx, projectKey)
+		return nil, err
+	}
+	if err := storage.CommitTransaction(ctx, projectKey); err != nil {
+		return nil, fmt.Errorf("cli: failed to commit transaction: %w", err)
+	}
+	return actions, nil
+}
+
+// hasOpenChild reports whether an issue has subtasks that are not done.
+func hasOpenChild(index *models.ProjectIndex, issueID string, wf *models.Workflow) bool {
+	for _, child := range index.Children(issueID) {
+		if !wf.IsDoneStatus(child.Status) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeRetentionActions prints retention actions, as planned ("Would archive")
+// when previewing or as done ("Archived") otherwise.
+func writeRetentionActions(cmd *cobra.Command, actions []RetentionAction, preview bool) error {
+	out := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		if actions == nil {
+			actions = []RetentionAction{}
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(actions)
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(out, actions)
+	}
+
+	if len(actions) == 0 {
+		fmt.Fprintln(out, "Nothing to do")
+		return nil
+	}
+	verb := "Archived"
+	if preview {
+		verb = "Would archive"
+	}
+	styles := ui.NewStyles()
+	for _, action := range actions {
+		fmt.Fprintf(out, "%s %s %s (%s)\n", verb, styles.ID(action.IssueID), action.Title, action.Reason)
+	}
+	return nil
+}
+
+// loadArchivedIssue loads an issue archived by a retention policy, or returns
+// false if the issue is not archived.
+func loadArchivedIssue(ctx context.Context, projectKey, issueID string) (*models.Issue, bool) {
+	archivePath, err := storage.ArchivePath(ctx, projectKey, issueID)
+	if err != nil {
+		return nil, false
+	}
+	var issue models.Issue
+	if err := storage.ReadJSON(ctx, archivePath, &issue); err != nil {
+		return nil, false
+	}
+	return &issue, true
+}
+
+// maxArchivedSequence returns the highest sequence number of the archived
+// issues of a project, so that their IDs are not reused.
+func maxArchivedSequence(ctx context.Context, projectKey string) int {
+	archiveDir, err := storage.ArchiveDir(ctx, projectKey)
+	if err != nil {
+		return 0
+	}
+	entries, err := os.ReadDir(archiveDir)
+	if err != nil {
+		return 0
+	}
+	maxSeq := 0
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		if _, seq, err := models.ParseIssueID(id); err == nil && models.IsIssueIDOf(id, projectKey) && seq > maxSeq {
+			maxSeq = seq
+		}
+	}
+	return maxSeq
+}
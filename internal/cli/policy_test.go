@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestRetentionPolicy(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(errOut)
+		err := cmd.Execute()
+		return out.String(), errOut.String(), err
+	}
+	id := func(n string) string { return projectKey + "-" + n }
+
+	const created = "2024-01-01T09:00:00Z"
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "Still open"},
+		{"issue", "create", "--project", projectKey, "--title", "Done parent", "--status", "DONE"},
+		{"issue", "create", "--project", projectKey, "--title", "Open subtask", "--parent", id("2")},
+		{"issue", "create", "--project", projectKey, "--title", "Old done", "--status", "DONE"},
+		{"issue", "create", "--project", projectKey, "--title", "Recently done", "--status", "DONE"},
+	} {
+		if _, _, err := run(append(args, "--fixed-time", created)...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+	if _, _, err := run("issue", "update", id("5"), "--title", "Recently done!", "--fixed-time", "2024-02-20T09:00:00Z"); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+
+	if out, _, _ := run("tick", "--project", projectKey); !strings.Contains(out, "Nothing to do") {
+		t.Errorf("Expected nothing to do without a policy, got: %s", out)
+	}
+	if _, _, err := run("policy", "set", "--project", projectKey, "--archive-done-after", "soon"); err == nil {
+		t.Error("Expected an invalid duration to fail")
+	}
+	if _, _, err := run("policy", "set", "--project", projectKey, "--archive-done-after", "60d"); err != nil {
+		t.Fatalf("policy set failed: %v", err)
+	}
+	if out, _, _ := run("policy", "show", "--project", projectKey); !strings.Contains(out, "60d") {
+		t.Errorf("Expected the policy to be shown, got: %s", out)
+	}
+
+	// Only the old done issue qualifies: the parent has an open subtask
+	const later = "2024-03-11T09:00:00Z"
+	out, _, err := run("policy", "preview", "--project", projectKey, "--fixed-time", later)
+	if err != nil {
+		t.Fatalf("policy preview failed: %v", err)
+	}
+	if !strings.Contains(out, "Would archive "+id("4")+" Old done (DONE, unchanged for 70d)") || strings.Count(out, "Would archive") != 1 {
+		t.Errorf("Unexpected preview: %s", out)
+	}
+	if index, _ := loadQueryIndex(t.Context(), projectKey); index.FindIssue(id("4")) == nil {
+		t.Fatal("Expected preview not to change the project")
+	}
+
+	out, _, err = run("tick", "--fixed-time", later)
+	if err != nil {
+		t.Fatalf("tick failed: %v", err)
+	}
+	if !strings.Contains(out, "Archived "+id("4")) {
+		t.Errorf("Expected %s to be archived, got: %s", id("4"), out)
+	}
+	index, _ := loadQueryIndex(t.Context(), projectKey)
+	if index.FindIssue(id("4")) != nil || len(index.Issues) != 4 {
+		t.Errorf("Expected %s to be removed from the index, got %+v", id("4"), index.Issues)
+	}
+	if issue, _ := loadLocalIssue(t.Context(), projectKey, id("4")); issue != nil {
+		t.Error("Expected the issue file to move to the archive")
+	}
+
+	// Archived issues can still be viewed, and their IDs are not reused
+	out, errOut, err := run("view", id("4"), "--format", "lson")
+	if err != nil || !strings.Contains(out, "@TITLE: Old done") || !strings.Contains(errOut, "archived") {
+		t.Errorf("Expected archived issue to be viewable, got %v: %s %s", err, out, errOut)
+	}
+	if _, _, err := run("issue", "create", "--project", projectKey, "--title", "New"); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if index, _ := loadQueryIndex(t.Context(), projectKey); index.FindIssue(id("6")) == nil {
+		t.Errorf("Expected the new issue to get %s, got %+v", id("6"), index.Issues)
+	}
+
+	if out, _, _ := run("policy", "preview", "--project", projectKey, "--fixed-time", later); !strings.Contains(out, "Nothing to do") {
+		t.Errorf("Expected nothing left to archive, got: %s", out)
+	}
+}
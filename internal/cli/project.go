@@ -2,11 +2,13 @@ package cli
 
 import (
 	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/buyruk-project/buyruk-cli/internal/config"
 	"github.com/buyruk-project/buyruk-cli/internal/models"
@@ -25,6 +27,16 @@ func NewProjectCmd() *cobra.Command {
 	cmd.AddCommand(NewProjectCreateCmd())
 	cmd.AddCommand(NewProjectRepairCmd())
 	cmd.AddCommand(NewProjectDeleteCmd())
+	cmd.AddCommand(NewProjectWorkflowCmd())
+	cmd.AddCommand(NewProjectIDModeCmd())
+	cmd.AddCommand(NewProjectCRDTCmd())
+	cmd.AddCommand(NewProjectIndexShardsCmd())
+	cmd.AddCommand(NewProjectAttachmentLimitCmd())
+	cmd.AddCommand(NewProjectSplitCmd())
+	cmd.AddCommand(NewProjectRenameCmd())
+	cmd.AddCommand(NewProjectMigrateEpicsCmd())
+	cmd.AddCommand(NewProjectLockCmd())
+	cmd.AddCommand(NewProjectUnlockCmd())
 
 	return cmd
 }
@@ -34,8 +46,17 @@ func NewProjectCreateCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "create <key>",
 		Short: "Create a new project",
-		Long:  "Create a new buyruk project with the specified key",
-		Args:  cobra.ExactArgs(1),
+		Long: `Create a new buyruk project with the specified key.
+
+--id-scheme sets how issue IDs are formed, for good: sequence (CORE-12, the
+default), padded (CORE-0012), date (CORE-2024-012, with the year the issue was
+created; the number counts on across years), or ulid (CORE- followed by a
+ULID, unique across devices without a shared counter). A key ending in a year
+(CORE-2024) is refused next to a project CORE with date-based IDs, and the
+date scheme next to such a key, as their issue IDs would read alike.`,
+		Example: `  buyruk project create CORE
+  buyruk project create OPS --id-scheme date`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			projectKey := args[0]
 			return createProject(projectKey, cmd)
@@ -43,6 +64,10 @@ func NewProjectCreateCmd() *cobra.Command {
 	}
 
 	cmd.Flags().String("name", "", "Project name (optional)")
+	cmd.Flags().String("id-mode", models.IDModeSequence, "How issues are identified: sequence, or ulid to also give each issue a uid for sync")
+	cmd.Flags().String("id-scheme", models.IDSchemeSequence, "How issue IDs are formed: sequence, padded, date, or ulid")
+	// --id-mode ulid reads like --id-scheme ulid, but only adds uids
+	cmd.Flags().MarkDeprecated("id-mode", "use --id-scheme ulid for ULID issue IDs, or `buyruk project id-mode <key> ulid` to give sequential issues a uid")
 
 	return cmd
 }
@@ -50,10 +75,11 @@ func NewProjectCreateCmd() *cobra.Command {
 // NewProjectRepairCmd creates and returns the project repair command.
 func NewProjectRepairCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "repair <key>",
-		Short: "Repair project index",
-		Long:  "Rebuild project.json index from issues directory",
-		Args:  cobra.ExactArgs(1),
+		Use:               "repair <key>",
+		Short:             "Repair project index",
+		Long:              "Rebuild project.json index from issues directory",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeProjectArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			projectKey := args[0]
 			return repairProject(projectKey, cmd)
@@ -67,7 +93,7 @@ func NewProjectRepairCmd() *cobra.Command {
 func createProject(projectKey string, cmd *cobra.Command) error {
 	// Validate project key format
 	if !isValidProjectKey(projectKey) {
-		return fmt.Errorf("cli: invalid project key %q (must contain only uppercase letters, numbers, and hyphens)", projectKey)
+		return invalidf("cli: invalid project key %q (must contain only uppercase letters, numbers, and hyphens)", projectKey)
 	}
 
 	// Get project name from flag or use key
@@ -76,23 +102,42 @@ func createProject(projectKey string, cmd *cobra.Command) error {
 		projectName = projectKey
 	}
 
+	// Get ID mode (flag is absent when called from project split)
+	idMode, _ := cmd.Flags().GetString("id-mode")
+	if idMode != "" && !models.IsValidIDMode(idMode) {
+		return invalidf("cli: invalid ID mode %q (allowed: %s)", idMode, strings.Join(models.ValidIDModes, ", "))
+	}
+	if idMode == models.IDModeSequence {
+		idMode = ""
+	}
+	idScheme, _ := cmd.Flags().GetString("id-scheme")
+	if idScheme != "" && !models.IsValidIDScheme(idScheme) {
+		return invalidf("cli: invalid ID scheme %q (allowed: %s)", idScheme, strings.Join(models.ValidIDSchemes, ", "))
+	}
+	if idScheme == models.IDSchemeSequence {
+		idScheme = ""
+	}
+	if err := checkProjectKeyScheme(cmd.Context(), projectKey, idScheme); err != nil {
+		return err
+	}
+
 	// Resolve paths
-	projectDir, err := storage.ProjectDir(projectKey)
+	projectDir, err := storage.ProjectDir(cmd.Context(), projectKey)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve project directory: %w", err)
 	}
 
-	issuesDir, err := storage.IssuesDir(projectKey)
+	issuesDir, err := storage.IssuesDir(cmd.Context(), projectKey)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve issues directory: %w", err)
 	}
 
-	epicsDir, err := storage.EpicsDir(projectKey)
+	epicsDir, err := storage.EpicsDir(cmd.Context(), projectKey)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve epics directory: %w", err)
 	}
 
-	indexPath, err := storage.ProjectIndexPath(projectKey)
+	indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve index path: %w", err)
 	}
@@ -102,14 +147,16 @@ func createProject(projectKey string, cmd *cobra.Command) error {
 	index := &models.ProjectIndex{
 		ProjectKey:  projectKey,
 		ProjectName: projectName,
+		IDMode:      idMode,
+		IDScheme:    idScheme,
 		Issues:      []models.IndexEntry{},
-		CreatedAt:   time.Now().Format(time.RFC3339),
-		UpdatedAt:   time.Now().Format(time.RFC3339),
+		CreatedAt:   storage.Timestamp(cmd.Context()),
+		UpdatedAt:   storage.Timestamp(cmd.Context()),
 	}
 
-	if err := storage.WriteJSONAtomicCreate(indexPath, index); err != nil {
+	if err := storage.WriteJSONAtomicCreate(cmd.Context(), indexPath, index); err != nil {
 		if strings.Contains(err.Error(), "already exists") {
-			return fmt.Errorf("cli: project %q already exists", projectKey)
+			return conflictf("cli: project %q already exists", projectKey)
 		}
 		return fmt.Errorf("cli: failed to create project index: %w", err)
 	}
@@ -129,23 +176,31 @@ func createProject(projectKey string, cmd *cobra.Command) error {
 	}
 
 	// Success message
-	out := cmd.OutOrStdout()
+	out := successOut(cmd)
 	fmt.Fprintf(out, "Created project %q\n", projectKey)
 
 	return nil
 }
 
 // repairProject repairs a project index by rebuilding it from the issues directory.
+// The project stays locked from reading the files to writing the index, so
+// concurrent writes cannot leave the rebuilt index stale.
 func repairProject(projectKey string, cmd *cobra.Command) error {
 	// Check if project exists
-	projectDir, err := storage.ProjectDir(projectKey)
+	projectDir, err := storage.ProjectDir(cmd.Context(), projectKey)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve project directory: %w", err)
 	}
 
 	if _, err := os.Stat(projectDir); os.IsNotExist(err) {
-		return fmt.Errorf("cli: project %q does not exist", projectKey)
+		return notFoundf("cli: project %q does not exist", projectKey)
+	}
+
+	cleanup, err := storage.AcquireLock(cmd.Context(), projectKey)
+	if err != nil {
+		return err
 	}
+	defer cleanup()
 
 	// Check for pending transaction
 	pendingPath := filepath.Join(projectDir, ".buyruk_pending")
@@ -154,81 +209,174 @@ func repairProject(projectKey string, cmd *cobra.Command) error {
 		fmt.Fprintf(out, "Warning: Found pending transaction. This may indicate a previous crash.\n")
 	}
 
-	// Read all issue files from issues directory
-	issuesDir, err := storage.IssuesDir(projectKey)
+	// Read all issue files from issues directory, several at a time
+	files, err := storage.ReadAllIssues(cmd.Context(), projectKey)
 	if err != nil {
-		return fmt.Errorf("cli: failed to resolve issues directory: %w", err)
+		return fmt.Errorf("cli: failed to read issues: %w", err)
 	}
 
-	entries, err := os.ReadDir(issuesDir)
+	// Load project workflow so custom statuses and types validate
+	wf, err := loadWorkflow(cmd.Context(), projectKey)
 	if err != nil {
-		return fmt.Errorf("cli: failed to read issues directory: %w", err)
+		return err
 	}
 
 	// Rebuild index from issue files
 	indexEntries := []models.IndexEntry{}
+	var writes []fileWrite
 
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
-			continue
-		}
-
-		issuePath := filepath.Join(issuesDir, entry.Name())
-		var issue models.Issue
-
-		if err := storage.ReadJSON(issuePath, &issue); err != nil {
+	for _, file := range files {
+		if file.Err != nil {
 			// Log error but continue
 			errOut := cmd.ErrOrStderr()
-			fmt.Fprintf(errOut, "Warning: failed to read issue file %s: %v\n", entry.Name(), err)
+			fmt.Fprintf(errOut, "Warning: failed to read issue file %s.json: %v\n", file.ID, file.Err)
 			continue
 		}
+		issue := file.Issue
 
 		// Validate issue
-		if err := issue.Validate(); err != nil {
+		if err := issue.ValidateWithWorkflow(wf); err != nil {
 			errOut := cmd.ErrOrStderr()
-			fmt.Fprintf(errOut, "Warning: invalid issue in %s: %v\n", entry.Name(), err)
+			fmt.Fprintf(errOut, "Warning: invalid issue in %s.json: %v\n", file.ID, err)
 			continue
 		}
 
+		// Migrate timestamps written in local time to UTC
+		if issue.NormalizeTimestamps() {
+			write, err := plannedJSONWrite(file.Path, issue)
+			if err != nil {
+				return err
+			}
+			writes = append(writes, write)
+		}
+
 		// Add to index
 		indexEntries = append(indexEntries, models.IndexEntry{
-			ID:     issue.ID,
-			Title:  issue.Title,
-			Status: issue.Status,
-			Type:   issue.Type,
-			EpicID: issue.EpicID,
+			ID:           issue.ID,
+			UID:          issue.UID,
+			Title:        issue.Title,
+			Status:       issue.Status,
+			Type:         issue.Type,
+			Priority:     issue.Priority,
+			EpicID:       issue.EpicID,
+			FixVersion:   issue.FixVersion,
+			ParentID:     issue.ParentID,
+			Labels:       issue.Labels,
+			Components:   issue.Components,
+			Assignee:     issue.Assignee,
+			Rank:         issue.Rank,
+			Points:       issue.Points,
+			Estimate:     issue.Estimate,
+			SnoozedUntil: issue.SnoozedUntil,
 		})
 	}
 
-	// Update index atomically (read-modify-write with locking)
-	indexPath, err := storage.ProjectIndexPath(projectKey)
+	// Load epics for the index, migrating their timestamps to UTC
+	epics, epicWrites, err := repairEpics(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	writes = append(writes, epicWrites...)
+	migrated := len(writes)
+
+	indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve index path: %w", err)
 	}
 
 	var index models.ProjectIndex
-	if err := storage.UpdateJSONAtomic(indexPath, &index, func(v interface{}) error {
-		idx := v.(*models.ProjectIndex)
-		// If index doesn't exist, initialize it
-		if idx.ProjectKey == "" {
-			idx.ProjectKey = projectKey
-			idx.Issues = []models.IndexEntry{}
+	if err := storage.ReadIndex(cmd.Context(), indexPath, &index); err != nil && !errors.Is(err, os.ErrNotExist) {
+		// The entries are rebuilt anyway; keep the settings of an index whose
+		// shards are missing or damaged
+		data, readErr := os.ReadFile(indexPath)
+		if readErr != nil || json.Unmarshal(data, &index) != nil {
+			return fmt.Errorf("cli: failed to read index: %w", err)
 		}
-		// Update with rebuilt entries
-		idx.Issues = indexEntries
-		idx.UpdatedAt = time.Now().Format(time.RFC3339)
-		return nil
+	}
+	index.Shards = nil
+	// If index doesn't exist, initialize it
+	if index.ProjectKey == "" {
+		index.ProjectKey = projectKey
+	}
+	// Update with rebuilt entries
+	index.Issues = indexEntries
+	index.Epics = nil
+	for _, epic := range epics {
+		index.SetEpic(epic)
+	}
+	index.NormalizeTimestamps()
+	index.UpdatedAt = storage.Timestamp(cmd.Context())
+	indexWrite, err := plannedIndexWrite(indexPath, &index)
+	if err != nil {
+		return err
+	}
+	writes = append(writes, indexWrite)
+
+	if err := storage.BeginTransaction(cmd.Context(), projectKey, "repair_project", map[string]interface{}{
+		"files": len(writes),
 	}); err != nil {
+		return fmt.Errorf("cli: failed to begin transaction: %w", err)
+	}
+	if err := writeFiles(cmd.Context(), writes); err != nil {
+		storage.RollbackTransaction(cmd.Context(), projectKey)
 		return fmt.Errorf("cli: failed to write repaired index: %w", err)
 	}
+	if err := storage.CommitTransaction(cmd.Context(), projectKey); err != nil {
+		return fmt.Errorf("cli: failed to commit transaction: %w", err)
+	}
 
 	// Success message
-	out := cmd.OutOrStdout()
+	out := successOut(cmd)
 	fmt.Fprintf(out, "Repaired project %q: %d issues indexed\n", projectKey, len(indexEntries))
+	if migrated > 0 {
+		fmt.Fprintf(out, "Converted timestamps to UTC: %d files\n", migrated)
+	}
 
 	return nil
 }
 
+// repairEpics loads the epics of a project for the rebuilt index, with the
+// writes that migrate epics whose timestamps are not in UTC.
+func repairEpics(ctx context.Context, projectKey string) ([]*models.Epic, []fileWrite, error) {
+	epicsDir, err := storage.EpicsDir(ctx, projectKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cli: failed to resolve epics directory: %w", err)
+	}
+
+	entries, err := os.ReadDir(epicsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("cli: failed to read epics directory: %w", err)
+	}
+
+	var epics []*models.Epic
+	var writes []fileWrite
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		epicPath := filepath.Join(epicsDir, entry.Name())
+		var epic models.Epic
+		if err := storage.ReadJSON(ctx, epicPath, &epic); err != nil {
+			continue
+		}
+
+		if epic.NormalizeTimestamps() {
+			write, err := plannedJSONWrite(epicPath, &epic)
+			if err != nil {
+				return nil, nil, err
+			}
+			writes = append(writes, write)
+		}
+		epics = append(epics, &epic)
+	}
+
+	return epics, writes, nil
+}
+
 // isValidProjectKey validates that the project key is uppercase alphanumeric or hyphen.
 func isValidProjectKey(key string) bool {
 	if len(key) == 0 {
@@ -245,10 +393,11 @@ func isValidProjectKey(key string) bool {
 // NewProjectDeleteCmd creates and returns the project delete command.
 func NewProjectDeleteCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "delete <key>",
-		Short: "Delete a project",
-		Long:  "Delete a project and all its data (issues, epics, etc.)",
-		Args:  cobra.ExactArgs(1),
+		Use:               "delete <key>",
+		Short:             "Delete a project",
+		Long:              "Delete a project and all its data (issues, epics, etc.)",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeProjectArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			projectKey := args[0]
 			return deleteProject(projectKey, cmd)
@@ -264,11 +413,11 @@ func NewProjectDeleteCmd() *cobra.Command {
 func deleteProject(projectKey string, cmd *cobra.Command) error {
 	// Validate project key format
 	if !isValidProjectKey(projectKey) {
-		return fmt.Errorf("cli: invalid project key %q (must contain only uppercase letters, numbers, and hyphens)", projectKey)
+		return invalidf("cli: invalid project key %q (must contain only uppercase letters, numbers, and hyphens)", projectKey)
 	}
 
 	// Resolve project directory
-	projectDir, err := storage.ProjectDir(projectKey)
+	projectDir, err := storage.ProjectDir(cmd.Context(), projectKey)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve project directory: %w", err)
 	}
@@ -276,13 +425,17 @@ func deleteProject(projectKey string, cmd *cobra.Command) error {
 	// Check if project exists
 	if _, err := os.Stat(projectDir); err != nil {
 		if os.IsNotExist(err) {
-			return fmt.Errorf("cli: project %q does not exist", projectKey)
+			return notFoundf("cli: project %q does not exist", projectKey)
 		}
 		return fmt.Errorf("cli: failed to access project directory %q: %w", projectDir, err)
 	}
 
+	if err := checkProjectWritable(cmd.Context(), projectKey); err != nil {
+		return err
+	}
+
 	// Check for pending transaction before acquiring lock
-	hasPending, _, err := storage.CheckPendingTransaction(projectKey)
+	hasPending, _, err := storage.CheckPendingTransaction(cmd.Context(), projectKey)
 	if err != nil {
 		return fmt.Errorf("cli: failed to check pending transaction: %w", err)
 	}
@@ -297,7 +450,7 @@ func deleteProject(projectKey string, cmd *cobra.Command) error {
 
 	// Acquire project lock to prevent concurrent modifications during deletion
 	// This ensures no other operations are running while we delete
-	cleanup, err := storage.AcquireLock(projectKey)
+	cleanup, err := storage.AcquireLock(cmd.Context(), projectKey)
 	if err != nil {
 		yes, _ := cmd.Flags().GetBool("yes")
 		if !yes {
@@ -313,15 +466,15 @@ func deleteProject(projectKey string, cmd *cobra.Command) error {
 	issueCount := 0
 	epicCount := 0
 
-	indexPath, err := storage.ProjectIndexPath(projectKey)
+	indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
 	if err == nil {
 		var index models.ProjectIndex
-		if err := storage.ReadJSON(indexPath, &index); err == nil {
+		if err := storage.ReadIndex(cmd.Context(), indexPath, &index); err == nil {
 			issueCount = len(index.Issues)
 		}
 	}
 
-	epicsDir, err := storage.EpicsDir(projectKey)
+	epicsDir, err := storage.EpicsDir(cmd.Context(), projectKey)
 	if err == nil {
 		if entries, err := os.ReadDir(epicsDir); err == nil {
 			for _, entry := range entries {
@@ -350,7 +503,7 @@ func deleteProject(projectKey string, cmd *cobra.Command) error {
 	}
 
 	// Begin transaction for project deletion
-	if err := storage.BeginTransaction(projectKey, "delete_project", map[string]interface{}{
+	if err := storage.BeginTransaction(cmd.Context(), projectKey, "delete_project", map[string]interface{}{
 		"project_key": projectKey,
 	}); err != nil {
 		return fmt.Errorf("cli: failed to begin deletion transaction: %w", err)
@@ -359,7 +512,7 @@ func deleteProject(projectKey string, cmd *cobra.Command) error {
 	success := false
 	defer func() {
 		if !success {
-			storage.RollbackTransaction(projectKey)
+			storage.RollbackTransaction(cmd.Context(), projectKey)
 		}
 	}()
 
@@ -373,11 +526,11 @@ func deleteProject(projectKey string, cmd *cobra.Command) error {
 	// Commit transaction (though the directory is already deleted, this cleans up
 	// any remaining transaction state if the deletion was partial)
 	// Since the directory is gone, this may fail, but that's okay
-	_ = storage.CommitTransaction(projectKey)
+	_ = storage.CommitTransaction(cmd.Context(), projectKey)
 	success = true
 
 	// Success message
-	out := cmd.OutOrStdout()
+	out := successOut(cmd)
 	fmt.Fprintf(out, "Deleted project %q\n", projectKey)
 
 	return nil
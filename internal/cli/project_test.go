@@ -91,7 +91,7 @@ func TestCreateProject_ValidKey(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -114,7 +114,7 @@ func TestCreateProject_ValidKey(t *testing.T) {
 	}
 
 	// Verify project structure was created
-	projectDir, err := storage.ProjectDir(projectKey)
+	projectDir, err := storage.ProjectDir(t.Context(), projectKey)
 	if err != nil {
 		t.Fatalf("Failed to resolve project directory: %v", err)
 	}
@@ -123,13 +123,13 @@ func TestCreateProject_ValidKey(t *testing.T) {
 		t.Errorf("Project directory was not created: %s", projectDir)
 	}
 
-	indexPath, err := storage.ProjectIndexPath(projectKey)
+	indexPath, err := storage.ProjectIndexPath(t.Context(), projectKey)
 	if err != nil {
 		t.Fatalf("Failed to resolve index path: %v", err)
 	}
 
 	var index models.ProjectIndex
-	if err := storage.ReadJSON(indexPath, &index); err != nil {
+	if err := storage.ReadIndex(t.Context(), indexPath, &index); err != nil {
 		t.Fatalf("Failed to read project index: %v", err)
 	}
 
@@ -146,7 +146,7 @@ func TestCreateProject_WithName(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -162,13 +162,13 @@ func TestCreateProject_WithName(t *testing.T) {
 	}
 
 	// Verify project name was set
-	indexPath, err := storage.ProjectIndexPath(projectKey)
+	indexPath, err := storage.ProjectIndexPath(t.Context(), projectKey)
 	if err != nil {
 		t.Fatalf("Failed to resolve index path: %v", err)
 	}
 
 	var index models.ProjectIndex
-	if err := storage.ReadJSON(indexPath, &index); err != nil {
+	if err := storage.ReadIndex(t.Context(), indexPath, &index); err != nil {
 		t.Fatalf("Failed to read project index: %v", err)
 	}
 
@@ -201,7 +201,7 @@ func TestCreateProject_AlreadyExists(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -234,7 +234,7 @@ func TestRepairProject_ValidProject(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -254,12 +254,12 @@ func TestRepairProject_ValidProject(t *testing.T) {
 		Status: models.StatusTODO,
 	}
 
-	issuePath, err := storage.IssuePath(projectKey, projectKey+"-1")
+	issuePath, err := storage.IssuePath(t.Context(), projectKey, projectKey+"-1")
 	if err != nil {
 		t.Fatalf("Failed to resolve issue path: %v", err)
 	}
 
-	if err := storage.WriteJSONAtomic(issuePath, issue); err != nil {
+	if err := storage.WriteJSONAtomic(t.Context(), issuePath, issue); err != nil {
 		t.Fatalf("Failed to write issue: %v", err)
 	}
 
@@ -281,13 +281,13 @@ func TestRepairProject_ValidProject(t *testing.T) {
 	}
 
 	// Verify index was updated
-	indexPath, err := storage.ProjectIndexPath(projectKey)
+	indexPath, err := storage.ProjectIndexPath(t.Context(), projectKey)
 	if err != nil {
 		t.Fatalf("Failed to resolve index path: %v", err)
 	}
 
 	var index models.ProjectIndex
-	if err := storage.ReadJSON(indexPath, &index); err != nil {
+	if err := storage.ReadIndex(t.Context(), indexPath, &index); err != nil {
 		t.Fatalf("Failed to read project index: %v", err)
 	}
 
@@ -300,6 +300,58 @@ func TestRepairProject_ValidProject(t *testing.T) {
 	}
 }
 
+func TestRepairProject_MigratesTimestamps(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	rootCmd := NewRootCmd()
+	rootCmd.SetArgs([]string{"project", "create", projectKey})
+	rootCmd.SetOut(new(bytes.Buffer))
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	// Write an issue with local-time timestamps, as older versions did
+	issue := &models.Issue{
+		ID:        projectKey + "-1",
+		Type:      models.TypeTask,
+		Title:     "Old Issue",
+		Status:    models.StatusTODO,
+		CreatedAt: "2024-06-05T15:00:00+03:00",
+		UpdatedAt: "2024-06-05T15:00:00+03:00",
+	}
+	issuePath, err := storage.IssuePath(t.Context(), projectKey, issue.ID)
+	if err != nil {
+		t.Fatalf("Failed to resolve issue path: %v", err)
+	}
+	if err := storage.WriteJSONAtomic(t.Context(), issuePath, issue); err != nil {
+		t.Fatalf("Failed to write issue: %v", err)
+	}
+
+	rootCmd2 := NewRootCmd()
+	rootCmd2.SetArgs([]string{"project", "repair", projectKey})
+	buf := new(bytes.Buffer)
+	rootCmd2.SetOut(buf)
+	if err := rootCmd2.Execute(); err != nil {
+		t.Fatalf("project repair command failed: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "Converted timestamps to UTC: 1 files") {
+		t.Errorf("Expected migration message, got: %s", buf.String())
+	}
+
+	var migrated models.Issue
+	if err := storage.ReadJSON(t.Context(), issuePath, &migrated); err != nil {
+		t.Fatalf("Failed to read issue: %v", err)
+	}
+	if migrated.CreatedAt != "2024-06-05T12:00:00Z" {
+		t.Errorf("CreatedAt = %q, want 2024-06-05T12:00:00Z", migrated.CreatedAt)
+	}
+}
+
 func TestRepairProject_MissingProject(t *testing.T) {
 	// Use a unique non-existent project key (sanitize test name)
 	projectKey := sanitizeTestName("MISSING" + t.Name())
@@ -325,7 +377,7 @@ func TestRepairProject_CorruptedIssueFiles(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -345,17 +397,17 @@ func TestRepairProject_CorruptedIssueFiles(t *testing.T) {
 		Status: models.StatusTODO,
 	}
 
-	issuePath, err := storage.IssuePath(projectKey, projectKey+"-1")
+	issuePath, err := storage.IssuePath(t.Context(), projectKey, projectKey+"-1")
 	if err != nil {
 		t.Fatalf("Failed to resolve issue path: %v", err)
 	}
 
-	if err := storage.WriteJSONAtomic(issuePath, issue); err != nil {
+	if err := storage.WriteJSONAtomic(t.Context(), issuePath, issue); err != nil {
 		t.Fatalf("Failed to write issue: %v", err)
 	}
 
 	// Create a corrupted issue file
-	issuesDir, err := storage.IssuesDir(projectKey)
+	issuesDir, err := storage.IssuesDir(t.Context(), projectKey)
 	if err != nil {
 		t.Fatalf("Failed to resolve issues directory: %v", err)
 	}
@@ -385,13 +437,13 @@ func TestRepairProject_CorruptedIssueFiles(t *testing.T) {
 	}
 
 	// Should still index the valid issue
-	indexPath, err := storage.ProjectIndexPath(projectKey)
+	indexPath, err := storage.ProjectIndexPath(t.Context(), projectKey)
 	if err != nil {
 		t.Fatalf("Failed to resolve index path: %v", err)
 	}
 
 	var index models.ProjectIndex
-	if err := storage.ReadJSON(indexPath, &index); err != nil {
+	if err := storage.ReadIndex(t.Context(), indexPath, &index); err != nil {
 		t.Fatalf("Failed to read project index: %v", err)
 	}
 
@@ -423,7 +475,7 @@ func TestResolveProjectKey(t *testing.T) {
 func TestDeleteProject_WithYesFlag(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -463,7 +515,7 @@ func TestDeleteProject_WithYesFlag(t *testing.T) {
 	}
 
 	// Verify project directory was deleted
-	projectDir, err := storage.ProjectDir(projectKey)
+	projectDir, err := storage.ProjectDir(t.Context(), projectKey)
 	if err != nil {
 		t.Fatalf("Failed to resolve project directory: %v", err)
 	}
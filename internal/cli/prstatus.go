@@ -0,0 +1,462 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// prStatusMaxAge is how long a fetched PR status is reused. Merged PRs
+// never change, so their status is reused for good.
+const prStatusMaxAge = 5 * time.Minute
+
+// prStatusTimeout is how long a query to GitHub or GitLab may take.
+const prStatusTimeout = 15 * time.Second
+
+// PRStatusReport is the PR status of an issue.
+type PRStatusReport struct {
+	IssueID   string            `json:"issue_id"`
+	PRs       []models.PRStatus `json:"prs"`
+	AllMerged bool              `json:"all_merged"`
+	Closed    string            `json:"closed,omitempty"`  // Done status the issue moved to (--close)
+	Blocked   string            `json:"blocked,omitempty"` // Why --close did not move the issue
+}
+
+// NewIssuePRStatusCmd creates and returns the issue pr status command.
+func NewIssuePRStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status <id>",
+		Short: "Show the state and checks of an issue's PRs",
+		Long: `Query GitHub or GitLab for the state (open, merged, closed) and CI checks of
+each PR linked to an issue. Tokens are read from $GITHUB_TOKEN (or $GH_TOKEN)
+and $GITLAB_TOKEN; public repositories work without one. Statuses are cached
+for 5 minutes (merged PRs for good); --refresh fetches them again.
+
+With --close, an issue whose PRs are all merged moves to the first done status
+of its workflow, if the workflow allows it.`,
+		Example: `  buyruk issue pr status CORE-12
+  buyruk issue pr status CORE-12 --refresh --close`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeIssueArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveIssueArgs(cmd, args[:1]); err != nil {
+				return err
+			}
+			return showPRStatus(args[0], cmd)
+		},
+	}
+
+	cmd.Flags().Bool("refresh", false, "Ignore cached statuses")
+	cmd.Flags().Bool("close", false, "Close the issue when all its PRs are merged")
+
+	return cmd
+}
+
+// showPRStatus prints the PR statuses of an issue and closes it if asked to.
+func showPRStatus(issueID string, cmd *cobra.Command) error {
+	projectKey, _, err := parseIssueID(cmd.Context(), issueID)
+	if err != nil {
+		return invalidf("cli: invalid issue ID %q: %w", issueID, err)
+	}
+	issue, err := loadLocalIssue(cmd.Context(), projectKey, issueID)
+	if err != nil {
+		return err
+	}
+	if issue == nil {
+		return notFoundf("cli: issue %q not found", issueID)
+	}
+	if len(issue.PRs) == 0 {
+		return fmt.Errorf("cli: issue %q has no PRs (add one with 'buyruk issue pr %s <url>')", issueID, issueID)
+	}
+
+	refresh, _ := cmd.Flags().GetBool("refresh")
+	report := &PRStatusReport{IssueID: issueID, PRs: fetchPRStatuses(cmd.Context(), issue.PRs, refresh)}
+	report.AllMerged = models.AllPRsMerged(report.PRs)
+	if closeIssue, _ := cmd.Flags().GetBool("close"); closeIssue && report.AllMerged {
+		if report.Closed, report.Blocked, err = closeMergedIssue(cmd.Context(), projectKey, issue); err != nil {
+			return err
+		}
+	}
+
+	out := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(out, report)
+	case config.DefaultFormatLSON:
+		renderPRStatuses(report.PRs, cmd, out)
+		fmt.Fprintf(out, "@ALL_MERGED: %t\n", report.AllMerged)
+		if report.Closed != "" {
+			fmt.Fprintf(out, "@CLOSED: %s\n", report.Closed)
+		}
+	default:
+		renderPRStatuses(report.PRs, cmd, out)
+		switch {
+		case report.Closed != "":
+			fmt.Fprintf(out, "All PRs merged: moved %s to %s\n", issueID, report.Closed)
+		case report.Blocked != "":
+			fmt.Fprintf(out, "All PRs merged, but %s stays open: %s\n", issueID, report.Blocked)
+		}
+	}
+	return nil
+}
+
+// closeMergedIssue moves an issue whose PRs are all merged to its first done
+// status, and returns the status or why the issue could not move.
+func closeMergedIssue(ctx context.Context, projectKey string, current *models.Issue) (closed, blocked string, err error) {
+	wf, err := loadWorkflow(ctx, projectKey)
+	if err != nil {
+		return "", "", err
+	}
+	resolution := "Merged in " + strings.Join(current.PRs, ", ")
+	check := *current
+	if closed, blocked = closeIssue(&check, wf, resolution); closed == "" {
+		return "", blocked, nil
+	}
+
+	issueID := current.ID
+	issuePath, err := storage.IssuePath(ctx, projectKey, issueID)
+	if err != nil {
+		return "", "", fmt.Errorf("cli: failed to resolve issue path: %w", err)
+	}
+	var issue models.Issue
+	if err := storage.UpdateJSONAtomic(ctx, issuePath, &issue, func(v interface{}) error {
+		iss := v.(*models.Issue)
+		if iss.ID == "" || iss.ID != issueID {
+			return notFoundf("cli: issue %q not found", issueID)
+		}
+		if closed, blocked = closeIssue(iss, wf, resolution); closed == "" && blocked != "" {
+			return invalidf("cli: cannot close %s: %s", issueID, blocked)
+		}
+		iss.UpdatedAt = storage.Timestamp(ctx)
+		return nil
+	}); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return "", "", notFoundf("cli: issue %q not found", issueID)
+		}
+		return "", "", fmt.Errorf("cli: failed to update issue: %w", err)
+	}
+
+	indexPath, err := storage.ProjectIndexPath(ctx, projectKey)
+	if err != nil {
+		return "", "", fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	if err := storage.UpdateIndexAtomic(ctx, indexPath, &models.ProjectIndex{}, func(v interface{}) error {
+		idx := v.(*models.ProjectIndex)
+		idx.AddIssue(&issue)
+		idx.UpdatedAt = storage.Timestamp(ctx)
+		return nil
+	}); err != nil {
+		return "", "", fmt.Errorf("cli: failed to update project index: %w", err)
+	}
+	return closed, "", nil
+}
+
+// fetchPRStatuses returns the status of each PR URL, from the cache when it
+// is fresh enough. A PR that cannot be fetched gets its last known status, or
+// none, with the error.
+func fetchPRStatuses(ctx context.Context, prURLs []string, refresh bool) []models.PRStatus {
+	cache := loadPRStatusCache(ctx)
+	changed := false
+	statuses := make([]models.PRStatus, 0, len(prURLs))
+	for _, prURL := range prURLs {
+		cached, ok := cache[prURL]
+		if ok && !refresh && cached.Error == "" && prStatusFresh(ctx, &cached) {
+			statuses = append(statuses, cached)
+			continue
+		}
+		status, err := fetchPRStatus(ctx, prURL)
+		if err != nil {
+			if !ok {
+				cached = models.PRStatus{URL: prURL}
+			}
+			cached.Error = err.Error()
+			statuses = append(statuses, cached)
+			continue
+		}
+		status.FetchedAt = storage.Timestamp(ctx)
+		cache[prURL] = *status
+		changed = true
+		statuses = append(statuses, *status)
+	}
+	if changed {
+		savePRStatusCache(ctx, cache)
+	}
+	return statuses
+}
+
+// prStatusFresh reports whether a cached PR status can be reused.
+func prStatusFresh(ctx context.Context, status *models.PRStatus) bool {
+	if status.State == models.PRStateMerged {
+		return true
+	}
+	fetched, err := time.Parse(time.RFC3339, status.FetchedAt)
+	return err == nil && storage.Now(ctx).Sub(fetched) < prStatusMaxAge
+}
+
+// loadPRStatusCache loads the PR status cache. The cache is best effort: an
+// unreadable cache is treated as empty.
+func loadPRStatusCache(ctx context.Context) map[string]models.PRStatus {
+	cache := map[string]models.PRStatus{}
+	if cachePath, err := storage.PRStatusCachePath(); err == nil {
+		storage.ReadJSON(ctx, cachePath, &cache)
+	}
+	return cache
+}
+
+// savePRStatusCache saves the PR status cache, ignoring errors.
+func savePRStatusCache(ctx context.Context, cache map[string]models.PRStatus) {
+	cachePath, err := storage.PRStatusCachePath()
+	if err != nil {
+		return
+	}
+	if data, err := json.MarshalIndent(cache, "", "  "); err == nil {
+		storage.WriteAtomic(ctx, cachePath, data)
+	}
+}
+
+// fetchPRStatus queries the provider of a PR for its status.
+func fetchPRStatus(ctx context.Context, prURL string) (*models.PRStatus, error) {
+	ref, err := models.ParsePRURL(prURL)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Provider == models.ProviderGitLab {
+		return fetchGitLabStatus(ctx, prURL, ref)
+	}
+	return fetchGitHubStatus(ctx, prURL, ref)
+}
+
+// prAPIBaseURL returns the API base URL of a PR's host.
+func prAPIBaseURL(ctx context.Context, ref *models.PRRef) string {
+	if base, ok := hostEnvFrom(ctx).prAPIBaseURLs[ref.Host]; ok {
+		return base
+	}
+	switch {
+	case ref.Provider == models.ProviderGitLab:
+		return "https://" + ref.Host + "/api/v4"
+	case ref.Host == "github.com":
+		return "https://api.github.com"
+	default:
+		return "https://" + ref.Host + "/api/v3" // GitHub Enterprise Server
+	}
+}
+
+// getPRAPI fetches a JSON document from a provider API.
+func getPRAPI(ctx context.Context, rawURL, provider string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "buyruk")
+	if provider == models.ProviderGitLab {
+		if token := os.Getenv("GITLAB_TOKEN"); token != "" {
+			req.Header.Set("PRIVATE-TOKEN", token)
+		}
+	} else {
+		req.Header.Set("Accept", "application/vnd.github+json")
+		token := os.Getenv("GITHUB_TOKEN")
+		if token == "" {
+			token = os.Getenv("GH_TOKEN")
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+	client := hostEnvFrom(ctx).prStatusClient
+	if client == nil {
+		client = &http.Client{Timeout: prStatusTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return fmt.Errorf("%s returned %s", req.URL.Host, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("unreadable response from %s: %w", req.URL.Host, err)
+	}
+	return nil
+}
+
+// fetchGitHubStatus fetches a GitHub pull request and the checks of its head
+// commit, combining check runs (GitHub Actions and apps) and commit statuses.
+func fetchGitHubStatus(ctx context.Context, prURL string, ref *models.PRRef) (*models.PRStatus, error) {
+	base := fmt.Sprintf("%s/repos/%s", prAPIBaseURL(ctx, ref), ref.Repo)
+	var pr struct {
+		Title  string `json:"title"`
+		State  string `json:"state"`
+		Merged bool   `json:"merged"`
+		Draft  bool   `json:"draft"`
+		Head   struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	}
+	if err := getPRAPI(ctx, fmt.Sprintf("%s/pulls/%d", base, ref.Number), ref.Provider, &pr); err != nil {
+		return nil, err
+	}
+	status := &models.PRStatus{URL: prURL, Title: pr.Title, State: models.PRStateOpen, Draft: pr.Draft, Checks: models.ChecksNone}
+	switch {
+	case pr.Merged:
+		status.State, status.Draft = models.PRStateMerged, false
+	case pr.State == "closed":
+		status.State, status.Draft = models.PRStateClosed, false
+	}
+	if pr.Head.SHA == "" {
+		return status, nil
+	}
+
+	var runs struct {
+		CheckRuns []struct {
+			Status     string `json:"status"`
+			Conclusion string `json:"conclusion"`
+		} `json:"check_runs"`
+	}
+	var combined struct {
+		State      string `json:"state"`
+		TotalCount int    `json:"total_count"`
+	}
+	if err := getPRAPI(ctx, fmt.Sprintf("%s/commits/%s/check-runs", base, pr.Head.SHA), ref.Provider, &runs); err != nil {
+		return nil, err
+	}
+	if err := getPRAPI(ctx, fmt.Sprintf("%s/commits/%s/status", base, pr.Head.SHA), ref.Provider, &combined); err != nil {
+		return nil, err
+	}
+	var results []string
+	for _, run := range runs.CheckRuns {
+		switch {
+		case run.Status != "completed":
+			results = append(results, models.ChecksPending)
+		case run.Conclusion == "success", run.Conclusion == "neutral", run.Conclusion == "skipped":
+			results = append(results, models.ChecksSuccess)
+		default:
+			results = append(results, models.ChecksFailure)
+		}
+	}
+	if combined.TotalCount > 0 {
+		switch combined.State {
+		case "success":
+			results = append(results, models.ChecksSuccess)
+		case "pending":
+			results = append(results, models.ChecksPending)
+		default:
+			results = append(results, models.ChecksFailure)
+		}
+	}
+	status.Checks = combineChecks(results)
+	return status, nil
+}
+
+// fetchGitLabStatus fetches a GitLab merge request and its head pipeline.
+func fetchGitLabStatus(ctx context.Context, prURL string, ref *models.PRRef) (*models.PRStatus, error) {
+	var mr struct {
+		Title        string `json:"title"`
+		State        string `json:"state"`
+		Draft        bool   `json:"draft"`
+		HeadPipeline *struct {
+			Status string `json:"status"`
+		} `json:"head_pipeline"`
+	}
+	apiURL := fmt.Sprintf("%s/projects/%s/merge_requests/%d", prAPIBaseURL(ctx, ref), url.PathEscape(ref.Repo), ref.Number)
+	if err := getPRAPI(ctx, apiURL, ref.Provider, &mr); err != nil {
+		return nil, err
+	}
+	status := &models.PRStatus{URL: prURL, Title: mr.Title, State: models.PRStateOpen, Draft: mr.Draft, Checks: models.ChecksNone}
+	switch mr.State {
+	case "merged":
+		status.State, status.Draft = models.PRStateMerged, false
+	case "closed", "locked":
+		status.State, status.Draft = models.PRStateClosed, false
+	}
+	if mr.HeadPipeline != nil {
+		switch mr.HeadPipeline.Status {
+		case "success":
+			status.Checks = models.ChecksSuccess
+		case "failed", "canceled":
+			status.Checks = models.ChecksFailure
+		case "skipped", "":
+		default: // created, pending, running, manual, scheduled, ...
+			status.Checks = models.ChecksPending
+		}
+	}
+	return status, nil
+}
+
+// combineChecks combines check results: any failure fails, then any pending
+// check is pending.
+func combineChecks(results []string) string {
+	combined := models.ChecksNone
+	for _, result := range results {
+		switch {
+		case result == models.ChecksFailure:
+			return models.ChecksFailure
+		case result == models.ChecksPending:
+			combined = models.ChecksPending
+		case combined == models.ChecksNone:
+			combined = result
+		}
+	}
+	return combined
+}
+
+// renderPRStatuses renders PR statuses in modern or L-SON format.
+func renderPRStatuses(statuses []models.PRStatus, cmd *cobra.Command, w io.Writer) {
+	if len(statuses) == 0 {
+		return
+	}
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatLSON:
+		for _, status := range statuses {
+			fmt.Fprintf(w, "@PR_STATUS: %s|%s|%s\n", status.URL, prStateLabel(&status), status.Checks)
+		}
+	case config.DefaultFormatModern:
+		styles := ui.NewStyles()
+		fmt.Fprintf(w, "\n%s:\n", styles.Label("PR Status"))
+		for _, status := range statuses {
+			if status.State == "" {
+				fmt.Fprintf(w, "  - %s %s\n", status.URL, styles.Error("unknown: "+status.Error))
+				continue
+			}
+			line := fmt.Sprintf("  - %s [%s]", status.URL, prStateLabel(&status))
+			if status.Checks != "" && status.Checks != models.ChecksNone {
+				line += " checks: " + status.Checks
+			}
+			if status.Title != "" {
+				line += " " + status.Title
+			}
+			if status.Error != "" {
+				line += " " + styles.Error("(stale: "+status.Error+")")
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+}
+
+// prStateLabel returns the state of a PR for display, marking drafts.
+func prStateLabel(status *models.PRStatus) string {
+	switch {
+	case status.State == "":
+		return "unknown"
+	case status.Draft:
+		return status.State + " (draft)"
+	}
+	return status.State
+}
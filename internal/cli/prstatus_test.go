@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestIssuePRStatus(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	// A fake GitHub and GitLab: PR 1 is merged, PR 2 is open with a failing
+	// check, and merge request 3 is merged
+	repo := strings.ToLower(projectKey)
+	var requests atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/acme/"+repo+"/pulls/1", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`{"title":"Fix login","state":"closed","merged":true,"head":{"sha":"aaa"}}`))
+	})
+	mux.HandleFunc("/repos/acme/"+repo+"/pulls/2", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`{"title":"Refactor","state":"open","draft":true,"head":{"sha":"bbb"}}`))
+	})
+	mux.HandleFunc("/repos/acme/"+repo+"/commits/", func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "bbb/check-runs"):
+			w.Write([]byte(`{"check_runs":[{"status":"completed","conclusion":"success"},{"status":"completed","conclusion":"failure"}]}`))
+		case strings.HasSuffix(r.URL.Path, "/check-runs"):
+			w.Write([]byte(`{"check_runs":[{"status":"completed","conclusion":"success"}]}`))
+		default:
+			w.Write([]byte(`{"state":"pending","total_count":0}`))
+		}
+	})
+	mux.HandleFunc("/gitlab/projects/", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.EscapedPath() != "/gitlab/projects/acme%2F"+repo+"/merge_requests/3" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"title":"Add API","state":"merged","head_pipeline":{"status":"running"}}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	ctx := withHostEnv(t.Context(), hostEnv{
+		prAPIBaseURLs: map[string]string{"github.com": server.URL, "gitlab.com": server.URL + "/gitlab"},
+	})
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.ExecuteContext(ctx)
+		return out.String(), err
+	}
+
+	pr1 := "https://github.com/acme/" + repo + "/pull/1"
+	pr2 := "https://github.com/acme/" + repo + "/pull/2"
+	mr3 := "https://gitlab.com/acme/" + repo + "/-/merge_requests/3"
+	issueID := projectKey + "-1"
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "Login"},
+		{"issue", "pr", issueID, pr1},
+		{"issue", "pr", issueID, pr2},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	out, err := run("issue", "pr", "status", issueID, "--refresh", "--close", "--format", "json")
+	if err != nil {
+		t.Fatalf("issue pr status failed: %v", err)
+	}
+	var report PRStatusReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("Failed to parse report: %v\n%s", err, out)
+	}
+	if len(report.PRs) != 2 || report.AllMerged || report.Closed != "" {
+		t.Fatalf("report = %+v", report)
+	}
+	if got := report.PRs[0]; got.State != models.PRStateMerged || got.Checks != models.ChecksSuccess || got.Title != "Fix login" {
+		t.Errorf("PR 1 = %+v", got)
+	}
+	if got := report.PRs[1]; got.State != models.PRStateOpen || !got.Draft || got.Checks != models.ChecksFailure {
+		t.Errorf("PR 2 = %+v", got)
+	}
+
+	// Statuses are cached
+	before := requests.Load()
+	out, err = run("view", issueID, "--pr-status")
+	if err != nil || !strings.Contains(out, "[open (draft)] checks: failure") {
+		t.Errorf("view --pr-status failed: %v\n%s", err, out)
+	}
+	if requests.Load() != before {
+		t.Error("view --pr-status fetched statuses again instead of using the cache")
+	}
+
+	// With every PR merged, --close closes the issue
+	for _, args := range [][]string{
+		{"issue", "pr", issueID, pr2, "--remove"},
+		{"issue", "pr", issueID, mr3},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+	out, err = run("issue", "pr", "status", issueID, "--refresh", "--close")
+	if err != nil || !strings.Contains(out, "moved "+issueID+" to DONE") || !strings.Contains(out, "checks: pending") {
+		t.Fatalf("issue pr status --close failed: %v\n%s", err, out)
+	}
+	issue, _ := loadLocalIssue(t.Context(), projectKey, issueID)
+	if issue == nil || issue.Status != models.StatusDONE {
+		t.Errorf("issue = %+v, want DONE", issue)
+	}
+	index, _ := loadQueryIndex(t.Context(), projectKey)
+	if len(index.Issues) != 1 || index.Issues[0].Status != models.StatusDONE {
+		t.Errorf("index = %+v, want the issue DONE", index.Issues)
+	}
+
+	if _, err := run("issue", "create", "--project", projectKey, "--title", "No PRs"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run("issue", "pr", "status", projectKey+"-2"); err == nil {
+		t.Error("issue pr status of an issue without PRs succeeded")
+	}
+}
@@ -0,0 +1,256 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// Query exit codes, following grep: 0 for a true/non-zero result, 1 for
+// false/zero. Errors exit with the code of their kind, like every command.
+const (
+	QueryExitTrue  = 0
+	QueryExitFalse = 1
+)
+
+// queryFields lists the index fields a query filter can match.
+var queryFields = []string{"id", "status", "type", "priority", "epic", "milestone", "parent", "label", "component", "assignee"}
+
+// queryCondition is one "field=value" or "field!=value" filter condition.
+// Values may list alternatives separated by "|".
+type queryCondition struct {
+	field  string
+	values []string
+	negate bool
+}
+
+// NewQueryCmd creates and returns the query command.
+func NewQueryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "query",
+		Short: "Answer yes/no and count questions for scripts",
+		Long: `Answer a question about a project with a single value and an exit status,
+for shell conditionals without parsing output:
+
+  buyruk query --exists CORE-12             # prints true/false
+  buyruk query --count "status=TODO"        # prints the number of matching issues
+  buyruk query --count "status!=DONE,label=infra|ui"
+  buyruk query --count all
+  buyruk query --empty                      # true if the project has no issues
+
+Filters are conditions separated by commas or "and" that must all match,
+on the fields
+` + strings.Join(queryFields, ", ") + `. Use != to negate and | for alternatives;
+field:value is the same as field=value.
+
+Exit status: 0 if the answer is true or the count is non-zero, 1 if it is false
+or zero. Errors exit with the usual codes, e.g. 2 if the project is not found
+and 3 for an invalid filter. With --quiet, nothing is printed and only the exit
+status answers.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := runQuery(cmd)
+			var exitErr *ExitError
+			if errors.As(err, &exitErr) {
+				// A false answer is a result, not an error message
+				cmd.SilenceErrors = exitErr.Err == nil
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().String("exists", "", "Check whether an issue exists")
+	cmd.Flags().String("count", "", "Count issues matching a filter (e.g. \"status=TODO\", or \"all\")")
+	cmd.Flags().Bool("empty", false, "Check whether the project has no issues")
+	cmd.MarkFlagsMutuallyExclusive("exists", "count", "empty")
+	cmd.MarkFlagsOneRequired("exists", "count", "empty")
+	cmd.RegisterFlagCompletionFunc("exists", completeIssueIDs)
+
+	return cmd
+}
+
+// runQuery answers the query and returns an ExitError for false or zero results.
+func runQuery(cmd *cobra.Command) error {
+	var result string
+	truthy := false
+
+	if issueID, _ := cmd.Flags().GetString("exists"); issueID != "" {
+		exists, err := issueExists(cmd.Context(), issueID)
+		if err != nil {
+			return err
+		}
+		result, truthy = fmt.Sprintf("%t", exists), exists
+	} else {
+		projectKey, err := config.ResolveProject(cmd)
+		if err != nil {
+			return err
+		}
+		index, err := loadQueryIndex(cmd.Context(), projectKey)
+		if err != nil {
+			return err
+		}
+
+		if empty, _ := cmd.Flags().GetBool("empty"); empty {
+			isEmpty := len(index.Issues) == 0
+			result, truthy = fmt.Sprintf("%t", isEmpty), isEmpty
+		} else {
+			filter, _ := cmd.Flags().GetString("count")
+			conditions, err := parseQueryFilter(filter)
+			if err != nil {
+				return err
+			}
+			count := 0
+			for i := range index.Issues {
+				if matchesQuery(&index.Issues[i], conditions) {
+					count++
+				}
+			}
+			result, truthy = fmt.Sprintf("%d", count), count > 0
+		}
+	}
+
+	// The exit status gives the answer too, so --quiet drops it like a
+	// success message
+	fmt.Fprintln(successOut(cmd), result)
+	if !truthy {
+		return &ExitError{Code: QueryExitFalse}
+	}
+	return nil
+}
+
+// issueExists reports whether an issue file exists.
+func issueExists(ctx context.Context, issueID string) (bool, error) {
+	projectKey, _, err := parseIssueID(ctx, issueID)
+	if err != nil {
+		return false, invalidf("cli: invalid issue ID %q: %w", issueID, err)
+	}
+	issuePath, err := storage.IssuePath(ctx, projectKey, issueID)
+	if err != nil {
+		return false, fmt.Errorf("cli: failed to resolve issue path: %w", err)
+	}
+	if _, err := os.Stat(issuePath); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("cli: failed to stat issue path %q: %w", issuePath, err)
+	}
+	return true, nil
+}
+
+// loadQueryIndex loads the index of an existing project.
+func loadQueryIndex(ctx context.Context, projectKey string) (*models.ProjectIndex, error) {
+	projectDir, err := storage.ProjectDir(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve project directory: %w", err)
+	}
+	if _, err := os.Stat(projectDir); os.IsNotExist(err) {
+		return nil, notFoundf("cli: project %q does not exist", projectKey)
+	}
+
+	indexPath, err := storage.ProjectIndexPath(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(ctx, indexPath, &index); err != nil {
+		return nil, fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+	return &index, nil
+}
+
+// queryAndRegex matches "and" between query conditions, which is the same as
+// a comma.
+var queryAndRegex = regexp.MustCompile(`(?i)\s+and\s+`)
+
+// parseQueryFilter parses a list of conditions separated by commas or "and".
+// "all" and the empty filter match every issue.
+func parseQueryFilter(filter string) ([]queryCondition, error) {
+	filter = strings.TrimSpace(filter)
+	if filter == "" || strings.EqualFold(filter, "all") {
+		return nil, nil
+	}
+	filter = queryAndRegex.ReplaceAllString(filter, ",")
+
+	var conditions []queryCondition
+	for _, part := range splitList(filter) {
+		field, value, ok := strings.Cut(part, "=")
+		if !ok {
+			// "epic:E-2" is accepted as a shorthand for "epic=E-2"
+			if field, value, ok = strings.Cut(part, ":"); !ok {
+				return nil, invalidf("cli: invalid query condition %q (expected field=value, field:value, or field!=value)", part)
+			}
+		}
+		cond := queryCondition{field: strings.ToLower(strings.TrimSpace(field))}
+		if f, ok := strings.CutSuffix(cond.field, "!"); ok {
+			cond.field = strings.TrimSpace(f)
+			cond.negate = true
+		}
+		if !slices.Contains(queryFields, cond.field) {
+			return nil, invalidf("cli: unknown query field %q (supported: %s)", cond.field, strings.Join(queryFields, ", "))
+		}
+		for _, v := range strings.Split(value, "|") {
+			cond.values = append(cond.values, strings.TrimSpace(v))
+		}
+		conditions = append(conditions, cond)
+	}
+	return conditions, nil
+}
+
+// matchesQuery reports whether an index entry matches all conditions.
+// Values compare case-insensitively; an empty value matches an unset field.
+func matchesQuery(entry *models.IndexEntry, conditions []queryCondition) bool {
+	for _, cond := range conditions {
+		var fieldValues []string
+		switch cond.field {
+		case "id":
+			fieldValues = []string{entry.ID}
+		case "status":
+			fieldValues = []string{entry.Status}
+		case "type":
+			fieldValues = []string{entry.Type}
+		case "priority":
+			fieldValues = []string{entry.Priority}
+		case "epic":
+			fieldValues = []string{entry.EpicID}
+		case "milestone":
+			fieldValues = []string{entry.FixVersion}
+		case "parent":
+			fieldValues = []string{entry.ParentID}
+		case "assignee":
+			fieldValues = []string{entry.Assignee}
+		case "label":
+			fieldValues = entry.Labels
+			if len(fieldValues) == 0 {
+				fieldValues = []string{""}
+			}
+		case "component":
+			fieldValues = entry.Components
+			if len(fieldValues) == 0 {
+				fieldValues = []string{""}
+			}
+		}
+
+		matched := false
+		for _, want := range cond.values {
+			for _, have := range fieldValues {
+				if strings.EqualFold(have, want) {
+					matched = true
+				}
+			}
+		}
+		if matched == cond.negate {
+			return false
+		}
+	}
+	return true
+}
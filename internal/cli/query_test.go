@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+)
+
+func TestNewQueryCmd(t *testing.T) {
+	cmd := NewQueryCmd()
+	if cmd == nil {
+		t.Fatal("NewQueryCmd() returned nil")
+	}
+	if cmd.Use != "query" {
+		t.Errorf("Expected Use to be 'query', got '%s'", cmd.Use)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, 0},
+		{"plain error", errors.New("boom"), 1},
+		{"exit error", &ExitError{Code: 1}, 1},
+		{"wrapped exit error", &ExitError{Code: 2, Err: errors.New("boom")}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesQuery(t *testing.T) {
+	entry := &models.IndexEntry{ID: "CORE-1", Status: "TODO", Type: "task", EpicID: "E-1", Labels: []string{"infra", "ui"}}
+
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		{"all", true},
+		{"status=TODO", true},
+		{"status=todo", true},
+		{"status=DONE", false},
+		{"status!=DONE", true},
+		{"status=DOING|TODO", true},
+		{"status=TODO,type=bug", false},
+		{"label=infra", true},
+		{"label!=infra", false},
+		{"label=backend|ui", true},
+		{"epic=E-1,parent=", true},
+		{"epic:E-1,label:ui", true},
+		{"parent!=", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			conditions, err := parseQueryFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("parseQueryFilter() error = %v", err)
+			}
+			if got := matchesQuery(entry, conditions); got != tt.want {
+				t.Errorf("matchesQuery(%q) = %v, want %v", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQueryFilter_Invalid(t *testing.T) {
+	for _, filter := range []string{"status", "owner=me"} {
+		if _, err := parseQueryFilter(filter); err == nil {
+			t.Errorf("parseQueryFilter(%q) should fail", filter)
+		}
+	}
+}
+
+// runQueryTest runs a query and returns its output and exit code.
+func runQueryTest(t *testing.T, args ...string) (string, string, int) {
+	t.Helper()
+	cmd := NewRootCmd()
+	cmd.SetArgs(append([]string{"query"}, args...))
+	out := new(bytes.Buffer)
+	errOut := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(errOut)
+	err := cmd.Execute()
+	return strings.TrimSpace(out.String()), errOut.String(), ExitCode(err)
+}
+
+func TestQuery(t *testing.T) {
+	projectKey := setupApplyProject(t)
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantOut  string
+		wantCode int
+	}{
+		{"exists", []string{"--exists", projectKey + "-1"}, "true", 0},
+		{"missing", []string{"--exists", projectKey + "-99"}, "false", 1},
+		{"count all", []string{"--project", projectKey, "--count", "all"}, "1", 0},
+		{"count match", []string{"--project", projectKey, "--count", "status=TODO"}, "1", 0},
+		{"count none", []string{"--project", projectKey, "--count", "status=DONE"}, "0", 1},
+		{"empty", []string{"--project", projectKey, "--empty"}, "false", 1},
+		{"quiet", []string{"--project", projectKey, "--count", "all", "--quiet"}, "", 0},
+		{"quiet false", []string{"-q", "--project", projectKey, "--count", "status=DONE"}, "", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, errOut, code := runQueryTest(t, tt.args...)
+			if out != tt.wantOut {
+				t.Errorf("Expected output %q, got %q", tt.wantOut, out)
+			}
+			if code != tt.wantCode {
+				t.Errorf("Expected exit code %d, got %d", tt.wantCode, code)
+			}
+			if errOut != "" {
+				t.Errorf("Expected no error output, got %q", errOut)
+			}
+		})
+	}
+}
+
+func TestQuery_Errors(t *testing.T) {
+	projectKey := setupApplyProject(t)
+
+	if _, errOut, code := runQueryTest(t, "--project", projectKey, "--count", "owner=me"); code != ExitInvalid || errOut == "" {
+		t.Errorf("Expected exit code %d with an error message, got %d (%q)", ExitInvalid, code, errOut)
+	}
+	if _, _, code := runQueryTest(t, "--project", "NOPE"+projectKey, "--empty"); code != ExitNotFound {
+		t.Errorf("Expected exit code %d for missing project, got %d", ExitNotFound, code)
+	}
+}
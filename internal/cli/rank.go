@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// NewIssueRankCmd creates and returns the issue rank command.
+func NewIssueRankCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rank <id>",
+		Short: "Order an issue manually in the backlog",
+		Long: `Move an issue before or after another issue of its project, or to the top or
+bottom, in the manual order shown by list --sort rank and share board columns.
+
+Each ranked issue keeps a short rank string that sorts between its neighbors',
+so moving an issue only rewrites that issue (and the other issue, the first
+time it is ranked). Ranked issues come before unranked ones.`,
+		Example: `  buyruk issue rank CORE-12 --before CORE-7
+  buyruk issue rank CORE-12 --after CORE-9
+  buyruk issue rank CORE-12 --top`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeIssueArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveIssueArgs(cmd, args[:1]); err != nil {
+				return err
+			}
+			return rankIssue(args[0], cmd)
+		},
+	}
+
+	cmd.Flags().String("before", "", "Rank the issue just before this issue")
+	cmd.Flags().String("after", "", "Rank the issue just after this issue")
+	cmd.Flags().Bool("top", false, "Rank the issue first")
+	cmd.Flags().Bool("bottom", false, "Rank the issue last among ranked issues")
+	cmd.MarkFlagsMutuallyExclusive("before", "after", "top", "bottom")
+	cmd.MarkFlagsOneRequired("before", "after", "top", "bottom")
+	cmd.RegisterFlagCompletionFunc("before", completeIssueIDs)
+	cmd.RegisterFlagCompletionFunc("after", completeIssueIDs)
+
+	return cmd
+}
+
+// rankIssue gives an issue a rank between the neighbors of the position the
+// flags ask for, ranking the other issue last first if it has no rank yet.
+func rankIssue(issueID string, cmd *cobra.Command) error {
+	projectKey, _, err := parseIssueID(cmd.Context(), issueID)
+	if err != nil {
+		return invalidf("cli: invalid issue ID %q: %w", issueID, err)
+	}
+	before, _ := cmd.Flags().GetString("before")
+	after, _ := cmd.Flags().GetString("after")
+	top, _ := cmd.Flags().GetBool("top")
+	otherID := before + after
+	if otherID != "" {
+		otherKey, _, err := parseIssueID(cmd.Context(), otherID)
+		if err != nil {
+			return invalidf("cli: invalid issue ID %q: %w", otherID, err)
+		}
+		if otherKey != projectKey {
+			return invalidf("cli: %s and %s are in different projects (ranks order issues within a project)", issueID, otherID)
+		}
+		if otherID == issueID {
+			return invalidf("cli: cannot rank %s relative to itself", issueID)
+		}
+	}
+
+	indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	tx, err := storage.BeginProjectTx(cmd.Context(), projectKey, "rank_issue", map[string]interface{}{
+		"issue_id": issueID,
+	})
+	if err != nil {
+		return fmt.Errorf("cli: failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(cmd.Context(), indexPath, &index); err != nil {
+		return fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+	if index.FindIssue(issueID) == nil {
+		return notFoundf("cli: issue %q not found", issueID)
+	}
+
+	// The ranked issues other than the one being moved, in rank order
+	var ranked []models.IndexEntry
+	for _, entry := range index.Issues {
+		if entry.Rank != "" && entry.ID != issueID {
+			ranked = append(ranked, entry)
+		}
+	}
+	slices.SortStableFunc(ranked, func(a, b models.IndexEntry) int {
+		return models.CompareRanks(a.Rank, b.Rank)
+	})
+	rankAt := func(i int) string {
+		if i < 0 || i >= len(ranked) {
+			return ""
+		}
+		return ranked[i].Rank
+	}
+
+	var low, high string
+	switch {
+	case top:
+		high = rankAt(0)
+	case otherID != "":
+		if index.FindIssue(otherID) == nil {
+			return notFoundf("cli: issue %q not found", otherID)
+		}
+		pos := slices.IndexFunc(ranked, func(e models.IndexEntry) bool { return e.ID == otherID })
+		if pos < 0 {
+			// Rank the other issue last, so there is a position next to it
+			rank, err := models.RankBetween(rankAt(len(ranked)-1), "")
+			if err != nil {
+				return fmt.Errorf("cli: failed to rank %s: %w", otherID, err)
+			}
+			if err := setIssueRank(cmd.Context(), tx, &index, projectKey, otherID, rank); err != nil {
+				return err
+			}
+			ranked = append(ranked, *index.FindIssue(otherID))
+			pos = len(ranked) - 1
+		}
+		if before != "" {
+			low, high = rankAt(pos-1), ranked[pos].Rank
+		} else {
+			low, high = ranked[pos].Rank, rankAt(pos+1)
+		}
+	default: // bottom
+		low = rankAt(len(ranked) - 1)
+	}
+
+	rank, err := models.RankBetween(low, high)
+	if err != nil {
+		return fmt.Errorf("cli: failed to rank %s: %w", issueID, err)
+	}
+	if err := setIssueRank(cmd.Context(), tx, &index, projectKey, issueID, rank); err != nil {
+		return err
+	}
+	if err := tx.WriteIndex(indexPath, &index); err != nil {
+		return fmt.Errorf("cli: failed to update project index: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("cli: failed to commit transaction: %w", err)
+	}
+
+	out := successOut(cmd)
+	switch {
+	case top:
+		fmt.Fprintf(out, "Ranked %s first\n", issueID)
+	case before != "":
+		fmt.Fprintf(out, "Ranked %s before %s\n", issueID, before)
+	case after != "":
+		fmt.Fprintf(out, "Ranked %s after %s\n", issueID, after)
+	default:
+		fmt.Fprintf(out, "Ranked %s last\n", issueID)
+	}
+	return nil
+}
+
+// setIssueRank writes a new rank to an issue and its index entry.
+func setIssueRank(ctx context.Context, tx *storage.ProjectTx, index *models.ProjectIndex, projectKey, issueID, rank string) error {
+	issuePath, err := storage.IssuePath(ctx, projectKey, issueID)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+	}
+	var issue models.Issue
+	if err := storage.ReadJSON(ctx, issuePath, &issue); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return notFoundf("cli: issue %q not found", issueID)
+		}
+		return fmt.Errorf("cli: failed to load issue: %w", err)
+	}
+	issue.Rank = rank
+	issue.UpdatedAt = storage.Timestamp(ctx)
+	if err := tx.WriteJSON(issuePath, &issue); err != nil {
+		return fmt.Errorf("cli: failed to update issue: %w", err)
+	}
+	index.AddIssue(&issue)
+	return nil
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestIssueRank(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	id := func(n string) string { return projectKey + "-" + n }
+	order := func() string {
+		t.Helper()
+		out, err := run("list", "--project", projectKey, "--sort", "rank", "--template", "{{.ID}}")
+		if err != nil {
+			t.Fatalf("list failed: %v", err)
+		}
+		return strings.ReplaceAll(strings.Join(strings.Fields(out), " "), projectKey+"-", "")
+	}
+
+	if _, err := run("project", "create", projectKey); err != nil {
+		t.Fatalf("project create failed: %v", err)
+	}
+	for _, title := range []string{"One", "Two", "Three", "Four"} {
+		if _, err := run("issue", "create", "--project", projectKey, "--title", title); err != nil {
+			t.Fatalf("issue create failed: %v", err)
+		}
+	}
+
+	// Ranking before an unranked issue ranks both; the rest follow unranked
+	if _, err := run("issue", "rank", id("3"), "--before", id("2")); err != nil {
+		t.Fatalf("issue rank failed: %v", err)
+	}
+	if got := order(); !strings.HasPrefix(got, "3 2 ") {
+		t.Errorf("Expected 3 and 2 first, got %q", got)
+	}
+
+	for _, args := range [][]string{
+		{"issue", "rank", id("4"), "--top"},
+		{"issue", "rank", id("1"), "--after", id("3")},
+		{"issue", "rank", id("3"), "--bottom"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+	if got := order(); got != "4 1 2 3" {
+		t.Errorf("Expected order 4 1 2 3, got %q", got)
+	}
+
+	// Moving an issue only rewrites that issue
+	before, err := loadLocalIssue(t.Context(), projectKey, id("2"))
+	if err != nil {
+		t.Fatalf("Failed to load issue: %v", err)
+	}
+	if _, err := run("issue", "rank", id("3"), "--before", id("1")); err != nil {
+		t.Fatalf("issue rank failed: %v", err)
+	}
+	after, _ := loadLocalIssue(t.Context(), projectKey, id("2"))
+	if after.Rank != before.Rank || after.UpdatedAt != before.UpdatedAt {
+		t.Errorf("Expected %s to be unchanged, rank %q -> %q", id("2"), before.Rank, after.Rank)
+	}
+	if got := order(); got != "4 3 1 2" {
+		t.Errorf("Expected order 4 3 1 2, got %q", got)
+	}
+
+	if _, err := run("issue", "rank", id("1"), "--before", id("1")); err == nil {
+		t.Error("Expected ranking an issue relative to itself to fail")
+	}
+	if _, err := run("issue", "rank", id("1")); err == nil {
+		t.Error("Expected issue rank without a position to fail")
+	}
+	if _, err := run("issue", "rank", id("1"), "--before", id("9")); err == nil {
+		t.Error("Expected ranking relative to a missing issue to fail")
+	}
+}
@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// NewProjectLockCmd creates and returns the project lock command.
+func NewProjectLockCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lock <key>",
+		Short: "Make a project read-only",
+		Long: `Make a project read-only, e.g. during a release freeze. Every command that
+would change the project (issues, epics, settings, the trash, ...) refuses to
+until buyruk project unlock; reading and exporting it still work. A single
+command can still change it with --unlock-override.
+
+The lock is kept in projects/[KEY]/readonly.json, so it travels with sync
+and backups. (buyruk unlock is unrelated: it unlocks the keys of sensitive
+issues.)`,
+		Example: `  buyruk project lock CORE --reason "v1.2 release freeze"
+  buyruk issue update CORE-7 --status DONE --unlock-override`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeProjectArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return lockProjectWrites(args[0], cmd)
+		},
+	}
+
+	cmd.Flags().String("reason", "", "Why the project is read-only, shown to commands it refuses")
+
+	return cmd
+}
+
+// lockProjectWrites makes a project read-only.
+func lockProjectWrites(projectKey string, cmd *cobra.Command) error {
+	if _, err := loadQueryIndex(cmd.Context(), projectKey); err != nil {
+		return err
+	}
+	lock, err := loadProjectLock(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	if lock != nil {
+		return conflictf("cli: project %s is already read-only (locked at %s)", projectKey, lock.LockedAt)
+	}
+
+	reason, _ := cmd.Flags().GetString("reason")
+	lock = &models.ProjectLock{
+		LockedAt: storage.Timestamp(cmd.Context()),
+		LockedBy: currentActor(cmd.Context()),
+		Reason:   strings.TrimSpace(reason),
+	}
+	lockPath, err := storage.ReadOnlyPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve lock path: %w", err)
+	}
+	if err := storage.WriteJSONAtomic(cmd.Context(), lockPath, lock); err != nil {
+		return fmt.Errorf("cli: failed to lock project: %w", err)
+	}
+
+	fmt.Fprintf(successOut(cmd), "Locked %s: it is read-only until `buyruk project unlock %s`\n", projectKey, projectKey)
+	return nil
+}
+
+// NewProjectUnlockCmd creates and returns the project unlock command.
+func NewProjectUnlockCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "unlock <key>",
+		Short:             "Restore write access to a read-only project",
+		Long:              "Restore write access to a project made read-only with buyruk project lock.",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeProjectArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return unlockProjectWrites(args[0], cmd)
+		},
+	}
+
+	return cmd
+}
+
+// unlockProjectWrites restores write access to a read-only project.
+func unlockProjectWrites(projectKey string, cmd *cobra.Command) error {
+	if _, err := loadQueryIndex(cmd.Context(), projectKey); err != nil {
+		return err
+	}
+	lock, err := loadProjectLock(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	if lock == nil {
+		return invalidf("cli: project %s is not read-only", projectKey)
+	}
+
+	lockPath, err := storage.ReadOnlyPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve lock path: %w", err)
+	}
+	cleanup, err := storage.AcquireLock(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to acquire lock: %w", err)
+	}
+	defer cleanup()
+	if err := os.Remove(lockPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("cli: failed to unlock project: %w", err)
+	}
+
+	fmt.Fprintf(successOut(cmd), "Unlocked %s\n", projectKey)
+	return nil
+}
+
+// loadProjectLock returns the read-only lock of a project, or nil if the
+// project isn't read-only.
+func loadProjectLock(ctx context.Context, projectKey string) (*models.ProjectLock, error) {
+	lockPath, err := storage.ReadOnlyPath(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve lock path: %w", err)
+	}
+	var lock models.ProjectLock
+	if err := storage.ReadJSON(ctx, lockPath, &lock); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cli: failed to load the lock of project %s: %w", projectKey, err)
+	}
+	return &lock, nil
+}
+
+// checkProjectWritable returns an error if a project is read-only, unless
+// --unlock-override is given.
+func checkProjectWritable(ctx context.Context, projectKey string) error {
+	if state := runStateFrom(ctx); state != nil && state.unlockOverride {
+		return nil
+	}
+	lock, err := loadProjectLock(ctx, projectKey)
+	if err != nil || lock == nil {
+		return err
+	}
+	detail := "locked at " + lock.LockedAt
+	if lock.LockedBy != "" {
+		detail += " by " + lock.LockedBy
+	}
+	if lock.Reason != "" {
+		detail += ": " + lock.Reason
+	}
+	return conflictf("cli: project %s is read-only (%s); unlock it with `buyruk project unlock %s`, or pass --unlock-override", projectKey, detail, projectKey)
+}
+
+// readOnlyWriteFilter refuses writes to the files of read-only projects.
+// The lock stats, which commands record while reading too, are still written.
+func readOnlyWriteFilter(ctx context.Context, path string, data []byte) ([]byte, error) {
+	projectKey, ok := projectFileKey(ctx, path)
+	if !ok {
+		return data, nil
+	}
+	if statsPath, err := storage.LockStatsPath(ctx, projectKey); err == nil && statsPath == path {
+		return data, nil
+	}
+	if err := checkProjectWritable(ctx, projectKey); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// projectFileKey returns the key of the project a path is in, if it is in a
+// project directory.
+func projectFileKey(ctx context.Context, path string) (string, bool) {
+	dataDir, err := storage.DataDir(ctx)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(filepath.Join(dataDir, "projects"), path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	projectKey, _, ok := strings.Cut(rel, string(filepath.Separator))
+	if !ok || !isValidProjectKey(projectKey) {
+		return "", false
+	}
+	return projectKey, true
+}
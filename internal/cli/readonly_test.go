@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestProjectLock(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"epic", "create", "--project", projectKey, "--title", "Release"},
+		{"issue", "create", "--project", projectKey, "--title", "Changelog"},
+		{"issue", "create", "--project", projectKey, "--title", "Tag"},
+		{"issue", "delete", projectKey + "-2", "--yes"},
+		{"project", "lock", projectKey, "--reason", "v1.2 release freeze"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	if _, err := run("project", "lock", projectKey); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected locking a read-only project to conflict, got %v", err)
+	}
+
+	issueID := projectKey + "-1"
+	for _, args := range [][]string{
+		{"issue", "create", "--project", projectKey, "--title", "Hotfix"},
+		{"issue", "update", issueID, "--status", "DOING"},
+		{"issue", "comment", issueID, "Frozen?"},
+		{"issue", "delete", issueID, "--yes"},
+		{"epic", "update", projectKey + "-E1", "--project", projectKey, "--title", "Renamed"},
+		{"trash", "empty", "--project", projectKey, "--yes"},
+		{"project", "delete", projectKey, "--yes"},
+	} {
+		_, err := run(args...)
+		if !errors.Is(err, ErrConflict) || !strings.Contains(err.Error(), "v1.2 release freeze") {
+			t.Errorf("Expected %v to be refused on a read-only project, got %v", args, err)
+		}
+	}
+
+	// Creating an issue is refused before a sequence number is reserved
+	if _, err := run("issue", "create", "--project", projectKey, "--title", "Hotfix"); err == nil || strings.Contains(err.Error(), "sequence") {
+		t.Errorf("Expected issue create to be refused without reserving a sequence number, got %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"list", "--project", projectKey},
+		{"issue", "view", issueID},
+		{"epic", "list", "--project", projectKey},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Errorf("Expected %v to work on a read-only project, got %v", args, err)
+		}
+	}
+
+	if _, err := run("issue", "update", issueID, "--status", "DONE", "--unlock-override"); err != nil {
+		t.Fatalf("Expected --unlock-override to allow the update, got %v", err)
+	}
+	out, err := run("issue", "view", issueID, "--format", "lson")
+	if err != nil || !strings.Contains(out, "DONE") {
+		t.Errorf("Expected the overridden update to be written, got %q (%v)", out, err)
+	}
+
+	if _, err := run("project", "unlock", projectKey); err != nil {
+		t.Fatalf("project unlock failed: %v", err)
+	}
+	if _, err := run("project", "unlock", projectKey); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected unlocking a writable project to fail, got %v", err)
+	}
+	if _, err := run("issue", "update", issueID, "--status", "TODO"); err != nil {
+		t.Errorf("Expected updates after unlock to work, got %v", err)
+	}
+}
@@ -0,0 +1,234 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+// fileWrite is a planned change to one file, kept with its original content so
+// that a multi-file change can be undone.
+type fileWrite struct {
+	path     string
+	data     []byte               // nil removes the file, unless index is set
+	index    *models.ProjectIndex // Written with storage.WriteIndex instead of data
+	original []byte               // nil if the file did not exist
+}
+
+// writeFiles applies writes in order. If one fails, the files already written
+// are restored so no partial change is left behind.
+func writeFiles(ctx context.Context, writes []fileWrite) error {
+	for i, w := range writes {
+		var err error
+		if w.index != nil {
+			err = storage.WriteIndex(ctx, w.path, w.index)
+		} else if w.data == nil {
+			if projectKey, ok := projectFileKey(ctx, w.path); ok {
+				err = checkProjectWritable(ctx, projectKey)
+			}
+			if err == nil {
+				err = os.Remove(w.path)
+			}
+		} else {
+			err = storage.WriteAtomic(ctx, w.path, w.data)
+		}
+		if err != nil {
+			restoreFiles(ctx, writes[:i])
+			return fmt.Errorf("cli: failed to write %s: %w", w.path, err)
+		}
+	}
+	return nil
+}
+
+// restoreFiles puts back the original content of written files, newest first.
+func restoreFiles(ctx context.Context, writes []fileWrite) {
+	for i := len(writes) - 1; i >= 0; i-- {
+		w := writes[i]
+		if w.original == nil {
+			os.Remove(w.path)
+		} else {
+			storage.RestoreAtomic(ctx, w.path, w.original)
+		}
+	}
+}
+
+// beginProjectTxs begins a transaction in each of the given projects, in key
+// order like storage.AcquireLocks, so commands changing the same projects
+// don't deadlock. The returned rollback ends the transactions not committed,
+// and can be deferred right away.
+func beginProjectTxs(ctx context.Context, operation string, metadata map[string]interface{}, projectKeys ...string) (map[string]*storage.ProjectTx, func(), error) {
+	keys := slices.Clone(projectKeys)
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+
+	txs := make(map[string]*storage.ProjectTx, len(keys))
+	rollback := func() {
+		for i := len(keys) - 1; i >= 0; i-- {
+			if tx := txs[keys[i]]; tx != nil {
+				tx.Rollback()
+			}
+		}
+	}
+	for _, key := range keys {
+		tx, err := storage.BeginProjectTx(ctx, key, operation, metadata)
+		if err != nil {
+			rollback()
+			return nil, nil, fmt.Errorf("cli: failed to begin transaction in project %q: %w", key, err)
+		}
+		txs[key] = tx
+	}
+	return txs, rollback, nil
+}
+
+// writeFilesTx applies writes in order through the transaction of the project
+// each file is in. If one fails, rolling back the transactions restores the
+// files already written.
+func writeFilesTx(ctx context.Context, txs map[string]*storage.ProjectTx, writes []fileWrite) error {
+	for _, w := range writes {
+		projectKey, _ := projectFileKey(ctx, w.path)
+		tx := txs[projectKey]
+		if tx == nil {
+			return fmt.Errorf("cli: %s is not in a project of the transaction", w.path)
+		}
+		var err error
+		if w.index != nil {
+			err = tx.WriteIndex(w.path, w.index)
+		} else if w.data == nil {
+			if err = checkProjectWritable(ctx, projectKey); err == nil {
+				err = tx.Remove(w.path)
+			}
+		} else {
+			err = tx.Write(w.path, w.data)
+		}
+		if err != nil {
+			return fmt.Errorf("cli: failed to write %s: %w", w.path, err)
+		}
+	}
+	return nil
+}
+
+// referenceRewrite plans updates to issues (in any project) whose dependencies
+// or typed links reference issues that are getting new IDs.
+type referenceRewrite struct {
+	rename    func(id string) string // Returns the new ID of an issue, or id unchanged
+	locked    map[string]bool        // Projects already locked by the caller
+	exclude   map[string]bool        // Projects the caller rewrites itself
+	skip      map[string]bool        // Issues the caller rewrites itself
+	redirects bool                   // Also rewrite redirect targets
+	now       string
+}
+
+// plan returns the files to rewrite and locks the projects they belong to.
+// The returned unlock function must be called even if an error is returned.
+func (rw *referenceRewrite) plan(ctx context.Context) ([]fileWrite, func(), error) {
+	var cleanups []func()
+	unlock := func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}
+
+	keys, err := storage.ListProjects(ctx)
+	if err != nil {
+		return nil, unlock, fmt.Errorf("cli: failed to list projects: %w", err)
+	}
+
+	var writes []fileWrite
+	for _, key := range keys {
+		if rw.exclude[key] {
+			continue
+		}
+		projectWrites, err := rw.planProject(ctx, key)
+		if err != nil {
+			return nil, unlock, err
+		}
+		if len(projectWrites) == 0 {
+			continue
+		}
+
+		if !rw.locked[key] {
+			cleanup, err := storage.AcquireLock(ctx, key)
+			if err != nil {
+				return nil, unlock, fmt.Errorf("cli: failed to acquire project lock for %q: %w", key, err)
+			}
+			cleanups = append(cleanups, cleanup)
+		}
+		writes = append(writes, projectWrites...)
+	}
+	return writes, unlock, nil
+}
+
+// planProject returns the rewritten files of one project.
+func (rw *referenceRewrite) planProject(ctx context.Context, key string) ([]fileWrite, error) {
+	var writes []fileWrite
+
+	issuesDir, err := storage.IssuesDir(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve issues directory: %w", err)
+	}
+	for _, id := range jsonFileIDs(issuesDir) {
+		if rw.skip[id] {
+			continue
+		}
+		path := filepath.Join(issuesDir, id+".json")
+		original, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("cli: failed to read %s: %w", path, err)
+		}
+		var issue models.Issue
+		if err := json.Unmarshal(original, &issue); err != nil {
+			continue
+		}
+		if !issue.RenameLinks(rw.rename) {
+			continue
+		}
+		issue.UpdatedAt = rw.now
+		data, err := json.MarshalIndent(&issue, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("cli: failed to marshal issue %s: %w", issue.ID, err)
+		}
+		writes = append(writes, fileWrite{path: path, data: data, original: original})
+	}
+
+	if !rw.redirects {
+		return writes, nil
+	}
+	redirectsPath, err := storage.RedirectsPath(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve redirects path: %w", err)
+	}
+	original, err := os.ReadFile(redirectsPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return writes, nil
+		}
+		return nil, fmt.Errorf("cli: failed to read %s: %w", redirectsPath, err)
+	}
+	var redirects map[string]string
+	if err := json.Unmarshal(original, &redirects); err != nil {
+		return writes, nil
+	}
+	changed := false
+	for oldID, newID := range redirects {
+		if renamed := rw.rename(newID); renamed != newID {
+			redirects[oldID] = renamed
+			changed = true
+		}
+	}
+	if changed {
+		data, err := json.MarshalIndent(redirects, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("cli: failed to marshal redirects: %w", err)
+		}
+		writes = append(writes, fileWrite{path: redirectsPath, data: data, original: original})
+	}
+
+	return writes, nil
+}
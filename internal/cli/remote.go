@@ -0,0 +1,590 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// defaultServeAddr is where buyruk serve listens by default: this machine only.
+const defaultServeAddr = "127.0.0.1:7420"
+
+// remoteTokenEnv names the variable holding the token shared by a server and
+// its clients. It is never written to disk by buyruk.
+const remoteTokenEnv = "BUYRUK_TOKEN"
+
+// maxPushSize limits the size of a pushed project.
+const maxPushSize = 64 << 20
+
+// remoteTimeout is how long a request of clone, pull, and push may take.
+const remoteTimeout = 30 * time.Second
+
+// NewServeCmd creates and returns the serve command.
+func NewServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve projects to other machines over HTTP",
+		Long: `Serve the local projects over HTTP so that other machines can clone them and
+pull and push changes, for a small team sharing one copy of a project.
+
+  GET /projects         List the project keys
+  GET /projects/KEY     Export a project (JSON, with an ETag version)
+  PUT /projects/KEY     Replace a project (requires If-Match with the ETag
+                        of the last pull, or * to force)
+  DELETE /projects/KEY  Delete a project (requires If-Match, like PUT)
+
+The server listens on 127.0.0.1 by default; use --addr :7420 to accept other
+machines. If --token or $BUYRUK_TOKEN is set, clients must send the same token
+(they read $BUYRUK_TOKEN). Traffic is not encrypted, so only serve on trusted
+networks or behind a TLS proxy.`,
+		Example: `  buyruk serve
+  BUYRUK_TOKEN=s3cret buyruk serve --addr :7420 --read-only`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return serveProjects(cmd)
+		},
+	}
+
+	cmd.Flags().String("addr", defaultServeAddr, "Address to listen on")
+	cmd.Flags().String("token", "", "Token clients must send (default: $BUYRUK_TOKEN)")
+	cmd.Flags().Bool("read-only", false, "Reject pushes")
+
+	return cmd
+}
+
+// NewCloneCmd creates and returns the clone command.
+func NewCloneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clone <url|key>",
+		Short: "Copy a project from a buyruk server, S3 bucket, or WebDAV share",
+		Long: `Copy a project into local storage from a machine running buyruk serve, an S3
+bucket, or a WebDAV share. The URL is remembered, so buyruk pull and buyruk
+push work against it later. With a default remote set (buyruk config set
+default_remote URL), a project key is enough: it is cloned from URL/KEY.
+
+  http://host:7420/projects/CORE   a buyruk server ($BUYRUK_TOKEN)
+  s3://bucket/buyruk/CORE          the object buyruk/CORE.json ($AWS_ACCESS_KEY_ID,
+                                   $AWS_SECRET_ACCESS_KEY, $AWS_REGION, and
+                                   $AWS_ENDPOINT_URL for S3-compatible services)
+  webdav://host/dav/CORE           the file /dav/CORE.json over HTTPS
+                                   ($BUYRUK_WEBDAV_USER, $BUYRUK_WEBDAV_PASSWORD;
+                                   webdav+http:// without TLS)`,
+		Example: `  buyruk clone http://tracker.local:7420/projects/CORE
+  buyruk clone s3://team-bucket/buyruk/CORE
+  buyruk config set default_remote webdav://files.example.com/dav/buyruk && buyruk clone CORE`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cloneProject(args[0], cmd)
+		},
+	}
+
+	cmd.Flags().Bool("overwrite", false, "Replace the project if it already exists locally")
+
+	return cmd
+}
+
+// NewPullCmd creates and returns the pull command.
+func NewPullCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pull",
+		Short: "Fetch changes from the project's remote",
+		Long: `Fetch the project from the remote it was cloned from, or from under the
+default remote if it has none. Issues and epics that differ from the local
+copy, and those deleted on the remote since the last pull or push, are staged
+for sync review, where each change can be accepted or rejected; with
+--overwrite the local project data is replaced instead. Until every staged
+change is accepted or rejected, push refuses to replace the remote copy.`,
+		Example: `  buyruk pull --project CORE
+  buyruk sync review --project CORE`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return pullProject(cmd)
+		},
+	}
+
+	cmd.Flags().Bool("overwrite", false, "Replace the local project data instead of staging changes for review")
+
+	return cmd
+}
+
+// NewPushCmd creates and returns the push command.
+func NewPushCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "push",
+		Short: "Send the project to its remote",
+		Long: `Replace the project on the remote it was cloned from, or under the default
+remote if it has none, with the local copy. The write is conditional on the
+remote's version (an ETag): it is refused if the remote copy changed since the
+last pull or push, so other people's changes are not lost. Pull and review
+them first (push also waits for staged changes to be reviewed), or use
+--force to replace them anyway. A project pushed to the
+default remote for the first time must not exist there yet.`,
+		Example: `  buyruk push --project CORE`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return pushProject(cmd)
+		},
+	}
+
+	cmd.Flags().Bool("force", false, "Push even if the remote copy changed since the last pull")
+
+	return cmd
+}
+
+// serveProjects runs the project server until interrupted.
+func serveProjects(cmd *cobra.Command) error {
+	if state := runStateFrom(cmd.Context()); state != nil && state.backend != nil {
+		return invalidf("cli: serve shares projects of a data directory, not of the storage backend (pass --data-dir, or unset storage_backend)")
+	}
+	addr, _ := cmd.Flags().GetString("addr")
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = os.Getenv(remoteTokenEnv)
+	}
+	readOnly, _ := cmd.Flags().GetBool("read-only")
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           newServeHandler(cmd, token, readOnly),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Serving projects on http://%s (Ctrl+C to stop)\n", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("cli: failed to serve: %w", err)
+	}
+	return nil
+}
+
+// newServeHandler returns the HTTP handler of buyruk serve. Pushes are
+// serialized, so that checking the version and replacing the project happen
+// together, and reads wait for a push in progress.
+func newServeHandler(cmd *cobra.Command, token string, readOnly bool) http.Handler {
+	var pushMu sync.RWMutex
+	errOut := cmd.ErrOrStderr()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /projects", func(w http.ResponseWriter, r *http.Request) {
+		keys, err := storage.ListProjects(cmd.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(keys)
+	})
+	mux.HandleFunc("GET /projects/{key}", func(w http.ResponseWriter, r *http.Request) {
+		pushMu.RLock()
+		defer pushMu.RUnlock()
+		data, etag, err := loadServedProject(cmd.Context(), r.PathValue("key"), errOut)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(data)
+	})
+	mux.HandleFunc("PUT /projects/{key}", func(w http.ResponseWriter, r *http.Request) {
+		if readOnly {
+			http.Error(w, "server is read-only", http.StatusForbidden)
+			return
+		}
+		key := r.PathValue("key")
+		var data ExportData
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPushSize)).Decode(&data); err != nil {
+			http.Error(w, "invalid project data: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := upgradeExportData(&data); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := validateExportData(&data); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if data.Project.ProjectKey != key {
+			http.Error(w, fmt.Sprintf("project key %q does not match the URL", data.Project.ProjectKey), http.StatusBadRequest)
+			return
+		}
+
+		pushMu.Lock()
+		defer pushMu.Unlock()
+		if _, current, err := loadServedProject(cmd.Context(), key, io.Discard); err == nil {
+			if match := r.Header.Get("If-Match"); match != "*" && match != current {
+				http.Error(w, "project changed since the last pull", http.StatusPreconditionFailed)
+				return
+			}
+		}
+		// The push runs in the server's storage (--data-dir and all) and
+		// stops with the request
+		ctx, cancel := context.WithCancel(cmd.Context())
+		defer cancel()
+		defer context.AfterFunc(r.Context(), cancel)()
+		pushCmd := &cobra.Command{}
+		pushCmd.SetContext(ctx)
+		pushCmd.SetOut(io.Discard)
+		pushCmd.SetErr(errOut)
+		if err := importExportData(&data, true, pushCmd); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, etag, err := loadServedProject(cmd.Context(), key, errOut)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(errOut, "Project %q pushed from %s\n", key, r.RemoteAddr)
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("DELETE /projects/{key}", func(w http.ResponseWriter, r *http.Request) {
+		if readOnly {
+			http.Error(w, "server is read-only", http.StatusForbidden)
+			return
+		}
+		key := r.PathValue("key")
+		pushMu.Lock()
+		defer pushMu.Unlock()
+		_, current, err := loadServedProject(cmd.Context(), key, io.Discard)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if match := r.Header.Get("If-Match"); match != "*" && match != current {
+			http.Error(w, "project changed since the last pull", http.StatusPreconditionFailed)
+			return
+		}
+		deleteCmd := &cobra.Command{}
+		deleteCmd.Flags().Bool("yes", true, "")
+		deleteCmd.SetContext(cmd.Context())
+		deleteCmd.SetOut(io.Discard)
+		deleteCmd.SetErr(errOut)
+		if err := deleteProject(key, deleteCmd); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(errOut, "Project %q deleted from %s\n", key, r.RemoteAddr)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+			http.Error(w, "missing or wrong token", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// loadServedProject loads a project for serving, with its ETag: a hash of its
+// content, so it only changes when the project does.
+func loadServedProject(ctx context.Context, projectKey string, errOut io.Writer) (*ExportData, string, error) {
+	data, err := loadExportData(ctx, projectKey, errOut)
+	if err != nil {
+		return nil, "", err
+	}
+	exportedAt := data.ExportedAt
+	data.ExportedAt = ""
+	content, err := json.Marshal(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("cli: failed to marshal project: %w", err)
+	}
+	data.ExportedAt = exportedAt
+	sum := sha256.Sum256(content)
+	return data, `"` + hex.EncodeToString(sum[:8]) + `"`, nil
+}
+
+// cloneProject copies a project from a remote and remembers it for pull and
+// push. A bare project key is cloned from the default remote.
+func cloneProject(rawURL string, cmd *cobra.Command) error {
+	rawURL = strings.TrimSuffix(rawURL, "/")
+	if !strings.Contains(rawURL, "://") {
+		remote := defaultRemote(rawURL)
+		if remote == nil {
+			return invalidf("cli: %q is not a URL (set a default remote with 'buyruk config set default_remote URL' to clone by project key)", rawURL)
+		}
+		rawURL = remote.URL
+	}
+	remote := &models.Remote{URL: rawURL}
+	if err := remote.Validate(); err != nil {
+		return invalidf("cli: %w", err)
+	}
+	projectKey, err := remoteProjectKey(remote.URL)
+	if err != nil {
+		return err
+	}
+
+	data, etag, err := fetchRemote(cmd.Context(), remote)
+	if err != nil {
+		return err
+	}
+	if data.Project.ProjectKey != projectKey {
+		return fmt.Errorf("cli: remote returned project %q instead of %q", data.Project.ProjectKey, projectKey)
+	}
+	overwrite, _ := cmd.Flags().GetBool("overwrite")
+	if err := importExportData(data, overwrite, cmd); err != nil {
+		return err
+	}
+	if err := saveRemote(cmd.Context(), projectKey, remote, etag, exportRecordIDs(data)); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(successOut(cmd), "Cloned project %q from %s\n", projectKey, remote.URL)
+	return nil
+}
+
+// pullProject fetches a project from its remote and stages or applies the
+// changes. While staged changes await review, the pulled version is kept
+// aside: push still checks against the version before it, so the remote
+// changes cannot be replaced unreviewed.
+func pullProject(cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	remote, err := loadRemote(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	data, etag, err := fetchRemote(cmd.Context(), remote)
+	if err != nil {
+		return err
+	}
+	if data.Project.ProjectKey != projectKey {
+		return fmt.Errorf("cli: remote returned project %q instead of %q", data.Project.ProjectKey, projectKey)
+	}
+
+	out := successOut(cmd)
+	if overwrite, _ := cmd.Flags().GetBool("overwrite"); overwrite {
+		if err := importExportData(data, true, cmd); err != nil {
+			return err
+		}
+		return saveRemote(cmd.Context(), projectKey, remote, etag, exportRecordIDs(data))
+	}
+
+	staged, err := stageIncoming(projectKey, data, remote.Records, cmd)
+	if err != nil {
+		return err
+	}
+	pending, err := loadIncoming(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		if err := saveRemote(cmd.Context(), projectKey, remote, etag, exportRecordIDs(data)); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Already up to date with %s\n", remote.URL)
+		return nil
+	}
+
+	remote.Pending = &models.RemoteVersion{ETag: etag, Records: exportRecordIDs(data), FetchedAt: storage.Timestamp(cmd.Context())}
+	if err := writeRemote(cmd.Context(), projectKey, remote); err != nil {
+		return err
+	}
+	if staged == 0 {
+		fmt.Fprintf(out, "No new changes from %s; %d changes still await review (run 'buyruk sync review --project %s')\n", remote.URL, len(pending), projectKey)
+		return nil
+	}
+	fmt.Fprintf(out, "Staged %d changes for review (run 'buyruk sync review --project %s')\n", staged, projectKey)
+	return nil
+}
+
+// pushProject replaces the project on its remote with the local copy.
+func pushProject(cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	remote, err := loadRemote(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	data, err := loadExportData(cmd.Context(), projectKey, cmd.ErrOrStderr())
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("cli: failed to marshal project: %w", err)
+	}
+
+	store, err := newRemoteStore(cmd.Context(), remote.URL)
+	if err != nil {
+		return err
+	}
+	match := remote.ETag
+	if force, _ := cmd.Flags().GetBool("force"); force {
+		match = "*"
+	} else {
+		pending, err := loadIncoming(cmd.Context(), projectKey)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return conflictf("cli: project %q has %d incoming changes awaiting review (run 'buyruk sync review --project %s' first, or push --force)", projectKey, len(pending), projectKey)
+		}
+	}
+	etag, err := store.Put(body, match)
+	if errors.Is(err, errRemoteChanged) {
+		if match == "" {
+			return conflictf("cli: project %q already exists at %s (run 'buyruk pull' first, or push --force)", projectKey, remote.URL)
+		}
+		return conflictf("cli: project %q changed on the remote since the last pull (run 'buyruk pull' first, or push --force)", projectKey)
+	}
+	if err != nil {
+		return err
+	}
+	if err := saveRemote(cmd.Context(), projectKey, remote, etag, exportRecordIDs(data)); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(successOut(cmd), "Pushed project %q to %s (%d issues, %d epics)\n",
+		projectKey, remote.URL, len(data.Issues), len(data.Epics))
+	return nil
+}
+
+// fetchRemote downloads a project from its remote, returning it with its ETag.
+func fetchRemote(ctx context.Context, remote *models.Remote) (*ExportData, string, error) {
+	store, err := newRemoteStore(ctx, remote.URL)
+	if err != nil {
+		return nil, "", err
+	}
+	content, etag, err := store.Get("")
+	if errors.Is(err, errRemoteMissing) {
+		return nil, "", notFoundf("cli: no project at %s", remote.URL)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := parseRemoteProject(content)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, etag, nil
+}
+
+// parseRemoteProject parses, upgrades, and validates a project downloaded
+// from a remote.
+func parseRemoteProject(content []byte) (*ExportData, error) {
+	var data ExportData
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, fmt.Errorf("cli: failed to parse remote project: %w", err)
+	}
+	if err := upgradeExportData(&data); err != nil {
+		return nil, invalidf("cli: invalid remote project: %w", err)
+	}
+	if err := validateExportData(&data); err != nil {
+		return nil, invalidf("cli: invalid remote project: %w", err)
+	}
+	return &data, nil
+}
+
+// remoteError turns an unexpected response into an error with the server's message.
+func remoteError(resp *http.Response) error {
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("cli: remote returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+}
+
+// loadRemote loads the remote of a project. A project that was never pulled
+// or pushed uses its copy under the default remote, if one is set.
+func loadRemote(ctx context.Context, projectKey string) (*models.Remote, error) {
+	remotePath, err := storage.RemotePath(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve remote path: %w", err)
+	}
+	var remote models.Remote
+	if err := storage.ReadJSON(ctx, remotePath, &remote); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			if remote := defaultRemote(projectKey); remote != nil {
+				return remote, nil
+			}
+			return nil, fmt.Errorf("cli: project %q has no remote (get it with 'buyruk clone <url>', or set one for all projects with 'buyruk config set default_remote URL')", projectKey)
+		}
+		return nil, fmt.Errorf("cli: failed to load remote: %w", err)
+	}
+	return &remote, nil
+}
+
+// saveRemote records the remote of a project and the version last synced
+// with it, with the IDs of the issues and epics that version has. A pulled
+// version awaiting review is superseded.
+func saveRemote(ctx context.Context, projectKey string, remote *models.Remote, etag string, records []string) error {
+	remote.ETag = etag
+	remote.Records = records
+	remote.SyncedAt = storage.Timestamp(ctx)
+	remote.Pending = nil
+	return writeRemote(ctx, projectKey, remote)
+}
+
+// writeRemote writes the remote of a project.
+func writeRemote(ctx context.Context, projectKey string, remote *models.Remote) error {
+	remotePath, err := storage.RemotePath(ctx, projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve remote path: %w", err)
+	}
+	if err := storage.WriteJSONAtomic(ctx, remotePath, remote); err != nil {
+		return fmt.Errorf("cli: failed to save remote: %w", err)
+	}
+	return nil
+}
+
+// plannedPendingRemoteWrite plans to make the pulled version of a project's
+// remote that awaits review its synced version. Returns nil if no pulled
+// version awaits review.
+func plannedPendingRemoteWrite(ctx context.Context, projectKey string) (*fileWrite, error) {
+	remotePath, err := storage.RemotePath(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve remote path: %w", err)
+	}
+	var remote models.Remote
+	if err := storage.ReadJSON(ctx, remotePath, &remote); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cli: failed to load remote: %w", err)
+	}
+	if remote.Pending == nil {
+		return nil, nil
+	}
+	remote.ETag = remote.Pending.ETag
+	remote.Records = remote.Pending.Records
+	remote.SyncedAt = remote.Pending.FetchedAt
+	remote.Pending = nil
+	remoteWrite, err := plannedJSONWrite(remotePath, &remote)
+	if err != nil {
+		return nil, err
+	}
+	return &remoteWrite, nil
+}
@@ -0,0 +1,311 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestRemoteClonePullPush(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	serve := func(token string, readOnly bool) *httptest.Server {
+		cmd := NewServeCmd()
+		cmd.SetContext(t.Context())
+		cmd.SetErr(new(bytes.Buffer))
+		server := httptest.NewServer(newServeHandler(cmd, token, readOnly))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "Login page"},
+		{"issue", "create", "--project", projectKey, "--title", "Token refresh"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	// The server and this client share the same storage, so the clone
+	// replaces the project with the copy it just served
+	server := serve("", false)
+	projectURL := server.URL + "/projects/" + projectKey
+	if _, err := run("clone", server.URL+"/issues/"+projectKey); err == nil {
+		t.Error("Expected a URL without /projects/ to fail")
+	}
+	if _, err := run("clone", projectURL); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected clone into an existing project to fail, got %v", err)
+	}
+	out, err := run("clone", projectURL, "--overwrite")
+	if err != nil || !strings.Contains(out, "Cloned project") {
+		t.Fatalf("clone failed: %v: %s", err, out)
+	}
+	remote, err := loadRemote(t.Context(), projectKey)
+	if err != nil || remote.URL != projectURL || remote.ETag == "" {
+		t.Fatalf("Expected the remote to be saved, got %+v, %v", remote, err)
+	}
+	if index, _ := loadQueryIndex(t.Context(), projectKey); len(index.Issues) != 2 {
+		t.Errorf("Expected 2 cloned issues, got %d", len(index.Issues))
+	}
+
+	if out, err := run("push", "--project", projectKey); err != nil || !strings.Contains(out, "Pushed project") {
+		t.Fatalf("push failed: %v: %s", err, out)
+	}
+
+	// A change on the server after the last sync blocks the push
+	saved, _ := loadRemote(t.Context(), projectKey)
+	if _, err := run("issue", "create", "--project", projectKey, "--title", "Audit log"); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if _, err := run("push", "--project", projectKey); err == nil || !strings.Contains(err.Error(), "changed on the remote") {
+		t.Errorf("Expected a stale push to be refused, got %v", err)
+	}
+	if out, err := run("pull", "--project", projectKey); err != nil || !strings.Contains(out, "Already up to date") {
+		t.Fatalf("pull failed: %v: %s", err, out)
+	}
+	if remote, _ := loadRemote(t.Context(), projectKey); remote.ETag == saved.ETag {
+		t.Error("Expected pull to record the new remote version")
+	}
+	if _, err := run("push", "--project", projectKey); err != nil {
+		t.Errorf("Expected push after pull to succeed, got %v", err)
+	}
+
+	// Tokens and read-only servers
+	server = serve("s3cret", true)
+	resp, err := http.Get(server.URL + "/projects")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a request without the token to be refused, got %s", resp.Status)
+	}
+	t.Setenv(remoteTokenEnv, "s3cret")
+	if _, err := run("clone", server.URL+"/projects/"+projectKey, "--overwrite"); err != nil {
+		t.Fatalf("clone with token failed: %v", err)
+	}
+	if _, err := run("push", "--project", projectKey); err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("Expected a push to a read-only server to fail, got %v", err)
+	}
+}
+
+func TestRemotePushToDataDir(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "Login page"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	// A server with its own data directory, like serve --data-dir
+	serverCtx := storage.WithEnv(t.Context(), storage.Env{DataDir: t.TempDir()})
+	cmd := NewServeCmd()
+	cmd.SetContext(serverCtx)
+	cmd.SetErr(new(bytes.Buffer))
+	server := httptest.NewServer(newServeHandler(cmd, "", false))
+	defer server.Close()
+
+	if err := saveRemote(t.Context(), projectKey, &models.Remote{URL: server.URL + "/projects/" + projectKey}, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := run("push", "--project", projectKey); err != nil || !strings.Contains(out, "Pushed project") {
+		t.Fatalf("push failed: %v: %s", err, out)
+	}
+	index, err := loadQueryIndex(serverCtx, projectKey)
+	if err != nil || len(index.Issues) != 1 || index.Issues[0].Title != "Login page" {
+		t.Errorf("Expected the pushed project in the server's data directory, got %+v (%v)", index, err)
+	}
+}
+
+func TestRemotePullReview(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	runContext := func(ctx context.Context, args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.ExecuteContext(ctx)
+		return out.String(), err
+	}
+	run := func(args ...string) (string, error) {
+		return runContext(t.Context(), args...)
+	}
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "Login page"},
+		{"issue", "create", "--project", projectKey, "--title", "Token refresh"},
+		{"epic", "create", "--project", projectKey, "--id", projectKey + "-E1", "--title", "Auth"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	// A server with its own data directory, so the two copies can change
+	// independently
+	serverCtx := storage.WithEnv(t.Context(), storage.Env{DataDir: t.TempDir()})
+	cmd := NewServeCmd()
+	cmd.SetContext(serverCtx)
+	cmd.SetErr(new(bytes.Buffer))
+	server := httptest.NewServer(newServeHandler(cmd, "", false))
+	defer server.Close()
+	if err := saveRemote(t.Context(), projectKey, &models.Remote{URL: server.URL + "/projects/" + projectKey}, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run("push", "--project", projectKey); err != nil {
+		t.Fatalf("push failed: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"issue", "update", projectKey + "-1", "--title", "Server edit"},
+		{"issue", "delete", projectKey + "-2", "--yes"},
+		{"epic", "update", projectKey + "-E1", "--title", "Authentication"},
+	} {
+		if _, err := runContext(serverCtx, args...); err != nil {
+			t.Fatalf("%v on the server failed: %v", args, err)
+		}
+	}
+	saved, _ := loadRemote(t.Context(), projectKey)
+
+	// Pull stages the edit, the deletion, and the epic change
+	out, err := run("pull", "--project", projectKey)
+	if err != nil || !strings.Contains(out, "Staged 3 changes") {
+		t.Fatalf("Expected the edit, the deletion, and the epic change staged, got %v: %s", err, out)
+	}
+	out, err = run("sync", "review", "--project", projectKey, "--list")
+	if err != nil {
+		t.Fatalf("sync review --list failed: %v", err)
+	}
+	for _, want := range []string{"title: Login page → Server edit", projectKey + "-2", "(deleted)", "(changed epic)", "title: Auth → Authentication"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in review list, got: %s", want, out)
+		}
+	}
+	if remote, _ := loadRemote(t.Context(), projectKey); remote.ETag != saved.ETag || remote.Pending == nil {
+		t.Errorf("Expected pull to keep the pulled version aside until review, got %+v", remote)
+	}
+
+	// Pushing now would replace the server's edits nobody has reviewed
+	if _, err := run("push", "--project", projectKey); err == nil || !strings.Contains(err.Error(), "awaiting review") {
+		t.Fatalf("Expected push to wait for the review, got %v", err)
+	}
+	if index, _ := loadQueryIndex(serverCtx, projectKey); index.FindIssue(projectKey+"-1").Title != "Server edit" {
+		t.Fatal("Expected the server's edit to survive the refused push")
+	}
+
+	if _, err := run("sync", "review", "--project", projectKey, "--accept", "all"); err != nil {
+		t.Fatalf("sync review --accept failed: %v", err)
+	}
+	if issue, err := loadLocalIssue(t.Context(), projectKey, projectKey+"-2"); err != nil || issue != nil {
+		t.Errorf("Expected the accepted deletion to remove the local issue, got %+v (%v)", issue, err)
+	}
+	if epic, _ := loadLocalEpic(t.Context(), projectKey, projectKey+"-E1"); epic == nil || epic.Title != "Authentication" {
+		t.Errorf("Expected the accepted epic change to apply, got %+v", epic)
+	}
+	if remote, _ := loadRemote(t.Context(), projectKey); remote.ETag == saved.ETag || remote.Pending != nil {
+		t.Errorf("Expected the reviewed version to become the remote version, got %+v", remote)
+	}
+	if _, err := run("push", "--project", projectKey); err != nil {
+		t.Errorf("Expected push after the review to succeed, got %v", err)
+	}
+
+	// An issue added here since the last push is not a deletion on the remote
+	if _, err := run("issue", "create", "--project", projectKey, "--title", "Audit log"); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if out, err := run("pull", "--project", projectKey); err != nil || !strings.Contains(out, "Already up to date") {
+		t.Errorf("Expected nothing staged, got %v: %s", err, out)
+	}
+}
+
+func TestRemoteServeStore(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"project", "create", projectKey})
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("project create failed: %v", err)
+	}
+	serveCmd := NewServeCmd()
+	serveCmd.SetContext(t.Context())
+	serveCmd.SetErr(new(bytes.Buffer))
+	server := httptest.NewServer(newServeHandler(serveCmd, "", false))
+	defer server.Close()
+
+	keys, err := listRemoteProjects(t.Context(), server.URL+"/projects")
+	if err != nil || !slices.Contains(keys, projectKey) {
+		t.Fatalf("listRemoteProjects = %v, %v; want %s", keys, err, projectKey)
+	}
+	store, err := newRemoteStore(t.Context(), server.URL+"/projects/"+projectKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, etag, err := store.Get("")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if _, _, err := store.Get(etag); err != errRemoteUnchanged {
+		t.Errorf("Expected Get of the known version to report it unchanged, got %v", err)
+	}
+	if err := store.Delete(`"stale"`); err != errRemoteChanged {
+		t.Errorf("Expected a stale delete to fail, got %v", err)
+	}
+	if err := store.Delete(etag); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, _, err := store.Get(""); err != errRemoteMissing {
+		t.Errorf("Expected the project to be deleted, got %v", err)
+	}
+}
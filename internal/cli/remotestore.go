@@ -0,0 +1,476 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+)
+
+// Environment variables holding the credentials of WebDAV remotes. Like
+// $BUYRUK_TOKEN, they are never written to disk by buyruk.
+const (
+	webdavUserEnv     = "BUYRUK_WEBDAV_USER"
+	webdavPasswordEnv = "BUYRUK_WEBDAV_PASSWORD"
+)
+
+// errRemoteChanged is returned by remoteStore.Put when the remote copy is not
+// at the version the write was based on.
+var errRemoteChanged = errors.New("cli: remote copy changed")
+
+// errRemoteMissing is returned by remoteStore.Get when there is no remote copy.
+var errRemoteMissing = errors.New("cli: remote copy not found")
+
+// errRemoteUnchanged is returned by remoteStore.Get when the remote copy is
+// still at the version already known.
+var errRemoteUnchanged = errors.New("cli: remote copy unchanged")
+
+// remoteStore reads and replaces the shared copy of one project. Writes are
+// conditional on the version read or written last, so two machines pushing at
+// once cannot overwrite each other's changes without holding a lock.
+type remoteStore interface {
+	// Get returns the exported project and its version (an ETag), or
+	// errRemoteUnchanged if it is still at version known ("" to always
+	// download it).
+	Get(known string) (data []byte, etag string, err error)
+	// Put replaces the exported project if the remote copy is still at
+	// version match ("*" for any version, "" for no copy yet), and returns
+	// the new version.
+	Put(data []byte, match string) (etag string, err error)
+	// Delete removes the remote copy if it is still at version match. A copy
+	// that is already gone is not an error.
+	Delete(match string) error
+}
+
+// newRemoteStore returns the store of a remote URL:
+//
+//	http://host:7420/projects/CORE   buyruk serve
+//	s3://bucket/buyruk/CORE          object buyruk/CORE.json of an S3 bucket
+//	webdav://host/dav/CORE           file /dav/CORE.json of a WebDAV share
+//	                                 (webdav+http:// without TLS)
+func newRemoteStore(ctx context.Context, rawURL string) (remoteStore, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, invalidf("cli: invalid remote URL %q: %w", rawURL, err)
+	}
+	client := remoteHTTPClient(ctx)
+	switch u.Scheme {
+	case "http", "https":
+		return &httpStore{url: rawURL, client: client, authorize: authorizeServer}, nil
+	case "webdav", "webdav+http":
+		file := *u
+		file.Scheme = "https"
+		if u.Scheme == "webdav+http" {
+			file.Scheme = "http"
+		}
+		file.Path += ".json"
+		file.RawPath = ""
+		return &httpStore{url: file.String(), client: client, authorize: authorizeWebDAV}, nil
+	case "s3":
+		return newS3Store(u, client), nil
+	default:
+		return nil, fmt.Errorf("cli: unsupported remote URL %q", rawURL)
+	}
+}
+
+// remoteHTTPClient returns the HTTP client of remote requests.
+func remoteHTTPClient(ctx context.Context) *http.Client {
+	if client := hostEnvFrom(ctx).remoteClient; client != nil {
+		return client
+	}
+	return &http.Client{Timeout: remoteTimeout}
+}
+
+// listRemoteProjects returns the keys of the projects under a base URL like
+// default_remote and storage_backend take: the project list of a buyruk
+// server, the .json files of a WebDAV directory (PROPFIND), or the .json
+// objects of an S3 prefix (ListObjectsV2).
+func listRemoteProjects(ctx context.Context, baseURL string) ([]string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, invalidf("cli: invalid remote URL %q: %w", baseURL, err)
+	}
+	client := remoteHTTPClient(ctx)
+	switch u.Scheme {
+	case "http", "https":
+		lister := &httpStore{url: baseURL, client: client, authorize: authorizeServer}
+		resp, err := lister.do(http.MethodGet, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, remoteError(resp)
+		}
+		var keys []string
+		if err := json.NewDecoder(resp.Body).Decode(&keys); err != nil {
+			return nil, fmt.Errorf("cli: failed to parse remote project list: %w", err)
+		}
+		return keys, nil
+	case "webdav", "webdav+http":
+		dir := *u
+		dir.Scheme = "https"
+		if u.Scheme == "webdav+http" {
+			dir.Scheme = "http"
+		}
+		dir.Path = strings.TrimSuffix(u.Path, "/") + "/"
+		dir.RawPath = ""
+		return listWebDAVProjects(&httpStore{url: dir.String(), client: client, authorize: authorizeWebDAV})
+	case "s3":
+		return listS3Projects(u, client)
+	default:
+		return nil, fmt.Errorf("cli: unsupported remote URL %q", baseURL)
+	}
+}
+
+// listWebDAVProjects lists the .json files of a WebDAV directory. A missing
+// directory has no projects.
+func listWebDAVProjects(dir *httpStore) ([]string, error) {
+	header := http.Header{}
+	header.Set("Depth", "1")
+	header.Set("Content-Type", "application/xml")
+	resp, err := dir.do("PROPFIND", header, []byte(`<?xml version="1.0"?><propfind xmlns="DAV:"><prop><resourcetype/></prop></propfind>`))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusMultiStatus:
+	case http.StatusNotFound:
+		return []string{}, nil
+	default:
+		return nil, remoteError(resp)
+	}
+	var listing struct {
+		Responses []struct {
+			Href string `xml:"href"`
+		} `xml:"response"`
+	}
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, maxPushSize)).Decode(&listing); err != nil {
+		return nil, fmt.Errorf("cli: failed to parse WebDAV listing: %w", err)
+	}
+	keys := []string{}
+	for _, response := range listing.Responses {
+		href, err := url.Parse(response.Href)
+		if err != nil {
+			continue
+		}
+		if projectKey, ok := strings.CutSuffix(path.Base(href.Path), ".json"); ok && projectKey != "" {
+			keys = append(keys, projectKey)
+		}
+	}
+	return keys, nil
+}
+
+// listS3Projects lists the .json objects directly under the prefix of an
+// s3://bucket/prefix URL, page by page.
+func listS3Projects(u *url.URL, client *http.Client) ([]string, error) {
+	prefix := strings.Trim(u.Path, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	bucketURL, authorize := s3Bucket(u.Host)
+	keys := []string{}
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}, "delimiter": {"/"}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		list, err := url.Parse(bucketURL)
+		if err != nil {
+			return nil, fmt.Errorf("cli: invalid S3 endpoint: %w", err)
+		}
+		if list.Path == "" {
+			list.Path = "/"
+		}
+		// Signature Version 4 needs spaces encoded as %20
+		list.RawQuery = strings.ReplaceAll(query.Encode(), "+", "%20")
+		lister := &httpStore{url: list.String(), client: client, authorize: authorize}
+		resp, err := lister.do(http.MethodGet, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Contents []struct {
+				Key string `xml:"Key"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			err = remoteError(resp)
+		} else if err = xml.NewDecoder(io.LimitReader(resp.Body, maxPushSize)).Decode(&page); err != nil {
+			err = fmt.Errorf("cli: failed to parse S3 listing: %w", err)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range page.Contents {
+			name := strings.TrimPrefix(object.Key, prefix)
+			if projectKey, ok := strings.CutSuffix(name, ".json"); ok && projectKey != "" && !strings.Contains(projectKey, "/") {
+				keys = append(keys, projectKey)
+			}
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return keys, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// remoteProjectKey returns the key of the project a remote URL points to: its
+// last path element, which on a buyruk server must follow /projects/.
+func remoteProjectKey(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", invalidf("cli: invalid remote URL %q: %w", rawURL, err)
+	}
+	dir, projectKey := path.Split(u.Path)
+	if projectKey == "" || ((u.Scheme == "http" || u.Scheme == "https") && path.Base(dir) != "projects") {
+		return "", invalidf("cli: invalid remote URL %q (expected e.g. http://host:7420/projects/CORE or s3://bucket/buyruk/CORE)", rawURL)
+	}
+	return projectKey, nil
+}
+
+// defaultRemote returns the remote of a project under the base URL set with
+// config set default_remote, or nil when none is set. It only gives clone,
+// pull, and push a URL to use: projects are still read from and written to
+// the local data directory, and only sync with it when those commands run
+// (storage_backend keeps them in the remote instead, see backend.go).
+func defaultRemote(projectKey string) *models.Remote {
+	cfg, err := config.Get()
+	if err != nil || cfg.DefaultRemote == "" {
+		return nil
+	}
+	return &models.Remote{URL: cfg.DefaultRemote + "/" + projectKey}
+}
+
+// httpStore is a remote copy read with GET and replaced with a conditional
+// PUT, which buyruk servers, WebDAV shares, and S3 all support.
+type httpStore struct {
+	url       string
+	client    *http.Client
+	authorize func(req *http.Request, body []byte) error
+}
+
+// Get downloads the remote copy, with If-None-Match if a version is known.
+func (s *httpStore) Get(known string) ([]byte, string, error) {
+	header := http.Header{}
+	if known != "" {
+		header.Set("If-None-Match", known)
+	}
+	resp, err := s.do(http.MethodGet, header, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, "", errRemoteUnchanged
+	case http.StatusNotFound:
+		return nil, "", errRemoteMissing
+	default:
+		return nil, "", remoteError(resp)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPushSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("cli: failed to read remote project: %w", err)
+	}
+	if len(data) > maxPushSize {
+		return nil, "", fmt.Errorf("cli: remote project is larger than %s", formatSize(maxPushSize))
+	}
+	return data, resp.Header.Get("ETag"), nil
+}
+
+// Put uploads the project with If-Match, or If-None-Match: * for a new copy.
+func (s *httpStore) Put(data []byte, match string) (string, error) {
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	if match == "" {
+		header.Set("If-None-Match", "*")
+	} else {
+		header.Set("If-Match", match)
+	}
+	resp, err := s.do(http.MethodPut, header, data)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+	case http.StatusPreconditionFailed, http.StatusConflict:
+		// S3 answers 409 when another conditional write is in progress
+		return "", errRemoteChanged
+	default:
+		return "", remoteError(resp)
+	}
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		return etag, nil
+	}
+
+	// Some WebDAV servers only report the new version on request
+	head, err := s.do(http.MethodHead, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	head.Body.Close()
+	return head.Header.Get("ETag"), nil
+}
+
+// Delete removes the remote copy with If-Match.
+func (s *httpStore) Delete(match string) error {
+	header := http.Header{}
+	header.Set("If-Match", match)
+	resp, err := s.do(http.MethodDelete, header, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusAccepted, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	case http.StatusPreconditionFailed, http.StatusConflict:
+		return errRemoteChanged
+	default:
+		return remoteError(resp)
+	}
+}
+
+// do sends an authorized request for the remote copy.
+func (s *httpStore) do(method string, header http.Header, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, s.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to create request: %w", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if err := s.authorize(req, body); err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to reach remote: %w", err)
+	}
+	return resp, nil
+}
+
+// authorizeServer sends the token shared with a buyruk server, if set.
+func authorizeServer(req *http.Request, body []byte) error {
+	if token := os.Getenv(remoteTokenEnv); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return nil
+}
+
+// authorizeWebDAV sends the WebDAV credentials from the environment, if set.
+func authorizeWebDAV(req *http.Request, body []byte) error {
+	if user := os.Getenv(webdavUserEnv); user != "" {
+		req.SetBasicAuth(user, os.Getenv(webdavPasswordEnv))
+	}
+	return nil
+}
+
+// newS3Store returns the store of an s3://bucket/prefix/KEY URL. It follows
+// the AWS CLI's environment: AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY,
+// AWS_SESSION_TOKEN, AWS_REGION, and AWS_ENDPOINT_URL_S3 or AWS_ENDPOINT_URL
+// for S3-compatible services (addressed path-style).
+func newS3Store(u *url.URL, client *http.Client) *httpStore {
+	bucketURL, authorize := s3Bucket(u.Host)
+	object := strings.Trim(u.Path, "/") + ".json"
+	return &httpStore{url: bucketURL + "/" + object, client: client, authorize: authorize}
+}
+
+// s3Bucket returns the URL of an S3 bucket and the function signing its
+// requests.
+func s3Bucket(bucket string) (string, func(req *http.Request, body []byte) error) {
+	region := firstEnv("AWS_REGION", "AWS_DEFAULT_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+	bucketURL := fmt.Sprintf("https://%s.s3.%s.amazonaws.com", bucket, region)
+	if endpoint := firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"); endpoint != "" {
+		bucketURL = strings.TrimSuffix(endpoint, "/") + "/" + bucket
+	}
+	return bucketURL, func(req *http.Request, body []byte) error {
+		return signS3Request(req, body, region, time.Now())
+	}
+}
+
+// firstEnv returns the first of the environment variables that is set.
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// signS3Request signs a request with AWS Signature Version 4, covering every
+// header set so far.
+func signS3Request(req *http.Request, body []byte, region string, now time.Time) error {
+	accessKey, secretKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey == "" || secretKey == "" {
+		return fmt.Errorf("cli: S3 remotes need AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	payload := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(payload[:])
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if token := os.Getenv("AWS_SESSION_TOKEN"); token != "" {
+		req.Header.Set("X-Amz-Security-Token", token)
+	}
+
+	names := []string{"host"}
+	for name := range req.Header {
+		names = append(names, strings.ToLower(name))
+	}
+	slices.Sort(names)
+	var headers strings.Builder
+	for _, name := range names {
+		value := req.Host
+		if name != "host" {
+			value = strings.TrimSpace(req.Header.Get(name))
+		}
+		fmt.Fprintf(&headers, "%s:%s\n", name, value)
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonicalRequest := strings.Join([]string{
+		req.Method, req.URL.EscapedPath(), req.URL.RawQuery, headers.String(), signedHeaders, payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+	key := []byte("AWS4" + secretKey)
+	for _, part := range []string{date, region, "s3", "aws4_request", stringToSign} {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(part))
+		key = mac.Sum(nil)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, hex.EncodeToString(key)))
+	return nil
+}
@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+// objectServer is a minimal WebDAV/S3-like server: files by path, with ETags,
+// conditional GETs, PUTs, and DELETEs, and directory listings (PROPFIND and
+// ListObjectsV2).
+type objectServer struct {
+	mu       sync.Mutex
+	files    map[string][]byte
+	etags    map[string]string
+	versions int
+	requests []*http.Request
+	// beforeWrite, if set, runs before a PUT or DELETE is checked, like a
+	// write of another machine arriving first.
+	beforeWrite func(path string)
+}
+
+func newObjectServer(t *testing.T) (*objectServer, *httptest.Server) {
+	s := &objectServer{files: map[string][]byte{}, etags: map[string]string{}}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	return s, server
+}
+
+func (s *objectServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r)
+	if (r.Method == http.MethodPut || r.Method == http.MethodDelete) && s.beforeWrite != nil {
+		s.beforeWrite(r.URL.Path)
+	}
+	data, exists := s.files[r.URL.Path]
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		if r.URL.Query().Get("list-type") == "2" {
+			s.listObjects(w, r)
+			return
+		}
+		if !exists {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("ETag", s.etags[r.URL.Path])
+		if r.Header.Get("If-None-Match") == s.etags[r.URL.Path] {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write(data)
+	case http.MethodPut:
+		if match := r.Header.Get("If-Match"); match != "" && match != "*" && (!exists || match != s.etags[r.URL.Path]) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		if r.Header.Get("If-None-Match") == "*" && exists {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		s.set(r.URL.Path, body)
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodDelete:
+		if !exists {
+			http.NotFound(w, r)
+			return
+		}
+		if match := r.Header.Get("If-Match"); match != "" && match != "*" && match != s.etags[r.URL.Path] {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		delete(s.files, r.URL.Path)
+		delete(s.etags, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	case "PROPFIND":
+		var listing strings.Builder
+		listing.WriteString(`<?xml version="1.0"?><D:multistatus xmlns:D="DAV:">`)
+		fmt.Fprintf(&listing, "<D:response><D:href>%s</D:href></D:response>", r.URL.Path)
+		for _, name := range slices.Sorted(maps.Keys(s.files)) {
+			if dir, _ := path.Split(name); dir == r.URL.Path {
+				fmt.Fprintf(&listing, "<D:response><D:href>%s</D:href></D:response>", name)
+			}
+		}
+		listing.WriteString(`</D:multistatus>`)
+		w.WriteHeader(http.StatusMultiStatus)
+		io.WriteString(w, listing.String())
+	}
+}
+
+// listObjects answers an S3 ListObjectsV2 request of the bucket in the path,
+// one object per page.
+func (s *objectServer) listObjects(w http.ResponseWriter, r *http.Request) {
+	bucket := strings.TrimSuffix(r.URL.Path, "/")
+	prefix := bucket + "/" + r.URL.Query().Get("prefix")
+	var keys []string
+	for _, name := range slices.Sorted(maps.Keys(s.files)) {
+		if strings.HasPrefix(name, prefix) && name > r.URL.Query().Get("continuation-token") {
+			keys = append(keys, name)
+		}
+	}
+	fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><ListBucketResult>`)
+	if len(keys) > 0 {
+		fmt.Fprintf(w, "<Contents><Key>%s</Key></Contents>", strings.TrimPrefix(keys[0], bucket+"/"))
+	}
+	if len(keys) > 1 {
+		fmt.Fprintf(w, "<IsTruncated>true</IsTruncated><NextContinuationToken>%s</NextContinuationToken>", keys[0])
+	}
+	fmt.Fprint(w, `</ListBucketResult>`)
+}
+
+// set stores a file as a new version.
+func (s *objectServer) set(path string, data []byte) {
+	s.versions++
+	s.files[path] = data
+	s.etags[path] = fmt.Sprintf(`"v%d"`, s.versions)
+}
+
+func TestDefaultRemoteWebDAV(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+	defer os.RemoveAll(projectDir)
+	originalCfg, _ := config.Get()
+	defer func() {
+		if originalCfg != nil {
+			config.Save(t.Context(), originalCfg)
+		}
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(append(args, "--no-hooks"))
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "Login page"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	objects, server := newObjectServer(t)
+	t.Setenv(webdavUserEnv, "alice")
+	t.Setenv(webdavPasswordEnv, "s3cret")
+	baseURL := strings.Replace(server.URL, "http://", "webdav+http://", 1) + "/dav/buyruk"
+	if _, err := run("config", "set", "default_remote", baseURL); err != nil {
+		t.Fatalf("config set failed: %v", err)
+	}
+
+	// The first push creates the project's file under the default remote
+	if out, err := run("push", "--project", projectKey); err != nil || !strings.Contains(out, "Pushed project") {
+		t.Fatalf("push failed: %v: %s", err, out)
+	}
+	filePath := "/dav/buyruk/" + projectKey + ".json"
+	if _, ok := objects.files[filePath]; !ok {
+		t.Fatalf("Expected %s on the server, got %v", filePath, objects.files)
+	}
+	if user, password, _ := objects.requests[0].BasicAuth(); user != "alice" || password != "s3cret" {
+		t.Errorf("Expected WebDAV credentials, got %q/%q", user, password)
+	}
+	remote, err := loadRemote(t.Context(), projectKey)
+	if err != nil || remote.URL != baseURL+"/"+projectKey || remote.ETag != objects.etags[filePath] {
+		t.Fatalf("Expected the default remote to be saved, got %+v, %v", remote, err)
+	}
+
+	// Another machine writes a new version: the next push is refused
+	objects.set(filePath, objects.files[filePath])
+	if _, err := run("push", "--project", projectKey); err == nil || !strings.Contains(err.Error(), "changed on the remote") {
+		t.Fatalf("Expected a stale push to be refused, got %v", err)
+	}
+	if out, err := run("pull", "--project", projectKey); err != nil || !strings.Contains(out, "Already up to date") {
+		t.Fatalf("pull failed: %v: %s", err, out)
+	}
+	if _, err := run("push", "--project", projectKey); err != nil {
+		t.Fatalf("push after pull failed: %v", err)
+	}
+
+	// A project key is enough to clone from the default remote
+	os.RemoveAll(projectDir)
+	if out, err := run("clone", projectKey); err != nil || !strings.Contains(out, "Cloned project") {
+		t.Fatalf("clone by key failed: %v: %s", err, out)
+	}
+	if index, _ := loadQueryIndex(t.Context(), projectKey); index == nil || len(index.Issues) != 1 {
+		t.Errorf("Expected the cloned project to have 1 issue, got %+v", index)
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	objects, server := newObjectServer(t)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_REGION", "eu-central-1")
+	t.Setenv("AWS_ENDPOINT_URL", server.URL)
+
+	store, err := newRemoteStore(t.Context(), "s3://team-bucket/buyruk/CORE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	etag, err := store.Put([]byte(`{"v": 1}`), "")
+	if err != nil || etag == "" {
+		t.Fatalf("Put failed: %q, %v", etag, err)
+	}
+	if _, err := store.Put([]byte(`{"v": 2}`), ""); err != errRemoteChanged {
+		t.Errorf("Expected creating an existing object to fail, got %v", err)
+	}
+	if _, err := store.Put([]byte(`{"v": 2}`), `"stale"`); err != errRemoteChanged {
+		t.Errorf("Expected a stale write to fail, got %v", err)
+	}
+	if _, err := store.Put([]byte(`{"v": 2}`), etag); err != nil {
+		t.Errorf("Put with the current ETag failed: %v", err)
+	}
+	data, _, err := store.Get("")
+	if err != nil || string(data) != `{"v": 2}` {
+		t.Errorf("Get = %s, %v", data, err)
+	}
+
+	req := objects.requests[0]
+	if req.URL.Path != "/team-bucket/buyruk/CORE.json" {
+		t.Errorf("Expected a path-style object URL, got %s", req.URL.Path)
+	}
+	auth := req.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") || !strings.Contains(auth, "/eu-central-1/s3/aws4_request") ||
+		!strings.Contains(auth, "SignedHeaders=content-type;host;if-none-match;x-amz-content-sha256;x-amz-date,") {
+		t.Errorf("Unexpected Authorization header: %s", auth)
+	}
+
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	if _, _, err := store.Get(""); err == nil || !strings.Contains(err.Error(), "AWS_SECRET_ACCESS_KEY") {
+		t.Errorf("Expected missing credentials to fail, got %v", err)
+	}
+}
+
+func TestRemoteProjectKey(t *testing.T) {
+	for rawURL, want := range map[string]string{
+		"http://host:7420/projects/CORE":  "CORE",
+		"s3://bucket/buyruk/CORE":         "CORE",
+		"webdav://host/dav/CORE":          "CORE",
+		"http://host:7420/issues/CORE":    "",
+		"webdav+http://host/dav/buyruk/":  "",
+		"s3://bucket/team/buyruk/WEB-APP": "WEB-APP",
+	} {
+		got, err := remoteProjectKey(rawURL)
+		if got != want || (want == "") != (err != nil) {
+			t.Errorf("remoteProjectKey(%q) = %q, %v; want %q", rawURL, got, err, want)
+		}
+	}
+}
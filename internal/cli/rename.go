@@ -0,0 +1,431 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// NewProjectRenameCmd creates and returns the project rename command.
+func NewProjectRenameCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rename <old-key> <new-key>",
+		Short: "Rename a project key",
+		Long: `Rename a project key and migrate all its data.
+
+Every issue ID (OLD-n becomes NEW-n), index entry, blocked_by reference, subtask
+link, and epic ID prefixed with the old key is rewritten. Dependencies on the
+renamed issues from other projects are updated too, and the default project is
+updated if it was the old key.
+
+The renamed project is built next to the old one and swapped in, so a failure
+at any point leaves the old project untouched.`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeProjectArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return renameProject(args[0], args[1], cmd)
+		},
+	}
+
+	cmd.Flags().String("name", "", "New project name (defaults to the new key if the name was the old key)")
+
+	return cmd
+}
+
+// projectRename rewrites IDs from an old project key to a new one.
+type projectRename struct {
+	oldKey string
+	newKey string
+	name   string // New project name; empty keeps the current name
+	now    string
+	issues map[string]string // Old issue ID -> new issue ID
+	epics  map[string]string // Old epic ID -> new epic ID (only epics prefixed with the old key)
+}
+
+// issueID returns the new ID of an issue of the renamed project.
+// IDs of other projects are returned unchanged.
+func (r *projectRename) issueID(id string) string {
+	if newID, ok := r.issues[id]; ok {
+		return newID
+	}
+	// References to issues that no longer exist still follow the key
+	if models.IsIssueIDOf(id, r.oldKey) {
+		return r.newKey + strings.TrimPrefix(id, r.oldKey)
+	}
+	return id
+}
+
+// epicID returns the new ID of an epic of the renamed project.
+func (r *projectRename) epicID(id string) string {
+	if newID, ok := r.epics[id]; ok {
+		return newID
+	}
+	return id
+}
+
+// renameProject renames a project key and rewrites every reference to its issues.
+func renameProject(oldKey, newKey string, cmd *cobra.Command) error {
+	for _, key := range []string{oldKey, newKey} {
+		if !isValidProjectKey(key) {
+			return invalidf("cli: invalid project key %q (must contain only uppercase letters, numbers, and hyphens)", key)
+		}
+	}
+	if oldKey == newKey {
+		return invalidf("cli: new key must differ from %q", oldKey)
+	}
+
+	oldDir, err := storage.ProjectDir(cmd.Context(), oldKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve project directory: %w", err)
+	}
+	newDir, err := storage.ProjectDir(cmd.Context(), newKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve project directory: %w", err)
+	}
+	if _, err := os.Stat(oldDir); err != nil {
+		if os.IsNotExist(err) {
+			return notFoundf("cli: project %q does not exist", oldKey)
+		}
+		return fmt.Errorf("cli: failed to access project directory %q: %w", oldDir, err)
+	}
+	if _, err := os.Stat(newDir); err == nil {
+		return conflictf("cli: project %q already exists", newKey)
+	}
+	oldIndex, err := loadQueryIndex(cmd.Context(), oldKey)
+	if err != nil {
+		return err
+	}
+	if err := checkProjectKeyScheme(cmd.Context(), newKey, oldIndex.IDScheme); err != nil {
+		return err
+	}
+	if err := checkProjectWritable(cmd.Context(), oldKey); err != nil {
+		return err
+	}
+
+	hasPending, _, err := storage.CheckPendingTransaction(cmd.Context(), oldKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to check pending transaction: %w", err)
+	}
+	if hasPending {
+		return fmt.Errorf("cli: project %q has a pending transaction (may indicate a crash); run 'buyruk project repair %s' first", oldKey, oldKey)
+	}
+
+	cleanup, err := storage.AcquireLock(cmd.Context(), oldKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to acquire project lock for %q: %w", oldKey, err)
+	}
+	// Once swapped, the lock file leaves with the old directory
+	swapped := false
+	defer func() {
+		if !swapped {
+			cleanup()
+		}
+	}()
+
+	r, err := newProjectRename(cmd.Context(), oldKey, newKey)
+	if err != nil {
+		return err
+	}
+	r.name, _ = cmd.Flags().GetString("name")
+
+	// Plan updates to other projects before touching anything
+	refs := &referenceRewrite{
+		rename:    r.issueID,
+		exclude:   map[string]bool{oldKey: true, newKey: true},
+		redirects: true,
+		now:       r.now,
+	}
+	external, unlock, err := refs.plan(cmd.Context())
+	defer unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := storage.BeginTransaction(cmd.Context(), oldKey, "rename_project", map[string]interface{}{
+		"from": oldKey,
+		"to":   newKey,
+	}); err != nil {
+		return fmt.Errorf("cli: failed to begin rename transaction: %w", err)
+	}
+
+	projectsDir := filepath.Dir(oldDir)
+	stageDir := filepath.Join(projectsDir, "."+newKey+".renaming")
+	backupDir := filepath.Join(projectsDir, "."+oldKey+".renamed")
+
+	// Build the renamed project next to the old one
+	os.RemoveAll(stageDir)
+	if err := r.stage(cmd.Context(), oldDir, stageDir); err != nil {
+		os.RemoveAll(stageDir)
+		storage.RollbackTransaction(cmd.Context(), oldKey)
+		return err
+	}
+
+	// Swap directories; each step is undone if a later one fails
+	if err := os.Rename(oldDir, backupDir); err != nil {
+		os.RemoveAll(stageDir)
+		storage.RollbackTransaction(cmd.Context(), oldKey)
+		return fmt.Errorf("cli: failed to move project %q aside: %w", oldKey, err)
+	}
+	if err := os.Rename(stageDir, newDir); err != nil {
+		os.Rename(backupDir, oldDir)
+		os.RemoveAll(stageDir)
+		storage.RollbackTransaction(cmd.Context(), oldKey)
+		return fmt.Errorf("cli: failed to create project %q: %w", newKey, err)
+	}
+	if err := writeFiles(cmd.Context(), external); err != nil {
+		os.Rename(newDir, stageDir)
+		os.Rename(backupDir, oldDir)
+		os.RemoveAll(stageDir)
+		storage.RollbackTransaction(cmd.Context(), oldKey)
+		return err
+	}
+	swapped = true
+
+	// The old directory also holds the lock file and the transaction log
+	errOut := cmd.ErrOrStderr()
+	if err := os.RemoveAll(backupDir); err != nil {
+		fmt.Fprintf(errOut, "Warning: failed to remove old project data %s: %v\n", backupDir, err)
+	}
+
+	if cfg, err := config.Get(); err == nil && cfg.DefaultProject == oldKey {
+		if err := config.Set(cmd.Context(), "default_project", newKey); err != nil {
+			fmt.Fprintf(errOut, "Warning: failed to update default project: %v\n", err)
+		}
+	}
+	if err := renameHooks(oldKey, newKey); err != nil {
+		fmt.Fprintf(errOut, "Warning: failed to move the project's hooks: %v\n", err)
+	}
+
+	out := successOut(cmd)
+	fmt.Fprintf(out, "Renamed project %q to %q (%d issues)\n", oldKey, newKey, len(r.issues))
+	for _, oldID := range slices.Sorted(maps.Keys(r.epics)) {
+		fmt.Fprintf(out, "  %s -> %s\n", oldID, r.epics[oldID])
+	}
+	if len(external) > 0 {
+		fmt.Fprintf(out, "Updated %d references in other projects\n", len(external))
+	}
+	return nil
+}
+
+// newProjectRename builds the ID mappings from the issues and epics of the old project.
+func newProjectRename(ctx context.Context, oldKey, newKey string) (*projectRename, error) {
+	r := &projectRename{
+		oldKey: oldKey,
+		newKey: newKey,
+		now:    storage.Timestamp(ctx),
+		issues: map[string]string{},
+		epics:  map[string]string{},
+	}
+
+	issuesDir, err := storage.IssuesDir(ctx, oldKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve issues directory: %w", err)
+	}
+	for _, id := range jsonFileIDs(issuesDir) {
+		if models.IsIssueIDOf(id, oldKey) {
+			r.issues[id] = newKey + strings.TrimPrefix(id, oldKey)
+		}
+	}
+
+	epicsDir, err := storage.EpicsDir(ctx, oldKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve epics directory: %w", err)
+	}
+	for _, id := range jsonFileIDs(epicsDir) {
+		if rest, ok := strings.CutPrefix(id, oldKey+"-"); ok {
+			r.epics[id] = newKey + "-" + rest
+		}
+	}
+
+	return r, nil
+}
+
+// jsonFileIDs returns the names of the .json files in dir without their extension.
+func jsonFileIDs(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var ids []string
+	for _, entry := range entries {
+		if id, ok := strings.CutSuffix(entry.Name(), ".json"); ok && !entry.IsDir() {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// stage writes a renamed copy of the project in oldDir to stageDir.
+// Known files are rewritten; other files are copied unchanged.
+func (r *projectRename) stage(ctx context.Context, oldDir, stageDir string) error {
+	return filepath.WalkDir(oldDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("cli: failed to read %s: %w", path, err)
+		}
+		rel, err := filepath.Rel(oldDir, path)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve %s: %w", path, err)
+		}
+		// Shards name the old IDs; the index is staged whole and sharded again on its next write
+		if d.IsDir() && rel == storage.IndexShardsDirName {
+			return filepath.SkipDir
+		}
+		if d.IsDir() {
+			if filepath.Dir(rel) == "attachments" {
+				return nil // Created under the new issue ID with its files
+			}
+			return os.MkdirAll(filepath.Join(stageDir, rel), 0755)
+		}
+		// Lock, transaction log, and leftovers of interrupted writes stay behind
+		name := d.Name()
+		if name == ".buyruk.lock" || name == ".buyruk_pending" || strings.HasSuffix(name, ".tmp") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("cli: failed to read %s: %w", path, err)
+		}
+
+		target := rel
+		dir := filepath.Dir(rel)
+		switch {
+		case rel == "project.json":
+			// ReadIndex puts back the entries of a sharded index
+			var index models.ProjectIndex
+			if err = storage.ReadIndex(ctx, path, &index); err == nil {
+				r.renameIndex(&index)
+				data, err = json.MarshalIndent(&index, "", "  ")
+			}
+		case rel == "roadmap.json":
+			data, err = r.rewriteJSON(data, &models.Roadmap{}, r.renameRoadmap)
+		case rel == "sprints.json":
+			data, err = r.rewriteJSON(data, &models.Sprints{}, r.renameSprints)
+		case rel == "redirects.json":
+			data, err = r.rewriteJSON(data, &map[string]string{}, r.renameRedirects)
+		case dir == "issues" && strings.HasSuffix(name, ".json"):
+			var issue models.Issue
+			data, err = r.rewriteJSON(data, &issue, r.renameIssue)
+			target = filepath.Join(dir, issue.ID+".json")
+		case filepath.Dir(dir) == "attachments":
+			// Attached files are kept unchanged under the new issue ID
+			target = filepath.Join("attachments", r.issueID(filepath.Base(dir)), name)
+			err = os.MkdirAll(filepath.Join(stageDir, filepath.Dir(target)), 0755)
+		case dir == "epics" && strings.HasSuffix(name, ".json"):
+			var epic models.Epic
+			data, err = r.rewriteJSON(data, &epic, r.renameEpic)
+			target = filepath.Join(dir, epic.ID+".json")
+		}
+		if err != nil {
+			return fmt.Errorf("cli: failed to rewrite %s: %w", rel, err)
+		}
+
+		if err := os.WriteFile(filepath.Join(stageDir, target), data, 0644); err != nil {
+			return fmt.Errorf("cli: failed to write %s: %w", target, err)
+		}
+		return nil
+	})
+}
+
+// rewriteJSON decodes data into v, renames it, and encodes it again.
+func (r *projectRename) rewriteJSON(data []byte, v interface{}, rename func(v interface{})) ([]byte, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+	rename(v)
+	return json.MarshalIndent(v, "", "  ")
+}
+
+func (r *projectRename) renameIndex(v interface{}) {
+	index := v.(*models.ProjectIndex)
+	index.ProjectKey = r.newKey
+	switch {
+	case r.name != "":
+		index.ProjectName = r.name
+	case index.ProjectName == r.oldKey || index.ProjectName == "":
+		index.ProjectName = r.newKey
+	}
+	for i := range index.Issues {
+		entry := &index.Issues[i]
+		entry.ID = r.issueID(entry.ID)
+		entry.EpicID = r.epicID(entry.EpicID)
+		if entry.ParentID != "" {
+			entry.ParentID = r.issueID(entry.ParentID)
+		}
+	}
+	index.UpdatedAt = r.now
+}
+
+func (r *projectRename) renameIssue(v interface{}) {
+	issue := v.(*models.Issue)
+	issue.ID = r.issueID(issue.ID)
+	issue.RenameLinks(r.issueID)
+	issue.EpicID = r.epicID(issue.EpicID)
+	if issue.ParentID != "" {
+		issue.ParentID = r.issueID(issue.ParentID)
+	}
+	issue.UpdatedAt = r.now
+}
+
+func (r *projectRename) renameEpic(v interface{}) {
+	epic := v.(*models.Epic)
+	if newID := r.epicID(epic.ID); newID != epic.ID {
+		epic.ID = newID
+		epic.UpdatedAt = r.now
+	}
+}
+
+func (r *projectRename) renameRoadmap(v interface{}) {
+	roadmap := v.(*models.Roadmap)
+	for i := range roadmap.Items {
+		roadmap.Items[i].EpicID = r.epicID(roadmap.Items[i].EpicID)
+	}
+}
+
+func (r *projectRename) renameSprints(v interface{}) {
+	sprints := v.(*models.Sprints)
+	for i := range sprints.Sprints {
+		for j, issueID := range sprints.Sprints[i].Issues {
+			sprints.Sprints[i].Issues[j] = r.issueID(issueID)
+		}
+	}
+}
+
+// renameRedirects renames both the moved IDs and their targets.
+func (r *projectRename) renameRedirects(v interface{}) {
+	redirects := v.(*map[string]string)
+	renamed := make(map[string]string, len(*redirects))
+	for oldID, newID := range *redirects {
+		renamed[r.issueID(oldID)] = r.issueID(newID)
+	}
+	*redirects = renamed
+}
+
+// renameHooks moves the hooks of a project, kept in the config directory, to
+// its new key.
+func renameHooks(oldKey, newKey string) error {
+	oldPath, err := storage.HooksPath(oldKey)
+	if err != nil {
+		return err
+	}
+	newPath, err := storage.HooksPath(newKey)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(oldPath, newPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestRenameProject(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	newKey := projectKey + "-NEW"
+	otherKey := projectKey + "-OTHER"
+	defer func() {
+		for _, key := range []string{projectKey, newKey, otherKey} {
+			projectDir, _ := storage.ProjectDir(t.Context(), key)
+			os.RemoveAll(projectDir)
+		}
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		buf := new(bytes.Buffer)
+		cmd.SetOut(buf)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return buf.String(), err
+	}
+
+	steps := [][]string{
+		{"project", "create", projectKey},
+		{"project", "create", otherKey},
+		{"epic", "create", "--project", projectKey, "--title", "Prefixed", "--id", projectKey + "-E1"},
+		{"issue", "create", "--project", projectKey, "--title", "Backend", "--epic", projectKey + "-E1"},
+		{"issue", "create", "--project", projectKey, "--title", "Screen", "--epic", "E-2"},
+		{"issue", "create", "--project", projectKey, "--title", "Button", "--parent", projectKey + "-2"},
+		{"issue", "create", "--project", otherKey, "--title", "Release"},
+		{"issue", "link", projectKey + "-2", projectKey + "-1"},
+		{"issue", "link", otherKey + "-1", projectKey + "-2"},
+		{"roadmap", "add", projectKey + "-E1", "--project", projectKey, "--quarter", "2024-Q3"},
+	}
+	for i, args := range steps {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+		if i == 2 {
+			writeLegacyEpic(t, projectKey, "E-2", "Plain")
+		}
+	}
+
+	output, err := run("project", "rename", projectKey, newKey)
+	if err != nil {
+		t.Fatalf("project rename failed: %v", err)
+	}
+	if !strings.Contains(output, "(3 issues)") || !strings.Contains(output, projectKey+"-E1 -> "+newKey+"-E1") {
+		t.Errorf("Expected issue count and epic mapping in output, got: %s", output)
+	}
+
+	oldDir, _ := storage.ProjectDir(t.Context(), projectKey)
+	if _, err := os.Stat(oldDir); !os.IsNotExist(err) {
+		t.Errorf("Old project directory should be gone")
+	}
+	keys, _ := storage.ListProjects(t.Context())
+	for _, key := range keys {
+		if strings.HasPrefix(key, ".") {
+			t.Errorf("ListProjects() returned hidden directory %q", key)
+		}
+	}
+
+	readIssue := func(key, id string) *models.Issue {
+		t.Helper()
+		path, _ := storage.IssuePath(t.Context(), key, id)
+		var issue models.Issue
+		if err := storage.ReadJSON(t.Context(), path, &issue); err != nil {
+			t.Fatalf("Failed to read issue %s: %v", id, err)
+		}
+		return &issue
+	}
+
+	backend := readIssue(newKey, newKey+"-1")
+	if backend.EpicID != newKey+"-E1" {
+		t.Errorf("EpicID = %q, want %q", backend.EpicID, newKey+"-E1")
+	}
+	screen := readIssue(newKey, newKey+"-2")
+	if screen.EpicID != "E-2" {
+		t.Errorf("Unprefixed EpicID = %q, want unchanged", screen.EpicID)
+	}
+	if !slices.Equal(screen.BlockedBy, []string{newKey + "-1"}) {
+		t.Errorf("BlockedBy = %v, want [%s-1]", screen.BlockedBy, newKey)
+	}
+	if child := readIssue(newKey, newKey+"-3"); child.ParentID != newKey+"-2" {
+		t.Errorf("ParentID = %q, want %q", child.ParentID, newKey+"-2")
+	}
+	if other := readIssue(otherKey, otherKey+"-1"); !slices.Equal(other.BlockedBy, []string{newKey + "-2"}) {
+		t.Errorf("Other project BlockedBy = %v, want [%s-2]", other.BlockedBy, newKey)
+	}
+
+	epicPath, _ := storage.EpicPath(t.Context(), newKey, newKey+"-E1")
+	if _, err := os.Stat(epicPath); err != nil {
+		t.Errorf("Renamed epic file missing: %v", err)
+	}
+
+	indexPath, _ := storage.ProjectIndexPath(t.Context(), newKey)
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(t.Context(), indexPath, &index); err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	if index.ProjectKey != newKey || index.ProjectName != newKey {
+		t.Errorf("Index key/name = %q/%q, want %q", index.ProjectKey, index.ProjectName, newKey)
+	}
+	if entry := index.FindIssue(newKey + "-3"); entry == nil || entry.ParentID != newKey+"-2" {
+		t.Errorf("Index entry for subtask not renamed: %+v", entry)
+	}
+
+	roadmapPath, _ := storage.RoadmapPath(t.Context(), newKey)
+	var roadmap models.Roadmap
+	if err := storage.ReadJSON(t.Context(), roadmapPath, &roadmap); err != nil {
+		t.Fatalf("Failed to read roadmap: %v", err)
+	}
+	if len(roadmap.Items) != 1 || roadmap.Items[0].EpicID != newKey+"-E1" {
+		t.Errorf("Roadmap items not renamed: %+v", roadmap.Items)
+	}
+
+	if hasPending, _, _ := storage.CheckPendingTransaction(t.Context(), newKey); hasPending {
+		t.Error("Renamed project should not have a pending transaction")
+	}
+}
+
+func TestRenameProject_Errors(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	otherKey := projectKey + "-OTHER"
+	defer func() {
+		for _, key := range []string{projectKey, otherKey} {
+			projectDir, _ := storage.ProjectDir(t.Context(), key)
+			os.RemoveAll(projectDir)
+		}
+	}()
+
+	for _, key := range []string{projectKey, otherKey} {
+		createCmd := NewProjectCreateCmd()
+		createCmd.SetOut(new(bytes.Buffer))
+		if err := createProject(key, createCmd); err != nil {
+			t.Fatalf("Failed to create project: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		oldKey string
+		newKey string
+		errMsg string
+	}{
+		{"same key", projectKey, projectKey, "must differ"},
+		{"target exists", projectKey, otherKey, "already exists"},
+		{"missing", projectKey + "-MISSING", projectKey + "-X", "does not exist"},
+		{"invalid key", projectKey, "lower", "invalid project key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewProjectRenameCmd()
+			cmd.SetOut(new(bytes.Buffer))
+			err := renameProject(tt.oldKey, tt.newKey, cmd)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,366 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// chartWidth is the width of the longest bar of report charts.
+const chartWidth = 30
+
+// Burndown is the number of issues of a sprint left to do at the end of each
+// day, as parallel arrays.
+type Burndown struct {
+	Sprint    string    `json:"sprint"`
+	Start     string    `json:"start"`
+	End       string    `json:"end"`
+	Total     int       `json:"total"`
+	Days      []string  `json:"days"`      // Days of the sprint up to today
+	Remaining []int     `json:"remaining"` // Issues not done at the end of each day
+	Ideal     []float64 `json:"ideal"`     // Remaining issues at a steady pace
+}
+
+// Velocity is the number of issues completed in each sprint, as parallel
+// arrays.
+type Velocity struct {
+	Project   string   `json:"project"`
+	Sprints   []string `json:"sprints"`
+	Committed []int    `json:"committed"` // Issues planned into each sprint
+	Completed []int    `json:"completed"` // Issues done during each sprint
+	Average   float64  `json:"average"`   // Average completed per finished sprint
+}
+
+// NewReportCmd creates and returns the report command.
+func NewReportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Show sprint reports",
+		Long: `Show reports over the sprints of a project (see buyruk sprint).
+
+Reports come from the issues' status history, so issues closed before history
+was recorded count as done from their last update.`,
+	}
+
+	cmd.AddCommand(NewReportBurndownCmd())
+	cmd.AddCommand(NewReportVelocityCmd())
+
+	return cmd
+}
+
+// NewReportBurndownCmd creates and returns the report burndown command.
+func NewReportBurndownCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "burndown",
+		Short: "Show the issues left in a sprint day by day",
+		Long: `Show the number of issues of a sprint that were not done at the end of each
+day, up to today, next to the ideal steady pace. Modern format draws a chart;
+--format json gives the days and counts as arrays.`,
+		Example: "  buyruk report burndown --sprint S-3",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return showBurndown(cmd)
+		},
+	}
+
+	cmd.Flags().String("sprint", "", "Sprint ID (required)")
+	cmd.RegisterFlagCompletionFunc("sprint", completeSprintIDs)
+
+	return cmd
+}
+
+// showBurndown computes and prints the burndown of a sprint.
+func showBurndown(cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	sprintID, _ := cmd.Flags().GetString("sprint")
+	if sprintID == "" {
+		return invalidf("cli: --sprint is required")
+	}
+	sprints, err := loadSprints(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	sprint := sprints.Find(sprintID)
+	if sprint == nil {
+		return notFoundf("cli: sprint %q not found", sprintID)
+	}
+	wf, err := loadWorkflow(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+
+	issues := loadSprintIssues(cmd.Context(), projectKey, sprint, cmd.ErrOrStderr())
+	burndown := computeBurndown(sprint, issues, wf, storage.Now(cmd.Context()).In(config.Location()))
+
+	out := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		data, err := json.MarshalIndent(burndown, "", "  ")
+		if err != nil {
+			return fmt.Errorf("cli: failed to marshal burndown: %w", err)
+		}
+		fmt.Fprintln(out, string(data))
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(out, burndown)
+	case config.DefaultFormatLSON:
+		fmt.Fprintf(out, "@SPRINT: %s|%s|%s\n", burndown.Sprint, burndown.Start, burndown.End)
+		fmt.Fprintf(out, "@TOTAL: %d\n", burndown.Total)
+		for i, day := range burndown.Days {
+			fmt.Fprintf(out, "@DAY: %s|%d|%g\n", day, burndown.Remaining[i], burndown.Ideal[i])
+		}
+	default:
+		styles := ui.NewStyles()
+		fmt.Fprintf(out, "%s %s to %s, %d issue(s)\n", styles.Title("Burndown of "+burndown.Sprint), burndown.Start, burndown.End, burndown.Total)
+		if len(burndown.Days) == 0 {
+			fmt.Fprintln(out, "The sprint has not started yet")
+			return nil
+		}
+		fmt.Fprintln(out)
+		for i, day := range burndown.Days {
+			date, _ := time.Parse(time.DateOnly, day)
+			fmt.Fprintf(out, "  %s %s %s %d %s\n", date.Format("01-02 Mon"), chartBar(burndown.Remaining[i], burndown.Total),
+				strings.Repeat(" ", chartWidth-barLength(burndown.Remaining[i], burndown.Total)),
+				burndown.Remaining[i], styles.Label(fmt.Sprintf("(ideal %g)", burndown.Ideal[i])))
+		}
+	}
+	return nil
+}
+
+// computeBurndown counts the issues not done at the end of each sprint day up
+// to now.
+func computeBurndown(sprint *models.Sprint, issues []*models.Issue, wf *models.Workflow, now time.Time) *Burndown {
+	burndown := &Burndown{
+		Sprint:    sprint.ID,
+		Start:     sprint.Start,
+		End:       sprint.End,
+		Total:     len(issues),
+		Days:      []string{},
+		Remaining: []int{},
+		Ideal:     []float64{},
+	}
+	days := sprint.Days(now.Location())
+	for i, day := range days {
+		if day.After(now) {
+			break
+		}
+		end := day.AddDate(0, 0, 1)
+		if end.After(now) {
+			end = now
+		}
+		remaining := 0
+		for _, issue := range issues {
+			if !doneAt(issue, wf, end) {
+				remaining++
+			}
+		}
+		ideal := float64(burndown.Total)
+		if len(days) > 1 {
+			ideal -= float64(burndown.Total) * float64(i) / float64(len(days)-1)
+		}
+		burndown.Days = append(burndown.Days, day.Format(time.DateOnly))
+		burndown.Remaining = append(burndown.Remaining, remaining)
+		burndown.Ideal = append(burndown.Ideal, float64(int(ideal*10+0.5))/10)
+	}
+	return burndown
+}
+
+// NewReportVelocityCmd creates and returns the report velocity command.
+func NewReportVelocityCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "velocity",
+		Short: "Show the issues completed in each sprint",
+		Long: `Show the number of issues planned into each started sprint and how many of
+them were done during the sprint, with the average over finished sprints.
+Modern format draws a chart; --format json gives the counts as arrays.`,
+		Example: "  buyruk report velocity --last 6",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return showVelocity(cmd)
+		},
+	}
+
+	cmd.Flags().Int("last", 0, "Only show the last N started sprints (default: all)")
+
+	return cmd
+}
+
+// showVelocity computes and prints the velocity of a project's sprints.
+func showVelocity(cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	last, _ := cmd.Flags().GetInt("last")
+	if last < 0 {
+		return invalidf("cli: --last must not be negative")
+	}
+	sprints, err := loadSprints(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	wf, err := loadWorkflow(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+
+	issues := map[string][]*models.Issue{}
+	for _, sprint := range sprints.Sprints {
+		issues[sprint.ID] = loadSprintIssues(cmd.Context(), projectKey, &sprint, cmd.ErrOrStderr())
+	}
+	velocity := computeVelocity(projectKey, sprints.Sprints, issues, wf, storage.Now(cmd.Context()).In(config.Location()), last)
+
+	out := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		data, err := json.MarshalIndent(velocity, "", "  ")
+		if err != nil {
+			return fmt.Errorf("cli: failed to marshal velocity: %w", err)
+		}
+		fmt.Fprintln(out, string(data))
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(out, velocity)
+	case config.DefaultFormatLSON:
+		for i, sprintID := range velocity.Sprints {
+			fmt.Fprintf(out, "@SPRINT: %s|%d|%d\n", sprintID, velocity.Completed[i], velocity.Committed[i])
+		}
+		fmt.Fprintf(out, "@AVERAGE: %g\n", velocity.Average)
+	default:
+		if len(velocity.Sprints) == 0 {
+			fmt.Fprintf(out, "No started sprints (create one with `buyruk sprint create S-1 --start monday --end 2w`)\n")
+			return nil
+		}
+		styles := ui.NewStyles()
+		fmt.Fprintln(out, styles.Title("Velocity of "+projectKey))
+		fmt.Fprintln(out)
+		most := 0
+		for _, committed := range velocity.Committed {
+			most = max(most, committed)
+		}
+		width := 0
+		for _, sprintID := range velocity.Sprints {
+			width = max(width, len(sprintID))
+		}
+		for i, sprintID := range velocity.Sprints {
+			fmt.Fprintf(out, "  %s%s %s%s %d/%d\n", styles.ID(sprintID), strings.Repeat(" ", width-len(sprintID)),
+				chartBar(velocity.Completed[i], most), strings.Repeat(" ", chartWidth-barLength(velocity.Completed[i], most)),
+				velocity.Completed[i], velocity.Committed[i])
+		}
+		fmt.Fprintf(out, "\n%s %g issues per sprint\n", styles.Label("Average:"), velocity.Average)
+	}
+	return nil
+}
+
+// computeVelocity counts the issues done during each sprint that has started
+// by now, keeping the last ones if last is positive.
+func computeVelocity(projectKey string, sprints []models.Sprint, issues map[string][]*models.Issue, wf *models.Workflow, now time.Time, last int) *Velocity {
+	velocity := &Velocity{Project: projectKey, Sprints: []string{}, Committed: []int{}, Completed: []int{}}
+	var finished []int
+	for _, sprint := range sprints {
+		days := sprint.Days(now.Location())
+		if len(days) == 0 || days[0].After(now) {
+			continue
+		}
+		end := days[len(days)-1].AddDate(0, 0, 1)
+		ended := !end.After(now)
+		if !ended {
+			end = now
+		}
+		completed := 0
+		for _, issue := range issues[sprint.ID] {
+			if doneAt(issue, wf, end) && !doneAt(issue, wf, days[0]) {
+				completed++
+			}
+		}
+		velocity.Sprints = append(velocity.Sprints, sprint.ID)
+		velocity.Committed = append(velocity.Committed, len(issues[sprint.ID]))
+		velocity.Completed = append(velocity.Completed, completed)
+		if ended {
+			finished = append(finished, completed)
+		}
+	}
+
+	if last > 0 && len(velocity.Sprints) > last {
+		cut := len(velocity.Sprints) - last
+		velocity.Sprints = velocity.Sprints[cut:]
+		velocity.Committed = velocity.Committed[cut:]
+		velocity.Completed = velocity.Completed[cut:]
+		finished = finished[max(0, len(finished)-last):]
+	}
+	if len(finished) > 0 {
+		total := 0
+		for _, completed := range finished {
+			total += completed
+		}
+		velocity.Average = float64(int(float64(total)/float64(len(finished))*10+0.5)) / 10
+	}
+	return velocity
+}
+
+// loadSprintIssues loads the issues planned into a sprint, including archived
+// ones. Issues that no longer exist are skipped with a warning.
+func loadSprintIssues(ctx context.Context, projectKey string, sprint *models.Sprint, errOut io.Writer) []*models.Issue {
+	var issues []*models.Issue
+	for _, issueID := range sprint.Issues {
+		issue, err := loadLocalIssue(ctx, projectKey, issueID)
+		if err == nil && issue == nil {
+			issue, _ = loadArchivedIssue(ctx, projectKey, issueID)
+		}
+		if issue == nil {
+			fmt.Fprintf(errOut, "Warning: issue %s of sprint %s not found\n", issueID, sprint.ID)
+			continue
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// doneAt reports whether an issue was in a done status at a time, from its
+// status history. An issue done without recorded history counts as done from
+// its last update.
+func doneAt(issue *models.Issue, wf *models.Workflow, t time.Time) bool {
+	status, recorded := "", false
+	for _, event := range issue.History {
+		if event.To == "" {
+			continue
+		}
+		if !recorded {
+			status, recorded = event.From, true
+		}
+		if at, err := time.Parse(time.RFC3339, event.At); err == nil && !at.After(t) {
+			status = event.To
+		}
+	}
+	if recorded {
+		return wf.IsDoneStatus(status)
+	}
+	if !wf.IsDoneStatus(issue.Status) {
+		return false
+	}
+	updated, err := time.Parse(time.RFC3339, issue.UpdatedAt)
+	return err == nil && !updated.After(t)
+}
+
+// barLength returns the length of a chart bar for value out of most.
+func barLength(value, most int) int {
+	if most == 0 {
+		return 0
+	}
+	return value * chartWidth / most
+}
+
+// chartBar draws a chart bar for value out of most.
+func chartBar(value, most int) string {
+	return strings.Repeat("█", barLength(value, most))
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestReports(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(at string, args ...string) string {
+		t.Helper()
+		cmd := NewRootCmd()
+		cmd.SetArgs(append(args, "--project", projectKey, "--fixed-time", at, "--no-hooks"))
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+		return out.String()
+	}
+
+	run("2024-05-31T09:00:00Z", "project", "create", projectKey)
+	for _, title := range []string{"Login page", "Signup page", "Logout", "Profile"} {
+		run("2024-05-31T09:00:00Z", "issue", "create", "--title", title)
+	}
+	run("2024-05-31T09:00:00Z", "sprint", "create", "S-1", "--start", "2024-06-03", "--end", "2024-06-06")
+	run("2024-05-31T09:00:00Z", "sprint", "create", "S-2", "--start", "2024-06-07", "--end", "2024-06-12")
+	run("2024-05-31T09:00:00Z", "sprint", "add", "S-1", projectKey+"-1", projectKey+"-2", projectKey+"-3")
+	run("2024-05-31T09:00:00Z", "sprint", "add", "S-2", projectKey+"-3", projectKey+"-4")
+
+	run("2024-06-04T10:00:00Z", "issue", "update", projectKey+"-1", "--status", "DONE")
+	run("2024-06-05T10:00:00Z", "issue", "update", projectKey+"-2", "--status", "DONE")
+	run("2024-06-10T10:00:00Z", "issue", "update", projectKey+"-3", "--status", "DONE")
+
+	var burndown Burndown
+	out := run("2024-06-08T12:00:00Z", "report", "burndown", "--sprint", "S-1", "--format", "json")
+	if err := json.Unmarshal([]byte(out), &burndown); err != nil {
+		t.Fatalf("Failed to parse burndown: %v\n%s", err, out)
+	}
+	if burndown.Total != 3 || !slices.Equal(burndown.Remaining, []int{3, 2, 1, 1}) || !slices.Equal(burndown.Ideal, []float64{3, 2, 1, 0}) {
+		t.Errorf("Burndown = %+v, want 3 issues burning down 3, 2, 1, 1", burndown)
+	}
+
+	// A running sprint stops at today
+	out = run("2024-06-08T12:00:00Z", "report", "burndown", "--sprint", "S-2")
+	if !strings.Contains(out, "06-08 Sat") || strings.Contains(out, "06-09") {
+		t.Errorf("Expected the chart to end today, got:\n%s", out)
+	}
+
+	var velocity Velocity
+	out = run("2024-06-13T12:00:00Z", "report", "velocity", "--format", "json")
+	if err := json.Unmarshal([]byte(out), &velocity); err != nil {
+		t.Fatalf("Failed to parse velocity: %v\n%s", err, out)
+	}
+	if !slices.Equal(velocity.Sprints, []string{"S-1", "S-2"}) || !slices.Equal(velocity.Completed, []int{2, 1}) ||
+		!slices.Equal(velocity.Committed, []int{3, 2}) || velocity.Average != 1.5 {
+		t.Errorf("Velocity = %+v, want 2/3 and 1/2 done, 1.5 on average", velocity)
+	}
+	if out := run("2024-06-13T12:00:00Z", "report", "velocity", "--last", "1"); !strings.Contains(out, "S-2") || strings.Contains(out, "S-1") {
+		t.Errorf("Expected only the last sprint, got:\n%s", out)
+	}
+}
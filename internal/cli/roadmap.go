@@ -0,0 +1,434 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// RoadmapEpic is a planned epic with progress rolled up from its issues.
+type RoadmapEpic struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Status string `json:"status,omitempty"` // Empty for planned epics that don't exist yet
+	Done   int    `json:"done"`
+	Total  int    `json:"total"`
+}
+
+// RoadmapQuarter groups the epics planned for one quarter.
+type RoadmapQuarter struct {
+	Quarter string        `json:"quarter"`
+	Epics   []RoadmapEpic `json:"epics"`
+}
+
+// NewRoadmapCmd creates and returns the roadmap command.
+func NewRoadmapCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "roadmap",
+		Short: "Plan epics by quarter, or chart them as a Mermaid gantt",
+		Long: `Plan epics into target quarters and track their progress.
+
+The roadmap is stored in projects/[KEY]/roadmap.json. Epic progress is rolled
+up from the issues linked to each epic.
+
+Without a subcommand, print a Mermaid gantt chart of the project's epics and
+their issues for embedding in docs. Each issue runs from the day it was
+created to its due date, the day it was closed, or today; overdue issues are
+marked critical.`,
+		Example: `  buyruk roadmap --project CORE > docs/roadmap.mmd
+  buyruk roadmap add CORE-E1 --quarter 2024-Q3`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectKey, err := config.ResolveProject(cmd)
+			if err != nil {
+				return err
+			}
+			return showGantt(cmd.Context(), cmd.OutOrStdout(), projectKey)
+		},
+	}
+
+	cmd.AddCommand(NewRoadmapAddCmd())
+	cmd.AddCommand(NewRoadmapRemoveCmd())
+	cmd.AddCommand(NewRoadmapViewCmd())
+	cmd.AddCommand(NewRoadmapExportCmd())
+
+	return cmd
+}
+
+// NewRoadmapAddCmd creates and returns the roadmap add command.
+func NewRoadmapAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <epic-id>",
+		Short: "Plan an epic for a quarter",
+		Long: `Plan an epic for a target quarter, or move it to another quarter.
+
+Epics that don't exist yet can be planned by giving a --title:
+  buyruk roadmap add E-7 --quarter 2024-Q4 --title "Offline sync"`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeEpicArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			epicID := args[0]
+			return addRoadmapItem(epicID, cmd)
+		},
+	}
+
+	cmd.Flags().String("quarter", "", "Target quarter, e.g. 2024-Q3 (required)")
+	cmd.Flags().String("title", "", "Title for a planned epic that doesn't exist yet")
+
+	return cmd
+}
+
+// addRoadmapItem plans an epic for a quarter.
+func addRoadmapItem(epicID string, cmd *cobra.Command) error {
+	projectKey, err := resolveEpicProject(epicID, cmd)
+	if err != nil {
+		return err
+	}
+
+	quarter, _ := cmd.Flags().GetString("quarter")
+	if quarter == "" {
+		return invalidf("cli: --quarter is required")
+	}
+	if _, _, err := models.ParseQuarter(quarter); err != nil {
+		return fmt.Errorf("cli: %w", err)
+	}
+	title, _ := cmd.Flags().GetString("title")
+
+	epicPath, err := storage.EpicPath(cmd.Context(), projectKey, epicID)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve epic path: %w", err)
+	}
+	if _, err := os.Stat(epicPath); os.IsNotExist(err) {
+		if title == "" {
+			return notFoundf("cli: epic %q not found (use --title to plan an epic that doesn't exist yet)", epicID)
+		}
+		// Planned epics are created later with this ID, so it is scoped like theirs
+		if !strings.HasPrefix(epicID, projectKey+"-") {
+			return invalidf("cli: planned epic ID %q must start with the project key (e.g. %s)", epicID, models.GenerateEpicID(projectKey, 1))
+		}
+	}
+
+	roadmapPath, err := storage.RoadmapPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve roadmap path: %w", err)
+	}
+
+	var roadmap models.Roadmap
+	if err := storage.UpdateJSONAtomic(cmd.Context(), roadmapPath, &roadmap, func(v interface{}) error {
+		r := v.(*models.Roadmap)
+		r.Set(models.RoadmapItem{EpicID: epicID, Quarter: quarter, Title: title})
+		return r.Validate()
+	}); err != nil {
+		return fmt.Errorf("cli: failed to update roadmap: %w", err)
+	}
+
+	out := successOut(cmd)
+	fmt.Fprintf(out, "Planned epic %s for %s\n", epicID, quarter)
+
+	return nil
+}
+
+// NewRoadmapRemoveCmd creates and returns the roadmap remove command.
+func NewRoadmapRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "remove <epic-id>",
+		Short:             "Remove an epic from the roadmap",
+		Long:              "Remove an epic from the roadmap. The epic itself is not changed.",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeEpicArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			epicID := args[0]
+			return removeRoadmapItem(epicID, cmd)
+		},
+	}
+
+	return cmd
+}
+
+// removeRoadmapItem removes an epic from the roadmap.
+func removeRoadmapItem(epicID string, cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+
+	roadmapPath, err := storage.RoadmapPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve roadmap path: %w", err)
+	}
+	if _, err := os.Stat(roadmapPath); os.IsNotExist(err) {
+		return fmt.Errorf("cli: epic %q is not on the roadmap", epicID)
+	}
+
+	var roadmap models.Roadmap
+	if err := storage.UpdateJSONAtomic(cmd.Context(), roadmapPath, &roadmap, func(v interface{}) error {
+		r := v.(*models.Roadmap)
+		if !r.Remove(epicID) {
+			return fmt.Errorf("cli: epic %q is not on the roadmap", epicID)
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("cli: failed to update roadmap: %w", err)
+	}
+
+	out := successOut(cmd)
+	fmt.Fprintf(out, "Removed epic %s from the roadmap\n", epicID)
+
+	return nil
+}
+
+// NewRoadmapViewCmd creates and returns the roadmap view command.
+func NewRoadmapViewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "view",
+		Short: "Show the roadmap quarter by quarter",
+		Long:  "Show the planned epics quarter by quarter with progress rolled up from their issues",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return viewRoadmap(cmd)
+		},
+	}
+
+	return cmd
+}
+
+// viewRoadmap renders the roadmap in the resolved output format.
+func viewRoadmap(cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+
+	quarters, err := loadRoadmap(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+
+	w := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(quarters)
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(w, quarters)
+	case config.DefaultFormatLSON:
+		for _, q := range quarters {
+			fmt.Fprintf(w, "@QUARTER: %s\n", q.Quarter)
+			for _, epic := range q.Epics {
+				fmt.Fprintf(w, "@EPIC: %s|%s|%s|%d/%d\n", epic.ID, roadmapStatus(epic), epic.Title, epic.Done, epic.Total)
+			}
+		}
+	default:
+		if len(quarters) == 0 {
+			fmt.Fprintf(w, "Roadmap is empty (plan an epic with `buyruk roadmap add <epic-id> --quarter 2024-Q3`)\n")
+			return nil
+		}
+		styles := ui.NewStyles()
+		for i, q := range quarters {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintln(w, styles.Label(q.Quarter))
+			for _, epic := range q.Epics {
+				status := roadmapStatus(epic)
+				fmt.Fprintf(w, "  %s %s %s %s\n",
+					styles.ID(epic.ID),
+					styles.Title(epic.Title),
+					styles.StatusColor(status)(status),
+					formatProgress(epic.Done, epic.Total))
+			}
+		}
+	}
+	return nil
+}
+
+// NewRoadmapExportCmd creates and returns the roadmap export command.
+func NewRoadmapExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the roadmap",
+		Long: `Export the roadmap as a Markdown document (default), as JSON or YAML with
+--format json|yaml, or as the Mermaid gantt chart of the project's epics and
+issues with --format mermaid. Writes to stdout unless --output is given.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exportRoadmap(cmd)
+		},
+	}
+
+	cmd.Flags().String("output", "", "Output file path (default: stdout)")
+
+	return cmd
+}
+
+// exportRoadmap writes the roadmap as Markdown, JSON, or YAML.
+func exportRoadmap(cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+
+	// Markdown is the natural export format, so the configured default format
+	// only applies when --format is given explicitly
+	format := "markdown"
+	if cmd.Flags().Changed("format") {
+		format = strings.ToLower(GetFormat(cmd))
+	}
+
+	quarters, err := loadRoadmap(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	switch format {
+	case "markdown", "md":
+		writeRoadmapMarkdown(&sb, projectKey, quarters)
+	case config.DefaultFormatJSON:
+		data, err := json.MarshalIndent(quarters, "", "  ")
+		if err != nil {
+			return fmt.Errorf("cli: failed to marshal roadmap: %w", err)
+		}
+		sb.Write(data)
+		sb.WriteString("\n")
+	case config.DefaultFormatYAML:
+		if err := ui.EncodeYAML(&sb, quarters); err != nil {
+			return fmt.Errorf("cli: failed to encode roadmap: %w", err)
+		}
+	case "mermaid":
+		if err := showGantt(cmd.Context(), &sb, projectKey); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("cli: unsupported roadmap export format %q (use markdown, json, yaml, or mermaid)", format)
+	}
+
+	outputPath, _ := cmd.Flags().GetString("output")
+	if outputPath == "" {
+		_, err := io.WriteString(cmd.OutOrStdout(), sb.String())
+		return err
+	}
+
+	if err := storage.WriteAtomic(cmd.Context(), outputPath, []byte(sb.String())); err != nil {
+		return fmt.Errorf("cli: failed to write roadmap: %w", err)
+	}
+
+	out := successOut(cmd)
+	fmt.Fprintf(out, "Exported roadmap for project %q to %s\n", projectKey, outputPath)
+
+	return nil
+}
+
+// loadRoadmap loads the roadmap of a project grouped by quarter, with progress
+// rolled up from the project index. Projects without a roadmap have no quarters.
+func loadRoadmap(ctx context.Context, projectKey string) ([]RoadmapQuarter, error) {
+	projectDir, err := storage.ProjectDir(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve project directory: %w", err)
+	}
+	if _, err := os.Stat(projectDir); os.IsNotExist(err) {
+		return nil, notFoundf("cli: project %q does not exist", projectKey)
+	}
+
+	roadmapPath, err := storage.RoadmapPath(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve roadmap path: %w", err)
+	}
+
+	var roadmap models.Roadmap
+	if err := storage.ReadJSON(ctx, roadmapPath, &roadmap); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("cli: failed to load roadmap: %w", err)
+	}
+
+	indexPath, err := storage.ProjectIndexPath(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(ctx, indexPath, &index); err != nil {
+		return nil, fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+
+	wf, err := loadWorkflow(ctx, projectKey)
+	if err != nil {
+		return nil, err
+	}
+
+	quarters := []RoadmapQuarter{}
+	for _, quarter := range roadmap.Quarters() {
+		q := RoadmapQuarter{Quarter: quarter, Epics: []RoadmapEpic{}}
+		for _, item := range roadmap.ItemsIn(quarter) {
+			epic := RoadmapEpic{ID: item.EpicID, Title: item.Title}
+
+			epicPath, err := storage.EpicPath(ctx, projectKey, item.EpicID)
+			if err != nil {
+				return nil, fmt.Errorf("cli: failed to resolve epic path: %w", err)
+			}
+			var e models.Epic
+			if err := storage.ReadJSON(ctx, epicPath, &e); err == nil {
+				epic.Title = e.Title
+				epic.Status = e.Status
+			} else if !errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("cli: failed to load epic %s: %w", item.EpicID, err)
+			}
+
+			epic.Done, epic.Total = index.EpicProgress(item.EpicID, wf)
+			q.Epics = append(q.Epics, epic)
+		}
+		quarters = append(quarters, q)
+	}
+
+	return quarters, nil
+}
+
+// writeRoadmapMarkdown writes the roadmap as a Markdown document with one table per quarter.
+func writeRoadmapMarkdown(w io.Writer, projectKey string, quarters []RoadmapQuarter) {
+	fmt.Fprintf(w, "# Roadmap: %s\n", projectKey)
+	for _, q := range quarters {
+		fmt.Fprintf(w, "\n## %s\n\n", q.Quarter)
+		fmt.Fprintf(w, "| Epic | Title | Status | Progress |\n")
+		fmt.Fprintf(w, "| :--- | :--- | :--- | :--- |\n")
+		for _, epic := range q.Epics {
+			title := strings.ReplaceAll(epic.Title, "|", "\\|")
+			fmt.Fprintf(w, "| %s | %s | %s | %s |\n", epic.ID, title, roadmapStatus(epic), formatProgressText(epic.Done, epic.Total))
+		}
+	}
+}
+
+// roadmapStatus returns the epic status, or PLANNED for epics that don't exist yet.
+func roadmapStatus(epic RoadmapEpic) string {
+	if epic.Status == "" {
+		return "PLANNED"
+	}
+	return epic.Status
+}
+
+// formatProgress renders progress as a bar followed by the done/total counts.
+func formatProgress(done, total int) string {
+	const width = 10
+	filled := 0
+	if total > 0 {
+		filled = done * width / total
+	}
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", width-filled) + "] " + formatProgressText(done, total)
+}
+
+// formatProgressText renders progress as "done/total (percent%)".
+func formatProgressText(done, total int) string {
+	if total == 0 {
+		return "0/0"
+	}
+	return fmt.Sprintf("%d/%d (%d%%)", done, total, done*100/total)
+}
@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestNewRoadmapCmd(t *testing.T) {
+	cmd := NewRoadmapCmd()
+	if cmd == nil {
+		t.Fatal("NewRoadmapCmd() returned nil")
+	}
+	if cmd.Use != "roadmap" {
+		t.Errorf("Expected Use to be 'roadmap', got '%s'", cmd.Use)
+	}
+}
+
+// runRoadmapTestCmd runs the root command with args and returns its stdout.
+func runRoadmapTestCmd(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := NewRootCmd()
+	cmd.SetArgs(args)
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestRoadmap_ViewAndExport(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	setup := [][]string{
+		{"project", "create", projectKey},
+		{"epic", "create", "--project", projectKey, "--id", projectKey + "-E1", "--title", "Search"},
+		{"issue", "create", "--project", projectKey, "--title", "Index", "--epic", projectKey + "-E1"},
+		{"issue", "create", "--project", projectKey, "--title", "Query", "--epic", projectKey + "-E1"},
+		{"roadmap", "add", projectKey + "-E1", "--project", projectKey, "--quarter", "2024-Q3"},
+		{"roadmap", "add", projectKey + "-E2", "--project", projectKey, "--quarter", "2024-Q4", "--title", "Offline sync"},
+	}
+	for _, args := range setup {
+		if _, err := runRoadmapTestCmd(t, args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	issueID := projectKey + "-1"
+	if _, err := runRoadmapTestCmd(t, "issue", "update", issueID, "--project", projectKey, "--status", "DONE"); err != nil {
+		t.Fatalf("Failed to update issue: %v", err)
+	}
+
+	out, err := runRoadmapTestCmd(t, "roadmap", "view", "--project", projectKey, "--format", "json")
+	if err != nil {
+		t.Fatalf("roadmap view failed: %v", err)
+	}
+	var quarters []RoadmapQuarter
+	if err := json.Unmarshal([]byte(out), &quarters); err != nil {
+		t.Fatalf("Failed to parse roadmap JSON: %v\n%s", err, out)
+	}
+	if len(quarters) != 2 || quarters[0].Quarter != "2024-Q3" || quarters[1].Quarter != "2024-Q4" {
+		t.Fatalf("Expected quarters 2024-Q3, 2024-Q4, got %+v", quarters)
+	}
+	search := quarters[0].Epics[0]
+	if search.ID != projectKey+"-E1" || search.Title != "Search" || search.Done != 1 || search.Total != 2 {
+		t.Errorf("Unexpected rollup for %s-E1: %+v", projectKey, search)
+	}
+	planned := quarters[1].Epics[0]
+	if planned.Title != "Offline sync" || planned.Status != "" {
+		t.Errorf("Unexpected planned epic: %+v", planned)
+	}
+
+	out, err = runRoadmapTestCmd(t, "roadmap", "view", "--project", projectKey, "--format", "lson")
+	if err != nil {
+		t.Fatalf("roadmap view lson failed: %v", err)
+	}
+	if !strings.Contains(out, "@EPIC: "+projectKey+"-E2|PLANNED|Offline sync|0/0") {
+		t.Errorf("LSON output missing planned epic, got:\n%s", out)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "roadmap.md")
+	if _, err := runRoadmapTestCmd(t, "roadmap", "export", "--project", projectKey, "--output", outputPath); err != nil {
+		t.Fatalf("roadmap export failed: %v", err)
+	}
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	markdown := string(data)
+	for _, want := range []string{"# Roadmap: " + projectKey, "## 2024-Q3", "| " + projectKey + "-E1 | Search | TODO | 1/2 (50%) |", "| " + projectKey + "-E2 | Offline sync | PLANNED | 0/0 |"} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Markdown export missing %q, got:\n%s", want, markdown)
+		}
+	}
+
+	if _, err := runRoadmapTestCmd(t, "roadmap", "remove", projectKey+"-E2", "--project", projectKey); err != nil {
+		t.Fatalf("roadmap remove failed: %v", err)
+	}
+	out, err = runRoadmapTestCmd(t, "roadmap", "view", "--project", projectKey, "--format", "lson")
+	if err != nil {
+		t.Fatalf("roadmap view failed: %v", err)
+	}
+	if strings.Contains(out, projectKey+"-E2") {
+		t.Errorf("%s-E2 should be removed from the roadmap, got:\n%s", projectKey, out)
+	}
+}
+
+func TestRoadmap_AddValidation(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	if _, err := runRoadmapTestCmd(t, "project", "create", projectKey); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"missing quarter", []string{"roadmap", "add", projectKey + "-E1", "--project", projectKey, "--title", "X"}, "--quarter is required"},
+		{"invalid quarter", []string{"roadmap", "add", projectKey + "-E1", "--project", projectKey, "--quarter", "Q3", "--title", "X"}, "invalid quarter"},
+		{"unknown epic", []string{"roadmap", "add", projectKey + "-E1", "--project", projectKey, "--quarter", "2024-Q3"}, "not found"},
+		{"unscoped planned epic", []string{"roadmap", "add", "E-9", "--project", projectKey, "--quarter", "2024-Q3", "--title", "X"}, "must start with the project key"},
+		{"other project's epic", []string{"roadmap", "add", "OTHER-E1", "--project", projectKey, "--quarter", "2024-Q3"}, "belongs to project"},
+		{"not on roadmap", []string{"roadmap", "remove", projectKey + "-E1", "--project", projectKey}, "not on the roadmap"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runRoadmapTestCmd(t, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestRoadmap_Gantt(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(at string, args ...string) string {
+		t.Helper()
+		out, err := runRoadmapTestCmd(t, append(args, "--project", projectKey, "--fixed-time", at, "--no-hooks")...)
+		if err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+		return out
+	}
+
+	run("2024-06-03T09:00:00Z", "project", "create", projectKey)
+	run("2024-06-03T09:00:00Z", "epic", "create", "--id", projectKey+"-E1", "--title", "Search: v2")
+	run("2024-06-03T09:00:00Z", "epic", "create", "--id", projectKey+"-E2", "--title", "Empty")
+	run("2024-06-03T09:00:00Z", "issue", "create", "--title", "Index", "--epic", projectKey+"-E1")
+	run("2024-06-04T09:00:00Z", "issue", "create", "--title", "Query #1", "--epic", projectKey+"-E1", "--due", "2024-06-07")
+	run("2024-06-05T09:00:00Z", "issue", "create", "--title", "Docs", "--due", "2024-06-20")
+	run("2024-06-05T09:00:00Z", "issue", "update", projectKey+"-1", "--status", "DONE")
+	run("2024-06-06T09:00:00Z", "issue", "update", projectKey+"-2", "--status", "DOING")
+
+	want := `gantt
+    title Roadmap: ` + projectKey + `
+    dateFormat YYYY-MM-DD
+    section ` + projectKey + `-E1 Search - v2
+    ` + projectKey + `-1 Index :done, 2024-06-03, 2024-06-06
+    ` + projectKey + `-2 Query 1 :crit, active, 2024-06-04, 2024-06-08
+    section No epic
+    ` + projectKey + `-3 Docs :2024-06-05, 2024-06-21
+`
+	if out := run("2024-06-10T09:00:00Z", "roadmap"); out != want {
+		t.Errorf("roadmap =\n%s\nwant\n%s", out, want)
+	}
+	if out := run("2024-06-10T09:00:00Z", "roadmap", "export", "--format", "mermaid"); out != want {
+		t.Errorf("roadmap export --format mermaid =\n%s\nwant\n%s", out, want)
+	}
+}
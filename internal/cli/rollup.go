@@ -0,0 +1,242 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// epicRollup is an epic whose status was rolled up from its issues.
+type epicRollup struct {
+	epicID string
+	status string
+}
+
+// pendingRollup is the epic status rollup of an index write, applied once
+// the index is in place.
+type pendingRollup struct {
+	rollups []epicRollup
+	epics   []fileWrite
+}
+
+// epicRollupWriteFilter rolls up the status of the epics an index write
+// changes the issues of when epic_status is auto: the epic of a new or
+// deleted issue, and the old and new epics of an issue whose status or epic
+// changed. The index gets the new statuses in the write itself; the epic
+// files are written by applyEpicRollups once the index is in place, still
+// under the lock the writer holds, so a failed index write leaves both as they
+// were. Epics without issues keep their status.
+func epicRollupWriteFilter(ctx context.Context, path string, data []byte) ([]byte, error) {
+	state := runStateFrom(ctx)
+	if state == nil || state.epicStatus != config.EpicStatusAuto {
+		return data, nil
+	}
+	projectKey, ok := indexFileProject(ctx, path)
+	if !ok {
+		return data, nil
+	}
+	state.mu.Lock()
+	delete(state.pendingRollups, path)
+	state.mu.Unlock()
+
+	var updated, old models.ProjectIndex
+	if err := json.Unmarshal(data, &updated); err != nil || len(updated.Epics) == 0 {
+		return data, nil
+	}
+	if err := storage.ReadIndex(ctx, path, &old); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return data, nil
+	}
+	epicIDs := changedIssueEpics(&old, &updated)
+	if len(epicIDs) == 0 {
+		return data, nil
+	}
+
+	pending, err := rollupIndexEpics(ctx, projectKey, &updated, epicIDs)
+	if err != nil {
+		return nil, fmt.Errorf("cli: epic status rollup failed: %w", err)
+	}
+	if len(pending.rollups) == 0 {
+		return data, nil
+	}
+	state.mu.Lock()
+	if state.pendingRollups == nil {
+		state.pendingRollups = map[string]*pendingRollup{}
+	}
+	state.pendingRollups[path] = pending
+	state.mu.Unlock()
+	rolledUp, err := json.MarshalIndent(&updated, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to marshal project index: %w", err)
+	}
+	return rolledUp, nil
+}
+
+// applyEpicRollups writes the epics an index write rolled up the status of,
+// now that the index is in place.
+func applyEpicRollups(ctx context.Context, path string) error {
+	state := runStateFrom(ctx)
+	if state == nil {
+		return nil
+	}
+	state.mu.Lock()
+	pending := state.pendingRollups[path]
+	delete(state.pendingRollups, path)
+	state.mu.Unlock()
+	if pending == nil {
+		return nil
+	}
+	for _, epic := range pending.epics {
+		if err := storage.WriteAtomic(ctx, epic.path, epic.data); err != nil {
+			return fmt.Errorf("cli: failed to update epic %s: %w", strings.TrimSuffix(filepath.Base(epic.path), ".json"), err)
+		}
+	}
+	state.mu.Lock()
+	state.epicRollups = append(state.epicRollups, pending.rollups...)
+	state.mu.Unlock()
+	return nil
+}
+
+// indexFileProject returns the project key of path if it is the project.json
+// of a project.
+func indexFileProject(ctx context.Context, path string) (string, bool) {
+	if filepath.Base(path) != "project.json" {
+		return "", false
+	}
+	projectKey := filepath.Base(filepath.Dir(path))
+	if indexPath, err := storage.ProjectIndexPath(ctx, projectKey); err != nil || indexPath != path {
+		return "", false
+	}
+	return projectKey, true
+}
+
+// changedIssueEpics returns the epics, sorted, whose issues differ between
+// two versions of an index: the epic of an added or removed issue, and the
+// old and new epics of an issue whose status or epic changed.
+func changedIssueEpics(old, updated *models.ProjectIndex) []string {
+	var epicIDs []string
+	add := func(epicID string) {
+		if epicID != "" && !slices.Contains(epicIDs, epicID) {
+			epicIDs = append(epicIDs, epicID)
+		}
+	}
+	before := make(map[string]*models.IndexEntry, len(old.Issues))
+	for i := range old.Issues {
+		before[old.Issues[i].ID] = &old.Issues[i]
+	}
+	for i := range updated.Issues {
+		entry := &updated.Issues[i]
+		previous, ok := before[entry.ID]
+		delete(before, entry.ID)
+		if !ok {
+			add(entry.EpicID)
+		} else if previous.Status != entry.Status || previous.EpicID != entry.EpicID {
+			add(previous.EpicID)
+			add(entry.EpicID)
+		}
+	}
+	for _, removed := range before {
+		add(removed.EpicID)
+	}
+	slices.Sort(epicIDs)
+	return epicIDs
+}
+
+// rollupIndexEpics sets the status of the epics of a project from their
+// issues in idx, and returns the epics whose status changed with the epic
+// files to write. The caller holds the project's lock.
+func rollupIndexEpics(ctx context.Context, projectKey string, idx *models.ProjectIndex, epicIDs []string) (*pendingRollup, error) {
+	wf, err := loadWorkflow(ctx, projectKey)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := &pendingRollup{}
+	for _, epicID := range epicIDs {
+		status, ok := rolledUpEpicStatus(idx.EpicIssues(epicID), wf)
+		if !ok {
+			continue
+		}
+		epicPath, err := storage.EpicPath(ctx, projectKey, epicID)
+		if err != nil {
+			return nil, fmt.Errorf("cli: failed to resolve epic path: %w", err)
+		}
+
+		// Issues can keep the ID of an epic that was deleted
+		var epic models.Epic
+		if err := storage.ReadJSON(ctx, epicPath, &epic); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("cli: failed to load epic %s: %w", epicID, err)
+		}
+		if epic.ID != epicID || epic.Status == status {
+			continue
+		}
+		epic.Status = status
+		epic.UpdatedAt = storage.Timestamp(ctx)
+		epicData, err := json.MarshalIndent(&epic, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("cli: failed to marshal epic %s: %w", epicID, err)
+		}
+		idx.SetEpic(&epic)
+		pending.epics = append(pending.epics, fileWrite{path: epicPath, data: epicData})
+		pending.rollups = append(pending.rollups, epicRollup{epicID: epicID, status: status})
+	}
+	return pending, nil
+}
+
+// reportEpicRollups prints the epics whose status the command rolled up,
+// unless --quiet is given.
+func reportEpicRollups(cmd *cobra.Command) {
+	state := runStateFrom(cmd.Context())
+	if state == nil {
+		return
+	}
+	state.mu.Lock()
+	rollups := state.epicRollups
+	state.epicRollups = nil
+	state.mu.Unlock()
+	if quiet, _ := cmd.Flags().GetBool("quiet"); quiet {
+		return
+	}
+	for _, rollup := range rollups {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Epic %s is now %s (rolled up from its issues)\n", rollup.epicID, rollup.status)
+	}
+}
+
+// rolledUpEpicStatus returns the status of an epic from its issues: done when
+// all of them are done, in progress once any of them left the default status,
+// and the default status otherwise. It returns false for an epic without
+// issues.
+func rolledUpEpicStatus(issues []models.IndexEntry, wf *models.Workflow) (string, bool) {
+	if len(issues) == 0 {
+		return "", false
+	}
+	done, started := 0, false
+	for _, entry := range issues {
+		if wf.IsDoneStatus(entry.Status) {
+			done++
+		}
+		if entry.Status != wf.DefaultStatus() {
+			started = true
+		}
+	}
+	switch {
+	case done == len(issues):
+		return wf.DoneStatusList()[0], true
+	case started:
+		return wf.InProgressStatus(), true
+	default:
+		return wf.DefaultStatus(), true
+	}
+}
@@ -1,6 +1,14 @@
 package cli
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
 	"github.com/spf13/cobra"
 )
 
@@ -10,34 +18,231 @@ func NewRootCmd() *cobra.Command {
 		Use:   "buyruk",
 		Short: "A local-first project management tool",
 		Long:  "Buyruk is a high-performance, local-first orchestration tool that treats the filesystem as a database.",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// A command run by another command (stress worker operations)
+			// starts from the Env of that command
+			ctx := cmd.Context()
+			env := storage.EnvFrom(ctx)
+			if err := applyFixedTime(cmd, &env); err != nil {
+				return err
+			}
+			if err := applyDataDir(cmd, &env); err != nil {
+				return err
+			}
+			applyDurability(&env)
+			if env.Cache == nil {
+				env.Cache = storage.NewCache()
+			}
+			env.WriteFilter = issueWriteFilter
+			env.AfterWrite = applyEpicRollups
+			parent := runStateFrom(ctx)
+			cmd.SetContext(storage.WithEnv(ctx, env))
+			cmd.SetContext(withRunState(cmd.Context(), startRun(cmd, parent)))
+			return refreshBackend(cmd)
+		},
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			recordUsage(cmd.Context())
+			if err := storeBackend(cmd); err != nil {
+				return err
+			}
+			reportEpicRollups(cmd)
+			autocommit(cmd)
+			fireHooks(cmd)
+			return nil
+		},
 	}
 
 	// Persistent flags
-	rootCmd.PersistentFlags().String("format", "modern", "Output format (modern, json, lson)")
+	rootCmd.PersistentFlags().String("format", "modern", "Output format (modern, json, lson, ndjson, yaml, template)")
 	rootCmd.PersistentFlags().String("project", "", "Project key to operate on")
+	rootCmd.PersistentFlags().String("fixed-time", "", "Use a fixed current time (RFC3339) for deterministic output")
+	rootCmd.PersistentFlags().MarkHidden("fixed-time")
+	rootCmd.PersistentFlags().String("data-dir", "", "Directory to keep projects in (overrides repo-local mode and the data_dir config)")
+	rootCmd.PersistentFlags().Bool("no-hooks", false, "Don't run the project's hooks for this command")
+	rootCmd.PersistentFlags().Bool("no-autocommit", false, "Don't commit data changes to git for this command (repo-local mode and sync init)")
+	rootCmd.PersistentFlags().Bool("unlock-override", false, "Change a read-only project anyway (see buyruk project lock)")
+	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "Don't print success messages (errors and requested output are still printed)")
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return invalidf("%w", err)
+	})
+	rootCmd.RegisterFlagCompletionFunc("project", completeProjectKeys)
+	rootCmd.RegisterFlagCompletionFunc("format", completeFormats)
 
 	// Add subcommands
 	rootCmd.AddCommand(NewVersionCmd())
 	rootCmd.AddCommand(NewListCmd())
-	rootCmd.AddCommand(NewViewCmd())
+	rootCmd.AddCommand(NewSearchCmd())
+	rootCmd.AddCommand(NewSavedViewCmd())
 	rootCmd.AddCommand(NewProjectCmd())
 	rootCmd.AddCommand(NewIssueCmd())
 	rootCmd.AddCommand(NewEpicCmd())
 	rootCmd.AddCommand(NewConfigCmd())
 	rootCmd.AddCommand(NewExportCmd())
 	rootCmd.AddCommand(NewImportCmd())
+	rootCmd.AddCommand(NewDoctorCmd())
+	rootCmd.AddCommand(NewRoadmapCmd())
+	rootCmd.AddCommand(NewApplyCmd())
+	rootCmd.AddCommand(NewCompletionCmd())
+	rootCmd.AddCommand(NewQueryCmd())
+	rootCmd.AddCommand(NewLockCmd())
+	rootCmd.AddCommand(NewMigrateWizardCmd())
+	rootCmd.AddCommand(NewPlanCmd())
+	rootCmd.AddCommand(NewShareCmd())
+	rootCmd.AddCommand(NewUnlockCmd())
+	rootCmd.AddCommand(NewAwayCmd())
+	rootCmd.AddCommand(NewSyncCmd())
+	rootCmd.AddCommand(NewBlockersCmd())
+	rootCmd.AddCommand(NewStatsCmd())
+	rootCmd.AddCommand(NewStandupCmd())
+	rootCmd.AddCommand(NewMeCmd())
+	rootCmd.AddCommand(NewEscalateCmd())
+	rootCmd.AddCommand(NewSprintCmd())
+	rootCmd.AddCommand(NewMilestoneCmd())
+	rootCmd.AddCommand(NewComponentCmd())
+	rootCmd.AddCommand(NewTrashCmd())
+	rootCmd.AddCommand(NewReportCmd())
+	rootCmd.AddCommand(NewPolicyCmd())
+	rootCmd.AddCommand(NewTickCmd())
+	rootCmd.AddCommand(NewNotificationsCmd())
+	rootCmd.AddCommand(NewNotifyCmd())
+	rootCmd.AddCommand(NewServeCmd())
+	rootCmd.AddCommand(NewCloneCmd())
+	rootCmd.AddCommand(NewPullCmd())
+	rootCmd.AddCommand(NewPushCmd())
+	rootCmd.AddCommand(NewInsightsCmd())
+	rootCmd.AddCommand(NewHooksCmd())
+	rootCmd.AddCommand(NewBranchCmd())
+	rootCmd.AddCommand(NewGitCmd())
+	rootCmd.AddCommand(NewStressCmd())
+	rootCmd.AddCommand(NewBackupCmd())
+	rootCmd.AddCommand(NewRestoreCmd())
+	markArgErrorsInvalid(rootCmd)
 
 	return rootCmd
 }
 
+// markArgErrorsInvalid makes the argument errors of a command and its
+// subcommands invalid-usage errors, like flag errors.
+func markArgErrorsInvalid(cmd *cobra.Command) {
+	if validate := cmd.Args; validate != nil {
+		cmd.Args = func(cmd *cobra.Command, args []string) error {
+			if err := validate(cmd, args); err != nil {
+				return invalidf("%w", err)
+			}
+			return nil
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		markArgErrorsInvalid(sub)
+	}
+}
+
+// ExitError is returned by commands that report their result through the exit status.
+// An ExitError without a wrapped error is a result, not a failure, and is not printed.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the process exit code for an error returned by Execute:
+// the code of an ExitError, or the code for the kind of the error.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	return exitCodeOf(err)
+}
+
 // GetFormat returns the format flag value from the command.
 func GetFormat(cmd *cobra.Command) string {
 	format, _ := cmd.Flags().GetString("format")
 	return format
 }
 
+// successOut returns the writer for success messages of the command, which
+// --quiet discards. Data the command was asked for is written to
+// cmd.OutOrStdout() instead.
+func successOut(cmd *cobra.Command) io.Writer {
+	if quiet, _ := cmd.Flags().GetBool("quiet"); quiet {
+		return io.Discard
+	}
+	return cmd.OutOrStdout()
+}
+
 // GetProject returns the project flag value from the command.
 func GetProject(cmd *cobra.Command) string {
 	project, _ := cmd.Flags().GetString("project")
 	return project
 }
+
+// applyDataDir sets the directory projects are kept in: the --data-dir flag,
+// or unless in repo-local mode, the local copy of the storage_backend config
+// or the data_dir config.
+func applyDataDir(cmd *cobra.Command, env *storage.Env) error {
+	dir, _ := cmd.Flags().GetString("data-dir")
+	if dir == "" {
+		if _, local := storage.LocalDir(); local {
+			return nil
+		}
+		if backendURL := storageBackendOf(cmd); backendURL != "" {
+			dir, err := storage.BackendDir(backendURL)
+			if err != nil {
+				return fmt.Errorf("cli: failed to resolve storage backend copy: %w", err)
+			}
+			env.DataDir = dir
+			return nil
+		}
+		cfg, err := config.Get()
+		if err != nil {
+			return nil // Commands report config errors themselves
+		}
+		if dir, err = cfg.DataDirPath(); err != nil || dir == "" {
+			return err
+		}
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return invalidf("cli: invalid data directory %q: %w", dir, err)
+	}
+	env.DataDir = abs
+	return nil
+}
+
+// applyDurability sets how storage syncs writes to disk from the durability config.
+func applyDurability(env *storage.Env) {
+	if cfg, err := config.Get(); err == nil {
+		env.Durability = cfg.DurabilityLevel()
+	}
+}
+
+// applyFixedTime sets a fixed clock when the hidden --fixed-time flag is set,
+// so that timestamps and relative dates are reproducible (demos, golden files).
+func applyFixedTime(cmd *cobra.Command, env *storage.Env) error {
+	value, _ := cmd.Flags().GetString("fixed-time")
+	if value == "" {
+		return nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return invalidf("cli: invalid --fixed-time value %q (must be RFC3339): %w", value, err)
+	}
+	env.Clock = storage.FixedClock(t)
+	return nil
+}
@@ -1,7 +1,17 @@
 package cli
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 func TestNewRootCmd(t *testing.T) {
@@ -36,6 +46,22 @@ func TestRootCmdFlags(t *testing.T) {
 	}
 }
 
+func TestRootCmd_FlagUsagesHaveNoBackticks(t *testing.T) {
+	// pflag takes a backticked word of a usage as the flag's value name
+	var check func(cmd *cobra.Command)
+	check = func(cmd *cobra.Command) {
+		cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+			if strings.Contains(flag.Usage, "`") {
+				t.Errorf("%s --%s: usage %q has backticks", cmd.CommandPath(), flag.Name, flag.Usage)
+			}
+		})
+		for _, sub := range cmd.Commands() {
+			check(sub)
+		}
+	}
+	check(NewRootCmd())
+}
+
 func TestRootCmdHasVersionSubcommand(t *testing.T) {
 	cmd := NewRootCmd()
 	versionCmd, _, err := cmd.Find([]string{"version"})
@@ -76,3 +102,77 @@ func TestGetProject(t *testing.T) {
 		t.Errorf("Expected GetProject() to return 'test-project', got '%s'", project)
 	}
 }
+
+func TestRootCmd_FixedTime(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	rootCmd := NewRootCmd()
+	rootCmd.SetArgs([]string{"project", "create", projectKey, "--fixed-time", "2024-06-05T12:00:00Z"})
+	rootCmd.SetOut(new(bytes.Buffer))
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	rootCmd2 := NewRootCmd()
+	rootCmd2.SetArgs([]string{"issue", "create", "--project", projectKey, "--title", "Fixed", "--due", "tomorrow",
+		"--fixed-time", "2024-06-05T12:00:00Z"})
+	rootCmd2.SetOut(new(bytes.Buffer))
+	if err := rootCmd2.Execute(); err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+
+	issuePath, _ := storage.IssuePath(t.Context(), projectKey, projectKey+"-1")
+	var issue models.Issue
+	if err := storage.ReadJSON(t.Context(), issuePath, &issue); err != nil {
+		t.Fatalf("Failed to read issue: %v", err)
+	}
+	if issue.CreatedAt != "2024-06-05T12:00:00Z" {
+		t.Errorf("Issue CreatedAt = %q, want 2024-06-05T12:00:00Z", issue.CreatedAt)
+	}
+	if !strings.HasPrefix(issue.Due, "2024-06-0") {
+		t.Errorf("Issue Due = %q, want a date relative to the fixed time", issue.Due)
+	}
+
+	// The clock is restored after the command finishes
+	if time.Since(storage.Now(t.Context())) > time.Minute {
+		t.Error("storage.Now() should use the system clock after the command")
+	}
+
+	// Invalid values are rejected
+	rootCmd3 := NewRootCmd()
+	rootCmd3.SetArgs([]string{"version", "--fixed-time", "yesterday"})
+	rootCmd3.SetOut(new(bytes.Buffer))
+	rootCmd3.SetErr(new(bytes.Buffer))
+	if err := rootCmd3.Execute(); err == nil {
+		t.Error("Expected error for invalid --fixed-time")
+	}
+}
+
+func TestRootCmd_DataDir(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	dataDir := t.TempDir()
+
+	rootCmd := NewRootCmd()
+	rootCmd.SetArgs([]string{"project", "create", projectKey, "--data-dir", dataDir})
+	rootCmd.SetOut(new(bytes.Buffer))
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dataDir, "projects", projectKey, "project.json")); err != nil {
+		t.Errorf("Expected the project in the data directory: %v", err)
+	}
+	// The default directory is restored after the command finishes
+	if dir, _ := storage.DataDir(t.Context()); dir == dataDir {
+		t.Error("storage.DataDir() should not use --data-dir after the command")
+	}
+	projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+	if _, err := os.Stat(projectDir); err == nil {
+		os.RemoveAll(projectDir)
+		t.Errorf("Expected no project in the default data directory, found %s", projectDir)
+	}
+}
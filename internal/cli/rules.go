@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// ruleFieldHints tell how to set each field validation rules can check.
+var ruleFieldHints = map[string]string{
+	"title":       "--title",
+	"description": "--description",
+	"priority":    "--priority",
+	"resolution":  "--resolution",
+	"epic":        "--epic",
+	"due":         "--due",
+	"prs":         "buyruk issue pr",
+	"assignee":    "--assignee",
+	"labels":      "--labels",
+	"components":  "--components",
+	"fix_version": "--fix-version",
+	"estimate":    "--estimate",
+}
+
+// checkRules returns an error describing the rule violations, or with
+// --skip-rules, prints them as a warning instead.
+func checkRules(cmd *cobra.Command, issueID string, violations []models.RuleViolation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	if skip, _ := cmd.Flags().GetBool("skip-rules"); skip {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: skipping project rules for %s: %s\n", issueID, formatRuleViolations(violations))
+		return nil
+	}
+	return invalidf("cli: %s breaks project rules: %s (use --skip-rules to override)", issueID, formatRuleViolations(violations))
+}
+
+// introducedViolations returns the violations that were not already broken
+// before an update.
+func introducedViolations(before, after []models.RuleViolation) (introduced, kept []models.RuleViolation) {
+	for _, v := range after {
+		if slices.ContainsFunc(before, func(b models.RuleViolation) bool { return b.Rule == v.Rule }) {
+			kept = append(kept, v)
+		} else {
+			introduced = append(introduced, v)
+		}
+	}
+	return introduced, kept
+}
+
+// formatRuleViolations describes violations with how to fix each one.
+func formatRuleViolations(violations []models.RuleViolation) string {
+	messages := make([]string, len(violations))
+	for i, v := range violations {
+		messages[i] = v.Message
+		if hint, ok := ruleFieldHints[v.Rule.Field]; ok {
+			messages[i] += " (set it with " + hint + ")"
+		}
+	}
+	return strings.Join(messages, "; ")
+}
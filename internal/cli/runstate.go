@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"context"
+	"sync"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// runState is the state of one command run that its storage writes are
+// recorded and checked against. The root command creates it before the
+// command runs and keeps it in the command's context, next to the command's
+// storage.Env, so commands running side by side (tests, serve) never share
+// it. Commands that serve requests concurrently share their runState, hence
+// the mutex.
+type runState struct {
+	// historySource is the command syncing issues in, or "" for local changes.
+	historySource string
+	// nested is set for a command run by another command (stress worker
+	// operations), which is recorded as part of it.
+	nested bool
+	// unlockOverride lets the command change read-only projects; it is set
+	// from --unlock-override.
+	unlockOverride bool
+	// epicStatus is the epic_status mode of the command, read from the
+	// config when it starts.
+	epicStatus string
+	// backend is the command's local copy of the storage backend, or nil if
+	// it keeps projects in a data directory.
+	backend *backendRun
+
+	mu             sync.Mutex
+	actor          string                    // Who the command's changes are attributed to; see currentActor
+	actorResolved  bool                      // Whether actor is resolved
+	usage          *usageRecord              // The usage log entry of the command, or nil
+	epicRollups    []epicRollup              // Epics whose status the command rolled up
+	pendingRollups map[string]*pendingRollup // Epic rollups of index writes not yet in place, by index path
+	hookEvents     []HookEvent               // Events to fire the hooks of once the command succeeds
+	crdtModes      map[string]crdtModeEntry  // Whether projects are in CRDT mode, by index path; see crdtEnabled
+	replica        string                    // The replica ID of CRDT clocks, or "" until first used; see replicaID
+}
+
+// runStateKey is the context key of the runState.
+type runStateKey struct{}
+
+// withRunState returns a copy of ctx that carries state.
+func withRunState(ctx context.Context, state *runState) context.Context {
+	return context.WithValue(ctx, runStateKey{}, state)
+}
+
+// runStateFrom returns the runState carried by ctx, or nil outside a command
+// run (tests calling helpers directly).
+func runStateFrom(ctx context.Context) *runState {
+	if ctx == nil {
+		return nil
+	}
+	state, _ := ctx.Value(runStateKey{}).(*runState)
+	return state
+}
+
+// startRun creates the runState of a command about to run. parent is the
+// runState of the command running it, if any.
+func startRun(cmd *cobra.Command, parent *runState) *runState {
+	state := &runState{
+		historySource: historySourceOf(cmd),
+		nested:        parent != nil,
+	}
+	state.unlockOverride, _ = cmd.Flags().GetBool("unlock-override")
+	state.backend = startBackendRun(cmd, parent)
+	if cfg, err := config.Get(); err == nil {
+		state.epicStatus = cfg.EpicStatusMode()
+	}
+	if !state.nested {
+		state.usage = startUsage(cmd)
+	}
+	return state
+}
@@ -0,0 +1,357 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// Where a saved view is stored.
+const (
+	viewScopeProject = "project"
+	viewScopeGlobal  = "global"
+)
+
+// ScopedView is a saved view with where it is stored, for view list.
+type ScopedView struct {
+	models.SavedView
+	Scope string `json:"scope"` // "project" or "global"
+}
+
+// NewSavedViewCmd creates and returns the top-level view command: the view
+// command with the subcommands managing saved views, which issue view leaves
+// out.
+func NewSavedViewCmd() *cobra.Command {
+	cmd := NewViewCmd()
+	cmd.Long = `View detailed information about an issue.
+
+The save, run, list, and delete subcommands manage saved views: named list
+filters, such as "type=bug and status!=DONE", rerun with buyruk view run.`
+
+	cmd.AddCommand(NewViewSaveCmd())
+	cmd.AddCommand(NewViewRunCmd())
+	cmd.AddCommand(NewViewListCmd())
+	cmd.AddCommand(NewViewDeleteCmd())
+
+	return cmd
+}
+
+// NewViewSaveCmd creates and returns the view save command.
+func NewViewSaveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "save <name>",
+		Short: "Save a list filter under a name",
+		Long: `Save a query filter (and optionally a sort order) under a name, to list the
+matching issues later with buyruk view run. Views are saved in the project
+(projects/[KEY]/views.json, which buyruk export includes), or with --global
+for every project. Saving an existing name replaces the view.`,
+		Example: `  buyruk view save my-bugs --query "type=bug and status!=DONE and assignee=alice"
+  buyruk view save urgent --query "priority=HIGH|CRITICAL" --sort priority:desc --global`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeViewArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return saveView(args[0], cmd)
+		},
+	}
+
+	cmd.Flags().String("query", "", "Query filter (e.g. \"type=bug and status!=DONE\"; required)")
+	cmd.Flags().String("sort", "", "Sort by field[:asc|desc] ("+strings.Join(listSortFields, ", ")+")")
+	cmd.Flags().String("description", "", "What the view is for")
+	cmd.Flags().Bool("global", false, "Save the view for all projects instead of the current one")
+	cmd.MarkFlagRequired("query")
+
+	return cmd
+}
+
+// saveView saves a named view in the project or global views.
+func saveView(name string, cmd *cobra.Command) error {
+	if err := models.ValidateViewName(name); err != nil {
+		return invalidf("cli: %w", err)
+	}
+	query, _ := cmd.Flags().GetString("query")
+	if strings.TrimSpace(query) == "" {
+		return invalidf("cli: --query cannot be empty (use \"all\" for every issue)")
+	}
+	if _, err := parseQueryFilter(query); err != nil {
+		return err
+	}
+	sortValue, _ := cmd.Flags().GetString("sort")
+	if field, _, _ := strings.Cut(sortValue, ":"); sortValue != "" && !slices.Contains(listSortFields, strings.ToLower(strings.TrimSpace(field))) {
+		return invalidf("cli: invalid sort field %q (allowed: %s)", field, strings.Join(listSortFields, ", "))
+	}
+	description, _ := cmd.Flags().GetString("description")
+
+	viewsPath, scope, err := resolveViewsPath(cmd)
+	if err != nil {
+		return err
+	}
+	if err := updateViews(cmd.Context(), viewsPath, scope, func(views *models.SavedViews) error {
+		views.Set(models.SavedView{
+			Name:        name,
+			Query:       query,
+			Sort:        sortValue,
+			Description: description,
+			CreatedAt:   storage.Timestamp(cmd.Context()),
+		})
+		return views.Validate()
+	}); err != nil {
+		return fmt.Errorf("cli: failed to update views: %w", err)
+	}
+
+	fmt.Fprintf(successOut(cmd), "Saved %s view %s (run it with `buyruk view run %s`)\n", scope, name, name)
+	return nil
+}
+
+// NewViewRunCmd creates and returns the view run command.
+func NewViewRunCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run <name>",
+		Short: "List the issues of a saved view",
+		Long: `List the issues of the current project that match a saved view, in its sort
+order. A project view is used before a global view of the same name. The list
+flags work as with buyruk list: --filter narrows the view further, --sort
+overrides its order, and --all-projects runs the view across every project.`,
+		Example: `  buyruk view run my-bugs
+  buyruk view run urgent --project WEB --columns id,title,assignee
+  buyruk view run urgent --all-projects`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeViewArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runView(args[0], cmd)
+		},
+	}
+
+	addListFlags(cmd)
+
+	return cmd
+}
+
+// runView lists the issues of a saved view through the list flags.
+func runView(name string, cmd *cobra.Command) error {
+	view, err := findView(cmd, name)
+	if err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+	filter := view.Query
+	if extra, _ := flags.GetString("filter"); extra != "" {
+		if strings.EqualFold(strings.TrimSpace(filter), "all") {
+			filter = extra
+		} else {
+			filter += "," + extra
+		}
+	}
+	if err := flags.Set("filter", filter); err != nil {
+		return err
+	}
+	if view.Sort != "" && !flags.Changed("sort") {
+		if err := flags.Set("sort", view.Sort); err != nil {
+			return err
+		}
+	}
+	return listIssues(cmd)
+}
+
+// NewViewListCmd creates and returns the view list command.
+func NewViewListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List saved views",
+		Long:  "List the views saved in the current project, if there is one, and the global views.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listViews(cmd)
+		},
+	}
+
+	return cmd
+}
+
+// listViews renders the project and global views in the resolved output
+// format.
+func listViews(cmd *cobra.Command) error {
+	views, err := loadScopedViews(cmd)
+	if err != nil {
+		return err
+	}
+
+	w := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(views)
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(w, views)
+	case config.DefaultFormatLSON:
+		for _, view := range views {
+			fmt.Fprintf(w, "@VIEW: %s|%s|%s|%s\n", view.Name, view.Scope, view.Query, view.Sort)
+		}
+	default:
+		if len(views) == 0 {
+			fmt.Fprintf(w, "No saved views (save one with `buyruk view save my-bugs --query \"type=bug and status!=DONE\"`)\n")
+			return nil
+		}
+		styles := ui.NewStyles()
+		for _, view := range views {
+			line := fmt.Sprintf("%s  %-7s  %s", styles.ID(view.Name), view.Scope, view.Query)
+			if view.Sort != "" {
+				line += "  sort " + view.Sort
+			}
+			if view.Description != "" {
+				line += "  " + styles.Title(view.Description)
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+	return nil
+}
+
+// NewViewDeleteCmd creates and returns the view delete command.
+func NewViewDeleteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "delete <name>",
+		Short:             "Delete a saved view",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeViewArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return deleteView(args[0], cmd)
+		},
+	}
+
+	cmd.Flags().Bool("global", false, "Delete a global view instead of a project view")
+
+	return cmd
+}
+
+// deleteView deletes a saved view from the project or global views.
+func deleteView(name string, cmd *cobra.Command) error {
+	viewsPath, scope, err := resolveViewsPath(cmd)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(viewsPath); os.IsNotExist(err) {
+		return notFoundf("cli: %s view %q not found", scope, name)
+	}
+	if err := updateViews(cmd.Context(), viewsPath, scope, func(views *models.SavedViews) error {
+		if views.Find(name) == nil {
+			return notFoundf("cli: %s view %q not found", scope, name)
+		}
+		views.Views = slices.DeleteFunc(views.Views, func(view models.SavedView) bool { return view.Name == name })
+		return nil
+	}); err != nil {
+		return fmt.Errorf("cli: failed to update views: %w", err)
+	}
+
+	fmt.Fprintf(successOut(cmd), "Deleted %s view %s\n", scope, name)
+	return nil
+}
+
+// resolveViewsPath returns the views file a command saves to or deletes from:
+// the global one with --global, otherwise the project's.
+func resolveViewsPath(cmd *cobra.Command) (string, string, error) {
+	if global, _ := cmd.Flags().GetBool("global"); global {
+		path, err := storage.GlobalViewsPath()
+		if err != nil {
+			return "", "", fmt.Errorf("cli: failed to resolve views path: %w", err)
+		}
+		return path, viewScopeGlobal, nil
+	}
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := loadQueryIndex(cmd.Context(), projectKey); err != nil {
+		return "", "", err
+	}
+	path, err := storage.ViewsPath(cmd.Context(), projectKey)
+	if err != nil {
+		return "", "", fmt.Errorf("cli: failed to resolve views path: %w", err)
+	}
+	return path, viewScopeProject, nil
+}
+
+// updateViews updates a views file. Project views are updated under the
+// project lock; global views, like other files in the config directory, are
+// rewritten atomically.
+func updateViews(ctx context.Context, viewsPath, scope string, update func(*models.SavedViews) error) error {
+	if scope == viewScopeProject {
+		return storage.UpdateJSONAtomic(ctx, viewsPath, &models.SavedViews{}, func(v interface{}) error {
+			return update(v.(*models.SavedViews))
+		})
+	}
+	var views models.SavedViews
+	if err := storage.ReadJSON(ctx, viewsPath, &views); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := update(&views); err != nil {
+		return err
+	}
+	if err := storage.EnsureDir(viewsPath); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(&views, "", "  ")
+	if err != nil {
+		return err
+	}
+	return storage.WriteAtomic(ctx, viewsPath, data)
+}
+
+// findView returns the saved view with a name, from the project's views
+// first and then the global ones.
+func findView(cmd *cobra.Command, name string) (*models.SavedView, error) {
+	views, err := loadScopedViews(cmd)
+	if err != nil {
+		return nil, err
+	}
+	for _, view := range views {
+		if view.Name == name {
+			return &view.SavedView, nil
+		}
+	}
+	return nil, notFoundf("cli: view %q not found (see `buyruk view list`)", name)
+}
+
+// loadScopedViews loads the views of the resolved project, if there is one,
+// followed by the global views.
+func loadScopedViews(cmd *cobra.Command) ([]ScopedView, error) {
+	var paths [][2]string
+	if projectKey, err := config.ResolveProject(cmd); err == nil {
+		path, err := storage.ViewsPath(cmd.Context(), projectKey)
+		if err != nil {
+			return nil, fmt.Errorf("cli: failed to resolve views path: %w", err)
+		}
+		paths = append(paths, [2]string{path, viewScopeProject})
+	}
+	globalPath, err := storage.GlobalViewsPath()
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve views path: %w", err)
+	}
+	paths = append(paths, [2]string{globalPath, viewScopeGlobal})
+
+	scoped := []ScopedView{}
+	for _, p := range paths {
+		var views models.SavedViews
+		if err := storage.ReadJSON(cmd.Context(), p[0], &views); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("cli: failed to load %s views: %w", p[1], err)
+		}
+		for _, view := range views.Views {
+			scoped = append(scoped, ScopedView{SavedView: view, Scope: p[1]})
+		}
+	}
+	return scoped, nil
+}
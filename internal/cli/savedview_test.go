@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestSavedViews(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		globalPath, _ := storage.GlobalViewsPath()
+		os.Remove(globalPath)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	runView := func(args ...string) string {
+		t.Helper()
+		out, err := run(append([]string{"view", "run", "--project", projectKey, "--template", "{{.ID}}"}, args...)...)
+		if err != nil {
+			t.Fatalf("view run %v failed: %v", args, err)
+		}
+		return strings.ReplaceAll(strings.Join(strings.Fields(out), " "), projectKey+"-", "")
+	}
+
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "Crash", "--type", "bug", "--priority", "LOW"},
+		{"issue", "create", "--project", projectKey, "--title", "Typo", "--type", "bug", "--priority", "HIGH", "--labels", "docs"},
+		{"issue", "create", "--project", projectKey, "--title", "Feature", "--priority", "HIGH"},
+		{"issue", "create", "--project", projectKey, "--title", "Fixed", "--type", "bug", "--status", "DONE"},
+		{"view", "save", "open-bugs", "--project", projectKey, "--query", "type=bug and status!=DONE", "--sort", "id:desc"},
+		{"view", "save", "urgent", "--global", "--query", "priority=HIGH"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	if got := runView("open-bugs"); got != "2 1" {
+		t.Errorf("Expected open bugs 2 1, got %q", got)
+	}
+	if got := runView("open-bugs", "--sort", "id", "--filter", "label=docs"); got != "2" {
+		t.Errorf("Expected --filter to narrow the view to 2, got %q", got)
+	}
+	if got := runView("urgent", "--sort", "id"); got != "2 3" {
+		t.Errorf("Expected the global view to match 2 3, got %q", got)
+	}
+
+	// A project view shadows a global view of the same name
+	if _, err := run("view", "save", "urgent", "--project", projectKey, "--query", "priority=HIGH,type=task"); err != nil {
+		t.Fatalf("view save failed: %v", err)
+	}
+	if got := runView("urgent"); got != "3" {
+		t.Errorf("Expected the project view to be used, got %q", got)
+	}
+
+	if _, err := run("view", "save", "bad", "--project", projectKey, "--query", "color=red"); err == nil {
+		t.Error("Expected a view with an unknown query field to be refused")
+	}
+	if _, err := run("view", "run", "missing", "--project", projectKey); err == nil {
+		t.Error("Expected running a missing view to fail")
+	}
+
+	out, err := run("view", "list", "--project", projectKey, "--format", "lson")
+	if err != nil {
+		t.Fatalf("view list failed: %v", err)
+	}
+	want := "@VIEW: open-bugs|project|type=bug and status!=DONE|id:desc\n" +
+		"@VIEW: urgent|project|priority=HIGH,type=task|\n" +
+		"@VIEW: urgent|global|priority=HIGH|\n"
+	if out != want {
+		t.Errorf("Expected views:\n%s\ngot:\n%s", want, out)
+	}
+
+	// Project views are shared through export
+	exportPath := filepath.Join(t.TempDir(), "export.json")
+	if _, err := run("export", projectKey, "--output", exportPath); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	data, err := os.ReadFile(exportPath)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	var exported ExportData
+	if err := json.Unmarshal(data, &exported); err != nil {
+		t.Fatalf("Failed to parse export: %v", err)
+	}
+	if len(exported.Views) != 2 || exported.Views[0].Name != "open-bugs" {
+		t.Errorf("Expected the two project views in the export, got %+v", exported.Views)
+	}
+
+	if _, err := run("view", "delete", "urgent", "--project", projectKey); err != nil {
+		t.Fatalf("view delete failed: %v", err)
+	}
+	if got := runView("urgent", "--sort", "id"); got != "2 3" {
+		t.Errorf("Expected the global view after deleting the project one, got %q", got)
+	}
+}
+
+func TestSavedViewCmd_OnlyAtRoot(t *testing.T) {
+	if cmd, _, err := NewRootCmd().Find([]string{"view", "save"}); err != nil || cmd.Name() != "save" {
+		t.Errorf("Expected view save at the root, got %v (%v)", cmd, err)
+	}
+	if cmd := NewIssueViewCmd(); cmd.HasSubCommands() {
+		t.Errorf("Expected issue view to have no saved view subcommands, got %v", cmd.Commands())
+	}
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewSearchCmd creates and returns the search command.
+func NewSearchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "search <text>",
+		Short: "Search issues across all projects",
+		Long: `List the issues whose ID or title contains a text (case-insensitive), in every
+project unless --project names one. It takes the filter, sort, page, and
+output flags of buyruk list, so one combined queue can be narrowed further.`,
+		Example: `  buyruk search login
+  buyruk search crash --filter "type=bug and status!=DONE" --sort priority:desc
+  buyruk search login --project CORE`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return searchIssues(args[0], cmd)
+		},
+	}
+
+	addListFlags(cmd)
+	cmd.Flags().MarkHidden("search")
+
+	return cmd
+}
+
+// searchIssues lists the issues matching a text, across all projects unless
+// --project is given.
+func searchIssues(text string, cmd *cobra.Command) error {
+	flags := cmd.Flags()
+	if err := flags.Set("search", text); err != nil {
+		return err
+	}
+	if !flags.Changed("project") && !flags.Changed("watch") {
+		if err := flags.Set("all-projects", "true"); err != nil {
+			return err
+		}
+	}
+	return listIssues(cmd)
+}
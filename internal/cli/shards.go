@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// NewProjectIndexShardsCmd creates and returns the project index-shards command.
+func NewProjectIndexShardsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "index-shards <key> [size|off]",
+		Short: "View or change how a project's index is split into files",
+		Long: `View or change the shard size of a project's index.
+
+By default project.json holds an entry for every issue, and every change to an
+issue rewrites all of it. With a shard size, the entries are kept in files of
+one ID range each under index/ (CORE-1000 to CORE-1999 for a size of 1000),
+and a change only rewrites the file of its range and the small project.json.
+Use it for projects with many thousands of issues.
+
+Commands see the same index either way, but issues in a sharded index are
+listed by ID range rather than in the order they were last changed (use --sort
+to choose an order). Sync merges of a sharded project.json keep one side's
+index; run buyruk project repair after one to re-index the other side's
+changes. off puts all entries back into project.json.`,
+		Example: `  buyruk project index-shards CORE
+  buyruk project index-shards CORE 1000
+  buyruk project index-shards CORE off`,
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: completeProjectArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				return showIndexShards(args[0], cmd)
+			}
+			return setIndexShards(args[0], args[1], cmd)
+		},
+	}
+
+	return cmd
+}
+
+// showIndexShards prints the shard size of a project's index.
+func showIndexShards(projectKey string, cmd *cobra.Command) error {
+	index, err := loadQueryIndex(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	if index.ShardSize == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "off")
+		return nil
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), index.ShardSize)
+	return nil
+}
+
+// setIndexShards changes the shard size of a project's index, and rewrites the
+// index with it.
+func setIndexShards(projectKey, value string, cmd *cobra.Command) error {
+	size := 0
+	if value != "off" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return invalidf("cli: invalid shard size %q (must be a positive number or off)", value)
+		}
+		size = n
+	}
+	if _, err := loadQueryIndex(cmd.Context(), projectKey); err != nil {
+		return err
+	}
+	indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	var index models.ProjectIndex
+	if err := storage.UpdateIndexAtomic(cmd.Context(), indexPath, &index, func(v interface{}) error {
+		v.(*models.ProjectIndex).ShardSize = size
+		return nil
+	}); err != nil {
+		return fmt.Errorf("cli: failed to update project index: %w", err)
+	}
+
+	out := successOut(cmd)
+	if size == 0 {
+		fmt.Fprintf(out, "Index of project %q is no longer sharded\n", projectKey)
+	} else {
+		fmt.Fprintf(out, "Index of project %q is sharded by %d IDs\n", projectKey, size)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestProjectIndexShards(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(append(args, "--project", projectKey, "--no-hooks"))
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	mustRun := func(args ...string) string {
+		t.Helper()
+		out, err := run(args...)
+		if err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+		return out
+	}
+	shards := func() []string {
+		t.Helper()
+		dir, _ := storage.IndexShardsDir(t.Context(), projectKey)
+		entries, _ := os.ReadDir(dir)
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		return names
+	}
+	header := func() models.ProjectIndex {
+		t.Helper()
+		indexPath, _ := storage.ProjectIndexPath(t.Context(), projectKey)
+		data, err := os.ReadFile(indexPath)
+		if err != nil {
+			t.Fatalf("Failed to read project.json: %v", err)
+		}
+		var index models.ProjectIndex
+		json.Unmarshal(data, &index)
+		return index
+	}
+
+	mustRun("project", "create", projectKey)
+	for i := 0; i < 5; i++ {
+		mustRun("issue", "create", "--title", "Issue")
+	}
+	if out := mustRun("project", "index-shards", projectKey); strings.TrimSpace(out) != "off" {
+		t.Errorf("index-shards = %q, want off", out)
+	}
+	if _, err := run("project", "index-shards", projectKey, "0"); err == nil {
+		t.Error("Expected a shard size of 0 to be rejected")
+	}
+
+	mustRun("project", "index-shards", projectKey, "2")
+	// IDs 1, 2-3, and 4-5
+	if h := header(); len(h.Shards) != 3 || len(h.Issues) != 0 || h.ShardSize != 2 {
+		t.Fatalf("project.json = %+v, want 3 shards and no entries", h)
+	}
+	if out := mustRun("list", "--format", "json"); strings.Count(out, `"id":`) != 5 {
+		t.Errorf("Expected list to show all 5 issues of the sharded index, got %s", out)
+	}
+
+	// An update rewrites only the shard of its range; the previous one is kept
+	before := header().Shards
+	mustRun("issue", "update", projectKey+"-3", "--status", "DOING")
+	after := header().Shards
+	if after[0] != before[0] || after[1] == before[1] || after[2] != before[2] {
+		t.Errorf("Shards went from %v to %v, want only the second replaced", before, after)
+	}
+	if out := mustRun("view", projectKey+"-3", "--format", "json"); !strings.Contains(out, `"status": "DOING"`) {
+		t.Errorf("Expected the update to be kept, got %s", out)
+	}
+	if len(shards()) != 4 {
+		t.Errorf("Shard files = %v, want the 3 current and 1 previous", shards())
+	}
+	mustRun("issue", "update", projectKey+"-3", "--status", "TODO")
+	if len(shards()) != 4 {
+		t.Errorf("Shard files = %v, want older shards removed", shards())
+	}
+
+	// Repair rebuilds a sharded index whose shards are gone
+	dir, _ := storage.IndexShardsDir(t.Context(), projectKey)
+	os.RemoveAll(dir)
+	if _, err := run("list"); err == nil {
+		t.Error("Expected a missing shard to be reported")
+	}
+	mustRun("project", "repair", projectKey)
+	if out := mustRun("list", "--format", "json"); strings.Count(out, `"id":`) != 5 {
+		t.Errorf("Expected repair to re-index all 5 issues, got %s", out)
+	}
+
+	mustRun("project", "index-shards", projectKey, "off")
+	if h := header(); len(h.Shards) != 0 || len(h.Issues) != 5 {
+		t.Errorf("project.json = %+v, want all entries back", h)
+	}
+	mustRun("issue", "update", projectKey+"-1", "--status", "DOING")
+	if names := shards(); len(names) != 0 {
+		t.Errorf("Shard files = %v, want them removed once unused", names)
+	}
+}
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// NewShareCmd creates and returns the share command.
+func NewShareCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "share",
+		Short: "Share read-only project views",
+		Long: `Create read-only views of a project for people without access to it,
+such as clients who need visibility into progress.`,
+	}
+
+	cmd.AddCommand(NewShareCreateCmd())
+
+	return cmd
+}
+
+// NewShareCreateCmd creates and returns the share create command.
+func NewShareCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <project>",
+		Short: "Create a self-contained HTML page of a project",
+		Long: `Create a single HTML file with board and list views of a project's issues.
+
+All data is inlined and the page needs no server or script, so it can be
+emailed or attached to a ticket. Select issues with --filter, using the query
+syntax (e.g. "epic:E-2", "status!=DONE,label=client"). Comments are left out
+unless --comments is given.`,
+		Example: `  buyruk share create CORE --filter "epic:E-2"
+  buyruk share create CORE --filter "label=client" --title "Client status" --output status.html`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeProjectArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return createShare(args[0], cmd)
+		},
+	}
+
+	cmd.Flags().String("filter", "", "Only share issues matching a query filter (e.g. \"epic:E-2\")")
+	cmd.Flags().String("title", "", "Page title (default: project name)")
+	cmd.Flags().Bool("comments", false, "Include issue comments")
+	cmd.Flags().String("output", "", "Output file path (default: <project>-share.html)")
+
+	return cmd
+}
+
+// createShare writes a share page of the project's issues matching the filter.
+func createShare(projectKey string, cmd *cobra.Command) error {
+	filter, _ := cmd.Flags().GetString("filter")
+	conditions, err := parseQueryFilter(filter)
+	if err != nil {
+		return err
+	}
+
+	index, err := loadQueryIndex(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	wf, err := loadWorkflow(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+
+	bundle := ui.ShareBundle{
+		Title:       index.ProjectName,
+		Project:     projectKey,
+		Filter:      filter,
+		GeneratedAt: storage.Timestamp(cmd.Context()),
+		Statuses:    wf.StatusList(),
+		Issues:      []*models.Issue{},
+		Epics:       map[string]string{},
+	}
+	if title, _ := cmd.Flags().GetString("title"); title != "" {
+		bundle.Title = title
+	}
+	if bundle.Title == "" {
+		bundle.Title = projectKey
+	}
+	bundle.IncludeComments, _ = cmd.Flags().GetBool("comments")
+
+	errOut := cmd.ErrOrStderr()
+	for _, entry := range index.Issues {
+		if !matchesQuery(&entry, conditions) {
+			continue
+		}
+		issuePath, err := storage.IssuePath(cmd.Context(), projectKey, entry.ID)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+		}
+		var issue models.Issue
+		if err := storage.ReadJSON(cmd.Context(), issuePath, &issue); err != nil {
+			fmt.Fprintf(errOut, "Warning: failed to load issue %s: %v\n", entry.ID, err)
+			continue
+		}
+		bundle.Issues = append(bundle.Issues, &issue)
+
+		if issue.EpicID != "" {
+			if _, ok := bundle.Epics[issue.EpicID]; !ok {
+				bundle.Epics[issue.EpicID] = ""
+				if epicPath, err := storage.EpicPath(cmd.Context(), projectKey, issue.EpicID); err == nil {
+					var epic models.Epic
+					if err := storage.ReadJSON(cmd.Context(), epicPath, &epic); err == nil {
+						bundle.Epics[issue.EpicID] = epic.Title
+					}
+				}
+			}
+		}
+	}
+	if len(bundle.Issues) == 0 {
+		fmt.Fprintln(errOut, "Warning: no issues match the filter; the page will be empty")
+	}
+
+	var buf bytes.Buffer
+	if err := ui.RenderShareHTML(&bundle, &buf); err != nil {
+		return fmt.Errorf("cli: failed to render share page: %w", err)
+	}
+
+	outputPath, _ := cmd.Flags().GetString("output")
+	if outputPath == "" {
+		outputPath = fmt.Sprintf("%s-share.html", projectKey)
+	}
+	if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("cli: failed to write share page: %w", err)
+	}
+
+	fmt.Fprintf(successOut(cmd), "Created share page for project %q at %s (%d issues)\n", projectKey, outputPath, len(bundle.Issues))
+	return nil
+}
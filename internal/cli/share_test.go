@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestCreateShare(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(errOut)
+		err := cmd.Execute()
+		return out.String(), errOut.String(), err
+	}
+
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"epic", "create", "--project", projectKey, "--title", "Onboarding"},
+		{"issue", "create", "--project", projectKey, "--title", "Login page", "--epic", projectKey + "-E1"},
+		{"issue", "create", "--project", projectKey, "--title", "Internal cleanup"},
+	} {
+		if _, _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "share.html")
+	out, _, err := run("share", "create", projectKey, "--filter", "epic:"+projectKey+"-E1", "--title", "Client status", "--output", outputPath)
+	if err != nil {
+		t.Fatalf("share create failed: %v", err)
+	}
+	if !strings.Contains(out, "(1 issues)") {
+		t.Errorf("Expected one shared issue, got: %s", out)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read share page: %v", err)
+	}
+	page := string(data)
+	for _, want := range []string{"<title>Client status</title>", "Login page", projectKey + "-E1 Onboarding", "epic:" + projectKey + "-E1"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Expected share page to contain %q", want)
+		}
+	}
+	if strings.Contains(page, "Internal cleanup") {
+		t.Error("Expected filtered-out issue not to be shared")
+	}
+
+	if _, _, err := run("share", "create", projectKey, "--filter", "owner=me", "--output", outputPath); err == nil {
+		t.Error("Expected invalid filter to fail")
+	}
+	if _, _, err := run("share", "create", "NOPE"+projectKey, "--output", outputPath); err == nil {
+		t.Error("Expected missing project to fail")
+	}
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// NewIssueSnoozeCmd creates and returns the issue snooze command.
+func NewIssueSnoozeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snooze <id>",
+		Short: "Hide an issue from list until a date",
+		Long: `Hide an issue from buyruk list (and view run and search) until a date passes,
+e.g. while it waits on someone outside the project. list --include-snoozed
+lists snoozed issues too, and the issue itself is unchanged. --clear ends the
+snooze early.`,
+		Example: `  buyruk issue snooze CORE-12 --until monday
+  buyruk issue snooze CORE-12 --until 2w
+  buyruk issue snooze CORE-12 --clear`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeIssueArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveIssueArgs(cmd, args[:1]); err != nil {
+				return err
+			}
+			return snoozeIssue(args[0], cmd)
+		},
+	}
+
+	cmd.Flags().String("until", "", "When the issue is listed again (e.g. monday, 2024-06-01, 2w)")
+	cmd.Flags().Bool("clear", false, "End the snooze now")
+	cmd.MarkFlagsMutuallyExclusive("until", "clear")
+	cmd.MarkFlagsOneRequired("until", "clear")
+
+	return cmd
+}
+
+// snoozeIssue sets or clears the snooze of an issue and its index entry.
+func snoozeIssue(issueID string, cmd *cobra.Command) error {
+	projectKey, _, err := parseIssueID(cmd.Context(), issueID)
+	if err != nil {
+		return invalidf("cli: invalid issue ID %q: %w", issueID, err)
+	}
+
+	until := ""
+	if t, ok, err := getTimeFlag(cmd, "until"); err != nil {
+		return err
+	} else if ok {
+		if !t.After(storage.Now(cmd.Context())) {
+			return invalidf("cli: --until must be in the future")
+		}
+		until = t.UTC().Format(time.RFC3339)
+	}
+
+	issuePath, err := storage.IssuePath(cmd.Context(), projectKey, issueID)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+	}
+	indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	tx, err := storage.BeginProjectTx(cmd.Context(), projectKey, "snooze_issue", map[string]interface{}{
+		"issue_id": issueID,
+	})
+	if err != nil {
+		return fmt.Errorf("cli: failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var issue models.Issue
+	if err := storage.ReadJSON(cmd.Context(), issuePath, &issue); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return notFoundf("cli: issue %q not found", issueID)
+		}
+		return fmt.Errorf("cli: failed to load issue: %w", err)
+	}
+	if until == "" && issue.SnoozedUntil == "" {
+		fmt.Fprintf(successOut(cmd), "%s isn't snoozed\n", issueID)
+		return nil
+	}
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(cmd.Context(), indexPath, &index); err != nil {
+		return fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+
+	issue.SnoozedUntil = until
+	issue.UpdatedAt = storage.Timestamp(cmd.Context())
+	if err := tx.WriteJSON(issuePath, &issue); err != nil {
+		return fmt.Errorf("cli: failed to update issue: %w", err)
+	}
+	index.AddIssue(&issue)
+	if err := tx.WriteIndex(indexPath, &index); err != nil {
+		return fmt.Errorf("cli: failed to update project index: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("cli: failed to commit transaction: %w", err)
+	}
+
+	if until == "" {
+		fmt.Fprintf(successOut(cmd), "Unsnoozed %s\n", issueID)
+		return nil
+	}
+	fmt.Fprintf(successOut(cmd), "Snoozed %s until %s\n", issueID, ui.FormatTime(until, config.Location()))
+	return nil
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestIssueSnooze(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return strings.Join(strings.Fields(out.String()), " "), err
+	}
+	id := func(n string) string { return projectKey + "-" + n }
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "Waiting on vendor"},
+		{"issue", "create", "--project", projectKey, "--title", "Ready"},
+		{"issue", "snooze", id("1"), "--until", "monday", "--fixed-time", "2024-06-12T09:00:00Z"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	issue, err := loadLocalIssue(t.Context(), projectKey, id("1"))
+	if err != nil || issue == nil {
+		t.Fatalf("Failed to load issue: %v", err)
+	}
+	if !strings.HasPrefix(issue.SnoozedUntil, "2024-06-17") {
+		t.Errorf("Expected the issue snoozed until Monday 2024-06-17, got %q", issue.SnoozedUntil)
+	}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"list", "--project", projectKey, "--fixed-time", "2024-06-13T09:00:00Z"}, id("2")},
+		{[]string{"list", "--project", projectKey, "--include-snoozed", "--fixed-time", "2024-06-13T09:00:00Z"}, id("1") + " " + id("2")},
+		{[]string{"list", "--project", projectKey, "--fixed-time", "2024-06-18T09:00:00Z"}, id("1") + " " + id("2")},
+	}
+	for _, tt := range tests {
+		got, err := run(append(tt.args, "--sort", "id", "--template", "{{.ID}}")...)
+		if err != nil {
+			t.Errorf("%v failed: %v", tt.args, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%v = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+
+	if _, err := run("issue", "snooze", id("2"), "--until", "3d ago"); err == nil {
+		t.Error("Expected a snooze in the past to fail")
+	}
+	if _, err := run("issue", "snooze", id("2")); err == nil {
+		t.Error("Expected snooze without --until or --clear to fail")
+	}
+
+	if _, err := run("issue", "snooze", id("1"), "--clear"); err != nil {
+		t.Fatalf("snooze --clear failed: %v", err)
+	}
+	got, err := run("list", "--project", projectKey, "--sort", "id", "--template", "{{.ID}}", "--fixed-time", "2024-06-13T09:00:00Z")
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if got != id("1")+" "+id("2") {
+		t.Errorf("Expected the unsnoozed issue listed, got %q", got)
+	}
+}
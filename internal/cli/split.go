@@ -0,0 +1,453 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// maxRedirects bounds how many redirects are followed when resolving a moved issue.
+const maxRedirects = 10
+
+// NewProjectSplitCmd creates and returns the project split command.
+func NewProjectSplitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "split <key>",
+		Short: "Move an epic or a set of issues into a new project",
+		Long: `Move an epic and its issues (or an explicit list of issues) into a new project.
+
+Moved issues get new IDs in the target project. Dependencies and subtask links
+between moved issues are remapped, subtasks of moved issues move with them, and
+the source project keeps redirects so old IDs still resolve with 'view'. A moved
+epic follows the target key (CORE-E4 becomes CORE-UI-E4); issues moved without
+their epic lose the epic link.
+
+Examples:
+  buyruk project split CORE --epic CORE-E4 --to CORE-UI
+  buyruk project split CORE --issues CORE-3,CORE-7 --to CORE-API`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeProjectArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectKey := args[0]
+			return splitProject(projectKey, cmd)
+		},
+	}
+
+	cmd.Flags().String("epic", "", "Move this epic and all its issues")
+	cmd.Flags().String("issues", "", "Comma-separated list of issue IDs to move")
+	cmd.Flags().String("to", "", "Key of the new project (required)")
+	cmd.Flags().String("name", "", "Name of the new project (optional)")
+
+	return cmd
+}
+
+// splitProject moves an epic or a set of issues from one project into a new project.
+func splitProject(projectKey string, cmd *cobra.Command) error {
+	epicID, _ := cmd.Flags().GetString("epic")
+	issueList, _ := cmd.Flags().GetString("issues")
+	targetKey, _ := cmd.Flags().GetString("to")
+
+	if targetKey == "" {
+		return invalidf("cli: --to is required")
+	}
+	if epicID == "" && issueList == "" {
+		return invalidf("cli: --epic or --issues is required")
+	}
+	if !isValidProjectKey(targetKey) {
+		return invalidf("cli: invalid project key %q (must contain only uppercase letters, numbers, and hyphens)", targetKey)
+	}
+	if targetKey == projectKey {
+		return invalidf("cli: target project must differ from %q", projectKey)
+	}
+
+	// Create the target project; it is removed again if the split fails
+	if err := createProject(targetKey, cmd); err != nil {
+		return err
+	}
+	done := false
+	defer func() {
+		if !done {
+			removeSplitTarget(cmd.Context(), targetKey)
+		}
+	}()
+	if err := copyWorkflow(cmd.Context(), projectKey, targetKey); err != nil {
+		return err
+	}
+
+	cleanup, err := storage.AcquireLocks(cmd.Context(), projectKey, targetKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to acquire project locks: %w", err)
+	}
+	defer cleanup()
+
+	// Load source project index
+	indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(cmd.Context(), indexPath, &index); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return notFoundf("cli: project %q does not exist", projectKey)
+		}
+		return fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+	targetIndexPath, err := storage.ProjectIndexPath(cmd.Context(), targetKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	var targetIndex models.ProjectIndex
+	if err := storage.ReadIndex(cmd.Context(), targetIndexPath, &targetIndex); err != nil {
+		return fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+	targetIndex.IDMode, targetIndex.IDScheme = index.IDMode, index.IDScheme
+
+	// Load the epic being moved
+	var epic *models.Epic
+	var epicPath string
+	if epicID != "" {
+		if err := validateProjectEpicID(projectKey, epicID); err != nil {
+			return err
+		}
+		if epicPath, err = storage.EpicPath(cmd.Context(), projectKey, epicID); err != nil {
+			return fmt.Errorf("cli: failed to resolve epic path: %w", err)
+		}
+		epic = &models.Epic{}
+		if err := storage.ReadJSON(cmd.Context(), epicPath, epic); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return notFoundf("cli: epic %q not found", epicID)
+			}
+			return fmt.Errorf("cli: failed to load epic: %w", err)
+		}
+	}
+
+	// Select issues: by epic or explicit list, plus all their subtasks
+	selected := map[string]bool{}
+	for _, id := range splitList(issueList) {
+		if index.FindIssue(id) == nil {
+			return notFoundf("cli: issue %q not found in project %q", id, projectKey)
+		}
+		selected[id] = true
+	}
+	for _, entry := range index.Issues {
+		if epicID != "" && entry.EpicID == epicID {
+			selected[entry.ID] = true
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, entry := range index.Issues {
+			if entry.ParentID != "" && selected[entry.ParentID] && !selected[entry.ID] {
+				selected[entry.ID] = true
+				changed = true
+			}
+		}
+	}
+	if len(selected) == 0 && epic == nil {
+		return fmt.Errorf("cli: no issues to move")
+	}
+
+	// Load selected issues in sequence order and assign new IDs
+	moved, err := loadIssuesByID(cmd.Context(), projectKey, selected)
+	if err != nil {
+		return err
+	}
+	oldIDs := make([]string, len(moved))
+	mapping := make(map[string]string, len(moved))
+	for i, issue := range moved {
+		oldIDs[i] = issue.ID
+		if mapping[issue.ID], err = targetIndex.NewIssueID(targetKey, i+1, storage.Now(cmd.Context())); err != nil {
+			return err
+		}
+	}
+
+	errOut := cmd.ErrOrStderr()
+	now := storage.Timestamp(cmd.Context())
+
+	// The moved epic follows the target key, as in project rename
+	newEpicID := epicID
+	if strings.HasPrefix(epicID, projectKey+"-") {
+		newEpicID = targetKey + strings.TrimPrefix(epicID, projectKey)
+	}
+
+	// Moved issues with remapped IDs and relations, in place of the old ones
+	var writes []fileWrite
+	for _, issue := range moved {
+		oldID := issue.ID
+		issue.ID = mapping[oldID]
+		issue.RenameLinks(renameFromMapping(mapping))
+		if issue.ParentID != "" {
+			if newParent, ok := mapping[issue.ParentID]; ok {
+				issue.ParentID = newParent
+			} else {
+				fmt.Fprintf(errOut, "Warning: %s was a subtask of %s, which stays in %s; the parent link was removed\n", oldID, issue.ParentID, projectKey)
+				issue.ParentID = ""
+			}
+		}
+		if issue.EpicID != "" {
+			if epic != nil && issue.EpicID == epic.ID {
+				issue.EpicID = newEpicID
+			} else {
+				fmt.Fprintf(errOut, "Warning: %s was in epic %s, which stays in %s; the epic link was removed\n", oldID, issue.EpicID, projectKey)
+				issue.EpicID = ""
+			}
+		}
+		issue.UpdatedAt = now
+		if err := targetIndex.AssignUID(issue, storage.Now(cmd.Context())); err != nil {
+			return err
+		}
+
+		targetPath, err := storage.IssuePath(cmd.Context(), targetKey, issue.ID)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+		}
+		data, err := json.MarshalIndent(issue, "", "  ")
+		if err != nil {
+			return fmt.Errorf("cli: failed to marshal issue %s: %w", issue.ID, err)
+		}
+		writes = append(writes, fileWrite{path: targetPath, data: data})
+
+		sourcePath, err := storage.IssuePath(cmd.Context(), projectKey, oldID)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+		}
+		original, err := os.ReadFile(sourcePath)
+		if err != nil {
+			return fmt.Errorf("cli: failed to read issue %s: %w", oldID, err)
+		}
+		writes = append(writes, fileWrite{path: sourcePath, original: original})
+
+		index.RemoveIssue(oldID)
+		targetIndex.AddIssue(issue)
+	}
+
+	if epic != nil {
+		original, err := os.ReadFile(epicPath)
+		if err != nil {
+			return fmt.Errorf("cli: failed to read epic %s: %w", epicID, err)
+		}
+		epic.ID = newEpicID
+		epic.UpdatedAt = now
+		targetEpicPath, err := storage.EpicPath(cmd.Context(), targetKey, epic.ID)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve epic path: %w", err)
+		}
+		write, err := plannedJSONWrite(targetEpicPath, epic)
+		if err != nil {
+			return err
+		}
+		writes = append(writes, write, fileWrite{path: epicPath, original: original})
+		index.RemoveEpic(epicID)
+		targetIndex.SetEpic(epic)
+	}
+
+	// Point dependencies of the remaining issues at the new IDs
+	for _, entry := range index.Issues {
+		issuePath, err := storage.IssuePath(cmd.Context(), projectKey, entry.ID)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+		}
+		var issue models.Issue
+		if err := storage.ReadJSON(cmd.Context(), issuePath, &issue); err != nil {
+			fmt.Fprintf(errOut, "Warning: failed to load issue %s: %v\n", entry.ID, err)
+			continue
+		}
+		if !issue.RenameLinks(renameFromMapping(mapping)) {
+			continue
+		}
+		issue.UpdatedAt = now
+		write, err := plannedJSONWrite(issuePath, &issue)
+		if err != nil {
+			return err
+		}
+		writes = append(writes, write)
+	}
+
+	// Both indexes, and the redirects that keep old IDs resolving
+	index.UpdatedAt = now
+	targetIndex.UpdatedAt = now
+	redirectsPath, err := storage.RedirectsPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve redirects path: %w", err)
+	}
+	redirects := map[string]string{}
+	if err := storage.ReadJSON(cmd.Context(), redirectsPath, &redirects); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("cli: failed to load redirects: %w", err)
+	}
+	for oldID, newID := range mapping {
+		redirects[oldID] = newID
+	}
+	for _, file := range []struct {
+		path  string
+		index *models.ProjectIndex
+	}{{targetIndexPath, &targetIndex}, {indexPath, &index}} {
+		write, err := plannedIndexWrite(file.path, file.index)
+		if err != nil {
+			return err
+		}
+		writes = append(writes, write)
+	}
+	redirectsWrite, err := plannedJSONWrite(redirectsPath, redirects)
+	if err != nil {
+		return err
+	}
+	writes = append(writes, redirectsWrite)
+
+	// Apply everything under one transaction per project
+	for _, key := range []string{projectKey, targetKey} {
+		if err := storage.BeginTransaction(cmd.Context(), key, "split_project", map[string]interface{}{
+			"from": projectKey,
+			"to":   targetKey,
+		}); err != nil {
+			storage.RollbackTransaction(cmd.Context(), projectKey)
+			return fmt.Errorf("cli: failed to begin transaction: %w", err)
+		}
+	}
+	if err := writeFiles(cmd.Context(), writes); err != nil {
+		storage.RollbackTransaction(cmd.Context(), projectKey)
+		storage.RollbackTransaction(cmd.Context(), targetKey)
+		return err
+	}
+	for _, key := range []string{projectKey, targetKey} {
+		if err := storage.CommitTransaction(cmd.Context(), key); err != nil {
+			return fmt.Errorf("cli: failed to commit transaction: %w", err)
+		}
+	}
+	done = true
+
+	// Success message
+	out := successOut(cmd)
+	fmt.Fprintf(out, "Moved %d issues from %q to %q\n", len(moved), projectKey, targetKey)
+	for _, oldID := range oldIDs {
+		fmt.Fprintf(out, "  %s -> %s\n", oldID, mapping[oldID])
+	}
+	if epic != nil && newEpicID != epicID {
+		fmt.Fprintf(out, "  %s -> %s\n", epicID, newEpicID)
+	}
+
+	return nil
+}
+
+// removeSplitTarget removes the project a failed split created, so that the
+// split can be retried.
+func removeSplitTarget(ctx context.Context, targetKey string) {
+	if projectDir, err := storage.ProjectDir(ctx, targetKey); err == nil {
+		os.RemoveAll(projectDir)
+	}
+}
+
+// loadIssuesByID loads the given issues of a project, sorted by sequence number.
+func loadIssuesByID(ctx context.Context, projectKey string, ids map[string]bool) ([]*models.Issue, error) {
+	issues := make([]*models.Issue, 0, len(ids))
+	for id := range ids {
+		issuePath, err := storage.IssuePath(ctx, projectKey, id)
+		if err != nil {
+			return nil, fmt.Errorf("cli: failed to resolve issue path: %w", err)
+		}
+		var issue models.Issue
+		if err := storage.ReadJSON(ctx, issuePath, &issue); err != nil {
+			return nil, fmt.Errorf("cli: failed to load issue %s: %w", id, err)
+		}
+		issues = append(issues, &issue)
+	}
+
+	sort.Slice(issues, func(i, j int) bool {
+		_, seqI, _ := models.ParseIssueID(issues[i].ID)
+		_, seqJ, _ := models.ParseIssueID(issues[j].ID)
+		if seqI != seqJ {
+			return seqI < seqJ
+		}
+		return issues[i].ID < issues[j].ID
+	})
+	return issues, nil
+}
+
+// renameFromMapping returns a function that renames IDs found in mapping and
+// keeps the others unchanged.
+func renameFromMapping(mapping map[string]string) func(id string) string {
+	return func(id string) string {
+		if newID, ok := mapping[id]; ok {
+			return newID
+		}
+		return id
+	}
+}
+
+// copyWorkflow copies the workflow of one project to another, if it has one.
+func copyWorkflow(ctx context.Context, fromKey, toKey string) error {
+	fromPath, err := storage.WorkflowPath(ctx, fromKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve workflow path: %w", err)
+	}
+	var wf models.Workflow
+	if err := storage.ReadJSON(ctx, fromPath, &wf); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("cli: failed to load workflow: %w", err)
+	}
+
+	toPath, err := storage.WorkflowPath(ctx, toKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve workflow path: %w", err)
+	}
+	if err := storage.WriteJSONAtomic(ctx, toPath, &wf); err != nil {
+		return fmt.Errorf("cli: failed to write workflow: %w", err)
+	}
+	return nil
+}
+
+// resolveRedirect follows redirects left by project split and issue move for an issue ID.
+// Returns false if the issue was never moved.
+func resolveRedirect(ctx context.Context, issueID string) (string, bool) {
+	current := issueID
+	for i := 0; i < maxRedirects; i++ {
+		projectKey, _, err := parseIssueID(ctx, current)
+		if err != nil {
+			break
+		}
+		redirectsPath, err := storage.RedirectsPath(ctx, projectKey)
+		if err != nil {
+			break
+		}
+		var redirects map[string]string
+		if err := storage.ReadJSON(ctx, redirectsPath, &redirects); err != nil {
+			break
+		}
+		next, ok := redirects[current]
+		if !ok {
+			break
+		}
+		current = next
+	}
+	return current, current != issueID
+}
+
+// maxRedirectSequence returns the highest sequence number of the issues moved out
+// of a project, archived, or in the trash, so that their IDs (which keep
+// resolving or can be restored) are not reused.
+func maxRedirectSequence(ctx context.Context, projectKey string) int {
+	maxSeq := max(maxArchivedSequence(ctx, projectKey), maxTrashedSequence(ctx, projectKey))
+	redirectsPath, err := storage.RedirectsPath(ctx, projectKey)
+	if err != nil {
+		return maxSeq
+	}
+	var redirects map[string]string
+	if err := storage.ReadJSON(ctx, redirectsPath, &redirects); err != nil {
+		return maxSeq
+	}
+	for oldID := range redirects {
+		if _, seq, err := models.ParseIssueID(oldID); err == nil && models.IsIssueIDOf(oldID, projectKey) && seq > maxSeq {
+			maxSeq = seq
+		}
+	}
+	return maxSeq
+}
@@ -0,0 +1,216 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestSplitProject_ByEpic(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	targetKey := projectKey + "-UI"
+	defer func() {
+		for _, key := range []string{projectKey, targetKey} {
+			projectDir, _ := storage.ProjectDir(t.Context(), key)
+			os.RemoveAll(projectDir)
+		}
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		buf := new(bytes.Buffer)
+		cmd.SetOut(buf)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return buf.String(), err
+	}
+
+	if _, err := run("project", "create", projectKey); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := run("epic", "create", "--project", projectKey, "--title", "UI", "--id", projectKey+"-E1"); err != nil {
+		t.Fatalf("Failed to create epic: %v", err)
+	}
+
+	// KEY-1 stays; KEY-2 (in epic) and its subtask KEY-3 move; KEY-4 stays and depends on KEY-2
+	steps := [][]string{
+		{"issue", "create", "--project", projectKey, "--title", "Backend"},
+		{"issue", "create", "--project", projectKey, "--title", "Screen", "--epic", projectKey + "-E1"},
+		{"issue", "create", "--project", projectKey, "--title", "Button", "--parent", projectKey + "-2"},
+		{"issue", "create", "--project", projectKey, "--title", "Release"},
+		{"issue", "link", projectKey + "-4", projectKey + "-2"},
+		{"issue", "link", projectKey + "-3", projectKey + "-1"},
+	}
+	for _, args := range steps {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	output, err := run("project", "split", projectKey, "--epic", projectKey+"-E1", "--to", targetKey)
+	if err != nil {
+		t.Fatalf("project split failed: %v", err)
+	}
+	if !strings.Contains(output, projectKey+"-2 -> "+targetKey+"-1") {
+		t.Errorf("Expected ID mapping in output, got: %s", output)
+	}
+
+	readIssue := func(key, id string) *models.Issue {
+		t.Helper()
+		path, _ := storage.IssuePath(t.Context(), key, id)
+		var issue models.Issue
+		if err := storage.ReadJSON(t.Context(), path, &issue); err != nil {
+			t.Fatalf("Failed to read issue %s: %v", id, err)
+		}
+		return &issue
+	}
+
+	// Subtask moved with its parent, links remapped
+	child := readIssue(targetKey, targetKey+"-2")
+	if child.ParentID != targetKey+"-1" {
+		t.Errorf("Moved subtask ParentID = %q, want %q", child.ParentID, targetKey+"-1")
+	}
+	if !slices.Equal(child.BlockedBy, []string{projectKey + "-1"}) {
+		t.Errorf("Moved subtask BlockedBy = %v, want dependency on remaining issue", child.BlockedBy)
+	}
+
+	// Remaining issue now depends on the new ID
+	release := readIssue(projectKey, projectKey+"-4")
+	if !slices.Equal(release.BlockedBy, []string{targetKey + "-1"}) {
+		t.Errorf("Remaining issue BlockedBy = %v, want [%s-1]", release.BlockedBy, targetKey)
+	}
+
+	// Epic moved
+	epicPath, _ := storage.EpicPath(t.Context(), targetKey, targetKey+"-E1")
+	if _, err := os.Stat(epicPath); err != nil {
+		t.Errorf("Expected epic in target project: %v", err)
+	}
+	if screen := readIssue(targetKey, targetKey+"-1"); screen.EpicID != targetKey+"-E1" {
+		t.Errorf("Moved issue EpicID = %q, want %s-E1", screen.EpicID, targetKey)
+	}
+	oldEpicPath, _ := storage.EpicPath(t.Context(), projectKey, projectKey+"-E1")
+	if _, err := os.Stat(oldEpicPath); !os.IsNotExist(err) {
+		t.Error("Expected epic to be removed from source project")
+	}
+
+	// Source index no longer lists moved issues
+	indexPath, _ := storage.ProjectIndexPath(t.Context(), projectKey)
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(t.Context(), indexPath, &index); err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	if len(index.Issues) != 2 {
+		t.Errorf("Source index has %d issues, want 2", len(index.Issues))
+	}
+
+	// Old IDs redirect to the new ones
+	output, err = run("view", projectKey+"-2", "--format", "lson")
+	if err != nil {
+		t.Fatalf("view of moved issue failed: %v", err)
+	}
+	if !strings.Contains(output, "@ID: "+targetKey+"-1") {
+		t.Errorf("Expected redirect to moved issue, got: %s", output)
+	}
+}
+
+func TestSplitProject_Validation(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	rootCmd := NewRootCmd()
+	rootCmd.SetArgs([]string{"project", "create", projectKey})
+	rootCmd.SetOut(new(bytes.Buffer))
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	tests := [][]string{
+		{"project", "split", projectKey, "--epic", projectKey + "-E1"},
+		{"project", "split", projectKey, "--to", projectKey + "-NEW"},
+		{"project", "split", projectKey, "--epic", projectKey + "-E404", "--to", projectKey + "-NEW"},
+		{"project", "split", projectKey, "--issues", projectKey + "-404", "--to", projectKey + "-NEW"},
+		{"project", "split", projectKey, "--epic", projectKey + "-E1", "--to", projectKey},
+	}
+	for _, args := range tests {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+		if err := cmd.Execute(); err == nil {
+			t.Errorf("%v should fail", args)
+		}
+	}
+
+	newDir, _ := storage.ProjectDir(t.Context(), projectKey+"-NEW")
+	if _, err := os.Stat(newDir); !os.IsNotExist(err) {
+		t.Error("Failed split should not create the target project")
+		os.RemoveAll(newDir)
+	}
+}
+
+func TestSplitProject_FailureLeavesNothingBehind(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	targetKey := projectKey + "-UI"
+	defer func() {
+		for _, key := range []string{projectKey, targetKey} {
+			projectDir, _ := storage.ProjectDir(t.Context(), key)
+			os.RemoveAll(projectDir)
+		}
+	}()
+
+	steps := [][]string{
+		{"project", "create", projectKey},
+		{"epic", "create", "--project", projectKey, "--title", "UI", "--id", projectKey + "-E1"},
+		{"issue", "create", "--project", projectKey, "--title", "Screen", "--epic", projectKey + "-E1"},
+		{"issue", "create", "--project", projectKey, "--title", "Dialog", "--epic", projectKey + "-E1"},
+		// The source refuses the removals, after the target issues are written
+		{"project", "lock", projectKey},
+	}
+	for _, args := range steps {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		cmd.SetOut(new(bytes.Buffer))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"project", "split", projectKey, "--epic", projectKey + "-E1", "--to", targetKey})
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("project split of a read-only project should fail")
+	}
+
+	indexPath, _ := storage.ProjectIndexPath(t.Context(), projectKey)
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(t.Context(), indexPath, &index); err != nil {
+		t.Fatalf("Failed to read project index: %v", err)
+	}
+	if len(index.Issues) != 2 || index.FindEpic(projectKey+"-E1") == nil {
+		t.Errorf("Source index changed by a failed split: %+v", index)
+	}
+	for _, id := range []string{projectKey + "-1", projectKey + "-2"} {
+		issuePath, _ := storage.IssuePath(t.Context(), projectKey, id)
+		if _, err := os.Stat(issuePath); err != nil {
+			t.Errorf("Issue %s removed by a failed split: %v", id, err)
+		}
+	}
+	targetDir, _ := storage.ProjectDir(t.Context(), targetKey)
+	if _, err := os.Stat(targetDir); !os.IsNotExist(err) {
+		t.Error("Failed split should remove the target project")
+	}
+	if pending, _, err := storage.CheckPendingTransaction(t.Context(), projectKey); err != nil || pending {
+		t.Errorf("Failed split left a pending transaction (err %v)", err)
+	}
+}
@@ -0,0 +1,383 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// NewSprintCmd creates and returns the sprint command.
+func NewSprintCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sprint",
+		Short: "Plan issues into sprints",
+		Long: `Plan issues into time-boxed sprints, for burndown and velocity reports.
+
+Sprints are stored in projects/[KEY]/sprints.json. An issue can be planned into
+more than one sprint, e.g. when unfinished work is carried over.`,
+	}
+
+	cmd.AddCommand(NewSprintCreateCmd())
+	cmd.AddCommand(NewSprintAddCmd())
+	cmd.AddCommand(NewSprintRemoveCmd())
+	cmd.AddCommand(NewSprintListCmd())
+	cmd.AddCommand(NewSprintViewCmd())
+
+	return cmd
+}
+
+// NewSprintCreateCmd creates and returns the sprint create command.
+func NewSprintCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <sprint-id>",
+		Short: "Create a sprint, or change its dates or goal",
+		Long: `Create a sprint from --start to --end (both days included), or change the
+dates or goal of an existing sprint.`,
+		Example: `  buyruk sprint create S-3 --start 2024-06-03 --end 2024-06-14 --goal "Ship login"
+  buyruk sprint create S-3 --end 2024-06-17`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSprintArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return createSprint(args[0], cmd)
+		},
+	}
+
+	cmd.Flags().String("start", "", "First day of the sprint (e.g. 2024-06-03, monday; required for new sprints)")
+	cmd.Flags().String("end", "", "Last day of the sprint (e.g. 2024-06-14, 2w; required for new sprints)")
+	cmd.Flags().String("goal", "", "Sprint goal")
+
+	return cmd
+}
+
+// createSprint creates a sprint or updates its dates and goal.
+func createSprint(sprintID string, cmd *cobra.Command) error {
+	if err := models.ValidateSprintID(sprintID); err != nil {
+		return invalidf("cli: %w", err)
+	}
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	if _, err := loadSprints(cmd.Context(), projectKey); err != nil {
+		return err
+	}
+
+	dates := map[string]string{}
+	for _, name := range []string{"start", "end"} {
+		t, ok, err := getTimeFlag(cmd, name)
+		if err != nil {
+			return err
+		}
+		if ok {
+			dates[name] = t.In(config.Location()).Format(time.DateOnly)
+		}
+	}
+	goal, _ := cmd.Flags().GetString("goal")
+
+	sprintsPath, err := storage.SprintsPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve sprints path: %w", err)
+	}
+
+	created := false
+	var sprints models.Sprints
+	if err := storage.UpdateJSONAtomic(cmd.Context(), sprintsPath, &sprints, func(v interface{}) error {
+		s := v.(*models.Sprints)
+		sprint := models.Sprint{ID: sprintID}
+		if existing := s.Find(sprintID); existing != nil {
+			sprint = *existing
+		} else if dates["start"] == "" || dates["end"] == "" {
+			return fmt.Errorf("cli: --start and --end are required for a new sprint")
+		} else {
+			created = true
+		}
+		if start, ok := dates["start"]; ok {
+			sprint.Start = start
+		}
+		if end, ok := dates["end"]; ok {
+			sprint.End = end
+		}
+		if cmd.Flags().Changed("goal") {
+			sprint.Goal = goal
+		}
+		s.Set(sprint)
+		return s.Validate()
+	}); err != nil {
+		return fmt.Errorf("cli: failed to update sprints: %w", err)
+	}
+
+	sprint := sprints.Find(sprintID)
+	out := successOut(cmd)
+	if created {
+		fmt.Fprintf(out, "Created sprint %s (%s to %s)\n", sprintID, sprint.Start, sprint.End)
+	} else {
+		fmt.Fprintf(out, "Updated sprint %s (%s to %s)\n", sprintID, sprint.Start, sprint.End)
+	}
+	return nil
+}
+
+// NewSprintAddCmd creates and returns the sprint add command.
+func NewSprintAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "add <sprint-id> <issue-id>...",
+		Short:   "Plan issues into a sprint",
+		Example: "  buyruk sprint add S-3 CORE-12 CORE-14",
+		Args:    cobra.MinimumNArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return completeSprintIDs(cmd, args, toComplete)
+			}
+			return completeIssueIDs(cmd, args, toComplete)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveIssueArgs(cmd, args[1:]); err != nil {
+				return err
+			}
+			return updateSprintIssues(args[0], args[1:], true, cmd)
+		},
+	}
+
+	return cmd
+}
+
+// NewSprintRemoveCmd creates and returns the sprint remove command.
+func NewSprintRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove <sprint-id> <issue-id>...",
+		Short: "Remove issues from a sprint",
+		Long:  "Remove issues from a sprint. The issues themselves are not changed.",
+		Args:  cobra.MinimumNArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return completeSprintIDs(cmd, args, toComplete)
+			}
+			return completeIssueIDs(cmd, args, toComplete)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveIssueArgs(cmd, args[1:]); err != nil {
+				return err
+			}
+			return updateSprintIssues(args[0], args[1:], false, cmd)
+		},
+	}
+
+	return cmd
+}
+
+// updateSprintIssues adds issues to or removes them from a sprint.
+func updateSprintIssues(sprintID string, issueIDs []string, add bool, cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	if add {
+		for _, issueID := range issueIDs {
+			issue, err := loadLocalIssue(cmd.Context(), projectKey, issueID)
+			if err != nil {
+				return err
+			}
+			if issue == nil {
+				return notFoundf("cli: issue %q not found in project %s", issueID, projectKey)
+			}
+		}
+	}
+
+	sprintsPath, err := storage.SprintsPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve sprints path: %w", err)
+	}
+	if _, err := os.Stat(sprintsPath); os.IsNotExist(err) {
+		return notFoundf("cli: sprint %q not found", sprintID)
+	}
+
+	var sprints models.Sprints
+	if err := storage.UpdateJSONAtomic(cmd.Context(), sprintsPath, &sprints, func(v interface{}) error {
+		sprint := v.(*models.Sprints).Find(sprintID)
+		if sprint == nil {
+			return notFoundf("cli: sprint %q not found", sprintID)
+		}
+		for _, issueID := range issueIDs {
+			planned := slices.Contains(sprint.Issues, issueID)
+			switch {
+			case add && !planned:
+				sprint.Issues = append(sprint.Issues, issueID)
+			case !add && planned:
+				sprint.Issues = slices.DeleteFunc(sprint.Issues, func(id string) bool { return id == issueID })
+			case !add:
+				return notFoundf("cli: issue %s is not in sprint %s", issueID, sprintID)
+			}
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("cli: failed to update sprints: %w", err)
+	}
+
+	out := successOut(cmd)
+	if add {
+		fmt.Fprintf(out, "Added %d issue(s) to sprint %s\n", len(issueIDs), sprintID)
+	} else {
+		fmt.Fprintf(out, "Removed %d issue(s) from sprint %s\n", len(issueIDs), sprintID)
+	}
+	return nil
+}
+
+// NewSprintListCmd creates and returns the sprint list command.
+func NewSprintListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the sprints of a project",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listSprints(cmd)
+		},
+	}
+
+	return cmd
+}
+
+// listSprints renders the sprints of a project in the resolved output format.
+func listSprints(cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	sprints, err := loadSprints(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+
+	w := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(sprints.Sprints)
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(w, sprints.Sprints)
+	case config.DefaultFormatLSON:
+		for _, sprint := range sprints.Sprints {
+			fmt.Fprintf(w, "@SPRINT: %s|%s|%s|%d\n", sprint.ID, sprint.Start, sprint.End, len(sprint.Issues))
+		}
+	default:
+		if len(sprints.Sprints) == 0 {
+			fmt.Fprintf(w, "No sprints (create one with `buyruk sprint create S-1 --start monday --end 2w`)\n")
+			return nil
+		}
+		styles := ui.NewStyles()
+		for _, sprint := range sprints.Sprints {
+			line := fmt.Sprintf("%s  %s to %s  %d issue(s)", styles.ID(sprint.ID), sprint.Start, sprint.End, len(sprint.Issues))
+			if sprint.Goal != "" {
+				line += "  " + styles.Title(sprint.Goal)
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+	return nil
+}
+
+// NewSprintViewCmd creates and returns the sprint view command.
+func NewSprintViewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "view <sprint-id>",
+		Short: "Show a sprint with the progress of its issues",
+		Long: `Show the dates and goal of a sprint with the progress of the issues planned
+into it: how many are done, their story points and estimates in all and done,
+and the open issues.`,
+		Example:           "  buyruk sprint view S-3",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSprintArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return viewSprint(args[0], cmd)
+		},
+	}
+
+	return cmd
+}
+
+// SprintWithProgress is a sprint with the progress of its issues, for JSON
+// and YAML output.
+type SprintWithProgress struct {
+	models.Sprint
+	Progress *EpicProgress `json:"progress"`
+}
+
+// viewSprint shows a sprint with the progress of its issues.
+func viewSprint(sprintID string, cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	sprints, err := loadSprints(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	sprint := sprints.Find(sprintID)
+	if sprint == nil {
+		return notFoundf("cli: sprint %q not found", sprintID)
+	}
+	progress, err := loadProgress(cmd.Context(), projectKey, []string{sprint.ID}, func(entry *models.IndexEntry) string {
+		if slices.Contains(sprint.Issues, entry.ID) {
+			return sprint.ID
+		}
+		return ""
+	})
+	if err != nil {
+		return err
+	}
+
+	w := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(SprintWithProgress{*sprint, progress[sprint.ID]})
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(w, SprintWithProgress{*sprint, progress[sprint.ID]})
+	case config.DefaultFormatLSON:
+		fmt.Fprintf(w, "@SPRINT: %s|%s|%s|%d\n", sprint.ID, sprint.Start, sprint.End, len(sprint.Issues))
+		if sprint.Goal != "" {
+			fmt.Fprintf(w, "@GOAL: %s\n", sprint.Goal)
+		}
+	default:
+		styles := ui.NewStyles()
+		fmt.Fprintf(w, "%s  %s to %s\n", styles.ID(sprint.ID), sprint.Start, sprint.End)
+		if sprint.Goal != "" {
+			fmt.Fprintf(w, "%s: %s\n", styles.Label("Goal"), sprint.Goal)
+		}
+		fmt.Fprintln(w)
+	}
+	renderEpicProgress(progress[sprint.ID], true, cmd, w)
+	return nil
+}
+
+// loadSprints loads the sprints of a project. Projects without sprints have
+// none.
+func loadSprints(ctx context.Context, projectKey string) (*models.Sprints, error) {
+	projectDir, err := storage.ProjectDir(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve project directory: %w", err)
+	}
+	if _, err := os.Stat(projectDir); os.IsNotExist(err) {
+		return nil, notFoundf("cli: project %q does not exist", projectKey)
+	}
+
+	sprintsPath, err := storage.SprintsPath(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve sprints path: %w", err)
+	}
+	sprints := &models.Sprints{Sprints: []models.Sprint{}}
+	if err := storage.ReadJSON(ctx, sprintsPath, sprints); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("cli: failed to load sprints: %w", err)
+	}
+	return sprints, nil
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestSprint(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(append(args, "--project", projectKey, "--no-hooks"))
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	mustRun := func(args ...string) string {
+		t.Helper()
+		out, err := run(args...)
+		if err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+		return out
+	}
+
+	mustRun("project", "create", projectKey)
+	mustRun("issue", "create", "--title", "Login page")
+	mustRun("issue", "create", "--title", "Signup page")
+
+	if _, err := run("sprint", "create", "S-1", "--start", "2024-06-03"); err == nil || !strings.Contains(err.Error(), "--end are required") {
+		t.Errorf("Expected a new sprint without --end to fail, got %v", err)
+	}
+	mustRun("sprint", "create", "S-1", "--start", "2024-06-03", "--end", "2024-06-14")
+	if out := mustRun("sprint", "create", "S-1", "--end", "2024-06-17", "--goal", "Ship login"); !strings.Contains(out, "Updated sprint S-1 (2024-06-03 to 2024-06-17)") {
+		t.Errorf("Expected the sprint to be updated, got %q", out)
+	}
+	if _, err := run("sprint", "create", "S-2", "--start", "2024-06-14", "--end", "2024-06-03"); err == nil {
+		t.Error("Expected a sprint ending before it starts to fail")
+	}
+
+	mustRun("sprint", "add", "S-1", projectKey+"-1", projectKey+"-2")
+	mustRun("sprint", "add", "S-1", projectKey+"-1")
+	if _, err := run("sprint", "add", "S-1", projectKey+"-9"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected adding a missing issue to fail, got %v", err)
+	}
+	if _, err := run("sprint", "add", "S-9", projectKey+"-1"); err == nil || !strings.Contains(err.Error(), `sprint "S-9" not found`) {
+		t.Errorf("Expected adding to a missing sprint to fail, got %v", err)
+	}
+	mustRun("sprint", "remove", "S-1", projectKey+"-2")
+	if _, err := run("sprint", "remove", "S-1", projectKey+"-2"); err == nil {
+		t.Error("Expected removing an issue not in the sprint to fail")
+	}
+
+	sprints, err := loadSprints(t.Context(), projectKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sprint := sprints.Find("S-1")
+	if sprint == nil || sprint.Goal != "Ship login" || len(sprint.Issues) != 1 || sprint.Issues[0] != projectKey+"-1" {
+		t.Errorf("S-1 = %+v, want goal and only %s-1", sprint, projectKey)
+	}
+	if out := mustRun("sprint", "list"); !strings.Contains(out, "S-1  2024-06-03 to 2024-06-17  1 issue(s)  Ship login") {
+		t.Errorf("Unexpected sprint list:\n%s", out)
+	}
+}
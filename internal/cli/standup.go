@@ -0,0 +1,296 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// Standup is what a user completed since a time, is working on, and is
+// blocked on, by project.
+type Standup struct {
+	User     string           `json:"user"`
+	Since    string           `json:"since"`
+	Projects []StandupProject `json:"projects"`
+}
+
+// StandupProject is the part of a standup in one project.
+type StandupProject struct {
+	Project   string        `json:"project"`
+	Completed []StandupItem `json:"completed"`
+	Doing     []StandupItem `json:"doing"`
+	Blocked   []StandupItem `json:"blocked"`
+}
+
+// StandupItem is an issue in a standup.
+type StandupItem struct {
+	ID        string        `json:"id"`
+	Title     string        `json:"title"`
+	Status    string        `json:"status"`
+	Blockers  []BlockerInfo `json:"blockers,omitempty"`
+	updatedAt string
+}
+
+// NewStandupCmd creates and returns the standup command.
+func NewStandupCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "standup",
+		Short: "Summarize your work for a standup",
+		Long: `Summarize, as Markdown for pasting into chat, the issues a user completed
+since --since (yesterday by default), the ones in progress, and the open ones
+waiting on a blocker, grouped by project. All projects are included, or only
+--project.
+
+Issues count as the user's when they are assigned to them or, for completed
+issues, when they moved them to done. Without --user, that is you: the name
+and email changes are attributed to (user.name and user.email, or git's), so
+an issue assigned to "alice" is yours with the email alice@example.com.`,
+		Example: `  buyruk standup
+  buyruk standup --since "3d ago" --user alice
+  buyruk standup --project CORE --format json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return showStandup(cmd)
+		},
+	}
+
+	cmd.Flags().String("since", "yesterday", "Include issues completed since then (e.g. yesterday, monday, 2024-06-01)")
+	cmd.Flags().String("user", "", "User to report on (default: you)")
+
+	return cmd
+}
+
+// showStandup builds and prints the standup of a user.
+func showStandup(cmd *cobra.Command) error {
+	since, _, err := getTimeFlag(cmd, "since")
+	if err != nil {
+		return err
+	}
+	names, user, err := standupUser(cmd)
+	if err != nil {
+		return err
+	}
+
+	var projectKeys []string
+	if projectKey, _ := cmd.Flags().GetString("project"); projectKey != "" {
+		projectKeys = []string{projectKey}
+	} else {
+		keys, err := storage.ListProjects(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("cli: failed to list projects: %w", err)
+		}
+		projectKeys = keys
+	}
+
+	standup := &Standup{User: user, Since: since.UTC().Format(time.RFC3339), Projects: []StandupProject{}}
+	now := storage.Now(cmd.Context())
+	for _, projectKey := range projectKeys {
+		project, err := buildStandupProject(cmd.Context(), projectKey, names, since, now, cmd.ErrOrStderr())
+		if err != nil {
+			return err
+		}
+		if len(project.Completed)+len(project.Doing)+len(project.Blocked) > 0 {
+			standup.Projects = append(standup.Projects, *project)
+		}
+	}
+	return writeStandup(cmd, standup)
+}
+
+// standupUser returns the names an issue's assignee or a change's author is
+// matched against, and the user to show in the heading.
+func standupUser(cmd *cobra.Command) ([]string, string, error) {
+	if user, _ := cmd.Flags().GetString("user"); user != "" {
+		if err := models.ValidateUser(user); err != nil {
+			return nil, "", invalidf("cli: %w", err)
+		}
+		return []string{user}, user, nil
+	}
+	actor := currentActor(cmd.Context())
+	if actor == "" {
+		return nil, "", invalidf("cli: who you are is unknown (give --user, or set user.name or user.email)")
+	}
+	var names []string
+	if name := models.ActorName(actor); name != "" && !strings.Contains(name, "@") {
+		names = append(names, name)
+	}
+	if _, email, ok := strings.Cut(actor, "<"); ok {
+		if local, _, ok := strings.Cut(email, "@"); ok && local != "" {
+			names = append(names, local)
+		}
+	}
+	return names, models.ActorName(actor), nil
+}
+
+// matchesUser reports whether an assignee or a change's author ("Name
+// <email>") is one of names.
+func matchesUser(names []string, who string) bool {
+	if who == "" {
+		return false
+	}
+	candidates := []string{who, models.ActorName(who)}
+	if _, email, ok := strings.Cut(who, "<"); ok {
+		local, _, _ := strings.Cut(email, "@")
+		candidates = append(candidates, local)
+	}
+	for _, name := range names {
+		for _, candidate := range candidates {
+			if strings.EqualFold(name, candidate) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// buildStandupProject collects the issues of a project that belong in a
+// standup.
+func buildStandupProject(ctx context.Context, projectKey string, names []string, since, now time.Time, errOut io.Writer) (*StandupProject, error) {
+	wf, err := loadWorkflow(ctx, projectKey)
+	if err != nil {
+		return nil, err
+	}
+	files, err := storage.ReadAllIssues(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to read issues of %s: %w", projectKey, err)
+	}
+
+	project := &StandupProject{
+		Project:   projectKey,
+		Completed: []StandupItem{},
+		Doing:     []StandupItem{},
+		Blocked:   []StandupItem{},
+	}
+	lookup := newBlockerLookup(projectKey, wf)
+	for _, file := range files {
+		if file.Err != nil {
+			fmt.Fprintf(errOut, "Warning: failed to load issue %s\n", file.ID)
+			continue
+		}
+		issue := file.Issue
+		item := StandupItem{ID: issue.ID, Title: issue.Title, Status: issue.Status, updatedAt: issue.UpdatedAt}
+		assigned := matchesUser(names, issue.Assignee)
+
+		if wf.IsDoneStatus(issue.Status) {
+			if doneAt(issue, wf, now) && !doneAt(issue, wf, since) && (assigned || matchesUser(names, doneBy(issue, wf))) {
+				project.Completed = append(project.Completed, item)
+			}
+			continue
+		}
+		if !assigned {
+			continue
+		}
+		for _, id := range issue.BlockedBy {
+			if blocker, ok := lookup.find(ctx, id); ok && !blocker.done {
+				item.Blockers = append(item.Blockers, blocker.BlockerInfo)
+			}
+		}
+		switch {
+		case len(item.Blockers) > 0:
+			project.Blocked = append(project.Blocked, item)
+		case issue.Status != wf.DefaultStatus():
+			project.Doing = append(project.Doing, item)
+		}
+	}
+	sortStandupItems(project.Completed)
+	sortStandupItems(project.Doing)
+	sortStandupItems(project.Blocked)
+	return project, nil
+}
+
+// doneBy returns who last moved an issue to a done status, from its history.
+func doneBy(issue *models.Issue, wf *models.Workflow) string {
+	by := ""
+	for _, event := range issue.History {
+		if event.To != "" && wf.IsDoneStatus(event.To) {
+			by = event.By
+		}
+	}
+	if by == "" && len(issue.History) == 0 {
+		return issue.UpdatedBy
+	}
+	return by
+}
+
+// sortStandupItems orders items by when they last changed, most recent first.
+func sortStandupItems(items []StandupItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].updatedAt > items[j].updatedAt
+	})
+}
+
+// writeStandup prints a standup in the resolved output format; the modern
+// format is Markdown.
+func writeStandup(cmd *cobra.Command, standup *Standup) error {
+	out := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		data, err := json.MarshalIndent(standup, "", "  ")
+		if err != nil {
+			return fmt.Errorf("cli: failed to marshal standup: %w", err)
+		}
+		fmt.Fprintln(out, string(data))
+		return nil
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(out, standup)
+	case config.DefaultFormatLSON:
+		for _, project := range standup.Projects {
+			fmt.Fprintf(out, "@PROJECT: %s\n", project.Project)
+			for _, item := range project.Completed {
+				fmt.Fprintf(out, "@COMPLETED: %s|%s\n", item.ID, item.Title)
+			}
+			for _, item := range project.Doing {
+				fmt.Fprintf(out, "@DOING: %s|%s\n", item.ID, item.Title)
+			}
+			for _, item := range project.Blocked {
+				fmt.Fprintf(out, "@BLOCKED: %s|%s|%s\n", item.ID, item.Title, strings.Join(blockerIDs(item.Blockers), ","))
+			}
+			fmt.Fprintln(out)
+		}
+		return nil
+	}
+
+	fmt.Fprintf(out, "## Standup: %s (since %s)\n", standup.User, ui.FormatTime(standup.Since, config.Location()))
+	if len(standup.Projects) == 0 {
+		fmt.Fprintln(out, "\nNothing completed, in progress, or blocked.")
+		return nil
+	}
+	for _, project := range standup.Projects {
+		fmt.Fprintf(out, "\n### %s\n", project.Project)
+		section := func(heading string, items []StandupItem) {
+			if len(items) == 0 {
+				return
+			}
+			fmt.Fprintf(out, "\n**%s**\n", heading)
+			for _, item := range items {
+				fmt.Fprintf(out, "- %s %s", item.ID, item.Title)
+				if len(item.Blockers) > 0 {
+					fmt.Fprintf(out, " (blocked by %s)", strings.Join(blockerIDs(item.Blockers), ", "))
+				}
+				fmt.Fprintln(out)
+			}
+		}
+		section("Completed", project.Completed)
+		section("In progress", project.Doing)
+		section("Blocked", project.Blocked)
+	}
+	return nil
+}
+
+// blockerIDs returns the IDs of blockers.
+func blockerIDs(blockers []BlockerInfo) []string {
+	ids := make([]string, len(blockers))
+	for i, blocker := range blockers {
+		ids[i] = blocker.ID
+	}
+	return ids
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestStandup(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	id := func(n string) string { return projectKey + "-" + n }
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "Login page", "--assignee", "alice"},
+		{"issue", "create", "--project", projectKey, "--title", "Old fix", "--assignee", "alice"},
+		{"issue", "create", "--project", projectKey, "--title", "Payment API", "--assignee", "alice"},
+		{"issue", "create", "--project", projectKey, "--title", "Checkout", "--assignee", "alice"},
+		{"issue", "create", "--project", projectKey, "--title", "Backlog item", "--assignee", "alice"},
+		{"issue", "create", "--project", projectKey, "--title", "Bob's work", "--assignee", "bob"},
+		{"issue", "update", id("2"), "--status", "DONE", "--fixed-time", "2024-06-10T09:00:00Z"},
+		{"issue", "update", id("1"), "--status", "DONE", "--fixed-time", "2024-06-14T15:00:00Z"},
+		{"issue", "update", id("3"), "--status", "DOING", "--fixed-time", "2024-06-14T10:00:00Z"},
+		{"issue", "update", id("6"), "--status", "DONE", "--fixed-time", "2024-06-14T11:00:00Z"},
+		{"issue", "link", id("4"), id("6")},
+		{"issue", "link", id("4"), id("3")},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	out, err := run("standup", "--user", "alice", "--project", projectKey, "--format", "json", "--fixed-time", "2024-06-15T09:00:00Z")
+	if err != nil {
+		t.Fatalf("standup failed: %v", err)
+	}
+	var standup Standup
+	if err := json.Unmarshal([]byte(out), &standup); err != nil {
+		t.Fatalf("Failed to parse standup: %v\n%s", err, out)
+	}
+	if len(standup.Projects) != 1 {
+		t.Fatalf("Expected one project, got %+v", standup.Projects)
+	}
+	project := standup.Projects[0]
+	ids := func(items []StandupItem) string {
+		var ids []string
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+		return strings.Join(ids, ",")
+	}
+	if got := ids(project.Completed); got != id("1") {
+		t.Errorf("Expected only %s completed since yesterday, got %s", id("1"), got)
+	}
+	if got := ids(project.Doing); got != id("3") {
+		t.Errorf("Expected %s in progress, got %s", id("3"), got)
+	}
+	if got := ids(project.Blocked); got != id("4") {
+		t.Errorf("Expected %s blocked, got %s", id("4"), got)
+	} else if blockers := project.Blocked[0].Blockers; len(blockers) != 1 || blockers[0].ID != id("3") {
+		t.Errorf("Expected %s to be blocked only by the open %s, got %+v", id("4"), id("3"), blockers)
+	}
+
+	out, err = run("standup", "--user", "alice", "--project", projectKey, "--since", "2024-06-01", "--fixed-time", "2024-06-15T09:00:00Z")
+	if err != nil {
+		t.Fatalf("standup failed: %v", err)
+	}
+	for _, want := range []string{
+		"### " + projectKey,
+		"**Completed**\n- " + id("1") + " Login page\n- " + id("2") + " Old fix\n",
+		"**In progress**\n- " + id("3") + " Payment API\n",
+		"**Blocked**\n- " + id("4") + " Checkout (blocked by " + id("3") + ")\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected Markdown to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Backlog item") || strings.Contains(out, "Bob's work") {
+		t.Errorf("Expected unstarted and other users' issues to be left out, got:\n%s", out)
+	}
+}
@@ -0,0 +1,443 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// ProjectStats are the metrics of a project reported by buyruk stats.
+type ProjectStats struct {
+	Project    string         `json:"project"`
+	Issues     int            `json:"issues"`
+	Open       int            `json:"open"`
+	ByStatus   []StatsCount   `json:"by_status"`
+	ByType     []StatsCount   `json:"by_type"`
+	ByPriority []StatsCount   `json:"by_priority"`
+	Weekly     []StatsWeek    `json:"weekly"`
+	CycleTime  StatsDuration  `json:"cycle_time"` // From leaving the first status to done
+	LeadTime   StatsDuration  `json:"lead_time"`  // From creation to done
+	Oldest     []StatsOldest  `json:"oldest_open"`
+	Checklists StatsChecklist `json:"checklists"`
+	Effort     Effort         `json:"effort"` // Story points and estimates of the issues
+}
+
+// StatsCount is the number of issues with a status, type, or priority.
+type StatsCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+	Limit int    `json:"wip_limit,omitempty"` // WIP limit of a status
+}
+
+// StatsWeek counts the issues created and closed in a week.
+type StatsWeek struct {
+	Week    string `json:"week"` // Date of the Monday the week starts on
+	Created int    `json:"created"`
+	Closed  int    `json:"closed"`
+}
+
+// StatsDuration summarizes how long done issues took.
+type StatsDuration struct {
+	Issues       int     `json:"issues"` // Done issues the times are computed from
+	AverageHours float64 `json:"average_hours"`
+	MedianHours  float64 `json:"median_hours"`
+}
+
+// StatsChecklist counts the checklist items of the issues of a project.
+type StatsChecklist struct {
+	Issues int `json:"issues"` // Issues with a checklist
+	Items  int `json:"items"`
+	Done   int `json:"done"`
+}
+
+// StatsOldest is an open issue, with how long ago it was created.
+type StatsOldest struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	Age    string `json:"age"`
+}
+
+// NewStatsCmd creates and returns the stats command.
+func NewStatsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats [project]",
+		Short: "Show issue counts, throughput, and cycle times of a project",
+		Long: `Show metrics of a project: issue counts by status, type, and priority, the
+issues created and closed in each of the last --weeks weeks, the average and
+median cycle time (from leaving the workflow's first status to a done status)
+and lead time (from creation to a done status), the oldest open issues,
+how many checklist items of the issues are checked off, and the story points
+and estimates of the issues, in all and done. Statuses with a WIP limit show
+it next to their count, marked when over it.
+
+Issues archived by a retention policy count towards throughput and times.
+Times come from the issues' status history, so issues closed before history
+was recorded use their last update as the close time. Use --format json for
+dashboards.`,
+		Example: `  buyruk stats CORE
+  buyruk stats --project CORE --weeks 12 --format json`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeProjectArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectKey := ""
+			if len(args) == 1 {
+				projectKey = args[0]
+			} else {
+				key, err := config.ResolveProject(cmd)
+				if err != nil {
+					return err
+				}
+				projectKey = key
+			}
+			return showStats(projectKey, cmd)
+		},
+	}
+
+	cmd.Flags().Int("weeks", 8, "Number of weeks of created and closed issues to show")
+	cmd.Flags().Int("oldest", 5, "Number of oldest open issues to show")
+
+	return cmd
+}
+
+// showStats computes and prints the metrics of a project.
+func showStats(projectKey string, cmd *cobra.Command) error {
+	weeks, _ := cmd.Flags().GetInt("weeks")
+	oldest, _ := cmd.Flags().GetInt("oldest")
+	if weeks < 1 {
+		return invalidf("cli: --weeks must be at least 1")
+	}
+	if oldest < 0 {
+		return invalidf("cli: --oldest must not be negative")
+	}
+
+	index, err := loadQueryIndex(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	wf, err := loadWorkflow(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	var issues []*models.Issue
+	errOut := cmd.ErrOrStderr()
+	for _, entry := range index.Issues {
+		issue, err := loadLocalIssue(cmd.Context(), projectKey, entry.ID)
+		if err != nil || issue == nil {
+			fmt.Fprintf(errOut, "Warning: failed to load issue %s\n", entry.ID)
+			continue
+		}
+		issues = append(issues, issue)
+	}
+
+	stats := computeStats(projectKey, issues, loadArchivedIssues(cmd.Context(), projectKey), wf, storage.Now(cmd.Context()).In(config.Location()), weeks, oldest)
+	return writeStats(cmd, stats)
+}
+
+// loadArchivedIssues loads the issues archived by a retention policy.
+func loadArchivedIssues(ctx context.Context, projectKey string) []*models.Issue {
+	archiveDir, err := storage.ArchiveDir(ctx, projectKey)
+	if err != nil {
+		return nil
+	}
+	entries, err := os.ReadDir(archiveDir)
+	if err != nil {
+		return nil
+	}
+	var issues []*models.Issue
+	for _, entry := range entries {
+		if id, ok := strings.CutSuffix(entry.Name(), ".json"); ok {
+			if issue, ok := loadArchivedIssue(ctx, projectKey, id); ok {
+				issues = append(issues, issue)
+			}
+		}
+	}
+	return issues
+}
+
+// computeStats computes the metrics of the issues of a project. Archived
+// issues only count towards throughput and times.
+func computeStats(projectKey string, issues, archived []*models.Issue, wf *models.Workflow, now time.Time, weeks, oldest int) *ProjectStats {
+	stats := &ProjectStats{
+		Project:    projectKey,
+		Issues:     len(issues),
+		ByStatus:   countIssues(issues, wf.StatusList(), func(i *models.Issue) string { return i.Status }),
+		ByType:     countIssues(issues, wf.TypeList(), func(i *models.Issue) string { return i.Type }),
+		ByPriority: countIssues(issues, wf.PriorityList(), func(i *models.Issue) string { return i.Priority }),
+		Oldest:     []StatsOldest{},
+	}
+	for i, count := range stats.ByStatus {
+		stats.ByStatus[i].Limit = wf.WIPLimit(count.Name)
+	}
+
+	// Weeks start on Monday, in the configured timezone
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	firstWeek := today.AddDate(0, 0, -((int(today.Weekday())+6)%7)-7*(weeks-1))
+	stats.Weekly = make([]StatsWeek, weeks)
+	for i := range stats.Weekly {
+		stats.Weekly[i].Week = firstWeek.AddDate(0, 0, 7*i).Format(time.DateOnly)
+	}
+	week := func(t time.Time) int {
+		if t.Before(firstWeek) {
+			return -1
+		}
+		return int(t.In(now.Location()).Sub(firstWeek).Hours()) / (24 * 7)
+	}
+
+	var open []*models.Issue
+	for _, issue := range issues {
+		if !wf.IsDoneStatus(issue.Status) {
+			open = append(open, issue)
+		}
+		stats.Effort.add(issue.Points, issue.Estimate, wf.IsDoneStatus(issue.Status))
+		if done, total := issue.ChecklistProgress(); total > 0 {
+			stats.Checklists.Issues++
+			stats.Checklists.Items += total
+			stats.Checklists.Done += done
+		}
+	}
+	stats.Open = len(open)
+
+	var cycleTimes, leadTimes []time.Duration
+	for _, issue := range slices.Concat(issues, archived) {
+		created, createdErr := time.Parse(time.RFC3339, issue.CreatedAt)
+		if createdErr == nil {
+			if i := week(created); i >= 0 && i < weeks {
+				stats.Weekly[i].Created++
+			}
+		}
+		if !wf.IsDoneStatus(issue.Status) {
+			continue
+		}
+
+		started, closed := issueStartAndClose(issue, wf)
+		if closed.IsZero() {
+			continue
+		}
+		if i := week(closed); i >= 0 && i < weeks {
+			stats.Weekly[i].Closed++
+		}
+		if createdErr == nil && !closed.Before(created) {
+			leadTimes = append(leadTimes, closed.Sub(created))
+		}
+		if !started.IsZero() && !closed.Before(started) {
+			cycleTimes = append(cycleTimes, closed.Sub(started))
+		}
+	}
+	stats.CycleTime = summarizeDurations(cycleTimes)
+	stats.LeadTime = summarizeDurations(leadTimes)
+
+	open = slices.DeleteFunc(open, func(issue *models.Issue) bool { return issue.CreatedAt == "" })
+	slices.SortStableFunc(open, func(a, b *models.Issue) int { return compareTimestamps(a.CreatedAt, b.CreatedAt) })
+	for _, issue := range open[:min(oldest, len(open))] {
+		created, _ := time.Parse(time.RFC3339, issue.CreatedAt)
+		stats.Oldest = append(stats.Oldest, StatsOldest{ID: issue.ID, Title: issue.Title, Status: issue.Status, Age: formatAge(now.Sub(created))})
+	}
+	return stats
+}
+
+// countIssues counts issues by a field, in the order of names followed by
+// other values in order of appearance; issues without a value count as "none".
+func countIssues(issues []*models.Issue, names []string, field func(*models.Issue) string) []StatsCount {
+	counts := make([]StatsCount, len(names))
+	for i, name := range names {
+		counts[i].Name = name
+	}
+	for _, issue := range issues {
+		value := field(issue)
+		if value == "" {
+			value = "none"
+		}
+		i := slices.IndexFunc(counts, func(c StatsCount) bool { return c.Name == value })
+		if i < 0 {
+			counts = append(counts, StatsCount{Name: value})
+			i = len(counts) - 1
+		}
+		counts[i].Count++
+	}
+	return counts
+}
+
+// issueStartAndClose returns when a done issue was started (left the
+// workflow's first status) and closed (last entered a done status), from its
+// status history. An issue created past the first status started when it was
+// created, and one closed without recorded history closed at its last update.
+func issueStartAndClose(issue *models.Issue, wf *models.Workflow) (started, closed time.Time) {
+	initial := issue.Status
+	for _, event := range issue.History {
+		if event.To != "" {
+			initial = event.From
+			break
+		}
+	}
+	if initial != wf.DefaultStatus() {
+		started, _ = time.Parse(time.RFC3339, issue.CreatedAt)
+	}
+	for _, event := range issue.History {
+		if event.To == "" {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, event.At)
+		if err != nil {
+			continue
+		}
+		if started.IsZero() && event.From == wf.DefaultStatus() {
+			started = at
+		}
+		if wf.IsDoneStatus(event.To) && !wf.IsDoneStatus(event.From) {
+			closed = at
+		}
+	}
+	if closed.IsZero() {
+		closed, _ = time.Parse(time.RFC3339, issue.UpdatedAt)
+	}
+	return started, closed
+}
+
+// summarizeDurations returns the average and median of durations in hours.
+func summarizeDurations(durations []time.Duration) StatsDuration {
+	summary := StatsDuration{Issues: len(durations)}
+	if len(durations) == 0 {
+		return summary
+	}
+	slices.Sort(durations)
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+	median := durations[len(durations)/2]
+	if len(durations)%2 == 0 {
+		median = (durations[len(durations)/2-1] + median) / 2
+	}
+	summary.AverageHours = roundHours((total / time.Duration(len(durations))).Hours())
+	summary.MedianHours = roundHours(median.Hours())
+	return summary
+}
+
+// writeStats prints the metrics in the resolved output format.
+func writeStats(cmd *cobra.Command, stats *ProjectStats) error {
+	out := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		data, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return fmt.Errorf("cli: failed to marshal stats: %w", err)
+		}
+		fmt.Fprintln(out, string(data))
+		return nil
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(out, stats)
+	case config.DefaultFormatLSON:
+		writeStatsLSON(out, stats)
+		return nil
+	}
+
+	styles := ui.NewStyles()
+	fmt.Fprintf(out, "%s: %d issues, %d open\n\n", styles.Title(stats.Project), stats.Issues, stats.Open)
+	for _, group := range []struct {
+		heading string
+		counts  []StatsCount
+	}{
+		{"Status", stats.ByStatus},
+		{"Type", stats.ByType},
+		{"Priority", stats.ByPriority},
+	} {
+		var parts []string
+		for _, count := range group.counts {
+			part := fmt.Sprintf("%s %d", count.Name, count.Count)
+			if count.Limit > 0 {
+				part += fmt.Sprintf("/%d", count.Limit)
+				if count.Count > count.Limit {
+					part = styles.Error(part + " over WIP limit")
+				}
+			}
+			parts = append(parts, part)
+		}
+		fmt.Fprintf(out, "%s: %s\n", styles.Label(group.heading), strings.Join(parts, ", "))
+	}
+
+	fmt.Fprintf(out, "\n%s\n", styles.Label("Created / closed per week"))
+	for _, week := range stats.Weekly {
+		fmt.Fprintf(out, "  %s  +%-3d -%d\n", week.Week, week.Created, week.Closed)
+	}
+
+	fmt.Fprintln(out)
+	for _, metric := range []struct {
+		label    string
+		duration StatsDuration
+	}{
+		{"Cycle time", stats.CycleTime},
+		{"Lead time", stats.LeadTime},
+	} {
+		if metric.duration.Issues == 0 {
+			fmt.Fprintf(out, "%s: no done issues\n", styles.Label(metric.label))
+			continue
+		}
+		fmt.Fprintf(out, "%s: %s average, %s median (%d issues)\n", styles.Label(metric.label),
+			formatStatsHours(metric.duration.AverageHours), formatStatsHours(metric.duration.MedianHours), metric.duration.Issues)
+	}
+
+	if stats.Checklists.Items > 0 {
+		fmt.Fprintf(out, "%s: %d/%d items done in %d issues\n", styles.Label("Checklists"),
+			stats.Checklists.Done, stats.Checklists.Items, stats.Checklists.Issues)
+	}
+	renderEffort(stats.Effort, config.DefaultFormatModern, out)
+
+	if len(stats.Oldest) > 0 {
+		fmt.Fprintf(out, "\n%s\n", styles.Label("Oldest open issues"))
+		for _, issue := range stats.Oldest {
+			fmt.Fprintf(out, "  %s %s [%s, %s old]\n", styles.ID(issue.ID), issue.Title, issue.Status, issue.Age)
+		}
+	}
+	return nil
+}
+
+// formatStatsHours formats a number of hours as elapsed time, e.g. "2d 4h".
+func formatStatsHours(hours float64) string {
+	return ui.FormatElapsed(time.Duration(hours * float64(time.Hour)).Round(time.Minute))
+}
+
+// writeStatsLSON prints the metrics as L-SON.
+func writeStatsLSON(out io.Writer, stats *ProjectStats) {
+	fmt.Fprintf(out, "@PROJECT: %s\n", stats.Project)
+	fmt.Fprintf(out, "@ISSUES: %d\n", stats.Issues)
+	fmt.Fprintf(out, "@OPEN: %d\n", stats.Open)
+	for _, group := range []struct {
+		tag    string
+		counts []StatsCount
+	}{
+		{"STATUS", stats.ByStatus},
+		{"TYPE", stats.ByType},
+		{"PRIORITY", stats.ByPriority},
+	} {
+		for _, count := range group.counts {
+			fmt.Fprintf(out, "@%s: %s|%d\n", group.tag, count.Name, count.Count)
+		}
+	}
+	for _, count := range stats.ByStatus {
+		if count.Limit > 0 {
+			fmt.Fprintf(out, "@WIP: %s|%d|%d|%t\n", count.Name, count.Count, count.Limit, count.Count > count.Limit)
+		}
+	}
+	for _, week := range stats.Weekly {
+		fmt.Fprintf(out, "@WEEK: %s|%d|%d\n", week.Week, week.Created, week.Closed)
+	}
+	fmt.Fprintf(out, "@CYCLE_TIME_HOURS: %g|%g|%d\n", stats.CycleTime.AverageHours, stats.CycleTime.MedianHours, stats.CycleTime.Issues)
+	fmt.Fprintf(out, "@LEAD_TIME_HOURS: %g|%g|%d\n", stats.LeadTime.AverageHours, stats.LeadTime.MedianHours, stats.LeadTime.Issues)
+	for _, issue := range stats.Oldest {
+		fmt.Fprintf(out, "@OLDEST: %s|%s|%s\n", issue.ID, issue.Status, issue.Age)
+	}
+	fmt.Fprintf(out, "@CHECKLISTS: %d|%d|%d\n", stats.Checklists.Done, stats.Checklists.Items, stats.Checklists.Issues)
+	renderEffort(stats.Effort, config.DefaultFormatLSON, out)
+}
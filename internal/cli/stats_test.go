@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestStats(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(at string, args ...string) string {
+		t.Helper()
+		cmd := NewRootCmd()
+		cmd.SetArgs(append(args, "--project", projectKey, "--fixed-time", at, "--no-hooks"))
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+		return out.String()
+	}
+
+	run("2026-10-05T09:00:00Z", "project", "create", projectKey)
+	run("2026-10-05T09:00:00Z", "issue", "create", "--title", "Login page", "--type", "bug", "--priority", "HIGH")
+	run("2026-10-05T09:00:00Z", "issue", "create", "--title", "Signup page")
+	run("2026-10-13T09:00:00Z", "issue", "create", "--title", "Logout")
+	run("2026-10-06T09:00:00Z", "issue", "update", projectKey+"-1", "--status", "DOING")
+	run("2026-10-08T09:00:00Z", "issue", "update", projectKey+"-1", "--status", "DONE")
+
+	var stats ProjectStats
+	out := run("2026-10-14T09:00:00Z", "stats", "--weeks", "2", "--format", "json")
+	if err := json.Unmarshal([]byte(out), &stats); err != nil {
+		t.Fatalf("Failed to parse stats: %v\n%s", err, out)
+	}
+	if stats.Issues != 3 || stats.Open != 2 {
+		t.Errorf("Issues, Open = %d, %d; want 3, 2", stats.Issues, stats.Open)
+	}
+	if got := stats.ByStatus[0]; got.Name != "TODO" || got.Count != 2 {
+		t.Errorf("ByStatus[0] = %+v, want TODO 2", got)
+	}
+	want := []StatsWeek{{"2026-10-05", 2, 1}, {"2026-10-12", 1, 0}}
+	if len(stats.Weekly) != 2 || stats.Weekly[0] != want[0] || stats.Weekly[1] != want[1] {
+		t.Errorf("Weekly = %+v, want %+v", stats.Weekly, want)
+	}
+	if stats.CycleTime != (StatsDuration{Issues: 1, AverageHours: 48, MedianHours: 48}) {
+		t.Errorf("CycleTime = %+v, want 48h from DOING to DONE", stats.CycleTime)
+	}
+	if stats.LeadTime != (StatsDuration{Issues: 1, AverageHours: 72, MedianHours: 72}) {
+		t.Errorf("LeadTime = %+v, want 72h from creation to DONE", stats.LeadTime)
+	}
+	if len(stats.Oldest) != 2 || stats.Oldest[0].ID != projectKey+"-2" || stats.Oldest[0].Age != "9d" {
+		t.Errorf("Oldest = %+v, want %s-2 first, 9d old", stats.Oldest, projectKey)
+	}
+
+	out = run("2026-10-14T09:00:00Z", "stats")
+	for _, want := range []string{"3 issues, 2 open", "Cycle time: 2d average", "Lead time: 3d average"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in the report, got:\n%s", want, out)
+		}
+	}
+}
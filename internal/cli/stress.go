@@ -0,0 +1,592 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// stressErrorSamples is how many unexpected errors each worker reports in full.
+const stressErrorSamples = 5
+
+// stressWorkerCommand returns the command running a stress worker process
+// with the given buyruk arguments. Tests use another one (see hostEnv), as
+// their executable is not buyruk.
+func stressWorkerCommand(args ...string) (*exec.Cmd, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	return exec.Command(exe, args...), nil
+}
+
+// StressReport is the result of a stress run.
+type StressReport struct {
+	Project    string       `json:"project"`
+	Dir        string       `json:"dir"` // Directory the project was stored in
+	Writers    int          `json:"writers"`
+	Readers    int          `json:"readers"`
+	Seed       int64        `json:"seed"`
+	Fuzz       bool         `json:"fuzz,omitempty"`
+	DurationMs int64        `json:"duration_ms"`
+	Counts     StressCounts `json:"counts"`
+	Issues     int          `json:"issues"`     // Issues left at the end
+	Violations []string     `json:"violations"` // Broken invariants; empty when the run passed
+}
+
+// StressCounts counts the operations of stress workers.
+type StressCounts struct {
+	Ops          int      `json:"ops"`
+	Creates      int      `json:"creates"`
+	Updates      int      `json:"updates"`
+	Deletes      int      `json:"deletes"`
+	Repairs      int      `json:"repairs"`
+	Reads        int      `json:"reads"`
+	Conflicts    int      `json:"conflicts"`     // Lost a race (e.g. the issue was deleted) or input rejected by validation
+	LockTimeouts int      `json:"lock_timeouts"` // Gave up waiting for the project lock
+	Failures     int      `json:"failures"`      // Any other error
+	Errors       []string `json:"errors,omitempty"`
+}
+
+// add adds the counts of another worker.
+func (c *StressCounts) add(o StressCounts) {
+	c.Ops += o.Ops
+	c.Creates += o.Creates
+	c.Updates += o.Updates
+	c.Deletes += o.Deletes
+	c.Repairs += o.Repairs
+	c.Reads += o.Reads
+	c.Conflicts += o.Conflicts
+	c.LockTimeouts += o.LockTimeouts
+	c.Failures += o.Failures
+	for _, e := range o.Errors {
+		if len(c.Errors) < stressErrorSamples*2 {
+			c.Errors = append(c.Errors, e)
+		}
+	}
+}
+
+// NewStressCmd creates and returns the stress command.
+func NewStressCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stress",
+		Short: "Check locking and transactions under concurrent load",
+		Long: `Hammer a throwaway project with concurrent creates, updates, deletes, and
+repairs from several processes, while reader processes list and view issues
+the way an open list --watch would, then verify that:
+
+  - no two issues share an ID
+  - the index lists exactly the issue files, with matching titles and statuses
+  - every JSON file parses (no partial writes)
+  - no transaction log or temporary file is left behind
+
+Use it to check that buyruk's file locking holds on your filesystem, such as
+a network drive (--dir). Lock timeouts under heavy load are reported but do
+not fail the run; broken invariants do. --fuzz uses random titles, labels,
+and field values, including invalid ones that must be rejected cleanly.
+The project is deleted afterwards unless --keep is given.`,
+		Example: `  buyruk stress --writers 8 --ops 10000
+  buyruk stress --dir /mnt/share --fuzz --seed 42`,
+		Args: cobra.NoArgs,
+		// A failed run has already printed its report; usage would bury it
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStress(cmd)
+		},
+	}
+
+	cmd.Flags().Int("writers", 4, "Number of concurrent writer processes")
+	cmd.Flags().Int("readers", 1, "Number of concurrent reader processes")
+	cmd.Flags().Int("ops", 1000, "Total number of write operations, split across writers")
+	cmd.Flags().String("dir", "", "Run in a temporary directory under this path instead of the data directory")
+	cmd.Flags().Int64("seed", 0, "Random seed (default: time based); the same seed replays the same operations per worker")
+	cmd.Flags().Bool("fuzz", false, "Use random, sometimes invalid, field values")
+	cmd.Flags().Bool("keep", false, "Keep the project afterwards for inspection")
+
+	cmd.AddCommand(NewStressWorkerCmd())
+
+	return cmd
+}
+
+// NewStressWorkerCmd creates and returns the hidden stress worker command,
+// run as a separate process by buyruk stress.
+func NewStressWorkerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    "worker",
+		Short:  "Run one stress worker process",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStressWorker(cmd)
+		},
+	}
+
+	cmd.Flags().String("role", "writer", "writer or reader")
+	cmd.Flags().Int("ops", 0, "Number of operations")
+	cmd.Flags().Int64("seed", 0, "Random seed")
+	cmd.Flags().Bool("fuzz", false, "Use random, sometimes invalid, field values")
+
+	return cmd
+}
+
+// runStress runs a stress test and reports the result.
+func runStress(cmd *cobra.Command) error {
+	writers, _ := cmd.Flags().GetInt("writers")
+	readers, _ := cmd.Flags().GetInt("readers")
+	ops, _ := cmd.Flags().GetInt("ops")
+	dir, _ := cmd.Flags().GetString("dir")
+	seed, _ := cmd.Flags().GetInt64("seed")
+	fuzz, _ := cmd.Flags().GetBool("fuzz")
+	keep, _ := cmd.Flags().GetBool("keep")
+	if writers < 1 || readers < 0 || ops < 1 {
+		return invalidf("cli: --writers and --ops must be at least 1 and --readers at least 0")
+	}
+	if !cmd.Flags().Changed("seed") {
+		seed = time.Now().UnixNano()
+	}
+
+	// Run in a fresh repo-local directory when --dir is given
+	workDir := ""
+	if dir != "" {
+		tmp, err := os.MkdirTemp(dir, "buyruk-stress-")
+		if err != nil {
+			return fmt.Errorf("cli: failed to create stress directory: %w", err)
+		}
+		if !keep {
+			defer os.RemoveAll(tmp)
+		}
+		if err := os.Mkdir(filepath.Join(tmp, storage.LocalDirName), 0755); err != nil {
+			return fmt.Errorf("cli: failed to create stress directory: %w", err)
+		}
+		previous, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("cli: %w", err)
+		}
+		if err := os.Chdir(tmp); err != nil {
+			return fmt.Errorf("cli: %w", err)
+		}
+		defer os.Chdir(previous)
+		workDir = tmp
+	}
+
+	projectKey := fmt.Sprintf("STRESS%d", os.Getpid())
+	projectDir, err := storage.ProjectDir(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: %w", err)
+	}
+	if _, err := os.Stat(projectDir); err == nil {
+		return conflictf("cli: project %q already exists", projectKey)
+	}
+	if err := runStressOp(cmd.Context(), "project", "create", projectKey); err != nil {
+		return fmt.Errorf("cli: failed to create stress project: %w", err)
+	}
+	if !keep {
+		defer os.RemoveAll(projectDir)
+	}
+
+	errOut := cmd.ErrOrStderr()
+	fmt.Fprintf(errOut, "Stressing %s with %d writers and %d readers (%d ops, seed %d)...\n", projectKey, writers, readers, ops, seed)
+
+	report := &StressReport{Project: projectKey, Dir: filepath.Dir(filepath.Dir(projectDir)), Writers: writers, Readers: readers, Seed: seed, Fuzz: fuzz, Violations: []string{}}
+	start := time.Now()
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	spawn := func(role string, n, ops int) {
+		defer wg.Done()
+		args := []string{"stress", "worker", "--project", projectKey, "--role", role,
+			"--ops", strconv.Itoa(ops), "--seed", strconv.FormatInt(seed+int64(n), 10)}
+		if fuzz {
+			args = append(args, "--fuzz")
+		}
+		counts, err := runStressWorkerProcess(cmd.Context(), workDir, args)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			report.Violations = append(report.Violations, fmt.Sprintf("%s %d crashed: %v", role, n, err))
+			return
+		}
+		report.Counts.add(*counts)
+	}
+	for i := range writers {
+		wg.Add(1)
+		go spawn("writer", i, ops/writers+boolInt(i < ops%writers))
+	}
+	for i := range readers {
+		wg.Add(1)
+		go spawn("reader", writers+i, max(ops/writers, 1))
+	}
+	wg.Wait()
+	report.DurationMs = time.Since(start).Milliseconds()
+
+	issues, violations := checkStressInvariants(cmd.Context(), projectKey)
+	report.Issues = issues
+	report.Violations = append(report.Violations, violations...)
+	if report.Counts.Failures > 0 {
+		report.Violations = append(report.Violations, fmt.Sprintf("%d operations failed unexpectedly", report.Counts.Failures))
+	}
+	if keep {
+		fmt.Fprintf(errOut, "Kept project %s in %s\n", projectKey, report.Dir)
+	}
+
+	out := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	case config.DefaultFormatYAML:
+		if err := ui.EncodeYAML(out, report); err != nil {
+			return err
+		}
+	case config.DefaultFormatLSON:
+		renderStressLSON(report, out)
+	default:
+		renderStress(report, out)
+	}
+
+	if len(report.Violations) > 0 {
+		return fmt.Errorf("cli: stress test found %d problems", len(report.Violations))
+	}
+	return nil
+}
+
+// boolInt returns 1 for true and 0 for false.
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// runStressWorkerProcess runs a worker process and returns its counts.
+func runStressWorkerProcess(ctx context.Context, dir string, args []string) (*StressCounts, error) {
+	command := hostEnvFrom(ctx).stressWorker
+	if command == nil {
+		command = stressWorkerCommand
+	}
+	c, err := command(args...)
+	if err != nil {
+		return nil, err
+	}
+	c.Dir = dir
+	var stdout, stderr bytes.Buffer
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	var counts StressCounts
+	if err := json.Unmarshal(stdout.Bytes(), &counts); err != nil {
+		return nil, fmt.Errorf("unreadable worker result: %w", err)
+	}
+	return &counts, nil
+}
+
+// runStressOp runs one buyruk command in this process, without autocommit,
+// hooks, or output.
+func runStressOp(ctx context.Context, args ...string) error {
+	root := NewRootCmd()
+	root.SetArgs(append(args, "--no-autocommit", "--no-hooks"))
+	root.SetOut(io.Discard)
+	root.SetErr(io.Discard)
+	root.SilenceUsage = true
+	root.SilenceErrors = true
+	return root.ExecuteContext(ctx)
+}
+
+// runStressWorker runs the operations of one worker and prints its counts.
+func runStressWorker(cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	role, _ := cmd.Flags().GetString("role")
+	ops, _ := cmd.Flags().GetInt("ops")
+	seed, _ := cmd.Flags().GetInt64("seed")
+	fuzz, _ := cmd.Flags().GetBool("fuzz")
+	if role != "writer" && role != "reader" {
+		return invalidf("cli: invalid --role %q", role)
+	}
+	// Keep the worker, and the operations it runs, out of the usage log
+	if state := runStateFrom(cmd.Context()); state != nil {
+		state.stopUsage()
+	}
+
+	w := &stressWorker{projectKey: projectKey, rng: rand.New(rand.NewSource(seed)), fuzz: fuzz}
+	for range ops {
+		if role == "reader" {
+			w.read(cmd.Context())
+		} else {
+			w.write(cmd.Context())
+		}
+	}
+
+	data, err := json.Marshal(&w.counts)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(data))
+	return nil
+}
+
+// stressWorker runs random operations against a project.
+type stressWorker struct {
+	projectKey string
+	rng        *rand.Rand
+	fuzz       bool
+	counts     StressCounts
+}
+
+// stressStatuses are the statuses writers move issues between.
+var stressStatuses = []string{models.StatusTODO, models.StatusDOING, models.StatusDONE}
+
+// write runs one random write operation.
+func (w *stressWorker) write(ctx context.Context) {
+	w.counts.Ops++
+	issueID := w.randomIssueID(ctx)
+	roll := w.rng.Intn(100)
+	switch {
+	case roll < 40 || issueID == "":
+		w.counts.Creates++
+		args := []string{"issue", "create", "--project", w.projectKey, "--title", w.text("Issue")}
+		if w.rng.Intn(2) == 0 {
+			args = append(args, "--labels", w.labels(), "--priority", w.priority())
+		}
+		w.record(runStressOp(ctx, args...))
+	case roll < 75:
+		w.counts.Updates++
+		args := []string{"issue", "update", issueID, "--force", "--status", w.status()}
+		if w.rng.Intn(2) == 0 {
+			args = append(args, "--title", w.text("Renamed"), "--description", w.text("Description"))
+		}
+		w.record(runStressOp(ctx, args...))
+	case roll < 95:
+		w.counts.Deletes++
+		w.record(runStressOp(ctx, "issue", "delete", issueID, "--yes"))
+	default:
+		w.counts.Repairs++
+		w.record(runStressOp(ctx, "project", "repair", w.projectKey))
+	}
+}
+
+// read lists the project and views a random issue.
+func (w *stressWorker) read(ctx context.Context) {
+	w.counts.Ops++
+	w.counts.Reads++
+	w.record(runStressOp(ctx, "list", "--project", w.projectKey, "--format", "json"))
+	if issueID := w.randomIssueID(ctx); issueID != "" {
+		w.counts.Reads++
+		w.record(runStressOp(ctx, "view", issueID, "--format", "json"))
+	}
+}
+
+// record counts the outcome of an operation.
+func (w *stressWorker) record(err error) {
+	if err == nil {
+		return
+	}
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "lock timeout"):
+		w.counts.LockTimeouts++
+	case errors.Is(err, ErrNotFound), strings.Contains(msg, "not found"), strings.Contains(msg, "invalid"), strings.Contains(msg, "required"):
+		// Includes reading an issue another worker just moved to the trash
+		w.counts.Conflicts++
+	default:
+		w.counts.Failures++
+		if len(w.counts.Errors) < stressErrorSamples {
+			w.counts.Errors = append(w.counts.Errors, msg)
+		}
+	}
+}
+
+// randomIssueID picks an issue from the index, or returns "" if there is none.
+func (w *stressWorker) randomIssueID(ctx context.Context) string {
+	index, err := loadQueryIndex(ctx, w.projectKey)
+	if err != nil {
+		w.record(err)
+		return ""
+	}
+	if len(index.Issues) == 0 {
+		return ""
+	}
+	return index.Issues[w.rng.Intn(len(index.Issues))].ID
+}
+
+// fuzzRunes are characters fuzzed text is made of, including quoting,
+// markup, and multi-byte characters.
+var fuzzRunes = []rune("abcXYZ019 -_,.:;\"'`\\/{}[]<>|#*@!?çğıöşüİ€😀\t\n")
+
+// text returns a plain value, or random text when fuzzing.
+func (w *stressWorker) text(prefix string) string {
+	if !w.fuzz {
+		return fmt.Sprintf("%s %d", prefix, w.rng.Intn(100000))
+	}
+	runes := make([]rune, w.rng.Intn(200))
+	for i := range runes {
+		runes[i] = fuzzRunes[w.rng.Intn(len(fuzzRunes))]
+	}
+	return string(runes)
+}
+
+// labels returns a label list, sometimes with invalid labels when fuzzing.
+func (w *stressWorker) labels() string {
+	if w.fuzz && w.rng.Intn(4) == 0 {
+		return w.text("")
+	}
+	return fmt.Sprintf("area%d,team%d", w.rng.Intn(5), w.rng.Intn(3))
+}
+
+// priority returns a priority, sometimes an invalid one when fuzzing.
+func (w *stressWorker) priority() string {
+	if w.fuzz && w.rng.Intn(4) == 0 {
+		return "URGENT"
+	}
+	return models.ValidPriorities[w.rng.Intn(len(models.ValidPriorities))]
+}
+
+// status returns a status, sometimes an invalid one when fuzzing.
+func (w *stressWorker) status() string {
+	if w.fuzz && w.rng.Intn(4) == 0 {
+		return "BLOCKED"
+	}
+	return stressStatuses[w.rng.Intn(len(stressStatuses))]
+}
+
+// checkStressInvariants checks a project after a stress run and returns its
+// issue count and the invariants it breaks.
+func checkStressInvariants(ctx context.Context, projectKey string) (int, []string) {
+	var violations []string
+	projectDir, err := storage.ProjectDir(ctx, projectKey)
+	if err != nil {
+		return 0, []string{err.Error()}
+	}
+
+	// Leftovers of interrupted writes and transactions
+	filepath.WalkDir(projectDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			violations = append(violations, fmt.Sprintf("cannot read %s: %v", path, err))
+			return nil
+		}
+		switch {
+		case d.IsDir():
+		case strings.HasSuffix(path, ".tmp"):
+			violations = append(violations, fmt.Sprintf("temporary file left behind: %s", path))
+		case d.Name() == ".buyruk_pending":
+			violations = append(violations, "transaction log left behind (a write was interrupted)")
+		case d.Name() == ".buyruk.lock":
+			violations = append(violations, "project lock left behind")
+		case strings.HasSuffix(path, ".json"):
+			var v any
+			if err := storage.ReadJSON(ctx, path, &v); err != nil {
+				violations = append(violations, fmt.Sprintf("unreadable JSON in %s: %v", path, err))
+			}
+		}
+		return nil
+	})
+
+	var index models.ProjectIndex
+	indexPath, _ := storage.ProjectIndexPath(ctx, projectKey)
+	if err := storage.ReadIndex(ctx, indexPath, &index); err != nil {
+		return 0, append(violations, fmt.Sprintf("unreadable index: %v", err))
+	}
+	indexed := map[string]models.IndexEntry{}
+	for _, entry := range index.Issues {
+		if _, ok := indexed[entry.ID]; ok {
+			violations = append(violations, fmt.Sprintf("duplicate issue ID %s in the index", entry.ID))
+		}
+		indexed[entry.ID] = entry
+	}
+
+	issuesDir, _ := storage.IssuesDir(ctx, projectKey)
+	entries, err := os.ReadDir(issuesDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return len(index.Issues), append(violations, fmt.Sprintf("cannot read issues: %v", err))
+	}
+	files := map[string]bool{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		var issue models.Issue
+		if err := storage.ReadJSON(ctx, filepath.Join(issuesDir, entry.Name()), &issue); err != nil {
+			continue // Reported above
+		}
+		if issue.ID+".json" != entry.Name() {
+			violations = append(violations, fmt.Sprintf("%s holds issue %s", entry.Name(), issue.ID))
+		}
+		files[issue.ID] = true
+		indexEntry, ok := indexed[issue.ID]
+		switch {
+		case !ok:
+			violations = append(violations, fmt.Sprintf("%s has a file but is not in the index", issue.ID))
+		case indexEntry.Title != issue.Title || indexEntry.Status != issue.Status:
+			violations = append(violations, fmt.Sprintf("index entry of %s does not match its file", issue.ID))
+		}
+	}
+	for id := range indexed {
+		if !files[id] {
+			violations = append(violations, fmt.Sprintf("%s is in the index but has no file", id))
+		}
+	}
+	return len(files), violations
+}
+
+// renderStress renders a stress report in modern format.
+func renderStress(report *StressReport, w io.Writer) {
+	styles := ui.NewStyles()
+	c := report.Counts
+	fmt.Fprintf(w, "%s\n", styles.Title(fmt.Sprintf("Stress test of %s in %s", report.Project, report.Dir)))
+	fmt.Fprintf(w, "%s: %d writers, %d readers, seed %d, %.1fs\n", styles.Label("Run"), report.Writers, report.Readers, report.Seed, float64(report.DurationMs)/1000)
+	fmt.Fprintf(w, "%s: %d (%d creates, %d updates, %d deletes, %d repairs, %d reads)\n", styles.Label("Operations"), c.Ops, c.Creates, c.Updates, c.Deletes, c.Repairs, c.Reads)
+	fmt.Fprintf(w, "%s: %d conflicts, %d lock timeouts, %d failures\n", styles.Label("Outcomes"), c.Conflicts, c.LockTimeouts, c.Failures)
+	for _, e := range c.Errors {
+		fmt.Fprintf(w, "  - %s\n", e)
+	}
+	fmt.Fprintf(w, "%s: %d\n", styles.Label("Issues left"), report.Issues)
+	if len(report.Violations) == 0 {
+		fmt.Fprintln(w, styles.Success("All invariants hold"))
+		return
+	}
+	fmt.Fprintf(w, "%s:\n", styles.Error("Problems"))
+	for _, v := range report.Violations {
+		fmt.Fprintf(w, "  - %s\n", v)
+	}
+}
+
+// renderStressLSON renders a stress report in L-SON format.
+func renderStressLSON(report *StressReport, w io.Writer) {
+	c := report.Counts
+	fmt.Fprintf(w, "@PROJECT: %s\n", report.Project)
+	fmt.Fprintf(w, "@SEED: %d\n", report.Seed)
+	fmt.Fprintf(w, "@OPS: %d|%d|%d|%d|%d|%d\n", c.Ops, c.Creates, c.Updates, c.Deletes, c.Repairs, c.Reads)
+	fmt.Fprintf(w, "@OUTCOMES: %d|%d|%d\n", c.Conflicts, c.LockTimeouts, c.Failures)
+	fmt.Fprintf(w, "@ISSUES: %d\n", report.Issues)
+	for _, v := range report.Violations {
+		fmt.Fprintf(w, "@VIOLATION: %s\n", v)
+	}
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+// TestStressHelperProcess runs buyruk as a stress worker process. It is not a
+// real test: TestStress starts the test binary with this test selected.
+func TestStressHelperProcess(t *testing.T) {
+	if os.Getenv("BUYRUK_STRESS_HELPER") != "1" {
+		return
+	}
+	args := os.Args
+	for i, arg := range args {
+		if arg == "--" {
+			args = args[i+1:]
+			break
+		}
+	}
+	cmd := NewRootCmd()
+	cmd.SetArgs(args)
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestStress(t *testing.T) {
+	// Stress workers run TestStressHelperProcess in the test binary
+	ctx := withHostEnv(t.Context(), hostEnv{stressWorker: func(args ...string) (*exec.Cmd, error) {
+		c := exec.Command(os.Args[0], append([]string{"-test.run=^TestStressHelperProcess$", "--"}, args...)...)
+		c.Env = append(os.Environ(), "BUYRUK_STRESS_HELPER=1")
+		return c, nil
+	}})
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.ExecuteContext(ctx)
+		return out.String(), err
+	}
+
+	for _, fuzz := range []bool{false, true} {
+		args := []string{"stress", "--dir", t.TempDir(), "--writers", "4", "--ops", "120", "--seed", "7", "--format", "json"}
+		if fuzz {
+			args = append(args, "--fuzz")
+		}
+		out, err := run(args...)
+		var report StressReport
+		if jsonErr := json.Unmarshal([]byte(out), &report); jsonErr != nil {
+			t.Fatalf("stress (fuzz %t) output is not JSON: %v\n%s", fuzz, jsonErr, out)
+		}
+		if err != nil {
+			t.Fatalf("stress (fuzz %t) failed: %v\nviolations: %v\nerrors: %v", fuzz, err, report.Violations, report.Counts.Errors)
+		}
+		if report.Counts.Ops < 120 || report.Counts.Creates == 0 || report.Counts.Reads == 0 {
+			t.Errorf("stress (fuzz %t) counts = %+v", fuzz, report.Counts)
+		}
+	}
+
+	if _, err := run("stress", "--writers", "0"); err == nil {
+		t.Error("stress with no writers succeeded")
+	}
+}
+
+func TestCheckStressInvariants(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	t.Chdir(t.TempDir())
+	if err := os.Mkdir(".buyruk", 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "One"},
+		{"issue", "create", "--project", projectKey, "--title", "Two"},
+	} {
+		if err := runStressOp(t.Context(), args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+	if issues, violations := checkStressInvariants(t.Context(), projectKey); issues != 2 || len(violations) != 0 {
+		t.Fatalf("checkStressInvariants = %d, %v", issues, violations)
+	}
+
+	// A stray issue file and a partial write break the invariants
+	if err := os.WriteFile(".buyruk/projects/"+projectKey+"/issues/"+projectKey+"-9.json", []byte(`{"id":`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, violations := checkStressInvariants(t.Context(), projectKey); len(violations) == 0 {
+		t.Error("checkStressInvariants found no violations in a corrupted project")
+	}
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// validateParent checks that parentID can be the parent of issueID:
+// it must be an existing issue in the same project and must not be a
+// descendant of issueID (which would create a cycle).
+func validateParent(ctx context.Context, projectKey, issueID, parentID string) error {
+	parentKey, _, err := parseIssueID(ctx, parentID)
+	if err != nil {
+		return invalidf("cli: invalid parent ID %q: %w", parentID, err)
+	}
+	if parentKey != projectKey {
+		return invalidf("cli: parent %q must be in project %q", parentID, projectKey)
+	}
+	if parentID == issueID {
+		return invalidf("cli: issue %q cannot be its own parent", issueID)
+	}
+
+	parentPath, err := storage.IssuePath(ctx, projectKey, parentID)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve parent path: %w", err)
+	}
+	if _, err := os.Stat(parentPath); err != nil {
+		if os.IsNotExist(err) {
+			return notFoundf("cli: parent issue %q not found", parentID)
+		}
+		return fmt.Errorf("cli: failed to stat parent path %q: %w", parentPath, err)
+	}
+
+	// Walk up from the new parent; reaching issueID means a cycle
+	indexPath, err := storage.ProjectIndexPath(ctx, projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(ctx, indexPath, &index); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+
+	seen := map[string]bool{}
+	for current := parentID; current != "" && !seen[current]; {
+		if current == issueID {
+			return invalidf("cli: cannot make %q a subtask of its own subtask %q", issueID, parentID)
+		}
+		seen[current] = true
+		entry := index.FindIssue(current)
+		if entry == nil {
+			break
+		}
+		current = entry.ParentID
+	}
+
+	return nil
+}
+
+// loadChildren returns the index entries of the subtasks of an issue.
+func loadChildren(ctx context.Context, projectKey, issueID string) ([]models.IndexEntry, error) {
+	indexPath, err := storage.ProjectIndexPath(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(ctx, indexPath, &index); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+
+	return index.Children(issueID), nil
+}
+
+// renderSubtasks renders the subtasks of an issue with completion progress.
+// Only the modern and lson formats include subtasks; json and yaml output
+// stay a plain issue object (use `list --parent` to get subtasks there).
+func renderSubtasks(children []models.IndexEntry, wf *models.Workflow, cmd *cobra.Command, w io.Writer) {
+	if len(children) == 0 {
+		return
+	}
+
+	done := 0
+	for _, child := range children {
+		if wf.IsDoneStatus(child.Status) {
+			done++
+		}
+	}
+
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatLSON:
+		fmt.Fprintf(w, "@PROGRESS: %d/%d\n", done, len(children))
+		for _, child := range children {
+			fmt.Fprintf(w, "@CHILD: %s|%s|%s\n", child.ID, child.Status, child.Title)
+		}
+	case config.DefaultFormatModern:
+		styles := ui.NewStyles()
+		fmt.Fprintf(w, "\n%s (%d/%d done):\n", styles.Label("Subtasks"), done, len(children))
+		for _, child := range children {
+			fmt.Fprintf(w, "  - %s %s [%s]\n", styles.ID(child.ID), child.Title, styles.StatusColor(child.Status)(child.Status))
+		}
+	}
+}
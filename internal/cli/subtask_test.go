@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestSubtasks(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		buf := new(bytes.Buffer)
+		cmd.SetOut(buf)
+		cmd.SetErr(new(bytes.Buffer))
+		err := cmd.Execute()
+		return buf.String(), err
+	}
+
+	if _, err := run("project", "create", projectKey); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	parentID := projectKey + "-1"
+	childID := projectKey + "-2"
+	if _, err := run("issue", "create", "--project", projectKey, "--title", "Parent"); err != nil {
+		t.Fatalf("Failed to create parent: %v", err)
+	}
+	if _, err := run("issue", "create", "--project", projectKey, "--title", "Child", "--parent", parentID); err != nil {
+		t.Fatalf("Failed to create subtask: %v", err)
+	}
+	if _, err := run("issue", "create", "--project", projectKey, "--title", "Done child", "--parent", parentID, "--status", "DONE"); err != nil {
+		t.Fatalf("Failed to create subtask: %v", err)
+	}
+	if _, err := run("issue", "create", "--project", projectKey, "--title", "Orphan", "--parent", projectKey+"-99"); err == nil {
+		t.Error("Expected error for missing parent")
+	}
+
+	// View shows subtasks with progress
+	output, err := run("view", parentID, "--format", "lson")
+	if err != nil {
+		t.Fatalf("view command failed: %v", err)
+	}
+	if !strings.Contains(output, "@PROGRESS: 1/2") {
+		t.Errorf("Expected subtask progress, got: %s", output)
+	}
+	if !strings.Contains(output, "@CHILD: "+childID+"|TODO|Child") {
+		t.Errorf("Expected subtask entry, got: %s", output)
+	}
+
+	// List can be filtered by parent
+	output, err = run("list", "--project", projectKey, "--parent", parentID, "--template", "{{.ID}}")
+	if err != nil {
+		t.Fatalf("list command failed: %v", err)
+	}
+	if strings.Contains(output, parentID+"\n") || !strings.Contains(output, childID) {
+		t.Errorf("Expected only subtasks in list, got: %s", output)
+	}
+
+	// A parent cannot become a subtask of its own subtask
+	if _, err := run("issue", "update", parentID, "--parent", childID); err == nil {
+		t.Error("Expected error for parent cycle")
+	}
+
+	// Issues with subtasks are protected from deletion
+	_, err = run("issue", "delete", parentID)
+	if err == nil || !strings.Contains(err.Error(), "subtask") {
+		t.Errorf("Expected deletion to be refused, got: %v", err)
+	}
+
+	// Re-parenting to "none" detaches the subtask
+	if _, err := run("issue", "update", childID, "--parent", "none"); err != nil {
+		t.Fatalf("Failed to detach subtask: %v", err)
+	}
+	issuePath, _ := storage.IssuePath(t.Context(), projectKey, childID)
+	var child models.Issue
+	if err := storage.ReadJSON(t.Context(), issuePath, &child); err != nil {
+		t.Fatalf("Failed to read issue: %v", err)
+	}
+	if child.ParentID != "" {
+		t.Errorf("ParentID = %q, want empty", child.ParentID)
+	}
+}
@@ -0,0 +1,264 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// Suggestion weights: a shared label is a stronger signal than a shared title word,
+// and epic membership alone is too weak to suggest a link on its own.
+const (
+	suggestWordScore  = 2
+	suggestLabelScore = 3
+	suggestEpicScore  = 1
+)
+
+// suggestStopWords are common title words that say nothing about relatedness.
+var suggestStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "from": true, "into": true,
+	"when": true, "not": true, "add": true, "fix": true, "use": true, "should": true,
+	"can": true, "are": true, "was": true, "does": true, "this": true, "that": true,
+}
+
+// LinkSuggestion is an issue that is likely related to another issue.
+type LinkSuggestion struct {
+	ID      string   `json:"id"`
+	Title   string   `json:"title"`
+	Status  string   `json:"status"`
+	Score   int      `json:"score"`
+	Reasons []string `json:"reasons"`
+}
+
+// NewIssueSuggestLinksCmd creates and returns the issue suggest-links command.
+func NewIssueSuggestLinksCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "suggest-links <id>",
+		Short: "Suggest related or blocking issues",
+		Long: `Suggest issues that are likely related to an issue, based on shared title
+words, shared labels, and epic membership. Done issues and existing links are skipped.
+
+Each suggestion is offered for confirmation:
+  b  the issue is blocked by the suggestion
+  k  the issue blocks the suggestion
+  s  skip
+  q  quit
+With --dry-run, or with --format json|yaml|lson, suggestions are only listed.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeIssueArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveIssueArgs(cmd, args[:1]); err != nil {
+				return err
+			}
+			issueID := args[0]
+			return suggestLinks(issueID, cmd)
+		},
+	}
+
+	cmd.Flags().Int("limit", 5, "Maximum number of suggestions")
+	cmd.Flags().Int("min-score", suggestWordScore, "Minimum score for a suggestion")
+	cmd.Flags().Bool("dry-run", false, "List suggestions without prompting")
+
+	return cmd
+}
+
+// suggestLinks finds link suggestions for an issue and offers them for confirmation.
+func suggestLinks(issueID string, cmd *cobra.Command) error {
+	projectKey, _, err := parseIssueID(cmd.Context(), issueID)
+	if err != nil {
+		return invalidf("cli: invalid issue ID %q: %w", issueID, err)
+	}
+
+	issuePath, err := storage.IssuePath(cmd.Context(), projectKey, issueID)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+	}
+
+	var issue models.Issue
+	if err := storage.ReadJSON(cmd.Context(), issuePath, &issue); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return notFoundf("cli: issue %q not found", issueID)
+		}
+		return fmt.Errorf("cli: failed to load issue: %w", err)
+	}
+
+	indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(cmd.Context(), indexPath, &index); err != nil {
+		return fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+
+	wf, err := loadWorkflow(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+
+	limit, _ := cmd.Flags().GetInt("limit")
+	minScore, _ := cmd.Flags().GetInt("min-score")
+
+	suggestions := []LinkSuggestion{}
+	for _, s := range scoreLinkSuggestions(&issue, index.Issues, wf, minScore) {
+		if len(suggestions) == limit {
+			break
+		}
+		// Skip issues that already depend on this one
+		if blocksIssue(cmd.Context(), projectKey, s.ID, issueID) {
+			continue
+		}
+		suggestions = append(suggestions, s)
+	}
+
+	w := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(suggestions)
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(w, suggestions)
+	case config.DefaultFormatLSON:
+		for _, s := range suggestions {
+			fmt.Fprintf(w, "@SUGGEST: %s|%d|%s|%s\n", s.ID, s.Score, s.Title, strings.Join(s.Reasons, "; "))
+		}
+		return nil
+	}
+
+	if len(suggestions) == 0 {
+		fmt.Fprintf(w, "No link suggestions for %s\n", issueID)
+		return nil
+	}
+
+	styles := ui.NewStyles()
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	if dryRun {
+		for _, s := range suggestions {
+			fmt.Fprintf(w, "%s %s (%s)\n", styles.ID(s.ID), styles.Title(s.Title), strings.Join(s.Reasons, "; "))
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(cmd.InOrStdin())
+	errOut := cmd.ErrOrStderr()
+	for _, s := range suggestions {
+		fmt.Fprintf(w, "%s %s (%s)\n", styles.ID(s.ID), styles.Title(s.Title), strings.Join(s.Reasons, "; "))
+		fmt.Fprintf(errOut, "Link? [b] %s blocked by %s, [k] %s blocks %s, [s]kip, [q]uit: ", issueID, s.ID, issueID, s.ID)
+		if !scanner.Scan() {
+			fmt.Fprintln(errOut)
+			break
+		}
+
+		switch strings.TrimSpace(strings.ToLower(scanner.Text())) {
+		case "b":
+			if err := setDependency(cmd.Context(), issueID, s.ID, false); err != nil {
+				return err
+			}
+			fmt.Fprintf(successOut(cmd), "Linked %s -> %s (blocked by)\n", issueID, s.ID)
+		case "k":
+			if err := setDependency(cmd.Context(), s.ID, issueID, false); err != nil {
+				return err
+			}
+			fmt.Fprintf(successOut(cmd), "Linked %s -> %s (blocked by)\n", s.ID, issueID)
+		case "q":
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// scoreLinkSuggestions ranks index entries by how likely they are related to issue.
+// Done issues, the issue itself, and its existing dependencies are skipped.
+func scoreLinkSuggestions(issue *models.Issue, entries []models.IndexEntry, wf *models.Workflow, minScore int) []LinkSuggestion {
+	words := titleWords(issue.Title)
+
+	var suggestions []LinkSuggestion
+	for _, entry := range entries {
+		if entry.ID == issue.ID || wf.IsDoneStatus(entry.Status) || slices.Contains(issue.BlockedBy, entry.ID) {
+			continue
+		}
+
+		s := LinkSuggestion{ID: entry.ID, Title: entry.Title, Status: entry.Status}
+
+		var sharedWords []string
+		for _, word := range titleWords(entry.Title) {
+			if slices.Contains(words, word) {
+				sharedWords = append(sharedWords, word)
+			}
+		}
+		if len(sharedWords) > 0 {
+			s.Score += suggestWordScore * len(sharedWords)
+			s.Reasons = append(s.Reasons, "shared words: "+strings.Join(sharedWords, ", "))
+		}
+
+		var sharedLabels []string
+		for _, label := range entry.Labels {
+			if slices.Contains(issue.Labels, label) {
+				sharedLabels = append(sharedLabels, label)
+			}
+		}
+		if len(sharedLabels) > 0 {
+			s.Score += suggestLabelScore * len(sharedLabels)
+			s.Reasons = append(s.Reasons, "shared labels: "+strings.Join(sharedLabels, ", "))
+		}
+
+		if issue.EpicID != "" && entry.EpicID == issue.EpicID {
+			s.Score += suggestEpicScore
+			s.Reasons = append(s.Reasons, "same epic: "+issue.EpicID)
+		}
+
+		if s.Score > 0 && s.Score >= minScore {
+			suggestions = append(suggestions, s)
+		}
+	}
+
+	// Highest score first; ties keep index order
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Score > suggestions[j].Score
+	})
+	return suggestions
+}
+
+// titleWords returns the distinct lowercase words of a title, without short and stop words.
+func titleWords(title string) []string {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(word)) < 3 || suggestStopWords[word] || slices.Contains(words, word) {
+			continue
+		}
+		words = append(words, word)
+	}
+	return words
+}
+
+// blocksIssue reports whether candidateID is already blocked by issueID.
+// Unreadable candidates are treated as not linked.
+func blocksIssue(ctx context.Context, projectKey, candidateID, issueID string) bool {
+	path, err := storage.IssuePath(ctx, projectKey, candidateID)
+	if err != nil {
+		return false
+	}
+	var candidate models.Issue
+	if err := storage.ReadJSON(ctx, path, &candidate); err != nil {
+		return false
+	}
+	return slices.Contains(candidate.BlockedBy, issueID)
+}
@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestTitleWords(t *testing.T) {
+	got := titleWords("Fix the crash on empty index, crash again!")
+	want := []string{"crash", "empty", "index", "again"}
+	if !slices.Equal(got, want) {
+		t.Errorf("titleWords() = %v, want %v", got, want)
+	}
+}
+
+func TestScoreLinkSuggestions(t *testing.T) {
+	issue := &models.Issue{
+		ID:        "P-1",
+		Title:     "Crash when index is empty",
+		Labels:    []string{"storage"},
+		EpicID:    "E-1",
+		BlockedBy: []string{"P-5"},
+	}
+	entries := []models.IndexEntry{
+		{ID: "P-1", Title: "Crash when index is empty", Status: models.StatusTODO},
+		{ID: "P-2", Title: "Rebuild index on startup", Status: models.StatusTODO},
+		{ID: "P-3", Title: "Unrelated", Status: models.StatusTODO, Labels: []string{"storage"}, EpicID: "E-1"},
+		{ID: "P-4", Title: "Empty index crash", Status: models.StatusDONE},
+		{ID: "P-5", Title: "Index crash", Status: models.StatusTODO},
+		{ID: "P-6", Title: "Only same epic", Status: models.StatusTODO, EpicID: "E-1"},
+	}
+
+	suggestions := scoreLinkSuggestions(issue, entries, nil, suggestWordScore)
+
+	var ids []string
+	for _, s := range suggestions {
+		ids = append(ids, s.ID)
+	}
+	// P-3: shared label + same epic (4), P-2: one shared word (2).
+	// P-1 is the issue itself, P-4 is done, P-5 is already linked, P-6 scores below the minimum.
+	if want := []string{"P-3", "P-2"}; !slices.Equal(ids, want) {
+		t.Fatalf("suggestions = %v, want %v", ids, want)
+	}
+	if suggestions[0].Score != suggestLabelScore+suggestEpicScore {
+		t.Errorf("P-3 score = %d, want %d", suggestions[0].Score, suggestLabelScore+suggestEpicScore)
+	}
+	if got := strings.Join(suggestions[1].Reasons, "; "); got != "shared words: index" {
+		t.Errorf("P-2 reasons = %q", got)
+	}
+}
+
+func TestIssueSuggestLinks_Interactive(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	setup := [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "Login page crashes", "--labels", "auth"},
+		{"issue", "create", "--project", projectKey, "--title", "Session token refresh", "--labels", "auth"},
+		{"issue", "create", "--project", projectKey, "--title", "Login page styling"},
+		{"issue", "create", "--project", projectKey, "--title", "Unrelated work"},
+	}
+	for _, args := range setup {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		cmd.SetOut(new(bytes.Buffer))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	issueID := projectKey + "-1"
+
+	// Accept the first suggestion as a blocker, mark the second as blocked by the issue
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"issue", "suggest-links", issueID})
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetIn(strings.NewReader("b\nk\n"))
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("suggest-links failed: %v", err)
+	}
+	if strings.Contains(out.String(), projectKey+"-4") {
+		t.Errorf("Unrelated issue should not be suggested, got:\n%s", out.String())
+	}
+
+	loadIssue := func(id string) *models.Issue {
+		path, _ := storage.IssuePath(t.Context(), projectKey, id)
+		var issue models.Issue
+		if err := storage.ReadJSON(t.Context(), path, &issue); err != nil {
+			t.Fatalf("Failed to load %s: %v", id, err)
+		}
+		return &issue
+	}
+
+	// -3 shares two title words (score 4) and comes before -2, which shares a label (score 3)
+	if got := loadIssue(issueID).BlockedBy; !slices.Equal(got, []string{projectKey + "-3"}) {
+		t.Errorf("%s blocked by = %v, want [%s-3]", issueID, got, projectKey)
+	}
+	if got := loadIssue(projectKey + "-2").BlockedBy; !slices.Equal(got, []string{issueID}) {
+		t.Errorf("%s-2 blocked by = %v, want [%s]", projectKey, got, issueID)
+	}
+
+	// Linked issues are no longer suggested
+	cmd = NewRootCmd()
+	cmd.SetArgs([]string{"issue", "suggest-links", issueID, "--format", "lson"})
+	out = new(bytes.Buffer)
+	cmd.SetOut(out)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("suggest-links failed: %v", err)
+	}
+	if out.String() != "" {
+		t.Errorf("Expected no suggestions after linking, got:\n%s", out.String())
+	}
+}
+
+func TestIssueLabels(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	issueID := projectKey + "-1"
+	steps := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"issue", "create", "--project", projectKey, "--title", "Labels", "--labels", "infra, ui"}, []string{"infra", "ui"}},
+		{[]string{"issue", "update", issueID, "--add-label", "perf", "--remove-label", "ui"}, []string{"infra", "perf"}},
+		{[]string{"issue", "update", issueID, "--labels", "docs"}, []string{"docs"}},
+		{[]string{"issue", "update", issueID, "--labels", "none"}, nil},
+	}
+
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"project", "create", projectKey})
+	cmd.SetOut(new(bytes.Buffer))
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	for _, step := range steps {
+		cmd := NewRootCmd()
+		cmd.SetArgs(step.args)
+		cmd.SetOut(new(bytes.Buffer))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%v failed: %v", step.args, err)
+		}
+
+		indexPath, _ := storage.ProjectIndexPath(t.Context(), projectKey)
+		var index models.ProjectIndex
+		if err := storage.ReadIndex(t.Context(), indexPath, &index); err != nil {
+			t.Fatalf("Failed to load index: %v", err)
+		}
+		if got := index.FindIssue(issueID).Labels; !slices.Equal(got, step.want) {
+			t.Errorf("after %v: index labels = %v, want %v", step.args, got, step.want)
+		}
+	}
+
+	cmd = NewRootCmd()
+	cmd.SetArgs([]string{"issue", "update", issueID, "--add-label", "two words"})
+	cmd.SetOut(new(bytes.Buffer))
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "invalid label") {
+		t.Errorf("Expected invalid label error, got %v", err)
+	}
+}
@@ -0,0 +1,776 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// NewSyncCmd creates and returns the sync command.
+func NewSyncCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Bring in changes from other copies of a project",
+		Long: `Bring in changes from other copies of a project.
+
+Changes from elsewhere (import --review, pull) are not applied directly: they
+are staged in the project's incoming area, and sync review shows them issue by
+issue and epic by epic so each can be accepted or rejected before it touches
+local data.
+
+Projects in CRDT mode kept in a synced folder can instead merge the conflict
+copies the sync service leaves with sync merge.
+
+To sync through git instead, sync init turns the data directory (or a single
+project) into a repository that records every change; sync push and sync pull
+exchange changes with a remote, merging issues edited on both sides.`,
+	}
+
+	cmd.AddCommand(NewSyncReviewCmd())
+	cmd.AddCommand(NewSyncMergeCmd())
+	cmd.AddCommand(NewSyncInitCmd())
+	cmd.AddCommand(NewSyncPushCmd())
+	cmd.AddCommand(NewSyncPullCmd())
+
+	return cmd
+}
+
+// NewSyncReviewCmd creates and returns the sync review command.
+func NewSyncReviewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "review",
+		Short: "Review staged incoming changes",
+		Long: `Show the staged incoming changes of a project as per-issue and per-epic diffs
+and accept or reject each. Without flags every change is shown and you are asked for each
+one; unanswered changes stay staged.`,
+		Example: `  buyruk sync review --project CORE
+  buyruk sync review --list
+  buyruk sync review --accept CORE-12,CORE-14 --reject CORE-13
+  buyruk sync review --accept all`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return reviewIncoming(cmd)
+		},
+	}
+
+	cmd.Flags().Bool("list", false, "Only show the staged changes")
+	cmd.Flags().String("accept", "", "Accept these changes (comma-separated issue and epic IDs, or \"all\")")
+	cmd.Flags().String("reject", "", "Reject these changes (comma-separated issue and epic IDs, or \"all\")")
+	cmd.MarkFlagsMutuallyExclusive("list", "accept")
+	cmd.MarkFlagsMutuallyExclusive("list", "reject")
+
+	return cmd
+}
+
+// incomingChange is a staged change to an issue or epic and the local record
+// it replaces, both as JSON.
+type incomingChange struct {
+	ID      string
+	Title   string
+	Epic    bool   // A change to an epic rather than an issue
+	Deleted bool   // The record was deleted in the other copy
+	Staged  []byte // nil for a deletion
+	Local   []byte // nil for a new record
+	path    string // The staged file
+}
+
+// fieldChange is a top-level field that differs between two versions of a record.
+type fieldChange struct {
+	Field string          `json:"field"`
+	From  json.RawMessage `json:"from"` // nil (null) if unset
+	To    json.RawMessage `json:"to"`
+}
+
+// uncomparedFields are bookkeeping fields left out of field comparisons.
+var uncomparedFields = []string{"updated_at", "updated_by", "history"}
+
+// jsonFieldChanges compares two JSON objects field by field, with status
+// first and the rest by name. When and by whom the object was last updated
+// and the history of status, PR, and sync changes are not compared. A nil before compares
+// against an empty object.
+func jsonFieldChanges(before, after []byte) ([]fieldChange, error) {
+	old := map[string]json.RawMessage{}
+	updated := map[string]json.RawMessage{}
+	if before != nil {
+		if err := json.Unmarshal(before, &old); err != nil {
+			return nil, err
+		}
+	}
+	if err := json.Unmarshal(after, &updated); err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for key := range old {
+		keys = append(keys, key)
+	}
+	for key := range updated {
+		if _, ok := old[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	// Status first, as it is the change most worth reading
+	slices.SortFunc(keys, func(a, b string) int {
+		if (a == "status") != (b == "status") {
+			if a == "status" {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+
+	var changes []fieldChange
+	for _, key := range keys {
+		if slices.Contains(uncomparedFields, key) || bytes.Equal(old[key], updated[key]) {
+			continue
+		}
+		changes = append(changes, fieldChange{Field: key, From: old[key], To: updated[key]})
+	}
+	return changes, nil
+}
+
+// formatFieldValue formats a JSON value for a one-line diff: strings as text
+// (only their first line), other values as compact JSON, shortened to fit.
+func formatFieldValue(raw json.RawMessage) string {
+	if raw == nil {
+		return "(none)"
+	}
+	text := string(raw)
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		if s == "" {
+			return "(none)"
+		}
+		text = s
+		if first, _, multiline := strings.Cut(s, "\n"); multiline {
+			text = first + " …"
+		}
+	} else {
+		var compact bytes.Buffer
+		if json.Compact(&compact, raw) == nil {
+			text = compact.String()
+		}
+	}
+	if runes := []rune(text); len(runes) > 60 {
+		text = string(runes[:59]) + "…"
+	}
+	return text
+}
+
+// stagedDeletion marks an issue or epic deleted in another copy of a project.
+// It is staged as ID.deleted next to where a change to the record would be.
+type stagedDeletion struct {
+	ID string `json:"id"`
+}
+
+// incomingDeletionPath returns the path of the staged deletion of the record
+// whose staged change would be at path.
+func incomingDeletionPath(path string) string {
+	return strings.TrimSuffix(path, ".json") + ".deleted"
+}
+
+// stageIncoming stages the issues and epics of another copy of a project for
+// review, skipping those that match the local copy. Records invalid for the
+// project workflow are skipped with a warning. With base, the IDs of the
+// records the other copy had when last synced, local records it no longer has
+// are staged for deletion; records added here since are left alone. Returns
+// the number of staged changes.
+func stageIncoming(projectKey string, data *ExportData, base []string, cmd *cobra.Command) (int, error) {
+	ctx := cmd.Context()
+	wf, err := loadWorkflow(ctx, projectKey)
+	if err != nil {
+		return 0, err
+	}
+	incomingDir, err := storage.IncomingDir(ctx, projectKey)
+	if err != nil {
+		return 0, fmt.Errorf("cli: failed to resolve incoming directory: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Join(incomingDir, "epics"), 0755); err != nil {
+		return 0, fmt.Errorf("cli: failed to create incoming directory: %w", err)
+	}
+
+	errOut := cmd.ErrOrStderr()
+	staged := 0
+	for _, issue := range data.Issues {
+		if !models.IsIssueIDOf(issue.ID, projectKey) {
+			fmt.Fprintf(errOut, "Warning: skipping issue %s: not an issue of project %q\n", issue.ID, projectKey)
+			continue
+		}
+		if err := issue.ValidateWithWorkflow(wf); err != nil {
+			fmt.Fprintf(errOut, "Warning: skipping invalid issue %s: %v\n", issue.ID, err)
+			continue
+		}
+		incomingPath, err := storage.IncomingPath(ctx, projectKey, issue.ID)
+		if err != nil {
+			return staged, fmt.Errorf("cli: failed to resolve incoming path: %w", err)
+		}
+		changed, err := stageRecord(ctx, projectKey, issue.ID, false, incomingPath, issue)
+		if err != nil {
+			return staged, err
+		}
+		if changed {
+			staged++
+		}
+	}
+	for _, epic := range data.Epics {
+		if err := epic.ValidateWithWorkflow(wf); err != nil {
+			fmt.Fprintf(errOut, "Warning: skipping invalid epic %s: %v\n", epic.ID, err)
+			continue
+		}
+		incomingPath, err := storage.IncomingEpicPath(ctx, projectKey, epic.ID)
+		if err != nil {
+			return staged, fmt.Errorf("cli: failed to resolve incoming path: %w", err)
+		}
+		changed, err := stageRecord(ctx, projectKey, epic.ID, true, incomingPath, epic)
+		if err != nil {
+			return staged, err
+		}
+		if changed {
+			staged++
+		}
+	}
+	if base == nil || data.Partial {
+		return staged, nil
+	}
+
+	// Records the other copy had at the last sync but no longer has were
+	// deleted there
+	index, err := loadQueryIndex(ctx, projectKey)
+	if err != nil {
+		return staged, err
+	}
+	remaining := make(map[string]bool)
+	for _, id := range exportRecordIDs(data) {
+		remaining[id] = true
+	}
+	for _, id := range base {
+		if remaining[id] {
+			continue
+		}
+		var incomingPath string
+		switch {
+		case index.FindIssue(id) != nil:
+			incomingPath, err = storage.IncomingPath(ctx, projectKey, id)
+		case index.FindEpic(id) != nil:
+			incomingPath, err = storage.IncomingEpicPath(ctx, projectKey, id)
+		default:
+			continue // Deleted here too
+		}
+		if err != nil {
+			return staged, fmt.Errorf("cli: failed to resolve incoming path: %w", err)
+		}
+		if err := os.Remove(incomingPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return staged, fmt.Errorf("cli: failed to drop incoming change %s: %w", id, err)
+		}
+		if err := storage.WriteJSONAtomic(ctx, incomingDeletionPath(incomingPath), &stagedDeletion{ID: id}); err != nil {
+			return staged, fmt.Errorf("cli: failed to stage deletion of %s: %w", id, err)
+		}
+		staged++
+	}
+	return staged, nil
+}
+
+// stageRecord stages an issue or epic at incomingPath, dropping a staged
+// deletion of it. A record that matches the local copy drops the change
+// staged for it instead. Returns whether a change was staged.
+func stageRecord(ctx context.Context, projectKey, id string, epic bool, incomingPath string, record interface{}) (bool, error) {
+	if err := os.Remove(incomingDeletionPath(incomingPath)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, fmt.Errorf("cli: failed to drop incoming deletion of %s: %w", id, err)
+	}
+	local, err := loadLocalRecord(ctx, projectKey, id, epic)
+	if err != nil {
+		return false, err
+	}
+	if local != nil {
+		incoming, err := json.Marshal(record)
+		if err != nil {
+			return false, fmt.Errorf("cli: failed to marshal %s: %w", id, err)
+		}
+		if changes, err := jsonFieldChanges(local, incoming); err == nil && len(changes) == 0 {
+			if err := os.Remove(incomingPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return false, fmt.Errorf("cli: failed to drop incoming change %s: %w", id, err)
+			}
+			return false, nil
+		}
+	}
+	if err := storage.WriteJSONAtomic(ctx, incomingPath, record); err != nil {
+		return false, fmt.Errorf("cli: failed to stage %s: %w", id, err)
+	}
+	return true, nil
+}
+
+// exportRecordIDs returns the IDs of the issues and epics of an export.
+func exportRecordIDs(data *ExportData) []string {
+	ids := make([]string, 0, len(data.Issues)+len(data.Epics))
+	for _, issue := range data.Issues {
+		ids = append(ids, issue.ID)
+	}
+	for _, epic := range data.Epics {
+		ids = append(ids, epic.ID)
+	}
+	return ids
+}
+
+// loadLocalIssue loads an issue of the project, or returns nil if it doesn't exist.
+func loadLocalIssue(ctx context.Context, projectKey, issueID string) (*models.Issue, error) {
+	issuePath, err := storage.IssuePath(ctx, projectKey, issueID)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve issue path: %w", err)
+	}
+	var issue models.Issue
+	if err := storage.ReadJSON(ctx, issuePath, &issue); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cli: failed to load issue %s: %w", issueID, err)
+	}
+	return &issue, nil
+}
+
+// loadLocalEpic loads an epic of the project, or returns nil if it doesn't exist.
+func loadLocalEpic(ctx context.Context, projectKey, epicID string) (*models.Epic, error) {
+	epicPath, err := storage.EpicPath(ctx, projectKey, epicID)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve epic path: %w", err)
+	}
+	var epic models.Epic
+	if err := storage.ReadJSON(ctx, epicPath, &epic); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cli: failed to load epic %s: %w", epicID, err)
+	}
+	return &epic, nil
+}
+
+// loadLocalRecord loads an issue or epic of the project as compact JSON, or
+// returns nil if it doesn't exist.
+func loadLocalRecord(ctx context.Context, projectKey, id string, epic bool) ([]byte, error) {
+	var record interface{}
+	if epic {
+		local, err := loadLocalEpic(ctx, projectKey, id)
+		if err != nil || local == nil {
+			return nil, err
+		}
+		record = local
+	} else {
+		local, err := loadLocalIssue(ctx, projectKey, id)
+		if err != nil || local == nil {
+			return nil, err
+		}
+		record = local
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to marshal %s: %w", id, err)
+	}
+	return data, nil
+}
+
+// parseIncomingRecord parses a staged issue or epic, returning it as compact
+// JSON with its title.
+func parseIncomingRecord(data []byte, epic bool) ([]byte, string, error) {
+	var record interface{}
+	var title string
+	if epic {
+		var e models.Epic
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, "", err
+		}
+		record, title = &e, e.Title
+	} else {
+		var issue models.Issue
+		if err := json.Unmarshal(data, &issue); err != nil {
+			return nil, "", err
+		}
+		record, title = &issue, issue.Title
+	}
+	compact, err := json.Marshal(record)
+	return compact, title, err
+}
+
+// loadIncoming loads the staged changes of a project: issues in issue ID
+// order, then epics.
+func loadIncoming(ctx context.Context, projectKey string) ([]incomingChange, error) {
+	incomingDir, err := storage.IncomingDir(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve incoming directory: %w", err)
+	}
+	changes, err := loadIncomingDir(ctx, projectKey, incomingDir, false)
+	if err != nil {
+		return nil, err
+	}
+	epics, err := loadIncomingDir(ctx, projectKey, filepath.Join(incomingDir, "epics"), true)
+	if err != nil {
+		return nil, err
+	}
+	return append(changes, epics...), nil
+}
+
+// loadIncomingDir loads the staged changes to issues, or to epics, in dir,
+// in ID order.
+func loadIncomingDir(ctx context.Context, projectKey, dir string, epic bool) ([]incomingChange, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cli: failed to read incoming directory: %w", err)
+	}
+
+	var changes []incomingChange
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		change := incomingChange{Epic: epic, path: filepath.Join(dir, entry.Name())}
+		if id, ok := strings.CutSuffix(entry.Name(), ".json"); ok {
+			change.ID = id
+		} else if id, ok := strings.CutSuffix(entry.Name(), ".deleted"); ok {
+			change.ID, change.Deleted = id, true
+		} else {
+			continue
+		}
+
+		if change.Local, err = loadLocalRecord(ctx, projectKey, change.ID, epic); err != nil {
+			return nil, err
+		}
+		if change.Local != nil {
+			_, change.Title, _ = parseIncomingRecord(change.Local, epic)
+		}
+		if !change.Deleted {
+			staged, err := os.ReadFile(change.path)
+			if err != nil {
+				return nil, fmt.Errorf("cli: failed to read incoming change %s: %w", change.ID, err)
+			}
+			if change.Staged, change.Title, err = parseIncomingRecord(staged, epic); err != nil {
+				return nil, fmt.Errorf("cli: failed to load incoming change %s: %w", change.ID, err)
+			}
+		}
+		changes = append(changes, change)
+	}
+	slices.SortFunc(changes, func(a, b incomingChange) int {
+		seqA, seqB := incomingSequence(a), incomingSequence(b)
+		if seqA != seqB {
+			return seqA - seqB
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return changes, nil
+}
+
+// incomingSequence returns the sequence number of the ID of a staged change,
+// or 0 if it has none.
+func incomingSequence(change incomingChange) int {
+	if change.Epic {
+		_, sequence, _ := models.ParseEpicID(change.ID)
+		return sequence
+	}
+	_, sequence, _ := models.ParseIssueID(change.ID)
+	return sequence
+}
+
+// renderIncomingChange prints an incoming change as a per-field diff.
+func renderIncomingChange(w io.Writer, change incomingChange) {
+	styles := ui.NewStyles()
+	kind := "changed"
+	switch {
+	case change.Deleted:
+		kind = "deleted"
+	case change.Local == nil:
+		kind = "new"
+	}
+	if change.Epic {
+		kind += " epic"
+	}
+
+	fmt.Fprintf(w, "%s %s (%s)\n", styles.ID(change.ID), styles.Title(change.Title), kind)
+	if change.Deleted {
+		return
+	}
+	fields, err := jsonFieldChanges(change.Local, change.Staged)
+	if err != nil {
+		return
+	}
+	for _, field := range fields {
+		if change.Local == nil {
+			fmt.Fprintf(w, "  %s: %s\n", field.Field, formatFieldValue(field.To))
+		} else {
+			fmt.Fprintf(w, "  %s: %s → %s\n", field.Field, formatFieldValue(field.From), formatFieldValue(field.To))
+		}
+	}
+}
+
+// reviewIncoming shows, accepts, or rejects the staged changes of a project.
+func reviewIncoming(cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	if _, err := loadQueryIndex(cmd.Context(), projectKey); err != nil {
+		return err
+	}
+	changes, err := loadIncoming(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if len(changes) == 0 {
+		fmt.Fprintf(out, "No incoming changes to review in project %q\n", projectKey)
+		return nil
+	}
+
+	if list, _ := cmd.Flags().GetBool("list"); list {
+		for i, change := range changes {
+			if i > 0 {
+				fmt.Fprintln(out)
+			}
+			renderIncomingChange(out, change)
+		}
+		return nil
+	}
+
+	acceptValue, _ := cmd.Flags().GetString("accept")
+	rejectValue, _ := cmd.Flags().GetString("reject")
+	if acceptValue != "" || rejectValue != "" {
+		accept, err := selectIncoming(changes, acceptValue)
+		if err != nil {
+			return err
+		}
+		reject, err := selectIncoming(changes, rejectValue)
+		if err != nil {
+			return err
+		}
+		for _, id := range accept {
+			if slices.Contains(reject, id) {
+				return invalidf("cli: %s cannot be both accepted and rejected", id)
+			}
+		}
+		return resolveIncoming(cmd.Context(), projectKey, changes, accept, reject, out)
+	}
+
+	// Ask for each change; stop at quit or end of input, leaving the rest staged
+	scanner := bufio.NewScanner(cmd.InOrStdin())
+	errOut := cmd.ErrOrStderr()
+	var accept, reject []string
+	for _, change := range changes {
+		renderIncomingChange(out, change)
+		action := "Accept"
+		if change.Deleted {
+			action = "Accept deleting"
+		}
+		fmt.Fprintf(errOut, "%s %s? [y]es, [n]o (reject), [s]kip, [q]uit: ", action, change.ID)
+		if !scanner.Scan() {
+			fmt.Fprintln(errOut)
+			break
+		}
+		answer := strings.TrimSpace(strings.ToLower(scanner.Text()))
+		if answer == "q" {
+			break
+		}
+		switch answer {
+		case "y", "yes":
+			accept = append(accept, change.ID)
+		case "n", "no":
+			reject = append(reject, change.ID)
+		}
+	}
+	return resolveIncoming(cmd.Context(), projectKey, changes, accept, reject, out)
+}
+
+// selectIncoming resolves a comma-separated list of staged issue and epic
+// IDs, or "all".
+func selectIncoming(changes []incomingChange, value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var staged []string
+	for _, change := range changes {
+		staged = append(staged, change.ID)
+	}
+	if strings.EqualFold(strings.TrimSpace(value), "all") {
+		return staged, nil
+	}
+	ids := splitList(value)
+	for _, id := range ids {
+		if !slices.Contains(staged, id) {
+			return nil, notFoundf("cli: no incoming change for %s", id)
+		}
+	}
+	return ids, nil
+}
+
+// resolveIncoming applies the accepted changes to the project and drops the
+// accepted and rejected ones from the incoming area. Accepted records, the
+// index, and the staged files change together or not at all. Once nothing is
+// left to review, a pulled remote version awaiting review becomes the
+// project's remote version.
+func resolveIncoming(ctx context.Context, projectKey string, changes []incomingChange, accept, reject []string, out io.Writer) error {
+	if len(accept) == 0 && len(reject) == 0 {
+		fmt.Fprintln(out, "No changes accepted or rejected")
+		return nil
+	}
+	wf, err := loadWorkflow(ctx, projectKey)
+	if err != nil {
+		return err
+	}
+	staged := make(map[string]incomingChange, len(changes))
+	for _, change := range changes {
+		staged[change.ID] = change
+	}
+
+	cleanup, err := storage.AcquireLock(ctx, projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to acquire lock: %w", err)
+	}
+	defer cleanup()
+
+	indexPath, err := storage.ProjectIndexPath(ctx, projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(ctx, indexPath, &index); err != nil {
+		return fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+
+	var writes []fileWrite
+	for _, id := range accept {
+		change := staged[id]
+		content, err := os.ReadFile(change.path)
+		if err != nil {
+			return fmt.Errorf("cli: failed to read incoming change %s: %w", id, err)
+		}
+		recordWrite, err := plannedIncomingWrite(ctx, projectKey, change, content, wf, &index)
+		if err != nil {
+			return err
+		}
+		if recordWrite != nil {
+			writes = append(writes, *recordWrite)
+		}
+		writes = append(writes, fileWrite{path: change.path, original: content})
+	}
+	for _, id := range reject {
+		change := staged[id]
+		content, err := os.ReadFile(change.path)
+		if err != nil {
+			return fmt.Errorf("cli: failed to read incoming change %s: %w", id, err)
+		}
+		writes = append(writes, fileWrite{path: change.path, original: content})
+	}
+	if len(accept) > 0 {
+		index.UpdatedAt = storage.Timestamp(ctx)
+		indexWrite, err := plannedIndexWrite(indexPath, &index)
+		if err != nil {
+			return err
+		}
+		writes = append(writes, indexWrite)
+	}
+	if len(accept)+len(reject) == len(changes) {
+		remoteWrite, err := plannedPendingRemoteWrite(ctx, projectKey)
+		if err != nil {
+			return err
+		}
+		if remoteWrite != nil {
+			writes = append(writes, *remoteWrite)
+		}
+	}
+
+	if err := storage.BeginTransaction(ctx, projectKey, "sync_review", map[string]interface{}{
+		"accepted": accept,
+		"rejected": reject,
+	}); err != nil {
+		return fmt.Errorf("cli: failed to begin transaction: %w", err)
+	}
+	if err := writeFiles(ctx, writes); err != nil {
+		storage.RollbackTransaction(ctx, projectKey)
+		return err
+	}
+	if err := storage.CommitTransaction(ctx, projectKey); err != nil {
+		return fmt.Errorf("cli: failed to commit transaction: %w", err)
+	}
+
+	for _, id := range accept {
+		fmt.Fprintf(out, "Accepted %s\n", id)
+	}
+	for _, id := range reject {
+		fmt.Fprintf(out, "Rejected %s\n", id)
+	}
+	return nil
+}
+
+// plannedIncomingWrite plans the write that applies an accepted change with
+// the given staged content and updates index to match. Returns nil for the
+// deletion of a record that is already gone.
+func plannedIncomingWrite(ctx context.Context, projectKey string, change incomingChange, content []byte, wf *models.Workflow, index *models.ProjectIndex) (*fileWrite, error) {
+	var path string
+	var err error
+	if change.Epic {
+		path, err = storage.EpicPath(ctx, projectKey, change.ID)
+	} else {
+		path, err = storage.IssuePath(ctx, projectKey, change.ID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve path of %s: %w", change.ID, err)
+	}
+
+	if change.Deleted {
+		original, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cli: failed to read %s: %w", path, err)
+		}
+		if change.Epic {
+			index.RemoveEpic(change.ID)
+		} else {
+			index.RemoveIssue(change.ID)
+		}
+		return &fileWrite{path: path, original: original}, nil
+	}
+
+	var record interface{}
+	if change.Epic {
+		var epic models.Epic
+		if err := json.Unmarshal(content, &epic); err != nil {
+			return nil, fmt.Errorf("cli: failed to parse incoming change %s: %w", change.ID, err)
+		}
+		if err := epic.ValidateWithWorkflow(wf); err != nil {
+			return nil, fmt.Errorf("cli: incoming change %s is invalid: %w", change.ID, err)
+		}
+		index.SetEpic(&epic)
+		record = &epic
+	} else {
+		var issue models.Issue
+		if err := json.Unmarshal(content, &issue); err != nil {
+			return nil, fmt.Errorf("cli: failed to parse incoming change %s: %w", change.ID, err)
+		}
+		if err := issue.ValidateWithWorkflow(wf); err != nil {
+			return nil, fmt.Errorf("cli: incoming change %s is invalid: %w", change.ID, err)
+		}
+		index.AddIssue(&issue)
+		record = &issue
+	}
+	recordWrite, err := plannedJSONWrite(path, record)
+	if err != nil {
+		return nil, err
+	}
+	return &recordWrite, nil
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestSyncReview(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(input string, args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		cmd.SetIn(strings.NewReader(input))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	loadIssue := func(id string) *models.Issue {
+		t.Helper()
+		issue, err := loadLocalIssue(t.Context(), projectKey, id)
+		if err != nil {
+			t.Fatalf("Failed to load %s: %v", id, err)
+		}
+		return issue
+	}
+
+	exportFile := filepath.Join(t.TempDir(), "remote.json")
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "Login page"},
+		{"issue", "create", "--project", projectKey, "--title", "Token refresh"},
+		{"export", projectKey, "--output", exportFile},
+	} {
+		if _, err := run("", args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	// The remote copy moved issue 1 along and added issue 3; issue 2 is unchanged
+	data, _ := os.ReadFile(exportFile)
+	var remote ExportData
+	if err := json.Unmarshal(data, &remote); err != nil {
+		t.Fatalf("Failed to parse export: %v", err)
+	}
+	remote.Issues[0].Status = models.StatusDOING
+	remote.Issues[0].Title = "Login page redesign"
+	remote.Issues = append(remote.Issues, &models.Issue{
+		ID: projectKey + "-3", Type: models.TypeTask, Title: "Audit log", Status: models.StatusTODO,
+	})
+	data, _ = json.Marshal(&remote)
+	os.WriteFile(exportFile, data, 0644)
+
+	if _, err := run("", "import", exportFile, "--review", "--overwrite"); err == nil {
+		t.Error("Expected --review and --overwrite together to fail")
+	}
+	out, err := run("", "import", exportFile, "--review")
+	if err != nil {
+		t.Fatalf("import --review failed: %v", err)
+	}
+	if !strings.Contains(out, "Staged 2 changes") {
+		t.Errorf("Expected 2 staged changes, got: %s", out)
+	}
+	if loadIssue(projectKey+"-1").Status != models.StatusTODO || loadIssue(projectKey+"-3") != nil {
+		t.Fatal("Expected staged changes not to touch local data")
+	}
+
+	out, err = run("", "sync", "review", "--project", projectKey, "--list")
+	if err != nil {
+		t.Fatalf("sync review --list failed: %v", err)
+	}
+	for _, want := range []string{
+		projectKey + "-1", "(changed)", "status: TODO → DOING", "title: Login page → Login page redesign",
+		projectKey + "-3", "(new)", "title: Audit log",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in review list, got: %s", want, out)
+		}
+	}
+	if strings.Contains(out, projectKey+"-2 ") {
+		t.Errorf("Expected unchanged issue not to be staged, got: %s", out)
+	}
+
+	if _, err := run("", "sync", "review", "--project", projectKey, "--accept", projectKey+"-9"); err == nil {
+		t.Error("Expected accepting an unstaged issue to fail")
+	}
+
+	// Accept the change to issue 1, reject the new issue
+	out, err = run("y\nn\n", "sync", "review", "--project", projectKey)
+	if err != nil {
+		t.Fatalf("sync review failed: %v", err)
+	}
+	if !strings.Contains(out, "Accepted "+projectKey+"-1") || !strings.Contains(out, "Rejected "+projectKey+"-3") {
+		t.Errorf("Expected one accepted and one rejected change, got: %s", out)
+	}
+	if issue := loadIssue(projectKey + "-1"); issue.Status != models.StatusDOING || issue.Title != "Login page redesign" {
+		t.Errorf("Expected accepted change to apply, got %s %q", issue.Status, issue.Title)
+	}
+	if loadIssue(projectKey+"-3") != nil {
+		t.Error("Expected rejected issue not to be created")
+	}
+	if out, _ := run("", "sync", "review", "--project", projectKey); !strings.Contains(out, "No incoming changes") {
+		t.Errorf("Expected the incoming area to be empty, got: %s", out)
+	}
+
+	// Staging again only picks up what still differs; accept it non-interactively
+	if out, _ := run("", "import", exportFile, "--review"); !strings.Contains(out, "Staged 1 changes") {
+		t.Errorf("Expected 1 staged change, got: %s", out)
+	}
+	if _, err := run("", "sync", "review", "--project", projectKey, "--accept", "all"); err != nil {
+		t.Fatalf("sync review --accept all failed: %v", err)
+	}
+	index, err := loadQueryIndex(t.Context(), projectKey)
+	if err != nil {
+		t.Fatalf("Failed to load index: %v", err)
+	}
+	if entry := index.FindIssue(projectKey + "-3"); entry == nil || entry.Title != "Audit log" {
+		t.Errorf("Expected accepted new issue in the index, got %+v", entry)
+	}
+}
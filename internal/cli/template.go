@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// templateFormat is the --format value that renders through --template or --template-file.
+const templateFormat = "template"
+
+// resolveTemplate returns the template text requested via the --template or --template-file flag.
+// The --template value may name a template stored in config (template.<name>) or be inline template text.
+// Returns false if no template was requested; --format template without a template is an error.
+func resolveTemplate(cmd *cobra.Command) (string, bool, error) {
+	if path, _ := cmd.Flags().GetString("template-file"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", false, fmt.Errorf("cli: failed to read template file: %w", err)
+		}
+		return string(data), true, nil
+	}
+	if value, _ := cmd.Flags().GetString("template"); value != "" {
+		if text, ok := config.LookupTemplate(value); ok {
+			return text, true, nil
+		}
+		return value, true, nil
+	}
+	if config.ResolveFormat(cmd) == templateFormat {
+		return "", false, invalidf("cli: --format template requires --template or --template-file")
+	}
+	return "", false, nil
+}
+
+// addTemplateFlags adds the --template and --template-file flags to a command.
+func addTemplateFlags(cmd *cobra.Command, usage string) {
+	cmd.Flags().String("template", "", usage+" (inline text or name of a configured template)")
+	cmd.Flags().String("template-file", "", usage+" read from a file")
+	cmd.MarkFlagsMutuallyExclusive("template", "template-file")
+}
+
+// renderIssuesWithTemplate renders each issue through the given template text.
+func renderIssuesWithTemplate(ctx context.Context, text string, issues []*models.Issue, w io.Writer) error {
+	items := make([]interface{}, len(issues))
+	for i, issue := range issues {
+		items[i] = issue
+	}
+	return ui.RenderTemplate(ctx, text, items, w)
+}
@@ -0,0 +1,503 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// NewTrashCmd creates and returns the trash command.
+func NewTrashCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trash",
+		Short: "List or empty deleted issues",
+		Long: `List or empty the issues deleted from a project.
+
+issue delete moves issues into projects/[KEY]/.trash/ with the time they were
+deleted, out of the index. They stay there, and can be brought back with
+buyruk issue restore, until the trash is emptied; their attachments are kept
+until then too.`,
+	}
+
+	cmd.AddCommand(NewTrashListCmd())
+	cmd.AddCommand(NewTrashEmptyCmd())
+
+	return cmd
+}
+
+// NewTrashListCmd creates and returns the trash list command.
+func NewTrashListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the deleted issues of a project",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listTrash(cmd)
+		},
+	}
+
+	return cmd
+}
+
+// NewTrashEmptyCmd creates and returns the trash empty command.
+func NewTrashEmptyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "empty",
+		Short: "Permanently delete the issues in the trash",
+		Long: `Permanently delete the issues in the trash of a project, or with
+--older-than only those deleted longer ago than that.`,
+		Example: `  buyruk trash empty --project CORE
+  buyruk trash empty --project CORE --older-than 30d --yes`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return emptyTrash(cmd)
+		},
+	}
+
+	cmd.Flags().String("older-than", "", "Only delete issues deleted longer ago than this (e.g. 30d, 4w)")
+	cmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
+
+	return cmd
+}
+
+// NewIssueRestoreCmd creates and returns the issue restore command.
+func NewIssueRestoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore <id>",
+		Short: "Restore a deleted issue from the trash",
+		Long: `Restore a deleted issue from the trash of its project.
+
+The issue gets back its dependencies and links to issues that still exist, and
+the issues on the other side get back their reverse links. A parent issue that
+no longer exists is dropped.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return restoreIssue(args[0], cmd)
+		},
+	}
+
+	return cmd
+}
+
+// trashListEntry is a deleted issue as listed by trash list.
+type trashListEntry struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	DeletedAt string `json:"deleted_at"`
+	DeletedBy string `json:"deleted_by,omitempty"`
+}
+
+// listTrash renders the deleted issues of a project in the resolved output format.
+func listTrash(cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	trashed, err := loadTrash(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+
+	entries := make([]trashListEntry, 0, len(trashed))
+	for _, t := range trashed {
+		entry := trashListEntry{ID: t.ID, DeletedAt: t.DeletedAt, DeletedBy: t.DeletedBy}
+		if issue, err := t.ParseIssue(); err == nil {
+			entry.Title = issue.Title
+		}
+		entries = append(entries, entry)
+	}
+
+	w := cmd.OutOrStdout()
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(w, entries)
+	case config.DefaultFormatLSON:
+		for _, entry := range entries {
+			fmt.Fprintf(w, "@TRASH: %s|%s|%s\n", entry.ID, entry.DeletedAt, entry.Title)
+		}
+	default:
+		if len(entries) == 0 {
+			fmt.Fprintf(w, "The trash of %s is empty\n", projectKey)
+			return nil
+		}
+		styles := ui.NewStyles()
+		loc := config.Location()
+		now := storage.Now(cmd.Context())
+		for _, entry := range entries {
+			line := fmt.Sprintf("%s  %s  deleted %s", styles.ID(entry.ID), styles.Title(entry.Title), ui.FormatTimeRelative(entry.DeletedAt, loc, now))
+			if entry.DeletedBy != "" {
+				line += " by " + entry.DeletedBy
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+	return nil
+}
+
+// emptyTrash permanently deletes the issues in the trash of a project.
+func emptyTrash(cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	olderThan, hasOlderThan, err := getDurationFlag(cmd, "older-than")
+	if err != nil {
+		return err
+	}
+	trashed, err := loadTrash(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+	if err := checkProjectWritable(cmd.Context(), projectKey); err != nil {
+		return err
+	}
+
+	cutoff := storage.Now(cmd.Context()).Add(-olderThan)
+	var ids []string
+	for _, t := range trashed {
+		if hasOlderThan {
+			deletedAt, err := time.Parse(time.RFC3339, t.DeletedAt)
+			if err != nil || deletedAt.After(cutoff) {
+				continue
+			}
+		}
+		ids = append(ids, t.ID)
+	}
+	out := successOut(cmd)
+	if len(ids) == 0 {
+		fmt.Fprintf(out, "No issues to delete from the trash of %s\n", projectKey)
+		return nil
+	}
+
+	if yes, _ := cmd.Flags().GetBool("yes"); !yes {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Permanently delete %d issue(s) from the trash of %s? (yes/no): ", len(ids), projectKey)
+		scanner := bufio.NewScanner(cmd.InOrStdin())
+		if !scanner.Scan() {
+			return fmt.Errorf("cli: failed to read confirmation: %w", scanner.Err())
+		}
+		response := strings.TrimSpace(strings.ToLower(scanner.Text()))
+		if response != "yes" && response != "y" {
+			return fmt.Errorf("cli: emptying the trash cancelled")
+		}
+	}
+
+	cleanup, err := storage.AcquireLock(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to acquire lock: %w", err)
+	}
+	defer cleanup()
+
+	for _, id := range ids {
+		trashPath, err := storage.TrashPath(cmd.Context(), projectKey, id)
+		if err != nil {
+			return fmt.Errorf("cli: failed to resolve trash path: %w", err)
+		}
+		if err := os.Remove(trashPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("cli: failed to delete %s from the trash: %w", id, err)
+		}
+		if attachmentsDir, err := storage.AttachmentsDir(cmd.Context(), projectKey, id); err == nil {
+			if err := os.RemoveAll(attachmentsDir); err != nil {
+				return fmt.Errorf("cli: failed to delete attachments of %s: %w", id, err)
+			}
+		}
+	}
+
+	fmt.Fprintf(out, "Permanently deleted %d issue(s) from the trash of %s\n", len(ids), projectKey)
+	return nil
+}
+
+// restoreIssue moves a deleted issue from the trash back into its project.
+func restoreIssue(issueID string, cmd *cobra.Command) error {
+	projectKey, _, err := parseIssueID(cmd.Context(), issueID)
+	if err != nil {
+		return invalidf("cli: invalid issue ID %q: %w", issueID, err)
+	}
+	trashPath, err := storage.TrashPath(cmd.Context(), projectKey, issueID)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve trash path: %w", err)
+	}
+	issuePath, err := storage.IssuePath(cmd.Context(), projectKey, issueID)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve issue path: %w", err)
+	}
+	indexPath, err := storage.ProjectIndexPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+
+	// Links to the restored issue are added back to the issues on the other
+	// side, so the projects of linked issues are locked along with this one
+	trashed, _, err := loadTrashedIssue(cmd.Context(), projectKey, issueID)
+	if err != nil {
+		return err
+	}
+	issue, err := trashed.ParseIssue()
+	if err != nil {
+		return err
+	}
+	projectKeys := []string{projectKey}
+	for _, id := range issue.LinkedIssueIDs() {
+		if key, _, err := parseIssueID(cmd.Context(), id); err == nil && !slices.Contains(projectKeys, key) {
+			projectKeys = append(projectKeys, key)
+		}
+	}
+
+	cleanup, err := storage.AcquireLocks(cmd.Context(), projectKeys...)
+	if err != nil {
+		return fmt.Errorf("cli: failed to acquire lock: %w", err)
+	}
+	defer cleanup()
+
+	// Re-read under the lock, the trash may have changed since
+	trashed, original, err := loadTrashedIssue(cmd.Context(), projectKey, issueID)
+	if err != nil {
+		return err
+	}
+	if issue, err = trashed.ParseIssue(); err != nil {
+		return err
+	}
+	if _, err := os.Stat(issuePath); err == nil {
+		return conflictf("cli: issue %q already exists", issueID)
+	}
+
+	now := storage.Timestamp(cmd.Context())
+	writes, relinked, err := relinkRestoredIssue(cmd.Context(), issue, projectKeys, now)
+	if err != nil {
+		return err
+	}
+	parentDropped := false
+	if issue.ParentID != "" {
+		parentPath, err := storage.IssuePath(cmd.Context(), projectKey, issue.ParentID)
+		if err == nil {
+			_, err = os.Stat(parentPath)
+		}
+		if err != nil {
+			issue.ParentID = ""
+			parentDropped = true
+		}
+	}
+	issue.UpdatedAt = now
+
+	issueData, err := json.MarshalIndent(issue, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cli: failed to marshal issue: %w", err)
+	}
+
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(cmd.Context(), indexPath, &index); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("cli: failed to read project index: %w", err)
+		}
+		index = models.ProjectIndex{ProjectKey: projectKey, Issues: []models.IndexEntry{}}
+	}
+	index.AddIssue(issue)
+	index.UpdatedAt = now
+	indexWrite, err := plannedIndexWrite(indexPath, &index)
+	if err != nil {
+		return err
+	}
+	writes = append([]fileWrite{{path: issuePath, data: issueData}}, writes...)
+	writes = append(writes, indexWrite, fileWrite{path: trashPath, original: original})
+
+	for _, key := range projectKeys {
+		if err := storage.BeginTransaction(cmd.Context(), key, "restore_issue", map[string]interface{}{
+			"issue_id": issueID,
+			"file":     issuePath,
+		}); err != nil {
+			return fmt.Errorf("cli: failed to begin transaction: %w", err)
+		}
+	}
+
+	success := false
+	defer func() {
+		if !success {
+			for _, key := range projectKeys {
+				storage.RollbackTransaction(cmd.Context(), key)
+			}
+		}
+	}()
+
+	if err := writeFiles(cmd.Context(), writes); err != nil {
+		return err
+	}
+	for _, key := range projectKeys {
+		if err := storage.CommitTransaction(cmd.Context(), key); err != nil {
+			return fmt.Errorf("cli: failed to commit transaction: %w", err)
+		}
+	}
+	success = true
+
+	out := successOut(cmd)
+	fmt.Fprintf(out, "Restored issue %q\n", issueID)
+	if len(relinked) > 0 {
+		fmt.Fprintf(out, "Restored links to %s in %s\n", issueID, strings.Join(relinked, ", "))
+	}
+	if parentDropped {
+		fmt.Fprintf(out, "Parent issue of %s no longer exists; it is now a top-level issue\n", issueID)
+	}
+	return nil
+}
+
+// relinkRestoredIssue plans adding back the reverse links of a restored
+// issue's dependencies and links on the issues of the given (locked) projects,
+// and drops its links to issues that no longer exist. Returns the writes and
+// the IDs of the relinked issues.
+func relinkRestoredIssue(ctx context.Context, issue *models.Issue, lockedKeys []string, now string) ([]fileWrite, []string, error) {
+	var writes []fileWrite
+	var relinked []string
+	for _, id := range issue.LinkedIssueIDs() {
+		key, _, err := parseIssueID(ctx, id)
+		if err != nil || !slices.Contains(lockedKeys, key) {
+			continue
+		}
+		path, err := storage.IssuePath(ctx, key, id)
+		if err != nil {
+			continue
+		}
+		original, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				issue.RemoveLinksTo(id)
+				continue
+			}
+			return nil, nil, fmt.Errorf("cli: failed to read %s: %w", path, err)
+		}
+		var other models.Issue
+		if err := json.Unmarshal(original, &other); err != nil {
+			continue
+		}
+
+		before := len(other.BlockedBy) + len(other.Links)
+		if slices.Contains(issue.BlockedBy, id) {
+			models.LinkIssues(issue, &other, models.LinkBlockedBy)
+		}
+		for _, link := range issue.Links {
+			if link.ID == id {
+				models.LinkIssues(issue, &other, link.Type)
+			}
+		}
+		if len(other.BlockedBy)+len(other.Links) == before {
+			continue
+		}
+		other.MarkBlocked(now)
+		other.UpdatedAt = now
+		data, err := json.MarshalIndent(&other, "", "  ")
+		if err != nil {
+			return nil, nil, fmt.Errorf("cli: failed to marshal issue %s: %w", id, err)
+		}
+		writes = append(writes, fileWrite{path: path, data: data, original: original})
+		relinked = append(relinked, id)
+	}
+	return writes, relinked, nil
+}
+
+// trashIssueWrite plans keeping the file of a deleted issue (original) in the
+// trash. A file that isn't valid JSON can't be kept, and false is returned.
+func trashIssueWrite(ctx context.Context, projectKey, issueID string, original []byte) (fileWrite, bool, error) {
+	if !json.Valid(original) {
+		return fileWrite{}, false, nil
+	}
+	trashPath, err := storage.TrashPath(ctx, projectKey, issueID)
+	if err != nil {
+		return fileWrite{}, false, fmt.Errorf("cli: failed to resolve trash path: %w", err)
+	}
+	write, err := plannedJSONWrite(trashPath, &models.TrashedIssue{
+		ID:        issueID,
+		DeletedAt: storage.Timestamp(ctx),
+		DeletedBy: currentActor(ctx),
+		Issue:     original,
+	})
+	if err != nil {
+		return fileWrite{}, false, err
+	}
+	return write, true, nil
+}
+
+// loadTrashedIssue loads a deleted issue from the trash, and the content of
+// its trash file.
+func loadTrashedIssue(ctx context.Context, projectKey, issueID string) (*models.TrashedIssue, []byte, error) {
+	trashPath, err := storage.TrashPath(ctx, projectKey, issueID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cli: failed to resolve trash path: %w", err)
+	}
+	data, err := os.ReadFile(trashPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, notFoundf("cli: issue %q is not in the trash", issueID)
+		}
+		return nil, nil, fmt.Errorf("cli: failed to read %s: %w", trashPath, err)
+	}
+	var trashed models.TrashedIssue
+	if err := json.Unmarshal(data, &trashed); err != nil {
+		return nil, nil, fmt.Errorf("cli: invalid trash file %s: %w", trashPath, err)
+	}
+	return &trashed, data, nil
+}
+
+// loadTrash loads the deleted issues of a project, in ID order. Trash files
+// that can't be read are skipped.
+func loadTrash(ctx context.Context, projectKey string) ([]models.TrashedIssue, error) {
+	projectDir, err := storage.ProjectDir(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve project directory: %w", err)
+	}
+	if _, err := os.Stat(projectDir); os.IsNotExist(err) {
+		return nil, notFoundf("cli: project %q does not exist", projectKey)
+	}
+	trashDir, err := storage.TrashDir(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve trash directory: %w", err)
+	}
+
+	var trashed []models.TrashedIssue
+	for _, id := range jsonFileIDs(trashDir) {
+		var t models.TrashedIssue
+		if err := storage.ReadJSON(ctx, filepath.Join(trashDir, id+".json"), &t); err != nil {
+			continue
+		}
+		trashed = append(trashed, t)
+	}
+	slices.SortFunc(trashed, func(a, b models.TrashedIssue) int {
+		_, seqA, _ := models.ParseIssueID(a.ID)
+		_, seqB, _ := models.ParseIssueID(b.ID)
+		if seqA != seqB {
+			return seqA - seqB
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return trashed, nil
+}
+
+// maxTrashedSequence returns the highest sequence number of the deleted
+// issues of a project, so that their IDs are not reused while they can be
+// restored.
+func maxTrashedSequence(ctx context.Context, projectKey string) int {
+	trashDir, err := storage.TrashDir(ctx, projectKey)
+	if err != nil {
+		return 0
+	}
+	maxSeq := 0
+	for _, id := range jsonFileIDs(trashDir) {
+		if _, seq, err := models.ParseIssueID(id); err == nil && models.IsIssueIDOf(id, projectKey) && seq > maxSeq {
+			maxSeq = seq
+		}
+	}
+	return maxSeq
+}
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestTrash(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(input string, args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(append(args, "--project", projectKey, "--no-hooks"))
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(new(bytes.Buffer))
+		cmd.SetIn(strings.NewReader(input))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	mustRun := func(args ...string) string {
+		t.Helper()
+		out, err := run("", args...)
+		if err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+		return out
+	}
+	id := func(n int) string { return fmt.Sprintf("%s-%d", projectKey, n) }
+	readIssue := func(issueID string) *models.Issue {
+		t.Helper()
+		path, _ := storage.IssuePath(t.Context(), projectKey, issueID)
+		var issue models.Issue
+		if err := storage.ReadJSON(t.Context(), path, &issue); err != nil {
+			t.Fatalf("Failed to read issue %s: %v", issueID, err)
+		}
+		return &issue
+	}
+
+	mustRun("project", "create", projectKey)
+	mustRun("issue", "create", "--title", "Keep")
+	mustRun("issue", "create", "--title", "Delete me")
+	mustRun("issue", "create", "--title", "Old")
+	mustRun("issue", "link", id(2), id(1))
+
+	// Deleting moves the issue into the trash, out of the index
+	if out := mustRun("issue", "delete", id(2), "--yes"); !strings.Contains(out, "Moved "+id(2)+" to the trash") {
+		t.Errorf("Expected trash message, got: %s", out)
+	}
+	if len(readIssue(id(1)).Links) != 0 {
+		t.Errorf("Expected the reverse link to be removed on delete")
+	}
+	if _, err := run("", "view", id(2)); err == nil || !strings.Contains(err.Error(), "is in the trash") {
+		t.Errorf("Expected view to point to the trash, got: %v", err)
+	}
+	if out := mustRun("trash", "list", "--format", "json"); !strings.Contains(out, `"title": "Delete me"`) || !strings.Contains(out, `"deleted_at"`) {
+		t.Errorf("Expected trashed issue in list, got: %s", out)
+	}
+
+	// IDs of trashed issues are not reused
+	mustRun("issue", "create", "--title", "New")
+	newPath, _ := storage.IssuePath(t.Context(), projectKey, id(4))
+	if _, err := os.Stat(newPath); err != nil {
+		t.Errorf("Expected the new issue to be %s: %v", id(4), err)
+	}
+
+	// Restoring brings back the issue, its index entry, and reverse links
+	if out := mustRun("issue", "restore", id(2)); !strings.Contains(out, "Restored issue") || !strings.Contains(out, "Restored links to "+id(2)+" in "+id(1)) {
+		t.Errorf("Unexpected restore output: %s", out)
+	}
+	if got := readIssue(id(2)).BlockedBy; !slices.Equal(got, []string{id(1)}) {
+		t.Errorf("BlockedBy = %v, want [%s]", got, id(1))
+	}
+	if got := readIssue(id(1)).LinkedIDs(models.LinkBlocks); !slices.Equal(got, []string{id(2)}) {
+		t.Errorf("Blocks = %v, want [%s]", got, id(2))
+	}
+	if out := mustRun("list", "--format", "json"); !strings.Contains(out, id(2)) {
+		t.Errorf("Expected restored issue in list, got: %s", out)
+	}
+	if _, err := run("", "issue", "restore", id(2)); err == nil || !strings.Contains(err.Error(), "not in the trash") {
+		t.Errorf("Expected not in trash error, got: %v", err)
+	}
+
+	// Emptying with --older-than keeps recently deleted issues
+	mustRun("issue", "delete", id(3), "--yes")
+	mustRun("issue", "delete", id(4), "--yes")
+	trashPath, _ := storage.TrashPath(t.Context(), projectKey, id(3))
+	var entry models.TrashedIssue
+	storage.ReadJSON(t.Context(), trashPath, &entry)
+	entry.DeletedAt = storage.Now(t.Context()).Add(-60 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	storage.WriteJSONAtomic(t.Context(), trashPath, &entry)
+
+	if _, err := run("no\n", "trash", "empty"); err == nil {
+		t.Error("Expected emptying to be cancelled")
+	}
+	if out := mustRun("trash", "empty", "--older-than", "30d", "--yes"); !strings.Contains(out, "Permanently deleted 1 issue(s)") {
+		t.Errorf("Unexpected empty output: %s", out)
+	}
+	if out := mustRun("trash", "list", "--format", "lson"); strings.Contains(out, id(3)+"|") || !strings.Contains(out, "@TRASH: "+id(4)+"|") {
+		t.Errorf("Expected only %s left in the trash, got: %s", id(4), out)
+	}
+	if _, err := run("yes\n", "trash", "empty"); err != nil {
+		t.Fatalf("trash empty failed: %v", err)
+	}
+	if out := mustRun("trash", "list"); !strings.Contains(out, "is empty") {
+		t.Errorf("Expected an empty trash, got: %s", out)
+	}
+}
@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/secret"
+	"github.com/buyruk-project/buyruk-cli/internal/timeparse"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// NewUnlockCmd creates and returns the unlock command.
+func NewUnlockCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unlock",
+		Short: "Unlock sensitive issues for this session",
+		Long: `Unlock a project key so the descriptions and comments of sensitive issues
+can be viewed and edited from this shell session.
+
+Sensitive issues (issue create/update --sensitive) keep their description and
+comments encrypted at rest; titles and other fields stay listable. The first
+unlock of a key creates it from the passphrase entered twice. A project can
+have several keys (--key) to separate who can read which issues.
+
+The unlocked key is kept for --ttl in the user's config directory, readable by
+the user only, and is tied to the parent shell (or $BUYRUK_SESSION). Use
+--forget to lock the project again. Scripts can set $BUYRUK_PASSPHRASE instead.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return unlockProject(cmd)
+		},
+	}
+
+	cmd.Flags().String("key", secret.DefaultKey, "Name of the project key")
+	cmd.Flags().String("ttl", "8h", "How long the key stays unlocked (e.g. 30m, 8h, 1d)")
+	cmd.Flags().Bool("forget", false, "Lock the project's keys again in this session")
+
+	return cmd
+}
+
+// unlockProject unlocks (or creates) a project key for the current session.
+func unlockProject(cmd *cobra.Command) error {
+	projectKey, err := config.ResolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	if _, err := loadQueryIndex(cmd.Context(), projectKey); err != nil {
+		return err
+	}
+	out := successOut(cmd)
+
+	if forget, _ := cmd.Flags().GetBool("forget"); forget {
+		removed, err := secret.ForgetSessionKeys(projectKey)
+		if err != nil {
+			return fmt.Errorf("cli: failed to forget keys: %w", err)
+		}
+		fmt.Fprintf(out, "Locked project %q (%d unlocked keys forgotten)\n", projectKey, removed)
+		return nil
+	}
+
+	name, _ := cmd.Flags().GetString("key")
+	if err := models.ValidateLabel(name); err != nil {
+		return invalidf("cli: invalid key name %q", name)
+	}
+	ttlValue, _ := cmd.Flags().GetString("ttl")
+	ttl, err := timeparse.ParseDuration(ttlValue)
+	if err != nil {
+		return invalidf("cli: invalid --ttl value: %w", err)
+	}
+
+	keys, err := secret.LoadKeys(cmd.Context(), projectKey)
+	if err != nil {
+		return err
+	}
+
+	errOut := cmd.ErrOrStderr()
+	reader := bufio.NewReader(cmd.InOrStdin())
+	var key []byte
+	if info, ok := keys[name]; ok {
+		passphrase, err := readPassphrase(cmd, reader, fmt.Sprintf("Passphrase for key %q of %s: ", name, projectKey))
+		if err != nil {
+			return err
+		}
+		if key, err = info.Unlock(passphrase); err != nil {
+			if errors.Is(err, secret.ErrWrongPassphrase) {
+				return fmt.Errorf("cli: wrong passphrase for key %q", name)
+			}
+			return fmt.Errorf("cli: failed to unlock key: %w", err)
+		}
+	} else {
+		fmt.Fprintf(errOut, "Key %q of project %q does not exist yet and will be created.\n", name, projectKey)
+		fmt.Fprintln(errOut, "There is no way to recover encrypted issues if the passphrase is lost.")
+		passphrase, err := readPassphrase(cmd, reader, "New passphrase: ")
+		if err != nil {
+			return err
+		}
+		confirm, err := readPassphrase(cmd, reader, "Repeat passphrase: ")
+		if err != nil {
+			return err
+		}
+		if passphrase != confirm {
+			return fmt.Errorf("cli: passphrases do not match")
+		}
+		info, newKey, err := secret.NewKeyInfo(cmd.Context(), passphrase)
+		if err != nil {
+			return fmt.Errorf("cli: failed to create key: %w", err)
+		}
+		if err := secret.AddKey(cmd.Context(), projectKey, name, info); err != nil {
+			return fmt.Errorf("cli: failed to save key: %w", err)
+		}
+		key = newKey
+	}
+
+	if err := secret.SaveSessionKey(cmd.Context(), projectKey, name, key, ttl); err != nil {
+		return fmt.Errorf("cli: failed to unlock key: %w", err)
+	}
+	fmt.Fprintf(out, "Unlocked key %q of project %q for %s\n", name, projectKey, ttlValue)
+	return nil
+}
+
+// readPassphrase prompts for a passphrase without echo on a terminal, or reads
+// a line from the command's input otherwise.
+func readPassphrase(cmd *cobra.Command, reader *bufio.Reader, prompt string) (string, error) {
+	errOut := cmd.ErrOrStderr()
+	fmt.Fprint(errOut, prompt)
+	if f, ok := cmd.InOrStdin().(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		data, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(errOut)
+		if err != nil {
+			return "", fmt.Errorf("cli: failed to read passphrase: %w", err)
+		}
+		return string(data), nil
+	}
+	line, err := reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("cli: failed to read passphrase: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// issueKey returns the unlocked key of a sensitive issue, or an error telling
+// the user to unlock it.
+func issueKey(ctx context.Context, projectKey, name string) ([]byte, error) {
+	key, ok := secret.SessionKey(ctx, projectKey, name)
+	if !ok {
+		if name == secret.DefaultKey {
+			return nil, fmt.Errorf("cli: project %q is locked (run `buyruk unlock --project %s`)", projectKey, projectKey)
+		}
+		return nil, fmt.Errorf("cli: key %q of project %q is locked (run `buyruk unlock --project %s --key %s`)", name, projectKey, projectKey, name)
+	}
+	return key, nil
+}
+
+// editSealed runs edit on a sealed issue's decrypted description and comments,
+// then encrypts them again. Issues that are not sealed are edited directly.
+func editSealed(ctx context.Context, issue *models.Issue, projectKey string, edit func() error) error {
+	if issue.Sealed == nil {
+		return edit()
+	}
+	name := issue.Sealed.Key
+	key, err := issueKey(ctx, projectKey, name)
+	if err != nil {
+		return err
+	}
+	if err := secret.OpenIssue(issue, key); err != nil {
+		return fmt.Errorf("cli: failed to decrypt %s: %w", issue.ID, err)
+	}
+	if err := edit(); err != nil {
+		return err
+	}
+	if err := secret.SealIssue(issue, name, key); err != nil {
+		return fmt.Errorf("cli: failed to encrypt %s: %w", issue.ID, err)
+	}
+	return nil
+}
+
+// setSensitive marks an issue sensitive, encrypting it with the named key, or
+// decrypts it and clears the mark.
+func setSensitive(ctx context.Context, issue *models.Issue, projectKey, keyName string, sensitive bool) error {
+	if !sensitive {
+		if issue.Sealed != nil {
+			key, err := issueKey(ctx, projectKey, issue.Sealed.Key)
+			if err != nil {
+				return err
+			}
+			if err := secret.OpenIssue(issue, key); err != nil {
+				return fmt.Errorf("cli: failed to decrypt %s: %w", issue.ID, err)
+			}
+		}
+		issue.Sensitive = false
+		return nil
+	}
+
+	// Re-encrypt with another key if asked
+	if issue.Sealed != nil {
+		if issue.Sealed.Key == keyName {
+			return nil
+		}
+		if err := setSensitive(ctx, issue, projectKey, "", false); err != nil {
+			return err
+		}
+	}
+	key, err := issueKey(ctx, projectKey, keyName)
+	if err != nil {
+		return err
+	}
+	if err := secret.SealIssue(issue, keyName, key); err != nil {
+		return fmt.Errorf("cli: failed to encrypt %s: %w", issue.ID, err)
+	}
+	return nil
+}
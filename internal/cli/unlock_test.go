@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/secret"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestSensitiveIssue(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	t.Setenv("BUYRUK_SESSION", "test-"+projectKey)
+	t.Setenv("BUYRUK_PASSPHRASE", "")
+	defer func() {
+		secret.ForgetSessionKeys(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(input string, args ...string) (string, string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(errOut)
+		cmd.SetIn(strings.NewReader(input))
+		err := cmd.Execute()
+		return out.String(), errOut.String(), err
+	}
+
+	if _, _, err := run("", "project", "create", projectKey); err != nil {
+		t.Fatalf("project create failed: %v", err)
+	}
+
+	// Creating a sensitive issue needs an unlocked key
+	if _, _, err := run("", "issue", "create", "--project", projectKey, "--title", "Rotate keys", "--description", "hunter2", "--sensitive"); err == nil {
+		t.Fatal("Expected sensitive create to fail while locked")
+	}
+
+	if _, _, err := run("pw\nother\n", "unlock", "--project", projectKey); err == nil {
+		t.Error("Expected mismatched passphrases to fail")
+	}
+	if _, _, err := run("pw\npw\n", "unlock", "--project", projectKey); err != nil {
+		t.Fatalf("unlock failed: %v", err)
+	}
+	if _, _, err := run("nope\n", "unlock", "--project", projectKey); err == nil {
+		t.Error("Expected wrong passphrase to fail")
+	}
+
+	if _, _, err := run("", "issue", "create", "--project", projectKey, "--title", "Rotate keys", "--description", "hunter2", "--sensitive"); err != nil {
+		t.Fatalf("sensitive create failed: %v", err)
+	}
+	if _, _, err := run("", "issue", "comment", projectKey+"-1", "staging done"); err != nil {
+		t.Fatalf("comment failed: %v", err)
+	}
+
+	issuePath, _ := storage.IssuePath(t.Context(), projectKey, projectKey+"-1")
+	data, err := os.ReadFile(issuePath)
+	if err != nil {
+		t.Fatalf("Failed to read issue: %v", err)
+	}
+	if strings.Contains(string(data), "hunter2") || strings.Contains(string(data), "staging done") {
+		t.Error("Expected description and comments to be encrypted at rest")
+	}
+	if !strings.Contains(string(data), "Rotate keys") {
+		t.Error("Expected title to stay in plain text")
+	}
+
+	out, _, err := run("", "issue", "view", projectKey+"-1", "--format", "lson")
+	if err != nil {
+		t.Fatalf("view failed: %v", err)
+	}
+	if !strings.Contains(out, "@DESC: hunter2") || !strings.Contains(out, "staging done") || !strings.Contains(out, "@SENSITIVE: unlocked") {
+		t.Errorf("Expected unlocked view to show the description and comments, got: %s", out)
+	}
+
+	// Titles stay listable, and status changes keep the sealed data
+	if _, _, err := run("", "issue", "update", projectKey+"-1", "--status", "DOING"); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+
+	if _, _, err := run("", "unlock", "--project", projectKey, "--forget"); err != nil {
+		t.Fatalf("unlock --forget failed: %v", err)
+	}
+	out, errOut, err := run("", "issue", "view", projectKey+"-1", "--format", "lson")
+	if err != nil {
+		t.Fatalf("view failed: %v", err)
+	}
+	if strings.Contains(out, "hunter2") || !strings.Contains(out, "@SENSITIVE: locked") || !strings.Contains(errOut, "is sensitive") {
+		t.Errorf("Expected locked view to hide the description, got: %s %s", out, errOut)
+	}
+	out, _, err = run("", "list", "--project", projectKey, "--format", "lson")
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if !strings.Contains(out, "Rotate keys") {
+		t.Errorf("Expected title to be listed, got: %s", out)
+	}
+	if _, _, err := run("", "issue", "comment", projectKey+"-1", "more"); err == nil {
+		t.Error("Expected comment to fail while locked")
+	}
+
+	// Scripts can unlock with $BUYRUK_PASSPHRASE
+	t.Setenv("BUYRUK_PASSPHRASE", "pw")
+	if _, _, err := run("", "issue", "update", projectKey+"-1", "--sensitive=false"); err != nil {
+		t.Fatalf("update --sensitive=false failed: %v", err)
+	}
+	data, _ = os.ReadFile(issuePath)
+	if !strings.Contains(string(data), "hunter2") || strings.Contains(string(data), "sealed") {
+		t.Errorf("Expected issue to be decrypted, got: %s", data)
+	}
+}
@@ -17,10 +17,12 @@ func NewVersionCmd() *cobra.Command {
 			format := GetFormat(cmd)
 			out := cmd.OutOrStdout()
 			switch format {
-			case "json":
+			case "json", "ndjson":
 				fmt.Fprintf(out, `{"version":"%s"}`+"\n", build.Version)
 			case "lson":
 				fmt.Fprintf(out, "@VERSION: %s\n", build.Version)
+			case "yaml":
+				fmt.Fprintf(out, "version: %s\n", build.Version)
 			default: // modern
 				fmt.Fprintf(out, "buyruk version %s\n", build.Version)
 			}
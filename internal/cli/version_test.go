@@ -56,6 +56,25 @@ func TestVersionCmdOutputJSON(t *testing.T) {
 	}
 }
 
+func TestVersionCmdOutputYAML(t *testing.T) {
+	rootCmd := NewRootCmd()
+	rootCmd.SetArgs([]string{"version", "--format", "yaml"})
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatalf("version command failed: %v", err)
+	}
+
+	output := buf.String()
+	expected := "version: " + build.Version + "\n"
+	if output != expected {
+		t.Errorf("Expected output '%s', got '%s'", expected, output)
+	}
+}
+
 func TestVersionCmdOutputLSON(t *testing.T) {
 	rootCmd := NewRootCmd()
 	rootCmd.SetArgs([]string{"version", "--format", "lson"})
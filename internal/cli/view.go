@@ -1,10 +1,12 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/secret"
 	"github.com/buyruk-project/buyruk-cli/internal/storage"
 	"github.com/buyruk-project/buyruk-cli/internal/ui"
 	"github.com/spf13/cobra"
@@ -13,39 +15,82 @@ import (
 // NewViewCmd creates and returns the view command.
 func NewViewCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "view <id>",
-		Short: "View issue details",
-		Long:  "View detailed information about an issue",
-		Args:  cobra.ExactArgs(1),
+		Use:               "view <id>",
+		Short:             "View issue details",
+		Long:              `View detailed information about an issue.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeIssueArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveIssueArgs(cmd, args[:1]); err != nil {
+				return err
+			}
 			issueID := args[0]
 			return viewIssue(issueID, cmd)
 		},
 	}
 
+	addTemplateFlags(cmd, "Render with a Go template")
+	cmd.Flags().Bool("pr-status", false, "Show the state and checks of the issue's PRs (queries GitHub/GitLab)")
+
 	return cmd
 }
 
 // viewIssue views a single issue by ID.
 func viewIssue(issueID string, cmd *cobra.Command) error {
 	// Parse issue ID to get project key
-	projectKey, _, err := models.ParseIssueID(issueID)
+	projectKey, _, err := parseIssueID(cmd.Context(), issueID)
 	if err != nil {
-		return fmt.Errorf("cli: invalid issue ID %q: %w", issueID, err)
+		return invalidf("cli: invalid issue ID %q: %w", issueID, err)
 	}
 
 	// Load issue
-	issuePath, err := storage.IssuePath(projectKey, issueID)
+	issuePath, err := storage.IssuePath(cmd.Context(), projectKey, issueID)
 	if err != nil {
 		return fmt.Errorf("cli: failed to resolve issue path: %w", err)
 	}
 
 	var issue models.Issue
-	if err := storage.ReadJSON(issuePath, &issue); err != nil {
-		if os.IsNotExist(err) {
-			return fmt.Errorf("cli: issue %q not found", issueID)
+	if err := storage.ReadJSON(cmd.Context(), issuePath, &issue); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("cli: failed to load issue: %w", err)
+		}
+		archived, ok := loadArchivedIssue(cmd.Context(), projectKey, issueID)
+		if !ok {
+			// Follow redirects left by project split and issue move
+			if newID, ok := resolveRedirect(cmd.Context(), issueID); ok {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Note: %s moved to %s\n", issueID, newID)
+				return viewIssue(newID, cmd)
+			}
+			if _, _, err := loadTrashedIssue(cmd.Context(), projectKey, issueID); err == nil {
+				return notFoundf("cli: issue %q is in the trash (restore it with `buyruk issue restore %s`)", issueID, issueID)
+			}
+			return notFoundf("cli: issue %q not found", issueID)
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Note: %s is archived\n", issueID)
+		issue = *archived
+	}
+
+	// Decrypt sensitive issues if their key is unlocked in this session
+	if issue.Sealed != nil {
+		if key, ok := secret.SessionKey(cmd.Context(), projectKey, issue.Sealed.Key); ok {
+			if err := secret.OpenIssue(&issue, key); err != nil {
+				return fmt.Errorf("cli: failed to decrypt %s: %w", issueID, err)
+			}
+		} else {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Note: %s is sensitive; its description and comments are encrypted (run `buyruk unlock` to view them)\n", issueID)
 		}
-		return fmt.Errorf("cli: failed to load issue: %w", err)
+	}
+
+	// Render through a custom template if requested
+	text, ok, err := resolveTemplate(cmd)
+	if err != nil {
+		return err
+	}
+	if ok {
+		if err := renderIssuesWithTemplate(cmd.Context(), text, []*models.Issue{&issue}, cmd.OutOrStdout()); err != nil {
+			return fmt.Errorf("cli: failed to render issue: %w", err)
+		}
+		return nil
 	}
 
 	// Render using UI layer
@@ -59,5 +104,23 @@ func viewIssue(issueID string, cmd *cobra.Command) error {
 		return fmt.Errorf("cli: failed to render issue: %w", err)
 	}
 
+	// Show subtasks with completion progress
+	children, err := loadChildren(cmd.Context(), projectKey, issueID)
+	if err != nil {
+		return err
+	}
+	if len(children) > 0 {
+		wf, err := loadWorkflow(cmd.Context(), projectKey)
+		if err != nil {
+			return err
+		}
+		renderSubtasks(children, wf, cmd, out)
+	}
+
+	// Show PR states when asked to, as they need network requests
+	if prStatus, _ := cmd.Flags().GetBool("pr-status"); prStatus {
+		renderPRStatuses(fetchPRStatuses(cmd.Context(), issue.PRs, false), cmd, out)
+	}
+
 	return nil
 }
@@ -25,7 +25,7 @@ func TestViewIssue_Success(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -50,12 +50,12 @@ func TestViewIssue_Success(t *testing.T) {
 		UpdatedAt:   "2024-01-01T00:00:00Z",
 	}
 
-	issuePath, err := storage.IssuePath(projectKey, issueID)
+	issuePath, err := storage.IssuePath(t.Context(), projectKey, issueID)
 	if err != nil {
 		t.Fatalf("Failed to resolve issue path: %v", err)
 	}
 
-	if err := storage.WriteJSONAtomic(issuePath, issue); err != nil {
+	if err := storage.WriteJSONAtomic(t.Context(), issuePath, issue); err != nil {
 		t.Fatalf("Failed to write issue: %v", err)
 	}
 
@@ -87,7 +87,7 @@ func TestViewIssue_NotFound(t *testing.T) {
 	projectKey := sanitizeTestName("TEST" + t.Name())
 	// Clean up after test
 	defer func() {
-		projectDir, _ := storage.ProjectDir(projectKey)
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
 		os.RemoveAll(projectDir)
 	}()
 
@@ -133,3 +133,45 @@ func TestViewIssue_InvalidID(t *testing.T) {
 		t.Errorf("Expected error about invalid ID, got: %v", err)
 	}
 }
+
+func TestViewIssue_Template(t *testing.T) {
+	// Use unique project key to avoid conflicts
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	// Clean up after test
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	// Create project and issue
+	rootCmd := NewRootCmd()
+	rootCmd.SetArgs([]string{"project", "create", projectKey})
+	rootCmd.SetOut(new(bytes.Buffer))
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	rootCmd2 := NewRootCmd()
+	rootCmd2.SetArgs([]string{"issue", "create", "--project", projectKey, "--title", "Template Issue"})
+	rootCmd2.SetOut(new(bytes.Buffer))
+	if err := rootCmd2.Execute(); err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+
+	// View through the issue subcommand with an inline template
+	issueID := projectKey + "-1"
+	rootCmd3 := NewRootCmd()
+	rootCmd3.SetArgs([]string{"issue", "view", issueID, "--template", "{{.ID}}: {{.Title}} [{{.Status}}]"})
+
+	buf := new(bytes.Buffer)
+	rootCmd3.SetOut(buf)
+
+	if err := rootCmd3.Execute(); err != nil {
+		t.Fatalf("issue view command failed: %v", err)
+	}
+
+	want := issueID + ": Template Issue [TODO]\n"
+	if buf.String() != want {
+		t.Errorf("Expected output %q, got %q", want, buf.String())
+	}
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// defaultWatchInterval is how often a watched project directory is checked
+// for changes.
+const defaultWatchInterval = time.Second
+
+// clearScreen moves the cursor home and clears a terminal.
+const clearScreen = "\x1b[H\x1b[2J"
+
+// watchProject renders the output of render, then re-renders it whenever a file
+// in the project directory changes, until interrupted. On a terminal the
+// screen is cleared before each render; otherwise renders follow each other.
+//
+// The directory is polled rather than watched with inotify/FSEvents, so this
+// works the same on every platform and network filesystem.
+func watchProject(cmd *cobra.Command, projectKey string, render func(w io.Writer) error) error {
+	projectDir, err := storage.ProjectDir(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve project directory: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	out := cmd.OutOrStdout()
+	tty := false
+	if f, ok := out.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		tty = true
+	}
+
+	// Render into a buffer first so the screen is not left blank while loading
+	show := func() error {
+		var buf bytes.Buffer
+		if err := render(&buf); err != nil {
+			return err
+		}
+		if tty {
+			fmt.Fprint(out, clearScreen)
+		}
+		out.Write(buf.Bytes())
+		fmt.Fprintf(out, "\nWatching project %q (updated %s, Ctrl+C to stop)\n",
+			projectKey, storage.Now(cmd.Context()).Local().Format("15:04:05"))
+		return nil
+	}
+
+	last, err := projectSignature(projectDir)
+	if err != nil {
+		return err
+	}
+	if err := show(); err != nil {
+		return err
+	}
+
+	interval := hostEnvFrom(ctx).watchInterval
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		sig, err := projectSignature(projectDir)
+		if err != nil {
+			return err
+		}
+		if sig == last {
+			continue
+		}
+		last = sig
+		// A render can race a write in progress; keep watching and retry on the next change
+		if err := show(); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %v\n", err)
+		}
+	}
+}
+
+// projectSignature hashes the names, sizes, and modification times of the
+// files in a project directory. Lock files, transaction logs, and temporary
+// files are skipped so that reads and in-flight writes don't trigger renders.
+func projectSignature(projectDir string) (uint64, error) {
+	h := fnv.New64a()
+	err := filepath.WalkDir(projectDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Files can disappear between listing and stat (atomic renames, deletes)
+			if errors.Is(err, fs.ErrNotExist) && path != projectDir {
+				return nil
+			}
+			return err
+		}
+		name := d.Name()
+		if path != projectDir && (strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".tmp")) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00%d\n", path, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, fmt.Errorf("cli: project directory %q not found", projectDir)
+		}
+		return 0, fmt.Errorf("cli: failed to scan project directory: %w", err)
+	}
+	return h.Sum64(), nil
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+// syncBuffer is a bytes.Buffer safe for a watching command and the test to share.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestListWatch(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) error {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+		return cmd.Execute()
+	}
+	if err := run("project", "create", projectKey); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if err := run("issue", "create", "--project", projectKey, "--title", "First issue"); err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(withHostEnv(context.Background(), hostEnv{watchInterval: 10 * time.Millisecond}))
+	defer cancel()
+	out := new(syncBuffer)
+	done := make(chan error, 1)
+	go func() {
+		cmd := NewRootCmd()
+		cmd.SetArgs([]string{"list", "--project", projectKey, "--format", "lson", "--watch"})
+		cmd.SetOut(out)
+		cmd.SetErr(new(syncBuffer))
+		done <- cmd.ExecuteContext(ctx)
+	}()
+
+	waitFor := func(text string, count int) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for strings.Count(out.String(), text) < count {
+			if time.Now().After(deadline) {
+				t.Fatalf("Timed out waiting for %d x %q, got: %s", count, text, out.String())
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	waitFor("First issue", 1)
+	if !strings.Contains(out.String(), "Watching project") {
+		t.Errorf("Expected watch footer, got: %s", out.String())
+	}
+
+	// A change to the project re-renders the list
+	if err := run("issue", "create", "--project", projectKey, "--title", "Second issue"); err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+	waitFor("Second issue", 1)
+	waitFor("First issue", 2)
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected watch to stop cleanly, got: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for watch to stop")
+	}
+}
+
+func TestProjectSignature(t *testing.T) {
+	dir := t.TempDir()
+	before, err := projectSignature(dir)
+	if err != nil {
+		t.Fatalf("projectSignature() error = %v", err)
+	}
+
+	// Lock files and temporary files don't count as changes
+	os.WriteFile(filepath.Join(dir, ".buyruk.lock"), []byte("1"), 0644)
+	os.WriteFile(filepath.Join(dir, "project.json.tmp"), []byte("{}"), 0644)
+	if sig, _ := projectSignature(dir); sig != before {
+		t.Error("Expected lock and temporary files to be ignored")
+	}
+
+	os.WriteFile(filepath.Join(dir, "project.json"), []byte("{}"), 0644)
+	if sig, _ := projectSignature(dir); sig == before {
+		t.Error("Expected a new file to change the signature")
+	}
+
+	if _, err := projectSignature(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected an error for a missing project directory")
+	}
+}
@@ -0,0 +1,433 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// NewProjectWorkflowCmd creates and returns the project workflow command.
+func NewProjectWorkflowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "workflow <key>",
+		Short: "View or change a project's workflow",
+		Long: `View or change the statuses, types, and priorities allowed in a project.
+
+Without flags the current workflow is shown. Lists are comma-separated, e.g.:
+  buyruk project workflow CORE --statuses TODO,DOING,REVIEW,DONE --priorities P3,P2,P1
+Priorities are ordered from lowest to highest. The first status is used for new issues.
+
+Transition rules restrict which statuses an issue may move to next, and
+required fields must be set before an issue enters a status:
+  buyruk project workflow CORE --transition TODO:DOING --transition DOING:REVIEW,TODO
+  buyruk project workflow CORE --require DONE:resolution
+An empty list removes the rule (e.g. --transition TODO:).
+
+Validation rules are checked whenever an issue is created or updated. A rule
+requires a field, or a minimum length of the title or description, for all
+issues or for one type (issue create/update --skip-rules overrides them):
+  buyruk project workflow CORE --rule priority:required
+  buyruk project workflow CORE --rule description:min=20
+  buyruk project workflow CORE --rule epic:required,type=task
+Rules replace earlier rules of the same field and type, and an empty list
+removes all rules of a field (e.g. --rule epic:).
+
+WIP limits cap how many issues may be in a status at once. Moving an issue
+over a limit with issue update warns, or with --wip-mode refuse fails unless
+--force is given; buyruk stats marks statuses over their limit:
+  buyruk project workflow CORE --wip-limit DOING:3 --wip-mode refuse
+A limit of 0 removes it (e.g. --wip-limit DOING:0).`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeProjectArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectKey := args[0]
+			return projectWorkflow(projectKey, cmd)
+		},
+	}
+
+	cmd.Flags().String("statuses", "", "Comma-separated list of statuses")
+	cmd.Flags().String("types", "", "Comma-separated list of issue types")
+	cmd.Flags().String("priorities", "", "Comma-separated list of priorities (lowest first)")
+	cmd.Flags().String("done-statuses", "", "Comma-separated list of statuses that count as completed")
+	cmd.Flags().StringArray("transition", nil, "Allowed next statuses as FROM:TO1,TO2 (repeatable)")
+	cmd.Flags().StringArray("require", nil, "Required fields to enter a status as STATUS:field1,field2 (repeatable)")
+	cmd.Flags().StringArray("rule", nil, "Validation rule as FIELD:required,min=N,type=TYPE (repeatable)")
+	cmd.Flags().StringArray("wip-limit", nil, "Most issues allowed in a status at once as STATUS:N (repeatable, 0 removes it)")
+	cmd.Flags().String("wip-mode", "", "What moving an issue over a WIP limit does: warn or refuse")
+	cmd.Flags().Bool("reset", false, "Reset the workflow to the built-in defaults")
+
+	return cmd
+}
+
+// projectWorkflow shows or updates the workflow of a project.
+func projectWorkflow(projectKey string, cmd *cobra.Command) error {
+	projectDir, err := storage.ProjectDir(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve project directory: %w", err)
+	}
+
+	if _, err := os.Stat(projectDir); os.IsNotExist(err) {
+		return notFoundf("cli: project %q does not exist", projectKey)
+	}
+
+	flagNames := []string{"statuses", "types", "priorities", "done-statuses", "transition", "require", "rule", "wip-limit", "wip-mode", "reset"}
+	changed := false
+	for _, name := range flagNames {
+		if cmd.Flags().Changed(name) {
+			changed = true
+		}
+	}
+
+	if !changed {
+		wf, err := loadWorkflow(cmd.Context(), projectKey)
+		if err != nil {
+			return err
+		}
+		return renderWorkflow(wf, cmd, cmd.OutOrStdout())
+	}
+
+	workflowPath, err := storage.WorkflowPath(cmd.Context(), projectKey)
+	if err != nil {
+		return fmt.Errorf("cli: failed to resolve workflow path: %w", err)
+	}
+
+	var wf models.Workflow
+	if err := storage.UpdateJSONAtomic(cmd.Context(), workflowPath, &wf, func(v interface{}) error {
+		w := v.(*models.Workflow)
+
+		if reset, _ := cmd.Flags().GetBool("reset"); reset {
+			*w = *models.DefaultWorkflow()
+		}
+		if cmd.Flags().Changed("statuses") {
+			value, _ := cmd.Flags().GetString("statuses")
+			w.Statuses = splitList(value)
+		}
+		if cmd.Flags().Changed("types") {
+			value, _ := cmd.Flags().GetString("types")
+			w.Types = splitList(value)
+		}
+		if cmd.Flags().Changed("priorities") {
+			value, _ := cmd.Flags().GetString("priorities")
+			w.Priorities = splitList(value)
+		}
+		if cmd.Flags().Changed("done-statuses") {
+			value, _ := cmd.Flags().GetString("done-statuses")
+			w.DoneStatuses = splitList(value)
+		}
+		transitions, _ := cmd.Flags().GetStringArray("transition")
+		for _, rule := range transitions {
+			from, next, err := parseWorkflowRule(rule)
+			if err != nil {
+				return err
+			}
+			w.Transitions = setWorkflowRule(w.Transitions, from, next)
+		}
+		requires, _ := cmd.Flags().GetStringArray("require")
+		for _, rule := range requires {
+			status, fields, err := parseWorkflowRule(rule)
+			if err != nil {
+				return err
+			}
+			w.RequiredFields = setWorkflowRule(w.RequiredFields, status, fields)
+		}
+		rules, _ := cmd.Flags().GetStringArray("rule")
+		for _, value := range rules {
+			field, checks, err := parseWorkflowRule(value)
+			if err != nil {
+				return err
+			}
+			if len(checks) == 0 {
+				w.Rules = slices.DeleteFunc(w.Rules, func(r models.Rule) bool { return r.Field == field })
+				continue
+			}
+			rule, err := parseValidationRule(field, checks)
+			if err != nil {
+				return err
+			}
+			w.Rules = setValidationRule(w.Rules, rule)
+		}
+		limits, _ := cmd.Flags().GetStringArray("wip-limit")
+		for _, value := range limits {
+			status, limit, err := parseWIPLimit(value)
+			if err != nil {
+				return err
+			}
+			if limit == 0 {
+				delete(w.WIPLimits, status)
+				continue
+			}
+			if w.WIPLimits == nil {
+				w.WIPLimits = map[string]int{}
+			}
+			w.WIPLimits[status] = limit
+		}
+		if len(w.WIPLimits) == 0 {
+			w.WIPLimits = nil
+		}
+		if cmd.Flags().Changed("wip-mode") {
+			w.WIPMode, _ = cmd.Flags().GetString("wip-mode")
+		}
+
+		if err := w.Validate(); err != nil {
+			return invalidf("cli: invalid workflow: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("cli: failed to update workflow: %w", err)
+	}
+
+	out := successOut(cmd)
+	fmt.Fprintf(out, "Updated workflow for project %q\n", projectKey)
+
+	return nil
+}
+
+// loadWorkflow loads the workflow of a project.
+// Projects without a workflow file use the default workflow.
+func loadWorkflow(ctx context.Context, projectKey string) (*models.Workflow, error) {
+	workflowPath, err := storage.WorkflowPath(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("cli: failed to resolve workflow path: %w", err)
+	}
+
+	var wf models.Workflow
+	if err := storage.ReadJSON(ctx, workflowPath, &wf); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return models.DefaultWorkflow(), nil
+		}
+		return nil, fmt.Errorf("cli: failed to load workflow: %w", err)
+	}
+
+	return &wf, nil
+}
+
+// checkWIPLimit checks moving an issue into a status against the status's WIP
+// limit. Going over the limit returns a warning, or an error if the workflow
+// refuses it and force is not set.
+func checkWIPLimit(ctx context.Context, wf *models.Workflow, projectKey, issueID, status string, force bool) (string, error) {
+	limit := wf.WIPLimit(status)
+	if limit == 0 {
+		return "", nil
+	}
+	indexPath, err := storage.ProjectIndexPath(ctx, projectKey)
+	if err != nil {
+		return "", fmt.Errorf("cli: failed to resolve index path: %w", err)
+	}
+	var index models.ProjectIndex
+	if err := storage.ReadIndex(ctx, indexPath, &index); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("cli: failed to load project index: %w", err)
+	}
+	count := 1
+	for _, entry := range index.Issues {
+		if entry.Status == status && entry.ID != issueID {
+			count++
+		}
+	}
+	if count <= limit {
+		return "", nil
+	}
+	if wf.RefusesOverWIP() && !force {
+		return "", conflictf("cli: moving %s to %s would make %d issues in %s, over its WIP limit of %d (use --force to override)",
+			issueID, status, count, status, limit)
+	}
+	return fmt.Sprintf("%s now has %d issues, over its WIP limit of %d", status, count, limit), nil
+}
+
+// renderWorkflow renders the effective workflow in the resolved output format.
+func renderWorkflow(wf *models.Workflow, cmd *cobra.Command, w io.Writer) error {
+	effective := &models.Workflow{
+		Statuses:       wf.StatusList(),
+		Types:          wf.TypeList(),
+		Priorities:     wf.PriorityList(),
+		DoneStatuses:   wf.DoneStatusList(),
+		Transitions:    wf.Transitions,
+		RequiredFields: wf.RequiredFields,
+		Rules:          wf.Rules,
+		WIPLimits:      wf.WIPLimits,
+		WIPMode:        wf.WIPMode,
+	}
+
+	switch config.ResolveFormat(cmd) {
+	case config.DefaultFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(effective)
+	case config.DefaultFormatYAML:
+		return ui.EncodeYAML(w, effective)
+	case config.DefaultFormatLSON:
+		fmt.Fprintf(w, "@STATUSES: %s\n", strings.Join(effective.Statuses, ","))
+		fmt.Fprintf(w, "@TYPES: %s\n", strings.Join(effective.Types, ","))
+		fmt.Fprintf(w, "@PRIORITIES: %s\n", strings.Join(effective.Priorities, ","))
+		fmt.Fprintf(w, "@DONE: %s\n", strings.Join(effective.DoneStatuses, ","))
+		for _, from := range sortedRuleKeys(effective.Transitions) {
+			fmt.Fprintf(w, "@TRANSITION: %s>%s\n", from, strings.Join(effective.Transitions[from], ","))
+		}
+		for _, status := range sortedRuleKeys(effective.RequiredFields) {
+			fmt.Fprintf(w, "@REQUIRE: %s:%s\n", status, strings.Join(effective.RequiredFields[status], ","))
+		}
+		for _, rule := range effective.Rules {
+			fmt.Fprintf(w, "@RULE: %s\n", rule)
+		}
+		for _, status := range sortedWIPStatuses(effective) {
+			fmt.Fprintf(w, "@WIP_LIMIT: %s:%d\n", status, effective.WIPLimits[status])
+		}
+		if len(effective.WIPLimits) > 0 {
+			fmt.Fprintf(w, "@WIP_MODE: %s\n", wipMode(effective))
+		}
+	default:
+		styles := ui.NewStyles()
+		fmt.Fprintf(w, "%s: %s\n", styles.Label("Statuses"), strings.Join(effective.Statuses, " → "))
+		fmt.Fprintf(w, "%s: %s\n", styles.Label("Types"), strings.Join(effective.Types, ", "))
+		fmt.Fprintf(w, "%s: %s\n", styles.Label("Priorities"), strings.Join(effective.Priorities, " < "))
+		fmt.Fprintf(w, "%s: %s\n", styles.Label("Done"), strings.Join(effective.DoneStatuses, ", "))
+		for _, from := range sortedRuleKeys(effective.Transitions) {
+			fmt.Fprintf(w, "%s: %s → %s\n", styles.Label("Transition"), from, formatStatusList(effective.Transitions[from]))
+		}
+		for _, status := range sortedRuleKeys(effective.RequiredFields) {
+			fmt.Fprintf(w, "%s: %s needs %s\n", styles.Label("Required"), status, strings.Join(effective.RequiredFields[status], ", "))
+		}
+		for _, rule := range effective.Rules {
+			fmt.Fprintf(w, "%s: %s\n", styles.Label("Rule"), rule)
+		}
+		for _, status := range sortedWIPStatuses(effective) {
+			fmt.Fprintf(w, "%s: %s ≤ %d (%s)\n", styles.Label("WIP limit"), status, effective.WIPLimits[status], wipMode(effective))
+		}
+	}
+	return nil
+}
+
+// splitList splits a comma-separated flag value into trimmed, non-empty items.
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseWorkflowRule parses a "KEY:item1,item2" rule flag value.
+func parseWorkflowRule(rule string) (string, []string, error) {
+	key, items, ok := strings.Cut(rule, ":")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return "", nil, invalidf("cli: invalid rule %q (expected KEY:item1,item2)", rule)
+	}
+	return key, splitList(items), nil
+}
+
+// parseWIPLimit parses a "STATUS:N" WIP limit flag value.
+func parseWIPLimit(value string) (string, int, error) {
+	status, limit, ok := strings.Cut(value, ":")
+	status = strings.TrimSpace(status)
+	n, err := strconv.Atoi(strings.TrimSpace(limit))
+	if !ok || status == "" || err != nil || n < 0 {
+		return "", 0, invalidf("cli: invalid WIP limit %q (expected STATUS:N, e.g. DOING:3)", value)
+	}
+	return status, n, nil
+}
+
+// sortedWIPStatuses returns the statuses with a WIP limit in workflow order.
+func sortedWIPStatuses(wf *models.Workflow) []string {
+	var statuses []string
+	for _, status := range wf.StatusList() {
+		if wf.WIPLimit(status) > 0 {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
+// wipMode returns the effective WIP mode of a workflow.
+func wipMode(wf *models.Workflow) string {
+	if wf.RefusesOverWIP() {
+		return models.WIPRefuse
+	}
+	return models.WIPWarn
+}
+
+// setWorkflowRule sets or, for an empty list, removes a rule.
+func setWorkflowRule(rules map[string][]string, key string, items []string) map[string][]string {
+	if len(items) == 0 {
+		delete(rules, key)
+		if len(rules) == 0 {
+			return nil
+		}
+		return rules
+	}
+	if rules == nil {
+		rules = map[string][]string{}
+	}
+	rules[key] = items
+	return rules
+}
+
+// parseValidationRule parses the checks of a --rule flag value, such as
+// "required", "min=20", and "type=task".
+func parseValidationRule(field string, checks []string) (models.Rule, error) {
+	rule := models.Rule{Field: field}
+	for _, check := range checks {
+		name, value, _ := strings.Cut(check, "=")
+		switch name {
+		case "required":
+			rule.Required = true
+		case "min":
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return rule, invalidf("cli: invalid rule check %q (min needs a positive number, e.g. min=20)", check)
+			}
+			rule.MinLength = n
+		case "type":
+			if value == "" {
+				return rule, invalidf("cli: invalid rule check %q (e.g. type=task)", check)
+			}
+			rule.Type = value
+		default:
+			return rule, invalidf("cli: invalid rule check %q (expected required, min=N, or type=TYPE)", check)
+		}
+	}
+	return rule, nil
+}
+
+// setValidationRule adds a rule, replacing a rule of the same field and type.
+func setValidationRule(rules []models.Rule, rule models.Rule) []models.Rule {
+	for i, r := range rules {
+		if r.Field == rule.Field && r.Type == rule.Type {
+			rules[i] = rule
+			return rules
+		}
+	}
+	return append(rules, rule)
+}
+
+// sortedRuleKeys returns the keys of a rule map in sorted order.
+func sortedRuleKeys(rules map[string][]string) []string {
+	keys := make([]string, 0, len(rules))
+	for key := range rules {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatStatusList formats a list of statuses for messages, using "none" for an empty list.
+func formatStatusList(statuses []string) string {
+	if len(statuses) == 0 {
+		return "none"
+	}
+	return strings.Join(statuses, ", ")
+}
@@ -0,0 +1,373 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+func TestNewProjectWorkflowCmd(t *testing.T) {
+	cmd := NewProjectWorkflowCmd()
+	if cmd == nil {
+		t.Fatal("NewProjectWorkflowCmd() returned nil")
+	}
+	if !strings.HasPrefix(cmd.Use, "workflow") {
+		t.Errorf("Expected Use to start with 'workflow', got '%s'", cmd.Use)
+	}
+}
+
+func TestProjectWorkflow_ShowDefault(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	rootCmd := NewRootCmd()
+	rootCmd.SetArgs([]string{"project", "create", projectKey})
+	rootCmd.SetOut(new(bytes.Buffer))
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	rootCmd2 := NewRootCmd()
+	rootCmd2.SetArgs([]string{"project", "workflow", projectKey, "--format", "lson"})
+	buf := new(bytes.Buffer)
+	rootCmd2.SetOut(buf)
+	if err := rootCmd2.Execute(); err != nil {
+		t.Fatalf("project workflow command failed: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "@STATUSES: TODO,DOING,DONE") {
+		t.Errorf("Expected default statuses, got: %s", buf.String())
+	}
+}
+
+func TestProjectWorkflow_CustomStatuses(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	rootCmd := NewRootCmd()
+	rootCmd.SetArgs([]string{"project", "create", projectKey})
+	rootCmd.SetOut(new(bytes.Buffer))
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	// Define a custom workflow
+	rootCmd2 := NewRootCmd()
+	rootCmd2.SetArgs([]string{"project", "workflow", projectKey, "--statuses", "TODO,DOING,REVIEW,DONE", "--types", "task,bug,story"})
+	rootCmd2.SetOut(new(bytes.Buffer))
+	if err := rootCmd2.Execute(); err != nil {
+		t.Fatalf("project workflow command failed: %v", err)
+	}
+
+	// Custom status and type are accepted on create
+	rootCmd3 := NewRootCmd()
+	rootCmd3.SetArgs([]string{"issue", "create", "--project", projectKey, "--title", "Story", "--type", "story", "--status", "REVIEW"})
+	rootCmd3.SetOut(new(bytes.Buffer))
+	if err := rootCmd3.Execute(); err != nil {
+		t.Fatalf("issue create with custom status failed: %v", err)
+	}
+
+	issuePath, _ := storage.IssuePath(t.Context(), projectKey, projectKey+"-1")
+	var issue models.Issue
+	if err := storage.ReadJSON(t.Context(), issuePath, &issue); err != nil {
+		t.Fatalf("Failed to read issue: %v", err)
+	}
+	if issue.Status != "REVIEW" || issue.Type != "story" {
+		t.Errorf("Issue status/type = %q/%q, want REVIEW/story", issue.Status, issue.Type)
+	}
+
+	// Unknown status is rejected on update with the allowed list
+	rootCmd4 := NewRootCmd()
+	rootCmd4.SetArgs([]string{"issue", "update", projectKey + "-1", "--status", "BLOCKED"})
+	rootCmd4.SetOut(new(bytes.Buffer))
+	rootCmd4.SetErr(new(bytes.Buffer))
+	err := rootCmd4.Execute()
+	if err == nil {
+		t.Fatal("issue update should reject status outside the workflow")
+	}
+	if !strings.Contains(err.Error(), "REVIEW") {
+		t.Errorf("Expected error to list allowed statuses, got: %v", err)
+	}
+
+	// Repair keeps issues with custom statuses
+	rootCmd5 := NewRootCmd()
+	rootCmd5.SetArgs([]string{"project", "repair", projectKey})
+	buf := new(bytes.Buffer)
+	rootCmd5.SetOut(buf)
+	rootCmd5.SetErr(new(bytes.Buffer))
+	if err := rootCmd5.Execute(); err != nil {
+		t.Fatalf("project repair failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "1 issues indexed") {
+		t.Errorf("Expected repaired index to contain custom-status issue, got: %s", buf.String())
+	}
+}
+
+func TestProjectWorkflow_Invalid(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	rootCmd := NewRootCmd()
+	rootCmd.SetArgs([]string{"project", "create", projectKey})
+	rootCmd.SetOut(new(bytes.Buffer))
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	rootCmd2 := NewRootCmd()
+	rootCmd2.SetArgs([]string{"project", "workflow", projectKey, "--statuses", "TODO,TODO"})
+	rootCmd2.SetOut(new(bytes.Buffer))
+	rootCmd2.SetErr(new(bytes.Buffer))
+	if err := rootCmd2.Execute(); err == nil {
+		t.Fatal("project workflow should reject duplicate statuses")
+	}
+}
+
+func TestProjectWorkflow_ProjectNotFound(t *testing.T) {
+	rootCmd := NewRootCmd()
+	rootCmd.SetArgs([]string{"project", "workflow", "NONEXISTENT-WF"})
+	rootCmd.SetErr(new(bytes.Buffer))
+	if err := rootCmd.Execute(); err == nil {
+		t.Fatal("project workflow should fail for missing project")
+	}
+}
+
+func TestUpdateIssue_TransitionRules(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	rootCmd := NewRootCmd()
+	rootCmd.SetArgs([]string{"project", "create", projectKey})
+	rootCmd.SetOut(new(bytes.Buffer))
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	rootCmd2 := NewRootCmd()
+	rootCmd2.SetArgs([]string{"project", "workflow", projectKey,
+		"--transition", "TODO:DOING", "--transition", "DOING:DONE,TODO", "--require", "DONE:resolution"})
+	rootCmd2.SetOut(new(bytes.Buffer))
+	if err := rootCmd2.Execute(); err != nil {
+		t.Fatalf("project workflow command failed: %v", err)
+	}
+
+	issueID := projectKey + "-1"
+	rootCmd3 := NewRootCmd()
+	rootCmd3.SetArgs([]string{"issue", "create", "--project", projectKey, "--title", "Rules"})
+	rootCmd3.SetOut(new(bytes.Buffer))
+	if err := rootCmd3.Execute(); err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+
+	update := func(args ...string) error {
+		cmd := NewRootCmd()
+		cmd.SetArgs(append([]string{"issue", "update", issueID}, args...))
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+		return cmd.Execute()
+	}
+
+	// TODO -> DONE is not allowed, and the error lists the legal next states
+	err := update("--status", "DONE")
+	if err == nil {
+		t.Fatal("TODO -> DONE should be rejected")
+	}
+	if !strings.Contains(err.Error(), "allowed next: DOING") {
+		t.Errorf("Error = %q, want it to list allowed next statuses", err)
+	}
+
+	if err := update("--status", "DOING"); err != nil {
+		t.Fatalf("TODO -> DOING failed: %v", err)
+	}
+
+	// DONE requires a resolution
+	err = update("--status", "DONE")
+	if err == nil {
+		t.Fatal("DOING -> DONE without resolution should be rejected")
+	}
+	if !strings.Contains(err.Error(), "requires: resolution") {
+		t.Errorf("Error = %q, want it to mention the missing field", err)
+	}
+
+	if err := update("--status", "DONE", "--resolution", "fixed"); err != nil {
+		t.Fatalf("DOING -> DONE with resolution failed: %v", err)
+	}
+
+	// --force bypasses the rules
+	if err := update("--status", "TODO"); err != nil {
+		t.Fatalf("DONE -> TODO (unrestricted) failed: %v", err)
+	}
+	if err := update("--status", "DONE", "--force"); err != nil {
+		t.Fatalf("forced TODO -> DONE failed: %v", err)
+	}
+
+	issuePath, _ := storage.IssuePath(t.Context(), projectKey, issueID)
+	var issue models.Issue
+	if err := storage.ReadJSON(t.Context(), issuePath, &issue); err != nil {
+		t.Fatalf("Failed to read issue: %v", err)
+	}
+	if issue.Status != models.StatusDONE || issue.Resolution != "fixed" {
+		t.Errorf("Issue = %s/%q, want DONE/fixed", issue.Status, issue.Resolution)
+	}
+}
+
+func TestIssue_ValidationRules(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		cmd.SetOut(new(bytes.Buffer))
+		errOut := new(bytes.Buffer)
+		cmd.SetErr(errOut)
+		err := cmd.Execute()
+		return errOut.String(), err
+	}
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"issue", "create", "--project", projectKey, "--title", "Before the rules"},
+		{"epic", "create", "--project", projectKey, "--id", projectKey + "-E1", "--title", "Epic"},
+		{"project", "workflow", projectKey, "--rule", "priority:required", "--rule", "description:min=10", "--rule", "epic:required,type=task"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	_, err := run("issue", "create", "--project", projectKey, "--title", "Crash", "--type", "bug", "--description", "Short")
+	if err == nil {
+		t.Fatal("Expected an issue that breaks the rules to be refused")
+	}
+	for _, want := range []string{"priority is required (set it with --priority)", "description must be at least 10 characters (has 5)", "--skip-rules"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q in the error, got %q", want, err)
+		}
+	}
+	if _, err := run("issue", "create", "--project", projectKey, "--title", "Crash", "--priority", "HIGH", "--description", "Crashes on start"); err == nil || !strings.Contains(err.Error(), "epic is required for task issues") {
+		t.Errorf("Expected tasks to require an epic, got %v", err)
+	}
+	for _, args := range [][]string{
+		{"issue", "create", "--project", projectKey, "--title", "Crash", "--type", "bug", "--priority", "HIGH", "--description", "Crashes on start"},
+		{"issue", "create", "--project", projectKey, "--title", "Spike", "--skip-rules"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	// Updates can't break more rules, but issues that broke them before
+	// are only warned about
+	if _, err := run("issue", "update", projectKey+"-2", "--description", "Crash"); err == nil {
+		t.Error("Expected an update that breaks a rule to be refused")
+	}
+	errOut, err := run("issue", "update", projectKey+"-1", "--status", "DOING")
+	if err != nil {
+		t.Fatalf("Updating an issue created before the rules failed: %v", err)
+	}
+	if !strings.Contains(errOut, "still breaks project rules") {
+		t.Errorf("Expected a warning about the rules the issue breaks, got %q", errOut)
+	}
+
+	if _, err := run("project", "workflow", projectKey, "--rule", "labels:min=2"); err == nil {
+		t.Error("Expected a minimum length for labels to be refused")
+	}
+	if _, err := run("project", "workflow", projectKey, "--rule", "epic:"); err != nil {
+		t.Fatalf("Removing the epic rule failed: %v", err)
+	}
+	wf, err := loadWorkflow(t.Context(), projectKey)
+	if err != nil {
+		t.Fatalf("Failed to load workflow: %v", err)
+	}
+	if len(wf.Rules) != 2 {
+		t.Errorf("Expected 2 rules left, got %+v", wf.Rules)
+	}
+}
+
+func TestUpdateIssue_WIPLimits(t *testing.T) {
+	projectKey := sanitizeTestName("TEST" + t.Name())
+	defer func() {
+		projectDir, _ := storage.ProjectDir(t.Context(), projectKey)
+		os.RemoveAll(projectDir)
+	}()
+
+	run := func(args ...string) (string, string, error) {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(errOut)
+		err := cmd.Execute()
+		return out.String(), errOut.String(), err
+	}
+	for _, args := range [][]string{
+		{"project", "create", projectKey},
+		{"project", "workflow", projectKey, "--wip-limit", "DOING:1"},
+		{"issue", "create", "--project", projectKey, "--title", "First"},
+		{"issue", "create", "--project", projectKey, "--title", "Second"},
+		{"issue", "create", "--project", projectKey, "--title", "Third"},
+	} {
+		if _, _, err := run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+	if _, _, err := run("project", "workflow", projectKey, "--wip-limit", "REVIEW:2"); err == nil {
+		t.Error("Expected a WIP limit for an unknown status to fail")
+	}
+
+	if _, errOut, err := run("issue", "update", projectKey+"-1", "--status", "DOING"); err != nil || errOut != "" {
+		t.Fatalf("Moving within the limit: err %v, stderr %q", err, errOut)
+	}
+
+	// Over the limit warns by default
+	_, errOut, err := run("issue", "update", projectKey+"-2", "--status", "DOING")
+	if err != nil {
+		t.Fatalf("Moving over the limit in warn mode failed: %v", err)
+	}
+	if !strings.Contains(errOut, "DOING now has 2 issues, over its WIP limit of 1") {
+		t.Errorf("Expected a WIP warning, got %q", errOut)
+	}
+
+	// ...and is refused in refuse mode unless forced
+	if _, _, err := run("project", "workflow", projectKey, "--wip-mode", "refuse"); err != nil {
+		t.Fatalf("Setting the WIP mode failed: %v", err)
+	}
+	_, _, err = run("issue", "update", projectKey+"-3", "--status", "DOING")
+	if err == nil || !strings.Contains(err.Error(), "over its WIP limit of 1") {
+		t.Errorf("Expected moving over the limit to be refused, got %v", err)
+	}
+	if _, _, err := run("issue", "update", projectKey+"-3", "--status", "DOING", "--force"); err != nil {
+		t.Errorf("Forced move over the limit failed: %v", err)
+	}
+
+	out, _, err := run("stats", projectKey, "--format", "lson")
+	if err != nil {
+		t.Fatalf("stats failed: %v", err)
+	}
+	if !strings.Contains(out, "@WIP: DOING|3|1|true") {
+		t.Errorf("Expected over-limit status in stats, got: %s", out)
+	}
+	out, _, _ = run("project", "workflow", projectKey, "--format", "lson")
+	if !strings.Contains(out, "@WIP_LIMIT: DOING:1") || !strings.Contains(out, "@WIP_MODE: refuse") {
+		t.Errorf("Expected WIP limit in workflow, got: %s", out)
+	}
+}
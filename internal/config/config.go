@@ -1,20 +1,62 @@
 package config
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/buyruk-project/buyruk-cli/internal/models"
 	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/buyruk-project/buyruk-cli/internal/timeparse"
 )
 
 // Config represents the global configuration structure.
 type Config struct {
-	DefaultProject string `json:"default_project,omitempty"`
-	DefaultFormat  string `json:"default_format,omitempty"`
+	DefaultProject string            `json:"default_project,omitempty"`
+	DefaultFormat  string            `json:"default_format,omitempty"`
+	Timezone       string            `json:"timezone,omitempty"`        // IANA timezone for parsing and displaying dates
+	Templates      map[string]string `json:"templates,omitempty"`       // Named output templates
+	MaxIssues      int               `json:"max_issues,omitempty"`      // Soft limit on issues per project (0 = default)
+	MaxIndexSize   int64             `json:"max_index_size,omitempty"`  // Soft limit on project.json size in bytes (0 = default)
+	Autocommit     bool              `json:"autocommit,omitempty"`      // Commit .buyruk/ changes after each command in repo-local mode
+	Theme          string            `json:"theme,omitempty"`           // Color theme: auto, a built-in theme, or a file in themes/
+	UsageLog       bool              `json:"usage_log,omitempty"`       // Record commands run (no content) in a local log for buyruk insights
+	BranchTemplate string            `json:"branch_template,omitempty"` // Git branch name template of buyruk branch
+	DefaultRemote  string            `json:"default_remote,omitempty"`  // Base URL clone, pull, and push use for projects without a remote (s3://, webdav://, or a buyruk server)
+	StorageBackend string            `json:"storage_backend,omitempty"` // Base URL projects are kept in instead of the data directory (s3://, webdav://, or a buyruk server)
+	UserName       string            `json:"user_name,omitempty"`       // Name changes are attributed to (default: git's user.name)
+	UserEmail      string            `json:"user_email,omitempty"`      // Email changes are attributed to (default: git's user.email)
+	DataDir        string            `json:"data_dir,omitempty"`        // Directory projects are kept in (default: the config directory)
+	Durability     string            `json:"durability,omitempty"`      // How writes are synced to disk: none, file, or full (default)
+	NotifyCommand  string            `json:"notify_command,omitempty"`  // Shell command buyruk notify runs instead of desktop notifications
+	Aliases        map[string]string `json:"aliases,omitempty"`         // Command aliases, e.g. "ls" for "list --status TODO"
+	EpicStatus     string            `json:"epic_status,omitempty"`     // How epic statuses are set: manual (default) or auto, rolled up from their issues
+	Escalate       map[string]string `json:"escalate,omitempty"`        // How long issues of a priority may wait in the first status before buyruk escalate acts, e.g. "LOW": "30d"
+	EscalateAction string            `json:"escalate_action,omitempty"` // What buyruk escalate does: bump (default) the priority, or flag the issue
+
+	ProjectDefaults map[string]IssueDefaults `json:"project_defaults,omitempty"` // Field values issue create uses per project key
+}
+
+// IssueDefaults are the values issue create uses in a project for the flags
+// that are not given.
+type IssueDefaults struct {
+	Type     string   `json:"type,omitempty"`
+	Priority string   `json:"priority,omitempty"`
+	Labels   []string `json:"labels,omitempty"`
+	Assignee string   `json:"assignee,omitempty"`
 }
 
+// IssueDefaultFields are the fields of IssueDefaults, as used in config keys.
+var IssueDefaultFields = []string{"type", "priority", "labels", "assignee"}
+
 const (
 	// DefaultFormatModern is the default modern format.
 	DefaultFormatModern = "modern"
@@ -22,9 +64,45 @@ const (
 	DefaultFormatJSON = "json"
 	// DefaultFormatLSON is the L-SON format.
 	DefaultFormatLSON = "lson"
+	// DefaultFormatYAML is the YAML format.
+	DefaultFormatYAML = "yaml"
+	// DefaultFormatNDJSON is newline-delimited JSON, one object per line.
+	DefaultFormatNDJSON = "ndjson"
 
 	// ConfigFileName is the name of the config file.
 	ConfigFileName = "config.json"
+
+	// TemplateKeyPrefix is the config key prefix for named templates (e.g., "template.short").
+	TemplateKeyPrefix = "template."
+
+	// AliasKeyPrefix is the config key prefix for command aliases (e.g., "alias.ls").
+	AliasKeyPrefix = "alias."
+
+	// EscalateKeyPrefix is the config key prefix for escalation thresholds
+	// per priority (e.g., "escalate.LOW").
+	EscalateKeyPrefix = "escalate."
+
+	// ProjectKeyPrefix is the config key prefix for per-project issue
+	// defaults (e.g., "project.OPS.priority").
+	ProjectKeyPrefix = "project."
+
+	// ThemeAuto picks the dark or light theme from the terminal background.
+	ThemeAuto = "auto"
+	// ThemeDark is the built-in theme for dark terminal backgrounds.
+	ThemeDark = "dark"
+	// ThemeLight is the built-in theme for light terminal backgrounds.
+	ThemeLight = "light"
+	// ThemeHighContrast is the built-in theme using bright, bold colors.
+	ThemeHighContrast = "high-contrast"
+
+	// DefaultMaxIssues is the default soft limit on issues per project.
+	DefaultMaxIssues = 2000
+	// DefaultMaxIndexSize is the default soft limit on the project index size (1 MB).
+	DefaultMaxIndexSize = 1 << 20
+
+	// DefaultBranchTemplate is the default git branch name template, e.g.
+	// "feat/CORE-12-login-page" (or "fix/..." for bugs).
+	DefaultBranchTemplate = "{prefix}/{id}-{slug}"
 )
 
 // Load loads the configuration from disk.
@@ -41,7 +119,7 @@ func Load() (*Config, error) {
 	}
 
 	var cfg Config
-	if err := storage.ReadJSON(configPath, &cfg); err != nil {
+	if err := storage.ReadJSON(context.Background(), configPath, &cfg); err != nil {
 		return nil, fmt.Errorf("config: failed to load config: %w", err)
 	}
 
@@ -54,7 +132,7 @@ func Load() (*Config, error) {
 }
 
 // Save saves the configuration to disk using atomic write.
-func Save(cfg *Config) error {
+func Save(ctx context.Context, cfg *Config) error {
 	// Validate before saving
 	if err := Validate(cfg); err != nil {
 		return fmt.Errorf("config: invalid config: %w", err)
@@ -77,7 +155,7 @@ func Save(cfg *Config) error {
 	}
 
 	// Use atomic write (config files don't need project-level locking)
-	if err := storage.WriteAtomic(configPath, data); err != nil {
+	if err := storage.WriteAtomic(ctx, configPath, data); err != nil {
 		return fmt.Errorf("config: failed to save config: %w", err)
 	}
 
@@ -90,7 +168,7 @@ func Get() (*Config, error) {
 }
 
 // Set sets a configuration value.
-func Set(key, value string) error {
+func Set(ctx context.Context, key, value string) error {
 	cfg, err := Get()
 	if err != nil {
 		return fmt.Errorf("config: failed to load config: %w", err)
@@ -104,14 +182,163 @@ func Set(key, value string) error {
 		cfg.DefaultProject = value
 	case "default_format":
 		if value != "" && !isValidFormat(value) {
-			return fmt.Errorf("config: invalid format %q (must be modern, json, or lson)", value)
+			return fmt.Errorf("config: invalid format %q (must be modern, json, lson, ndjson, or yaml)", value)
 		}
 		cfg.DefaultFormat = value
+	case "timezone":
+		if value != "" && !isValidTimezone(value) {
+			return fmt.Errorf("config: invalid timezone %q (must be an IANA name such as Europe/Istanbul, UTC, or Local)", value)
+		}
+		cfg.Timezone = value
+	case "max_issues":
+		n := 0
+		if value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 {
+				return fmt.Errorf("config: invalid max_issues %q (must be a positive number)", value)
+			}
+			n = parsed
+		}
+		cfg.MaxIssues = n
+	case "max_index_size":
+		var n int64
+		if value != "" {
+			parsed, err := ParseSize(value)
+			if err != nil {
+				return err
+			}
+			n = parsed
+		}
+		cfg.MaxIndexSize = n
+	case "autocommit":
+		enabled := false
+		if value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("config: invalid autocommit %q (must be true or false)", value)
+			}
+			enabled = parsed
+		}
+		cfg.Autocommit = enabled
+	case "usage_log":
+		enabled := false
+		if value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("config: invalid usage_log %q (must be true or false)", value)
+			}
+			enabled = parsed
+		}
+		cfg.UsageLog = enabled
+	case "branch_template":
+		if value != "" && !isValidBranchTemplate(value) {
+			return fmt.Errorf("config: invalid branch_template %q (must contain {id})", value)
+		}
+		cfg.BranchTemplate = value
+	case "user.name":
+		if strings.ContainsAny(value, "<>\n") {
+			return fmt.Errorf("config: invalid user.name %q (must not contain <, >, or line breaks)", value)
+		}
+		cfg.UserName = strings.TrimSpace(value)
+	case "user.email":
+		if value != "" && !isValidEmail(value) {
+			return fmt.Errorf("config: invalid user.email %q", value)
+		}
+		cfg.UserEmail = value
+	case "default_remote":
+		value = strings.TrimSuffix(value, "/")
+		if value != "" && !isValidRemoteBase(value) {
+			return fmt.Errorf("config: invalid default_remote %q (expected e.g. s3://bucket/buyruk, webdav://host/dav/buyruk, or http://host:7420/projects)", value)
+		}
+		cfg.DefaultRemote = value
+	case "storage_backend":
+		value = strings.TrimSuffix(value, "/")
+		if value != "" && !isValidRemoteBase(value) {
+			return fmt.Errorf("config: invalid storage_backend %q (expected e.g. s3://bucket/buyruk, webdav://host/dav/buyruk, or http://host:7420/projects)", value)
+		}
+		cfg.StorageBackend = value
+	case "data_dir":
+		if value != "" && !isValidDataDir(value) {
+			return fmt.Errorf("config: invalid data_dir %q (must be an absolute path or start with ~/)", value)
+		}
+		cfg.DataDir = value
+	case "durability":
+		if value != "" && !storage.IsValidDurability(value) {
+			return fmt.Errorf("config: invalid durability %q (must be none, file, or full)", value)
+		}
+		cfg.Durability = value
+	case "notify_command":
+		cfg.NotifyCommand = strings.TrimSpace(value)
+	case "epic_status":
+		if value != "" && !isValidEpicStatus(value) {
+			return fmt.Errorf("config: invalid epic_status %q (must be manual or auto)", value)
+		}
+		cfg.EpicStatus = value
+	case "escalate_action":
+		if value != "" && !isValidEscalateAction(value) {
+			return fmt.Errorf("config: invalid escalate_action %q (must be bump or flag)", value)
+		}
+		cfg.EscalateAction = value
+	case "theme":
+		if value != "" {
+			if err := checkTheme(value); err != nil {
+				return err
+			}
+		}
+		cfg.Theme = value
 	default:
-		return fmt.Errorf("config: unknown config key %q", key)
+		if projectKey, field, ok := projectDefaultKey(key); ok {
+			if err := setProjectDefault(cfg, projectKey, field, strings.TrimSpace(value)); err != nil {
+				return err
+			}
+			break
+		}
+		if priority, ok := escalatePriority(key); ok {
+			if !isValidEscalatePriority(priority) {
+				return fmt.Errorf("config: invalid escalation priority %q (must be letters, digits, '-', or '_')", priority)
+			}
+			if value = strings.TrimSpace(value); value == "" {
+				delete(cfg.Escalate, priority)
+				break
+			}
+			if _, err := timeparse.ParseDuration(value); err != nil {
+				return fmt.Errorf("config: invalid %s%s %q (must be a duration such as 14d or 2w)", EscalateKeyPrefix, priority, value)
+			}
+			if cfg.Escalate == nil {
+				cfg.Escalate = map[string]string{}
+			}
+			cfg.Escalate[priority] = value
+			break
+		}
+		if name, ok := aliasName(key); ok {
+			if !isValidAliasName(name) {
+				return fmt.Errorf("config: invalid alias name %q (must be lowercase letters, digits, '-', or '_', starting with a letter)", name)
+			}
+			if value = strings.TrimSpace(value); value == "" {
+				delete(cfg.Aliases, name)
+			} else {
+				if cfg.Aliases == nil {
+					cfg.Aliases = map[string]string{}
+				}
+				cfg.Aliases[name] = value
+			}
+			break
+		}
+		name, ok := templateName(key)
+		if !ok {
+			return fmt.Errorf("config: unknown config key %q", key)
+		}
+		if value == "" {
+			delete(cfg.Templates, name)
+		} else {
+			if cfg.Templates == nil {
+				cfg.Templates = map[string]string{}
+			}
+			cfg.Templates[name] = value
+		}
 	}
 
-	return Save(cfg)
+	return Save(ctx, cfg)
 }
 
 // GetValue gets a configuration value.
@@ -126,16 +353,432 @@ func GetValue(key string) (string, error) {
 		return cfg.DefaultProject, nil
 	case "default_format":
 		return cfg.DefaultFormat, nil
+	case "timezone":
+		return cfg.Timezone, nil
+	case "max_issues":
+		if cfg.MaxIssues == 0 {
+			return "", nil
+		}
+		return strconv.Itoa(cfg.MaxIssues), nil
+	case "max_index_size":
+		if cfg.MaxIndexSize == 0 {
+			return "", nil
+		}
+		return strconv.FormatInt(cfg.MaxIndexSize, 10), nil
+	case "autocommit":
+		return strconv.FormatBool(cfg.Autocommit), nil
+	case "usage_log":
+		return strconv.FormatBool(cfg.UsageLog), nil
+	case "branch_template":
+		return cfg.BranchTemplate, nil
+	case "default_remote":
+		return cfg.DefaultRemote, nil
+	case "storage_backend":
+		return cfg.StorageBackend, nil
+	case "data_dir":
+		return cfg.DataDir, nil
+	case "durability":
+		return cfg.Durability, nil
+	case "notify_command":
+		return cfg.NotifyCommand, nil
+	case "epic_status":
+		return cfg.EpicStatus, nil
+	case "escalate_action":
+		return cfg.EscalateAction, nil
+	case "user.name":
+		return cfg.UserName, nil
+	case "user.email":
+		return cfg.UserEmail, nil
+	case "theme":
+		return cfg.Theme, nil
+	default:
+		if projectKey, field, ok := projectDefaultKey(key); ok {
+			if !slices.Contains(IssueDefaultFields, field) {
+				return "", fmt.Errorf("config: unknown project default %q (must be %s)", field, strings.Join(IssueDefaultFields, ", "))
+			}
+			return cfg.ProjectDefaults[projectKey].Value(field), nil
+		}
+		if priority, ok := escalatePriority(key); ok {
+			return cfg.Escalate[priority], nil
+		}
+		if name, ok := aliasName(key); ok {
+			return cfg.Aliases[name], nil
+		}
+		name, ok := templateName(key)
+		if !ok {
+			return "", fmt.Errorf("config: unknown config key %q", key)
+		}
+		return cfg.Templates[name], nil
+	}
+}
+
+// templateName extracts the template name from a "template.<name>" config key.
+func templateName(key string) (string, bool) {
+	if !strings.HasPrefix(key, TemplateKeyPrefix) {
+		return "", false
+	}
+	name := strings.TrimPrefix(key, TemplateKeyPrefix)
+	if name == "" {
+		return "", false
+	}
+	return name, true
+}
+
+// LookupTemplate returns the named template from config, if one exists.
+func LookupTemplate(name string) (string, bool) {
+	cfg, err := Get()
+	if err != nil {
+		return "", false
+	}
+	text, ok := cfg.Templates[name]
+	return text, ok
+}
+
+// projectDefaultKey splits a "project.<KEY>.<field>" config key.
+func projectDefaultKey(key string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(key, ProjectKeyPrefix)
+	if !ok {
+		return "", "", false
+	}
+	i := strings.LastIndex(rest, ".")
+	if i <= 0 || i == len(rest)-1 {
+		return "", "", false
+	}
+	return rest[:i], rest[i+1:], true
+}
+
+// setProjectDefault sets one issue default of a project; an empty value
+// clears it.
+func setProjectDefault(cfg *Config, projectKey, field, value string) error {
+	if !isValidProjectKey(projectKey) {
+		return fmt.Errorf("config: invalid project key %q (must be uppercase alphanumeric or hyphen)", projectKey)
+	}
+	defaults := cfg.ProjectDefaults[projectKey]
+	switch field {
+	case "type":
+		defaults.Type = value
+	case "priority":
+		defaults.Priority = value
+	case "labels":
+		defaults.Labels = nil
+		for _, label := range strings.Split(value, ",") {
+			if label = strings.TrimSpace(label); label != "" && !slices.Contains(defaults.Labels, label) {
+				defaults.Labels = append(defaults.Labels, label)
+			}
+		}
+	case "assignee":
+		defaults.Assignee = value
 	default:
-		return "", fmt.Errorf("config: unknown config key %q", key)
+		return fmt.Errorf("config: unknown project default %q (must be %s)", field, strings.Join(IssueDefaultFields, ", "))
+	}
+	if err := defaults.Validate(); err != nil {
+		return err
+	}
+
+	if defaults.IsZero() {
+		delete(cfg.ProjectDefaults, projectKey)
+		return nil
+	}
+	if cfg.ProjectDefaults == nil {
+		cfg.ProjectDefaults = map[string]IssueDefaults{}
+	}
+	cfg.ProjectDefaults[projectKey] = defaults
+	return nil
+}
+
+// Value returns one field of the defaults as a config value.
+func (d IssueDefaults) Value(field string) string {
+	switch field {
+	case "type":
+		return d.Type
+	case "priority":
+		return d.Priority
+	case "labels":
+		return strings.Join(d.Labels, ",")
+	case "assignee":
+		return d.Assignee
+	}
+	return ""
+}
+
+// IsZero reports whether no default is set.
+func (d IssueDefaults) IsZero() bool {
+	return d.Type == "" && d.Priority == "" && len(d.Labels) == 0 && d.Assignee == ""
+}
+
+// Validate checks the labels and assignee of the defaults. Types and
+// priorities depend on each project's workflow and are checked by issue
+// create.
+func (d IssueDefaults) Validate() error {
+	for _, label := range d.Labels {
+		if err := models.ValidateLabel(label); err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+	}
+	if d.Assignee != "" {
+		if err := models.ValidateUser(d.Assignee); err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+	}
+	return nil
+}
+
+// LookupProjectDefaults returns the issue defaults of a project.
+func LookupProjectDefaults(projectKey string) IssueDefaults {
+	cfg, err := Get()
+	if err != nil {
+		return IssueDefaults{}
+	}
+	return cfg.ProjectDefaults[projectKey]
+}
+
+// aliasName extracts the alias name from an "alias.<name>" config key.
+func aliasName(key string) (string, bool) {
+	name, ok := strings.CutPrefix(key, AliasKeyPrefix)
+	return name, ok && name != ""
+}
+
+var aliasNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+func isValidAliasName(name string) bool {
+	return aliasNameRegex.MatchString(name)
+}
+
+// LookupAlias returns the command an alias stands for, if one exists.
+func LookupAlias(name string) (string, bool) {
+	cfg, err := Get()
+	if err != nil {
+		return "", false
 	}
+	expansion, ok := cfg.Aliases[name]
+	return expansion, ok
+}
+
+// escalatePriority extracts the priority from an "escalate.<priority>" config
+// key.
+func escalatePriority(key string) (string, bool) {
+	priority, ok := strings.CutPrefix(key, EscalateKeyPrefix)
+	return priority, ok && priority != ""
+}
+
+var escalatePriorityRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func isValidEscalatePriority(priority string) bool {
+	return escalatePriorityRegex.MatchString(priority)
 }
 
 // isValidFormat validates that the format is one of the allowed values.
 func isValidFormat(format string) bool {
 	return format == DefaultFormatModern ||
 		format == DefaultFormatJSON ||
-		format == DefaultFormatLSON
+		format == DefaultFormatLSON ||
+		format == DefaultFormatNDJSON ||
+		format == DefaultFormatYAML
+}
+
+// IsBuiltinTheme reports whether name is auto or one of the built-in themes.
+func IsBuiltinTheme(name string) bool {
+	return name == ThemeAuto || name == ThemeDark || name == ThemeLight || name == ThemeHighContrast
+}
+
+// themeNameRegex matches names of custom themes, which are also file names.
+var themeNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// IsCustomThemeName reports whether name can name a custom theme file.
+func IsCustomThemeName(name string) bool {
+	return !IsBuiltinTheme(name) && themeNameRegex.MatchString(name)
+}
+
+// isValidThemeName validates that the theme is built in or a valid custom theme name.
+func isValidThemeName(name string) bool {
+	return IsBuiltinTheme(name) || themeNameRegex.MatchString(name)
+}
+
+// checkTheme validates a theme name and that a custom theme has a file in themes/.
+func checkTheme(name string) error {
+	if !isValidThemeName(name) {
+		return fmt.Errorf("config: invalid theme %q (must be auto, dark, light, high-contrast, or a lowercase theme file name)", name)
+	}
+	if IsBuiltinTheme(name) {
+		return nil
+	}
+	themePath, err := storage.ThemePath(name)
+	if err != nil {
+		return fmt.Errorf("config: failed to resolve theme path: %w", err)
+	}
+	if _, err := os.Stat(themePath); err != nil {
+		return fmt.Errorf("config: theme %q not found (expected %s)", name, themePath)
+	}
+	return nil
+}
+
+// isValidBranchTemplate validates that a branch template names the issue, so
+// that buyruk issue current can find the issue of a branch.
+func isValidBranchTemplate(template string) bool {
+	return strings.Contains(template, "{id}")
+}
+
+// RemoteBaseSchemes are the URL schemes default_remote and storage_backend
+// accept: an S3 bucket, a WebDAV share over HTTPS or plain HTTP, or a buyruk
+// server.
+var RemoteBaseSchemes = []string{"s3", "webdav", "webdav+http", "http", "https"}
+
+// isValidRemoteBase validates that a base URL of projects has a supported
+// scheme and a host (the bucket, for S3).
+func isValidRemoteBase(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && slices.Contains(RemoteBaseSchemes, u.Scheme) && u.Host != ""
+}
+
+// isValidDataDir validates that the data directory is an absolute path, or
+// one in the home directory (~/).
+func isValidDataDir(value string) bool {
+	return filepath.IsAbs(value) || strings.HasPrefix(value, "~/")
+}
+
+// DurabilityLevel returns how writes are synced to disk (storage.DurabilityFull
+// if not set).
+func (c *Config) DurabilityLevel() string {
+	if c.Durability == "" {
+		return storage.DurabilityFull
+	}
+	return c.Durability
+}
+
+// Epic status modes: set by hand with epic update, or rolled up from the
+// statuses of the epic's issues.
+const (
+	EpicStatusManual = "manual"
+	EpicStatusAuto   = "auto"
+)
+
+// isValidEpicStatus validates an epic_status mode.
+func isValidEpicStatus(value string) bool {
+	return value == EpicStatusManual || value == EpicStatusAuto
+}
+
+// EpicStatusMode returns how epic statuses are set (EpicStatusManual if not
+// set).
+func (c *Config) EpicStatusMode() string {
+	if c.EpicStatus == "" {
+		return EpicStatusManual
+	}
+	return c.EpicStatus
+}
+
+// Escalation actions of buyruk escalate: raise the priority of a waiting
+// issue, or flag it with a label.
+const (
+	EscalateBump = "bump"
+	EscalateFlag = "flag"
+)
+
+// isValidEscalateAction validates an escalate_action.
+func isValidEscalateAction(value string) bool {
+	return value == EscalateBump || value == EscalateFlag
+}
+
+// EscalateActionMode returns what buyruk escalate does (EscalateBump if not
+// set).
+func (c *Config) EscalateActionMode() string {
+	if c.EscalateAction == "" {
+		return EscalateBump
+	}
+	return c.EscalateAction
+}
+
+// DataDirPath returns the data_dir setting with a leading ~/ expanded to the
+// home directory, or "" if it isn't set.
+func (c *Config) DataDirPath() (string, error) {
+	if rest, ok := strings.CutPrefix(c.DataDir, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("config: failed to expand data_dir: %w", err)
+		}
+		return filepath.Join(home, rest), nil
+	}
+	return c.DataDir, nil
+}
+
+// isValidEmail validates that the email has a local part and a domain, and no
+// spaces or angle brackets (it is written as "Name <email>").
+func isValidEmail(email string) bool {
+	local, domain, ok := strings.Cut(email, "@")
+	return ok && local != "" && domain != "" && !strings.ContainsAny(email, " <>\n")
+}
+
+// isValidTimezone validates that the timezone can be loaded.
+func isValidTimezone(name string) bool {
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+// Location returns the configured timezone, falling back to the local timezone
+// when none is configured or the configured one cannot be loaded.
+func Location() *time.Location {
+	cfg, err := Get()
+	if err != nil || cfg.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// IssueLimit returns the soft limit on issues per project.
+func (c *Config) IssueLimit() int {
+	if c.MaxIssues > 0 {
+		return c.MaxIssues
+	}
+	return DefaultMaxIssues
+}
+
+// BranchNameTemplate returns the git branch name template.
+func (c *Config) BranchNameTemplate() string {
+	if c.BranchTemplate != "" {
+		return c.BranchTemplate
+	}
+	return DefaultBranchTemplate
+}
+
+// IndexSizeLimit returns the soft limit on the project index size in bytes.
+func (c *Config) IndexSizeLimit() int64 {
+	if c.MaxIndexSize > 0 {
+		return c.MaxIndexSize
+	}
+	return DefaultMaxIndexSize
+}
+
+// sizeUnits maps size suffixes to their multiplier, longest suffix first.
+var sizeUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseSize parses a size in bytes with an optional KB, MB, or GB suffix (e.g. "512KB", "2MB").
+func ParseSize(value string) (int64, error) {
+	v := strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(v, unit.suffix) {
+			v = strings.TrimSpace(strings.TrimSuffix(v, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("config: invalid size %q (use bytes or a KB, MB, or GB suffix, e.g. 512KB)", value)
+	}
+	return n * multiplier, nil
 }
 
 // isValidProjectKey validates that the project key is uppercase alphanumeric or hyphen.
@@ -151,6 +794,78 @@ func Validate(cfg *Config) error {
 		return fmt.Errorf("config: invalid default_format %q", cfg.DefaultFormat)
 	}
 
+	if cfg.Timezone != "" && !isValidTimezone(cfg.Timezone) {
+		return fmt.Errorf("config: invalid timezone %q", cfg.Timezone)
+	}
+
+	if cfg.Theme != "" && !isValidThemeName(cfg.Theme) {
+		return fmt.Errorf("config: invalid theme %q", cfg.Theme)
+	}
+
+	if cfg.BranchTemplate != "" && !isValidBranchTemplate(cfg.BranchTemplate) {
+		return fmt.Errorf("config: invalid branch_template %q", cfg.BranchTemplate)
+	}
+
+	if cfg.UserEmail != "" && !isValidEmail(cfg.UserEmail) {
+		return fmt.Errorf("config: invalid user.email %q", cfg.UserEmail)
+	}
+
+	if cfg.DefaultRemote != "" && !isValidRemoteBase(cfg.DefaultRemote) {
+		return fmt.Errorf("config: invalid default_remote %q", cfg.DefaultRemote)
+	}
+
+	if cfg.StorageBackend != "" && !isValidRemoteBase(cfg.StorageBackend) {
+		return fmt.Errorf("config: invalid storage_backend %q", cfg.StorageBackend)
+	}
+
+	if cfg.DataDir != "" && !isValidDataDir(cfg.DataDir) {
+		return fmt.Errorf("config: invalid data_dir %q", cfg.DataDir)
+	}
+
+	if cfg.Durability != "" && !storage.IsValidDurability(cfg.Durability) {
+		return fmt.Errorf("config: invalid durability %q", cfg.Durability)
+	}
+
+	if cfg.EpicStatus != "" && !isValidEpicStatus(cfg.EpicStatus) {
+		return fmt.Errorf("config: invalid epic_status %q", cfg.EpicStatus)
+	}
+
+	if cfg.EscalateAction != "" && !isValidEscalateAction(cfg.EscalateAction) {
+		return fmt.Errorf("config: invalid escalate_action %q", cfg.EscalateAction)
+	}
+
+	for priority, after := range cfg.Escalate {
+		if !isValidEscalatePriority(priority) {
+			return fmt.Errorf("config: invalid escalation priority %q", priority)
+		}
+		if _, err := timeparse.ParseDuration(after); err != nil {
+			return fmt.Errorf("config: invalid %s%s %q", EscalateKeyPrefix, priority, after)
+		}
+	}
+
+	for projectKey, defaults := range cfg.ProjectDefaults {
+		if !isValidProjectKey(projectKey) {
+			return fmt.Errorf("config: invalid project key %q in project defaults", projectKey)
+		}
+		if err := defaults.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for name := range cfg.Aliases {
+		if !isValidAliasName(name) {
+			return fmt.Errorf("config: invalid alias name %q", name)
+		}
+	}
+
+	if cfg.MaxIssues < 0 {
+		return fmt.Errorf("config: invalid max_issues %d", cfg.MaxIssues)
+	}
+
+	if cfg.MaxIndexSize < 0 {
+		return fmt.Errorf("config: invalid max_index_size %d", cfg.MaxIndexSize)
+	}
+
 	// Project key validation (uppercase alphanumeric or hyphen)
 	if cfg.DefaultProject != "" {
 		if !isValidProjectKey(cfg.DefaultProject) {
@@ -6,7 +6,9 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
 	"github.com/spf13/cobra"
 )
 
@@ -81,12 +83,12 @@ func TestResolveFormat_Config(t *testing.T) {
 	defer func() {
 		// Restore original config
 		if originalCfg != nil {
-			Save(originalCfg)
+			Save(t.Context(), originalCfg)
 		}
 	}()
 
 	// Set config format to json
-	if err := Set("default_format", "json"); err != nil {
+	if err := Set(t.Context(), "default_format", "json"); err != nil {
 		t.Fatalf("Failed to set config: %v", err)
 	}
 
@@ -105,12 +107,12 @@ func TestResolveFormat_Default(t *testing.T) {
 	originalCfg, _ := Get()
 	defer func() {
 		if originalCfg != nil {
-			Save(originalCfg)
+			Save(t.Context(), originalCfg)
 		}
 	}()
 
 	// Clear default_format
-	if err := Set("default_format", ""); err != nil {
+	if err := Set(t.Context(), "default_format", ""); err != nil {
 		t.Fatalf("Failed to clear config: %v", err)
 	}
 
@@ -146,12 +148,12 @@ func TestResolveProject_Config(t *testing.T) {
 	defer func() {
 		// Restore original config
 		if originalCfg != nil {
-			Save(originalCfg)
+			Save(t.Context(), originalCfg)
 		}
 	}()
 
 	// Set config project
-	if err := Set("default_project", "TESTPROJ"); err != nil {
+	if err := Set(t.Context(), "default_project", "TESTPROJ"); err != nil {
 		t.Fatalf("Failed to set config: %v", err)
 	}
 
@@ -173,12 +175,12 @@ func TestResolveProject_Error(t *testing.T) {
 	originalCfg, _ := Get()
 	defer func() {
 		if originalCfg != nil {
-			Save(originalCfg)
+			Save(t.Context(), originalCfg)
 		}
 	}()
 
 	// Clear default_project
-	if err := Set("default_project", ""); err != nil {
+	if err := Set(t.Context(), "default_project", ""); err != nil {
 		t.Fatalf("Failed to clear config: %v", err)
 	}
 
@@ -196,12 +198,12 @@ func TestSet_DefaultProject(t *testing.T) {
 	originalCfg, _ := Get()
 	defer func() {
 		if originalCfg != nil {
-			Save(originalCfg)
+			Save(t.Context(), originalCfg)
 		}
 	}()
 
 	// Test setting default_project
-	if err := Set("default_project", "TEST123"); err != nil {
+	if err := Set(t.Context(), "default_project", "TEST123"); err != nil {
 		t.Fatalf("Set() failed: %v", err)
 	}
 
@@ -215,7 +217,7 @@ func TestSet_DefaultProject(t *testing.T) {
 	}
 
 	// Test clearing it
-	if err := Set("default_project", ""); err != nil {
+	if err := Set(t.Context(), "default_project", ""); err != nil {
 		t.Fatalf("Set() failed to clear: %v", err)
 	}
 
@@ -232,12 +234,12 @@ func TestSet_DefaultFormat(t *testing.T) {
 	originalCfg, _ := Get()
 	defer func() {
 		if originalCfg != nil {
-			Save(originalCfg)
+			Save(t.Context(), originalCfg)
 		}
 	}()
 
 	// Test setting default_format
-	if err := Set("default_format", "json"); err != nil {
+	if err := Set(t.Context(), "default_format", "json"); err != nil {
 		t.Fatalf("Set() failed: %v", err)
 	}
 
@@ -251,7 +253,7 @@ func TestSet_DefaultFormat(t *testing.T) {
 	}
 
 	// Test setting to lson
-	if err := Set("default_format", "lson"); err != nil {
+	if err := Set(t.Context(), "default_format", "lson"); err != nil {
 		t.Fatalf("Set() failed: %v", err)
 	}
 
@@ -265,7 +267,7 @@ func TestSet_DefaultFormat(t *testing.T) {
 }
 
 func TestSet_InvalidKey(t *testing.T) {
-	err := Set("invalid_key", "value")
+	err := Set(t.Context(), "invalid_key", "value")
 	if err == nil {
 		t.Fatal("Set() should fail for invalid key")
 	}
@@ -278,15 +280,15 @@ func TestSet_InvalidFormat(t *testing.T) {
 	originalCfg, _ := Get()
 	defer func() {
 		if originalCfg != nil {
-			Save(originalCfg)
+			Save(t.Context(), originalCfg)
 		}
 	}()
 
-	err := Set("default_format", "invalid_format")
+	err := Set(t.Context(), "default_format", "invalid_format")
 	if err == nil {
 		t.Fatal("Set() should fail for invalid format")
 	}
-	if err.Error() != "config: invalid format \"invalid_format\" (must be modern, json, or lson)" {
+	if err.Error() != "config: invalid format \"invalid_format\" (must be modern, json, lson, ndjson, or yaml)" {
 		t.Errorf("Set() error = %q, want error about invalid format", err.Error())
 	}
 }
@@ -295,11 +297,11 @@ func TestSet_InvalidProjectKey(t *testing.T) {
 	originalCfg, _ := Get()
 	defer func() {
 		if originalCfg != nil {
-			Save(originalCfg)
+			Save(t.Context(), originalCfg)
 		}
 	}()
 
-	err := Set("default_project", "invalid-project")
+	err := Set(t.Context(), "default_project", "invalid-project")
 	if err == nil {
 		t.Fatal("Set() should fail for invalid project key")
 	}
@@ -312,12 +314,12 @@ func TestGetValue(t *testing.T) {
 	originalCfg, _ := Get()
 	defer func() {
 		if originalCfg != nil {
-			Save(originalCfg)
+			Save(t.Context(), originalCfg)
 		}
 	}()
 
 	// Test getting default_project
-	if err := Set("default_project", "GETTEST"); err != nil {
+	if err := Set(t.Context(), "default_project", "GETTEST"); err != nil {
 		t.Fatalf("Set() failed: %v", err)
 	}
 
@@ -330,7 +332,7 @@ func TestGetValue(t *testing.T) {
 	}
 
 	// Test getting default_format
-	if err := Set("default_format", "lson"); err != nil {
+	if err := Set(t.Context(), "default_format", "lson"); err != nil {
 		t.Fatalf("Set() failed: %v", err)
 	}
 
@@ -352,6 +354,368 @@ func TestGetValue(t *testing.T) {
 	}
 }
 
+func TestSet_Template(t *testing.T) {
+	originalCfg, _ := Get()
+	defer func() {
+		if originalCfg != nil {
+			Save(t.Context(), originalCfg)
+		}
+	}()
+
+	if err := Set(t.Context(), "template.short", "{{.ID}}"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+
+	value, err := GetValue("template.short")
+	if err != nil {
+		t.Fatalf("GetValue() failed: %v", err)
+	}
+	if value != "{{.ID}}" {
+		t.Errorf("GetValue() = %q, want {{.ID}}", value)
+	}
+
+	if text, ok := LookupTemplate("short"); !ok || text != "{{.ID}}" {
+		t.Errorf("LookupTemplate() = %q, %v, want {{.ID}}, true", text, ok)
+	}
+
+	// Setting an empty value removes the template
+	if err := Set(t.Context(), "template.short", ""); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if _, ok := LookupTemplate("short"); ok {
+		t.Error("LookupTemplate() should not find removed template")
+	}
+
+	// A template key without a name is unknown
+	if err := Set(t.Context(), "template.", "{{.ID}}"); err == nil {
+		t.Error("Set() should fail for template key without name")
+	}
+}
+
+func TestSet_Escalate(t *testing.T) {
+	originalCfg, _ := Get()
+	defer func() {
+		if originalCfg != nil {
+			Save(t.Context(), originalCfg)
+		}
+	}()
+
+	if err := Set(t.Context(), "escalate.LOW", "30d"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if value, _ := GetValue("escalate.LOW"); value != "30d" {
+		t.Errorf("GetValue() = %q, want 30d", value)
+	}
+	if err := Set(t.Context(), "escalate.LOW", "someday"); err == nil {
+		t.Error("Set() should fail for an invalid duration")
+	}
+	if err := Set(t.Context(), "escalate.LOW HIGH", "30d"); err == nil {
+		t.Error("Set() should fail for an invalid priority")
+	}
+
+	// Setting an empty value removes the threshold
+	if err := Set(t.Context(), "escalate.LOW", ""); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if value, _ := GetValue("escalate.LOW"); value != "" {
+		t.Errorf("GetValue() = %q, want empty", value)
+	}
+
+	cfg, _ := Get()
+	if cfg.EscalateActionMode() != EscalateBump {
+		t.Errorf("EscalateActionMode() = %q, want %q", cfg.EscalateActionMode(), EscalateBump)
+	}
+	if err := Set(t.Context(), "escalate_action", "flag"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if err := Set(t.Context(), "escalate_action", "close"); err == nil {
+		t.Error("Set() should fail for an invalid escalate_action")
+	}
+	cfg, _ = Get()
+	if cfg.EscalateActionMode() != EscalateFlag {
+		t.Errorf("EscalateActionMode() = %q, want %q", cfg.EscalateActionMode(), EscalateFlag)
+	}
+}
+
+func TestSet_ProjectDefaults(t *testing.T) {
+	originalCfg, _ := Get()
+	defer func() {
+		if originalCfg != nil {
+			Save(t.Context(), originalCfg)
+		}
+	}()
+
+	for key, value := range map[string]string{
+		"project.OPS.priority": "HIGH",
+		"project.OPS.labels":   "ops, oncall,ops",
+		"project.OPS.assignee": "alice",
+	} {
+		if err := Set(t.Context(), key, value); err != nil {
+			t.Fatalf("Set(%q) failed: %v", key, err)
+		}
+	}
+
+	defaults := LookupProjectDefaults("OPS")
+	if defaults.Priority != "HIGH" || strings.Join(defaults.Labels, ",") != "ops,oncall" || defaults.Assignee != "alice" {
+		t.Errorf("LookupProjectDefaults() = %+v", defaults)
+	}
+	if value, err := GetValue("project.OPS.labels"); err != nil || value != "ops,oncall" {
+		t.Errorf("GetValue() = %q, %v, want ops,oncall", value, err)
+	}
+
+	for _, key := range []string{"project.ops.priority", "project.OPS.color", "project.OPS."} {
+		if err := Set(t.Context(), key, "HIGH"); err == nil {
+			t.Errorf("Set(%q) should fail", key)
+		}
+	}
+	if err := Set(t.Context(), "project.OPS.assignee", "alice smith"); err == nil {
+		t.Error("Set() should fail for an invalid assignee")
+	}
+
+	// Clearing every default removes the project's entry
+	for _, field := range []string{"priority", "labels", "assignee"} {
+		if err := Set(t.Context(), "project.OPS."+field, ""); err != nil {
+			t.Fatalf("Set() failed: %v", err)
+		}
+	}
+	cfg, _ := Get()
+	if _, ok := cfg.ProjectDefaults["OPS"]; ok {
+		t.Errorf("Expected the OPS defaults to be removed, got %+v", cfg.ProjectDefaults)
+	}
+}
+
+func TestSet_Timezone(t *testing.T) {
+	originalCfg, _ := Get()
+	defer func() {
+		if originalCfg != nil {
+			Save(t.Context(), originalCfg)
+		}
+	}()
+
+	if err := Set(t.Context(), "timezone", "Europe/Istanbul"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+
+	value, err := GetValue("timezone")
+	if err != nil {
+		t.Fatalf("GetValue() failed: %v", err)
+	}
+	if value != "Europe/Istanbul" {
+		t.Errorf("GetValue() = %q, want Europe/Istanbul", value)
+	}
+	if loc := Location(); loc.String() != "Europe/Istanbul" {
+		t.Errorf("Location() = %q, want Europe/Istanbul", loc)
+	}
+
+	if err := Set(t.Context(), "timezone", "Mars/Olympus"); err == nil {
+		t.Error("Set() should fail for unknown timezone")
+	}
+
+	// Clearing the timezone falls back to the local timezone
+	if err := Set(t.Context(), "timezone", ""); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if loc := Location(); loc != time.Local {
+		t.Errorf("Location() = %q, want local", loc)
+	}
+}
+
+func TestSet_Quotas(t *testing.T) {
+	originalCfg, _ := Get()
+	defer func() {
+		if originalCfg != nil {
+			Save(t.Context(), originalCfg)
+		}
+	}()
+
+	if err := Set(t.Context(), "max_issues", "500"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if err := Set(t.Context(), "max_index_size", "512KB"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+
+	cfg, err := Get()
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if cfg.IssueLimit() != 500 {
+		t.Errorf("IssueLimit() = %d, want 500", cfg.IssueLimit())
+	}
+	if cfg.IndexSizeLimit() != 512*1024 {
+		t.Errorf("IndexSizeLimit() = %d, want %d", cfg.IndexSizeLimit(), 512*1024)
+	}
+	if value, _ := GetValue("max_index_size"); value != "524288" {
+		t.Errorf("GetValue(max_index_size) = %q, want 524288", value)
+	}
+
+	for _, tt := range []struct{ key, value string }{
+		{"max_issues", "0"},
+		{"max_issues", "many"},
+		{"max_index_size", "-1MB"},
+		{"max_index_size", "1TB"},
+	} {
+		if err := Set(t.Context(), tt.key, tt.value); err == nil {
+			t.Errorf("Set(%q, %q) should fail", tt.key, tt.value)
+		}
+	}
+
+	// Clearing a limit restores the default
+	if err := Set(t.Context(), "max_issues", ""); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	cfg, _ = Get()
+	if cfg.IssueLimit() != DefaultMaxIssues {
+		t.Errorf("IssueLimit() = %d, want default %d", cfg.IssueLimit(), DefaultMaxIssues)
+	}
+}
+
+func TestSet_DefaultRemote(t *testing.T) {
+	originalCfg, _ := Get()
+	defer func() {
+		if originalCfg != nil {
+			Save(t.Context(), originalCfg)
+		}
+	}()
+
+	if err := Set(t.Context(), "default_remote", "s3://team-bucket/buyruk/"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if value, _ := GetValue("default_remote"); value != "s3://team-bucket/buyruk" {
+		t.Errorf("GetValue(default_remote) = %q, want the URL without the trailing slash", value)
+	}
+	for _, value := range []string{"ftp://host/buyruk", "s3:///buyruk", "team-bucket"} {
+		if err := Set(t.Context(), "default_remote", value); err == nil {
+			t.Errorf("Set(default_remote, %q) should fail", value)
+		}
+	}
+}
+
+func TestSet_StorageBackend(t *testing.T) {
+	originalCfg, _ := Get()
+	defer func() {
+		if originalCfg != nil {
+			Save(t.Context(), originalCfg)
+		}
+	}()
+
+	if err := Set(t.Context(), "storage_backend", "webdav://files.example.com/dav/buyruk/"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if value, _ := GetValue("storage_backend"); value != "webdav://files.example.com/dav/buyruk" {
+		t.Errorf("GetValue(storage_backend) = %q, want the URL without the trailing slash", value)
+	}
+	if err := Set(t.Context(), "storage_backend", "ftp://host/buyruk"); err == nil {
+		t.Error("Set(storage_backend, ftp://host/buyruk) should fail")
+	}
+}
+
+func TestSet_User(t *testing.T) {
+	originalCfg, _ := Get()
+	defer func() {
+		if originalCfg != nil {
+			Save(t.Context(), originalCfg)
+		}
+	}()
+
+	if err := Set(t.Context(), "user.name", "Alice Doe"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if err := Set(t.Context(), "user.email", "alice@example.com"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if value, _ := GetValue("user.name"); value != "Alice Doe" {
+		t.Errorf("GetValue(user.name) = %q, want Alice Doe", value)
+	}
+	for _, tt := range []struct{ key, value string }{
+		{"user.name", "Alice <alice@example.com>"},
+		{"user.email", "alice"},
+		{"user.email", "alice @example.com"},
+	} {
+		if err := Set(t.Context(), tt.key, tt.value); err == nil {
+			t.Errorf("Set(%q, %q) should fail", tt.key, tt.value)
+		}
+	}
+}
+
+func TestSet_DataDir(t *testing.T) {
+	originalCfg, _ := Get()
+	defer func() {
+		if originalCfg != nil {
+			Save(t.Context(), originalCfg)
+		}
+	}()
+
+	if err := Set(t.Context(), "data_dir", "~/Sync/buyruk/"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	cfg, _ := Get()
+	home, _ := os.UserHomeDir()
+	if dir, err := cfg.DataDirPath(); err != nil || dir != filepath.Join(home, "Sync", "buyruk") {
+		t.Errorf("DataDirPath() = %q, %v, want it under the home directory", dir, err)
+	}
+	for _, value := range []string{"data", "./data", "~alice/data"} {
+		if err := Set(t.Context(), "data_dir", value); err == nil {
+			t.Errorf("Set(data_dir, %q) should fail", value)
+		}
+	}
+	if err := Set(t.Context(), "data_dir", ""); err != nil {
+		t.Fatalf("Set() failed to unset data_dir: %v", err)
+	}
+	cfg, _ = Get()
+	if dir, _ := cfg.DataDirPath(); dir != "" {
+		t.Errorf("DataDirPath() = %q, want none once unset", dir)
+	}
+}
+
+func TestSet_Durability(t *testing.T) {
+	originalCfg, _ := Get()
+	defer func() {
+		if originalCfg != nil {
+			Save(t.Context(), originalCfg)
+		}
+	}()
+
+	cfg, _ := Get()
+	cfg.Durability = ""
+	if level := cfg.DurabilityLevel(); level != storage.DurabilityFull {
+		t.Errorf("DurabilityLevel() = %q, want %q by default", level, storage.DurabilityFull)
+	}
+	if err := Set(t.Context(), "durability", "file"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if value, _ := GetValue("durability"); value != "file" {
+		t.Errorf("GetValue(durability) = %q, want file", value)
+	}
+	if err := Set(t.Context(), "durability", "paranoid"); err == nil {
+		t.Error("Set(durability, paranoid) should fail")
+	}
+}
+
+func TestSet_EpicStatus(t *testing.T) {
+	originalCfg, _ := Get()
+	defer func() {
+		if originalCfg != nil {
+			Save(t.Context(), originalCfg)
+		}
+	}()
+
+	cfg, _ := Get()
+	cfg.EpicStatus = ""
+	if mode := cfg.EpicStatusMode(); mode != EpicStatusManual {
+		t.Errorf("EpicStatusMode() = %q, want %q by default", mode, EpicStatusManual)
+	}
+	if err := Set(t.Context(), "epic_status", "auto"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if value, _ := GetValue("epic_status"); value != EpicStatusAuto {
+		t.Errorf("GetValue(epic_status) = %q, want auto", value)
+	}
+	if err := Set(t.Context(), "epic_status", "rollup"); err == nil {
+		t.Error("Set(epic_status, rollup) should fail")
+	}
+}
+
 func TestIsValidFormat(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -361,6 +725,7 @@ func TestIsValidFormat(t *testing.T) {
 		{"modern", DefaultFormatModern, true},
 		{"json", DefaultFormatJSON, true},
 		{"lson", DefaultFormatLSON, true},
+		{"yaml", DefaultFormatYAML, true},
 		{"invalid", "invalid", false},
 		{"empty", "", false},
 		{"mixed case", "Modern", false},
@@ -436,3 +801,37 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestSet_Theme(t *testing.T) {
+	originalCfg, _ := Get()
+	defer func() {
+		if originalCfg != nil {
+			Save(t.Context(), originalCfg)
+		}
+	}()
+
+	for _, name := range []string{ThemeAuto, ThemeDark, ThemeLight, ThemeHighContrast, ""} {
+		if err := Set(t.Context(), "theme", name); err != nil {
+			t.Errorf("Set(theme, %q) failed: %v", name, err)
+		}
+	}
+	if err := Set(t.Context(), "theme", "Solarized Dark"); err == nil {
+		t.Error("Set() should fail for an invalid theme name")
+	}
+	if err := Set(t.Context(), "theme", "no-such-theme"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Set() should fail for a missing theme file, got %v", err)
+	}
+
+	themePath, _ := storage.ThemePath("test-set-theme")
+	defer os.Remove(themePath)
+	storage.EnsureDir(themePath)
+	if err := os.WriteFile(themePath, []byte(`{"id": "5"}`), 0644); err != nil {
+		t.Fatalf("Failed to write theme: %v", err)
+	}
+	if err := Set(t.Context(), "theme", "test-set-theme"); err != nil {
+		t.Fatalf("Set() failed for a custom theme: %v", err)
+	}
+	if value, _ := GetValue("theme"); value != "test-set-theme" {
+		t.Errorf("GetValue() = %q, want test-set-theme", value)
+	}
+}
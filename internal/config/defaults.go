@@ -13,11 +13,20 @@ func Default() *Config {
 	}
 }
 
-// ResolveFormat resolves the format from flag > config > default.
-// Priority: --format flag > config.default_format > "modern"
+// ResolveFormat resolves the format from flag > local file > config > default.
+// Priority: --format flag > .buyruk.toml format > config.default_format > "modern"
 func ResolveFormat(cmd *cobra.Command) string {
 	// Check flag first
 	format, _ := cmd.Flags().GetString("format")
+	if cmd.Flags().Changed("format") {
+		return format
+	}
+
+	// Check the directory's .buyruk.toml (an invalid one is reported by ResolveProject)
+	if local, err := LoadLocal(); err == nil && local != nil && local.Format != "" {
+		return local.Format
+	}
+
 	if format != "" {
 		return format
 	}
@@ -32,8 +41,8 @@ func ResolveFormat(cmd *cobra.Command) string {
 	return DefaultFormatModern
 }
 
-// ResolveProject resolves the project from flag > config > error.
-// Priority: --project flag > config.default_project > error
+// ResolveProject resolves the project from flag > local file > config > error.
+// Priority: --project flag > .buyruk.toml project > config.default_project > error
 func ResolveProject(cmd *cobra.Command) (string, error) {
 	// Check flag first
 	project, _ := cmd.Flags().GetString("project")
@@ -41,6 +50,15 @@ func ResolveProject(cmd *cobra.Command) (string, error) {
 		return project, nil
 	}
 
+	// Check the directory's .buyruk.toml
+	local, err := LoadLocal()
+	if err != nil {
+		return "", err
+	}
+	if local != nil && local.Project != "" {
+		return local.Project, nil
+	}
+
 	// Check config
 	cfg, err := Get()
 	if err == nil && cfg.DefaultProject != "" {
@@ -48,5 +66,5 @@ func ResolveProject(cmd *cobra.Command) (string, error) {
 	}
 
 	// No project specified
-	return "", fmt.Errorf("config: no project specified (use --project flag or set default_project in config)")
+	return "", fmt.Errorf("config: no project specified (use --project flag, a .buyruk.toml file, or set default_project in config)")
 }
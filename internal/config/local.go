@@ -0,0 +1,149 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LocalConfigFileName is the name of the per-directory config file. The
+// nearest one in the working directory or its parents pins the project and
+// format of commands run there, so each checkout can target its own project.
+const LocalConfigFileName = ".buyruk.toml"
+
+// LocalConfig is the content of a .buyruk.toml file.
+type LocalConfig struct {
+	Path    string // File the settings were read from
+	Project string // project = "CORE"
+	Format  string // format = "json"
+}
+
+// FindLocalConfig returns the path of the nearest .buyruk.toml in the working
+// directory or one of its parents.
+func FindLocalConfig() (string, bool) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+	for {
+		candidate := filepath.Join(dir, LocalConfigFileName)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// LoadLocal loads the nearest .buyruk.toml. It returns nil if there is none.
+func LoadLocal() (*LocalConfig, error) {
+	path, ok := FindLocalConfig()
+	if !ok {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to read %s: %w", path, err)
+	}
+	local, err := ParseLocalConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("config: invalid %s: %w", path, err)
+	}
+	local.Path = path
+	return local, nil
+}
+
+// ParseLocalConfig parses the content of a .buyruk.toml file: top-level
+// string keys, as in
+//
+//	# Issues of this checkout
+//	project = "CORE"
+//	format = "json"
+//
+// Tables and other value types are not supported.
+func ParseLocalConfig(data []byte) (*LocalConfig, error) {
+	local := &LocalConfig{}
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, rest, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = \"value\"", lineNo)
+		}
+		key = strings.TrimSpace(key)
+		value, err := parseTOMLString(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("line %d: %s is set more than once", lineNo, key)
+		}
+		seen[key] = true
+
+		switch key {
+		case "project":
+			if !isValidProjectKey(value) {
+				return nil, fmt.Errorf("line %d: invalid project key %q (must be uppercase alphanumeric or hyphen)", lineNo, value)
+			}
+			local.Project = value
+		case "format":
+			if !isValidFormat(value) {
+				return nil, fmt.Errorf("line %d: invalid format %q (must be modern, json, lson, ndjson, or yaml)", lineNo, value)
+			}
+			local.Format = value
+		default:
+			return nil, fmt.Errorf("line %d: unknown key %q (supported: project, format)", lineNo, key)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return local, nil
+}
+
+// parseTOMLString parses a TOML basic ("...") or literal ('...') string,
+// followed by an optional comment.
+func parseTOMLString(s string) (string, error) {
+	if strings.HasPrefix(s, "'") {
+		end := strings.Index(s[1:], "'")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string")
+		}
+		return s[1 : end+1], checkTOMLComment(s[end+2:])
+	}
+	if !strings.HasPrefix(s, `"`) {
+		return "", fmt.Errorf("value must be a quoted string")
+	}
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			value, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", fmt.Errorf("invalid string %s", s[:i+1])
+			}
+			return value, checkTOMLComment(s[i+1:])
+		}
+	}
+	return "", fmt.Errorf("unterminated string")
+}
+
+// checkTOMLComment checks that only a comment follows a value.
+func checkTOMLComment(s string) error {
+	if s = strings.TrimSpace(s); s != "" && !strings.HasPrefix(s, "#") {
+		return fmt.Errorf("unexpected %q after the value", s)
+	}
+	return nil
+}
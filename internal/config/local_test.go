@@ -0,0 +1,91 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestParseLocalConfig(t *testing.T) {
+	local, err := ParseLocalConfig([]byte(`# Issues of this checkout
+project = "CORE"   # pinned
+format = 'json'
+`))
+	if err != nil {
+		t.Fatalf("ParseLocalConfig() error = %v", err)
+	}
+	if local.Project != "CORE" || local.Format != "json" {
+		t.Errorf("ParseLocalConfig() = %+v, want CORE and json", local)
+	}
+
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"no value", "project", "expected key"},
+		{"unquoted", "project = CORE", "quoted string"},
+		{"unterminated", `project = "CORE`, "unterminated"},
+		{"trailing text", `project = "CORE" x`, "after the value"},
+		{"unknown key", `theme = "dark"`, "unknown key"},
+		{"invalid project", `project = "core"`, "invalid project key"},
+		{"invalid format", `format = "xml"`, "invalid format"},
+		{"repeated key", "project = \"A\"\nproject = \"B\"", "more than once"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseLocalConfig([]byte(tt.data)); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestResolve_LocalConfig(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, LocalConfigFileName), []byte("project = \"LOCAL\"\nformat = \"lson\"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", LocalConfigFileName, err)
+	}
+	sub := filepath.Join(root, "src", "app")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(sub)
+
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("project", "", "Project key")
+		cmd.Flags().String("format", "modern", "Output format")
+		return cmd
+	}
+
+	cmd := newCmd()
+	if project, err := ResolveProject(cmd); err != nil || project != "LOCAL" {
+		t.Errorf("ResolveProject() = %q, %v, want LOCAL from %s", project, err, LocalConfigFileName)
+	}
+	if format := ResolveFormat(cmd); format != "lson" {
+		t.Errorf("ResolveFormat() = %q, want lson from %s", format, LocalConfigFileName)
+	}
+
+	// Flags still win
+	cmd = newCmd()
+	cmd.Flags().Set("project", "FLAG")
+	cmd.Flags().Set("format", "modern")
+	if project, _ := ResolveProject(cmd); project != "FLAG" {
+		t.Errorf("ResolveProject() = %q, want FLAG", project)
+	}
+	if format := ResolveFormat(cmd); format != "modern" {
+		t.Errorf("ResolveFormat() = %q, want modern", format)
+	}
+
+	// An invalid file is reported
+	if err := os.WriteFile(filepath.Join(root, LocalConfigFileName), []byte("project = CORE\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ResolveProject(newCmd()); err == nil || !strings.Contains(err.Error(), LocalConfigFileName) {
+		t.Errorf("Expected an invalid %s to be reported, got %v", LocalConfigFileName, err)
+	}
+}
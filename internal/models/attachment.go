@@ -0,0 +1,59 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultMaxAttachmentSize is the largest file issue attach accepts in
+// projects that don't set max_attachment_size.
+const DefaultMaxAttachmentSize = 10 << 20
+
+// Attachment describes a file attached to an issue. The file itself is kept
+// in the project's attachments/<issue ID>/ directory under Name.
+type Attachment struct {
+	Name    string `json:"name"`               // File name, unique per issue
+	Size    int64  `json:"size"`               // Size in bytes
+	SHA256  string `json:"sha256"`             // Hex SHA-256 checksum of the content
+	AddedAt string `json:"added_at,omitempty"` // ISO 8601 timestamp
+	AddedBy string `json:"added_by,omitempty"` // Who attached the file
+}
+
+// ValidateAttachmentName checks that name can be used as the file name of an
+// attachment.
+func ValidateAttachmentName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("models: invalid attachment name %q (must be a file name without directories)", name)
+	}
+	return nil
+}
+
+// FindAttachment returns the attachment with the given name, or nil.
+func (i *Issue) FindAttachment(name string) *Attachment {
+	for n := range i.Attachments {
+		if i.Attachments[n].Name == name {
+			return &i.Attachments[n]
+		}
+	}
+	return nil
+}
+
+// SetAttachment adds an attachment to the issue, replacing the one with the
+// same name. Reports whether one was replaced.
+func (i *Issue) SetAttachment(a Attachment) bool {
+	if existing := i.FindAttachment(a.Name); existing != nil {
+		*existing = a
+		return true
+	}
+	i.Attachments = append(i.Attachments, a)
+	return false
+}
+
+// AttachmentSizeLimit returns the largest file issue attach accepts in the
+// project, in bytes.
+func (idx *ProjectIndex) AttachmentSizeLimit() int64 {
+	if idx.MaxAttachmentSize > 0 {
+		return idx.MaxAttachmentSize
+	}
+	return DefaultMaxAttachmentSize
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Away marks a user as away, optionally until a date and with a delegate who
+// takes over their work.
+type Away struct {
+	User      string `json:"user"`               // Required: user name
+	Until     string `json:"until,omitempty"`    // Optional: ISO 8601 timestamp the user is back; empty for no end date
+	Delegate  string `json:"delegate,omitempty"` // Optional: user who takes over while away
+	Note      string `json:"note,omitempty"`     // Optional: free-form note
+	CreatedAt string `json:"created_at,omitempty"`
+}
+
+// AwayRegistry lists the users marked away, shared by all projects.
+type AwayRegistry struct {
+	Users []Away `json:"users"`
+}
+
+// Validate validates an away entry.
+func (a *Away) Validate() error {
+	if err := ValidateUser(a.User); err != nil {
+		return err
+	}
+	if a.Delegate != "" {
+		if err := ValidateUser(a.Delegate); err != nil {
+			return err
+		}
+		if a.Delegate == a.User {
+			return fmt.Errorf("models: %s cannot be their own delegate", a.User)
+		}
+	}
+	if a.Until != "" {
+		if _, err := time.Parse(time.RFC3339, a.Until); err != nil {
+			return fmt.Errorf("models: invalid until timestamp %q", a.Until)
+		}
+	}
+	return nil
+}
+
+// IsAway reports whether the user is away at the given time.
+func (a *Away) IsAway(now time.Time) bool {
+	if a.Until == "" {
+		return true
+	}
+	until, err := time.Parse(time.RFC3339, a.Until)
+	return err != nil || now.Before(until)
+}
+
+// Find returns the away entry of a user, or nil if none.
+func (r *AwayRegistry) Find(user string) *Away {
+	for i := range r.Users {
+		if r.Users[i].User == user {
+			return &r.Users[i]
+		}
+	}
+	return nil
+}
+
+// Active returns the away entry of a user if they are away at the given time.
+func (r *AwayRegistry) Active(user string, now time.Time) *Away {
+	if a := r.Find(user); a != nil && a.IsAway(now) {
+		return a
+	}
+	return nil
+}
+
+// Set adds or replaces the away entry of a user.
+func (r *AwayRegistry) Set(away Away) {
+	if existing := r.Find(away.User); existing != nil {
+		*existing = away
+		return
+	}
+	r.Users = append(r.Users, away)
+}
+
+// Remove removes the away entry of a user. Returns false if there was none.
+func (r *AwayRegistry) Remove(user string) bool {
+	for i := range r.Users {
+		if r.Users[i].User == user {
+			r.Users = append(r.Users[:i], r.Users[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
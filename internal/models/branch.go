@@ -0,0 +1,68 @@
+package models
+
+import (
+	"strings"
+)
+
+// branchSlugMaxLen is the longest title slug used in a branch name.
+const branchSlugMaxLen = 40
+
+// slugReplacer transliterates letters that have a close ASCII equivalent.
+var slugReplacer = strings.NewReplacer(
+	"ç", "c", "ğ", "g", "ı", "i", "ö", "o", "ş", "s", "ü", "u",
+	"Ç", "c", "Ğ", "g", "İ", "i", "Ö", "o", "Ş", "s", "Ü", "u",
+	"ä", "a", "é", "e", "è", "e", "à", "a", "ß", "ss",
+)
+
+// Slugify turns a title into a lowercase, hyphen-separated ASCII slug of at
+// most 40 characters, cut at a word boundary: "Fix login (SSO)" becomes
+// "fix-login-sso".
+func Slugify(title string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range slugReplacer.Replace(title) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+			hyphen = false
+		case r >= 'A' && r <= 'Z':
+			b.WriteRune(r + 'a' - 'A')
+			hyphen = false
+		case !hyphen && b.Len() > 0:
+			b.WriteByte('-')
+			hyphen = true
+		}
+	}
+	slug := strings.TrimSuffix(b.String(), "-")
+	if len(slug) > branchSlugMaxLen {
+		slug = slug[:branchSlugMaxLen]
+		if i := strings.LastIndex(slug, "-"); i > 0 {
+			slug = slug[:i]
+		}
+	}
+	return slug
+}
+
+// BranchName builds a git branch name for an issue of a project from a
+// template with the placeholders {id} (CORE-12), {project} (CORE), {type}
+// (task), {prefix} ("fix" for bugs, "feat" otherwise), and {slug} (the
+// slugified title).
+func BranchName(template, projectKey string, issue *Issue) string {
+	prefix := "feat"
+	if strings.EqualFold(issue.Type, TypeBug) {
+		prefix = "fix"
+	}
+	slug := Slugify(issue.Title)
+	name := strings.NewReplacer(
+		"{id}", issue.ID,
+		"{project}", projectKey,
+		"{type}", strings.ToLower(issue.Type),
+		"{prefix}", prefix,
+		"{slug}", slug,
+	).Replace(template)
+	if slug == "" {
+		// An untitled slug leaves a dangling separator, as in "feat/CORE-12-"
+		name = strings.TrimRight(name, "-_/.")
+	}
+	return name
+}
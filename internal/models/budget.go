@@ -0,0 +1,125 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/timeparse"
+)
+
+// DefaultBudgetWarnings are the percentages of a budget that warn when
+// crossed, for budgets without their own.
+var DefaultBudgetWarnings = []int{80, 100}
+
+// TimeEntry is working time logged against an issue.
+type TimeEntry struct {
+	Duration string `json:"duration"`            // Required: Working time, e.g. "1h30m", "2d" (a day is 8 hours)
+	User     string `json:"user,omitempty"`      // Optional: Who did the work
+	Note     string `json:"note,omitempty"`      // Optional: What the time was spent on
+	LoggedAt string `json:"logged_at,omitempty"` // ISO 8601 timestamp
+}
+
+// Budget is the optional cost budget of an epic. Logged time counts against
+// Hours directly and against Amount at Rate per hour.
+type Budget struct {
+	Hours    float64 `json:"hours,omitempty"`    // Optional: Budgeted hours
+	Amount   float64 `json:"amount,omitempty"`   // Optional: Budgeted amount of money
+	Rate     float64 `json:"rate,omitempty"`     // Optional: Hourly rate billed for logged time
+	Currency string  `json:"currency,omitempty"` // Optional: Currency of Amount and Rate, e.g. "USD"
+	Warnings []int   `json:"warnings,omitempty"` // Optional: Percentages that warn when crossed (default 80, 100)
+}
+
+// Validate validates a time entry.
+func (e *TimeEntry) Validate() error {
+	d, err := timeparse.ParseWorkDuration(e.Duration)
+	if err != nil {
+		return fmt.Errorf("models: invalid logged time: %w", err)
+	}
+	if d <= 0 {
+		return fmt.Errorf("models: logged time must be positive")
+	}
+	if e.User != "" {
+		if err := ValidateUser(e.User); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoggedTime returns the working time logged against the issue. Entries that
+// do not parse are skipped.
+func (i *Issue) LoggedTime() time.Duration {
+	var total time.Duration
+	for _, entry := range i.TimeEntries {
+		if d, err := timeparse.ParseWorkDuration(entry.Duration); err == nil {
+			total += d
+		}
+	}
+	return total
+}
+
+// Validate validates a budget.
+func (b *Budget) Validate() error {
+	for _, v := range []struct {
+		name  string
+		value float64
+	}{{"hours", b.Hours}, {"amount", b.Amount}, {"rate", b.Rate}} {
+		if v.value < 0 || math.IsNaN(v.value) || math.IsInf(v.value, 0) {
+			return fmt.Errorf("models: invalid budget %s %v", v.name, v.value)
+		}
+	}
+	if b.Amount > 0 && b.Rate == 0 {
+		return fmt.Errorf("models: a budget amount needs an hourly rate")
+	}
+	for _, p := range b.Warnings {
+		if p <= 0 {
+			return fmt.Errorf("models: invalid budget warning %d%% (must be positive)", p)
+		}
+	}
+	return nil
+}
+
+// IsZero reports whether the budget sets nothing.
+func (b *Budget) IsZero() bool {
+	return b == nil || b.Hours == 0 && b.Amount == 0 && b.Rate == 0 && b.Currency == "" && len(b.Warnings) == 0
+}
+
+// WarningLevels returns the budget's warning percentages in ascending order.
+func (b *Budget) WarningLevels() []int {
+	if len(b.Warnings) == 0 {
+		return DefaultBudgetWarnings
+	}
+	levels := slices.Clone(b.Warnings)
+	slices.Sort(levels)
+	return slices.Compact(levels)
+}
+
+// Burn returns how much of the budget the logged time uses, as a percentage
+// of budgeted hours or of budgeted amount, whichever is higher. It is 0 when
+// the budget sets neither.
+func (b *Budget) Burn(logged time.Duration) float64 {
+	hours := logged.Hours()
+	var burn float64
+	if b.Hours > 0 {
+		burn = hours * 100 / b.Hours
+	}
+	if b.Amount > 0 {
+		burn = max(burn, hours*b.Rate*100/b.Amount)
+	}
+	return burn
+}
+
+// CrossedWarning returns the highest warning level crossed going from
+// before to after logged time, or 0 if none was crossed.
+func (b *Budget) CrossedWarning(before, after time.Duration) int {
+	from, to := b.Burn(before), b.Burn(after)
+	crossed := 0
+	for _, level := range b.WarningLevels() {
+		if from < float64(level) && to >= float64(level) {
+			crossed = level
+		}
+	}
+	return crossed
+}
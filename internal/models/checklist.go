@@ -0,0 +1,19 @@
+package models
+
+// ChecklistItem is an item of the checklist of an issue.
+type ChecklistItem struct {
+	Text   string `json:"text"`              // Required: What is to be done
+	Done   bool   `json:"done,omitempty"`    // Whether the item is checked off
+	DoneAt string `json:"done_at,omitempty"` // ISO 8601 timestamp the item was checked off
+}
+
+// ChecklistProgress returns the number of checked off and all checklist
+// items of the issue.
+func (i *Issue) ChecklistProgress() (done, total int) {
+	for _, item := range i.Checklist {
+		if item.Done {
+			done++
+		}
+	}
+	return done, len(i.Checklist)
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// Components are the registered areas of a project's codebase that issues
+// can be filed under.
+type Components struct {
+	Components []Component `json:"components"`
+}
+
+// Component is an area of a project, such as "ui" or "api/auth".
+type Component struct {
+	Name        string `json:"name"`                  // Component name, e.g. "ui"
+	Description string `json:"description,omitempty"` // Optional: What the component covers
+	Owner       string `json:"owner,omitempty"`       // Optional: User new issues of the component are assigned to
+}
+
+var componentNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9._/-]*$`)
+
+// ValidateComponentName validates a component name
+func ValidateComponentName(name string) error {
+	if !componentNameRegex.MatchString(name) {
+		return fmt.Errorf("models: invalid component %q (use lowercase letters, digits, '.', '_', '/', and '-', e.g. api/auth)", name)
+	}
+	return nil
+}
+
+// Find returns the component with a name, or nil.
+func (c *Components) Find(name string) *Component {
+	for i := range c.Components {
+		if c.Components[i].Name == name {
+			return &c.Components[i]
+		}
+	}
+	return nil
+}
+
+// Set adds a component or replaces the component with the same name, keeping
+// components ordered by name.
+func (c *Components) Set(component Component) {
+	if existing := c.Find(component.Name); existing != nil {
+		*existing = component
+	} else {
+		c.Components = append(c.Components, component)
+	}
+	sort.SliceStable(c.Components, func(i, j int) bool { return c.Components[i].Name < c.Components[j].Name })
+}
+
+// Validate validates the Components struct
+func (c *Components) Validate() error {
+	seen := map[string]bool{}
+	for _, component := range c.Components {
+		if err := ValidateComponentName(component.Name); err != nil {
+			return err
+		}
+		if seen[component.Name] {
+			return fmt.Errorf("models: component %q is defined more than once", component.Name)
+		}
+		seen[component.Name] = true
+		if component.Owner != "" {
+			if err := ValidateUser(component.Owner); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
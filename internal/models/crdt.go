@@ -0,0 +1,318 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// CRDTState is the replication metadata of an issue in a project in CRDT
+// mode. It lets two copies of an issue edited on different machines merge
+// without losing either side's changes:
+//
+//   - Plain fields are last-writer-wins registers: the clock of each field's
+//     last change decides which copy's value is kept.
+//   - List fields (labels, PRs, blockers, links, comments, time entries) are
+//     observed-remove sets: each add gets a unique tag, and a remove only
+//     cancels the tags it has seen, so an element added on one machine
+//     survives a concurrent remove on the other.
+//
+// Clocks are "<UTC time with nanoseconds>@<replica>", which sort by time and
+// then by replica.
+type CRDTState struct {
+	Fields map[string]string                   `json:"fields,omitempty"` // Field name to clock of its last change
+	Sets   map[string]map[string]*ORSetElement `json:"sets,omitempty"`   // List field to element (as JSON) to its tags
+}
+
+// ORSetElement holds the add tags of a set element and the ones removed.
+// The element is present while an add tag has not been removed.
+type ORSetElement struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// crdtSetFields are the issue fields merged as observed-remove sets.
+var crdtSetFields = []string{"labels", "prs", "blocked_by", "links", "comments", "time_entries", "history", "commits"}
+
+// crdtStateField is the JSON name of the CRDT state in an issue.
+const crdtStateField = "crdt"
+
+// clockLayout is a fixed-width UTC layout, so clocks compare as strings.
+const clockLayout = "2006-01-02T15:04:05.000000000Z"
+
+// seedClock tags set elements written before the issue had CRDT state. Every
+// replica seeds the same tag, as they all observed the same add.
+const seedClock = "0000-00-00T00:00:00.000000000Z@"
+
+// NewClock returns the clock of a change made at t by a replica.
+func NewClock(t time.Time, replica string) string {
+	return t.UTC().Format(clockLayout) + "@" + replica
+}
+
+// present reports whether a set element has an add tag that was not removed.
+func (e *ORSetElement) present() bool {
+	for _, tag := range e.Added {
+		if !slices.Contains(e.Removed, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// StampIssue records in updated.CRDT the changes from old (nil for a new
+// issue) to updated, made at clock. If updated already carries CRDT state
+// other than old's (a merge result or a restored copy), it is kept as is.
+func StampIssue(old, updated *Issue, clock string) error {
+	var state *CRDTState
+	if old != nil {
+		state = old.CRDT
+	}
+	if updated.CRDT != nil && !crdtStateEqual(updated.CRDT, state) {
+		return nil
+	}
+
+	before, err := issueFields(old)
+	if err != nil {
+		return err
+	}
+	after, err := issueFields(updated)
+	if err != nil {
+		return err
+	}
+
+	stamped := cloneCRDTState(state)
+	for field := range unionKeys(before, after) {
+		if slices.Contains(crdtSetFields, field) {
+			oldKeys, err := setElementKeys(before[field])
+			if err != nil {
+				return fmt.Errorf("models: invalid %s: %w", field, err)
+			}
+			newKeys, err := setElementKeys(after[field])
+			if err != nil {
+				return fmt.Errorf("models: invalid %s: %w", field, err)
+			}
+			elements := stamped.set(field)
+			for _, key := range oldKeys {
+				seedElement(elements, key)
+			}
+			for _, key := range newKeys {
+				if !slices.Contains(oldKeys, key) {
+					addTag(elements, key, clock)
+				}
+			}
+			for _, key := range oldKeys {
+				if !slices.Contains(newKeys, key) {
+					e := elements[key]
+					e.Removed = appendUnique(e.Removed, e.Added...)
+				}
+			}
+			continue
+		}
+		if !bytes.Equal(before[field], after[field]) {
+			stamped.Fields[field] = clock
+		}
+	}
+
+	updated.CRDT = stamped
+	return nil
+}
+
+// MergeIssues merges two copies of an issue. Plain fields take the value of
+// the copy that changed them last (a on ties), and list fields contain the
+// elements present in the union of both copies' sets.
+func MergeIssues(a, b *Issue) (*Issue, error) {
+	fieldsA, err := issueFields(a)
+	if err != nil {
+		return nil, err
+	}
+	fieldsB, err := issueFields(b)
+	if err != nil {
+		return nil, err
+	}
+	stateA, stateB := seededState(a.CRDT, fieldsA), seededState(b.CRDT, fieldsB)
+
+	merged := cloneCRDTState(stateA)
+	for field, clock := range stateB.Fields {
+		if clock > merged.Fields[field] {
+			merged.Fields[field] = clock
+		}
+	}
+	for field, elements := range stateB.Sets {
+		target := merged.set(field)
+		for key, e := range elements {
+			if existing, ok := target[key]; ok {
+				existing.Added = appendUnique(existing.Added, e.Added...)
+				existing.Removed = appendUnique(existing.Removed, e.Removed...)
+			} else {
+				target[key] = &ORSetElement{Added: slices.Clone(e.Added), Removed: slices.Clone(e.Removed)}
+			}
+		}
+	}
+
+	result := map[string]json.RawMessage{}
+	for field := range unionKeys(fieldsA, fieldsB) {
+		if slices.Contains(crdtSetFields, field) {
+			keysA, _ := setElementKeys(fieldsA[field])
+			keysB, _ := setElementKeys(fieldsB[field])
+			var present []json.RawMessage
+			for _, key := range appendUnique(keysA, keysB...) {
+				if e := merged.Sets[field][key]; e != nil && e.present() {
+					present = append(present, json.RawMessage(key))
+				}
+			}
+			if len(present) > 0 {
+				data, err := json.Marshal(present)
+				if err != nil {
+					return nil, fmt.Errorf("models: failed to merge %s: %w", field, err)
+				}
+				result[field] = data
+			}
+			continue
+		}
+		value, ok := fieldsA[field]
+		if stateB.Fields[field] > stateA.Fields[field] {
+			value, ok = fieldsB[field]
+		}
+		if ok {
+			result[field] = value
+		}
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("models: failed to merge issue: %w", err)
+	}
+	var issue Issue
+	if err := json.Unmarshal(data, &issue); err != nil {
+		return nil, fmt.Errorf("models: failed to merge issue: %w", err)
+	}
+	issue.CRDT = merged
+	return &issue, nil
+}
+
+// issueFields returns the JSON fields of an issue, compacted, without its
+// CRDT state. A nil issue has no fields.
+func issueFields(issue *Issue) (map[string]json.RawMessage, error) {
+	fields := map[string]json.RawMessage{}
+	if issue == nil {
+		return fields, nil
+	}
+	data, err := json.Marshal(issue)
+	if err != nil {
+		return nil, fmt.Errorf("models: failed to marshal issue: %w", err)
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("models: failed to read issue fields: %w", err)
+	}
+	delete(fields, crdtStateField)
+	return fields, nil
+}
+
+// setElementKeys returns the elements of a JSON array as compact JSON, in order.
+func setElementKeys(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var elements []json.RawMessage
+	if err := json.Unmarshal(raw, &elements); err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(elements))
+	for _, element := range elements {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, element); err != nil {
+			return nil, err
+		}
+		keys = appendUnique(keys, buf.String())
+	}
+	return keys, nil
+}
+
+// seededState returns a copy of state in which every list element of fields
+// has tags, seeding the ones written before the issue had CRDT state.
+func seededState(state *CRDTState, fields map[string]json.RawMessage) *CRDTState {
+	seeded := cloneCRDTState(state)
+	for _, field := range crdtSetFields {
+		keys, _ := setElementKeys(fields[field])
+		for _, key := range keys {
+			seedElement(seeded.set(field), key)
+		}
+	}
+	return seeded
+}
+
+// seedElement gives an element without tags the seed tag.
+func seedElement(elements map[string]*ORSetElement, key string) {
+	if e, ok := elements[key]; !ok || len(e.Added) == 0 {
+		elements[key] = &ORSetElement{Added: []string{seedClock}}
+	}
+}
+
+// addTag adds a tag to a set element.
+func addTag(elements map[string]*ORSetElement, key, tag string) {
+	e, ok := elements[key]
+	if !ok {
+		e = &ORSetElement{}
+		elements[key] = e
+	}
+	e.Added = appendUnique(e.Added, tag)
+}
+
+// set returns the elements of a list field, creating the map if needed.
+func (s *CRDTState) set(field string) map[string]*ORSetElement {
+	elements, ok := s.Sets[field]
+	if !ok {
+		elements = map[string]*ORSetElement{}
+		s.Sets[field] = elements
+	}
+	return elements
+}
+
+// cloneCRDTState returns a deep copy of state with non-nil maps.
+func cloneCRDTState(state *CRDTState) *CRDTState {
+	clone := &CRDTState{Fields: map[string]string{}, Sets: map[string]map[string]*ORSetElement{}}
+	if state == nil {
+		return clone
+	}
+	for field, clock := range state.Fields {
+		clone.Fields[field] = clock
+	}
+	for field, elements := range state.Sets {
+		target := clone.set(field)
+		for key, e := range elements {
+			target[key] = &ORSetElement{Added: slices.Clone(e.Added), Removed: slices.Clone(e.Removed)}
+		}
+	}
+	return clone
+}
+
+// crdtStateEqual reports whether two CRDT states are the same.
+func crdtStateEqual(a, b *CRDTState) bool {
+	dataA, errA := json.Marshal(a)
+	dataB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(dataA, dataB)
+}
+
+// unionKeys returns the keys present in either map.
+func unionKeys(a, b map[string]json.RawMessage) map[string]bool {
+	keys := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	return keys
+}
+
+// appendUnique appends the values not already in list.
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		if !slices.Contains(list, v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GenerateEpicID returns the project-scoped ID of an epic (CORE-E1).
+func GenerateEpicID(projectKey string, sequence int) string {
+	return fmt.Sprintf("%s-E%d", projectKey, sequence)
+}
+
+// ParseEpicID parses a project-scoped epic ID (CORE-E1) into its project key
+// and sequence number. Custom epic IDs and the unscoped IDs of older projects
+// (E-1) do not parse.
+func ParseEpicID(id string) (string, int, error) {
+	key, suffix, ok := cutLastHyphen(id)
+	digits, scoped := strings.CutPrefix(suffix, "E")
+	if !ok || key == "" || !scoped || !isDigits(digits) {
+		return "", 0, fmt.Errorf("models: invalid epic ID %q (expected KEY-E<number>)", id)
+	}
+	sequence, err := strconv.Atoi(digits)
+	if err != nil {
+		return "", 0, fmt.Errorf("models: invalid epic ID %q: %w", id, err)
+	}
+	return key, sequence, nil
+}
+
+// LegacyEpicSequence returns the sequence number of an unscoped epic ID
+// generated before epic IDs carried their project key (E-1).
+func LegacyEpicSequence(id string) (int, bool) {
+	digits, ok := strings.CutPrefix(id, "E-")
+	if !ok || !isDigits(digits) {
+		return 0, false
+	}
+	sequence, err := strconv.Atoi(digits)
+	return sequence, err == nil
+}
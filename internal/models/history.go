@@ -0,0 +1,79 @@
+package models
+
+import (
+	"slices"
+	"strings"
+)
+
+// History event types.
+const (
+	HistoryStatus     = "status"      // Status changed From → To
+	HistoryPRAdded    = "pr_added"    // PR Detail was linked
+	HistoryPRRemoved  = "pr_removed"  // PR Detail was unlinked
+	HistorySync       = "sync"        // Changes arrived from another copy through command Detail
+	HistoryMergedInto = "merged_into" // The issue was merged into issue Detail as a duplicate
+	HistoryMergedFrom = "merged_from" // Duplicate issue Detail was merged into the issue
+	HistoryEscalated  = "escalated"   // The priority was raised to Detail, or the issue flagged when Detail is empty, after waiting too long
+)
+
+// HistoryEvent records a change of an issue that its other fields do not
+// keep a trace of. Comments and time entries carry their own timestamps and
+// are not repeated here.
+type HistoryEvent struct {
+	At     string `json:"at"`               // ISO 8601 timestamp
+	Type   string `json:"type"`             // One of the History* types
+	From   string `json:"from,omitempty"`   // Previous status
+	To     string `json:"to,omitempty"`     // New status
+	Detail string `json:"detail,omitempty"` // PR URL, the command a sync came through, or the other issue of a merge
+	By     string `json:"by,omitempty"`     // Who made the change
+}
+
+// ActorName returns the name of who made a change, given as "Name <email>",
+// or the email when there is no name.
+func ActorName(actor string) string {
+	if name, _, ok := strings.Cut(actor, " <"); ok {
+		return name
+	}
+	return strings.Trim(actor, "<>")
+}
+
+// RecordHistory appends to updated.History the status and PR changes made
+// from old, which is nil for a new issue.
+func RecordHistory(old, updated *Issue, at string) {
+	if old == nil {
+		return
+	}
+	if old.Status != updated.Status {
+		updated.History = append(updated.History, HistoryEvent{At: at, Type: HistoryStatus, From: old.Status, To: updated.Status})
+	}
+	for _, pr := range updated.PRs {
+		if !slices.Contains(old.PRs, pr) {
+			updated.History = append(updated.History, HistoryEvent{At: at, Type: HistoryPRAdded, Detail: pr})
+		}
+	}
+	for _, pr := range old.PRs {
+		if !slices.Contains(updated.PRs, pr) {
+			updated.History = append(updated.History, HistoryEvent{At: at, Type: HistoryPRRemoved, Detail: pr})
+		}
+	}
+}
+
+// RecordSyncHistory merges the history of an issue arriving from another
+// copy with the local one, and records the arrival as a sync event through
+// source (e.g. "sync review"). The sync event carries the status change, if
+// any. Events are kept in time order.
+func RecordSyncHistory(old, updated *Issue, at, source string) {
+	merged := slices.Clone(old.History)
+	for _, event := range updated.History {
+		if !slices.Contains(merged, event) {
+			merged = append(merged, event)
+		}
+	}
+	slices.SortStableFunc(merged, func(a, b HistoryEvent) int { return strings.Compare(a.At, b.At) })
+
+	event := HistoryEvent{At: at, Type: HistorySync, Detail: source}
+	if old.Status != updated.Status {
+		event.From, event.To = old.Status, updated.Status
+	}
+	updated.History = append(merged, event)
+}
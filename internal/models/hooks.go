@@ -0,0 +1,108 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Hook events.
+const (
+	EventIssueCreated       = "issue.created"
+	EventIssueUpdated       = "issue.updated"
+	EventIssueStatusChanged = "issue.status_changed"
+	EventIssueDeleted       = "issue.deleted"
+)
+
+// HookEvents lists the events hooks can subscribe to.
+var HookEvents = []string{EventIssueCreated, EventIssueUpdated, EventIssueStatusChanged, EventIssueDeleted}
+
+// DefaultHookTimeout is how long a hook may run when it sets no timeout.
+const DefaultHookTimeout = 10 * time.Second
+
+// HookConfig is the hook configuration of a project (hooks/KEY.json in the
+// config directory).
+type HookConfig struct {
+	Hooks []Hook `json:"hooks"`
+}
+
+// Hook runs a shell command or POSTs to a URL when one of its events happens.
+// The event payload is passed as JSON: on stdin to commands, as the request
+// body to URLs.
+type Hook struct {
+	Name    string            `json:"name,omitempty"`    // Optional: Shown in warnings and hooks list
+	Events  []string          `json:"events"`            // Required: Events to fire on; "issue.*" or "*" for all
+	Command string            `json:"command,omitempty"` // Shell command (one of Command and URL is required)
+	URL     string            `json:"url,omitempty"`     // HTTP(S) URL to POST to
+	Headers map[string]string `json:"headers,omitempty"` // Optional: Extra request headers for URL hooks
+	Timeout string            `json:"timeout,omitempty"` // Optional: e.g. "5s" (default 10s)
+}
+
+// Validate validates the hook configuration.
+func (c *HookConfig) Validate() error {
+	for i := range c.Hooks {
+		if err := c.Hooks[i].Validate(); err != nil {
+			return fmt.Errorf("models: hook %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Validate validates a hook.
+func (h *Hook) Validate() error {
+	if (h.Command == "") == (h.URL == "") {
+		return fmt.Errorf("exactly one of command and url is required")
+	}
+	if h.URL != "" {
+		u, err := url.Parse(h.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid url %q", h.URL)
+		}
+	}
+	if len(h.Events) == 0 {
+		return fmt.Errorf("at least one event is required")
+	}
+	for _, event := range h.Events {
+		if event != "*" && event != "issue.*" && !slices.Contains(HookEvents, event) {
+			return fmt.Errorf("unknown event %q (allowed: %s, issue.*, *)", event, strings.Join(HookEvents, ", "))
+		}
+	}
+	if h.Timeout != "" {
+		if d, err := time.ParseDuration(h.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("invalid timeout %q", h.Timeout)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the hook fires on an event.
+func (h *Hook) Matches(event string) bool {
+	for _, e := range h.Events {
+		if e == event || e == "*" || (e == "issue.*" && strings.HasPrefix(event, "issue.")) {
+			return true
+		}
+	}
+	return false
+}
+
+// TimeoutDuration returns how long the hook may run.
+func (h *Hook) TimeoutDuration() time.Duration {
+	if d, err := time.ParseDuration(h.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return DefaultHookTimeout
+}
+
+// Label returns the hook's name, or its command or URL.
+func (h *Hook) Label() string {
+	switch {
+	case h.Name != "":
+		return h.Name
+	case h.Command != "":
+		return h.Command
+	default:
+		return h.URL
+	}
+}
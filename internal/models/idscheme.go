@@ -0,0 +1,95 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ID schemes of a project: how the part of an issue ID after the project key
+// is formed. The sequence number counts on in every scheme but ulid, so IDs
+// stay unique and ordered.
+const (
+	IDSchemeSequence = "sequence" // CORE-12
+	IDSchemePadded   = "padded"   // CORE-0012
+	IDSchemeDate     = "date"     // CORE-2024-012, the year the issue was created
+	IDSchemeULID     = "ulid"     // CORE-01J0ZQ5B0M8N1XK3V7T2R9Y6WD
+)
+
+// ValidIDSchemes lists the supported ID schemes.
+var ValidIDSchemes = []string{IDSchemeSequence, IDSchemePadded, IDSchemeDate, IDSchemeULID}
+
+const (
+	paddedSequenceWidth = 4 // Digits of a padded sequence number
+	dateSequenceWidth   = 3 // Digits of the sequence number after the year
+)
+
+// IsValidIDScheme reports whether scheme is a supported ID scheme.
+func IsValidIDScheme(scheme string) bool {
+	return slices.Contains(ValidIDSchemes, scheme)
+}
+
+// FormatIssueID returns the ID of a new issue of a project under an ID scheme.
+// The date scheme uses the year of now, and the ulid scheme ignores sequence.
+func FormatIssueID(scheme, projectKey string, sequence int, now time.Time) (string, error) {
+	switch scheme {
+	case "", IDSchemeSequence:
+		return GenerateIssueID(projectKey, sequence), nil
+	case IDSchemePadded:
+		return fmt.Sprintf("%s-%0*d", projectKey, paddedSequenceWidth, sequence), nil
+	case IDSchemeDate:
+		return fmt.Sprintf("%s-%04d-%0*d", projectKey, now.Year(), dateSequenceWidth, sequence), nil
+	case IDSchemeULID:
+		ulid, err := NewULID(now)
+		if err != nil {
+			return "", err
+		}
+		return projectKey + "-" + ulid, nil
+	}
+	return "", fmt.Errorf("models: invalid ID scheme %q", scheme)
+}
+
+// NewIssueID returns the ID of a new issue of the project under its ID scheme.
+func (idx *ProjectIndex) NewIssueID(projectKey string, sequence int, now time.Time) (string, error) {
+	return FormatIssueID(idx.IDScheme, projectKey, sequence, now)
+}
+
+// IsIssueIDOf reports whether id can be the ID of an issue of a project: its
+// key is projectKey, or it is a date-based ID of the project (see
+// DateIssueIDKey).
+func IsIssueIDOf(id, projectKey string) bool {
+	if key, _, err := ParseIssueID(id); err == nil && key == projectKey {
+		return true
+	}
+	key, ok := DateIssueIDKey(id)
+	return ok && key == projectKey
+}
+
+// YearKeyBase returns the key without its year of a project key ending in a
+// year (CORE for CORE-2024), whose issue IDs read as date-based IDs of that
+// project (CORE-2024-101). It returns false for other keys.
+func YearKeyBase(key string) (string, bool) {
+	base, year, ok := cutLastHyphen(key)
+	if !ok || base == "" || !isYear(year) {
+		return "", false
+	}
+	return base, true
+}
+
+// isYear reports whether s is a four-digit year.
+func isYear(s string) bool {
+	return len(s) == 4 && isDigits(s)
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,179 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Link types between issues. blocked_by is stored in Issue.BlockedBy and
+// parent_of in the child's ParentID; the other types are stored in Issue.Links.
+const (
+	LinkBlockedBy    = "blocked_by"
+	LinkBlocks       = "blocks"
+	LinkRelatesTo    = "relates_to"
+	LinkDuplicates   = "duplicates"
+	LinkDuplicatedBy = "duplicated_by"
+	LinkParentOf     = "parent_of"
+)
+
+// LinkTypes lists the link types accepted by `issue link`, in display order.
+var LinkTypes = []string{LinkBlockedBy, LinkBlocks, LinkRelatesTo, LinkDuplicates, LinkDuplicatedBy, LinkParentOf}
+
+// linkReverse maps each link type to the type seen from the other issue.
+var linkReverse = map[string]string{
+	LinkBlockedBy:    LinkBlocks,
+	LinkBlocks:       LinkBlockedBy,
+	LinkRelatesTo:    LinkRelatesTo,
+	LinkDuplicates:   LinkDuplicatedBy,
+	LinkDuplicatedBy: LinkDuplicates,
+}
+
+// Link is a typed relation from an issue to another issue.
+type Link struct {
+	Type string `json:"type"` // One of the Link* types other than blocked_by and parent_of
+	ID   string `json:"id"`   // Linked issue ID
+}
+
+// ValidateLinkType checks that t is a known link type.
+func ValidateLinkType(t string) error {
+	if !slices.Contains(LinkTypes, t) {
+		return fmt.Errorf("models: invalid link type %q (valid: %v)", t, LinkTypes)
+	}
+	return nil
+}
+
+// ReverseLinkType returns the type of the link seen from the linked issue
+// ("blocks" for "blocked_by"). parent_of has no stored reverse.
+func ReverseLinkType(t string) string {
+	return linkReverse[t]
+}
+
+// AddLink adds a typed link to the issue. blocked_by links are added to BlockedBy.
+func (i *Issue) AddLink(linkType, issueID string) {
+	if linkType == LinkBlockedBy {
+		i.AddDependency(issueID)
+		return
+	}
+	link := Link{Type: linkType, ID: issueID}
+	if !slices.Contains(i.Links, link) {
+		i.Links = append(i.Links, link)
+	}
+}
+
+// RemoveLink removes a typed link from the issue. blocked_by links are removed from BlockedBy.
+func (i *Issue) RemoveLink(linkType, issueID string) {
+	if linkType == LinkBlockedBy {
+		i.RemoveDependency(issueID)
+		return
+	}
+	i.Links = slices.DeleteFunc(i.Links, func(l Link) bool { return l.Type == linkType && l.ID == issueID })
+}
+
+// LinkedIDs returns the IDs linked with the given type, including BlockedBy for blocked_by.
+func (i *Issue) LinkedIDs(linkType string) []string {
+	if linkType == LinkBlockedBy {
+		return i.BlockedBy
+	}
+	var ids []string
+	for _, link := range i.Links {
+		if link.Type == linkType {
+			ids = append(ids, link.ID)
+		}
+	}
+	return ids
+}
+
+// RenameLinks rewrites the IDs of dependencies and typed links using rename,
+// which returns the new ID of an issue (or the ID unchanged). Reports whether
+// anything changed.
+func (i *Issue) RenameLinks(rename func(id string) string) bool {
+	changed := false
+	for n, dep := range i.BlockedBy {
+		if renamed := rename(dep); renamed != dep {
+			i.BlockedBy[n] = renamed
+			changed = true
+		}
+	}
+	for n, link := range i.Links {
+		if renamed := rename(link.ID); renamed != link.ID {
+			i.Links[n].ID = renamed
+			changed = true
+		}
+	}
+	return changed
+}
+
+// LinkIssues links issue to other with the given type and adds the reverse
+// link to other, so both sides stay consistent. parent_of is not handled here.
+func LinkIssues(issue, other *Issue, linkType string) {
+	issue.AddLink(linkType, other.ID)
+	if reverse := ReverseLinkType(linkType); reverse != "" {
+		other.AddLink(reverse, issue.ID)
+	}
+}
+
+// UnlinkIssues removes a link added by LinkIssues from both issues.
+// other may be nil if the linked issue no longer exists.
+func UnlinkIssues(issue, other *Issue, otherID, linkType string) {
+	issue.RemoveLink(linkType, otherID)
+	if other == nil {
+		return
+	}
+	if reverse := ReverseLinkType(linkType); reverse != "" {
+		other.RemoveLink(reverse, issue.ID)
+	}
+}
+
+// MoveLinks moves the dependencies and typed links of issue onto target, as
+// when issue is merged into target, and points the reverse links of the
+// linked issues (looked up in others by ID) at target. Links between the two
+// issues are dropped, and links to issues missing from others are only
+// removed from issue.
+func MoveLinks(issue, target *Issue, others map[string]*Issue) {
+	move := func(linkType, otherID string) {
+		if otherID == target.ID {
+			UnlinkIssues(issue, target, otherID, linkType)
+			return
+		}
+		other := others[otherID]
+		UnlinkIssues(issue, other, otherID, linkType)
+		if other != nil {
+			LinkIssues(target, other, linkType)
+		}
+	}
+	for _, dependencyID := range slices.Clone(issue.BlockedBy) {
+		move(LinkBlockedBy, dependencyID)
+	}
+	for _, link := range slices.Clone(issue.Links) {
+		move(link.Type, link.ID)
+	}
+}
+
+// LinkedIssueIDs returns the IDs of all issues the issue depends on or links to,
+// without duplicates, in the order they appear.
+func (i *Issue) LinkedIssueIDs() []string {
+	var ids []string
+	for _, id := range i.BlockedBy {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	for _, link := range i.Links {
+		if !slices.Contains(ids, link.ID) {
+			ids = append(ids, link.ID)
+		}
+	}
+	return ids
+}
+
+// RemoveLinksTo removes every dependency and typed link to the given issue,
+// as when that issue is deleted. Reports whether anything was removed.
+func (i *Issue) RemoveLinksTo(issueID string) bool {
+	before := len(i.BlockedBy) + len(i.Links)
+	i.BlockedBy = slices.DeleteFunc(i.BlockedBy, func(id string) bool { return id == issueID })
+	i.Links = slices.DeleteFunc(i.Links, func(l Link) bool { return l.ID == issueID })
+	if len(i.BlockedBy) == 0 {
+		i.BlockedSince = ""
+	}
+	return len(i.BlockedBy)+len(i.Links) != before
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Milestone statuses
+const (
+	MilestoneOpen   = "open"
+	MilestoneClosed = "closed"
+)
+
+// Milestones are the releases of a project.
+type Milestones struct {
+	Milestones []Milestone `json:"milestones"`
+}
+
+// Milestone is a release, such as a version, that issues ship in through
+// their fix version. Unlike an epic, a milestone can take issues of any epic.
+type Milestone struct {
+	ID          string `json:"id"`                    // Milestone ID, e.g. "v1.2"
+	Description string `json:"description,omitempty"` // Optional: What the release is about
+	Due         string `json:"due,omitempty"`         // Optional: Planned release day, "YYYY-MM-DD"
+	Status      string `json:"status"`                // "open" or "closed"
+	CreatedAt   string `json:"created_at,omitempty"`  // ISO 8601 timestamp
+	ClosedAt    string `json:"closed_at,omitempty"`   // ISO 8601 timestamp of when it was closed
+}
+
+var milestoneIDRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateMilestoneID validates a milestone ID
+func ValidateMilestoneID(id string) error {
+	if !milestoneIDRegex.MatchString(id) {
+		return fmt.Errorf("models: invalid milestone ID %q (use letters, digits, '.', '_', and '-', e.g. v1.2)", id)
+	}
+	return nil
+}
+
+// Find returns the milestone with an ID, or nil.
+func (m *Milestones) Find(id string) *Milestone {
+	for i := range m.Milestones {
+		if m.Milestones[i].ID == id {
+			return &m.Milestones[i]
+		}
+	}
+	return nil
+}
+
+// Set adds a milestone or replaces the milestone with the same ID. New
+// milestones are added last.
+func (m *Milestones) Set(milestone Milestone) {
+	if existing := m.Find(milestone.ID); existing != nil {
+		*existing = milestone
+		return
+	}
+	m.Milestones = append(m.Milestones, milestone)
+}
+
+// IsClosed reports whether the milestone has been closed.
+func (m *Milestone) IsClosed() bool {
+	return m.Status == MilestoneClosed
+}
+
+// Validate validates the Milestones struct
+func (m *Milestones) Validate() error {
+	seen := map[string]bool{}
+	for _, milestone := range m.Milestones {
+		if err := ValidateMilestoneID(milestone.ID); err != nil {
+			return err
+		}
+		if seen[milestone.ID] {
+			return fmt.Errorf("models: milestone %q is defined more than once", milestone.ID)
+		}
+		seen[milestone.ID] = true
+
+		if milestone.Status != MilestoneOpen && milestone.Status != MilestoneClosed {
+			return fmt.Errorf("models: milestone %s has an invalid status %q (must be open or closed)", milestone.ID, milestone.Status)
+		}
+		if milestone.Due != "" {
+			if _, err := time.Parse(time.DateOnly, milestone.Due); err != nil {
+				return fmt.Errorf("models: milestone %s has an invalid due date %q (expected format: 2024-06-01)", milestone.ID, milestone.Due)
+			}
+		}
+	}
+	return nil
+}
@@ -5,47 +5,178 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Issue represents a task or bug issue
 type Issue struct {
-	ID          string   `json:"id"`                    // Required: e.g., "CORE-12"
-	Type        string   `json:"type"`                  // Required: "task" or "bug"
-	Title       string   `json:"title"`                 // Required
-	Status      string   `json:"status"`                // Required: TODO, DOING, DONE
-	Priority    string   `json:"priority,omitempty"`    // Optional: LOW, MEDIUM, HIGH, CRITICAL
-	Description string   `json:"description,omitempty"` // Optional: Markdown
-	PRs         []string `json:"prs,omitempty"`         // Optional: Array of PR URLs
-	BlockedBy   []string `json:"blocked_by,omitempty"`  // Optional: Array of issue IDs
-	EpicID      string   `json:"epic_id,omitempty"`     // Optional: Link to epic
-	CreatedAt   string   `json:"created_at,omitempty"`  // ISO 8601 timestamp
-	UpdatedAt   string   `json:"updated_at,omitempty"`  // ISO 8601 timestamp
-}
-
-// Validate validates the Issue struct
+	ID           string          `json:"id"`                      // Required: e.g., "CORE-12"
+	UID          string          `json:"uid,omitempty"`           // Optional: ULID for sync/merge identity (projects in ulid ID mode)
+	Type         string          `json:"type"`                    // Required: "task" or "bug"
+	Title        string          `json:"title"`                   // Required
+	Status       string          `json:"status"`                  // Required: TODO, DOING, DONE
+	Priority     string          `json:"priority,omitempty"`      // Optional: LOW, MEDIUM, HIGH, CRITICAL
+	Assignee     string          `json:"assignee,omitempty"`      // Optional: User the issue is assigned to
+	Description  string          `json:"description,omitempty"`   // Optional: Markdown
+	PRs          []string        `json:"prs,omitempty"`           // Optional: Array of PR URLs
+	Branch       string          `json:"branch,omitempty"`        // Optional: Git branch the issue is worked on
+	Commits      []string        `json:"commits,omitempty"`       // Optional: Git commits that reference the issue (full SHAs)
+	BlockedBy    []string        `json:"blocked_by,omitempty"`    // Optional: Array of issue IDs
+	BlockedSince string          `json:"blocked_since,omitempty"` // ISO 8601 timestamp of the first dependency still in BlockedBy
+	Links        []Link          `json:"links,omitempty"`         // Optional: Typed links (relates_to, duplicates, ...)
+	EpicID       string          `json:"epic_id,omitempty"`       // Optional: Link to epic
+	FixVersion   string          `json:"fix_version,omitempty"`   // Optional: Milestone the issue ships in, e.g. "v1.2"
+	ParentID     string          `json:"parent_id,omitempty"`     // Optional: Parent issue for subtasks
+	Due          string          `json:"due,omitempty"`           // Optional: ISO 8601 due date
+	Estimate     string          `json:"estimate,omitempty"`      // Optional: Effort estimate in working time, e.g. "4h", "2d"
+	Points       int             `json:"points,omitempty"`        // Optional: Story points
+	Resolution   string          `json:"resolution,omitempty"`    // Optional: How the issue was resolved
+	Labels       []string        `json:"labels,omitempty"`        // Optional: Free-form labels, e.g. "infra"
+	Components   []string        `json:"components,omitempty"`    // Optional: Registered components of the project, e.g. "ui"
+	Rank         string          `json:"rank,omitempty"`          // Optional: Manual order set with issue rank (see RankBetween)
+	SnoozedUntil string          `json:"snoozed_until,omitempty"` // Optional: ISO 8601 time until which list hides the issue
+	Checklist    []ChecklistItem `json:"checklist,omitempty"`     // Optional: Checklist items, in order
+	Comments     []Comment       `json:"comments,omitempty"`      // Optional: Comments, oldest first
+	TimeEntries  []TimeEntry     `json:"time_entries,omitempty"`  // Optional: Working time logged, oldest first
+	Attachments  []Attachment    `json:"attachments,omitempty"`   // Optional: Files attached with issue attach, oldest first
+	Reminders    []Reminder      `json:"reminders,omitempty"`     // Optional: Times buyruk notify reminds of the issue, earliest first
+	History      []HistoryEvent  `json:"history,omitempty"`       // Status, PR, and sync changes, oldest first
+	Sensitive    bool            `json:"sensitive,omitempty"`     // Optional: Description and comments are encrypted at rest
+	Sealed       *Sealed         `json:"sealed,omitempty"`        // Encrypted description and comments of a sensitive issue
+	CreatedAt    string          `json:"created_at,omitempty"`    // ISO 8601 timestamp
+	UpdatedAt    string          `json:"updated_at,omitempty"`    // ISO 8601 timestamp
+	CreatedBy    string          `json:"created_by,omitempty"`    // Who created the issue, e.g. "Alice <alice@example.com>"
+	UpdatedBy    string          `json:"updated_by,omitempty"`    // Who changed the issue last
+	CRDT         *CRDTState      `json:"crdt,omitempty"`          // Replication metadata (projects in CRDT mode)
+}
+
+// Sealed holds the encrypted description and comments of a sensitive issue.
+type Sealed struct {
+	Key  string `json:"key"`  // Name of the project key the fields are encrypted with
+	Data string `json:"data"` // Base64 nonce and AES-GCM ciphertext
+}
+
+// Comment represents a comment on an issue
+type Comment struct {
+	Body      string `json:"body"`                 // Required: Markdown
+	CreatedAt string `json:"created_at,omitempty"` // ISO 8601 timestamp
+	CreatedBy string `json:"created_by,omitempty"` // Who wrote the comment
+}
+
+// Validate validates the Issue struct against the default workflow
 // ID, Type, and Status are optional (can be auto-generated/defaulted during creation)
 // Only Title is required
 func (i *Issue) Validate() error {
+	return i.ValidateWithWorkflow(nil)
+}
+
+// ValidateWithWorkflow validates the Issue struct against a project workflow
+// A nil workflow validates against the default statuses, types, and priorities
+func (i *Issue) ValidateWithWorkflow(wf *Workflow) error {
 	// Title is the only required field
 	if i.Title == "" {
 		return fmt.Errorf("models: issue title is required")
 	}
 
 	// Validate Type if provided
-	if i.Type != "" && !IsValidType(i.Type) {
+	if i.Type != "" && !wf.IsValidType(i.Type) {
 		return fmt.Errorf("models: invalid type %q", i.Type)
 	}
 
 	// Validate Status if provided
-	if i.Status != "" && !IsValidStatus(i.Status) {
+	if i.Status != "" && !wf.IsValidStatus(i.Status) {
 		return fmt.Errorf("models: invalid status %q", i.Status)
 	}
 
 	// Validate Priority if provided
-	if i.Priority != "" && !IsValidPriority(i.Priority) {
+	if i.Priority != "" && !wf.IsValidPriority(i.Priority) {
 		return fmt.Errorf("models: invalid priority %q", i.Priority)
 	}
 
+	// Validate UID if provided
+	if i.UID != "" && !IsValidULID(i.UID) {
+		return fmt.Errorf("models: invalid uid %q (must be a ULID)", i.UID)
+	}
+
+	// Validate assignee if provided
+	if i.Assignee != "" {
+		if err := ValidateUser(i.Assignee); err != nil {
+			return err
+		}
+	}
+
+	// Validate labels
+	for _, label := range i.Labels {
+		if err := ValidateLabel(label); err != nil {
+			return err
+		}
+	}
+
+	// Validate components
+	for j, component := range i.Components {
+		if err := ValidateComponentName(component); err != nil {
+			return err
+		}
+		if slices.Contains(i.Components[:j], component) {
+			return fmt.Errorf("models: component %q is given more than once", component)
+		}
+	}
+
+	// Validate fix version if provided
+	if i.FixVersion != "" {
+		if err := ValidateMilestoneID(i.FixVersion); err != nil {
+			return err
+		}
+	}
+
+	// Validate story points
+	if i.Points < 0 {
+		return fmt.Errorf("models: story points must not be negative")
+	}
+
+	// Validate rank if provided
+	if i.Rank != "" {
+		if err := ValidateRank(i.Rank); err != nil {
+			return err
+		}
+	}
+
+	// Validate comments
+	for _, comment := range i.Comments {
+		if strings.TrimSpace(comment.Body) == "" {
+			return fmt.Errorf("models: comment body is required")
+		}
+	}
+
+	// Validate checklist items
+	for _, item := range i.Checklist {
+		if strings.TrimSpace(item.Text) == "" {
+			return fmt.Errorf("models: checklist item text is required")
+		}
+	}
+
+	// Validate logged time
+	for _, entry := range i.TimeEntries {
+		if err := entry.Validate(); err != nil {
+			return err
+		}
+	}
+
+	// Validate typed links
+	for _, link := range i.Links {
+		if ReverseLinkType(link.Type) == "" || link.Type == LinkBlockedBy {
+			return fmt.Errorf("models: invalid link type %q", link.Type)
+		}
+		if link.ID == "" || link.ID == i.ID {
+			return fmt.Errorf("models: invalid %s link to %q", link.Type, link.ID)
+		}
+	}
+
+	// An issue cannot be its own parent
+	if i.ParentID != "" && i.ParentID == i.ID {
+		return fmt.Errorf("models: issue %q cannot be its own parent", i.ID)
+	}
+
 	return nil
 }
 
@@ -59,6 +190,17 @@ func (i *Issue) AddDependency(issueID string) {
 // RemoveDependency removes a dependency from the issue
 func (i *Issue) RemoveDependency(issueID string) {
 	i.BlockedBy = slices.DeleteFunc(i.BlockedBy, func(s string) bool { return s == issueID })
+	if len(i.BlockedBy) == 0 {
+		i.BlockedSince = ""
+	}
+}
+
+// MarkBlocked records now as the time the issue became blocked, if it has
+// dependencies and was not already blocked
+func (i *Issue) MarkBlocked(now string) {
+	if len(i.BlockedBy) > 0 && i.BlockedSince == "" {
+		i.BlockedSince = now
+	}
 }
 
 // AddPR adds a PR URL to the issue
@@ -73,56 +215,148 @@ func (i *Issue) RemovePR(url string) {
 	i.PRs = slices.DeleteFunc(i.PRs, func(s string) bool { return s == url })
 }
 
+// AddLabel adds a label to the issue
+func (i *Issue) AddLabel(label string) {
+	if !slices.Contains(i.Labels, label) {
+		i.Labels = append(i.Labels, label)
+	}
+}
+
+// RemoveLabel removes a label from the issue
+func (i *Issue) RemoveLabel(label string) {
+	i.Labels = slices.DeleteFunc(i.Labels, func(s string) bool { return s == label })
+}
+
+// AddComment appends a comment to the issue
+func (i *Issue) AddComment(body, createdAt string) {
+	i.Comments = append(i.Comments, Comment{Body: body, CreatedAt: createdAt})
+}
+
+// ValidateLabel checks that a label is non-empty and has no whitespace or commas
+func ValidateLabel(label string) error {
+	if label == "" || strings.ContainsAny(label, " \t\n,") {
+		return fmt.Errorf("models: invalid label %q (labels cannot be empty or contain spaces or commas)", label)
+	}
+	return nil
+}
+
+// ValidateUser checks that a user name is non-empty and has no spaces or commas.
+func ValidateUser(user string) error {
+	if user == "" || strings.ContainsAny(user, " \t\n,") {
+		return fmt.Errorf("models: invalid user %q (user names cannot be empty or contain spaces or commas)", user)
+	}
+	return nil
+}
+
 // Epic represents an epic that groups multiple issues
 type Epic struct {
-	ID          string `json:"id"`                    // Required: e.g., "E-1"
-	Title       string `json:"title"`                 // Required
-	Description string `json:"description,omitempty"` // Optional: Markdown
-	Status      string `json:"status,omitempty"`      // Optional: TODO, DOING, DONE
-	CreatedAt   string `json:"created_at,omitempty"`  // ISO 8601 timestamp
-	UpdatedAt   string `json:"updated_at,omitempty"`  // ISO 8601 timestamp
+	ID          string  `json:"id"`                    // Required: e.g., "CORE-E1"
+	Title       string  `json:"title"`                 // Required
+	Description string  `json:"description,omitempty"` // Optional: Markdown
+	Status      string  `json:"status,omitempty"`      // Optional: TODO, DOING, DONE
+	Budget      *Budget `json:"budget,omitempty"`      // Optional: Cost budget tracked against logged time
+	CreatedAt   string  `json:"created_at,omitempty"`  // ISO 8601 timestamp
+	UpdatedAt   string  `json:"updated_at,omitempty"`  // ISO 8601 timestamp
+	CreatedBy   string  `json:"created_by,omitempty"`  // Who created the epic
+	UpdatedBy   string  `json:"updated_by,omitempty"`  // Who changed the epic last
 }
 
-// Validate validates the Epic struct
+// Validate validates the Epic struct against the default workflow
 func (e *Epic) Validate() error {
+	return e.ValidateWithWorkflow(nil)
+}
+
+// ValidateWithWorkflow validates the Epic struct against a project workflow
+func (e *Epic) ValidateWithWorkflow(wf *Workflow) error {
 	if e.ID == "" {
 		return fmt.Errorf("models: epic ID is required")
 	}
 	if e.Title == "" {
 		return fmt.Errorf("models: epic title is required")
 	}
-	if e.Status != "" && !IsValidStatus(e.Status) {
+	if e.Status != "" && !wf.IsValidStatus(e.Status) {
 		return fmt.Errorf("models: invalid status %q", e.Status)
 	}
+	if e.Budget != nil {
+		if err := e.Budget.Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 // IndexEntry represents a single entry in the project index
 type IndexEntry struct {
-	ID     string `json:"id"`                // Issue ID: e.g., "CORE-12"
-	Title  string `json:"title"`             // Issue title
-	Status string `json:"status"`            // Issue status
-	Type   string `json:"type"`              // Issue type
-	EpicID string `json:"epic_id,omitempty"` // Optional epic link
+	ID           string   `json:"id"`                      // Issue ID: e.g., "CORE-12"
+	UID          string   `json:"uid,omitempty"`           // Optional ULID of the issue
+	Title        string   `json:"title"`                   // Issue title
+	Status       string   `json:"status"`                  // Issue status
+	Type         string   `json:"type"`                    // Issue type
+	Priority     string   `json:"priority,omitempty"`      // Issue priority
+	EpicID       string   `json:"epic_id,omitempty"`       // Optional epic link
+	FixVersion   string   `json:"fix_version,omitempty"`   // Optional milestone
+	ParentID     string   `json:"parent_id,omitempty"`     // Optional parent issue
+	Labels       []string `json:"labels,omitempty"`        // Optional labels
+	Components   []string `json:"components,omitempty"`    // Optional components
+	Assignee     string   `json:"assignee,omitempty"`      // Optional assignee
+	Rank         string   `json:"rank,omitempty"`          // Optional manual order
+	Points       int      `json:"points,omitempty"`        // Optional story points
+	Estimate     string   `json:"estimate,omitempty"`      // Optional effort estimate
+	SnoozedUntil string   `json:"snoozed_until,omitempty"` // Optional end of a snooze
+}
+
+// IsSnoozed reports whether the issue of the entry is snoozed at now.
+func (e *IndexEntry) IsSnoozed(now time.Time) bool {
+	if e.SnoozedUntil == "" {
+		return false
+	}
+	until, err := time.Parse(time.RFC3339, e.SnoozedUntil)
+	return err == nil && now.Before(until)
+}
+
+// EpicIndexEntry represents an epic in the project index
+type EpicIndexEntry struct {
+	ID     string `json:"id"`               // Epic ID: e.g., "CORE-E1"
+	Title  string `json:"title"`            // Epic title
+	Status string `json:"status,omitempty"` // Epic status
+	Issues int    `json:"issues"`           // Number of issues linked to the epic
 }
 
 // ProjectIndex represents the index of all issues in a project
 type ProjectIndex struct {
-	ProjectKey  string       `json:"project_key"`            // Required: e.g., "CORE"
-	ProjectName string       `json:"project_name,omitempty"` // Optional
-	Issues      []IndexEntry `json:"issues"`                 // Array of index entries
-	CreatedAt   string       `json:"created_at,omitempty"`   // ISO 8601
-	UpdatedAt   string       `json:"updated_at,omitempty"`   // ISO 8601
+	ProjectKey        string           `json:"project_key"`                   // Required: e.g., "CORE"
+	ProjectName       string           `json:"project_name,omitempty"`        // Optional
+	IDMode            string           `json:"id_mode,omitempty"`             // Optional: "ulid" gives issues a uid; empty for sequence only
+	IDScheme          string           `json:"id_scheme,omitempty"`           // Optional: how new issue IDs are formed (padded, date, ulid); empty for sequence
+	CRDT              bool             `json:"crdt,omitempty"`                // Optional: record per-field clocks in issues so copies merge (experimental)
+	ShardSize         int              `json:"shard_size,omitempty"`          // Optional: keep issue entries in shard files of this many IDs each
+	MaxAttachmentSize int64            `json:"max_attachment_size,omitempty"` // Optional: largest file issue attach accepts, in bytes
+	Shards            []string         `json:"shards,omitempty"`              // Shard files holding the issue entries (managed by storage)
+	Issues            []IndexEntry     `json:"issues"`                        // Array of index entries
+	Epics             []EpicIndexEntry `json:"epics,omitempty"`               // Epics with their issue counts
+	CreatedAt         string           `json:"created_at,omitempty"`          // ISO 8601
+	UpdatedAt         string           `json:"updated_at,omitempty"`          // ISO 8601
 }
 
 // AddIssue adds an issue to the project index
 func (idx *ProjectIndex) AddIssue(issue *Issue) {
 	entry := IndexEntry{
-		ID:     issue.ID,
-		Title:  issue.Title,
-		Status: issue.Status,
-		Type:   issue.Type,
-		EpicID: issue.EpicID,
+		ID:           issue.ID,
+		UID:          issue.UID,
+		Title:        issue.Title,
+		Status:       issue.Status,
+		Type:         issue.Type,
+		Priority:     issue.Priority,
+		EpicID:       issue.EpicID,
+		FixVersion:   issue.FixVersion,
+		ParentID:     issue.ParentID,
+		Labels:       issue.Labels,
+		Components:   issue.Components,
+		Assignee:     issue.Assignee,
+		Rank:         issue.Rank,
+		Points:       issue.Points,
+		Estimate:     issue.Estimate,
+		SnoozedUntil: issue.SnoozedUntil,
 	}
 
 	// Remove existing entry if present
@@ -130,11 +364,105 @@ func (idx *ProjectIndex) AddIssue(issue *Issue) {
 
 	// Add new entry
 	idx.Issues = append(idx.Issues, entry)
+	idx.recountEpics(issue.EpicID)
 }
 
 // RemoveIssue removes an issue from the project index
 func (idx *ProjectIndex) RemoveIssue(issueID string) {
+	var epicID string
+	if entry := idx.FindIssue(issueID); entry != nil {
+		epicID = entry.EpicID
+	}
 	idx.Issues = removeIndexEntry(idx.Issues, issueID)
+	idx.recountEpics(epicID)
+}
+
+// SetEpic adds or updates an epic in the project index
+func (idx *ProjectIndex) SetEpic(epic *Epic) {
+	entry := EpicIndexEntry{ID: epic.ID, Title: epic.Title, Status: epic.Status}
+	if existing := idx.FindEpic(epic.ID); existing != nil {
+		*existing = entry
+	} else {
+		idx.Epics = append(idx.Epics, entry)
+	}
+	idx.recountEpics(epic.ID)
+}
+
+// RemoveEpic removes an epic from the project index
+func (idx *ProjectIndex) RemoveEpic(epicID string) {
+	epics := []EpicIndexEntry{}
+	for _, e := range idx.Epics {
+		if e.ID != epicID {
+			epics = append(epics, e)
+		}
+	}
+	idx.Epics = epics
+	if len(idx.Epics) == 0 {
+		idx.Epics = nil
+	}
+}
+
+// FindEpic finds an epic in the project index by ID
+func (idx *ProjectIndex) FindEpic(epicID string) *EpicIndexEntry {
+	for i := range idx.Epics {
+		if idx.Epics[i].ID == epicID {
+			return &idx.Epics[i]
+		}
+	}
+	return nil
+}
+
+// EpicIssues returns the index entries of the issues linked to an epic
+func (idx *ProjectIndex) EpicIssues(epicID string) []IndexEntry {
+	issues := []IndexEntry{}
+	for _, entry := range idx.Issues {
+		if entry.EpicID == epicID {
+			issues = append(issues, entry)
+		}
+	}
+	return issues
+}
+
+// RecountEpics recounts the issues of every epic in the index, after the
+// issue entries were rebuilt
+func (idx *ProjectIndex) RecountEpics() {
+	for i := range idx.Epics {
+		idx.recountEpics(idx.Epics[i].ID)
+	}
+}
+
+// recountEpics recounts the issues of the given epics
+func (idx *ProjectIndex) recountEpics(epicIDs ...string) {
+	for _, epicID := range epicIDs {
+		if epic := idx.FindEpic(epicID); epic != nil {
+			epic.Issues = len(idx.EpicIssues(epicID))
+		}
+	}
+}
+
+// Children returns the index entries whose parent is the given issue
+func (idx *ProjectIndex) Children(issueID string) []IndexEntry {
+	var children []IndexEntry
+	for _, entry := range idx.Issues {
+		if entry.ParentID == issueID {
+			children = append(children, entry)
+		}
+	}
+	return children
+}
+
+// EpicProgress counts the issues linked to an epic and how many of them are done
+func (idx *ProjectIndex) EpicProgress(epicID string, wf *Workflow) (done, total int) {
+	for _, entry := range idx.Issues {
+		if entry.EpicID != epicID {
+			continue
+		}
+		total++
+		if wf.IsDoneStatus(entry.Status) {
+			done++
+		}
+	}
+	return done, total
 }
 
 // FindIssue finds an issue in the project index by ID
@@ -147,21 +475,32 @@ func (idx *ProjectIndex) FindIssue(issueID string) *IndexEntry {
 	return nil
 }
 
-// Validate validates the ProjectIndex struct
+// Validate validates the ProjectIndex struct against the default workflow
 func (idx *ProjectIndex) Validate() error {
+	return idx.ValidateWithWorkflow(nil)
+}
+
+// ValidateWithWorkflow validates the ProjectIndex struct against a project workflow
+func (idx *ProjectIndex) ValidateWithWorkflow(wf *Workflow) error {
 	if idx.ProjectKey == "" {
 		return fmt.Errorf("models: project key is required")
 	}
+	if idx.IDMode != "" && !IsValidIDMode(idx.IDMode) {
+		return fmt.Errorf("models: invalid ID mode %q", idx.IDMode)
+	}
+	if idx.IDScheme != "" && !IsValidIDScheme(idx.IDScheme) {
+		return fmt.Errorf("models: invalid ID scheme %q", idx.IDScheme)
+	}
 
 	// Validate all index entries
 	for i, entry := range idx.Issues {
 		if entry.ID == "" {
 			return fmt.Errorf("models: index entry %d has empty ID", i)
 		}
-		if !IsValidStatus(entry.Status) {
+		if !wf.IsValidStatus(entry.Status) {
 			return fmt.Errorf("models: index entry %s has invalid status %q", entry.ID, entry.Status)
 		}
-		if entry.Type != "" && !IsValidType(entry.Type) {
+		if entry.Type != "" && !wf.IsValidType(entry.Type) {
 			return fmt.Errorf("models: index entry %s has invalid type %q", entry.ID, entry.Type)
 		}
 	}
@@ -223,33 +562,26 @@ func GenerateIssueID(projectKey string, sequence int) string {
 	return fmt.Sprintf("%s-%d", projectKey, sequence)
 }
 
-// ParseIssueID parses an issue ID into project key and sequence number
-// Supports project keys with hyphens by splitting from the right (last hyphen)
+// ParseIssueID parses an issue ID into project key and sequence number.
+// Supports project keys with hyphens by splitting from the right (last hyphen),
+// and the IDs of every ID scheme: padded (CORE-0012), date-based, and ULID
+// suffixes (CORE-01J0ZQ5B0M8N1XK3V7T2R9Y6WD, whose sequence number is 0).
+// The year of a date-based ID stays in the key (CORE-2024 for CORE-2024-012),
+// since it can't be told apart from a key ending in a year without knowing
+// the projects; see DateIssueIDKey.
 func ParseIssueID(id string) (projectKey string, sequence int, err error) {
-	// Find the last hyphen to support project keys with hyphens
-	lastHyphen := strings.LastIndex(id, "-")
-	if lastHyphen == -1 {
-		return "", 0, fmt.Errorf("models: invalid issue ID format %q", id)
-	}
-
-	projectKey = id[:lastHyphen]
-	sequenceStr := id[lastHyphen+1:]
-
-	// Validate project key is not empty
-	if projectKey == "" {
+	projectKey, sequenceStr, ok := cutLastHyphen(id)
+	if !ok || projectKey == "" || sequenceStr == "" {
 		return "", 0, fmt.Errorf("models: invalid issue ID format %q", id)
 	}
 
-	// Validate sequence string is not empty
-	if sequenceStr == "" {
-		return "", 0, fmt.Errorf("models: invalid issue ID format %q", id)
+	if IsValidULID(sequenceStr) && !isDigits(sequenceStr) {
+		return projectKey, 0, nil
 	}
 
 	// Validate sequence contains only digits (no hyphens or other characters)
-	for _, r := range sequenceStr {
-		if r < '0' || r > '9' {
-			return "", 0, fmt.Errorf("models: invalid sequence in ID %q: sequence must be numeric", id)
-		}
+	if !isDigits(sequenceStr) {
+		return "", 0, fmt.Errorf("models: invalid sequence in ID %q: sequence must be numeric", id)
 	}
 
 	sequence, err = strconv.Atoi(sequenceStr)
@@ -259,3 +591,28 @@ func ParseIssueID(id string) (projectKey string, sequence int, err error) {
 
 	return projectKey, sequence, nil
 }
+
+// DateIssueIDKey returns the project key of id read as a date-based ID, with
+// the year between the key and the sequence number (CORE for CORE-2024-012).
+// It returns false if id can't be a date-based ID. Whether it is one depends
+// on the projects: CORE-2024-012 is also issue 12 of a project CORE-2024.
+func DateIssueIDKey(id string) (string, bool) {
+	rest, sequenceStr, ok := cutLastHyphen(id)
+	if !ok || len(sequenceStr) < dateSequenceWidth || !isDigits(sequenceStr) {
+		return "", false
+	}
+	key, year, ok := cutLastHyphen(rest)
+	if !ok || key == "" || !isYear(year) {
+		return "", false
+	}
+	return key, true
+}
+
+// cutLastHyphen splits s around its last hyphen.
+func cutLastHyphen(s string) (before, after string, found bool) {
+	i := strings.LastIndex(s, "-")
+	if i < 0 {
+		return s, "", false
+	}
+	return s[:i], s[i+1:], true
+}
@@ -247,6 +247,94 @@ func TestIssue_RemoveDependency(t *testing.T) {
 	}
 }
 
+func TestIssue_Links(t *testing.T) {
+	issue := &Issue{ID: "CORE-1", Type: TypeTask, Title: "Test Issue", Status: StatusTODO}
+
+	issue.AddLink(LinkRelatesTo, "CORE-2")
+	issue.AddLink(LinkRelatesTo, "CORE-2")
+	issue.AddLink(LinkDuplicates, "CORE-3")
+	issue.AddLink(LinkBlockedBy, "CORE-4")
+	if len(issue.Links) != 2 {
+		t.Errorf("AddLink() should store two typed links without duplicates, got %v", issue.Links)
+	}
+	if !slices.Equal(issue.BlockedBy, []string{"CORE-4"}) {
+		t.Errorf("AddLink(blocked_by) should add a dependency, got %v", issue.BlockedBy)
+	}
+	if got := issue.LinkedIDs(LinkDuplicates); !slices.Equal(got, []string{"CORE-3"}) {
+		t.Errorf("LinkedIDs(duplicates) = %v, want [CORE-3]", got)
+	}
+	if err := issue.Validate(); err != nil {
+		t.Errorf("Validate() failed for valid links: %v", err)
+	}
+
+	if !issue.RenameLinks(func(id string) string { return strings.Replace(id, "CORE-", "NEW-", 1) }) {
+		t.Error("RenameLinks() should report a change")
+	}
+	if issue.BlockedBy[0] != "NEW-4" || issue.Links[0].ID != "NEW-2" {
+		t.Errorf("RenameLinks() did not rename all links: %v %v", issue.BlockedBy, issue.Links)
+	}
+
+	issue.RemoveLink(LinkRelatesTo, "NEW-2")
+	issue.RemoveLink(LinkBlockedBy, "NEW-4")
+	if len(issue.Links) != 1 || len(issue.BlockedBy) != 0 {
+		t.Errorf("RemoveLink() left %v %v", issue.Links, issue.BlockedBy)
+	}
+
+	for linkType, reverse := range map[string]string{
+		LinkBlockedBy:  LinkBlocks,
+		LinkRelatesTo:  LinkRelatesTo,
+		LinkDuplicates: LinkDuplicatedBy,
+		LinkParentOf:   "",
+	} {
+		if got := ReverseLinkType(linkType); got != reverse {
+			t.Errorf("ReverseLinkType(%s) = %q, want %q", linkType, got, reverse)
+		}
+	}
+
+	if err := ValidateLinkType("clones"); err == nil {
+		t.Error("ValidateLinkType() should reject unknown types")
+	}
+	issue.Links = []Link{{Type: LinkParentOf, ID: "CORE-9"}}
+	if err := issue.Validate(); err == nil {
+		t.Error("Validate() should reject parent_of in typed links")
+	}
+	issue.Links = []Link{{Type: LinkRelatesTo, ID: issue.ID}}
+	if err := issue.Validate(); err == nil {
+		t.Error("Validate() should reject links to the issue itself")
+	}
+}
+
+func TestLinkIssues(t *testing.T) {
+	a := &Issue{ID: "CORE-1"}
+	b := &Issue{ID: "CORE-2"}
+	c := &Issue{ID: "OTHER-1"}
+
+	LinkIssues(a, b, LinkBlockedBy)
+	LinkIssues(a, c, LinkDuplicates)
+	if !slices.Equal(a.BlockedBy, []string{"CORE-2"}) || !slices.Equal(b.LinkedIDs(LinkBlocks), []string{"CORE-1"}) {
+		t.Errorf("LinkIssues(blocked_by) = %v / %v, want both sides", a.BlockedBy, b.Links)
+	}
+	if !slices.Equal(c.LinkedIDs(LinkDuplicatedBy), []string{"CORE-1"}) {
+		t.Errorf("LinkIssues(duplicates) should add duplicated_by, got %v", c.Links)
+	}
+	if got := a.LinkedIssueIDs(); !slices.Equal(got, []string{"CORE-2", "OTHER-1"}) {
+		t.Errorf("LinkedIssueIDs() = %v, want [CORE-2 OTHER-1]", got)
+	}
+
+	UnlinkIssues(a, b, b.ID, LinkBlockedBy)
+	if len(a.BlockedBy) != 0 || len(b.Links) != 0 {
+		t.Errorf("UnlinkIssues() left %v / %v", a.BlockedBy, b.Links)
+	}
+	UnlinkIssues(a, nil, "CORE-9", LinkRelatesTo)
+
+	if !c.RemoveLinksTo("CORE-1") || len(c.Links) != 0 {
+		t.Errorf("RemoveLinksTo() left %v", c.Links)
+	}
+	if c.RemoveLinksTo("CORE-1") {
+		t.Error("RemoveLinksTo() should report no change when nothing links")
+	}
+}
+
 func TestIssue_AddPR(t *testing.T) {
 	issue := &Issue{
 		ID:     "CORE-12",
@@ -663,6 +751,48 @@ func TestGenerateIssueID(t *testing.T) {
 	}
 }
 
+func TestFormatIssueID(t *testing.T) {
+	now := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		scheme string
+		want   string
+	}{
+		{"", "CORE-12"},
+		{IDSchemeSequence, "CORE-12"},
+		{IDSchemePadded, "CORE-0012"},
+		{IDSchemeDate, "CORE-2024-012"},
+	}
+	for _, tt := range tests {
+		got, err := FormatIssueID(tt.scheme, "CORE", 12, now)
+		if err != nil || got != tt.want {
+			t.Errorf("FormatIssueID(%q) = %q, %v, want %q", tt.scheme, got, err, tt.want)
+		}
+		if _, seq, err := ParseIssueID(got); err != nil || seq != 12 {
+			t.Errorf("ParseIssueID(%q) = %d, %v, want sequence 12", got, seq, err)
+		}
+	}
+
+	id, err := FormatIssueID(IDSchemeULID, "CORE", 12, now)
+	if err != nil {
+		t.Fatalf("FormatIssueID(ulid) failed: %v", err)
+	}
+	if key, _, err := ParseIssueID(id); err != nil || key != "CORE" || !IsValidULID(strings.TrimPrefix(id, "CORE-")) {
+		t.Errorf("FormatIssueID(ulid) = %q, want CORE-<ULID>", id)
+	}
+	if _, err := FormatIssueID("roman", "CORE", 12, now); err == nil {
+		t.Error("FormatIssueID() should fail for an unknown scheme")
+	}
+
+	if base, ok := YearKeyBase("CORE-2024"); !ok || base != "CORE" {
+		t.Errorf("YearKeyBase(CORE-2024) = %q, %v; want CORE", base, ok)
+	}
+	for _, key := range []string{"CORE-12", "CORE", "-2024"} {
+		if _, ok := YearKeyBase(key); ok {
+			t.Errorf("YearKeyBase(%s) should only match keys ending in a year", key)
+		}
+	}
+}
+
 func TestParseIssueID(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -681,6 +811,11 @@ func TestParseIssueID(t *testing.T) {
 		{"empty", "", "", 0, true},
 		{"only key", "CORE", "", 0, true},
 		{"only sequence", "-12", "", 0, true},
+		{"padded", "CORE-0012", "CORE", 12, false},
+		{"date-based keeps the year in the key", "CORE-2024-012", "CORE-2024", 12, false},
+		{"key ending in a year", "REL-2024-100", "REL-2024", 100, false},
+		{"ULID suffix", "CORE-01J0ZQ5B0M8N1XK3V7T2R9Y6WD", "CORE", 0, false},
+		{"lowercase ULID suffix", "CORE-01j0zq5b0m8n1xk3v7t2r9y6wd", "", 0, true},
 	}
 
 	for _, tt := range tests {
@@ -702,6 +837,59 @@ func TestParseIssueID(t *testing.T) {
 	}
 }
 
+func TestDateIssueIDKey(t *testing.T) {
+	tests := []struct {
+		id      string
+		wantKey string
+		wantOK  bool
+	}{
+		{"CORE-2024-012", "CORE", true},
+		{"MY-APP-2024-1000", "MY-APP", true},
+		{"CORE-2024-12", "", false},
+		{"CORE-12", "", false},
+		{"2024-012", "", false},
+		{"CORE-24-012", "", false},
+	}
+	for _, tt := range tests {
+		if key, ok := DateIssueIDKey(tt.id); key != tt.wantKey || ok != tt.wantOK {
+			t.Errorf("DateIssueIDKey(%q) = %q, %v, want %q, %v", tt.id, key, ok, tt.wantKey, tt.wantOK)
+		}
+	}
+}
+
+func TestParseEpicID(t *testing.T) {
+	tests := []struct {
+		id           string
+		wantKey      string
+		wantSequence int
+		wantErr      bool
+	}{
+		{"CORE-E1", "CORE", 1, false},
+		{"MY-APP-E12", "MY-APP", 12, false},
+		{"E-1", "", 0, true},
+		{"CORE-AUTH", "", 0, true},
+		{"CORE-E", "", 0, true},
+		{"-E1", "", 0, true},
+		{"CORE-12", "", 0, true},
+	}
+	for _, tt := range tests {
+		key, sequence, err := ParseEpicID(tt.id)
+		if (err != nil) != tt.wantErr || key != tt.wantKey || sequence != tt.wantSequence {
+			t.Errorf("ParseEpicID(%q) = %q, %d, %v, want %q, %d", tt.id, key, sequence, err, tt.wantKey, tt.wantSequence)
+		}
+	}
+
+	if id := GenerateEpicID("CORE", 3); id != "CORE-E3" {
+		t.Errorf("GenerateEpicID() = %q, want CORE-E3", id)
+	}
+	if seq, ok := LegacyEpicSequence("E-7"); !ok || seq != 7 {
+		t.Errorf("LegacyEpicSequence(E-7) = %d, %v, want 7", seq, ok)
+	}
+	if _, ok := LegacyEpicSequence("CORE-E7"); ok {
+		t.Error("LegacyEpicSequence() should not match scoped IDs")
+	}
+}
+
 // Test JSON Serialization
 
 func TestIssue_JSON(t *testing.T) {
@@ -1025,3 +1213,459 @@ func TestProjectIndex_EmptyIssues(t *testing.T) {
 		t.Errorf("RemoveIssue() should not affect empty index, got %d issues", len(idx.Issues))
 	}
 }
+
+func TestNormalizeTimestamp(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"2024-06-05T15:00:00+03:00", "2024-06-05T12:00:00Z"},
+		{"2024-06-05T12:00:00Z", "2024-06-05T12:00:00Z"},
+		{"", ""},
+		{"not a time", "not a time"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeTimestamp(tt.value); got != tt.want {
+			t.Errorf("NormalizeTimestamp(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+
+	issue := &Issue{CreatedAt: "2024-06-05T15:00:00+03:00", UpdatedAt: "2024-06-05T12:00:00Z"}
+	if !issue.NormalizeTimestamps() {
+		t.Error("NormalizeTimestamps() = false, want true")
+	}
+	if issue.NormalizeTimestamps() {
+		t.Error("NormalizeTimestamps() on UTC timestamps = true, want false")
+	}
+}
+
+func TestProjectIndex_Children(t *testing.T) {
+	idx := &ProjectIndex{ProjectKey: "CORE"}
+	idx.AddIssue(&Issue{ID: "CORE-1", Title: "Parent"})
+	idx.AddIssue(&Issue{ID: "CORE-2", Title: "Child", ParentID: "CORE-1"})
+	idx.AddIssue(&Issue{ID: "CORE-3", Title: "Other"})
+
+	children := idx.Children("CORE-1")
+	if len(children) != 1 || children[0].ID != "CORE-2" {
+		t.Errorf("Children() = %v, want [CORE-2]", children)
+	}
+
+	self := &Issue{ID: "CORE-1", Title: "Loop", ParentID: "CORE-1"}
+	if err := self.Validate(); err == nil {
+		t.Error("Validate() should fail for an issue that is its own parent")
+	}
+}
+
+func TestNewULID(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	a, err := NewULID(now)
+	if err != nil {
+		t.Fatalf("NewULID() failed: %v", err)
+	}
+	b, _ := NewULID(now)
+	later, _ := NewULID(now.Add(time.Millisecond))
+
+	if !IsValidULID(a) {
+		t.Errorf("NewULID() = %q, not a valid ULID", a)
+	}
+	if a == b {
+		t.Error("NewULID() returned the same ULID twice")
+	}
+	if a[:10] != b[:10] || later[:10] <= a[:10] {
+		t.Errorf("Expected ULIDs to sort by time, got %q %q %q", a, b, later)
+	}
+	// Known timestamp prefix from the ULID spec encoding
+	if got, _ := NewULID(time.UnixMilli(1469918176385)); got[:10] != "01ARYZ6S41" {
+		t.Errorf("NewULID() timestamp = %q, want 01ARYZ6S41", got[:10])
+	}
+
+	for _, invalid := range []string{"", "01ARYZ6S41", "01ARYZ6S41TSV4RRFFQ69G5FAI", "81ARYZ6S41TSV4RRFFQ69G5FAV"} {
+		if IsValidULID(invalid) {
+			t.Errorf("IsValidULID(%q) = true, want false", invalid)
+		}
+	}
+}
+
+func TestProjectIndex_AssignUID(t *testing.T) {
+	now := time.Now()
+	issue := &Issue{ID: "CORE-1", Title: "Sync"}
+
+	idx := &ProjectIndex{ProjectKey: "CORE"}
+	if err := idx.AssignUID(issue, now); err != nil || issue.UID != "" {
+		t.Errorf("Expected no uid in sequence mode, got %q (%v)", issue.UID, err)
+	}
+
+	idx.IDMode = IDModeULID
+	if err := idx.AssignUID(issue, now); err != nil || !IsValidULID(issue.UID) {
+		t.Fatalf("Expected a uid in ulid mode, got %q (%v)", issue.UID, err)
+	}
+	uid := issue.UID
+	idx.AssignUID(issue, now)
+	if issue.UID != uid {
+		t.Error("Expected an existing uid to be kept")
+	}
+
+	idx.AddIssue(issue)
+	if idx.FindIssue("CORE-1").UID != uid {
+		t.Error("Expected the index entry to carry the uid")
+	}
+
+	idx.IDMode = "uuid"
+	if err := idx.Validate(); err == nil {
+		t.Error("Expected invalid ID mode to fail validation")
+	}
+	issue.UID = "not-a-ulid"
+	if err := issue.Validate(); err == nil {
+		t.Error("Expected invalid uid to fail validation")
+	}
+}
+
+func TestProjectIndex_Epics(t *testing.T) {
+	idx := &ProjectIndex{ProjectKey: "CORE"}
+	idx.SetEpic(&Epic{ID: "E-1", Title: "Onboarding", Status: StatusTODO})
+	idx.SetEpic(&Epic{ID: "E-2", Title: "Billing"})
+	idx.AddIssue(&Issue{ID: "CORE-1", Title: "Login", EpicID: "E-1"})
+	idx.AddIssue(&Issue{ID: "CORE-2", Title: "Signup", EpicID: "E-1"})
+	idx.AddIssue(&Issue{ID: "CORE-3", Title: "Invoices", EpicID: "E-2"})
+
+	if got := idx.FindEpic("E-1").Issues; got != 2 {
+		t.Errorf("E-1 issues = %d, want 2", got)
+	}
+
+	// Moving an issue between epics updates both counts
+	idx.AddIssue(&Issue{ID: "CORE-2", Title: "Signup", EpicID: "E-2"})
+	if idx.FindEpic("E-1").Issues != 1 || idx.FindEpic("E-2").Issues != 2 {
+		t.Errorf("Expected counts 1 and 2 after moving an issue, got %+v", idx.Epics)
+	}
+	idx.RemoveIssue("CORE-3")
+	if got := idx.FindEpic("E-2").Issues; got != 1 {
+		t.Errorf("E-2 issues = %d after removal, want 1", got)
+	}
+
+	// Updating an epic keeps its count
+	idx.SetEpic(&Epic{ID: "E-1", Title: "Onboarding v2", Status: StatusDOING})
+	if e := idx.FindEpic("E-1"); e.Title != "Onboarding v2" || e.Status != StatusDOING || e.Issues != 1 || len(idx.Epics) != 2 {
+		t.Errorf("Unexpected epic entry after update: %+v", idx.Epics)
+	}
+	if issues := idx.EpicIssues("E-2"); len(issues) != 1 || issues[0].ID != "CORE-2" {
+		t.Errorf("EpicIssues(E-2) = %v, want [CORE-2]", issues)
+	}
+
+	idx.RemoveEpic("E-1")
+	idx.RemoveEpic("E-2")
+	if idx.FindEpic("E-1") != nil || idx.Epics != nil {
+		t.Errorf("Expected no epics after removal, got %+v", idx.Epics)
+	}
+}
+
+func TestAwayRegistry(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	registry := &AwayRegistry{}
+	registry.Set(Away{User: "alice", Until: "2025-02-01T00:00:00Z", Delegate: "bob"})
+	registry.Set(Away{User: "carol"})
+	registry.Set(Away{User: "dave", Until: "2025-01-01T00:00:00Z"})
+
+	if a := registry.Active("alice", now); a == nil || a.Delegate != "bob" {
+		t.Errorf("Expected alice to be away with delegate bob, got %+v", a)
+	}
+	if registry.Active("carol", now) == nil {
+		t.Error("Expected carol to be away without an end date")
+	}
+	if registry.Active("dave", now) != nil {
+		t.Error("Expected dave to be back after the end date")
+	}
+	if registry.Active("erin", now) != nil {
+		t.Error("Expected unknown user not to be away")
+	}
+
+	registry.Set(Away{User: "alice", Delegate: "carol"})
+	if len(registry.Users) != 3 || registry.Find("alice").Delegate != "carol" {
+		t.Errorf("Expected alice's entry to be replaced, got %+v", registry.Users)
+	}
+	if !registry.Remove("alice") || registry.Remove("alice") {
+		t.Error("Expected Remove() to remove alice once")
+	}
+
+	for _, invalid := range []Away{{User: ""}, {User: "a b"}, {User: "alice", Delegate: "alice"}, {User: "alice", Until: "soon"}} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", invalid)
+		}
+	}
+}
+
+func TestIssueMarkBlocked(t *testing.T) {
+	issue := &Issue{ID: "CORE-1"}
+	issue.MarkBlocked("2024-06-01T00:00:00Z")
+	if issue.BlockedSince != "" {
+		t.Error("Expected an issue without dependencies not to be marked blocked")
+	}
+	issue.AddDependency("CORE-2")
+	issue.MarkBlocked("2024-06-01T00:00:00Z")
+	issue.AddDependency("CORE-3")
+	issue.MarkBlocked("2024-06-02T00:00:00Z")
+	if issue.BlockedSince != "2024-06-01T00:00:00Z" {
+		t.Errorf("BlockedSince = %q, want the first dependency's time", issue.BlockedSince)
+	}
+	issue.RemoveLinksTo("CORE-2")
+	if issue.BlockedSince == "" {
+		t.Error("Expected BlockedSince to remain while a dependency is left")
+	}
+	issue.RemoveDependency("CORE-3")
+	if issue.BlockedSince != "" {
+		t.Error("Expected BlockedSince to be cleared with the last dependency")
+	}
+}
+
+func TestMergeIssues(t *testing.T) {
+	at := func(minute int, replica string) string {
+		return NewClock(time.Date(2024, 6, 1, 9, minute, 0, 0, time.UTC), replica)
+	}
+	edit := func(base *Issue, clock string, change func(*Issue)) *Issue {
+		t.Helper()
+		data, _ := json.Marshal(base)
+		var updated Issue
+		json.Unmarshal(data, &updated)
+		change(&updated)
+		if err := StampIssue(base, &updated, clock); err != nil {
+			t.Fatalf("StampIssue() failed: %v", err)
+		}
+		return &updated
+	}
+
+	// A base issue written before CRDT mode: its labels get the seed tag
+	base := &Issue{ID: "CORE-1", Type: TypeTask, Title: "Login", Status: StatusTODO, Labels: []string{"infra", "ui"}}
+
+	// Machine a renames the issue and drops "ui"; machine b moves it to
+	// DOING, renames it later, and adds a label
+	a := edit(base, at(1, "a"), func(i *Issue) {
+		i.Title = "Login page"
+		i.Labels = []string{"infra"}
+	})
+	b := edit(base, at(2, "b"), func(i *Issue) {
+		i.Status = StatusDOING
+		i.Labels = append(i.Labels, "auth")
+	})
+	b = edit(b, at(3, "b"), func(i *Issue) { i.Title = "Login flow" })
+
+	for _, merged := range []*Issue{mustMerge(t, a, b), mustMerge(t, b, a)} {
+		if merged.Title != "Login flow" || merged.Status != StatusDOING {
+			t.Errorf("Expected the latest title and status, got %q %s", merged.Title, merged.Status)
+		}
+		if got := strings.Join(slices.Sorted(slices.Values(merged.Labels)), ","); got != "auth,infra" {
+			t.Errorf("Labels = %s, want auth,infra", got)
+		}
+	}
+
+	// A concurrent re-add survives a remove that did not observe it
+	merged := mustMerge(t, a, b)
+	c := edit(merged, at(4, "a"), func(i *Issue) { i.Labels = []string{"infra"} })
+	d := edit(merged, at(4, "b"), func(i *Issue) { i.Labels = []string{"infra", "auth", "ui"} })
+	if got := strings.Join(mustMerge(t, c, d).Labels, ","); got != "infra,ui" {
+		t.Errorf("Labels = %s, want infra,ui", got)
+	}
+
+	// Merging a copy with itself changes nothing
+	if again := mustMerge(t, merged, merged); again.Title != merged.Title || len(again.Labels) != len(merged.Labels) {
+		t.Errorf("Expected merge to be idempotent, got %+v", again)
+	}
+}
+
+func mustMerge(t *testing.T, a, b *Issue) *Issue {
+	t.Helper()
+	merged, err := MergeIssues(a, b)
+	if err != nil {
+		t.Fatalf("MergeIssues() failed: %v", err)
+	}
+	return merged
+}
+
+func TestBudget(t *testing.T) {
+	budget := &Budget{Hours: 100, Amount: 12000, Rate: 150}
+	if err := budget.Validate(); err != nil {
+		t.Fatalf("Validate() failed: %v", err)
+	}
+	for _, invalid := range []*Budget{{Hours: -1}, {Amount: 100}, {Hours: 10, Warnings: []int{0}}} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", invalid)
+		}
+	}
+
+	// 60h is 60% of the hours but 75% of the amount (60h at 150 is 9000)
+	if burn := budget.Burn(60 * time.Hour); burn != 75 {
+		t.Errorf("Burn(60h) = %v, want 75", burn)
+	}
+	if level := budget.CrossedWarning(50*time.Hour, 60*time.Hour); level != 0 {
+		t.Errorf("Expected no warning below 80%%, got %d", level)
+	}
+	if level := budget.CrossedWarning(60*time.Hour, 90*time.Hour); level != 100 {
+		t.Errorf("Expected the 100%% warning, got %d", level)
+	}
+	budget.Warnings = []int{50, 25, 50}
+	if levels := budget.WarningLevels(); !slices.Equal(levels, []int{25, 50}) {
+		t.Errorf("WarningLevels() = %v, want [25 50]", levels)
+	}
+
+	issue := &Issue{TimeEntries: []TimeEntry{{Duration: "1d"}, {Duration: "1h30m"}}}
+	if logged := issue.LoggedTime(); logged != 9*time.Hour+30*time.Minute {
+		t.Errorf("LoggedTime() = %v, want 9h30m", logged)
+	}
+}
+
+func TestHookValidateAndMatches(t *testing.T) {
+	valid := []Hook{
+		{Events: []string{EventIssueCreated}, Command: "./notify.sh"},
+		{Events: []string{"issue.*"}, URL: "https://example.com/hook", Timeout: "3s"},
+	}
+	for _, hook := range valid {
+		if err := hook.Validate(); err != nil {
+			t.Errorf("Validate(%+v) failed: %v", hook, err)
+		}
+	}
+	invalid := []Hook{
+		{Command: "./notify.sh"},
+		{Events: []string{"issue.closed"}, Command: "./notify.sh"},
+		{Events: []string{EventIssueCreated}},
+		{Events: []string{EventIssueCreated}, Command: "./notify.sh", URL: "https://example.com/hook"},
+		{Events: []string{EventIssueCreated}, URL: "example.com/hook"},
+		{Events: []string{EventIssueCreated}, Command: "./notify.sh", Timeout: "soon"},
+	}
+	for _, hook := range invalid {
+		if err := hook.Validate(); err == nil {
+			t.Errorf("Validate(%+v) should have failed", hook)
+		}
+	}
+
+	if !valid[1].Matches(EventIssueDeleted) || valid[0].Matches(EventIssueUpdated) || !valid[0].Matches(EventIssueCreated) {
+		t.Error("Matches() returned unexpected results")
+	}
+	if valid[1].TimeoutDuration() != 3*time.Second || valid[0].TimeoutDuration() != DefaultHookTimeout {
+		t.Error("TimeoutDuration() returned unexpected results")
+	}
+}
+
+func TestBranchName(t *testing.T) {
+	tests := []struct {
+		template string
+		issue    Issue
+		want     string
+	}{
+		{"{prefix}/{id}-{slug}", Issue{ID: "CORE-12", Type: TypeTask, Title: "Add login page"}, "feat/CORE-12-add-login-page"},
+		{"{prefix}/{id}-{slug}", Issue{ID: "CORE-3", Type: TypeBug, Title: "Şifre sıfırlama çalışmıyor!"}, "fix/CORE-3-sifre-sifirlama-calismiyor"},
+		{"{type}/{project}/{id}", Issue{ID: "MY-APP-7", Type: TypeTask, Title: "x"}, "task/MY-APP/MY-APP-7"},
+		{"{prefix}/{id}-{slug}", Issue{ID: "CORE-4", Type: TypeTask, Title: "???"}, "feat/CORE-4"},
+		{"{id}-{slug}", Issue{ID: "CORE-5", Type: TypeTask, Title: "Make the very long title fit into a reasonable branch name"}, "CORE-5-make-the-very-long-title-fit-into-a"},
+	}
+	for _, tt := range tests {
+		projectKey, _, _ := ParseIssueID(tt.issue.ID)
+		if got := BranchName(tt.template, projectKey, &tt.issue); got != tt.want {
+			t.Errorf("BranchName(%q, %q) = %q, want %q", tt.template, tt.issue.Title, got, tt.want)
+		}
+	}
+}
+
+func TestRecordHistory(t *testing.T) {
+	old := &Issue{ID: "CORE-1", Status: StatusTODO, PRs: []string{"https://example.com/pr/1"}}
+	updated := &Issue{ID: "CORE-1", Status: StatusDOING, PRs: []string{"https://example.com/pr/2"}}
+	RecordHistory(old, updated, "2024-06-03T10:00:00Z")
+	want := []HistoryEvent{
+		{At: "2024-06-03T10:00:00Z", Type: HistoryStatus, From: StatusTODO, To: StatusDOING},
+		{At: "2024-06-03T10:00:00Z", Type: HistoryPRAdded, Detail: "https://example.com/pr/2"},
+		{At: "2024-06-03T10:00:00Z", Type: HistoryPRRemoved, Detail: "https://example.com/pr/1"},
+	}
+	if !slices.Equal(updated.History, want) {
+		t.Errorf("RecordHistory() = %+v, want %+v", updated.History, want)
+	}
+
+	// A synced copy keeps both histories in time order, plus the sync
+	local := &Issue{ID: "CORE-1", Status: StatusDOING, History: want[:1]}
+	remote := &Issue{ID: "CORE-1", Status: StatusDONE, History: []HistoryEvent{
+		want[0],
+		{At: "2024-06-02T09:00:00Z", Type: HistoryPRAdded, Detail: "https://example.com/pr/3"},
+		{At: "2024-06-04T09:00:00Z", Type: HistoryStatus, From: StatusDOING, To: StatusDONE},
+	}}
+	RecordSyncHistory(local, remote, "2024-06-05T09:00:00Z", "sync review")
+	if len(remote.History) != 4 || remote.History[0].Detail != "https://example.com/pr/3" ||
+		remote.History[3] != (HistoryEvent{At: "2024-06-05T09:00:00Z", Type: HistorySync, From: StatusDOING, To: StatusDONE, Detail: "sync review"}) {
+		t.Errorf("RecordSyncHistory() = %+v", remote.History)
+	}
+}
+
+func TestParsePRURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want PRRef
+	}{
+		{"https://github.com/acme/api/pull/12", PRRef{ProviderGitHub, "github.com", "acme/api", 12}},
+		{"https://github.com/acme/api/pull/12/files", PRRef{ProviderGitHub, "github.com", "acme/api", 12}},
+		{"https://git.example.com/acme/api/pull/3", PRRef{ProviderGitHub, "git.example.com", "acme/api", 3}},
+		{"https://gitlab.com/acme/backend/api/-/merge_requests/7", PRRef{ProviderGitLab, "gitlab.com", "acme/backend/api", 7}},
+		{"https://gitlab.example.com/acme/api/-/merge_requests/7/diffs", PRRef{ProviderGitLab, "gitlab.example.com", "acme/api", 7}},
+	}
+	for _, tt := range tests {
+		got, err := ParsePRURL(tt.url)
+		if err != nil {
+			t.Errorf("ParsePRURL(%q) failed: %v", tt.url, err)
+			continue
+		}
+		if *got != tt.want {
+			t.Errorf("ParsePRURL(%q) = %+v, want %+v", tt.url, *got, tt.want)
+		}
+	}
+	for _, bad := range []string{"not a url", "https://github.com/acme/api/issues/12", "https://github.com/acme/api/pull/x", "ftp://github.com/a/b/pull/1"} {
+		if _, err := ParsePRURL(bad); err == nil {
+			t.Errorf("ParsePRURL(%q) succeeded", bad)
+		}
+	}
+
+	if AllPRsMerged(nil) || AllPRsMerged([]PRStatus{{State: PRStateMerged}, {State: PRStateOpen}}) || !AllPRsMerged([]PRStatus{{State: PRStateMerged}}) {
+		t.Error("AllPRsMerged gave a wrong answer")
+	}
+}
+
+func TestRankBetween(t *testing.T) {
+	tests := []struct {
+		before, after string
+	}{
+		{"", ""},
+		{"", "i"},
+		{"i", ""},
+		{"a", "b"},
+		{"", "1"},
+		{"", "01"},
+		{"z", ""},
+		{"zz", ""},
+		{"a", "a1"},
+		{"ai", "b"},
+	}
+	for _, tt := range tests {
+		got, err := RankBetween(tt.before, tt.after)
+		if err != nil {
+			t.Errorf("RankBetween(%q, %q) failed: %v", tt.before, tt.after, err)
+			continue
+		}
+		if got <= tt.before || (tt.after != "" && got >= tt.after) {
+			t.Errorf("RankBetween(%q, %q) = %q, not between them", tt.before, tt.after, got)
+		}
+		if err := ValidateRank(got); err != nil {
+			t.Errorf("RankBetween(%q, %q) = %q: %v", tt.before, tt.after, got, err)
+		}
+	}
+
+	if _, err := RankBetween("b", "a"); err == nil {
+		t.Error("RankBetween(b, a) should fail")
+	}
+
+	// Repeatedly inserting at the same place keeps ranks short
+	low, high := "", ""
+	for i := 0; i < 50; i++ {
+		rank, err := RankBetween(low, high)
+		if err != nil {
+			t.Fatalf("RankBetween(%q, %q) failed: %v", low, high, err)
+		}
+		high = rank
+	}
+	if len(high) > 12 {
+		t.Errorf("Expected 50 inserts at the top to keep ranks short, got %q", high)
+	}
+}
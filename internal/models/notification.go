@@ -0,0 +1,27 @@
+package models
+
+// NotificationState records which notifications a user has read, shared by
+// all projects. Notifications themselves are derived from the issues, so only
+// their IDs are stored.
+type NotificationState struct {
+	User string            `json:"user"`           // Required: user name
+	Read map[string]string `json:"read,omitempty"` // Notification ID to ISO 8601 timestamp it was read
+}
+
+// IsRead reports whether a notification has been read.
+func (s *NotificationState) IsRead(id string) bool {
+	_, ok := s.Read[id]
+	return ok
+}
+
+// MarkRead marks a notification as read. Returns false if it already was.
+func (s *NotificationState) MarkRead(id, readAt string) bool {
+	if s.IsRead(id) {
+		return false
+	}
+	if s.Read == nil {
+		s.Read = map[string]string{}
+	}
+	s.Read[id] = readAt
+	return true
+}
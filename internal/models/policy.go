@@ -0,0 +1,41 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/timeparse"
+)
+
+// Policy is a project's retention policy, applied by buyruk tick.
+type Policy struct {
+	ArchiveDoneAfter string `json:"archive_done_after,omitempty"` // Optional: archive done issues not updated for this long, e.g. "60d"
+}
+
+// Validate validates the retention policy.
+func (p *Policy) Validate() error {
+	if p.ArchiveDoneAfter != "" {
+		if _, err := timeparse.ParseDuration(p.ArchiveDoneAfter); err != nil {
+			return fmt.Errorf("models: invalid archive_done_after: %w", err)
+		}
+	}
+	return nil
+}
+
+// IsEmpty reports whether the policy has no rules.
+func (p *Policy) IsEmpty() bool {
+	return p.ArchiveDoneAfter == ""
+}
+
+// ArchiveAge returns how long a done issue stays unchanged before it is
+// archived, or false if the policy does not archive issues.
+func (p *Policy) ArchiveAge() (time.Duration, bool) {
+	if p.ArchiveDoneAfter == "" {
+		return 0, false
+	}
+	d, err := timeparse.ParseDuration(p.ArchiveDoneAfter)
+	if err != nil {
+		return 0, false
+	}
+	return d, true
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PR providers.
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+// PR states.
+const (
+	PRStateOpen   = "open"
+	PRStateMerged = "merged"
+	PRStateClosed = "closed"
+)
+
+// PR check states: the combined result of the CI checks of a PR's head commit.
+const (
+	ChecksSuccess = "success"
+	ChecksFailure = "failure"
+	ChecksPending = "pending"
+	ChecksNone    = "none"
+)
+
+// PRRef identifies a pull request (GitHub) or merge request (GitLab).
+type PRRef struct {
+	Provider string // ProviderGitHub or ProviderGitLab
+	Host     string // e.g. github.com, or the host of a self-hosted instance
+	Repo     string // owner/repo for GitHub, the full project path for GitLab
+	Number   int
+}
+
+// ParsePRURL parses a pull request URL such as
+// https://github.com/owner/repo/pull/12 or a merge request URL such as
+// https://gitlab.com/group/sub/project/-/merge_requests/7. Hosts other than
+// github.com and gitlab.com are recognized by the URL layout.
+func ParsePRURL(rawURL string) (*PRRef, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("models: invalid PR URL %q", rawURL)
+	}
+	path := strings.Trim(u.Path, "/")
+
+	if repo, rest, ok := strings.Cut(path, "/-/merge_requests/"); ok {
+		if number, err := strconv.Atoi(strings.SplitN(rest, "/", 2)[0]); err == nil && number > 0 && strings.Contains(repo, "/") {
+			return &PRRef{Provider: ProviderGitLab, Host: u.Host, Repo: repo, Number: number}, nil
+		}
+	}
+	parts := strings.Split(path, "/")
+	if len(parts) >= 4 && parts[2] == "pull" && u.Host != "gitlab.com" {
+		if number, err := strconv.Atoi(parts[3]); err == nil && number > 0 {
+			return &PRRef{Provider: ProviderGitHub, Host: u.Host, Repo: parts[0] + "/" + parts[1], Number: number}, nil
+		}
+	}
+	return nil, fmt.Errorf("models: unrecognized PR URL %q (expected a GitHub pull request or GitLab merge request)", rawURL)
+}
+
+// PRStatus is the state of a pull request as last fetched from its provider.
+type PRStatus struct {
+	URL       string `json:"url"`
+	Title     string `json:"title,omitempty"`
+	State     string `json:"state,omitempty"`  // PRStateOpen, PRStateMerged, or PRStateClosed
+	Draft     bool   `json:"draft,omitempty"`  // Open as a draft
+	Checks    string `json:"checks,omitempty"` // ChecksSuccess, ChecksFailure, ChecksPending, or ChecksNone
+	FetchedAt string `json:"fetched_at,omitempty"`
+	Error     string `json:"error,omitempty"` // Why the status could not be fetched
+}
+
+// AllPRsMerged reports whether there is at least one PR status and all of
+// them are merged.
+func AllPRsMerged(statuses []PRStatus) bool {
+	for _, status := range statuses {
+		if status.State != PRStateMerged {
+			return false
+		}
+	}
+	return len(statuses) > 0
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// rankDigits are the digits of issue ranks, in order. A rank is a base-36
+// fraction written without "0.", so ranks compare as plain strings, and there
+// is always room for another rank between two (LexoRank-style): moving an
+// issue only changes its own rank.
+const rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// ValidateRank checks that a rank only uses rank digits and doesn't end in
+// 0, which would leave no room for a rank before it.
+func ValidateRank(rank string) error {
+	if rank == "" || strings.HasSuffix(rank, "0") || strings.Trim(rank, rankDigits) != "" {
+		return fmt.Errorf("models: invalid rank %q (must be lowercase letters and digits, not ending in 0)", rank)
+	}
+	return nil
+}
+
+// RankBetween returns a rank that sorts after before and ahead of after. An
+// empty before means the start of the order, and an empty after its end.
+// Ranks grow longer only when there is no room between neighbors, so an issue
+// moved between two ranked ones usually gets a rank one or two digits long.
+func RankBetween(before, after string) (string, error) {
+	if after != "" && before >= after {
+		return "", fmt.Errorf("models: no rank between %q and %q", before, after)
+	}
+	var rank strings.Builder
+	for i := 0; ; i++ {
+		low := 0
+		if i < len(before) {
+			low = strings.IndexByte(rankDigits, before[i])
+		}
+		high := len(rankDigits)
+		if i < len(after) {
+			high = strings.IndexByte(rankDigits, after[i])
+		}
+		if low < 0 || high < 0 {
+			return "", fmt.Errorf("models: invalid rank %q or %q", before, after)
+		}
+		if high-low > 1 {
+			rank.WriteByte(rankDigits[(low+high)/2])
+			return rank.String(), nil
+		}
+		rank.WriteByte(rankDigits[low])
+		if high > low {
+			// The rank is now ahead of after whatever follows
+			after = ""
+		}
+	}
+}
+
+// CompareRanks orders two ranks, with unranked ("") after ranked.
+func CompareRanks(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,10 @@
+package models
+
+// ProjectLock marks a project read-only, e.g. during a release freeze. It is
+// unrelated to the lock file that serializes writes: while a project has one,
+// commands refuse to change the project at all.
+type ProjectLock struct {
+	LockedAt string `json:"locked_at"`           // ISO 8601 timestamp
+	LockedBy string `json:"locked_by,omitempty"` // Who locked the project
+	Reason   string `json:"reason,omitempty"`    // Why, e.g. "v1.2 release freeze"
+}
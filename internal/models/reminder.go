@@ -0,0 +1,27 @@
+package models
+
+import "sort"
+
+// Reminder is a time at which buyruk notify reminds of an issue.
+type Reminder struct {
+	At        string `json:"at"`                   // ISO 8601 timestamp (UTC)
+	Note      string `json:"note,omitempty"`       // Optional: What to be reminded of
+	CreatedBy string `json:"created_by,omitempty"` // Who set the reminder
+}
+
+// AddReminder adds a reminder to the issue, keeping reminders ordered by time.
+// A reminder at the same time replaces the existing one; reports whether one
+// was replaced.
+func (i *Issue) AddReminder(r Reminder) bool {
+	for n := range i.Reminders {
+		if i.Reminders[n].At == r.At {
+			i.Reminders[n] = r
+			return true
+		}
+	}
+	i.Reminders = append(i.Reminders, r)
+	sort.SliceStable(i.Reminders, func(a, b int) bool {
+		return i.Reminders[a].At < i.Reminders[b].At
+	})
+	return false
+}
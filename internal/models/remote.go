@@ -0,0 +1,49 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+)
+
+// Remote is the shared copy of a project used by pull and push: a project on a
+// buyruk server, or a file in an S3 bucket or on a WebDAV share.
+type Remote struct {
+	URL      string         `json:"url"`                 // Required: project URL, e.g. http://host:7420/projects/CORE or s3://bucket/buyruk/CORE
+	ETag     string         `json:"etag,omitempty"`      // Version of the remote project at the last pull or push
+	Records  []string       `json:"records,omitempty"`   // IDs of the issues and epics of the remote project at the last pull or push
+	SyncedAt string         `json:"synced_at,omitempty"` // ISO 8601 timestamp of the last pull or push
+	Pending  *RemoteVersion `json:"pending,omitempty"`   // Pulled version whose changes await sync review
+}
+
+// RemoteVersion is a version of a remote project that was pulled but whose
+// changes are still staged for review. It becomes the remote's version once
+// every staged change is accepted or rejected, so a push cannot replace remote
+// changes nobody has reviewed.
+type RemoteVersion struct {
+	ETag      string   `json:"etag"`                 // Version of the remote project
+	Records   []string `json:"records,omitempty"`    // IDs of its issues and epics
+	FetchedAt string   `json:"fetched_at,omitempty"` // ISO 8601 timestamp of the pull
+}
+
+// BackendCopy records which version of a project in the storage backend its
+// local copy was fetched at or last written as.
+type BackendCopy struct {
+	URL      string `json:"url"`                 // Project URL in the storage backend
+	ETag     string `json:"etag"`                // Version of the project in the backend
+	Hash     string `json:"hash"`                // Content hash of the local copy at that version
+	SyncedAt string `json:"synced_at,omitempty"` // ISO 8601 timestamp of the fetch or write
+}
+
+// remoteSchemes are the URL schemes of remotes: a buyruk server, an S3
+// bucket, and a WebDAV share over HTTPS or plain HTTP.
+var remoteSchemes = []string{"http", "https", "s3", "webdav", "webdav+http"}
+
+// Validate validates the remote.
+func (r *Remote) Validate() error {
+	u, err := url.Parse(r.URL)
+	if err != nil || !slices.Contains(remoteSchemes, u.Scheme) || u.Host == "" {
+		return fmt.Errorf("models: invalid remote URL %q (expected e.g. http://host:7420/projects/CORE, s3://bucket/CORE, or webdav://host/dav/CORE)", r.URL)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// Roadmap plans epics into target quarters.
+type Roadmap struct {
+	Items []RoadmapItem `json:"items"`
+}
+
+// RoadmapItem places one epic in a target quarter.
+type RoadmapItem struct {
+	EpicID  string `json:"epic_id"`         // Epic ID; the epic may not exist yet for planned work
+	Quarter string `json:"quarter"`         // Target quarter, e.g. "2024-Q3"
+	Title   string `json:"title,omitempty"` // Optional: Title for planned epics that don't exist yet
+}
+
+var quarterRegex = regexp.MustCompile(`^(\d{4})-Q([1-4])$`)
+
+// ParseQuarter parses a quarter in "YYYY-QN" form.
+func ParseQuarter(quarter string) (year, q int, err error) {
+	m := quarterRegex.FindStringSubmatch(quarter)
+	if m == nil {
+		return 0, 0, fmt.Errorf("models: invalid quarter %q (expected format: 2024-Q3)", quarter)
+	}
+	year, _ = strconv.Atoi(m[1])
+	q, _ = strconv.Atoi(m[2])
+	return year, q, nil
+}
+
+// Set adds an item or replaces the item for the same epic
+func (r *Roadmap) Set(item RoadmapItem) {
+	for i := range r.Items {
+		if r.Items[i].EpicID == item.EpicID {
+			r.Items[i] = item
+			return
+		}
+	}
+	r.Items = append(r.Items, item)
+}
+
+// Remove removes the item for an epic. Returns false if the epic was not on the roadmap.
+func (r *Roadmap) Remove(epicID string) bool {
+	for i := range r.Items {
+		if r.Items[i].EpicID == epicID {
+			r.Items = append(r.Items[:i], r.Items[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Quarters returns the quarters on the roadmap in chronological order.
+func (r *Roadmap) Quarters() []string {
+	seen := map[string]bool{}
+	quarters := []string{}
+	for _, item := range r.Items {
+		if !seen[item.Quarter] {
+			seen[item.Quarter] = true
+			quarters = append(quarters, item.Quarter)
+		}
+	}
+	// "YYYY-QN" sorts chronologically as a string
+	sort.Strings(quarters)
+	return quarters
+}
+
+// ItemsIn returns the items planned for a quarter, in roadmap order.
+func (r *Roadmap) ItemsIn(quarter string) []RoadmapItem {
+	var items []RoadmapItem
+	for _, item := range r.Items {
+		if item.Quarter == quarter {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Validate validates the Roadmap struct
+func (r *Roadmap) Validate() error {
+	seen := map[string]bool{}
+	for _, item := range r.Items {
+		if item.EpicID == "" {
+			return fmt.Errorf("models: roadmap item epic ID is required")
+		}
+		if seen[item.EpicID] {
+			return fmt.Errorf("models: epic %q is on the roadmap more than once", item.EpicID)
+		}
+		seen[item.EpicID] = true
+		if _, _, err := ParseQuarter(item.Quarter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseQuarter(t *testing.T) {
+	year, q, err := ParseQuarter("2024-Q3")
+	if err != nil {
+		t.Fatalf("ParseQuarter() error = %v", err)
+	}
+	if year != 2024 || q != 3 {
+		t.Errorf("ParseQuarter() = %d, %d, want 2024, 3", year, q)
+	}
+
+	for _, invalid := range []string{"", "2024", "2024-Q5", "2024-q3", "24-Q1", "Q3-2024"} {
+		if _, _, err := ParseQuarter(invalid); err == nil {
+			t.Errorf("ParseQuarter(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestRoadmap_SetRemoveQuarters(t *testing.T) {
+	var r Roadmap
+	r.Set(RoadmapItem{EpicID: "E-1", Quarter: "2025-Q1"})
+	r.Set(RoadmapItem{EpicID: "E-2", Quarter: "2024-Q4"})
+	r.Set(RoadmapItem{EpicID: "E-3", Quarter: "2025-Q1"})
+
+	// Setting an existing epic moves it instead of adding a duplicate
+	r.Set(RoadmapItem{EpicID: "E-1", Quarter: "2024-Q4"})
+	if len(r.Items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(r.Items))
+	}
+
+	if got, want := r.Quarters(), []string{"2024-Q4", "2025-Q1"}; !slices.Equal(got, want) {
+		t.Errorf("Quarters() = %v, want %v", got, want)
+	}
+	if got := r.ItemsIn("2024-Q4"); len(got) != 2 || got[0].EpicID != "E-1" || got[1].EpicID != "E-2" {
+		t.Errorf("ItemsIn(2024-Q4) = %v, want E-1, E-2 in roadmap order", got)
+	}
+
+	if !r.Remove("E-3") {
+		t.Error("Remove(E-3) should return true")
+	}
+	if r.Remove("E-3") {
+		t.Error("Remove(E-3) twice should return false")
+	}
+	if got, want := r.Quarters(), []string{"2024-Q4"}; !slices.Equal(got, want) {
+		t.Errorf("Quarters() after remove = %v, want %v", got, want)
+	}
+}
+
+func TestRoadmap_Validate(t *testing.T) {
+	valid := Roadmap{Items: []RoadmapItem{{EpicID: "E-1", Quarter: "2024-Q1"}}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	invalid := []Roadmap{
+		{Items: []RoadmapItem{{EpicID: "", Quarter: "2024-Q1"}}},
+		{Items: []RoadmapItem{{EpicID: "E-1", Quarter: "soon"}}},
+		{Items: []RoadmapItem{{EpicID: "E-1", Quarter: "2024-Q1"}, {EpicID: "E-1", Quarter: "2024-Q2"}}},
+	}
+	for _, r := range invalid {
+		if err := r.Validate(); err == nil {
+			t.Errorf("Validate(%v) should fail", r.Items)
+		}
+	}
+}
+
+func TestProjectIndex_EpicProgress(t *testing.T) {
+	idx := &ProjectIndex{Issues: []IndexEntry{
+		{ID: "P-1", Status: StatusDONE, EpicID: "E-1"},
+		{ID: "P-2", Status: StatusDOING, EpicID: "E-1"},
+		{ID: "P-3", Status: StatusDONE, EpicID: "E-2"},
+		{ID: "P-4", Status: StatusDONE},
+	}}
+
+	done, total := idx.EpicProgress("E-1", nil)
+	if done != 1 || total != 2 {
+		t.Errorf("EpicProgress(E-1) = %d/%d, want 1/2", done, total)
+	}
+
+	done, total = idx.EpicProgress("E-9", nil)
+	if done != 0 || total != 0 {
+		t.Errorf("EpicProgress(E-9) = %d/%d, want 0/0", done, total)
+	}
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Rule is a validation rule every issue of a project (or of one issue type)
+// must pass when it is created or updated.
+type Rule struct {
+	Field     string `json:"field"`                // One of RuleFields
+	Type      string `json:"type,omitempty"`       // Only issues of this type (default: all)
+	Required  bool   `json:"required,omitempty"`   // The field must be set
+	MinLength int    `json:"min_length,omitempty"` // Minimum length in characters of title or description
+}
+
+// RuleFields lists the issue fields validation rules can check.
+var RuleFields = append(slices.Clone(RequirableFields), "title", "assignee", "labels", "components", "fix_version", "estimate")
+
+// lengthFields lists the rule fields a minimum length applies to.
+var lengthFields = []string{"title", "description"}
+
+// RuleViolation is a rule an issue breaks.
+type RuleViolation struct {
+	Rule    Rule   `json:"rule"`
+	Message string `json:"message"`
+}
+
+// String describes the rule, e.g. "epic required for task issues".
+func (r Rule) String() string {
+	var checks []string
+	if r.Required {
+		checks = append(checks, "required")
+	}
+	if r.MinLength > 0 {
+		checks = append(checks, fmt.Sprintf("at least %d characters", r.MinLength))
+	}
+	s := r.Field + " " + strings.Join(checks, ", ")
+	if r.Type != "" {
+		s += " for " + r.Type + " issues"
+	}
+	return s
+}
+
+// Validate validates the rule against the workflow it belongs to.
+func (r Rule) Validate(w *Workflow) error {
+	if !slices.Contains(RuleFields, r.Field) {
+		return fmt.Errorf("models: rule field %q is not supported (supported: %s)", r.Field, strings.Join(RuleFields, ", "))
+	}
+	if !r.Required && r.MinLength == 0 {
+		return fmt.Errorf("models: rule for %q checks nothing (set required or min_length)", r.Field)
+	}
+	if r.MinLength < 0 {
+		return fmt.Errorf("models: rule for %q has a negative min_length", r.Field)
+	}
+	if r.MinLength > 0 && !slices.Contains(lengthFields, r.Field) {
+		return fmt.Errorf("models: min_length only applies to %s, not %q", strings.Join(lengthFields, " and "), r.Field)
+	}
+	if r.Type != "" && !w.IsValidType(r.Type) {
+		return fmt.Errorf("models: rule for %q: type %q is not a workflow type", r.Field, r.Type)
+	}
+	return nil
+}
+
+// RuleViolations returns the rules of the workflow the issue breaks.
+func (w *Workflow) RuleViolations(issue *Issue) []RuleViolation {
+	if w == nil {
+		return nil
+	}
+	var violations []RuleViolation
+	for _, rule := range w.Rules {
+		if rule.Type != "" && rule.Type != issue.Type {
+			continue
+		}
+		if rule.Required && !issueFieldSet(issue, rule.Field) {
+			message := rule.Field + " is required"
+			if rule.Type != "" {
+				message += " for " + rule.Type + " issues"
+			}
+			violations = append(violations, RuleViolation{Rule: rule, Message: message})
+			continue
+		}
+		// The length of an encrypted description is unknown
+		if rule.MinLength > 0 && !(rule.Field == "description" && issue.Sealed != nil) {
+			text := issue.Title
+			if rule.Field == "description" {
+				text = issue.Description
+			}
+			if n := utf8.RuneCountInString(strings.TrimSpace(text)); n < rule.MinLength {
+				violations = append(violations, RuleViolation{
+					Rule:    rule,
+					Message: fmt.Sprintf("%s must be at least %d characters (has %d)", rule.Field, rule.MinLength, n),
+				})
+			}
+		}
+	}
+	return violations
+}
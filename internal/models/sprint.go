@@ -0,0 +1,102 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"time"
+)
+
+// Sprints are the time-boxed iterations of a project.
+type Sprints struct {
+	Sprints []Sprint `json:"sprints"`
+}
+
+// Sprint is an iteration from its start date to its end date, inclusive,
+// with the issues planned into it.
+type Sprint struct {
+	ID     string   `json:"id"`               // Sprint ID, e.g. "S-3"
+	Start  string   `json:"start"`            // First day, "YYYY-MM-DD"
+	End    string   `json:"end"`              // Last day, "YYYY-MM-DD"
+	Goal   string   `json:"goal,omitempty"`   // Optional: Sprint goal
+	Issues []string `json:"issues,omitempty"` // IDs of the issues planned into the sprint
+}
+
+var sprintIDRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateSprintID validates a sprint ID
+func ValidateSprintID(id string) error {
+	if !sprintIDRegex.MatchString(id) {
+		return fmt.Errorf("models: invalid sprint ID %q (use letters, digits, '.', '_', and '-', e.g. S-3)", id)
+	}
+	return nil
+}
+
+// Find returns the sprint with an ID, or nil.
+func (s *Sprints) Find(id string) *Sprint {
+	for i := range s.Sprints {
+		if s.Sprints[i].ID == id {
+			return &s.Sprints[i]
+		}
+	}
+	return nil
+}
+
+// Set adds a sprint or replaces the sprint with the same ID, keeping sprints
+// ordered by start date.
+func (s *Sprints) Set(sprint Sprint) {
+	if existing := s.Find(sprint.ID); existing != nil {
+		*existing = sprint
+	} else {
+		s.Sprints = append(s.Sprints, sprint)
+	}
+	// "YYYY-MM-DD" sorts chronologically as a string
+	sort.SliceStable(s.Sprints, func(i, j int) bool { return s.Sprints[i].Start < s.Sprints[j].Start })
+}
+
+// Days returns the days of the sprint, at midnight in loc.
+func (s *Sprint) Days(loc *time.Location) []time.Time {
+	start, errStart := time.ParseInLocation(time.DateOnly, s.Start, loc)
+	end, errEnd := time.ParseInLocation(time.DateOnly, s.End, loc)
+	if errStart != nil || errEnd != nil {
+		return nil
+	}
+	var days []time.Time
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days
+}
+
+// Validate validates the Sprints struct
+func (s *Sprints) Validate() error {
+	seen := map[string]bool{}
+	for _, sprint := range s.Sprints {
+		if err := ValidateSprintID(sprint.ID); err != nil {
+			return err
+		}
+		if seen[sprint.ID] {
+			return fmt.Errorf("models: sprint %q is defined more than once", sprint.ID)
+		}
+		seen[sprint.ID] = true
+
+		start, err := time.Parse(time.DateOnly, sprint.Start)
+		if err != nil {
+			return fmt.Errorf("models: sprint %s has an invalid start date %q (expected format: 2024-06-01)", sprint.ID, sprint.Start)
+		}
+		end, err := time.Parse(time.DateOnly, sprint.End)
+		if err != nil {
+			return fmt.Errorf("models: sprint %s has an invalid end date %q (expected format: 2024-06-01)", sprint.ID, sprint.End)
+		}
+		if end.Before(start) {
+			return fmt.Errorf("models: sprint %s ends before it starts", sprint.ID)
+		}
+		for i, issueID := range sprint.Issues {
+			if slices.Contains(sprint.Issues[:i], issueID) {
+				return fmt.Errorf("models: issue %s is in sprint %s more than once", issueID, sprint.ID)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSprints_SetFind(t *testing.T) {
+	var s Sprints
+	s.Set(Sprint{ID: "S-2", Start: "2024-06-15", End: "2024-06-28"})
+	s.Set(Sprint{ID: "S-1", Start: "2024-06-01", End: "2024-06-14"})
+	s.Set(Sprint{ID: "S-2", Start: "2024-06-15", End: "2024-06-30"})
+
+	if len(s.Sprints) != 2 || s.Sprints[0].ID != "S-1" || s.Sprints[1].ID != "S-2" {
+		t.Fatalf("Sprints = %+v, want S-1, S-2 by start date", s.Sprints)
+	}
+	if sprint := s.Find("S-2"); sprint == nil || sprint.End != "2024-06-30" {
+		t.Errorf("Find(S-2) = %+v, want the replaced sprint", sprint)
+	}
+	if s.Find("S-9") != nil {
+		t.Error("Find(S-9) should return nil")
+	}
+}
+
+func TestSprint_Days(t *testing.T) {
+	sprint := Sprint{ID: "S-1", Start: "2024-03-30", End: "2024-04-02"}
+	days := sprint.Days(time.UTC)
+	if len(days) != 4 || days[0].Format(time.DateOnly) != "2024-03-30" || days[3].Format(time.DateOnly) != "2024-04-02" {
+		t.Errorf("Days() = %v, want 2024-03-30 to 2024-04-02", days)
+	}
+}
+
+func TestSprints_Validate(t *testing.T) {
+	valid := Sprints{Sprints: []Sprint{{ID: "S-1", Start: "2024-06-01", End: "2024-06-01", Issues: []string{"CORE-1"}}}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	invalid := []Sprints{
+		{Sprints: []Sprint{{ID: "", Start: "2024-06-01", End: "2024-06-14"}}},
+		{Sprints: []Sprint{{ID: "S 1", Start: "2024-06-01", End: "2024-06-14"}}},
+		{Sprints: []Sprint{{ID: "S-1", Start: "June", End: "2024-06-14"}}},
+		{Sprints: []Sprint{{ID: "S-1", Start: "2024-06-14", End: "2024-06-01"}}},
+		{Sprints: []Sprint{{ID: "S-1", Start: "2024-06-01", End: "2024-06-14", Issues: []string{"CORE-1", "CORE-1"}}}},
+		{Sprints: []Sprint{{ID: "S-1", Start: "2024-06-01", End: "2024-06-14"}, {ID: "S-1", Start: "2024-06-15", End: "2024-06-28"}}},
+	}
+	for i, s := range invalid {
+		if err := s.Validate(); err == nil {
+			t.Errorf("invalid[%d].Validate() should fail", i)
+		}
+	}
+}
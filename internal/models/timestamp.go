@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// NormalizeTimestamp converts an RFC3339 timestamp to UTC.
+// Empty or unparsable values are returned unchanged.
+func NormalizeTimestamp(value string) string {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// normalizeTimestamps normalizes each timestamp in place and reports whether any changed.
+func normalizeTimestamps(values ...*string) bool {
+	changed := false
+	for _, v := range values {
+		if normalized := NormalizeTimestamp(*v); normalized != *v {
+			*v = normalized
+			changed = true
+		}
+	}
+	return changed
+}
+
+// NormalizeTimestamps converts the issue's timestamps to UTC.
+// Returns true if any timestamp changed.
+func (i *Issue) NormalizeTimestamps() bool {
+	changed := normalizeTimestamps(&i.CreatedAt, &i.UpdatedAt, &i.Due)
+	for c := range i.Comments {
+		if normalizeTimestamps(&i.Comments[c].CreatedAt) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// NormalizeTimestamps converts the epic's timestamps to UTC.
+// Returns true if any timestamp changed.
+func (e *Epic) NormalizeTimestamps() bool {
+	return normalizeTimestamps(&e.CreatedAt, &e.UpdatedAt)
+}
+
+// NormalizeTimestamps converts the index's timestamps to UTC.
+// Returns true if any timestamp changed.
+func (idx *ProjectIndex) NormalizeTimestamps() bool {
+	return normalizeTimestamps(&idx.CreatedAt, &idx.UpdatedAt)
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// TrashedIssue is a deleted issue kept in a project's trash until the trash
+// is emptied.
+type TrashedIssue struct {
+	ID        string          `json:"id"`                   // Issue ID
+	DeletedAt string          `json:"deleted_at"`           // ISO 8601 timestamp
+	DeletedBy string          `json:"deleted_by,omitempty"` // Who deleted the issue
+	Issue     json.RawMessage `json:"issue"`                // Issue file content as it was deleted
+}
+
+// ParseIssue decodes the issue kept in the trash.
+func (t *TrashedIssue) ParseIssue() (*Issue, error) {
+	var issue Issue
+	if err := json.Unmarshal(t.Issue, &issue); err != nil {
+		return nil, fmt.Errorf("models: invalid trashed issue %s: %w", t.ID, err)
+	}
+	return &issue, nil
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"crypto/rand"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ID modes of a project
+const (
+	IDModeSequence = "sequence" // Sequential IDs only (CORE-1, CORE-2, ...)
+	IDModeULID     = "ulid"     // Sequential IDs for display, plus a ULID uid per issue
+)
+
+// ValidIDModes lists the supported ID modes.
+var ValidIDModes = []string{IDModeSequence, IDModeULID}
+
+// ulidAlphabet is Crockford's base32 alphabet used by ULIDs.
+const ulidAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewULID returns a new ULID: a 48-bit millisecond timestamp followed by 80
+// random bits, encoded as 26 characters of Crockford base32. ULIDs sort by
+// creation time and are unique across devices without coordination.
+func NewULID(t time.Time) (string, error) {
+	var data [16]byte
+	ms := uint64(t.UnixMilli())
+	for i := 5; i >= 0; i-- {
+		data[i] = byte(ms)
+		ms >>= 8
+	}
+	if _, err := rand.Read(data[6:]); err != nil {
+		return "", fmt.Errorf("models: failed to generate ULID: %w", err)
+	}
+
+	// 128 bits in 26 characters: the first holds the top 3 bits
+	var out [26]byte
+	var acc uint64
+	bits := 2 // 130 encoded bits, so the top 2 are zero padding
+	pos := 0
+	for _, b := range data {
+		acc = acc<<8 | uint64(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			out[pos] = ulidAlphabet[(acc>>uint(bits))&0x1f]
+			pos++
+		}
+	}
+	return string(out[:]), nil
+}
+
+// IsValidULID reports whether s is a well-formed ULID.
+func IsValidULID(s string) bool {
+	if len(s) != 26 || s[0] > '7' {
+		return false
+	}
+	for _, c := range s {
+		if !strings.ContainsRune(ulidAlphabet, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsValidIDMode reports whether mode is a supported ID mode.
+func IsValidIDMode(mode string) bool {
+	return slices.Contains(ValidIDModes, mode)
+}
+
+// UsesULID reports whether issues of the project get a ULID uid.
+func (idx *ProjectIndex) UsesULID() bool {
+	return idx.IDMode == IDModeULID
+}
+
+// AssignUID gives an issue a ULID uid if the project uses them and the issue
+// has none yet. Existing uids are kept, so an issue keeps its identity when it
+// is moved, renamed, or imported.
+func (idx *ProjectIndex) AssignUID(issue *Issue, now time.Time) error {
+	if !idx.UsesULID() || issue.UID != "" {
+		return nil
+	}
+	uid, err := NewULID(now)
+	if err != nil {
+		return err
+	}
+	issue.UID = uid
+	return nil
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// SavedViews are named issue filters, saved with buyruk view save.
+type SavedViews struct {
+	Views []SavedView `json:"views"`
+}
+
+// SavedView is a named list filter and sort order.
+type SavedView struct {
+	Name        string `json:"name"`                  // View name, e.g. "my-bugs"
+	Query       string `json:"query"`                 // Query filter, e.g. "type=bug and status!=DONE"
+	Sort        string `json:"sort,omitempty"`        // Optional: list --sort value, e.g. "priority:desc"
+	Description string `json:"description,omitempty"` // Optional: What the view is for
+	CreatedAt   string `json:"created_at,omitempty"`  // ISO 8601 timestamp
+}
+
+var viewNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateViewName validates a saved view name
+func ValidateViewName(name string) error {
+	if !viewNameRegex.MatchString(name) {
+		return fmt.Errorf("models: invalid view name %q (use letters, digits, '.', '_', and '-', e.g. my-bugs)", name)
+	}
+	return nil
+}
+
+// Find returns the view with a name, or nil.
+func (v *SavedViews) Find(name string) *SavedView {
+	for i := range v.Views {
+		if v.Views[i].Name == name {
+			return &v.Views[i]
+		}
+	}
+	return nil
+}
+
+// Set adds a view or replaces the view with the same name, keeping views
+// ordered by name.
+func (v *SavedViews) Set(view SavedView) {
+	if existing := v.Find(view.Name); existing != nil {
+		*existing = view
+	} else {
+		v.Views = append(v.Views, view)
+	}
+	sort.SliceStable(v.Views, func(i, j int) bool { return v.Views[i].Name < v.Views[j].Name })
+}
+
+// Validate validates the SavedViews struct. Queries are parsed when a view
+// is saved or run.
+func (v *SavedViews) Validate() error {
+	seen := map[string]bool{}
+	for _, view := range v.Views {
+		if err := ValidateViewName(view.Name); err != nil {
+			return err
+		}
+		if seen[view.Name] {
+			return fmt.Errorf("models: view %q is defined more than once", view.Name)
+		}
+		seen[view.Name] = true
+		if view.Query == "" {
+			return fmt.Errorf("models: view %s has no query", view.Name)
+		}
+	}
+	return nil
+}
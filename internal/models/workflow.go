@@ -0,0 +1,277 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Workflow defines the statuses, types, and priorities allowed in a project.
+// Empty lists fall back to the global defaults, and a nil *Workflow behaves
+// like DefaultWorkflow(), so callers never need to special-case projects
+// without a workflow file.
+type Workflow struct {
+	Statuses       []string            `json:"statuses,omitempty"`        // Ordered statuses; the first is the default for new issues
+	Types          []string            `json:"types,omitempty"`           // Allowed issue types
+	Priorities     []string            `json:"priorities,omitempty"`      // Ordered from lowest to highest
+	DoneStatuses   []string            `json:"done_statuses,omitempty"`   // Statuses that count as completed (default: DONE)
+	Transitions    map[string][]string `json:"transitions,omitempty"`     // Allowed next statuses by current status; unlisted statuses are unrestricted
+	RequiredFields map[string][]string `json:"required_fields,omitempty"` // Fields that must be set to enter a status
+	Rules          []Rule              `json:"rules,omitempty"`           // Validation rules checked on issue create and update
+	WIPLimits      map[string]int      `json:"wip_limits,omitempty"`      // Most issues allowed in a status at once
+	WIPMode        string              `json:"wip_mode,omitempty"`        // What moving an issue over a WIP limit does: warn (default) or refuse
+}
+
+// WIP limit modes: warn about, or refuse, moving an issue into a status that
+// is at its limit.
+const (
+	WIPWarn   = "warn"
+	WIPRefuse = "refuse"
+)
+
+// RequirableFields lists the issue fields that can be required by a workflow.
+var RequirableFields = []string{"description", "priority", "resolution", "epic", "due", "prs"}
+
+// DefaultWorkflow returns the built-in workflow (TODO/DOING/DONE, task/bug/epic, LOW..CRITICAL).
+func DefaultWorkflow() *Workflow {
+	return &Workflow{
+		Statuses:     slices.Clone(ValidStatuses),
+		Types:        slices.Clone(ValidTypes),
+		Priorities:   slices.Clone(ValidPriorities),
+		DoneStatuses: []string{StatusDONE},
+	}
+}
+
+// StatusList returns the effective list of statuses.
+func (w *Workflow) StatusList() []string {
+	if w == nil || len(w.Statuses) == 0 {
+		return ValidStatuses
+	}
+	return w.Statuses
+}
+
+// TypeList returns the effective list of types.
+func (w *Workflow) TypeList() []string {
+	if w == nil || len(w.Types) == 0 {
+		return ValidTypes
+	}
+	return w.Types
+}
+
+// PriorityList returns the effective list of priorities, lowest first.
+func (w *Workflow) PriorityList() []string {
+	if w == nil || len(w.Priorities) == 0 {
+		return ValidPriorities
+	}
+	return w.Priorities
+}
+
+// DoneStatusList returns the effective list of completed statuses.
+func (w *Workflow) DoneStatusList() []string {
+	if w == nil || len(w.DoneStatuses) == 0 {
+		return []string{StatusDONE}
+	}
+	return w.DoneStatuses
+}
+
+// DefaultStatus returns the status assigned to new issues (the first workflow status).
+func (w *Workflow) DefaultStatus() string {
+	return w.StatusList()[0]
+}
+
+// InProgressStatus returns the status of work that has started: the first
+// workflow status that is neither the default nor a done status, or the
+// default status if there is none.
+func (w *Workflow) InProgressStatus() string {
+	for _, status := range w.StatusList()[1:] {
+		if !w.IsDoneStatus(status) {
+			return status
+		}
+	}
+	return w.DefaultStatus()
+}
+
+// IsValidStatus checks if the status is allowed by the workflow
+func (w *Workflow) IsValidStatus(s string) bool {
+	return slices.Contains(w.StatusList(), s)
+}
+
+// IsValidType checks if the type is allowed by the workflow
+func (w *Workflow) IsValidType(t string) bool {
+	return slices.Contains(w.TypeList(), t)
+}
+
+// IsValidPriority checks if the priority is allowed by the workflow
+func (w *Workflow) IsValidPriority(p string) bool {
+	return slices.Contains(w.PriorityList(), p)
+}
+
+// IsDoneStatus checks if the status counts as completed
+func (w *Workflow) IsDoneStatus(s string) bool {
+	return slices.Contains(w.DoneStatusList(), s)
+}
+
+// PriorityRank returns the position of the priority in the workflow (0 = lowest).
+// Returns -1 for empty or unknown priorities.
+func (w *Workflow) PriorityRank(p string) int {
+	return slices.Index(w.PriorityList(), p)
+}
+
+// NextStatuses returns the statuses an issue may move to from the given status.
+// Returns false if the workflow does not restrict transitions from that status.
+func (w *Workflow) NextStatuses(from string) ([]string, bool) {
+	if w == nil {
+		return nil, false
+	}
+	next, ok := w.Transitions[from]
+	return next, ok
+}
+
+// CanTransition checks if an issue may move from one status to another.
+// Staying in the same status is always allowed.
+func (w *Workflow) CanTransition(from, to string) bool {
+	if from == to {
+		return true
+	}
+	next, ok := w.NextStatuses(from)
+	if !ok {
+		return true
+	}
+	return slices.Contains(next, to)
+}
+
+// WIPLimit returns the most issues allowed in a status at once, or 0 if the
+// status has no limit.
+func (w *Workflow) WIPLimit(status string) int {
+	if w == nil {
+		return 0
+	}
+	return w.WIPLimits[status]
+}
+
+// RefusesOverWIP reports whether moving an issue over a WIP limit is refused
+// rather than only warned about.
+func (w *Workflow) RefusesOverWIP() bool {
+	return w != nil && w.WIPMode == WIPRefuse
+}
+
+// MissingFields returns the required fields for entering status that are not set on issue.
+func (w *Workflow) MissingFields(issue *Issue, status string) []string {
+	if w == nil {
+		return nil
+	}
+	var missing []string
+	for _, field := range w.RequiredFields[status] {
+		if !issueFieldSet(issue, field) {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
+
+// issueFieldSet reports whether a requirable field has a value on the issue.
+func issueFieldSet(issue *Issue, field string) bool {
+	switch field {
+	case "description":
+		return issue.Description != "" || issue.Sealed != nil
+	case "priority":
+		return issue.Priority != ""
+	case "resolution":
+		return issue.Resolution != ""
+	case "epic":
+		return issue.EpicID != ""
+	case "due":
+		return issue.Due != ""
+	case "prs":
+		return len(issue.PRs) > 0
+	case "title":
+		return strings.TrimSpace(issue.Title) != ""
+	case "assignee":
+		return issue.Assignee != ""
+	case "labels":
+		return len(issue.Labels) > 0
+	case "components":
+		return len(issue.Components) > 0
+	case "fix_version":
+		return issue.FixVersion != ""
+	case "estimate":
+		return issue.Estimate != ""
+	default:
+		return false
+	}
+}
+
+// Validate validates the Workflow struct
+func (w *Workflow) Validate() error {
+	lists := []struct {
+		name   string
+		values []string
+	}{
+		{"statuses", w.Statuses},
+		{"types", w.Types},
+		{"priorities", w.Priorities},
+		{"done_statuses", w.DoneStatuses},
+	}
+
+	for _, list := range lists {
+		seen := map[string]bool{}
+		for _, v := range list.values {
+			if strings.TrimSpace(v) == "" || strings.ContainsAny(v, " \t,") {
+				return fmt.Errorf("models: invalid workflow %s value %q", list.name, v)
+			}
+			if seen[v] {
+				return fmt.Errorf("models: duplicate workflow %s value %q", list.name, v)
+			}
+			seen[v] = true
+		}
+	}
+
+	for _, s := range w.DoneStatuses {
+		if !w.IsValidStatus(s) {
+			return fmt.Errorf("models: done status %q is not a workflow status", s)
+		}
+	}
+
+	for from, next := range w.Transitions {
+		if !w.IsValidStatus(from) {
+			return fmt.Errorf("models: transition from %q: not a workflow status", from)
+		}
+		for _, to := range next {
+			if !w.IsValidStatus(to) {
+				return fmt.Errorf("models: transition from %q to %q: not a workflow status", from, to)
+			}
+		}
+	}
+
+	for status, fields := range w.RequiredFields {
+		if !w.IsValidStatus(status) {
+			return fmt.Errorf("models: required fields for %q: not a workflow status", status)
+		}
+		for _, field := range fields {
+			if !slices.Contains(RequirableFields, field) {
+				return fmt.Errorf("models: required field %q for %q is not supported (supported: %s)", field, status, strings.Join(RequirableFields, ", "))
+			}
+		}
+	}
+
+	for _, rule := range w.Rules {
+		if err := rule.Validate(w); err != nil {
+			return err
+		}
+	}
+
+	for status, limit := range w.WIPLimits {
+		if !w.IsValidStatus(status) {
+			return fmt.Errorf("models: WIP limit for %q: not a workflow status", status)
+		}
+		if limit < 1 {
+			return fmt.Errorf("models: WIP limit for %q must be at least 1, got %d", status, limit)
+		}
+	}
+
+	if w.WIPMode != "" && w.WIPMode != WIPWarn && w.WIPMode != WIPRefuse {
+		return fmt.Errorf("models: invalid WIP mode %q (must be %s or %s)", w.WIPMode, WIPWarn, WIPRefuse)
+	}
+
+	return nil
+}
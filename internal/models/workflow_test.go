@@ -0,0 +1,234 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWorkflow_NilUsesDefaults(t *testing.T) {
+	var wf *Workflow
+
+	if !wf.IsValidStatus(StatusDOING) {
+		t.Error("nil workflow should accept default status DOING")
+	}
+	if wf.IsValidStatus("REVIEW") {
+		t.Error("nil workflow should reject unknown status REVIEW")
+	}
+	if !wf.IsValidType(TypeBug) {
+		t.Error("nil workflow should accept default type bug")
+	}
+	if !wf.IsValidPriority(PriorityCRITICAL) {
+		t.Error("nil workflow should accept default priority CRITICAL")
+	}
+	if wf.DefaultStatus() != StatusTODO {
+		t.Errorf("DefaultStatus() = %q, want %q", wf.DefaultStatus(), StatusTODO)
+	}
+	if !wf.IsDoneStatus(StatusDONE) {
+		t.Error("nil workflow should treat DONE as done")
+	}
+	if wf.InProgressStatus() != StatusDOING {
+		t.Errorf("InProgressStatus() = %q, want %q", wf.InProgressStatus(), StatusDOING)
+	}
+}
+
+func TestWorkflow_Custom(t *testing.T) {
+	wf := &Workflow{
+		Statuses:     []string{"BACKLOG", "DOING", "REVIEW", "SHIPPED"},
+		Priorities:   []string{"P3", "P2", "P1"},
+		DoneStatuses: []string{"SHIPPED"},
+	}
+
+	tests := []struct {
+		name string
+		got  bool
+		want bool
+	}{
+		{"custom status", wf.IsValidStatus("REVIEW"), true},
+		{"removed default status", wf.IsValidStatus(StatusTODO), false},
+		{"default types still apply", wf.IsValidType(TypeTask), true},
+		{"custom priority", wf.IsValidPriority("P1"), true},
+		{"default priority not allowed", wf.IsValidPriority(PriorityHIGH), false},
+		{"custom done status", wf.IsDoneStatus("SHIPPED"), true},
+		{"DONE not configured", wf.IsDoneStatus(StatusDONE), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+
+	if wf.DefaultStatus() != "BACKLOG" {
+		t.Errorf("DefaultStatus() = %q, want BACKLOG", wf.DefaultStatus())
+	}
+	if wf.InProgressStatus() != "DOING" {
+		t.Errorf("InProgressStatus() = %q, want DOING", wf.InProgressStatus())
+	}
+	if two := (&Workflow{Statuses: []string{"OPEN", "CLOSED"}, DoneStatuses: []string{"CLOSED"}}); two.InProgressStatus() != "OPEN" {
+		t.Errorf("InProgressStatus() = %q, want the default OPEN without a started status", two.InProgressStatus())
+	}
+	if wf.PriorityRank("P1") != 2 || wf.PriorityRank("") != -1 {
+		t.Errorf("PriorityRank() returned unexpected values: P1=%d empty=%d", wf.PriorityRank("P1"), wf.PriorityRank(""))
+	}
+}
+
+func TestWorkflow_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		wf      Workflow
+		wantErr bool
+	}{
+		{"default", *DefaultWorkflow(), false},
+		{"empty uses defaults", Workflow{}, false},
+		{"duplicate status", Workflow{Statuses: []string{"TODO", "TODO"}}, true},
+		{"blank status", Workflow{Statuses: []string{"TODO", " "}}, true},
+		{"status with space", Workflow{Statuses: []string{"IN PROGRESS"}}, true},
+		{"unknown done status", Workflow{Statuses: []string{"OPEN", "CLOSED"}, DoneStatuses: []string{"DONE"}}, true},
+		{"valid done status", Workflow{Statuses: []string{"OPEN", "CLOSED"}, DoneStatuses: []string{"CLOSED"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.wf.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDefaultWorkflow_IsCopy(t *testing.T) {
+	wf := DefaultWorkflow()
+	wf.Statuses[0] = "CHANGED"
+	if !slices.Contains(ValidStatuses, StatusTODO) {
+		t.Error("DefaultWorkflow() should not share slices with package defaults")
+	}
+}
+
+func TestIssue_ValidateWithWorkflow(t *testing.T) {
+	wf := &Workflow{Statuses: []string{"TODO", "REVIEW", "DONE"}, Types: []string{"story"}}
+
+	issue := &Issue{Title: "Custom", Status: "REVIEW", Type: "story"}
+	if err := issue.ValidateWithWorkflow(wf); err != nil {
+		t.Errorf("ValidateWithWorkflow() unexpected error: %v", err)
+	}
+	if err := issue.Validate(); err == nil {
+		t.Error("Validate() should reject custom status without workflow")
+	}
+
+	index := &ProjectIndex{ProjectKey: "CORE", Issues: []IndexEntry{{ID: "CORE-1", Status: "REVIEW", Type: "story"}}}
+	if err := index.ValidateWithWorkflow(wf); err != nil {
+		t.Errorf("ProjectIndex.ValidateWithWorkflow() unexpected error: %v", err)
+	}
+
+	epic := &Epic{ID: "E-1", Title: "Epic", Status: "REVIEW"}
+	if err := epic.ValidateWithWorkflow(wf); err != nil {
+		t.Errorf("Epic.ValidateWithWorkflow() unexpected error: %v", err)
+	}
+}
+
+func TestWorkflow_Transitions(t *testing.T) {
+	wf := &Workflow{
+		Statuses:    []string{"TODO", "DOING", "REVIEW", "DONE"},
+		Transitions: map[string][]string{"TODO": {"DOING"}, "DOING": {"REVIEW", "TODO"}},
+	}
+
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{"TODO", "DOING", true},
+		{"TODO", "DONE", false},
+		{"DOING", "REVIEW", true},
+		{"TODO", "TODO", true},
+		{"REVIEW", "DONE", true}, // no rule for REVIEW
+	}
+	for _, tt := range tests {
+		if got := wf.CanTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransition(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+
+	var nilWf *Workflow
+	if !nilWf.CanTransition(StatusTODO, StatusDONE) {
+		t.Error("nil workflow should allow any transition")
+	}
+}
+
+func TestWorkflow_MissingFields(t *testing.T) {
+	wf := &Workflow{RequiredFields: map[string][]string{"DONE": {"resolution", "prs"}}}
+
+	issue := &Issue{Title: "Test", PRs: []string{"https://example.com/pr/1"}}
+	if got := wf.MissingFields(issue, "DONE"); !slices.Equal(got, []string{"resolution"}) {
+		t.Errorf("MissingFields() = %v, want [resolution]", got)
+	}
+
+	issue.Resolution = "fixed"
+	if got := wf.MissingFields(issue, "DONE"); len(got) != 0 {
+		t.Errorf("MissingFields() = %v, want none", got)
+	}
+	if got := wf.MissingFields(&Issue{}, "DOING"); len(got) != 0 {
+		t.Errorf("MissingFields() for unrestricted status = %v, want none", got)
+	}
+}
+
+func TestWorkflow_ValidateRules(t *testing.T) {
+	tests := []struct {
+		name string
+		wf   *Workflow
+	}{
+		{"unknown transition source", &Workflow{Transitions: map[string][]string{"REVIEW": {"DONE"}}}},
+		{"unknown transition target", &Workflow{Transitions: map[string][]string{"TODO": {"REVIEW"}}}},
+		{"unknown required status", &Workflow{RequiredFields: map[string][]string{"CLOSED": {"resolution"}}}},
+		{"unsupported required field", &Workflow{RequiredFields: map[string][]string{"DONE": {"owner"}}}},
+		{"unsupported rule field", &Workflow{Rules: []Rule{{Field: "owner", Required: true}}}},
+		{"rule without checks", &Workflow{Rules: []Rule{{Field: "priority"}}}},
+		{"min length of a list field", &Workflow{Rules: []Rule{{Field: "labels", MinLength: 2}}}},
+		{"rule for an unknown type", &Workflow{Rules: []Rule{{Field: "epic", Type: "story", Required: true}}}},
+		{"WIP limit for an unknown status", &Workflow{WIPLimits: map[string]int{"REVIEW": 2}}},
+		{"WIP limit below 1", &Workflow{WIPLimits: map[string]int{"DOING": 0}}},
+		{"unknown WIP mode", &Workflow{WIPMode: "block"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.wf.Validate(); err == nil {
+				t.Error("Validate() should fail")
+			}
+		})
+	}
+}
+
+func TestWorkflow_RuleViolations(t *testing.T) {
+	wf := &Workflow{Rules: []Rule{
+		{Field: "priority", Required: true},
+		{Field: "description", MinLength: 10},
+		{Field: "epic", Type: TypeTask, Required: true},
+	}}
+
+	issue := &Issue{Title: "Test", Type: TypeBug, Description: "Too short"}
+	var messages []string
+	for _, v := range wf.RuleViolations(issue) {
+		messages = append(messages, v.Message)
+	}
+	want := []string{"priority is required", "description must be at least 10 characters (has 9)"}
+	if !slices.Equal(messages, want) {
+		t.Errorf("RuleViolations() = %q, want %q", messages, want)
+	}
+
+	issue.Type = TypeTask
+	issue.Priority = PriorityHIGH
+	issue.Description = "Long enough now"
+	if got := wf.RuleViolations(issue); len(got) != 1 || got[0].Message != "epic is required for task issues" {
+		t.Errorf("RuleViolations() = %v, want only the task epic rule", got)
+	}
+
+	// The length of an encrypted description is not checked
+	issue.EpicID = "E-1"
+	issue.Description = ""
+	issue.Sealed = &Sealed{}
+	if got := wf.RuleViolations(issue); len(got) != 0 {
+		t.Errorf("RuleViolations() = %v, want none", got)
+	}
+}
@@ -0,0 +1,203 @@
+// Package secret encrypts the description and comments of sensitive issues with
+// passphrase-derived project keys, and keeps unlocked keys for a shell session.
+package secret
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+// DefaultKey is the name of a project's default key.
+const DefaultKey = "project"
+
+// keyIterations is the PBKDF2-SHA256 iteration count for new keys.
+const keyIterations = 600000
+
+// checkValue is encrypted with a key to verify passphrases.
+const checkValue = "buyruk"
+
+// ErrWrongPassphrase is returned when a passphrase does not match a key.
+var ErrWrongPassphrase = errors.New("secret: wrong passphrase")
+
+// KeyInfo describes a passphrase-derived key without storing the key itself.
+type KeyInfo struct {
+	Salt       string `json:"salt"`       // Base64 PBKDF2 salt
+	Iterations int    `json:"iterations"` // PBKDF2 iteration count
+	Check      string `json:"check"`      // checkValue sealed with the key, to verify passphrases
+	CreatedAt  string `json:"created_at,omitempty"`
+}
+
+// NewKeyInfo creates a key from a passphrase. Returns the key info to store and the key.
+func NewKeyInfo(ctx context.Context, passphrase string) (*KeyInfo, []byte, error) {
+	if passphrase == "" {
+		return nil, nil, fmt.Errorf("secret: passphrase cannot be empty")
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, nil, fmt.Errorf("secret: failed to generate salt: %w", err)
+	}
+	info := &KeyInfo{
+		Salt:       base64.StdEncoding.EncodeToString(salt),
+		Iterations: keyIterations,
+		CreatedAt:  storage.Timestamp(ctx),
+	}
+	key, err := info.derive(passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+	if info.Check, err = Seal(key, []byte(checkValue)); err != nil {
+		return nil, nil, err
+	}
+	return info, key, nil
+}
+
+// Unlock derives the key from a passphrase, returning ErrWrongPassphrase if it does not match.
+func (k *KeyInfo) Unlock(passphrase string) ([]byte, error) {
+	key, err := k.derive(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if check, err := Open(key, k.Check); err != nil || string(check) != checkValue {
+		return nil, ErrWrongPassphrase
+	}
+	return key, nil
+}
+
+// derive derives the AES-256 key of a passphrase.
+func (k *KeyInfo) derive(passphrase string) ([]byte, error) {
+	salt, err := base64.StdEncoding.DecodeString(k.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("secret: invalid key salt: %w", err)
+	}
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, k.Iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("secret: failed to derive key: %w", err)
+	}
+	return key, nil
+}
+
+// LoadKeys returns the keys of a project by name. Returns an empty map if the
+// project has no keys yet.
+func LoadKeys(ctx context.Context, projectKey string) (map[string]*KeyInfo, error) {
+	keysPath, err := storage.KeysPath(ctx, projectKey)
+	if err != nil {
+		return nil, err
+	}
+	keys := map[string]*KeyInfo{}
+	if err := storage.ReadJSON(ctx, keysPath, &keys); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("secret: failed to load keys: %w", err)
+	}
+	return keys, nil
+}
+
+// AddKey stores a new key of a project. It fails if a key with the name exists.
+func AddKey(ctx context.Context, projectKey, name string, info *KeyInfo) error {
+	keysPath, err := storage.KeysPath(ctx, projectKey)
+	if err != nil {
+		return err
+	}
+	keys := map[string]*KeyInfo{}
+	return storage.UpdateJSONAtomic(ctx, keysPath, &keys, func(v interface{}) error {
+		keys := *v.(*map[string]*KeyInfo)
+		if _, ok := keys[name]; ok {
+			return fmt.Errorf("secret: key %q already exists", name)
+		}
+		keys[name] = info
+		return nil
+	})
+}
+
+// Seal encrypts plaintext with AES-GCM and returns the base64 nonce and ciphertext.
+func Seal(key, plaintext []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("secret: failed to generate nonce: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, plaintext, nil)), nil
+}
+
+// Open decrypts data sealed with Seal.
+func Open(key []byte, sealed string) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("secret: invalid sealed data")
+	}
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("secret: failed to decrypt (wrong key or corrupted data)")
+	}
+	return plaintext, nil
+}
+
+// newGCM returns an AES-GCM cipher for the key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("secret: invalid key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealedFields are the issue fields encrypted at rest.
+type sealedFields struct {
+	Description string           `json:"description,omitempty"`
+	Comments    []models.Comment `json:"comments,omitempty"`
+}
+
+// SealIssue encrypts the description and comments of an issue with the named
+// key, clearing them from the plain fields, and marks the issue sensitive.
+func SealIssue(issue *models.Issue, keyName string, key []byte) error {
+	data, err := json.Marshal(sealedFields{Description: issue.Description, Comments: issue.Comments})
+	if err != nil {
+		return fmt.Errorf("secret: failed to marshal sealed fields: %w", err)
+	}
+	sealed, err := Seal(key, data)
+	if err != nil {
+		return err
+	}
+	issue.Sensitive = true
+	issue.Sealed = &models.Sealed{Key: keyName, Data: sealed}
+	issue.Description = ""
+	issue.Comments = nil
+	return nil
+}
+
+// OpenIssue decrypts the description and comments of a sealed issue into the
+// plain fields. The issue stays marked sensitive.
+func OpenIssue(issue *models.Issue, key []byte) error {
+	if issue.Sealed == nil {
+		return nil
+	}
+	data, err := Open(key, issue.Sealed.Data)
+	if err != nil {
+		return err
+	}
+	var fields sealedFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("secret: failed to parse sealed fields: %w", err)
+	}
+	issue.Description = fields.Description
+	issue.Comments = fields.Comments
+	issue.Sealed = nil
+	return nil
+}
@@ -0,0 +1,114 @@
+package secret
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+)
+
+func TestKeyInfo(t *testing.T) {
+	info, key, err := NewKeyInfo(t.Context(), "correct horse")
+	if err != nil {
+		t.Fatalf("NewKeyInfo() failed: %v", err)
+	}
+	if len(key) != 32 {
+		t.Errorf("Expected a 32-byte key, got %d bytes", len(key))
+	}
+
+	unlocked, err := info.Unlock("correct horse")
+	if err != nil {
+		t.Fatalf("Unlock() failed: %v", err)
+	}
+	if string(unlocked) != string(key) {
+		t.Error("Expected Unlock() to derive the same key")
+	}
+	if _, err := info.Unlock("wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Expected ErrWrongPassphrase, got %v", err)
+	}
+	if _, _, err := NewKeyInfo(t.Context(), ""); err == nil {
+		t.Error("Expected empty passphrase to fail")
+	}
+}
+
+func TestSealIssue(t *testing.T) {
+	_, key, err := NewKeyInfo(t.Context(), "secret")
+	if err != nil {
+		t.Fatalf("NewKeyInfo() failed: %v", err)
+	}
+
+	issue := &models.Issue{ID: "CORE-1", Title: "Rotate credentials", Description: "The password is hunter2"}
+	issue.AddComment("Done for staging", "2026-01-01T00:00:00Z")
+
+	if err := SealIssue(issue, DefaultKey, key); err != nil {
+		t.Fatalf("SealIssue() failed: %v", err)
+	}
+	if !issue.Sensitive || issue.Sealed == nil || issue.Sealed.Key != DefaultKey {
+		t.Fatalf("Expected issue to be sealed with %q, got %+v", DefaultKey, issue.Sealed)
+	}
+	if issue.Description != "" || len(issue.Comments) != 0 {
+		t.Error("Expected description and comments to be cleared")
+	}
+	if issue.Title != "Rotate credentials" {
+		t.Error("Expected title to stay readable")
+	}
+
+	_, otherKey, _ := NewKeyInfo(t.Context(), "other")
+	if err := OpenIssue(issue, otherKey); err == nil {
+		t.Error("Expected opening with another key to fail")
+	}
+
+	if err := OpenIssue(issue, key); err != nil {
+		t.Fatalf("OpenIssue() failed: %v", err)
+	}
+	if issue.Description != "The password is hunter2" || len(issue.Comments) != 1 || issue.Comments[0].Body != "Done for staging" {
+		t.Errorf("Expected description and comments to be restored, got %q %+v", issue.Description, issue.Comments)
+	}
+	if issue.Sealed != nil || !issue.Sensitive {
+		t.Error("Expected opened issue to stay sensitive without sealed data")
+	}
+}
+
+func TestSessionKey(t *testing.T) {
+	t.Setenv("BUYRUK_SESSION", "test-"+t.Name())
+	t.Setenv("BUYRUK_PASSPHRASE", "")
+	projectKey := "TESTSESSIONKEY"
+	defer ForgetSessionKeys(projectKey)
+
+	if _, ok := SessionKey(t.Context(), projectKey, DefaultKey); ok {
+		t.Fatal("Expected no unlocked key")
+	}
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+	if err := SaveSessionKey(t.Context(), projectKey, DefaultKey, key, time.Hour); err != nil {
+		t.Fatalf("SaveSessionKey() failed: %v", err)
+	}
+	got, ok := SessionKey(t.Context(), projectKey, DefaultKey)
+	if !ok || string(got) != string(key) {
+		t.Error("Expected unlocked key to be returned")
+	}
+
+	// Expired keys are not returned
+	if err := SaveSessionKey(t.Context(), projectKey, "old", key, -time.Minute); err != nil {
+		t.Fatalf("SaveSessionKey() failed: %v", err)
+	}
+	if _, ok := SessionKey(t.Context(), projectKey, "old"); ok {
+		t.Error("Expected expired key not to be returned")
+	}
+
+	removed, err := ForgetSessionKeys(projectKey)
+	if err != nil {
+		t.Fatalf("ForgetSessionKeys() failed: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Expected 1 key forgotten, got %d", removed)
+	}
+	if _, ok := SessionKey(t.Context(), projectKey, DefaultKey); ok {
+		t.Error("Expected forgotten key not to be returned")
+	}
+
+	if err := SaveSessionKey(t.Context(), projectKey, "../escape", key, time.Hour); err == nil {
+		t.Error("Expected invalid key name to fail")
+	}
+}
@@ -0,0 +1,123 @@
+package secret
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+)
+
+// sessionKey is an unlocked key kept for a session.
+type sessionKey struct {
+	Key       string `json:"key"`        // Base64 key
+	ExpiresAt string `json:"expires_at"` // ISO 8601 timestamp
+}
+
+// SessionID identifies the current session: $BUYRUK_SESSION if set, otherwise
+// the parent process, so keys unlocked in a shell are available to the commands
+// run from that shell only.
+func SessionID() string {
+	if id := os.Getenv("BUYRUK_SESSION"); id != "" {
+		return id
+	}
+	return strconv.Itoa(os.Getppid())
+}
+
+// sessionKeyPath returns the path of an unlocked key in the current session.
+func sessionKeyPath(projectKey, name string) (string, error) {
+	sessionsDir, err := storage.SessionsDir()
+	if err != nil {
+		return "", err
+	}
+	// Keys are simple identifiers; reject anything that could escape the directory
+	if strings.ContainsAny(projectKey+name, `/\`) || strings.Contains(projectKey+name, "..") {
+		return "", fmt.Errorf("secret: invalid key name %q", name)
+	}
+	sessionDir := filepath.Join(sessionsDir, filepath.Clean(SessionID()))
+	return filepath.Join(sessionDir, projectKey+"."+name+".json"), nil
+}
+
+// SaveSessionKey keeps an unlocked key for the current session until ttl elapses.
+func SaveSessionKey(ctx context.Context, projectKey, name string, key []byte, ttl time.Duration) error {
+	path, err := sessionKeyPath(projectKey, name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("secret: failed to create session directory: %w", err)
+	}
+	data, err := json.MarshalIndent(sessionKey{
+		Key:       base64.StdEncoding.EncodeToString(key),
+		ExpiresAt: storage.Now(ctx).Add(ttl).UTC().Format(time.RFC3339),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("secret: failed to marshal session key: %w", err)
+	}
+	// Readable by the user only; the file holds the key itself
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("secret: failed to save session key: %w", err)
+	}
+	return nil
+}
+
+// SessionKey returns the named key of a project if it is unlocked in the current
+// session. With $BUYRUK_PASSPHRASE set (for scripts), the key is derived from it
+// instead. Expired keys are removed.
+func SessionKey(ctx context.Context, projectKey, name string) ([]byte, bool) {
+	if passphrase := os.Getenv("BUYRUK_PASSPHRASE"); passphrase != "" {
+		keys, err := LoadKeys(ctx, projectKey)
+		if err != nil || keys[name] == nil {
+			return nil, false
+		}
+		key, err := keys[name].Unlock(passphrase)
+		return key, err == nil
+	}
+
+	path, err := sessionKeyPath(projectKey, name)
+	if err != nil {
+		return nil, false
+	}
+	var session sessionKey
+	if err := storage.ReadJSON(ctx, path, &session); err != nil {
+		return nil, false
+	}
+	expires, err := time.Parse(time.RFC3339, session.ExpiresAt)
+	if err != nil || !storage.Now(ctx).Before(expires) {
+		os.Remove(path)
+		return nil, false
+	}
+	key, err := base64.StdEncoding.DecodeString(session.Key)
+	if err != nil {
+		return nil, false
+	}
+	return key, true
+}
+
+// ForgetSessionKeys removes the keys of a project unlocked in the current session.
+// Returns the number of keys removed.
+func ForgetSessionKeys(projectKey string) (int, error) {
+	path, err := sessionKeyPath(projectKey, DefaultKey)
+	if err != nil {
+		return 0, err
+	}
+	matches, err := filepath.Glob(filepath.Join(filepath.Dir(path), projectKey+".*.json"))
+	if err != nil {
+		return 0, fmt.Errorf("secret: failed to list session keys: %w", err)
+	}
+	removed := 0
+	for _, match := range matches {
+		if err := os.Remove(match); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return removed, fmt.Errorf("secret: failed to remove session key: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -1,24 +1,118 @@
 package storage
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+)
+
+// RestoreAtomic writes data to a file atomically like WriteAtomic, but
+// without the write filter: it puts back content exactly as it was, to undo a
+// failed change.
+func RestoreAtomic(ctx context.Context, path string, data []byte) error {
+	return WriteAtomic(withoutWriteFilter(ctx), path, data)
+}
+
+// withoutWriteFilter returns a copy of ctx whose Env has no write filter,
+// nor AfterWrite.
+func withoutWriteFilter(ctx context.Context) context.Context {
+	env := EnvFrom(ctx)
+	env.WriteFilter = nil
+	env.AfterWrite = nil
+	return WithEnv(ctx, env)
+}
+
+// afterWrite runs the AfterWrite of the context's Env, if any, for a write
+// that went through its write filter.
+func afterWrite(ctx context.Context, path string) error {
+	env := EnvFrom(ctx)
+	if env.WriteFilter == nil || env.AfterWrite == nil {
+		return nil
+	}
+	return env.AfterWrite(ctx, path)
+}
+
+// Durability levels of WriteAtomic, from fastest to safest.
+const (
+	// DurabilityNone only renames the temp file; a power loss may leave an
+	// empty or truncated file.
+	DurabilityNone = "none"
+	// DurabilityFile syncs the temp file to disk before renaming it.
+	DurabilityFile = "file"
+	// DurabilityFull also syncs the parent directory, so the rename itself
+	// survives a power loss. This is the default.
+	DurabilityFull = "full"
 )
 
+// writeFile writes the temp file of WriteAtomic with the context Env's WriteFile.
+func writeFile(ctx context.Context, f *os.File, data []byte) (int, error) {
+	if write := EnvFrom(ctx).WriteFile; write != nil {
+		return write(f, data)
+	}
+	return f.Write(data)
+}
+
+// syncFile syncs the temp file of WriteAtomic with the context Env's SyncFile.
+func syncFile(ctx context.Context, f *os.File) error {
+	if sync := EnvFrom(ctx).SyncFile; sync != nil {
+		return sync(f)
+	}
+	return f.Sync()
+}
+
+// syncDir syncs the directory of a WriteAtomic with the context Env's SyncDir.
+func syncDir(ctx context.Context, dir string) error {
+	if sync := EnvFrom(ctx).SyncDir; sync != nil {
+		return sync(dir)
+	}
+	return syncDirectory(dir)
+}
+
+// IsValidDurability reports whether level is a durability level.
+func IsValidDurability(level string) bool {
+	return level == DurabilityNone || level == DurabilityFile || level == DurabilityFull
+}
+
+// durabilityLevel returns the durability level of the context's Env.
+func durabilityLevel(ctx context.Context) string {
+	if level := EnvFrom(ctx).Durability; IsValidDurability(level) {
+		return level
+	}
+	return DurabilityFull
+}
+
 // WriteAtomic writes data to a file atomically using the temp file and rename pattern.
+// Depending on the durability level of the context's Env, the temp file and
+// the parent directory are synced to disk, so a crash never leaves a partial file in place of the old one.
 // This function does NOT handle locking - it should be called from within a locked context.
-func WriteAtomic(path string, data []byte) error {
+func WriteAtomic(ctx context.Context, path string, data []byte) error {
 	// Ensure parent directory exists
 	if err := EnsureDir(path); err != nil {
 		return err
 	}
 
+	if filter := EnvFrom(ctx).WriteFilter; filter != nil {
+		filtered, err := filter(ctx, path, data)
+		if err != nil {
+			return err
+		}
+		data = filtered
+	}
+
+	durability := durabilityLevel(ctx)
 	tmpPath := path + ".tmp"
-	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
-		return fmt.Errorf("storage: failed to write temp file: %w", err)
+	if err := writeTempFile(ctx, tmpPath, data, durability); err != nil {
+		// Never leave a partial temp file behind
+		os.Remove(tmpPath)
+		return err
 	}
 
 	if err := os.Rename(tmpPath, path); err != nil {
@@ -26,14 +120,64 @@ func WriteAtomic(path string, data []byte) error {
 		os.Remove(tmpPath)
 		return fmt.Errorf("storage: failed to rename temp file: %w", err)
 	}
+	if cache := cacheFrom(ctx); cache != nil {
+		cache.files.remove(path)
+	}
+
+	if durability == DurabilityFull {
+		if err := syncDir(ctx, filepath.Dir(path)); err != nil {
+			return fmt.Errorf("storage: failed to sync directory: %w", err)
+		}
+	}
 
+	return afterWrite(ctx, path)
+}
+
+// writeTempFile writes data to the temp file of WriteAtomic, syncing it to
+// disk unless durability is DurabilityNone.
+func writeTempFile(ctx context.Context, tmpPath string, data []byte, durability string) error {
+	f, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("storage: failed to write temp file: %w", err)
+	}
+	if n, err := writeFile(ctx, f, data); err != nil || n < len(data) {
+		f.Close()
+		if err == nil {
+			err = io.ErrShortWrite
+		}
+		return fmt.Errorf("storage: failed to write temp file: %w", err)
+	}
+	if durability != DurabilityNone {
+		if err := syncFile(ctx, f); err != nil {
+			f.Close()
+			return fmt.Errorf("storage: failed to sync temp file: %w", err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("storage: failed to write temp file: %w", err)
+	}
 	return nil
 }
 
+// syncDirectory syncs a directory to disk, so renames in it are durable.
+// Windows can't open directories for syncing, and NTFS journals renames, so
+// it is skipped there.
+func syncDirectory(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
 // WriteJSONAtomic writes a JSON-serializable value to a file atomically.
 // This function handles the full atomic protocol: lock, transaction, write, commit.
 // It extracts the project key from the file path.
-func WriteJSONAtomic(path string, v interface{}) error {
+func WriteJSONAtomic(ctx context.Context, path string, v interface{}) error {
 	// Extract project key from path
 	// Path format: [ConfigDir]/projects/[projectKey]/...
 	projectKey, err := extractProjectKeyFromPath(path)
@@ -42,14 +186,14 @@ func WriteJSONAtomic(path string, v interface{}) error {
 	}
 
 	// Step 1: Acquire lock
-	cleanup, err := AcquireLock(projectKey)
+	cleanup, err := AcquireLock(ctx, projectKey)
 	if err != nil {
 		return err
 	}
 	defer cleanup()
 
 	// Step 2: Begin transaction
-	if err := BeginTransaction(projectKey, "write_json", map[string]interface{}{
+	if err := BeginTransaction(ctx, projectKey, "write_json", map[string]interface{}{
 		"file": path,
 	}); err != nil {
 		return err
@@ -59,7 +203,7 @@ func WriteJSONAtomic(path string, v interface{}) error {
 	success := false
 	defer func() {
 		if !success {
-			RollbackTransaction(projectKey)
+			RollbackTransaction(ctx, projectKey)
 		}
 	}()
 
@@ -70,12 +214,12 @@ func WriteJSONAtomic(path string, v interface{}) error {
 	}
 
 	// Step 4: Write atomically
-	if err := WriteAtomic(path, data); err != nil {
+	if err := WriteAtomic(ctx, path, data); err != nil {
 		return err
 	}
 
 	// Step 5: Commit transaction
-	if err := CommitTransaction(projectKey); err != nil {
+	if err := CommitTransaction(ctx, projectKey); err != nil {
 		return err
 	}
 
@@ -118,7 +262,7 @@ func extractProjectKeyFromPath(path string) (string, error) {
 // This function handles the full atomic protocol: lock, transaction, check existence, write, commit.
 // It extracts the project key from the file path.
 // Returns an error if the file already exists.
-func WriteJSONAtomicCreate(path string, v interface{}) error {
+func WriteJSONAtomicCreate(ctx context.Context, path string, v interface{}) error {
 	// Extract project key from path
 	projectKey, err := extractProjectKeyFromPath(path)
 	if err != nil {
@@ -126,14 +270,14 @@ func WriteJSONAtomicCreate(path string, v interface{}) error {
 	}
 
 	// Step 1: Acquire lock
-	cleanup, err := AcquireLock(projectKey)
+	cleanup, err := AcquireLock(ctx, projectKey)
 	if err != nil {
 		return err
 	}
 	defer cleanup()
 
 	// Step 2: Begin transaction
-	if err := BeginTransaction(projectKey, "write_json_create", map[string]interface{}{
+	if err := BeginTransaction(ctx, projectKey, "write_json_create", map[string]interface{}{
 		"file": path,
 	}); err != nil {
 		return err
@@ -143,7 +287,7 @@ func WriteJSONAtomicCreate(path string, v interface{}) error {
 	success := false
 	defer func() {
 		if !success {
-			RollbackTransaction(projectKey)
+			RollbackTransaction(ctx, projectKey)
 		}
 	}()
 
@@ -161,12 +305,12 @@ func WriteJSONAtomicCreate(path string, v interface{}) error {
 	}
 
 	// Step 5: Write atomically
-	if err := WriteAtomic(path, data); err != nil {
+	if err := WriteAtomic(ctx, path, data); err != nil {
 		return err
 	}
 
 	// Step 6: Commit transaction
-	if err := CommitTransaction(projectKey); err != nil {
+	if err := CommitTransaction(ctx, projectKey); err != nil {
 		return err
 	}
 
@@ -195,10 +339,10 @@ type UpdateFunc func(v interface{}) error
 //	err := UpdateJSONAtomic(indexPath, &index, func(v interface{}) error {
 //	    idx := v.(*models.ProjectIndex)
 //	    idx.AddIssue(issue)
-//	    idx.UpdatedAt = time.Now().Format(time.RFC3339)
+//	    idx.UpdatedAt = Timestamp()
 //	    return nil
 //	})
-func UpdateJSONAtomic(path string, v interface{}, updateFunc UpdateFunc) error {
+func UpdateJSONAtomic(ctx context.Context, path string, v interface{}, updateFunc UpdateFunc) error {
 	// Extract project key from path
 	projectKey, err := extractProjectKeyFromPath(path)
 	if err != nil {
@@ -206,14 +350,14 @@ func UpdateJSONAtomic(path string, v interface{}, updateFunc UpdateFunc) error {
 	}
 
 	// Step 1: Acquire lock
-	cleanup, err := AcquireLock(projectKey)
+	cleanup, err := AcquireLock(ctx, projectKey)
 	if err != nil {
 		return err
 	}
 	defer cleanup()
 
 	// Step 2: Begin transaction
-	if err := BeginTransaction(projectKey, "update_json", map[string]interface{}{
+	if err := BeginTransaction(ctx, projectKey, "update_json", map[string]interface{}{
 		"file": path,
 	}); err != nil {
 		return err
@@ -223,14 +367,14 @@ func UpdateJSONAtomic(path string, v interface{}, updateFunc UpdateFunc) error {
 	success := false
 	defer func() {
 		if !success {
-			RollbackTransaction(projectKey)
+			RollbackTransaction(ctx, projectKey)
 		}
 	}()
 
 	// Step 3: Read current value (if file exists)
 	if _, err := os.Stat(path); err == nil {
 		// File exists, read it
-		if err := ReadJSON(path, v); err != nil {
+		if err := ReadJSON(ctx, path, v); err != nil {
 			return fmt.Errorf("storage: failed to read current value: %w", err)
 		}
 	}
@@ -248,14 +392,130 @@ func UpdateJSONAtomic(path string, v interface{}, updateFunc UpdateFunc) error {
 	}
 
 	// Step 6: Write atomically
-	if err := WriteAtomic(path, data); err != nil {
+	if err := WriteAtomic(ctx, path, data); err != nil {
 		return err
 	}
 
 	// Step 7: Commit transaction
-	if err := CommitTransaction(projectKey); err != nil {
+	if err := CommitTransaction(ctx, projectKey); err != nil {
+		return err
+	}
+
+	// Mark as successful so rollback won't execute
+	success = true
+	return nil
+}
+
+// UpdateJSONFilesAtomic performs an atomic read-modify-write operation on several
+// JSON files, possibly in different projects, under one lock per project.
+// It is used where files must change together, such as the two sides of an issue link.
+//
+// Each file is read into the value at the same position of values (a pointer),
+// then updateFunc is called once with whether each file exists. Files that do
+// not exist keep the zero value and are never created. If updateFunc returns an
+// error, nothing is written; if a write fails, the files already written are
+// restored and all transactions are rolled back. A project index value
+// (*models.ProjectIndex) is read with ReadIndex and written with WriteIndex.
+func UpdateJSONFilesAtomic(ctx context.Context, paths []string, values []interface{}, updateFunc func(exists []bool) error) error {
+	if len(paths) != len(values) {
+		return fmt.Errorf("storage: got %d paths but %d values", len(paths), len(values))
+	}
+
+	var projectKeys []string
+	for _, path := range paths {
+		projectKey, err := extractProjectKeyFromPath(path)
+		if err != nil {
+			return fmt.Errorf("storage: failed to extract project key from path: %w", err)
+		}
+		if !slices.Contains(projectKeys, projectKey) {
+			projectKeys = append(projectKeys, projectKey)
+		}
+	}
+
+	// Step 1: Acquire locks
+	cleanup, err := AcquireLocks(ctx, projectKeys...)
+	if err != nil {
 		return err
 	}
+	defer cleanup()
+
+	// Step 2: Begin transactions
+	success := false
+	var begun []string
+	defer func() {
+		if !success {
+			for _, projectKey := range begun {
+				RollbackTransaction(ctx, projectKey)
+			}
+		}
+	}()
+	for _, projectKey := range projectKeys {
+		if err := BeginTransaction(ctx, projectKey, "update_json_files", map[string]interface{}{
+			"files": paths,
+		}); err != nil {
+			return err
+		}
+		begun = append(begun, projectKey)
+	}
+
+	// Step 3: Read current values
+	exists := make([]bool, len(paths))
+	originals := make([][]byte, len(paths))
+	for i, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return fmt.Errorf("storage: failed to read %s: %w", path, err)
+		}
+		if index, ok := values[i].(*models.ProjectIndex); ok {
+			err = ReadIndex(ctx, path, index)
+		} else {
+			err = json.Unmarshal(data, values[i])
+		}
+		if err != nil {
+			return fmt.Errorf("storage: failed to parse %s: %w", path, err)
+		}
+		exists[i] = true
+		originals[i] = data
+	}
+
+	// Step 4: Call update function to modify the values
+	if err := updateFunc(exists); err != nil {
+		return fmt.Errorf("storage: update function failed: %w", err)
+	}
+
+	// Step 5: Write existing files, restoring the written ones on failure
+	var written []int
+	for i, path := range paths {
+		if !exists[i] {
+			continue
+		}
+		var err error
+		if index, ok := values[i].(*models.ProjectIndex); ok {
+			err = WriteIndex(ctx, path, index)
+		} else {
+			var data []byte
+			if data, err = json.MarshalIndent(values[i], "", "  "); err == nil {
+				err = WriteAtomic(ctx, path, data)
+			}
+		}
+		if err != nil {
+			for _, j := range written {
+				RestoreAtomic(ctx, paths[j], originals[j])
+			}
+			return fmt.Errorf("storage: failed to write %s: %w", path, err)
+		}
+		written = append(written, i)
+	}
+
+	// Step 6: Commit transactions
+	for _, projectKey := range projectKeys {
+		if err := CommitTransaction(ctx, projectKey); err != nil {
+			return err
+		}
+	}
 
 	// Mark as successful so rollback won't execute
 	success = true
@@ -265,7 +525,7 @@ func UpdateJSONAtomic(path string, v interface{}, updateFunc UpdateFunc) error {
 // DeleteAtomic deletes a file atomically using the lock and transaction protocol.
 // This function handles the full atomic protocol: lock, transaction, delete, commit.
 // It extracts the project key from the file path.
-func DeleteAtomic(path string) error {
+func DeleteAtomic(ctx context.Context, path string) error {
 	// Extract project key from path
 	projectKey, err := extractProjectKeyFromPath(path)
 	if err != nil {
@@ -273,14 +533,14 @@ func DeleteAtomic(path string) error {
 	}
 
 	// Step 1: Acquire lock
-	cleanup, err := AcquireLock(projectKey)
+	cleanup, err := AcquireLock(ctx, projectKey)
 	if err != nil {
 		return err
 	}
 	defer cleanup()
 
 	// Step 2: Begin transaction
-	if err := BeginTransaction(projectKey, "delete_file", map[string]interface{}{
+	if err := BeginTransaction(ctx, projectKey, "delete_file", map[string]interface{}{
 		"file": path,
 	}); err != nil {
 		return err
@@ -290,7 +550,7 @@ func DeleteAtomic(path string) error {
 	success := false
 	defer func() {
 		if !success {
-			RollbackTransaction(projectKey)
+			RollbackTransaction(ctx, projectKey)
 		}
 	}()
 
@@ -308,7 +568,7 @@ func DeleteAtomic(path string) error {
 	}
 
 	// Step 5: Commit transaction
-	if err := CommitTransaction(projectKey); err != nil {
+	if err := CommitTransaction(ctx, projectKey); err != nil {
 		return err
 	}
 
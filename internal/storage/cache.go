@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+)
+
+// fileCache keeps the content of files read from disk, so a command that
+// reads the same file many times (the project index, above all) reads it from
+// disk once. An entry is used only while the file is still the same file
+// (writes replace files by renaming) with the same size and mtime.
+type fileCache struct {
+	mu       sync.Mutex
+	files    map[string]cachedFile
+	bytes    int64
+	readFile func(path string) ([]byte, error) // Reads a file from disk
+}
+
+// cachedFile is the content of a file and its info when it was read.
+type cachedFile struct {
+	info os.FileInfo
+	data []byte
+}
+
+const (
+	// racyWindow is how old a file's mtime must be for its content to be
+	// cached. A file changed in place within the mtime granularity of the
+	// filesystem could otherwise keep its size and mtime.
+	racyWindow = time.Second
+	// maxReadCacheBytes bounds the content kept; the cache is emptied when
+	// it would grow past it.
+	maxReadCacheBytes = 64 << 20
+)
+
+// Cache keeps the files a command reads, so it reads each one from disk once
+// however often it is read, as long as it doesn't change. It is carried in
+// the Env, so each command has its own.
+type Cache struct {
+	files  *fileCache
+	shards shardCache
+}
+
+// NewCache returns an empty Cache.
+func NewCache() *Cache {
+	return &Cache{
+		files:  newFileCache(os.ReadFile),
+		shards: shardCache{shards: map[string]cachedShard{}},
+	}
+}
+
+// cacheFrom returns the Cache of the Env carried by ctx, or nil if files are
+// read from disk every time.
+func cacheFrom(ctx context.Context) *Cache {
+	return EnvFrom(ctx).Cache
+}
+
+// newFileCache returns an empty cache of the files read with readFile.
+func newFileCache(readFile func(path string) ([]byte, error)) *fileCache {
+	return &fileCache{files: map[string]cachedFile{}, readFile: readFile}
+}
+
+// read returns the content of a file, from the cache if the file hasn't
+// changed since it was cached.
+func (c *fileCache) read(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	cached, ok := c.files[path]
+	c.mu.Unlock()
+	if ok && os.SameFile(cached.info, info) && cached.info.Size() == info.Size() && cached.info.ModTime().Equal(info.ModTime()) {
+		return cached.data, nil
+	}
+
+	data, err := c.readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) == info.Size() && time.Since(info.ModTime()) >= racyWindow {
+		c.add(path, cachedFile{info: info, data: data})
+	}
+	return data, nil
+}
+
+// add adds a file to the cache.
+func (c *fileCache) add(path string, file cachedFile) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if previous, ok := c.files[path]; ok {
+		c.bytes -= int64(len(previous.data))
+	}
+	if c.bytes+int64(len(file.data)) > maxReadCacheBytes {
+		c.files = map[string]cachedFile{}
+		c.bytes = 0
+	}
+	c.files[path] = file
+	c.bytes += int64(len(file.data))
+}
+
+// remove drops a file from the cache after it is written.
+func (c *fileCache) remove(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if previous, ok := c.files[path]; ok {
+		c.bytes -= int64(len(previous.data))
+		delete(c.files, path)
+	}
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// FixedClock returns a clock that always reports t.
+func FixedClock(t time.Time) func() time.Time {
+	return func() time.Time { return t }
+}
+
+// Now returns the current time from the clock of the context's Env.
+func Now(ctx context.Context) time.Time {
+	if clock := EnvFrom(ctx).Clock; clock != nil {
+		return clock()
+	}
+	return time.Now()
+}
+
+// Timestamp returns the current time as an RFC3339 timestamp in UTC.
+// All stored timestamps use this format so files compare and sort the same
+// regardless of the timezone of the machine that wrote them.
+func Timestamp(ctx context.Context) string {
+	return Now(ctx).UTC().Format(time.RFC3339)
+}
+
+// CounterSequence returns a sequence source that counts up from start for each project.
+func CounterSequence(start int) func(projectKey string) int {
+	var mu sync.Mutex
+	counters := map[string]int{}
+	return func(projectKey string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		next, ok := counters[projectKey]
+		if !ok {
+			next = start
+		}
+		counters[projectKey] = next + 1
+		return next
+	}
+}
+
+// NextSequence returns the sequence number for the next issue in a project.
+// computed is the default derived from the project index; it is used unless
+// the context's Env has a sequence source.
+func NextSequence(ctx context.Context, projectKey string, computed int) int {
+	if next := EnvFrom(ctx).Sequence; next != nil {
+		return next(projectKey)
+	}
+	return computed
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// Env is the storage configuration of one command: where projects are kept,
+// the clock of timestamps, and how writes are made. It travels in the context
+// passed to storage functions, so commands running side by side (tests, the
+// serve command) never see each other's settings. The zero Env uses the
+// defaults.
+type Env struct {
+	// DataDir is the directory projects are kept in; "" for the repo-local
+	// or config directory (see DataDir).
+	DataDir string
+	// Clock returns the current time; nil for the system clock.
+	Clock func() time.Time
+	// Sequence chooses the next issue sequence number of a project; nil to
+	// derive it from the project index.
+	Sequence func(projectKey string) int
+	// Cache keeps the files read with ReadJSON and the index shards read
+	// with ReadIndex; nil to read them from disk every time.
+	Cache *Cache
+	// LockTimeout is how long AcquireLock waits for an existing lock to be
+	// released; 0 for 5 seconds.
+	LockTimeout time.Duration
+	// Durability is how WriteAtomic syncs writes to disk; "" for DurabilityFull.
+	Durability string
+	// WriteFilter can change the data of every WriteAtomic before it happens
+	// (given the destination path), e.g. to keep replication metadata up to
+	// date whatever command writes a file; nil for none.
+	WriteFilter func(ctx context.Context, path string, data []byte) ([]byte, error)
+	// AfterWrite runs once a write that went through WriteFilter is in
+	// place, e.g. to write files the filter derived from it only when it
+	// succeeded; an error fails the write. nil for none.
+	AfterWrite func(ctx context.Context, path string) error
+	// WriteFile, SyncFile, and SyncDir write the temp file of WriteAtomic
+	// and sync it and its directory to disk; nil for the real ones. Tests
+	// replace them to simulate failing disks.
+	WriteFile func(f *os.File, data []byte) (int, error)
+	SyncFile  func(f *os.File) error
+	SyncDir   func(dir string) error
+}
+
+// envKey is the context key of the Env.
+type envKey struct{}
+
+// WithEnv returns a copy of ctx that carries env.
+func WithEnv(ctx context.Context, env Env) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, envKey{}, env)
+}
+
+// EnvFrom returns the Env carried by ctx, or the zero Env. A nil ctx is
+// allowed, for commands that were never executed (tests building a command
+// and calling its handler directly).
+func EnvFrom(ctx context.Context) Env {
+	if ctx == nil {
+		return Env{}
+	}
+	env, _ := ctx.Value(envKey{}).(Env)
+	return env
+}
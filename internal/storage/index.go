@@ -0,0 +1,428 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+)
+
+// IndexShardsDirName is the directory of a project that holds the shards of a
+// sharded index.
+//
+// A project index with a shard_size keeps its issue entries out of
+// project.json, in shard files of the IDs of one range each (CORE-1000 to
+// CORE-1999 for a shard size of 1000), named after their content. IDs without
+// a sequence number (ULIDs) are spread over a fixed number of shards of their
+// own by a hash of the ID. project.json lists the shards instead. WriteIndex
+// only writes the shards whose entries changed, so an update rewrites one
+// shard and the small project.json however many issues there are. ReadIndex
+// puts the entries back, so commands see a whole index either way.
+//
+// Shard files are never changed, only replaced, so restoring an earlier
+// project.json (as a failed multi-file write does) restores the entries too.
+// The shards of the previous project.json are kept for this; older ones are
+// removed when the index is written.
+const IndexShardsDirName = "index"
+
+// maxShardReadAttempts is how many times a sharded index is read when a shard
+// it lists is removed by a concurrent write.
+const maxShardReadAttempts = 3
+
+// sequencelessShards is how many shards hold the entries of IDs without a
+// sequence number. Their names start with "u" and the shard number instead of
+// the start of an ID range.
+const sequencelessShards = 16
+
+// IndexShardsDir returns the shards directory of the given project key.
+func IndexShardsDir(ctx context.Context, projectKey string) (string, error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(projectDir, IndexShardsDirName), nil
+}
+
+// IndexSize returns the size in bytes of a project's index: project.json and
+// the shards it lists.
+func IndexSize(ctx context.Context, projectKey string) (int64, error) {
+	indexPath, err := ProjectIndexPath(ctx, projectKey)
+	if err != nil {
+		return 0, err
+	}
+	info, err := os.Stat(indexPath)
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size()
+
+	header, err := readIndexHeader(indexPath)
+	if err != nil {
+		return 0, err
+	}
+	dir := filepath.Join(filepath.Dir(indexPath), IndexShardsDirName)
+	for _, shard := range header.Shards {
+		if info, err := os.Stat(filepath.Join(dir, shard)); err == nil {
+			size += info.Size()
+		}
+	}
+	return size, nil
+}
+
+// ReadIndex reads a project index from its project.json (indexPath). The
+// entries of a sharded index are read back from its shards, so the index is
+// whole either way. Like ReadJSON, files that haven't changed since they were
+// last read are not read from disk again.
+func ReadIndex(ctx context.Context, indexPath string, index *models.ProjectIndex) error {
+	for attempt := 1; ; attempt++ {
+		var read models.ProjectIndex
+		if err := ReadJSON(ctx, indexPath, &read); err != nil {
+			return err
+		}
+		err := readShards(ctx, indexPath, &read)
+		if err == nil {
+			*index = read
+			return nil
+		}
+		if !errors.Is(err, os.ErrNotExist) || attempt >= maxShardReadAttempts {
+			return err
+		}
+		// A concurrent write replaced the index; read the new one
+	}
+}
+
+// readShards reads the shards listed in index into its issue entries.
+func readShards(ctx context.Context, indexPath string, index *models.ProjectIndex) error {
+	if len(index.Shards) == 0 {
+		return nil
+	}
+	dir := filepath.Join(filepath.Dir(indexPath), IndexShardsDirName)
+	shards := make([][]models.IndexEntry, 0, len(index.Shards))
+	count := 0
+	for _, shard := range index.Shards {
+		entries, err := readShard(ctx, filepath.Join(dir, shard))
+		if err != nil {
+			return fmt.Errorf("storage: failed to read index shard %s (run `buyruk project repair %s`): %w", shard, index.ProjectKey, err)
+		}
+		shards = append(shards, entries)
+		count += len(entries)
+	}
+	index.Issues = make([]models.IndexEntry, 0, count)
+	for _, entries := range shards {
+		index.Issues = appendEntries(index.Issues, entries)
+	}
+	index.Shards = nil
+	return nil
+}
+
+// WriteIndex writes a project index to its project.json (indexPath)
+// atomically, like WriteAtomic. The write filter sees the whole index. A
+// sharded index has its entries written to the shards of their ranges, only
+// those whose entries changed, and project.json lists the shards instead.
+// This function does NOT handle locking - it should be called from within a
+// locked context.
+func WriteIndex(ctx context.Context, indexPath string, index *models.ProjectIndex) error {
+	whole := *index
+	whole.Shards = nil
+	var data []byte
+	if filter := EnvFrom(ctx).WriteFilter; filter != nil {
+		var err error
+		if data, err = json.MarshalIndent(&whole, "", "  "); err != nil {
+			return fmt.Errorf("storage: failed to marshal index: %w", err)
+		}
+		filtered, err := filter(ctx, indexPath, data)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(filtered, data) {
+			whole = models.ProjectIndex{}
+			if err := json.Unmarshal(filtered, &whole); err != nil {
+				return fmt.Errorf("storage: failed to unmarshal filtered index: %w", err)
+			}
+			data = filtered
+		}
+	}
+	filtered := ctx
+	ctx = withoutWriteFilter(ctx)
+
+	dir := filepath.Join(filepath.Dir(indexPath), IndexShardsDirName)
+	if whole.ShardSize <= 0 {
+		if data == nil {
+			var err error
+			if data, err = json.MarshalIndent(&whole, "", "  "); err != nil {
+				return fmt.Errorf("storage: failed to marshal index: %w", err)
+			}
+		}
+		// Remove the shards of an index that is no longer sharded
+		if _, err := os.Stat(dir); err == nil {
+			removeUnusedShards(indexPath, dir, nil)
+		}
+		if err := WriteAtomic(ctx, indexPath, data); err != nil {
+			return err
+		}
+		return afterWrite(filtered, indexPath)
+	}
+
+	shards, err := writeShards(ctx, indexPath, &whole)
+	if err != nil {
+		return err
+	}
+	whole.Shards = shards
+	whole.Issues = []models.IndexEntry{}
+	header, err := json.MarshalIndent(&whole, "", "  ")
+	if err != nil {
+		return fmt.Errorf("storage: failed to marshal index: %w", err)
+	}
+	removeUnusedShards(indexPath, dir, shards)
+	if err := WriteAtomic(ctx, indexPath, header); err != nil {
+		return err
+	}
+	return afterWrite(filtered, indexPath)
+}
+
+// UpdateIndexAtomic performs an atomic read-modify-write of a project index,
+// like UpdateJSONAtomic but with ReadIndex and WriteIndex.
+func UpdateIndexAtomic(ctx context.Context, indexPath string, index *models.ProjectIndex, updateFunc UpdateFunc) error {
+	projectKey, err := extractProjectKeyFromPath(indexPath)
+	if err != nil {
+		return fmt.Errorf("storage: failed to extract project key from path: %w", err)
+	}
+	tx, err := BeginProjectTx(ctx, projectKey, "update_index", map[string]interface{}{
+		"file": indexPath,
+	})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := tx.UpdateIndex(indexPath, index, updateFunc); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// writeShards writes the entries of a sharded index to the shards of their
+// ranges and returns the names of the shards, in order. A range whose entries
+// are those of the shard project.json lists for it keeps that shard.
+func writeShards(ctx context.Context, indexPath string, index *models.ProjectIndex) ([]string, error) {
+	dir := filepath.Join(filepath.Dir(indexPath), IndexShardsDirName)
+	current := map[string]string{}
+	if header, err := readIndexHeader(indexPath); err == nil {
+		for _, name := range header.Shards {
+			prefix, _, _ := strings.Cut(name, "-")
+			current[prefix] = name
+		}
+	}
+
+	// Group the entries by shard, keeping their order within a shard
+	groups := map[string][]models.IndexEntry{}
+	for _, entry := range index.Issues {
+		prefix := shardPrefix(entry.ID, index.ShardSize)
+		groups[prefix] = append(groups[prefix], entry)
+	}
+
+	names := make([]string, 0, len(groups))
+	for _, prefix := range slices.SortedFunc(maps.Keys(groups), compareShardPrefixes) {
+		entries := groups[prefix]
+		if name, ok := current[prefix]; ok {
+			if previous, err := readShard(ctx, filepath.Join(dir, name)); err == nil && reflect.DeepEqual(previous, entries) {
+				names = append(names, name)
+				continue
+			}
+		}
+
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("storage: failed to marshal index shard: %w", err)
+		}
+		sum := sha256.Sum256(data)
+		name := prefix + "-" + hex.EncodeToString(sum[:6]) + ".json"
+		shardPath := filepath.Join(dir, name)
+		// Shards are named after their content, so an existing one is unchanged
+		if _, err := os.Stat(shardPath); err != nil {
+			if err := WriteAtomic(ctx, shardPath, data); err != nil {
+				return nil, err
+			}
+		}
+		cacheShard(ctx, shardPath, entries)
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// shardPrefix returns the start of the name of the shard that holds the entry
+// of id: the start of its ID range, or "u" and a shard number for IDs without
+// a sequence number.
+func shardPrefix(id string, shardSize int) string {
+	if _, seq, err := models.ParseIssueID(id); err == nil && seq > 0 {
+		return strconv.Itoa(seq / shardSize * shardSize)
+	}
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return "u" + strconv.Itoa(int(h.Sum32()%sequencelessShards))
+}
+
+// compareShardPrefixes orders the shards of ID ranges by their start, before
+// the shards of IDs without a sequence number.
+func compareShardPrefixes(a, b string) int {
+	aNum, aRest := strings.CutPrefix(a, "u")
+	bNum, bRest := strings.CutPrefix(b, "u")
+	if aRest != bRest {
+		if aRest {
+			return 1
+		}
+		return -1
+	}
+	x, _ := strconv.Atoi(aNum)
+	y, _ := strconv.Atoi(bNum)
+	return x - y
+}
+
+// shardCache keeps the entries of the shard files read and written, so a
+// command decodes each shard once however often it reads and writes the
+// index. Shards are never changed in place, so an entry is used as long as
+// the file is still the same file.
+type shardCache struct {
+	mu      sync.Mutex
+	shards  map[string]cachedShard
+	entries int
+}
+
+// cachedShard is the entries of a shard file and its info when it was read.
+type cachedShard struct {
+	info    os.FileInfo
+	entries []models.IndexEntry
+}
+
+// maxShardCacheEntries bounds the entries kept; the cache is emptied when it
+// would grow past it.
+const maxShardCacheEntries = 1 << 18
+
+// readShard returns the entries of a shard file, from the shard cache of the
+// Env if the file is the one they were read from. The entries may be shared
+// with the cache and must not be changed.
+func readShard(ctx context.Context, path string) ([]models.IndexEntry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	cache := cacheFrom(ctx)
+	if cache != nil {
+		cache.shards.mu.Lock()
+		cached, ok := cache.shards.shards[path]
+		cache.shards.mu.Unlock()
+		if ok && os.SameFile(cached.info, info) && cached.info.Size() == info.Size() {
+			return cached.entries, nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []models.IndexEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	if cache != nil {
+		cache.shards.store(path, cachedShard{info: info, entries: entries})
+	}
+	return entries, nil
+}
+
+// cacheShard adds the entries of a shard file just written to the shard cache
+// of the Env.
+func cacheShard(ctx context.Context, path string, entries []models.IndexEntry) {
+	cache := cacheFrom(ctx)
+	if cache == nil {
+		return
+	}
+	if info, err := os.Stat(path); err == nil {
+		cache.shards.store(path, cachedShard{info: info, entries: appendEntries(nil, entries)})
+	}
+}
+
+// store adds a shard file to the shard cache.
+func (c *shardCache) store(path string, shard cachedShard) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if previous, ok := c.shards[path]; ok {
+		c.entries -= len(previous.entries)
+	}
+	if c.entries+len(shard.entries) > maxShardCacheEntries {
+		c.shards = map[string]cachedShard{}
+		c.entries = 0
+	}
+	c.shards[path] = shard
+	c.entries += len(shard.entries)
+}
+
+// appendEntries appends copies of entries to dst that share no slices with them.
+func appendEntries(dst, entries []models.IndexEntry) []models.IndexEntry {
+	for _, entry := range entries {
+		entry.Labels = slices.Clone(entry.Labels)
+		entry.Components = slices.Clone(entry.Components)
+		dst = append(dst, entry)
+	}
+	return dst
+}
+
+// readIndexHeader reads project.json without putting back sharded entries.
+func readIndexHeader(indexPath string) (*models.ProjectIndex, error) {
+	data, err := os.ReadFile(indexPath)
+	if err != nil {
+		return nil, err
+	}
+	var header models.ProjectIndex
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("storage: failed to unmarshal JSON from %s: %w", indexPath, err)
+	}
+	return &header, nil
+}
+
+// removeUnusedShards removes the shards neither listed in keep nor in the
+// project.json currently on disk, which is about to become the previous one.
+// Removal is best effort: a leftover shard only takes space.
+func removeUnusedShards(indexPath, dir string, keep []string) {
+	used := map[string]bool{}
+	for _, name := range keep {
+		used[name] = true
+	}
+	if header, err := readIndexHeader(indexPath); err == nil {
+		for _, name := range header.Shards {
+			used[name] = true
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !used[entry.Name()] && isShardName(entry.Name()) {
+			os.Remove(filepath.Join(dir, entry.Name()))
+		}
+	}
+}
+
+// isShardName reports whether name is the name of a shard file.
+func isShardName(name string) bool {
+	prefix, rest, ok := strings.Cut(strings.TrimSuffix(name, ".json"), "-")
+	if !ok || !strings.HasSuffix(name, ".json") {
+		return false
+	}
+	_, err := strconv.Atoi(strings.TrimPrefix(prefix, "u"))
+	return err == nil && len(rest) == 12
+}
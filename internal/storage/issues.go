@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+)
+
+// readWorkers bounds how many issue files ReadIssues reads at once.
+const readWorkers = 8
+
+// IssueFile is an issue file read by ReadIssues or ReadAllIssues.
+type IssueFile struct {
+	ID    string        // Issue ID the file is named after
+	Path  string        // Path of the file
+	Issue *models.Issue // Parsed issue, nil if Err is set
+	Err   error         // Why the file couldn't be read or parsed
+}
+
+// ReadAllIssues reads and parses every issue file of a project, several at a
+// time, in file name order. Files that can't be read or parsed are returned
+// with Err set, so the caller can report them and go on. It returns an error
+// only if the issues directory can't be listed; a project without one has no
+// issues.
+func ReadAllIssues(ctx context.Context, projectKey string) ([]IssueFile, error) {
+	issuesDir, err := IssuesDir(ctx, projectKey)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(issuesDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("storage: failed to read issues directory: %w", err)
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			ids = append(ids, strings.TrimSuffix(entry.Name(), ".json"))
+		}
+	}
+	sort.Strings(ids)
+	return ReadIssues(ctx, projectKey, ids), nil
+}
+
+// ReadIssues reads and parses the issue files of the given issue IDs, several
+// at a time. The results are in the order of ids; files that can't be read or
+// parsed are returned with Err set.
+func ReadIssues(ctx context.Context, projectKey string, ids []string) []IssueFile {
+	files := make([]IssueFile, len(ids))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(readWorkers, len(ids)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				files[i] = readIssueFile(ctx, projectKey, ids[i])
+			}
+		}()
+	}
+	for i := range ids {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return files
+}
+
+// readIssueFile reads and parses one issue file.
+func readIssueFile(ctx context.Context, projectKey, id string) IssueFile {
+	path, err := IssuePath(ctx, projectKey, id)
+	if err != nil {
+		return IssueFile{ID: id, Err: err}
+	}
+	var issue models.Issue
+	if err := ReadJSON(ctx, path, &issue); err != nil {
+		return IssueFile{ID: id, Path: path, Err: err}
+	}
+	return IssueFile{ID: id, Path: path, Issue: &issue}
+}
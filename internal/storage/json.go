@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -8,9 +9,17 @@ import (
 )
 
 // ReadJSON reads and unmarshals JSON from a file path.
-// This is a read-only operation, so no locking is needed.
-func ReadJSON(path string, v interface{}) error {
-	data, err := os.ReadFile(path)
+// This is a read-only operation, so no locking is needed. With a Cache in the
+// Env, files that haven't changed since they were last read are not read from
+// disk again.
+func ReadJSON(ctx context.Context, path string, v interface{}) error {
+	var data []byte
+	var err error
+	if cache := cacheFrom(ctx); cache != nil {
+		data, err = cache.files.read(path)
+	} else {
+		data, err = os.ReadFile(path)
+	}
 	if err != nil {
 		if os.IsNotExist(err) {
 			return fmt.Errorf("storage: file not found %s: %w", path, err)
@@ -27,14 +36,14 @@ func ReadJSON(path string, v interface{}) error {
 
 // ReadJSONAtomic is an alias for ReadJSON since reads don't need locking.
 // This function exists for API consistency.
-func ReadJSONAtomic(path string, v interface{}) error {
-	return ReadJSON(path, v)
+func ReadJSONAtomic(ctx context.Context, path string, v interface{}) error {
+	return ReadJSON(ctx, path, v)
 }
 
 // WriteJSON writes JSON to a file using the atomic write protocol.
 // This is a convenience wrapper around WriteJSONAtomic.
-func WriteJSON(path string, v interface{}) error {
-	return WriteJSONAtomic(path, v)
+func WriteJSON(ctx context.Context, path string, v interface{}) error {
+	return WriteJSONAtomic(ctx, path, v)
 }
 
 // EnsureDir ensures that the directory containing the given file path exists.
@@ -1,18 +1,31 @@
 package storage
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"time"
 )
 
+// ErrLockTimeout is returned when a project lock isn't released in time.
+var ErrLockTimeout = errors.New("storage: lock timeout")
+
+// defaultLockTimeout is how long AcquireLock waits for an existing lock to be
+// released, unless the Env sets LockTimeout.
+const defaultLockTimeout = 5 * time.Second
+
 // AcquireLock acquires a lock for the given project key.
 // It returns a cleanup function that must be called to release the lock.
-// The function will wait up to 5 seconds for an existing lock to be released.
+// The function will wait up to 5 seconds (the LockTimeout of the Env) for an
+// existing lock to be released.
 // Uses atomic file creation (O_CREATE|O_EXCL) to prevent race conditions.
-func AcquireLock(projectKey string) (func(), error) {
-	projectDir, err := ProjectDir(projectKey)
+// Acquisitions that had to wait, and timeouts, are recorded in the project's
+// lock stats.
+func AcquireLock(ctx context.Context, projectKey string) (func(), error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
 	if err != nil {
 		return nil, err
 	}
@@ -25,9 +38,14 @@ func AcquireLock(projectKey string) (func(), error) {
 
 	// Try to create lock file atomically, waiting up to 5 seconds if it already exists
 	pid := fmt.Sprintf("%d", os.Getpid())
-	timeout := 5 * time.Second
-	deadline := time.Now().Add(timeout)
+	timeout := EnvFrom(ctx).LockTimeout
+	if timeout <= 0 {
+		timeout = defaultLockTimeout
+	}
+	start := time.Now()
+	deadline := start.Add(timeout)
 	checkInterval := 100 * time.Millisecond
+	contended := false
 
 	for {
 		// Use O_CREATE|O_EXCL for atomic test-and-set semantics
@@ -45,6 +63,17 @@ func AcquireLock(projectKey string) (func(), error) {
 				os.Remove(lockPath)
 				return nil, fmt.Errorf("storage: failed to close lock file: %w", closeErr)
 			}
+			// Only waits are recorded, so commands that get the lock right
+			// away (most of them, and all reads) don't write to the project
+			if contended {
+				recordLockSample(ctx, projectKey, LockSample{
+					At:        Timestamp(ctx),
+					WaitMs:    time.Since(start).Milliseconds(),
+					Contended: true,
+					PID:       os.Getpid(),
+				}, true)
+			}
+
 			// Return cleanup function
 			return func() {
 				os.Remove(lockPath)
@@ -56,10 +85,18 @@ func AcquireLock(projectKey string) (func(), error) {
 			// Some other error occurred
 			return nil, fmt.Errorf("storage: failed to create lock file: %w", err)
 		}
+		contended = true
 
 		// Check if we've exceeded the timeout
 		if time.Now().After(deadline) {
-			return nil, fmt.Errorf("storage: lock timeout after %v", timeout)
+			recordLockSample(ctx, projectKey, LockSample{
+				At:        Timestamp(ctx),
+				WaitMs:    time.Since(start).Milliseconds(),
+				Contended: true,
+				TimedOut:  true,
+				PID:       os.Getpid(),
+			}, false)
+			return nil, fmt.Errorf("%w after %v", ErrLockTimeout, timeout)
 		}
 
 		// Wait before retrying
@@ -67,10 +104,36 @@ func AcquireLock(projectKey string) (func(), error) {
 	}
 }
 
+// AcquireLocks acquires the locks of several projects, in sorted key order so
+// that concurrent callers cannot deadlock. Duplicate keys are locked once.
+// It returns a cleanup function that releases all of them; if a lock cannot be
+// acquired, the locks already held are released before returning the error.
+func AcquireLocks(ctx context.Context, projectKeys ...string) (func(), error) {
+	keys := slices.Clone(projectKeys)
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+
+	var cleanups []func()
+	release := func() {
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			cleanups[i]()
+		}
+	}
+	for _, key := range keys {
+		cleanup, err := AcquireLock(ctx, key)
+		if err != nil {
+			release()
+			return nil, fmt.Errorf("storage: failed to lock project %q: %w", key, err)
+		}
+		cleanups = append(cleanups, cleanup)
+	}
+	return release, nil
+}
+
 // CheckLock checks if a lock exists for the given project key.
 // Returns true if lock exists, false otherwise.
-func CheckLock(projectKey string) (bool, error) {
-	projectDir, err := ProjectDir(projectKey)
+func CheckLock(ctx context.Context, projectKey string) (bool, error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
 	if err != nil {
 		return false, err
 	}
@@ -89,12 +152,12 @@ func CheckLock(projectKey string) (bool, error) {
 
 // WaitForLock waits for a lock to be released, checking at 100ms intervals.
 // Returns an error if the lock still exists after the timeout duration.
-func WaitForLock(projectKey string, timeout time.Duration) error {
+func WaitForLock(ctx context.Context, projectKey string, timeout time.Duration) error {
 	checkInterval := 100 * time.Millisecond
 	deadline := time.Now().Add(timeout)
 
 	for time.Now().Before(deadline) {
-		exists, err := CheckLock(projectKey)
+		exists, err := CheckLock(ctx, projectKey)
 		if err != nil {
 			return err
 		}
@@ -107,5 +170,5 @@ func WaitForLock(projectKey string, timeout time.Duration) error {
 	}
 
 	// Lock still exists after timeout
-	return fmt.Errorf("storage: lock timeout after %v", timeout)
+	return fmt.Errorf("%w after %v", ErrLockTimeout, timeout)
 }
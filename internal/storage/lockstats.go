@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// lockStatsMaxBytes is the size at which the stats file is trimmed.
+	lockStatsMaxBytes = 64 * 1024
+	// lockStatsKeep is the number of most recent samples kept when trimming.
+	lockStatsKeep = 500
+)
+
+// LockSample records one attempt to acquire a project lock that had to wait.
+type LockSample struct {
+	At        string `json:"at"`
+	WaitMs    int64  `json:"wait_ms"`
+	Contended bool   `json:"contended,omitempty"`
+	TimedOut  bool   `json:"timed_out,omitempty"`
+	PID       int    `json:"pid"`
+}
+
+// LockStatsPath returns the lock stats path for the given project key.
+// The file is JSON Lines, one LockSample per acquisition that had to wait
+// and per timeout.
+func LockStatsPath(ctx context.Context, projectKey string) (string, error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(projectDir, ".buyruk_lockstats"), nil
+}
+
+// recordLockSample appends a sample to the project's lock stats.
+// Samples are appended with O_APPEND so that a process that timed out (and so does
+// not hold the lock) can record safely. Trimming rewrites the file and is only done
+// while holding the lock. Recording is best effort: stats must never fail a command.
+func recordLockSample(ctx context.Context, projectKey string, sample LockSample, holdsLock bool) {
+	path, err := LockStatsPath(ctx, projectKey)
+	if err != nil {
+		return
+	}
+	line, err := json.Marshal(sample)
+	if err != nil {
+		return
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return
+	}
+	_, writeErr := f.Write(append(line, '\n'))
+	info, statErr := f.Stat()
+	f.Close()
+	if writeErr != nil || statErr != nil {
+		return
+	}
+
+	if holdsLock && info.Size() > lockStatsMaxBytes {
+		trimLockStats(ctx, path)
+	}
+}
+
+// trimLockStats keeps only the most recent samples in the stats file.
+func trimLockStats(ctx context.Context, path string) {
+	samples, err := readLockSamples(path)
+	if err != nil || len(samples) <= lockStatsKeep {
+		return
+	}
+
+	var data []byte
+	for _, sample := range samples[len(samples)-lockStatsKeep:] {
+		line, err := json.Marshal(sample)
+		if err != nil {
+			return
+		}
+		data = append(data, line...)
+		data = append(data, '\n')
+	}
+	WriteAtomic(ctx, path, data)
+}
+
+// ReadLockStats returns the recorded lock samples of a project, oldest first.
+// A project without stats returns no samples.
+func ReadLockStats(ctx context.Context, projectKey string) ([]LockSample, error) {
+	path, err := LockStatsPath(ctx, projectKey)
+	if err != nil {
+		return nil, err
+	}
+	samples, err := readLockSamples(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("storage: failed to read lock stats: %w", err)
+	}
+	return samples, nil
+}
+
+// ResetLockStats removes the recorded lock samples of a project.
+func ResetLockStats(ctx context.Context, projectKey string) error {
+	path, err := LockStatsPath(ctx, projectKey)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("storage: failed to reset lock stats: %w", err)
+	}
+	return nil
+}
+
+// readLockSamples reads a stats file, skipping lines that do not parse
+// (for example a partial line from an interrupted append).
+func readLockSamples(path string) ([]LockSample, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var samples []LockSample
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var sample LockSample
+		if err := json.Unmarshal(scanner.Bytes(), &sample); err != nil {
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
@@ -0,0 +1,237 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+)
+
+// ProjectTx is a transaction over several files of one project. It holds the
+// project lock from BeginProjectTx until Commit or Rollback, so other
+// commands never see some of its changes without the others, and journals the
+// original content of every file it changes so Rollback can put it back.
+// The files are also listed in the pending transaction log, which `repair`
+// reports after a crash.
+//
+// Example usage:
+//
+//	tx, err := BeginProjectTx(ctx, projectKey, "create_issue", nil)
+//	if err != nil {
+//	    return err
+//	}
+//	defer tx.Rollback()
+//	if err := tx.CreateJSON(issuePath, issue); err != nil {
+//	    return err
+//	}
+//	if err := tx.UpdateJSON(indexPath, &index, addIssue); err != nil {
+//	    return err
+//	}
+//	return tx.Commit()
+type ProjectTx struct {
+	ctx        context.Context
+	projectKey string
+	operation  string
+	metadata   map[string]interface{}
+	cleanup    func()
+	journal    []txEntry
+	done       bool
+}
+
+// txEntry is the content a file had before a ProjectTx first changed it.
+type txEntry struct {
+	path     string
+	original []byte // nil if the file did not exist
+}
+
+// BeginProjectTx acquires the project lock and begins a transaction. The
+// returned transaction must be ended with Commit or Rollback.
+func BeginProjectTx(ctx context.Context, projectKey, operation string, metadata map[string]interface{}) (*ProjectTx, error) {
+	cleanup, err := AcquireLock(ctx, projectKey)
+	if err != nil {
+		return nil, err
+	}
+	tx := &ProjectTx{
+		ctx:        ctx,
+		projectKey: projectKey,
+		operation:  operation,
+		metadata:   metadata,
+		cleanup:    cleanup,
+	}
+	if err := tx.writeLog(); err != nil {
+		cleanup()
+		return nil, err
+	}
+	return tx, nil
+}
+
+// WriteJSON writes a JSON-serializable value to a file of the project.
+func (tx *ProjectTx) WriteJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("storage: failed to marshal JSON: %w", err)
+	}
+	return tx.write(path, data)
+}
+
+// Write writes data to a file of the project.
+func (tx *ProjectTx) Write(path string, data []byte) error {
+	return tx.write(path, data)
+}
+
+// CreateJSON writes a JSON-serializable value to a file of the project, but
+// only if the file doesn't exist.
+func (tx *ProjectTx) CreateJSON(path string, v interface{}) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("storage: file already exists: %s", path)
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("storage: failed to check file existence: %w", err)
+	}
+	return tx.WriteJSON(path, v)
+}
+
+// UpdateJSON performs a read-modify-write of a JSON file of the project, like
+// UpdateJSONAtomic but within the transaction.
+func (tx *ProjectTx) UpdateJSON(path string, v interface{}, updateFunc UpdateFunc) error {
+	if err := ReadJSON(tx.ctx, path, v); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("storage: failed to read current value: %w", err)
+	}
+	if err := updateFunc(v); err != nil {
+		return fmt.Errorf("storage: update function failed: %w", err)
+	}
+	return tx.WriteJSON(path, v)
+}
+
+// WriteIndex writes the project index to its project.json with WriteIndex.
+func (tx *ProjectTx) WriteIndex(indexPath string, index *models.ProjectIndex) error {
+	if err := tx.record(indexPath); err != nil {
+		return err
+	}
+	return WriteIndex(tx.ctx, indexPath, index)
+}
+
+// UpdateIndex performs a read-modify-write of the project index, like
+// UpdateJSON but with ReadIndex and WriteIndex. The update function is given
+// index, which keeps its zero value if project.json doesn't exist.
+func (tx *ProjectTx) UpdateIndex(indexPath string, index *models.ProjectIndex, updateFunc UpdateFunc) error {
+	if _, err := os.Stat(indexPath); err == nil {
+		if err := ReadIndex(tx.ctx, indexPath, index); err != nil {
+			return fmt.Errorf("storage: failed to read current value: %w", err)
+		}
+	}
+	if err := updateFunc(index); err != nil {
+		return fmt.Errorf("storage: update function failed: %w", err)
+	}
+	return tx.WriteIndex(indexPath, index)
+}
+
+// Remove deletes a file of the project. A file that doesn't exist is not an error.
+func (tx *ProjectTx) Remove(path string) error {
+	if err := tx.record(path); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("storage: failed to remove %s: %w", path, err)
+	}
+	return nil
+}
+
+// Commit ends the transaction, keeping its changes, and releases the lock.
+func (tx *ProjectTx) Commit() error {
+	if tx.done {
+		return fmt.Errorf("storage: transaction already ended")
+	}
+	tx.done = true
+	defer tx.cleanup()
+	return CommitTransaction(tx.ctx, tx.projectKey)
+}
+
+// Rollback ends the transaction, restoring the files it changed (newest
+// first), and releases the lock. It does nothing once the transaction has
+// ended, so it can be deferred right after BeginProjectTx.
+func (tx *ProjectTx) Rollback() error {
+	if tx.done {
+		return nil
+	}
+	tx.done = true
+	defer tx.cleanup()
+
+	var errs []error
+	for i := len(tx.journal) - 1; i >= 0; i-- {
+		entry := tx.journal[i]
+		var err error
+		if entry.original == nil {
+			err = os.Remove(entry.path)
+			if os.IsNotExist(err) {
+				err = nil
+			}
+		} else {
+			err = RestoreAtomic(tx.ctx, entry.path, entry.original)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("storage: failed to restore %s: %w", entry.path, err))
+		}
+	}
+	if len(errs) > 0 {
+		// Keep the pending log so repair reports the interrupted transaction
+		return errors.Join(errs...)
+	}
+	return RollbackTransaction(tx.ctx, tx.projectKey)
+}
+
+// write writes data to a file of the project, journaling its original content.
+func (tx *ProjectTx) write(path string, data []byte) error {
+	if err := tx.record(path); err != nil {
+		return err
+	}
+	return WriteAtomic(tx.ctx, path, data)
+}
+
+// record journals the content of a file before the transaction first changes it.
+func (tx *ProjectTx) record(path string) error {
+	if tx.done {
+		return fmt.Errorf("storage: transaction already ended")
+	}
+	projectKey, err := extractProjectKeyFromPath(path)
+	if err != nil {
+		return fmt.Errorf("storage: failed to extract project key from path: %w", err)
+	}
+	if projectKey != tx.projectKey {
+		return fmt.Errorf("storage: %s is not a file of project %q", path, tx.projectKey)
+	}
+	for _, entry := range tx.journal {
+		if entry.path == path {
+			return nil
+		}
+	}
+
+	original, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("storage: failed to read %s: %w", path, err)
+	}
+	if original == nil && err == nil {
+		original = []byte{} // Existing but empty, restored as such
+	}
+	tx.journal = append(tx.journal, txEntry{path: path, original: original})
+	return tx.writeLog()
+}
+
+// writeLog records the transaction and the files it has changed so far in
+// the pending transaction log.
+func (tx *ProjectTx) writeLog() error {
+	metadata := map[string]interface{}{}
+	for key, value := range tx.metadata {
+		metadata[key] = value
+	}
+	if len(tx.journal) > 0 {
+		files := make([]string, 0, len(tx.journal))
+		for _, entry := range tx.journal {
+			files = append(files, entry.path)
+		}
+		metadata["files"] = files
+	}
+	return BeginTransaction(tx.ctx, tx.projectKey, tx.operation, metadata)
+}
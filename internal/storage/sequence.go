@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SequencePath returns the path of the issue sequence counter for the given
+// project key. It holds the next sequence number AllocateSequence hands out.
+func SequencePath(ctx context.Context, projectKey string) (string, error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(projectDir, ".buyruk_seq"), nil
+}
+
+// AllocateSequence reserves the next issue sequence number of a project.
+// computed is the next number derived from the project index; the number
+// handed out is the higher of it and the project's sequence counter, and the
+// counter moves past it under the project lock, so concurrent creates never
+// get the same number. A sequence source of the context's Env takes
+// precedence, as in NextSequence.
+func AllocateSequence(ctx context.Context, projectKey string, computed int) (int, error) {
+	if next := EnvFrom(ctx).Sequence; next != nil {
+		return next(projectKey), nil
+	}
+
+	path, err := SequencePath(ctx, projectKey)
+	if err != nil {
+		return 0, err
+	}
+
+	cleanup, err := AcquireLock(ctx, projectKey)
+	if err != nil {
+		return 0, err
+	}
+	defer cleanup()
+
+	seq := computed
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		// An unreadable counter is rebuilt from the index
+		if counter, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil && counter > seq {
+			seq = counter
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return 0, fmt.Errorf("storage: failed to read sequence counter: %w", err)
+	}
+
+	if err := WriteAtomic(ctx, path, []byte(strconv.Itoa(seq+1)+"\n")); err != nil {
+		return 0, fmt.Errorf("storage: failed to write sequence counter: %w", err)
+	}
+	return seq, nil
+}
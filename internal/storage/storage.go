@@ -1,6 +1,9 @@
 package storage
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -49,9 +52,48 @@ func ConfigDir() (string, error) {
 	return cachedConfigDir, nil
 }
 
+// LocalDirName is the name of the directory that switches buyruk to repo-local
+// mode: projects are kept in it instead of the user config directory, so they
+// can be versioned with the code.
+const LocalDirName = ".buyruk"
+
+// LocalDir returns the repo-local data directory: the nearest .buyruk
+// directory in the working directory or one of its parents.
+func LocalDir() (string, bool) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+	for {
+		candidate := filepath.Join(dir, LocalDirName)
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// DataDir returns the directory projects are stored in: the directory of the
+// context's Env, the repo-local directory in repo-local mode, or otherwise
+// the config directory. Global files (config, sessions, away list) always stay
+// in the config directory.
+func DataDir(ctx context.Context) (string, error) {
+	if dir := EnvFrom(ctx).DataDir; dir != "" {
+		return dir, nil
+	}
+	if dir, ok := LocalDir(); ok {
+		return dir, nil
+	}
+	return ConfigDir()
+}
+
 // ProjectDir returns the project directory path for the given project key.
-func ProjectDir(projectKey string) (string, error) {
-	configDir, err := ConfigDir()
+func ProjectDir(ctx context.Context, projectKey string) (string, error) {
+	dataDir, err := DataDir(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -65,12 +107,38 @@ func ProjectDir(projectKey string) (string, error) {
 		strings.Contains(cleanKey, "/") || strings.Contains(cleanKey, "\\") {
 		return "", fmt.Errorf("storage: invalid project key %q", projectKey)
 	}
-	return filepath.Join(configDir, "projects", cleanKey), nil
+	return filepath.Join(dataDir, "projects", cleanKey), nil
+}
+
+// ListProjects returns the keys of all projects, in directory order.
+// Hidden directories (such as a project being renamed) are skipped.
+// Returns an empty list if no project has been created yet.
+func ListProjects(ctx context.Context) ([]string, error) {
+	dataDir, err := DataDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dataDir, "projects"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("storage: failed to read projects directory: %w", err)
+	}
+
+	keys := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			keys = append(keys, entry.Name())
+		}
+	}
+	return keys, nil
 }
 
 // ProjectIndexPath returns the project.json path for the given project key.
-func ProjectIndexPath(projectKey string) (string, error) {
-	projectDir, err := ProjectDir(projectKey)
+func ProjectIndexPath(ctx context.Context, projectKey string) (string, error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
 	if err != nil {
 		return "", err
 	}
@@ -78,9 +146,179 @@ func ProjectIndexPath(projectKey string) (string, error) {
 	return filepath.Join(projectDir, "project.json"), nil
 }
 
+// WorkflowPath returns the workflow.json path for the given project key.
+func WorkflowPath(ctx context.Context, projectKey string) (string, error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(projectDir, "workflow.json"), nil
+}
+
+// RoadmapPath returns the roadmap.json path for the given project key.
+func RoadmapPath(ctx context.Context, projectKey string) (string, error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(projectDir, "roadmap.json"), nil
+}
+
+// SprintsPath returns the sprints.json path for the given project key.
+func SprintsPath(ctx context.Context, projectKey string) (string, error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(projectDir, "sprints.json"), nil
+}
+
+// MilestonesPath returns the milestones.json path for the given project key.
+func MilestonesPath(ctx context.Context, projectKey string) (string, error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(projectDir, "milestones.json"), nil
+}
+
+// ComponentsPath returns the components.json path for the given project key.
+func ComponentsPath(ctx context.Context, projectKey string) (string, error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(projectDir, "components.json"), nil
+}
+
+// ViewsPath returns the views.json path for the given project key.
+func ViewsPath(ctx context.Context, projectKey string) (string, error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(projectDir, "views.json"), nil
+}
+
+// ReadOnlyPath returns the readonly.json path for the given project key.
+// It exists only while the project is locked read-only.
+func ReadOnlyPath(ctx context.Context, projectKey string) (string, error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(projectDir, "readonly.json"), nil
+}
+
+// RedirectsPath returns the redirects.json path for the given project key.
+// It maps IDs of issues moved out of the project to their new IDs.
+func RedirectsPath(ctx context.Context, projectKey string) (string, error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(projectDir, "redirects.json"), nil
+}
+
+// MigrateCheckpointPath returns the checkpoint path of an interrupted migrate-wizard
+// run into the given project.
+func MigrateCheckpointPath(ctx context.Context, projectKey string) (string, error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(projectDir, ".buyruk_migrate"), nil
+}
+
+// KeysPath returns the keys.json path for the given project key.
+// It holds the salts and passphrase checks of the keys sensitive issues are
+// encrypted with, never the keys themselves.
+func KeysPath(ctx context.Context, projectKey string) (string, error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(projectDir, "keys.json"), nil
+}
+
+// SessionsDir returns the directory of unlocked keys, one subdirectory per session.
+func SessionsDir() (string, error) {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "sessions"), nil
+}
+
+// AwayPath returns the path of the away registry, shared by all projects.
+func AwayPath() (string, error) {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "away.json"), nil
+}
+
+// NotificationsPath returns the path of a user's notification read state,
+// shared by all projects.
+func NotificationsPath(user string) (string, error) {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "notifications", user+".json"), nil
+}
+
+// PRStatusCachePath returns the path of the cache of fetched pull request
+// statuses, shared by all projects.
+func PRStatusCachePath() (string, error) {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "pr_status_cache.json"), nil
+}
+
+// NotifySentPath returns the path of the record of reminders and overdue
+// issues buyruk notify has already sent, shared by all projects.
+func NotifySentPath() (string, error) {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "notify_sent.json"), nil
+}
+
+// GlobalViewsPath returns the path of the saved views shared by all projects.
+func GlobalViewsPath() (string, error) {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "views.json"), nil
+}
+
+// ThemesDir returns the directory of custom color themes.
+func ThemesDir() (string, error) {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "themes"), nil
+}
+
+// ThemePath returns the path of a custom color theme.
+func ThemePath(name string) (string, error) {
+	themesDir, err := ThemesDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(themesDir, name+".json"), nil
+}
+
 // IssuesDir returns the issues/ directory path for the given project key.
-func IssuesDir(projectKey string) (string, error) {
-	projectDir, err := ProjectDir(projectKey)
+func IssuesDir(ctx context.Context, projectKey string) (string, error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
 	if err != nil {
 		return "", err
 	}
@@ -88,9 +326,195 @@ func IssuesDir(projectKey string) (string, error) {
 	return filepath.Join(projectDir, "issues"), nil
 }
 
+// IncomingDir returns the incoming/ directory path for the given project key.
+// It holds issue and epic changes from other copies of the project awaiting
+// review.
+func IncomingDir(ctx context.Context, projectKey string) (string, error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(projectDir, "incoming"), nil
+}
+
+// IncomingPath returns the path of a staged incoming change to an issue.
+func IncomingPath(ctx context.Context, projectKey, issueID string) (string, error) {
+	incomingDir, err := IncomingDir(ctx, projectKey)
+	if err != nil {
+		return "", err
+	}
+
+	cleanID := filepath.Clean(issueID)
+	if cleanID != issueID || filepath.IsAbs(cleanID) || strings.ContainsAny(cleanID, `/\`) {
+		return "", fmt.Errorf("storage: invalid issue ID: contains path separators or is absolute")
+	}
+	return filepath.Join(incomingDir, cleanID+".json"), nil
+}
+
+// IncomingEpicPath returns the path of a staged incoming change to an epic.
+func IncomingEpicPath(ctx context.Context, projectKey, epicID string) (string, error) {
+	incomingDir, err := IncomingDir(ctx, projectKey)
+	if err != nil {
+		return "", err
+	}
+
+	cleanID := filepath.Clean(epicID)
+	if cleanID != epicID || filepath.IsAbs(cleanID) || strings.ContainsAny(cleanID, `/\`) {
+		return "", fmt.Errorf("storage: invalid epic ID: contains path separators or is absolute")
+	}
+	return filepath.Join(incomingDir, "epics", cleanID+".json"), nil
+}
+
+// ArchiveDir returns the archive/ directory path for the given project key.
+// It holds issues archived by the retention policy, out of the index.
+func ArchiveDir(ctx context.Context, projectKey string) (string, error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(projectDir, "archive"), nil
+}
+
+// ArchivePath returns the path of an archived issue.
+func ArchivePath(ctx context.Context, projectKey, issueID string) (string, error) {
+	archiveDir, err := ArchiveDir(ctx, projectKey)
+	if err != nil {
+		return "", err
+	}
+
+	cleanID := filepath.Clean(issueID)
+	if cleanID != issueID || filepath.IsAbs(cleanID) || strings.ContainsAny(cleanID, `/\`) {
+		return "", fmt.Errorf("storage: invalid issue ID: contains path separators or is absolute")
+	}
+	return filepath.Join(archiveDir, cleanID+".json"), nil
+}
+
+// AttachmentsDir returns the attachments/ directory path of an issue of the
+// given project key. It holds copies of the files attached to the issue.
+func AttachmentsDir(ctx context.Context, projectKey, issueID string) (string, error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
+	if err != nil {
+		return "", err
+	}
+
+	cleanID := filepath.Clean(issueID)
+	if cleanID != issueID || filepath.IsAbs(cleanID) || strings.ContainsAny(cleanID, `/\`) || cleanID == ".." {
+		return "", fmt.Errorf("storage: invalid issue ID: contains path separators or is absolute")
+	}
+	return filepath.Join(projectDir, "attachments", cleanID), nil
+}
+
+// AttachmentPath returns the path of a file attached to an issue.
+func AttachmentPath(ctx context.Context, projectKey, issueID, name string) (string, error) {
+	attachmentsDir, err := AttachmentsDir(ctx, projectKey, issueID)
+	if err != nil {
+		return "", err
+	}
+
+	cleanName := filepath.Clean(name)
+	if cleanName != name || filepath.IsAbs(cleanName) || strings.ContainsAny(cleanName, `/\`) || cleanName == "." || cleanName == ".." {
+		return "", fmt.Errorf("storage: invalid attachment name: contains path separators or is absolute")
+	}
+	return filepath.Join(attachmentsDir, cleanName), nil
+}
+
+// TrashDir returns the .trash/ directory path for the given project key.
+// It holds deleted issues until the trash is emptied, out of the index.
+func TrashDir(ctx context.Context, projectKey string) (string, error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(projectDir, ".trash"), nil
+}
+
+// TrashPath returns the path of a deleted issue in the trash.
+func TrashPath(ctx context.Context, projectKey, issueID string) (string, error) {
+	trashDir, err := TrashDir(ctx, projectKey)
+	if err != nil {
+		return "", err
+	}
+
+	cleanID := filepath.Clean(issueID)
+	if cleanID != issueID || filepath.IsAbs(cleanID) || strings.ContainsAny(cleanID, `/\`) {
+		return "", fmt.Errorf("storage: invalid issue ID: contains path separators or is absolute")
+	}
+	return filepath.Join(trashDir, cleanID+".json"), nil
+}
+
+// PolicyPath returns the policy.json path (retention policy) for the given project key.
+func PolicyPath(ctx context.Context, projectKey string) (string, error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(projectDir, "policy.json"), nil
+}
+
+// RemotePath returns the path of the remote a project was cloned from.
+func RemotePath(ctx context.Context, projectKey string) (string, error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(projectDir, "remote.json"), nil
+}
+
+// BackendCopyPath returns the path recording which version of the storage
+// backend's copy of a project the local copy is at.
+func BackendCopyPath(ctx context.Context, projectKey string) (string, error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(projectDir, "backend.json"), nil
+}
+
+// BackendDir returns the data directory of the local copy of a storage
+// backend's projects, in the config directory under a hash of its URL.
+func BackendDir(backendURL string) (string, error) {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(backendURL))
+	return filepath.Join(configDir, "backends", hex.EncodeToString(sum[:8])), nil
+}
+
+// HooksPath returns the path of a project's hook configuration. Hooks run
+// commands, so they are kept in the user's config directory, never with the
+// project data that sync, backups, and imports bring in from other machines.
+func HooksPath(projectKey string) (string, error) {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	cleanKey := filepath.Clean(projectKey)
+	if cleanKey == "" || cleanKey == "." || cleanKey == ".." ||
+		strings.Contains(cleanKey, "/") || strings.Contains(cleanKey, "\\") {
+		return "", fmt.Errorf("storage: invalid project key %q", projectKey)
+	}
+	return filepath.Join(configDir, "hooks", cleanKey+".json"), nil
+}
+
+// LegacyHooksPath returns where hooks of a project were configured before
+// they moved to the config directory: hooks.json in the project directory.
+// It is only looked at to warn that it is ignored.
+func LegacyHooksPath(ctx context.Context, projectKey string) (string, error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(projectDir, "hooks.json"), nil
+}
+
 // EpicsDir returns the epics/ directory path for the given project key.
-func EpicsDir(projectKey string) (string, error) {
-	projectDir, err := ProjectDir(projectKey)
+func EpicsDir(ctx context.Context, projectKey string) (string, error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
 	if err != nil {
 		return "", err
 	}
@@ -99,8 +523,8 @@ func EpicsDir(projectKey string) (string, error) {
 }
 
 // IssuePath returns the individual issue file path for the given project key and issue ID.
-func IssuePath(projectKey, issueID string) (string, error) {
-	issuesDir, err := IssuesDir(projectKey)
+func IssuePath(ctx context.Context, projectKey, issueID string) (string, error) {
+	issuesDir, err := IssuesDir(ctx, projectKey)
 	if err != nil {
 		return "", err
 	}
@@ -131,8 +555,8 @@ func IssuePath(projectKey, issueID string) (string, error) {
 }
 
 // EpicPath returns the individual epic file path for the given project key and epic ID.
-func EpicPath(projectKey, epicID string) (string, error) {
-	epicsDir, err := EpicsDir(projectKey)
+func EpicPath(ctx context.Context, projectKey, epicID string) (string, error) {
+	epicsDir, err := EpicsDir(ctx, projectKey)
 	if err != nil {
 		return "", err
 	}
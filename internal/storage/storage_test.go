@@ -1,16 +1,21 @@
 package storage
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
 )
 
 // TestConfigDir tests the ConfigDir function
@@ -60,7 +65,7 @@ func TestProjectDir(t *testing.T) {
 		return tmpDir, nil
 	}
 
-	projectDir, err := ProjectDir("TEST-PROJ")
+	projectDir, err := ProjectDir(t.Context(), "TEST-PROJ")
 	if err != nil {
 		t.Fatalf("ProjectDir() failed: %v", err)
 	}
@@ -71,6 +76,77 @@ func TestProjectDir(t *testing.T) {
 	}
 }
 
+// TestLocalDir tests repo-local mode: a .buyruk directory in the working
+// directory or a parent holds the projects instead of the config directory.
+func TestLocalDir(t *testing.T) {
+	repo := t.TempDir()
+	nested := filepath.Join(repo, "src", "pkg")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatalf("Failed to create directories: %v", err)
+	}
+	t.Chdir(nested)
+
+	if dir, ok := LocalDir(); ok {
+		t.Fatalf("LocalDir() = %s, want none without a .buyruk directory", dir)
+	}
+	configDir, _ := ConfigDir()
+	if dataDir, _ := DataDir(t.Context()); dataDir != configDir {
+		t.Errorf("DataDir() = %s, want config dir %s", dataDir, configDir)
+	}
+
+	// A .buyruk file is not a repo-local directory
+	if err := os.WriteFile(filepath.Join(nested, LocalDirName), nil, 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, ok := LocalDir(); ok {
+		t.Error("Expected a .buyruk file to be ignored")
+	}
+
+	localDir := filepath.Join(repo, LocalDirName)
+	if err := os.Mkdir(localDir, 0755); err != nil {
+		t.Fatalf("Failed to create %s: %v", localDir, err)
+	}
+	// Resolve symlinks in the temp dir (macOS /var -> /private/var)
+	want, _ := filepath.EvalSymlinks(localDir)
+	dir, ok := LocalDir()
+	if got, _ := filepath.EvalSymlinks(dir); !ok || got != want {
+		t.Fatalf("LocalDir() = %s, %v; want %s", dir, ok, want)
+	}
+	projectDir, err := ProjectDir(t.Context(), "CORE")
+	if err != nil {
+		t.Fatalf("ProjectDir() failed: %v", err)
+	}
+	if projectDir != filepath.Join(dir, "projects", "CORE") {
+		t.Errorf("ProjectDir() = %s, want it under %s", projectDir, dir)
+	}
+	// Global files stay in the config directory
+	if awayPath, _ := AwayPath(); !strings.HasPrefix(awayPath, configDir) {
+		t.Errorf("AwayPath() = %s, want it under %s", awayPath, configDir)
+	}
+}
+
+// TestEnvDataDir tests keeping projects in the data directory of the Env
+func TestEnvDataDir(t *testing.T) {
+	t.Chdir(t.TempDir())
+	dir := t.TempDir()
+
+	ctx := WithEnv(t.Context(), Env{DataDir: dir})
+	if got, _ := DataDir(ctx); got != dir {
+		t.Errorf("DataDir() = %s, want %s", got, dir)
+	}
+	if projectDir, _ := ProjectDir(ctx, "CORE"); projectDir != filepath.Join(dir, "projects", "CORE") {
+		t.Errorf("ProjectDir() = %s, want it under %s", projectDir, dir)
+	}
+	configDir, _ := ConfigDir()
+	if awayPath, _ := AwayPath(); !strings.HasPrefix(awayPath, configDir) {
+		t.Errorf("AwayPath() = %s, want it under %s", awayPath, configDir)
+	}
+
+	if got, _ := DataDir(t.Context()); got != configDir {
+		t.Errorf("DataDir() = %s without an Env, want config dir %s", got, configDir)
+	}
+}
+
 // TestProjectIndexPath tests the ProjectIndexPath function
 func TestProjectIndexPath(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -87,7 +163,7 @@ func TestProjectIndexPath(t *testing.T) {
 		return tmpDir, nil
 	}
 
-	indexPath, err := ProjectIndexPath("TEST-PROJ")
+	indexPath, err := ProjectIndexPath(t.Context(), "TEST-PROJ")
 	if err != nil {
 		t.Fatalf("ProjectIndexPath() failed: %v", err)
 	}
@@ -114,7 +190,7 @@ func TestIssuePath(t *testing.T) {
 		return tmpDir, nil
 	}
 
-	issuePath, err := IssuePath("TEST-PROJ", "T-123")
+	issuePath, err := IssuePath(t.Context(), "TEST-PROJ", "T-123")
 	if err != nil {
 		t.Fatalf("IssuePath() failed: %v", err)
 	}
@@ -141,7 +217,7 @@ func TestEpicPath(t *testing.T) {
 		return tmpDir, nil
 	}
 
-	epicPath, err := EpicPath("TEST-PROJ", "E-1")
+	epicPath, err := EpicPath(t.Context(), "TEST-PROJ", "E-1")
 	if err != nil {
 		t.Fatalf("EpicPath() failed: %v", err)
 	}
@@ -194,11 +270,11 @@ func TestAcquireLock(t *testing.T) {
 	}
 
 	projectKey := "TEST-PROJ"
-	projectDir, _ := ProjectDir(projectKey)
+	projectDir, _ := ProjectDir(t.Context(), projectKey)
 	os.MkdirAll(projectDir, 0755)
 
 	// Acquire lock
-	cleanup, err := AcquireLock(projectKey)
+	cleanup, err := AcquireLock(t.Context(), projectKey)
 	if err != nil {
 		t.Fatalf("AcquireLock() failed: %v", err)
 	}
@@ -210,7 +286,7 @@ func TestAcquireLock(t *testing.T) {
 	}
 
 	// Verify lock is detected
-	exists, err := CheckLock(projectKey)
+	exists, err := CheckLock(t.Context(), projectKey)
 	if err != nil {
 		t.Fatalf("CheckLock() failed: %v", err)
 	}
@@ -227,7 +303,7 @@ func TestAcquireLock(t *testing.T) {
 	}
 
 	// Verify lock is no longer detected
-	exists, err = CheckLock(projectKey)
+	exists, err = CheckLock(t.Context(), projectKey)
 	if err != nil {
 		t.Fatalf("CheckLock() failed: %v", err)
 	}
@@ -251,7 +327,7 @@ func TestWaitForLock(t *testing.T) {
 	}
 
 	projectKey := "TEST-PROJ"
-	projectDir, _ := ProjectDir(projectKey)
+	projectDir, _ := ProjectDir(t.Context(), projectKey)
 	os.MkdirAll(projectDir, 0755)
 	lockPath := filepath.Join(projectDir, ".buyruk.lock")
 
@@ -260,7 +336,7 @@ func TestWaitForLock(t *testing.T) {
 
 	// Test timeout - should fail after short timeout
 	start := time.Now()
-	err := WaitForLock(projectKey, 200*time.Millisecond)
+	err := WaitForLock(t.Context(), projectKey, 200*time.Millisecond)
 	duration := time.Since(start)
 
 	if err == nil {
@@ -274,7 +350,7 @@ func TestWaitForLock(t *testing.T) {
 
 	// Remove lock and verify it succeeds immediately
 	os.Remove(lockPath)
-	err = WaitForLock(projectKey, 200*time.Millisecond)
+	err = WaitForLock(t.Context(), projectKey, 200*time.Millisecond)
 	if err != nil {
 		t.Fatalf("WaitForLock() should succeed when lock doesn't exist: %v", err)
 	}
@@ -295,7 +371,7 @@ func TestConcurrentLocks(t *testing.T) {
 	}
 
 	projectKey := "TEST-PROJ"
-	projectDir, _ := ProjectDir(projectKey)
+	projectDir, _ := ProjectDir(t.Context(), projectKey)
 	os.MkdirAll(projectDir, 0755)
 
 	// Channel to collect results
@@ -304,7 +380,7 @@ func TestConcurrentLocks(t *testing.T) {
 	// Launch 10 concurrent lock attempts
 	for i := 0; i < 10; i++ {
 		go func() {
-			cleanup, err := AcquireLock(projectKey)
+			cleanup, err := AcquireLock(t.Context(), projectKey)
 			if err != nil {
 				results <- err
 				return
@@ -351,13 +427,13 @@ func TestBeginTransaction(t *testing.T) {
 		"file":     "issues/T-123.json",
 	}
 
-	err := BeginTransaction(projectKey, "create_issue", metadata)
+	err := BeginTransaction(t.Context(), projectKey, "create_issue", metadata)
 	if err != nil {
 		t.Fatalf("BeginTransaction() failed: %v", err)
 	}
 
 	// Verify transaction file exists
-	projectDir, _ := ProjectDir(projectKey)
+	projectDir, _ := ProjectDir(t.Context(), projectKey)
 	transactionPath := filepath.Join(projectDir, ".buyruk_pending")
 	if _, err := os.Stat(transactionPath); os.IsNotExist(err) {
 		t.Fatal("Transaction file was not created")
@@ -398,14 +474,14 @@ func TestCommitTransaction(t *testing.T) {
 	}
 
 	projectKey := "TEST-PROJ"
-	projectDir, _ := ProjectDir(projectKey)
+	projectDir, _ := ProjectDir(t.Context(), projectKey)
 	os.MkdirAll(projectDir, 0755)
 
 	// Begin transaction
-	BeginTransaction(projectKey, "test", nil)
+	BeginTransaction(t.Context(), projectKey, "test", nil)
 
 	// Commit transaction
-	err := CommitTransaction(projectKey)
+	err := CommitTransaction(t.Context(), projectKey)
 	if err != nil {
 		t.Fatalf("CommitTransaction() failed: %v", err)
 	}
@@ -432,14 +508,14 @@ func TestRollbackTransaction(t *testing.T) {
 	}
 
 	projectKey := "TEST-PROJ"
-	projectDir, _ := ProjectDir(projectKey)
+	projectDir, _ := ProjectDir(t.Context(), projectKey)
 	os.MkdirAll(projectDir, 0755)
 
 	// Begin transaction
-	BeginTransaction(projectKey, "test", nil)
+	BeginTransaction(t.Context(), projectKey, "test", nil)
 
 	// Rollback transaction
-	err := RollbackTransaction(projectKey)
+	err := RollbackTransaction(t.Context(), projectKey)
 	if err != nil {
 		t.Fatalf("RollbackTransaction() failed: %v", err)
 	}
@@ -471,7 +547,7 @@ func TestCheckPendingTransaction(t *testing.T) {
 	}
 
 	// Check when no transaction exists
-	exists, _, err := CheckPendingTransaction(projectKey)
+	exists, _, err := CheckPendingTransaction(t.Context(), projectKey)
 	if err != nil {
 		t.Fatalf("CheckPendingTransaction() failed: %v", err)
 	}
@@ -480,10 +556,10 @@ func TestCheckPendingTransaction(t *testing.T) {
 	}
 
 	// Begin transaction
-	BeginTransaction(projectKey, "test", metadata)
+	BeginTransaction(t.Context(), projectKey, "test", metadata)
 
 	// Check when transaction exists
-	exists, retrievedTransaction, err := CheckPendingTransaction(projectKey)
+	exists, retrievedTransaction, err := CheckPendingTransaction(t.Context(), projectKey)
 	if err != nil {
 		t.Fatalf("CheckPendingTransaction() failed: %v", err)
 	}
@@ -505,7 +581,7 @@ func TestWriteAtomic(t *testing.T) {
 	testFile := filepath.Join(tmpDir, "test.json")
 	testData := []byte(`{"test": "data"}`)
 
-	err := WriteAtomic(testFile, testData)
+	err := WriteAtomic(t.Context(), testFile, testData)
 	if err != nil {
 		t.Fatalf("WriteAtomic() failed: %v", err)
 	}
@@ -532,6 +608,80 @@ func TestWriteAtomic(t *testing.T) {
 	}
 }
 
+// TestWriteAtomic_PartialWrite tests that a write that fails partway leaves
+// the previous content in place
+func TestWriteAtomic_PartialWrite(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test.json")
+	if err := WriteAtomic(t.Context(), testFile, []byte(`{"value": 1}`)); err != nil {
+		t.Fatalf("WriteAtomic() failed: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		env  Env
+	}{
+		{"write error", Env{WriteFile: func(f *os.File, data []byte) (int, error) {
+			n, _ := f.Write(data[:len(data)/2])
+			return n, errors.New("disk full")
+		}}},
+		{"short write", Env{WriteFile: func(f *os.File, data []byte) (int, error) {
+			return f.Write(data[:len(data)/2])
+		}}},
+		{"sync error", Env{SyncFile: func(f *os.File) error {
+			return errors.New("i/o error")
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := WithEnv(t.Context(), tt.env)
+			if err := WriteAtomic(ctx, testFile, []byte(`{"value": 2}`)); err == nil {
+				t.Fatal("WriteAtomic() should fail")
+			}
+			if data, _ := os.ReadFile(testFile); string(data) != `{"value": 1}` {
+				t.Errorf("File content = %s, want the previous content", data)
+			}
+			if _, err := os.Stat(testFile + ".tmp"); !os.IsNotExist(err) {
+				t.Error("Partial temp file was not cleaned up")
+			}
+		})
+	}
+}
+
+// TestEnvDurability tests which syncs each durability level makes
+func TestEnvDurability(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test.json")
+	tests := []struct {
+		level           string
+		fileSyncs, dirs int
+	}{
+		{DurabilityNone, 0, 0},
+		{DurabilityFile, 1, 0},
+		{DurabilityFull, 1, 1},
+		{"", 1, 1},
+	}
+	for _, tt := range tests {
+		var fileSyncs, dirSyncs int
+		ctx := WithEnv(t.Context(), Env{
+			Durability: tt.level,
+			SyncFile: func(f *os.File) error {
+				fileSyncs++
+				return f.Sync()
+			},
+			SyncDir: func(dir string) error {
+				dirSyncs++
+				return syncDirectory(dir)
+			},
+		})
+		if err := WriteAtomic(ctx, testFile, []byte(`{}`)); err != nil {
+			t.Fatalf("WriteAtomic() with durability %q failed: %v", tt.level, err)
+		}
+		if fileSyncs != tt.fileSyncs || dirSyncs != tt.dirs {
+			t.Errorf("Durability %q synced the file %d and the directory %d times, want %d and %d",
+				tt.level, fileSyncs, dirSyncs, tt.fileSyncs, tt.dirs)
+		}
+	}
+}
+
 // TestUpdateJSONAtomic tests atomic read-modify-write operation
 func TestUpdateJSONAtomic(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -548,10 +698,10 @@ func TestUpdateJSONAtomic(t *testing.T) {
 	}
 
 	projectKey := "TEST-PROJ"
-	projectDir, _ := ProjectDir(projectKey)
+	projectDir, _ := ProjectDir(t.Context(), projectKey)
 	os.MkdirAll(projectDir, 0755)
 
-	indexPath, _ := ProjectIndexPath(projectKey)
+	indexPath, _ := ProjectIndexPath(t.Context(), projectKey)
 
 	// Test updating non-existent file (should create it)
 	type TestData struct {
@@ -559,7 +709,7 @@ func TestUpdateJSONAtomic(t *testing.T) {
 	}
 
 	var data TestData
-	err := UpdateJSONAtomic(indexPath, &data, func(v interface{}) error {
+	err := UpdateJSONAtomic(t.Context(), indexPath, &data, func(v interface{}) error {
 		d := v.(*TestData)
 		d.Value = 42
 		return nil
@@ -571,7 +721,7 @@ func TestUpdateJSONAtomic(t *testing.T) {
 
 	// Verify file was created and written
 	var readData TestData
-	if err := ReadJSON(indexPath, &readData); err != nil {
+	if err := ReadJSON(t.Context(), indexPath, &readData); err != nil {
 		t.Fatalf("Failed to read updated file: %v", err)
 	}
 
@@ -580,7 +730,7 @@ func TestUpdateJSONAtomic(t *testing.T) {
 	}
 
 	// Test updating existing file
-	err = UpdateJSONAtomic(indexPath, &readData, func(v interface{}) error {
+	err = UpdateJSONAtomic(t.Context(), indexPath, &readData, func(v interface{}) error {
 		d := v.(*TestData)
 		d.Value = 100
 		return nil
@@ -592,7 +742,7 @@ func TestUpdateJSONAtomic(t *testing.T) {
 
 	// Verify update
 	var readData2 TestData
-	if err := ReadJSON(indexPath, &readData2); err != nil {
+	if err := ReadJSON(t.Context(), indexPath, &readData2); err != nil {
 		t.Fatalf("Failed to read updated file: %v", err)
 	}
 
@@ -617,23 +767,23 @@ func TestUpdateJSONAtomic_ErrorHandling(t *testing.T) {
 	}
 
 	projectKey := "TEST-PROJ"
-	projectDir, _ := ProjectDir(projectKey)
+	projectDir, _ := ProjectDir(t.Context(), projectKey)
 	os.MkdirAll(projectDir, 0755)
 
-	indexPath, _ := ProjectIndexPath(projectKey)
+	indexPath, _ := ProjectIndexPath(t.Context(), projectKey)
 
 	// Create initial file
 	type TestData struct {
 		Value int `json:"value"`
 	}
 	initialData := TestData{Value: 10}
-	if err := WriteJSONAtomic(indexPath, &initialData); err != nil {
+	if err := WriteJSONAtomic(t.Context(), indexPath, &initialData); err != nil {
 		t.Fatalf("Failed to create initial file: %v", err)
 	}
 
 	// Test that error in update function aborts the update
 	var data TestData
-	err := UpdateJSONAtomic(indexPath, &data, func(v interface{}) error {
+	err := UpdateJSONAtomic(t.Context(), indexPath, &data, func(v interface{}) error {
 		return fmt.Errorf("update function error")
 	})
 
@@ -643,7 +793,7 @@ func TestUpdateJSONAtomic_ErrorHandling(t *testing.T) {
 
 	// Verify file was not modified
 	var readData TestData
-	if err := ReadJSON(indexPath, &readData); err != nil {
+	if err := ReadJSON(t.Context(), indexPath, &readData); err != nil {
 		t.Fatalf("Failed to read file: %v", err)
 	}
 
@@ -652,6 +802,187 @@ func TestUpdateJSONAtomic_ErrorHandling(t *testing.T) {
 	}
 }
 
+// TestUpdateJSONFilesAtomic tests updating files of two projects together
+func TestUpdateJSONFilesAtomic(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalUserConfigDir := userConfigDirFunc
+	originalCachedDir := cachedConfigDir
+	defer func() {
+		userConfigDirFunc = originalUserConfigDir
+		cachedConfigDir = originalCachedDir
+	}()
+
+	resetConfigDirCache()
+	userConfigDirFunc = func() (string, error) {
+		return tmpDir, nil
+	}
+
+	type TestData struct {
+		Value int `json:"value"`
+	}
+	pathA, _ := IssuePath(t.Context(), "PROJ-A", "PROJ-A-1")
+	pathB, _ := IssuePath(t.Context(), "PROJ-B", "PROJ-B-1")
+	missing, _ := IssuePath(t.Context(), "PROJ-B", "PROJ-B-2")
+	for _, path := range []string{pathA, pathB} {
+		if err := WriteJSONAtomic(t.Context(), path, &TestData{Value: 1}); err != nil {
+			t.Fatalf("Failed to create %s: %v", path, err)
+		}
+	}
+
+	var a, b, c TestData
+	err := UpdateJSONFilesAtomic(t.Context(), []string{pathA, pathB, missing}, []interface{}{&a, &b, &c}, func(exists []bool) error {
+		if !exists[0] || !exists[1] || exists[2] {
+			return fmt.Errorf("unexpected exists %v", exists)
+		}
+		// Both project locks are held during the update
+		for _, key := range []string{"PROJ-A", "PROJ-B"} {
+			if locked, _ := CheckLock(t.Context(), key); !locked {
+				return fmt.Errorf("project %s is not locked", key)
+			}
+		}
+		a.Value, b.Value, c.Value = 2, 3, 4
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("UpdateJSONFilesAtomic() failed: %v", err)
+	}
+
+	for path, want := range map[string]int{pathA: 2, pathB: 3} {
+		var got TestData
+		if err := ReadJSON(t.Context(), path, &got); err != nil {
+			t.Fatalf("Failed to read %s: %v", path, err)
+		}
+		if got.Value != want {
+			t.Errorf("%s value = %d, want %d", path, got.Value, want)
+		}
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Error("UpdateJSONFilesAtomic() should not create missing files")
+	}
+	for _, key := range []string{"PROJ-A", "PROJ-B"} {
+		if locked, _ := CheckLock(t.Context(), key); locked {
+			t.Errorf("Lock of %s should be released", key)
+		}
+		if pending, _, _ := CheckPendingTransaction(t.Context(), key); pending {
+			t.Errorf("Transaction of %s should be committed", key)
+		}
+	}
+
+	// An update error leaves both files untouched
+	err = UpdateJSONFilesAtomic(t.Context(), []string{pathA, pathB}, []interface{}{&a, &b}, func(exists []bool) error {
+		a.Value, b.Value = 10, 10
+		return fmt.Errorf("update function error")
+	})
+	if err == nil {
+		t.Fatal("UpdateJSONFilesAtomic() should fail when update function returns error")
+	}
+	var got TestData
+	if err := ReadJSON(t.Context(), pathA, &got); err != nil || got.Value != 2 {
+		t.Errorf("UpdateJSONFilesAtomic() should not modify files on error, value = %d", got.Value)
+	}
+}
+
+func TestProjectTx(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalUserConfigDir := userConfigDirFunc
+	originalCachedDir := cachedConfigDir
+	defer func() {
+		userConfigDirFunc = originalUserConfigDir
+		cachedConfigDir = originalCachedDir
+	}()
+
+	resetConfigDirCache()
+	userConfigDirFunc = func() (string, error) {
+		return tmpDir, nil
+	}
+
+	type TestData struct {
+		Value int `json:"value"`
+	}
+	created, _ := IssuePath(t.Context(), "PROJ", "PROJ-1")
+	updated, _ := IssuePath(t.Context(), "PROJ", "PROJ-2")
+	removed, _ := IssuePath(t.Context(), "PROJ", "PROJ-3")
+	other, _ := IssuePath(t.Context(), "OTHER", "OTHER-1")
+	for _, path := range []string{updated, removed} {
+		if err := WriteJSONAtomic(t.Context(), path, &TestData{Value: 1}); err != nil {
+			t.Fatalf("Failed to create %s: %v", path, err)
+		}
+	}
+
+	change := func(tx *ProjectTx) {
+		t.Helper()
+		if err := tx.CreateJSON(created, &TestData{Value: 1}); err != nil {
+			t.Fatalf("CreateJSON() failed: %v", err)
+		}
+		if err := tx.CreateJSON(updated, &TestData{Value: 1}); err == nil {
+			t.Error("CreateJSON() should fail for an existing file")
+		}
+		var data TestData
+		if err := tx.UpdateJSON(updated, &data, func(v interface{}) error {
+			v.(*TestData).Value++
+			return nil
+		}); err != nil {
+			t.Fatalf("UpdateJSON() failed: %v", err)
+		}
+		if err := tx.Remove(removed); err != nil {
+			t.Fatalf("Remove() failed: %v", err)
+		}
+		if err := tx.WriteJSON(other, &TestData{}); err == nil {
+			t.Error("WriteJSON() should refuse files of other projects")
+		}
+		// The lock is held and the pending log lists the changed files
+		if locked, _ := CheckLock(t.Context(), "PROJ"); !locked {
+			t.Error("Project should be locked during the transaction")
+		}
+		if _, log, _ := CheckPendingTransaction(t.Context(), "PROJ"); len(log.Metadata["files"].([]interface{})) != 3 {
+			t.Errorf("Pending log files = %v, want the 3 changed files", log.Metadata["files"])
+		}
+	}
+	value := func(path string) int {
+		var data TestData
+		if err := ReadJSON(t.Context(), path, &data); err != nil {
+			return -1
+		}
+		return data.Value
+	}
+
+	// Rollback restores every file
+	tx, err := BeginProjectTx(t.Context(), "PROJ", "test", nil)
+	if err != nil {
+		t.Fatalf("BeginProjectTx() failed: %v", err)
+	}
+	change(tx)
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback() failed: %v", err)
+	}
+	if value(created) != -1 || value(updated) != 1 || value(removed) != 1 {
+		t.Errorf("Rollback() left %d, %d, %d; want the files as before", value(created), value(updated), value(removed))
+	}
+	if locked, _ := CheckLock(t.Context(), "PROJ"); locked {
+		t.Error("Rollback() should release the lock")
+	}
+
+	// Commit keeps the changes; a deferred Rollback then does nothing
+	tx, err = BeginProjectTx(t.Context(), "PROJ", "test", nil)
+	if err != nil {
+		t.Fatalf("BeginProjectTx() failed: %v", err)
+	}
+	change(tx)
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() failed: %v", err)
+	}
+	tx.Rollback()
+	if value(created) != 1 || value(updated) != 2 || value(removed) != -1 {
+		t.Errorf("Commit() left %d, %d, %d; want 1, 2, and removed", value(created), value(updated), value(removed))
+	}
+	if pending, _, _ := CheckPendingTransaction(t.Context(), "PROJ"); pending {
+		t.Error("Commit() should remove the pending log")
+	}
+	if locked, _ := CheckLock(t.Context(), "PROJ"); locked {
+		t.Error("Commit() should release the lock")
+	}
+}
+
 // isAccessDenied checks if an error is an "access denied" error on Windows
 func isAccessDenied(err error) bool {
 	if err == nil {
@@ -679,17 +1010,17 @@ func TestUpdateJSONAtomic_Concurrent(t *testing.T) {
 	}
 
 	projectKey := "TEST-PROJ"
-	projectDir, _ := ProjectDir(projectKey)
+	projectDir, _ := ProjectDir(t.Context(), projectKey)
 	os.MkdirAll(projectDir, 0755)
 
-	indexPath, _ := ProjectIndexPath(projectKey)
+	indexPath, _ := ProjectIndexPath(t.Context(), projectKey)
 
 	// Create initial file
 	type Counter struct {
 		Count int `json:"count"`
 	}
 	initialData := Counter{Count: 0}
-	if err := WriteJSONAtomic(indexPath, &initialData); err != nil {
+	if err := WriteJSONAtomic(t.Context(), indexPath, &initialData); err != nil {
 		t.Fatalf("Failed to create initial file: %v", err)
 	}
 
@@ -713,7 +1044,7 @@ func TestUpdateJSONAtomic_Concurrent(t *testing.T) {
 
 				// Retry logic for Windows file locking
 				for retry <= maxRetries {
-					err = UpdateJSONAtomic(indexPath, &data, func(v interface{}) error {
+					err = UpdateJSONAtomic(t.Context(), indexPath, &data, func(v interface{}) error {
 						c := v.(*Counter)
 						c.Count++
 						return nil
@@ -773,7 +1104,7 @@ func TestUpdateJSONAtomic_Concurrent(t *testing.T) {
 
 	// Verify final count matches successful updates
 	var finalData Counter
-	if err := ReadJSON(indexPath, &finalData); err != nil {
+	if err := ReadJSON(t.Context(), indexPath, &finalData); err != nil {
 		t.Fatalf("Failed to read final file: %v", err)
 	}
 
@@ -798,10 +1129,10 @@ func TestWriteJSONAtomicCreate(t *testing.T) {
 	}
 
 	projectKey := "TEST-PROJ"
-	projectDir, _ := ProjectDir(projectKey)
+	projectDir, _ := ProjectDir(t.Context(), projectKey)
 	os.MkdirAll(projectDir, 0755)
 
-	indexPath, _ := ProjectIndexPath(projectKey)
+	indexPath, _ := ProjectIndexPath(t.Context(), projectKey)
 
 	// Test creating new file
 	testData := map[string]interface{}{
@@ -809,7 +1140,7 @@ func TestWriteJSONAtomicCreate(t *testing.T) {
 		"title": "Test Issue",
 	}
 
-	err := WriteJSONAtomicCreate(indexPath, testData)
+	err := WriteJSONAtomicCreate(t.Context(), indexPath, testData)
 	if err != nil {
 		t.Fatalf("WriteJSONAtomicCreate() failed: %v", err)
 	}
@@ -820,7 +1151,7 @@ func TestWriteJSONAtomicCreate(t *testing.T) {
 	}
 
 	// Test that creating again fails
-	err = WriteJSONAtomicCreate(indexPath, testData)
+	err = WriteJSONAtomicCreate(t.Context(), indexPath, testData)
 	if err == nil {
 		t.Fatal("WriteJSONAtomicCreate() should fail when file already exists")
 	}
@@ -846,10 +1177,10 @@ func TestWriteJSONAtomicCreate_Concurrent(t *testing.T) {
 	}
 
 	projectKey := "TEST-PROJ"
-	projectDir, _ := ProjectDir(projectKey)
+	projectDir, _ := ProjectDir(t.Context(), projectKey)
 	os.MkdirAll(projectDir, 0755)
 
-	indexPath, _ := ProjectIndexPath(projectKey)
+	indexPath, _ := ProjectIndexPath(t.Context(), projectKey)
 
 	// Try to create the same file concurrently
 	numGoroutines := 10
@@ -863,7 +1194,7 @@ func TestWriteJSONAtomicCreate_Concurrent(t *testing.T) {
 				"id":    "T-123",
 				"title": "Test Issue",
 			}
-			err := WriteJSONAtomicCreate(indexPath, testData)
+			err := WriteJSONAtomicCreate(t.Context(), indexPath, testData)
 			if err == nil {
 				atomic.AddInt64(&successCount, 1)
 			} else {
@@ -917,13 +1248,13 @@ func TestWriteJSONAtomic(t *testing.T) {
 	}
 
 	// Ensure project directory exists (WriteJSONAtomic will create it via BeginTransaction)
-	projectDir, _ := ProjectDir(projectKey)
+	projectDir, _ := ProjectDir(t.Context(), projectKey)
 	os.MkdirAll(projectDir, 0755)
 
 	// Get the path for the project index
-	indexPath, _ := ProjectIndexPath(projectKey)
+	indexPath, _ := ProjectIndexPath(t.Context(), projectKey)
 
-	err := WriteJSONAtomic(indexPath, testData)
+	err := WriteJSONAtomic(t.Context(), indexPath, testData)
 	if err != nil {
 		t.Fatalf("WriteJSONAtomic() failed: %v", err)
 	}
@@ -935,7 +1266,7 @@ func TestWriteJSONAtomic(t *testing.T) {
 
 	// Verify content
 	var readData map[string]interface{}
-	err = ReadJSON(indexPath, &readData)
+	err = ReadJSON(t.Context(), indexPath, &readData)
 	if err != nil {
 		t.Fatalf("Failed to read JSON: %v", err)
 	}
@@ -972,7 +1303,7 @@ func TestReadJSON(t *testing.T) {
 
 	// Read JSON
 	var readData map[string]interface{}
-	err := ReadJSON(testFile, &readData)
+	err := ReadJSON(t.Context(), testFile, &readData)
 	if err != nil {
 		t.Fatalf("ReadJSON() failed: %v", err)
 	}
@@ -992,7 +1323,7 @@ func TestReadJSONNotFound(t *testing.T) {
 	testFile := filepath.Join(tmpDir, "nonexistent.json")
 
 	var readData map[string]interface{}
-	err := ReadJSON(testFile, &readData)
+	err := ReadJSON(t.Context(), testFile, &readData)
 	if err == nil {
 		t.Fatal("ReadJSON() should fail for nonexistent file")
 	}
@@ -1002,6 +1333,255 @@ func TestReadJSONNotFound(t *testing.T) {
 	}
 }
 
+// TestFileCache tests that unchanged files are read from disk once
+func TestFileCache(t *testing.T) {
+	reads := 0
+	cache := newFileCache(func(path string) ([]byte, error) {
+		reads++
+		return os.ReadFile(path)
+	})
+
+	testFile := filepath.Join(t.TempDir(), "index.json")
+	old := time.Now().Add(-time.Hour)
+	write := func(data string, mtime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(testFile, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if err := os.Chtimes(testFile, mtime, mtime); err != nil {
+			t.Fatalf("Failed to set mtime: %v", err)
+		}
+	}
+	read := func() int {
+		t.Helper()
+		data, err := cache.read(testFile)
+		if err != nil {
+			t.Fatalf("read() failed: %v", err)
+		}
+		var v struct{ Value int }
+		if err := json.Unmarshal(data, &v); err != nil {
+			t.Fatalf("Failed to unmarshal %s: %v", data, err)
+		}
+		return v.Value
+	}
+
+	write(`{"Value": 1}`, old)
+	for i := 0; i < 3; i++ {
+		if got := read(); got != 1 {
+			t.Errorf("read() = %d, want 1", got)
+		}
+	}
+	if reads != 1 {
+		t.Errorf("Read an unchanged file %d times, want once", reads)
+	}
+
+	// Changes in place are noticed by mtime, writes by the rename
+	write(`{"Value": 2}`, old.Add(time.Second))
+	if got := read(); got != 2 {
+		t.Errorf("read() = %d after an in-place change, want 2", got)
+	}
+	if err := WriteAtomic(t.Context(), testFile, []byte(`{"Value": 3}`)); err != nil {
+		t.Fatalf("WriteAtomic() failed: %v", err)
+	}
+	if got := read(); got != 3 {
+		t.Errorf("read() = %d after WriteAtomic, want 3", got)
+	}
+
+	// Recently changed files are not cached, their mtime may not change again
+	reads = 0
+	write(`{"Value": 4}`, time.Now())
+	read()
+	read()
+	if reads != 2 {
+		t.Errorf("Read a recently changed file %d times, want every time", reads)
+	}
+
+	// ReadJSON uses the Cache of the Env, and reads from disk without one
+	reads = 0
+	write(`{"Value": 5}`, old)
+	var v struct{ Value int }
+	ctx := WithEnv(t.Context(), Env{Cache: &Cache{files: cache}})
+	for _, ctx := range []context.Context{ctx, ctx, t.Context()} {
+		if err := ReadJSON(ctx, testFile, &v); err != nil || v.Value != 5 {
+			t.Fatalf("ReadJSON() = %d, %v; want 5", v.Value, err)
+		}
+	}
+	if reads != 1 {
+		t.Errorf("Read through the Cache %d times, want once", reads)
+	}
+}
+
+// TestReadAllIssues tests reading all issue files of a project
+func TestReadAllIssues(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalUserConfigDir := userConfigDirFunc
+	defer func() {
+		userConfigDirFunc = originalUserConfigDir
+		resetConfigDirCache()
+	}()
+
+	resetConfigDirCache()
+	userConfigDirFunc = func() (string, error) {
+		return tmpDir, nil
+	}
+
+	if files, err := ReadAllIssues(t.Context(), "PROJ"); err != nil || len(files) != 0 {
+		t.Fatalf("ReadAllIssues() = %v, %v; want no issues without an issues directory", files, err)
+	}
+
+	const n = 50
+	for i := n; i >= 1; i-- {
+		id := fmt.Sprintf("PROJ-%03d", i)
+		path, _ := IssuePath(t.Context(), "PROJ", id)
+		if err := WriteAtomic(t.Context(), path, []byte(fmt.Sprintf(`{"id": %q, "title": "Issue %d"}`, id, i))); err != nil {
+			t.Fatalf("Failed to write %s: %v", id, err)
+		}
+	}
+	broken, _ := IssuePath(t.Context(), "PROJ", "PROJ-999")
+	if err := WriteAtomic(t.Context(), broken, []byte(`{"id": `)); err != nil {
+		t.Fatalf("Failed to write broken issue: %v", err)
+	}
+	issuesDir, _ := IssuesDir(t.Context(), "PROJ")
+	os.WriteFile(filepath.Join(issuesDir, "PROJ-1.json.tmp"), []byte(`{}`), 0644)
+
+	files, err := ReadAllIssues(t.Context(), "PROJ")
+	if err != nil {
+		t.Fatalf("ReadAllIssues() failed: %v", err)
+	}
+	if len(files) != n+1 {
+		t.Fatalf("ReadAllIssues() returned %d files, want %d", len(files), n+1)
+	}
+	for i, file := range files[:n] {
+		want := fmt.Sprintf("PROJ-%03d", i+1)
+		if file.Err != nil || file.Issue == nil || file.Issue.ID != want || file.ID != want {
+			t.Errorf("files[%d] = %+v, want issue %s", i, file, want)
+		}
+	}
+	if last := files[n]; last.ID != "PROJ-999" || last.Err == nil || last.Issue != nil {
+		t.Errorf("Expected the broken file to be returned with an error, got %+v", last)
+	}
+
+	// ReadIssues keeps the order of the IDs and reports missing files
+	files = ReadIssues(t.Context(), "PROJ", []string{"PROJ-002", "PROJ-404", "PROJ-001"})
+	if files[0].Issue == nil || files[0].Issue.ID != "PROJ-002" || files[1].Err == nil || files[2].Issue == nil || files[2].Issue.ID != "PROJ-001" {
+		t.Errorf("ReadIssues() = %+v, want PROJ-002, an error, and PROJ-001", files)
+	}
+}
+
+// TestShardedIndex tests that a sharded index is written in shards and read whole
+func TestShardedIndex(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalUserConfigDir := userConfigDirFunc
+	defer func() {
+		userConfigDirFunc = originalUserConfigDir
+		resetConfigDirCache()
+	}()
+
+	resetConfigDirCache()
+	userConfigDirFunc = func() (string, error) {
+		return tmpDir, nil
+	}
+
+	// Shards are read through the shard cache, as in a command
+	ctx := WithEnv(t.Context(), Env{Cache: NewCache()})
+	projectKey := "TEST"
+	indexPath, _ := ProjectIndexPath(ctx, projectKey)
+	index := models.ProjectIndex{ProjectKey: projectKey, ShardSize: 2}
+	for _, id := range []string{"TEST-1", "TEST-2", "TEST-3", "TEST-5"} {
+		index.Issues = append(index.Issues, models.IndexEntry{ID: id, Title: id})
+	}
+	if err := WriteIndex(ctx, indexPath, &index); err != nil {
+		t.Fatalf("WriteIndex() failed: %v", err)
+	}
+	first, _ := os.ReadFile(indexPath)
+	header, err := readIndexHeader(indexPath)
+	if err != nil || len(header.Shards) != 3 || len(header.Issues) != 0 {
+		t.Fatalf("project.json = %s, want 3 shards and no entries", first)
+	}
+
+	var read models.ProjectIndex
+	if err := ReadIndex(ctx, indexPath, &read); err != nil {
+		t.Fatalf("ReadIndex() failed: %v", err)
+	}
+	if len(read.Issues) != 4 || read.Shards != nil || read.ShardSize != 2 {
+		t.Errorf("ReadIndex() = %+v, want all 4 entries", read)
+	}
+
+	// Only the shard of a changed entry is replaced
+	read.Issues[0].Title = "Changed"
+	if err := WriteIndex(ctx, indexPath, &read); err != nil {
+		t.Fatalf("WriteIndex() failed: %v", err)
+	}
+	changed, _ := readIndexHeader(indexPath)
+	if changed.Shards[0] == header.Shards[0] || !slices.Equal(changed.Shards[1:], header.Shards[1:]) {
+		t.Errorf("Shards went from %v to %v, want only the first replaced", header.Shards, changed.Shards)
+	}
+
+	// Restoring earlier content of project.json restores its entries
+	if err := RestoreAtomic(ctx, indexPath, first); err != nil {
+		t.Fatalf("RestoreAtomic() failed: %v", err)
+	}
+	var restored models.ProjectIndex
+	if err := ReadIndex(ctx, indexPath, &restored); err != nil {
+		t.Fatalf("ReadIndex() failed: %v", err)
+	}
+	if len(restored.Issues) != 4 || restored.Issues[0].Title != "TEST-1" {
+		t.Errorf("ReadIndex() after restore = %+v, want the original entries", restored.Issues)
+	}
+
+	if size, err := IndexSize(ctx, projectKey); err != nil || size <= int64(len(first)) {
+		t.Errorf("IndexSize() = %d, %v, want project.json and its shards", size, err)
+	}
+
+	// A missing shard is reported
+	shardsDir, _ := IndexShardsDir(ctx, projectKey)
+	os.Remove(filepath.Join(shardsDir, header.Shards[0]))
+	if err := ReadIndex(ctx, indexPath, &restored); err == nil {
+		t.Error("ReadIndex() succeeded with a missing shard")
+	}
+}
+
+// TestShardedIndex_Sequenceless tests that entries of IDs without a sequence
+// number are spread over shards of their own
+func TestShardedIndex_Sequenceless(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalUserConfigDir := userConfigDirFunc
+	defer func() {
+		userConfigDirFunc = originalUserConfigDir
+		resetConfigDirCache()
+	}()
+
+	resetConfigDirCache()
+	userConfigDirFunc = func() (string, error) {
+		return tmpDir, nil
+	}
+
+	indexPath, _ := ProjectIndexPath(t.Context(), "TEST")
+	index := models.ProjectIndex{ProjectKey: "TEST", ShardSize: 1000}
+	index.Issues = append(index.Issues, models.IndexEntry{ID: "TEST-1"})
+	for i := 0; i < 64; i++ {
+		id := fmt.Sprintf("TEST-01J0ZQ5B0M8N1XK3V7T2R9%04d", i)
+		index.Issues = append(index.Issues, models.IndexEntry{ID: id})
+	}
+	if err := WriteIndex(t.Context(), indexPath, &index); err != nil {
+		t.Fatalf("WriteIndex() failed: %v", err)
+	}
+	header, _ := readIndexHeader(indexPath)
+	if len(header.Shards) < 2 || !strings.HasPrefix(header.Shards[0], "0-") {
+		t.Fatalf("Shards = %v, want the range of TEST-1 first", header.Shards)
+	}
+	for _, name := range header.Shards[1:] {
+		if !strings.HasPrefix(name, "u") || !isShardName(name) {
+			t.Errorf("Shard %s of sequence-less IDs, want a u shard", name)
+		}
+	}
+
+	var read models.ProjectIndex
+	if err := ReadIndex(t.Context(), indexPath, &read); err != nil || len(read.Issues) != 65 {
+		t.Errorf("ReadIndex() = %d entries, %v, want 65", len(read.Issues), err)
+	}
+}
+
 // TestEnsureDir tests directory creation
 func TestEnsureDir(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -1037,9 +1617,9 @@ func TestCrossPlatformPaths(t *testing.T) {
 	}
 
 	// Test various path operations
-	projectDir, _ := ProjectDir("TEST-PROJ")
-	indexPath, _ := ProjectIndexPath("TEST-PROJ")
-	issuePath, _ := IssuePath("TEST-PROJ", "T-123")
+	projectDir, _ := ProjectDir(t.Context(), "TEST-PROJ")
+	indexPath, _ := ProjectIndexPath(t.Context(), "TEST-PROJ")
+	issuePath, _ := IssuePath(t.Context(), "TEST-PROJ", "T-123")
 
 	// Verify paths use correct separators for current OS
 	separator := string(filepath.Separator)
@@ -1099,7 +1679,7 @@ func TestPathTraversalProtection(t *testing.T) {
 	}
 
 	for _, maliciousID := range maliciousIDs {
-		_, err := IssuePath("TEST-PROJ", maliciousID)
+		_, err := IssuePath(t.Context(), "TEST-PROJ", maliciousID)
 		if err == nil {
 			t.Errorf("IssuePath should reject path traversal attempt: %s", maliciousID)
 		}
@@ -1107,14 +1687,14 @@ func TestPathTraversalProtection(t *testing.T) {
 
 	// Test EpicPath with path traversal attempts
 	for _, maliciousID := range maliciousIDs {
-		_, err := EpicPath("TEST-PROJ", maliciousID)
+		_, err := EpicPath(t.Context(), "TEST-PROJ", maliciousID)
 		if err == nil {
 			t.Errorf("EpicPath should reject path traversal attempt: %s", maliciousID)
 		}
 	}
 
 	// Test that valid IDs still work
-	validPath, err := IssuePath("TEST-PROJ", "T-123")
+	validPath, err := IssuePath(t.Context(), "TEST-PROJ", "T-123")
 	if err != nil {
 		t.Fatalf("IssuePath should accept valid ID: %v", err)
 	}
@@ -1122,3 +1702,212 @@ func TestPathTraversalProtection(t *testing.T) {
 		t.Errorf("IssuePath should return valid path for valid ID, got: %s", validPath)
 	}
 }
+
+// TestEnvClock tests overriding the clock used for timestamps
+func TestEnvClock(t *testing.T) {
+	fixed := time.Date(2024, 6, 5, 15, 0, 0, 0, time.FixedZone("UTC+3", 3*60*60))
+	ctx := WithEnv(t.Context(), Env{Clock: FixedClock(fixed)})
+
+	if !Now(ctx).Equal(fixed) {
+		t.Errorf("Now() = %v, want %v", Now(ctx), fixed)
+	}
+	if got := Timestamp(ctx); got != "2024-06-05T12:00:00Z" {
+		t.Errorf("Timestamp() = %q, want 2024-06-05T12:00:00Z", got)
+	}
+
+	if time.Since(Now(t.Context())) > time.Minute {
+		t.Error("Now() should use the system clock without an Env")
+	}
+}
+
+// TestEnvSequence tests overriding the next issue sequence number
+func TestEnvSequence(t *testing.T) {
+	if got := NextSequence(t.Context(), "CORE", 7); got != 7 {
+		t.Errorf("NextSequence() = %d, want computed 7", got)
+	}
+
+	ctx := WithEnv(t.Context(), Env{Sequence: CounterSequence(100)})
+	for _, want := range []int{100, 101} {
+		if got := NextSequence(ctx, "CORE", 7); got != want {
+			t.Errorf("NextSequence(CORE) = %d, want %d", got, want)
+		}
+	}
+	if got := NextSequence(ctx, "WEB", 7); got != 100 {
+		t.Errorf("NextSequence(WEB) = %d, want 100", got)
+	}
+}
+
+// TestAllocateSequence tests that concurrent allocations get distinct numbers
+func TestAllocateSequence(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalUserConfigDir := userConfigDirFunc
+	defer func() {
+		userConfigDirFunc = originalUserConfigDir
+		resetConfigDirCache()
+	}()
+
+	resetConfigDirCache()
+	userConfigDirFunc = func() (string, error) {
+		return tmpDir, nil
+	}
+
+	projectKey := "TEST-PROJ"
+	projectDir, _ := ProjectDir(t.Context(), projectKey)
+	os.MkdirAll(projectDir, 0755)
+
+	// Every allocation computes the same number from an unchanged index
+	const n = 10
+	results := make(chan int, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			seq, err := AllocateSequence(t.Context(), projectKey, 5)
+			if err != nil {
+				t.Errorf("AllocateSequence() failed: %v", err)
+				return
+			}
+			results <- seq
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	seen := map[int]bool{}
+	for seq := range results {
+		if seen[seq] || seq < 5 || seq >= 5+n {
+			t.Errorf("AllocateSequence() handed out %d more than once or out of range", seq)
+		}
+		seen[seq] = true
+	}
+
+	// A higher number from the index wins over the counter
+	if seq, _ := AllocateSequence(t.Context(), projectKey, 40); seq != 40 {
+		t.Errorf("AllocateSequence() = %d, want 40 from the index", seq)
+	}
+	if seq, _ := AllocateSequence(t.Context(), projectKey, 1); seq != 41 {
+		t.Errorf("AllocateSequence() = %d, want 41 from the counter", seq)
+	}
+
+	ctx := WithEnv(t.Context(), Env{Sequence: CounterSequence(100)})
+	if seq, _ := AllocateSequence(ctx, projectKey, 1); seq != 100 {
+		t.Errorf("AllocateSequence() = %d, want 100 from the Env", seq)
+	}
+}
+
+// TestLockStats tests that lock acquisitions and timeouts are recorded
+func TestLockStats(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalUserConfigDir := userConfigDirFunc
+	defer func() {
+		userConfigDirFunc = originalUserConfigDir
+		resetConfigDirCache()
+	}()
+
+	resetConfigDirCache()
+	userConfigDirFunc = func() (string, error) {
+		return tmpDir, nil
+	}
+	ctx := WithEnv(t.Context(), Env{LockTimeout: 200 * time.Millisecond})
+
+	projectKey := "TEST-PROJ"
+
+	// No stats yet
+	samples, err := ReadLockStats(t.Context(), projectKey)
+	if err != nil {
+		t.Fatalf("ReadLockStats() failed: %v", err)
+	}
+	if len(samples) != 0 {
+		t.Fatalf("Expected no samples, got %d", len(samples))
+	}
+
+	// Uncontended acquisitions are not recorded
+	cleanup, err := AcquireLock(ctx, projectKey)
+	if err != nil {
+		t.Fatalf("AcquireLock() failed: %v", err)
+	}
+	if samples, _ = ReadLockStats(t.Context(), projectKey); len(samples) != 0 {
+		t.Fatalf("Expected no samples for an uncontended acquisition, got %+v", samples)
+	}
+
+	// Timed out acquisition while the lock is held
+	if _, err := AcquireLock(ctx, projectKey); err == nil {
+		t.Fatal("AcquireLock() should time out while the lock is held")
+	}
+
+	// Acquisition that waits for the lock to be released
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cleanup()
+	}()
+	cleanup, err = AcquireLock(ctx, projectKey)
+	if err != nil {
+		t.Fatalf("AcquireLock() failed: %v", err)
+	}
+	cleanup()
+
+	samples, err = ReadLockStats(t.Context(), projectKey)
+	if err != nil {
+		t.Fatalf("ReadLockStats() failed: %v", err)
+	}
+	if len(samples) != 2 {
+		t.Fatalf("Expected 2 samples, got %d", len(samples))
+	}
+	if !samples[0].Contended || !samples[0].TimedOut || samples[0].WaitMs < 200 {
+		t.Errorf("First sample should be a timeout of at least 200ms, got %+v", samples[0])
+	}
+	if !samples[1].Contended || samples[1].TimedOut || samples[1].WaitMs <= 0 {
+		t.Errorf("Second sample should be a wait, got %+v", samples[1])
+	}
+
+	// Partial lines are skipped
+	path, _ := LockStatsPath(t.Context(), projectKey)
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	f.WriteString(`{"at":"2024-`)
+	f.Close()
+	if samples, err = ReadLockStats(t.Context(), projectKey); err != nil || len(samples) != 2 {
+		t.Errorf("Expected 2 samples with a partial line, got %d (%v)", len(samples), err)
+	}
+
+	if err := ResetLockStats(t.Context(), projectKey); err != nil {
+		t.Fatalf("ResetLockStats() failed: %v", err)
+	}
+	if samples, _ = ReadLockStats(t.Context(), projectKey); len(samples) != 0 {
+		t.Errorf("Expected no samples after reset, got %d", len(samples))
+	}
+}
+
+// TestLockStatsTrim tests that the stats file is trimmed to the most recent samples
+func TestLockStatsTrim(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalUserConfigDir := userConfigDirFunc
+	defer func() {
+		userConfigDirFunc = originalUserConfigDir
+		resetConfigDirCache()
+	}()
+
+	resetConfigDirCache()
+	userConfigDirFunc = func() (string, error) {
+		return tmpDir, nil
+	}
+
+	projectKey := "TEST-PROJ"
+	projectDir, _ := ProjectDir(t.Context(), projectKey)
+	os.MkdirAll(projectDir, 0755)
+
+	for i := 0; i < 3*lockStatsKeep; i++ {
+		recordLockSample(t.Context(), projectKey, LockSample{At: "2024-01-01T00:00:00Z", WaitMs: int64(i), PID: 1}, true)
+	}
+
+	samples, err := ReadLockStats(t.Context(), projectKey)
+	if err != nil {
+		t.Fatalf("ReadLockStats() failed: %v", err)
+	}
+	if len(samples) >= 3*lockStatsKeep {
+		t.Errorf("Expected the stats file to be trimmed, got %d samples", len(samples))
+	}
+	if last := samples[len(samples)-1]; last.WaitMs != int64(3*lockStatsKeep-1) {
+		t.Errorf("Expected the most recent sample to be kept, got %+v", last)
+	}
+}
@@ -1,11 +1,11 @@
 package storage
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
-	"time"
 )
 
 // TransactionLog represents a transaction log entry.
@@ -16,8 +16,8 @@ type TransactionLog struct {
 }
 
 // BeginTransaction creates a transaction log entry before any file modification.
-func BeginTransaction(projectKey, operation string, metadata map[string]interface{}) error {
-	projectDir, err := ProjectDir(projectKey)
+func BeginTransaction(ctx context.Context, projectKey, operation string, metadata map[string]interface{}) error {
+	projectDir, err := ProjectDir(ctx, projectKey)
 	if err != nil {
 		return err
 	}
@@ -31,7 +31,7 @@ func BeginTransaction(projectKey, operation string, metadata map[string]interfac
 
 	transaction := TransactionLog{
 		Operation: operation,
-		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Timestamp: Timestamp(ctx),
 		Metadata:  metadata,
 	}
 
@@ -54,8 +54,8 @@ func BeginTransaction(projectKey, operation string, metadata map[string]interfac
 }
 
 // CommitTransaction removes the transaction log after successful operation.
-func CommitTransaction(projectKey string) error {
-	projectDir, err := ProjectDir(projectKey)
+func CommitTransaction(ctx context.Context, projectKey string) error {
+	projectDir, err := ProjectDir(ctx, projectKey)
 	if err != nil {
 		return err
 	}
@@ -73,14 +73,14 @@ func CommitTransaction(projectKey string) error {
 
 // RollbackTransaction removes the transaction log when an operation fails.
 // This is the same as CommitTransaction but semantically different.
-func RollbackTransaction(projectKey string) error {
-	return CommitTransaction(projectKey)
+func RollbackTransaction(ctx context.Context, projectKey string) error {
+	return CommitTransaction(ctx, projectKey)
 }
 
 // CheckPendingTransaction checks if there's a pending transaction and returns it.
 // Returns true if a pending transaction exists, along with the full transaction log.
-func CheckPendingTransaction(projectKey string) (bool, TransactionLog, error) {
-	projectDir, err := ProjectDir(projectKey)
+func CheckPendingTransaction(ctx context.Context, projectKey string) (bool, TransactionLog, error) {
+	projectDir, err := ProjectDir(ctx, projectKey)
 	if err != nil {
 		return false, TransactionLog{}, err
 	}
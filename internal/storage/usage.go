@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// usageMaxBytes is the size at which the usage log is trimmed.
+	usageMaxBytes = 1024 * 1024
+	// usageKeep is the number of most recent entries kept when trimming.
+	usageKeep = 10000
+)
+
+// UsageEntry records one command run. It holds no arguments, flags, or
+// project data: only which command ran, when, and for how long.
+type UsageEntry struct {
+	At         string `json:"at"`               // RFC3339 timestamp of the start
+	Command    string `json:"command"`          // Command path without "buyruk", e.g. "issue update"
+	DurationMs int64  `json:"duration_ms"`      // Wall time of the command
+	Closed     int    `json:"closed,omitempty"` // Issues the command moved to a done status
+}
+
+// UsagePath returns the path of the local usage log, shared by all projects.
+// The file is JSON Lines, one UsageEntry per command.
+func UsagePath() (string, error) {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "usage.jsonl"), nil
+}
+
+// RecordUsage appends an entry to the usage log. Entries are appended with
+// O_APPEND so concurrent commands do not need a lock; trimming rewrites the
+// file atomically. Recording is best effort: it must never fail a command.
+func RecordUsage(ctx context.Context, entry UsageEntry) {
+	path, err := UsagePath()
+	if err != nil {
+		return
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := EnsureDir(path); err != nil {
+		return
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return
+	}
+	_, writeErr := f.Write(append(line, '\n'))
+	info, statErr := f.Stat()
+	f.Close()
+	if writeErr != nil || statErr != nil {
+		return
+	}
+
+	if info.Size() > usageMaxBytes {
+		trimUsage(ctx, path)
+	}
+}
+
+// trimUsage keeps only the most recent entries in the usage log.
+func trimUsage(ctx context.Context, path string) {
+	entries, err := readUsageEntries(path)
+	if err != nil || len(entries) <= usageKeep {
+		return
+	}
+
+	var data []byte
+	for _, entry := range entries[len(entries)-usageKeep:] {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return
+		}
+		data = append(data, line...)
+		data = append(data, '\n')
+	}
+	WriteAtomic(ctx, path, data)
+}
+
+// ReadUsage returns the recorded usage entries, oldest first. Without a usage
+// log it returns no entries.
+func ReadUsage() ([]UsageEntry, error) {
+	path, err := UsagePath()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := readUsageEntries(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("storage: failed to read usage log: %w", err)
+	}
+	return entries, nil
+}
+
+// ResetUsage removes the usage log.
+func ResetUsage() error {
+	path, err := UsagePath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("storage: failed to remove usage log: %w", err)
+	}
+	return nil
+}
+
+// readUsageEntries reads a usage log, skipping lines that do not parse
+// (for example a partial line from an interrupted append).
+func readUsageEntries(path string) ([]UsageEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []UsageEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry UsageEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
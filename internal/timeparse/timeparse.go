@@ -0,0 +1,187 @@
+package timeparse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayouts are the absolute date formats accepted by ParseTime, tried in order.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// durationUnits maps the extended unit suffixes to their length.
+// Units not listed here (h, m, s, ms...) are handled by time.ParseDuration.
+var durationUnits = map[string]time.Duration{
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// workDurationUnits maps the unit suffixes of effort estimates to working time.
+var workDurationUnits = map[string]time.Duration{
+	"d": 8 * time.Hour,
+	"w": 5 * 8 * time.Hour,
+}
+
+// ParseTime parses a human-friendly date/time value relative to now, in loc.
+//
+// Accepted values:
+//
+//	2024-06-01, 2024-06-01T09:00, 2024-06-01 09:00, RFC3339
+//	now, today, tomorrow, yesterday
+//	eod (end of today), eow (end of this week, Sunday)
+//	monday..sunday, next monday..next sunday (next occurrence after today)
+//	3d, +2w, 4h (from now), -3d, 3d ago (before now)
+//
+// Date-only values resolve to the start of that day in loc.
+func ParseTime(value string, now time.Time, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	now = now.In(loc)
+	v := strings.ToLower(strings.TrimSpace(value))
+	if v == "" {
+		return time.Time{}, fmt.Errorf("timeparse: empty date")
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	today := startOfDay(now)
+	switch v {
+	case "now":
+		return now, nil
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "eod":
+		return endOfDay(today), nil
+	case "eow":
+		daysUntilSunday := (7 - int(today.Weekday())) % 7
+		return endOfDay(today.AddDate(0, 0, daysUntilSunday)), nil
+	}
+
+	if weekday, ok := parseWeekday(strings.TrimPrefix(v, "next ")); ok {
+		days := (int(weekday) - int(today.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+		return today.AddDate(0, 0, days), nil
+	}
+
+	// Relative offsets: "3d", "+3d", "-3d", "3d ago"
+	sign := time.Duration(1)
+	rel := v
+	if strings.HasSuffix(rel, " ago") {
+		sign = -1
+		rel = strings.TrimSpace(strings.TrimSuffix(rel, " ago"))
+	}
+	if strings.HasPrefix(rel, "-") {
+		sign = -sign
+		rel = rel[1:]
+	} else {
+		rel = strings.TrimPrefix(rel, "+")
+	}
+	if d, err := ParseDuration(rel); err == nil {
+		return now.Add(sign * d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("timeparse: invalid date %q (use YYYY-MM-DD, today, tomorrow, eod, eow, a weekday like \"next friday\", or an offset like 3d, 2w, \"3d ago\")", value)
+}
+
+// ParseDuration parses a duration, extending time.ParseDuration with days (d)
+// and weeks (w). Compound values such as "1w2d" or "2d12h" are supported.
+func ParseDuration(value string) (time.Duration, error) {
+	return parseDuration(value, durationUnits)
+}
+
+// ParseWorkDuration parses an effort estimate such as "4h", "2d", or "1w2d",
+// where a day is 8 working hours and a week is 5 working days.
+func ParseWorkDuration(value string) (time.Duration, error) {
+	return parseDuration(value, workDurationUnits)
+}
+
+// parseDuration parses a compound duration with the given extended units.
+func parseDuration(value string, units map[string]time.Duration) (time.Duration, error) {
+	v := strings.ToLower(strings.TrimSpace(value))
+	if v == "" {
+		return 0, fmt.Errorf("timeparse: empty duration")
+	}
+
+	var total time.Duration
+	rest := v
+	for rest != "" {
+		// Read the numeric part
+		i := 0
+		for i < len(rest) && (rest[i] >= '0' && rest[i] <= '9' || rest[i] == '.') {
+			i++
+		}
+		if i == 0 {
+			return 0, invalidDuration(value)
+		}
+		number := rest[:i]
+		rest = rest[i:]
+
+		// Read the unit part
+		j := 0
+		for j < len(rest) && (rest[j] < '0' || rest[j] > '9') && rest[j] != '.' {
+			j++
+		}
+		unit := rest[:j]
+		rest = rest[j:]
+
+		if length, ok := units[unit]; ok {
+			n, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return 0, invalidDuration(value)
+			}
+			total += time.Duration(n * float64(length))
+			continue
+		}
+
+		d, err := time.ParseDuration(number + unit)
+		if err != nil {
+			return 0, invalidDuration(value)
+		}
+		total += d
+	}
+
+	return total, nil
+}
+
+// invalidDuration builds the shared error for unparsable durations.
+func invalidDuration(value string) error {
+	return fmt.Errorf("timeparse: invalid duration %q (use a number with a unit: m, h, d, w, e.g. 30m, 3d, 2w)", value)
+}
+
+// parseWeekday parses a full or three-letter weekday name.
+func parseWeekday(value string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if value == name || value == name[:3] {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// startOfDay returns midnight of t's day in t's location.
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// endOfDay returns the last second of t's day in t's location.
+func endOfDay(t time.Time) time.Time {
+	return startOfDay(t).Add(24*time.Hour - time.Second)
+}
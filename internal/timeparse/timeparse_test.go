@@ -0,0 +1,144 @@
+package timeparse
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// now is a fixed reference time: Wednesday 2024-06-05 14:30 UTC
+var now = time.Date(2024, 6, 5, 14, 30, 0, 0, time.UTC)
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2024-06-01", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"2024-06-01T09:15", time.Date(2024, 6, 1, 9, 15, 0, 0, time.UTC)},
+		{"2024-06-01 09:15", time.Date(2024, 6, 1, 9, 15, 0, 0, time.UTC)},
+		{"2024-06-01T09:15:00Z", time.Date(2024, 6, 1, 9, 15, 0, 0, time.UTC)},
+		{"now", now},
+		{"today", time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC)},
+		{"Tomorrow", time.Date(2024, 6, 6, 0, 0, 0, 0, time.UTC)},
+		{"yesterday", time.Date(2024, 6, 4, 0, 0, 0, 0, time.UTC)},
+		{"eod", time.Date(2024, 6, 5, 23, 59, 59, 0, time.UTC)},
+		{"eow", time.Date(2024, 6, 9, 23, 59, 59, 0, time.UTC)},
+		{"friday", time.Date(2024, 6, 7, 0, 0, 0, 0, time.UTC)},
+		{"next friday", time.Date(2024, 6, 7, 0, 0, 0, 0, time.UTC)},
+		{"next wed", time.Date(2024, 6, 12, 0, 0, 0, 0, time.UTC)},
+		{"3d", now.Add(72 * time.Hour)},
+		{"+2w", now.Add(14 * 24 * time.Hour)},
+		{"4h", now.Add(4 * time.Hour)},
+		{"-3d", now.Add(-72 * time.Hour)},
+		{"3d ago", now.Add(-72 * time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseTime(tt.value, now, time.UTC)
+			if err != nil {
+				t.Fatalf("ParseTime(%q) failed: %v", tt.value, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseTime(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTime_Location(t *testing.T) {
+	loc := time.FixedZone("UTC+3", 3*60*60)
+
+	got, err := ParseTime("2024-06-01", now, loc)
+	if err != nil {
+		t.Fatalf("ParseTime() failed: %v", err)
+	}
+	want := time.Date(2024, 5, 31, 21, 0, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("ParseTime() = %v, want %v", got.UTC(), want)
+	}
+
+	// "today" follows the calendar day in loc, not in UTC
+	late := time.Date(2024, 6, 5, 22, 0, 0, 0, time.UTC) // 01:00 on June 6 in loc
+	got, err = ParseTime("today", late, loc)
+	if err != nil {
+		t.Fatalf("ParseTime() failed: %v", err)
+	}
+	if got.Day() != 6 {
+		t.Errorf("ParseTime(today) day = %d, want 6", got.Day())
+	}
+}
+
+func TestParseTime_Invalid(t *testing.T) {
+	for _, value := range []string{"", "someday", "2024-13-01", "next", "3x"} {
+		_, err := ParseTime(value, now, time.UTC)
+		if err == nil {
+			t.Errorf("ParseTime(%q) should fail", value)
+			continue
+		}
+		if !strings.HasPrefix(err.Error(), "timeparse:") {
+			t.Errorf("ParseTime(%q) error = %q, want timeparse prefix", value, err)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"30m", 30 * time.Minute},
+		{"12h", 12 * time.Hour},
+		{"3d", 72 * time.Hour},
+		{"2w", 14 * 24 * time.Hour},
+		{"1w2d", 9 * 24 * time.Hour},
+		{"2d12h", 60 * time.Hour},
+		{"1.5d", 36 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseDuration(tt.value)
+			if err != nil {
+				t.Fatalf("ParseDuration(%q) failed: %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDuration(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDuration_Invalid(t *testing.T) {
+	for _, value := range []string{"", "d", "3", "3y", "abc"} {
+		if _, err := ParseDuration(value); err == nil {
+			t.Errorf("ParseDuration(%q) should fail", value)
+		}
+	}
+}
+
+func TestParseWorkDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"4h", 4 * time.Hour},
+		{"2d", 16 * time.Hour},
+		{"1w", 40 * time.Hour},
+		{"1w2d", 56 * time.Hour},
+		{"1.5d", 12 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseWorkDuration(tt.value)
+			if err != nil {
+				t.Fatalf("ParseWorkDuration(%q) failed: %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseWorkDuration(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -8,18 +8,25 @@ import (
 )
 
 // Styles provides styling utilities for rendering
-type Styles struct{}
+type Styles struct {
+	theme *Theme
+}
 
-// NewStyles creates a new Styles instance
+// NewStyles creates a new Styles instance using the configured theme
 func NewStyles() *Styles {
-	return &Styles{}
+	return &Styles{theme: ActiveTheme()}
+}
+
+// NewStylesWithTheme creates a new Styles instance using the given theme
+func NewStylesWithTheme(theme *Theme) *Styles {
+	return &Styles{theme: theme}
 }
 
 // ID styles an issue ID
 func (s *Styles) ID(id string) string {
 	style := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("6"))
+		Foreground(themeColor(s.theme.ID))
 	return style.Render(id)
 }
 
@@ -27,7 +34,7 @@ func (s *Styles) ID(id string) string {
 func (s *Styles) Title(title string) string {
 	style := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("7"))
+		Foreground(themeColor(s.theme.Title))
 	return style.Render(title)
 }
 
@@ -35,43 +42,31 @@ func (s *Styles) Title(title string) string {
 func (s *Styles) Label(label string) string {
 	style := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("4"))
+		Foreground(themeColor(s.theme.Label))
 	return style.Render(label)
 }
 
 // StatusColor returns a function that styles text with the appropriate color for a status
 func (s *Styles) StatusColor(status string) func(string) string {
-	colors := map[string]lipgloss.Color{
-		models.StatusTODO:  lipgloss.Color("3"), // Yellow
-		models.StatusDOING: lipgloss.Color("6"), // Cyan
-		models.StatusDONE:  lipgloss.Color("2"), // Green
-	}
-
-	color := colors[status]
-	if color == "" {
-		color = lipgloss.Color("7") // Default white
+	color, ok := s.theme.Statuses[status]
+	if !ok {
+		color = s.theme.Default
 	}
 
+	style := lipgloss.NewStyle().Foreground(themeColor(color)).Bold(s.theme.Bold)
 	return func(text string) string {
-		return lipgloss.NewStyle().Foreground(color).Render(text)
+		return style.Render(text)
 	}
 }
 
 // PriorityColor returns a function that styles text with the appropriate color for a priority
 func (s *Styles) PriorityColor(priority string) func(string) string {
-	colors := map[string]lipgloss.Color{
-		models.PriorityLOW:      lipgloss.Color("2"), // Green
-		models.PriorityMEDIUM:   lipgloss.Color("3"), // Yellow
-		models.PriorityHIGH:     lipgloss.Color("1"), // Red
-		models.PriorityCRITICAL: lipgloss.Color("1"), // Red
-	}
-
-	color := colors[priority]
-	if color == "" {
-		color = lipgloss.Color("7") // Default white
+	color, ok := s.theme.Priorities[priority]
+	if !ok {
+		color = s.theme.Default
 	}
 
-	style := lipgloss.NewStyle().Foreground(color)
+	style := lipgloss.NewStyle().Foreground(themeColor(color)).Bold(s.theme.Bold)
 	if priority == models.PriorityCRITICAL {
 		style = style.Bold(true)
 	}
@@ -84,7 +79,7 @@ func (s *Styles) PriorityColor(priority string) func(string) string {
 // Error styles error text
 func (s *Styles) Error(text string) string {
 	style := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("1")).
+		Foreground(themeColor(s.theme.Error)).
 		Bold(true)
 	return style.Render(text)
 }
@@ -92,7 +87,7 @@ func (s *Styles) Error(text string) string {
 // Success styles success text
 func (s *Styles) Success(text string) string {
 	style := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("2")).
+		Foreground(themeColor(s.theme.Success)).
 		Bold(true)
 	return style.Render(text)
 }
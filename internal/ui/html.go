@@ -0,0 +1,172 @@
+package ui
+
+import (
+	"bytes"
+	"html/template"
+	"io"
+	"slices"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/yuin/goldmark"
+)
+
+// ShareBundle is the content of a read-only share page.
+type ShareBundle struct {
+	Title           string            // Page title
+	Project         string            // Project key
+	Filter          string            // Filter the issues were selected with (empty for all)
+	GeneratedAt     string            // ISO 8601 timestamp
+	Statuses        []string          // Board columns, in workflow order
+	Issues          []*models.Issue   // Shared issues, in display order
+	Epics           map[string]string // Epic ID to title, for the shared issues' epics
+	IncludeComments bool              // Whether issue comments are shown
+}
+
+// shareColumn is one board column of a share page.
+type shareColumn struct {
+	Status string
+	Issues []*models.Issue
+}
+
+// RenderShareHTML writes a share bundle as a single self-contained HTML page with
+// board and list views. All data and styles are inlined and the page needs no
+// script, so it can be opened from an email attachment. Markdown in descriptions
+// and comments is rendered without raw HTML.
+func RenderShareHTML(bundle *ShareBundle, w io.Writer) error {
+	// Columns follow the workflow; statuses outside it are appended in order of
+	// appearance. Ranked issues (issue rank) lead their column in rank order
+	statuses := slices.Clone(bundle.Statuses)
+	for _, issue := range bundle.Issues {
+		if !slices.Contains(statuses, issue.Status) {
+			statuses = append(statuses, issue.Status)
+		}
+	}
+	columns := make([]shareColumn, 0, len(statuses))
+	for _, status := range statuses {
+		column := shareColumn{Status: status}
+		for _, issue := range bundle.Issues {
+			if issue.Status == status {
+				column.Issues = append(column.Issues, issue)
+			}
+		}
+		slices.SortStableFunc(column.Issues, func(a, b *models.Issue) int {
+			return models.CompareRanks(a.Rank, b.Rank)
+		})
+		columns = append(columns, column)
+	}
+
+	md := goldmark.New()
+	funcs := template.FuncMap{
+		"markdown": func(text string) template.HTML {
+			var buf bytes.Buffer
+			if err := md.Convert([]byte(text), &buf); err != nil {
+				return template.HTML(template.HTMLEscapeString(text))
+			}
+			return template.HTML(buf.String())
+		},
+		"time": func(value string) string {
+			return FormatTime(value, config.Location())
+		},
+		"epic": func(id string) string {
+			if title := bundle.Epics[id]; title != "" {
+				return id + " " + title
+			}
+			return id
+		},
+	}
+	tmpl, err := template.New("share").Funcs(funcs).Parse(shareTemplate)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, struct {
+		*ShareBundle
+		Columns []shareColumn
+	}{bundle, columns})
+}
+
+// shareTemplate is the share page. The board/list switch uses radio inputs and
+// CSS only, since mail clients and locked-down browsers often block scripts.
+const shareTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="generator" content="buyruk">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 0 auto; max-width: 1200px; padding: 1.5rem; color: #1f2328; background: #fff; }
+h1 { margin: 0 0 .25rem; }
+.meta { color: #59636e; font-size: .875rem; margin: .25rem 0; }
+.views > input { display: none; }
+.views > label { display: inline-block; padding: .4rem 1rem; margin: 1rem .25rem 1rem 0; border: 1px solid #d1d9e0; border-radius: 6px; cursor: pointer; }
+#view-board:checked + label, #view-list:checked + label { background: #1f2328; color: #fff; }
+.board, .list { display: none; }
+#view-board:checked ~ .board { display: flex; }
+#view-list:checked ~ .list { display: block; }
+.board { gap: 1rem; overflow-x: auto; align-items: flex-start; }
+.column { flex: 1 0 220px; background: #f6f8fa; border-radius: 8px; padding: .5rem; }
+.column h2 { font-size: .95rem; margin: .25rem .25rem .5rem; }
+.count { color: #59636e; font-weight: normal; }
+.card { display: block; background: #fff; border: 1px solid #d1d9e0; border-radius: 6px; padding: .5rem; margin-bottom: .5rem; color: inherit; text-decoration: none; }
+.card:hover { border-color: #0969da; }
+.id { color: #0969da; font-family: ui-monospace, Menlo, monospace; font-size: .8rem; }
+.tag { display: inline-block; font-size: .75rem; border: 1px solid #d1d9e0; border-radius: 1rem; padding: 0 .5rem; margin-right: .25rem; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: .4rem .5rem; border-bottom: 1px solid #d1d9e0; vertical-align: top; }
+th { font-size: .8rem; color: #59636e; }
+article { border-top: 1px solid #d1d9e0; padding: 1rem 0; }
+article h3 { margin: 0 0 .25rem; }
+.comment { border-left: 3px solid #d1d9e0; padding-left: .75rem; margin: .75rem 0; }
+pre { background: #f6f8fa; padding: .5rem; overflow-x: auto; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+<p class="meta">{{.Project}} &middot; {{len .Issues}} issues{{if .Filter}} &middot; filter <code>{{.Filter}}</code>{{end}} &middot; generated {{time .GeneratedAt}} &middot; read-only</p>
+</header>
+<div class="views">
+<input type="radio" name="view" id="view-board" checked><label for="view-board">Board</label>
+<input type="radio" name="view" id="view-list"><label for="view-list">List</label>
+<section class="board">
+{{- range .Columns}}
+<div class="column">
+<h2>{{.Status}} <span class="count">{{len .Issues}}</span></h2>
+{{- range .Issues}}
+<a class="card" href="#{{.ID}}"><span class="id">{{.ID}}</span> {{.Title}}{{if .Priority}}<br><span class="tag">{{.Priority}}</span>{{end}}</a>
+{{- end}}
+</div>
+{{- end}}
+</section>
+<section class="list">
+<table>
+<thead><tr><th>ID</th><th>Title</th><th>Status</th><th>Type</th><th>Priority</th><th>Epic</th><th>Due</th></tr></thead>
+<tbody>
+{{- range .Issues}}
+<tr><td><a class="id" href="#{{.ID}}">{{.ID}}</a></td><td>{{.Title}}</td><td>{{.Status}}</td><td>{{.Type}}</td><td>{{.Priority}}</td><td>{{with .EpicID}}{{epic .}}{{end}}</td><td>{{with .Due}}{{time .}}{{end}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+</section>
+</div>
+<section class="details">
+{{- range .Issues}}
+<article id="{{.ID}}">
+<h3><span class="id">{{.ID}}</span> {{.Title}}</h3>
+<p class="meta">{{.Status}} &middot; {{.Type}}{{if .Priority}} &middot; {{.Priority}}{{end}}{{with .EpicID}} &middot; {{epic .}}{{end}}{{with .Due}} &middot; due {{time .}}{{end}}{{with .UpdatedAt}} &middot; updated {{time .}}{{end}}</p>
+{{- if .Labels}}
+<p>{{range .Labels}}<span class="tag">{{.}}</span>{{end}}</p>
+{{- end}}
+{{- with .Description}}
+<div class="description">{{markdown .}}</div>
+{{- end}}
+{{- if $.IncludeComments}}{{range .Comments}}
+<div class="comment"><p class="meta">{{time .CreatedAt}}</p>{{markdown .Body}}</div>
+{{- end}}{{end}}
+</article>
+{{- end}}
+</section>
+</body>
+</html>
+`
@@ -3,6 +3,7 @@ package ui
 import (
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/buyruk-project/buyruk-cli/internal/models"
 )
@@ -18,6 +19,9 @@ func NewLSONRenderer() *LSONRenderer {
 // RenderIssue renders a single issue in L-SON format
 func (r *LSONRenderer) RenderIssue(issue *models.Issue, w io.Writer) error {
 	fmt.Fprintf(w, "@ID: %s\n", issue.ID)
+	if issue.UID != "" {
+		fmt.Fprintf(w, "@UID: %s\n", issue.UID)
+	}
 	fmt.Fprintf(w, "@TYPE: %s\n", issue.Type)
 	fmt.Fprintf(w, "@STATUS: %s\n", issue.Status)
 
@@ -27,22 +31,110 @@ func (r *LSONRenderer) RenderIssue(issue *models.Issue, w io.Writer) error {
 
 	fmt.Fprintf(w, "@TITLE: %s\n", issue.Title)
 
+	if issue.Assignee != "" {
+		fmt.Fprintf(w, "@ASSIGNEE: %s\n", issue.Assignee)
+	}
+
 	if issue.EpicID != "" {
 		fmt.Fprintf(w, "@EPIC: %s\n", issue.EpicID)
 	}
 
+	if issue.FixVersion != "" {
+		fmt.Fprintf(w, "@FIX_VERSION: %s\n", issue.FixVersion)
+	}
+
+	if issue.ParentID != "" {
+		fmt.Fprintf(w, "@PARENT: %s\n", issue.ParentID)
+	}
+
+	if len(issue.Labels) > 0 {
+		fmt.Fprintf(w, "@LABELS: %s\n", strings.Join(issue.Labels, ","))
+	}
+
+	if len(issue.Components) > 0 {
+		fmt.Fprintf(w, "@COMPONENTS: %s\n", strings.Join(issue.Components, ","))
+	}
+
+	if issue.Due != "" {
+		fmt.Fprintf(w, "@DUE: %s\n", issue.Due)
+	}
+
+	if issue.SnoozedUntil != "" {
+		fmt.Fprintf(w, "@SNOOZED_UNTIL: %s\n", issue.SnoozedUntil)
+	}
+
+	for _, reminder := range issue.Reminders {
+		fmt.Fprintf(w, "@REMIND: %s|%s\n", reminder.At, reminder.Note)
+	}
+
+	if issue.Estimate != "" {
+		fmt.Fprintf(w, "@ESTIMATE: %s\n", issue.Estimate)
+	}
+
+	if issue.Points > 0 {
+		fmt.Fprintf(w, "@POINTS: %d\n", issue.Points)
+	}
+
+	for _, entry := range issue.TimeEntries {
+		fmt.Fprintf(w, "@TIME: %s|%s|%s\n", entry.Duration, entry.User, entry.Note)
+	}
+
+	if issue.Resolution != "" {
+		fmt.Fprintf(w, "@RESOLUTION: %s\n", issue.Resolution)
+	}
+
+	if issue.Sealed != nil {
+		fmt.Fprintf(w, "@SENSITIVE: locked\n")
+	} else if issue.Sensitive {
+		fmt.Fprintf(w, "@SENSITIVE: unlocked\n")
+	}
+
 	if len(issue.BlockedBy) > 0 {
 		for _, dep := range issue.BlockedBy {
 			fmt.Fprintf(w, "@DEP: %s\n", dep)
 		}
 	}
 
+	for _, link := range issue.Links {
+		fmt.Fprintf(w, "@LINK: %s|%s\n", link.Type, link.ID)
+	}
+
+	if issue.Branch != "" {
+		fmt.Fprintf(w, "@BRANCH: %s\n", issue.Branch)
+	}
+
+	for _, sha := range issue.Commits {
+		fmt.Fprintf(w, "@COMMIT: %s\n", sha)
+	}
+
+	if done, total := issue.ChecklistProgress(); total > 0 {
+		fmt.Fprintf(w, "@CHECKLIST: %d/%d\n", done, total)
+	}
+	for n, item := range issue.Checklist {
+		done := ""
+		if item.Done {
+			done = "x"
+		}
+		fmt.Fprintf(w, "@CHECK: %d|%s|%s\n", n+1, done, item.Text)
+	}
+
 	if len(issue.PRs) > 0 {
 		for _, pr := range issue.PRs {
 			fmt.Fprintf(w, "@PR: %s\n", pr)
 		}
 	}
 
+	if issue.CreatedBy != "" {
+		fmt.Fprintf(w, "@CREATED_BY: %s\n", issue.CreatedBy)
+	}
+	if issue.UpdatedBy != "" {
+		fmt.Fprintf(w, "@UPDATED_BY: %s\n", issue.UpdatedBy)
+	}
+
+	for _, comment := range issue.Comments {
+		fmt.Fprintf(w, "@COMMENT: %s|%s\n", comment.CreatedAt, comment.Body)
+	}
+
 	if issue.Description != "" {
 		fmt.Fprintf(w, "@DESC: %s\n", issue.Description)
 	}
@@ -65,6 +157,9 @@ func (r *LSONRenderer) RenderIssueList(issues []*models.Issue, w io.Writer) erro
 		if issue.Type != "" {
 			fmt.Fprintf(w, "@TYPE: %s\n", issue.Type)
 		}
+		if done, total := issue.ChecklistProgress(); total > 0 {
+			fmt.Fprintf(w, "@CHECKLIST: %d/%d\n", done, total)
+		}
 	}
 	return nil
 }
@@ -56,18 +56,18 @@ func getTerminalWidth() int {
 
 // getMarkdownRenderer returns a cached markdown renderer instance
 // This is thread-safe and creates the renderer only once
-// Uses a fixed "dark" style to avoid slow terminal detection (WithAutoStyle takes ~5s)
+// Uses the dark or light style of the active theme rather than WithAutoStyle,
+// to avoid slow terminal detection (WithAutoStyle takes ~5s)
 // Word wrap width is detected dynamically from terminal size
 func getMarkdownRenderer() (*glamour.TermRenderer, error) {
 	rendererOnce.Do(func() {
 		// Detect terminal width dynamically
 		wordWrap := getTerminalWidth()
 
-		// Use a fixed "dark" style instead of WithAutoStyle() to avoid slow terminal detection
+		// Use the theme's style instead of WithAutoStyle() to avoid slow terminal detection
 		// WithAutoStyle() does terminal capability detection which takes ~5 seconds
-		// The "dark" style works well in most terminals and is much faster
 		cachedRenderer, rendererErr = glamour.NewTermRenderer(
-			glamour.WithStandardStyle("dark"),
+			glamour.WithStandardStyle(ActiveTheme().MarkdownStyle()),
 			glamour.WithWordWrap(wordWrap),
 		)
 	})
@@ -3,49 +3,198 @@ package ui
 import (
 	"fmt"
 	"io"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/buyruk-project/buyruk-cli/internal/config"
 	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/olekukonko/tablewriter"
 )
 
+// linkLabels are the headings of link groups in issue details.
+var linkLabels = map[string]string{
+	models.LinkBlockedBy:    "Blocked By",
+	models.LinkBlocks:       "Blocks",
+	models.LinkRelatesTo:    "Relates To",
+	models.LinkDuplicates:   "Duplicates",
+	models.LinkDuplicatedBy: "Duplicated By",
+}
+
+// IssueColumns lists the columns the issue table can show.
+var IssueColumns = []string{"id", "title", "status", "priority", "type", "assignee", "epic", "parent", "labels", "components", "due", "estimate", "points", "created", "updated"}
+
+// DefaultIssueColumns are the columns the issue table shows unless others are selected.
+var DefaultIssueColumns = []string{"id", "title", "status", "priority", "type"}
+
+// columnHeaders are the headings of the issue table columns.
+var columnHeaders = map[string]string{
+	"id":         "ID",
+	"title":      "Title",
+	"status":     "Status",
+	"priority":   "Priority",
+	"type":       "Type",
+	"assignee":   "Assignee",
+	"epic":       "Epic",
+	"parent":     "Parent",
+	"labels":     "Labels",
+	"components": "Components",
+	"due":        "Due",
+	"estimate":   "Estimate",
+	"points":     "Points",
+	"created":    "Created",
+	"updated":    "Updated",
+}
+
+// minTitleWidth is how narrow --max-width may make the title column.
+const minTitleWidth = 10
+
+// TableOptions controls the issue table of the modern renderer.
+type TableOptions struct {
+	Columns    []string // Columns to show, in order (DefaultIssueColumns if empty)
+	NoTruncate bool     // Show long values in full on one line instead of wrapping them
+	MaxWidth   int      // Shorten titles so rows fit in this many characters (0 for no limit)
+}
+
 // ModernRenderer renders output in a modern, human-readable format with tables and colors
 type ModernRenderer struct {
 	styles *Styles
+	loc    *time.Location   // Timezone used to display timestamps
+	now    func() time.Time // Reference time for relative timestamps
+	table  TableOptions     // Columns and width of issue tables
 }
 
 // NewModernRenderer creates a new ModernRenderer
 func NewModernRenderer() *ModernRenderer {
 	return &ModernRenderer{
 		styles: NewStyles(),
+		loc:    config.Location(),
+		now:    time.Now,
 	}
 }
 
+// SetClock sets the reference time of relative timestamps
+func (r *ModernRenderer) SetClock(now func() time.Time) {
+	r.now = now
+}
+
+// SetTableOptions sets the columns and width of issue tables
+func (r *ModernRenderer) SetTableOptions(opts TableOptions) {
+	r.table = opts
+}
+
 // RenderIssueList renders a list of issues as a table
 func (r *ModernRenderer) RenderIssueList(issues []*models.Issue, w io.Writer) error {
+	columns := r.table.Columns
+	if len(columns) == 0 {
+		columns = DefaultIssueColumns
+	}
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = columnHeaders[column]
+	}
+	rows := make([][]string, len(issues))
+	for i, issue := range issues {
+		rows[i] = make([]string, len(columns))
+		for j, column := range columns {
+			rows[i][j] = r.issueCell(issue, column)
+		}
+	}
+	if r.table.MaxWidth > 0 {
+		fitTitles(rows, headers, columns, r.table.MaxWidth)
+	}
+
 	table := tablewriter.NewWriter(w)
-	table.SetHeader([]string{"ID", "Title", "Status", "Priority", "Type"})
+	table.SetHeader(headers)
 	table.SetBorder(false)
 	table.SetColumnSeparator(" ")
 	table.SetRowSeparator("")
 	table.SetCenterSeparator("")
+	table.SetAutoWrapText(!r.table.NoTruncate && r.table.MaxWidth == 0)
+	table.AppendBulk(rows)
 
-	for _, issue := range issues {
-		statusColor := r.styles.StatusColor(issue.Status)
-		priorityColor := r.styles.PriorityColor(issue.Priority)
+	table.Render()
+	return nil
+}
 
-		row := []string{
-			r.styles.ID(issue.ID),
-			issue.Title,
-			statusColor(issue.Status),
-			priorityColor(issue.Priority),
-			issue.Type,
+// issueCell returns the value of an issue table column for an issue.
+func (r *ModernRenderer) issueCell(issue *models.Issue, column string) string {
+	switch column {
+	case "id":
+		return r.styles.ID(issue.ID)
+	case "title":
+		if done, total := issue.ChecklistProgress(); total > 0 {
+			return fmt.Sprintf("%s (%d/%d)", issue.Title, done, total)
+		}
+		return issue.Title
+	case "status":
+		return r.styles.StatusColor(issue.Status)(issue.Status)
+	case "priority":
+		return r.styles.PriorityColor(issue.Priority)(issue.Priority)
+	case "type":
+		return issue.Type
+	case "assignee":
+		return issue.Assignee
+	case "epic":
+		return issue.EpicID
+	case "parent":
+		return issue.ParentID
+	case "labels":
+		return strings.Join(issue.Labels, ", ")
+	case "components":
+		return strings.Join(issue.Components, ", ")
+	case "due":
+		return FormatTime(issue.Due, r.loc)
+	case "estimate":
+		return issue.Estimate
+	case "points":
+		if issue.Points == 0 {
+			return ""
 		}
-		table.Append(row)
+		return strconv.Itoa(issue.Points)
+	case "created":
+		return r.relativeTime(issue.CreatedAt)
+	case "updated":
+		return r.relativeTime(issue.UpdatedAt)
 	}
+	return ""
+}
 
-	table.Render()
-	return nil
+// relativeTime formats an RFC3339 timestamp relative to now ("3h ago").
+func (r *ModernRenderer) relativeTime(value string) string {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return RelativeTime(t, r.now())
+}
+
+// fitTitles shortens the title column so that table rows fit in maxWidth
+// characters, but not below minTitleWidth. Each column takes its widest value
+// plus three characters of padding and separator.
+func fitTitles(rows [][]string, headers, columns []string, maxWidth int) {
+	titleColumn := slices.Index(columns, "title")
+	if titleColumn < 0 {
+		return
+	}
+	other := 1
+	for i := range columns {
+		other += 3
+		if i == titleColumn {
+			continue
+		}
+		width := lipgloss.Width(headers[i])
+		for _, row := range rows {
+			width = max(width, lipgloss.Width(row[i]))
+		}
+		other += width
+	}
+	budget := max(maxWidth-other, minTitleWidth)
+	for _, row := range rows {
+		row[titleColumn] = templateTruncate(budget, row[titleColumn])
+	}
 }
 
 // RenderIssue renders a single issue in detail
@@ -63,13 +212,64 @@ func (r *ModernRenderer) RenderIssue(issue *models.Issue, w io.Writer) error {
 	if issue.Type != "" {
 		fmt.Fprintf(w, "%s: %s\n", styles.Label("Type"), issue.Type)
 	}
+	if issue.Assignee != "" {
+		fmt.Fprintf(w, "%s: %s\n", styles.Label("Assignee"), issue.Assignee)
+	}
 	if issue.EpicID != "" {
 		fmt.Fprintf(w, "%s: %s\n", styles.Label("Epic"), issue.EpicID)
 	}
+	if issue.FixVersion != "" {
+		fmt.Fprintf(w, "%s: %s\n", styles.Label("Fix version"), issue.FixVersion)
+	}
+	if issue.ParentID != "" {
+		fmt.Fprintf(w, "%s: %s\n", styles.Label("Parent"), issue.ParentID)
+	}
+	if len(issue.Labels) > 0 {
+		fmt.Fprintf(w, "%s: %s\n", styles.Label("Labels"), strings.Join(issue.Labels, ", "))
+	}
+	if len(issue.Components) > 0 {
+		fmt.Fprintf(w, "%s: %s\n", styles.Label("Components"), strings.Join(issue.Components, ", "))
+	}
+	if issue.Due != "" {
+		fmt.Fprintf(w, "%s: %s\n", styles.Label("Due"), FormatTimeRelative(issue.Due, r.loc, r.now()))
+	}
+	if issue.SnoozedUntil != "" {
+		fmt.Fprintf(w, "%s: %s\n", styles.Label("Snoozed until"), FormatTimeRelative(issue.SnoozedUntil, r.loc, r.now()))
+	}
+	for _, reminder := range issue.Reminders {
+		note := ""
+		if reminder.Note != "" {
+			note = " (" + reminder.Note + ")"
+		}
+		fmt.Fprintf(w, "%s: %s%s\n", styles.Label("Reminder"), FormatTimeRelative(reminder.At, r.loc, r.now()), note)
+	}
+	if issue.Estimate != "" {
+		fmt.Fprintf(w, "%s: %s\n", styles.Label("Estimate"), issue.Estimate)
+	}
+	if issue.Points > 0 {
+		fmt.Fprintf(w, "%s: %d\n", styles.Label("Points"), issue.Points)
+	}
+	if logged := issue.LoggedTime(); logged > 0 {
+		fmt.Fprintf(w, "%s: %s (%d entries)\n", styles.Label("Logged"), FormatWorkDuration(logged), len(issue.TimeEntries))
+	}
+	if issue.Resolution != "" {
+		fmt.Fprintf(w, "%s: %s\n", styles.Label("Resolution"), issue.Resolution)
+	}
+	if issue.Sensitive {
+		fmt.Fprintf(w, "%s: %s\n", styles.Label("Sensitive"), "yes")
+	}
+	if issue.CreatedAt != "" {
+		fmt.Fprintf(w, "%s: %s%s\n", styles.Label("Created"), FormatTimeRelative(issue.CreatedAt, r.loc, r.now()), byActor(issue.CreatedBy))
+	}
+	if issue.UpdatedAt != "" && issue.UpdatedAt != issue.CreatedAt {
+		fmt.Fprintf(w, "%s: %s%s\n", styles.Label("Updated"), FormatTimeRelative(issue.UpdatedAt, r.loc, r.now()), byActor(issue.UpdatedBy))
+	}
 	fmt.Fprintf(w, "\n")
 
 	// Description
-	if issue.Description != "" {
+	if issue.Sealed != nil {
+		fmt.Fprintf(w, "%s\n%s\n\n", styles.Label("Description"), "Encrypted (run `buyruk unlock` to view)")
+	} else if issue.Description != "" {
 		fmt.Fprintf(w, "%s\n", styles.Label("Description"))
 		rendered, err := RenderMarkdown(issue.Description)
 		if err != nil {
@@ -78,9 +278,33 @@ func (r *ModernRenderer) RenderIssue(issue *models.Issue, w io.Writer) error {
 		fmt.Fprintf(w, "%s\n\n", rendered)
 	}
 
-	// Dependencies
-	if len(issue.BlockedBy) > 0 {
-		fmt.Fprintf(w, "%s: %s\n", styles.Label("Blocked By"), strings.Join(issue.BlockedBy, ", "))
+	// Dependencies and typed links, grouped by type
+	for _, linkType := range models.LinkTypes {
+		if ids := issue.LinkedIDs(linkType); len(ids) > 0 {
+			fmt.Fprintf(w, "%s: %s\n", styles.Label(linkLabels[linkType]), strings.Join(ids, ", "))
+		}
+	}
+
+	if issue.Branch != "" {
+		fmt.Fprintf(w, "%s: %s\n", styles.Label("Branch"), issue.Branch)
+	}
+	if len(issue.Commits) > 0 {
+		fmt.Fprintf(w, "%s:\n", styles.Label("Commits"))
+		for _, sha := range issue.Commits {
+			fmt.Fprintf(w, "  - %s\n", sha[:min(len(sha), 12)])
+		}
+	}
+
+	// Checklist
+	if done, total := issue.ChecklistProgress(); total > 0 {
+		fmt.Fprintf(w, "%s (%d/%d):\n", styles.Label("Checklist"), done, total)
+		for n, item := range issue.Checklist {
+			mark := " "
+			if item.Done {
+				mark = "x"
+			}
+			fmt.Fprintf(w, "  %d. [%s] %s\n", n+1, mark, item.Text)
+		}
 	}
 
 	// PRs
@@ -91,9 +315,25 @@ func (r *ModernRenderer) RenderIssue(issue *models.Issue, w io.Writer) error {
 		}
 	}
 
+	// Comments
+	if len(issue.Comments) > 0 {
+		fmt.Fprintf(w, "%s:\n", styles.Label("Comments"))
+		for _, comment := range issue.Comments {
+			fmt.Fprintf(w, "  - %s%s: %s\n", FormatTimeRelative(comment.CreatedAt, r.loc, r.now()), byActor(comment.CreatedBy), comment.Body)
+		}
+	}
+
 	return nil
 }
 
+// byActor returns " by Name" for who made a change, or "" if unknown.
+func byActor(actor string) string {
+	if actor == "" {
+		return ""
+	}
+	return " by " + models.ActorName(actor)
+}
+
 // RenderEpic renders an epic in detail
 func (r *ModernRenderer) RenderEpic(epic *models.Epic, w io.Writer) error {
 	styles := r.styles
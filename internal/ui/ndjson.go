@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+)
+
+// NDJSONRenderer renders output as newline-delimited JSON: one compact JSON
+// object per line, so lists can be streamed through jq or xargs
+type NDJSONRenderer struct{}
+
+// NewNDJSONRenderer creates a new NDJSONRenderer
+func NewNDJSONRenderer() *NDJSONRenderer {
+	return &NDJSONRenderer{}
+}
+
+// RenderIssue renders a single issue as one JSON line
+func (r *NDJSONRenderer) RenderIssue(issue *models.Issue, w io.Writer) error {
+	return json.NewEncoder(w).Encode(issue)
+}
+
+// RenderIssueList renders each issue as its own JSON line
+func (r *NDJSONRenderer) RenderIssueList(issues []*models.Issue, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	for _, issue := range issues {
+		if err := encoder.Encode(issue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenderEpic renders an epic as one JSON line
+func (r *NDJSONRenderer) RenderEpic(epic *models.Epic, w io.Writer) error {
+	return json.NewEncoder(w).Encode(epic)
+}
+
+// RenderProjectIndex renders each index entry as its own JSON line
+func (r *NDJSONRenderer) RenderProjectIndex(index *models.ProjectIndex, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	for _, entry := range index.Issues {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,9 +3,11 @@ package ui
 import (
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/buyruk-project/buyruk-cli/internal/config"
 	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
 	"github.com/spf13/cobra"
 )
 
@@ -26,6 +28,10 @@ func NewRenderer(format string) (Renderer, error) {
 		return NewJSONRenderer(), nil
 	case "lson":
 		return NewLSONRenderer(), nil
+	case "ndjson":
+		return NewNDJSONRenderer(), nil
+	case "yaml":
+		return NewYAMLRenderer(), nil
 	default:
 		return nil, fmt.Errorf("ui: unknown format %q", format)
 	}
@@ -34,5 +40,9 @@ func NewRenderer(format string) (Renderer, error) {
 // GetRenderer gets a renderer from a cobra command, resolving format from flag > config > default
 func GetRenderer(cmd *cobra.Command) (Renderer, error) {
 	format := config.ResolveFormat(cmd)
-	return NewRenderer(format)
+	renderer, err := NewRenderer(format)
+	if modern, ok := renderer.(*ModernRenderer); ok {
+		modern.SetClock(func() time.Time { return storage.Now(cmd.Context()) })
+	}
+	return renderer, err
 }
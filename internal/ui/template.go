@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// namedColors maps human-friendly color names to ANSI color codes
+var namedColors = map[string]string{
+	"black":   "0",
+	"red":     "1",
+	"green":   "2",
+	"yellow":  "3",
+	"blue":    "4",
+	"magenta": "5",
+	"cyan":    "6",
+	"white":   "7",
+}
+
+// TemplateFuncs returns the helper functions available to issue templates:
+//
+//	color "red" .Title       - colorize text (named color or ANSI code)
+//	truncate 20 .Title       - truncate text to n runes, adding an ellipsis
+//	date "2006-01-02" .CreatedAt - reformat an RFC3339 timestamp in the configured timezone
+//	ago .UpdatedAt           - relative time, e.g. "3h ago"
+//	upper, lower             - change case
+//	join ", " .BlockedBy     - join a list of strings
+func TemplateFuncs(ctx context.Context) template.FuncMap {
+	return template.FuncMap{
+		"color":    templateColor,
+		"truncate": templateTruncate,
+		"date":     templateDate,
+		"ago":      func(value string) string { return templateAgo(value, storage.Now(ctx)) },
+		"upper":    strings.ToUpper,
+		"lower":    strings.ToLower,
+		"join":     func(sep string, items []string) string { return strings.Join(items, sep) },
+	}
+}
+
+// ParseTemplate parses template text with the issue template helper functions.
+func ParseTemplate(ctx context.Context, text string) (*template.Template, error) {
+	tmpl, err := template.New("buyruk").Funcs(TemplateFuncs(ctx)).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("ui: invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// RenderTemplate executes the template once for each item, writing a trailing
+// newline after each item unless the template output already ends with one.
+func RenderTemplate(ctx context.Context, text string, items []interface{}, w io.Writer) error {
+	tmpl, err := ParseTemplate(ctx, text)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, item); err != nil {
+			return fmt.Errorf("ui: failed to execute template: %w", err)
+		}
+		output := sb.String()
+		if !strings.HasSuffix(output, "\n") {
+			output += "\n"
+		}
+		if _, err := io.WriteString(w, output); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateColor renders text in the given color (named color or ANSI code)
+func templateColor(color, text string) string {
+	if code, ok := namedColors[strings.ToLower(color)]; ok {
+		color = code
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render(text)
+}
+
+// templateTruncate shortens text to at most n runes, ending with an ellipsis when cut
+func templateTruncate(n int, text string) string {
+	runes := []rune(text)
+	if n <= 0 || len(runes) <= n {
+		return text
+	}
+	if n == 1 {
+		return "…"
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// templateDate reformats an RFC3339 timestamp in the configured timezone using a Go time layout.
+// Values that are not valid RFC3339 timestamps are returned unchanged.
+func templateDate(layout, value string) string {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return t.In(config.Location()).Format(layout)
+}
+
+// templateAgo describes an RFC3339 timestamp relative to now.
+// Values that are not valid RFC3339 timestamps are returned unchanged.
+func templateAgo(value string, now time.Time) string {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return RelativeTime(t, now)
+}
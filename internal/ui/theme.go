@@ -0,0 +1,227 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/buyruk-project/buyruk-cli/internal/config"
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme holds the colors used by Styles. Colors are ANSI codes ("1", "208"),
+// hex values ("#ff8800"), or the names accepted by the color template function.
+//
+// Custom themes are JSON files in [ConfigDir]/buyruk/themes/. They start from
+// their base theme (dark by default) and only need the colors they change.
+type Theme struct {
+	Name       string            `json:"name,omitempty"`
+	Base       string            `json:"base,omitempty"`       // Built-in theme a custom theme starts from
+	ID         string            `json:"id,omitempty"`         // Issue IDs
+	Title      string            `json:"title,omitempty"`      // Titles and headings
+	Label      string            `json:"label,omitempty"`      // Field labels
+	Error      string            `json:"error,omitempty"`      // Error text
+	Success    string            `json:"success,omitempty"`    // Success text
+	Default    string            `json:"default,omitempty"`    // Statuses and priorities without a color
+	Bold       bool              `json:"bold,omitempty"`       // Render statuses and priorities in bold
+	Statuses   map[string]string `json:"statuses,omitempty"`   // Status name to color
+	Priorities map[string]string `json:"priorities,omitempty"` // Priority name to color
+}
+
+// builtinThemes are the themes that ship with buyruk.
+var builtinThemes = map[string]Theme{
+	config.ThemeDark: {
+		Name:    config.ThemeDark,
+		ID:      "6",
+		Title:   "7",
+		Label:   "4",
+		Error:   "1",
+		Success: "2",
+		Default: "7",
+		Statuses: map[string]string{
+			models.StatusTODO:  "3",
+			models.StatusDOING: "6",
+			models.StatusDONE:  "2",
+		},
+		Priorities: map[string]string{
+			models.PriorityLOW:      "2",
+			models.PriorityMEDIUM:   "3",
+			models.PriorityHIGH:     "1",
+			models.PriorityCRITICAL: "1",
+		},
+	},
+	config.ThemeLight: {
+		Name:    config.ThemeLight,
+		ID:      "4",
+		Title:   "0",
+		Label:   "5",
+		Error:   "1",
+		Success: "2",
+		Default: "0",
+		Statuses: map[string]string{
+			models.StatusTODO:  "130",
+			models.StatusDOING: "4",
+			models.StatusDONE:  "2",
+		},
+		Priorities: map[string]string{
+			models.PriorityLOW:      "2",
+			models.PriorityMEDIUM:   "130",
+			models.PriorityHIGH:     "1",
+			models.PriorityCRITICAL: "1",
+		},
+	},
+	config.ThemeHighContrast: {
+		Name:    config.ThemeHighContrast,
+		ID:      "14",
+		Title:   "15",
+		Label:   "12",
+		Error:   "9",
+		Success: "10",
+		Default: "15",
+		Bold:    true,
+		Statuses: map[string]string{
+			models.StatusTODO:  "11",
+			models.StatusDOING: "14",
+			models.StatusDONE:  "10",
+		},
+		Priorities: map[string]string{
+			models.PriorityLOW:      "10",
+			models.PriorityMEDIUM:   "11",
+			models.PriorityHIGH:     "9",
+			models.PriorityCRITICAL: "9",
+		},
+	},
+}
+
+// BuiltinThemeNames returns the names of the built-in themes.
+func BuiltinThemeNames() []string {
+	return []string{config.ThemeDark, config.ThemeLight, config.ThemeHighContrast}
+}
+
+// LoadTheme returns a theme by name: auto picks dark or light from the
+// terminal background, built-in names return their theme, and other names
+// are read from the themes directory.
+func LoadTheme(name string) (*Theme, error) {
+	if name == "" || name == config.ThemeAuto {
+		name = config.ThemeDark
+		if !hasDarkBackground() {
+			name = config.ThemeLight
+		}
+	}
+	if builtin, ok := builtinThemes[name]; ok {
+		theme := builtin
+		return &theme, nil
+	}
+
+	themePath, err := storage.ThemePath(name)
+	if err != nil {
+		return nil, fmt.Errorf("ui: failed to resolve theme path: %w", err)
+	}
+	var custom Theme
+	if err := storage.ReadJSON(context.Background(), themePath, &custom); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("ui: theme %q not found", name)
+		}
+		return nil, fmt.Errorf("ui: failed to load theme %q: %w", name, err)
+	}
+
+	baseName := custom.Base
+	if baseName == "" {
+		baseName = config.ThemeDark
+	}
+	base, ok := builtinThemes[baseName]
+	if !ok {
+		return nil, fmt.Errorf("ui: theme %q has unknown base %q (must be dark, light, or high-contrast)", name, baseName)
+	}
+	theme := base.merge(&custom)
+	theme.Name, theme.Base = name, baseName
+	return theme, nil
+}
+
+// hasDarkBackground reports whether the terminal background is dark, from the
+// COLORFGBG variable set by many terminals ("15;0" is white on black). The
+// terminal is not queried directly, as that can take seconds (see
+// getMarkdownRenderer); without the variable the background is assumed dark.
+func hasDarkBackground() bool {
+	colorfgbg := os.Getenv("COLORFGBG")
+	if colorfgbg == "" {
+		return true
+	}
+	fields := strings.Split(colorfgbg, ";")
+	bg, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil {
+		return true
+	}
+	// 0-6 and 8 are the dark ANSI colors; 7 and 9-15 are light
+	return bg < 7 || bg == 8
+}
+
+// MarkdownStyle returns the glamour style matching the theme background.
+func (t *Theme) MarkdownStyle() string {
+	if t.Name == config.ThemeLight || t.Base == config.ThemeLight {
+		return "light"
+	}
+	return "dark"
+}
+
+// ActiveTheme returns the theme set in config, falling back to auto when none
+// is set or the configured one cannot be loaded.
+func ActiveTheme() *Theme {
+	name := config.ThemeAuto
+	if cfg, err := config.Get(); err == nil && cfg.Theme != "" {
+		name = cfg.Theme
+	}
+	theme, err := LoadTheme(name)
+	if err != nil {
+		theme, _ = LoadTheme(config.ThemeAuto)
+	}
+	return theme
+}
+
+// merge returns a copy of t with the colors set in other replacing its own.
+func (t Theme) merge(other *Theme) *Theme {
+	for _, field := range []struct {
+		dst *string
+		src string
+	}{
+		{&t.ID, other.ID},
+		{&t.Title, other.Title},
+		{&t.Label, other.Label},
+		{&t.Error, other.Error},
+		{&t.Success, other.Success},
+		{&t.Default, other.Default},
+	} {
+		if field.src != "" {
+			*field.dst = field.src
+		}
+	}
+	t.Bold = t.Bold || other.Bold
+	t.Statuses = mergeColors(t.Statuses, other.Statuses)
+	t.Priorities = mergeColors(t.Priorities, other.Priorities)
+	return &t
+}
+
+// mergeColors returns a new map with the colors of both, override winning.
+func mergeColors(base, override map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[strings.ToUpper(k)] = v
+	}
+	return merged
+}
+
+// themeColor converts a theme color to a lipgloss color, resolving color names.
+func themeColor(value string) lipgloss.Color {
+	if code, ok := namedColors[strings.ToLower(value)]; ok {
+		value = code
+	}
+	return lipgloss.Color(value)
+}
@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// timeLayout is the layout used to display timestamps in modern output
+const timeLayout = "2006-01-02 15:04"
+
+// FormatTime formats an RFC3339 timestamp in loc.
+// Values that are not valid RFC3339 timestamps are returned unchanged.
+func FormatTime(value string, loc *time.Location) string {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return t.In(loc).Format(timeLayout)
+}
+
+// FormatTimeRelative formats an RFC3339 timestamp in loc followed by the time
+// relative to now, e.g. "2024-06-01 14:00 (3h ago)".
+// Values that are not valid RFC3339 timestamps are returned unchanged.
+func FormatTimeRelative(value string, loc *time.Location, now time.Time) string {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return fmt.Sprintf("%s (%s)", t.In(loc).Format(timeLayout), RelativeTime(t, now))
+}
+
+// RelativeTime describes t relative to now, e.g. "just now", "3h ago", or "in 2d".
+func RelativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+
+	var amount string
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		amount = fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 24*time.Hour:
+		amount = fmt.Sprintf("%dh", int(d/time.Hour))
+	case d < 30*24*time.Hour:
+		amount = fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d < 365*24*time.Hour:
+		amount = fmt.Sprintf("%dmo", int(d/(30*24*time.Hour)))
+	default:
+		amount = fmt.Sprintf("%dy", int(d/(365*24*time.Hour)))
+	}
+
+	if future {
+		return "in " + amount
+	}
+	return amount + " ago"
+}
+
+// FormatWorkDuration formats an amount of working time with 8-hour days,
+// e.g. "2d 4h". It is the display counterpart of timeparse.ParseWorkDuration.
+func FormatWorkDuration(d time.Duration) string {
+	const day = 8 * time.Hour
+	if d <= 0 {
+		return "0h"
+	}
+
+	var parts []string
+	if days := d / day; days > 0 {
+		parts = append(parts, fmt.Sprintf("%dd", days))
+		d -= days * day
+	}
+	if hours := d / time.Hour; hours > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", hours))
+		d -= hours * time.Hour
+	}
+	if minutes := d / time.Minute; minutes > 0 {
+		parts = append(parts, fmt.Sprintf("%dm", minutes))
+	}
+	if len(parts) == 0 {
+		return "0h"
+	}
+	return strings.Join(parts, " ")
+}
+
+// FormatElapsed formats wall-clock time with 24-hour days, to at most two
+// units, e.g. "3d 4h", "2h 15m", or "40s".
+func FormatElapsed(d time.Duration) string {
+	days := d / (24 * time.Hour)
+	hours := d % (24 * time.Hour) / time.Hour
+	minutes := d % time.Hour / time.Minute
+	switch {
+	case days > 0 && hours > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case days > 0:
+		return fmt.Sprintf("%dd", days)
+	case hours > 0 && minutes > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%dh", hours)
+	case minutes > 0:
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%ds", int(d.Seconds()))
+}
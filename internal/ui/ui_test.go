@@ -2,11 +2,17 @@ package ui
 
 import (
 	"bytes"
+	"encoding/json"
+	"flag"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/buyruk-project/buyruk-cli/internal/models"
+	"github.com/buyruk-project/buyruk-cli/internal/storage"
 	"github.com/spf13/cobra"
 )
 
@@ -20,6 +26,8 @@ func TestNewRenderer(t *testing.T) {
 		{"modern format", "modern", false},
 		{"json format", "json", false},
 		{"lson format", "lson", false},
+		{"ndjson format", "ndjson", false},
+		{"yaml format", "yaml", false},
 		{"invalid format", "invalid", true},
 	}
 
@@ -93,6 +101,43 @@ func TestModernRenderer_RenderIssueList(t *testing.T) {
 	}
 }
 
+// TestModernRenderer_RenderIssueList_Columns tests column selection and width control
+func TestModernRenderer_RenderIssueList_Columns(t *testing.T) {
+	renderer := NewModernRenderer()
+	longTitle := "Migrate the authentication service to the new session store without downtime"
+	issues := []*models.Issue{
+		{ID: "CORE-1", Title: longTitle, Status: models.StatusTODO, Assignee: "alice", Labels: []string{"infra", "auth"}},
+		{ID: "CORE-2", Title: "Short", Status: models.StatusDONE},
+	}
+
+	renderer.SetTableOptions(TableOptions{Columns: []string{"id", "assignee", "labels", "title"}, NoTruncate: true})
+	var buf bytes.Buffer
+	if err := renderer.RenderIssueList(issues, &buf); err != nil {
+		t.Fatalf("RenderIssueList() failed: %v", err)
+	}
+	output := buf.String()
+	if !strings.Contains(output, "ASSIGNEE") || !strings.Contains(output, "infra, auth") || strings.Contains(output, "STATUS") {
+		t.Errorf("RenderIssueList() did not show the selected columns: %s", output)
+	}
+	if !strings.Contains(output, longTitle) {
+		t.Errorf("RenderIssueList() with NoTruncate should show the full title: %s", output)
+	}
+
+	renderer.SetTableOptions(TableOptions{MaxWidth: 60})
+	buf.Reset()
+	if err := renderer.RenderIssueList(issues, &buf); err != nil {
+		t.Fatalf("RenderIssueList() failed: %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+		if width := len([]rune(strings.TrimRight(line, " "))); width > 60 {
+			t.Errorf("Line is %d characters wide, want at most 60: %q", width, line)
+		}
+	}
+	if !strings.Contains(buf.String(), "…") || !strings.Contains(buf.String(), "Short") {
+		t.Errorf("RenderIssueList() with MaxWidth should shorten only long titles: %s", buf.String())
+	}
+}
+
 // TestModernRenderer_RenderIssue tests modern format issue detail rendering
 func TestModernRenderer_RenderIssue(t *testing.T) {
 	renderer := NewModernRenderer()
@@ -125,6 +170,36 @@ func TestModernRenderer_RenderIssue(t *testing.T) {
 	}
 }
 
+func TestModernRenderer_RenderIssue_Links(t *testing.T) {
+	renderer := NewModernRenderer()
+	issue := &models.Issue{
+		ID:        "CORE-12",
+		Title:     "Test Issue",
+		Status:    models.StatusTODO,
+		BlockedBy: []string{"CORE-10"},
+		Links: []models.Link{
+			{Type: models.LinkRelatesTo, ID: "CORE-3"},
+			{Type: models.LinkBlocks, ID: "CORE-14"},
+			{Type: models.LinkRelatesTo, ID: "CORE-4"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := renderer.RenderIssue(issue, &buf); err != nil {
+		t.Fatalf("RenderIssue() failed: %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{"CORE-10", "Blocks", "CORE-14", "Relates To", "CORE-3, CORE-4"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("RenderIssue() output missing %q:\n%s", want, output)
+		}
+	}
+	if strings.Index(output, "Blocks") > strings.Index(output, "Relates To") {
+		t.Errorf("Expected link groups in type order, got:\n%s", output)
+	}
+}
+
 // TestModernRenderer_RenderIssue_EmptyFields tests rendering issue with empty optional fields
 func TestModernRenderer_RenderIssue_EmptyFields(t *testing.T) {
 	renderer := NewModernRenderer()
@@ -284,6 +359,34 @@ func TestJSONRenderer_RenderIssueList(t *testing.T) {
 	}
 }
 
+// TestNDJSONRenderer_RenderIssueList tests NDJSON format issue list rendering
+func TestNDJSONRenderer_RenderIssueList(t *testing.T) {
+	renderer := NewNDJSONRenderer()
+	issues := []*models.Issue{
+		{ID: "CORE-1", Title: "Issue 1", Status: models.StatusTODO, Type: models.TypeTask},
+		{ID: "CORE-2", Title: "Issue 2", Status: models.StatusDONE, Type: models.TypeBug},
+	}
+
+	var buf bytes.Buffer
+	if err := renderer.RenderIssueList(issues, &buf); err != nil {
+		t.Fatalf("RenderIssueList() failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("RenderIssueList() wrote %d lines, want one per issue: %s", len(lines), buf.String())
+	}
+	for i, line := range lines {
+		var issue models.Issue
+		if err := json.Unmarshal([]byte(line), &issue); err != nil {
+			t.Fatalf("Line %d is not a JSON object: %v", i+1, err)
+		}
+		if issue.ID != issues[i].ID {
+			t.Errorf("Line %d has issue %s, want %s", i+1, issue.ID, issues[i].ID)
+		}
+	}
+}
+
 // TestJSONRenderer_RenderEpic tests JSON format epic rendering
 func TestJSONRenderer_RenderEpic(t *testing.T) {
 	renderer := NewJSONRenderer()
@@ -662,3 +765,503 @@ func TestLSONRenderer_RenderIssueList_Empty(t *testing.T) {
 		t.Error("RenderIssueList() with empty list should produce minimal output")
 	}
 }
+
+// TestYAMLRenderer_RenderIssue tests YAML format issue rendering
+func TestYAMLRenderer_RenderIssue(t *testing.T) {
+	renderer := NewYAMLRenderer()
+	issue := &models.Issue{
+		ID:        "CORE-1",
+		Title:     "Test Issue",
+		Status:    models.StatusTODO,
+		Type:      models.TypeTask,
+		BlockedBy: []string{"CORE-2"},
+	}
+
+	var buf bytes.Buffer
+	if err := renderer.RenderIssue(issue, &buf); err != nil {
+		t.Fatalf("RenderIssue() failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.HasPrefix(output, "id: CORE-1\n") {
+		t.Errorf("RenderIssue() YAML output should start with id field, got: %s", output)
+	}
+	if !strings.Contains(output, "blocked_by:\n  - CORE-2") {
+		t.Errorf("RenderIssue() YAML output should use JSON field names, got: %s", output)
+	}
+	if strings.Contains(output, "priority") {
+		t.Errorf("RenderIssue() YAML output should omit empty fields, got: %s", output)
+	}
+}
+
+// TestYAMLRenderer_RenderIssueList tests YAML format issue list rendering
+func TestYAMLRenderer_RenderIssueList(t *testing.T) {
+	renderer := NewYAMLRenderer()
+	issues := []*models.Issue{
+		{ID: "CORE-1", Title: "Issue 1", Status: models.StatusTODO},
+		{ID: "CORE-2", Title: "Issue 2", Status: models.StatusDONE},
+	}
+
+	var buf bytes.Buffer
+	if err := renderer.RenderIssueList(issues, &buf); err != nil {
+		t.Fatalf("RenderIssueList() failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "- id: CORE-1") || !strings.Contains(output, "- id: CORE-2") {
+		t.Errorf("RenderIssueList() YAML output missing sequence items, got: %s", output)
+	}
+}
+
+// TestYAMLRenderer_RenderProjectIndex tests YAML format project index rendering
+func TestYAMLRenderer_RenderProjectIndex(t *testing.T) {
+	renderer := NewYAMLRenderer()
+	index := &models.ProjectIndex{
+		ProjectKey: "CORE",
+		Issues:     []models.IndexEntry{},
+	}
+
+	var buf bytes.Buffer
+	if err := renderer.RenderProjectIndex(index, &buf); err != nil {
+		t.Fatalf("RenderProjectIndex() failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "project_key: CORE") {
+		t.Errorf("RenderProjectIndex() YAML output missing project key, got: %s", output)
+	}
+	if !strings.Contains(output, "issues: []") {
+		t.Errorf("RenderProjectIndex() YAML output missing empty issues list, got: %s", output)
+	}
+}
+
+// TestMarshalYAML_RoundTrip tests that UnmarshalYAML reverses MarshalYAML
+func TestMarshalYAML_RoundTrip(t *testing.T) {
+	epic := &models.Epic{
+		ID:          "E-1",
+		Title:       "Epic: with colon",
+		Status:      models.StatusDOING,
+		Description: "line one\nline two",
+	}
+
+	data, err := MarshalYAML(epic)
+	if err != nil {
+		t.Fatalf("MarshalYAML() failed: %v", err)
+	}
+
+	var decoded models.Epic
+	if err := UnmarshalYAML(data, &decoded); err != nil {
+		t.Fatalf("UnmarshalYAML() failed: %v", err)
+	}
+
+	if decoded != *epic {
+		t.Errorf("UnmarshalYAML() = %+v, want %+v", decoded, *epic)
+	}
+}
+
+// TestUnmarshalYAML_Invalid tests that invalid YAML returns an error
+func TestUnmarshalYAML_Invalid(t *testing.T) {
+	var epic models.Epic
+	if err := UnmarshalYAML([]byte("id: [unclosed"), &epic); err == nil {
+		t.Error("UnmarshalYAML() should fail for invalid YAML")
+	}
+}
+
+// TestMarshalYAML_Scalars tests that strings which read as other types, or
+// need escaping, survive a round trip
+func TestMarshalYAML_Scalars(t *testing.T) {
+	values := []string{
+		"", "true", "No", "null", "~", "42", "1.5", "0x1F", "2024-06-01",
+		"- dash", "#hash", "key: value", "trailing:", "a #comment", " padded ",
+		"'single'", "\"double\"", "tab\there", "ends with newline\n",
+		"\n leading newline", "first\n  indented\n\nlast", "crlf\r\nline",
+		"https://example.com/a:b", "ünïcödé", "[flow]", "{flow}", "|", ">",
+	}
+	wrapped := map[string]interface{}{"values": values, "nested": [][]string{{"a", "b"}, {}}}
+
+	data, err := MarshalYAML(wrapped)
+	if err != nil {
+		t.Fatalf("MarshalYAML() failed: %v", err)
+	}
+
+	var decoded struct {
+		Values []string   `json:"values"`
+		Nested [][]string `json:"nested"`
+	}
+	if err := UnmarshalYAML(data, &decoded); err != nil {
+		t.Fatalf("UnmarshalYAML() failed: %v\n%s", err, data)
+	}
+	if !slices.Equal(decoded.Values, values) {
+		t.Errorf("UnmarshalYAML() values = %q, want %q\n%s", decoded.Values, values, data)
+	}
+	if len(decoded.Nested) != 2 || !slices.Equal(decoded.Nested[0], []string{"a", "b"}) || len(decoded.Nested[1]) != 0 {
+		t.Errorf("UnmarshalYAML() nested = %q\n%s", decoded.Nested, data)
+	}
+}
+
+// TestUnmarshalYAML_HandWritten tests YAML that MarshalYAML doesn't write
+// but users might
+func TestUnmarshalYAML_HandWritten(t *testing.T) {
+	data := `---
+# An epic written by hand
+id: E-2
+'title': 'It''s "quoted"'
+status: DOING   # trailing comment
+description: >
+  folded
+  text
+
+  kept paragraph
+labels: [one, "two, three", 4]
+meta: {a: 1, b: [x, y]}
+prs:
+- https://example.com/1
+- https://example.com/2
+`
+	var decoded struct {
+		ID          string                 `json:"id"`
+		Title       string                 `json:"title"`
+		Status      string                 `json:"status"`
+		Description string                 `json:"description"`
+		Labels      []interface{}          `json:"labels"`
+		Meta        map[string]interface{} `json:"meta"`
+		PRs         []string               `json:"prs"`
+	}
+	if err := UnmarshalYAML([]byte(data), &decoded); err != nil {
+		t.Fatalf("UnmarshalYAML() failed: %v", err)
+	}
+
+	if decoded.ID != "E-2" || decoded.Title != `It's "quoted"` || decoded.Status != "DOING" {
+		t.Errorf("UnmarshalYAML() scalars = %q, %q, %q", decoded.ID, decoded.Title, decoded.Status)
+	}
+	if want := "folded text\nkept paragraph\n"; decoded.Description != want {
+		t.Errorf("UnmarshalYAML() description = %q, want %q", decoded.Description, want)
+	}
+	if len(decoded.Labels) != 3 || decoded.Labels[1] != "two, three" || decoded.Labels[2] != float64(4) {
+		t.Errorf("UnmarshalYAML() labels = %#v", decoded.Labels)
+	}
+	if decoded.Meta["a"] != float64(1) || len(decoded.Meta["b"].([]interface{})) != 2 {
+		t.Errorf("UnmarshalYAML() meta = %#v", decoded.Meta)
+	}
+	if !slices.Equal(decoded.PRs, []string{"https://example.com/1", "https://example.com/2"}) {
+		t.Errorf("UnmarshalYAML() prs = %q", decoded.PRs)
+	}
+}
+
+// TestRenderTemplate tests rendering items through a Go template
+func TestRenderTemplate(t *testing.T) {
+	issues := []interface{}{
+		&models.Issue{ID: "CORE-1", Title: "First", Status: models.StatusTODO},
+		&models.Issue{ID: "CORE-2", Title: "Second", Status: models.StatusDONE},
+	}
+
+	var buf bytes.Buffer
+	if err := RenderTemplate(t.Context(), "{{.ID}}: {{.Title}} [{{.Status}}]", issues, &buf); err != nil {
+		t.Fatalf("RenderTemplate() failed: %v", err)
+	}
+
+	want := "CORE-1: First [TODO]\nCORE-2: Second [DONE]\n"
+	if buf.String() != want {
+		t.Errorf("RenderTemplate() = %q, want %q", buf.String(), want)
+	}
+}
+
+// TestRenderTemplate_Helpers tests the template helper functions
+func TestRenderTemplate_Helpers(t *testing.T) {
+	issue := &models.Issue{
+		ID:        "CORE-1",
+		Title:     "A very long issue title",
+		Status:    models.StatusTODO,
+		BlockedBy: []string{"CORE-2", "CORE-3"},
+		CreatedAt: "2024-03-05T10:00:00Z",
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"truncate", `{{truncate 6 .Title}}`, "A ver…\n"},
+		{"truncate short", `{{truncate 50 .ID}}`, "CORE-1\n"},
+		{"date", `{{date "2006-01-02" .CreatedAt}}`, "2024-03-05\n"},
+		{"date invalid", `{{date "2006-01-02" .Title}}`, "A very long issue title\n"},
+		{"lower", `{{lower .Status}}`, "todo\n"},
+		{"join", `{{join "," .BlockedBy}}`, "CORE-2,CORE-3\n"},
+		{"color", `{{color "red" .ID}}`, "CORE-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := RenderTemplate(t.Context(), tt.text, []interface{}{issue}, &buf); err != nil {
+				t.Fatalf("RenderTemplate() failed: %v", err)
+			}
+			if tt.name == "color" {
+				// Colors may be disabled in test environments; only the text is guaranteed
+				if !strings.Contains(buf.String(), tt.want) {
+					t.Errorf("RenderTemplate() = %q, want it to contain %q", buf.String(), tt.want)
+				}
+				return
+			}
+			if buf.String() != tt.want {
+				t.Errorf("RenderTemplate() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+// TestRenderTemplate_Invalid tests that invalid templates return errors
+func TestRenderTemplate_Invalid(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderTemplate(t.Context(), "{{.ID", []interface{}{&models.Issue{}}, &buf); err == nil {
+		t.Error("RenderTemplate() should fail for unparsable template")
+	}
+	if err := RenderTemplate(t.Context(), "{{.Missing}}", []interface{}{&models.Issue{}}, &buf); err == nil {
+		t.Error("RenderTemplate() should fail for unknown field")
+	}
+}
+
+// TestRelativeTime tests relative time descriptions
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2024, 6, 5, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{now.Add(-30 * time.Second), "just now"},
+		{now.Add(-5 * time.Minute), "5m ago"},
+		{now.Add(-3 * time.Hour), "3h ago"},
+		{now.Add(-50 * time.Hour), "2d ago"},
+		{now.Add(-60 * 24 * time.Hour), "2mo ago"},
+		{now.Add(-400 * 24 * time.Hour), "1y ago"},
+		{now.Add(72 * time.Hour), "in 3d"},
+	}
+
+	for _, tt := range tests {
+		if got := RelativeTime(tt.t, now); got != tt.want {
+			t.Errorf("RelativeTime(%v) = %q, want %q", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestFormatWorkDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0h"},
+		{4 * time.Hour, "4h"},
+		{16 * time.Hour, "2d"},
+		{20*time.Hour + 30*time.Minute, "2d 4h 30m"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatWorkDuration(tt.d); got != tt.want {
+			t.Errorf("FormatWorkDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestFormatElapsed(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{40 * time.Second, "40s"},
+		{15 * time.Minute, "15m"},
+		{2*time.Hour + 30*time.Minute, "2h 30m"},
+		{27*time.Hour + 5*time.Minute, "1d 3h"},
+		{48 * time.Hour, "2d"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatElapsed(tt.d); got != tt.want {
+			t.Errorf("FormatElapsed(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+// TestModernRenderer_RenderIssue_Timestamps tests timestamps are shown in the configured timezone
+func TestModernRenderer_RenderIssue_Timestamps(t *testing.T) {
+	renderer := NewModernRenderer()
+	renderer.loc = time.FixedZone("UTC+3", 3*60*60)
+	renderer.now = func() time.Time { return time.Date(2024, 6, 5, 12, 0, 0, 0, time.UTC) }
+
+	issue := &models.Issue{
+		ID:        "CORE-1",
+		Title:     "Timestamps",
+		Status:    models.StatusTODO,
+		CreatedAt: "2024-06-05T09:00:00Z",
+		UpdatedAt: "2024-06-05T11:30:00Z",
+	}
+
+	var buf bytes.Buffer
+	if err := renderer.RenderIssue(issue, &buf); err != nil {
+		t.Fatalf("RenderIssue() failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "2024-06-05 12:00 (3h ago)") {
+		t.Errorf("RenderIssue() output missing local created time, got: %s", output)
+	}
+	if !strings.Contains(output, "2024-06-05 14:30 (30m ago)") {
+		t.Errorf("RenderIssue() output missing local updated time, got: %s", output)
+	}
+}
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata")
+
+// assertGolden compares output with testdata/<name>.golden, rewriting it when -update is set
+func assertGolden(t *testing.T, name string, output []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *updateGolden {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			t.Fatalf("Failed to create testdata: %v", err)
+		}
+		if err := os.WriteFile(path, output, 0644); err != nil {
+			t.Fatalf("Failed to update golden file: %v", err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read golden file (run with -update to create it): %v", err)
+	}
+	if !bytes.Equal(output, want) {
+		t.Errorf("Output does not match %s\ngot:\n%s\nwant:\n%s", path, output, want)
+	}
+}
+
+// TestRenderIssue_Golden tests issue rendering against golden files using a fixed clock
+func TestRenderIssue_Golden(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	issue := &models.Issue{
+		ID:         "CORE-12",
+		Type:       models.TypeBug,
+		Title:      "Crash on empty index",
+		Status:     models.StatusDONE,
+		Priority:   models.PriorityHIGH,
+		BlockedBy:  []string{"CORE-10"},
+		PRs:        []string{"https://github.com/example/pr/1"},
+		EpicID:     "E-1",
+		Due:        "2024-06-07T00:00:00Z",
+		Resolution: "fixed",
+		Labels:     []string{"crash", "index"},
+		Comments:   []models.Comment{{Body: "Fixed by rebuilding the index", CreatedAt: "2024-06-05T09:00:00Z"}},
+		CreatedAt:  "2024-06-01T09:00:00Z",
+		UpdatedAt:  "2024-06-05T09:00:00Z",
+	}
+
+	modern := NewModernRenderer()
+	modern.loc = time.UTC
+	modern.SetClock(func() time.Time { return time.Date(2024, 6, 5, 12, 0, 0, 0, time.UTC) })
+	renderers := map[string]Renderer{
+		"issue_modern": modern,
+		"issue_lson":   NewLSONRenderer(),
+		"issue_json":   NewJSONRenderer(),
+		"issue_yaml":   NewYAMLRenderer(),
+	}
+
+	for name, renderer := range renderers {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := renderer.RenderIssue(issue, &buf); err != nil {
+				t.Fatalf("RenderIssue() failed: %v", err)
+			}
+			assertGolden(t, name, buf.Bytes())
+		})
+	}
+}
+
+func TestRenderShareHTML(t *testing.T) {
+	bundle := &ShareBundle{
+		Title:       "Client <status>",
+		Project:     "CORE",
+		Filter:      "epic:E-2",
+		GeneratedAt: "2024-06-05T12:00:00Z",
+		Statuses:    []string{"TODO", "DOING", "DONE"},
+		Issues: []*models.Issue{
+			{ID: "CORE-1", Title: "Login page", Status: "DOING", Type: "task", EpicID: "E-2",
+				Description: "**Bold** <script>alert(1)</script>",
+				Comments:    []models.Comment{{Body: "Internal note", CreatedAt: "2024-06-05T12:00:00Z"}}},
+			{ID: "CORE-2", Title: "Legacy", Status: "ARCHIVED", Type: "task"},
+		},
+		Epics: map[string]string{"E-2": "Onboarding"},
+	}
+
+	var buf bytes.Buffer
+	if err := RenderShareHTML(bundle, &buf); err != nil {
+		t.Fatalf("RenderShareHTML() failed: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"<title>Client &lt;status&gt;</title>",
+		"<strong>Bold</strong>",
+		`<h2>ARCHIVED <span class="count">1</span></h2>`,
+		`<h2>TODO <span class="count">0</span></h2>`,
+		"E-2 Onboarding",
+		`<article id="CORE-1">`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected share page to contain %q", want)
+		}
+	}
+	for _, unwanted := range []string{"<script>", "Internal note"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("Expected share page not to contain %q", unwanted)
+		}
+	}
+
+	bundle.IncludeComments = true
+	buf.Reset()
+	if err := RenderShareHTML(bundle, &buf); err != nil {
+		t.Fatalf("RenderShareHTML() failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Internal note") {
+		t.Error("Expected comments with IncludeComments")
+	}
+}
+
+// TestLoadTheme tests built-in themes, background detection, and custom theme files
+func TestLoadTheme(t *testing.T) {
+	t.Setenv("COLORFGBG", "0;15")
+	theme, err := LoadTheme("auto")
+	if err != nil || theme.Name != "light" || theme.MarkdownStyle() != "light" {
+		t.Errorf("LoadTheme(auto) on a light background = %+v, %v", theme, err)
+	}
+	t.Setenv("COLORFGBG", "15;default;0")
+	if theme, _ := LoadTheme("auto"); theme.Name != "dark" {
+		t.Errorf("LoadTheme(auto) on a dark background = %q, want dark", theme.Name)
+	}
+	if _, err := LoadTheme("no-such-theme"); err == nil {
+		t.Error("LoadTheme() should fail for a missing theme")
+	}
+
+	themePath, _ := storage.ThemePath("test-load-theme")
+	defer os.Remove(themePath)
+	storage.EnsureDir(themePath)
+	custom := `{"base": "light", "id": "magenta", "statuses": {"review": "#ff8800"}}`
+	if err := os.WriteFile(themePath, []byte(custom), 0644); err != nil {
+		t.Fatalf("Failed to write theme: %v", err)
+	}
+	theme, err = LoadTheme("test-load-theme")
+	if err != nil {
+		t.Fatalf("LoadTheme() failed: %v", err)
+	}
+	if theme.ID != "magenta" || theme.Title != "0" || theme.MarkdownStyle() != "light" {
+		t.Errorf("Expected custom colors over the light theme, got %+v", theme)
+	}
+	if theme.Statuses["REVIEW"] != "#ff8800" || theme.Statuses[models.StatusDONE] != "2" {
+		t.Errorf("Expected merged status colors, got %v", theme.Statuses)
+	}
+	if builtinThemes["light"].Statuses["REVIEW"] != "" {
+		t.Error("Loading a custom theme must not change its base theme")
+	}
+
+	os.WriteFile(themePath, []byte(`{"base": "sepia"}`), 0644)
+	if _, err := LoadTheme("test-load-theme"); err == nil {
+		t.Error("LoadTheme() should fail for an unknown base theme")
+	}
+}
@@ -0,0 +1,799 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/buyruk-project/buyruk-cli/internal/models"
+)
+
+// YAMLRenderer renders output in YAML format
+type YAMLRenderer struct{}
+
+// NewYAMLRenderer creates a new YAMLRenderer
+func NewYAMLRenderer() *YAMLRenderer {
+	return &YAMLRenderer{}
+}
+
+// RenderIssue renders a single issue as YAML
+func (r *YAMLRenderer) RenderIssue(issue *models.Issue, w io.Writer) error {
+	return EncodeYAML(w, issue)
+}
+
+// RenderIssueList renders a list of issues as YAML
+func (r *YAMLRenderer) RenderIssueList(issues []*models.Issue, w io.Writer) error {
+	return EncodeYAML(w, issues)
+}
+
+// RenderEpic renders an epic as YAML
+func (r *YAMLRenderer) RenderEpic(epic *models.Epic, w io.Writer) error {
+	return EncodeYAML(w, epic)
+}
+
+// RenderProjectIndex renders a project index as YAML
+func (r *YAMLRenderer) RenderProjectIndex(index *models.ProjectIndex, w io.Writer) error {
+	return EncodeYAML(w, index)
+}
+
+// EncodeYAML writes v to w as YAML.
+// Field names and omission rules follow the JSON tags of v so that YAML output
+// always mirrors the JSON representation and the on-disk storage format.
+func EncodeYAML(w io.Writer, v interface{}) error {
+	data, err := MarshalYAML(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// MarshalYAML marshals v to YAML, preserving JSON field names and field order.
+// It writes block style YAML indented by two spaces, quoting strings that
+// would otherwise read as another type.
+func MarshalYAML(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("ui: failed to marshal value: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	value, err := readOrderedJSON(dec)
+	if err != nil {
+		return nil, fmt.Errorf("ui: failed to convert value to YAML: %w", err)
+	}
+
+	var buf bytes.Buffer
+	writeYAMLValue(&buf, value, 0)
+	return buf.Bytes(), nil
+}
+
+// UnmarshalYAML unmarshals YAML data into v using v's JSON tags.
+// This is the inverse of MarshalYAML. Block and flow collections, plain and
+// quoted scalars, block scalars, and comments are understood; anchors, tags,
+// and multi-document streams are not.
+func UnmarshalYAML(data []byte, v interface{}) error {
+	p := &yamlParser{lines: strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")}
+	raw, err := p.parseDocument()
+	if err != nil {
+		return fmt.Errorf("ui: failed to parse YAML: %w", err)
+	}
+
+	jsonData, err := json.Marshal(raw)
+	if err != nil {
+		return fmt.Errorf("ui: failed to convert YAML to JSON: %w", err)
+	}
+
+	if err := json.Unmarshal(jsonData, v); err != nil {
+		return fmt.Errorf("ui: failed to decode YAML: %w", err)
+	}
+	return nil
+}
+
+// orderedMap is a JSON object with its keys in their JSON order, which a Go
+// map would lose.
+type orderedMap struct {
+	keys   []string
+	values []interface{}
+}
+
+// readOrderedJSON reads the next JSON value from dec. Objects are read as
+// orderedMap, arrays as []interface{}, and numbers as json.Number.
+func readOrderedJSON(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+	switch delim {
+	case '{':
+		m := &orderedMap{}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := keyTok.(string)
+			if !ok {
+				return nil, fmt.Errorf("unexpected object key %v", keyTok)
+			}
+			value, err := readOrderedJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			m.keys = append(m.keys, key)
+			m.values = append(m.values, value)
+		}
+		_, err := dec.Token()
+		return m, err
+	case '[':
+		list := []interface{}{}
+		for dec.More() {
+			value, err := readOrderedJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err := dec.Token()
+		return list, err
+	default:
+		return nil, fmt.Errorf("unexpected delimiter %q", delim)
+	}
+}
+
+// writeYAMLValue writes a value at the top level, or as the value of a
+// sequence item or mapping key that has started the current line.
+// Nested lines are indented by indent spaces.
+func writeYAMLValue(buf *bytes.Buffer, value interface{}, indent int) {
+	switch v := value.(type) {
+	case *orderedMap:
+		if len(v.keys) == 0 {
+			buf.WriteString("{}\n")
+			return
+		}
+		for i, key := range v.keys {
+			if i > 0 {
+				buf.WriteString(strings.Repeat(" ", indent))
+			}
+			buf.WriteString(yamlScalar(key))
+			buf.WriteString(":")
+			writeYAMLChild(buf, v.values[i], indent, false)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			buf.WriteString("[]\n")
+			return
+		}
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteString(strings.Repeat(" ", indent))
+			}
+			buf.WriteString("-")
+			writeYAMLChild(buf, item, indent, true)
+		}
+	default:
+		writeYAMLScalar(buf, value, indent)
+	}
+}
+
+// writeYAMLChild writes the value of a mapping key or sequence item whose
+// indicator ("key:" or "-") was written at indent. Collections go on the
+// following lines, indented further, except that a mapping in a sequence
+// starts on the item's line. Anything else follows on the same line.
+func writeYAMLChild(buf *bytes.Buffer, value interface{}, indent int, inSequence bool) {
+	childIndent := indent + 2
+	switch v := value.(type) {
+	case *orderedMap:
+		if len(v.keys) > 0 {
+			if inSequence {
+				buf.WriteString(" ")
+				writeYAMLValue(buf, v, childIndent)
+				return
+			}
+			buf.WriteString("\n" + strings.Repeat(" ", childIndent))
+			writeYAMLValue(buf, v, childIndent)
+			return
+		}
+	case []interface{}:
+		if len(v) > 0 {
+			buf.WriteString("\n" + strings.Repeat(" ", childIndent))
+			writeYAMLValue(buf, v, childIndent)
+			return
+		}
+	}
+	buf.WriteString(" ")
+	writeYAMLValue(buf, value, childIndent)
+}
+
+// writeYAMLScalar writes a scalar and ends the line. Multi-line strings are
+// written as literal block scalars, indented by indent, when they can be.
+func writeYAMLScalar(buf *bytes.Buffer, value interface{}, indent int) {
+	s, ok := value.(string)
+	if !ok {
+		switch v := value.(type) {
+		case nil:
+			buf.WriteString("null\n")
+		case bool:
+			buf.WriteString(strconv.FormatBool(v) + "\n")
+		default:
+			fmt.Fprintf(buf, "%v\n", v)
+		}
+		return
+	}
+	if !literalBlockSafe(s) {
+		buf.WriteString(yamlScalar(s) + "\n")
+		return
+	}
+
+	content := s
+	if strings.HasSuffix(s, "\n") {
+		buf.WriteString("|\n")
+		content = strings.TrimSuffix(s, "\n")
+	} else {
+		buf.WriteString("|-\n")
+	}
+	pad := strings.Repeat(" ", indent)
+	for _, line := range strings.Split(content, "\n") {
+		if line != "" {
+			buf.WriteString(pad + line)
+		}
+		buf.WriteString("\n")
+	}
+}
+
+// literalBlockSafe reports whether a string can be written as a literal
+// block scalar and read back unchanged.
+func literalBlockSafe(s string) bool {
+	if !strings.Contains(s, "\n") || strings.HasSuffix(s, "\n\n") || s == "\n" {
+		return false
+	}
+	if s[0] == ' ' || s[0] == '\t' || s[0] == '\n' {
+		return false
+	}
+	for _, r := range s {
+		if r != '\n' && r != '\t' && !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	// yamlNumberRegex matches plain scalars that read as integers or floats.
+	yamlNumberRegex = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9][0-9_]*(\.[0-9_]*)?)([eE][-+]?[0-9]+)?$|^[-+]?0[xXoObB][0-9a-fA-F_]+$|^[-+]?\.(inf|Inf|INF)$|^\.(nan|NaN|NAN)$`)
+	// yamlTimestampRegex matches plain scalars that read as timestamps.
+	yamlTimestampRegex = regexp.MustCompile(`^[0-9]{4}-[0-9]{1,2}-[0-9]{1,2}`)
+	// yamlSpecialWords are plain scalars that read as null or booleans, in
+	// YAML 1.2 or 1.1.
+	yamlSpecialWords = map[string]bool{
+		"~": true, "null": true, "true": true, "false": true,
+		"yes": true, "no": true, "on": true, "off": true, "y": true, "n": true,
+	}
+)
+
+// yamlScalar returns s as a plain scalar if it reads back as the same
+// string, and as a double-quoted one otherwise.
+func yamlScalar(s string) string {
+	if plainScalarSafe(s) {
+		return s
+	}
+	return strconv.Quote(s)
+}
+
+// plainScalarSafe reports whether s can be written unquoted.
+func plainScalarSafe(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return false
+	}
+	if strings.ContainsRune("-?:,[]{}#&*!|>'\"%@`.", rune(s[0])) {
+		return false
+	}
+	if yamlSpecialWords[strings.ToLower(s)] || yamlNumberRegex.MatchString(s) || yamlTimestampRegex.MatchString(s) {
+		return false
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// yamlParser reads YAML documents line by line; see UnmarshalYAML.
+type yamlParser struct {
+	lines []string
+	pos   int
+}
+
+// parseDocument parses the single document of the input.
+func (p *yamlParser) parseDocument() (interface{}, error) {
+	if p.skipBlank() && strings.TrimSpace(p.lines[p.pos]) == "---" {
+		p.pos++
+	}
+	if !p.skipBlank() {
+		return nil, nil
+	}
+	indent, _, err := p.current()
+	if err != nil {
+		return nil, err
+	}
+	value, err := p.parseNode(indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.skipBlank() && strings.TrimSpace(p.lines[p.pos]) != "..." {
+		return nil, p.errorf("unexpected content %q", strings.TrimSpace(p.lines[p.pos]))
+	}
+	return value, nil
+}
+
+// skipBlank moves past empty and comment lines, and reports whether a line
+// with content is left.
+func (p *yamlParser) skipBlank() bool {
+	for ; p.pos < len(p.lines); p.pos++ {
+		line := strings.TrimSpace(p.lines[p.pos])
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return true
+		}
+	}
+	return false
+}
+
+// current returns the indentation and content of the current line.
+func (p *yamlParser) current() (int, string, error) {
+	line := p.lines[p.pos]
+	content := strings.TrimLeft(line, " ")
+	if strings.HasPrefix(content, "\t") {
+		return 0, "", p.errorf("tabs can't be used for indentation")
+	}
+	return len(line) - len(content), strings.TrimRight(content, " \t"), nil
+}
+
+// errorf returns a parse error at the current line.
+func (p *yamlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", p.pos+1, fmt.Sprintf(format, args...))
+}
+
+// parseNode parses the block node starting at the current line, which is
+// indented by indent.
+func (p *yamlParser) parseNode(indent int) (interface{}, error) {
+	_, content, err := p.current()
+	if err != nil {
+		return nil, err
+	}
+	if content == "-" || strings.HasPrefix(content, "- ") {
+		return p.parseSequence(indent)
+	}
+	if _, _, ok, err := splitMappingKey(content); err != nil {
+		return nil, p.errorf("%v", err)
+	} else if ok {
+		return p.parseMapping(indent)
+	}
+
+	value, err := p.parseInline(content, indent)
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// parseSequence parses the items of a block sequence indented by indent.
+func (p *yamlParser) parseSequence(indent int) (interface{}, error) {
+	list := []interface{}{}
+	for p.skipBlank() {
+		lineIndent, content, err := p.current()
+		if err != nil {
+			return nil, err
+		}
+		if lineIndent < indent {
+			break
+		}
+		if lineIndent > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+		if content != "-" && !strings.HasPrefix(content, "- ") {
+			break
+		}
+
+		rest := strings.TrimLeft(strings.TrimPrefix(content, "-"), " ")
+		if rest == "" || strings.HasPrefix(rest, "#") {
+			p.pos++
+			item, err := p.parseChild(indent, false)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+			continue
+		}
+		if rest[0] == '|' || rest[0] == '>' {
+			item, err := p.parseBlockScalar(rest, indent)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+			continue
+		}
+		// Parse the rest of the line as if it started a line of its own,
+		// so the keys of a mapping item line up with its first key
+		column := lineIndent + len(content) - len(rest)
+		p.lines[p.pos] = strings.Repeat(" ", column) + rest
+		item, err := p.parseNode(column)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, item)
+	}
+	return list, nil
+}
+
+// parseMapping parses the keys of a block mapping indented by indent.
+func (p *yamlParser) parseMapping(indent int) (interface{}, error) {
+	m := map[string]interface{}{}
+	for p.skipBlank() {
+		lineIndent, content, err := p.current()
+		if err != nil {
+			return nil, err
+		}
+		if lineIndent < indent {
+			break
+		}
+		if lineIndent > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+		key, rest, ok, err := splitMappingKey(content)
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		if !ok {
+			return nil, p.errorf("expected a mapping key, got %q", content)
+		}
+		if _, dup := m[key]; dup {
+			return nil, p.errorf("duplicate key %q", key)
+		}
+
+		if rest == "" || strings.HasPrefix(rest, "#") {
+			p.pos++
+			if m[key], err = p.parseChild(indent, true); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if m[key], err = p.parseInline(rest, indent); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// parseChild parses the block node under a mapping key or sequence item
+// indented by indent whose line has no value. A sequence under a mapping key
+// may have the key's indentation.
+func (p *yamlParser) parseChild(indent int, sameIndentSequence bool) (interface{}, error) {
+	if !p.skipBlank() {
+		return nil, nil
+	}
+	childIndent, content, err := p.current()
+	if err != nil {
+		return nil, err
+	}
+	if childIndent > indent {
+		return p.parseNode(childIndent)
+	}
+	if childIndent == indent && sameIndentSequence && (content == "-" || strings.HasPrefix(content, "- ")) {
+		return p.parseSequence(indent)
+	}
+	return nil, nil
+}
+
+// parseInline parses a value that follows its key or item indicator on the
+// current line, and moves past the lines it takes. A block scalar takes the
+// following lines indented further than indent.
+func (p *yamlParser) parseInline(s string, indent int) (interface{}, error) {
+	if s[0] == '|' || s[0] == '>' {
+		return p.parseBlockScalar(s, indent)
+	}
+	value, rest, err := parseFlowValue(s, false)
+	if err != nil {
+		return nil, p.errorf("%v", err)
+	}
+	if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "#") {
+		return nil, p.errorf("unexpected %q after value", rest)
+	}
+	p.pos++
+	return value, nil
+}
+
+// parseBlockScalar parses a literal (|) or folded (>) block scalar with the
+// given header line.
+func (p *yamlParser) parseBlockScalar(header string, indent int) (interface{}, error) {
+	if i := strings.Index(header, " #"); i >= 0 {
+		header = header[:i]
+	}
+	folded := header[0] == '>'
+	chomp, contentIndent := byte(0), 0
+	for _, c := range header[1:] {
+		switch {
+		case c == '-' || c == '+':
+			chomp = byte(c)
+		case c >= '1' && c <= '9':
+			contentIndent = indent + int(c-'0')
+		default:
+			return nil, p.errorf("invalid block scalar header %q", header)
+		}
+	}
+	p.pos++
+
+	var lines []string
+	for ; p.pos < len(p.lines); p.pos++ {
+		line := p.lines[p.pos]
+		if strings.TrimSpace(line) == "" {
+			if contentIndent > 0 && len(line) > contentIndent {
+				lines = append(lines, line[contentIndent:])
+			} else {
+				lines = append(lines, "")
+			}
+			continue
+		}
+		lineIndent := len(line) - len(strings.TrimLeft(line, " "))
+		if contentIndent == 0 {
+			if lineIndent <= indent {
+				break
+			}
+			contentIndent = lineIndent
+		}
+		if lineIndent < contentIndent {
+			break
+		}
+		lines = append(lines, line[contentIndent:])
+	}
+
+	// Trailing empty lines are kept only with the + indicator
+	trailing := 0
+	for trailing < len(lines) && lines[len(lines)-1-trailing] == "" {
+		trailing++
+	}
+	lines = lines[:len(lines)-trailing]
+	if len(lines) == 0 {
+		return "", nil
+	}
+
+	var text string
+	if folded {
+		var b strings.Builder
+		for i, line := range lines {
+			prevText := i > 0 && lines[i-1] != "" && !strings.HasPrefix(lines[i-1], " ")
+			switch {
+			case i == 0:
+			case prevText && line == "":
+				// The break before empty lines is dropped
+			case prevText && !strings.HasPrefix(line, " "):
+				b.WriteString(" ")
+			default:
+				b.WriteString("\n")
+			}
+			b.WriteString(line)
+		}
+		text = b.String()
+	} else {
+		text = strings.Join(lines, "\n")
+	}
+
+	switch chomp {
+	case '-':
+	case '+':
+		text += strings.Repeat("\n", trailing+1)
+	default:
+		text += "\n"
+	}
+	return text, nil
+}
+
+// splitMappingKey splits "key: value" into its key and the rest of the
+// line, and reports whether content starts with a mapping key at all.
+func splitMappingKey(content string) (string, string, bool, error) {
+	if content[0] == '"' || content[0] == '\'' {
+		key, rest, err := parseQuoted(content)
+		if err != nil {
+			return "", "", false, err
+		}
+		rest = strings.TrimLeft(rest, " ")
+		if rest != ":" && !strings.HasPrefix(rest, ": ") {
+			return "", "", false, nil
+		}
+		return key, strings.TrimSpace(rest[1:]), true, nil
+	}
+	if strings.ContainsRune("[{|>", rune(content[0])) {
+		return "", "", false, nil
+	}
+	for i := 0; i < len(content); i++ {
+		if content[i] == '#' && i > 0 && content[i-1] == ' ' {
+			return "", "", false, nil
+		}
+		if content[i] == ':' && (i+1 == len(content) || content[i+1] == ' ') {
+			return strings.TrimSpace(content[:i]), strings.TrimSpace(content[i+1:]), true, nil
+		}
+	}
+	return "", "", false, nil
+}
+
+// parseFlowValue parses a scalar or flow collection at the start of s and
+// returns the rest of s. In a flow collection, plain scalars end at , ] or }.
+func parseFlowValue(s string, inFlow bool) (interface{}, string, error) {
+	s = strings.TrimLeft(s, " ")
+	if s == "" {
+		return nil, "", nil
+	}
+	switch s[0] {
+	case '"', '\'':
+		return parseQuoted(s)
+	case '[':
+		list := []interface{}{}
+		rest := strings.TrimLeft(s[1:], " ")
+		for {
+			if rest == "" {
+				return nil, "", fmt.Errorf("unclosed flow sequence")
+			}
+			if rest[0] == ']' {
+				return list, rest[1:], nil
+			}
+			item, after, err := parseFlowValue(rest, true)
+			if err != nil {
+				return nil, "", err
+			}
+			list = append(list, item)
+			if rest, err = flowSeparator(after, ']'); err != nil {
+				return nil, "", err
+			}
+		}
+	case '{':
+		m := map[string]interface{}{}
+		rest := strings.TrimLeft(s[1:], " ")
+		for {
+			if rest == "" {
+				return nil, "", fmt.Errorf("unclosed flow mapping")
+			}
+			if rest[0] == '}' {
+				return m, rest[1:], nil
+			}
+			key, after, ok, err := splitFlowKey(rest)
+			if err != nil {
+				return nil, "", err
+			}
+			if !ok {
+				return nil, "", fmt.Errorf("expected a mapping key at %q", rest)
+			}
+			value, after, err := parseFlowValue(after, true)
+			if err != nil {
+				return nil, "", err
+			}
+			m[key] = value
+			if rest, err = flowSeparator(after, '}'); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	end := len(s)
+	if inFlow {
+		if i := strings.IndexAny(s, ",]}"); i >= 0 {
+			end = i
+		}
+	}
+	if i := strings.Index(s[:end], " #"); i >= 0 {
+		end = i
+	}
+	return resolvePlainScalar(strings.TrimSpace(s[:end])), s[end:], nil
+}
+
+// flowSeparator moves past the comma after a flow collection item, leaving
+// the closing bracket for the caller.
+func flowSeparator(s string, closing byte) (string, error) {
+	s = strings.TrimLeft(s, " ")
+	switch {
+	case s == "":
+		return "", fmt.Errorf("unclosed flow collection")
+	case s[0] == ',':
+		return strings.TrimLeft(s[1:], " "), nil
+	case s[0] == closing:
+		return s, nil
+	default:
+		return "", fmt.Errorf("unexpected %q in flow collection", s)
+	}
+}
+
+// splitFlowKey splits "key: value" in a flow mapping into its key and the
+// rest.
+func splitFlowKey(s string) (string, string, bool, error) {
+	if s[0] == '"' || s[0] == '\'' {
+		key, rest, err := parseQuoted(s)
+		if err != nil {
+			return "", "", false, err
+		}
+		rest = strings.TrimLeft(rest, " ")
+		if !strings.HasPrefix(rest, ":") {
+			return "", "", false, nil
+		}
+		return key, rest[1:], true, nil
+	}
+	i := strings.Index(s, ":")
+	if i < 0 || strings.ContainsAny(s[:i], ",]}") {
+		return "", "", false, nil
+	}
+	return strings.TrimSpace(s[:i]), s[i+1:], true, nil
+}
+
+// parseQuoted parses a single- or double-quoted scalar at the start of s and
+// returns the rest of s.
+func parseQuoted(s string) (string, string, error) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == quote && quote == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			b.WriteByte('\'')
+			i++
+		case c == quote:
+			if quote == '\'' {
+				return b.String(), s[i+1:], nil
+			}
+			// Double-quoted escapes are those of Go, but for \/ and \e
+			unquoted, err := strconv.Unquote(`"` + strings.NewReplacer(`\/`, `/`, `\e`, `\x1b`).Replace(s[1:i]) + `"`)
+			if err != nil {
+				return "", "", fmt.Errorf("invalid quoted scalar %s", s[:i+1])
+			}
+			return unquoted, s[i+1:], nil
+		case c == '\\' && quote == '"':
+			if i+1 < len(s) {
+				i++
+			}
+		default:
+			if quote == '\'' {
+				b.WriteByte(c)
+			}
+		}
+	}
+	return "", "", fmt.Errorf("unclosed quoted scalar %s", s)
+}
+
+// resolvePlainScalar returns the value a plain scalar reads as: null, a
+// boolean, a number, or else the string itself.
+func resolvePlainScalar(s string) interface{} {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	if !yamlNumberRegex.MatchString(s) {
+		return s
+	}
+	clean := strings.ReplaceAll(s, "_", "")
+	if n, err := strconv.ParseInt(clean, 0, 64); err == nil {
+		return json.Number(strconv.FormatInt(n, 10))
+	}
+	if f, err := strconv.ParseFloat(clean, 64); err == nil && !strings.ContainsAny(strings.ToLower(clean), "xob") {
+		if json.Valid([]byte(clean)) {
+			return json.Number(clean)
+		}
+		return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+	}
+	// .inf and .nan have no JSON form
+	return s
+}